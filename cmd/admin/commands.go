@@ -0,0 +1,426 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"os/user"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/audit"
+	"github.com/darisadam/madabank-server/internal/domain/queue"
+	domainUser "github.com/darisadam/madabank-server/internal/domain/user"
+	"github.com/darisadam/madabank-server/internal/pkg/crypto"
+	"github.com/darisadam/madabank-server/internal/pkg/keyprovider"
+	"github.com/darisadam/madabank-server/internal/pkg/notifier"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/darisadam/madabank-server/internal/service"
+	"github.com/google/uuid"
+)
+
+const cardBatchSize = 500
+
+func createAdminUser(db *sql.DB, args []string) error {
+	fs := flag.NewFlagSet("create-admin-user", flag.ExitOnError)
+	email := fs.String("email", "", "admin email (required)")
+	firstName := fs.String("first-name", "Admin", "first name")
+	lastName := fs.String("last-name", "User", "last name")
+	_ = fs.Parse(args)
+
+	if *email == "" {
+		return fmt.Errorf("-email is required")
+	}
+
+	// Read the password from the environment so it never lands in shell history
+	password := os.Getenv("ADMIN_PASSWORD")
+	if len(password) < 8 {
+		return fmt.Errorf("ADMIN_PASSWORD must be set to at least 8 characters")
+	}
+
+	hash, err := crypto.HashPassword(password)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	u := &domainUser.User{
+		ID:           uuid.New(),
+		Email:        *email,
+		PasswordHash: hash,
+		FirstName:    *firstName,
+		LastName:     *lastName,
+		KYCStatus:    "verified",
+		Role:         domainUser.RoleAdmin,
+		IsActive:     true,
+	}
+	if err := repository.NewUserRepository(db).Create(u); err != nil {
+		return err
+	}
+
+	recordAudit(db, "ADMIN_USER_CREATED", fmt.Sprintf("user:%s", u.ID), nil)
+	fmt.Printf("Created admin user %s (%s)\n", u.Email, u.ID)
+	return nil
+}
+
+func unlockUser(db *sql.DB, args []string) error {
+	fs := flag.NewFlagSet("unlock-user", flag.ExitOnError)
+	email := fs.String("email", "", "user email (required)")
+	_ = fs.Parse(args)
+
+	if *email == "" {
+		return fmt.Errorf("-email is required")
+	}
+
+	userRepo := repository.NewUserRepository(db)
+	u, err := userRepo.GetByEmail(*email)
+	if err != nil {
+		return err
+	}
+
+	if err := userRepo.Update(u.ID, map[string]interface{}{"is_active": true}); err != nil {
+		return err
+	}
+
+	recordAudit(db, "ADMIN_USER_UNLOCKED", fmt.Sprintf("user:%s", u.ID), nil)
+	fmt.Printf("Unlocked user %s\n", u.Email)
+	return nil
+}
+
+func freezeAccount(db *sql.DB, args []string) error {
+	fs := flag.NewFlagSet("freeze-account", flag.ExitOnError)
+	accountNumber := fs.String("account", "", "account number (required)")
+	reason := fs.String("reason", "", "freeze reason: fraud, legal_hold or customer_request (required)")
+	_ = fs.Parse(args)
+
+	if *accountNumber == "" || *reason == "" {
+		return fmt.Errorf("-account and -reason are required")
+	}
+	if !account.FreezeReason(*reason).Valid() {
+		return fmt.Errorf("-reason must be fraud, legal_hold or customer_request")
+	}
+
+	emailNotifier, err := notifier.FromEnv()
+	if err != nil {
+		return err
+	}
+
+	accountRepo := repository.NewAccountRepository(db)
+	acc, err := accountRepo.GetByAccountNumber(*accountNumber)
+	if err != nil {
+		return err
+	}
+
+	accountService := service.NewAccountService(accountRepo, repository.NewUserRepository(db), repository.NewAuditRepository(db), repository.NewInterestRepository(db), emailNotifier)
+	if _, err := accountService.FreezeAccount(acc.ID, account.FreezeReason(*reason), operatorMetadata(nil)); err != nil {
+		return err
+	}
+
+	fmt.Printf("Froze account %s (%s)\n", acc.AccountNumber, *reason)
+	return nil
+}
+
+// reissueEncryptionKey re-encrypts every card number, CVV, PIN and wallet token
+// number and recomputes their fingerprints, which are keyed off the data key. The old key is
+// read from OLD_ENCRYPTION_KEY; the new key comes from the configured key
+// provider (ENCRYPTION_KEY_PROVIDER), exactly as the API resolves it.
+func reissueEncryptionKey(db *sql.DB, args []string) error {
+	fs := flag.NewFlagSet("reissue-encryption-key", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "decrypt and re-encrypt without writing")
+	_ = fs.Parse(args)
+
+	oldEncryptor, err := crypto.NewEncryptor(os.Getenv("OLD_ENCRYPTION_KEY"))
+	if err != nil {
+		return fmt.Errorf("OLD_ENCRYPTION_KEY: %w", err)
+	}
+
+	ctx := context.Background()
+	provider, err := keyprovider.FromEnv(ctx)
+	if err != nil {
+		return err
+	}
+	newKey, err := provider.DataKey(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to resolve new data key from %s: %w", provider.Name(), err)
+	}
+	newEncryptor, err := crypto.NewEncryptor(string(newKey))
+	if err != nil {
+		return err
+	}
+
+	adminRepo := repository.NewAdminRepository(db)
+	cardRepo := repository.NewCardRepository(db)
+
+	var afterID uuid.UUID
+	var processed int
+	for {
+		cards, err := adminRepo.ListCardsAfter(afterID, cardBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(cards) == 0 {
+			break
+		}
+
+		for _, c := range cards {
+			number, err := oldEncryptor.Decrypt(c.CardNumberEncrypted)
+			if err != nil {
+				return fmt.Errorf("card %s: failed to decrypt number with old key: %w", c.ID, err)
+			}
+			cvv, err := oldEncryptor.Decrypt(c.CVVEncrypted)
+			if err != nil {
+				return fmt.Errorf("card %s: failed to decrypt CVV with old key: %w", c.ID, err)
+			}
+
+			numberEnc, err := newEncryptor.Encrypt(number)
+			if err != nil {
+				return err
+			}
+			cvvEnc, err := newEncryptor.Encrypt(cvv)
+			if err != nil {
+				return err
+			}
+
+			updates := map[string]interface{}{
+				"card_number_encrypted": numberEnc,
+				"card_number_hash":      newEncryptor.Fingerprint(number),
+				"cvv_encrypted":         cvvEnc,
+			}
+			if c.HasPIN() {
+				pin, err := oldEncryptor.Decrypt(c.PINEncrypted)
+				if err != nil {
+					return fmt.Errorf("card %s: failed to decrypt PIN with old key: %w", c.ID, err)
+				}
+				pinEnc, err := newEncryptor.Encrypt(pin)
+				if err != nil {
+					return err
+				}
+				updates["pin_encrypted"] = pinEnc
+			}
+
+			if !*dryRun {
+				if err := cardRepo.Update(c.ID, updates); err != nil {
+					return err
+				}
+			}
+			processed++
+		}
+
+		afterID = cards[len(cards)-1].ID
+	}
+
+	var tokens int
+	afterID = uuid.Nil
+	for {
+		batch, err := adminRepo.ListCardTokensAfter(afterID, cardBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		for _, t := range batch {
+			number, err := oldEncryptor.Decrypt(t.TokenNumberEncrypted)
+			if err != nil {
+				return fmt.Errorf("card token %s: failed to decrypt number with old key: %w", t.ID, err)
+			}
+			numberEnc, err := newEncryptor.Encrypt(number)
+			if err != nil {
+				return err
+			}
+
+			if !*dryRun {
+				if err := adminRepo.UpdateCardTokenNumber(t.ID, numberEnc, newEncryptor.Fingerprint(number)); err != nil {
+					return err
+				}
+			}
+			tokens++
+		}
+
+		afterID = batch[len(batch)-1].ID
+	}
+
+	if *dryRun {
+		fmt.Printf("Dry run: %d cards and %d wallet tokens can be re-encrypted with the %s key\n", processed, tokens, provider.Name())
+		return nil
+	}
+
+	recordAudit(db, "ADMIN_ENCRYPTION_KEY_REISSUED", "cards", map[string]interface{}{"cards": processed, "tokens": tokens, "provider": provider.Name()})
+	fmt.Printf("Re-encrypted %d cards and %d wallet tokens with the %s key\n", processed, tokens, provider.Name())
+	return nil
+}
+
+// backfillCardHashes fills in the card number fingerprint for cards created
+// before card authorizations needed to look cards up by number
+func backfillCardHashes(db *sql.DB, _ []string) error {
+	ctx := context.Background()
+	provider, err := keyprovider.FromEnv(ctx)
+	if err != nil {
+		return err
+	}
+	key, err := provider.DataKey(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to resolve data key from %s: %w", provider.Name(), err)
+	}
+	encryptor, err := crypto.NewEncryptor(string(key))
+	if err != nil {
+		return err
+	}
+
+	adminRepo := repository.NewAdminRepository(db)
+	cardRepo := repository.NewCardRepository(db)
+
+	var afterID uuid.UUID
+	var updated int
+	for {
+		cards, err := adminRepo.ListCardsAfter(afterID, cardBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(cards) == 0 {
+			break
+		}
+
+		for _, c := range cards {
+			if c.CardNumberHash != "" {
+				continue
+			}
+			number, err := encryptor.Decrypt(c.CardNumberEncrypted)
+			if err != nil {
+				return fmt.Errorf("card %s: failed to decrypt number: %w", c.ID, err)
+			}
+			if err := cardRepo.Update(c.ID, map[string]interface{}{"card_number_hash": encryptor.Fingerprint(number)}); err != nil {
+				return err
+			}
+			updated++
+		}
+
+		afterID = cards[len(cards)-1].ID
+	}
+
+	fmt.Printf("Backfilled card number hashes for %d cards\n", updated)
+	return nil
+}
+
+// replayOutbox sends undelivered work out again: dead-lettered jobs go back
+// to the queue with a fresh set of attempts, and merchant payment
+// notifications that ran out of retries are scheduled for delivery again.
+func replayOutbox(db *sql.DB, args []string) error {
+	fs := flag.NewFlagSet("replay-outbox", flag.ExitOnError)
+	kind := fs.String("kind", "", "only retry dead jobs of this kind")
+	limit := fs.Int("limit", 1000, "maximum number of dead jobs to retry")
+	since := fs.Duration("since", 7*24*time.Hour, "redeliver failed notifications for links paid within this window")
+	dryRun := fs.Bool("dry-run", false, "list dead jobs without retrying anything")
+	_ = fs.Parse(args)
+
+	jobRepo := repository.NewJobRepository(db)
+	dead, err := jobRepo.List(queue.StatusDead, *kind, *limit)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var retried int
+	for _, job := range dead {
+		fmt.Printf("%s %s attempts=%d error=%q\n", job.ID, job.Kind, job.Attempts, job.LastError)
+		if *dryRun {
+			continue
+		}
+		if _, err := jobRepo.Retry(job.ID, now); err != nil {
+			return fmt.Errorf("job %s: %w", job.ID, err)
+		}
+		retried++
+	}
+
+	if *dryRun {
+		fmt.Printf("Found %d dead jobs (dry run)\n", len(dead))
+		return nil
+	}
+
+	notifications, err := repository.NewMerchantRepository(db).RequeueFailedNotifications(now.Add(-*since))
+	if err != nil {
+		return err
+	}
+
+	recordAudit(db, "ADMIN_OUTBOX_REPLAYED", "outbox", map[string]interface{}{
+		"kind":          *kind,
+		"jobs":          retried,
+		"notifications": notifications,
+		"since":         since.String(),
+	})
+	fmt.Printf("Retried %d dead jobs and requeued %d failed merchant notifications\n", retried, notifications)
+	return nil
+}
+
+func recomputeBalances(db *sql.DB, args []string) error {
+	fs := flag.NewFlagSet("recompute-balances", flag.ExitOnError)
+	apply := fs.Bool("apply", false, "overwrite stored balances with the ledger balance")
+	_ = fs.Parse(args)
+
+	balances, err := repository.NewAdminRepository(db).LedgerBalances()
+	if err != nil {
+		return err
+	}
+
+	accountRepo := repository.NewAccountRepository(db)
+	var drifted int
+	for _, b := range balances {
+		if math.Abs(b.Drift()) < 0.005 {
+			continue
+		}
+		drifted++
+		fmt.Printf("%s stored=%.2f ledger=%.2f drift=%.2f\n", b.AccountNumber, b.StoredBalance, b.LedgerBalance, b.Drift())
+
+		if *apply {
+			acc, err := accountRepo.GetByID(b.AccountID)
+			if err != nil {
+				return err
+			}
+			if err := accountRepo.UpdateBalance(b.AccountID, acc.Version, b.LedgerBalance); err != nil {
+				return err
+			}
+			recordAudit(db, "ADMIN_BALANCE_RECOMPUTED", fmt.Sprintf("account:%s", b.AccountID), map[string]interface{}{
+				"stored_balance": b.StoredBalance,
+				"ledger_balance": b.LedgerBalance,
+			})
+		}
+	}
+
+	fmt.Printf("Checked %d accounts, %d out of sync", len(balances), drifted)
+	if *apply && drifted > 0 {
+		fmt.Print(" (fixed)")
+	}
+	fmt.Println()
+	return nil
+}
+
+// operatorMetadata marks audit metadata as written by the admin CLI, and by
+// which operator
+func operatorMetadata(metadata map[string]interface{}) map[string]interface{} {
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	metadata["source"] = "admin-cli"
+	if u, err := user.Current(); err == nil {
+		metadata["operator"] = u.Username
+	}
+	return metadata
+}
+
+// recordAudit writes an audit entry attributed to the operator running the CLI.
+// Failures are reported but do not undo the operation.
+func recordAudit(db *sql.DB, action, resource string, metadata map[string]interface{}) {
+	err := repository.NewAuditRepository(db).Create(&audit.AuditLog{
+		EventID:  uuid.New(),
+		Action:   action,
+		Resource: resource,
+		Status:   "success",
+		Metadata: operatorMetadata(metadata),
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to write audit log: %v\n", err)
+	}
+}
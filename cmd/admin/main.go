@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/pkg/dbmetrics"
+	"github.com/darisadam/madabank-server/internal/pkg/metricspush"
+	"github.com/joho/godotenv"
+)
+
+// command is a single admin subcommand
+type command struct {
+	name        string
+	description string
+	run         func(db *sql.DB, args []string) error
+}
+
+var commands = []command{
+	{"create-admin-user", "Create a user with the admin role", createAdminUser},
+	{"unlock-user", "Reactivate a deactivated user", unlockUser},
+	{"freeze-account", "Freeze an account by account number", freezeAccount},
+	{"reissue-encryption-key", "Re-encrypt card data from OLD_ENCRYPTION_KEY to the current data key", reissueEncryptionKey},
+	{"backfill-card-hashes", "Compute card number fingerprints for cards that predate card authorizations", backfillCardHashes},
+	{"replay-outbox", "Retry dead-lettered jobs and failed merchant webhook notifications", replayOutbox},
+	{"recompute-balances", "Compare stored balances with the transaction ledger and optionally fix them", recomputeBalances},
+}
+
+func main() {
+	_ = godotenv.Load()
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	name := os.Args[1]
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+
+		db, err := openDB()
+		if err != nil {
+			log.Fatal("Failed to connect to database: ", err)
+		}
+		defer func() { _ = db.Close() }()
+
+		started := time.Now()
+		err = cmd.run(db, os.Args[2:])
+		publishRun(name, started, err)
+		if err != nil {
+			_ = db.Close()
+			log.Fatalf("%s failed: %v", name, err)
+		}
+		return
+	}
+
+	usage()
+	os.Exit(2)
+}
+
+// publishRun pushes the outcome of the command when a Pushgateway or
+// remote-write endpoint is configured, as the process exits long before the
+// next scrape
+func publishRun(name string, started time.Time, err error) {
+	publisher, pubErr := metricspush.FromEnv()
+	if pubErr != nil {
+		log.Printf("Metrics not pushed: %v", pubErr)
+		return
+	}
+	if publisher == nil {
+		return
+	}
+	job := "admin_" + strings.ReplaceAll(name, "-", "_")
+	if pubErr := publisher.Publish(context.Background(), job, metricspush.RunRegistry(started, time.Now(), err)); pubErr != nil {
+		log.Printf("Metrics not pushed to %s: %v", publisher.Name(), pubErr)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: admin <command> [flags]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-24s %s\n", cmd.name, cmd.description)
+	}
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Run 'admin <command> -h' for command flags.")
+}
+
+func openDB() (*sql.DB, error) {
+	databaseURL := os.Getenv("DATABASE_URL")
+	if databaseURL == "" {
+		host := os.Getenv("DB_HOST")
+		port := os.Getenv("DB_PORT")
+		user := os.Getenv("DB_USER")
+		name := os.Getenv("DB_NAME")
+		password := os.Getenv("DB_PASSWORD")
+
+		if host != "" && port != "" && user != "" && name != "" && password != "" {
+			databaseURL = fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable", user, url.QueryEscape(password), host, port, name)
+		} else {
+			return nil, fmt.Errorf("DATABASE_URL is not set and DB_* variables are missing")
+		}
+	}
+
+	// Inject password if present (from Secrets Manager)
+	if dbPassword := os.Getenv("DB_PASSWORD"); dbPassword != "" {
+		databaseURL = strings.Replace(databaseURL, "PLACEHOLDER", url.QueryEscape(dbPassword), 1)
+	}
+
+	db, err := dbmetrics.Open(databaseURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return db, nil
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var migrationNamePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// createMigration writes empty <timestamp>_<name>.up.sql/.down.sql files
+func createMigration(dir, name string, now time.Time) (string, string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !migrationNamePattern.MatchString(name) {
+		return "", "", fmt.Errorf("name must contain only letters, digits and underscores")
+	}
+
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", "", err
+	}
+
+	base := fmt.Sprintf("%s_%s", now.UTC().Format("20060102150405"), name)
+	up := filepath.Join(dir, base+".up.sql")
+	down := filepath.Join(dir, base+".down.sql")
+
+	for _, path := range []string{up, down} {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err != nil {
+			return "", "", err
+		}
+		_ = f.Close()
+	}
+
+	return up, down, nil
+}
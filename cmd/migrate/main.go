@@ -6,20 +6,54 @@ import (
 	"log"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/golang-migrate/migrate/v4"
-
-	"github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
+
+	"github.com/darisadam/madabank-server/internal/pkg/dbmigrate"
 )
 
+const usageText = `Usage: migrate <command> [args]
+
+Commands:
+  up [N]            Apply all (or the next N) pending migrations
+  down [N]          Roll back all (or the last N) migrations
+  goto <version>    Migrate up or down to a specific version
+  force <version>   Set the version without running migrations (clears dirty state)
+  version           Print the current version
+  create <name>     Create timestamped up/down files in the migrations directory
+  seed [flags]      Insert demo users, accounts, cards and transactions (development only)`
+
+const migrationsDir = "migrations"
+
 func main() {
 	// Load .env
 	_ = godotenv.Load()
 
+	if len(os.Args) < 2 {
+		log.Fatal(usageText)
+	}
+
+	command := os.Args[1]
+	args := os.Args[2:]
+
+	// create only touches the filesystem
+	if command == "create" {
+		if len(args) != 1 {
+			log.Fatal("Usage: migrate create <name>")
+		}
+		up, down, err := createMigration(migrationsDir, args[0], time.Now())
+		if err != nil {
+			log.Fatal("Failed to create migration: ", err)
+		}
+		fmt.Printf("Created: %s\nCreated: %s\n", up, down)
+		return
+	}
+
 	databaseURL := os.Getenv("DATABASE_URL")
 	if databaseURL == "" {
 		host := os.Getenv("DB_HOST")
@@ -50,42 +84,80 @@ func main() {
 		_ = db.Close()
 	}()
 
-	// Create driver
-	driver, err := postgres.WithInstance(db, &postgres.Config{})
-	if err != nil {
-		log.Fatal("Failed to create migration driver:", err)
+	// seed writes data through the repositories; the schema must already be migrated
+	if command == "seed" {
+		if err := seedDatabase(db, args); err != nil {
+			_ = db.Close()
+			log.Fatal("Failed to seed database: ", err)
+		}
+		return
 	}
 
-	// Create migration instance
-	m, err := migrate.NewWithDatabaseInstance(
-		"file://migrations",
-		"postgres",
-		driver,
-	)
+	// Create migration instance over the embedded migrations
+	m, err := dbmigrate.New(db)
 	if err != nil {
-		log.Fatal("Failed to create migration instance:", err)
+		log.Fatal(err)
 	}
 
-	// Get command
-	if len(os.Args) < 2 {
-		log.Fatal("Usage: migrate [up|down|version]")
+	// Refuse to run on a dirty database; only force can repair it
+	if command != "force" {
+		if version, dirty, err := m.Version(); err == nil && dirty {
+			fmt.Fprintf(os.Stderr, "Database is dirty at version %d. Fix the failed migration manually, then run: migrate force <version>\n", version)
+			os.Exit(1)
+		}
 	}
 
-	command := os.Args[1]
-
 	switch command {
 	case "up":
-		if err := m.Up(); err != nil && err != migrate.ErrNoChange {
+		steps, err := optionalSteps(args)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if steps > 0 {
+			err = m.Steps(steps)
+		} else {
+			err = m.Up()
+		}
+		if err != nil && err != migrate.ErrNoChange {
 			log.Fatal("Failed to run migrations:", err)
 		}
 		fmt.Println("Migrations applied successfully")
 
 	case "down":
-		if err := m.Down(); err != nil && err != migrate.ErrNoChange {
+		steps, err := optionalSteps(args)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if steps > 0 {
+			err = m.Steps(-steps)
+		} else {
+			err = m.Down()
+		}
+		if err != nil && err != migrate.ErrNoChange {
 			log.Fatal("Failed to rollback migrations:", err)
 		}
 		fmt.Println("Migrations rolled back successfully")
 
+	case "goto":
+		version, err := requiredVersion(args)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := m.Migrate(uint(version)); err != nil && err != migrate.ErrNoChange {
+			log.Fatal("Failed to migrate to version:", err)
+		}
+		fmt.Printf("Migrated to version %d\n", version)
+
+	case "force":
+		version, err := requiredVersion(args)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := m.Force(int(version)); err != nil {
+			log.Fatal("Failed to force version:", err)
+		}
+		fmt.Printf("Forced version %d\n", version)
+
 	case "version":
 		version, dirty, err := m.Version()
 		if err != nil {
@@ -94,6 +166,30 @@ func main() {
 		fmt.Printf("Version: %d, Dirty: %v\n", version, dirty)
 
 	default:
-		log.Fatal("Unknown command. Use: up, down, or version")
+		log.Fatal(usageText)
+	}
+}
+
+// optionalSteps parses the optional N argument of up/down (0 means "all")
+func optionalSteps(args []string) (int, error) {
+	if len(args) == 0 {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("N must be a positive integer, got %q", args[0])
+	}
+	return n, nil
+}
+
+// requiredVersion parses the version argument of goto/force
+func requiredVersion(args []string) (uint64, error) {
+	if len(args) != 1 {
+		return 0, fmt.Errorf("a version argument is required")
+	}
+	v, err := strconv.ParseUint(args[0], 10, 63)
+	if err != nil {
+		return 0, fmt.Errorf("invalid version %q", args[0])
 	}
+	return v, nil
 }
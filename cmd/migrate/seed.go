@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/card"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/domain/user"
+	"github.com/darisadam/madabank-server/internal/pkg/crypto"
+	"github.com/darisadam/madabank-server/internal/pkg/keyprovider"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+)
+
+// seedPassword is shared by every demo user so the mobile team can log in
+const seedPassword = "Password123!"
+
+var seedNames = [][2]string{
+	{"Adam", "Darisman"}, {"Siti", "Rahayu"}, {"Budi", "Santoso"}, {"Dewi", "Lestari"},
+	{"Agus", "Wijaya"}, {"Putri", "Maharani"}, {"Rizky", "Pratama"}, {"Ayu", "Kusuma"},
+	{"Eko", "Saputra"}, {"Nur", "Hidayah"}, {"Fajar", "Nugroho"}, {"Intan", "Permata"},
+}
+
+var seedDescriptions = []string{
+	"Lunch", "Rent share", "Groceries", "Coffee", "Electricity bill", "Birthday gift",
+	"Fuel", "Internet", "Movie tickets", "Book", "Dinner split", "Gym membership",
+}
+
+// seedDatabase creates demo users with checking and savings accounts, a debit
+// card each, and a history of deposits, transfers and withdrawals. Balances
+// are only moved through the repository's ACID operations, so they always
+// match the transaction ledger.
+func seedDatabase(db *sql.DB, args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ExitOnError)
+	users := fs.Int("users", 10, "number of demo users")
+	txns := fs.Int("transactions", 300, "number of transactions to generate")
+	randSeed := fs.Int64("seed", 1, "random seed (same seed, same data shape)")
+	force := fs.Bool("force", false, "allow seeding when ENV=production")
+	_ = fs.Parse(args)
+
+	if os.Getenv("ENV") == "production" && !*force {
+		return fmt.Errorf("refusing to seed a production database (use -force to override)")
+	}
+
+	ctx := context.Background()
+	provider, err := keyprovider.FromEnv(ctx)
+	if err != nil {
+		return err
+	}
+	key, err := provider.DataKey(ctx)
+	if err != nil {
+		return err
+	}
+	encryptor, err := crypto.NewEncryptor(string(key))
+	if err != nil {
+		return err
+	}
+
+	passwordHash, err := crypto.HashPassword(seedPassword)
+	if err != nil {
+		return err
+	}
+
+	userRepo := repository.NewUserRepository(db)
+	accountRepo := repository.NewAccountRepository(db)
+	cardRepo := repository.NewCardRepository(db)
+	transactionRepo := repository.NewTransactionRepository(db, sql.LevelDefault)
+
+	rng := rand.New(rand.NewSource(*randSeed)) // #nosec G404 -- demo data only
+	runID := time.Now().Unix()
+
+	var accounts []*account.Account
+	for i := 0; i < *users; i++ {
+		name := seedNames[i%len(seedNames)]
+		phone := fmt.Sprintf("+62812%08d", rng.Intn(100_000_000))
+		u := &user.User{
+			ID:           uuid.New(),
+			Email:        fmt.Sprintf("demo%d.%d@madabank.dev", runID, i+1),
+			PasswordHash: passwordHash,
+			FirstName:    name[0],
+			LastName:     name[1],
+			Phone:        &phone,
+			KYCStatus:    "verified",
+			Role:         user.RoleCustomer,
+			IsActive:     true,
+		}
+		if err := userRepo.Create(u); err != nil {
+			return err
+		}
+
+		for _, accountType := range []account.AccountType{account.AccountTypeChecking, account.AccountTypeSavings} {
+			number, err := accountRepo.GenerateAccountNumber()
+			if err != nil {
+				return err
+			}
+			acc := &account.Account{
+				ID:            uuid.New(),
+				UserID:        u.ID,
+				AccountNumber: number,
+				AccountType:   accountType,
+				Currency:      "IDR",
+				Status:        account.AccountStatusActive,
+			}
+			if accountType == account.AccountTypeSavings {
+				acc.InterestRate = 0.025
+			}
+			if err := accountRepo.Create(acc); err != nil {
+				return err
+			}
+			accounts = append(accounts, acc)
+
+			// Opening deposit
+			if err := seedTransaction(transactionRepo, transaction.TransactionTypeDeposit, nil, &acc.ID,
+				float64(1_000_000+rng.Intn(49)*1_000_000), "Opening deposit"); err != nil {
+				return err
+			}
+		}
+
+		if err := seedCard(cardRepo, encryptor, accounts[len(accounts)-2], u); err != nil {
+			return err
+		}
+	}
+
+	if len(accounts) < 2 {
+		fmt.Printf("Seeded %d users\n", *users)
+		return nil
+	}
+
+	var created int
+	for i := 0; i < *txns; i++ {
+		from := accounts[rng.Intn(len(accounts))]
+		amount := float64(10_000 + rng.Intn(500)*1_000)
+		description := seedDescriptions[rng.Intn(len(seedDescriptions))]
+
+		var err error
+		switch roll := rng.Intn(10); {
+		case roll < 6:
+			to := accounts[rng.Intn(len(accounts))]
+			if to.ID == from.ID {
+				continue
+			}
+			err = seedTransaction(transactionRepo, transaction.TransactionTypeTransfer, &from.ID, &to.ID, amount, description)
+		case roll < 8:
+			err = seedTransaction(transactionRepo, transaction.TransactionTypeDeposit, nil, &from.ID, amount*10, "Salary")
+		default:
+			err = seedTransaction(transactionRepo, transaction.TransactionTypeWithdrawal, &from.ID, nil, amount, "ATM withdrawal")
+		}
+		if err != nil {
+			// Insufficient funds and similar business errors are expected in random data
+			continue
+		}
+		created++
+	}
+
+	fmt.Printf("Seeded %d users, %d accounts, %d cards and %d transactions (password: %s)\n",
+		*users, len(accounts), *users, created+len(accounts), seedPassword)
+	return nil
+}
+
+func seedCard(cardRepo repository.CardRepository, encryptor *crypto.Encryptor, acc *account.Account, u *user.User) error {
+	number, err := cardRepo.GenerateCardNumber()
+	if err != nil {
+		return err
+	}
+	numberEnc, err := encryptor.Encrypt(number)
+	if err != nil {
+		return err
+	}
+	cvvEnc, err := encryptor.Encrypt(cardRepo.GenerateCVV())
+	if err != nil {
+		return err
+	}
+
+	expiry := time.Now().AddDate(3, 0, 0)
+	return cardRepo.Create(&card.Card{
+		ID:                  uuid.New(),
+		AccountID:           acc.ID,
+		CardNumberEncrypted: numberEnc,
+		CardNumberHash:      encryptor.Fingerprint(number),
+		CVVEncrypted:        cvvEnc,
+		CardHolderName:      fmt.Sprintf("%s %s", u.FirstName, u.LastName),
+		CardType:            card.CardTypeDebit,
+		ExpiryMonth:         int(expiry.Month()),
+		ExpiryYear:          expiry.Year(),
+		Status:              card.CardStatusActive,
+		DailyLimit:          10_000_000,
+		Controls:            card.DefaultControls(),
+	})
+}
+
+func seedTransaction(repo repository.TransactionRepository, txnType transaction.TransactionType, from, to *uuid.UUID, amount float64, description string) error {
+	txn := &transaction.Transaction{
+		ID:              uuid.New(),
+		IdempotencyKey:  "seed-" + uuid.NewString(),
+		FromAccountID:   from,
+		ToAccountID:     to,
+		Amount:          amount,
+		TransactionType: txnType,
+		Status:          transaction.TransactionStatusPending,
+		Description:     description,
+		Metadata:        map[string]interface{}{"seed": true, "currency": "IDR"},
+	}
+
+	switch txnType {
+	case transaction.TransactionTypeTransfer:
+		return repo.ExecuteTransfer(*from, *to, amount, txn)
+	case transaction.TransactionTypeDeposit:
+		return repo.ExecuteDeposit(*to, amount, txn)
+	default:
+		return repo.ExecuteWithdrawal(*from, amount, txn)
+	}
+}
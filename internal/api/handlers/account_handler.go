@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/darisadam/madabank-server/internal/domain/account"
@@ -48,7 +49,7 @@ func (h *AccountHandler) CreateAccount(c *gin.Context) {
 
 	newAccount, err := h.accountService.CreateAccount(userID, &req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, errorBody(err))
 		return
 	}
 
@@ -57,11 +58,17 @@ func (h *AccountHandler) CreateAccount(c *gin.Context) {
 
 // GetAccounts godoc
 // @Summary Get user accounts
-// @Description Get all accounts belonging to the authenticated user
+// @Description Get a page of the accounts belonging to the authenticated user. Closed accounts are only listed with status=closed.
 // @Tags accounts
 // @Produce json
 // @Security BearerAuth
+// @Param status query string false "Status (active, frozen, closed)"
+// @Param type query string false "Account type (checking, savings)"
+// @Param sort query string false "created_at, balance or account_number, prefixed with - for descending" default(-created_at)
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
 // @Success 200 {object} account.AccountListResponse
+// @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
 // @Router /api/v1/accounts [get]
 func (h *AccountHandler) GetAccounts(c *gin.Context) {
@@ -72,7 +79,14 @@ func (h *AccountHandler) GetAccounts(c *gin.Context) {
 	}
 	userID := val.(uuid.UUID)
 
-	accounts, err := h.accountService.GetUserAccounts(userID)
+	var req account.ListAccountsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter := req.Filter(userID)
+
+	accounts, total, err := h.accountService.GetUserAccounts(filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -81,21 +95,15 @@ func (h *AccountHandler) GetAccounts(c *gin.Context) {
 	// Convert to response format
 	accountResponses := make([]account.AccountResponse, len(accounts))
 	for i, acc := range accounts {
-		accountResponses[i] = account.AccountResponse{
-			ID:            acc.ID,
-			AccountNumber: acc.AccountNumber,
-			AccountType:   acc.AccountType,
-			Balance:       acc.Balance,
-			Currency:      acc.Currency,
-			InterestRate:  acc.InterestRate,
-			Status:        acc.Status,
-			CreatedAt:     acc.CreatedAt,
-		}
+		accountResponses[i] = account.ToResponse(acc)
 	}
 
 	c.JSON(http.StatusOK, account.AccountListResponse{
 		Accounts: accountResponses,
-		Total:    len(accountResponses),
+		Total:    total,
+		Limit:    filter.Limit,
+		Offset:   filter.Offset,
+		HasMore:  filter.Offset+len(accountResponses) < total,
 	})
 }
 
@@ -171,9 +179,51 @@ func (h *AccountHandler) GetBalance(c *gin.Context) {
 	c.JSON(http.StatusOK, balance)
 }
 
+// AddCurrency godoc
+// @Summary Add a currency to an account
+// @Description Open a zero balance in another currency, so the account can send and receive in it
+// @Tags accounts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Account ID"
+// @Param request body account.AddCurrencyRequest true "Currency"
+// @Success 201 {object} account.CurrencyBalance
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/accounts/{id}/currencies [post]
+func (h *AccountHandler) AddCurrency(c *gin.Context) {
+	val, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	userID := val.(uuid.UUID)
+
+	accountID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account ID"})
+		return
+	}
+
+	var req account.AddCurrencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	balance, err := h.accountService.AddCurrency(accountID, userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, balance)
+}
+
 // UpdateAccount godoc
 // @Summary Update account
-// @Description Update account status (freeze, activate, close)
+// @Description Update account status (freeze, activate, close). Freezing requires the reason customer_request; accounts the bank froze cannot be unfrozen here. Send the version last read to refuse the update if the account changed since
 // @Tags accounts
 // @Accept json
 // @Produce json
@@ -183,6 +233,7 @@ func (h *AccountHandler) GetBalance(c *gin.Context) {
 // @Success 200 {object} account.Account
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Router /api/v1/accounts/{id} [patch]
 func (h *AccountHandler) UpdateAccount(c *gin.Context) {
 	val, exists := c.Get("user_id")
@@ -206,6 +257,10 @@ func (h *AccountHandler) UpdateAccount(c *gin.Context) {
 	}
 
 	updated, err := h.accountService.UpdateAccount(accountID, userID, &req)
+	if errors.Is(err, account.ErrVersionConflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
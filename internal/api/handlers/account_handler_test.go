@@ -43,12 +43,12 @@ func (m *MockAccountService) GetAccountByNumber(accountNumber string, userID uui
 	return args.Get(0).(*account.Account), args.Error(1)
 }
 
-func (m *MockAccountService) GetUserAccounts(userID uuid.UUID) ([]*account.Account, error) {
-	args := m.Called(userID)
+func (m *MockAccountService) GetUserAccounts(f *account.ListFilter) ([]*account.Account, int, error) {
+	args := m.Called(f)
 	if args.Get(0) == nil {
-		return nil, args.Error(1)
+		return nil, args.Int(1), args.Error(2)
 	}
-	return args.Get(0).([]*account.Account), args.Error(1)
+	return args.Get(0).([]*account.Account), args.Int(1), args.Error(2)
 }
 
 func (m *MockAccountService) GetBalance(accountID uuid.UUID, userID uuid.UUID) (*account.BalanceResponse, error) {
@@ -59,6 +59,14 @@ func (m *MockAccountService) GetBalance(accountID uuid.UUID, userID uuid.UUID) (
 	return args.Get(0).(*account.BalanceResponse), args.Error(1)
 }
 
+func (m *MockAccountService) AddCurrency(accountID uuid.UUID, userID uuid.UUID, req *account.AddCurrencyRequest) (*account.CurrencyBalance, error) {
+	args := m.Called(accountID, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*account.CurrencyBalance), args.Error(1)
+}
+
 func (m *MockAccountService) UpdateAccount(accountID uuid.UUID, userID uuid.UUID, req *account.UpdateAccountRequest) (*account.Account, error) {
 	args := m.Called(accountID, userID, req)
 	if args.Get(0) == nil {
@@ -67,6 +75,14 @@ func (m *MockAccountService) UpdateAccount(accountID uuid.UUID, userID uuid.UUID
 	return args.Get(0).(*account.Account), args.Error(1)
 }
 
+func (m *MockAccountService) FreezeAccount(accountID uuid.UUID, reason account.FreezeReason, metadata map[string]interface{}) (*account.Account, error) {
+	args := m.Called(accountID, reason, metadata)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*account.Account), args.Error(1)
+}
+
 func (m *MockAccountService) CloseAccount(accountID uuid.UUID, userID uuid.UUID) error {
 	args := m.Called(accountID, userID)
 	return args.Error(0)
@@ -176,7 +192,7 @@ func TestAccountHandler_GetAccounts_Success(t *testing.T) {
 		{ID: uuid.New(), AccountNumber: "2222222222", Balance: 2000},
 	}
 
-	mockService.On("GetUserAccounts", userID).Return(accounts, nil)
+	mockService.On("GetUserAccounts", &account.ListFilter{UserID: userID, Sort: "-created_at", Limit: 20}).Return(accounts, 2, nil)
 
 	req, _ := http.NewRequest("GET", "/accounts", nil)
 	w := httptest.NewRecorder()
@@ -188,9 +204,57 @@ func TestAccountHandler_GetAccounts_Success(t *testing.T) {
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
 	assert.Len(t, response.Accounts, 2)
+	assert.Equal(t, 2, response.Total)
+	assert.False(t, response.HasMore)
 	mockService.AssertExpectations(t)
 }
 
+func TestAccountHandler_GetAccounts_ClosedSortedByBalance(t *testing.T) {
+	mockService := new(MockAccountService)
+	handler := NewAccountHandler(mockService)
+
+	router := setupAccountRouter()
+	userID := uuid.New()
+
+	router.GET("/accounts", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		handler.GetAccounts(c)
+	})
+
+	mockService.On("GetUserAccounts", &account.ListFilter{
+		UserID: userID, Status: account.AccountStatusClosed, Type: account.AccountTypeSavings, Sort: "-balance", Limit: 1,
+	}).Return([]*account.Account{{ID: uuid.New(), Status: account.AccountStatusClosed}}, 3, nil)
+
+	req, _ := http.NewRequest("GET", "/accounts?status=closed&type=savings&sort=-balance&limit=1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response account.AccountListResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 3, response.Total)
+	assert.True(t, response.HasMore)
+}
+
+func TestAccountHandler_GetAccounts_InvalidSort(t *testing.T) {
+	mockService := new(MockAccountService)
+	handler := NewAccountHandler(mockService)
+
+	router := setupAccountRouter()
+	router.GET("/accounts", func(c *gin.Context) {
+		c.Set("user_id", uuid.New())
+		handler.GetAccounts(c)
+	})
+
+	req, _ := http.NewRequest("GET", "/accounts?sort=password", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "GetUserAccounts", mock.Anything)
+}
+
 // ==================== GetAccountByID Tests ====================
 
 func TestAccountHandler_GetAccountByID_Success(t *testing.T) {
@@ -246,6 +310,34 @@ func TestAccountHandler_GetAccountByID_NotFound(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+// ==================== AddCurrency Tests ====================
+
+func TestAccountHandler_AddCurrency_Success(t *testing.T) {
+	mockService := new(MockAccountService)
+	handler := NewAccountHandler(mockService)
+
+	router := setupAccountRouter()
+	userID := uuid.New()
+	accountID := uuid.New()
+
+	router.POST("/accounts/:id/currencies", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		handler.AddCurrency(c)
+	})
+
+	mockService.On("AddCurrency", accountID, userID, &account.AddCurrencyRequest{Currency: "USD"}).
+		Return(account.NewCurrencyBalance("USD", 0), nil)
+
+	req, _ := http.NewRequest("POST", "/accounts/"+accountID.String()+"/currencies", bytes.NewBufferString(`{"currency":"USD"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"currency":"USD","balance":0,"formatted_balance":"USD 0.00"}`, w.Body.String())
+	mockService.AssertExpectations(t)
+}
+
 // ==================== GetBalance Tests ====================
 
 func TestAccountHandler_GetBalance_Success(t *testing.T) {
@@ -299,7 +391,7 @@ func TestAccountHandler_UpdateAccount_Success(t *testing.T) {
 
 	mockService.On("UpdateAccount", accountID, userID, mock.AnythingOfType("*account.UpdateAccountRequest")).Return(updatedAccount, nil)
 
-	reqBody := `{"status":"frozen"}`
+	reqBody := `{"status":"frozen","reason":"customer_request"}`
 	req, _ := http.NewRequest("PUT", "/accounts/"+accountID.String(), bytes.NewBufferString(reqBody))
 	req.Header.Set("Content-Type", "application/json")
 
@@ -310,6 +402,81 @@ func TestAccountHandler_UpdateAccount_Success(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestAccountHandler_UpdateAccount_UnknownFreezeReason(t *testing.T) {
+	mockService := new(MockAccountService)
+	handler := NewAccountHandler(mockService)
+
+	router := setupAccountRouter()
+	accountID := uuid.New()
+
+	router.PUT("/accounts/:id", func(c *gin.Context) {
+		c.Set("user_id", uuid.New())
+		handler.UpdateAccount(c)
+	})
+
+	reqBody := `{"status":"frozen","reason":"bored"}`
+	req, _ := http.NewRequest("PUT", "/accounts/"+accountID.String(), bytes.NewBufferString(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "UpdateAccount", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAccountHandler_UpdateAccount_BankFreezeReason(t *testing.T) {
+	mockService := new(MockAccountService)
+	handler := NewAccountHandler(mockService)
+
+	router := setupAccountRouter()
+	accountID := uuid.New()
+
+	router.PUT("/accounts/:id", func(c *gin.Context) {
+		c.Set("user_id", uuid.New())
+		handler.UpdateAccount(c)
+	})
+
+	// Only the bank freezes for fraud, through the admin CLI
+	reqBody := `{"status":"frozen","reason":"fraud"}`
+	req, _ := http.NewRequest("PUT", "/accounts/"+accountID.String(), bytes.NewBufferString(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "UpdateAccount", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAccountHandler_UpdateAccount_VersionConflict(t *testing.T) {
+	mockService := new(MockAccountService)
+	handler := NewAccountHandler(mockService)
+
+	router := setupAccountRouter()
+	userID := uuid.New()
+	accountID := uuid.New()
+
+	router.PUT("/accounts/:id", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		handler.UpdateAccount(c)
+	})
+
+	mockService.On("UpdateAccount", accountID, userID, mock.MatchedBy(func(r *account.UpdateAccountRequest) bool {
+		return r.Version != nil && *r.Version == 2
+	})).Return(nil, account.ErrVersionConflict)
+
+	reqBody := `{"status":"frozen","reason":"customer_request","version":2}`
+	req, _ := http.NewRequest("PUT", "/accounts/"+accountID.String(), bytes.NewBufferString(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "changed by another request")
+}
+
 // ==================== CloseAccount Tests ====================
 
 func TestAccountHandler_CloseAccount_Success(t *testing.T) {
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/darisadam/madabank-server/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type AdminHandler struct {
+	auditService service.AuditService
+}
+
+func NewAdminHandler(auditService service.AuditService) *AdminHandler {
+	return &AdminHandler{
+		auditService: auditService,
+	}
+}
+
+// VerifyAuditChain godoc
+// @Summary Verify audit log integrity
+// @Description Recompute the audit log hash chain and report the first tampered record, if any
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} audit.ChainVerification
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} audit.ChainVerification
+// @Router /api/v1/admin/audit/verify [get]
+func (h *AdminHandler) VerifyAuditChain(c *gin.Context) {
+	result, err := h.auditService.VerifyChain()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to verify audit chain"})
+		return
+	}
+
+	if !result.Valid {
+		c.JSON(http.StatusConflict, result)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
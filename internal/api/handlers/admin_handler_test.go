@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/darisadam/madabank-server/internal/domain/audit"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAuditService is a mock implementation of service.AuditService
+type MockAuditService struct {
+	mock.Mock
+}
+
+func (m *MockAuditService) VerifyChain() (*audit.ChainVerification, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*audit.ChainVerification), args.Error(1)
+}
+
+func setupAdminRouter(handler *AdminHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/admin/audit/verify", handler.VerifyAuditChain)
+	return router
+}
+
+func TestAdminHandler_VerifyAuditChain_Valid(t *testing.T) {
+	mockService := new(MockAuditService)
+	router := setupAdminRouter(NewAdminHandler(mockService))
+
+	mockService.On("VerifyChain").Return(&audit.ChainVerification{Valid: true, RecordsChecked: 10}, nil)
+
+	req, _ := http.NewRequest("GET", "/admin/audit/verify", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"valid":true`)
+}
+
+func TestAdminHandler_VerifyAuditChain_Broken(t *testing.T) {
+	mockService := new(MockAuditService)
+	router := setupAdminRouter(NewAdminHandler(mockService))
+
+	id := int64(42)
+	mockService.On("VerifyChain").Return(&audit.ChainVerification{Valid: false, BrokenAtID: &id}, nil)
+
+	req, _ := http.NewRequest("GET", "/admin/audit/verify", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), `"broken_at_id":42`)
+}
+
+func TestAdminHandler_VerifyAuditChain_Error(t *testing.T) {
+	mockService := new(MockAuditService)
+	router := setupAdminRouter(NewAdminHandler(mockService))
+
+	mockService.On("VerifyChain").Return(nil, fmt.Errorf("db down"))
+
+	req, _ := http.NewRequest("GET", "/admin/audit/verify", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/darisadam/madabank-server/internal/domain/beneficiary"
+	"github.com/darisadam/madabank-server/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type BeneficiaryHandler struct {
+	beneficiaryService service.BeneficiaryService
+}
+
+func NewBeneficiaryHandler(beneficiaryService service.BeneficiaryService) *BeneficiaryHandler {
+	return &BeneficiaryHandler{
+		beneficiaryService: beneficiaryService,
+	}
+}
+
+// CreateBeneficiary godoc
+// @Summary Save a beneficiary
+// @Description Save a transfer recipient: an account at this bank, a registered phone number, or an account at another bank. Internal account and phone recipients are verified against an active account; external bank recipients are saved unverified.
+// @Tags beneficiaries
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body beneficiary.CreateBeneficiaryRequest true "Recipient details"
+// @Success 201 {object} beneficiary.Beneficiary
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/beneficiaries [post]
+func (h *BeneficiaryHandler) CreateBeneficiary(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req beneficiary.CreateBeneficiaryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	b, err := h.beneficiaryService.CreateBeneficiary(userID.(uuid.UUID), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, b)
+}
+
+// ListBeneficiaries godoc
+// @Summary List beneficiaries
+// @Description Get the user's saved recipients, most recently used first
+// @Tags beneficiaries
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} beneficiary.Beneficiary
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/beneficiaries [get]
+func (h *BeneficiaryHandler) ListBeneficiaries(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	beneficiaries, err := h.beneficiaryService.ListBeneficiaries(userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, beneficiaries)
+}
+
+// GetBeneficiary godoc
+// @Summary Get beneficiary
+// @Description Get a saved recipient and its verification state
+// @Tags beneficiaries
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Beneficiary ID"
+// @Success 200 {object} beneficiary.Beneficiary
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/beneficiaries/{id} [get]
+func (h *BeneficiaryHandler) GetBeneficiary(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	beneficiaryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid beneficiary ID"})
+		return
+	}
+
+	b, err := h.beneficiaryService.GetBeneficiary(userID.(uuid.UUID), beneficiaryID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, b)
+}
+
+// UpdateBeneficiary godoc
+// @Summary Rename beneficiary
+// @Description Change a saved recipient's nickname. The destination cannot be changed; save a new beneficiary instead.
+// @Tags beneficiaries
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Beneficiary ID"
+// @Param request body beneficiary.UpdateBeneficiaryRequest true "New nickname"
+// @Success 200 {object} beneficiary.Beneficiary
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/beneficiaries/{id} [patch]
+func (h *BeneficiaryHandler) UpdateBeneficiary(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	beneficiaryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid beneficiary ID"})
+		return
+	}
+
+	var req beneficiary.UpdateBeneficiaryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	b, err := h.beneficiaryService.UpdateBeneficiary(userID.(uuid.UUID), beneficiaryID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, b)
+}
+
+// DeleteBeneficiary godoc
+// @Summary Delete beneficiary
+// @Description Remove a saved recipient. Later transfers to it are flagged as going to a new beneficiary.
+// @Tags beneficiaries
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Beneficiary ID"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/beneficiaries/{id} [delete]
+func (h *BeneficiaryHandler) DeleteBeneficiary(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	beneficiaryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid beneficiary ID"})
+		return
+	}
+
+	if err := h.beneficiaryService.DeleteBeneficiary(userID.(uuid.UUID), beneficiaryID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// VerifyBeneficiary godoc
+// @Summary Re-verify beneficiary
+// @Description Resolve an internal account or phone recipient again. The beneficiary becomes failed if it no longer resolves to an active account.
+// @Tags beneficiaries
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Beneficiary ID"
+// @Success 200 {object} beneficiary.Beneficiary
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/beneficiaries/{id}/verify [post]
+func (h *BeneficiaryHandler) VerifyBeneficiary(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	beneficiaryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid beneficiary ID"})
+		return
+	}
+
+	b, err := h.beneficiaryService.VerifyBeneficiary(userID.(uuid.UUID), beneficiaryID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, b)
+}
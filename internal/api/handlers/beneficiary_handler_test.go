@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/darisadam/madabank-server/internal/domain/beneficiary"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockBeneficiaryService is a mock implementation of service.BeneficiaryService
+type MockBeneficiaryService struct {
+	mock.Mock
+}
+
+func (m *MockBeneficiaryService) CreateBeneficiary(userID uuid.UUID, req *beneficiary.CreateBeneficiaryRequest) (*beneficiary.Beneficiary, error) {
+	args := m.Called(userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*beneficiary.Beneficiary), args.Error(1)
+}
+
+func (m *MockBeneficiaryService) ListBeneficiaries(userID uuid.UUID) ([]*beneficiary.Beneficiary, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*beneficiary.Beneficiary), args.Error(1)
+}
+
+func (m *MockBeneficiaryService) GetBeneficiary(userID uuid.UUID, id uuid.UUID) (*beneficiary.Beneficiary, error) {
+	args := m.Called(userID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*beneficiary.Beneficiary), args.Error(1)
+}
+
+func (m *MockBeneficiaryService) UpdateBeneficiary(userID uuid.UUID, id uuid.UUID, req *beneficiary.UpdateBeneficiaryRequest) (*beneficiary.Beneficiary, error) {
+	args := m.Called(userID, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*beneficiary.Beneficiary), args.Error(1)
+}
+
+func (m *MockBeneficiaryService) DeleteBeneficiary(userID uuid.UUID, id uuid.UUID) error {
+	args := m.Called(userID, id)
+	return args.Error(0)
+}
+
+func (m *MockBeneficiaryService) VerifyBeneficiary(userID uuid.UUID, id uuid.UUID) (*beneficiary.Beneficiary, error) {
+	args := m.Called(userID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*beneficiary.Beneficiary), args.Error(1)
+}
+
+func setupBeneficiaryRouter(handler *BeneficiaryHandler, userID uuid.UUID) *gin.Engine {
+	router := setupCardRouter()
+	group := router.Group("/beneficiaries", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Next()
+	})
+	group.POST("", handler.CreateBeneficiary)
+	group.GET("", handler.ListBeneficiaries)
+	group.GET("/:id", handler.GetBeneficiary)
+	group.PATCH("/:id", handler.UpdateBeneficiary)
+	group.DELETE("/:id", handler.DeleteBeneficiary)
+	group.POST("/:id/verify", handler.VerifyBeneficiary)
+	return router
+}
+
+func TestBeneficiaryHandler_CreateBeneficiary(t *testing.T) {
+	mockService := new(MockBeneficiaryService)
+	userID := uuid.New()
+	router := setupBeneficiaryRouter(NewBeneficiaryHandler(mockService), userID)
+
+	mockService.On("CreateBeneficiary", userID, &beneficiary.CreateBeneficiaryRequest{
+		Type:        beneficiary.TypePhone,
+		Nickname:    "Mom",
+		PhoneNumber: "081234567890",
+	}).Return(&beneficiary.Beneficiary{ID: uuid.New(), Nickname: "Mom", VerificationStatus: beneficiary.VerificationVerified}, nil)
+
+	req, _ := http.NewRequest("POST", "/beneficiaries", bytes.NewBufferString(`{"type":"phone","nickname":"Mom","phone_number":"081234567890"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"verification_status":"verified"`)
+}
+
+func TestBeneficiaryHandler_CreateBeneficiary_InvalidType(t *testing.T) {
+	mockService := new(MockBeneficiaryService)
+	router := setupBeneficiaryRouter(NewBeneficiaryHandler(mockService), uuid.New())
+
+	req, _ := http.NewRequest("POST", "/beneficiaries", bytes.NewBufferString(`{"type":"wallet","nickname":"Mom"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "CreateBeneficiary", mock.Anything, mock.Anything)
+}
+
+func TestBeneficiaryHandler_GetBeneficiary_NotFound(t *testing.T) {
+	mockService := new(MockBeneficiaryService)
+	userID := uuid.New()
+	id := uuid.New()
+	router := setupBeneficiaryRouter(NewBeneficiaryHandler(mockService), userID)
+	mockService.On("GetBeneficiary", userID, id).Return(nil, fmt.Errorf("beneficiary not found"))
+
+	req, _ := http.NewRequest("GET", "/beneficiaries/"+id.String(), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestBeneficiaryHandler_DeleteBeneficiary(t *testing.T) {
+	mockService := new(MockBeneficiaryService)
+	userID := uuid.New()
+	id := uuid.New()
+	router := setupBeneficiaryRouter(NewBeneficiaryHandler(mockService), userID)
+	mockService.On("DeleteBeneficiary", userID, id).Return(nil)
+
+	req, _ := http.NewRequest("DELETE", "/beneficiaries/"+id.String(), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestBeneficiaryHandler_VerifyBeneficiary(t *testing.T) {
+	mockService := new(MockBeneficiaryService)
+	userID := uuid.New()
+	id := uuid.New()
+	router := setupBeneficiaryRouter(NewBeneficiaryHandler(mockService), userID)
+	mockService.On("VerifyBeneficiary", userID, id).
+		Return(&beneficiary.Beneficiary{ID: id, VerificationStatus: beneficiary.VerificationFailed}, nil)
+
+	req, _ := http.NewRequest("POST", "/beneficiaries/"+id.String()+"/verify", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"verification_status":"failed"`)
+}
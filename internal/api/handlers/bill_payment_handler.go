@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/darisadam/madabank-server/internal/domain/billpay"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type BillPaymentHandler struct {
+	billPaymentService service.BillPaymentService
+}
+
+func NewBillPaymentHandler(billPaymentService service.BillPaymentService) *BillPaymentHandler {
+	return &BillPaymentHandler{
+		billPaymentService: billPaymentService,
+	}
+}
+
+// ListBillers godoc
+// @Summary List billers
+// @Description Get the biller catalog, optionally filtered by category
+// @Tags bills
+// @Produce json
+// @Security BearerAuth
+// @Param category query string false "electricity, water, internet, phone or tax"
+// @Success 200 {array} billpay.Biller
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/bills/billers [get]
+func (h *BillPaymentHandler) ListBillers(c *gin.Context) {
+	var req billpay.ListBillersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	billers, err := h.billPaymentService.ListBillers(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, billers)
+}
+
+// Inquire godoc
+// @Summary Bill inquiry
+// @Description Fetch the amount due for a customer number. The returned inquiry_id is paid with /bills/payments.
+// @Tags bills
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body billpay.InquiryRequest true "Biller and customer number"
+// @Success 200 {object} billpay.Bill
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/bills/inquiry [post]
+func (h *BillPaymentHandler) Inquire(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req billpay.InquiryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	bill, err := h.billPaymentService.Inquire(userID.(uuid.UUID), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, bill)
+}
+
+// Pay godoc
+// @Summary Pay a bill
+// @Description Pay an inquired bill from one of the user's accounts. A 202 means the biller has not confirmed yet.
+// @Tags bills
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body billpay.PaymentRequest true "Payment details"
+// @Success 201 {object} transaction.Transaction
+// @Success 202 {object} transaction.Transaction
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/bills/payments [post]
+func (h *BillPaymentHandler) Pay(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req billpay.PaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	txn, err := h.billPaymentService.Pay(userID.(uuid.UUID), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if txn.Status == transaction.TransactionStatusPending {
+		c.JSON(http.StatusAccepted, txn)
+		return
+	}
+
+	c.JSON(http.StatusCreated, txn)
+}
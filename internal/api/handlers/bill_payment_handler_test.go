@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/darisadam/madabank-server/internal/domain/billpay"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockBillPaymentService is a mock implementation of service.BillPaymentService
+type MockBillPaymentService struct {
+	mock.Mock
+}
+
+func (m *MockBillPaymentService) ListBillers(req *billpay.ListBillersRequest) ([]*billpay.Biller, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*billpay.Biller), args.Error(1)
+}
+
+func (m *MockBillPaymentService) Inquire(userID uuid.UUID, req *billpay.InquiryRequest) (*billpay.Bill, error) {
+	args := m.Called(userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*billpay.Bill), args.Error(1)
+}
+
+func (m *MockBillPaymentService) Pay(userID uuid.UUID, req *billpay.PaymentRequest) (*transaction.Transaction, error) {
+	args := m.Called(userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*transaction.Transaction), args.Error(1)
+}
+
+func setupBillRouter(handler *BillPaymentHandler, userID uuid.UUID) *gin.Engine {
+	router := setupCardRouter()
+	withUser := func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Next()
+	}
+	router.GET("/bills/billers", handler.ListBillers)
+	router.POST("/bills/inquiry", withUser, handler.Inquire)
+	router.POST("/bills/payments", withUser, handler.Pay)
+	return router
+}
+
+func TestBillPaymentHandler_ListBillers(t *testing.T) {
+	mockService := new(MockBillPaymentService)
+	router := setupBillRouter(NewBillPaymentHandler(mockService), uuid.New())
+
+	mockService.On("ListBillers", &billpay.ListBillersRequest{Category: "electricity"}).Return([]*billpay.Biller{
+		{Code: "PLN_POSTPAID", Name: "PLN Postpaid", Category: billpay.BillerCategoryElectricity},
+	}, nil)
+
+	req, _ := http.NewRequest("GET", "/bills/billers?category=electricity", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "PLN_POSTPAID")
+	mockService.AssertExpectations(t)
+}
+
+func TestBillPaymentHandler_Inquire(t *testing.T) {
+	mockService := new(MockBillPaymentService)
+	userID := uuid.New()
+	router := setupBillRouter(NewBillPaymentHandler(mockService), userID)
+
+	mockService.On("Inquire", userID, &billpay.InquiryRequest{BillerCode: "PLN_POSTPAID", CustomerNumber: "512345678901"}).
+		Return(&billpay.Bill{InquiryID: "inq-1", Amount: 150000, TotalAmount: 152500}, nil)
+
+	body := []byte(`{"biller_code":"PLN_POSTPAID","customer_number":"512345678901"}`)
+	req, _ := http.NewRequest("POST", "/bills/inquiry", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"total_amount":152500`)
+	mockService.AssertExpectations(t)
+}
+
+func TestBillPaymentHandler_Inquire_InvalidCustomerNumber(t *testing.T) {
+	mockService := new(MockBillPaymentService)
+	router := setupBillRouter(NewBillPaymentHandler(mockService), uuid.New())
+
+	body := []byte(`{"biller_code":"PLN_POSTPAID","customer_number":"abc"}`)
+	req, _ := http.NewRequest("POST", "/bills/inquiry", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "Inquire", mock.Anything, mock.Anything)
+}
+
+func TestBillPaymentHandler_Pay(t *testing.T) {
+	tests := []struct {
+		name   string
+		status transaction.TransactionStatus
+		code   int
+	}{
+		{"completed", transaction.TransactionStatusCompleted, http.StatusCreated},
+		{"awaiting biller", transaction.TransactionStatusPending, http.StatusAccepted},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockBillPaymentService)
+			userID := uuid.New()
+			accountID := uuid.New()
+			inquiryID := uuid.New()
+			router := setupBillRouter(NewBillPaymentHandler(mockService), userID)
+
+			mockService.On("Pay", userID, &billpay.PaymentRequest{
+				AccountID:      accountID.String(),
+				InquiryID:      inquiryID.String(),
+				IdempotencyKey: "bill-1",
+			}).Return(&transaction.Transaction{ID: uuid.New(), TransactionType: transaction.TransactionTypeBillPayment, Status: tc.status}, nil)
+
+			body := []byte(fmt.Sprintf(`{"account_id":"%s","inquiry_id":"%s","idempotency_key":"bill-1"}`, accountID, inquiryID))
+			req, _ := http.NewRequest("POST", "/bills/payments", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.code, w.Code)
+			assert.Contains(t, w.Body.String(), "bill_payment")
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBillPaymentHandler_Pay_Error(t *testing.T) {
+	mockService := new(MockBillPaymentService)
+	router := setupBillRouter(NewBillPaymentHandler(mockService), uuid.New())
+
+	mockService.On("Pay", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("inquiry not found or expired"))
+
+	body := []byte(fmt.Sprintf(`{"account_id":"%s","inquiry_id":"%s","idempotency_key":"bill-1"}`, uuid.New(), uuid.New()))
+	req, _ := http.NewRequest("POST", "/bills/payments", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "inquiry not found or expired")
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/darisadam/madabank-server/internal/domain/card"
+	"github.com/darisadam/madabank-server/internal/domain/validation"
+	"github.com/darisadam/madabank-server/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CardAuthorizationHandler struct {
+	authorizationService service.CardAuthorizationService
+}
+
+func NewCardAuthorizationHandler(authorizationService service.CardAuthorizationService) *CardAuthorizationHandler {
+	return &CardAuthorizationHandler{
+		authorizationService: authorizationService,
+	}
+}
+
+// Authorize godoc
+// @Summary Authorize card payment
+// @Description Acquirer endpoint that validates a card payment and places a hold on the funds. Declines are returned with HTTP 200 and an ISO 8583 response code.
+// @Tags cards
+// @Accept json
+// @Produce json
+// @Param X-API-Key header string true "Partner API key"
+// @Param request body card.AuthorizeRequest true "Authorization request"
+// @Success 200 {object} card.AuthorizeResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/cards/authorize [post]
+func (h *CardAuthorizationHandler) Authorize(c *gin.Context) {
+	var req card.AuthorizeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.authorizationService.Authorize(&req)
+	var invalid validation.Errors
+	if errors.As(err, &invalid) {
+		c.JSON(http.StatusBadRequest, errorBody(err))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to process authorization"})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// Capture godoc
+// @Summary Capture card authorization
+// @Description Acquirer endpoint that settles an approved authorization, or part of it. A debit card payment is debited from the account; a credit card payment is charged to the card's balance. The rest of the hold is released.
+// @Tags cards
+// @Accept json
+// @Produce json
+// @Param X-API-Key header string true "Partner API key"
+// @Param id path string true "Authorization ID"
+// @Param request body card.CaptureRequest false "Amount to capture"
+// @Success 200 {object} card.Authorization
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/cards/authorizations/{id}/capture [post]
+func (h *CardAuthorizationHandler) Capture(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid authorization ID"})
+		return
+	}
+
+	// The body is optional: without one the whole authorization is captured
+	var req card.CaptureRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	a, err := h.authorizationService.Capture(id, &req)
+	if errors.Is(err, card.ErrAuthorizationNotOpen) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, a)
+}
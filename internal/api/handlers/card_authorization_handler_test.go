@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/darisadam/madabank-server/internal/domain/card"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockCardAuthorizationService is a mock implementation of service.CardAuthorizationService
+type MockCardAuthorizationService struct {
+	mock.Mock
+}
+
+func (m *MockCardAuthorizationService) Authorize(req *card.AuthorizeRequest) (*card.AuthorizeResponse, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*card.AuthorizeResponse), args.Error(1)
+}
+
+func (m *MockCardAuthorizationService) Capture(id uuid.UUID, req *card.CaptureRequest) (*card.Authorization, error) {
+	args := m.Called(id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*card.Authorization), args.Error(1)
+}
+
+const authorizeBody = `{"card_number":"4532015112830366","cvv":"123","expiry_month":12,"expiry_year":2029,
+	"amount":150000,"currency":"IDR","channel":"ecommerce","merchant_name":"Toko Buku",
+	"merchant_category_code":"5942","merchant_country":"ID"}`
+
+func TestCardAuthorizationHandler_Authorize_Approved(t *testing.T) {
+	mockService := new(MockCardAuthorizationService)
+	handler := NewCardAuthorizationHandler(mockService)
+
+	router := setupCardRouter()
+	router.POST("/cards/authorize", handler.Authorize)
+
+	mockService.On("Authorize", mock.AnythingOfType("*card.AuthorizeRequest")).
+		Return(&card.AuthorizeResponse{Approved: true, ResponseCode: card.ResponseApproved, AuthCode: "123456"}, nil)
+
+	req, _ := http.NewRequest("POST", "/cards/authorize", bytes.NewBufferString(authorizeBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"auth_code":"123456"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestCardAuthorizationHandler_Authorize_DeclineIsOK(t *testing.T) {
+	mockService := new(MockCardAuthorizationService)
+	handler := NewCardAuthorizationHandler(mockService)
+
+	router := setupCardRouter()
+	router.POST("/cards/authorize", handler.Authorize)
+
+	mockService.On("Authorize", mock.AnythingOfType("*card.AuthorizeRequest")).
+		Return(&card.AuthorizeResponse{Approved: false, ResponseCode: card.ResponseInsufficientFunds, DeclineReason: "insufficient funds"}, nil)
+
+	req, _ := http.NewRequest("POST", "/cards/authorize", bytes.NewBufferString(authorizeBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"response_code":"51"`)
+}
+
+func TestCardAuthorizationHandler_Authorize_InvalidBody(t *testing.T) {
+	mockService := new(MockCardAuthorizationService)
+	handler := NewCardAuthorizationHandler(mockService)
+
+	router := setupCardRouter()
+	router.POST("/cards/authorize", handler.Authorize)
+
+	req, _ := http.NewRequest("POST", "/cards/authorize", bytes.NewBufferString(`{"card_number":"abc"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "Authorize", mock.Anything)
+}
+
+func TestCardAuthorizationHandler_Authorize_ServiceError(t *testing.T) {
+	mockService := new(MockCardAuthorizationService)
+	handler := NewCardAuthorizationHandler(mockService)
+
+	router := setupCardRouter()
+	router.POST("/cards/authorize", handler.Authorize)
+
+	mockService.On("Authorize", mock.AnythingOfType("*card.AuthorizeRequest")).
+		Return(nil, fmt.Errorf("failed to lock funds for authorization: connection reset"))
+
+	req, _ := http.NewRequest("POST", "/cards/authorize", bytes.NewBufferString(authorizeBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.NotContains(t, w.Body.String(), "connection reset")
+}
+
+func TestCardAuthorizationHandler_Capture(t *testing.T) {
+	mockService := new(MockCardAuthorizationService)
+	handler := NewCardAuthorizationHandler(mockService)
+
+	router := setupCardRouter()
+	router.POST("/cards/authorizations/:id/capture", handler.Capture)
+
+	id := uuid.New()
+	mockService.On("Capture", id, &card.CaptureRequest{}).
+		Return(&card.Authorization{ID: id, Status: card.AuthorizationStatusCaptured}, nil)
+
+	// Without a body the whole authorization is captured
+	req, _ := http.NewRequest("POST", "/cards/authorizations/"+id.String()+"/capture", bytes.NewBuffer(nil))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"captured"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestCardAuthorizationHandler_Capture_NotOpen(t *testing.T) {
+	mockService := new(MockCardAuthorizationService)
+	handler := NewCardAuthorizationHandler(mockService)
+
+	router := setupCardRouter()
+	router.POST("/cards/authorizations/:id/capture", handler.Capture)
+
+	mockService.On("Capture", mock.Anything, mock.Anything).Return(nil, card.ErrAuthorizationNotOpen)
+
+	req, _ := http.NewRequest("POST", "/cards/authorizations/"+uuid.NewString()+"/capture", bytes.NewBufferString(`{"amount":50000}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
@@ -92,14 +92,98 @@ func (h *CardHandler) GetCards(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"cards": cards})
 }
 
+// RequestRevealChallenge godoc
+// @Summary Request card reveal code
+// @Description Start the step-up check for revealing card details. Verifies the password and sends a one-time code.
+// @Tags cards
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Card ID"
+// @Param request body card.RevealChallengeRequest true "Password"
+// @Success 200 {object} card.RevealChallengeResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/cards/{id}/reveal/challenge [post]
+func (h *CardHandler) RequestRevealChallenge(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	cardIDStr := c.Param("id")
+	cardID, err := uuid.Parse(cardIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid card ID"})
+		return
+	}
+
+	var req card.RevealChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	challenge, err := h.cardService.RequestRevealChallenge(userID.(uuid.UUID), cardID, req.Password)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, challenge)
+}
+
+// VerifyRevealChallenge godoc
+// @Summary Verify card reveal code
+// @Description Exchange the one-time code for a single-use reveal token valid for 2 minutes
+// @Tags cards
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Card ID"
+// @Param request body card.RevealVerifyRequest true "Challenge ID and code"
+// @Success 200 {object} card.RevealTokenResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/cards/{id}/reveal/verify [post]
+func (h *CardHandler) VerifyRevealChallenge(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	cardIDStr := c.Param("id")
+	cardID, err := uuid.Parse(cardIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid card ID"})
+		return
+	}
+
+	var req card.RevealVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	token, err := h.cardService.VerifyRevealChallenge(userID.(uuid.UUID), cardID, &req)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, token)
+}
+
 // GetCardDetails godoc
 // @Summary Get full card details
-// @Description Get decrypted card details (requires password verification)
+// @Description Get decrypted card details. Requires a reveal token from the step-up challenge; each token works once.
 // @Tags cards
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param request body card.CardDetailsRequest true "Card ID and password"
+// @Param request body card.CardDetailsRequest true "Card ID and reveal token"
 // @Success 200 {object} card.CardDetailsResponse
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
@@ -123,7 +207,7 @@ func (h *CardHandler) GetCardDetails(c *gin.Context) {
 		return
 	}
 
-	details, err := h.cardService.GetCardDetails(userID.(uuid.UUID), cardID, req.Password)
+	details, err := h.cardService.GetCardDetails(userID.(uuid.UUID), cardID, req.RevealToken)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
@@ -134,7 +218,7 @@ func (h *CardHandler) GetCardDetails(c *gin.Context) {
 
 // UpdateCard godoc
 // @Summary Update card
-// @Description Update card status or daily limit
+// @Description Update card status or daily limit. Blocked cards cannot be reactivated.
 // @Tags cards
 // @Accept json
 // @Produce json
@@ -174,6 +258,48 @@ func (h *CardHandler) UpdateCard(c *gin.Context) {
 	c.JSON(http.StatusOK, updated)
 }
 
+// UpdateControls godoc
+// @Summary Update card spending controls
+// @Description Enable or disable e-commerce, contactless, ATM and foreign usage, and block merchant categories
+// @Tags cards
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Card ID"
+// @Param request body card.UpdateControlsRequest true "Controls to change"
+// @Success 200 {object} card.CardResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/cards/{id}/controls [patch]
+func (h *CardHandler) UpdateControls(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	cardIDStr := c.Param("id")
+	cardID, err := uuid.Parse(cardIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid card ID"})
+		return
+	}
+
+	var req card.UpdateControlsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := h.cardService.UpdateControls(userID.(uuid.UUID), cardID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
 // BlockCard godoc
 // @Summary Block card
 // @Description Block a card (set status to blocked)
@@ -207,6 +333,114 @@ func (h *CardHandler) BlockCard(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Card blocked successfully"})
 }
 
+// FreezeCard godoc
+// @Summary Freeze card
+// @Description Temporarily freeze a card. Unlike blocking, this can be undone with unfreeze.
+// @Tags cards
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Card ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/cards/{id}/freeze [post]
+func (h *CardHandler) FreezeCard(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	cardIDStr := c.Param("id")
+	cardID, err := uuid.Parse(cardIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid card ID"})
+		return
+	}
+
+	if err := h.cardService.FreezeCard(userID.(uuid.UUID), cardID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Card frozen successfully"})
+}
+
+// UnfreezeCard godoc
+// @Summary Unfreeze card
+// @Description Reactivate a frozen card
+// @Tags cards
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Card ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/cards/{id}/unfreeze [post]
+func (h *CardHandler) UnfreezeCard(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	cardIDStr := c.Param("id")
+	cardID, err := uuid.Parse(cardIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid card ID"})
+		return
+	}
+
+	if err := h.cardService.UnfreezeCard(userID.(uuid.UUID), cardID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Card unfrozen successfully"})
+}
+
+// ReissueCard godoc
+// @Summary Reissue card
+// @Description Block the card and issue a replacement with a new number, CVV and expiry. Limits and controls are kept.
+// @Tags cards
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Card ID"
+// @Param request body card.ReissueCardRequest true "Reissue reason"
+// @Success 201 {object} card.CardResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/cards/{id}/reissue [post]
+func (h *CardHandler) ReissueCard(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	cardIDStr := c.Param("id")
+	cardID, err := uuid.Parse(cardIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid card ID"})
+		return
+	}
+
+	var req card.ReissueCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	replacement, err := h.cardService.ReissueCard(userID.(uuid.UUID), cardID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, replacement)
+}
+
 // DeleteCard godoc
 // @Summary Delete card
 // @Description Delete a card (soft delete)
@@ -239,3 +473,133 @@ func (h *CardHandler) DeleteCard(c *gin.Context) {
 
 	c.JSON(http.StatusNoContent, nil)
 }
+
+// RotateCVV godoc
+// @Summary Rotate card CVV
+// @Description Replace the card's CVV after a suspected compromise (requires password confirmation). Reveal the new CVV through the card details flow.
+// @Tags cards
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Card ID"
+// @Param request body card.RotateCVVRequest true "Password"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/cards/{id}/rotate-cvv [post]
+func (h *CardHandler) RotateCVV(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	cardIDStr := c.Param("id")
+	cardID, err := uuid.Parse(cardIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid card ID"})
+		return
+	}
+
+	var req card.RotateCVVRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.cardService.RotateCVV(userID.(uuid.UUID), cardID, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "CVV rotated successfully"})
+}
+
+// SetPIN godoc
+// @Summary Set card PIN
+// @Description Set or change the card PIN (requires password confirmation)
+// @Tags cards
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Card ID"
+// @Param request body card.SetPINRequest true "New PIN and password"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/cards/{id}/pin [post]
+func (h *CardHandler) SetPIN(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	cardIDStr := c.Param("id")
+	cardID, err := uuid.Parse(cardIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid card ID"})
+		return
+	}
+
+	var req card.SetPINRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.cardService.SetPIN(userID.(uuid.UUID), cardID, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "PIN set successfully"})
+}
+
+// VerifyPIN godoc
+// @Summary Verify card PIN
+// @Description Check a PIN against the card. The PIN is locked after 3 consecutive wrong attempts.
+// @Tags cards
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Card ID"
+// @Param request body card.VerifyPINRequest true "PIN"
+// @Success 200 {object} card.VerifyPINResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 423 {object} card.VerifyPINResponse
+// @Router /api/v1/cards/{id}/pin/verify [post]
+func (h *CardHandler) VerifyPIN(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	cardIDStr := c.Param("id")
+	cardID, err := uuid.Parse(cardIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid card ID"})
+		return
+	}
+
+	var req card.VerifyPINRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.cardService.VerifyPIN(userID.(uuid.UUID), cardID, req.PIN)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if result.LockedUntil != nil {
+		c.JSON(http.StatusLocked, result)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -2,9 +2,11 @@ package handlers
 
 import (
 	"bytes"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/darisadam/madabank-server/internal/domain/card"
 	"github.com/gin-gonic/gin"
@@ -34,11 +36,27 @@ func (m *MockCardService) GetUserCards(userID uuid.UUID, accountID uuid.UUID) ([
 	return args.Get(0).([]*card.CardResponse), args.Error(1)
 }
 
-func (m *MockCardService) GetCardDetails(userID uuid.UUID, cardID uuid.UUID, password string) (*card.CardDetailsResponse, error) {
+func (m *MockCardService) RequestRevealChallenge(userID uuid.UUID, cardID uuid.UUID, password string) (*card.RevealChallengeResponse, error) {
 	args := m.Called(userID, cardID, password)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
+	return args.Get(0).(*card.RevealChallengeResponse), args.Error(1)
+}
+
+func (m *MockCardService) VerifyRevealChallenge(userID uuid.UUID, cardID uuid.UUID, req *card.RevealVerifyRequest) (*card.RevealTokenResponse, error) {
+	args := m.Called(userID, cardID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*card.RevealTokenResponse), args.Error(1)
+}
+
+func (m *MockCardService) GetCardDetails(userID uuid.UUID, cardID uuid.UUID, revealToken string) (*card.CardDetailsResponse, error) {
+	args := m.Called(userID, cardID, revealToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
 	return args.Get(0).(*card.CardDetailsResponse), args.Error(1)
 }
 
@@ -55,11 +73,60 @@ func (m *MockCardService) BlockCard(userID uuid.UUID, cardID uuid.UUID) error {
 	return args.Error(0)
 }
 
+func (m *MockCardService) FreezeCard(userID uuid.UUID, cardID uuid.UUID) error {
+	args := m.Called(userID, cardID)
+	return args.Error(0)
+}
+
+func (m *MockCardService) UnfreezeCard(userID uuid.UUID, cardID uuid.UUID) error {
+	args := m.Called(userID, cardID)
+	return args.Error(0)
+}
+
+func (m *MockCardService) ReissueCard(userID uuid.UUID, cardID uuid.UUID, req *card.ReissueCardRequest) (*card.CardResponse, error) {
+	args := m.Called(userID, cardID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*card.CardResponse), args.Error(1)
+}
+
 func (m *MockCardService) DeleteCard(userID uuid.UUID, cardID uuid.UUID) error {
 	args := m.Called(userID, cardID)
 	return args.Error(0)
 }
 
+func (m *MockCardService) UpdateControls(userID uuid.UUID, cardID uuid.UUID, req *card.UpdateControlsRequest) (*card.CardResponse, error) {
+	args := m.Called(userID, cardID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*card.CardResponse), args.Error(1)
+}
+
+func (m *MockCardService) RotateCVV(userID uuid.UUID, cardID uuid.UUID, req *card.RotateCVVRequest) error {
+	args := m.Called(userID, cardID, req)
+	return args.Error(0)
+}
+
+func (m *MockCardService) SetPIN(userID uuid.UUID, cardID uuid.UUID, req *card.SetPINRequest) error {
+	args := m.Called(userID, cardID, req)
+	return args.Error(0)
+}
+
+func (m *MockCardService) VerifyPIN(userID uuid.UUID, cardID uuid.UUID, pin string) (*card.VerifyPINResponse, error) {
+	args := m.Called(userID, cardID, pin)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*card.VerifyPINResponse), args.Error(1)
+}
+
+func (m *MockCardService) ExpireCards(now time.Time) (int, error) {
+	args := m.Called(now)
+	return args.Int(0), args.Error(1)
+}
+
 func setupCardRouter() *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	return gin.New()
@@ -189,9 +256,9 @@ func TestCardHandler_GetCardDetails_Success(t *testing.T) {
 		ExpiryYear:  2028,
 	}
 
-	mockService.On("GetCardDetails", userID, cardID, "password123").Return(detailsResp, nil)
+	mockService.On("GetCardDetails", userID, cardID, "reveal-token").Return(detailsResp, nil)
 
-	reqBody := `{"card_id":"` + cardID.String() + `","password":"password123"}`
+	reqBody := `{"card_id":"` + cardID.String() + `","reveal_token":"reveal-token"}`
 	req, _ := http.NewRequest("POST", "/cards/details", bytes.NewBufferString(reqBody))
 	req.Header.Set("Content-Type", "application/json")
 
@@ -202,7 +269,7 @@ func TestCardHandler_GetCardDetails_Success(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
-func TestCardHandler_GetCardDetails_WrongPassword(t *testing.T) {
+func TestCardHandler_GetCardDetails_InvalidRevealToken(t *testing.T) {
 	mockService := new(MockCardService)
 	handler := NewCardHandler(mockService)
 
@@ -215,16 +282,95 @@ func TestCardHandler_GetCardDetails_WrongPassword(t *testing.T) {
 		handler.GetCardDetails(c)
 	})
 
-	mockService.On("GetCardDetails", userID, cardID, "wrongpassword").Return(nil, assert.AnError)
+	mockService.On("GetCardDetails", userID, cardID, "used-token").Return(nil, assert.AnError)
+
+	reqBody := `{"card_id":"` + cardID.String() + `","reveal_token":"used-token"}`
+	req, _ := http.NewRequest("POST", "/cards/details", bytes.NewBufferString(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestCardHandler_GetCardDetails_PasswordNoLongerAccepted(t *testing.T) {
+	mockService := new(MockCardService)
+	handler := NewCardHandler(mockService)
+
+	router := setupCardRouter()
+	router.POST("/cards/details", func(c *gin.Context) {
+		c.Set("user_id", uuid.New())
+		handler.GetCardDetails(c)
+	})
 
-	reqBody := `{"card_id":"` + cardID.String() + `","password":"wrongpassword"}`
+	reqBody := `{"card_id":"` + uuid.New().String() + `","password":"password123"}`
 	req, _ := http.NewRequest("POST", "/cards/details", bytes.NewBufferString(reqBody))
 	req.Header.Set("Content-Type", "application/json")
 
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "GetCardDetails", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// ==================== Reveal Challenge Tests ====================
+
+func TestCardHandler_RequestRevealChallenge_Success(t *testing.T) {
+	mockService := new(MockCardService)
+	handler := NewCardHandler(mockService)
+
+	router := setupCardRouter()
+	userID := uuid.New()
+	cardID := uuid.New()
+
+	router.POST("/cards/:id/reveal/challenge", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		handler.RequestRevealChallenge(c)
+	})
+
+	mockService.On("RequestRevealChallenge", userID, cardID, "password123").
+		Return(&card.RevealChallengeResponse{ChallengeID: "challenge-1"}, nil)
+
+	req, _ := http.NewRequest("POST", "/cards/"+cardID.String()+"/reveal/challenge", bytes.NewBufferString(`{"password":"password123"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "challenge-1")
+	mockService.AssertExpectations(t)
+}
+
+func TestCardHandler_VerifyRevealChallenge_WrongOTP(t *testing.T) {
+	mockService := new(MockCardService)
+	handler := NewCardHandler(mockService)
+
+	router := setupCardRouter()
+	userID := uuid.New()
+	cardID := uuid.New()
+	challengeID := uuid.New().String()
+
+	router.POST("/cards/:id/reveal/verify", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		handler.VerifyRevealChallenge(c)
+	})
+
+	mockService.On("VerifyRevealChallenge", userID, cardID, &card.RevealVerifyRequest{ChallengeID: challengeID, OTP: "123456"}).
+		Return(nil, fmt.Errorf("invalid OTP code"))
+
+	reqBody := `{"challenge_id":"` + challengeID + `","otp":"123456"}`
+	req, _ := http.NewRequest("POST", "/cards/"+cardID.String()+"/reveal/verify", bytes.NewBufferString(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid OTP code")
 	mockService.AssertExpectations(t)
 }
 
@@ -286,6 +432,107 @@ func TestCardHandler_BlockCard_Success(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+// ==================== Freeze Tests ====================
+
+func TestCardHandler_FreezeCard_Success(t *testing.T) {
+	mockService := new(MockCardService)
+	handler := NewCardHandler(mockService)
+
+	router := setupCardRouter()
+	userID := uuid.New()
+	cardID := uuid.New()
+
+	router.POST("/cards/:id/freeze", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		handler.FreezeCard(c)
+	})
+
+	mockService.On("FreezeCard", userID, cardID).Return(nil)
+
+	req, _ := http.NewRequest("POST", "/cards/"+cardID.String()+"/freeze", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestCardHandler_UnfreezeCard_NotFrozen(t *testing.T) {
+	mockService := new(MockCardService)
+	handler := NewCardHandler(mockService)
+
+	router := setupCardRouter()
+	userID := uuid.New()
+	cardID := uuid.New()
+
+	router.POST("/cards/:id/unfreeze", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		handler.UnfreezeCard(c)
+	})
+
+	mockService.On("UnfreezeCard", userID, cardID).Return(fmt.Errorf("card is blocked, expected frozen"))
+
+	req, _ := http.NewRequest("POST", "/cards/"+cardID.String()+"/unfreeze", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+// ==================== ReissueCard Tests ====================
+
+func TestCardHandler_ReissueCard_Success(t *testing.T) {
+	mockService := new(MockCardService)
+	handler := NewCardHandler(mockService)
+
+	router := setupCardRouter()
+	userID := uuid.New()
+	cardID := uuid.New()
+	newCardID := uuid.New()
+
+	router.POST("/cards/:id/reissue", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		handler.ReissueCard(c)
+	})
+
+	mockService.On("ReissueCard", userID, cardID, &card.ReissueCardRequest{Reason: "lost"}).
+		Return(&card.CardResponse{ID: newCardID, ReplacesCardID: &cardID}, nil)
+
+	body := []byte(`{"reason":"lost"}`)
+	req, _ := http.NewRequest("POST", "/cards/"+cardID.String()+"/reissue", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), cardID.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestCardHandler_ReissueCard_InvalidReason(t *testing.T) {
+	mockService := new(MockCardService)
+	handler := NewCardHandler(mockService)
+
+	router := setupCardRouter()
+	userID := uuid.New()
+	cardID := uuid.New()
+
+	router.POST("/cards/:id/reissue", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		handler.ReissueCard(c)
+	})
+
+	body := []byte(`{"reason":"bored"}`)
+	req, _ := http.NewRequest("POST", "/cards/"+cardID.String()+"/reissue", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "ReissueCard", mock.Anything, mock.Anything, mock.Anything)
+}
+
 // ==================== DeleteCard Tests ====================
 
 func TestCardHandler_DeleteCard_Success(t *testing.T) {
@@ -326,3 +573,205 @@ func TestCardHandler_DeleteCard_Unauthorized(t *testing.T) {
 
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
 }
+
+// ==================== PIN Tests ====================
+
+func TestCardHandler_SetPIN_Success(t *testing.T) {
+	mockService := new(MockCardService)
+	handler := NewCardHandler(mockService)
+
+	router := setupCardRouter()
+	userID := uuid.New()
+	cardID := uuid.New()
+
+	router.POST("/cards/:id/pin", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		handler.SetPIN(c)
+	})
+
+	mockService.On("SetPIN", userID, cardID, &card.SetPINRequest{PIN: "4826", Password: "password123"}).Return(nil)
+
+	body := []byte(`{"pin":"4826","password":"password123"}`)
+	req, _ := http.NewRequest("POST", "/cards/"+cardID.String()+"/pin", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestCardHandler_SetPIN_InvalidPIN(t *testing.T) {
+	mockService := new(MockCardService)
+	handler := NewCardHandler(mockService)
+
+	router := setupCardRouter()
+	userID := uuid.New()
+	cardID := uuid.New()
+
+	router.POST("/cards/:id/pin", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		handler.SetPIN(c)
+	})
+
+	body := []byte(`{"pin":"12ab","password":"password123"}`)
+	req, _ := http.NewRequest("POST", "/cards/"+cardID.String()+"/pin", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "SetPIN", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCardHandler_VerifyPIN_Valid(t *testing.T) {
+	mockService := new(MockCardService)
+	handler := NewCardHandler(mockService)
+
+	router := setupCardRouter()
+	userID := uuid.New()
+	cardID := uuid.New()
+
+	router.POST("/cards/:id/pin/verify", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		handler.VerifyPIN(c)
+	})
+
+	mockService.On("VerifyPIN", userID, cardID, "4826").Return(&card.VerifyPINResponse{Valid: true, RemainingAttempts: 3}, nil)
+
+	body := []byte(`{"pin":"4826"}`)
+	req, _ := http.NewRequest("POST", "/cards/"+cardID.String()+"/pin/verify", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"valid":true`)
+	mockService.AssertExpectations(t)
+}
+
+func TestCardHandler_VerifyPIN_Locked(t *testing.T) {
+	mockService := new(MockCardService)
+	handler := NewCardHandler(mockService)
+
+	router := setupCardRouter()
+	userID := uuid.New()
+	cardID := uuid.New()
+	lockedUntil := time.Now().Add(time.Hour)
+
+	router.POST("/cards/:id/pin/verify", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		handler.VerifyPIN(c)
+	})
+
+	mockService.On("VerifyPIN", userID, cardID, "0000").Return(&card.VerifyPINResponse{Valid: false, LockedUntil: &lockedUntil}, nil)
+
+	body := []byte(`{"pin":"0000"}`)
+	req, _ := http.NewRequest("POST", "/cards/"+cardID.String()+"/pin/verify", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusLocked, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+// ==================== Controls Tests ====================
+
+func TestCardHandler_UpdateControls_Success(t *testing.T) {
+	mockService := new(MockCardService)
+	handler := NewCardHandler(mockService)
+
+	router := setupCardRouter()
+	userID := uuid.New()
+	cardID := uuid.New()
+
+	router.PATCH("/cards/:id/controls", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		handler.UpdateControls(c)
+	})
+
+	controls := card.DefaultControls()
+	controls.EcommerceEnabled = false
+	mockService.On("UpdateControls", userID, cardID, mock.AnythingOfType("*card.UpdateControlsRequest")).
+		Return(&card.CardResponse{ID: cardID, Controls: controls}, nil)
+
+	body := []byte(`{"ecommerce_enabled":false,"blocked_mccs":["7995"]}`)
+	req, _ := http.NewRequest("PATCH", "/cards/"+cardID.String()+"/controls", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"ecommerce_enabled":false`)
+	mockService.AssertExpectations(t)
+}
+
+func TestCardHandler_UpdateControls_InvalidMCC(t *testing.T) {
+	mockService := new(MockCardService)
+	handler := NewCardHandler(mockService)
+
+	router := setupCardRouter()
+	userID := uuid.New()
+	cardID := uuid.New()
+
+	router.PATCH("/cards/:id/controls", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		handler.UpdateControls(c)
+	})
+
+	body := []byte(`{"blocked_mccs":["gambling"]}`)
+	req, _ := http.NewRequest("PATCH", "/cards/"+cardID.String()+"/controls", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "UpdateControls", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// ==================== RotateCVV Tests ====================
+
+func TestCardHandler_RotateCVV_Success(t *testing.T) {
+	mockService := new(MockCardService)
+	handler := NewCardHandler(mockService)
+
+	router := setupCardRouter()
+	userID := uuid.New()
+	cardID := uuid.New()
+
+	router.POST("/cards/:id/rotate-cvv", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		handler.RotateCVV(c)
+	})
+
+	mockService.On("RotateCVV", userID, cardID, &card.RotateCVVRequest{Password: "password123"}).Return(nil)
+
+	req, _ := http.NewRequest("POST", "/cards/"+cardID.String()+"/rotate-cvv", bytes.NewBufferString(`{"password":"password123"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "cvv\"")
+	mockService.AssertExpectations(t)
+}
+
+func TestCardHandler_RotateCVV_MissingPassword(t *testing.T) {
+	mockService := new(MockCardService)
+	handler := NewCardHandler(mockService)
+
+	router := setupCardRouter()
+	router.POST("/cards/:id/rotate-cvv", func(c *gin.Context) {
+		c.Set("user_id", uuid.New())
+		handler.RotateCVV(c)
+	})
+
+	req, _ := http.NewRequest("POST", "/cards/"+uuid.New().String()+"/rotate-cvv", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "RotateCVV", mock.Anything, mock.Anything, mock.Anything)
+}
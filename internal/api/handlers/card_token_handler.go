@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/darisadam/madabank-server/internal/domain/card"
+	"github.com/darisadam/madabank-server/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CardTokenHandler struct {
+	tokenService service.CardTokenService
+}
+
+func NewCardTokenHandler(tokenService service.CardTokenService) *CardTokenHandler {
+	return &CardTokenHandler{
+		tokenService: tokenService,
+	}
+}
+
+// ProvisionToken godoc
+// @Summary Add card to wallet
+// @Description Issue a device-bound wallet token for the card. The token number is returned once and replaces the card number on the device.
+// @Tags cards
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Card ID"
+// @Param request body card.ProvisionTokenRequest true "Wallet and device"
+// @Success 201 {object} card.ProvisionTokenResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/cards/{id}/tokens [post]
+func (h *CardTokenHandler) ProvisionToken(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	cardIDStr := c.Param("id")
+	cardID, err := uuid.Parse(cardIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid card ID"})
+		return
+	}
+
+	var req card.ProvisionTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.tokenService.ProvisionToken(userID.(uuid.UUID), cardID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// ListTokens godoc
+// @Summary List wallet tokens
+// @Description List the card's active and suspended wallet tokens
+// @Tags cards
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Card ID"
+// @Success 200 {array} card.Token
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/cards/{id}/tokens [get]
+func (h *CardTokenHandler) ListTokens(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	cardIDStr := c.Param("id")
+	cardID, err := uuid.Parse(cardIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid card ID"})
+		return
+	}
+
+	tokens, err := h.tokenService.ListTokens(userID.(uuid.UUID), cardID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tokens": tokens})
+}
+
+// SuspendToken godoc
+// @Summary Suspend wallet token
+// @Description Temporarily stop payments with a wallet token, e.g. when the device is misplaced
+// @Tags cards
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Card ID"
+// @Param tokenId path string true "Token ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/cards/{id}/tokens/{tokenId}/suspend [post]
+func (h *CardTokenHandler) SuspendToken(c *gin.Context) {
+	h.changeTokenStatus(c, h.tokenService.SuspendToken, "Token suspended successfully")
+}
+
+// ResumeToken godoc
+// @Summary Resume wallet token
+// @Description Re-enable a suspended wallet token
+// @Tags cards
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Card ID"
+// @Param tokenId path string true "Token ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/cards/{id}/tokens/{tokenId}/resume [post]
+func (h *CardTokenHandler) ResumeToken(c *gin.Context) {
+	h.changeTokenStatus(c, h.tokenService.ResumeToken, "Token resumed successfully")
+}
+
+// DeleteToken godoc
+// @Summary Remove card from wallet
+// @Description Permanently delete a wallet token
+// @Tags cards
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Card ID"
+// @Param tokenId path string true "Token ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/cards/{id}/tokens/{tokenId} [delete]
+func (h *CardTokenHandler) DeleteToken(c *gin.Context) {
+	h.changeTokenStatus(c, h.tokenService.DeleteToken, "Token deleted successfully")
+}
+
+func (h *CardTokenHandler) changeTokenStatus(c *gin.Context, change func(userID, cardID, tokenID uuid.UUID) error, message string) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	cardID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid card ID"})
+		return
+	}
+
+	tokenID, err := uuid.Parse(c.Param("tokenId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid token ID"})
+		return
+	}
+
+	if err := change(userID.(uuid.UUID), cardID, tokenID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": message})
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/darisadam/madabank-server/internal/domain/card"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockCardTokenService is a mock implementation of service.CardTokenService
+type MockCardTokenService struct {
+	mock.Mock
+}
+
+func (m *MockCardTokenService) ProvisionToken(userID uuid.UUID, cardID uuid.UUID, req *card.ProvisionTokenRequest) (*card.ProvisionTokenResponse, error) {
+	args := m.Called(userID, cardID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*card.ProvisionTokenResponse), args.Error(1)
+}
+
+func (m *MockCardTokenService) ListTokens(userID uuid.UUID, cardID uuid.UUID) ([]*card.Token, error) {
+	args := m.Called(userID, cardID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*card.Token), args.Error(1)
+}
+
+func (m *MockCardTokenService) SuspendToken(userID uuid.UUID, cardID uuid.UUID, tokenID uuid.UUID) error {
+	args := m.Called(userID, cardID, tokenID)
+	return args.Error(0)
+}
+
+func (m *MockCardTokenService) ResumeToken(userID uuid.UUID, cardID uuid.UUID, tokenID uuid.UUID) error {
+	args := m.Called(userID, cardID, tokenID)
+	return args.Error(0)
+}
+
+func (m *MockCardTokenService) DeleteToken(userID uuid.UUID, cardID uuid.UUID, tokenID uuid.UUID) error {
+	args := m.Called(userID, cardID, tokenID)
+	return args.Error(0)
+}
+
+func TestCardTokenHandler_ProvisionToken_Success(t *testing.T) {
+	mockService := new(MockCardTokenService)
+	handler := NewCardTokenHandler(mockService)
+
+	router := setupCardRouter()
+	userID := uuid.New()
+	cardID := uuid.New()
+
+	router.POST("/cards/:id/tokens", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		handler.ProvisionToken(c)
+	})
+
+	mockService.On("ProvisionToken", userID, cardID, &card.ProvisionTokenRequest{
+		Wallet:   card.WalletGooglePay,
+		DeviceID: "pixel-8-abc",
+	}).Return(&card.ProvisionTokenResponse{
+		Token:       &card.Token{ID: uuid.New(), CardID: cardID, LastFour: "7897", Wallet: card.WalletGooglePay},
+		TokenNumber: "4895371234567897",
+	}, nil)
+
+	body := []byte(`{"wallet":"google_pay","device_id":"pixel-8-abc"}`)
+	req, _ := http.NewRequest("POST", "/cards/"+cardID.String()+"/tokens", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), "4895371234567897")
+	mockService.AssertExpectations(t)
+}
+
+func TestCardTokenHandler_ProvisionToken_UnknownWallet(t *testing.T) {
+	mockService := new(MockCardTokenService)
+	handler := NewCardTokenHandler(mockService)
+
+	router := setupCardRouter()
+	router.POST("/cards/:id/tokens", func(c *gin.Context) {
+		c.Set("user_id", uuid.New())
+		handler.ProvisionToken(c)
+	})
+
+	body := []byte(`{"wallet":"paypal","device_id":"pixel-8-abc"}`)
+	req, _ := http.NewRequest("POST", "/cards/"+uuid.New().String()+"/tokens", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "ProvisionToken", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCardTokenHandler_ListTokens(t *testing.T) {
+	mockService := new(MockCardTokenService)
+	handler := NewCardTokenHandler(mockService)
+
+	router := setupCardRouter()
+	userID := uuid.New()
+	cardID := uuid.New()
+
+	router.GET("/cards/:id/tokens", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		handler.ListTokens(c)
+	})
+
+	mockService.On("ListTokens", userID, cardID).Return([]*card.Token{{ID: uuid.New(), LastFour: "7897"}}, nil)
+
+	req, _ := http.NewRequest("GET", "/cards/"+cardID.String()+"/tokens", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"tokens"`)
+	assert.Contains(t, w.Body.String(), "7897")
+}
+
+func TestCardTokenHandler_SuspendToken(t *testing.T) {
+	mockService := new(MockCardTokenService)
+	handler := NewCardTokenHandler(mockService)
+
+	router := setupCardRouter()
+	userID := uuid.New()
+	cardID := uuid.New()
+	tokenID := uuid.New()
+
+	router.POST("/cards/:id/tokens/:tokenId/suspend", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		handler.SuspendToken(c)
+	})
+
+	mockService.On("SuspendToken", userID, cardID, tokenID).Return(nil)
+
+	req, _ := http.NewRequest("POST", fmt.Sprintf("/cards/%s/tokens/%s/suspend", cardID, tokenID), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestCardTokenHandler_DeleteToken_InvalidTokenID(t *testing.T) {
+	mockService := new(MockCardTokenService)
+	handler := NewCardTokenHandler(mockService)
+
+	router := setupCardRouter()
+	router.DELETE("/cards/:id/tokens/:tokenId", func(c *gin.Context) {
+		c.Set("user_id", uuid.New())
+		handler.DeleteToken(c)
+	})
+
+	req, _ := http.NewRequest("DELETE", "/cards/"+uuid.New().String()+"/tokens/not-a-uuid", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid token ID")
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/darisadam/madabank-server/internal/domain/card"
+	"github.com/darisadam/madabank-server/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CreditCardHandler struct {
+	creditCardService service.CreditCardService
+}
+
+func NewCreditCardHandler(creditCardService service.CreditCardService) *CreditCardHandler {
+	return &CreditCardHandler{
+		creditCardService: creditCardService,
+	}
+}
+
+// Repay godoc
+// @Summary Repay credit card
+// @Description Pay down a credit card balance from one of the user's accounts
+// @Tags cards
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Card ID"
+// @Param request body card.RepayCardRequest true "Repayment details"
+// @Success 201 {object} transaction.Transaction
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/cards/{id}/repayments [post]
+func (h *CreditCardHandler) Repay(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	cardIDStr := c.Param("id")
+	cardID, err := uuid.Parse(cardIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid card ID"})
+		return
+	}
+
+	var req card.RepayCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	txn, err := h.creditCardService.Repay(userID.(uuid.UUID), cardID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, txn)
+}
+
+// GetStatements godoc
+// @Summary List credit card statements
+// @Description Get the closed billing cycles of a credit card, newest first
+// @Tags cards
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Card ID"
+// @Success 200 {array} card.Statement
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/cards/{id}/statements [get]
+func (h *CreditCardHandler) GetStatements(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	cardIDStr := c.Param("id")
+	cardID, err := uuid.Parse(cardIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid card ID"})
+		return
+	}
+
+	statements, err := h.creditCardService.GetStatements(userID.(uuid.UUID), cardID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, statements)
+}
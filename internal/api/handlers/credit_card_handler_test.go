@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/card"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockCreditCardService is a mock implementation of service.CreditCardService
+type MockCreditCardService struct {
+	mock.Mock
+}
+
+func (m *MockCreditCardService) Repay(userID uuid.UUID, cardID uuid.UUID, req *card.RepayCardRequest) (*transaction.Transaction, error) {
+	args := m.Called(userID, cardID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*transaction.Transaction), args.Error(1)
+}
+
+func (m *MockCreditCardService) GetStatements(userID uuid.UUID, cardID uuid.UUID) ([]*card.Statement, error) {
+	args := m.Called(userID, cardID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*card.Statement), args.Error(1)
+}
+
+func (m *MockCreditCardService) CloseDueStatements(now time.Time) (int, error) {
+	args := m.Called(now)
+	return args.Int(0), args.Error(1)
+}
+
+func TestCreditCardHandler_Repay_Success(t *testing.T) {
+	mockService := new(MockCreditCardService)
+	handler := NewCreditCardHandler(mockService)
+
+	router := setupCardRouter()
+	userID := uuid.New()
+	cardID := uuid.New()
+	fromAccountID := uuid.New()
+
+	router.POST("/cards/:id/repayments", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		handler.Repay(c)
+	})
+
+	mockService.On("Repay", userID, cardID, &card.RepayCardRequest{
+		FromAccountID:  fromAccountID.String(),
+		Amount:         250000,
+		IdempotencyKey: "repay-1",
+	}).Return(&transaction.Transaction{ID: uuid.New(), Amount: 250000, TransactionType: transaction.TransactionTypeCardRepayment}, nil)
+
+	body := []byte(fmt.Sprintf(`{"from_account_id":"%s","amount":250000,"idempotency_key":"repay-1"}`, fromAccountID))
+	req, _ := http.NewRequest("POST", "/cards/"+cardID.String()+"/repayments", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), "card_repayment")
+	mockService.AssertExpectations(t)
+}
+
+func TestCreditCardHandler_Repay_Unauthorized(t *testing.T) {
+	mockService := new(MockCreditCardService)
+	handler := NewCreditCardHandler(mockService)
+
+	router := setupCardRouter()
+	router.POST("/cards/:id/repayments", handler.Repay) // No user_id
+
+	req, _ := http.NewRequest("POST", "/cards/"+uuid.New().String()+"/repayments", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestCreditCardHandler_GetStatements_NotCreditCard(t *testing.T) {
+	mockService := new(MockCreditCardService)
+	handler := NewCreditCardHandler(mockService)
+
+	router := setupCardRouter()
+	userID := uuid.New()
+	cardID := uuid.New()
+
+	router.GET("/cards/:id/statements", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		handler.GetStatements(c)
+	})
+
+	mockService.On("GetStatements", userID, cardID).Return(nil, fmt.Errorf("card is not a credit card"))
+
+	req, _ := http.NewRequest("GET", "/cards/"+cardID.String()+"/statements", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/darisadam/madabank-server/internal/domain/validation"
+	"github.com/gin-gonic/gin"
+)
+
+// errorBody is the JSON body of an error response. Validation failures also
+// list every invalid field, with a code clients can match on.
+func errorBody(err error) gin.H {
+	body := gin.H{"error": err.Error()}
+	var invalid validation.Errors
+	if errors.As(err, &invalid) {
+		body["fields"] = invalid
+	}
+	return body
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/darisadam/madabank-server/internal/domain/export"
+	"github.com/darisadam/madabank-server/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ExportHandler struct {
+	exportService service.ExportService
+}
+
+func NewExportHandler(exportService service.ExportService) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportService,
+	}
+}
+
+// RequestExport godoc
+// @Summary Request a bulk export
+// @Description Export every account's statement, or every transaction, for a period of days (up to 366) to object storage in the background. The requesting admin is emailed when it completes or fails.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body export.CreateExportRequest true "Export kind and period"
+// @Success 202 {object} export.ExportResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/admin/exports [post]
+func (h *ExportHandler) RequestExport(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req export.CreateExportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	e, err := h.exportService.RequestExport(userID.(uuid.UUID), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, export.ToResponse(e))
+}
+
+// ListExports godoc
+// @Summary List bulk exports
+// @Description Get the most recent exports with their progress
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} export.ExportResponse
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/admin/exports [get]
+func (h *ExportHandler) ListExports(c *gin.Context) {
+	exports, err := h.exportService.ListExports()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	responses := make([]export.ExportResponse, 0, len(exports))
+	for _, e := range exports {
+		responses = append(responses, export.ToResponse(e))
+	}
+	c.JSON(http.StatusOK, responses)
+}
+
+// GetExport godoc
+// @Summary Get a bulk export
+// @Description Get an export's status, progress and, once completed, where it was stored
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Export ID"
+// @Success 200 {object} export.ExportResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/admin/exports/{id} [get]
+func (h *ExportHandler) GetExport(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid export ID"})
+		return
+	}
+
+	e, err := h.exportService.GetExport(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, export.ToResponse(e))
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/darisadam/madabank-server/internal/domain/export"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockExportService is a mock implementation of service.ExportService
+type MockExportService struct {
+	mock.Mock
+}
+
+func (m *MockExportService) RequestExport(userID uuid.UUID, req *export.CreateExportRequest) (*export.Export, error) {
+	args := m.Called(userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*export.Export), args.Error(1)
+}
+
+func (m *MockExportService) ListExports() ([]*export.Export, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*export.Export), args.Error(1)
+}
+
+func (m *MockExportService) GetExport(id uuid.UUID) (*export.Export, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*export.Export), args.Error(1)
+}
+
+func (m *MockExportService) RunExport(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func setupExportRouter(handler *ExportHandler, userID uuid.UUID) *gin.Engine {
+	router := setupCardRouter()
+	exports := router.Group("/admin/exports", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Next()
+	})
+	exports.POST("", handler.RequestExport)
+	exports.GET("", handler.ListExports)
+	exports.GET("/:id", handler.GetExport)
+	return router
+}
+
+func TestExportHandler_RequestExport(t *testing.T) {
+	mockService := new(MockExportService)
+	adminID := uuid.New()
+	router := setupExportRouter(NewExportHandler(mockService), adminID)
+	mockService.On("RequestExport", adminID, &export.CreateExportRequest{Kind: export.KindStatements, From: "2024-01-01", To: "2024-01-31"}).
+		Return(&export.Export{ID: uuid.New(), Kind: export.KindStatements, Status: export.StatusPending}, nil)
+
+	body := `{"kind":"statements","from":"2024-01-01","to":"2024-01-31"}`
+	req, _ := http.NewRequest("POST", "/admin/exports", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"pending"`)
+	assert.Contains(t, w.Body.String(), `"progress":0`)
+}
+
+func TestExportHandler_RequestExport_UnknownKind(t *testing.T) {
+	mockService := new(MockExportService)
+	router := setupExportRouter(NewExportHandler(mockService), uuid.New())
+
+	body := `{"kind":"users","from":"2024-01-01","to":"2024-01-31"}`
+	req, _ := http.NewRequest("POST", "/admin/exports", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "RequestExport", mock.Anything, mock.Anything)
+}
+
+func TestExportHandler_GetExport_ShowsProgress(t *testing.T) {
+	mockService := new(MockExportService)
+	router := setupExportRouter(NewExportHandler(mockService), uuid.New())
+	id := uuid.New()
+	mockService.On("GetExport", id).Return(&export.Export{ID: id, Status: export.StatusRunning, Total: 200, Processed: 50}, nil)
+
+	req, _ := http.NewRequest("GET", "/admin/exports/"+id.String(), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"progress":25`)
+}
+
+func TestExportHandler_GetExport_NotFound(t *testing.T) {
+	mockService := new(MockExportService)
+	router := setupExportRouter(NewExportHandler(mockService), uuid.New())
+	id := uuid.New()
+	mockService.On("GetExport", id).Return(nil, fmt.Errorf("export not found"))
+
+	req, _ := http.NewRequest("GET", "/admin/exports/"+id.String(), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/darisadam/madabank-server/internal/domain/fx"
+	"github.com/darisadam/madabank-server/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type FXHandler struct {
+	fxService service.FXService
+}
+
+func NewFXHandler(fxService service.FXService) *FXHandler {
+	return &FXHandler{
+		fxService: fxService,
+	}
+}
+
+// GetRates godoc
+// @Summary Get exchange rates
+// @Description Indicative mid-market rates of every supported currency against the base currency, for showing a conversion before an FX transfer
+// @Tags fx
+// @Produce json
+// @Security BearerAuth
+// @Param base query string false "Base currency (default IDR)"
+// @Success 200 {object} fx.RatesResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /api/v1/fx/rates [get]
+func (h *FXHandler) GetRates(c *gin.Context) {
+	var req fx.RatesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rates, err := h.fxService.GetRates(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, fx.ErrRatesUnavailable) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, errorBody(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, rates)
+}
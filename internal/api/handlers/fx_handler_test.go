@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/fx"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockFXService is a mock implementation of service.FXService
+type MockFXService struct {
+	mock.Mock
+}
+
+func (m *MockFXService) GetRates(ctx context.Context, req *fx.RatesRequest) (*fx.RatesResponse, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*fx.RatesResponse), args.Error(1)
+}
+
+func setupFXRouter(handler *FXHandler) *gin.Engine {
+	router := setupCardRouter()
+	router.GET("/fx/rates", handler.GetRates)
+	return router
+}
+
+func TestFXHandler_GetRates(t *testing.T) {
+	mockService := new(MockFXService)
+	router := setupFXRouter(NewFXHandler(mockService))
+
+	mockService.On("GetRates", &fx.RatesRequest{Base: "USD"}).Return(&fx.RatesResponse{
+		Base:  "USD",
+		Rates: map[string]float64{"USD": 1, "IDR": 16300},
+		AsOf:  time.Now(),
+	}, nil)
+
+	req, _ := http.NewRequest("GET", "/fx/rates?base=USD", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"IDR":16300`)
+	assert.Contains(t, w.Body.String(), `"stale":false`)
+	mockService.AssertExpectations(t)
+}
+
+func TestFXHandler_GetRates_InvalidBase(t *testing.T) {
+	mockService := new(MockFXService)
+	router := setupFXRouter(NewFXHandler(mockService))
+
+	mockService.On("GetRates", mock.Anything).Return(nil, fmt.Errorf("base: must be a supported currency code such as IDR"))
+
+	req, _ := http.NewRequest("GET", "/fx/rates?base=XYZ", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestFXHandler_GetRates_Unavailable(t *testing.T) {
+	mockService := new(MockFXService)
+	router := setupFXRouter(NewFXHandler(mockService))
+
+	mockService.On("GetRates", mock.Anything).Return(nil, fx.ErrRatesUnavailable)
+
+	req, _ := http.NewRequest("GET", "/fx/rates", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
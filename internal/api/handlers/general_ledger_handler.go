@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+
+	"github.com/darisadam/madabank-server/internal/domain/gl"
+	"github.com/darisadam/madabank-server/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type GeneralLedgerHandler struct {
+	glService service.GeneralLedgerService
+}
+
+func NewGeneralLedgerHandler(glService service.GeneralLedgerService) *GeneralLedgerHandler {
+	return &GeneralLedgerHandler{
+		glService: glService,
+	}
+}
+
+// ExportJournal godoc
+// @Summary Export the general ledger journal
+// @Description Get a day's completed transactions as balanced journal entries posted to GL codes, for import into the finance ERP
+// @Tags admin
+// @Produce json
+// @Produce text/csv
+// @Security BearerAuth
+// @Param date query string true "Accounting date (YYYY-MM-DD)"
+// @Param format query string false "Output format (json, csv). Defaults to json."
+// @Success 200 {object} gl.Journal
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/admin/gl-export [get]
+func (h *GeneralLedgerHandler) ExportJournal(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req gl.ExportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	journal, err := h.glService.ExportJournal(userID.(uuid.UUID), req.Date)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Format != gl.FormatCSV {
+		c.JSON(http.StatusOK, journal)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := journal.WriteCSV(&buf); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to write journal"})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="gl-journal-%s.csv"`, journal.Date))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
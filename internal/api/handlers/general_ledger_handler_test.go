@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/gl"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockGeneralLedgerService is a mock implementation of service.GeneralLedgerService
+type MockGeneralLedgerService struct {
+	mock.Mock
+}
+
+func (m *MockGeneralLedgerService) ExportJournal(userID uuid.UUID, date string) (*gl.Journal, error) {
+	args := m.Called(userID, date)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*gl.Journal), args.Error(1)
+}
+
+func setupGeneralLedgerRouter(handler *GeneralLedgerHandler, userID uuid.UUID) *gin.Engine {
+	router := setupCardRouter()
+	admin := router.Group("/admin", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Next()
+	})
+	admin.GET("/gl-export", handler.ExportJournal)
+	return router
+}
+
+func testJournal() *gl.Journal {
+	to := uuid.New()
+	return gl.BuildJournal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), "IDR", []*transaction.Transaction{
+		{ID: uuid.New(), ToAccountID: &to, Amount: 500000, TransactionType: transaction.TransactionTypeDeposit},
+	}, time.Now())
+}
+
+func TestGeneralLedgerHandler_ExportJournal_JSON(t *testing.T) {
+	mockService := new(MockGeneralLedgerService)
+	adminID := uuid.New()
+	router := setupGeneralLedgerRouter(NewGeneralLedgerHandler(mockService), adminID)
+	mockService.On("ExportJournal", adminID, "2026-03-01").Return(testJournal(), nil)
+
+	req, _ := http.NewRequest("GET", "/admin/gl-export?date=2026-03-01", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"journal_id":"JE-20260301-000001"`)
+	assert.Contains(t, w.Body.String(), `"gl_code":"2100"`)
+}
+
+func TestGeneralLedgerHandler_ExportJournal_CSV(t *testing.T) {
+	mockService := new(MockGeneralLedgerService)
+	adminID := uuid.New()
+	router := setupGeneralLedgerRouter(NewGeneralLedgerHandler(mockService), adminID)
+	mockService.On("ExportJournal", adminID, "2026-03-01").Return(testJournal(), nil)
+
+	req, _ := http.NewRequest("GET", "/admin/gl-export?date=2026-03-01&format=csv", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="gl-journal-2026-03-01.csv"`, w.Header().Get("Content-Disposition"))
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	assert.Len(t, lines, 3)
+	assert.True(t, strings.HasPrefix(lines[0], "journal_id,posting_date"))
+}
+
+func TestGeneralLedgerHandler_ExportJournal_MissingDate(t *testing.T) {
+	mockService := new(MockGeneralLedgerService)
+	router := setupGeneralLedgerRouter(NewGeneralLedgerHandler(mockService), uuid.New())
+
+	req, _ := http.NewRequest("GET", "/admin/gl-export?format=xml", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "ExportJournal", mock.Anything, mock.Anything)
+}
+
+func TestGeneralLedgerHandler_ExportJournal_ServiceError(t *testing.T) {
+	mockService := new(MockGeneralLedgerService)
+	router := setupGeneralLedgerRouter(NewGeneralLedgerHandler(mockService), uuid.New())
+	mockService.On("ExportJournal", mock.Anything, "2099-01-01").Return(nil, fmt.Errorf("date cannot be in the future"))
+
+	req, _ := http.NewRequest("GET", "/admin/gl-export?date=2099-01-01", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "date cannot be in the future")
+}
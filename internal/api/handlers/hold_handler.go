@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type HoldHandler struct {
+	holdService service.HoldService
+}
+
+func NewHoldHandler(holdService service.HoldService) *HoldHandler {
+	return &HoldHandler{
+		holdService: holdService,
+	}
+}
+
+// PlaceHold godoc
+// @Summary Place a hold
+// @Description Reserve funds on an account for a payment that settles later. The hold lowers the available balance until it is captured, released or expires. Requires a service token with the holds.manage scope.
+// @Tags holds
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body account.PlaceHoldRequest true "Hold"
+// @Success 201 {object} account.Hold
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/holds [post]
+func (h *HoldHandler) PlaceHold(c *gin.Context) {
+	var req account.PlaceHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hold, err := h.holdService.PlaceHold(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, hold)
+}
+
+// GetHold godoc
+// @Summary Get a hold
+// @Tags holds
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Hold ID"
+// @Success 200 {object} account.Hold
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/holds/{id} [get]
+func (h *HoldHandler) GetHold(c *gin.Context) {
+	holdID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid hold ID"})
+		return
+	}
+
+	hold, err := h.holdService.GetHold(holdID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, hold)
+}
+
+// ExtendHold godoc
+// @Summary Extend a hold
+// @Description Move an active hold's expiry. A hold lasts at most 30 days from when it was placed.
+// @Tags holds
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Hold ID"
+// @Param request body account.ExtendHoldRequest true "New expiry"
+// @Success 200 {object} account.Hold
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/holds/{id}/extend [post]
+func (h *HoldHandler) ExtendHold(c *gin.Context) {
+	holdID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid hold ID"})
+		return
+	}
+
+	var req account.ExtendHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hold, err := h.holdService.ExtendHold(holdID, &req)
+	if err != nil {
+		respondHoldError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, hold)
+}
+
+// CaptureHold godoc
+// @Summary Capture a hold
+// @Description Debit the held funds, or part of them, and end the hold. The rest of a partial capture is released.
+// @Tags holds
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Hold ID"
+// @Param request body account.CaptureHoldRequest false "Amount and description"
+// @Success 200 {object} account.Hold
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/holds/{id}/capture [post]
+func (h *HoldHandler) CaptureHold(c *gin.Context) {
+	holdID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid hold ID"})
+		return
+	}
+
+	// The body is optional: without one the whole hold is captured
+	var req account.CaptureHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hold, err := h.holdService.CaptureHold(holdID, &req)
+	if err != nil {
+		respondHoldError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, hold)
+}
+
+// ReleaseHold godoc
+// @Summary Release a hold
+// @Description End an active hold without a debit, returning the funds to the available balance
+// @Tags holds
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Hold ID"
+// @Success 200 {object} account.Hold
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/holds/{id}/release [post]
+func (h *HoldHandler) ReleaseHold(c *gin.Context) {
+	holdID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid hold ID"})
+		return
+	}
+
+	hold, err := h.holdService.ReleaseHold(holdID)
+	if err != nil {
+		respondHoldError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, hold)
+}
+
+// ListHolds godoc
+// @Summary List holds on an account
+// @Description The active holds on one of the customer's accounts, oldest first
+// @Tags accounts
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Account ID"
+// @Success 200 {array} account.Hold
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/accounts/{id}/holds [get]
+func (h *HoldHandler) ListHolds(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	accountID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account ID"})
+		return
+	}
+
+	holds, err := h.holdService.ListHolds(accountID, userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, holds)
+}
+
+func respondHoldError(c *gin.Context, err error) {
+	if errors.Is(err, account.ErrHoldNotActive) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockHoldService is a mock implementation of service.HoldService
+type MockHoldService struct {
+	mock.Mock
+}
+
+func (m *MockHoldService) hold(args mock.Arguments) (*account.Hold, error) {
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*account.Hold), args.Error(1)
+}
+
+func (m *MockHoldService) PlaceHold(req *account.PlaceHoldRequest) (*account.Hold, error) {
+	return m.hold(m.Called(req))
+}
+
+func (m *MockHoldService) GetHold(id uuid.UUID) (*account.Hold, error) {
+	return m.hold(m.Called(id))
+}
+
+func (m *MockHoldService) ExtendHold(id uuid.UUID, req *account.ExtendHoldRequest) (*account.Hold, error) {
+	return m.hold(m.Called(id, req))
+}
+
+func (m *MockHoldService) CaptureHold(id uuid.UUID, req *account.CaptureHoldRequest) (*account.Hold, error) {
+	return m.hold(m.Called(id, req))
+}
+
+func (m *MockHoldService) ReleaseHold(id uuid.UUID) (*account.Hold, error) {
+	return m.hold(m.Called(id))
+}
+
+func (m *MockHoldService) ListHolds(accountID, userID uuid.UUID) ([]*account.Hold, error) {
+	args := m.Called(accountID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*account.Hold), args.Error(1)
+}
+
+func (m *MockHoldService) ExpireHolds(now time.Time) (int, error) {
+	args := m.Called(now)
+	return args.Int(0), args.Error(1)
+}
+
+func setupHoldRouter(handler *HoldHandler, userID uuid.UUID) *gin.Engine {
+	router := setupCardRouter()
+	holds := router.Group("/holds")
+	holds.POST("", handler.PlaceHold)
+	holds.GET("/:id", handler.GetHold)
+	holds.POST("/:id/extend", handler.ExtendHold)
+	holds.POST("/:id/capture", handler.CaptureHold)
+	holds.POST("/:id/release", handler.ReleaseHold)
+	router.GET("/accounts/:id/holds", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Next()
+	}, handler.ListHolds)
+	return router
+}
+
+func TestHoldHandler_PlaceHold(t *testing.T) {
+	mockService := new(MockHoldService)
+	router := setupHoldRouter(NewHoldHandler(mockService), uuid.New())
+	accountID := uuid.New()
+
+	mockService.On("PlaceHold", &account.PlaceHoldRequest{AccountID: accountID.String(), Amount: 250000, Purpose: "card_authorization"}).
+		Return(&account.Hold{ID: uuid.New(), AccountID: accountID, Amount: 250000, Status: account.HoldStatusActive}, nil)
+
+	body := []byte(fmt.Sprintf(`{"account_id":"%s","amount":250000,"purpose":"card_authorization"}`, accountID))
+	req, _ := http.NewRequest("POST", "/holds", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"active"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestHoldHandler_PlaceHold_InvalidPurpose(t *testing.T) {
+	mockService := new(MockHoldService)
+	router := setupHoldRouter(NewHoldHandler(mockService), uuid.New())
+
+	body := []byte(fmt.Sprintf(`{"account_id":"%s","amount":250000,"purpose":"groceries"}`, uuid.New()))
+	req, _ := http.NewRequest("POST", "/holds", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "PlaceHold", mock.Anything)
+}
+
+func TestHoldHandler_CaptureHold_WithoutBody(t *testing.T) {
+	mockService := new(MockHoldService)
+	router := setupHoldRouter(NewHoldHandler(mockService), uuid.New())
+	holdID := uuid.New()
+
+	mockService.On("CaptureHold", holdID, &account.CaptureHoldRequest{}).
+		Return(&account.Hold{ID: holdID, Status: account.HoldStatusCaptured}, nil)
+
+	req, _ := http.NewRequest("POST", fmt.Sprintf("/holds/%s/capture", holdID), bytes.NewBuffer(nil))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"captured"`)
+}
+
+func TestHoldHandler_ReleaseHold_NotActive(t *testing.T) {
+	mockService := new(MockHoldService)
+	router := setupHoldRouter(NewHoldHandler(mockService), uuid.New())
+	holdID := uuid.New()
+
+	mockService.On("ReleaseHold", holdID).Return(nil, account.ErrHoldNotActive)
+
+	req, _ := http.NewRequest("POST", fmt.Sprintf("/holds/%s/release", holdID), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.JSONEq(t, `{"error":"hold is no longer active"}`, w.Body.String())
+}
+
+func TestHoldHandler_ListHolds(t *testing.T) {
+	mockService := new(MockHoldService)
+	userID, accountID := uuid.New(), uuid.New()
+	router := setupHoldRouter(NewHoldHandler(mockService), userID)
+
+	mockService.On("ListHolds", accountID, userID).Return([]*account.Hold{{ID: uuid.New(), Amount: 50000}}, nil)
+
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/accounts/%s/holds", accountID), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"amount":50000`)
+}
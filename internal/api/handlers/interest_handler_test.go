@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/interest"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockInterestService is a mock implementation of service.InterestService
+type MockInterestService struct {
+	mock.Mock
+}
+
+func (m *MockInterestService) AccrueDaily(now time.Time) (int, error) {
+	args := m.Called(now)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockInterestService) PostDue(now time.Time) (int, error) {
+	args := m.Called(now)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockInterestService) GetSummary(userID uuid.UUID, accountID uuid.UUID, req *interest.SummaryRequest) (*interest.Summary, error) {
+	args := m.Called(userID, accountID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*interest.Summary), args.Error(1)
+}
+
+func setupInterestRouter(handler *InterestHandler, userID uuid.UUID) *gin.Engine {
+	router := setupCardRouter()
+	router.GET("/accounts/:id/interest", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		handler.GetSummary(c)
+	})
+	return router
+}
+
+func TestInterestHandler_GetSummary(t *testing.T) {
+	mockService := new(MockInterestService)
+	userID, accountID := uuid.New(), uuid.New()
+	router := setupInterestRouter(NewInterestHandler(mockService), userID)
+
+	mockService.On("GetSummary", userID, accountID, &interest.SummaryRequest{Year: 2025}).
+		Return(&interest.Summary{AccountID: accountID, Year: 2025, TotalPosted: 27602.74, TotalWithholdingTax: 5520.55}, nil)
+
+	req, _ := http.NewRequest("GET", "/accounts/"+accountID.String()+"/interest?year=2025", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"total_withholding_tax":5520.55`)
+	mockService.AssertExpectations(t)
+}
+
+func TestInterestHandler_GetSummary_InvalidYear(t *testing.T) {
+	mockService := new(MockInterestService)
+	router := setupInterestRouter(NewInterestHandler(mockService), uuid.New())
+
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/accounts/%s/interest?year=99", uuid.New()), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "GetSummary", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/darisadam/madabank-server/internal/domain/queue"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockJobService is a mock implementation of service.JobService
+type MockJobService struct {
+	mock.Mock
+}
+
+func (m *MockJobService) ListJobs(req *queue.ListJobsRequest) ([]*queue.Job, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*queue.Job), args.Error(1)
+}
+
+func (m *MockJobService) GetJob(id uuid.UUID) (*queue.Job, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*queue.Job), args.Error(1)
+}
+
+func (m *MockJobService) RetryJob(userID, id uuid.UUID) (*queue.Job, error) {
+	args := m.Called(userID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*queue.Job), args.Error(1)
+}
+
+func setupJobRouter(handler *JobHandler, userID uuid.UUID) *gin.Engine {
+	router := setupCardRouter()
+	jobs := router.Group("/admin/jobs", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Next()
+	})
+	jobs.GET("", handler.ListJobs)
+	jobs.GET("/:id", handler.GetJob)
+	jobs.POST("/:id/retry", handler.RetryJob)
+	return router
+}
+
+func TestJobHandler_ListJobs_DeadLetterQueue(t *testing.T) {
+	mockService := new(MockJobService)
+	router := setupJobRouter(NewJobHandler(mockService), uuid.New())
+	mockService.On("ListJobs", &queue.ListJobsRequest{Status: queue.StatusDead}).
+		Return([]*queue.Job{{ID: uuid.New(), Kind: "system_metrics", Status: queue.StatusDead}}, nil)
+
+	req, _ := http.NewRequest("GET", "/admin/jobs?status=dead", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"dead"`)
+}
+
+func TestJobHandler_ListJobs_InvalidStatus(t *testing.T) {
+	mockService := new(MockJobService)
+	router := setupJobRouter(NewJobHandler(mockService), uuid.New())
+
+	req, _ := http.NewRequest("GET", "/admin/jobs?status=stuck", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "ListJobs", mock.Anything)
+}
+
+func TestJobHandler_RetryJob(t *testing.T) {
+	mockService := new(MockJobService)
+	adminID, id := uuid.New(), uuid.New()
+	router := setupJobRouter(NewJobHandler(mockService), adminID)
+	mockService.On("RetryJob", adminID, id).Return(&queue.Job{ID: id, Status: queue.StatusPending}, nil)
+
+	req, _ := http.NewRequest("POST", "/admin/jobs/"+id.String()+"/retry", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"pending"`)
+}
+
+func TestJobHandler_RetryJob_NotDead(t *testing.T) {
+	mockService := new(MockJobService)
+	router := setupJobRouter(NewJobHandler(mockService), uuid.New())
+	mockService.On("RetryJob", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("dead job not found"))
+
+	req, _ := http.NewRequest("POST", "/admin/jobs/"+uuid.New().String()+"/retry", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,297 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/darisadam/madabank-server/internal/domain/loan"
+	"github.com/darisadam/madabank-server/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type LoanHandler struct {
+	loanService service.LoanService
+}
+
+func NewLoanHandler(loanService service.LoanService) *LoanHandler {
+	return &LoanHandler{
+		loanService: loanService,
+	}
+}
+
+// ListProducts godoc
+// @Summary List loan products
+// @Description Get the available loan products with their amount, tenor and rate ranges
+// @Tags loans
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} loan.Product
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/loans/products [get]
+func (h *LoanHandler) ListProducts(c *gin.Context) {
+	products, err := h.loanService.ListProducts()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, products)
+}
+
+// Quote godoc
+// @Summary Quote a loan
+// @Description Preview the installment, fees and amortization schedule of a loan without applying
+// @Tags loans
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body loan.QuoteRequest true "Loan terms"
+// @Success 200 {object} loan.Quote
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/loans/quote [post]
+func (h *LoanHandler) Quote(c *gin.Context) {
+	var req loan.QuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	quote, err := h.loanService.Quote(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, quote)
+}
+
+// Apply godoc
+// @Summary Apply for a loan
+// @Description Submit a loan application. Once approved, the loan is disbursed into the given checking account and installments are auto-debited from it.
+// @Tags loans
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body loan.ApplyLoanRequest true "Application details"
+// @Success 201 {object} loan.Loan
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/loans [post]
+func (h *LoanHandler) Apply(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req loan.ApplyLoanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	l, err := h.loanService.Apply(userID.(uuid.UUID), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, l)
+}
+
+// ListLoans godoc
+// @Summary List loans
+// @Description Get the user's loans and applications
+// @Tags loans
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} loan.Loan
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/loans [get]
+func (h *LoanHandler) ListLoans(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	loans, err := h.loanService.ListLoans(userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, loans)
+}
+
+// GetLoan godoc
+// @Summary Get a loan
+// @Description Get a loan with its amortization schedule
+// @Tags loans
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Loan ID"
+// @Success 200 {object} loan.Loan
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/loans/{id} [get]
+func (h *LoanHandler) GetLoan(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	loanID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid loan ID"})
+		return
+	}
+
+	l, err := h.loanService.GetLoan(userID.(uuid.UUID), loanID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, l)
+}
+
+// CancelApplication godoc
+// @Summary Cancel a loan application
+// @Description Withdraw a loan application that is still waiting for a decision
+// @Tags loans
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Loan ID"
+// @Success 200 {object} loan.Loan
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/loans/{id}/cancel [post]
+func (h *LoanHandler) CancelApplication(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	loanID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid loan ID"})
+		return
+	}
+
+	l, err := h.loanService.CancelApplication(userID.(uuid.UUID), loanID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, l)
+}
+
+// ListApplications godoc
+// @Summary List loan applications
+// @Description Get loans by status for review. Defaults to pending applications.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Loan status"
+// @Success 200 {array} loan.Loan
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/admin/loans [get]
+func (h *LoanHandler) ListApplications(c *gin.Context) {
+	var req loan.ListLoansRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	loans, err := h.loanService.ListApplications(loan.Status(req.Status))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, loans)
+}
+
+// Approve godoc
+// @Summary Approve a loan application
+// @Description Approve a pending application and disburse the loan, net of the provision fee, into the applicant's account
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Loan ID"
+// @Success 200 {object} loan.Loan
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/admin/loans/{id}/approve [post]
+func (h *LoanHandler) Approve(c *gin.Context) {
+	officerID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	loanID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid loan ID"})
+		return
+	}
+
+	l, err := h.loanService.Approve(officerID.(uuid.UUID), loanID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, l)
+}
+
+// Reject godoc
+// @Summary Reject a loan application
+// @Description Decline a pending application with a reason shown to the applicant
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Loan ID"
+// @Param request body loan.RejectLoanRequest true "Rejection reason"
+// @Success 200 {object} loan.Loan
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/admin/loans/{id}/reject [post]
+func (h *LoanHandler) Reject(c *gin.Context) {
+	officerID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	loanID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid loan ID"})
+		return
+	}
+
+	var req loan.RejectLoanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	l, err := h.loanService.Reject(officerID.(uuid.UUID), loanID, req.Reason)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, l)
+}
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/loan"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockLoanService is a mock implementation of service.LoanService
+type MockLoanService struct {
+	mock.Mock
+}
+
+func (m *MockLoanService) ListProducts() ([]*loan.Product, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*loan.Product), args.Error(1)
+}
+
+func (m *MockLoanService) Quote(req *loan.QuoteRequest) (*loan.Quote, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*loan.Quote), args.Error(1)
+}
+
+func (m *MockLoanService) Apply(userID uuid.UUID, req *loan.ApplyLoanRequest) (*loan.Loan, error) {
+	args := m.Called(userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*loan.Loan), args.Error(1)
+}
+
+func (m *MockLoanService) ListLoans(userID uuid.UUID) ([]*loan.Loan, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*loan.Loan), args.Error(1)
+}
+
+func (m *MockLoanService) GetLoan(userID uuid.UUID, loanID uuid.UUID) (*loan.Loan, error) {
+	args := m.Called(userID, loanID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*loan.Loan), args.Error(1)
+}
+
+func (m *MockLoanService) CancelApplication(userID uuid.UUID, loanID uuid.UUID) (*loan.Loan, error) {
+	args := m.Called(userID, loanID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*loan.Loan), args.Error(1)
+}
+
+func (m *MockLoanService) ListApplications(status loan.Status) ([]*loan.Loan, error) {
+	args := m.Called(status)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*loan.Loan), args.Error(1)
+}
+
+func (m *MockLoanService) Approve(officerID uuid.UUID, loanID uuid.UUID) (*loan.Loan, error) {
+	args := m.Called(officerID, loanID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*loan.Loan), args.Error(1)
+}
+
+func (m *MockLoanService) Reject(officerID uuid.UUID, loanID uuid.UUID, reason string) (*loan.Loan, error) {
+	args := m.Called(officerID, loanID, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*loan.Loan), args.Error(1)
+}
+
+func (m *MockLoanService) CollectDueInstallments(now time.Time) (int, error) {
+	args := m.Called(now)
+	return args.Int(0), args.Error(1)
+}
+
+func setupLoanRouter(handler *LoanHandler, userID uuid.UUID) *gin.Engine {
+	router := setupCardRouter()
+	loans := router.Group("/loans", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Next()
+	})
+	loans.GET("/products", handler.ListProducts)
+	loans.POST("/quote", handler.Quote)
+	loans.POST("", handler.Apply)
+	loans.GET("", handler.ListLoans)
+	loans.GET("/:id", handler.GetLoan)
+	loans.POST("/:id/cancel", handler.CancelApplication)
+
+	admin := router.Group("/admin/loans", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Next()
+	})
+	admin.GET("", handler.ListApplications)
+	admin.POST("/:id/approve", handler.Approve)
+	admin.POST("/:id/reject", handler.Reject)
+	return router
+}
+
+func TestLoanHandler_Apply(t *testing.T) {
+	mockService := new(MockLoanService)
+	userID := uuid.New()
+	accountID := uuid.New()
+	router := setupLoanRouter(NewLoanHandler(mockService), userID)
+
+	mockService.On("Apply", userID, &loan.ApplyLoanRequest{
+		ProductCode: "PERSONAL", AccountID: accountID.String(), Amount: 12000000, TenorMonths: 12,
+	}).Return(&loan.Loan{ID: uuid.New(), Status: loan.StatusPending}, nil)
+
+	body := []byte(fmt.Sprintf(`{"product_code":"PERSONAL","account_id":"%s","amount":12000000,"tenor_months":12}`, accountID))
+	req, _ := http.NewRequest("POST", "/loans", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"pending"`)
+}
+
+func TestLoanHandler_Apply_InvalidBody(t *testing.T) {
+	mockService := new(MockLoanService)
+	router := setupLoanRouter(NewLoanHandler(mockService), uuid.New())
+
+	req, _ := http.NewRequest("POST", "/loans", bytes.NewBufferString(`{"product_code":"PERSONAL","amount":12000000}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "Apply", mock.Anything, mock.Anything)
+}
+
+func TestLoanHandler_GetLoan_NotFound(t *testing.T) {
+	mockService := new(MockLoanService)
+	userID := uuid.New()
+	loanID := uuid.New()
+	router := setupLoanRouter(NewLoanHandler(mockService), userID)
+	mockService.On("GetLoan", userID, loanID).Return(nil, fmt.Errorf("loan not found"))
+
+	req, _ := http.NewRequest("GET", "/loans/"+loanID.String(), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestLoanHandler_ListApplications_DefaultsToPending(t *testing.T) {
+	mockService := new(MockLoanService)
+	router := setupLoanRouter(NewLoanHandler(mockService), uuid.New())
+	mockService.On("ListApplications", loan.Status("")).Return([]*loan.Loan{}, nil)
+
+	req, _ := http.NewRequest("GET", "/admin/loans", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestLoanHandler_Approve(t *testing.T) {
+	mockService := new(MockLoanService)
+	officerID := uuid.New()
+	loanID := uuid.New()
+	router := setupLoanRouter(NewLoanHandler(mockService), officerID)
+	mockService.On("Approve", officerID, loanID).Return(&loan.Loan{ID: loanID, Status: loan.StatusActive}, nil)
+
+	req, _ := http.NewRequest("POST", "/admin/loans/"+loanID.String()+"/approve", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"active"`)
+}
+
+func TestLoanHandler_Reject_RequiresReason(t *testing.T) {
+	mockService := new(MockLoanService)
+	router := setupLoanRouter(NewLoanHandler(mockService), uuid.New())
+
+	req, _ := http.NewRequest("POST", "/admin/loans/"+uuid.New().String()+"/reject", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "Reject", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/darisadam/madabank-server/internal/pkg/loglevel"
+	"github.com/darisadam/madabank-server/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type LogLevelHandler struct {
+	logLevelService service.LogLevelService
+}
+
+func NewLogLevelHandler(logLevelService service.LogLevelService) *LogLevelHandler {
+	return &LogLevelHandler{
+		logLevelService: logLevelService,
+	}
+}
+
+// GetLogLevel godoc
+// @Summary Get the log level
+// @Description Get the log level in effect, the level the server was started with and when a temporary change expires
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} loglevel.State
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/admin/log-level [get]
+func (h *LogLevelHandler) GetLogLevel(c *gin.Context) {
+	state, err := h.logLevelService.GetLogLevel()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, state)
+}
+
+// SetLogLevel godoc
+// @Summary Change the log level
+// @Description Change the log level of every replica for a while (1 hour by default, at most 24 hours), for example to capture debug logs of a production issue
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body loglevel.SetRequest true "Level and how long it lasts"
+// @Success 200 {object} loglevel.State
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/admin/log-level [put]
+func (h *LogLevelHandler) SetLogLevel(c *gin.Context) {
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req loglevel.SetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	state, err := h.logLevelService.SetLogLevel(adminID.(uuid.UUID), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, state)
+}
+
+// ResetLogLevel godoc
+// @Summary Reset the log level
+// @Description Drop a temporary change and return every replica to the level it was started with
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} loglevel.State
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/admin/log-level [delete]
+func (h *LogLevelHandler) ResetLogLevel(c *gin.Context) {
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	state, err := h.logLevelService.ResetLogLevel(adminID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, state)
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/darisadam/madabank-server/internal/pkg/loglevel"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockLogLevelService is a mock implementation of service.LogLevelService
+type MockLogLevelService struct {
+	mock.Mock
+}
+
+func (m *MockLogLevelService) GetLogLevel() (*loglevel.State, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*loglevel.State), args.Error(1)
+}
+
+func (m *MockLogLevelService) SetLogLevel(adminID uuid.UUID, req *loglevel.SetRequest) (*loglevel.State, error) {
+	args := m.Called(adminID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*loglevel.State), args.Error(1)
+}
+
+func (m *MockLogLevelService) ResetLogLevel(adminID uuid.UUID) (*loglevel.State, error) {
+	args := m.Called(adminID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*loglevel.State), args.Error(1)
+}
+
+func setupLogLevelRouter(handler *LogLevelHandler, userID uuid.UUID) *gin.Engine {
+	router := setupCardRouter()
+	group := router.Group("/admin", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Next()
+	})
+	group.GET("/log-level", handler.GetLogLevel)
+	group.PUT("/log-level", handler.SetLogLevel)
+	group.DELETE("/log-level", handler.ResetLogLevel)
+	return router
+}
+
+func TestLogLevelHandler_GetLogLevel(t *testing.T) {
+	mockService := new(MockLogLevelService)
+	router := setupLogLevelRouter(NewLogLevelHandler(mockService), uuid.New())
+	mockService.On("GetLogLevel").Return(&loglevel.State{Level: "info", Default: "info"}, nil)
+
+	req, _ := http.NewRequest("GET", "/admin/log-level", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"level":"info","default":"info"}`, w.Body.String())
+}
+
+func TestLogLevelHandler_SetLogLevel(t *testing.T) {
+	mockService := new(MockLogLevelService)
+	adminID := uuid.New()
+	router := setupLogLevelRouter(NewLogLevelHandler(mockService), adminID)
+	mockService.On("SetLogLevel", adminID, &loglevel.SetRequest{Level: "debug", Duration: "30m"}).
+		Return(&loglevel.State{Level: "debug", Default: "info"}, nil)
+
+	req, _ := http.NewRequest("PUT", "/admin/log-level", bytes.NewBufferString(`{"level":"debug","duration":"30m"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"level":"debug"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestLogLevelHandler_SetLogLevel_UnknownLevel(t *testing.T) {
+	mockService := new(MockLogLevelService)
+	router := setupLogLevelRouter(NewLogLevelHandler(mockService), uuid.New())
+
+	req, _ := http.NewRequest("PUT", "/admin/log-level", bytes.NewBufferString(`{"level":"fatal"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "SetLogLevel", mock.Anything, mock.Anything)
+}
+
+func TestLogLevelHandler_SetLogLevel_Invalid(t *testing.T) {
+	mockService := new(MockLogLevelService)
+	adminID := uuid.New()
+	router := setupLogLevelRouter(NewLogLevelHandler(mockService), adminID)
+	mockService.On("SetLogLevel", adminID, mock.Anything).Return(nil, fmt.Errorf("duration must not exceed 24 hours"))
+
+	req, _ := http.NewRequest("PUT", "/admin/log-level", bytes.NewBufferString(`{"level":"debug","duration":"48h"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "24 hours")
+}
+
+func TestLogLevelHandler_ResetLogLevel(t *testing.T) {
+	mockService := new(MockLogLevelService)
+	adminID := uuid.New()
+	router := setupLogLevelRouter(NewLogLevelHandler(mockService), adminID)
+	mockService.On("ResetLogLevel", adminID).Return(&loglevel.State{Level: "info", Default: "info"}, nil)
+
+	req, _ := http.NewRequest("DELETE", "/admin/log-level", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
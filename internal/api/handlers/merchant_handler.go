@@ -0,0 +1,537 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/darisadam/madabank-server/internal/domain/merchant"
+	"github.com/darisadam/madabank-server/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type MerchantHandler struct {
+	merchantService service.MerchantService
+}
+
+func NewMerchantHandler(merchantService service.MerchantService) *MerchantHandler {
+	return &MerchantHandler{
+		merchantService: merchantService,
+	}
+}
+
+// RegisterMerchant godoc
+// @Summary Register a merchant
+// @Description Register a merchant that settles into one of the user's accounts. The API key and webhook secret are only returned once.
+// @Tags merchants
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body merchant.RegisterMerchantRequest true "Merchant details"
+// @Success 201 {object} merchant.RegisterMerchantResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/merchants [post]
+func (h *MerchantHandler) RegisterMerchant(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req merchant.RegisterMerchantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.merchantService.RegisterMerchant(userID.(uuid.UUID), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// ListMerchants godoc
+// @Summary List merchants
+// @Description Get the merchants owned by the user
+// @Tags merchants
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} merchant.Merchant
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/merchants [get]
+func (h *MerchantHandler) ListMerchants(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	merchants, err := h.merchantService.ListMerchants(userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, merchants)
+}
+
+// RotateAPIKey godoc
+// @Summary Rotate a merchant API key
+// @Description Issue a new API key for the merchant. The previous key stops working immediately.
+// @Tags merchants
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Merchant ID"
+// @Success 200 {object} merchant.RotateAPIKeyResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/merchants/{id}/api-key [post]
+func (h *MerchantHandler) RotateAPIKey(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	merchantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid merchant ID"})
+		return
+	}
+
+	resp, err := h.merchantService.RotateAPIKey(userID.(uuid.UUID), merchantID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// UpdateWebhook godoc
+// @Summary Set a merchant webhook
+// @Description Set the HTTPS URL payment notifications are posted to, or remove it with an empty URL
+// @Tags merchants
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Merchant ID"
+// @Param request body merchant.UpdateWebhookRequest true "Webhook URL"
+// @Success 200 {object} merchant.Merchant
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/merchants/{id}/webhook [put]
+func (h *MerchantHandler) UpdateWebhook(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	merchantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid merchant ID"})
+		return
+	}
+
+	var req merchant.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	m, err := h.merchantService.UpdateWebhook(userID.(uuid.UUID), merchantID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, m)
+}
+
+// RotateWebhookSecret godoc
+// @Summary Rotate a merchant webhook secret
+// @Description Issue a new secret for signing webhooks. Deliveries from now on are signed with it.
+// @Tags merchants
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Merchant ID"
+// @Success 200 {object} merchant.RotateWebhookSecretResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/merchants/{id}/webhook/secret [post]
+func (h *MerchantHandler) RotateWebhookSecret(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	merchantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid merchant ID"})
+		return
+	}
+
+	resp, err := h.merchantService.RotateWebhookSecret(userID.(uuid.UUID), merchantID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ListWebhookDeliveries godoc
+// @Summary List webhook deliveries
+// @Description The merchant's 50 latest webhook attempts with the receiver's response code, newest first
+// @Tags merchants
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Merchant ID"
+// @Success 200 {array} merchant.WebhookDelivery
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/merchants/{id}/webhook/deliveries [get]
+func (h *MerchantHandler) ListWebhookDeliveries(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	merchantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid merchant ID"})
+		return
+	}
+
+	deliveries, err := h.merchantService.ListWebhookDeliveries(userID.(uuid.UUID), merchantID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// TestWebhook godoc
+// @Summary Send a test webhook
+// @Description Post a webhook.test event to the merchant's webhook and return the attempt, successful or not
+// @Tags merchants
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Merchant ID"
+// @Success 200 {object} merchant.WebhookDelivery
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/merchants/{id}/webhook/test [post]
+func (h *MerchantHandler) TestWebhook(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	merchantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid merchant ID"})
+		return
+	}
+
+	delivery, err := h.merchantService.TestWebhook(userID.(uuid.UUID), merchantID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
+
+// ReplayWebhook godoc
+// @Summary Replay webhook events
+// @Description Queue the payment.received events of links paid since a point in time for redelivery, for recovering from an outage of the receiver. Events are delivered by the notification job with the usual retries and show up in the delivery log.
+// @Tags merchants
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Merchant ID"
+// @Param since query string true "RFC 3339 time, at most 30 days ago"
+// @Success 202 {object} merchant.ReplayWebhookResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/merchants/{id}/webhook/replay [post]
+func (h *MerchantHandler) ReplayWebhook(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	merchantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid merchant ID"})
+		return
+	}
+
+	var req merchant.ReplayWebhookRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.merchantService.ReplayWebhook(userID.(uuid.UUID), merchantID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, resp)
+}
+
+// CreatePaymentLink godoc
+// @Summary Create a payment link
+// @Description Create a one-off payment link with a QR payload. Authenticated with the merchant API key.
+// @Tags merchants
+// @Accept json
+// @Produce json
+// @Param request body merchant.CreatePaymentLinkRequest true "Payment link details"
+// @Success 201 {object} merchant.PaymentLink
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/merchant/payment-links [post]
+func (h *MerchantHandler) CreatePaymentLink(c *gin.Context) {
+	merchantID, exists := c.Get("merchant_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req merchant.CreatePaymentLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	link, err := h.merchantService.CreatePaymentLink(merchantID.(uuid.UUID), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, link)
+}
+
+// ListPaymentLinks godoc
+// @Summary List payment links
+// @Description Get the merchant's most recent payment links
+// @Tags merchants
+// @Produce json
+// @Success 200 {array} merchant.PaymentLink
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/merchant/payment-links [get]
+func (h *MerchantHandler) ListPaymentLinks(c *gin.Context) {
+	merchantID, exists := c.Get("merchant_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	links, err := h.merchantService.ListPaymentLinks(merchantID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, links)
+}
+
+// GetMerchantPaymentLink godoc
+// @Summary Get a payment link
+// @Description Get one of the merchant's payment links and its payment status
+// @Tags merchants
+// @Produce json
+// @Param id path string true "Payment link ID"
+// @Success 200 {object} merchant.PaymentLink
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/merchant/payment-links/{id} [get]
+func (h *MerchantHandler) GetMerchantPaymentLink(c *gin.Context) {
+	merchantID, exists := c.Get("merchant_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	linkID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payment link ID"})
+		return
+	}
+
+	link, err := h.merchantService.GetMerchantPaymentLink(merchantID.(uuid.UUID), linkID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, link)
+}
+
+// CancelPaymentLink godoc
+// @Summary Cancel a payment link
+// @Description Cancel an open payment link so it can no longer be paid
+// @Tags merchants
+// @Produce json
+// @Param id path string true "Payment link ID"
+// @Success 200 {object} merchant.PaymentLink
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/merchant/payment-links/{id}/cancel [post]
+func (h *MerchantHandler) CancelPaymentLink(c *gin.Context) {
+	merchantID, exists := c.Get("merchant_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	linkID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payment link ID"})
+		return
+	}
+
+	link, err := h.merchantService.CancelPaymentLink(merchantID.(uuid.UUID), linkID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, link)
+}
+
+// ListSettlements godoc
+// @Summary List settlements
+// @Description Get the merchant's daily settlements, newest first
+// @Tags merchants
+// @Produce json
+// @Success 200 {array} merchant.Settlement
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/merchant/settlements [get]
+func (h *MerchantHandler) ListSettlements(c *gin.Context) {
+	merchantID, exists := c.Get("merchant_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	settlements, err := h.merchantService.ListSettlements(merchantID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, settlements)
+}
+
+// GetPaymentLink godoc
+// @Summary View a payment link
+// @Description Get a payment link's merchant, amount and status before paying it
+// @Tags payment-links
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Payment link ID"
+// @Success 200 {object} merchant.PaymentLink
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/payment-links/{id} [get]
+func (h *MerchantHandler) GetPaymentLink(c *gin.Context) {
+	linkID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payment link ID"})
+		return
+	}
+
+	link, err := h.merchantService.GetPaymentLink(linkID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, link)
+}
+
+// ResolvePaymentQR godoc
+// @Summary Resolve a merchant QR code
+// @Description Resolve a scanned merchant QR code to its payment link
+// @Tags payment-links
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body merchant.ResolvePaymentQRRequest true "QR payload"
+// @Success 200 {object} merchant.PaymentLink
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/payment-links/qr/resolve [post]
+func (h *MerchantHandler) ResolvePaymentQR(c *gin.Context) {
+	var req merchant.ResolvePaymentQRRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	link, err := h.merchantService.ResolveQR(req.QRPayload)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, link)
+}
+
+// PayPaymentLink godoc
+// @Summary Pay a payment link
+// @Description Pay a merchant payment link from one of the user's accounts
+// @Tags payment-links
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Payment link ID"
+// @Param request body merchant.PayPaymentLinkRequest true "Payment details"
+// @Success 200 {object} merchant.PaymentLink
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/payment-links/{id}/pay [post]
+func (h *MerchantHandler) PayPaymentLink(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	linkID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payment link ID"})
+		return
+	}
+
+	var req merchant.PayPaymentLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	link, err := h.merchantService.PayPaymentLink(userID.(uuid.UUID), linkID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, link)
+}
@@ -0,0 +1,428 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/merchant"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockMerchantService is a mock implementation of service.MerchantService
+type MockMerchantService struct {
+	mock.Mock
+}
+
+func (m *MockMerchantService) RegisterMerchant(ownerID uuid.UUID, req *merchant.RegisterMerchantRequest) (*merchant.RegisterMerchantResponse, error) {
+	args := m.Called(ownerID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*merchant.RegisterMerchantResponse), args.Error(1)
+}
+
+func (m *MockMerchantService) ListMerchants(ownerID uuid.UUID) ([]*merchant.Merchant, error) {
+	args := m.Called(ownerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*merchant.Merchant), args.Error(1)
+}
+
+func (m *MockMerchantService) RotateAPIKey(ownerID uuid.UUID, merchantID uuid.UUID) (*merchant.RotateAPIKeyResponse, error) {
+	args := m.Called(ownerID, merchantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*merchant.RotateAPIKeyResponse), args.Error(1)
+}
+
+func (m *MockMerchantService) UpdateWebhook(ownerID uuid.UUID, merchantID uuid.UUID, req *merchant.UpdateWebhookRequest) (*merchant.Merchant, error) {
+	args := m.Called(ownerID, merchantID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*merchant.Merchant), args.Error(1)
+}
+
+func (m *MockMerchantService) RotateWebhookSecret(ownerID uuid.UUID, merchantID uuid.UUID) (*merchant.RotateWebhookSecretResponse, error) {
+	args := m.Called(ownerID, merchantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*merchant.RotateWebhookSecretResponse), args.Error(1)
+}
+
+func (m *MockMerchantService) ListWebhookDeliveries(ownerID uuid.UUID, merchantID uuid.UUID) ([]*merchant.WebhookDelivery, error) {
+	args := m.Called(ownerID, merchantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*merchant.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockMerchantService) TestWebhook(ownerID uuid.UUID, merchantID uuid.UUID) (*merchant.WebhookDelivery, error) {
+	args := m.Called(ownerID, merchantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*merchant.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockMerchantService) ReplayWebhook(ownerID uuid.UUID, merchantID uuid.UUID, req *merchant.ReplayWebhookRequest) (*merchant.ReplayWebhookResponse, error) {
+	args := m.Called(ownerID, merchantID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*merchant.ReplayWebhookResponse), args.Error(1)
+}
+
+func (m *MockMerchantService) Authenticate(apiKey string) (*merchant.Merchant, error) {
+	args := m.Called(apiKey)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*merchant.Merchant), args.Error(1)
+}
+
+func (m *MockMerchantService) CreatePaymentLink(merchantID uuid.UUID, req *merchant.CreatePaymentLinkRequest) (*merchant.PaymentLink, error) {
+	args := m.Called(merchantID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*merchant.PaymentLink), args.Error(1)
+}
+
+func (m *MockMerchantService) GetMerchantPaymentLink(merchantID uuid.UUID, linkID uuid.UUID) (*merchant.PaymentLink, error) {
+	args := m.Called(merchantID, linkID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*merchant.PaymentLink), args.Error(1)
+}
+
+func (m *MockMerchantService) ListPaymentLinks(merchantID uuid.UUID) ([]*merchant.PaymentLink, error) {
+	args := m.Called(merchantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*merchant.PaymentLink), args.Error(1)
+}
+
+func (m *MockMerchantService) CancelPaymentLink(merchantID uuid.UUID, linkID uuid.UUID) (*merchant.PaymentLink, error) {
+	args := m.Called(merchantID, linkID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*merchant.PaymentLink), args.Error(1)
+}
+
+func (m *MockMerchantService) ListSettlements(merchantID uuid.UUID) ([]*merchant.Settlement, error) {
+	args := m.Called(merchantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*merchant.Settlement), args.Error(1)
+}
+
+func (m *MockMerchantService) GetPaymentLink(linkID uuid.UUID) (*merchant.PaymentLink, error) {
+	args := m.Called(linkID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*merchant.PaymentLink), args.Error(1)
+}
+
+func (m *MockMerchantService) ResolveQR(payload string) (*merchant.PaymentLink, error) {
+	args := m.Called(payload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*merchant.PaymentLink), args.Error(1)
+}
+
+func (m *MockMerchantService) PayPaymentLink(userID uuid.UUID, linkID uuid.UUID, req *merchant.PayPaymentLinkRequest) (*merchant.PaymentLink, error) {
+	args := m.Called(userID, linkID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*merchant.PaymentLink), args.Error(1)
+}
+
+func (m *MockMerchantService) SettleMerchants(now time.Time) (int, error) {
+	args := m.Called(now)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockMerchantService) DeliverNotifications(now time.Time) (int, error) {
+	args := m.Called(now)
+	return args.Int(0), args.Error(1)
+}
+
+func setupMerchantRouter(handler *MerchantHandler, userID, merchantID uuid.UUID) *gin.Engine {
+	router := setupCardRouter()
+	merchants := router.Group("/merchants", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Next()
+	})
+	merchants.POST("", handler.RegisterMerchant)
+	merchants.GET("", handler.ListMerchants)
+	merchants.POST("/:id/api-key", handler.RotateAPIKey)
+	merchants.PUT("/:id/webhook", handler.UpdateWebhook)
+	merchants.GET("/:id/webhook/deliveries", handler.ListWebhookDeliveries)
+	merchants.POST("/:id/webhook/test", handler.TestWebhook)
+	merchants.POST("/:id/webhook/replay", handler.ReplayWebhook)
+
+	merchantAPI := router.Group("/merchant", func(c *gin.Context) {
+		c.Set("merchant_id", merchantID)
+		c.Next()
+	})
+	merchantAPI.POST("/payment-links", handler.CreatePaymentLink)
+	merchantAPI.GET("/payment-links/:id", handler.GetMerchantPaymentLink)
+	merchantAPI.GET("/settlements", handler.ListSettlements)
+
+	links := router.Group("/payment-links", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Next()
+	})
+	links.POST("/qr/resolve", handler.ResolvePaymentQR)
+	links.GET("/:id", handler.GetPaymentLink)
+	links.POST("/:id/pay", handler.PayPaymentLink)
+	return router
+}
+
+func TestMerchantHandler_RegisterMerchant(t *testing.T) {
+	mockService := new(MockMerchantService)
+	userID := uuid.New()
+	accountID := uuid.New()
+	router := setupMerchantRouter(NewMerchantHandler(mockService), userID, uuid.New())
+
+	mockService.On("RegisterMerchant", userID, &merchant.RegisterMerchantRequest{
+		Name:                "Warung Kopi",
+		Category:            "food_beverage",
+		SettlementAccountID: accountID.String(),
+	}).Return(&merchant.RegisterMerchantResponse{
+		Merchant: &merchant.Merchant{ID: uuid.New(), APIKeyHash: "stored-hash"},
+		APIKey:   "mbk_live_abc",
+	}, nil)
+
+	body := []byte(fmt.Sprintf(`{"name":"Warung Kopi","category":"food_beverage","settlement_account_id":"%s"}`, accountID))
+	req, _ := http.NewRequest("POST", "/merchants", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"api_key":"mbk_live_abc"`)
+	assert.NotContains(t, w.Body.String(), "stored-hash")
+}
+
+func TestMerchantHandler_RegisterMerchant_PlainHTTPWebhook(t *testing.T) {
+	mockService := new(MockMerchantService)
+	router := setupMerchantRouter(NewMerchantHandler(mockService), uuid.New(), uuid.New())
+
+	body := []byte(fmt.Sprintf(`{"name":"Warung Kopi","category":"retail","settlement_account_id":"%s","webhook_url":"http://shop.example/hooks"}`, uuid.New()))
+	req, _ := http.NewRequest("POST", "/merchants", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "RegisterMerchant", mock.Anything, mock.Anything)
+}
+
+func TestMerchantHandler_CreatePaymentLink(t *testing.T) {
+	mockService := new(MockMerchantService)
+	merchantID := uuid.New()
+	router := setupMerchantRouter(NewMerchantHandler(mockService), uuid.New(), merchantID)
+
+	mockService.On("CreatePaymentLink", merchantID, &merchant.CreatePaymentLinkRequest{
+		Reference: "INV-001",
+		Amount:    150000,
+	}).Return(&merchant.PaymentLink{ID: uuid.New(), Status: merchant.PaymentLinkStatusOpen}, nil)
+
+	req, _ := http.NewRequest("POST", "/merchant/payment-links", bytes.NewBufferString(`{"reference":"INV-001","amount":150000}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestMerchantHandler_GetMerchantPaymentLink_NotFound(t *testing.T) {
+	mockService := new(MockMerchantService)
+	merchantID := uuid.New()
+	linkID := uuid.New()
+	router := setupMerchantRouter(NewMerchantHandler(mockService), uuid.New(), merchantID)
+
+	mockService.On("GetMerchantPaymentLink", merchantID, linkID).Return(nil, fmt.Errorf("payment link not found"))
+
+	req, _ := http.NewRequest("GET", "/merchant/payment-links/"+linkID.String(), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestMerchantHandler_ListSettlements(t *testing.T) {
+	mockService := new(MockMerchantService)
+	merchantID := uuid.New()
+	router := setupMerchantRouter(NewMerchantHandler(mockService), uuid.New(), merchantID)
+
+	mockService.On("ListSettlements", merchantID).Return([]*merchant.Settlement{{ID: uuid.New(), NetAmount: 99300}}, nil)
+
+	req, _ := http.NewRequest("GET", "/merchant/settlements", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"net_amount":99300`)
+}
+
+func TestMerchantHandler_ResolvePaymentQR(t *testing.T) {
+	mockService := new(MockMerchantService)
+	linkID := uuid.New()
+	router := setupMerchantRouter(NewMerchantHandler(mockService), uuid.New(), uuid.New())
+
+	mockService.On("ResolveQR", merchant.QRPayloadPrefix+linkID.String()).Return(&merchant.PaymentLink{ID: linkID}, nil)
+
+	body := []byte(fmt.Sprintf(`{"qr_payload":"%s%s"}`, merchant.QRPayloadPrefix, linkID))
+	req, _ := http.NewRequest("POST", "/payment-links/qr/resolve", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), linkID.String())
+}
+
+func TestMerchantHandler_PayPaymentLink(t *testing.T) {
+	mockService := new(MockMerchantService)
+	userID := uuid.New()
+	linkID := uuid.New()
+	accountID := uuid.New()
+	router := setupMerchantRouter(NewMerchantHandler(mockService), userID, uuid.New())
+
+	mockService.On("PayPaymentLink", userID, linkID, &merchant.PayPaymentLinkRequest{
+		AccountID:      accountID.String(),
+		IdempotencyKey: "pay-1",
+	}).Return(&merchant.PaymentLink{ID: linkID, Status: merchant.PaymentLinkStatusPaid}, nil)
+
+	body := []byte(fmt.Sprintf(`{"account_id":"%s","idempotency_key":"pay-1"}`, accountID))
+	req, _ := http.NewRequest("POST", "/payment-links/"+linkID.String()+"/pay", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"paid"`)
+}
+
+func TestMerchantHandler_PayPaymentLink_AlreadyPaid(t *testing.T) {
+	mockService := new(MockMerchantService)
+	userID := uuid.New()
+	linkID := uuid.New()
+	router := setupMerchantRouter(NewMerchantHandler(mockService), userID, uuid.New())
+
+	mockService.On("PayPaymentLink", userID, linkID, mock.Anything).Return(nil, fmt.Errorf("payment link is paid"))
+
+	body := []byte(fmt.Sprintf(`{"account_id":"%s","idempotency_key":"pay-2"}`, uuid.New()))
+	req, _ := http.NewRequest("POST", "/payment-links/"+linkID.String()+"/pay", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "payment link is paid")
+}
+
+func TestMerchantHandler_UpdateWebhook_RequiresHTTPS(t *testing.T) {
+	mockService := new(MockMerchantService)
+	router := setupMerchantRouter(NewMerchantHandler(mockService), uuid.New(), uuid.New())
+
+	body := []byte(`{"webhook_url":"http://shop.example/hooks"}`)
+	req, _ := http.NewRequest("PUT", "/merchants/"+uuid.New().String()+"/webhook", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "UpdateWebhook", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestMerchantHandler_ListWebhookDeliveries(t *testing.T) {
+	mockService := new(MockMerchantService)
+	userID := uuid.New()
+	merchantID := uuid.New()
+	router := setupMerchantRouter(NewMerchantHandler(mockService), userID, uuid.New())
+
+	mockService.On("ListWebhookDeliveries", userID, merchantID).Return([]*merchant.WebhookDelivery{
+		{ID: uuid.New(), Event: merchant.EventPaymentReceived, StatusCode: 502, Error: "webhook receiver returned status 502"},
+	}, nil)
+
+	req, _ := http.NewRequest("GET", "/merchants/"+merchantID.String()+"/webhook/deliveries", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status_code":502`)
+}
+
+func TestMerchantHandler_TestWebhook(t *testing.T) {
+	mockService := new(MockMerchantService)
+	userID := uuid.New()
+	merchantID := uuid.New()
+	router := setupMerchantRouter(NewMerchantHandler(mockService), userID, uuid.New())
+
+	mockService.On("TestWebhook", userID, merchantID).Return(&merchant.WebhookDelivery{
+		ID: uuid.New(), Event: merchant.EventWebhookTest, StatusCode: 200, Success: true,
+	}, nil)
+
+	req, _ := http.NewRequest("POST", "/merchants/"+merchantID.String()+"/webhook/test", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"event":"webhook.test"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestMerchantHandler_ReplayWebhook(t *testing.T) {
+	mockService := new(MockMerchantService)
+	userID := uuid.New()
+	merchantID := uuid.New()
+	router := setupMerchantRouter(NewMerchantHandler(mockService), userID, uuid.New())
+
+	since := time.Date(2026, 3, 30, 0, 0, 0, 0, time.UTC)
+	mockService.On("ReplayWebhook", userID, merchantID, &merchant.ReplayWebhookRequest{Since: "2026-03-30T00:00:00Z"}).
+		Return(&merchant.ReplayWebhookResponse{Since: since, Scheduled: 4}, nil)
+
+	req, _ := http.NewRequest("POST", "/merchants/"+merchantID.String()+"/webhook/replay?since=2026-03-30T00:00:00Z", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Contains(t, w.Body.String(), `"scheduled":4`)
+	mockService.AssertExpectations(t)
+}
+
+func TestMerchantHandler_ReplayWebhook_MissingSince(t *testing.T) {
+	mockService := new(MockMerchantService)
+	merchantID := uuid.New()
+	router := setupMerchantRouter(NewMerchantHandler(mockService), uuid.New(), uuid.New())
+
+	req, _ := http.NewRequest("POST", "/merchants/"+merchantID.String()+"/webhook/replay", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "ReplayWebhook", mock.Anything, mock.Anything, mock.Anything)
+}
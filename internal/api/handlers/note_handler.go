@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/darisadam/madabank-server/internal/domain/note"
+	"github.com/darisadam/madabank-server/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type NoteHandler struct {
+	noteService service.NoteService
+}
+
+func NewNoteHandler(noteService service.NoteService) *NoteHandler {
+	return &NoteHandler{
+		noteService: noteService,
+	}
+}
+
+// AddTransactionNote godoc
+// @Summary Add an internal note to a transaction
+// @Description Attach a free-text note for support staff, for example while handling a dispute. Notes are never shown to customers and cannot be edited.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Transaction ID"
+// @Param request body note.CreateNoteRequest true "Note"
+// @Success 201 {object} note.Note
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/admin/transactions/{id}/notes [post]
+func (h *NoteHandler) AddTransactionNote(c *gin.Context) {
+	h.addNote(c, note.SubjectTransaction)
+}
+
+// ListTransactionNotes godoc
+// @Summary List internal notes on a transaction
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Transaction ID"
+// @Success 200 {array} note.Note
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/admin/transactions/{id}/notes [get]
+func (h *NoteHandler) ListTransactionNotes(c *gin.Context) {
+	h.listNotes(c, note.SubjectTransaction)
+}
+
+// AddUserNote godoc
+// @Summary Add an internal note to a user
+// @Description Attach a free-text note to a customer's profile for support staff. Notes are never shown to customers and cannot be edited.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param request body note.CreateNoteRequest true "Note"
+// @Success 201 {object} note.Note
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/admin/users/{id}/notes [post]
+func (h *NoteHandler) AddUserNote(c *gin.Context) {
+	h.addNote(c, note.SubjectUser)
+}
+
+// ListUserNotes godoc
+// @Summary List internal notes on a user
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {array} note.Note
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/admin/users/{id}/notes [get]
+func (h *NoteHandler) ListUserNotes(c *gin.Context) {
+	h.listNotes(c, note.SubjectUser)
+}
+
+func (h *NoteHandler) addNote(c *gin.Context, subjectType note.SubjectType) {
+	authorID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	subjectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + string(subjectType) + " ID"})
+		return
+	}
+
+	var req note.CreateNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	n, err := h.noteService.AddNote(authorID.(uuid.UUID), subjectType, subjectID, &req)
+	if err != nil {
+		respondNoteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, n)
+}
+
+func (h *NoteHandler) listNotes(c *gin.Context, subjectType note.SubjectType) {
+	subjectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + string(subjectType) + " ID"})
+		return
+	}
+
+	notes, err := h.noteService.ListNotes(subjectType, subjectID)
+	if err != nil {
+		respondNoteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, notes)
+}
+
+func respondNoteError(c *gin.Context, err error) {
+	if errors.Is(err, note.ErrSubjectNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/darisadam/madabank-server/internal/domain/note"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockNoteService is a mock implementation of service.NoteService
+type MockNoteService struct {
+	mock.Mock
+}
+
+func (m *MockNoteService) AddNote(authorID uuid.UUID, subjectType note.SubjectType, subjectID uuid.UUID, req *note.CreateNoteRequest) (*note.Note, error) {
+	args := m.Called(authorID, subjectType, subjectID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*note.Note), args.Error(1)
+}
+
+func (m *MockNoteService) ListNotes(subjectType note.SubjectType, subjectID uuid.UUID) ([]*note.Note, error) {
+	args := m.Called(subjectType, subjectID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*note.Note), args.Error(1)
+}
+
+func setupNoteRouter(handler *NoteHandler, authorID uuid.UUID) *gin.Engine {
+	router := setupCardRouter()
+	admin := router.Group("/admin", func(c *gin.Context) {
+		c.Set("user_id", authorID)
+		c.Next()
+	})
+	admin.GET("/transactions/:id/notes", handler.ListTransactionNotes)
+	admin.POST("/transactions/:id/notes", handler.AddTransactionNote)
+	admin.GET("/users/:id/notes", handler.ListUserNotes)
+	admin.POST("/users/:id/notes", handler.AddUserNote)
+	return router
+}
+
+func TestNoteHandler_AddTransactionNote(t *testing.T) {
+	mockService := new(MockNoteService)
+	authorID, txnID := uuid.New(), uuid.New()
+	router := setupNoteRouter(NewNoteHandler(mockService), authorID)
+
+	mockService.On("AddNote", authorID, note.SubjectTransaction, txnID, &note.CreateNoteRequest{Body: "Chargeback filed with the card network"}).
+		Return(&note.Note{ID: uuid.New(), SubjectType: note.SubjectTransaction, SubjectID: txnID, AuthorID: authorID}, nil)
+
+	body := []byte(`{"body":"Chargeback filed with the card network"}`)
+	req, _ := http.NewRequest("POST", fmt.Sprintf("/admin/transactions/%s/notes", txnID), bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"subject_type":"transaction"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestNoteHandler_AddUserNote_Validation(t *testing.T) {
+	mockService := new(MockNoteService)
+	router := setupNoteRouter(NewNoteHandler(mockService), uuid.New())
+
+	for name, tt := range map[string]struct{ path, body string }{
+		"invalid ID": {"/admin/users/not-a-uuid/notes", `{"body":"Called the customer"}`},
+		"empty body": {"/admin/users/" + uuid.New().String() + "/notes", `{"body":""}`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+	mockService.AssertNotCalled(t, "AddNote", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestNoteHandler_ListUserNotes(t *testing.T) {
+	mockService := new(MockNoteService)
+	userID := uuid.New()
+	router := setupNoteRouter(NewNoteHandler(mockService), uuid.New())
+
+	mockService.On("ListNotes", note.SubjectUser, userID).
+		Return([]*note.Note{{ID: uuid.New(), Body: "Prefers contact by email", AuthorEmail: "agent@madabank.com"}}, nil)
+
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/admin/users/%s/notes", userID), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Prefers contact by email")
+}
+
+func TestNoteHandler_ListTransactionNotes_NotFound(t *testing.T) {
+	mockService := new(MockNoteService)
+	txnID := uuid.New()
+	router := setupNoteRouter(NewNoteHandler(mockService), uuid.New())
+
+	mockService.On("ListNotes", note.SubjectTransaction, txnID).Return(nil, fmt.Errorf("transaction %w", note.ErrSubjectNotFound))
+
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/admin/transactions/%s/notes", txnID), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"error":"transaction not found"}`, w.Body.String())
+}
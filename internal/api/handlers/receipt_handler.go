@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ReceiptHandler struct {
+	receiptService service.ReceiptService
+}
+
+func NewReceiptHandler(receiptService service.ReceiptService) *ReceiptHandler {
+	return &ReceiptHandler{
+		receiptService: receiptService,
+	}
+}
+
+// GetReceipt godoc
+// @Summary Get transaction receipt
+// @Description Get the receipt of a completed transaction, with a signed link anyone can open to view a limited copy
+// @Tags transactions
+// @Produce json
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param id path string true "Transaction ID"
+// @Param format query string false "Output format (json, pdf). Defaults to json."
+// @Success 200 {object} transaction.Receipt
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/transactions/{id}/receipt [get]
+func (h *ReceiptHandler) GetReceipt(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	transactionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid transaction ID"})
+		return
+	}
+
+	var req transaction.ReceiptRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	receipt, err := h.receiptService.GetReceipt(userID.(uuid.UUID), transactionID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.write(c, req.Format, receipt)
+}
+
+// GetSharedReceipt godoc
+// @Summary View a shared receipt
+// @Description Open a receipt shared through a signed link. No authentication; the description is left out.
+// @Tags transactions
+// @Produce json
+// @Produce application/pdf
+// @Param token path string true "Share token"
+// @Param format query string false "Output format (json, pdf). Defaults to json."
+// @Success 200 {object} transaction.Receipt
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/receipts/{token} [get]
+func (h *ReceiptHandler) GetSharedReceipt(c *gin.Context) {
+	var req transaction.ReceiptRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	receipt, err := h.receiptService.GetSharedReceipt(c.Param("token"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.write(c, req.Format, receipt)
+}
+
+func (h *ReceiptHandler) write(c *gin.Context, format string, receipt *transaction.Receipt) {
+	if format != transaction.ReceiptFormatPDF {
+		c.JSON(http.StatusOK, receipt)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s"`, receipt.FileName()))
+	c.Data(http.StatusOK, "application/pdf", h.receiptService.RenderPDF(receipt))
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockReceiptService is a mock implementation of service.ReceiptService
+type MockReceiptService struct {
+	mock.Mock
+}
+
+func (m *MockReceiptService) GetReceipt(userID uuid.UUID, transactionID uuid.UUID) (*transaction.Receipt, error) {
+	args := m.Called(userID, transactionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*transaction.Receipt), args.Error(1)
+}
+
+func (m *MockReceiptService) GetSharedReceipt(token string) (*transaction.Receipt, error) {
+	args := m.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*transaction.Receipt), args.Error(1)
+}
+
+func (m *MockReceiptService) RenderPDF(r *transaction.Receipt) []byte {
+	args := m.Called(r)
+	return args.Get(0).([]byte)
+}
+
+func setupReceiptRouter(handler *ReceiptHandler, userID uuid.UUID) *gin.Engine {
+	router := setupCardRouter()
+	router.GET("/receipts/:token", handler.GetSharedReceipt)
+	group := router.Group("/transactions", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Next()
+	})
+	group.GET("/:id/receipt", handler.GetReceipt)
+	return router
+}
+
+func TestReceiptHandler_GetReceipt_JSON(t *testing.T) {
+	mockService := new(MockReceiptService)
+	userID := uuid.New()
+	receipt := &transaction.Receipt{TransactionID: uuid.New(), Amount: 150000, ShareURL: "https://madabank.example/r/abc"}
+	mockService.On("GetReceipt", userID, receipt.TransactionID).Return(receipt, nil)
+	router := setupReceiptRouter(NewReceiptHandler(mockService), userID)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/transactions/"+receipt.TransactionID.String()+"/receipt", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"share_url":"https://madabank.example/r/abc"`)
+}
+
+func TestReceiptHandler_GetReceipt_PDF(t *testing.T) {
+	mockService := new(MockReceiptService)
+	userID := uuid.New()
+	receipt := &transaction.Receipt{TransactionID: uuid.New()}
+	mockService.On("GetReceipt", userID, receipt.TransactionID).Return(receipt, nil)
+	mockService.On("RenderPDF", receipt).Return([]byte("%PDF-1.4"))
+	router := setupReceiptRouter(NewReceiptHandler(mockService), userID)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/transactions/"+receipt.TransactionID.String()+"/receipt?format=pdf", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), receipt.FileName())
+	assert.Equal(t, "%PDF-1.4", w.Body.String())
+}
+
+func TestReceiptHandler_GetReceipt_InvalidFormat(t *testing.T) {
+	mockService := new(MockReceiptService)
+	router := setupReceiptRouter(NewReceiptHandler(mockService), uuid.New())
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/transactions/"+uuid.New().String()+"/receipt?format=png", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "GetReceipt", mock.Anything, mock.Anything)
+}
+
+func TestReceiptHandler_GetReceipt_NotCompleted(t *testing.T) {
+	mockService := new(MockReceiptService)
+	userID, txnID := uuid.New(), uuid.New()
+	mockService.On("GetReceipt", userID, txnID).Return(nil, fmt.Errorf("receipts are only available for completed transactions"))
+	router := setupReceiptRouter(NewReceiptHandler(mockService), userID)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/transactions/"+txnID.String()+"/receipt", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestReceiptHandler_GetSharedReceipt_Expired(t *testing.T) {
+	mockService := new(MockReceiptService)
+	mockService.On("GetSharedReceipt", "abc.def").Return(nil, transaction.ErrExpiredShareToken)
+	router := setupReceiptRouter(NewReceiptHandler(mockService), uuid.New())
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/receipts/abc.def", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "receipt link has expired")
+}
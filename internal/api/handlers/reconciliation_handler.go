@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/darisadam/madabank-server/internal/domain/reconciliation"
+	"github.com/darisadam/madabank-server/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ReconciliationHandler struct {
+	reconciliationService service.ReconciliationService
+}
+
+func NewReconciliationHandler(reconciliationService service.ReconciliationService) *ReconciliationHandler {
+	return &ReconciliationHandler{
+		reconciliationService: reconciliationService,
+	}
+}
+
+// ReconcileLedger godoc
+// @Summary Run a ledger reconciliation
+// @Description Compare every account's stored balance with the sum of its ledger entries now, instead of waiting for the nightly run
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} reconciliation.Run
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/admin/reconciliation/ledger [post]
+func (h *ReconciliationHandler) ReconcileLedger(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	run, err := h.reconciliationService.ReconcileLedger(userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, run)
+}
+
+// ImportSettlementFile godoc
+// @Summary Reconcile a settlement file
+// @Description Upload a counterparty settlement file (CSV with reference and amount columns) and compare it with the ledger for the business date
+// @Tags admin
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param source formData string true "Counterparty (biller_aggregator, topup_aggregator)"
+// @Param business_date formData string true "Business date (YYYY-MM-DD)"
+// @Param file formData file true "Settlement file"
+// @Success 200 {object} reconciliation.Run
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/admin/reconciliation/settlement-files [post]
+func (h *ReconciliationHandler) ImportSettlementFile(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "settlement file is required"})
+		return
+	}
+	if header.Size > reconciliation.MaxSettlementFileSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "settlement file is too large"})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read settlement file"})
+		return
+	}
+	defer func() { _ = file.Close() }()
+
+	run, err := h.reconciliationService.ImportSettlementFile(
+		userID.(uuid.UUID),
+		reconciliation.Source(c.PostForm("source")),
+		c.PostForm("business_date"),
+		header.Filename,
+		file,
+	)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, run)
+}
+
+// ListRuns godoc
+// @Summary List reconciliation runs
+// @Description Get the most recent reconciliation runs
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} reconciliation.Run
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/admin/reconciliation/runs [get]
+func (h *ReconciliationHandler) ListRuns(c *gin.Context) {
+	runs, err := h.reconciliationService.ListRuns()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, runs)
+}
+
+// ListExceptions godoc
+// @Summary List reconciliation exceptions
+// @Description Get reconciliation breaks by status, oldest first. Defaults to open exceptions.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Exception status (open, investigating, resolved, dismissed)"
+// @Param limit query int false "Maximum results (1-100)"
+// @Success 200 {array} reconciliation.Exception
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/admin/reconciliation/exceptions [get]
+func (h *ReconciliationHandler) ListExceptions(c *gin.Context) {
+	var req reconciliation.ListExceptionsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	exceptions, err := h.reconciliationService.ListExceptions(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, exceptions)
+}
+
+// GetException godoc
+// @Summary Get a reconciliation exception
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Exception ID"
+// @Success 200 {object} reconciliation.Exception
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/admin/reconciliation/exceptions/{id} [get]
+func (h *ReconciliationHandler) GetException(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid exception ID"})
+		return
+	}
+
+	e, err := h.reconciliationService.GetException(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, e)
+}
+
+// UpdateException godoc
+// @Summary Update a reconciliation exception
+// @Description Take an exception under investigation, or resolve or dismiss it with a note
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Exception ID"
+// @Param request body reconciliation.UpdateExceptionRequest true "New status"
+// @Success 200 {object} reconciliation.Exception
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/admin/reconciliation/exceptions/{id} [patch]
+func (h *ReconciliationHandler) UpdateException(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid exception ID"})
+		return
+	}
+
+	var req reconciliation.UpdateExceptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	e, err := h.reconciliationService.UpdateException(userID.(uuid.UUID), id, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, e)
+}
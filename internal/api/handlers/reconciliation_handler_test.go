@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/reconciliation"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockReconciliationService is a mock implementation of service.ReconciliationService
+type MockReconciliationService struct {
+	mock.Mock
+}
+
+func (m *MockReconciliationService) ReconcileLedger(startedBy uuid.UUID) (*reconciliation.Run, error) {
+	args := m.Called(startedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*reconciliation.Run), args.Error(1)
+}
+
+func (m *MockReconciliationService) RunScheduledLedger(now time.Time) (int, error) {
+	args := m.Called(now)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockReconciliationService) ImportSettlementFile(startedBy uuid.UUID, source reconciliation.Source, businessDate, fileName string, file io.Reader) (*reconciliation.Run, error) {
+	content, _ := io.ReadAll(file)
+	args := m.Called(startedBy, source, businessDate, fileName, string(content))
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*reconciliation.Run), args.Error(1)
+}
+
+func (m *MockReconciliationService) ListRuns() ([]*reconciliation.Run, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*reconciliation.Run), args.Error(1)
+}
+
+func (m *MockReconciliationService) ListExceptions(req *reconciliation.ListExceptionsRequest) ([]*reconciliation.Exception, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*reconciliation.Exception), args.Error(1)
+}
+
+func (m *MockReconciliationService) GetException(id uuid.UUID) (*reconciliation.Exception, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*reconciliation.Exception), args.Error(1)
+}
+
+func (m *MockReconciliationService) UpdateException(userID uuid.UUID, id uuid.UUID, req *reconciliation.UpdateExceptionRequest) (*reconciliation.Exception, error) {
+	args := m.Called(userID, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*reconciliation.Exception), args.Error(1)
+}
+
+func setupReconciliationRouter(handler *ReconciliationHandler, userID uuid.UUID) *gin.Engine {
+	router := setupCardRouter()
+	recon := router.Group("/admin/reconciliation", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Next()
+	})
+	recon.POST("/ledger", handler.ReconcileLedger)
+	recon.POST("/settlement-files", handler.ImportSettlementFile)
+	recon.GET("/runs", handler.ListRuns)
+	recon.GET("/exceptions", handler.ListExceptions)
+	recon.GET("/exceptions/:id", handler.GetException)
+	recon.PATCH("/exceptions/:id", handler.UpdateException)
+	return router
+}
+
+func settlementFileRequest(t *testing.T, fields map[string]string, content string) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for k, v := range fields {
+		assert.NoError(t, writer.WriteField(k, v))
+	}
+	if content != "" {
+		part, err := writer.CreateFormFile("file", "biller-20260301.csv")
+		assert.NoError(t, err)
+		_, _ = part.Write([]byte(content))
+	}
+	assert.NoError(t, writer.Close())
+
+	req, _ := http.NewRequest("POST", "/admin/reconciliation/settlement-files", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestReconciliationHandler_ImportSettlementFile(t *testing.T) {
+	mockService := new(MockReconciliationService)
+	adminID := uuid.New()
+	router := setupReconciliationRouter(NewReconciliationHandler(mockService), adminID)
+
+	content := "reference,amount\ntxn-1,150000\n"
+	mockService.On("ImportSettlementFile", adminID, reconciliation.SourceBillerAggregator, "2026-03-01", "biller-20260301.csv", content).
+		Return(&reconciliation.Run{ID: uuid.New(), ItemsChecked: 1}, nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, settlementFileRequest(t, map[string]string{
+		"source": "biller_aggregator", "business_date": "2026-03-01",
+	}, content))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"items_checked":1`)
+}
+
+func TestReconciliationHandler_ImportSettlementFile_MissingFile(t *testing.T) {
+	mockService := new(MockReconciliationService)
+	router := setupReconciliationRouter(NewReconciliationHandler(mockService), uuid.New())
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, settlementFileRequest(t, map[string]string{"source": "biller_aggregator"}, ""))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "settlement file is required")
+}
+
+func TestReconciliationHandler_UpdateException(t *testing.T) {
+	mockService := new(MockReconciliationService)
+	adminID := uuid.New()
+	id := uuid.New()
+	router := setupReconciliationRouter(NewReconciliationHandler(mockService), adminID)
+
+	mockService.On("UpdateException", adminID, id, &reconciliation.UpdateExceptionRequest{
+		Status: reconciliation.ExceptionStatusResolved, Note: "late biller confirmation",
+	}).Return(&reconciliation.Exception{ID: id, Status: reconciliation.ExceptionStatusResolved}, nil)
+
+	req, _ := http.NewRequest("PATCH", "/admin/reconciliation/exceptions/"+id.String(),
+		bytes.NewBufferString(`{"status":"resolved","note":"late biller confirmation"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"resolved"`)
+}
+
+func TestReconciliationHandler_UpdateException_CannotReopen(t *testing.T) {
+	mockService := new(MockReconciliationService)
+	router := setupReconciliationRouter(NewReconciliationHandler(mockService), uuid.New())
+
+	req, _ := http.NewRequest("PATCH", "/admin/reconciliation/exceptions/"+uuid.New().String(), bytes.NewBufferString(`{"status":"open"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "UpdateException", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestReconciliationHandler_GetException_NotFound(t *testing.T) {
+	mockService := new(MockReconciliationService)
+	id := uuid.New()
+	router := setupReconciliationRouter(NewReconciliationHandler(mockService), uuid.New())
+	mockService.On("GetException", id).Return(nil, fmt.Errorf("reconciliation exception not found"))
+
+	req, _ := http.NewRequest("GET", "/admin/reconciliation/exceptions/"+id.String(), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
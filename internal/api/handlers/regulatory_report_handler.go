@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/darisadam/madabank-server/internal/domain/regulatory"
+	"github.com/darisadam/madabank-server/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type RegulatoryReportHandler struct {
+	reportService service.RegulatoryReportService
+}
+
+func NewRegulatoryReportHandler(reportService service.RegulatoryReportService) *RegulatoryReportHandler {
+	return &RegulatoryReportHandler{
+		reportService: reportService,
+	}
+}
+
+// FlagTransaction godoc
+// @Summary Flag a suspicious transaction
+// @Description Mark a transaction as suspicious so it is listed in the suspicious transaction report for today
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Transaction ID"
+// @Param request body regulatory.FlagTransactionRequest true "Reason"
+// @Success 201 {object} regulatory.Flag
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/admin/transactions/{id}/flags [post]
+func (h *RegulatoryReportHandler) FlagTransaction(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	transactionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid transaction ID"})
+		return
+	}
+
+	var req regulatory.FlagTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	flag, err := h.reportService.FlagTransaction(userID.(uuid.UUID), transactionID, &req)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, flag)
+}
+
+// GenerateReport godoc
+// @Summary Generate a regulatory report
+// @Description Compile a large or suspicious transaction report for a period of past days (up to 31)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body regulatory.GenerateReportRequest true "Report type and period"
+// @Success 201 {object} regulatory.Report
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/admin/regulatory-reports [post]
+func (h *RegulatoryReportHandler) GenerateReport(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req regulatory.GenerateReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := h.reportService.GenerateReport(userID.(uuid.UUID), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, report)
+}
+
+// ListReports godoc
+// @Summary List regulatory reports
+// @Description Get the most recent generated reports, scheduled and on request
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} regulatory.Report
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/admin/regulatory-reports [get]
+func (h *RegulatoryReportHandler) ListReports(c *gin.Context) {
+	reports, err := h.reportService.ListReports()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, reports)
+}
+
+// GetReport godoc
+// @Summary Get a regulatory report
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Report ID"
+// @Success 200 {object} regulatory.Report
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/admin/regulatory-reports/{id} [get]
+func (h *RegulatoryReportHandler) GetReport(c *gin.Context) {
+	report, ok := h.getReport(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// DownloadReport godoc
+// @Summary Download a regulatory report file
+// @Description Get the report file in the regulator's XML submission format
+// @Tags admin
+// @Produce xml
+// @Security BearerAuth
+// @Param id path string true "Report ID"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/admin/regulatory-reports/{id}/file [get]
+func (h *RegulatoryReportHandler) DownloadReport(c *gin.Context) {
+	report, ok := h.getReport(c)
+	if !ok {
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, report.FileName))
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", report.Content)
+}
+
+func (h *RegulatoryReportHandler) getReport(c *gin.Context) (*regulatory.Report, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid report ID"})
+		return nil, false
+	}
+
+	report, err := h.reportService.GetReport(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	return report, true
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/regulatory"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockRegulatoryReportService is a mock implementation of service.RegulatoryReportService
+type MockRegulatoryReportService struct {
+	mock.Mock
+}
+
+func (m *MockRegulatoryReportService) FlagTransaction(userID, transactionID uuid.UUID, req *regulatory.FlagTransactionRequest) (*regulatory.Flag, error) {
+	args := m.Called(userID, transactionID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*regulatory.Flag), args.Error(1)
+}
+
+func (m *MockRegulatoryReportService) GenerateReport(userID uuid.UUID, req *regulatory.GenerateReportRequest) (*regulatory.Report, error) {
+	args := m.Called(userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*regulatory.Report), args.Error(1)
+}
+
+func (m *MockRegulatoryReportService) RunScheduled(now time.Time) (int, error) {
+	args := m.Called(now)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRegulatoryReportService) ListReports() ([]*regulatory.Report, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*regulatory.Report), args.Error(1)
+}
+
+func (m *MockRegulatoryReportService) GetReport(id uuid.UUID) (*regulatory.Report, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*regulatory.Report), args.Error(1)
+}
+
+func setupRegulatoryReportRouter(handler *RegulatoryReportHandler, userID uuid.UUID) *gin.Engine {
+	router := setupCardRouter()
+	admin := router.Group("/admin", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Next()
+	})
+	admin.POST("/transactions/:id/flags", handler.FlagTransaction)
+	admin.POST("/regulatory-reports", handler.GenerateReport)
+	admin.GET("/regulatory-reports", handler.ListReports)
+	admin.GET("/regulatory-reports/:id", handler.GetReport)
+	admin.GET("/regulatory-reports/:id/file", handler.DownloadReport)
+	return router
+}
+
+func TestRegulatoryReportHandler_FlagTransaction(t *testing.T) {
+	mockService := new(MockRegulatoryReportService)
+	officerID := uuid.New()
+	txnID := uuid.New()
+	router := setupRegulatoryReportRouter(NewRegulatoryReportHandler(mockService), officerID)
+
+	mockService.On("FlagTransaction", officerID, txnID, &regulatory.FlagTransactionRequest{Reason: "structuring"}).
+		Return(&regulatory.Flag{ID: uuid.New(), TransactionID: txnID, Source: regulatory.FlagSourceManual}, nil)
+
+	req, _ := http.NewRequest("POST", "/admin/transactions/"+txnID.String()+"/flags", bytes.NewBufferString(`{"reason":"structuring"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"source":"manual"`)
+}
+
+func TestRegulatoryReportHandler_FlagTransaction_MissingReason(t *testing.T) {
+	mockService := new(MockRegulatoryReportService)
+	router := setupRegulatoryReportRouter(NewRegulatoryReportHandler(mockService), uuid.New())
+
+	req, _ := http.NewRequest("POST", "/admin/transactions/"+uuid.New().String()+"/flags", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "FlagTransaction", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRegulatoryReportHandler_GenerateReport(t *testing.T) {
+	mockService := new(MockRegulatoryReportService)
+	officerID := uuid.New()
+	router := setupRegulatoryReportRouter(NewRegulatoryReportHandler(mockService), officerID)
+
+	mockService.On("GenerateReport", officerID, &regulatory.GenerateReportRequest{
+		Type: regulatory.ReportTypeLargeTransaction, From: "2026-03-01", To: "2026-03-07",
+	}).Return(&regulatory.Report{ID: uuid.New(), Type: regulatory.ReportTypeLargeTransaction, ItemCount: 2, Content: []byte("<report/>")}, nil)
+
+	req, _ := http.NewRequest("POST", "/admin/regulatory-reports",
+		bytes.NewBufferString(`{"type":"large_transaction","from":"2026-03-01","to":"2026-03-07"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"item_count":2`)
+	assert.NotContains(t, w.Body.String(), "report/")
+}
+
+func TestRegulatoryReportHandler_GenerateReport_InvalidType(t *testing.T) {
+	mockService := new(MockRegulatoryReportService)
+	router := setupRegulatoryReportRouter(NewRegulatoryReportHandler(mockService), uuid.New())
+
+	req, _ := http.NewRequest("POST", "/admin/regulatory-reports",
+		bytes.NewBufferString(`{"type":"cash","from":"2026-03-01","to":"2026-03-07"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestRegulatoryReportHandler_DownloadReport(t *testing.T) {
+	mockService := new(MockRegulatoryReportService)
+	id := uuid.New()
+	router := setupRegulatoryReportRouter(NewRegulatoryReportHandler(mockService), uuid.New())
+	mockService.On("GetReport", id).Return(&regulatory.Report{ID: id, FileName: "LTKM-20260301-20260301.xml", Content: []byte("<report/>")}, nil)
+
+	req, _ := http.NewRequest("GET", "/admin/regulatory-reports/"+id.String()+"/file", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/xml; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="LTKM-20260301-20260301.xml"`, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, "<report/>", w.Body.String())
+}
+
+func TestRegulatoryReportHandler_GetReport_NotFound(t *testing.T) {
+	mockService := new(MockRegulatoryReportService)
+	id := uuid.New()
+	router := setupRegulatoryReportRouter(NewRegulatoryReportHandler(mockService), uuid.New())
+	mockService.On("GetReport", id).Return(nil, fmt.Errorf("regulatory report not found"))
+
+	req, _ := http.NewRequest("GET", "/admin/regulatory-reports/"+id.String(), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/darisadam/madabank-server/internal/domain/report"
+	"github.com/darisadam/madabank-server/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type ReportHandler struct {
+	reportService service.ReportService
+}
+
+func NewReportHandler(reportService service.ReportService) *ReportHandler {
+	return &ReportHandler{
+		reportService: reportService,
+	}
+}
+
+// DailySummary godoc
+// @Summary Get the daily settlement summary
+// @Description Get a business day's completed totals by transaction type, fee revenue, interest paid and failed transaction counts
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param date query string true "Business date (YYYY-MM-DD)"
+// @Success 200 {object} report.DailySummary
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/admin/reports/daily [get]
+func (h *ReportHandler) DailySummary(c *gin.Context) {
+	var req report.DailySummaryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	summary, err := h.reportService.DailySummary(req.Date)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/report"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockReportService is a mock implementation of service.ReportService
+type MockReportService struct {
+	mock.Mock
+}
+
+func (m *MockReportService) DailySummary(date string) (*report.DailySummary, error) {
+	args := m.Called(date)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*report.DailySummary), args.Error(1)
+}
+
+func setupReportRouter(handler *ReportHandler) *gin.Engine {
+	router := setupCardRouter()
+	router.GET("/admin/reports/daily", handler.DailySummary)
+	return router
+}
+
+func TestReportHandler_DailySummary(t *testing.T) {
+	mockService := new(MockReportService)
+	router := setupReportRouter(NewReportHandler(mockService))
+	summary := report.BuildDailySummary(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), []*report.TypeTotal{
+		{TransactionType: transaction.TransactionTypeFee, Currency: "IDR", Count: 1, Amount: 6500, Fees: 6500},
+	}, []*report.FailedCount{
+		{TransactionType: transaction.TransactionTypeTransfer, Count: 2},
+	}, time.Now())
+	mockService.On("DailySummary", "2026-03-01").Return(summary, nil)
+
+	req, _ := http.NewRequest("GET", "/admin/reports/daily?date=2026-03-01", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"fee_revenue":{"IDR":6500}`)
+	assert.Contains(t, w.Body.String(), `"failed_total":2`)
+}
+
+func TestReportHandler_DailySummary_MissingDate(t *testing.T) {
+	mockService := new(MockReportService)
+	router := setupReportRouter(NewReportHandler(mockService))
+
+	req, _ := http.NewRequest("GET", "/admin/reports/daily", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "DailySummary", mock.Anything)
+}
+
+func TestReportHandler_DailySummary_InvalidDate(t *testing.T) {
+	mockService := new(MockReportService)
+	router := setupReportRouter(NewReportHandler(mockService))
+	mockService.On("DailySummary", "yesterday").Return(nil, fmt.Errorf("date must be formatted as YYYY-MM-DD"))
+
+	req, _ := http.NewRequest("GET", "/admin/reports/daily?date=yesterday", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/darisadam/madabank-server/internal/domain/roundup"
+	"github.com/darisadam/madabank-server/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type RoundUpHandler struct {
+	roundUpService service.RoundUpService
+}
+
+func NewRoundUpHandler(roundUpService service.RoundUpService) *RoundUpHandler {
+	return &RoundUpHandler{
+		roundUpService: roundUpService,
+	}
+}
+
+// EnableRoundUp godoc
+// @Summary Turn on round-up savings
+// @Description Round every transfer, withdrawal and merchant payment from the account up to the next 1,000 IDR and move the difference to one of the user's savings accounts. Calling it again changes the savings account.
+// @Tags accounts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Account ID"
+// @Param request body roundup.EnableRequest true "Savings account receiving the round-ups"
+// @Success 200 {object} roundup.Rule
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/accounts/{id}/round-up [put]
+func (h *RoundUpHandler) EnableRoundUp(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	accountID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account ID"})
+		return
+	}
+
+	var req roundup.EnableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := h.roundUpService.Enable(userID.(uuid.UUID), accountID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// GetRoundUp godoc
+// @Summary Get round-up savings
+// @Description Get where the account's round-ups go and how much they have saved so far
+// @Tags accounts
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Account ID"
+// @Success 200 {object} roundup.Rule
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/accounts/{id}/round-up [get]
+func (h *RoundUpHandler) GetRoundUp(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	accountID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account ID"})
+		return
+	}
+
+	rule, err := h.roundUpService.GetRule(userID.(uuid.UUID), accountID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// DisableRoundUp godoc
+// @Summary Turn off round-up savings
+// @Description Stop rounding up the account's debits. Money already saved stays in the savings account.
+// @Tags accounts
+// @Security BearerAuth
+// @Param id path string true "Account ID"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/accounts/{id}/round-up [delete]
+func (h *RoundUpHandler) DisableRoundUp(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	accountID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account ID"})
+		return
+	}
+
+	if err := h.roundUpService.Disable(userID.(uuid.UUID), accountID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
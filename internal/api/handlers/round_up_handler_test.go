@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/darisadam/madabank-server/internal/domain/roundup"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockRoundUpService is a mock implementation of service.RoundUpService
+type MockRoundUpService struct {
+	mock.Mock
+}
+
+func (m *MockRoundUpService) Enable(userID uuid.UUID, accountID uuid.UUID, req *roundup.EnableRequest) (*roundup.Rule, error) {
+	args := m.Called(userID, accountID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*roundup.Rule), args.Error(1)
+}
+
+func (m *MockRoundUpService) GetRule(userID uuid.UUID, accountID uuid.UUID) (*roundup.Rule, error) {
+	args := m.Called(userID, accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*roundup.Rule), args.Error(1)
+}
+
+func (m *MockRoundUpService) Disable(userID uuid.UUID, accountID uuid.UUID) error {
+	args := m.Called(userID, accountID)
+	return args.Error(0)
+}
+
+func setupRoundUpRouter(handler *RoundUpHandler, userID uuid.UUID) *gin.Engine {
+	router := setupCardRouter()
+	group := router.Group("/accounts", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Next()
+	})
+	group.GET("/:id/round-up", handler.GetRoundUp)
+	group.PUT("/:id/round-up", handler.EnableRoundUp)
+	group.DELETE("/:id/round-up", handler.DisableRoundUp)
+	return router
+}
+
+func TestRoundUpHandler_Enable(t *testing.T) {
+	mockService := new(MockRoundUpService)
+	userID, accountID, savingsAccountID := uuid.New(), uuid.New(), uuid.New()
+	mockService.On("Enable", userID, accountID, &roundup.EnableRequest{SavingsAccountID: savingsAccountID.String()}).
+		Return(&roundup.Rule{ID: uuid.New(), AccountID: accountID, SavingsAccountID: savingsAccountID}, nil)
+	router := setupRoundUpRouter(NewRoundUpHandler(mockService), userID)
+
+	w := httptest.NewRecorder()
+	body := fmt.Sprintf(`{"savings_account_id":"%s"}`, savingsAccountID)
+	req, _ := http.NewRequest("PUT", "/accounts/"+accountID.String()+"/round-up", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), savingsAccountID.String())
+}
+
+func TestRoundUpHandler_Enable_MissingSavingsAccount(t *testing.T) {
+	mockService := new(MockRoundUpService)
+	router := setupRoundUpRouter(NewRoundUpHandler(mockService), uuid.New())
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/accounts/"+uuid.New().String()+"/round-up", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "Enable", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRoundUpHandler_Get_NotEnabled(t *testing.T) {
+	mockService := new(MockRoundUpService)
+	userID, accountID := uuid.New(), uuid.New()
+	mockService.On("GetRule", userID, accountID).Return(nil, fmt.Errorf("round-up rule not found"))
+	router := setupRoundUpRouter(NewRoundUpHandler(mockService), userID)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/accounts/"+accountID.String()+"/round-up", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestRoundUpHandler_Disable(t *testing.T) {
+	mockService := new(MockRoundUpService)
+	userID, accountID := uuid.New(), uuid.New()
+	mockService.On("Disable", userID, accountID).Return(nil)
+	router := setupRoundUpRouter(NewRoundUpHandler(mockService), userID)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("DELETE", "/accounts/"+accountID.String()+"/round-up", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/statement"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockStatementService is a mock implementation of service.StatementService
+type MockStatementService struct {
+	mock.Mock
+}
+
+func (m *MockStatementService) Subscribe(userID uuid.UUID, accountID uuid.UUID, req *statement.SubscribeRequest) (*statement.Subscription, error) {
+	args := m.Called(userID, accountID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*statement.Subscription), args.Error(1)
+}
+
+func (m *MockStatementService) GetSubscription(userID uuid.UUID, accountID uuid.UUID) (*statement.Subscription, error) {
+	args := m.Called(userID, accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*statement.Subscription), args.Error(1)
+}
+
+func (m *MockStatementService) Unsubscribe(userID uuid.UUID, accountID uuid.UUID) error {
+	args := m.Called(userID, accountID)
+	return args.Error(0)
+}
+
+func (m *MockStatementService) ListDeliveries(userID uuid.UUID, accountID uuid.UUID) ([]*statement.Delivery, error) {
+	args := m.Called(userID, accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*statement.Delivery), args.Error(1)
+}
+
+func (m *MockStatementService) Resend(userID uuid.UUID, accountID uuid.UUID, deliveryID uuid.UUID) (*statement.Delivery, error) {
+	args := m.Called(userID, accountID, deliveryID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*statement.Delivery), args.Error(1)
+}
+
+func (m *MockStatementService) SendDueStatements(now time.Time) (int, error) {
+	args := m.Called(now)
+	return args.Int(0), args.Error(1)
+}
+
+func setupStatementRouter(handler *StatementHandler, userID uuid.UUID) *gin.Engine {
+	router := setupCardRouter()
+	group := router.Group("/accounts", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Next()
+	})
+	group.PUT("/:id/statement-subscription", handler.Subscribe)
+	group.GET("/:id/statement-subscription", handler.GetSubscription)
+	group.DELETE("/:id/statement-subscription", handler.Unsubscribe)
+	group.GET("/:id/statement-deliveries", handler.ListDeliveries)
+	group.POST("/:id/statement-deliveries/:deliveryId/resend", handler.ResendStatement)
+	return router
+}
+
+func TestStatementHandler_Subscribe(t *testing.T) {
+	mockService := new(MockStatementService)
+	userID, accountID := uuid.New(), uuid.New()
+	mockService.On("Subscribe", userID, accountID, &statement.SubscribeRequest{Email: "finance@example.com"}).
+		Return(&statement.Subscription{ID: uuid.New(), AccountID: accountID, Email: "finance@example.com"}, nil)
+	router := setupStatementRouter(NewStatementHandler(mockService), userID)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/accounts/"+accountID.String()+"/statement-subscription", bytes.NewBufferString(`{"email":"finance@example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"email":"finance@example.com"`)
+}
+
+func TestStatementHandler_Subscribe_InvalidEmail(t *testing.T) {
+	mockService := new(MockStatementService)
+	router := setupStatementRouter(NewStatementHandler(mockService), uuid.New())
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/accounts/"+uuid.New().String()+"/statement-subscription", bytes.NewBufferString(`{"email":"not-an-email"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "Subscribe", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestStatementHandler_Unsubscribe(t *testing.T) {
+	mockService := new(MockStatementService)
+	userID, accountID := uuid.New(), uuid.New()
+	mockService.On("Unsubscribe", userID, accountID).Return(nil)
+	router := setupStatementRouter(NewStatementHandler(mockService), userID)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("DELETE", "/accounts/"+accountID.String()+"/statement-subscription", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestStatementHandler_ListDeliveries(t *testing.T) {
+	mockService := new(MockStatementService)
+	userID, accountID := uuid.New(), uuid.New()
+	mockService.On("ListDeliveries", userID, accountID).Return([]*statement.Delivery{
+		{ID: uuid.New(), AccountID: accountID, Period: "2026-02", Status: statement.DeliveryStatusFailed, Attempts: 3, LastError: "relay unavailable"},
+	}, nil)
+	router := setupStatementRouter(NewStatementHandler(mockService), userID)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/accounts/"+accountID.String()+"/statement-deliveries", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"failed"`)
+}
+
+func TestStatementHandler_ResendStatement(t *testing.T) {
+	mockService := new(MockStatementService)
+	userID, accountID, deliveryID := uuid.New(), uuid.New(), uuid.New()
+	mockService.On("Resend", userID, accountID, deliveryID).Return(&statement.Delivery{ID: deliveryID, Status: statement.DeliveryStatusSent}, nil)
+	router := setupStatementRouter(NewStatementHandler(mockService), userID)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/accounts/"+accountID.String()+"/statement-deliveries/"+deliveryID.String()+"/resend", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"sent"`)
+}
+
+func TestStatementHandler_ResendStatement_SendFails(t *testing.T) {
+	mockService := new(MockStatementService)
+	userID, accountID, deliveryID := uuid.New(), uuid.New(), uuid.New()
+	mockService.On("Resend", userID, accountID, deliveryID).Return(nil, fmt.Errorf("failed to send statement: relay unavailable"))
+	router := setupStatementRouter(NewStatementHandler(mockService), userID)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/accounts/"+accountID.String()+"/statement-deliveries/"+deliveryID.String()+"/resend", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestStatementHandler_ResendStatement_InvalidDeliveryID(t *testing.T) {
+	mockService := new(MockStatementService)
+	router := setupStatementRouter(NewStatementHandler(mockService), uuid.New())
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/accounts/"+uuid.New().String()+"/statement-deliveries/nope/resend", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid delivery ID")
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/darisadam/madabank-server/internal/domain/user"
+	"github.com/darisadam/madabank-server/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TokenHandler struct {
+	tokenService service.TokenService
+}
+
+func NewTokenHandler(tokenService service.TokenService) *TokenHandler {
+	return &TokenHandler{
+		tokenService: tokenService,
+	}
+}
+
+// IssueServiceToken godoc
+// @Summary Issue service token
+// @Description OAuth 2.0 client credentials grant for internal services. The client ID and secret may be sent as form fields or with HTTP Basic authentication. Tokens are short-lived, carry the granted scopes and are not accepted on customer endpoints.
+// @Tags auth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param grant_type formData string true "client_credentials"
+// @Param client_id formData string false "Client ID, unless sent with Basic authentication"
+// @Param client_secret formData string false "Client secret, unless sent with Basic authentication"
+// @Param scope formData string false "Space separated scopes; all of the client's scopes when omitted"
+// @Success 200 {object} user.ServiceTokenResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/auth/service-token [post]
+func (h *TokenHandler) IssueServiceToken(c *gin.Context) {
+	var req user.ServiceTokenRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request"})
+		return
+	}
+	if clientID, clientSecret, ok := c.Request.BasicAuth(); ok {
+		req.ClientID, req.ClientSecret = clientID, clientSecret
+	}
+
+	c.Header("Cache-Control", "no-store")
+	resp, err := h.tokenService.IssueServiceToken(&req)
+	switch {
+	case errors.Is(err, user.ErrInvalidClient):
+		c.Header("WWW-Authenticate", `Basic realm="services"`)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, user.ErrInvalidScope), errors.Is(err, user.ErrUnsupportedGrantType):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to issue token"})
+	default:
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+// Introspect godoc
+// @Summary Introspect access token
+// @Description RFC 7662 style introspection for backend services: reports whether a customer access token is valid and, when it is, its claims. Invalid tokens are answered with 200 and active false. Requires a service token with the tokens.introspect scope.
+// @Tags auth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Security BearerAuth
+// @Param token formData string true "Access token"
+// @Param token_type_hint formData string false "Ignored; only access tokens are supported"
+// @Success 200 {object} user.IntrospectionResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/auth/introspect [post]
+func (h *TokenHandler) Introspect(c *gin.Context) {
+	var req user.IntrospectionRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, h.tokenService.Introspect(req.Token))
+}
+
+// Impersonate godoc
+// @Summary Impersonate a customer
+// @Description Mint a short-lived, read-only token acting as a customer so support can reproduce what they see. The token is flagged with the agent's ID, cannot be refreshed, and both its issue and every request made with it are audited.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param request body user.ImpersonationRequest true "Reason and duration"
+// @Success 201 {object} user.ImpersonationResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/admin/users/{id}/impersonate [post]
+func (h *TokenHandler) Impersonate(c *gin.Context) {
+	agentID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	var req user.ImpersonationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.tokenService.Impersonate(agentID.(uuid.UUID), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusCreated, resp)
+}
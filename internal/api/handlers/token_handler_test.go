@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/darisadam/madabank-server/internal/domain/user"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTokenService is a mock implementation of service.TokenService
+type MockTokenService struct {
+	mock.Mock
+}
+
+func (m *MockTokenService) Introspect(token string) *user.IntrospectionResponse {
+	args := m.Called(token)
+	return args.Get(0).(*user.IntrospectionResponse)
+}
+
+func (m *MockTokenService) IssueServiceToken(req *user.ServiceTokenRequest) (*user.ServiceTokenResponse, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.ServiceTokenResponse), args.Error(1)
+}
+
+func (m *MockTokenService) Impersonate(agentID, userID uuid.UUID, req *user.ImpersonationRequest) (*user.ImpersonationResponse, error) {
+	args := m.Called(agentID, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.ImpersonationResponse), args.Error(1)
+}
+
+func introspectRequest(form url.Values) *http.Request {
+	req, _ := http.NewRequest("POST", "/auth/introspect", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+func TestTokenHandler_Introspect_Active(t *testing.T) {
+	mockService := new(MockTokenService)
+	router := setupCardRouter()
+	router.POST("/auth/introspect", NewTokenHandler(mockService).Introspect)
+
+	userID := uuid.New()
+	mockService.On("Introspect", "access-token").Return(&user.IntrospectionResponse{
+		Active: true, TokenType: "Bearer", Sub: &userID, Username: "budi@example.com", Role: "customer", Exp: 1767225600,
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, introspectRequest(url.Values{"token": {"access-token"}, "token_type_hint": {"access_token"}}))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	assert.Contains(t, w.Body.String(), `"active":true`)
+	assert.Contains(t, w.Body.String(), `"sub":"`+userID.String()+`"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestTokenHandler_Introspect_Inactive(t *testing.T) {
+	mockService := new(MockTokenService)
+	router := setupCardRouter()
+	router.POST("/auth/introspect", NewTokenHandler(mockService).Introspect)
+
+	mockService.On("Introspect", "expired").Return(&user.IntrospectionResponse{Active: false})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, introspectRequest(url.Values{"token": {"expired"}}))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"active":false}`, w.Body.String())
+}
+
+func TestTokenHandler_Introspect_MissingToken(t *testing.T) {
+	mockService := new(MockTokenService)
+	router := setupCardRouter()
+	router.POST("/auth/introspect", NewTokenHandler(mockService).Introspect)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, introspectRequest(url.Values{}))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "Introspect", mock.Anything)
+}
+
+func serviceTokenRequest(form url.Values) *http.Request {
+	req, _ := http.NewRequest("POST", "/auth/service-token", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+func TestTokenHandler_IssueServiceToken_BasicAuth(t *testing.T) {
+	mockService := new(MockTokenService)
+	router := setupCardRouter()
+	router.POST("/auth/service-token", NewTokenHandler(mockService).IssueServiceToken)
+
+	mockService.On("IssueServiceToken", &user.ServiceTokenRequest{
+		GrantType: "client_credentials", ClientID: "fraud-worker", ClientSecret: "s3cret", Scope: "tokens.introspect",
+	}).Return(&user.ServiceTokenResponse{AccessToken: "svc-token", TokenType: "Bearer", ExpiresIn: 900, Scope: "tokens.introspect"}, nil)
+
+	req := serviceTokenRequest(url.Values{"grant_type": {"client_credentials"}, "scope": {"tokens.introspect"}})
+	req.SetBasicAuth("fraud-worker", "s3cret")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	assert.Contains(t, w.Body.String(), `"access_token":"svc-token"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestTokenHandler_IssueServiceToken_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"invalid client", user.ErrInvalidClient, http.StatusUnauthorized},
+		{"invalid scope", user.ErrInvalidScope, http.StatusBadRequest},
+		{"unsupported grant", user.ErrUnsupportedGrantType, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockTokenService)
+			router := setupCardRouter()
+			router.POST("/auth/service-token", NewTokenHandler(mockService).IssueServiceToken)
+			mockService.On("IssueServiceToken", mock.Anything).Return(nil, tt.err)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, serviceTokenRequest(url.Values{
+				"grant_type": {"client_credentials"}, "client_id": {"fraud-worker"}, "client_secret": {"guess"},
+			}))
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.JSONEq(t, `{"error":"`+tt.err.Error()+`"}`, w.Body.String())
+		})
+	}
+}
+
+func setupImpersonationRouter(handler *TokenHandler, agentID uuid.UUID) *gin.Engine {
+	router := setupCardRouter()
+	router.POST("/admin/users/:id/impersonate", func(c *gin.Context) {
+		c.Set("user_id", agentID)
+		c.Next()
+	}, handler.Impersonate)
+	return router
+}
+
+func TestTokenHandler_Impersonate(t *testing.T) {
+	mockService := new(MockTokenService)
+	agentID, userID := uuid.New(), uuid.New()
+	router := setupImpersonationRouter(NewTokenHandler(mockService), agentID)
+
+	mockService.On("Impersonate", agentID, userID, &user.ImpersonationRequest{
+		Reason: "ticket 4821: balance not updating", DurationMinutes: 30,
+	}).Return(&user.ImpersonationResponse{Token: "imp-token", UserID: userID, Scope: "accounts:read"}, nil)
+
+	body := []byte(`{"reason":"ticket 4821: balance not updating","duration_minutes":30}`)
+	req, _ := http.NewRequest("POST", fmt.Sprintf("/admin/users/%s/impersonate", userID), bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	assert.Contains(t, w.Body.String(), `"token":"imp-token"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestTokenHandler_Impersonate_Validation(t *testing.T) {
+	userID := uuid.New()
+	tests := []struct {
+		name string
+		path string
+		body string
+	}{
+		{"invalid user ID", "/admin/users/not-a-uuid/impersonate", `{"reason":"ticket 4821: balance not updating"}`},
+		{"missing reason", "/admin/users/" + userID.String() + "/impersonate", `{}`},
+		{"reason too short", "/admin/users/" + userID.String() + "/impersonate", `{"reason":"help"}`},
+		{"too long", "/admin/users/" + userID.String() + "/impersonate", `{"reason":"ticket 4821: balance not updating","duration_minutes":240}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockTokenService)
+			router := setupImpersonationRouter(NewTokenHandler(mockService), uuid.New())
+
+			req, _ := http.NewRequest("POST", tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			mockService.AssertNotCalled(t, "Impersonate", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
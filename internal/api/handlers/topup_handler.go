@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/darisadam/madabank-server/internal/domain/topup"
+	"github.com/darisadam/madabank-server/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TopupHandler struct {
+	topupService service.TopupService
+}
+
+func NewTopupHandler(topupService service.TopupService) *TopupHandler {
+	return &TopupHandler{
+		topupService: topupService,
+	}
+}
+
+// CreateTopup godoc
+// @Summary Top up phone credit or an e-wallet
+// @Description Debit an account and send phone credit or e-wallet balance to a phone number. Delivery is asynchronous; poll the top-up for its final status.
+// @Tags topups
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body topup.CreateTopupRequest true "Top-up details"
+// @Success 202 {object} topup.Topup
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/topups [post]
+func (h *TopupHandler) CreateTopup(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req topup.CreateTopupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	t, err := h.topupService.CreateTopup(userID.(uuid.UUID), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(err))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, t)
+}
+
+// ListTopups godoc
+// @Summary List top-ups
+// @Description Get the user's most recent top-ups
+// @Tags topups
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} topup.Topup
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/topups [get]
+func (h *TopupHandler) ListTopups(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	topups, err := h.topupService.ListTopups(userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, topups)
+}
+
+// GetTopup godoc
+// @Summary Get top-up status
+// @Description Get a top-up and its fulfillment status
+// @Tags topups
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Top-up ID"
+// @Success 200 {object} topup.Topup
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/topups/{id} [get]
+func (h *TopupHandler) GetTopup(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	topupID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid top-up ID"})
+		return
+	}
+
+	t, err := h.topupService.GetTopup(userID.(uuid.UUID), topupID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, t)
+}
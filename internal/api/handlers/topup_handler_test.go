@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/topup"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTopupService is a mock implementation of service.TopupService
+type MockTopupService struct {
+	mock.Mock
+}
+
+func (m *MockTopupService) CreateTopup(userID uuid.UUID, req *topup.CreateTopupRequest) (*topup.Topup, error) {
+	args := m.Called(userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*topup.Topup), args.Error(1)
+}
+
+func (m *MockTopupService) GetTopup(userID uuid.UUID, topupID uuid.UUID) (*topup.Topup, error) {
+	args := m.Called(userID, topupID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*topup.Topup), args.Error(1)
+}
+
+func (m *MockTopupService) ListTopups(userID uuid.UUID) ([]*topup.Topup, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*topup.Topup), args.Error(1)
+}
+
+func (m *MockTopupService) SyncUnfinished(now time.Time) (int, error) {
+	args := m.Called(now)
+	return args.Int(0), args.Error(1)
+}
+
+func setupTopupRouter(handler *TopupHandler, userID uuid.UUID) *gin.Engine {
+	router := setupCardRouter()
+	group := router.Group("/topups", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Next()
+	})
+	group.POST("", handler.CreateTopup)
+	group.GET("", handler.ListTopups)
+	group.GET("/:id", handler.GetTopup)
+	return router
+}
+
+func TestTopupHandler_CreateTopup(t *testing.T) {
+	mockService := new(MockTopupService)
+	userID := uuid.New()
+	accountID := uuid.New()
+	router := setupTopupRouter(NewTopupHandler(mockService), userID)
+
+	mockService.On("CreateTopup", userID, &topup.CreateTopupRequest{
+		AccountID:      accountID.String(),
+		Type:           "ewallet",
+		Provider:       "gopay",
+		Destination:    "081234567890",
+		Amount:         100000,
+		IdempotencyKey: "topup-1",
+	}).Return(&topup.Topup{ID: uuid.New(), Provider: topup.ProviderGoPay, Status: topup.StatusProcessing}, nil)
+
+	body := []byte(fmt.Sprintf(`{"account_id":"%s","type":"ewallet","provider":"gopay","destination":"081234567890","amount":100000,"idempotency_key":"topup-1"}`, accountID))
+	req, _ := http.NewRequest("POST", "/topups", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"processing"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestTopupHandler_CreateTopup_InvalidType(t *testing.T) {
+	mockService := new(MockTopupService)
+	router := setupTopupRouter(NewTopupHandler(mockService), uuid.New())
+
+	body := []byte(fmt.Sprintf(`{"account_id":"%s","type":"voucher","destination":"081234567890","amount":100000,"idempotency_key":"topup-1"}`, uuid.New()))
+	req, _ := http.NewRequest("POST", "/topups", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "CreateTopup", mock.Anything, mock.Anything)
+}
+
+func TestTopupHandler_GetTopup(t *testing.T) {
+	mockService := new(MockTopupService)
+	userID := uuid.New()
+	topupID := uuid.New()
+	router := setupTopupRouter(NewTopupHandler(mockService), userID)
+
+	mockService.On("GetTopup", userID, topupID).Return(&topup.Topup{ID: topupID, Status: topup.StatusSuccess}, nil)
+
+	req, _ := http.NewRequest("GET", "/topups/"+topupID.String(), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"success"`)
+}
+
+func TestTopupHandler_GetTopup_NotFound(t *testing.T) {
+	mockService := new(MockTopupService)
+	userID := uuid.New()
+	topupID := uuid.New()
+	router := setupTopupRouter(NewTopupHandler(mockService), userID)
+
+	mockService.On("GetTopup", userID, topupID).Return(nil, fmt.Errorf("top-up not found"))
+
+	req, _ := http.NewRequest("GET", "/topups/"+topupID.String(), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestTopupHandler_ListTopups(t *testing.T) {
+	mockService := new(MockTopupService)
+	userID := uuid.New()
+	router := setupTopupRouter(NewTopupHandler(mockService), userID)
+
+	mockService.On("ListTopups", userID).Return([]*topup.Topup{{ID: uuid.New()}, {ID: uuid.New()}}, nil)
+
+	req, _ := http.NewRequest("GET", "/topups", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
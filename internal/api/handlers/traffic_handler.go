@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/darisadam/madabank-server/internal/pkg/ddos"
+	"github.com/darisadam/madabank-server/internal/pkg/ratelimit"
+	"github.com/darisadam/madabank-server/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TrafficHandler struct {
+	trafficService service.TrafficService
+}
+
+func NewTrafficHandler(trafficService service.TrafficService) *TrafficHandler {
+	return &TrafficHandler{
+		trafficService: trafficService,
+	}
+}
+
+// GetDDoSConfig godoc
+// @Summary Get DDoS thresholds
+// @Description Get the thresholds the traffic monitor currently applies
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} ddos.Config
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/admin/ddos/config [get]
+func (h *TrafficHandler) GetDDoSConfig(c *gin.Context) {
+	config, err := h.trafficService.GetDDoSConfig()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, config)
+}
+
+// UpdateDDoSConfig godoc
+// @Summary Tune DDoS thresholds
+// @Description Change some thresholds on every replica without a restart, for example to tighten them during an attack. Omitted fields keep their value.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body ddos.ConfigUpdate true "Thresholds to change"
+// @Success 200 {object} ddos.Config
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/admin/ddos/config [patch]
+func (h *TrafficHandler) UpdateDDoSConfig(c *gin.Context) {
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req ddos.ConfigUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	config, err := h.trafficService.UpdateDDoSConfig(adminID.(uuid.UUID), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, config)
+}
+
+// ResetDDoSConfig godoc
+// @Summary Reset DDoS thresholds
+// @Description Drop runtime changes and return every replica to the thresholds it was started with
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} ddos.Config
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/admin/ddos/config [delete]
+func (h *TrafficHandler) ResetDDoSConfig(c *gin.Context) {
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	config, err := h.trafficService.ResetDDoSConfig(adminID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, config)
+}
+
+// ListBlockedIPs godoc
+// @Summary List blocked IPs
+// @Description Get the IPs under a temporary block, the permanently blocked ranges and the IPs the DDoS monitor currently flags
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} service.IPBlocklist
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/admin/ip-blocks [get]
+func (h *TrafficHandler) ListBlockedIPs(c *gin.Context) {
+	blocklist, err := h.trafficService.ListBlockedIPs()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, blocklist)
+}
+
+// UnblockIP godoc
+// @Summary Unblock an IP
+// @Description Lift a temporary block, such as one after repeated failed logins
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param ip path string true "IP address"
+// @Success 200 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/admin/ip-blocks/{ip} [delete]
+func (h *TrafficHandler) UnblockIP(c *gin.Context) {
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.trafficService.UnblockIP(adminID.(uuid.UUID), c.Param("ip")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "IP unblocked"})
+}
+
+// BlockRange godoc
+// @Summary Block an IP range
+// @Description Block a CIDR range, or a single IP, until it is removed. Allowlisted IPs are not affected.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body ratelimit.CIDRRequest true "Range to block"
+// @Success 201 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/admin/ip-ranges/blocked [post]
+func (h *TrafficHandler) BlockRange(c *gin.Context) {
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req ratelimit.CIDRRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cidr, err := h.trafficService.BlockRange(adminID.(uuid.UUID), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"cidr": cidr})
+}
+
+// UnblockRange godoc
+// @Summary Remove a blocked IP range
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param cidr query string true "CIDR range"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/admin/ip-ranges/blocked [delete]
+func (h *TrafficHandler) UnblockRange(c *gin.Context) {
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.trafficService.UnblockRange(adminID.(uuid.UUID), c.Query("cidr")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "IP range unblocked"})
+}
+
+// GetAllowlist godoc
+// @Summary List allowlisted IP ranges
+// @Description Get the ranges that skip rate limiting and IP blocking
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/admin/ip-ranges/allowed [get]
+func (h *TrafficHandler) GetAllowlist(c *gin.Context) {
+	ranges, err := h.trafficService.GetAllowlist()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ranges)
+}
+
+// AllowRange godoc
+// @Summary Allowlist an IP range
+// @Description Exempt a CIDR range, or a single IP, from rate limiting and IP blocking, for example a partner's egress addresses
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body ratelimit.CIDRRequest true "Range to allow"
+// @Success 201 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/admin/ip-ranges/allowed [post]
+func (h *TrafficHandler) AllowRange(c *gin.Context) {
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req ratelimit.CIDRRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cidr, err := h.trafficService.AllowRange(adminID.(uuid.UUID), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"cidr": cidr})
+}
+
+// DisallowRange godoc
+// @Summary Remove an allowlisted IP range
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param cidr query string true "CIDR range"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/admin/ip-ranges/allowed [delete]
+func (h *TrafficHandler) DisallowRange(c *gin.Context) {
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.trafficService.DisallowRange(adminID.(uuid.UUID), c.Query("cidr")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "IP range removed from allowlist"})
+}
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/darisadam/madabank-server/internal/pkg/ddos"
+	"github.com/darisadam/madabank-server/internal/pkg/ratelimit"
+	"github.com/darisadam/madabank-server/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTrafficService is a mock implementation of service.TrafficService
+type MockTrafficService struct {
+	mock.Mock
+}
+
+func (m *MockTrafficService) GetDDoSConfig() (ddos.Config, error) {
+	args := m.Called()
+	return args.Get(0).(ddos.Config), args.Error(1)
+}
+
+func (m *MockTrafficService) UpdateDDoSConfig(adminID uuid.UUID, req *ddos.ConfigUpdate) (ddos.Config, error) {
+	args := m.Called(adminID, req)
+	return args.Get(0).(ddos.Config), args.Error(1)
+}
+
+func (m *MockTrafficService) ResetDDoSConfig(adminID uuid.UUID) (ddos.Config, error) {
+	args := m.Called(adminID)
+	return args.Get(0).(ddos.Config), args.Error(1)
+}
+
+func (m *MockTrafficService) ListBlockedIPs() (*service.IPBlocklist, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.IPBlocklist), args.Error(1)
+}
+
+func (m *MockTrafficService) UnblockIP(adminID uuid.UUID, ip string) error {
+	args := m.Called(adminID, ip)
+	return args.Error(0)
+}
+
+func (m *MockTrafficService) BlockRange(adminID uuid.UUID, req *ratelimit.CIDRRequest) (string, error) {
+	args := m.Called(adminID, req)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockTrafficService) UnblockRange(adminID uuid.UUID, cidr string) error {
+	args := m.Called(adminID, cidr)
+	return args.Error(0)
+}
+
+func (m *MockTrafficService) GetAllowlist() ([]string, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockTrafficService) AllowRange(adminID uuid.UUID, req *ratelimit.CIDRRequest) (string, error) {
+	args := m.Called(adminID, req)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockTrafficService) DisallowRange(adminID uuid.UUID, cidr string) error {
+	args := m.Called(adminID, cidr)
+	return args.Error(0)
+}
+
+func setupTrafficRouter(handler *TrafficHandler, userID uuid.UUID) *gin.Engine {
+	router := setupCardRouter()
+	ddosGroup := router.Group("/admin/ddos", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Next()
+	})
+	ddosGroup.GET("/config", handler.GetDDoSConfig)
+	ddosGroup.PATCH("/config", handler.UpdateDDoSConfig)
+	ddosGroup.DELETE("/config", handler.ResetDDoSConfig)
+	ipGroup := router.Group("/admin", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Next()
+	})
+	ipGroup.GET("/ip-blocks", handler.ListBlockedIPs)
+	ipGroup.DELETE("/ip-blocks/:ip", handler.UnblockIP)
+	ipGroup.POST("/ip-ranges/blocked", handler.BlockRange)
+	ipGroup.DELETE("/ip-ranges/blocked", handler.UnblockRange)
+	ipGroup.GET("/ip-ranges/allowed", handler.GetAllowlist)
+	ipGroup.POST("/ip-ranges/allowed", handler.AllowRange)
+	ipGroup.DELETE("/ip-ranges/allowed", handler.DisallowRange)
+	return router
+}
+
+func TestTrafficHandler_GetDDoSConfig(t *testing.T) {
+	mockService := new(MockTrafficService)
+	router := setupTrafficRouter(NewTrafficHandler(mockService), uuid.New())
+	mockService.On("GetDDoSConfig").Return(ddos.DefaultConfig, nil)
+
+	req, _ := http.NewRequest("GET", "/admin/ddos/config", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"request_threshold":1000`)
+}
+
+func TestTrafficHandler_UpdateDDoSConfig(t *testing.T) {
+	mockService := new(MockTrafficService)
+	adminID := uuid.New()
+	router := setupTrafficRouter(NewTrafficHandler(mockService), adminID)
+
+	updated := ddos.DefaultConfig
+	updated.RequestThreshold = 200
+	mockService.On("UpdateDDoSConfig", adminID, mock.MatchedBy(func(req *ddos.ConfigUpdate) bool {
+		return req.RequestThreshold != nil && *req.RequestThreshold == 200 && req.WindowSeconds == nil
+	})).Return(updated, nil)
+
+	req, _ := http.NewRequest("PATCH", "/admin/ddos/config", bytes.NewBufferString(`{"request_threshold":200}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"request_threshold":200`)
+}
+
+func TestTrafficHandler_UpdateDDoSConfig_Invalid(t *testing.T) {
+	mockService := new(MockTrafficService)
+	adminID := uuid.New()
+	router := setupTrafficRouter(NewTrafficHandler(mockService), adminID)
+	mockService.On("UpdateDDoSConfig", adminID, mock.Anything).
+		Return(ddos.Config{}, fmt.Errorf("request threshold must be at least 1"))
+
+	req, _ := http.NewRequest("PATCH", "/admin/ddos/config", bytes.NewBufferString(`{"request_threshold":0}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "request threshold must be at least 1")
+}
+
+func TestTrafficHandler_ListBlockedIPs(t *testing.T) {
+	mockService := new(MockTrafficService)
+	router := setupTrafficRouter(NewTrafficHandler(mockService), uuid.New())
+	mockService.On("ListBlockedIPs").Return(&service.IPBlocklist{
+		Blocked:       []ratelimit.BlockedIP{{IP: "198.51.100.7"}},
+		BlockedRanges: []string{"203.0.113.0/24"},
+		Flagged:       []ddos.FlaggedIP{},
+	}, nil)
+
+	req, _ := http.NewRequest("GET", "/admin/ip-blocks", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"ip":"198.51.100.7"`)
+	assert.Contains(t, w.Body.String(), `"blocked_ranges":["203.0.113.0/24"]`)
+}
+
+func TestTrafficHandler_UnblockIP_NotBlocked(t *testing.T) {
+	mockService := new(MockTrafficService)
+	adminID := uuid.New()
+	router := setupTrafficRouter(NewTrafficHandler(mockService), adminID)
+	mockService.On("UnblockIP", adminID, "198.51.100.7").Return(ratelimit.ErrNotBlocked)
+
+	req, _ := http.NewRequest("DELETE", "/admin/ip-blocks/198.51.100.7", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestTrafficHandler_BlockRange(t *testing.T) {
+	mockService := new(MockTrafficService)
+	adminID := uuid.New()
+	router := setupTrafficRouter(NewTrafficHandler(mockService), adminID)
+	mockService.On("BlockRange", adminID, &ratelimit.CIDRRequest{CIDR: "10.20.0.0/8"}).Return("10.0.0.0/8", nil)
+
+	req, _ := http.NewRequest("POST", "/admin/ip-ranges/blocked", bytes.NewBufferString(`{"cidr":"10.20.0.0/8"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"cidr":"10.0.0.0/8"`)
+}
+
+func TestTrafficHandler_BlockRange_MissingCIDR(t *testing.T) {
+	mockService := new(MockTrafficService)
+	router := setupTrafficRouter(NewTrafficHandler(mockService), uuid.New())
+
+	req, _ := http.NewRequest("POST", "/admin/ip-ranges/blocked", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "BlockRange", mock.Anything, mock.Anything)
+}
+
+func TestTrafficHandler_DisallowRange(t *testing.T) {
+	mockService := new(MockTrafficService)
+	adminID := uuid.New()
+	router := setupTrafficRouter(NewTrafficHandler(mockService), adminID)
+	mockService.On("DisallowRange", adminID, "192.0.2.0/24").Return(nil)
+
+	req, _ := http.NewRequest("DELETE", "/admin/ip-ranges/allowed?cidr=192.0.2.0%2F24", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
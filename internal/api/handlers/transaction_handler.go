@@ -44,10 +44,11 @@ func (h *TransactionHandler) Transfer(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	req.Country = c.GetString("country")
 
 	txn, err := h.transactionService.Transfer(userID, &req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, errorBody(err))
 		return
 	}
 
@@ -82,7 +83,7 @@ func (h *TransactionHandler) Deposit(c *gin.Context) {
 
 	txn, err := h.transactionService.Deposit(userID, &req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, errorBody(err))
 		return
 	}
 
@@ -117,7 +118,7 @@ func (h *TransactionHandler) Withdraw(c *gin.Context) {
 
 	txn, err := h.transactionService.Withdrawal(userID, &req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, errorBody(err))
 		return
 	}
 
@@ -163,6 +164,85 @@ func (h *TransactionHandler) GetHistory(c *gin.Context) {
 	c.JSON(http.StatusOK, history)
 }
 
+// SearchTransactions godoc
+// @Summary Search transaction history
+// @Description Search the user's transactions by text in the description, the counterparty's name or account number, and metadata such as biller or merchant names. Matches tolerate typos and partial words. Results are best match first, or newest first without a query.
+// @Tags transactions
+// @Produce json
+// @Security BearerAuth
+// @Param q query string false "Search text"
+// @Param account_id query string false "Account ID, all of the user's accounts when empty"
+// @Param min_amount query number false "Minimum amount"
+// @Param max_amount query number false "Maximum amount"
+// @Param status query string false "Status (pending, completed, failed, reversed)"
+// @Param type query string false "Transaction type"
+// @Param start_date query string false "Start date (YYYY-MM-DD)"
+// @Param end_date query string false "End date, inclusive (YYYY-MM-DD)"
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} transaction.TransactionHistoryResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/transactions/search [get]
+func (h *TransactionHandler) SearchTransactions(c *gin.Context) {
+	val, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	userID := val.(uuid.UUID)
+
+	var req transaction.SearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	results, err := h.transactionService.SearchTransactions(userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// GetArchivedHistory godoc
+// @Summary Get archived transaction history
+// @Description Get an account's transactions from months moved to cold storage. The range may span at most 92 days.
+// @Tags transactions
+// @Produce json
+// @Security BearerAuth
+// @Param account_id query string true "Account ID"
+// @Param start_date query string true "Start date (YYYY-MM-DD)"
+// @Param end_date query string true "End date (YYYY-MM-DD)"
+// @Success 200 {object} transaction.TransactionHistoryResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/transactions/archived [get]
+func (h *TransactionHandler) GetArchivedHistory(c *gin.Context) {
+	val, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	userID := val.(uuid.UUID)
+
+	var req transaction.ArchivedHistoryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	history, err := h.transactionService.GetArchivedHistory(userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
 // GetTransaction godoc
 // @Summary Get transaction details
 // @Description Get details of a specific transaction
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/domain/validation"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -50,6 +51,22 @@ func (m *MockTransactionService) GetTransactionHistory(userID uuid.UUID, req *tr
 	return args.Get(0).(*transaction.TransactionHistoryResponse), args.Error(1)
 }
 
+func (m *MockTransactionService) GetArchivedHistory(userID uuid.UUID, req *transaction.ArchivedHistoryRequest) (*transaction.TransactionHistoryResponse, error) {
+	args := m.Called(userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*transaction.TransactionHistoryResponse), args.Error(1)
+}
+
+func (m *MockTransactionService) SearchTransactions(userID uuid.UUID, req *transaction.SearchRequest) (*transaction.TransactionHistoryResponse, error) {
+	args := m.Called(userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*transaction.TransactionHistoryResponse), args.Error(1)
+}
+
 func (m *MockTransactionService) GetTransaction(userID uuid.UUID, transactionID uuid.UUID) (*transaction.Transaction, error) {
 	args := m.Called(userID, transactionID)
 	if args.Get(0) == nil {
@@ -130,6 +147,35 @@ func TestTransactionHandler_Transfer_Error(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestTransactionHandler_Transfer_ValidationError(t *testing.T) {
+	mockService := new(MockTransactionService)
+	handler := NewTransactionHandler(mockService)
+
+	router := setupTransactionRouter()
+	userID := uuid.New()
+
+	router.POST("/transfer", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		handler.Transfer(c)
+	})
+
+	mockService.On("Transfer", userID, mock.AnythingOfType("*transaction.TransferRequest")).
+		Return(nil, validation.New().Amount("amount", 10.005).Err())
+
+	reqBody := `{"from_account_id":"` + uuid.New().String() + `","to_account_id":"` + uuid.New().String() + `","amount":10.005,"idempotency_key":"test-key"}`
+	req, _ := http.NewRequest("POST", "/transfer", bytes.NewBufferString(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{
+		"error": "amount must have at most 2 decimal places",
+		"fields": [{"field": "amount", "code": "too_precise", "message": "must have at most 2 decimal places"}]
+	}`, w.Body.String())
+}
+
 func TestTransactionHandler_Transfer_Unauthorized(t *testing.T) {
 	mockService := new(MockTransactionService)
 	handler := NewTransactionHandler(mockService)
@@ -275,6 +321,100 @@ func TestTransactionHandler_GetHistory_Success(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestTransactionHandler_GetArchivedHistory_Success(t *testing.T) {
+	mockService := new(MockTransactionService)
+	handler := NewTransactionHandler(mockService)
+
+	router := setupTransactionRouter()
+	userID := uuid.New()
+	accountID := uuid.New()
+
+	router.GET("/transactions/archived", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		handler.GetArchivedHistory(c)
+	})
+
+	mockService.On("GetArchivedHistory", userID, &transaction.ArchivedHistoryRequest{
+		AccountID: accountID.String(), StartDate: "2023-01-01", EndDate: "2023-01-31",
+	}).Return(&transaction.TransactionHistoryResponse{
+		Transactions: []transaction.TransactionResponse{{ID: uuid.New(), Amount: 100000}},
+		Total:        1,
+		Limit:        1,
+	}, nil)
+
+	req, _ := http.NewRequest("GET", "/transactions/archived?account_id="+accountID.String()+"&start_date=2023-01-01&end_date=2023-01-31", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestTransactionHandler_GetArchivedHistory_MissingRange(t *testing.T) {
+	mockService := new(MockTransactionService)
+	handler := NewTransactionHandler(mockService)
+
+	router := setupTransactionRouter()
+	router.GET("/transactions/archived", func(c *gin.Context) {
+		c.Set("user_id", uuid.New())
+		handler.GetArchivedHistory(c)
+	})
+
+	req, _ := http.NewRequest("GET", "/transactions/archived?account_id="+uuid.New().String(), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "GetArchivedHistory", mock.Anything, mock.Anything)
+}
+
+func TestTransactionHandler_SearchTransactions_Success(t *testing.T) {
+	mockService := new(MockTransactionService)
+	handler := NewTransactionHandler(mockService)
+
+	router := setupTransactionRouter()
+	userID := uuid.New()
+
+	router.GET("/transactions/search", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		handler.SearchTransactions(c)
+	})
+
+	mockService.On("SearchTransactions", userID, &transaction.SearchRequest{
+		Query: "bob", MaxAmount: 500000, Status: "completed", StartDate: "2026-03-01", EndDate: "2026-03-31",
+	}).Return(&transaction.TransactionHistoryResponse{
+		Transactions: []transaction.TransactionResponse{{ID: uuid.New(), Amount: 250000, Description: "Rent split with Bob"}},
+		Total:        1,
+		Limit:        20,
+	}, nil)
+
+	req, _ := http.NewRequest("GET", "/transactions/search?q=bob&max_amount=500000&status=completed&start_date=2026-03-01&end_date=2026-03-31", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Rent split with Bob")
+	mockService.AssertExpectations(t)
+}
+
+func TestTransactionHandler_SearchTransactions_InvalidStatus(t *testing.T) {
+	mockService := new(MockTransactionService)
+	handler := NewTransactionHandler(mockService)
+
+	router := setupTransactionRouter()
+	router.GET("/transactions/search", func(c *gin.Context) {
+		c.Set("user_id", uuid.New())
+		handler.SearchTransactions(c)
+	})
+
+	req, _ := http.NewRequest("GET", "/transactions/search?q=bob&status=settled", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "SearchTransactions", mock.Anything, mock.Anything)
+}
+
 // ==================== GetTransaction Tests ====================
 
 func TestTransactionHandler_GetTransaction_Success(t *testing.T) {
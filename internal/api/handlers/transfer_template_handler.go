@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TransferTemplateHandler struct {
+	templateService service.TransferTemplateService
+}
+
+func NewTransferTemplateHandler(templateService service.TransferTemplateService) *TransferTemplateHandler {
+	return &TransferTemplateHandler{
+		templateService: templateService,
+	}
+}
+
+// CreateTemplate godoc
+// @Summary Save a transfer template
+// @Description Save a transfer (source, destination account or beneficiary, amount, description) to repeat later with one call
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body transaction.CreateTemplateRequest true "Template details"
+// @Success 201 {object} transaction.Template
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/transactions/templates [post]
+func (h *TransferTemplateHandler) CreateTemplate(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req transaction.CreateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	t, err := h.templateService.CreateTemplate(userID.(uuid.UUID), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, t)
+}
+
+// ListTemplates godoc
+// @Summary List transfer templates
+// @Description Get the user's saved transfers, most recently used first
+// @Tags transactions
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} transaction.Template
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/transactions/templates [get]
+func (h *TransferTemplateHandler) ListTemplates(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	templates, err := h.templateService.ListTemplates(userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, templates)
+}
+
+// GetTemplate godoc
+// @Summary Get transfer template
+// @Description Get a saved transfer
+// @Tags transactions
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Template ID"
+// @Success 200 {object} transaction.Template
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/transactions/templates/{id} [get]
+func (h *TransferTemplateHandler) GetTemplate(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	templateID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid template ID"})
+		return
+	}
+
+	t, err := h.templateService.GetTemplate(userID.(uuid.UUID), templateID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, t)
+}
+
+// UpdateTemplate godoc
+// @Summary Update transfer template
+// @Description Change a saved transfer's name, amount or description
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Template ID"
+// @Param request body transaction.UpdateTemplateRequest true "Fields to change"
+// @Success 200 {object} transaction.Template
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/transactions/templates/{id} [patch]
+func (h *TransferTemplateHandler) UpdateTemplate(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	templateID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid template ID"})
+		return
+	}
+
+	var req transaction.UpdateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	t, err := h.templateService.UpdateTemplate(userID.(uuid.UUID), templateID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, t)
+}
+
+// DeleteTemplate godoc
+// @Summary Delete transfer template
+// @Description Remove a saved transfer
+// @Tags transactions
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Template ID"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/transactions/templates/{id} [delete]
+func (h *TransferTemplateHandler) DeleteTemplate(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	templateID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid template ID"})
+		return
+	}
+
+	if err := h.templateService.DeleteTemplate(userID.(uuid.UUID), templateID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// ExecuteTemplate godoc
+// @Summary Execute transfer template
+// @Description Run a saved transfer with a fresh idempotency key, optionally overriding the amount
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Template ID"
+// @Param request body transaction.ExecuteTemplateRequest true "Execution details"
+// @Success 201 {object} transaction.Transaction
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/transactions/templates/{id}/execute [post]
+func (h *TransferTemplateHandler) ExecuteTemplate(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	templateID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid template ID"})
+		return
+	}
+
+	var req transaction.ExecuteTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Country = c.GetString("country")
+
+	txn, err := h.templateService.ExecuteTemplate(userID.(uuid.UUID), templateID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, txn)
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTransferTemplateService is a mock implementation of service.TransferTemplateService
+type MockTransferTemplateService struct {
+	mock.Mock
+}
+
+func (m *MockTransferTemplateService) CreateTemplate(userID uuid.UUID, req *transaction.CreateTemplateRequest) (*transaction.Template, error) {
+	args := m.Called(userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*transaction.Template), args.Error(1)
+}
+
+func (m *MockTransferTemplateService) ListTemplates(userID uuid.UUID) ([]*transaction.Template, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*transaction.Template), args.Error(1)
+}
+
+func (m *MockTransferTemplateService) GetTemplate(userID uuid.UUID, id uuid.UUID) (*transaction.Template, error) {
+	args := m.Called(userID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*transaction.Template), args.Error(1)
+}
+
+func (m *MockTransferTemplateService) UpdateTemplate(userID uuid.UUID, id uuid.UUID, req *transaction.UpdateTemplateRequest) (*transaction.Template, error) {
+	args := m.Called(userID, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*transaction.Template), args.Error(1)
+}
+
+func (m *MockTransferTemplateService) DeleteTemplate(userID uuid.UUID, id uuid.UUID) error {
+	args := m.Called(userID, id)
+	return args.Error(0)
+}
+
+func (m *MockTransferTemplateService) ExecuteTemplate(userID uuid.UUID, id uuid.UUID, req *transaction.ExecuteTemplateRequest) (*transaction.Transaction, error) {
+	args := m.Called(userID, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*transaction.Transaction), args.Error(1)
+}
+
+func setupTransferTemplateRouter(handler *TransferTemplateHandler, userID uuid.UUID) *gin.Engine {
+	router := setupCardRouter()
+	group := router.Group("/transactions/templates", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Next()
+	})
+	group.POST("", handler.CreateTemplate)
+	group.GET("", handler.ListTemplates)
+	group.GET("/:id", handler.GetTemplate)
+	group.PATCH("/:id", handler.UpdateTemplate)
+	group.DELETE("/:id", handler.DeleteTemplate)
+	group.POST("/:id/execute", handler.ExecuteTemplate)
+	return router
+}
+
+func TestTransferTemplateHandler_CreateTemplate_RequiresDestination(t *testing.T) {
+	mockService := new(MockTransferTemplateService)
+	router := setupTransferTemplateRouter(NewTransferTemplateHandler(mockService), uuid.New())
+
+	body := fmt.Sprintf(`{"name":"Rent","from_account_id":"%s","amount":1500000}`, uuid.New())
+	req, _ := http.NewRequest("POST", "/transactions/templates", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "CreateTemplate", mock.Anything, mock.Anything)
+}
+
+func TestTransferTemplateHandler_CreateTemplate_RejectsBothDestinations(t *testing.T) {
+	mockService := new(MockTransferTemplateService)
+	router := setupTransferTemplateRouter(NewTransferTemplateHandler(mockService), uuid.New())
+
+	body := fmt.Sprintf(`{"name":"Rent","from_account_id":"%s","to_account_id":"%s","beneficiary_id":"%s","amount":1500000}`,
+		uuid.New(), uuid.New(), uuid.New())
+	req, _ := http.NewRequest("POST", "/transactions/templates", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestTransferTemplateHandler_ExecuteTemplate(t *testing.T) {
+	mockService := new(MockTransferTemplateService)
+	userID := uuid.New()
+	id := uuid.New()
+	router := setupTransferTemplateRouter(NewTransferTemplateHandler(mockService), userID)
+
+	mockService.On("ExecuteTemplate", userID, id, &transaction.ExecuteTemplateRequest{IdempotencyKey: "rent-march"}).
+		Return(&transaction.Transaction{ID: uuid.New(), Status: transaction.TransactionStatusCompleted}, nil)
+
+	req, _ := http.NewRequest("POST", "/transactions/templates/"+id.String()+"/execute", bytes.NewBufferString(`{"idempotency_key":"rent-march"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"completed"`)
+}
+
+func TestTransferTemplateHandler_ExecuteTemplate_RequiresIdempotencyKey(t *testing.T) {
+	mockService := new(MockTransferTemplateService)
+	router := setupTransferTemplateRouter(NewTransferTemplateHandler(mockService), uuid.New())
+
+	req, _ := http.NewRequest("POST", "/transactions/templates/"+uuid.New().String()+"/execute", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "ExecuteTemplate", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTransferTemplateHandler_GetTemplate_NotFound(t *testing.T) {
+	mockService := new(MockTransferTemplateService)
+	userID := uuid.New()
+	id := uuid.New()
+	router := setupTransferTemplateRouter(NewTransferTemplateHandler(mockService), userID)
+	mockService.On("GetTemplate", userID, id).Return(nil, fmt.Errorf("transfer template not found"))
+
+	req, _ := http.NewRequest("GET", "/transactions/templates/"+id.String(), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
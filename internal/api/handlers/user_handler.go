@@ -1,6 +1,9 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
 
 	"github.com/darisadam/madabank-server/internal/domain/user"
@@ -48,14 +51,16 @@ func (h *UserHandler) Register(c *gin.Context) {
 
 // Login godoc
 // @Summary Login user
-// @Description Authenticate user and return JWT token
+// @Description Authenticate user and return JWT token. When the GeoIP policy challenges the client's country, or the login comes from a new device or country and codes are required for those, the first attempt sends a code and fails with step_up_required; repeat it with the code in otp. The app identifies its install with X-Device-ID. Repeated failures on an account delay further attempts (429 with Retry-After) and then require a code.
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param request body user.LoginRequest true "Login credentials"
+// @Param X-Device-ID header string false "App install ID"
 // @Success 200 {object} user.LoginResponse
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
+// @Failure 429 {object} map[string]string
 // @Router /api/v1/auth/login [post]
 func (h *UserHandler) Login(c *gin.Context) {
 	var req user.LoginRequest
@@ -64,7 +69,25 @@ func (h *UserHandler) Login(c *gin.Context) {
 		return
 	}
 
+	req.StepUp = c.GetBool("step_up_required")
+	req.Origin = user.LoginOrigin{
+		DeviceID:  c.GetHeader("X-Device-ID"),
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+		Country:   c.GetString("country"),
+	}
+
 	response, err := h.userService.Login(&req)
+	if errors.Is(err, user.ErrStepUpRequired) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error(), "step_up_required": true})
+		return
+	}
+	var throttled *user.LoginThrottledError
+	if errors.As(err, &throttled) {
+		c.Header("Retry-After", fmt.Sprintf("%d", int(math.Ceil(throttled.RetryAfter.Seconds()))))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
@@ -189,7 +212,7 @@ func (h *UserHandler) RefreshToken(c *gin.Context) {
 
 // ForgotPassword godoc
 // @Summary Request password reset OTP
-// @Description Sends a 6-digit OTP to the user's email (Mocked in logs)
+// @Description Sends a 6-digit OTP by email or SMS. Without a channel the code is texted when the profile has a verified phone number, and emailed otherwise. Each channel can be used once every 15 minutes.
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -204,12 +227,13 @@ func (h *UserHandler) ForgotPassword(c *gin.Context) {
 		return
 	}
 
-	if err := h.userService.ForgotPassword(&req); err != nil {
+	channel, err := h.userService.ForgotPassword(&req)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "If this email exists, an OTP has been sent."})
+	c.JSON(http.StatusOK, gin.H{"message": "If this email exists, an OTP has been sent.", "channel": channel})
 }
 
 // ResetPassword godoc
@@ -236,3 +260,332 @@ func (h *UserHandler) ResetPassword(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Password reset successfully"})
 }
+
+// ResetPasswordWithLink godoc
+// @Summary Reset password with a link
+// @Description Reset the password with the token of the link emailed by /auth/forgot-password. The link works once, expires after 15 minutes and stops working once the OTP is used, and the other way round.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body user.ResetPasswordLinkRequest true "Link token and new password"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/auth/reset-password/link [post]
+func (h *UserHandler) ResetPasswordWithLink(c *gin.Context) {
+	var req user.ResetPasswordLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.userService.ResetPasswordWithLink(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password reset successfully"})
+}
+
+// ChangePassword godoc
+// @Summary Change password
+// @Description Replace the password after checking the current one. The new password must meet the password policy. Every session is signed out: refresh tokens stop working, issued access tokens run until they expire.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body user.ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/users/password [put]
+func (h *UserHandler) ChangePassword(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req user.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.userService.ChangePassword(userID.(uuid.UUID), &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed successfully"})
+}
+
+// RequestEmailChange godoc
+// @Summary Request an email change
+// @Description Sends a 6-digit code to the new address after checking the password. The email stays unchanged until the code is confirmed.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body user.EmailChangeRequest true "New email and current password"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/users/email/change [post]
+func (h *UserHandler) RequestEmailChange(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req user.EmailChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.userService.RequestEmailChange(userID.(uuid.UUID), &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "A confirmation code has been sent to the new address."})
+}
+
+// ConfirmEmailChange godoc
+// @Summary Confirm an email change
+// @Description Switch to the new address with the code sent to it. The old address is notified and every session is signed out.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body user.ConfirmEmailChangeRequest true "New email and code"
+// @Success 200 {object} user.User
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/users/email/confirm [post]
+func (h *UserHandler) ConfirmEmailChange(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req user.ConfirmEmailChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	u, err := h.userService.ConfirmEmailChange(userID.(uuid.UUID), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, u)
+}
+
+// RequestPhoneVerification godoc
+// @Summary Request phone verification code
+// @Description Texts a 6-digit code to the phone number on the profile. The number can only be used to log in and receive payments once verified.
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/users/phone/verification [post]
+func (h *UserHandler) RequestPhoneVerification(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.userService.RequestPhoneVerification(userID.(uuid.UUID)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "A verification code has been sent to your phone."})
+}
+
+// VerifyPhone godoc
+// @Summary Verify phone number
+// @Description Confirm the phone number on the profile with the code from /users/phone/verification
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body user.VerifyPhoneRequest true "Verification code"
+// @Success 200 {object} user.User
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/users/phone/verify [post]
+func (h *UserHandler) VerifyPhone(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req user.VerifyPhoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	u, err := h.userService.VerifyPhone(userID.(uuid.UUID), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, u)
+}
+
+// RequestStepUp godoc
+// @Summary Request a step-up code
+// @Description Sends a 6-digit code, by SMS when the phone number is verified and by email otherwise. Transfers from a country the GeoIP policy challenges need it in the X-Step-Up-Code header; each code confirms one transfer.
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/users/step-up [post]
+func (h *UserHandler) RequestStepUp(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	channel, err := h.userService.RequestStepUp(userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "A verification code has been sent.", "channel": channel})
+}
+
+// ResolveHandle godoc
+// @Summary Resolve a payment handle
+// @Description Look up the customer behind an @handle before sending a transfer with to_handle. Only the handle and a shortened name are returned, never the account number or contact details.
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param handle path string true "Handle, with or without the leading @"
+// @Success 200 {object} user.HandleResolution
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/users/handles/{handle} [get]
+func (h *UserHandler) ResolveHandle(c *gin.Context) {
+	if _, exists := c.Get("user_id"); !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	res, err := h.userService.ResolveHandle(c.Param("handle"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// RequestReactivation godoc
+// @Summary Request profile reactivation OTP
+// @Description Sends a 6-digit OTP to the email of a deleted or deactivated profile, after checking its password. Deleted profiles can be restored for 30 days.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body user.ReactivationOTPRequest true "Email and password"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/users/reactivate/otp [post]
+func (h *UserHandler) RequestReactivation(c *gin.Context) {
+	var req user.ReactivationOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.userService.RequestReactivation(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "An OTP has been sent to your email."})
+}
+
+// Reactivate godoc
+// @Summary Reactivate a deleted profile
+// @Description Restore a deleted or deactivated profile with its password and the OTP from /users/reactivate/otp. Accounts frozen by the deletion are unfrozen.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body user.ReactivateRequest true "Email, password and OTP"
+// @Success 200 {object} user.User
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/users/reactivate [post]
+func (h *UserHandler) Reactivate(c *gin.Context) {
+	var req user.ReactivateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	u, err := h.userService.Reactivate(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, u)
+}
+
+// ListUsers godoc
+// @Summary Search users
+// @Description Search users for support by email, phone, KYC status, active flag and registration date. Users come newest first; pass next_cursor as cursor for the following page.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param email query string false "Part of the email address, case-insensitive"
+// @Param phone query string false "Part of the phone number"
+// @Param kyc_status query string false "KYC status (pending, verified, rejected)"
+// @Param is_active query bool false "Active flag"
+// @Param created_from query string false "Registered on or after (YYYY-MM-DD)"
+// @Param created_to query string false "Registered on or before (YYYY-MM-DD)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Success 200 {object} user.UserListResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/admin/users [get]
+func (h *UserHandler) ListUsers(c *gin.Context) {
+	var req user.ListUsersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	f, err := req.Filter()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.userService.ListUsers(f)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list users"})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
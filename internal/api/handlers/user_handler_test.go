@@ -3,9 +3,11 @@ package handlers
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/darisadam/madabank-server/internal/domain/user"
 	"github.com/gin-gonic/gin"
@@ -64,9 +66,9 @@ func (m *MockUserService) RefreshToken(token string) (*user.LoginResponse, error
 	return args.Get(0).(*user.LoginResponse), args.Error(1)
 }
 
-func (m *MockUserService) ForgotPassword(req *user.ForgotPasswordRequest) error {
+func (m *MockUserService) ForgotPassword(req *user.ForgotPasswordRequest) (user.OTPChannel, error) {
 	args := m.Called(req)
-	return args.Error(0)
+	return args.Get(0).(user.OTPChannel), args.Error(1)
 }
 
 func (m *MockUserService) ResetPassword(req *user.ResetPasswordRequest) error {
@@ -74,6 +76,81 @@ func (m *MockUserService) ResetPassword(req *user.ResetPasswordRequest) error {
 	return args.Error(0)
 }
 
+func (m *MockUserService) ResetPasswordWithLink(req *user.ResetPasswordLinkRequest) error {
+	args := m.Called(req)
+	return args.Error(0)
+}
+
+func (m *MockUserService) ChangePassword(userID uuid.UUID, req *user.ChangePasswordRequest) error {
+	args := m.Called(userID, req)
+	return args.Error(0)
+}
+
+func (m *MockUserService) ResolveHandle(handle string) (*user.HandleResolution, error) {
+	args := m.Called(handle)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.HandleResolution), args.Error(1)
+}
+
+func (m *MockUserService) RequestEmailChange(userID uuid.UUID, req *user.EmailChangeRequest) error {
+	args := m.Called(userID, req)
+	return args.Error(0)
+}
+
+func (m *MockUserService) ConfirmEmailChange(userID uuid.UUID, req *user.ConfirmEmailChangeRequest) (*user.User, error) {
+	args := m.Called(userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.User), args.Error(1)
+}
+
+func (m *MockUserService) RequestPhoneVerification(userID uuid.UUID) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockUserService) VerifyPhone(userID uuid.UUID, req *user.VerifyPhoneRequest) (*user.User, error) {
+	args := m.Called(userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.User), args.Error(1)
+}
+
+func (m *MockUserService) RequestStepUp(userID uuid.UUID) (user.OTPChannel, error) {
+	args := m.Called(userID)
+	return args.Get(0).(user.OTPChannel), args.Error(1)
+}
+
+func (m *MockUserService) VerifyStepUp(userID uuid.UUID, otp string) error {
+	args := m.Called(userID, otp)
+	return args.Error(0)
+}
+
+func (m *MockUserService) RequestReactivation(req *user.ReactivationOTPRequest) error {
+	args := m.Called(req)
+	return args.Error(0)
+}
+
+func (m *MockUserService) Reactivate(req *user.ReactivateRequest) (*user.User, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.User), args.Error(1)
+}
+
+func (m *MockUserService) ListUsers(f *user.ListFilter) (*user.UserListResponse, error) {
+	args := m.Called(f)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.UserListResponse), args.Error(1)
+}
+
 func setupRouter() *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	return gin.New()
@@ -200,6 +277,101 @@ func TestUserHandler_Login_InvalidCredentials(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestUserHandler_Login_StepUpRequired(t *testing.T) {
+	mockService := new(MockUserService)
+	handler := NewUserHandler(mockService)
+
+	router := setupRouter()
+	router.POST("/login", func(c *gin.Context) {
+		c.Set("step_up_required", true)
+		c.Next()
+	}, handler.Login)
+
+	mockService.On("Login", mock.MatchedBy(func(req *user.LoginRequest) bool { return req.StepUp })).
+		Return(nil, user.ErrStepUpRequired)
+
+	reqBody := `{"email":"test@example.com","password":"password123"}`
+	req, _ := http.NewRequest("POST", "/login", bytes.NewBufferString(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), `"step_up_required":true`)
+	mockService.AssertExpectations(t)
+}
+
+func TestUserHandler_Login_Throttled(t *testing.T) {
+	mockService := new(MockUserService)
+	handler := NewUserHandler(mockService)
+
+	router := setupRouter()
+	router.POST("/login", handler.Login)
+
+	mockService.On("Login", mock.Anything).Return(nil, &user.LoginThrottledError{RetryAfter: 3500 * time.Millisecond})
+
+	reqBody := `{"email":"test@example.com","password":"password123"}`
+	req, _ := http.NewRequest("POST", "/login", bytes.NewBufferString(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "4", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), "too many failed login attempts")
+}
+
+func TestUserHandler_Login_PassesOrigin(t *testing.T) {
+	mockService := new(MockUserService)
+	handler := NewUserHandler(mockService)
+
+	router := setupRouter()
+	router.POST("/login", func(c *gin.Context) {
+		c.Set("country", "SG")
+		c.Next()
+	}, handler.Login)
+
+	mockService.On("Login", mock.MatchedBy(func(req *user.LoginRequest) bool {
+		return req.Origin.DeviceID == "install-1" && req.Origin.UserAgent == "MadaBank/3.2.0" && req.Origin.Country == "SG"
+	})).Return(&user.LoginResponse{Token: "token"}, nil)
+
+	reqBody := `{"email":"test@example.com","password":"password123"}`
+	req, _ := http.NewRequest("POST", "/login", bytes.NewBufferString(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "MadaBank/3.2.0")
+	req.Header.Set("X-Device-ID", "install-1")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestUserHandler_RequestStepUp(t *testing.T) {
+	mockService := new(MockUserService)
+	handler := NewUserHandler(mockService)
+	userID := uuid.New()
+
+	router := setupRouter()
+	router.POST("/users/step-up", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Next()
+	}, handler.RequestStepUp)
+
+	mockService.On("RequestStepUp", userID).Return(user.OTPChannelSMS, nil)
+
+	req, _ := http.NewRequest("POST", "/users/step-up", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"channel":"sms"`)
+	mockService.AssertExpectations(t)
+}
+
 func TestUserHandler_Login_InvalidRequest(t *testing.T) {
 	mockService := new(MockUserService)
 	handler := NewUserHandler(mockService)
@@ -430,7 +602,7 @@ func TestUserHandler_ForgotPassword_Success(t *testing.T) {
 	router := setupRouter()
 	router.POST("/forgot-password", handler.ForgotPassword)
 
-	mockService.On("ForgotPassword", mock.AnythingOfType("*user.ForgotPasswordRequest")).Return(nil)
+	mockService.On("ForgotPassword", mock.AnythingOfType("*user.ForgotPasswordRequest")).Return(user.OTPChannelSMS, nil)
 
 	reqBody := `{"email":"test@example.com"}`
 	req, _ := http.NewRequest("POST", "/forgot-password", bytes.NewBufferString(reqBody))
@@ -440,9 +612,26 @@ func TestUserHandler_ForgotPassword_Success(t *testing.T) {
 	router.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"channel":"sms"`)
 	mockService.AssertExpectations(t)
 }
 
+func TestUserHandler_ForgotPassword_UnknownChannel(t *testing.T) {
+	mockService := new(MockUserService)
+	handler := NewUserHandler(mockService)
+
+	router := setupRouter()
+	router.POST("/forgot-password", handler.ForgotPassword)
+
+	req, _ := http.NewRequest("POST", "/forgot-password", bytes.NewBufferString(`{"email":"test@example.com","channel":"pigeon"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "ForgotPassword", mock.Anything)
+}
+
 func TestUserHandler_ForgotPassword_InvalidRequest(t *testing.T) {
 	mockService := new(MockUserService)
 	handler := NewUserHandler(mockService)
@@ -501,3 +690,285 @@ func TestUserHandler_ResetPassword_InvalidOTP(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 	mockService.AssertExpectations(t)
 }
+
+// ==================== Admin User Search Tests ====================
+
+func TestUserHandler_ListUsers_Success(t *testing.T) {
+	mockService := new(MockUserService)
+	handler := NewUserHandler(mockService)
+
+	router := setupRouter()
+	router.GET("/admin/users", handler.ListUsers)
+
+	mockService.On("ListUsers", mock.MatchedBy(func(f *user.ListFilter) bool {
+		return f.Email == "budi" && f.KYCStatus == "pending" && f.IsActive != nil && !*f.IsActive && f.Limit == 10
+	})).Return(&user.UserListResponse{
+		Users:      []*user.User{{ID: uuid.New(), Email: "budi@example.com"}},
+		NextCursor: "abc",
+		HasMore:    true,
+	}, nil)
+
+	req, _ := http.NewRequest("GET", "/admin/users?email=budi&kyc_status=pending&is_active=false&limit=10", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"next_cursor":"abc"`)
+	assert.NotContains(t, w.Body.String(), "password")
+	mockService.AssertExpectations(t)
+}
+
+func TestUserHandler_ListUsers_InvalidCursor(t *testing.T) {
+	mockService := new(MockUserService)
+	handler := NewUserHandler(mockService)
+
+	router := setupRouter()
+	router.GET("/admin/users", handler.ListUsers)
+
+	req, _ := http.NewRequest("GET", "/admin/users?cursor=garbage", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid cursor")
+	mockService.AssertNotCalled(t, "ListUsers", mock.Anything)
+}
+
+// ==================== Reactivation Tests ====================
+
+func TestUserHandler_Reactivate_Success(t *testing.T) {
+	mockService := new(MockUserService)
+	handler := NewUserHandler(mockService)
+
+	router := setupRouter()
+	router.POST("/users/reactivate", handler.Reactivate)
+
+	reqBody := &user.ReactivateRequest{Email: "gone@example.com", Password: "secret123", OTP: "123456"}
+	mockService.On("Reactivate", reqBody).Return(&user.User{ID: uuid.New(), Email: "gone@example.com", IsActive: true}, nil)
+
+	body, _ := json.Marshal(reqBody)
+	req, _ := http.NewRequest("POST", "/users/reactivate", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"is_active":true`)
+	mockService.AssertExpectations(t)
+}
+
+func TestUserHandler_Reactivate_Expired(t *testing.T) {
+	mockService := new(MockUserService)
+	handler := NewUserHandler(mockService)
+
+	router := setupRouter()
+	router.POST("/users/reactivate", handler.Reactivate)
+
+	mockService.On("Reactivate", mock.Anything).Return(nil, fmt.Errorf("reactivation period has expired"))
+
+	req, _ := http.NewRequest("POST", "/users/reactivate", bytes.NewBufferString(`{"email":"gone@example.com","password":"secret123","otp":"123456"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "reactivation period has expired")
+}
+
+func TestUserHandler_RequestReactivation_InvalidRequest(t *testing.T) {
+	mockService := new(MockUserService)
+	handler := NewUserHandler(mockService)
+
+	router := setupRouter()
+	router.POST("/users/reactivate/otp", handler.RequestReactivation)
+
+	req, _ := http.NewRequest("POST", "/users/reactivate/otp", bytes.NewBufferString(`{"email":"gone@example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "RequestReactivation", mock.Anything)
+}
+
+// ==================== Phone Verification Tests ====================
+
+func TestUserHandler_VerifyPhone_Success(t *testing.T) {
+	mockService := new(MockUserService)
+	handler := NewUserHandler(mockService)
+
+	router := setupRouter()
+	userID := uuid.New()
+	router.POST("/users/phone/verify", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		handler.VerifyPhone(c)
+	})
+
+	phone := "+6281234567890"
+	verifiedAt := time.Now()
+	mockService.On("VerifyPhone", userID, &user.VerifyPhoneRequest{OTP: "123456"}).
+		Return(&user.User{ID: userID, Phone: &phone, PhoneVerifiedAt: &verifiedAt}, nil)
+
+	req, _ := http.NewRequest("POST", "/users/phone/verify", bytes.NewBufferString(`{"otp":"123456"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"phone_verified_at"`)
+}
+
+func TestUserHandler_RequestPhoneVerification_NoPhone(t *testing.T) {
+	mockService := new(MockUserService)
+	handler := NewUserHandler(mockService)
+
+	router := setupRouter()
+	userID := uuid.New()
+	router.POST("/users/phone/verification", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		handler.RequestPhoneVerification(c)
+	})
+
+	mockService.On("RequestPhoneVerification", userID).Return(fmt.Errorf("no phone number on profile"))
+
+	req, _ := http.NewRequest("POST", "/users/phone/verification", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "no phone number on profile")
+}
+
+// ==================== Email Change Tests ====================
+
+func TestUserHandler_RequestEmailChange_Success(t *testing.T) {
+	mockService := new(MockUserService)
+	handler := NewUserHandler(mockService)
+
+	router := setupRouter()
+	userID := uuid.New()
+	router.POST("/users/email/change", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		handler.RequestEmailChange(c)
+	})
+
+	mockService.On("RequestEmailChange", userID, &user.EmailChangeRequest{NewEmail: "new@example.com", Password: "secret123"}).Return(nil)
+
+	req, _ := http.NewRequest("POST", "/users/email/change", bytes.NewBufferString(`{"new_email":"new@example.com","password":"secret123"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestUserHandler_RequestEmailChange_InvalidEmail(t *testing.T) {
+	mockService := new(MockUserService)
+	handler := NewUserHandler(mockService)
+
+	router := setupRouter()
+	router.POST("/users/email/change", func(c *gin.Context) {
+		c.Set("user_id", uuid.New())
+		handler.RequestEmailChange(c)
+	})
+
+	req, _ := http.NewRequest("POST", "/users/email/change", bytes.NewBufferString(`{"new_email":"not-an-email","password":"secret123"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "RequestEmailChange", mock.Anything, mock.Anything)
+}
+
+func TestUserHandler_ConfirmEmailChange_InvalidOTP(t *testing.T) {
+	mockService := new(MockUserService)
+	handler := NewUserHandler(mockService)
+
+	router := setupRouter()
+	userID := uuid.New()
+	router.POST("/users/email/confirm", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		handler.ConfirmEmailChange(c)
+	})
+
+	mockService.On("ConfirmEmailChange", userID, mock.Anything).Return(nil, fmt.Errorf("invalid OTP code"))
+
+	req, _ := http.NewRequest("POST", "/users/email/confirm", bytes.NewBufferString(`{"new_email":"new@example.com","otp":"000000"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid OTP code")
+}
+
+func TestUserHandler_ResolveHandle(t *testing.T) {
+	mockService := new(MockUserService)
+	handler := NewUserHandler(mockService)
+
+	router := setupRouter()
+	router.GET("/users/handles/:handle", func(c *gin.Context) {
+		c.Set("user_id", uuid.New())
+		handler.ResolveHandle(c)
+	})
+
+	mockService.On("ResolveHandle", "@siti.r").Return(&user.HandleResolution{Handle: "@siti.r", DisplayName: "Siti R."}, nil)
+	mockService.On("ResolveHandle", "@nobody").Return(nil, fmt.Errorf("no customer with this handle"))
+
+	req, _ := http.NewRequest("GET", "/users/handles/@siti.r", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"display_name":"Siti R."`)
+
+	req, _ = http.NewRequest("GET", "/users/handles/@nobody", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestUserHandler_ChangePassword_PolicyViolation(t *testing.T) {
+	mockService := new(MockUserService)
+	handler := NewUserHandler(mockService)
+
+	router := setupRouter()
+	userID := uuid.New()
+	router.PUT("/users/password", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		handler.ChangePassword(c)
+	})
+
+	mockService.On("ChangePassword", userID, &user.ChangePasswordRequest{CurrentPassword: "Current123", NewPassword: "lowercase1"}).
+		Return(fmt.Errorf("password must contain an uppercase letter"))
+
+	req, _ := http.NewRequest("PUT", "/users/password", bytes.NewBufferString(`{"current_password":"Current123","new_password":"lowercase1"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "uppercase letter")
+}
+
+func TestUserHandler_ResetPasswordWithLink(t *testing.T) {
+	mockService := new(MockUserService)
+	handler := NewUserHandler(mockService)
+
+	router := setupRouter()
+	router.POST("/auth/reset-password/link", handler.ResetPasswordWithLink)
+
+	mockService.On("ResetPasswordWithLink", &user.ResetPasswordLinkRequest{Token: "used-token", NewPassword: "NewSecret123"}).
+		Return(fmt.Errorf("password reset link has already been used or replaced"))
+
+	req, _ := http.NewRequest("POST", "/auth/reset-password/link", bytes.NewBufferString(`{"token":"used-token","new_password":"NewSecret123"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "already been used")
+}
@@ -5,7 +5,9 @@ import (
 	"strings"
 
 	"github.com/darisadam/madabank-server/internal/pkg/jwt"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 func AuthMiddleware(jwtService *jwt.JWTService) gin.HandlerFunc {
@@ -39,7 +41,66 @@ func AuthMiddleware(jwtService *jwt.JWTService) gin.HandlerFunc {
 		c.Set("user_id", claims.UserID)
 		c.Set("email", claims.Email)
 		c.Set("role", claims.Role)
+		if claims.Limited() {
+			c.Set("scope", claims.Scope)
+		}
+		// Every request made while impersonating is attributed to the agent
+		if claims.ImpersonatedBy != nil {
+			c.Set("impersonated_by", *claims.ImpersonatedBy)
+			logger.Audit("IMPERSONATED_REQUEST",
+				zap.String("user_id", claims.UserID.String()),
+				zap.String("impersonated_by", claims.ImpersonatedBy.String()),
+				zap.String("token_id", claims.ID),
+				zap.String("method", c.Request.Method),
+				zap.String("path", c.Request.URL.Path),
+			)
+		}
 
 		c.Next()
 	}
 }
+
+// RequireRole allows the request only when the authenticated user's role
+// claim matches one of roles. It must run after AuthMiddleware.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("role")
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
+		c.Abort()
+	}
+}
+
+// RequireScope limits a route group to tokens granted access to resource:
+// "<resource>:read" for GET and HEAD requests, "<resource>:write" for the
+// rest. Tokens without scopes, as issued at login, are let through. It must
+// run after AuthMiddleware.
+func RequireScope(resource string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope := c.GetString("scope")
+		if scope == "" {
+			c.Next()
+			return
+		}
+
+		required := resource + ":write"
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			required = resource + ":read"
+		}
+		for _, granted := range strings.Fields(scope) {
+			if granted == required {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient scope", "required_scope": required})
+		c.Abort()
+	}
+}
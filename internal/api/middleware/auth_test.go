@@ -1,10 +1,13 @@
 package middleware
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/darisadam/madabank-server/internal/domain/merchant"
 	"github.com/darisadam/madabank-server/internal/pkg/jwt"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -137,3 +140,246 @@ func TestAuthMiddleware_EmptyBearerToken(t *testing.T) {
 
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
 }
+
+// ==================== RequireScope Tests ====================
+
+func TestRequireScope(t *testing.T) {
+	jwtService := jwt.NewJWTService("test-secret", 1)
+	router := setupTestRouter()
+	cards := router.Group("/cards", AuthMiddleware(jwtService), RequireScope("cards"))
+	cards.GET("", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+	cards.POST("", func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"message": "success"})
+	})
+
+	userID := uuid.New()
+	full, _, _ := jwtService.GenerateToken(userID, "test@example.com", "customer")
+	readOnly, _, _ := jwtService.GenerateScopedToken(userID, "test@example.com", "customer", []string{"cards:read"})
+	otherResource, _, _ := jwtService.GenerateScopedToken(userID, "test@example.com", "customer", []string{"transactions:write"})
+
+	tests := []struct {
+		name   string
+		method string
+		token  string
+		status int
+	}{
+		{"login token reads", "GET", full, http.StatusOK},
+		{"login token writes", "POST", full, http.StatusCreated},
+		{"read scope reads", "GET", readOnly, http.StatusOK},
+		{"read scope cannot write", "POST", readOnly, http.StatusForbidden},
+		{"other resource", "GET", otherResource, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, "/cards", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusForbidden {
+				assert.Contains(t, w.Body.String(), "insufficient scope")
+			}
+		})
+	}
+}
+
+func TestAuthMiddleware_ImpersonationToken(t *testing.T) {
+	jwtService := jwt.NewJWTService("test-secret", 1)
+	router := setupTestRouter()
+	accounts := router.Group("/accounts", AuthMiddleware(jwtService), RequireScope("accounts"))
+	accounts.GET("", func(c *gin.Context) {
+		agentID, _ := c.Get("impersonated_by")
+		c.JSON(http.StatusOK, gin.H{"impersonated_by": agentID})
+	})
+	accounts.POST("", func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"message": "success"})
+	})
+
+	agentID := uuid.New()
+	token, _, err := jwtService.GenerateImpersonationToken(uuid.New(), "test@example.com", "customer", agentID, uuid.New(), []string{"accounts:read"}, time.Now().Add(15*time.Minute))
+	assert.NoError(t, err)
+
+	req, _ := http.NewRequest("GET", "/accounts", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), agentID.String())
+
+	req, _ = http.NewRequest("POST", "/accounts", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+// ==================== RequireRole Tests ====================
+
+func TestRequireRole_Allowed(t *testing.T) {
+	jwtService := jwt.NewJWTService("test-secret", 1)
+	router := setupTestRouter()
+
+	router.Use(AuthMiddleware(jwtService))
+	router.Use(RequireRole("admin"))
+	router.GET("/admin", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+
+	token, _, _ := jwtService.GenerateToken(uuid.New(), "admin@example.com", "admin")
+	req, _ := http.NewRequest("GET", "/admin", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRequireRole_Forbidden(t *testing.T) {
+	jwtService := jwt.NewJWTService("test-secret", 1)
+	router := setupTestRouter()
+
+	router.Use(AuthMiddleware(jwtService))
+	router.Use(RequireRole("admin"))
+	router.GET("/admin", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+
+	token, _, _ := jwtService.GenerateToken(uuid.New(), "user@example.com", "customer")
+	req, _ := http.NewRequest("GET", "/admin", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "insufficient permissions")
+}
+
+// ==================== PartnerAuthMiddleware Tests ====================
+
+func TestPartnerAuthMiddleware(t *testing.T) {
+	router := setupTestRouter()
+	router.Use(PartnerAuthMiddleware([]string{"acquirer-key-1", "acquirer-key-2"}))
+	router.POST("/authorize", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+
+	tests := []struct {
+		name   string
+		key    string
+		status int
+	}{
+		{"missing key", "", http.StatusUnauthorized},
+		{"unknown key", "not-a-key", http.StatusUnauthorized},
+		{"first key", "acquirer-key-1", http.StatusOK},
+		{"second key", "acquirer-key-2", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "/authorize", nil)
+			if tt.key != "" {
+				req.Header.Set("X-API-Key", tt.key)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+// ==================== MerchantAuthMiddleware Tests ====================
+
+type stubMerchantAuthenticator map[string]*merchant.Merchant
+
+func (s stubMerchantAuthenticator) Authenticate(apiKey string) (*merchant.Merchant, error) {
+	if m, ok := s[apiKey]; ok {
+		return m, nil
+	}
+	return nil, fmt.Errorf("invalid API key")
+}
+
+func TestMerchantAuthMiddleware(t *testing.T) {
+	merchantID := uuid.New()
+	router := setupTestRouter()
+	router.Use(MerchantAuthMiddleware(stubMerchantAuthenticator{"mbk_live_good": {ID: merchantID}}))
+	router.GET("/merchant", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"merchant_id": c.MustGet("merchant_id")})
+	})
+
+	tests := []struct {
+		name   string
+		key    string
+		status int
+	}{
+		{"missing key", "", http.StatusUnauthorized},
+		{"unknown key", "mbk_live_bad", http.StatusUnauthorized},
+		{"valid key", "mbk_live_good", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/merchant", nil)
+			if tt.key != "" {
+				req.Header.Set("X-API-Key", tt.key)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusOK {
+				assert.Contains(t, w.Body.String(), merchantID.String())
+			}
+		})
+	}
+}
+
+// ==================== ServiceAuthMiddleware Tests ====================
+
+func TestServiceAuthMiddleware(t *testing.T) {
+	serviceTokens := jwt.NewServiceTokenService("service-secret", time.Minute)
+	router := setupTestRouter()
+	router.Use(ServiceAuthMiddleware(serviceTokens, "tokens.introspect"))
+	router.POST("/introspect", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"client_id": c.MustGet("client_id")})
+	})
+
+	scoped, _, _ := serviceTokens.GenerateServiceToken("fraud-worker", []string{"tokens.introspect"})
+	unscoped, _, _ := serviceTokens.GenerateServiceToken("statement-renderer", []string{"statements.render"})
+	customer, _, _ := jwt.NewJWTService("service-secret", 1).GenerateToken(uuid.New(), "test@madabank.com", "admin")
+
+	tests := []struct {
+		name   string
+		header string
+		status int
+	}{
+		{"missing token", "", http.StatusUnauthorized},
+		{"customer token", "Bearer " + customer, http.StatusUnauthorized},
+		{"missing scope", "Bearer " + unscoped, http.StatusForbidden},
+		{"scoped token", "Bearer " + scoped, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "/introspect", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusOK {
+				assert.Contains(t, w.Body.String(), "fraud-worker")
+			} else {
+				assert.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupBodyLimitRouter(cfg BodyLimitConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(BodyLimitMiddleware(cfg))
+	echo := func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.String(http.StatusOK, "%d", len(body))
+	}
+	router.POST("/api/v1/auth/login", echo)
+	router.POST("/api/v1/transactions/transfer", echo)
+	router.POST("/api/v1/admin/uploads", echo)
+	return router
+}
+
+func postBody(router *gin.Engine, path string, size int, chunked bool) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("POST", path, strings.NewReader(strings.Repeat("a", size)))
+	if chunked {
+		req.ContentLength = -1
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestBodyLimitMiddleware_WithinLimit(t *testing.T) {
+	router := setupBodyLimitRouter(BodyLimitConfig{MaxBytes: 100, AuthMaxBytes: 10})
+
+	w := postBody(router, "/api/v1/transactions/transfer", 100, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "100", w.Body.String())
+}
+
+func TestBodyLimitMiddleware_ContentLengthTooLarge(t *testing.T) {
+	router := setupBodyLimitRouter(BodyLimitConfig{MaxBytes: 100, AuthMaxBytes: 10})
+
+	w := postBody(router, "/api/v1/transactions/transfer", 101, false)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), "request body too large")
+}
+
+func TestBodyLimitMiddleware_AuthLimit(t *testing.T) {
+	router := setupBodyLimitRouter(BodyLimitConfig{MaxBytes: 100, AuthMaxBytes: 10})
+
+	assert.Equal(t, http.StatusOK, postBody(router, "/api/v1/auth/login", 10, false).Code)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, postBody(router, "/api/v1/auth/login", 11, false).Code)
+}
+
+func TestBodyLimitMiddleware_UnknownLength(t *testing.T) {
+	router := setupBodyLimitRouter(BodyLimitConfig{MaxBytes: 100, AuthMaxBytes: 10})
+
+	w := postBody(router, "/api/v1/transactions/transfer", 100, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "100", w.Body.String())
+
+	w = postBody(router, "/api/v1/transactions/transfer", 101, true)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestBodyLimitMiddleware_RouteOverride(t *testing.T) {
+	router := setupBodyLimitRouter(BodyLimitConfig{
+		MaxBytes:     100,
+		AuthMaxBytes: 10,
+		Routes:       map[string]int64{"/api/v1/admin/uploads": 1000},
+	})
+
+	assert.Equal(t, http.StatusOK, postBody(router, "/api/v1/admin/uploads", 1000, false).Code)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, postBody(router, "/api/v1/admin/uploads", 1001, false).Code)
+}
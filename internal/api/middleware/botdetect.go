@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/darisadam/madabank-server/internal/pkg/botdetect"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/pkg/metrics"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// BotDetectionMiddleware scores requests to the public auth endpoints. A
+// high score is denied; a medium one needs a CAPTCHA token in the
+// X-Captcha-Token header, after which the IP is not challenged for a while.
+// Denied requests and failed CAPTCHAs set "bot_flagged" for
+// SuspiciousActivityMiddleware. Without a CAPTCHA verifier challenged
+// requests are denied.
+func BotDetectionMiddleware(detector *botdetect.Detector, captcha botdetect.CaptchaVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !isBotGuardedPath(c.FullPath()) {
+			c.Next()
+			return
+		}
+
+		ctx := context.Background()
+		clientIP := c.ClientIP()
+
+		verdict, err := detector.Evaluate(ctx, botdetect.Request{
+			IP:             clientIP,
+			UserAgent:      c.GetHeader("User-Agent"),
+			Accept:         c.GetHeader("Accept"),
+			AcceptLanguage: c.GetHeader("Accept-Language"),
+			AcceptEncoding: c.GetHeader("Accept-Encoding"),
+		})
+		if err != nil {
+			logger.Error("Bot detection failed, scoring without cadence", zap.Error(err))
+		}
+		c.Set("bot_score", verdict.Score)
+
+		action := verdict.Action
+		if action == botdetect.ActionChallenge && captcha == nil {
+			action = botdetect.ActionDeny
+		}
+		if action == botdetect.ActionChallenge {
+			if verified, err := detector.IsVerified(ctx, clientIP); err != nil {
+				logger.Error("Failed to check CAPTCHA pass", zap.Error(err))
+			} else if verified {
+				action = botdetect.ActionAllow
+			}
+		}
+		metrics.RecordBotDetection(string(action))
+
+		switch action {
+		case botdetect.ActionDeny:
+			logger.Warn("Request denied by bot detection",
+				zap.String("ip", clientIP),
+				zap.String("path", c.FullPath()),
+				zap.Int("score", verdict.Score),
+				zap.Strings("signals", verdict.Signals),
+			)
+			c.Set("bot_flagged", true)
+			c.JSON(http.StatusForbidden, gin.H{"error": "Automated requests are not allowed."})
+			c.Abort()
+			return
+		case botdetect.ActionChallenge:
+			if !verifyCaptcha(c, detector, captcha, clientIP) {
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// verifyCaptcha checks the X-Captcha-Token header and aborts the request when
+// it is missing or wrong. When the CAPTCHA provider cannot be reached the
+// request is let through, so its outage does not lock users out.
+func verifyCaptcha(c *gin.Context, detector *botdetect.Detector, captcha botdetect.CaptchaVerifier, clientIP string) bool {
+	ctx := context.Background()
+
+	token := c.GetHeader("X-Captcha-Token")
+	if token == "" {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":            "Please complete the CAPTCHA to continue.",
+			"captcha_required": true,
+		})
+		c.Abort()
+		return false
+	}
+
+	ok, err := captcha.Verify(ctx, token, clientIP)
+	if err != nil {
+		logger.Warn("CAPTCHA verification failed, allowing request", zap.String("verifier", captcha.Name()), zap.Error(err))
+		return true
+	}
+	if !ok {
+		c.Set("bot_flagged", true)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":            "CAPTCHA verification failed.",
+			"captcha_required": true,
+		})
+		c.Abort()
+		return false
+	}
+
+	if err := detector.MarkVerified(ctx, clientIP); err != nil {
+		logger.Error("Failed to record CAPTCHA pass", zap.Error(err))
+	}
+	return true
+}
+
+// isBotGuardedPath reports whether bot detection applies to the route: the
+// unauthenticated endpoints that credential stuffing and sign-up scripts
+// target
+func isBotGuardedPath(path string) bool {
+	return strings.HasPrefix(path, "/api/v1/auth/") || strings.HasPrefix(path, "/api/v1/users/reactivate")
+}
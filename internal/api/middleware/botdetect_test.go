@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/darisadam/madabank-server/internal/pkg/botdetect"
+	"github.com/darisadam/madabank-server/internal/pkg/ratelimit"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubCaptcha struct {
+	token string
+}
+
+func (v *stubCaptcha) Verify(ctx context.Context, token, remoteIP string) (bool, error) {
+	return token == v.token, nil
+}
+
+func (v *stubCaptcha) Name() string {
+	return "stub"
+}
+
+func setupBotRouter(t *testing.T, captcha botdetect.CaptchaVerifier) (*gin.Engine, *ratelimit.RateLimiter) {
+	mr, limiter := setupRateLimitTest(t)
+	t.Cleanup(mr.Close)
+
+	detector, err := botdetect.NewDetector(redis.NewClient(&redis.Options{Addr: mr.Addr()}), botdetect.Config{
+		ChallengeScore: 30,
+		DenyScore:      50,
+	})
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(SuspiciousActivityMiddleware(limiter))
+	router.Use(BotDetectionMiddleware(detector, captcha))
+	handler := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	}
+	router.POST("/api/v1/auth/login", handler)
+	router.GET("/api/v1/accounts", handler)
+	return router, limiter
+}
+
+func botRequest(router *gin.Engine, method, path, userAgent, captchaToken string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, nil)
+	req.RemoteAddr = "198.51.100.10:4321"
+	if userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("Accept-Encoding", "gzip")
+	}
+	if captchaToken != "" {
+		req.Header.Set("X-Captcha-Token", captchaToken)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestBotDetectionMiddleware_AllowsApp(t *testing.T) {
+	router, _ := setupBotRouter(t, &stubCaptcha{token: "solved"})
+
+	w := botRequest(router, "POST", "/api/v1/auth/login", "MadaBank/3.2.0 (Android 14) okhttp/4.12.0", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestBotDetectionMiddleware_CaptchaChallenge(t *testing.T) {
+	router, _ := setupBotRouter(t, &stubCaptcha{token: "solved"})
+
+	w := botRequest(router, "POST", "/api/v1/auth/login", "curl/8.4.0", "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), `"captcha_required":true`)
+
+	w = botRequest(router, "POST", "/api/v1/auth/login", "curl/8.4.0", "guessed")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = botRequest(router, "POST", "/api/v1/auth/login", "curl/8.4.0", "solved")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// The IP is not challenged again once it solved a CAPTCHA
+	w = botRequest(router, "POST", "/api/v1/auth/login", "curl/8.4.0", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestBotDetectionMiddleware_DeniesWithoutCaptcha(t *testing.T) {
+	router, _ := setupBotRouter(t, nil)
+
+	w := botRequest(router, "POST", "/api/v1/auth/login", "curl/8.4.0", "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.NotContains(t, w.Body.String(), "captcha_required")
+
+	// Authenticated routes are not scored
+	w = botRequest(router, "GET", "/api/v1/accounts", "curl/8.4.0", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestBotDetectionMiddleware_RepeatedDenialsBlockIP(t *testing.T) {
+	router, limiter := setupBotRouter(t, nil)
+
+	for i := 0; i < 11; i++ {
+		w := botRequest(router, "POST", "/api/v1/auth/login", "", "")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	}
+
+	blocked, err := limiter.IsBlocked(context.Background(), "198.51.100.10")
+	require.NoError(t, err)
+	assert.True(t, blocked)
+}
@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var largeBody = strings.Repeat("madabank ", 500)
+
+func setupCompressionRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(CompressionMiddleware(DefaultCompressionMinSize))
+	router.GET("/large", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"data": largeBody})
+	})
+	router.GET("/small", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+	router.GET("/pdf", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/pdf", []byte(largeBody))
+	})
+	router.GET("/empty", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return router
+}
+
+func getWithEncoding(router *gin.Engine, path, acceptEncoding string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", path, nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestCompressionMiddleware_Gzip(t *testing.T) {
+	w := getWithEncoding(setupCompressionRouter(), "/large", "gzip, deflate")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+	assert.Less(t, w.Body.Len(), len(largeBody))
+
+	gz, err := gzip.NewReader(w.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), largeBody)
+}
+
+func TestCompressionMiddleware_Deflate(t *testing.T) {
+	w := getWithEncoding(setupCompressionRouter(), "/large", "gzip;q=0.5, deflate")
+
+	assert.Equal(t, "deflate", w.Header().Get("Content-Encoding"))
+	zr, err := zlib.NewReader(w.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), largeBody)
+}
+
+func TestCompressionMiddleware_NotAccepted(t *testing.T) {
+	router := setupCompressionRouter()
+
+	for _, acceptEncoding := range []string{"", "identity", "gzip;q=0"} {
+		w := getWithEncoding(router, "/large", acceptEncoding)
+		assert.Empty(t, w.Header().Get("Content-Encoding"), acceptEncoding)
+		assert.Contains(t, w.Body.String(), largeBody)
+	}
+}
+
+func TestCompressionMiddleware_SmallResponse(t *testing.T) {
+	w := getWithEncoding(setupCompressionRouter(), "/small", "gzip")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.JSONEq(t, `{"status":"ok"}`, w.Body.String())
+}
+
+func TestCompressionMiddleware_AlreadyCompressedType(t *testing.T) {
+	w := getWithEncoding(setupCompressionRouter(), "/pdf", "gzip")
+
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Equal(t, largeBody, w.Body.String())
+}
+
+func TestCompressionMiddleware_NoBody(t *testing.T) {
+	w := getWithEncoding(setupCompressionRouter(), "/empty", "gzip")
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Zero(t, w.Body.Len())
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConditionalGetMiddleware tags successful GET responses with an ETag and
+// answers 304 Not Modified, without a body, when the client's If-None-Match
+// already holds it. The tag is a hash of the uncompressed body, so it is
+// weak: the same representation sent with a different Content-Encoding
+// keeps its tag.
+func ConditionalGetMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		w := &bufferedWriter{ResponseWriter: original}
+		c.Writer = w
+		c.Next()
+		c.Writer = original
+
+		if original.Status() != http.StatusOK {
+			_, _ = original.Write(w.body)
+			return
+		}
+
+		sum := sha256.Sum256(w.body)
+		etag := `W/"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+		original.Header().Set("ETag", etag)
+		if original.Header().Get("Cache-Control") == "" {
+			// Account data may be kept by the client only, and only if it
+			// checks back first
+			original.Header().Set("Cache-Control", "private, no-cache")
+		}
+
+		if etagMatches(c.GetHeader("If-None-Match"), etag) {
+			original.Header().Del("Content-Type")
+			original.Header().Del("Content-Length")
+			original.WriteHeader(http.StatusNotModified)
+			original.WriteHeaderNow()
+			return
+		}
+		_, _ = original.Write(w.body)
+	}
+}
+
+// etagMatches applies the weak comparison If-None-Match calls for
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	want := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == want {
+			return true
+		}
+	}
+	return false
+}
+
+// bufferedWriter keeps the body back so it can be hashed before anything is
+// sent. The status is recorded by the wrapped writer, which sends nothing
+// until the first write.
+type bufferedWriter struct {
+	gin.ResponseWriter
+	body []byte
+}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	w.body = append(w.body, data...)
+	return len(data), nil
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// WriteHeaderNow is held back too; the middleware sends the header itself
+func (w *bufferedWriter) WriteHeaderNow() {}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupConditionalRouter(balance *float64) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(CompressionMiddleware(DefaultCompressionMinSize))
+	router.GET("/balance", ConditionalGetMiddleware(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"balance": *balance})
+	})
+	router.GET("/missing", ConditionalGetMiddleware(), func(c *gin.Context) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	})
+	return router
+}
+
+func conditionalGet(router *gin.Engine, path, ifNoneMatch string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", path, nil)
+	if ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestConditionalGetMiddleware_NotModified(t *testing.T) {
+	balance := 100.0
+	router := setupConditionalRouter(&balance)
+
+	first := conditionalGet(router, "/balance", "")
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.JSONEq(t, `{"balance":100}`, first.Body.String())
+	etag := first.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+	assert.Equal(t, "private, no-cache", first.Header().Get("Cache-Control"))
+
+	second := conditionalGet(router, "/balance", etag)
+	assert.Equal(t, http.StatusNotModified, second.Code)
+	assert.Zero(t, second.Body.Len())
+	assert.Equal(t, etag, second.Header().Get("ETag"))
+
+	// Strong and listed tags match too
+	assert.Equal(t, http.StatusNotModified, conditionalGet(router, "/balance", `"other", `+etag[2:]).Code)
+}
+
+func TestConditionalGetMiddleware_Changed(t *testing.T) {
+	balance := 100.0
+	router := setupConditionalRouter(&balance)
+	etag := conditionalGet(router, "/balance", "").Header().Get("ETag")
+
+	balance = 50
+	w := conditionalGet(router, "/balance", etag)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"balance":50}`, w.Body.String())
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+}
+
+func TestConditionalGetMiddleware_ErrorsUntagged(t *testing.T) {
+	router := setupConditionalRouter(new(float64))
+
+	w := conditionalGet(router, "/missing", "*")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Empty(t, w.Header().Get("ETag"))
+	assert.JSONEq(t, `{"error":"not found"}`, w.Body.String())
+}
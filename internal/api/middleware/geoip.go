@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/darisadam/madabank-server/internal/pkg/geoip"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/pkg/metrics"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// GeoIPMiddleware resolves the client's country, sets it as "country" in the
+// context and counts requests per country. On logins and transfers it
+// applies the policy: blocked countries get 403, challenged ones set
+// "step_up_required" for the login handler and StepUpMiddleware.
+func GeoIPMiddleware(resolver geoip.Resolver, policy geoip.Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		country := resolver.Country(c.Request, c.ClientIP())
+		c.Set("country", country)
+
+		action := geoip.ActionAllow
+		if isGeoGuardedPath(c.FullPath()) {
+			action = policy.Decide(country)
+		}
+		metrics.RecordGeoRequest(country, string(action))
+
+		switch action {
+		case geoip.ActionBlock:
+			logger.Warn("Request blocked by country policy",
+				zap.String("ip", c.ClientIP()),
+				zap.String("country", country),
+				zap.String("path", c.FullPath()),
+			)
+			c.JSON(http.StatusForbidden, gin.H{"error": "This action is not available from your country."})
+			c.Abort()
+			return
+		case geoip.ActionChallenge:
+			c.Set("step_up_required", true)
+		}
+
+		c.Next()
+	}
+}
+
+// isGeoGuardedPath reports whether the country policy applies to the route
+func isGeoGuardedPath(path string) bool {
+	switch path {
+	case "/api/v1/auth/login", "/api/v1/transactions/transfer", "/api/v1/transactions/templates/:id/execute":
+		return true
+	default:
+		return false
+	}
+}
+
+// StepUpVerifier spends a step-up code sent to the user
+type StepUpVerifier interface {
+	VerifyStepUp(userID uuid.UUID, otp string) error
+}
+
+// StepUpMiddleware asks for a step-up code in the X-Step-Up-Code header when
+// GeoIPMiddleware challenged the request. It runs after AuthMiddleware.
+func StepUpMiddleware(verifier StepUpVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !c.GetBool("step_up_required") {
+			c.Next()
+			return
+		}
+
+		userID, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			c.Abort()
+			return
+		}
+
+		code := c.GetHeader("X-Step-Up-Code")
+		if code == "" {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":            "additional verification required, request a code from /api/v1/users/step-up",
+				"step_up_required": true,
+			})
+			c.Abort()
+			return
+		}
+
+		if err := verifier.VerifyStepUp(userID.(uuid.UUID), code); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "step_up_required": true})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/darisadam/madabank-server/internal/pkg/geoip"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubStepUpVerifier struct {
+	code string
+}
+
+func (v *stubStepUpVerifier) VerifyStepUp(userID uuid.UUID, otp string) error {
+	if otp != v.code {
+		return fmt.Errorf("invalid OTP code")
+	}
+	return nil
+}
+
+func setupGeoRouter(t *testing.T) *gin.Engine {
+	policy, err := geoip.NewPolicy([]string{"KP"}, []string{"RU"})
+	assert.NoError(t, err)
+
+	router := gin.New()
+	router.Use(GeoIPMiddleware(geoip.NewHeaderResolver(""), policy))
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", uuid.New())
+		c.Next()
+	})
+	router.Use(StepUpMiddleware(&stubStepUpVerifier{code: "123456"}))
+	handler := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"country": c.GetString("country")})
+	}
+	router.POST("/api/v1/transactions/transfer", handler)
+	router.GET("/api/v1/accounts", handler)
+	return router
+}
+
+func geoRequest(router *gin.Engine, method, path, country, code string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, nil)
+	if country != "" {
+		req.Header.Set(geoip.DefaultCountryHeader, country)
+	}
+	if code != "" {
+		req.Header.Set("X-Step-Up-Code", code)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestGeoIPMiddleware_BlocksGuardedRoutes(t *testing.T) {
+	router := setupGeoRouter(t)
+
+	w := geoRequest(router, "POST", "/api/v1/transactions/transfer", "KP", "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Other routes only record the country
+	w = geoRequest(router, "GET", "/api/v1/accounts", "KP", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"country":"KP"`)
+}
+
+func TestGeoIPMiddleware_ChallengeNeedsStepUpCode(t *testing.T) {
+	router := setupGeoRouter(t)
+
+	w := geoRequest(router, "POST", "/api/v1/transactions/transfer", "RU", "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), `"step_up_required":true`)
+
+	w = geoRequest(router, "POST", "/api/v1/transactions/transfer", "RU", "000000")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid OTP code")
+
+	w = geoRequest(router, "POST", "/api/v1/transactions/transfer", "RU", "123456")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestGeoIPMiddleware_AllowsOtherCountries(t *testing.T) {
+	router := setupGeoRouter(t)
+
+	w := geoRequest(router, "POST", "/api/v1/transactions/transfer", "ID", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = geoRequest(router, "POST", "/api/v1/transactions/transfer", "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"country":""`)
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/darisadam/madabank-server/internal/domain/merchant"
+	"github.com/gin-gonic/gin"
+)
+
+// MerchantAuthenticator resolves the merchant owning an API key
+type MerchantAuthenticator interface {
+	Authenticate(apiKey string) (*merchant.Merchant, error)
+}
+
+// MerchantAuthMiddleware admits merchant servers presenting their API key in the
+// X-API-Key header and sets merchant_id in the context.
+func MerchantAuthMiddleware(authenticator MerchantAuthenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		apiKey := c.GetHeader("X-API-Key")
+		if apiKey == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "API key required"})
+			c.Abort()
+			return
+		}
+
+		m, err := authenticator.Authenticate(apiKey)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
+
+		c.Set("merchant_id", m.ID)
+		c.Next()
+	}
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"strings"
 	"time"
 
 	"github.com/darisadam/madabank-server/internal/pkg/metrics"
@@ -21,18 +22,26 @@ func MetricsMiddleware() gin.HandlerFunc {
 		status := c.Writer.Status()
 		method := c.Request.Method
 
-		// Fix status conversion (rune to string is likely wrong in previous code,
-		// but RecordHTTPRequest takes string(rune(status)) which gives a symbol, not number.
-		// Wait, user provided code: HTTPRequestsTotal.WithLabelValues(method, endpoint, string(rune(status))).Inc()
-		// string(rune(200)) is definitely not "200". It's a character.
-		// I should fix this bug while I'm at it?
-		// The user provided the code. I restore it as is, or fix it?
-		// User provided: string(rune(status)) in metrics.go.
-		// Let's check metrics.go content I just wrote.
-		// It has: HTTPRequestsTotal.WithLabelValues(method, endpoint, string(rune(status))).Inc()
-		// This is bug. I will fix it in metrics.go via Replace or Write.
-		// But first, let's restore middleware.
+		metrics.RecordHTTPRequest(method, path, status, duration, traceIDFromParent(c.GetHeader("traceparent")))
+	}
+}
 
-		metrics.RecordHTTPRequest(method, path, status, duration)
+// traceIDFromParent returns the trace ID of a W3C traceparent header
+// (version-traceid-parentid-flags), or "" when the header is missing or
+// malformed
+func traceIDFromParent(traceparent string) string {
+	parts := strings.Split(traceparent, "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return ""
+	}
+	traceID := parts[1]
+	if len(traceID) != 32 || strings.Trim(traceID, "0") == "" {
+		return ""
+	}
+	for _, r := range traceID {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return ""
+		}
 	}
+	return traceID
 }
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MetricsAuthConfig holds the credentials a scraper must present on /metrics.
+// A bearer token, basic auth credentials or both may be configured; a request
+// matching either is admitted.
+type MetricsAuthConfig struct {
+	BearerToken string
+	Username    string
+	Password    string
+}
+
+// Enabled reports whether any credentials are configured
+func (c MetricsAuthConfig) Enabled() bool {
+	return c.BearerToken != "" || c.Username != ""
+}
+
+// MetricsAuthMiddleware rejects scrapes without the configured credentials.
+// With none configured every request is let through.
+func MetricsAuthMiddleware(cfg MetricsAuthConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !cfg.Enabled() || metricsAuthorized(c.Request, cfg) {
+			c.Next()
+			return
+		}
+
+		if cfg.Username != "" {
+			c.Header("WWW-Authenticate", `Basic realm="metrics"`)
+		} else {
+			c.Header("WWW-Authenticate", `Bearer realm="metrics"`)
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		c.Abort()
+	}
+}
+
+func metricsAuthorized(r *http.Request, cfg MetricsAuthConfig) bool {
+	if cfg.BearerToken != "" {
+		if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok &&
+			subtle.ConstantTimeCompare([]byte(token), []byte(cfg.BearerToken)) == 1 {
+			return true
+		}
+	}
+	if cfg.Username != "" {
+		if username, password, ok := r.BasicAuth(); ok {
+			// Compare both so the timing does not reveal which one was wrong
+			userMatch := subtle.ConstantTimeCompare([]byte(username), []byte(cfg.Username))
+			passMatch := subtle.ConstantTimeCompare([]byte(password), []byte(cfg.Password))
+			return userMatch&passMatch == 1
+		}
+	}
+	return false
+}
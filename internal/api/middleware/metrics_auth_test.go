@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupMetricsRouter(cfg MetricsAuthConfig) *gin.Engine {
+	router := gin.New()
+	router.GET("/metrics", MetricsAuthMiddleware(cfg), func(c *gin.Context) {
+		c.String(http.StatusOK, "madabank_up 1")
+	})
+	return router
+}
+
+func scrapeMetrics(router *gin.Engine, setAuth func(*http.Request)) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", "/metrics", nil)
+	if setAuth != nil {
+		setAuth(req)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestMetricsAuthMiddleware_Disabled(t *testing.T) {
+	w := scrapeMetrics(setupMetricsRouter(MetricsAuthConfig{}), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestMetricsAuthMiddleware_BearerToken(t *testing.T) {
+	router := setupMetricsRouter(MetricsAuthConfig{BearerToken: "scrape-token"})
+
+	w := scrapeMetrics(router, nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, `Bearer realm="metrics"`, w.Header().Get("WWW-Authenticate"))
+
+	w = scrapeMetrics(router, func(r *http.Request) { r.Header.Set("Authorization", "Bearer wrong") })
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = scrapeMetrics(router, func(r *http.Request) { r.Header.Set("Authorization", "Bearer scrape-token") })
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestMetricsAuthMiddleware_BasicAuth(t *testing.T) {
+	router := setupMetricsRouter(MetricsAuthConfig{Username: "prometheus", Password: "s3cret"})
+
+	w := scrapeMetrics(router, nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, `Basic realm="metrics"`, w.Header().Get("WWW-Authenticate"))
+
+	w = scrapeMetrics(router, func(r *http.Request) { r.SetBasicAuth("prometheus", "wrong") })
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = scrapeMetrics(router, func(r *http.Request) { r.SetBasicAuth("prometheus", "s3cret") })
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestMetricsAuthMiddleware_EitherCredential(t *testing.T) {
+	router := setupMetricsRouter(MetricsAuthConfig{BearerToken: "scrape-token", Username: "prometheus", Password: "s3cret"})
+
+	w := scrapeMetrics(router, func(r *http.Request) { r.Header.Set("Authorization", "Bearer scrape-token") })
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = scrapeMetrics(router, func(r *http.Request) { r.SetBasicAuth("prometheus", "s3cret") })
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/darisadam/madabank-server/internal/pkg/logger"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -113,6 +114,55 @@ func TestMetricsMiddleware_RecordsErrorStatus(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 }
 
+func TestMetricsMiddleware_AttachesTraceExemplar(t *testing.T) {
+	router := gin.New()
+	router.Use(MetricsMiddleware())
+	router.GET("/traced", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	req, _ := http.NewRequest("GET", "/traced", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	assert.NoError(t, err)
+	var exemplars []string
+	for _, mf := range families {
+		if mf.GetName() != "madabank_http_request_duration_seconds" {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() != "endpoint" || l.GetValue() != "/traced" {
+					continue
+				}
+				for _, b := range m.GetHistogram().GetBucket() {
+					for _, el := range b.GetExemplar().GetLabel() {
+						exemplars = append(exemplars, el.GetName()+"="+el.GetValue())
+					}
+				}
+			}
+		}
+	}
+	assert.Contains(t, exemplars, "trace_id=4bf92f3577b34da6a3ce929d0e0e4736")
+}
+
+func TestTraceIDFromParent(t *testing.T) {
+	tests := map[string]string{
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01": "4bf92f3577b34da6a3ce929d0e0e4736",
+		"": "",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01": "",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01": "",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01": "",
+		"00-4bf92f3577b34da6-00f067aa0ba902b7-01":                 "",
+		"not a traceparent": "",
+	}
+	for header, want := range tests {
+		assert.Equal(t, want, traceIDFromParent(header), header)
+	}
+}
+
 // ==================== Maintenance Middleware Tests ====================
 
 func TestMaintenanceMiddleware_HealthEndpointAlwaysAccessible(t *testing.T) {
@@ -154,3 +204,20 @@ func TestMaintenanceMiddleware_MetricsEndpointAlwaysAccessible(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, w.Code)
 }
+
+// ==================== Recovery Middleware Tests ====================
+
+func TestRecoveryMiddleware_ReturnsInternalServerError(t *testing.T) {
+	router := gin.New()
+	router.Use(RecoveryMiddleware())
+	router.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	req, _ := http.NewRequest("GET", "/panic", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "internal server error")
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PartnerAuthMiddleware admits requests from trusted partners (such as card
+// acquirers) that present one of the configured keys in the X-API-Key header.
+func PartnerAuthMiddleware(apiKeys []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		presented := c.GetHeader("X-API-Key")
+		if presented == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "API key required"})
+			c.Abort()
+			return
+		}
+
+		valid := false
+		for _, key := range apiKeys {
+			if subtle.ConstantTimeCompare([]byte(presented), []byte(key)) == 1 {
+				valid = true
+			}
+		}
+
+		if !valid {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid API key"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/darisadam/madabank-server/internal/pkg/ddos"
 	"github.com/darisadam/madabank-server/internal/pkg/logger"
 	"github.com/darisadam/madabank-server/internal/pkg/metrics"
 	"github.com/darisadam/madabank-server/internal/pkg/ratelimit"
@@ -13,6 +14,18 @@ import (
 	"go.uber.org/zap"
 )
 
+// TrafficTrackingMiddleware counts every request per IP in the shared
+// counters the DDoS monitor scans. Requests rejected further down the chain
+// are counted too.
+func TrafficTrackingMiddleware(protection *ddos.DDoSProtection) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := protection.TrackRequest(context.Background(), c.ClientIP()); err != nil {
+			logger.Error("Failed to track request", zap.Error(err))
+		}
+		c.Next()
+	}
+}
+
 // RateLimitMiddleware applies rate limiting based on IP address and endpoint
 func RateLimitMiddleware(limiter *ratelimit.RateLimiter) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -33,6 +46,25 @@ func RateLimitMiddleware(limiter *ratelimit.RateLimiter) gin.HandlerFunc {
 		// Create rate limit key
 		key := fmt.Sprintf("ratelimit:%s:%s", clientIP, c.FullPath())
 
+		// Allowlisted networks are not counted; blocked ranges stay out
+		access, err := limiter.CheckIPAccess(ctx, clientIP)
+		if err != nil {
+			logger.Error("Failed to check IP access lists", zap.Error(err))
+		}
+
+		switch access {
+		case ratelimit.IPAccessAllowed:
+			c.Next()
+			return
+		case ratelimit.IPAccessBlocked:
+			logger.Warn("Request from blocked IP range", zap.String("ip", clientIP))
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Access from your network has been blocked.",
+			})
+			c.Abort()
+			return
+		}
+
 		// Check if IP is blocked
 		blocked, err := limiter.IsBlocked(ctx, clientIP)
 		if err != nil {
@@ -144,10 +176,11 @@ func getRateLimitConfig(path string) ratelimit.RateLimitConfig {
 	}
 }
 
-// SuspiciousActivityMiddleware detects and blocks suspicious patterns
+// SuspiciousActivityMiddleware detects and blocks suspicious patterns: an
+// IP is blocked for an hour after 5 failed logins, or after 10 requests
+// flagged by BotDetectionMiddleware, within 15 minutes
 func SuspiciousActivityMiddleware(limiter *ratelimit.RateLimiter) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx := context.Background()
 		clientIP := c.ClientIP()
 
 		// Check for suspicious patterns after request completes
@@ -158,31 +191,49 @@ func SuspiciousActivityMiddleware(limiter *ratelimit.RateLimiter) gin.HandlerFun
 
 		if status == http.StatusUnauthorized && c.FullPath() == "/api/v1/auth/login" {
 			// Failed login attempt
-			key := fmt.Sprintf("suspicious:login:%s", clientIP)
+			recordSuspicious(limiter, clientIP, "login", 5, "multiple failed login attempts")
+		}
 
-			allowed, err := limiter.CheckLimit(ctx, key, ratelimit.RateLimitConfig{
-				Requests: 5,
-				Window:   15 * time.Minute,
-			})
+		if c.GetBool("bot_flagged") {
+			recordSuspicious(limiter, clientIP, "bot", 10, "repeated automated requests")
+		}
+	}
+}
 
-			if err != nil {
-				logger.Error("Suspicious activity check failed", zap.Error(err))
-				return
-			}
+// recordSuspicious counts a suspicious event of the kind for the IP and
+// blocks the IP for an hour once more than limit happened in 15 minutes
+func recordSuspicious(limiter *ratelimit.RateLimiter, clientIP, kind string, limit int, reason string) {
+	ctx := context.Background()
+	key := fmt.Sprintf("suspicious:%s:%s", kind, clientIP)
 
-			if !allowed {
-				// Block IP for 1 hour after 5 failed attempts
-				logger.Warn("Blocking IP due to multiple failed login attempts",
-					zap.String("ip", clientIP),
-				)
+	allowed, err := limiter.CheckLimit(ctx, key, ratelimit.RateLimitConfig{
+		Requests: limit,
+		Window:   15 * time.Minute,
+	})
 
-				err := limiter.Block(ctx, clientIP, time.Hour)
-				if err != nil {
-					logger.Error("Failed to block IP", zap.Error(err))
-				}
+	if err != nil {
+		logger.Error("Suspicious activity check failed", zap.Error(err))
+		return
+	}
 
-				// TODO: Send alert to security team
-			}
-		}
+	if allowed {
+		return
+	}
+
+	// Allowlisted networks are never blocked
+	if access, err := limiter.CheckIPAccess(ctx, clientIP); err != nil {
+		logger.Error("Failed to check IP access lists", zap.Error(err))
+	} else if access == ratelimit.IPAccessAllowed {
+		return
 	}
+
+	logger.Warn("Blocking IP due to "+reason,
+		zap.String("ip", clientIP),
+	)
+
+	if err := limiter.Block(ctx, clientIP, time.Hour); err != nil {
+		logger.Error("Failed to block IP", zap.Error(err))
+	}
+
+	// TODO: Send alert to security team
 }
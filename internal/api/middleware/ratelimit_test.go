@@ -1,11 +1,14 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/alicebob/miniredis/v2"
+	"github.com/darisadam/madabank-server/internal/pkg/ddos"
 	"github.com/darisadam/madabank-server/internal/pkg/logger"
 	"github.com/darisadam/madabank-server/internal/pkg/ratelimit"
 	"github.com/gin-gonic/gin"
@@ -72,6 +75,54 @@ func TestRateLimitMiddleware_SetsHeaders(t *testing.T) {
 	assert.NotEmpty(t, w.Header().Get("X-RateLimit-Reset"))
 }
 
+func TestRateLimitMiddleware_AllowlistSkipsLimit(t *testing.T) {
+	mr, limiter := setupRateLimitTest(t)
+	defer mr.Close()
+	ctx := context.Background()
+	_, err := limiter.Allow(ctx, "203.0.113.0/24")
+	assert.NoError(t, err)
+	// Even a temporary block does not apply to an allowlisted IP
+	assert.NoError(t, limiter.Block(ctx, "203.0.113.5", time.Hour))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RateLimitMiddleware(limiter))
+	router.GET("/api/v1/auth/login", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+
+	for i := 0; i < ratelimit.AuthRateLimit.Requests+1; i++ {
+		req, _ := http.NewRequest("GET", "/api/v1/auth/login", nil)
+		req.RemoteAddr = "203.0.113.5:12345"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("X-RateLimit-Limit"))
+	}
+}
+
+func TestRateLimitMiddleware_BlockedRange(t *testing.T) {
+	mr, limiter := setupRateLimitTest(t)
+	defer mr.Close()
+	_, err := limiter.BlockRange(context.Background(), "198.51.100.0/24")
+	assert.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RateLimitMiddleware(limiter))
+	router.GET("/api/v1/test", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+
+	req, _ := http.NewRequest("GET", "/api/v1/test", nil)
+	req.RemoteAddr = "198.51.100.20:12345"
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
 // ==================== UserRateLimitMiddleware Tests ====================
 
 func TestUserRateLimitMiddleware_NoUserID(t *testing.T) {
@@ -114,6 +165,33 @@ func TestSuspiciousActivityMiddleware_PassesNormalRequest(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 }
 
+// ==================== TrafficTrackingMiddleware Tests ====================
+
+func TestTrafficTrackingMiddleware_CountsRequests(t *testing.T) {
+	logger.Init("test")
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer mr.Close()
+	protection := ddos.NewDDoSProtection(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(TrafficTrackingMiddleware(protection))
+	router.GET("/test", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for i := 0; i < 3; i++ {
+		req, _ := http.NewRequest("GET", "/test", nil)
+		req.RemoteAddr = "10.0.0.1:12345"
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	count, err := mr.Get("ddos:requests:10.0.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, "3", count)
+}
+
 // ==================== getRateLimitConfig Tests ====================
 
 func TestGetRateLimitConfig_AuthEndpoints(t *testing.T) {
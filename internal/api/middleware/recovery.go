@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RecoveryMiddleware replaces gin.Recovery: panics are logged, reported to the
+// error tracker and turned into a generic 500 response.
+func RecoveryMiddleware() gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
+		logger.Error("Panic recovered",
+			zap.Any("panic", recovered),
+			zap.String("method", c.Request.Method),
+			zap.String("path", c.FullPath()),
+		)
+		errtrack.CapturePanic(recovered, map[string]string{
+			"method": c.Request.Method,
+			"route":  c.FullPath(),
+		})
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	})
+}
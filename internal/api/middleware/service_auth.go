@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/darisadam/madabank-server/internal/pkg/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+// ServiceAuthMiddleware admits internal services presenting a client-credential
+// token that grants every one of scopes, and sets client_id in the context.
+// Customer tokens are never accepted.
+func ServiceAuthMiddleware(tokens *jwt.ServiceTokenService, scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			c.Header("WWW-Authenticate", `Bearer realm="services"`)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "service token required"})
+			c.Abort()
+			return
+		}
+
+		claims, err := tokens.ValidateServiceToken(token)
+		if err != nil {
+			c.Header("WWW-Authenticate", `Bearer realm="services", error="invalid_token"`)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired service token"})
+			c.Abort()
+			return
+		}
+
+		for _, scope := range scopes {
+			if !claims.HasScope(scope) {
+				c.Header("WWW-Authenticate", `Bearer realm="services", error="insufficient_scope", scope="`+strings.Join(scopes, " ")+`"`)
+				c.JSON(http.StatusForbidden, gin.H{"error": "insufficient scope"})
+				c.Abort()
+				return
+			}
+		}
+
+		c.Set("client_id", claims.ClientID)
+		c.Next()
+	}
+}
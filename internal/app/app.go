@@ -0,0 +1,199 @@
+// Package app wires the API server together: configuration from the
+// environment, the database and Redis, repositories, services, background
+// jobs and the HTTP router. cmd/api only loads the environment and runs the
+// App; integration tests build the same server around their own database and
+// Redis.
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/darisadam/madabank-server/internal/pkg/dbmigrate"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+)
+
+// BuildInfo identifies the running binary on /version and /
+type BuildInfo struct {
+	Version   string
+	CommitSHA string
+	BuildTime string
+}
+
+// Options configure an App. Everything not set here is read from the
+// environment.
+type Options struct {
+	// Env is the deployment environment, "production" enables gin's release
+	// mode and the production-only warnings
+	Env   string
+	Build BuildInfo
+
+	// DB and Redis are used instead of connecting from the DATABASE_URL/DB_*
+	// and REDIS_* variables. The App does not close clients it was given.
+	DB    *sql.DB
+	Redis *redis.Client
+
+	// DisableJobs skips the job queue, the scheduler and the other background
+	// workers, leaving only the HTTP server
+	DisableJobs bool
+}
+
+// App is the assembled API server
+type App struct {
+	env   string
+	build BuildInfo
+	db    *sql.DB
+	redis *redis.Client
+
+	// owned are the connections New opened, closed by Shutdown
+	owned []io.Closer
+
+	// ctx is the lifetime of background work, cancelled by Shutdown
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	components
+	repos    *repositories
+	services *services
+
+	router        *gin.Engine
+	server        *http.Server
+	metricsServer *http.Server
+}
+
+// New connects to the database and Redis, builds every repository, service
+// and handler, starts the background jobs and prepares the HTTP servers.
+// Nothing is listening until Run is called.
+func New(opts Options) (*App, error) {
+	env := opts.Env
+	if env == "" {
+		env = "development"
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	a := &App{
+		env:    env,
+		build:  opts.Build,
+		db:     opts.DB,
+		redis:  opts.Redis,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	if err := a.init(opts); err != nil {
+		a.cancel()
+		a.closeOwned()
+		return nil, err
+	}
+	return a, nil
+}
+
+func (a *App) init(opts Options) error {
+	if a.db == nil {
+		db, err := openDB()
+		if err != nil {
+			return fmt.Errorf("failed to connect to database: %w", err)
+		}
+		a.db = db
+		a.owned = append(a.owned, db)
+		logger.Info("Connected to database successfully")
+	}
+
+	// Optionally apply pending migrations (embedded in the binary)
+	if os.Getenv("AUTO_MIGRATE") == "true" {
+		version, err := dbmigrate.Up(a.db)
+		if err != nil {
+			return fmt.Errorf("failed to run database migrations: %w", err)
+		}
+		logger.Info("Database migrations applied", zap.Uint("version", version))
+	}
+
+	if a.redis == nil {
+		client, err := openRedis()
+		if err != nil {
+			return err
+		}
+		a.redis = client
+		a.owned = append(a.owned, client)
+	}
+
+	if err := a.initComponents(); err != nil {
+		return err
+	}
+	isolation, err := txIsolationFromEnv()
+	if err != nil {
+		return err
+	}
+	a.repos = newRepositories(a.ctx, a.db, isolation)
+	if err := a.initCache(); err != nil {
+		return err
+	}
+	if err := a.initServices(); err != nil {
+		return err
+	}
+	if !opts.DisableJobs {
+		if err := a.startJobs(); err != nil {
+			return err
+		}
+	}
+	return a.initServers()
+}
+
+// Handler returns the API router, for serving the App from httptest
+func (a *App) Handler() http.Handler {
+	return a.router
+}
+
+// Run serves the API, and the metrics endpoint when METRICS_LISTEN_ADDR is
+// set, until Shutdown is called. It returns nil after a graceful shutdown
+// and the error of whichever listener failed otherwise.
+func (a *App) Run() error {
+	errs := make(chan error, 2)
+	serve := func(srv *http.Server) {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errs <- err
+			return
+		}
+		errs <- nil
+	}
+
+	if a.metricsServer != nil {
+		logger.Info("Metrics server starting", zap.String("addr", a.metricsServer.Addr))
+		go serve(a.metricsServer)
+	}
+	logger.Info(fmt.Sprintf("Server starting on %s", a.server.Addr))
+	go serve(a.server)
+
+	return <-errs
+}
+
+// Shutdown stops the background jobs, lets in-flight requests finish until
+// ctx expires and closes the connections New opened
+func (a *App) Shutdown(ctx context.Context) error {
+	a.cancel()
+
+	err := a.server.Shutdown(ctx)
+	if a.metricsServer != nil {
+		if metricsErr := a.metricsServer.Shutdown(ctx); metricsErr != nil {
+			logger.Error("Metrics server forced to shutdown", zap.Error(metricsErr))
+		}
+	}
+	a.closeOwned()
+	return err
+}
+
+func (a *App) closeOwned() {
+	for i := len(a.owned) - 1; i >= 0; i-- {
+		if err := a.owned[i].Close(); err != nil {
+			logger.Error("Failed to close connection", zap.Error(err))
+		}
+	}
+	a.owned = nil
+}
@@ -0,0 +1,208 @@
+package app
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/darisadam/madabank-server/internal/domain/user"
+	"github.com/darisadam/madabank-server/internal/pkg/billeragg"
+	"github.com/darisadam/madabank-server/internal/pkg/botdetect"
+	"github.com/darisadam/madabank-server/internal/pkg/crypto"
+	"github.com/darisadam/madabank-server/internal/pkg/ddos"
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
+	"github.com/darisadam/madabank-server/internal/pkg/fxrates"
+	"github.com/darisadam/madabank-server/internal/pkg/geoip"
+	"github.com/darisadam/madabank-server/internal/pkg/jwt"
+	"github.com/darisadam/madabank-server/internal/pkg/keyprovider"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/pkg/loglevel"
+	"github.com/darisadam/madabank-server/internal/pkg/metricspush"
+	"github.com/darisadam/madabank-server/internal/pkg/notifier"
+	"github.com/darisadam/madabank-server/internal/pkg/passwordpolicy"
+	"github.com/darisadam/madabank-server/internal/pkg/ratelimit"
+	"github.com/darisadam/madabank-server/internal/pkg/topupagg"
+)
+
+// components are the clients and security primitives shared by services
+// and middleware
+type components struct {
+	rateLimiter    *ratelimit.RateLimiter
+	ddosProtection *ddos.DDoSProtection
+	logLevels      *loglevel.Controller
+	encryptor      *crypto.Encryptor
+	jwtService     *jwt.JWTService
+	tokenTTLs      user.TokenTTLs
+	serviceTokens  *jwt.ServiceTokenService // nil when no service clients are configured
+	serviceClients []user.ServiceClient
+
+	billerAggregator billeragg.Aggregator
+	topupAggregator  topupagg.Aggregator
+	fxRates          *fxrates.Cache
+	emailNotifier    notifier.Notifier
+	smsSender        notifier.SMSSender
+	passwordPolicy   *passwordpolicy.Validator
+
+	geoResolver      geoip.Resolver // nil when the GeoIP policy is off
+	geoPolicy        geoip.Policy
+	metricsPublisher metricspush.Publisher     // nil when batch metrics are not pushed
+	botDetector      *botdetect.Detector       // nil when bot detection is off
+	captchaVerifier  botdetect.CaptchaVerifier // nil when no CAPTCHA is configured
+
+	// accountingZone is where business days roll over for postings and reports
+	accountingZone *time.Location
+}
+
+func (a *App) initComponents() error {
+	// Initialize rate limiter
+	rateLimitAlgorithm, err := ratelimit.ParseAlgorithm(os.Getenv("RATE_LIMIT_ALGORITHM"))
+	if err != nil {
+		return fmt.Errorf("invalid rate limit algorithm: %w", err)
+	}
+	a.rateLimiter = ratelimit.NewRateLimiterWithAlgorithm(a.redis, rateLimitAlgorithm)
+	logger.Info("Rate limiter configured", zap.String("algorithm", string(rateLimitAlgorithm)))
+
+	// Initialize DDoS protection
+	ddosConfig, err := ddos.ConfigFromEnv()
+	if err != nil {
+		return fmt.Errorf("invalid DDoS protection config: %w", err)
+	}
+	a.ddosProtection = ddos.NewDDoSProtectionWithConfig(a.redis, ddosConfig)
+	go func() {
+		defer errtrack.RecoverWorker("ddos_monitor")
+		a.ddosProtection.MonitorGlobalTraffic(a.ctx)
+	}()
+
+	// Pick up log level changes made on other replicas
+	a.logLevels = loglevel.NewController(a.redis)
+	if _, err := a.logLevels.Refresh(a.ctx); err != nil {
+		logger.Error("Failed to load log level override", zap.Error(err))
+	}
+	go func() {
+		defer errtrack.RecoverWorker("log_level_watcher")
+		a.logLevels.Watch(a.ctx, loglevel.DefaultRefreshInterval)
+	}()
+
+	// Initialize encryptor for card data
+	keyProvider, err := keyprovider.FromEnv(a.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize encryption key provider: %w", err)
+	}
+	dataKey, err := keyProvider.DataKey(a.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to unwrap data encryption key with %s: %w", keyProvider.Name(), err)
+	}
+	a.encryptor, err = crypto.NewEncryptor(string(dataKey))
+	if err != nil {
+		return fmt.Errorf("failed to initialize encryptor: %w", err)
+	}
+	logger.Info("Data encryption key loaded", zap.String("provider", keyProvider.Name()))
+
+	// Configure password hashing cost
+	if err := crypto.SetArgon2Params(argon2ParamsFromEnv()); err != nil {
+		return fmt.Errorf("invalid Argon2 parameters: %w", err)
+	}
+
+	// Initialize JWT service
+	jwtExpiryHours, _ := strconv.Atoi(os.Getenv("JWT_EXPIRY_HOURS"))
+	if jwtExpiryHours == 0 {
+		jwtExpiryHours = 24
+	}
+	a.tokenTTLs, err = tokenTTLsFromEnv(time.Duration(jwtExpiryHours) * time.Hour)
+	if err != nil {
+		return fmt.Errorf("invalid token lifetimes: %w", err)
+	}
+	// Retired signing keys must outlive the longest-lived token
+	a.jwtService, err = initJWTService(int(math.Ceil(a.tokenTTLs.MaxAccess().Hours())))
+	if err != nil {
+		return fmt.Errorf("failed to initialize JWT service: %w", err)
+	}
+	if keysFile := os.Getenv("JWT_KEYS_FILE"); keysFile != "" {
+		go watchJWTKeys(a.ctx, keysFile, a.jwtService)
+	}
+	a.serviceTokens, a.serviceClients, err = serviceAuthFromEnv()
+	if err != nil {
+		return fmt.Errorf("failed to initialize service authentication: %w", err)
+	}
+
+	a.billerAggregator, err = billeragg.FromEnv()
+	if err != nil {
+		return fmt.Errorf("failed to initialize biller aggregator: %w", err)
+	}
+	logger.Info("Biller aggregator configured", zap.String("aggregator", a.billerAggregator.Name()))
+
+	a.topupAggregator, err = topupagg.FromEnv()
+	if err != nil {
+		return fmt.Errorf("failed to initialize top-up aggregator: %w", err)
+	}
+	logger.Info("Top-up aggregator configured", zap.String("aggregator", a.topupAggregator.Name()))
+
+	fxProvider, err := fxrates.FromEnv()
+	if err != nil {
+		return fmt.Errorf("failed to initialize FX rate provider: %w", err)
+	}
+	fxConfig, err := fxrates.ConfigFromEnv()
+	if err != nil {
+		return fmt.Errorf("invalid FX rates config: %w", err)
+	}
+	a.fxRates = fxrates.NewCache(fxProvider, a.redis, fxConfig)
+	logger.Info("FX rate provider configured", zap.String("provider", fxProvider.Name()), zap.Duration("ttl", fxConfig.TTL))
+
+	a.emailNotifier, err = notifier.FromEnv()
+	if err != nil {
+		return fmt.Errorf("failed to initialize email notifier: %w", err)
+	}
+	logger.Info("Email notifier configured", zap.String("notifier", a.emailNotifier.Name()))
+
+	a.smsSender, err = notifier.SMSFromEnv()
+	if err != nil {
+		return fmt.Errorf("failed to initialize SMS notifier: %w", err)
+	}
+	logger.Info("SMS notifier configured", zap.String("notifier", a.smsSender.Name()))
+
+	a.passwordPolicy, err = passwordpolicy.FromEnv()
+	if err != nil {
+		return fmt.Errorf("invalid password policy: %w", err)
+	}
+	logger.Info("Password policy configured", zap.Int("min_length", a.passwordPolicy.Policy().MinLength), zap.Bool("breach_check", os.Getenv("PASSWORD_BREACH_CHECK") == "true"))
+
+	a.geoResolver, a.geoPolicy, err = geoip.FromEnv()
+	if err != nil {
+		return fmt.Errorf("invalid GeoIP configuration: %w", err)
+	}
+	if a.geoResolver != nil {
+		logger.Info("GeoIP access policy enabled", zap.String("resolver", a.geoResolver.Name()),
+			zap.Int("blocked_countries", len(a.geoPolicy.Block)), zap.Int("challenged_countries", len(a.geoPolicy.Challenge)))
+	}
+
+	a.metricsPublisher, err = metricspush.FromEnv()
+	if err != nil {
+		return fmt.Errorf("invalid metrics push configuration: %w", err)
+	}
+	if a.metricsPublisher != nil {
+		logger.Info("Batch job metrics are pushed", zap.String("publisher", a.metricsPublisher.Name()))
+	}
+
+	botConfig, botDetection, err := botdetect.ConfigFromEnv()
+	if err != nil {
+		return fmt.Errorf("invalid bot detection configuration: %w", err)
+	}
+	if botDetection {
+		a.botDetector, err = botdetect.NewDetector(a.redis, botConfig)
+		if err != nil {
+			return fmt.Errorf("failed to initialize bot detection: %w", err)
+		}
+		a.captchaVerifier, err = botdetect.CaptchaFromEnv()
+		if err != nil {
+			return fmt.Errorf("invalid CAPTCHA configuration: %w", err)
+		}
+		logger.Info("Bot detection enabled", zap.Int("challenge_score", botConfig.ChallengeScore),
+			zap.Int("deny_score", botConfig.DenyScore), zap.Bool("captcha", a.captchaVerifier != nil))
+	}
+
+	return nil
+}
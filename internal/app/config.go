@@ -0,0 +1,479 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/darisadam/madabank-server/internal/api/middleware"
+	"github.com/darisadam/madabank-server/internal/domain/card"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/domain/user"
+	"github.com/darisadam/madabank-server/internal/jobs"
+	"github.com/darisadam/madabank-server/internal/pkg/cache"
+	"github.com/darisadam/madabank-server/internal/pkg/crypto"
+	"github.com/darisadam/madabank-server/internal/pkg/jwt"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/pkg/objectstore"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/darisadam/madabank-server/internal/service"
+)
+
+// jobQueueConfigFromEnv reads the job queue worker settings from
+// JOB_QUEUE_WORKERS and JOB_QUEUE_POLL_INTERVAL, leaving defaults when unset.
+func jobQueueConfigFromEnv() jobs.QueueConfig {
+	var cfg jobs.QueueConfig
+	if v, err := strconv.Atoi(os.Getenv("JOB_QUEUE_WORKERS")); err == nil {
+		cfg.Workers = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("JOB_QUEUE_POLL_INTERVAL")); err == nil {
+		cfg.PollInterval = v
+	}
+	return cfg
+}
+
+// systemMetricsIntervalFromEnv reads how often the business metrics are
+// refreshed from SYSTEM_METRICS_INTERVAL
+func systemMetricsIntervalFromEnv() time.Duration {
+	if v, err := time.ParseDuration(os.Getenv("SYSTEM_METRICS_INTERVAL")); err == nil && v > 0 {
+		return v
+	}
+	return jobs.DefaultSystemMetricsInterval
+}
+
+// schedulerLeaderTTLFromEnv reads how long the scheduler leader's lease lasts
+// without renewal, which bounds how long scheduled tasks pause after the
+// leader dies
+func schedulerLeaderTTLFromEnv() time.Duration {
+	if v, err := time.ParseDuration(os.Getenv("SCHEDULER_LEADER_TTL")); err == nil && v > 0 {
+		return v
+	}
+	return 30 * time.Second
+}
+
+// initAuditArchiver configures the audit log retention job. It is disabled
+// unless AUDIT_RETENTION_DAYS is set; archives go to AUDIT_ARCHIVE_BUCKET (S3)
+// or, for development, to the local AUDIT_ARCHIVE_DIR.
+func initAuditArchiver(ctx context.Context, auditRepo repository.AuditRepository) *jobs.AuditArchiver {
+	retentionDays, _ := strconv.Atoi(os.Getenv("AUDIT_RETENTION_DAYS"))
+	if retentionDays <= 0 {
+		return nil
+	}
+
+	var store objectstore.Store
+	if bucket := os.Getenv("AUDIT_ARCHIVE_BUCKET"); bucket != "" {
+		s3Store, err := objectstore.NewS3Store(ctx, bucket, os.Getenv("AUDIT_ARCHIVE_PREFIX"))
+		if err != nil {
+			logger.Error("Failed to initialize audit archive storage", zap.Error(err))
+			return nil
+		}
+		store = s3Store
+	} else if dir := os.Getenv("AUDIT_ARCHIVE_DIR"); dir != "" {
+		store = objectstore.NewFileStore(dir)
+	} else {
+		logger.Warn("AUDIT_RETENTION_DAYS is set but no archive storage is configured; audit archiving disabled")
+		return nil
+	}
+
+	interval, err := time.ParseDuration(os.Getenv("AUDIT_ARCHIVE_INTERVAL"))
+	if err != nil {
+		interval = 24 * time.Hour
+	}
+	batchSize, _ := strconv.Atoi(os.Getenv("AUDIT_ARCHIVE_BATCH_SIZE"))
+
+	logger.Info("Audit log archiving enabled",
+		zap.Int("retention_days", retentionDays),
+		zap.String("storage", store.Name()),
+		zap.Duration("interval", interval),
+	)
+
+	return jobs.NewAuditArchiver(auditRepo, store, jobs.AuditArchiveConfig{
+		RetentionDays: retentionDays,
+		BatchSize:     batchSize,
+		Interval:      interval,
+	})
+}
+
+// initUserAnonymizer configures the deleted user retention job. It is
+// disabled unless USER_RETENTION_DAYS is set.
+func initUserAnonymizer(userRepo repository.UserRepository) *jobs.UserAnonymizer {
+	retentionDays, _ := strconv.Atoi(os.Getenv("USER_RETENTION_DAYS"))
+	if retentionDays <= 0 {
+		return nil
+	}
+	if retentionDays < user.ReactivationWindowDays {
+		logger.Warn("USER_RETENTION_DAYS is shorter than the reactivation window; deleted users may be anonymized before they can reactivate",
+			zap.Int("retention_days", retentionDays),
+			zap.Int("reactivation_window_days", user.ReactivationWindowDays),
+		)
+	}
+	batchSize, _ := strconv.Atoi(os.Getenv("USER_ANONYMIZE_BATCH_SIZE"))
+
+	logger.Info("Deleted user anonymization enabled", zap.Int("retention_days", retentionDays))
+
+	return jobs.NewUserAnonymizer(userRepo, jobs.UserAnonymizeConfig{
+		RetentionDays: retentionDays,
+		BatchSize:     batchSize,
+	})
+}
+
+// transactionArchiveStoreFromEnv returns the cold storage for archived
+// transaction partitions: TRANSACTION_ARCHIVE_BUCKET (S3) or, for development,
+// the local TRANSACTION_ARCHIVE_DIR. It returns nil when neither is set.
+func transactionArchiveStoreFromEnv(ctx context.Context) objectstore.Store {
+	if bucket := os.Getenv("TRANSACTION_ARCHIVE_BUCKET"); bucket != "" {
+		s3Store, err := objectstore.NewS3Store(ctx, bucket, os.Getenv("TRANSACTION_ARCHIVE_PREFIX"))
+		if err != nil {
+			logger.Error("Failed to initialize transaction archive storage", zap.Error(err))
+			return nil
+		}
+		return s3Store
+	}
+	if dir := os.Getenv("TRANSACTION_ARCHIVE_DIR"); dir != "" {
+		return objectstore.NewFileStore(dir)
+	}
+	return nil
+}
+
+// exportStoreFromEnv returns the storage admin bulk exports are written to:
+// EXPORT_BUCKET (S3) or, for development, the local EXPORT_DIR. It returns
+// nil when neither is set, which turns exports off.
+func exportStoreFromEnv(ctx context.Context) objectstore.Store {
+	if bucket := os.Getenv("EXPORT_BUCKET"); bucket != "" {
+		s3Store, err := objectstore.NewS3Store(ctx, bucket, os.Getenv("EXPORT_PREFIX"))
+		if err != nil {
+			logger.Error("Failed to initialize export storage", zap.Error(err))
+			return nil
+		}
+		return s3Store
+	}
+	if dir := os.Getenv("EXPORT_DIR"); dir != "" {
+		return objectstore.NewFileStore(dir)
+	}
+	return nil
+}
+
+// initTransactionArchiver configures monthly partition maintenance, which
+// always runs. Partitions older than TRANSACTION_RETENTION_MONTHS are moved
+// to the archive store when both are configured.
+func initTransactionArchiver(archiveRepo repository.TransactionArchiveRepository, store objectstore.Store) *jobs.TransactionArchiver {
+	retentionMonths, _ := strconv.Atoi(os.Getenv("TRANSACTION_RETENTION_MONTHS"))
+	interval, err := time.ParseDuration(os.Getenv("TRANSACTION_ARCHIVE_INTERVAL"))
+	if err != nil {
+		interval = 24 * time.Hour
+	}
+
+	switch {
+	case retentionMonths > 0 && store == nil:
+		logger.Warn("TRANSACTION_RETENTION_MONTHS is set but no archive storage is configured; transaction archiving disabled")
+	case retentionMonths > 0:
+		logger.Info("Transaction archiving enabled",
+			zap.Int("retention_months", retentionMonths),
+			zap.String("storage", store.Name()),
+			zap.Duration("interval", interval),
+		)
+	}
+
+	return jobs.NewTransactionArchiver(archiveRepo, store, jobs.TransactionArchiveConfig{
+		RetentionMonths: retentionMonths,
+		Interval:        interval,
+	})
+}
+
+// argon2ParamsFromEnv overrides the default Argon2id cost with
+// ARGON2_MEMORY_KB, ARGON2_ITERATIONS and ARGON2_PARALLELISM when set.
+func argon2ParamsFromEnv() crypto.Argon2Params {
+	params := crypto.DefaultArgon2Params
+	if v, err := strconv.ParseUint(os.Getenv("ARGON2_MEMORY_KB"), 10, 32); err == nil {
+		params.Memory = uint32(v)
+	}
+	if v, err := strconv.ParseUint(os.Getenv("ARGON2_ITERATIONS"), 10, 32); err == nil {
+		params.Iterations = uint32(v)
+	}
+	if v, err := strconv.ParseUint(os.Getenv("ARGON2_PARALLELISM"), 10, 8); err == nil {
+		params.Parallelism = uint8(v)
+	}
+	return params
+}
+
+// cardLimitsFromEnv overrides the default card limits with CARD_MAX_PER_ACCOUNT
+// and CARD_MAX_PER_TYPE (for example "debit:2,credit:1") when set.
+func cardLimitsFromEnv() service.CardLimits {
+	limits := service.DefaultCardLimits()
+	if v, err := strconv.Atoi(os.Getenv("CARD_MAX_PER_ACCOUNT")); err == nil {
+		limits.MaxPerAccount = v
+	}
+	for _, entry := range strings.Split(os.Getenv("CARD_MAX_PER_TYPE"), ",") {
+		cardType, max, found := strings.Cut(strings.TrimSpace(entry), ":")
+		if !found {
+			continue
+		}
+		v, err := strconv.Atoi(max)
+		if err != nil {
+			logger.Warn("Ignoring invalid CARD_MAX_PER_TYPE entry", zap.String("entry", entry))
+			continue
+		}
+		limits.MaxPerType[card.CardType(cardType)] = v
+	}
+	return limits
+}
+
+// regulatoryReportConfigFromEnv reads the reporting entity ID and the large
+// transaction thresholds, in total and per transaction type
+func regulatoryReportConfigFromEnv() service.RegulatoryReportConfig {
+	config := service.DefaultRegulatoryReportConfig()
+	config.EntityID = os.Getenv("REGULATORY_ENTITY_ID")
+	if v, err := strconv.ParseFloat(os.Getenv("REGULATORY_LARGE_TXN_THRESHOLD"), 64); err == nil && v > 0 {
+		config.Thresholds.Default = v
+	}
+	for _, entry := range strings.Split(os.Getenv("REGULATORY_LARGE_TXN_THRESHOLD_PER_TYPE"), ",") {
+		txnType, threshold, found := strings.Cut(strings.TrimSpace(entry), ":")
+		if !found {
+			continue
+		}
+		v, err := strconv.ParseFloat(threshold, 64)
+		if err != nil || v <= 0 {
+			logger.Warn("Ignoring invalid REGULATORY_LARGE_TXN_THRESHOLD_PER_TYPE entry", zap.String("entry", entry))
+			continue
+		}
+		config.Thresholds.PerType[transaction.TransactionType(txnType)] = v
+	}
+	return config
+}
+
+// receiptConfigFromEnv reads the bank name printed on receipts and statements,
+// and the secret, public URL and lifetime of shared receipt links. Sharing
+// stays off until RECEIPT_SHARE_SECRET and RECEIPT_SHARE_BASE_URL are both set.
+func receiptConfigFromEnv() service.ReceiptConfig {
+	config := service.DefaultReceiptConfig()
+	if name := os.Getenv("BANK_NAME"); name != "" {
+		config.BankName = name
+	}
+	config.ShareSecret = []byte(os.Getenv("RECEIPT_SHARE_SECRET"))
+	config.ShareBaseURL = os.Getenv("RECEIPT_SHARE_BASE_URL")
+	if v, err := time.ParseDuration(os.Getenv("RECEIPT_SHARE_TTL")); err == nil && v > 0 {
+		config.ShareTTL = v
+	}
+	return config
+}
+
+// resetLinkConfigFromEnv reads the secret and web page of password reset
+// links. Links stay off until PASSWORD_RESET_LINK_SECRET and
+// PASSWORD_RESET_LINK_BASE_URL are both set; the OTP works either way.
+func resetLinkConfigFromEnv() service.ResetLinkConfig {
+	return service.ResetLinkConfig{
+		Secret:  []byte(os.Getenv("PASSWORD_RESET_LINK_SECRET")),
+		BaseURL: os.Getenv("PASSWORD_RESET_LINK_BASE_URL"),
+	}
+}
+
+// timezoneFromEnv loads the time zone named by envVar, falling back to the
+// given default. Card daily limits, merchant and reconciliation business days,
+// GL export and regulatory report dates and loan due dates all roll over at
+// midnight in their configured zone.
+func timezoneFromEnv(envVar, fallback string) (*time.Location, error) {
+	name := os.Getenv(envVar)
+	if name == "" {
+		name = fallback
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q: %w", envVar, name, err)
+	}
+	return loc, nil
+}
+
+// metricsAuthFromEnv reads the credentials required on /metrics: a bearer
+// token from METRICS_AUTH_TOKEN and/or basic auth from METRICS_AUTH_USERNAME
+// and METRICS_AUTH_PASSWORD
+func metricsAuthFromEnv() (middleware.MetricsAuthConfig, error) {
+	cfg := middleware.MetricsAuthConfig{
+		BearerToken: os.Getenv("METRICS_AUTH_TOKEN"),
+		Username:    os.Getenv("METRICS_AUTH_USERNAME"),
+		Password:    os.Getenv("METRICS_AUTH_PASSWORD"),
+	}
+	if (cfg.Username == "") != (cfg.Password == "") {
+		return cfg, fmt.Errorf("METRICS_AUTH_USERNAME and METRICS_AUTH_PASSWORD must be set together")
+	}
+	return cfg, nil
+}
+
+// bodyLimitFromEnv reads the request body limits in bytes from
+// MAX_BODY_BYTES and, for the auth endpoints, AUTH_MAX_BODY_BYTES
+func bodyLimitFromEnv() (middleware.BodyLimitConfig, error) {
+	cfg := middleware.DefaultBodyLimitConfig()
+	for env, limit := range map[string]*int64{
+		"MAX_BODY_BYTES":      &cfg.MaxBytes,
+		"AUTH_MAX_BODY_BYTES": &cfg.AuthMaxBytes,
+	} {
+		v := os.Getenv(env)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			return cfg, fmt.Errorf("invalid %s %q", env, v)
+		}
+		*limit = n
+	}
+	return cfg, nil
+}
+
+// cacheTTLFromEnv reads how long account and user rows stay cached from
+// CACHE_TTL; 0 turns the cache off
+func cacheTTLFromEnv() (time.Duration, error) {
+	v := os.Getenv("CACHE_TTL")
+	if v == "" {
+		return cache.DefaultTTL, nil
+	}
+	ttl, err := time.ParseDuration(v)
+	if err != nil || ttl < 0 {
+		return 0, fmt.Errorf("invalid CACHE_TTL %q", v)
+	}
+	return ttl, nil
+}
+
+// txIsolationLevels are the DB_TX_ISOLATION values
+var txIsolationLevels = map[string]sql.IsolationLevel{
+	"read_committed":  sql.LevelReadCommitted,
+	"repeatable_read": sql.LevelRepeatableRead,
+	"serializable":    sql.LevelSerializable,
+}
+
+// txIsolationFromEnv reads the isolation level of transfers, deposits and
+// withdrawals from DB_TX_ISOLATION, read_committed by default. The stricter
+// levels abort more transactions with serialization failures, which are
+// retried.
+func txIsolationFromEnv() (sql.IsolationLevel, error) {
+	v := os.Getenv("DB_TX_ISOLATION")
+	if v == "" {
+		return sql.LevelReadCommitted, nil
+	}
+	level, ok := txIsolationLevels[strings.ToLower(v)]
+	if !ok {
+		return 0, fmt.Errorf("invalid DB_TX_ISOLATION %q: want read_committed, repeatable_read or serializable", v)
+	}
+	return level, nil
+}
+
+// apiKeysFromEnv reads a comma-separated list of API keys, such as the partner
+// keys accepted by the card authorization endpoint (CARD_ACQUIRER_API_KEYS)
+func apiKeysFromEnv(name string) []string {
+	var keys []string
+	for _, key := range strings.Split(os.Getenv(name), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// defaultClientTokenTTLs are the lifetimes per client type unless overridden
+// with TOKEN_TTL_<TYPE>_ACCESS, _REFRESH and _SESSION_MAX
+var defaultClientTokenTTLs = map[user.ClientType]user.TokenTTL{
+	user.ClientTypeMobile:  {Access: 15 * time.Minute, Refresh: 30 * 24 * time.Hour, SessionMax: 30 * 24 * time.Hour},
+	user.ClientTypeWeb:     {Access: 15 * time.Minute, Refresh: 12 * time.Hour, SessionMax: 12 * time.Hour},
+	user.ClientTypePartner: {Access: 5 * time.Minute, Refresh: 24 * time.Hour, SessionMax: 24 * time.Hour},
+}
+
+// tokenTTLsFromEnv reads and validates the token lifetimes of every client
+// type. The default type keeps JWT_EXPIRY_HOURS for its access tokens so
+// clients that send no client_type see no change.
+func tokenTTLsFromEnv(defaultAccess time.Duration) (user.TokenTTLs, error) {
+	defaults := user.DefaultTokenTTL
+	defaults.Access = defaultAccess
+
+	ttls := user.TokenTTLs{user.ClientTypeDefault: defaults}
+	for clientType, ttl := range defaultClientTokenTTLs {
+		ttls[clientType] = ttl
+	}
+
+	for clientType, ttl := range ttls {
+		prefix := "TOKEN_TTL_" + strings.ToUpper(string(clientType))
+		for suffix, field := range map[string]*time.Duration{
+			"_ACCESS":      &ttl.Access,
+			"_REFRESH":     &ttl.Refresh,
+			"_SESSION_MAX": &ttl.SessionMax,
+		} {
+			v := os.Getenv(prefix + suffix)
+			if v == "" {
+				continue
+			}
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s%s %q", prefix, suffix, v)
+			}
+			*field = d
+		}
+		if err := ttl.Validate(); err != nil {
+			return nil, fmt.Errorf("%s client: %w", clientType, err)
+		}
+		ttls[clientType] = ttl
+	}
+
+	return ttls, nil
+}
+
+// DefaultServiceTokenTTL is the lifetime of service tokens unless SERVICE_TOKEN_TTL says otherwise
+const DefaultServiceTokenTTL = 15 * time.Minute
+
+// serviceAuthFromEnv reads the internal service clients from SERVICE_CLIENTS and
+// the secret their tokens are signed with from SERVICE_JWT_SECRET. Without
+// clients, service authentication is off and nil is returned.
+func serviceAuthFromEnv() (*jwt.ServiceTokenService, []user.ServiceClient, error) {
+	clients, err := user.ParseServiceClients(os.Getenv("SERVICE_CLIENTS"))
+	if err != nil || len(clients) == 0 {
+		return nil, nil, err
+	}
+
+	secret := os.Getenv("SERVICE_JWT_SECRET")
+	if len(secret) < 32 {
+		return nil, nil, fmt.Errorf("SERVICE_JWT_SECRET of at least 32 characters is required with SERVICE_CLIENTS")
+	}
+	if secret == os.Getenv("JWT_SECRET") {
+		return nil, nil, fmt.Errorf("SERVICE_JWT_SECRET must differ from JWT_SECRET")
+	}
+
+	ttl := DefaultServiceTokenTTL
+	if v := os.Getenv("SERVICE_TOKEN_TTL"); v != "" {
+		ttl, err = time.ParseDuration(v)
+		if err != nil || ttl <= 0 || ttl > time.Hour {
+			return nil, nil, fmt.Errorf("invalid SERVICE_TOKEN_TTL %q, expected a duration of at most 1h", v)
+		}
+	}
+
+	return jwt.NewServiceTokenService(secret, ttl), clients, nil
+}
+
+// initJWTService builds the JWT service from, in order of preference, a key file
+// (JWT_KEYS_FILE), a rotating key list (JWT_SIGNING_KEYS + JWT_ACTIVE_KID) or
+// the legacy single JWT_SECRET.
+func initJWTService(expiryHours int) (*jwt.JWTService, error) {
+	if keysFile := os.Getenv("JWT_KEYS_FILE"); keysFile != "" {
+		keySet, err := jwt.LoadKeySetFile(keysFile)
+		if err != nil {
+			return nil, err
+		}
+		return jwt.NewJWTServiceWithKeys(keySet, expiryHours)
+	}
+
+	if signingKeys := os.Getenv("JWT_SIGNING_KEYS"); signingKeys != "" {
+		keySet, err := jwt.ParseKeySet(signingKeys, os.Getenv("JWT_ACTIVE_KID"))
+		if err != nil {
+			return nil, err
+		}
+		return jwt.NewJWTServiceWithKeys(keySet, expiryHours)
+	}
+
+	jwtSecret := os.Getenv("JWT_SECRET")
+	if jwtSecret == "" {
+		return nil, fmt.Errorf("JWT_SECRET environment variable is required")
+	}
+	return jwt.NewJWTService(jwtSecret, expiryHours), nil
+}
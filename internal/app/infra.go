@@ -0,0 +1,168 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/darisadam/madabank-server/internal/pkg/cache"
+	"github.com/darisadam/madabank-server/internal/pkg/dbmetrics"
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
+	"github.com/darisadam/madabank-server/internal/pkg/jwt"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/repository"
+)
+
+// databaseURLFromEnv returns DATABASE_URL or, when it is unset, builds the
+// URL from the DB_* variables
+func databaseURLFromEnv() (string, error) {
+	databaseURL := os.Getenv("DATABASE_URL")
+	if databaseURL == "" {
+		host := os.Getenv("DB_HOST")
+		port := os.Getenv("DB_PORT")
+		user := os.Getenv("DB_USER")
+		name := os.Getenv("DB_NAME")
+		password := os.Getenv("DB_PASSWORD")
+
+		if host != "" && port != "" && user != "" && name != "" && password != "" {
+			databaseURL = fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable", user, url.QueryEscape(password), host, port, name)
+		} else {
+			return "", fmt.Errorf("DATABASE_URL environment variable is required and DB_* variables are missing")
+		}
+	}
+
+	// Inject password if present (from Secrets Manager)
+	dbPassword := os.Getenv("DB_PASSWORD")
+	if dbPassword != "" {
+		databaseURL = strings.Replace(databaseURL, "PLACEHOLDER", url.QueryEscape(dbPassword), 1)
+	}
+	return databaseURL, nil
+}
+
+// openDB connects to the database databaseURLFromEnv describes
+func openDB() (*sql.DB, error) {
+	databaseURL, err := databaseURLFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := dbmetrics.Open(databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// Set connection pool settings
+	db.SetMaxOpenConns(25)
+	db.SetMaxIdleConns(5)
+	db.SetConnMaxLifetime(5 * time.Minute)
+
+	// Test connection
+	if err := db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return db, nil
+}
+
+// openRedis connects to REDIS_HOST and REDIS_PORT or, when they are unset,
+// to REDIS_URL (localhost:6379 by default)
+func openRedis() (*redis.Client, error) {
+	var addr, password string
+
+	host := os.Getenv("REDIS_HOST")
+	port := os.Getenv("REDIS_PORT")
+	password = os.Getenv("REDIS_PASSWORD")
+
+	if host != "" && port != "" {
+		addr = fmt.Sprintf("%s:%s", host, port)
+	} else {
+		addr = os.Getenv("REDIS_URL")
+		if addr == "" {
+			addr = "localhost:6379"
+		}
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: password,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+
+	return client, nil
+}
+
+// initCache puts the Redis cache in front of the account and user lookups.
+// It stays off when CACHE_TTL is 0 or when the database URL is not in the
+// environment, as without the invalidation listener other replicas' writes
+// would go unnoticed until the TTL.
+func (a *App) initCache() error {
+	ttl, err := cacheTTLFromEnv()
+	if err != nil {
+		return err
+	}
+	if ttl == 0 {
+		logger.Info("Read cache disabled")
+		return nil
+	}
+	databaseURL, err := databaseURLFromEnv()
+	if err != nil {
+		logger.Warn("Read cache disabled, no database URL to listen for invalidations on")
+		return nil
+	}
+
+	c := cache.New(a.redis, ttl)
+	if err := c.Listen(a.ctx, databaseURL); err != nil {
+		return err
+	}
+	a.repos.account = repository.NewCachedAccountRepository(a.repos.account, c)
+	a.repos.user = repository.NewCachedUserRepository(a.repos.user, c)
+	logger.Info("Read cache enabled", zap.Duration("ttl", ttl))
+	return nil
+}
+
+// watchJWTKeys reloads the signing keys from JWT_KEYS_FILE whenever the process
+// receives SIGHUP, so keys can be rotated without a restart. It returns when
+// ctx is cancelled.
+func watchJWTKeys(ctx context.Context, keysFile string, jwtService *jwt.JWTService) {
+	defer errtrack.RecoverWorker("jwt_key_watcher")
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+		}
+
+		keySet, err := jwt.LoadKeySetFile(keysFile)
+		if err != nil {
+			logger.Error("Failed to reload JWT keys", zap.Error(err))
+			continue
+		}
+		if err := jwtService.ReloadKeys(keySet); err != nil {
+			logger.Error("Failed to apply reloaded JWT keys", zap.Error(err))
+			continue
+		}
+		logger.Info("JWT signing keys reloaded", zap.String("active_kid", keySet.ActiveKID))
+	}
+}
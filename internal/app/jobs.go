@@ -0,0 +1,76 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/export"
+	"github.com/darisadam/madabank-server/internal/jobs"
+	"github.com/darisadam/madabank-server/internal/pkg/distlock"
+	"github.com/darisadam/madabank-server/internal/pkg/leader"
+)
+
+// startJobs starts the archivers, the job queue and the scheduler. They all
+// stop when the App's context is cancelled.
+func (a *App) startJobs() error {
+	r, s := a.repos, a.services
+
+	if archiver := initAuditArchiver(a.ctx, r.audit); archiver != nil {
+		go archiver.Start(a.ctx)
+	}
+	go initTransactionArchiver(r.transactionArchive, r.archiveStore).Start(a.ctx)
+
+	jobQueue := jobs.NewQueue(r.job, jobQueueConfigFromEnv())
+	jobQueue.Register(jobs.KindSystemMetrics, jobs.NewSystemMetricsCollector(r.admin, a.db.Stats).Handle, jobs.KindOptions{
+		MaxAttempts: 1,
+		Timeout:     30 * time.Second,
+	})
+	jobQueue.Register(export.JobKind, jobs.BulkExportHandler(s.export.RunExport), jobs.KindOptions{
+		MaxAttempts: 1,
+		Timeout:     2 * time.Hour,
+	})
+	go jobQueue.Every(a.ctx, jobs.KindSystemMetrics, systemMetricsIntervalFromEnv())
+	go jobQueue.Start(a.ctx)
+
+	// Postings run on one elected replica only. Each is idempotent, so the
+	// hourly ones also pick up whatever a failed or missed run left over.
+	elector := leader.New(a.redis, "scheduler:leader", jobs.ReplicaID(), schedulerLeaderTTLFromEnv())
+	go elector.Run(a.ctx)
+
+	scheduler := jobs.NewScheduler(elector, a.accountingZone)
+	scheduler.UseLocker(distlock.New(a.redis))
+	if a.metricsPublisher != nil {
+		scheduler.UsePublisher(a.metricsPublisher)
+	}
+	for _, task := range []struct {
+		name, spec string
+		run        jobs.TaskFunc
+	}{
+		{"saga_recovery", "* * * * *", jobs.CountTask("sagas", s.saga.ResumeDue)},
+		{"interest_accrual", "5 0 * * *", jobs.CountTask("interest accruals", s.interest.AccrueDaily)},
+		{"card_expiry", "15 0 * * *", jobs.CountTask("expired cards", s.card.ExpireCards)},
+		{"hold_expiry", "*/5 * * * *", jobs.CountTask("expired holds", s.hold.ExpireHolds)},
+		{"statement_email", "0 6 * * *", jobs.CountTask("statement emails", s.statement.SendDueStatements)},
+		{"interest_posting", "30 0 * * *", jobs.CountTask("interest postings", s.interest.PostDue)},
+		{"statement_cycle", "0 * * * *", jobs.CountTask("credit card statements", s.creditCard.CloseDueStatements)},
+		{"merchant_settlement", "10 * * * *", jobs.CountTask("merchant settlements", s.merchant.SettleMerchants)},
+		{"loan_auto_debit", "20 * * * *", jobs.CountTask("loan installments", s.loan.CollectDueInstallments)},
+		{"ledger_reconciliation", "40 * * * *", jobs.CountTask("ledger breaks", s.reconciliation.RunScheduledLedger)},
+		{"regulatory_reporting", "50 * * * *", jobs.CountTask("regulatory reports", s.regulatoryReport.RunScheduled)},
+	} {
+		if err := scheduler.Add(task.name, task.spec, task.run); err != nil {
+			return fmt.Errorf("failed to schedule task: %w", err)
+		}
+	}
+	if anonymizer := initUserAnonymizer(r.user); anonymizer != nil {
+		if err := scheduler.Add("user_anonymization", "45 1 * * *", jobs.CountTask("anonymized users", anonymizer.Run)); err != nil {
+			return fmt.Errorf("failed to schedule task: %w", err)
+		}
+	}
+	go scheduler.Start(a.ctx)
+
+	go jobs.NewTopupTracker(s.topup, 30*time.Second).Start(a.ctx)
+	go jobs.NewMerchantNotifier(s.merchant, 30*time.Second).Start(a.ctx)
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/darisadam/madabank-server/internal/pkg/objectstore"
+	"github.com/darisadam/madabank-server/internal/repository"
+)
+
+type repositories struct {
+	user               repository.UserRepository
+	account            repository.AccountRepository
+	roundUp            repository.RoundUpRepository
+	transaction        repository.TransactionRepository
+	archiveStore       objectstore.Store // nil when archiving has no storage
+	transactionArchive repository.TransactionArchiveRepository
+	audit              repository.AuditRepository
+	card               repository.CardRepository
+	creditCard         repository.CreditCardRepository
+	cardAuthorization  repository.CardAuthorizationRepository
+	cardToken          repository.CardTokenRepository
+	billPayment        repository.BillPaymentRepository
+	topup              repository.TopupRepository
+	merchant           repository.MerchantRepository
+	loan               repository.LoanRepository
+	reconciliation     repository.ReconciliationRepository
+	regulatory         repository.RegulatoryRepository
+	report             repository.ReportRepository
+	note               repository.NoteRepository
+	hold               repository.HoldRepository
+	export             repository.ExportRepository
+	job                repository.JobRepository
+	admin              repository.AdminRepository
+	interest           repository.InterestRepository
+	saga               repository.SagaRepository
+	beneficiary        repository.BeneficiaryRepository
+	transferTemplate   repository.TransferTemplateRepository
+	statement          repository.StatementRepository
+}
+
+func newRepositories(ctx context.Context, db *sql.DB, isolation sql.IsolationLevel) *repositories {
+	r := &repositories{
+		user:             repository.NewUserRepository(db),
+		account:          repository.NewAccountRepository(db),
+		roundUp:          repository.NewRoundUpRepository(db),
+		audit:            repository.NewAuditRepository(db),
+		card:             repository.NewCardRepository(db),
+		creditCard:       repository.NewCreditCardRepository(db),
+		cardToken:        repository.NewCardTokenRepository(db),
+		billPayment:      repository.NewBillPaymentRepository(db),
+		topup:            repository.NewTopupRepository(db),
+		loan:             repository.NewLoanRepository(db),
+		reconciliation:   repository.NewReconciliationRepository(db),
+		regulatory:       repository.NewRegulatoryRepository(db),
+		report:           repository.NewReportRepository(db),
+		note:             repository.NewNoteRepository(db),
+		export:           repository.NewExportRepository(db),
+		job:              repository.NewJobRepository(db),
+		admin:            repository.NewAdminRepository(db),
+		interest:         repository.NewInterestRepository(db),
+		saga:             repository.NewSagaRepository(db),
+		beneficiary:      repository.NewBeneficiaryRepository(db),
+		transferTemplate: repository.NewTransferTemplateRepository(db),
+		statement:        repository.NewStatementRepository(db),
+		archiveStore:     transactionArchiveStoreFromEnv(ctx),
+	}
+	// Debits through any of these repositories sweep round-ups
+	r.transaction = repository.NewTransactionRepository(db, isolation, r.roundUp)
+	r.merchant = repository.NewMerchantRepository(db, r.roundUp)
+	r.hold = repository.NewHoldRepository(db, r.roundUp)
+	r.cardAuthorization = repository.NewCardAuthorizationRepository(db, r.roundUp)
+	r.transactionArchive = repository.NewTransactionArchiveRepository(db, r.archiveStore)
+	return r
+}
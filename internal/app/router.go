@@ -0,0 +1,496 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+
+	"github.com/darisadam/madabank-server/internal/api/handlers"
+	"github.com/darisadam/madabank-server/internal/api/middleware"
+	"github.com/darisadam/madabank-server/internal/domain/reconciliation"
+	"github.com/darisadam/madabank-server/internal/domain/user"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+)
+
+// initServers builds the handlers and the router, and the HTTP servers that
+// Run starts
+func (a *App) initServers() error {
+	s := a.services
+
+	// Initialize handlers
+	userHandler := handlers.NewUserHandler(s.user)
+	accountHandler := handlers.NewAccountHandler(s.account)
+	transactionHandler := handlers.NewTransactionHandler(s.transaction)
+	beneficiaryHandler := handlers.NewBeneficiaryHandler(s.beneficiary)
+	transferTemplateHandler := handlers.NewTransferTemplateHandler(s.transferTemplate)
+	receiptHandler := handlers.NewReceiptHandler(s.receipt)
+	statementHandler := handlers.NewStatementHandler(s.statement)
+	interestHandler := handlers.NewInterestHandler(s.interest)
+	roundUpHandler := handlers.NewRoundUpHandler(s.roundUp)
+	cardHandler := handlers.NewCardHandler(s.card)
+	creditCardHandler := handlers.NewCreditCardHandler(s.creditCard)
+	cardAuthorizationHandler := handlers.NewCardAuthorizationHandler(s.cardAuthorization)
+	cardTokenHandler := handlers.NewCardTokenHandler(s.cardToken)
+	billPaymentHandler := handlers.NewBillPaymentHandler(s.billPayment)
+	topupHandler := handlers.NewTopupHandler(s.topup)
+	fxHandler := handlers.NewFXHandler(s.fx)
+	merchantHandler := handlers.NewMerchantHandler(s.merchant)
+	loanHandler := handlers.NewLoanHandler(s.loan)
+	reconciliationHandler := handlers.NewReconciliationHandler(s.reconciliation)
+	generalLedgerHandler := handlers.NewGeneralLedgerHandler(s.generalLedger)
+	regulatoryReportHandler := handlers.NewRegulatoryReportHandler(s.regulatoryReport)
+	reportHandler := handlers.NewReportHandler(s.report)
+	noteHandler := handlers.NewNoteHandler(s.note)
+	holdHandler := handlers.NewHoldHandler(s.hold)
+	jobHandler := handlers.NewJobHandler(s.job)
+	exportHandler := handlers.NewExportHandler(s.export)
+	securityHandler := handlers.NewSecurityHandler(s.security)
+	tokenHandler := handlers.NewTokenHandler(s.token)
+	adminHandler := handlers.NewAdminHandler(s.audit)
+	trafficHandler := handlers.NewTrafficHandler(s.traffic)
+	logLevelHandler := handlers.NewLogLevelHandler(s.logLevel)
+
+	// Set Gin mode
+	if a.env == "production" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	// Initialize router
+	router := gin.New()
+	router.Use(middleware.RecoveryMiddleware())
+	router.Use(middleware.LoggerMiddleware())
+	router.Use(middleware.MetricsMiddleware())
+	router.Use(middleware.CompressionMiddleware(middleware.DefaultCompressionMinSize))
+	router.Use(middleware.CORSMiddleware())
+	bodyLimit, err := bodyLimitFromEnv()
+	if err != nil {
+		return fmt.Errorf("invalid body limit configuration: %w", err)
+	}
+	// Settlement files are uploaded as multipart forms, with room for the
+	// other form fields
+	bodyLimit.Routes = map[string]int64{
+		"/api/v1/admin/reconciliation/settlement-files": reconciliation.MaxSettlementFileSize + 64<<10,
+	}
+	router.Use(middleware.BodyLimitMiddleware(bodyLimit))
+	// Security: Set trusted proxies. For now, trusting local network.
+	// In production, this should be the ALB/Load Balancer IP or CIDR.
+	if err := router.SetTrustedProxies(nil); err != nil {
+		logger.Error("Failed to set trusted proxies", zap.Error(err))
+	}
+	router.Use(middleware.MaintenanceMiddleware(a.redis))
+	if a.geoResolver != nil {
+		router.Use(middleware.GeoIPMiddleware(a.geoResolver, a.geoPolicy))
+	}
+	router.Use(middleware.TrafficTrackingMiddleware(a.ddosProtection))
+	router.Use(middleware.RateLimitMiddleware(a.rateLimiter))
+	router.Use(middleware.SuspiciousActivityMiddleware(a.rateLimiter))
+	if a.botDetector != nil {
+		router.Use(middleware.BotDetectionMiddleware(a.botDetector, a.captchaVerifier))
+	}
+
+	// Metrics endpoint (Prometheus scraping), on its own listener when
+	// METRICS_LISTEN_ADDR is set
+	metricsAuth, err := metricsAuthFromEnv()
+	if err != nil {
+		return fmt.Errorf("invalid metrics auth configuration: %w", err)
+	}
+	metricsAddr := os.Getenv("METRICS_LISTEN_ADDR")
+	if a.env == "production" && metricsAddr == "" && !metricsAuth.Enabled() {
+		logger.Warn("/metrics is served on the public port without authentication")
+	}
+	metricsEndpoint := []gin.HandlerFunc{middleware.MetricsAuthMiddleware(metricsAuth), gin.WrapH(metricsHandler())}
+	if metricsAddr == "" {
+		router.GET("/metrics", metricsEndpoint...)
+	}
+
+	// Health check endpoints
+	router.GET("/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"status":    "healthy",
+			"timestamp": time.Now().Unix(),
+		})
+	})
+
+	router.GET("/ready", func(c *gin.Context) {
+		// Check database connection
+		if err := a.db.Ping(); err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"status": "not ready",
+				"error":  "database connection failed",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"status": "ready",
+		})
+	})
+
+	// Version endpoint
+	router.GET("/version", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"service":    "MadaBank API",
+			"version":    a.build.Version,
+			"commit_sha": a.build.CommitSHA,
+			"build_time": a.build.BuildTime,
+			"go_version": runtime.Version(),
+		})
+	})
+
+	// API version endpoint
+	router.GET("/", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"service": "MadaBank API",
+			"version": a.build.Version,
+			"status":  "operational",
+		})
+	})
+
+	// Read-heavy endpoints polled by the mobile app answer 304 when unchanged
+	conditionalGet := middleware.ConditionalGetMiddleware()
+
+	// API routes
+	v1 := router.Group("/api/v1")
+	{
+		// Security Routes (No Auth required for public key)
+		securityParams := v1.Group("/security")
+		{
+			securityParams.GET("/public-key", securityHandler.GetPublicKey)
+		}
+
+		// Public routes
+		auth := v1.Group("/auth")
+		{
+			auth.POST("/register", userHandler.Register)
+			auth.POST("/login", userHandler.Login)
+			auth.POST("/refresh", userHandler.RefreshToken)
+			auth.POST("/forgot-password", userHandler.ForgotPassword)
+			auth.POST("/reset-password", userHandler.ResetPassword)
+			auth.POST("/reset-password/link", userHandler.ResetPasswordWithLink)
+		}
+
+		// Deleted users cannot log in, so reactivation is public
+		reactivation := v1.Group("/users/reactivate")
+		{
+			reactivation.POST("", userHandler.Reactivate)
+			reactivation.POST("/otp", userHandler.RequestReactivation)
+		}
+
+		// Protected routes
+		users := v1.Group("/users")
+		users.Use(middleware.AuthMiddleware(a.jwtService))
+		users.Use(middleware.RequireScope("users"))
+		users.Use(middleware.UserRateLimitMiddleware(a.rateLimiter))
+		{
+			users.GET("/profile", conditionalGet, userHandler.GetProfile)
+			users.PUT("/profile", userHandler.UpdateProfile)
+			users.DELETE("/profile", userHandler.DeleteAccount)
+			users.PUT("/password", userHandler.ChangePassword)
+			users.POST("/email/change", userHandler.RequestEmailChange)
+			users.POST("/email/confirm", userHandler.ConfirmEmailChange)
+			users.POST("/phone/verification", userHandler.RequestPhoneVerification)
+			users.POST("/phone/verify", userHandler.VerifyPhone)
+			users.POST("/step-up", userHandler.RequestStepUp)
+			users.GET("/handles/:handle", userHandler.ResolveHandle)
+		}
+
+		accounts := v1.Group("/accounts")
+		accounts.Use(middleware.AuthMiddleware(a.jwtService))
+		accounts.Use(middleware.RequireScope("accounts"))
+		accounts.Use(middleware.UserRateLimitMiddleware(a.rateLimiter))
+		{
+			accounts.POST("", accountHandler.CreateAccount)
+			accounts.GET("", conditionalGet, accountHandler.GetAccounts)
+			accounts.GET("/:id", conditionalGet, accountHandler.GetAccount)
+			accounts.GET("/:id/balance", conditionalGet, accountHandler.GetBalance)
+			accounts.GET("/:id/holds", holdHandler.ListHolds)
+			accounts.POST("/:id/currencies", accountHandler.AddCurrency)
+			accounts.PATCH("/:id", accountHandler.UpdateAccount)
+			accounts.DELETE("/:id", accountHandler.CloseAccount)
+			accounts.GET("/:id/statement-subscription", statementHandler.GetSubscription)
+			accounts.PUT("/:id/statement-subscription", statementHandler.Subscribe)
+			accounts.DELETE("/:id/statement-subscription", statementHandler.Unsubscribe)
+			accounts.GET("/:id/statement-deliveries", statementHandler.ListDeliveries)
+			accounts.POST("/:id/statement-deliveries/:deliveryId/resend", statementHandler.ResendStatement)
+			accounts.GET("/:id/interest", interestHandler.GetSummary)
+			accounts.GET("/:id/round-up", roundUpHandler.GetRoundUp)
+			accounts.PUT("/:id/round-up", roundUpHandler.EnableRoundUp)
+			accounts.DELETE("/:id/round-up", roundUpHandler.DisableRoundUp)
+		}
+
+		transactions := v1.Group("/transactions")
+		transactions.Use(middleware.AuthMiddleware(a.jwtService))
+		transactions.Use(middleware.RequireScope("transactions"))
+		transactions.Use(middleware.UserRateLimitMiddleware(a.rateLimiter))
+		transactions.Use(middleware.StepUpMiddleware(s.user))
+		{
+			transactions.POST("/transfer", transactionHandler.Transfer)
+			transactions.POST("/deposit", transactionHandler.Deposit)
+			transactions.POST("/withdraw", transactionHandler.Withdraw)
+			transactions.POST("/qr/resolve", transactionHandler.ResolveQR)
+			transactions.GET("/history", conditionalGet, transactionHandler.GetHistory)
+			transactions.GET("/archived", transactionHandler.GetArchivedHistory)
+			transactions.GET("/search", transactionHandler.SearchTransactions)
+			transactions.POST("/templates", transferTemplateHandler.CreateTemplate)
+			transactions.GET("/templates", transferTemplateHandler.ListTemplates)
+			transactions.GET("/templates/:id", transferTemplateHandler.GetTemplate)
+			transactions.PATCH("/templates/:id", transferTemplateHandler.UpdateTemplate)
+			transactions.DELETE("/templates/:id", transferTemplateHandler.DeleteTemplate)
+			transactions.POST("/templates/:id/execute", transferTemplateHandler.ExecuteTemplate)
+			transactions.GET("/:id", transactionHandler.GetTransaction)
+			transactions.GET("/:id/receipt", receiptHandler.GetReceipt)
+		}
+
+		beneficiaries := v1.Group("/beneficiaries")
+		beneficiaries.Use(middleware.AuthMiddleware(a.jwtService))
+		beneficiaries.Use(middleware.RequireScope("beneficiaries"))
+		beneficiaries.Use(middleware.UserRateLimitMiddleware(a.rateLimiter))
+		{
+			beneficiaries.POST("", beneficiaryHandler.CreateBeneficiary)
+			beneficiaries.GET("", beneficiaryHandler.ListBeneficiaries)
+			beneficiaries.GET("/:id", beneficiaryHandler.GetBeneficiary)
+			beneficiaries.PATCH("/:id", beneficiaryHandler.UpdateBeneficiary)
+			beneficiaries.DELETE("/:id", beneficiaryHandler.DeleteBeneficiary)
+			beneficiaries.POST("/:id/verify", beneficiaryHandler.VerifyBeneficiary)
+		}
+
+		// CARD ROUTES
+		cards := v1.Group("/cards")
+		cards.Use(middleware.AuthMiddleware(a.jwtService))
+		cards.Use(middleware.RequireScope("cards"))
+		cards.Use(middleware.UserRateLimitMiddleware(a.rateLimiter))
+		{
+			cards.POST("", cardHandler.CreateCard)
+			cards.GET("", cardHandler.GetCards)
+			cards.POST("/details", cardHandler.GetCardDetails)
+			cards.POST("/:id/reveal/challenge", cardHandler.RequestRevealChallenge)
+			cards.POST("/:id/reveal/verify", cardHandler.VerifyRevealChallenge)
+			cards.PATCH("/:id", cardHandler.UpdateCard)
+			cards.PATCH("/:id/controls", cardHandler.UpdateControls)
+			cards.POST("/:id/block", cardHandler.BlockCard)
+			cards.POST("/:id/freeze", cardHandler.FreezeCard)
+			cards.POST("/:id/unfreeze", cardHandler.UnfreezeCard)
+			cards.POST("/:id/reissue", cardHandler.ReissueCard)
+			cards.POST("/:id/repayments", creditCardHandler.Repay)
+			cards.GET("/:id/statements", creditCardHandler.GetStatements)
+			cards.POST("/:id/rotate-cvv", cardHandler.RotateCVV)
+			cards.POST("/:id/pin", cardHandler.SetPIN)
+			cards.POST("/:id/pin/verify", cardHandler.VerifyPIN)
+			cards.POST("/:id/tokens", cardTokenHandler.ProvisionToken)
+			cards.GET("/:id/tokens", cardTokenHandler.ListTokens)
+			cards.POST("/:id/tokens/:tokenId/suspend", cardTokenHandler.SuspendToken)
+			cards.POST("/:id/tokens/:tokenId/resume", cardTokenHandler.ResumeToken)
+			cards.DELETE("/:id/tokens/:tokenId", cardTokenHandler.DeleteToken)
+			cards.DELETE("/:id", cardHandler.DeleteCard)
+		}
+
+		// BILL PAYMENTS
+		bills := v1.Group("/bills")
+		bills.Use(middleware.AuthMiddleware(a.jwtService))
+		bills.Use(middleware.RequireScope("bills"))
+		bills.Use(middleware.UserRateLimitMiddleware(a.rateLimiter))
+		{
+			bills.GET("/billers", billPaymentHandler.ListBillers)
+			bills.POST("/inquiry", billPaymentHandler.Inquire)
+			bills.POST("/payments", billPaymentHandler.Pay)
+		}
+
+		// TOP-UPS
+		topups := v1.Group("/topups")
+		topups.Use(middleware.AuthMiddleware(a.jwtService))
+		topups.Use(middleware.RequireScope("topups"))
+		topups.Use(middleware.UserRateLimitMiddleware(a.rateLimiter))
+		{
+			topups.POST("", topupHandler.CreateTopup)
+			topups.GET("", topupHandler.ListTopups)
+			topups.GET("/:id", topupHandler.GetTopup)
+		}
+
+		// FX (indicative rates shown before an FX transfer)
+		fxGroup := v1.Group("/fx")
+		fxGroup.Use(middleware.AuthMiddleware(a.jwtService))
+		fxGroup.Use(middleware.RequireScope("fx"))
+		fxGroup.Use(middleware.UserRateLimitMiddleware(a.rateLimiter))
+		{
+			fxGroup.GET("/rates", fxHandler.GetRates)
+		}
+
+		// MERCHANTS (registration, key and webhook management by the owning user)
+		merchants := v1.Group("/merchants")
+		merchants.Use(middleware.AuthMiddleware(a.jwtService))
+		merchants.Use(middleware.RequireScope("merchants"))
+		merchants.Use(middleware.UserRateLimitMiddleware(a.rateLimiter))
+		{
+			merchants.POST("", merchantHandler.RegisterMerchant)
+			merchants.GET("", merchantHandler.ListMerchants)
+			merchants.POST("/:id/api-key", merchantHandler.RotateAPIKey)
+			merchants.PUT("/:id/webhook", merchantHandler.UpdateWebhook)
+			merchants.POST("/:id/webhook/secret", merchantHandler.RotateWebhookSecret)
+			merchants.GET("/:id/webhook/deliveries", merchantHandler.ListWebhookDeliveries)
+			merchants.POST("/:id/webhook/test", merchantHandler.TestWebhook)
+			merchants.POST("/:id/webhook/replay", merchantHandler.ReplayWebhook)
+		}
+
+		// MERCHANT API (merchant servers, authenticated by merchant API key)
+		merchantAPI := v1.Group("/merchant")
+		merchantAPI.Use(middleware.MerchantAuthMiddleware(s.merchant))
+		{
+			merchantAPI.POST("/payment-links", merchantHandler.CreatePaymentLink)
+			merchantAPI.GET("/payment-links", merchantHandler.ListPaymentLinks)
+			merchantAPI.GET("/payment-links/:id", merchantHandler.GetMerchantPaymentLink)
+			merchantAPI.POST("/payment-links/:id/cancel", merchantHandler.CancelPaymentLink)
+			merchantAPI.GET("/settlements", merchantHandler.ListSettlements)
+		}
+
+		// SHARED RECEIPTS (public, authorized by the signed link)
+		receipts := v1.Group("/receipts")
+		{
+			receipts.GET("/:token", receiptHandler.GetSharedReceipt)
+		}
+
+		// PAYMENT LINKS (customers paying merchants)
+		paymentLinks := v1.Group("/payment-links")
+		paymentLinks.Use(middleware.AuthMiddleware(a.jwtService))
+		paymentLinks.Use(middleware.RequireScope("payment_links"))
+		paymentLinks.Use(middleware.UserRateLimitMiddleware(a.rateLimiter))
+		{
+			paymentLinks.POST("/qr/resolve", merchantHandler.ResolvePaymentQR)
+			paymentLinks.GET("/:id", merchantHandler.GetPaymentLink)
+			paymentLinks.POST("/:id/pay", merchantHandler.PayPaymentLink)
+		}
+
+		// LOANS
+		loans := v1.Group("/loans")
+		loans.Use(middleware.AuthMiddleware(a.jwtService))
+		loans.Use(middleware.RequireScope("loans"))
+		loans.Use(middleware.UserRateLimitMiddleware(a.rateLimiter))
+		{
+			loans.GET("/products", loanHandler.ListProducts)
+			loans.POST("/quote", loanHandler.Quote)
+			loans.POST("", loanHandler.Apply)
+			loans.GET("", loanHandler.ListLoans)
+			loans.GET("/:id", loanHandler.GetLoan)
+			loans.POST("/:id/cancel", loanHandler.CancelApplication)
+		}
+
+		// CARD AUTHORIZATION (acquirer partners, authenticated by API key)
+		if acquirerKeys := apiKeysFromEnv("CARD_ACQUIRER_API_KEYS"); len(acquirerKeys) > 0 {
+			v1.POST("/cards/authorize", middleware.PartnerAuthMiddleware(acquirerKeys), cardAuthorizationHandler.Authorize)
+			v1.POST("/cards/authorizations/:id/capture", middleware.PartnerAuthMiddleware(acquirerKeys), cardAuthorizationHandler.Capture)
+		} else {
+			logger.Warn("CARD_ACQUIRER_API_KEYS not set, card authorization endpoints disabled")
+		}
+
+		// INTERNAL SERVICES (client-credential tokens, scoped per endpoint)
+		if a.serviceTokens != nil {
+			v1.POST("/auth/service-token", tokenHandler.IssueServiceToken)
+			v1.POST("/auth/introspect", middleware.ServiceAuthMiddleware(a.serviceTokens, user.ScopeTokensIntrospect), tokenHandler.Introspect)
+
+			holds := v1.Group("/holds")
+			holds.Use(middleware.ServiceAuthMiddleware(a.serviceTokens, user.ScopeHoldsManage))
+			{
+				holds.POST("", holdHandler.PlaceHold)
+				holds.GET("/:id", holdHandler.GetHold)
+				holds.POST("/:id/extend", holdHandler.ExtendHold)
+				holds.POST("/:id/capture", holdHandler.CaptureHold)
+				holds.POST("/:id/release", holdHandler.ReleaseHold)
+			}
+		} else {
+			logger.Warn("SERVICE_CLIENTS not set, internal service endpoints disabled")
+		}
+
+		// ADMIN ROUTES
+		admin := v1.Group("/admin")
+		admin.Use(middleware.AuthMiddleware(a.jwtService))
+		admin.Use(middleware.RequireScope("admin"))
+		admin.Use(middleware.RequireRole(user.RoleAdmin))
+		{
+			admin.GET("/audit/verify", adminHandler.VerifyAuditChain)
+			admin.GET("/log-level", logLevelHandler.GetLogLevel)
+			admin.PUT("/log-level", logLevelHandler.SetLogLevel)
+			admin.DELETE("/log-level", logLevelHandler.ResetLogLevel)
+			admin.GET("/ddos/config", trafficHandler.GetDDoSConfig)
+			admin.PATCH("/ddos/config", trafficHandler.UpdateDDoSConfig)
+			admin.DELETE("/ddos/config", trafficHandler.ResetDDoSConfig)
+			admin.GET("/ip-blocks", trafficHandler.ListBlockedIPs)
+			admin.DELETE("/ip-blocks/:ip", trafficHandler.UnblockIP)
+			admin.POST("/ip-ranges/blocked", trafficHandler.BlockRange)
+			admin.DELETE("/ip-ranges/blocked", trafficHandler.UnblockRange)
+			admin.GET("/ip-ranges/allowed", trafficHandler.GetAllowlist)
+			admin.POST("/ip-ranges/allowed", trafficHandler.AllowRange)
+			admin.DELETE("/ip-ranges/allowed", trafficHandler.DisallowRange)
+			admin.GET("/users", userHandler.ListUsers)
+			admin.POST("/users/:id/impersonate", tokenHandler.Impersonate)
+			admin.GET("/users/:id/notes", noteHandler.ListUserNotes)
+			admin.POST("/users/:id/notes", noteHandler.AddUserNote)
+			admin.GET("/loans", loanHandler.ListApplications)
+			admin.POST("/loans/:id/approve", loanHandler.Approve)
+			admin.POST("/loans/:id/reject", loanHandler.Reject)
+			admin.POST("/reconciliation/ledger", reconciliationHandler.ReconcileLedger)
+			admin.POST("/reconciliation/settlement-files", reconciliationHandler.ImportSettlementFile)
+			admin.GET("/reconciliation/runs", reconciliationHandler.ListRuns)
+			admin.GET("/reconciliation/exceptions", reconciliationHandler.ListExceptions)
+			admin.GET("/reconciliation/exceptions/:id", reconciliationHandler.GetException)
+			admin.PATCH("/reconciliation/exceptions/:id", reconciliationHandler.UpdateException)
+			admin.GET("/gl-export", generalLedgerHandler.ExportJournal)
+			admin.POST("/transactions/:id/flags", regulatoryReportHandler.FlagTransaction)
+			admin.GET("/transactions/:id/notes", noteHandler.ListTransactionNotes)
+			admin.POST("/transactions/:id/notes", noteHandler.AddTransactionNote)
+			admin.POST("/regulatory-reports", regulatoryReportHandler.GenerateReport)
+			admin.GET("/regulatory-reports", regulatoryReportHandler.ListReports)
+			admin.GET("/regulatory-reports/:id", regulatoryReportHandler.GetReport)
+			admin.GET("/regulatory-reports/:id/file", regulatoryReportHandler.DownloadReport)
+			admin.GET("/reports/daily", reportHandler.DailySummary)
+			admin.GET("/jobs", jobHandler.ListJobs)
+			admin.GET("/jobs/:id", jobHandler.GetJob)
+			admin.POST("/jobs/:id/retry", jobHandler.RetryJob)
+			admin.POST("/exports", exportHandler.RequestExport)
+			admin.GET("/exports", exportHandler.ListExports)
+			admin.GET("/exports/:id", exportHandler.GetExport)
+		}
+	}
+
+	// Server configuration
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	a.router = router
+	a.server = &http.Server{
+		Addr:           fmt.Sprintf(":%s", port),
+		Handler:        router,
+		ReadTimeout:    10 * time.Second,
+		WriteTimeout:   10 * time.Second,
+		MaxHeaderBytes: 1 << 20,
+	}
+
+	if metricsAddr != "" {
+		metricsRouter := gin.New()
+		metricsRouter.Use(middleware.RecoveryMiddleware())
+		metricsRouter.GET("/metrics", metricsEndpoint...)
+		a.metricsServer = &http.Server{
+			Addr:              metricsAddr,
+			Handler:           metricsRouter,
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+	}
+
+	return nil
+}
+
+// metricsHandler serves the default registry like promhttp.Handler, but
+// negotiates the OpenMetrics format so latency exemplars reach Prometheus
+func metricsHandler() http.Handler {
+	return promhttp.InstrumentMetricHandler(
+		prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{EnableOpenMetrics: true}),
+	)
+}
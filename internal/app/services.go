@@ -0,0 +1,106 @@
+package app
+
+import (
+	"os"
+
+	"github.com/darisadam/madabank-server/internal/domain/card"
+	"github.com/darisadam/madabank-server/internal/domain/loan"
+	"github.com/darisadam/madabank-server/internal/domain/merchant"
+	"github.com/darisadam/madabank-server/internal/domain/reconciliation"
+	"github.com/darisadam/madabank-server/internal/pkg/webhook"
+	"github.com/darisadam/madabank-server/internal/service"
+)
+
+type services struct {
+	security          service.SecurityService
+	user              service.UserService
+	token             service.TokenService
+	account           service.AccountService
+	transaction       service.TransactionService
+	beneficiary       service.BeneficiaryService
+	transferTemplate  service.TransferTemplateService
+	card              service.CardService
+	creditCard        service.CreditCardService
+	cardAuthorization service.CardAuthorizationService
+	cardToken         service.CardTokenService
+	saga              service.SagaOrchestrator
+	billPayment       service.BillPaymentService
+	topup             service.TopupService
+	fx                service.FXService
+	merchant          service.MerchantService
+	loan              service.LoanService
+	reconciliation    service.ReconciliationService
+	generalLedger     service.GeneralLedgerService
+	regulatoryReport  service.RegulatoryReportService
+	report            service.ReportService
+	note              service.NoteService
+	hold              service.HoldService
+	interest          service.InterestService
+	receipt           service.ReceiptService
+	roundUp           service.RoundUpService
+	statement         service.StatementService
+	export            service.ExportService
+	audit             service.AuditService
+	traffic           service.TrafficService
+	logLevel          service.LogLevelService
+	job               service.JobService
+}
+
+func (a *App) initServices() error {
+	cardZone, err := timezoneFromEnv("CARD_LIMIT_TIMEZONE", card.DefaultLimitTimezone)
+	if err != nil {
+		return err
+	}
+	settlementZone, err := timezoneFromEnv("MERCHANT_SETTLEMENT_TIMEZONE", merchant.DefaultSettlementTimezone)
+	if err != nil {
+		return err
+	}
+	loanZone, err := timezoneFromEnv("LOAN_TIMEZONE", loan.DefaultTimezone)
+	if err != nil {
+		return err
+	}
+	accountingZone, err := timezoneFromEnv("RECONCILIATION_TIMEZONE", reconciliation.DefaultTimezone)
+	if err != nil {
+		return err
+	}
+	a.accountingZone = accountingZone
+
+	r := a.repos
+	s := &services{}
+	s.security = service.NewSecurityService()
+	s.user = service.NewUserService(r.user, r.account, r.card, a.jwtService, a.redis, a.encryptor, a.emailNotifier, a.smsSender, a.passwordPolicy, resetLinkConfigFromEnv(), service.LoginAlertConfig{RequireOTP: os.Getenv("LOGIN_NEW_DEVICE_OTP") == "true"}, a.tokenTTLs)
+	s.token = service.NewTokenService(a.jwtService, a.serviceTokens, a.serviceClients, r.user, r.audit)
+	s.account = service.NewAccountService(r.account, r.user, r.audit, r.interest, a.emailNotifier)
+	s.transaction = service.NewTransactionService(r.transaction, r.account, r.audit, r.user, r.transactionArchive, r.beneficiary)
+	s.beneficiary = service.NewBeneficiaryService(r.beneficiary, r.account, r.user, r.audit)
+	s.transferTemplate = service.NewTransferTemplateService(r.transferTemplate, r.account, r.beneficiary, r.audit, s.transaction)
+	s.card = service.NewCardService(r.card, r.account, r.user, r.audit, a.redis, a.encryptor, a.emailNotifier, a.smsSender, cardLimitsFromEnv())
+	s.creditCard = service.NewCreditCardService(r.card, r.creditCard, r.account, r.transaction, r.audit)
+	s.cardAuthorization = service.NewCardAuthorizationService(r.card, r.cardToken, r.cardAuthorization, r.account, a.encryptor, cardZone)
+	s.cardToken = service.NewCardTokenService(r.card, r.cardToken, r.account, r.audit, a.encryptor)
+	s.saga = service.NewSagaOrchestrator(r.saga)
+	s.billPayment = service.NewBillPaymentService(r.billPayment, r.account, r.transaction, r.audit, a.redis, a.billerAggregator, s.saga)
+	s.topup = service.NewTopupService(r.topup, r.account, r.transaction, r.audit, a.topupAggregator)
+	s.fx = service.NewFXService(a.fxRates)
+	s.merchant = service.NewMerchantService(r.merchant, r.account, r.transaction, r.audit, webhook.NewHTTPSender(), settlementZone, os.Getenv("PAYMENT_LINK_BASE_URL"))
+	s.loan = service.NewLoanService(r.loan, r.account, r.audit, loanZone)
+	s.reconciliation = service.NewReconciliationService(r.reconciliation, r.admin, r.audit, accountingZone)
+	s.generalLedger = service.NewGeneralLedgerService(r.transaction, r.audit, accountingZone)
+	s.regulatoryReport = service.NewRegulatoryReportService(r.regulatory, r.transaction, r.audit, regulatoryReportConfigFromEnv(), accountingZone)
+	s.report = service.NewReportService(r.report, accountingZone)
+	s.note = service.NewNoteService(r.note, r.transaction, r.user, r.audit)
+	s.hold = service.NewHoldService(r.hold, r.account)
+	s.interest = service.NewInterestService(r.interest, r.account, accountingZone)
+	receiptConfig := receiptConfigFromEnv()
+	s.receipt = service.NewReceiptService(s.transaction, r.transaction, r.account, r.user, receiptConfig, accountingZone)
+	s.roundUp = service.NewRoundUpService(r.roundUp, r.account, r.audit)
+	s.statement = service.NewStatementService(r.statement, r.account, r.transaction, r.user, r.audit, a.emailNotifier, receiptConfig.BankName, accountingZone)
+	s.export = service.NewExportService(r.export, r.job, r.account, r.transaction, r.user, r.audit, a.emailNotifier, exportStoreFromEnv(a.ctx), receiptConfig.BankName, accountingZone)
+	s.audit = service.NewAuditService(r.audit)
+	s.traffic = service.NewTrafficService(a.ddosProtection, a.rateLimiter, r.audit)
+	s.logLevel = service.NewLogLevelService(a.logLevels, r.audit)
+	s.job = service.NewJobService(r.job, r.audit)
+
+	a.services = s
+	return nil
+}
@@ -0,0 +1,90 @@
+package account
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type HoldStatus string
+
+// HoldPurpose is what funds are held for
+type HoldPurpose string
+
+const (
+	HoldStatusActive   HoldStatus = "active"
+	HoldStatusCaptured HoldStatus = "captured"
+	HoldStatusReleased HoldStatus = "released"
+	HoldStatusExpired  HoldStatus = "expired"
+
+	HoldPurposeCardAuthorization HoldPurpose = "card_authorization"
+	HoldPurposeBulkTransfer      HoldPurpose = "bulk_transfer"
+	HoldPurposeOther             HoldPurpose = "other"
+
+	DefaultHoldDuration = 7 * 24 * time.Hour
+	MaxHoldDuration     = 30 * 24 * time.Hour
+)
+
+var (
+	// ErrHoldNotActive is returned for holds already captured, released or
+	// expired
+	ErrHoldNotActive = errors.New("hold is no longer active")
+	// ErrInsufficientAvailable is returned when a hold exceeds the available balance
+	ErrInsufficientAvailable = errors.New("insufficient available balance")
+)
+
+// Hold reserves part of an account's balance for a payment that has not
+// settled yet. While active it lowers the available balance but not the
+// ledger balance. Capturing debits the account and ends the hold; releasing
+// or expiry ends it without a debit.
+type Hold struct {
+	ID        uuid.UUID   `json:"id"`
+	AccountID uuid.UUID   `json:"account_id"`
+	Amount    float64     `json:"amount"`
+	Currency  string      `json:"currency"`
+	Purpose   HoldPurpose `json:"purpose"`
+	Reference string      `json:"reference,omitempty"` // the caller's ID for what is held
+	Status    HoldStatus  `json:"status"`
+	ExpiresAt time.Time   `json:"expires_at"`
+	// Set once captured: the amount debited, up to Amount, and its transaction
+	CapturedAmount *float64   `json:"captured_amount,omitempty"`
+	TransactionID  *uuid.UUID `json:"transaction_id,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// IsActive reports whether the hold still reserves funds at now
+func (h *Hold) IsActive(now time.Time) bool {
+	return h.Status == HoldStatusActive && now.Before(h.ExpiresAt)
+}
+
+type PlaceHoldRequest struct {
+	AccountID string  `json:"account_id" binding:"required,uuid"`
+	Amount    float64 `json:"amount" binding:"required,gt=0"`
+	Purpose   string  `json:"purpose" binding:"required,oneof=card_authorization bulk_transfer other"`
+	Reference string  `json:"reference,omitempty" binding:"max=255"`
+	// ExpiresInHours defaults to DefaultHoldDuration
+	ExpiresInHours int `json:"expires_in_hours,omitempty" binding:"omitempty,min=1,max=720"`
+}
+
+// Duration is how long the hold lasts unless captured or released first
+func (r *PlaceHoldRequest) Duration() time.Duration {
+	if r.ExpiresInHours == 0 {
+		return DefaultHoldDuration
+	}
+	return time.Duration(r.ExpiresInHours) * time.Hour
+}
+
+// ExtendHoldRequest moves a hold's expiry to the given number of hours from
+// now. A hold never lasts more than MaxHoldDuration from when it was placed.
+type ExtendHoldRequest struct {
+	ExpiresInHours int `json:"expires_in_hours" binding:"required,min=1,max=720"`
+}
+
+// CaptureHoldRequest debits up to the held amount; the rest is released
+type CaptureHoldRequest struct {
+	// Amount defaults to the whole hold
+	Amount      float64 `json:"amount,omitempty" binding:"omitempty,gt=0"`
+	Description string  `json:"description,omitempty" binding:"max=255"`
+}
@@ -0,0 +1,23 @@
+package account
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHold_IsActive(t *testing.T) {
+	now := time.Now()
+	h := &Hold{Status: HoldStatusActive, ExpiresAt: now.Add(time.Hour)}
+	assert.True(t, h.IsActive(now))
+	assert.False(t, h.IsActive(now.Add(time.Hour)))
+
+	h.Status = HoldStatusReleased
+	assert.False(t, h.IsActive(now))
+}
+
+func TestPlaceHoldRequest_Duration(t *testing.T) {
+	assert.Equal(t, DefaultHoldDuration, (&PlaceHoldRequest{}).Duration())
+	assert.Equal(t, 48*time.Hour, (&PlaceHoldRequest{ExpiresInHours: 48}).Duration())
+}
@@ -0,0 +1,97 @@
+package account
+
+import (
+	"strings"
+
+	"github.com/darisadam/madabank-server/internal/domain/currency"
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+)
+
+// listSortColumns are the columns accounts can be sorted by
+var listSortColumns = map[string]string{
+	"created_at":     "created_at",
+	"balance":        "balance",
+	"account_number": "account_number",
+}
+
+// ListAccountsRequest lists the user's accounts. Without a status closed
+// accounts are left out; ask for status=closed to see them.
+type ListAccountsRequest struct {
+	Status string `form:"status" binding:"omitempty,oneof=active frozen closed"`
+	Type   string `form:"type" binding:"omitempty,oneof=checking savings"`
+	// Sort is a column, prefixed with - for descending order
+	Sort   string `form:"sort" binding:"omitempty,oneof=created_at -created_at balance -balance account_number -account_number"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int    `form:"offset" binding:"omitempty,min=0"`
+}
+
+// ListFilter selects a page of a user's accounts
+type ListFilter struct {
+	UserID uuid.UUID
+	Status AccountStatus // empty for every status but closed
+	Type   AccountType
+	Sort   string
+	Limit  int
+	Offset int
+}
+
+// Filter applies the defaults: newest first, DefaultListLimit per page
+func (r *ListAccountsRequest) Filter(userID uuid.UUID) *ListFilter {
+	f := &ListFilter{
+		UserID: userID,
+		Status: AccountStatus(r.Status),
+		Type:   AccountType(r.Type),
+		Sort:   r.Sort,
+		Limit:  r.Limit,
+		Offset: r.Offset,
+	}
+	if f.Sort == "" {
+		f.Sort = "-created_at"
+	}
+	if f.Limit == 0 {
+		f.Limit = DefaultListLimit
+	}
+	if f.Limit > MaxListLimit {
+		f.Limit = MaxListLimit
+	}
+	return f
+}
+
+// OrderBy is the ORDER BY clause of the sort. Unknown columns fall back to
+// newest first, so the result is always safe to put in a query. Ties are
+// broken by id to keep pages stable.
+func (f *ListFilter) OrderBy() string {
+	direction := "ASC"
+	sort := f.Sort
+	if strings.HasPrefix(sort, "-") {
+		direction = "DESC"
+		sort = sort[1:]
+	}
+	column, ok := listSortColumns[sort]
+	if !ok {
+		column, direction = "created_at", "DESC"
+	}
+	return column + " " + direction + ", id"
+}
+
+// ToResponse converts an account for the API
+func ToResponse(acc *Account) AccountResponse {
+	return AccountResponse{
+		ID:               acc.ID,
+		AccountNumber:    acc.AccountNumber,
+		AccountType:      acc.AccountType,
+		Balance:          acc.Balance,
+		FormattedBalance: currency.Format(acc.Currency, acc.Balance),
+		Currency:         acc.Currency,
+		InterestRate:     acc.InterestRate,
+		Status:           acc.Status,
+		FreezeReason:     acc.FreezeReason,
+		Version:          acc.Version,
+		CreatedAt:        acc.CreatedAt,
+	}
+}
@@ -0,0 +1,34 @@
+package account
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListAccountsRequest_Filter_Defaults(t *testing.T) {
+	userID := uuid.New()
+
+	f := (&ListAccountsRequest{}).Filter(userID)
+
+	assert.Equal(t, userID, f.UserID)
+	assert.Equal(t, DefaultListLimit, f.Limit)
+	assert.Equal(t, "created_at DESC, id", f.OrderBy())
+}
+
+func TestListFilter_OrderBy(t *testing.T) {
+	tests := []struct {
+		sort string
+		want string
+	}{
+		{sort: "balance", want: "balance ASC, id"},
+		{sort: "-balance", want: "balance DESC, id"},
+		{sort: "account_number", want: "account_number ASC, id"},
+		{sort: "balance; DROP TABLE accounts", want: "created_at DESC, id"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, (&ListFilter{Sort: tt.sort}).OrderBy(), tt.sort)
+	}
+}
@@ -1,14 +1,22 @@
 package account
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
+	"github.com/darisadam/madabank-server/internal/domain/currency"
+	"github.com/darisadam/madabank-server/internal/domain/interest"
+	"github.com/darisadam/madabank-server/internal/domain/validation"
 	"github.com/google/uuid"
 )
 
 type AccountType string
 type AccountStatus string
 
+// FreezeReason records why an account was frozen
+type FreezeReason string
+
 const (
 	AccountTypeChecking AccountType = "checking"
 	AccountTypeSavings  AccountType = "savings"
@@ -16,19 +24,75 @@ const (
 	AccountStatusActive AccountStatus = "active"
 	AccountStatusFrozen AccountStatus = "frozen"
 	AccountStatusClosed AccountStatus = "closed"
+
+	FreezeReasonFraud           FreezeReason = "fraud"
+	FreezeReasonLegalHold       FreezeReason = "legal_hold"
+	FreezeReasonCustomerRequest FreezeReason = "customer_request"
 )
 
+// Valid reports whether r is one of the known freeze reasons
+func (r FreezeReason) Valid() bool {
+	switch r {
+	case FreezeReasonFraud, FreezeReasonLegalHold, FreezeReasonCustomerRequest:
+		return true
+	}
+	return false
+}
+
+// ErrVersionConflict is returned when an account changed between being read
+// and being updated
+var ErrVersionConflict = errors.New("account was changed by another request, reload it and try again")
+
 type Account struct {
-	ID            uuid.UUID     `json:"id"`
-	UserID        uuid.UUID     `json:"user_id"`
-	AccountNumber string        `json:"account_number"`
-	AccountType   AccountType   `json:"account_type"`
-	Balance       float64       `json:"balance"`
-	Currency      string        `json:"currency"`
-	InterestRate  float64       `json:"interest_rate"`
-	Status        AccountStatus `json:"status"`
-	CreatedAt     time.Time     `json:"created_at"`
-	UpdatedAt     time.Time     `json:"updated_at"`
+	ID            uuid.UUID   `json:"id"`
+	UserID        uuid.UUID   `json:"user_id"`
+	AccountNumber string      `json:"account_number"`
+	AccountType   AccountType `json:"account_type"`
+	Balance       float64     `json:"balance"`
+	Currency      string      `json:"currency"`
+	InterestRate  float64     `json:"interest_rate"`
+	// OverdraftLimit is how far below zero the balance may go; zero for none
+	OverdraftLimit float64       `json:"overdraft_limit"`
+	Status         AccountStatus `json:"status"`
+	FreezeReason   *FreezeReason `json:"freeze_reason,omitempty"` // set while frozen
+	// Version goes up on every change to the row, for optimistic locking
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Set on savings account details only: the product's rate tiers, and the
+	// single rate the current balance earns across InterestRate and them
+	InterestTiers         []interest.Tier `json:"interest_tiers,omitempty"`
+	EffectiveInterestRate float64         `json:"effective_interest_rate,omitempty"`
+}
+
+// AvailableBalance is what the customer can spend: the ledger balance less
+// the funds held for pending payments, plus any overdraft
+func (a *Account) AvailableBalance(held float64) float64 {
+	return a.Balance - held + a.OverdraftLimit
+}
+
+// Holder is an account with its owner's name, for showing it as the other
+// side of a transaction
+type Holder struct {
+	AccountID     uuid.UUID
+	UserID        uuid.UUID
+	AccountNumber string
+	FirstName     string
+	LastName      string
+}
+
+// ReceivingAccount picks where payments addressed to a customer rather than
+// an account go, such as by phone number or @handle: their oldest active
+// account. It returns nil when none is active.
+func ReceivingAccount(accounts []*Account) *Account {
+	var oldest *Account
+	for _, a := range accounts {
+		if a.Status == AccountStatusActive && (oldest == nil || a.CreatedAt.Before(oldest.CreatedAt)) {
+			oldest = a
+		}
+	}
+	return oldest
 }
 
 type CreateAccountRequest struct {
@@ -37,30 +101,118 @@ type CreateAccountRequest struct {
 	InterestRate float64 `json:"interest_rate,omitempty"`
 }
 
+// Validate checks the currency; see package validation
+func (r *CreateAccountRequest) Validate() error {
+	return validation.New().Currency("currency", r.Currency).Err()
+}
+
 type AccountResponse struct {
-	ID            uuid.UUID     `json:"id"`
-	AccountNumber string        `json:"account_number"`
-	AccountType   AccountType   `json:"account_type"`
-	Balance       float64       `json:"balance"`
-	Currency      string        `json:"currency"`
-	InterestRate  float64       `json:"interest_rate"`
-	Status        AccountStatus `json:"status"`
-	CreatedAt     time.Time     `json:"created_at"`
+	ID               uuid.UUID     `json:"id"`
+	AccountNumber    string        `json:"account_number"`
+	AccountType      AccountType   `json:"account_type"`
+	Balance          float64       `json:"balance"`
+	FormattedBalance string        `json:"formatted_balance"` // e.g. IDR 1,500,000.00
+	Currency         string        `json:"currency"`
+	InterestRate     float64       `json:"interest_rate"`
+	Status           AccountStatus `json:"status"`
+	FreezeReason     *FreezeReason `json:"freeze_reason,omitempty"`
+	Version          int           `json:"version"`
+	CreatedAt        time.Time     `json:"created_at"`
 }
 
 type AccountListResponse struct {
 	Accounts []AccountResponse `json:"accounts"`
-	Total    int               `json:"total"`
+	Total    int               `json:"total"` // matching accounts across all pages
+	Limit    int               `json:"limit"`
+	Offset   int               `json:"offset"`
+	HasMore  bool              `json:"has_more"`
 }
 
+// BalanceResponse reports an account's ledger balance, the posted total, and
+// its available balance, what can be spent now. Balance and FormattedBalance
+// are the ledger balance.
 type BalanceResponse struct {
-	AccountID     uuid.UUID `json:"account_id"`
-	AccountNumber string    `json:"account_number"`
-	Balance       float64   `json:"balance"`
-	Currency      string    `json:"currency"`
-	AsOfDate      time.Time `json:"as_of_date"`
+	AccountID                 uuid.UUID `json:"account_id"`
+	AccountNumber             string    `json:"account_number"`
+	Balance                   float64   `json:"balance"`
+	FormattedBalance          string    `json:"formatted_balance"`
+	LedgerBalance             float64   `json:"ledger_balance"`
+	AvailableBalance          float64   `json:"available_balance"`
+	FormattedAvailableBalance string    `json:"formatted_available_balance"`
+	HeldAmount                float64   `json:"held_amount"`
+	OverdraftLimit            float64   `json:"overdraft_limit"`
+	Currency                  string    `json:"currency"`
+	AsOfDate                  time.Time `json:"as_of_date"`
+	// Balances lists every currency the account holds, its own first
+	Balances []CurrencyBalance `json:"balances"`
+}
+
+// CurrencyBalance is what an account holds in one currency. Besides its own
+// currency, whose balance is Account.Balance, an account can hold others the
+// customer opened, for receiving and sending in that currency.
+type CurrencyBalance struct {
+	Currency         string  `json:"currency"`
+	Balance          float64 `json:"balance"`
+	FormattedBalance string  `json:"formatted_balance"`
+}
+
+func NewCurrencyBalance(code string, balance float64) *CurrencyBalance {
+	return &CurrencyBalance{Currency: code, Balance: balance, FormattedBalance: currency.Format(code, balance)}
+}
+
+type AddCurrencyRequest struct {
+	Currency string `json:"currency" binding:"required,len=3"`
+}
+
+// Validate checks the currency; see package validation
+func (r *AddCurrencyRequest) Validate() error {
+	return validation.New().Currency("currency", r.Currency).Err()
 }
 
 type UpdateAccountRequest struct {
 	Status *string `json:"status,omitempty" binding:"omitempty,oneof=active frozen closed"`
+	// Reason is required when freezing, and only then. Customers freeze their
+	// own accounts for customer_request; the other reasons are the bank's.
+	Reason *string `json:"reason,omitempty" binding:"omitempty,oneof=customer_request"`
+	// Version, when set, is the version the client last read; the update is
+	// refused if the account has changed since
+	Version *int `json:"version,omitempty" binding:"omitempty,min=1"`
+}
+
+// Validate checks that a freeze says why, and that the reason is one a
+// customer may give
+func (r *UpdateAccountRequest) Validate() error {
+	freezing := r.Status != nil && AccountStatus(*r.Status) == AccountStatusFrozen
+	if freezing && r.Reason == nil {
+		return fmt.Errorf("reason is required when freezing an account")
+	}
+	if !freezing && r.Reason != nil {
+		return fmt.Errorf("reason only applies when freezing an account")
+	}
+	if freezing && FreezeReason(*r.Reason) != FreezeReasonCustomerRequest {
+		return fmt.Errorf("reason must be %s", FreezeReasonCustomerRequest)
+	}
+	return nil
+}
+
+// BalanceTotal is the sum of the balances of active accounts of one type and
+// currency
+type BalanceTotal struct {
+	AccountType AccountType
+	Currency    string
+	Total       float64
+}
+
+// LedgerBalance compares an account's stored balance with the balance implied
+// by its transactions
+type LedgerBalance struct {
+	AccountID     uuid.UUID `json:"account_id"`
+	AccountNumber string    `json:"account_number"`
+	StoredBalance float64   `json:"stored_balance"`
+	LedgerBalance float64   `json:"ledger_balance"`
+}
+
+// Drift returns stored minus ledger balance; non-zero means the account is out of sync
+func (l *LedgerBalance) Drift() float64 {
+	return l.StoredBalance - l.LedgerBalance
 }
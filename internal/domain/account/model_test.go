@@ -2,6 +2,7 @@ package account
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -67,6 +68,22 @@ func TestUpdateAccountRequest_OptionalStatus(t *testing.T) {
 	assert.Nil(t, reqEmpty.Status)
 }
 
+func TestUpdateAccountRequest_ValidateFreezeReason(t *testing.T) {
+	frozen, active, reason, bankReason := "frozen", "active", "customer_request", "legal_hold"
+
+	assert.NoError(t, (&UpdateAccountRequest{Status: &frozen, Reason: &reason}).Validate())
+	assert.EqualError(t, (&UpdateAccountRequest{Status: &frozen, Reason: &bankReason}).Validate(), "reason must be customer_request")
+	assert.NoError(t, (&UpdateAccountRequest{Status: &active}).Validate())
+	assert.EqualError(t, (&UpdateAccountRequest{Status: &frozen}).Validate(), "reason is required when freezing an account")
+	assert.EqualError(t, (&UpdateAccountRequest{Status: &active, Reason: &reason}).Validate(), "reason only applies when freezing an account")
+}
+
+func TestFreezeReason_Valid(t *testing.T) {
+	assert.True(t, FreezeReasonFraud.Valid())
+	assert.True(t, FreezeReasonCustomerRequest.Valid())
+	assert.False(t, FreezeReason("fraud investigation").Valid())
+}
+
 func TestBalanceResponse_Structure(t *testing.T) {
 	accountID := uuid.New()
 	resp := BalanceResponse{
@@ -79,3 +96,33 @@ func TestBalanceResponse_Structure(t *testing.T) {
 	assert.Equal(t, accountID, resp.AccountID)
 	assert.Equal(t, 500.25, resp.Balance)
 }
+
+func TestAccount_AvailableBalance(t *testing.T) {
+	acc := &Account{Balance: 100.00}
+	assert.Equal(t, 100.00, acc.AvailableBalance(0))
+	assert.Equal(t, 60.00, acc.AvailableBalance(40.00))
+
+	acc.OverdraftLimit = 50.00
+	assert.Equal(t, 110.00, acc.AvailableBalance(40.00))
+
+	acc.Balance = -20.00
+	assert.Equal(t, 30.00, acc.AvailableBalance(0))
+}
+
+func TestLedgerBalance_Drift(t *testing.T) {
+	b := LedgerBalance{StoredBalance: 150.00, LedgerBalance: 100.00}
+	assert.Equal(t, 50.00, b.Drift())
+
+	b.StoredBalance = 100.00
+	assert.Equal(t, 0.0, b.Drift())
+}
+
+func TestReceivingAccount(t *testing.T) {
+	now := time.Now()
+	oldest := &Account{ID: uuid.New(), Status: AccountStatusActive, CreatedAt: now.AddDate(-1, 0, 0)}
+	newer := &Account{ID: uuid.New(), Status: AccountStatusActive, CreatedAt: now}
+	frozen := &Account{ID: uuid.New(), Status: AccountStatusFrozen, CreatedAt: now.AddDate(-2, 0, 0)}
+
+	assert.Equal(t, oldest, ReceivingAccount([]*Account{newer, frozen, oldest}))
+	assert.Nil(t, ReceivingAccount([]*Account{frozen}))
+}
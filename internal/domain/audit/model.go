@@ -1,6 +1,11 @@
 package audit
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,6 +24,8 @@ type AuditLog struct {
 	RequestBody  map[string]interface{} `json:"request_body,omitempty"`
 	ResponseBody map[string]interface{} `json:"response_body,omitempty"`
 	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+	PrevHash     string                 `json:"prev_hash,omitempty"`
+	Hash         string                 `json:"hash,omitempty"`
 }
 
 type CreateAuditLogRequest struct {
@@ -32,3 +39,85 @@ type CreateAuditLogRequest struct {
 	ResponseBody map[string]interface{}
 	Metadata     map[string]interface{}
 }
+
+// chainPayload is the canonical form hashed into the audit chain. Field order
+// is fixed and map keys are sorted by encoding/json, so the hash is stable.
+type chainPayload struct {
+	PrevHash     string                 `json:"prev_hash"`
+	EventID      uuid.UUID              `json:"event_id"`
+	Timestamp    string                 `json:"timestamp"`
+	UserID       *uuid.UUID             `json:"user_id"`
+	Action       string                 `json:"action"`
+	Resource     string                 `json:"resource"`
+	IPAddress    string                 `json:"ip_address"`
+	UserAgent    string                 `json:"user_agent"`
+	Status       string                 `json:"status"`
+	RequestBody  map[string]interface{} `json:"request_body"`
+	ResponseBody map[string]interface{} `json:"response_body"`
+	Metadata     map[string]interface{} `json:"metadata"`
+}
+
+// ComputeHash returns the SHA-256 of the record linked to prevHash.
+// Timestamps are hashed at microsecond precision to match Postgres storage.
+func (l *AuditLog) ComputeHash(prevHash string) (string, error) {
+	payload, err := json.Marshal(chainPayload{
+		PrevHash:     prevHash,
+		EventID:      l.EventID,
+		Timestamp:    l.Timestamp.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano),
+		UserID:       l.UserID,
+		Action:       l.Action,
+		Resource:     l.Resource,
+		IPAddress:    l.IPAddress,
+		UserAgent:    l.UserAgent,
+		Status:       l.Status,
+		RequestBody:  l.RequestBody,
+		ResponseBody: l.ResponseBody,
+		Metadata:     l.Metadata,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode audit log: %w", err)
+	}
+
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// NormalizeIP returns ip in the text form Postgres gives back for the stored
+// inet, so the hash written on insert matches the one verification recomputes
+// from host(ip_address). IPv4-mapped addresses become plain IPv4, and
+// IPv4-compatible ones keep their dotted tail as Postgres prints them.
+// Values that are not IP addresses are returned unchanged.
+func NormalizeIP(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ip
+	}
+	if v4 := parsed.To4(); v4 != nil {
+		return v4.String()
+	}
+	if isZero(parsed[:12]) && !isZero(parsed[12:14]) {
+		return "::" + net.IP(parsed[12:]).String()
+	}
+	return parsed.String()
+}
+
+func isZero(b []byte) bool {
+	for _, c := range b {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// ChainVerification is the result of walking the audit hash chain
+type ChainVerification struct {
+	Valid          bool      `json:"valid"`
+	RecordsChecked int64     `json:"records_checked"`
+	FirstID        int64     `json:"first_id,omitempty"`
+	LastID         int64     `json:"last_id,omitempty"`
+	AnchorHash     string    `json:"anchor_hash,omitempty"` // prev_hash of the first remaining record (set when older rows were archived)
+	BrokenAtID     *int64    `json:"broken_at_id,omitempty"`
+	Reason         string    `json:"reason,omitempty"`
+	VerifiedAt     time.Time `json:"verified_at"`
+}
@@ -100,3 +100,51 @@ func TestAuditLog_RequestResponseBodies(t *testing.T) {
 	assert.NotNil(t, log.ResponseBody)
 	assert.Equal(t, 201, log.ResponseBody["status_code"])
 }
+
+func TestAuditLog_ComputeHash(t *testing.T) {
+	userID := uuid.New()
+	log := AuditLog{
+		EventID:   uuid.New(),
+		Timestamp: time.Date(2024, 1, 1, 12, 0, 0, 123456789, time.UTC),
+		UserID:    &userID,
+		Action:    "TRANSFER_COMPLETED",
+		Status:    "success",
+		Metadata:  map[string]interface{}{"amount": 100.0, "from": "a"},
+	}
+
+	hash1, err := log.ComputeHash("")
+	assert.NoError(t, err)
+	assert.Len(t, hash1, 64)
+
+	// Deterministic, and nanoseconds below Postgres precision are ignored
+	log.Timestamp = log.Timestamp.Truncate(time.Microsecond)
+	hash2, _ := log.ComputeHash("")
+	assert.Equal(t, hash1, hash2)
+
+	// Linked to the previous hash
+	hash3, _ := log.ComputeHash(hash1)
+	assert.NotEqual(t, hash1, hash3)
+
+	// Any field change changes the hash
+	log.Metadata["amount"] = 1000.0
+	hash4, _ := log.ComputeHash("")
+	assert.NotEqual(t, hash1, hash4)
+}
+
+func TestNormalizeIP(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"192.168.1.1", "192.168.1.1"},
+		{"::ffff:192.168.1.1", "192.168.1.1"},
+		{"2001:DB8:0:0::1", "2001:db8::1"},
+		{"::1", "::1"},
+		{"::192.168.1.1", "::192.168.1.1"},
+		{"", ""},
+		{"not-an-ip", "not-an-ip"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, NormalizeIP(tt.in), tt.in)
+	}
+}
@@ -0,0 +1,117 @@
+package beneficiary
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Type string
+type VerificationStatus string
+
+const (
+	// TypeInternalAccount is an account at this bank, addressed by account number
+	TypeInternalAccount Type = "internal_account"
+	// TypePhone is a customer of this bank, addressed by registered phone number
+	TypePhone Type = "phone"
+	// TypeExternalBank is an account at another bank
+	TypeExternalBank Type = "external_bank"
+
+	VerificationUnverified VerificationStatus = "unverified"
+	// VerificationVerified: the recipient resolved to an active account
+	VerificationVerified VerificationStatus = "verified"
+	// VerificationFailed: the recipient no longer resolves to an active account
+	VerificationFailed VerificationStatus = "failed"
+)
+
+const (
+	// NewBeneficiaryWindow is how long after a recipient is saved transfers
+	// to it are still flagged as going to a new beneficiary
+	NewBeneficiaryWindow = 24 * time.Hour
+
+	// MaxPerUser bounds a user's payee directory
+	MaxPerUser = 100
+)
+
+type Beneficiary struct {
+	ID                 uuid.UUID          `json:"id"`
+	UserID             uuid.UUID          `json:"user_id"`
+	Type               Type               `json:"type"`
+	Nickname           string             `json:"nickname"`
+	AccountNumber      string             `json:"account_number,omitempty"`
+	PhoneNumber        string             `json:"phone_number,omitempty"`
+	BankCode           string             `json:"bank_code,omitempty"`
+	HolderName         string             `json:"holder_name"`
+	AccountID          *uuid.UUID         `json:"account_id,omitempty"` // resolved account for internal and phone recipients
+	VerificationStatus VerificationStatus `json:"verification_status"`
+	VerifiedAt         *time.Time         `json:"verified_at,omitempty"`
+	LastUsedAt         *time.Time         `json:"last_used_at,omitempty"`
+	CreatedAt          time.Time          `json:"created_at"`
+	UpdatedAt          time.Time          `json:"updated_at"`
+}
+
+// Transferable reports whether the recipient can prefill an internal transfer
+func (b *Beneficiary) Transferable() bool {
+	return b.Type != TypeExternalBank && b.VerificationStatus == VerificationVerified && b.AccountID != nil
+}
+
+// IsNewDestination reports whether a transfer should be flagged for fraud
+// screening as going to a new beneficiary: the destination is not saved, not
+// verified, or was saved within NewBeneficiaryWindow. b is nil when the
+// destination is not in the user's payee directory.
+func IsNewDestination(b *Beneficiary, now time.Time) bool {
+	if b == nil || b.VerificationStatus != VerificationVerified {
+		return true
+	}
+	return now.Sub(b.CreatedAt) < NewBeneficiaryWindow
+}
+
+// NormalizePhoneNumber strips the separators customers type into phone numbers
+func NormalizePhoneNumber(raw string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(raw))
+}
+
+type CreateBeneficiaryRequest struct {
+	Type          Type   `json:"type" binding:"required,oneof=internal_account phone external_bank"`
+	Nickname      string `json:"nickname" binding:"required,max=50"`
+	AccountNumber string `json:"account_number,omitempty" binding:"omitempty,max=34"`
+	PhoneNumber   string `json:"phone_number,omitempty" binding:"omitempty,max=20"`
+	BankCode      string `json:"bank_code,omitempty" binding:"omitempty,max=20"`
+	HolderName    string `json:"holder_name,omitempty" binding:"omitempty,max=200"` // required for external_bank
+}
+
+// Validate checks the destination fields required by the recipient type
+func (r *CreateBeneficiaryRequest) Validate() error {
+	switch r.Type {
+	case TypeInternalAccount:
+		if r.AccountNumber == "" {
+			return fmt.Errorf("account_number is required for internal_account beneficiaries")
+		}
+		if r.PhoneNumber != "" || r.BankCode != "" {
+			return fmt.Errorf("internal_account beneficiaries only take an account_number")
+		}
+	case TypePhone:
+		if r.PhoneNumber == "" {
+			return fmt.Errorf("phone_number is required for phone beneficiaries")
+		}
+		if r.AccountNumber != "" || r.BankCode != "" {
+			return fmt.Errorf("phone beneficiaries only take a phone_number")
+		}
+	case TypeExternalBank:
+		if r.BankCode == "" || r.AccountNumber == "" || r.HolderName == "" {
+			return fmt.Errorf("bank_code, account_number and holder_name are required for external_bank beneficiaries")
+		}
+		if r.PhoneNumber != "" {
+			return fmt.Errorf("external_bank beneficiaries do not take a phone_number")
+		}
+	default:
+		return fmt.Errorf("invalid beneficiary type: %s", r.Type)
+	}
+	return nil
+}
+
+type UpdateBeneficiaryRequest struct {
+	Nickname string `json:"nickname" binding:"required,max=50"`
+}
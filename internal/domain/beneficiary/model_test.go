@@ -0,0 +1,63 @@
+package beneficiary
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsNewDestination(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	saved := func(status VerificationStatus, age time.Duration) *Beneficiary {
+		return &Beneficiary{VerificationStatus: status, CreatedAt: now.Add(-age)}
+	}
+
+	assert.True(t, IsNewDestination(nil, now))
+	assert.True(t, IsNewDestination(saved(VerificationVerified, time.Hour), now))
+	assert.True(t, IsNewDestination(saved(VerificationUnverified, 30*24*time.Hour), now))
+	assert.True(t, IsNewDestination(saved(VerificationFailed, 30*24*time.Hour), now))
+	assert.False(t, IsNewDestination(saved(VerificationVerified, NewBeneficiaryWindow), now))
+}
+
+func TestBeneficiary_Transferable(t *testing.T) {
+	accountID := uuid.New()
+
+	assert.True(t, (&Beneficiary{Type: TypePhone, VerificationStatus: VerificationVerified, AccountID: &accountID}).Transferable())
+	assert.False(t, (&Beneficiary{Type: TypeInternalAccount, VerificationStatus: VerificationFailed, AccountID: &accountID}).Transferable())
+	assert.False(t, (&Beneficiary{Type: TypeExternalBank, VerificationStatus: VerificationVerified, AccountID: &accountID}).Transferable())
+	assert.False(t, (&Beneficiary{Type: TypeInternalAccount, VerificationStatus: VerificationVerified}).Transferable())
+}
+
+func TestCreateBeneficiaryRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     CreateBeneficiaryRequest
+		wantErr bool
+	}{
+		{"internal account", CreateBeneficiaryRequest{Type: TypeInternalAccount, AccountNumber: "1234567890"}, false},
+		{"internal without number", CreateBeneficiaryRequest{Type: TypeInternalAccount}, true},
+		{"internal with phone", CreateBeneficiaryRequest{Type: TypeInternalAccount, AccountNumber: "1234567890", PhoneNumber: "0812"}, true},
+		{"phone", CreateBeneficiaryRequest{Type: TypePhone, PhoneNumber: "081234567890"}, false},
+		{"phone without number", CreateBeneficiaryRequest{Type: TypePhone}, true},
+		{"external bank", CreateBeneficiaryRequest{Type: TypeExternalBank, BankCode: "014", AccountNumber: "9876543210", HolderName: "Siti"}, false},
+		{"external without holder", CreateBeneficiaryRequest{Type: TypeExternalBank, BankCode: "014", AccountNumber: "9876543210"}, true},
+		{"unknown type", CreateBeneficiaryRequest{Type: "wallet"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNormalizePhoneNumber(t *testing.T) {
+	assert.Equal(t, "+6281234567890", NormalizePhoneNumber(" +62 812-3456-7890 "))
+}
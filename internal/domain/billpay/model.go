@@ -0,0 +1,66 @@
+package billpay
+
+import "time"
+
+type BillerCategory string
+
+const (
+	BillerCategoryElectricity BillerCategory = "electricity"
+	BillerCategoryWater       BillerCategory = "water"
+	BillerCategoryInternet    BillerCategory = "internet"
+	BillerCategoryPhone       BillerCategory = "phone"
+	BillerCategoryTax         BillerCategory = "tax"
+)
+
+// InquiryTTL is how long an inquiry result can be paid before the bill has to be fetched again
+const InquiryTTL = 15 * time.Minute
+
+// Biller is an entry of the biller catalog
+type Biller struct {
+	Code                string         `json:"code"`
+	Name                string         `json:"name"`
+	Category            BillerCategory `json:"category"`
+	CustomerNumberLabel string         `json:"customer_number_label"`
+	AdminFee            float64        `json:"admin_fee"`
+	Active              bool           `json:"active"`
+	CreatedAt           time.Time      `json:"created_at"`
+}
+
+// Bill is the amount due returned by an inquiry. It is kept server-side for
+// InquiryTTL so the payment cannot change the amount or the customer.
+type Bill struct {
+	InquiryID      string         `json:"inquiry_id"`
+	BillerCode     string         `json:"biller_code"`
+	BillerName     string         `json:"biller_name"`
+	Category       BillerCategory `json:"category"`
+	CustomerNumber string         `json:"customer_number"`
+	CustomerName   string         `json:"customer_name"`
+	Period         string         `json:"period,omitempty"`
+	Amount         float64        `json:"amount"`
+	AdminFee       float64        `json:"admin_fee"`
+	TotalAmount    float64        `json:"total_amount"`
+	ExpiresAt      time.Time      `json:"expires_at"`
+}
+
+func IsValidCategory(category BillerCategory) bool {
+	switch category {
+	case BillerCategoryElectricity, BillerCategoryWater, BillerCategoryInternet, BillerCategoryPhone, BillerCategoryTax:
+		return true
+	}
+	return false
+}
+
+type ListBillersRequest struct {
+	Category string `form:"category,omitempty"`
+}
+
+type InquiryRequest struct {
+	BillerCode     string `json:"biller_code" binding:"required"`
+	CustomerNumber string `json:"customer_number" binding:"required,min=4,max=32,numeric"`
+}
+
+type PaymentRequest struct {
+	AccountID      string `json:"account_id" binding:"required,uuid"`
+	InquiryID      string `json:"inquiry_id" binding:"required,uuid"`
+	IdempotencyKey string `json:"idempotency_key" binding:"required"`
+}
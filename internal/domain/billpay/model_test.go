@@ -0,0 +1,18 @@
+package billpay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsValidCategory(t *testing.T) {
+	for _, category := range []BillerCategory{
+		BillerCategoryElectricity, BillerCategoryWater, BillerCategoryInternet, BillerCategoryPhone, BillerCategoryTax,
+	} {
+		assert.True(t, IsValidCategory(category), category)
+	}
+
+	assert.False(t, IsValidCategory(""))
+	assert.False(t, IsValidCategory("groceries"))
+}
@@ -0,0 +1,126 @@
+package card
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/validation"
+	"github.com/google/uuid"
+)
+
+type AuthorizationStatus string
+
+const (
+	AuthorizationStatusApproved AuthorizationStatus = "approved"
+	AuthorizationStatusDeclined AuthorizationStatus = "declined"
+	AuthorizationStatusCaptured AuthorizationStatus = "captured"
+	AuthorizationStatusReleased AuthorizationStatus = "released"
+
+	// HoldDuration is how long an approved authorization reserves funds
+	HoldDuration = 7 * 24 * time.Hour
+
+	// DefaultLimitTimezone is where a card's daily limit resets at midnight
+	DefaultLimitTimezone = "Asia/Jakarta"
+)
+
+// ErrAuthorizationNotOpen is returned when capturing an authorization that is
+// not approved, or whose hold has expired
+var ErrAuthorizationNotOpen = errors.New("authorization is not open for capture")
+
+// HoldOutcome reports whether an authorization hold was placed and, if not, why
+type HoldOutcome int
+
+const (
+	HoldPlaced HoldOutcome = iota
+	HoldDailyLimitExceeded
+	HoldInsufficientFunds
+)
+
+// SpendDayStart returns the midnight in loc that starts the daily-limit window containing now
+func SpendDayStart(now time.Time, loc *time.Location) time.Time {
+	local := now.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+}
+
+// Response codes returned to the acquirer, following ISO 8583 field 39
+const (
+	ResponseApproved           = "00"
+	ResponseDoNotHonor         = "05"
+	ResponseInvalidCard        = "14"
+	ResponseInsufficientFunds  = "51"
+	ResponseExpiredCard        = "54"
+	ResponseIncorrectPIN       = "55"
+	ResponseNotPermitted       = "57"
+	ResponseExceedsLimit       = "61"
+	ResponseRestrictedCard     = "62"
+	ResponsePINTriesExceeded   = "75"
+	ResponseInvalidTransaction = "12"
+)
+
+// Authorization is an acquirer request to reserve funds for a card payment
+type Authorization struct {
+	ID                uuid.UUID           `json:"id"`
+	CardID            uuid.UUID           `json:"card_id"`
+	AccountID         uuid.UUID           `json:"account_id"`
+	TokenID           *uuid.UUID          `json:"token_id,omitempty"` // set when paid with a wallet token
+	Amount            float64             `json:"amount"`
+	Currency          string              `json:"currency"`
+	Status            AuthorizationStatus `json:"status"`
+	ResponseCode      string              `json:"response_code"`
+	DeclineReason     string              `json:"decline_reason,omitempty"`
+	AuthCode          string              `json:"auth_code,omitempty"`
+	MerchantName      string              `json:"merchant_name,omitempty"`
+	MerchantCategory  string              `json:"merchant_category_code,omitempty"`
+	Channel           Channel             `json:"channel"`
+	AcquirerReference string              `json:"acquirer_reference,omitempty"`
+	ExpiresAt         *time.Time          `json:"expires_at,omitempty"`
+	CapturedAmount    *float64            `json:"captured_amount,omitempty"`
+	TransactionID     *uuid.UUID          `json:"transaction_id,omitempty"` // the debit, for debit cards
+	CapturedAt        *time.Time          `json:"captured_at,omitempty"`
+	CreatedAt         time.Time           `json:"created_at"`
+}
+
+// IsOpen reports whether the authorization still holds funds that can be captured
+func (a *Authorization) IsOpen(now time.Time) bool {
+	return a.Status == AuthorizationStatusApproved && a.ExpiresAt != nil && a.ExpiresAt.After(now)
+}
+
+// CaptureRequest settles an approved authorization for up to its amount; the
+// rest of the hold is released
+type CaptureRequest struct {
+	// Amount defaults to the whole authorization
+	Amount float64 `json:"amount,omitempty" binding:"omitempty,gt=0"`
+}
+
+type AuthorizeRequest struct {
+	// CardNumber is the card number or a wallet token number
+	CardNumber        string  `json:"card_number" binding:"required,numeric,min=13,max=19"`
+	CVV               string  `json:"cvv,omitempty" binding:"omitempty,numeric,min=3,max=4"`
+	ExpiryMonth       int     `json:"expiry_month" binding:"required,min=1,max=12"`
+	ExpiryYear        int     `json:"expiry_year" binding:"required"`
+	PIN               string  `json:"pin,omitempty" binding:"omitempty,numeric,min=4,max=6"`
+	Amount            float64 `json:"amount" binding:"required,gt=0"`
+	Currency          string  `json:"currency" binding:"required,len=3"`
+	Channel           Channel `json:"channel" binding:"required,oneof=pos contactless ecommerce atm"`
+	MerchantName      string  `json:"merchant_name" binding:"required,max=255"`
+	MerchantCategory  string  `json:"merchant_category_code" binding:"required,len=4,numeric"`
+	MerchantCountry   string  `json:"merchant_country" binding:"required,len=2"`
+	AcquirerReference string  `json:"acquirer_reference,omitempty" binding:"max=255"`
+}
+
+// Validate checks the amount in the currency, and the currency; see package
+// validation
+func (r *AuthorizeRequest) Validate() error {
+	currency := strings.ToUpper(r.Currency)
+	return validation.New().AmountIn("amount", r.Amount, currency).Currency("currency", currency).Err()
+}
+
+type AuthorizeResponse struct {
+	Approved        bool       `json:"approved"`
+	ResponseCode    string     `json:"response_code"`
+	AuthCode        string     `json:"auth_code,omitempty"`
+	AuthorizationID *uuid.UUID `json:"authorization_id,omitempty"`
+	DeclineReason   string     `json:"decline_reason,omitempty"`
+	ExpiresAt       *time.Time `json:"expires_at,omitempty"`
+}
@@ -0,0 +1,31 @@
+package card
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpendDayStart(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+
+	// 20:30 UTC on the 1st is already 03:30 on the 2nd in Jakarta
+	start := SpendDayStart(time.Date(2026, 3, 1, 20, 30, 0, 0, time.UTC), jakarta)
+	assert.Equal(t, time.Date(2026, 3, 2, 0, 0, 0, 0, jakarta), start)
+	assert.Equal(t, time.Date(2026, 3, 1, 17, 0, 0, 0, time.UTC), start.UTC())
+
+	start = SpendDayStart(time.Date(2026, 3, 1, 16, 59, 0, 0, time.UTC), jakarta)
+	assert.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, jakarta), start)
+}
+
+func TestAuthorizeRequest_Validate(t *testing.T) {
+	req := &AuthorizeRequest{Amount: 1500, Currency: "jpy"}
+	assert.NoError(t, req.Validate())
+
+	req.Amount = 1500.50
+	assert.EqualError(t, req.Validate(), "amount must have at most 0 decimal places")
+
+	req = &AuthorizeRequest{Amount: 10, Currency: "ZZZ"}
+	assert.EqualError(t, req.Validate(), "currency must be a supported currency code such as IDR")
+}
@@ -0,0 +1,98 @@
+package card
+
+import "fmt"
+
+// Channel is the way a card is presented for a payment
+type Channel string
+
+const (
+	ChannelPOS         Channel = "pos" // chip or swipe at a terminal
+	ChannelContactless Channel = "contactless"
+	ChannelEcommerce   Channel = "ecommerce"
+	ChannelATM         Channel = "atm"
+)
+
+// Controls are the spending restrictions a cardholder sets on a card
+type Controls struct {
+	EcommerceEnabled   bool     `json:"ecommerce_enabled"`
+	ContactlessEnabled bool     `json:"contactless_enabled"`
+	ATMEnabled         bool     `json:"atm_enabled"`
+	ForeignEnabled     bool     `json:"foreign_enabled"`
+	BlockedMCCs        []string `json:"blocked_mccs"` // ISO 18245 merchant category codes
+}
+
+// DefaultControls leaves every channel enabled, matching cards issued before
+// controls existed
+func DefaultControls() Controls {
+	return Controls{
+		EcommerceEnabled:   true,
+		ContactlessEnabled: true,
+		ATMEnabled:         true,
+		ForeignEnabled:     true,
+		BlockedMCCs:        []string{},
+	}
+}
+
+// Check reports whether a payment with the given channel, merchant category
+// and origin is allowed by the controls
+func (c Controls) Check(channel Channel, mcc string, foreign bool) error {
+	switch channel {
+	case ChannelEcommerce:
+		if !c.EcommerceEnabled {
+			return fmt.Errorf("e-commerce payments are disabled for this card")
+		}
+	case ChannelContactless:
+		if !c.ContactlessEnabled {
+			return fmt.Errorf("contactless payments are disabled for this card")
+		}
+	case ChannelATM:
+		if !c.ATMEnabled {
+			return fmt.Errorf("ATM withdrawals are disabled for this card")
+		}
+	case ChannelPOS:
+	default:
+		return fmt.Errorf("unknown channel: %s", channel)
+	}
+
+	if foreign && !c.ForeignEnabled {
+		return fmt.Errorf("foreign payments are disabled for this card")
+	}
+
+	for _, blocked := range c.BlockedMCCs {
+		if blocked == mcc {
+			return fmt.Errorf("merchant category %s is blocked for this card", mcc)
+		}
+	}
+
+	return nil
+}
+
+// Apply merges the fields set in req into the controls
+func (c Controls) Apply(req *UpdateControlsRequest) Controls {
+	if req.EcommerceEnabled != nil {
+		c.EcommerceEnabled = *req.EcommerceEnabled
+	}
+	if req.ContactlessEnabled != nil {
+		c.ContactlessEnabled = *req.ContactlessEnabled
+	}
+	if req.ATMEnabled != nil {
+		c.ATMEnabled = *req.ATMEnabled
+	}
+	if req.ForeignEnabled != nil {
+		c.ForeignEnabled = *req.ForeignEnabled
+	}
+	if req.BlockedMCCs != nil {
+		c.BlockedMCCs = append([]string{}, req.BlockedMCCs...)
+	}
+	return c
+}
+
+// UpdateControlsRequest changes only the fields that are present. Sending an
+// empty blocked_mccs list clears all merchant category blocks.
+type UpdateControlsRequest struct {
+	EcommerceEnabled   *bool    `json:"ecommerce_enabled,omitempty"`
+	ContactlessEnabled *bool    `json:"contactless_enabled,omitempty"`
+	ATMEnabled         *bool    `json:"atm_enabled,omitempty"`
+	ForeignEnabled     *bool    `json:"foreign_enabled,omitempty"`
+	BlockedMCCs        []string `json:"blocked_mccs,omitempty" binding:"omitempty,max=50,dive,len=4,numeric"`
+}
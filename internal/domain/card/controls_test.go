@@ -0,0 +1,46 @@
+package card
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultControls_AllowEverything(t *testing.T) {
+	c := DefaultControls()
+
+	for _, channel := range []Channel{ChannelPOS, ChannelContactless, ChannelEcommerce, ChannelATM} {
+		assert.NoError(t, c.Check(channel, "5411", true))
+	}
+}
+
+func TestControls_Check(t *testing.T) {
+	c := DefaultControls()
+	c.EcommerceEnabled = false
+	c.ATMEnabled = false
+	c.ForeignEnabled = false
+	c.BlockedMCCs = []string{"7995"}
+
+	assert.Error(t, c.Check(ChannelEcommerce, "5411", false))
+	assert.Error(t, c.Check(ChannelATM, "6011", false))
+	assert.Error(t, c.Check(ChannelPOS, "5411", true))
+	assert.Error(t, c.Check(ChannelPOS, "7995", false))
+	assert.Error(t, c.Check(Channel("mail_order"), "5411", false))
+	assert.NoError(t, c.Check(ChannelContactless, "5411", false))
+	assert.NoError(t, c.Check(ChannelPOS, "5812", false))
+}
+
+func TestControls_Apply(t *testing.T) {
+	disabled := false
+	c := DefaultControls()
+	c.BlockedMCCs = []string{"7995"}
+
+	updated := c.Apply(&UpdateControlsRequest{ContactlessEnabled: &disabled})
+	assert.False(t, updated.ContactlessEnabled)
+	assert.True(t, updated.EcommerceEnabled)
+	assert.Equal(t, []string{"7995"}, updated.BlockedMCCs)
+
+	cleared := updated.Apply(&UpdateControlsRequest{BlockedMCCs: []string{}})
+	assert.Empty(t, cleared.BlockedMCCs)
+	assert.False(t, cleared.ContactlessEnabled)
+}
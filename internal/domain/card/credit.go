@@ -0,0 +1,119 @@
+package card
+
+import (
+	"math"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/validation"
+	"github.com/google/uuid"
+)
+
+// Credit card product terms
+const (
+	CreditAPR           = 0.24   // 24% a year on revolving balances
+	MinimumPaymentRate  = 0.10   // 10% of the closing balance
+	MinimumPaymentFloor = 50_000 // IDR
+	PaymentDueDays      = 20     // days between statement close and due date
+	MaxStatementDay     = 28     // keeps the cycle valid in every month
+)
+
+// Statement is a closed credit card billing cycle
+type Statement struct {
+	ID             uuid.UUID `json:"id"`
+	CardID         uuid.UUID `json:"card_id"`
+	PeriodStart    time.Time `json:"period_start"`
+	PeriodEnd      time.Time `json:"period_end"`
+	OpeningBalance float64   `json:"opening_balance"`
+	Purchases      float64   `json:"purchases"`
+	Payments       float64   `json:"payments"`
+	Interest       float64   `json:"interest"`
+	ClosingBalance float64   `json:"closing_balance"`
+	MinimumPayment float64   `json:"minimum_payment"`
+	DueDate        time.Time `json:"due_date"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type RepayCardRequest struct {
+	FromAccountID  string  `json:"from_account_id" binding:"required,uuid"`
+	Amount         float64 `json:"amount" binding:"required,gt=0"`
+	IdempotencyKey string  `json:"idempotency_key" binding:"required"`
+}
+
+// Validate checks the amount; see package validation
+func (r *RepayCardRequest) Validate() error {
+	return validation.New().Amount("amount", r.Amount).Err()
+}
+
+// AvailableCredit is the unused part of the credit limit
+func (c *Card) AvailableCredit() float64 {
+	return roundAmount(c.CreditLimit - c.OutstandingBalance)
+}
+
+// StatementDayFor picks the statement close day for a card issued on t
+func StatementDayFor(t time.Time) int {
+	if day := t.Day(); day <= MaxStatementDay {
+		return day
+	}
+	return MaxStatementDay
+}
+
+// MinimumPayment is the larger of MinimumPaymentRate of the closing balance and
+// MinimumPaymentFloor, never more than the balance itself
+func MinimumPayment(closingBalance float64) float64 {
+	if closingBalance <= 0 {
+		return 0
+	}
+	minimum := math.Max(closingBalance*MinimumPaymentRate, MinimumPaymentFloor)
+	return roundAmount(math.Min(minimum, closingBalance))
+}
+
+// RevolvingInterest charges daily interest on the part of the previous
+// statement balance that was not repaid during the cycle. A balance paid in
+// full accrues no interest.
+func RevolvingInterest(previousClosing, payments float64, days int) float64 {
+	revolving := previousClosing - payments
+	if revolving <= 0 || days <= 0 {
+		return 0
+	}
+	return roundAmount(revolving * CreditAPR / 365 * float64(days))
+}
+
+// BuildStatement closes a billing cycle for the card. previous is the last
+// closed statement, or nil for the first cycle.
+func BuildStatement(c *Card, previous *Statement, periodEnd time.Time) *Statement {
+	periodStart := c.CreatedAt
+	opening := 0.0
+	if previous != nil {
+		periodStart = previous.PeriodEnd
+		opening = previous.ClosingBalance
+	}
+	periodStart = truncateDay(periodStart)
+	periodEnd = truncateDay(periodEnd)
+
+	days := int(periodEnd.Sub(periodStart).Hours() / 24)
+	interest := RevolvingInterest(opening, c.CyclePayments, days)
+	closing := roundAmount(c.OutstandingBalance + interest)
+
+	return &Statement{
+		ID:             uuid.New(),
+		CardID:         c.ID,
+		PeriodStart:    periodStart,
+		PeriodEnd:      periodEnd,
+		OpeningBalance: opening,
+		Purchases:      c.CyclePurchases,
+		Payments:       c.CyclePayments,
+		Interest:       interest,
+		ClosingBalance: closing,
+		MinimumPayment: MinimumPayment(closing),
+		DueDate:        periodEnd.AddDate(0, 0, PaymentDueDays),
+	}
+}
+
+func truncateDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+func roundAmount(v float64) float64 {
+	return math.Round(v*100) / 100
+}
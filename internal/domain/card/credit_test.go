@@ -0,0 +1,81 @@
+package card
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMinimumPayment(t *testing.T) {
+	assert.Equal(t, 0.0, MinimumPayment(0))
+	// Below the floor the whole balance is due
+	assert.Equal(t, 30_000.0, MinimumPayment(30_000))
+	// Floor applies to small balances
+	assert.Equal(t, 50_000.0, MinimumPayment(200_000))
+	// Percentage applies to large balances
+	assert.Equal(t, 150_000.0, MinimumPayment(1_500_000))
+}
+
+func TestRevolvingInterest(t *testing.T) {
+	// Paid in full: no interest
+	assert.Equal(t, 0.0, RevolvingInterest(1_000_000, 1_000_000, 30))
+	assert.Equal(t, 0.0, RevolvingInterest(0, 0, 30))
+	// Half revolved for 365 days at the APR
+	assert.Equal(t, 120_000.0, RevolvingInterest(1_000_000, 500_000, 365))
+}
+
+func TestStatementDayFor(t *testing.T) {
+	assert.Equal(t, 5, StatementDayFor(time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, MaxStatementDay, StatementDayFor(time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestBuildStatement(t *testing.T) {
+	periodStart := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	periodEnd := time.Date(2026, 2, 10, 15, 30, 0, 0, time.UTC)
+	c := &Card{
+		ID:                 uuid.New(),
+		CardType:           CardTypeCredit,
+		CreditLimit:        5_000_000,
+		OutstandingBalance: 1_400_000,
+		CyclePurchases:     900_000,
+		CyclePayments:      500_000,
+	}
+	previous := &Statement{PeriodEnd: periodStart, ClosingBalance: 1_000_000}
+
+	st := BuildStatement(c, previous, periodEnd)
+
+	assert.Equal(t, c.ID, st.CardID)
+	assert.Equal(t, periodStart, st.PeriodStart)
+	assert.Equal(t, time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC), st.PeriodEnd)
+	assert.Equal(t, 1_000_000.0, st.OpeningBalance)
+	assert.Equal(t, 900_000.0, st.Purchases)
+	assert.Equal(t, 500_000.0, st.Payments)
+	// 500,000 revolved for 31 days
+	assert.Equal(t, 10_191.78, st.Interest)
+	assert.Equal(t, 1_410_191.78, st.ClosingBalance)
+	assert.Equal(t, 141_019.18, st.MinimumPayment)
+	assert.Equal(t, time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), st.DueDate)
+}
+
+func TestBuildStatement_FirstCycle(t *testing.T) {
+	c := &Card{
+		ID:                 uuid.New(),
+		OutstandingBalance: 250_000,
+		CyclePurchases:     250_000,
+		CreatedAt:          time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC),
+	}
+
+	st := BuildStatement(c, nil, time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC))
+
+	assert.Equal(t, 0.0, st.OpeningBalance)
+	assert.Equal(t, 0.0, st.Interest)
+	assert.Equal(t, 250_000.0, st.ClosingBalance)
+	assert.Equal(t, 50_000.0, st.MinimumPayment)
+}
+
+func TestCard_AvailableCredit(t *testing.T) {
+	c := &Card{CreditLimit: 5_000_000, OutstandingBalance: 1_250_000.5}
+	assert.Equal(t, 3_749_999.5, c.AvailableCredit())
+}
@@ -3,6 +3,7 @@ package card
 import (
 	"time"
 
+	"github.com/darisadam/madabank-server/internal/domain/user"
 	"github.com/google/uuid"
 )
 
@@ -14,14 +15,21 @@ const (
 	CardTypeCredit CardType = "credit"
 
 	CardStatusActive  CardStatus = "active"
-	CardStatusBlocked CardStatus = "blocked"
+	CardStatusFrozen  CardStatus = "frozen"  // temporary, reversible by the cardholder
+	CardStatusBlocked CardStatus = "blocked" // permanent, the card must be reissued
 	CardStatusExpired CardStatus = "expired"
+
+	// MaxPINAttempts is the number of consecutive wrong PINs before the PIN is locked
+	MaxPINAttempts = 3
+	// PINLockDuration is how long a PIN stays locked after too many wrong attempts
+	PINLockDuration = 24 * time.Hour
 )
 
 type Card struct {
 	ID                  uuid.UUID  `json:"id"`
 	AccountID           uuid.UUID  `json:"account_id"`
 	CardNumberEncrypted string     `json:"-"` // Never expose in JSON
+	CardNumberHash      string     `json:"-"` // Keyed digest used to look the card up by number
 	CVVEncrypted        string     `json:"-"` // Never expose in JSON
 	CardHolderName      string     `json:"card_holder_name"`
 	CardType            CardType   `json:"card_type"`
@@ -29,20 +37,85 @@ type Card struct {
 	ExpiryYear          int        `json:"expiry_year"`
 	Status              CardStatus `json:"status"`
 	DailyLimit          float64    `json:"daily_limit"`
+	PINEncrypted        string     `json:"-"` // Never expose in JSON
+	PINFailedAttempts   int        `json:"-"`
+	PINLockedUntil      *time.Time `json:"-"`
+	Controls            Controls   `json:"controls"`
+	ReplacesCardID      *uuid.UUID `json:"replaces_card_id,omitempty"`
+	CreditLimit         float64    `json:"credit_limit,omitempty"`
+	OutstandingBalance  float64    `json:"outstanding_balance,omitempty"`
+	StatementDay        int        `json:"statement_day,omitempty"`
+	CyclePurchases      float64    `json:"-"`
+	CyclePayments       float64    `json:"-"`
 	CreatedAt           time.Time  `json:"created_at"`
 }
 
+// statusTransitions lists the statuses each status may move to
+var statusTransitions = map[CardStatus][]CardStatus{
+	CardStatusActive:  {CardStatusFrozen, CardStatusBlocked, CardStatusExpired},
+	CardStatusFrozen:  {CardStatusActive, CardStatusBlocked, CardStatusExpired},
+	CardStatusBlocked: {CardStatusExpired},
+}
+
+// CanTransitionTo reports whether the card status may change from s to next
+func (s CardStatus) CanTransitionTo(next CardStatus) bool {
+	for _, allowed := range statusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPIN reports whether a PIN has been set on the card
+func (c *Card) HasPIN() bool {
+	return c.PINEncrypted != ""
+}
+
+// PINLocked reports whether PIN verification is currently locked
+func (c *Card) PINLocked(now time.Time) bool {
+	return c.PINLockedUntil != nil && now.Before(*c.PINLockedUntil)
+}
+
+// PINAttempt is a card's stored PIN state after an attempt was counted
+type PINAttempt struct {
+	// FailedAttempts is the number of consecutive wrong PINs, reset to zero
+	// by a correct PIN and when the PIN locks
+	FailedAttempts int
+	// LockedUntil is set when the PIN is locked, by this attempt or an
+	// earlier one
+	LockedUntil *time.Time
+}
+
+// IsExpired reports whether the card is past its expiry date. Cards stay
+// valid through the last day of their expiry month.
+func (c *Card) IsExpired(now time.Time) bool {
+	return pastExpiry(c.ExpiryMonth, c.ExpiryYear, now)
+}
+
+func pastExpiry(month, year int, now time.Time) bool {
+	validUntil := time.Date(year, time.Month(month)+1, 1, 0, 0, 0, 0, now.Location())
+	return !now.Before(validUntil)
+}
+
 type CardResponse struct {
-	ID               uuid.UUID  `json:"id"`
-	AccountID        uuid.UUID  `json:"account_id"`
-	CardNumberMasked string     `json:"card_number_masked"` // Only last 4 digits
-	CardHolderName   string     `json:"card_holder_name"`
-	CardType         CardType   `json:"card_type"`
-	ExpiryMonth      int        `json:"expiry_month"`
-	ExpiryYear       int        `json:"expiry_year"`
-	Status           CardStatus `json:"status"`
-	DailyLimit       float64    `json:"daily_limit"`
-	CreatedAt        time.Time  `json:"created_at"`
+	ID                 uuid.UUID  `json:"id"`
+	AccountID          uuid.UUID  `json:"account_id"`
+	CardNumberMasked   string     `json:"card_number_masked"` // Only last 4 digits
+	CardHolderName     string     `json:"card_holder_name"`
+	CardType           CardType   `json:"card_type"`
+	ExpiryMonth        int        `json:"expiry_month"`
+	ExpiryYear         int        `json:"expiry_year"`
+	Status             CardStatus `json:"status"`
+	DailyLimit         float64    `json:"daily_limit"`
+	HasPIN             bool       `json:"has_pin"`
+	Controls           Controls   `json:"controls"`
+	ReplacesCardID     *uuid.UUID `json:"replaces_card_id,omitempty"`
+	CreditLimit        float64    `json:"credit_limit,omitempty"`
+	OutstandingBalance float64    `json:"outstanding_balance,omitempty"`
+	AvailableCredit    float64    `json:"available_credit,omitempty"`
+	StatementDay       int        `json:"statement_day,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
 }
 
 type CreateCardRequest struct {
@@ -50,17 +123,48 @@ type CreateCardRequest struct {
 	CardHolderName string  `json:"card_holder_name" binding:"required,min=3,max=100"`
 	CardType       string  `json:"card_type" binding:"required,oneof=debit credit"`
 	DailyLimit     float64 `json:"daily_limit" binding:"required,gt=0"`
+	// Required for credit cards
+	CreditLimit float64 `json:"credit_limit,omitempty" binding:"omitempty,gt=0"`
 }
 
 type UpdateCardRequest struct {
-	Status     *string  `json:"status,omitempty" binding:"omitempty,oneof=active blocked"`
+	Status     *string  `json:"status,omitempty" binding:"omitempty,oneof=active frozen blocked"`
 	DailyLimit *float64 `json:"daily_limit,omitempty" binding:"omitempty,gt=0"`
 }
 
+// Revealing the full card number and CVV takes a step-up challenge: the
+// password unlocks an OTP, and the OTP a single-use reveal token.
+const (
+	RevealOTPTTL         = 5 * time.Minute
+	RevealTokenTTL       = 2 * time.Minute
+	RevealChallengeDelay = time.Minute // minimum gap between challenges for a card
+	MaxRevealOTPAttempts = 3
+)
+
+type RevealChallengeRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+type RevealChallengeResponse struct {
+	ChallengeID string          `json:"challenge_id"`
+	Channel     user.OTPChannel `json:"channel"`
+	ExpiresAt   time.Time       `json:"expires_at"`
+}
+
+type RevealVerifyRequest struct {
+	ChallengeID string `json:"challenge_id" binding:"required,uuid"`
+	OTP         string `json:"otp" binding:"required,len=6,numeric"`
+}
+
+type RevealTokenResponse struct {
+	RevealToken string    `json:"reveal_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
 type CardDetailsRequest struct {
 	CardID string `json:"card_id" binding:"required,uuid"`
-	// Require additional verification for showing full card details
-	Password string `json:"password" binding:"required"`
+	// Single-use token from a verified reveal challenge
+	RevealToken string `json:"reveal_token" binding:"required"`
 }
 
 type CardDetailsResponse struct {
@@ -69,3 +173,28 @@ type CardDetailsResponse struct {
 	ExpiryMonth int    `json:"expiry_month"`
 	ExpiryYear  int    `json:"expiry_year"`
 }
+
+type RotateCVVRequest struct {
+	// Require the account password to rotate the CVV
+	Password string `json:"password" binding:"required"`
+}
+
+type SetPINRequest struct {
+	PIN string `json:"pin" binding:"required,numeric,min=4,max=6"`
+	// Require the account password to set or change the PIN
+	Password string `json:"password" binding:"required"`
+}
+
+type ReissueCardRequest struct {
+	Reason string `json:"reason" binding:"required,oneof=lost stolen damaged expiring"`
+}
+
+type VerifyPINRequest struct {
+	PIN string `json:"pin" binding:"required,numeric,min=4,max=6"`
+}
+
+type VerifyPINResponse struct {
+	Valid             bool       `json:"valid"`
+	RemainingAttempts int        `json:"remaining_attempts"`
+	LockedUntil       *time.Time `json:"locked_until,omitempty"`
+}
@@ -92,12 +92,12 @@ func TestUpdateCardRequest_OptionalFields(t *testing.T) {
 
 func TestCardDetailsRequest_Structure(t *testing.T) {
 	req := CardDetailsRequest{
-		CardID:   uuid.New().String(),
-		Password: "userpassword",
+		CardID:      uuid.New().String(),
+		RevealToken: "reveal-token",
 	}
 
 	assert.NotEmpty(t, req.CardID)
-	assert.Equal(t, "userpassword", req.Password)
+	assert.Equal(t, "reveal-token", req.RevealToken)
 }
 
 func TestCardDetailsResponse_SensitiveData(t *testing.T) {
@@ -113,3 +113,43 @@ func TestCardDetailsResponse_SensitiveData(t *testing.T) {
 	assert.Equal(t, 12, resp.ExpiryMonth)
 	assert.Equal(t, 2027, resp.ExpiryYear)
 }
+
+func TestCard_PINState(t *testing.T) {
+	now := time.Now()
+	c := &Card{}
+	assert.False(t, c.HasPIN())
+	assert.False(t, c.PINLocked(now))
+
+	c.PINEncrypted = "encrypted"
+	assert.True(t, c.HasPIN())
+
+	future := now.Add(time.Hour)
+	c.PINLockedUntil = &future
+	assert.True(t, c.PINLocked(now))
+
+	past := now.Add(-time.Hour)
+	c.PINLockedUntil = &past
+	assert.False(t, c.PINLocked(now))
+}
+
+func TestCardStatus_CanTransitionTo(t *testing.T) {
+	assert.True(t, CardStatusActive.CanTransitionTo(CardStatusFrozen))
+	assert.True(t, CardStatusFrozen.CanTransitionTo(CardStatusActive))
+	assert.True(t, CardStatusActive.CanTransitionTo(CardStatusBlocked))
+	assert.True(t, CardStatusFrozen.CanTransitionTo(CardStatusBlocked))
+	assert.True(t, CardStatusBlocked.CanTransitionTo(CardStatusExpired))
+	assert.False(t, CardStatusBlocked.CanTransitionTo(CardStatusActive))
+	assert.False(t, CardStatusBlocked.CanTransitionTo(CardStatusFrozen))
+	assert.False(t, CardStatusExpired.CanTransitionTo(CardStatusActive))
+}
+
+func TestCard_IsExpired(t *testing.T) {
+	c := &Card{ExpiryMonth: 2, ExpiryYear: 2028}
+
+	assert.False(t, c.IsExpired(time.Date(2028, 2, 29, 23, 59, 0, 0, time.UTC)))
+	assert.True(t, c.IsExpired(time.Date(2028, 3, 1, 0, 0, 0, 0, time.UTC)))
+
+	c.ExpiryMonth = 12
+	assert.False(t, c.IsExpired(time.Date(2028, 12, 31, 12, 0, 0, 0, time.UTC)))
+	assert.True(t, c.IsExpired(time.Date(2029, 1, 1, 0, 0, 0, 0, time.UTC)))
+}
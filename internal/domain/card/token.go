@@ -0,0 +1,75 @@
+package card
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TokenStatus string
+type Wallet string
+
+const (
+	TokenStatusActive    TokenStatus = "active"
+	TokenStatusSuspended TokenStatus = "suspended" // temporarily unusable, e.g. device reported lost
+	TokenStatusDeleted   TokenStatus = "deleted"   // permanent, the card must be provisioned again
+
+	WalletGooglePay  Wallet = "google_pay"
+	WalletApplePay   Wallet = "apple_pay"
+	WalletSamsungPay Wallet = "samsung_pay"
+
+	// MaxTokensPerCard caps the live (active or suspended) tokens of a card
+	MaxTokensPerCard = 10
+)
+
+// tokenTransitions lists the statuses each token status may move to
+var tokenTransitions = map[TokenStatus][]TokenStatus{
+	TokenStatusActive:    {TokenStatusSuspended, TokenStatusDeleted},
+	TokenStatusSuspended: {TokenStatusActive, TokenStatusDeleted},
+}
+
+// CanTransitionTo reports whether the token status may change from s to next
+func (s TokenStatus) CanTransitionTo(next TokenStatus) bool {
+	for _, allowed := range tokenTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Token is a device-bound substitute card number issued to a wallet. Payments
+// made with it resolve to the underlying card without exposing the PAN.
+type Token struct {
+	ID                   uuid.UUID   `json:"id"`
+	CardID               uuid.UUID   `json:"card_id"`
+	TokenNumberEncrypted string      `json:"-"` // Never expose in JSON
+	TokenNumberHash      string      `json:"-"` // Keyed digest used to look the token up by number
+	LastFour             string      `json:"last_four"`
+	Wallet               Wallet      `json:"wallet"`
+	DeviceID             string      `json:"device_id"`
+	DeviceName           string      `json:"device_name,omitempty"`
+	Status               TokenStatus `json:"status"`
+	ExpiryMonth          int         `json:"expiry_month"`
+	ExpiryYear           int         `json:"expiry_year"`
+	CreatedAt            time.Time   `json:"created_at"`
+	UpdatedAt            time.Time   `json:"updated_at"`
+}
+
+// IsExpired reports whether the token is past its expiry month
+func (t *Token) IsExpired(now time.Time) bool {
+	return pastExpiry(t.ExpiryMonth, t.ExpiryYear, now)
+}
+
+type ProvisionTokenRequest struct {
+	Wallet     Wallet `json:"wallet" binding:"required,oneof=google_pay apple_pay samsung_pay"`
+	DeviceID   string `json:"device_id" binding:"required,max=128"`
+	DeviceName string `json:"device_name,omitempty" binding:"max=100"`
+}
+
+// ProvisionTokenResponse carries the token number to the wallet. It is the
+// only time the full token number is returned.
+type ProvisionTokenResponse struct {
+	Token       *Token `json:"token"`
+	TokenNumber string `json:"token_number"`
+}
@@ -0,0 +1,35 @@
+package card
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenStatus_CanTransitionTo(t *testing.T) {
+	assert.True(t, TokenStatusActive.CanTransitionTo(TokenStatusSuspended))
+	assert.True(t, TokenStatusSuspended.CanTransitionTo(TokenStatusActive))
+	assert.True(t, TokenStatusActive.CanTransitionTo(TokenStatusDeleted))
+	assert.True(t, TokenStatusSuspended.CanTransitionTo(TokenStatusDeleted))
+	assert.False(t, TokenStatusDeleted.CanTransitionTo(TokenStatusActive))
+	assert.False(t, TokenStatusActive.CanTransitionTo(TokenStatusActive))
+}
+
+func TestToken_NumberHidden(t *testing.T) {
+	tok := Token{TokenNumberEncrypted: "encrypted", TokenNumberHash: "hash", LastFour: "4242"}
+
+	data, err := json.Marshal(tok)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "encrypted")
+	assert.NotContains(t, string(data), "hash")
+	assert.Contains(t, string(data), "4242")
+}
+
+func TestToken_IsExpired(t *testing.T) {
+	tok := &Token{ExpiryMonth: 6, ExpiryYear: 2029}
+
+	assert.False(t, tok.IsExpired(time.Date(2029, 6, 30, 23, 0, 0, 0, time.UTC)))
+	assert.True(t, tok.IsExpired(time.Date(2029, 7, 1, 0, 0, 0, 0, time.UTC)))
+}
@@ -0,0 +1,107 @@
+// Package currency is the registry of the currencies accounts can be held
+// in, with the ISO 4217 minor units that decide how amounts in each are
+// rounded, validated and shown.
+package currency
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Currency is a supported ISO 4217 currency
+type Currency struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+	// MinorUnits is the number of decimal places amounts are quoted in:
+	// 2 for USD cents, 0 for JPY
+	MinorUnits int `json:"minor_units"`
+}
+
+// Default is the currency of the bank's books, which amounts without a
+// currency of their own are in
+const Default = "IDR"
+
+// registry holds the supported currencies by code. Adding one here makes it
+// available for new accounts and FX quotes.
+var registry = map[string]Currency{
+	"AUD": {Code: "AUD", Name: "Australian Dollar", MinorUnits: 2},
+	"CHF": {Code: "CHF", Name: "Swiss Franc", MinorUnits: 2},
+	"CNY": {Code: "CNY", Name: "Chinese Yuan", MinorUnits: 2},
+	"EUR": {Code: "EUR", Name: "Euro", MinorUnits: 2},
+	"GBP": {Code: "GBP", Name: "Pound Sterling", MinorUnits: 2},
+	"HKD": {Code: "HKD", Name: "Hong Kong Dollar", MinorUnits: 2},
+	"IDR": {Code: "IDR", Name: "Indonesian Rupiah", MinorUnits: 2},
+	"JPY": {Code: "JPY", Name: "Japanese Yen", MinorUnits: 0},
+	"KRW": {Code: "KRW", Name: "South Korean Won", MinorUnits: 0},
+	"MYR": {Code: "MYR", Name: "Malaysian Ringgit", MinorUnits: 2},
+	"SAR": {Code: "SAR", Name: "Saudi Riyal", MinorUnits: 2},
+	"SGD": {Code: "SGD", Name: "Singapore Dollar", MinorUnits: 2},
+	"THB": {Code: "THB", Name: "Thai Baht", MinorUnits: 2},
+	"USD": {Code: "USD", Name: "US Dollar", MinorUnits: 2},
+}
+
+// Lookup returns the supported currency with the upper-case code
+func Lookup(code string) (Currency, error) {
+	c, ok := registry[code]
+	if !ok {
+		return Currency{}, fmt.Errorf("unsupported currency: %q", code)
+	}
+	return c, nil
+}
+
+// IsSupported reports whether accounts can be held in the currency
+func IsSupported(code string) bool {
+	_, ok := registry[code]
+	return ok
+}
+
+// Supported lists the supported currencies by code
+func Supported() []Currency {
+	list := make([]Currency, 0, len(registry))
+	for _, c := range registry {
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+	return list
+}
+
+// MinorUnits returns the currency's decimal places, or 2 when the currency
+// is not supported
+func MinorUnits(code string) int {
+	if c, ok := registry[code]; ok {
+		return c.MinorUnits
+	}
+	return 2
+}
+
+// Round rounds an amount to the currency's minor units, half away from zero
+func (c Currency) Round(amount float64) float64 {
+	scale := math.Pow10(c.MinorUnits)
+	return math.Round(amount*scale) / scale
+}
+
+// Format shows an amount with the code, thousands separators and the
+// currency's decimal places, e.g. IDR 1,500,000.00 or JPY 1,500
+func (c Currency) Format(amount float64) string {
+	return Format(c.Code, amount)
+}
+
+// Format is Currency.Format by code; unsupported codes get 2 decimal places
+func Format(code string, amount float64) string {
+	s := strconv.FormatFloat(amount, 'f', MinorUnits(code), 64)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	whole, frac, _ := strings.Cut(s, ".")
+	if frac != "" {
+		frac = "." + frac
+	}
+	for i := len(whole) - 3; i > 0; i -= 3 {
+		whole = whole[:i] + "," + whole[i:]
+	}
+	return fmt.Sprintf("%s %s%s%s", code, sign, whole, frac)
+}
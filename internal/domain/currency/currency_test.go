@@ -0,0 +1,54 @@
+package currency
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookup(t *testing.T) {
+	idr, err := Lookup("IDR")
+	require.NoError(t, err)
+	assert.Equal(t, Currency{Code: "IDR", Name: "Indonesian Rupiah", MinorUnits: 2}, idr)
+
+	_, err = Lookup("idr")
+	assert.EqualError(t, err, `unsupported currency: "idr"`)
+	_, err = Lookup("XAU")
+	assert.Error(t, err)
+}
+
+func TestSupported(t *testing.T) {
+	list := Supported()
+	assert.Len(t, list, len(registry))
+	for i := 1; i < len(list); i++ {
+		assert.Less(t, list[i-1].Code, list[i].Code)
+	}
+	assert.True(t, IsSupported(Default))
+}
+
+func TestMinorUnits(t *testing.T) {
+	assert.Equal(t, 2, MinorUnits("USD"))
+	assert.Equal(t, 0, MinorUnits("JPY"))
+	assert.Equal(t, 2, MinorUnits("???"))
+}
+
+func TestRound(t *testing.T) {
+	usd, _ := Lookup("USD")
+	jpy, _ := Lookup("JPY")
+	assert.Equal(t, 10.13, usd.Round(10.125))
+	assert.Equal(t, -10.13, usd.Round(-10.125))
+	assert.Equal(t, 1501.0, jpy.Round(1500.5))
+}
+
+func TestFormat(t *testing.T) {
+	assert.Equal(t, "IDR 0.50", Format("IDR", 0.5))
+	assert.Equal(t, "IDR 1,500,000.00", Format("IDR", 1500000))
+	assert.Equal(t, "USD -12,345,678.90", Format("USD", -12345678.9))
+	assert.Equal(t, "JPY 1,500", Format("JPY", 1500))
+	assert.Equal(t, "KRW 999", Format("KRW", 999))
+	assert.Equal(t, "XYZ 1,000.00", Format("XYZ", 1000))
+
+	jpy, _ := Lookup("JPY")
+	assert.Equal(t, "JPY 12,000", jpy.Format(12000))
+}
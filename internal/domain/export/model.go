@@ -0,0 +1,156 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/google/uuid"
+)
+
+type Kind string
+type Status string
+
+const (
+	// KindStatements renders a PDF statement of the period for every account
+	KindStatements Kind = "statements"
+	// KindTransactions writes every transaction created in the period to one CSV file
+	KindTransactions Kind = "transactions"
+
+	StatusPending   Status = "pending"
+	StatusRunning   Status = "running"
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+)
+
+const (
+	// JobKind is the job queue kind that runs exports
+	JobKind = "bulk_export"
+
+	// MaxPeriodDays bounds the period of one export
+	MaxPeriodDays = 366
+
+	// DateLayout is the format of export period dates
+	DateLayout = "2006-01-02"
+)
+
+// Export is an admin-requested export of all statements or all transactions
+// over a period of whole days, written to object storage in the background.
+// Total and Processed count accounts for statements and transactions for
+// transactions, so Progress can be shown while it runs.
+type Export struct {
+	ID          uuid.UUID  `json:"id"`
+	Kind        Kind       `json:"kind"`
+	PeriodStart time.Time  `json:"period_start"`
+	PeriodEnd   time.Time  `json:"period_end"` // inclusive
+	Status      Status     `json:"status"`
+	Total       int        `json:"total"`
+	Processed   int        `json:"processed"`
+	Location    string     `json:"location,omitempty"` // object key or prefix, once completed
+	Error       string     `json:"error,omitempty"`
+	RequestedBy uuid.UUID  `json:"requested_by"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// Progress is the percentage of the export done, 100 once completed
+func (e *Export) Progress() float64 {
+	if e.Status == StatusCompleted {
+		return 100
+	}
+	if e.Total == 0 {
+		return 0
+	}
+	return float64(e.Processed) * 100 / float64(e.Total)
+}
+
+// Finished reports whether the export has completed or failed
+func (e *Export) Finished() bool {
+	return e.Status == StatusCompleted || e.Status == StatusFailed
+}
+
+// Prefix is where the export's objects are written
+func (e *Export) Prefix() string {
+	return "exports/" + e.ID.String() + "/"
+}
+
+// Label names the export's period, e.g. 2024-01-01_2024-03-31
+func (e *Export) Label() string {
+	return e.PeriodStart.Format(DateLayout) + "_" + e.PeriodEnd.Format(DateLayout)
+}
+
+type CreateExportRequest struct {
+	Kind Kind   `json:"kind" binding:"required,oneof=statements transactions"`
+	From string `json:"from" binding:"required"`
+	To   string `json:"to" binding:"required"`
+}
+
+// JobPayload is the queued job's payload
+type JobPayload struct {
+	ExportID uuid.UUID `json:"export_id"`
+}
+
+// ExportResponse is an export with its progress
+type ExportResponse struct {
+	*Export
+	Progress float64 `json:"progress"` // percent
+}
+
+func ToResponse(e *Export) ExportResponse {
+	return ExportResponse{Export: e, Progress: e.Progress()}
+}
+
+var transactionsHeader = []string{
+	"transaction_id", "created_at", "completed_at", "transaction_type", "status",
+	"amount", "currency", "from_account_id", "to_account_id", "description",
+}
+
+// TransactionWriter writes transactions as CSV rows
+type TransactionWriter struct {
+	csv *csv.Writer
+}
+
+// NewTransactionWriter writes the header row to w
+func NewTransactionWriter(w io.Writer) (*TransactionWriter, error) {
+	tw := &TransactionWriter{csv: csv.NewWriter(w)}
+	if err := tw.csv.Write(transactionsHeader); err != nil {
+		return nil, err
+	}
+	return tw, nil
+}
+
+func (tw *TransactionWriter) Write(txn *transaction.Transaction) error {
+	completedAt := ""
+	if txn.CompletedAt != nil {
+		completedAt = txn.CompletedAt.UTC().Format(time.RFC3339)
+	}
+	return tw.csv.Write([]string{
+		txn.ID.String(),
+		txn.CreatedAt.UTC().Format(time.RFC3339),
+		completedAt,
+		string(txn.TransactionType),
+		string(txn.Status),
+		strconv.FormatFloat(txn.Amount, 'f', 2, 64),
+		txn.Currency(),
+		optionalID(txn.FromAccountID),
+		optionalID(txn.ToAccountID),
+		txn.Description,
+	})
+}
+
+// Close flushes the rows written
+func (tw *TransactionWriter) Close() error {
+	tw.csv.Flush()
+	return tw.csv.Error()
+}
+
+func optionalID(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
@@ -0,0 +1,55 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExport_Progress(t *testing.T) {
+	assert.Equal(t, 0.0, (&Export{Status: StatusPending}).Progress())
+	assert.Equal(t, 40.0, (&Export{Status: StatusRunning, Total: 250, Processed: 100}).Progress())
+	// An empty period has nothing to process
+	assert.Equal(t, 100.0, (&Export{Status: StatusCompleted}).Progress())
+}
+
+func TestExport_Label(t *testing.T) {
+	e := &Export{
+		PeriodStart: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:   time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC),
+	}
+	assert.Equal(t, "2024-01-01_2024-03-31", e.Label())
+}
+
+func TestTransactionWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewTransactionWriter(&buf)
+	assert.NoError(t, err)
+
+	from, to := uuid.New(), uuid.New()
+	completed := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	txn := &transaction.Transaction{
+		ID:              uuid.New(),
+		FromAccountID:   &from,
+		ToAccountID:     &to,
+		Amount:          1250.5,
+		TransactionType: transaction.TransactionTypeTransfer,
+		Status:          transaction.TransactionStatusCompleted,
+		Description:     "Rent, January",
+		Metadata:        map[string]interface{}{"currency": "USD"},
+		CreatedAt:       completed,
+		CompletedAt:     &completed,
+	}
+	assert.NoError(t, w.Write(txn))
+	assert.NoError(t, w.Close())
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, "transaction_id,created_at,completed_at,transaction_type,status,amount,currency,from_account_id,to_account_id,description", lines[0])
+	assert.Equal(t, txn.ID.String()+",2024-01-02T03:04:05Z,2024-01-02T03:04:05Z,transfer,completed,1250.50,USD,"+
+		from.String()+","+to.String()+`,"Rent, January"`, lines[1])
+}
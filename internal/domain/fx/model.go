@@ -0,0 +1,40 @@
+// Package fx quotes indicative exchange rates between the supported
+// currencies, for showing a conversion before an FX transfer
+package fx
+
+import (
+	"errors"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/currency"
+	"github.com/darisadam/madabank-server/internal/domain/validation"
+)
+
+// ErrRatesUnavailable means the rate provider is down and the cached rates
+// are too old to quote
+var ErrRatesUnavailable = errors.New("exchange rates are temporarily unavailable")
+
+type RatesRequest struct {
+	// Base is the currency the rates are quoted against, by default IDR
+	Base string `form:"base"`
+}
+
+// Validate checks the base currency; see package validation
+func (r *RatesRequest) Validate() error {
+	if r.Base == "" {
+		r.Base = currency.Default
+	}
+	return validation.New().Currency("base", r.Base).Err()
+}
+
+// RatesResponse is how many units of each supported currency one unit of
+// Base buys. The rates are mid-market and indicative only: an FX transfer is
+// priced when it is made.
+type RatesResponse struct {
+	Base  string             `json:"base"`
+	Rates map[string]float64 `json:"rates"`
+	AsOf  time.Time          `json:"as_of"`
+	// Stale is set while the provider cannot be reached and the last rates
+	// fetched are quoted instead
+	Stale bool `json:"stale"`
+}
@@ -0,0 +1,270 @@
+package gl
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/google/uuid"
+)
+
+// Code is a general ledger account in the finance team's chart of accounts
+type Code string
+
+const (
+	CodeCash               Code = "1000"
+	CodeCardReceivables    Code = "1200"
+	CodeLoansReceivable    Code = "1300"
+	CodeCustomerDeposits   Code = "2100"
+	CodeMerchantPayables   Code = "2300" // customer payments held until merchant settlement
+	CodeBillerClearing     Code = "2400" // owed to the biller aggregator
+	CodeTopupClearing      Code = "2500" // owed to the top-up aggregator
+	CodeFeeIncome          Code = "4200"
+	CodeMDRIncome          Code = "4300"
+	CodeLoanInterestIncome Code = "4400"
+	CodeProvisionFeeIncome Code = "4500"
+	CodeInterestExpense    Code = "5100"
+	// CodeSuspense holds postings for transaction types without a mapping, so
+	// the export still balances and finance can reclassify them
+	CodeSuspense Code = "9999"
+)
+
+var codeNames = map[Code]string{
+	CodeCash:               "Cash and Settlement Accounts",
+	CodeCardReceivables:    "Credit Card Receivables",
+	CodeLoansReceivable:    "Loans Receivable",
+	CodeCustomerDeposits:   "Customer Deposits",
+	CodeMerchantPayables:   "Merchant Payables",
+	CodeBillerClearing:     "Biller Aggregator Clearing",
+	CodeTopupClearing:      "Top-up Aggregator Clearing",
+	CodeFeeIncome:          "Fee Income",
+	CodeMDRIncome:          "Merchant Discount Income",
+	CodeLoanInterestIncome: "Loan Interest Income",
+	CodeProvisionFeeIncome: "Loan Provision Fee Income",
+	CodeInterestExpense:    "Interest Expense",
+	CodeSuspense:           "Suspense",
+}
+
+func (c Code) Name() string {
+	return codeNames[c]
+}
+
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+
+	// DateLayout is the format of the export date
+	DateLayout = "2006-01-02"
+)
+
+// Line is one debit or credit of a journal entry. AccountID identifies the
+// customer sub-ledger account for postings to customer deposits.
+type Line struct {
+	GLCode    Code       `json:"gl_code"`
+	GLName    string     `json:"gl_name"`
+	AccountID *uuid.UUID `json:"account_id,omitempty"`
+	Debit     float64    `json:"debit"`
+	Credit    float64    `json:"credit"`
+}
+
+// Entry is the balanced journal entry for one transaction
+type Entry struct {
+	JournalID       string                      `json:"journal_id"`
+	TransactionID   uuid.UUID                   `json:"transaction_id"`
+	TransactionType transaction.TransactionType `json:"transaction_type"`
+	PostedAt        time.Time                   `json:"posted_at"`
+	Description     string                      `json:"description,omitempty"`
+	Lines           []Line                      `json:"lines"`
+}
+
+// Journal is a day's journal entries
+type Journal struct {
+	Date        string    `json:"date"`
+	Currency    string    `json:"currency"`
+	GeneratedAt time.Time `json:"generated_at"`
+	Entries     []*Entry  `json:"entries"`
+	TotalDebit  float64   `json:"total_debit"`
+	TotalCredit float64   `json:"total_credit"`
+}
+
+// Balanced reports whether debits equal credits
+func (e *Entry) Balanced() bool {
+	var debit, credit float64
+	for _, l := range e.Lines {
+		debit += l.Debit
+		credit += l.Credit
+	}
+	return math.Abs(debit-credit) < 0.005
+}
+
+// BuildJournal turns a day's completed transactions into journal entries,
+// numbered in posting order
+func BuildJournal(date time.Time, currency string, txns []*transaction.Transaction, generatedAt time.Time) *Journal {
+	j := &Journal{
+		Date:        date.Format(DateLayout),
+		Currency:    currency,
+		GeneratedAt: generatedAt,
+		Entries:     make([]*Entry, 0, len(txns)),
+	}
+
+	for i, txn := range txns {
+		e := EntryFor(txn)
+		e.JournalID = fmt.Sprintf("JE-%s-%06d", date.Format("20060102"), i+1)
+		for _, l := range e.Lines {
+			j.TotalDebit += l.Debit
+			j.TotalCredit += l.Credit
+		}
+		j.Entries = append(j.Entries, e)
+	}
+	j.TotalDebit = roundAmount(j.TotalDebit)
+	j.TotalCredit = roundAmount(j.TotalCredit)
+
+	return j
+}
+
+// EntryFor maps a completed transaction to its journal entry
+func EntryFor(txn *transaction.Transaction) *Entry {
+	e := &Entry{
+		TransactionID:   txn.ID,
+		TransactionType: txn.TransactionType,
+		PostedAt:        txn.CreatedAt,
+		Description:     txn.Description,
+	}
+	if txn.CompletedAt != nil {
+		e.PostedAt = *txn.CompletedAt
+	}
+
+	amount := txn.Amount
+	from, to := txn.FromAccountID, txn.ToAccountID
+
+	switch txn.TransactionType {
+	case transaction.TransactionTypeTransfer, transaction.TransactionTypeRoundUp:
+		e.debit(CodeCustomerDeposits, from, amount)
+		e.credit(CodeCustomerDeposits, to, amount)
+	case transaction.TransactionTypeDeposit:
+		e.debit(CodeCash, nil, amount)
+		e.credit(CodeCustomerDeposits, to, amount)
+	case transaction.TransactionTypeWithdrawal:
+		e.debit(CodeCustomerDeposits, from, amount)
+		e.credit(CodeCash, nil, amount)
+	case transaction.TransactionTypeInterest:
+		e.debit(CodeInterestExpense, nil, amount)
+		e.credit(CodeCustomerDeposits, to, amount)
+	case transaction.TransactionTypeFee:
+		e.debit(CodeCustomerDeposits, from, amount)
+		e.credit(CodeFeeIncome, nil, amount)
+	case transaction.TransactionTypeCardRepayment:
+		e.debit(CodeCustomerDeposits, from, amount)
+		e.credit(CodeCardReceivables, nil, amount)
+	case transaction.TransactionTypeBillPayment:
+		e.debit(CodeCustomerDeposits, from, amount)
+		e.credit(CodeBillerClearing, nil, amount)
+	case transaction.TransactionTypeTopup:
+		// The debit includes our admin fee on top of the amount owed to the aggregator
+		fee := metadataAmount(txn, "admin_fee")
+		e.debit(CodeCustomerDeposits, from, amount)
+		e.credit(CodeTopupClearing, nil, amount-fee)
+		e.credit(CodeFeeIncome, nil, fee)
+	case transaction.TransactionTypeMerchantPayment:
+		e.debit(CodeCustomerDeposits, from, amount)
+		e.credit(CodeMerchantPayables, nil, amount)
+	case transaction.TransactionTypeMerchantSettlement:
+		// The payout is net of the merchant discount, which the bank keeps
+		fee := metadataAmount(txn, "fee_amount")
+		e.debit(CodeMerchantPayables, nil, amount+fee)
+		e.credit(CodeCustomerDeposits, to, amount)
+		e.credit(CodeMDRIncome, nil, fee)
+	case transaction.TransactionTypeLoanDisbursement:
+		// The payout is net of the provision fee
+		fee := metadataAmount(txn, "provision_fee")
+		e.debit(CodeLoansReceivable, nil, amount+fee)
+		e.credit(CodeCustomerDeposits, to, amount)
+		e.credit(CodeProvisionFeeIncome, nil, fee)
+	case transaction.TransactionTypeLoanRepayment:
+		interest := metadataAmount(txn, "interest")
+		e.debit(CodeCustomerDeposits, from, amount)
+		e.credit(CodeLoansReceivable, nil, amount-interest)
+		e.credit(CodeLoanInterestIncome, nil, interest)
+	default:
+		e.debit(CodeSuspense, from, amount)
+		e.credit(CodeSuspense, to, amount)
+	}
+
+	return e
+}
+
+func (e *Entry) debit(code Code, accountID *uuid.UUID, amount float64) {
+	if amount == 0 {
+		return
+	}
+	e.Lines = append(e.Lines, Line{GLCode: code, GLName: code.Name(), AccountID: accountID, Debit: roundAmount(amount)})
+}
+
+func (e *Entry) credit(code Code, accountID *uuid.UUID, amount float64) {
+	if amount == 0 {
+		return
+	}
+	e.Lines = append(e.Lines, Line{GLCode: code, GLName: code.Name(), AccountID: accountID, Credit: roundAmount(amount)})
+}
+
+// metadataAmount reads an amount recorded in the transaction metadata, or 0
+func metadataAmount(txn *transaction.Transaction, key string) float64 {
+	switch v := txn.Metadata[key].(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	}
+	return 0
+}
+
+func roundAmount(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+var csvHeader = []string{
+	"journal_id", "posting_date", "posted_at", "gl_code", "gl_name", "account_id",
+	"debit", "credit", "currency", "transaction_id", "transaction_type", "description",
+}
+
+// WriteCSV writes the journal with one row per line, the layout most ERP
+// journal imports accept
+func (j *Journal) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, e := range j.Entries {
+		for _, l := range e.Lines {
+			accountID := ""
+			if l.AccountID != nil {
+				accountID = l.AccountID.String()
+			}
+			row := []string{
+				e.JournalID, j.Date, e.PostedAt.UTC().Format(time.RFC3339), string(l.GLCode), l.GLName, accountID,
+				formatAmount(l.Debit), formatAmount(l.Credit), j.Currency,
+				e.TransactionID.String(), string(e.TransactionType), e.Description,
+			}
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatAmount(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+type ExportRequest struct {
+	Date   string `form:"date" binding:"required"`
+	Format string `form:"format" binding:"omitempty,oneof=json csv"`
+}
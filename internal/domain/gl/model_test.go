@@ -0,0 +1,107 @@
+package gl
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntryFor_AllTypesBalance(t *testing.T) {
+	from, to := uuid.New(), uuid.New()
+	types := []transaction.TransactionType{
+		transaction.TransactionTypeTransfer,
+		transaction.TransactionTypeDeposit,
+		transaction.TransactionTypeWithdrawal,
+		transaction.TransactionTypeInterest,
+		transaction.TransactionTypeFee,
+		transaction.TransactionTypeCardRepayment,
+		transaction.TransactionTypeBillPayment,
+		transaction.TransactionTypeTopup,
+		transaction.TransactionTypeMerchantPayment,
+		transaction.TransactionTypeMerchantSettlement,
+		transaction.TransactionTypeLoanDisbursement,
+		transaction.TransactionTypeLoanRepayment,
+		transaction.TransactionTypeRoundUp,
+		"unknown",
+	}
+
+	for _, txnType := range types {
+		e := EntryFor(&transaction.Transaction{
+			ID: uuid.New(), FromAccountID: &from, ToAccountID: &to, Amount: 100000, TransactionType: txnType,
+			Metadata: map[string]interface{}{"admin_fee": 2500.0, "fee_amount": 700.0, "provision_fee": 1000.0, "interest": 1400.0},
+		})
+		assert.True(t, e.Balanced(), txnType)
+		assert.NotEmpty(t, e.Lines, txnType)
+	}
+}
+
+func TestEntryFor_LoanRepaymentSplitsInterest(t *testing.T) {
+	from := uuid.New()
+	e := EntryFor(&transaction.Transaction{
+		ID: uuid.New(), FromAccountID: &from, Amount: 1066185.47,
+		TransactionType: transaction.TransactionTypeLoanRepayment,
+		Metadata:        map[string]interface{}{"principal": 946185.47, "interest": 120000.0},
+	})
+
+	assert.Equal(t, []Line{
+		{GLCode: CodeCustomerDeposits, GLName: "Customer Deposits", AccountID: &from, Debit: 1066185.47},
+		{GLCode: CodeLoansReceivable, GLName: "Loans Receivable", Credit: 946185.47},
+		{GLCode: CodeLoanInterestIncome, GLName: "Loan Interest Income", Credit: 120000},
+	}, e.Lines)
+}
+
+func TestEntryFor_MerchantSettlementGrossesUpFee(t *testing.T) {
+	to := uuid.New()
+	e := EntryFor(&transaction.Transaction{
+		ID: uuid.New(), ToAccountID: &to, Amount: 99300,
+		TransactionType: transaction.TransactionTypeMerchantSettlement,
+		Metadata:        map[string]interface{}{"gross_amount": 100000.0, "fee_amount": 700.0},
+	})
+
+	assert.Equal(t, CodeMerchantPayables, e.Lines[0].GLCode)
+	assert.Equal(t, 100000.0, e.Lines[0].Debit)
+	assert.Equal(t, 700.0, e.Lines[2].Credit)
+}
+
+func TestBuildJournal(t *testing.T) {
+	date := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	completed := date.Add(9 * time.Hour)
+	to := uuid.New()
+	txns := []*transaction.Transaction{
+		{ID: uuid.New(), ToAccountID: &to, Amount: 500000, TransactionType: transaction.TransactionTypeDeposit, CompletedAt: &completed},
+		{ID: uuid.New(), ToAccountID: &to, Amount: 1250.5, TransactionType: transaction.TransactionTypeInterest, CompletedAt: &completed},
+	}
+
+	j := BuildJournal(date, "IDR", txns, time.Now())
+
+	assert.Equal(t, "2026-03-01", j.Date)
+	assert.Equal(t, "JE-20260301-000001", j.Entries[0].JournalID)
+	assert.Equal(t, "JE-20260301-000002", j.Entries[1].JournalID)
+	assert.Equal(t, 501250.5, j.TotalDebit)
+	assert.Equal(t, j.TotalDebit, j.TotalCredit)
+	assert.Equal(t, completed, j.Entries[0].PostedAt)
+}
+
+func TestJournal_WriteCSV(t *testing.T) {
+	date := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := uuid.New()
+	j := BuildJournal(date, "IDR", []*transaction.Transaction{
+		{ID: uuid.New(), ToAccountID: &to, Amount: 500000, TransactionType: transaction.TransactionTypeDeposit, Description: "Cash, deposit"},
+	}, time.Now())
+
+	var buf bytes.Buffer
+	assert.NoError(t, j.WriteCSV(&buf))
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, rows, 3)
+	assert.Equal(t, csvHeader, rows[0])
+	assert.Equal(t, []string{"1000", "500000.00", "0.00"}, []string{rows[1][3], rows[1][6], rows[1][7]})
+	assert.Equal(t, []string{"2100", to.String(), "0.00", "500000.00"}, []string{rows[2][3], rows[2][5], rows[2][6], rows[2][7]})
+	assert.Equal(t, "Cash, deposit", rows[2][11])
+}
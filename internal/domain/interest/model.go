@@ -0,0 +1,120 @@
+package interest
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DaysInYear is the day count convention for savings interest (actual/365)
+	DaysInYear = 365
+
+	// DateLayout is the format of accrual dates
+	DateLayout = "2006-01-02"
+)
+
+// Balance is a savings account's balance and annual rate at accrual time
+type Balance struct {
+	AccountID   uuid.UUID
+	AccountType string
+	Currency    string
+	Balance     float64
+	AnnualRate  float64
+}
+
+// Tier is a higher annual rate a product (account type and currency) pays on
+// the part of a balance above MinBalance, up to the next tier. Below the
+// first tier the account's own rate applies.
+type Tier struct {
+	AccountType string  `json:"-"`
+	Currency    string  `json:"-"`
+	MinBalance  float64 `json:"min_balance"`
+	AnnualRate  float64 `json:"annual_rate"`
+}
+
+// Tiers are the rate tiers of every product
+type Tiers []Tier
+
+// For returns the tiers of one product, lowest first
+func (t Tiers) For(accountType, currency string) []Tier {
+	tiers := []Tier{}
+	for _, tier := range t {
+		if tier.AccountType == accountType && tier.Currency == currency {
+			tiers = append(tiers, tier)
+		}
+	}
+	sort.Slice(tiers, func(i, j int) bool { return tiers[i].MinBalance < tiers[j].MinBalance })
+	return tiers
+}
+
+// EffectiveRate is the single annual rate balance earns when baseRate applies
+// below the first tier and each tier's rate to its own band. It keeps six
+// decimal places; an empty balance earns baseRate.
+func EffectiveRate(balance, baseRate float64, tiers []Tier) float64 {
+	if balance <= 0 {
+		return baseRate
+	}
+
+	var earned, from float64
+	rate := baseRate
+	for _, tier := range tiers {
+		if balance <= tier.MinBalance {
+			break
+		}
+		earned += (tier.MinBalance - from) * rate
+		from, rate = tier.MinBalance, tier.AnnualRate
+	}
+	earned += (balance - from) * rate
+
+	return math.Round(earned/balance*1e6) / 1e6
+}
+
+// Accrual is one day's interest earned by a savings account. Amounts keep six
+// decimal places and are only rounded to cents when posted.
+type Accrual struct {
+	ID                  uuid.UUID  `json:"id"`
+	AccountID           uuid.UUID  `json:"account_id"`
+	AccrualDate         time.Time  `json:"accrual_date"`
+	Balance             float64    `json:"balance"`
+	AnnualRate          float64    `json:"annual_rate"`
+	Amount              float64    `json:"amount"`
+	PostedTransactionID *uuid.UUID `json:"posted_transaction_id,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+}
+
+// DailyAmount is the interest earned by balance over one day at annualRate
+func DailyAmount(balance, annualRate float64) float64 {
+	if balance <= 0 || annualRate <= 0 {
+		return 0
+	}
+	return math.Round(balance*annualRate/DaysInYear*1e6) / 1e6
+}
+
+// PostingAmount rounds accrued interest to the cents credited to the account
+func PostingAmount(accrued float64) float64 {
+	return math.Round(accrued*100) / 100
+}
+
+// Accrue builds the day's accruals for the given balances, skipping accounts
+// that earn nothing
+func Accrue(day time.Time, balances []*Balance) []*Accrual {
+	accruals := []*Accrual{}
+	for _, b := range balances {
+		amount := DailyAmount(b.Balance, b.AnnualRate)
+		if amount == 0 {
+			continue
+		}
+		accruals = append(accruals, &Accrual{
+			ID:          uuid.New(),
+			AccountID:   b.AccountID,
+			AccrualDate: day,
+			Balance:     b.Balance,
+			AnnualRate:  b.AnnualRate,
+			Amount:      amount,
+		})
+	}
+	return accruals
+}
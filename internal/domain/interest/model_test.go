@@ -0,0 +1,63 @@
+package interest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDailyAmount(t *testing.T) {
+	assert.Equal(t, 890.410959, DailyAmount(10000000, 0.0325))
+	assert.Equal(t, 0.0, DailyAmount(-500, 0.0325))
+	assert.Equal(t, 0.0, DailyAmount(10000000, 0))
+}
+
+func TestEffectiveRate(t *testing.T) {
+	tiers := []Tier{
+		{MinBalance: 10000000, AnnualRate: 0.04},
+		{MinBalance: 100000000, AnnualRate: 0.045},
+	}
+
+	assert.Equal(t, 0.0325, EffectiveRate(5000000, 0.0325, tiers))
+	assert.Equal(t, 0.0325, EffectiveRate(10000000, 0.0325, tiers))
+	// 10M at 3.25% and 10M at 4%
+	assert.Equal(t, 0.03625, EffectiveRate(20000000, 0.0325, tiers))
+	// 10M at 3.25%, 90M at 4% and 100M at 4.5%
+	assert.Equal(t, 0.042125, EffectiveRate(200000000, 0.0325, tiers))
+	assert.Equal(t, 0.0325, EffectiveRate(0, 0.0325, tiers))
+	assert.Equal(t, 0.0325, EffectiveRate(20000000, 0.0325, nil))
+}
+
+func TestTiers_For(t *testing.T) {
+	tiers := Tiers{
+		{AccountType: "savings", Currency: "IDR", MinBalance: 100000000, AnnualRate: 0.045},
+		{AccountType: "savings", Currency: "USD", MinBalance: 10000, AnnualRate: 0.02},
+		{AccountType: "savings", Currency: "IDR", MinBalance: 10000000, AnnualRate: 0.04},
+	}
+
+	idr := tiers.For("savings", "IDR")
+	assert.Len(t, idr, 2)
+	assert.Equal(t, 10000000.0, idr[0].MinBalance)
+	assert.Empty(t, tiers.For("checking", "IDR"))
+}
+
+func TestPostingAmount(t *testing.T) {
+	// 31 days of 890.410959
+	assert.Equal(t, 27602.74, PostingAmount(27602.739729))
+	assert.Equal(t, 0.0, PostingAmount(0.004))
+}
+
+func TestAccrue_SkipsAccountsEarningNothing(t *testing.T) {
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	earning := &Balance{AccountID: uuid.New(), Balance: 5000000, AnnualRate: 0.0325}
+	empty := &Balance{AccountID: uuid.New(), Balance: 0, AnnualRate: 0.0325}
+
+	accruals := Accrue(day, []*Balance{earning, empty})
+
+	assert.Len(t, accruals, 1)
+	assert.Equal(t, earning.AccountID, accruals[0].AccountID)
+	assert.Equal(t, day, accruals[0].AccrualDate)
+	assert.Equal(t, 445.205479, accruals[0].Amount)
+}
@@ -0,0 +1,82 @@
+package interest
+
+import (
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/google/uuid"
+)
+
+// WithholdingTaxRate is the final income tax on savings interest
+const WithholdingTaxRate = 0.20
+
+type SummaryRequest struct {
+	// Year defaults to the current year
+	Year int `form:"year" binding:"omitempty,min=2000,max=9999"`
+}
+
+// MonthSummary is one calendar month of an account's interest. Accrued is
+// interest earned on the month's days; Posted is interest credited during
+// the month, normally the previous month's accruals.
+type MonthSummary struct {
+	Month          string  `json:"month"` // e.g. 2026-03
+	Accrued        float64 `json:"accrued"`
+	Posted         float64 `json:"posted"`
+	WithholdingTax float64 `json:"withholding_tax"`
+}
+
+// Summary is a year of an account's interest, for the holder's tax filing
+type Summary struct {
+	AccountID           uuid.UUID      `json:"account_id"`
+	Year                int            `json:"year"`
+	Currency            string         `json:"currency"`
+	Months              []MonthSummary `json:"months"`
+	TotalAccrued        float64        `json:"total_accrued"`
+	TotalPosted         float64        `json:"total_posted"`
+	TotalWithholdingTax float64        `json:"total_withholding_tax"`
+}
+
+// Summarize totals a year of accruals by accrual date and interest postings
+// by when they were credited in zone. Tax is withheld on each posting.
+func Summarize(accountID uuid.UUID, currency string, year int, zone *time.Location, accruals []*Accrual, postings []*transaction.Transaction) *Summary {
+	accrued := make([]float64, 12)
+	posted := make([]float64, 12)
+	tax := make([]float64, 12)
+
+	for _, a := range accruals {
+		if a.AccrualDate.Year() == year {
+			accrued[a.AccrualDate.Month()-1] += a.Amount
+		}
+	}
+	for _, p := range postings {
+		at := p.CreatedAt
+		if p.CompletedAt != nil {
+			at = *p.CompletedAt
+		}
+		at = at.In(zone)
+		if at.Year() != year {
+			continue
+		}
+		posted[at.Month()-1] += p.Amount
+		tax[at.Month()-1] += PostingAmount(p.Amount * WithholdingTaxRate)
+	}
+
+	s := &Summary{AccountID: accountID, Year: year, Currency: currency, Months: []MonthSummary{}}
+	for m := 0; m < 12; m++ {
+		month := MonthSummary{
+			Month:          time.Date(year, time.Month(m+1), 1, 0, 0, 0, 0, zone).Format("2006-01"),
+			Accrued:        PostingAmount(accrued[m]),
+			Posted:         PostingAmount(posted[m]),
+			WithholdingTax: PostingAmount(tax[m]),
+		}
+		s.Months = append(s.Months, month)
+		s.TotalAccrued += accrued[m]
+		s.TotalPosted += month.Posted
+		s.TotalWithholdingTax += month.WithholdingTax
+	}
+	s.TotalAccrued = PostingAmount(s.TotalAccrued)
+	s.TotalPosted = PostingAmount(s.TotalPosted)
+	s.TotalWithholdingTax = PostingAmount(s.TotalWithholdingTax)
+
+	return s
+}
@@ -0,0 +1,233 @@
+package loan
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/validation"
+	"github.com/google/uuid"
+)
+
+type Status string
+type InstallmentStatus string
+
+const (
+	StatusPending   Status = "pending"   // applied, waiting for a decision
+	StatusRejected  Status = "rejected"  // declined by an officer
+	StatusCancelled Status = "cancelled" // withdrawn by the applicant before a decision
+	StatusActive    Status = "active"    // disbursed and being repaid
+	StatusPaidOff   Status = "paid_off"
+
+	InstallmentStatusPending InstallmentStatus = "pending"
+	InstallmentStatusPaid    InstallmentStatus = "paid"
+)
+
+const (
+	// MaxDueDay keeps the monthly due date valid in every month
+	MaxDueDay = 28
+
+	// MaxPendingApplications stops a user queueing applications while one is undecided
+	MaxPendingApplications = 1
+
+	// DefaultTimezone is where a due date starts at midnight
+	DefaultTimezone = "Asia/Jakarta"
+)
+
+// Product is a loan offering with the ranges an application must fall in
+type Product struct {
+	Code             string    `json:"code"`
+	Name             string    `json:"name"`
+	MinAmount        float64   `json:"min_amount"`
+	MaxAmount        float64   `json:"max_amount"`
+	MinTenorMonths   int       `json:"min_tenor_months"`
+	MaxTenorMonths   int       `json:"max_tenor_months"`
+	AnnualRate       float64   `json:"annual_rate"`
+	ProvisionFeeRate float64   `json:"provision_fee_rate"`
+	Active           bool      `json:"active"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// Validate checks an amount and tenor against the product's ranges
+func (p *Product) Validate(amount float64, tenorMonths int) error {
+	if !p.Active {
+		return fmt.Errorf("loan product is not available")
+	}
+	if amount < p.MinAmount || amount > p.MaxAmount {
+		return fmt.Errorf("amount must be between %.0f and %.0f", p.MinAmount, p.MaxAmount)
+	}
+	if tenorMonths < p.MinTenorMonths || tenorMonths > p.MaxTenorMonths {
+		return fmt.Errorf("tenor must be between %d and %d months", p.MinTenorMonths, p.MaxTenorMonths)
+	}
+	return nil
+}
+
+// ProvisionFee is deducted from the disbursed amount
+func (p *Product) ProvisionFee(amount float64) float64 {
+	return roundAmount(amount * p.ProvisionFeeRate)
+}
+
+type Loan struct {
+	ID                        uuid.UUID  `json:"id"`
+	UserID                    uuid.UUID  `json:"user_id"`
+	ProductCode               string     `json:"product_code"`
+	AccountID                 uuid.UUID  `json:"account_id"` // receives the disbursement and is auto-debited
+	Principal                 float64    `json:"principal"`
+	TenorMonths               int        `json:"tenor_months"`
+	AnnualRate                float64    `json:"annual_rate"`
+	MonthlyInstallment        float64    `json:"monthly_installment"`
+	ProvisionFee              float64    `json:"provision_fee"`
+	OutstandingPrincipal      float64    `json:"outstanding_principal"`
+	Purpose                   string     `json:"purpose,omitempty"`
+	Status                    Status     `json:"status"`
+	DecisionReason            string     `json:"decision_reason,omitempty"`
+	DecidedBy                 *uuid.UUID `json:"-"`
+	DisbursementTransactionID *uuid.UUID `json:"disbursement_transaction_id,omitempty"`
+	CreatedAt                 time.Time  `json:"created_at"`
+	UpdatedAt                 time.Time  `json:"updated_at"`
+	DecidedAt                 *time.Time `json:"decided_at,omitempty"`
+	DisbursedAt               *time.Time `json:"disbursed_at,omitempty"`
+	ClosedAt                  *time.Time `json:"closed_at,omitempty"`
+
+	Schedule []*Installment `json:"schedule,omitempty"`
+}
+
+// Installment is one monthly repayment of the amortization schedule
+type Installment struct {
+	ID              uuid.UUID         `json:"id"`
+	LoanID          uuid.UUID         `json:"loan_id"`
+	Number          int               `json:"number"`
+	DueDate         time.Time         `json:"due_date"`
+	Principal       float64           `json:"principal"`
+	Interest        float64           `json:"interest"`
+	Amount          float64           `json:"amount"`
+	Status          InstallmentStatus `json:"status"`
+	DebitAttempts   int               `json:"debit_attempts"`
+	LastAttemptDate *time.Time        `json:"last_attempt_date,omitempty"`
+	PaidAt          *time.Time        `json:"paid_at,omitempty"`
+	TransactionID   *uuid.UUID        `json:"transaction_id,omitempty"`
+}
+
+// IsOverdue reports an unpaid installment whose due date has passed
+func (i *Installment) IsOverdue(today time.Time) bool {
+	return i.Status == InstallmentStatusPending && i.DueDate.Before(today)
+}
+
+// MonthlyPayment is the fixed annuity payment for the principal, rounded up so
+// the final installment never exceeds the others
+func MonthlyPayment(principal, annualRate float64, tenorMonths int) float64 {
+	if tenorMonths <= 0 {
+		return 0
+	}
+	r := annualRate / 12
+	if r == 0 {
+		return math.Ceil(principal/float64(tenorMonths)*100) / 100
+	}
+	payment := principal * r / (1 - math.Pow(1+r, -float64(tenorMonths)))
+	return math.Ceil(payment*100) / 100
+}
+
+// BuildSchedule amortizes the principal over the tenor with equal monthly
+// payments. Interest is charged monthly on the outstanding principal and the
+// last installment absorbs the rounding. The first payment is due one month
+// after disbursement, on the same day of the month capped at MaxDueDay.
+func BuildSchedule(loanID uuid.UUID, principal, annualRate float64, tenorMonths int, disbursedOn time.Time) []*Installment {
+	payment := MonthlyPayment(principal, annualRate, tenorMonths)
+	r := annualRate / 12
+
+	day := disbursedOn.Day()
+	if day > MaxDueDay {
+		day = MaxDueDay
+	}
+
+	schedule := make([]*Installment, 0, tenorMonths)
+	outstanding := principal
+	for n := 1; n <= tenorMonths; n++ {
+		interest := roundAmount(outstanding * r)
+		principalPart := roundAmount(payment - interest)
+		if n == tenorMonths || principalPart > outstanding {
+			principalPart = outstanding
+		}
+		outstanding = roundAmount(outstanding - principalPart)
+
+		schedule = append(schedule, &Installment{
+			ID:        uuid.New(),
+			LoanID:    loanID,
+			Number:    n,
+			DueDate:   time.Date(disbursedOn.Year(), disbursedOn.Month()+time.Month(n), day, 0, 0, 0, 0, disbursedOn.Location()),
+			Principal: principalPart,
+			Interest:  interest,
+			Amount:    roundAmount(principalPart + interest),
+			Status:    InstallmentStatusPending,
+		})
+	}
+
+	return schedule
+}
+
+// TotalRepayment sums the installments of a schedule
+func TotalRepayment(schedule []*Installment) float64 {
+	total := 0.0
+	for _, inst := range schedule {
+		total += inst.Amount
+	}
+	return roundAmount(total)
+}
+
+func roundAmount(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+type ApplyLoanRequest struct {
+	ProductCode string  `json:"product_code" binding:"required"`
+	AccountID   string  `json:"account_id" binding:"required,uuid"`
+	Amount      float64 `json:"amount" binding:"required,gt=0"`
+	TenorMonths int     `json:"tenor_months" binding:"required,min=1"`
+	Purpose     string  `json:"purpose,omitempty" binding:"max=255"`
+}
+
+// Validate checks the amount and purpose; see package validation
+func (r *ApplyLoanRequest) Validate() error {
+	return validation.New().Amount("amount", r.Amount).Description("purpose", r.Purpose).Err()
+}
+
+type QuoteRequest struct {
+	ProductCode string  `json:"product_code" binding:"required"`
+	Amount      float64 `json:"amount" binding:"required,gt=0"`
+	TenorMonths int     `json:"tenor_months" binding:"required,min=1"`
+}
+
+// Validate checks the amount; see package validation
+func (r *QuoteRequest) Validate() error {
+	return validation.New().Amount("amount", r.Amount).Err()
+}
+
+// Quote previews a loan's cost before applying; the schedule assumes disbursement today
+type Quote struct {
+	ProductCode        string         `json:"product_code"`
+	Principal          float64        `json:"principal"`
+	TenorMonths        int            `json:"tenor_months"`
+	AnnualRate         float64        `json:"annual_rate"`
+	ProvisionFee       float64        `json:"provision_fee"`
+	DisbursedAmount    float64        `json:"disbursed_amount"`
+	MonthlyInstallment float64        `json:"monthly_installment"`
+	TotalRepayment     float64        `json:"total_repayment"`
+	TotalInterest      float64        `json:"total_interest"`
+	Schedule           []*Installment `json:"schedule"`
+}
+
+type ListLoansRequest struct {
+	Status string `form:"status,omitempty"`
+}
+
+type RejectLoanRequest struct {
+	Reason string `json:"reason" binding:"required,max=255"`
+}
+
+func IsValidStatus(status Status) bool {
+	switch status {
+	case StatusPending, StatusRejected, StatusCancelled, StatusActive, StatusPaidOff:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package loan
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMonthlyPayment(t *testing.T) {
+	// 12,000,000 over 12 months at 12% a year is 1,066,185.47 a month, rounded up
+	assert.Equal(t, 1_066_185.47, MonthlyPayment(12_000_000, 0.12, 12))
+	assert.Equal(t, 1_000_000.0, MonthlyPayment(12_000_000, 0, 12))
+	assert.Equal(t, 0.0, MonthlyPayment(12_000_000, 0.12, 0))
+}
+
+func TestBuildSchedule(t *testing.T) {
+	loanID := uuid.New()
+	disbursed := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	schedule := BuildSchedule(loanID, 12_000_000, 0.12, 12, disbursed)
+
+	assert.Len(t, schedule, 12)
+	first := schedule[0]
+	assert.Equal(t, 1, first.Number)
+	assert.Equal(t, time.Date(2026, 2, 15, 0, 0, 0, 0, time.UTC), first.DueDate)
+	assert.Equal(t, 120_000.0, first.Interest)
+	assert.Equal(t, 1_066_185.47, first.Amount)
+	assert.Equal(t, InstallmentStatusPending, first.Status)
+	assert.Equal(t, time.Date(2027, 1, 15, 0, 0, 0, 0, time.UTC), schedule[11].DueDate)
+
+	principal := 0.0
+	for _, inst := range schedule {
+		assert.Equal(t, loanID, inst.LoanID)
+		assert.InDelta(t, inst.Principal+inst.Interest, inst.Amount, 0.001)
+		principal += inst.Principal
+	}
+	assert.InDelta(t, 12_000_000, principal, 0.001)
+	// The last installment absorbs rounding and is never larger than the others
+	assert.LessOrEqual(t, schedule[11].Amount, first.Amount)
+}
+
+func TestBuildSchedule_CapsDueDay(t *testing.T) {
+	disbursed := time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	schedule := BuildSchedule(uuid.New(), 3_000_000, 0.18, 3, disbursed)
+
+	assert.Equal(t, time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC), schedule[0].DueDate)
+	assert.Equal(t, time.Date(2026, 3, 28, 0, 0, 0, 0, time.UTC), schedule[1].DueDate)
+}
+
+func TestProduct_Validate(t *testing.T) {
+	p := &Product{MinAmount: 5_000_000, MaxAmount: 100_000_000, MinTenorMonths: 6, MaxTenorMonths: 36, Active: true}
+
+	assert.NoError(t, p.Validate(10_000_000, 12))
+	assert.EqualError(t, p.Validate(1_000_000, 12), "amount must be between 5000000 and 100000000")
+	assert.EqualError(t, p.Validate(10_000_000, 48), "tenor must be between 6 and 36 months")
+
+	p.Active = false
+	assert.EqualError(t, p.Validate(10_000_000, 12), "loan product is not available")
+}
+
+func TestInstallment_IsOverdue(t *testing.T) {
+	today := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	due := &Installment{Status: InstallmentStatusPending, DueDate: today}
+	assert.False(t, due.IsOverdue(today))
+
+	late := &Installment{Status: InstallmentStatusPending, DueDate: today.AddDate(0, 0, -1)}
+	assert.True(t, late.IsOverdue(today))
+
+	paid := &Installment{Status: InstallmentStatusPaid, DueDate: today.AddDate(0, 0, -1)}
+	assert.False(t, paid.IsOverdue(today))
+}
@@ -0,0 +1,284 @@
+package merchant
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/validation"
+	"github.com/google/uuid"
+)
+
+type Status string
+type PaymentLinkStatus string
+type Category string
+
+const (
+	StatusActive    Status = "active"
+	StatusSuspended Status = "suspended"
+
+	PaymentLinkStatusOpen      PaymentLinkStatus = "open"
+	PaymentLinkStatusPaid      PaymentLinkStatus = "paid"
+	PaymentLinkStatusCancelled PaymentLinkStatus = "cancelled"
+	// Expired is derived from ExpiresAt when a link is read; it is never stored
+	PaymentLinkStatusExpired PaymentLinkStatus = "expired"
+
+	CategoryRetail   Category = "retail"
+	CategoryFood     Category = "food_beverage"
+	CategoryServices Category = "services"
+	CategoryOnline   Category = "online"
+	CategoryOther    Category = "other"
+)
+
+const (
+	// MerchantDiscountRate is the share of each payment kept by the bank at settlement
+	MerchantDiscountRate = 0.007
+
+	// APIKeyPrefix marks merchant API keys so leaked keys are easy to recognise
+	APIKeyPrefix = "mbk_live_"
+
+	DefaultPaymentLinkTTL = 24 * time.Hour
+	MaxPaymentLinkTTL     = 30 * 24 * time.Hour
+
+	MinPaymentAmount = 1_000
+	MaxPaymentAmount = 50_000_000
+
+	// QRPayloadPrefix is followed by the payment link ID in QR codes
+	QRPayloadPrefix = "madabank:pay:"
+
+	// MaxNotifyAttempts bounds webhook retries; after that the merchant has to poll
+	MaxNotifyAttempts = 8
+
+	// MaxReplayWindow is how far back payment events can be redelivered
+	MaxReplayWindow = 30 * 24 * time.Hour
+
+	EventPaymentReceived = "payment.received"
+	// EventWebhookTest is sent on request, for checking the receiver
+	EventWebhookTest = "webhook.test"
+
+	// DefaultSettlementTimezone is where a business day ends for settlement
+	DefaultSettlementTimezone = "Asia/Jakarta"
+)
+
+type Merchant struct {
+	ID                  uuid.UUID `json:"id"`
+	OwnerID             uuid.UUID `json:"owner_id"`
+	Name                string    `json:"name"`
+	Category            Category  `json:"category"`
+	SettlementAccountID uuid.UUID `json:"settlement_account_id"`
+	WebhookURL          string    `json:"webhook_url,omitempty"`
+	WebhookSecret       string    `json:"-"`
+	APIKeyHint          string    `json:"api_key_hint"`
+	APIKeyHash          string    `json:"-"`
+	Status              Status    `json:"status"`
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+// PaymentLink is a one-off request for payment, shared as a URL or QR code
+type PaymentLink struct {
+	ID             uuid.UUID         `json:"id"`
+	MerchantID     uuid.UUID         `json:"merchant_id"`
+	MerchantName   string            `json:"merchant_name"`
+	Reference      string            `json:"reference"`
+	Amount         float64           `json:"amount"`
+	Description    string            `json:"description,omitempty"`
+	Status         PaymentLinkStatus `json:"status"`
+	ExpiresAt      time.Time         `json:"expires_at"`
+	PaidAt         *time.Time        `json:"paid_at,omitempty"`
+	PayerAccountID *uuid.UUID        `json:"-"`
+	TransactionID  *uuid.UUID        `json:"transaction_id,omitempty"`
+	SettlementID   *uuid.UUID        `json:"settlement_id,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+	QRPayload      string            `json:"qr_payload"`
+	URL            string            `json:"url,omitempty"`
+}
+
+// EffectiveStatus reports open links past their expiry as expired
+func (l *PaymentLink) EffectiveStatus(now time.Time) PaymentLinkStatus {
+	if l.Status == PaymentLinkStatusOpen && !now.Before(l.ExpiresAt) {
+		return PaymentLinkStatusExpired
+	}
+	return l.Status
+}
+
+// Settlement is the daily payout of a merchant's collected payments, net of the MDR fee
+type Settlement struct {
+	ID            uuid.UUID `json:"id"`
+	MerchantID    uuid.UUID `json:"merchant_id"`
+	AccountID     uuid.UUID `json:"account_id"`
+	BusinessDate  time.Time `json:"business_date"`
+	PaymentCount  int       `json:"payment_count"`
+	GrossAmount   float64   `json:"gross_amount"`
+	FeeAmount     float64   `json:"fee_amount"`
+	NetAmount     float64   `json:"net_amount"`
+	TransactionID uuid.UUID `json:"transaction_id"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// SettlementCutoff is the start of the current business day. Payments received
+// before it belong to the business date reported alongside it, the day before.
+func SettlementCutoff(now time.Time, loc *time.Location) (cutoff time.Time, businessDate time.Time) {
+	local := now.In(loc)
+	cutoff = time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	return cutoff, cutoff.AddDate(0, 0, -1)
+}
+
+// SettlementFee is the MDR fee on a gross amount, rounded to the rupiah
+func SettlementFee(gross float64) float64 {
+	return math.Round(gross * MerchantDiscountRate)
+}
+
+// ParseQRPayload extracts the payment link ID from a scanned QR code
+func ParseQRPayload(payload string) (uuid.UUID, error) {
+	if !strings.HasPrefix(payload, QRPayloadPrefix) {
+		return uuid.Nil, fmt.Errorf("invalid payment QR code")
+	}
+	id, err := uuid.Parse(strings.TrimPrefix(payload, QRPayloadPrefix))
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid payment QR code")
+	}
+	return id, nil
+}
+
+func IsValidCategory(category Category) bool {
+	switch category {
+	case CategoryRetail, CategoryFood, CategoryServices, CategoryOnline, CategoryOther:
+		return true
+	}
+	return false
+}
+
+// NotifyBackoff is the wait before the next webhook attempt, doubling from
+// 30 seconds up to one hour
+func NotifyBackoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	backoff := 30 * time.Second
+	for i := 1; i < attempts && backoff < time.Hour; i++ {
+		backoff *= 2
+	}
+	if backoff > time.Hour {
+		backoff = time.Hour
+	}
+	return backoff
+}
+
+// PendingNotification is a paid link whose webhook has not been delivered yet
+type PendingNotification struct {
+	Link          *PaymentLink
+	WebhookURL    string
+	WebhookSecret string
+	Attempts      int
+}
+
+// PaymentNotification is posted to the merchant's webhook when a payment link is paid
+type PaymentNotification struct {
+	Event         string    `json:"event"`
+	MerchantID    uuid.UUID `json:"merchant_id"`
+	PaymentLinkID uuid.UUID `json:"payment_link_id"`
+	Reference     string    `json:"reference"`
+	Amount        float64   `json:"amount"`
+	TransactionID uuid.UUID `json:"transaction_id"`
+	PaidAt        time.Time `json:"paid_at"`
+}
+
+type RegisterMerchantRequest struct {
+	Name                string `json:"name" binding:"required,min=3,max=100"`
+	Category            string `json:"category" binding:"required"`
+	SettlementAccountID string `json:"settlement_account_id" binding:"required,uuid"`
+	WebhookURL          string `json:"webhook_url,omitempty" binding:"omitempty,url,startswith=https://"`
+}
+
+// RegisterMerchantResponse carries the API key and webhook secret, which are only shown once
+type RegisterMerchantResponse struct {
+	Merchant      *Merchant `json:"merchant"`
+	APIKey        string    `json:"api_key"`
+	WebhookSecret string    `json:"webhook_secret"`
+}
+
+// WebhookDelivery is one attempt to post an event to a merchant's webhook
+type WebhookDelivery struct {
+	ID            uuid.UUID  `json:"id"`
+	MerchantID    uuid.UUID  `json:"merchant_id"`
+	Event         string     `json:"event"`
+	URL           string     `json:"url"`
+	PaymentLinkID *uuid.UUID `json:"payment_link_id,omitempty"`
+	StatusCode    int        `json:"status_code,omitempty"` // 0 when the receiver did not answer
+	Success       bool       `json:"success"`
+	Error         string     `json:"error,omitempty"`
+	DurationMs    int64      `json:"duration_ms"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// WebhookTestNotification is posted to the merchant's webhook on request
+type WebhookTestNotification struct {
+	Event      string    `json:"event"`
+	MerchantID uuid.UUID `json:"merchant_id"`
+	SentAt     time.Time `json:"sent_at"`
+}
+
+type UpdateWebhookRequest struct {
+	// WebhookURL replaces the merchant's webhook; empty removes it
+	WebhookURL string `json:"webhook_url" binding:"omitempty,url,startswith=https://,max=255"`
+}
+
+// RotateWebhookSecretResponse carries the new webhook secret, which is only shown once
+type RotateWebhookSecretResponse struct {
+	WebhookSecret string `json:"webhook_secret"`
+}
+
+// ReplayWebhookRequest asks for the payment events since a point in time to
+// be delivered again
+type ReplayWebhookRequest struct {
+	Since string `form:"since" binding:"required"`
+}
+
+// SinceTime parses Since as RFC 3339 and checks it lies within MaxReplayWindow of now
+func (r *ReplayWebhookRequest) SinceTime(now time.Time) (time.Time, error) {
+	since, err := time.Parse(time.RFC3339, r.Since)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid since, expected RFC 3339 such as 2026-03-01T00:00:00Z")
+	}
+	if since.After(now) {
+		return time.Time{}, fmt.Errorf("since must not be in the future")
+	}
+	if now.Sub(since) > MaxReplayWindow {
+		return time.Time{}, fmt.Errorf("since must be within the last %d days", int(MaxReplayWindow.Hours()/24))
+	}
+	return since.UTC(), nil
+}
+
+// ReplayWebhookResponse reports how many payment events were queued for redelivery
+type ReplayWebhookResponse struct {
+	Since     time.Time `json:"since"`
+	Scheduled int       `json:"scheduled"`
+}
+
+type RotateAPIKeyResponse struct {
+	APIKey     string `json:"api_key"`
+	APIKeyHint string `json:"api_key_hint"`
+}
+
+type CreatePaymentLinkRequest struct {
+	Reference        string  `json:"reference" binding:"required,max=64"`
+	Amount           float64 `json:"amount" binding:"required,gt=0"`
+	Description      string  `json:"description,omitempty" binding:"max=255"`
+	ExpiresInMinutes int     `json:"expires_in_minutes,omitempty" binding:"omitempty,min=1"`
+}
+
+// Validate checks the amount and description; see package validation
+func (r *CreatePaymentLinkRequest) Validate() error {
+	return validation.New().Amount("amount", r.Amount).Description("description", r.Description).Err()
+}
+
+type PayPaymentLinkRequest struct {
+	AccountID      string `json:"account_id" binding:"required,uuid"`
+	IdempotencyKey string `json:"idempotency_key" binding:"required"`
+}
+
+type ResolvePaymentQRRequest struct {
+	QRPayload string `json:"qr_payload" binding:"required"`
+}
@@ -0,0 +1,78 @@
+package merchant
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSettlementCutoff(t *testing.T) {
+	wib := time.FixedZone("WIB", 7*60*60)
+
+	// 23:30 UTC on 1 March is already 06:30 on 2 March in Jakarta
+	cutoff, businessDate := SettlementCutoff(time.Date(2026, 3, 1, 23, 30, 0, 0, time.UTC), wib)
+
+	assert.Equal(t, time.Date(2026, 3, 2, 0, 0, 0, 0, wib), cutoff)
+	assert.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, wib), businessDate)
+	assert.Equal(t, time.Date(2026, 3, 1, 17, 0, 0, 0, time.UTC), cutoff.UTC())
+}
+
+func TestSettlementFee(t *testing.T) {
+	assert.Equal(t, 700.0, SettlementFee(100_000))
+	assert.Equal(t, 1.0, SettlementFee(150)) // 1.05 rounds to the rupiah
+	assert.Equal(t, 0.0, SettlementFee(0))
+}
+
+func TestParseQRPayload(t *testing.T) {
+	id := uuid.New()
+
+	got, err := ParseQRPayload(QRPayloadPrefix + id.String())
+	assert.NoError(t, err)
+	assert.Equal(t, id, got)
+
+	_, err = ParseQRPayload("madabank:account:" + id.String())
+	assert.Error(t, err)
+
+	_, err = ParseQRPayload(QRPayloadPrefix + "not-a-uuid")
+	assert.Error(t, err)
+}
+
+func TestPaymentLink_EffectiveStatus(t *testing.T) {
+	now := time.Now()
+
+	open := &PaymentLink{Status: PaymentLinkStatusOpen, ExpiresAt: now.Add(time.Minute)}
+	assert.Equal(t, PaymentLinkStatusOpen, open.EffectiveStatus(now))
+
+	lapsed := &PaymentLink{Status: PaymentLinkStatusOpen, ExpiresAt: now}
+	assert.Equal(t, PaymentLinkStatusExpired, lapsed.EffectiveStatus(now))
+
+	// A paid link stays paid after it would have expired
+	paid := &PaymentLink{Status: PaymentLinkStatusPaid, ExpiresAt: now.Add(-time.Hour)}
+	assert.Equal(t, PaymentLinkStatusPaid, paid.EffectiveStatus(now))
+}
+
+func TestNotifyBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, NotifyBackoff(1))
+	assert.Equal(t, time.Minute, NotifyBackoff(2))
+	assert.Equal(t, 4*time.Minute, NotifyBackoff(4))
+	assert.Equal(t, time.Hour, NotifyBackoff(10))
+}
+
+func TestReplayWebhookRequest_SinceTime(t *testing.T) {
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+
+	since, err := (&ReplayWebhookRequest{Since: "2026-03-30T19:00:00+07:00"}).SinceTime(now)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 3, 30, 12, 0, 0, 0, time.UTC), since)
+
+	_, err = (&ReplayWebhookRequest{Since: "2026-03-30"}).SinceTime(now)
+	assert.ErrorContains(t, err, "invalid since")
+
+	_, err = (&ReplayWebhookRequest{Since: "2026-04-01T00:00:00Z"}).SinceTime(now)
+	assert.EqualError(t, err, "since must not be in the future")
+
+	_, err = (&ReplayWebhookRequest{Since: "2026-02-28T00:00:00Z"}).SinceTime(now)
+	assert.EqualError(t, err, "since must be within the last 30 days")
+}
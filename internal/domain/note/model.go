@@ -0,0 +1,38 @@
+package note
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrSubjectNotFound is returned when the transaction or user a note is for
+// does not exist
+var ErrSubjectNotFound = errors.New("not found")
+
+// SubjectType is what a note is attached to
+type SubjectType string
+
+const (
+	SubjectTransaction SubjectType = "transaction"
+	SubjectUser        SubjectType = "user"
+)
+
+// Note is a free-text annotation staff leave on a transaction or a user, for
+// example while handling a dispute. Notes are internal: they are only served
+// on admin routes and never included in customer responses. They cannot be
+// edited or deleted; a correction is a new note.
+type Note struct {
+	ID          uuid.UUID   `json:"id"`
+	SubjectType SubjectType `json:"subject_type"`
+	SubjectID   uuid.UUID   `json:"subject_id"`
+	Body        string      `json:"body"`
+	AuthorID    uuid.UUID   `json:"author_id"`
+	AuthorEmail string      `json:"author_email,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+type CreateNoteRequest struct {
+	Body string `json:"body" binding:"required,max=2000"`
+}
@@ -0,0 +1,80 @@
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusRunning   Status = "running"
+	StatusCompleted Status = "completed"
+	StatusDead      Status = "dead" // attempts exhausted, waiting for an operator
+)
+
+const (
+	DefaultMaxAttempts = 5
+
+	// baseBackoff and maxBackoff bound the delay before a failed job is retried
+	baseBackoff = 15 * time.Second
+	maxBackoff  = time.Hour
+)
+
+// ErrLockLost is returned when a worker finishes a job it no longer holds,
+// because its lock expired and the job was requeued or claimed by another worker
+var ErrLockLost = errors.New("job lock lost")
+
+// Job is one unit of queued background work
+type Job struct {
+	ID          uuid.UUID       `json:"id"`
+	Kind        string          `json:"kind"`
+	Payload     json.RawMessage `json:"payload"`
+	Status      Status          `json:"status"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	RunAt       time.Time       `json:"run_at"`
+	UniqueKey   *string         `json:"unique_key,omitempty"`
+	LastError   string          `json:"last_error,omitempty"`
+	LockedBy    *string         `json:"locked_by,omitempty"`
+	LockedAt    *time.Time      `json:"locked_at,omitempty"`
+	CompletedAt *time.Time      `json:"completed_at,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// Exhausted reports whether the job has used all of its attempts
+func (j *Job) Exhausted() bool {
+	return j.Attempts >= j.MaxAttempts
+}
+
+// Backoff is the delay before retrying a job that failed its nth attempt:
+// 15s, 30s, 1m, 2m, ... capped at an hour
+func Backoff(attempt int) time.Duration {
+	delay := baseBackoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return delay
+}
+
+func IsValidStatus(s Status) bool {
+	switch s {
+	case StatusPending, StatusRunning, StatusCompleted, StatusDead:
+		return true
+	}
+	return false
+}
+
+type ListJobsRequest struct {
+	Status Status `form:"status" binding:"omitempty,oneof=pending running completed dead"`
+	Kind   string `form:"kind"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
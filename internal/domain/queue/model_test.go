@@ -0,0 +1,20 @@
+package queue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 15*time.Second, Backoff(1))
+	assert.Equal(t, 30*time.Second, Backoff(2))
+	assert.Equal(t, 2*time.Minute, Backoff(4))
+	assert.Equal(t, time.Hour, Backoff(20))
+}
+
+func TestJob_Exhausted(t *testing.T) {
+	assert.False(t, (&Job{Attempts: 4, MaxAttempts: 5}).Exhausted())
+	assert.True(t, (&Job{Attempts: 5, MaxAttempts: 5}).Exhausted())
+}
@@ -0,0 +1,283 @@
+package reconciliation
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/google/uuid"
+)
+
+type RunKind string
+type Source string
+type ExceptionType string
+type ExceptionStatus string
+
+const (
+	RunKindLedger         RunKind = "ledger"          // stored balances against the transaction ledger
+	RunKindSettlementFile RunKind = "settlement_file" // transactions against a counterparty file
+
+	SourceLedger           Source = "ledger"
+	SourceBillerAggregator Source = "biller_aggregator" // references are our bill payment transaction IDs
+	SourceTopupAggregator  Source = "topup_aggregator"  // references are our top-up IDs
+
+	ExceptionBalanceMismatch ExceptionType = "balance_mismatch"
+	ExceptionMissingInLedger ExceptionType = "missing_in_ledger" // in the file but unknown to us
+	ExceptionMissingInFile   ExceptionType = "missing_in_file"   // settled by us but not by the counterparty
+	ExceptionAmountMismatch  ExceptionType = "amount_mismatch"
+	ExceptionStatusMismatch  ExceptionType = "status_mismatch" // settled by the counterparty but not by us
+
+	ExceptionStatusOpen          ExceptionStatus = "open"
+	ExceptionStatusInvestigating ExceptionStatus = "investigating"
+	ExceptionStatusResolved      ExceptionStatus = "resolved"
+	ExceptionStatusDismissed     ExceptionStatus = "dismissed" // not a real break, e.g. a timing difference
+)
+
+const (
+	// Tolerance absorbs rounding below one cent
+	Tolerance = 0.005
+
+	// MaxSettlementFileSize bounds uploaded settlement files
+	MaxSettlementFileSize = 5 << 20
+
+	// DefaultTimezone is where a business day starts and ends
+	DefaultTimezone = "Asia/Jakarta"
+
+	// BusinessDateLayout is the format of business dates in requests
+	BusinessDateLayout = "2006-01-02"
+)
+
+// Run records one reconciliation pass and how many breaks it found
+type Run struct {
+	ID           uuid.UUID  `json:"id"`
+	Kind         RunKind    `json:"kind"`
+	Source       Source     `json:"source"`
+	BusinessDate time.Time  `json:"business_date"`
+	FileName     string     `json:"file_name,omitempty"`
+	ItemsChecked int        `json:"items_checked"`
+	BreaksFound  int        `json:"breaks_found"`
+	StartedBy    *uuid.UUID `json:"started_by,omitempty"` // nil for scheduled runs
+	CreatedAt    time.Time  `json:"created_at"`
+
+	Exceptions []*Exception `json:"exceptions,omitempty"`
+}
+
+// Exception is a break found by a run. While it is open or under investigation,
+// later runs that find the same break update it instead of adding another.
+type Exception struct {
+	ID             uuid.UUID       `json:"id"`
+	RunID          uuid.UUID       `json:"run_id"`
+	Type           ExceptionType   `json:"type"`
+	Source         Source          `json:"source"`
+	Reference      string          `json:"reference"`
+	AccountID      *uuid.UUID      `json:"account_id,omitempty"`
+	ExpectedAmount *float64        `json:"expected_amount,omitempty"` // per our ledger
+	ActualAmount   *float64        `json:"actual_amount,omitempty"`   // stored balance or counterparty amount
+	Details        string          `json:"details,omitempty"`
+	Status         ExceptionStatus `json:"status"`
+	AssignedTo     *uuid.UUID      `json:"assigned_to,omitempty"`
+	ResolutionNote string          `json:"resolution_note,omitempty"`
+	ResolvedBy     *uuid.UUID      `json:"resolved_by,omitempty"`
+	ResolvedAt     *time.Time      `json:"resolved_at,omitempty"`
+	DetectedCount  int             `json:"detected_count"`
+	LastDetectedAt time.Time       `json:"last_detected_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// CanTransition reports whether an exception may move from one status to another
+func CanTransition(from, to ExceptionStatus) bool {
+	switch from {
+	case ExceptionStatusOpen:
+		return to == ExceptionStatusInvestigating || to == ExceptionStatusResolved || to == ExceptionStatusDismissed
+	case ExceptionStatusInvestigating:
+		return to == ExceptionStatusResolved || to == ExceptionStatusDismissed
+	}
+	return false
+}
+
+// IsClosed reports a status that ends the workflow
+func (s ExceptionStatus) IsClosed() bool {
+	return s == ExceptionStatusResolved || s == ExceptionStatusDismissed
+}
+
+// SettlementRecord is one line of a counterparty settlement file
+type SettlementRecord struct {
+	Reference string
+	Amount    float64
+}
+
+// LedgerEntry is our side of a settlement record
+type LedgerEntry struct {
+	Reference string
+	Amount    float64
+	Status    string
+	// Settled means we consider the entry final and expect it in the counterparty file
+	Settled bool
+}
+
+// ParseSettlementFile reads a CSV settlement file. The header row must name a
+// "reference" and an "amount" column; other columns are ignored.
+func ParseSettlementFile(r io.Reader) ([]*SettlementRecord, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("settlement file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid settlement file: %w", err)
+	}
+
+	refCol, amountCol := -1, -1
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "reference":
+			refCol = i
+		case "amount":
+			amountCol = i
+		}
+	}
+	if refCol < 0 || amountCol < 0 {
+		return nil, fmt.Errorf("settlement file must have reference and amount columns")
+	}
+
+	records := []*SettlementRecord{}
+	seen := make(map[string]bool)
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid settlement file: %w", err)
+		}
+		if refCol >= len(row) || amountCol >= len(row) {
+			return nil, fmt.Errorf("line %d: missing columns", line)
+		}
+
+		ref := strings.TrimSpace(row[refCol])
+		if ref == "" {
+			return nil, fmt.Errorf("line %d: reference is empty", line)
+		}
+		if seen[ref] {
+			return nil, fmt.Errorf("line %d: duplicate reference %s", line, ref)
+		}
+		seen[ref] = true
+
+		amount, err := strconv.ParseFloat(strings.TrimSpace(row[amountCol]), 64)
+		if err != nil || amount <= 0 {
+			return nil, fmt.Errorf("line %d: invalid amount", line)
+		}
+
+		records = append(records, &SettlementRecord{Reference: ref, Amount: amount})
+	}
+
+	return records, nil
+}
+
+// CompareSettlement matches a counterparty file against our entries. dayEntries
+// are our entries for the file's business date; otherEntries are entries from
+// other days that the file references, which are matched but never reported as
+// missing from the file.
+func CompareSettlement(source Source, records []*SettlementRecord, dayEntries, otherEntries []*LedgerEntry) []*Exception {
+	entries := make(map[string]*LedgerEntry, len(dayEntries)+len(otherEntries))
+	for _, e := range otherEntries {
+		entries[e.Reference] = e
+	}
+	for _, e := range dayEntries {
+		entries[e.Reference] = e
+	}
+
+	exceptions := []*Exception{}
+	inFile := make(map[string]bool, len(records))
+	for _, rec := range records {
+		inFile[rec.Reference] = true
+		actual := rec.Amount
+
+		entry, ok := entries[rec.Reference]
+		switch {
+		case !ok:
+			exceptions = append(exceptions, newException(ExceptionMissingInLedger, source, rec.Reference, nil, &actual,
+				"settled by the counterparty but not found in the ledger"))
+		case !entry.Settled:
+			expected := entry.Amount
+			exceptions = append(exceptions, newException(ExceptionStatusMismatch, source, rec.Reference, &expected, &actual,
+				fmt.Sprintf("settled by the counterparty but %s in the ledger", entry.Status)))
+		case math.Abs(entry.Amount-rec.Amount) >= Tolerance:
+			expected := entry.Amount
+			exceptions = append(exceptions, newException(ExceptionAmountMismatch, source, rec.Reference, &expected, &actual, ""))
+		}
+	}
+
+	for _, e := range dayEntries {
+		if e.Settled && !inFile[e.Reference] {
+			expected := e.Amount
+			exceptions = append(exceptions, newException(ExceptionMissingInFile, source, e.Reference, &expected, nil,
+				"settled in the ledger but not in the counterparty file"))
+		}
+	}
+
+	return exceptions
+}
+
+// CompareLedger flags accounts whose stored balance drifted from the ledger
+func CompareLedger(balances []*account.LedgerBalance) []*Exception {
+	exceptions := []*Exception{}
+	for _, b := range balances {
+		if math.Abs(b.Drift()) < Tolerance {
+			continue
+		}
+		expected, actual := b.LedgerBalance, b.StoredBalance
+		accountID := b.AccountID
+		e := newException(ExceptionBalanceMismatch, SourceLedger, b.AccountNumber, &expected, &actual,
+			fmt.Sprintf("stored balance drifted by %.2f", b.Drift()))
+		e.AccountID = &accountID
+		exceptions = append(exceptions, e)
+	}
+	return exceptions
+}
+
+func newException(t ExceptionType, source Source, reference string, expected, actual *float64, details string) *Exception {
+	return &Exception{
+		ID:             uuid.New(),
+		Type:           t,
+		Source:         source,
+		Reference:      reference,
+		ExpectedAmount: expected,
+		ActualAmount:   actual,
+		Details:        details,
+		Status:         ExceptionStatusOpen,
+		DetectedCount:  1,
+	}
+}
+
+// IsValidFileSource reports a counterparty that sends settlement files
+func IsValidFileSource(source Source) bool {
+	return source == SourceBillerAggregator || source == SourceTopupAggregator
+}
+
+func IsValidExceptionStatus(status ExceptionStatus) bool {
+	switch status {
+	case ExceptionStatusOpen, ExceptionStatusInvestigating, ExceptionStatusResolved, ExceptionStatusDismissed:
+		return true
+	}
+	return false
+}
+
+type ListExceptionsRequest struct {
+	Status string `form:"status,omitempty"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+type UpdateExceptionRequest struct {
+	Status ExceptionStatus `json:"status" binding:"required,oneof=investigating resolved dismissed"`
+	Note   string          `json:"note,omitempty" binding:"max=500"`
+}
@@ -0,0 +1,99 @@
+package reconciliation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSettlementFile(t *testing.T) {
+	file := "settled_at,Reference,Amount\n2026-03-01,ref-1,150000\n2026-03-01, ref-2 ,75000.50\n"
+
+	records, err := ParseSettlementFile(strings.NewReader(file))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*SettlementRecord{
+		{Reference: "ref-1", Amount: 150000},
+		{Reference: "ref-2", Amount: 75000.50},
+	}, records)
+}
+
+func TestParseSettlementFile_Invalid(t *testing.T) {
+	cases := map[string]string{
+		"empty":             "",
+		"missing column":    "reference,total\nref-1,100\n",
+		"bad amount":        "reference,amount\nref-1,abc\n",
+		"negative amount":   "reference,amount\nref-1,-5\n",
+		"empty reference":   "reference,amount\n,100\n",
+		"duplicate":         "reference,amount\nref-1,100\nref-1,100\n",
+		"short row":         "amount,reference\n100\n",
+		"unterminated line": "reference,amount\n\"ref-1,100\n",
+	}
+
+	for name, file := range cases {
+		_, err := ParseSettlementFile(strings.NewReader(file))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestCompareSettlement(t *testing.T) {
+	records := []*SettlementRecord{
+		{Reference: "matched", Amount: 100000},
+		{Reference: "wrong-amount", Amount: 90000},
+		{Reference: "unknown", Amount: 5000},
+		{Reference: "pending", Amount: 20000},
+		{Reference: "yesterday", Amount: 30000},
+	}
+	day := []*LedgerEntry{
+		{Reference: "matched", Amount: 100000, Settled: true},
+		{Reference: "wrong-amount", Amount: 95000, Settled: true},
+		{Reference: "pending", Amount: 20000, Status: "pending"},
+		{Reference: "not-in-file", Amount: 40000, Settled: true},
+		{Reference: "reversed", Amount: 10000, Status: "reversed"},
+	}
+	other := []*LedgerEntry{
+		{Reference: "yesterday", Amount: 30000, Settled: true},
+	}
+
+	exceptions := CompareSettlement(SourceBillerAggregator, records, day, other)
+
+	got := map[string]ExceptionType{}
+	for _, e := range exceptions {
+		got[e.Reference] = e.Type
+		assert.Equal(t, SourceBillerAggregator, e.Source)
+		assert.Equal(t, ExceptionStatusOpen, e.Status)
+	}
+	assert.Equal(t, map[string]ExceptionType{
+		"wrong-amount": ExceptionAmountMismatch,
+		"unknown":      ExceptionMissingInLedger,
+		"pending":      ExceptionStatusMismatch,
+		"not-in-file":  ExceptionMissingInFile,
+	}, got)
+}
+
+func TestCompareLedger(t *testing.T) {
+	drifted := uuid.New()
+	exceptions := CompareLedger([]*account.LedgerBalance{
+		{AccountID: uuid.New(), AccountNumber: "1001", StoredBalance: 500, LedgerBalance: 500.004},
+		{AccountID: drifted, AccountNumber: "1002", StoredBalance: 700, LedgerBalance: 500},
+	})
+
+	assert.Len(t, exceptions, 1)
+	assert.Equal(t, ExceptionBalanceMismatch, exceptions[0].Type)
+	assert.Equal(t, "1002", exceptions[0].Reference)
+	assert.Equal(t, drifted, *exceptions[0].AccountID)
+	assert.Equal(t, 500.0, *exceptions[0].ExpectedAmount)
+	assert.Equal(t, 700.0, *exceptions[0].ActualAmount)
+}
+
+func TestCanTransition(t *testing.T) {
+	assert.True(t, CanTransition(ExceptionStatusOpen, ExceptionStatusInvestigating))
+	assert.True(t, CanTransition(ExceptionStatusOpen, ExceptionStatusDismissed))
+	assert.True(t, CanTransition(ExceptionStatusInvestigating, ExceptionStatusResolved))
+	assert.False(t, CanTransition(ExceptionStatusInvestigating, ExceptionStatusOpen))
+	assert.False(t, CanTransition(ExceptionStatusResolved, ExceptionStatusInvestigating))
+	assert.False(t, CanTransition(ExceptionStatusDismissed, ExceptionStatusResolved))
+}
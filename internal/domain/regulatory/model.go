@@ -0,0 +1,240 @@
+package regulatory
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/google/uuid"
+)
+
+type ReportType string
+type FlagSource string
+
+const (
+	// ReportTypeLargeTransaction lists completed transactions at or above the
+	// reporting threshold (LTKT)
+	ReportTypeLargeTransaction ReportType = "large_transaction"
+	// ReportTypeSuspiciousTransaction lists transactions flagged as suspicious
+	// during the period (LTKM)
+	ReportTypeSuspiciousTransaction ReportType = "suspicious_transaction"
+
+	FlagSourceFraudRule FlagSource = "fraud_rule" // raised by automated fraud screening
+	FlagSourceManual    FlagSource = "manual"     // raised by a compliance officer
+)
+
+const (
+	// DefaultLargeTransactionThreshold is the regulator's reporting threshold of Rp 500 million
+	DefaultLargeTransactionThreshold = 500000000
+
+	// MaxReportPeriodDays bounds a manually requested report
+	MaxReportPeriodDays = 31
+
+	// DateLayout is the format of report period dates
+	DateLayout = "2006-01-02"
+
+	// submissionCode marks reports submitted electronically
+	submissionCode = "E"
+)
+
+var reportCodes = map[ReportType]string{
+	ReportTypeLargeTransaction:      "LTKT",
+	ReportTypeSuspiciousTransaction: "LTKM",
+}
+
+// Code is the regulator's code for the report type
+func (t ReportType) Code() string {
+	return reportCodes[t]
+}
+
+func IsValidReportType(t ReportType) bool {
+	_, ok := reportCodes[t]
+	return ok
+}
+
+// Thresholds are the amounts at or above which a completed transaction is
+// reported. PerType overrides Default for a transaction type.
+type Thresholds struct {
+	Default float64
+	PerType map[transaction.TransactionType]float64
+}
+
+func DefaultThresholds() Thresholds {
+	return Thresholds{
+		Default: DefaultLargeTransactionThreshold,
+		PerType: map[transaction.TransactionType]float64{},
+	}
+}
+
+// For returns the threshold for a transaction type
+func (t Thresholds) For(txnType transaction.TransactionType) float64 {
+	if v, ok := t.PerType[txnType]; ok {
+		return v
+	}
+	return t.Default
+}
+
+// Min returns the lowest threshold of any transaction type, used to narrow the
+// candidates loaded from the database
+func (t Thresholds) Min() float64 {
+	min := t.Default
+	for _, v := range t.PerType {
+		if v < min {
+			min = v
+		}
+	}
+	return min
+}
+
+// Flag marks a transaction as suspicious for the next suspicious transaction report
+type Flag struct {
+	ID            uuid.UUID  `json:"id"`
+	TransactionID uuid.UUID  `json:"transaction_id"`
+	Source        FlagSource `json:"source"`
+	Reason        string     `json:"reason"`
+	FlaggedBy     *uuid.UUID `json:"flagged_by,omitempty"` // nil for automated flags
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// Party is the customer on one side of a reported transaction
+type Party struct {
+	AccountID     uuid.UUID
+	AccountNumber string
+	HolderName    string
+}
+
+// Item is one reported transaction
+type Item struct {
+	TransactionID   uuid.UUID
+	TransactionType transaction.TransactionType
+	Amount          float64
+	Status          transaction.TransactionStatus
+	Description     string
+	PostedAt        time.Time
+	From            *Party // nil for cash deposits and bank-originated credits
+	To              *Party // nil for cash withdrawals and bank fees
+	Reasons         []string
+}
+
+// Report is a generated report file for a period of whole days
+type Report struct {
+	ID          uuid.UUID  `json:"id"`
+	Type        ReportType `json:"type"`
+	PeriodStart time.Time  `json:"period_start"`
+	PeriodEnd   time.Time  `json:"period_end"` // inclusive
+	ItemCount   int        `json:"item_count"`
+	TotalAmount float64    `json:"total_amount"`
+	FileName    string     `json:"file_name"`
+	GeneratedBy *uuid.UUID `json:"generated_by,omitempty"` // nil for scheduled reports
+	CreatedAt   time.Time  `json:"created_at"`
+
+	Content []byte `json:"-"`
+}
+
+// SelectLarge keeps the candidates at or above their type's threshold and
+// records why each one is reported
+func SelectLarge(candidates []*Item, thresholds Thresholds) []*Item {
+	items := []*Item{}
+	for _, item := range candidates {
+		threshold := thresholds.For(item.TransactionType)
+		if item.Amount < threshold {
+			continue
+		}
+		item.Reasons = []string{fmt.Sprintf("amount %s at or above reporting threshold %s",
+			formatAmount(item.Amount), formatAmount(threshold))}
+		items = append(items, item)
+	}
+	return items
+}
+
+// FileName names a report file by its code and period, e.g. LTKT-20260301-20260301.xml
+func FileName(t ReportType, periodStart, periodEnd time.Time) string {
+	return fmt.Sprintf("%s-%s-%s.xml", t.Code(), periodStart.Format("20060102"), periodEnd.Format("20060102"))
+}
+
+type xmlReport struct {
+	XMLName           xml.Name         `xml:"report"`
+	EntityID          string           `xml:"rentity_id"`
+	SubmissionCode    string           `xml:"submission_code"`
+	ReportCode        string           `xml:"report_code"`
+	EntityReference   string           `xml:"entity_reference"`
+	SubmissionDate    string           `xml:"submission_date"`
+	CurrencyCodeLocal string           `xml:"currency_code_local"`
+	PeriodFrom        string           `xml:"reporting_period>from"`
+	PeriodTo          string           `xml:"reporting_period>to"`
+	Transactions      []xmlTransaction `xml:"transaction"`
+}
+
+type xmlTransaction struct {
+	TransactionNumber string      `xml:"transactionnumber"`
+	Description       string      `xml:"transaction_description,omitempty"`
+	Date              string      `xml:"date_transaction"`
+	TransmodeCode     string      `xml:"transmode_code"`
+	AmountLocal       string      `xml:"amount_local"`
+	From              *xmlAccount `xml:"t_from_my_client>from_account,omitempty"`
+	To                *xmlAccount `xml:"t_to_my_client>to_account,omitempty"`
+	Comments          string      `xml:"comments,omitempty"`
+}
+
+type xmlAccount struct {
+	Account     string `xml:"account"`
+	AccountName string `xml:"account_name"`
+}
+
+// Render writes the report in the regulator's goAML XML layout
+func (r *Report) Render(entityID, currency string, items []*Item, generatedAt time.Time) ([]byte, error) {
+	doc := xmlReport{
+		EntityID:          entityID,
+		SubmissionCode:    submissionCode,
+		ReportCode:        r.Type.Code(),
+		EntityReference:   r.ID.String(),
+		SubmissionDate:    generatedAt.Format("2006-01-02T15:04:05"),
+		CurrencyCodeLocal: currency,
+		PeriodFrom:        r.PeriodStart.Format(DateLayout),
+		PeriodTo:          r.PeriodEnd.Format(DateLayout),
+		Transactions:      make([]xmlTransaction, 0, len(items)),
+	}
+
+	for _, item := range items {
+		doc.Transactions = append(doc.Transactions, xmlTransaction{
+			TransactionNumber: item.TransactionID.String(),
+			Description:       item.Description,
+			Date:              item.PostedAt.Format("2006-01-02T15:04:05"),
+			TransmodeCode:     string(item.TransactionType),
+			AmountLocal:       formatAmount(item.Amount),
+			From:              xmlParty(item.From),
+			To:                xmlParty(item.To),
+			Comments:          strings.Join(item.Reasons, "; "),
+		})
+	}
+
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to render report: %w", err)
+	}
+	return append([]byte(xml.Header), out...), nil
+}
+
+func xmlParty(p *Party) *xmlAccount {
+	if p == nil {
+		return nil
+	}
+	return &xmlAccount{Account: p.AccountNumber, AccountName: p.HolderName}
+}
+
+func formatAmount(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+type GenerateReportRequest struct {
+	Type ReportType `json:"type" binding:"required,oneof=large_transaction suspicious_transaction"`
+	From string     `json:"from" binding:"required"`
+	To   string     `json:"to" binding:"required"`
+}
+
+type FlagTransactionRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
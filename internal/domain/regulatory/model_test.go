@@ -0,0 +1,75 @@
+package regulatory
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThresholds(t *testing.T) {
+	thresholds := Thresholds{
+		Default: 500000000,
+		PerType: map[transaction.TransactionType]float64{transaction.TransactionTypeTransfer: 100000000},
+	}
+
+	assert.Equal(t, 100000000.0, thresholds.For(transaction.TransactionTypeTransfer))
+	assert.Equal(t, 500000000.0, thresholds.For(transaction.TransactionTypeDeposit))
+	assert.Equal(t, 100000000.0, thresholds.Min())
+	assert.Equal(t, float64(DefaultLargeTransactionThreshold), DefaultThresholds().Min())
+}
+
+func TestSelectLarge(t *testing.T) {
+	thresholds := Thresholds{
+		Default: 500000000,
+		PerType: map[transaction.TransactionType]float64{transaction.TransactionTypeTransfer: 100000000},
+	}
+	transfer := &Item{TransactionID: uuid.New(), TransactionType: transaction.TransactionTypeTransfer, Amount: 150000000}
+	smallDeposit := &Item{TransactionID: uuid.New(), TransactionType: transaction.TransactionTypeDeposit, Amount: 150000000}
+	largeDeposit := &Item{TransactionID: uuid.New(), TransactionType: transaction.TransactionTypeDeposit, Amount: 500000000}
+
+	items := SelectLarge([]*Item{transfer, smallDeposit, largeDeposit}, thresholds)
+
+	assert.Equal(t, []*Item{transfer, largeDeposit}, items)
+	assert.Equal(t, []string{"amount 150000000.00 at or above reporting threshold 100000000.00"}, transfer.Reasons)
+}
+
+func TestReportTypeCode(t *testing.T) {
+	assert.Equal(t, "LTKT", ReportTypeLargeTransaction.Code())
+	assert.Equal(t, "LTKM", ReportTypeSuspiciousTransaction.Code())
+	assert.True(t, IsValidReportType(ReportTypeSuspiciousTransaction))
+	assert.False(t, IsValidReportType("cash"))
+}
+
+func TestFileName(t *testing.T) {
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, "LTKM-20260301-20260307.xml", FileName(ReportTypeSuspiciousTransaction, day, day.AddDate(0, 0, 6)))
+}
+
+func TestReport_Render(t *testing.T) {
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	report := &Report{ID: uuid.New(), Type: ReportTypeSuspiciousTransaction, PeriodStart: day, PeriodEnd: day}
+	items := []*Item{{
+		TransactionID:   uuid.New(),
+		TransactionType: transaction.TransactionTypeWithdrawal,
+		Amount:          75000000,
+		PostedAt:        day.Add(10 * time.Hour),
+		From:            &Party{AccountID: uuid.New(), AccountNumber: "1234567890", HolderName: "Budi & Sons"},
+		Reasons:         []string{"manual: structuring", "fraud_rule: velocity"},
+	}}
+
+	out, err := report.Render("BANK-001", "IDR", items, day.AddDate(0, 0, 1))
+	doc := string(out)
+
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(doc, "<?xml"))
+	assert.Contains(t, doc, "<rentity_id>BANK-001</rentity_id>")
+	assert.Contains(t, doc, "<report_code>LTKM</report_code>")
+	assert.Contains(t, doc, "<amount_local>75000000.00</amount_local>")
+	assert.Contains(t, doc, "<account_name>Budi &amp; Sons</account_name>")
+	assert.Contains(t, doc, "<comments>manual: structuring; fraud_rule: velocity</comments>")
+	assert.NotContains(t, doc, "t_to_my_client")
+}
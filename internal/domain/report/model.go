@@ -0,0 +1,78 @@
+package report
+
+import (
+	"math"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+)
+
+// DateLayout is the format of report dates
+const DateLayout = "2006-01-02"
+
+type DailySummaryRequest struct {
+	Date string `form:"date" binding:"required"`
+}
+
+// TypeTotal sums the completed transactions of one type and currency. Fees
+// is the fee income they carry: the whole amount of fee transactions, and
+// the admin, merchant discount and provision fees recorded on top-ups,
+// merchant settlements and loan disbursements.
+type TypeTotal struct {
+	TransactionType transaction.TransactionType `json:"transaction_type"`
+	Currency        string                      `json:"currency"`
+	Count           int                         `json:"count"`
+	Amount          float64                     `json:"amount"`
+	Fees            float64                     `json:"fees,omitempty"`
+}
+
+// FailedCount counts the failed transactions of one type
+type FailedCount struct {
+	TransactionType transaction.TransactionType `json:"transaction_type"`
+	Count           int                         `json:"count"`
+}
+
+// DailySummary is a business day's settlement summary for the operations
+// morning check. Completed transactions count on the day they completed,
+// failed ones on the day they were created. Money is keyed by currency.
+type DailySummary struct {
+	Date         string             `json:"date"`
+	TimeZone     string             `json:"time_zone"`
+	GeneratedAt  time.Time          `json:"generated_at"`
+	Totals       []*TypeTotal       `json:"totals"`
+	FeeRevenue   map[string]float64 `json:"fee_revenue"`
+	InterestPaid map[string]float64 `json:"interest_paid"`
+	Failed       []*FailedCount     `json:"failed"`
+	FailedTotal  int                `json:"failed_total"`
+}
+
+// BuildDailySummary adds up the day's per-type totals and failed counts
+func BuildDailySummary(day time.Time, totals []*TypeTotal, failed []*FailedCount, generatedAt time.Time) *DailySummary {
+	s := &DailySummary{
+		Date:         day.Format(DateLayout),
+		TimeZone:     day.Location().String(),
+		GeneratedAt:  generatedAt,
+		Totals:       totals,
+		FeeRevenue:   map[string]float64{},
+		InterestPaid: map[string]float64{},
+		Failed:       failed,
+	}
+
+	for _, t := range totals {
+		if t.Fees != 0 {
+			s.FeeRevenue[t.Currency] = roundAmount(s.FeeRevenue[t.Currency] + t.Fees)
+		}
+		if t.TransactionType == transaction.TransactionTypeInterest {
+			s.InterestPaid[t.Currency] = roundAmount(s.InterestPaid[t.Currency] + t.Amount)
+		}
+	}
+	for _, f := range failed {
+		s.FailedTotal += f.Count
+	}
+
+	return s
+}
+
+func roundAmount(v float64) float64 {
+	return math.Round(v*100) / 100
+}
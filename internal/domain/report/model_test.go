@@ -0,0 +1,39 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildDailySummary(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, jakarta)
+
+	summary := BuildDailySummary(day, []*TypeTotal{
+		{TransactionType: transaction.TransactionTypeDeposit, Currency: "IDR", Count: 3, Amount: 1500000},
+		{TransactionType: transaction.TransactionTypeFee, Currency: "IDR", Count: 2, Amount: 13000, Fees: 13000},
+		{TransactionType: transaction.TransactionTypeTopup, Currency: "IDR", Count: 1, Amount: 51500, Fees: 1500},
+		{TransactionType: transaction.TransactionTypeInterest, Currency: "IDR", Count: 4, Amount: 820.25},
+		{TransactionType: transaction.TransactionTypeInterest, Currency: "USD", Count: 1, Amount: 0.12},
+	}, []*FailedCount{
+		{TransactionType: transaction.TransactionTypeTransfer, Count: 2},
+		{TransactionType: transaction.TransactionTypeBillPayment, Count: 1},
+	}, time.Now())
+
+	assert.Equal(t, "2026-03-01", summary.Date)
+	assert.Equal(t, "WIB", summary.TimeZone)
+	assert.Equal(t, map[string]float64{"IDR": 14500}, summary.FeeRevenue)
+	assert.Equal(t, map[string]float64{"IDR": 820.25, "USD": 0.12}, summary.InterestPaid)
+	assert.Equal(t, 3, summary.FailedTotal)
+}
+
+func TestBuildDailySummary_QuietDay(t *testing.T) {
+	summary := BuildDailySummary(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), nil, nil, time.Now())
+
+	assert.Empty(t, summary.FeeRevenue)
+	assert.Empty(t, summary.InterestPaid)
+	assert.Zero(t, summary.FailedTotal)
+}
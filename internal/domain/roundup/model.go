@@ -0,0 +1,39 @@
+package roundup
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Increment is the amount debits are rounded up to, in IDR
+const Increment = 1000
+
+// Rule moves the spare change of every debit of an account to a savings
+// account of the same customer
+type Rule struct {
+	ID               uuid.UUID `json:"id"`
+	UserID           uuid.UUID `json:"user_id"`
+	AccountID        uuid.UUID `json:"account_id"`
+	SavingsAccountID uuid.UUID `json:"savings_account_id"`
+	TotalSaved       float64   `json:"total_saved"` // sum of all round-ups moved so far
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+type EnableRequest struct {
+	SavingsAccountID string `json:"savings_account_id" binding:"required,uuid"`
+}
+
+// Amount returns how much a debit is short of the next multiple of
+// Increment, or 0 when it already is one
+func Amount(debit float64) float64 {
+	cents := math.Round(debit * 100)
+	step := float64(Increment * 100)
+	rest := math.Mod(cents, step)
+	if cents <= 0 || rest == 0 {
+		return 0
+	}
+	return (step - rest) / 100
+}
@@ -0,0 +1,25 @@
+package roundup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAmount(t *testing.T) {
+	tests := []struct {
+		debit float64
+		want  float64
+	}{
+		{debit: 12500, want: 500},
+		{debit: 999, want: 1},
+		{debit: 15000, want: 0},
+		{debit: 24999.99, want: 0.01},
+		{debit: 1000.5, want: 999.5},
+		{debit: 0, want: 0},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Amount(tt.debit), "debit %.2f", tt.debit)
+	}
+}
@@ -0,0 +1,102 @@
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Status string
+
+const (
+	StatusRunning      Status = "running"
+	StatusCompleted    Status = "completed"
+	StatusCompensating Status = "compensating"
+	StatusCompensated  Status = "compensated" // aborted, every completed step undone
+	StatusFailed       Status = "failed"      // attempts exhausted, waiting for an operator
+)
+
+const (
+	DefaultMaxAttempts = 10
+
+	// baseRetryDelay and maxRetryDelay bound the delay before a failed step is retried
+	baseRetryDelay = 30 * time.Second
+	maxRetryDelay  = time.Hour
+)
+
+// Saga is the persisted state of one multi-leg operation
+type Saga struct {
+	ID            uuid.UUID       `json:"id"`
+	Kind          string          `json:"kind"`
+	Status        Status          `json:"status"`
+	Data          json.RawMessage `json:"data"`
+	Step          int             `json:"step"`
+	Attempts      int             `json:"attempts"` // failed attempts of the current step
+	LastError     string          `json:"last_error,omitempty"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+	LockedUntil   *time.Time      `json:"locked_until,omitempty"`
+	CompletedAt   *time.Time      `json:"completed_at,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+// Finished reports whether the saga has nothing left to run
+func (s *Saga) Finished() bool {
+	return s.Status == StatusCompleted || s.Status == StatusCompensated || s.Status == StatusFailed
+}
+
+// Step is one leg of a saga. A crash can interrupt a step after its effect
+// but before the saga records it, so Action and Compensate must be safe to
+// run again.
+type Step struct {
+	Name string
+	// Action performs the step and may return updated saga data, or nil to
+	// keep it. Errors wrapped with Abort compensate the saga; any other error
+	// retries the step later.
+	Action func(ctx context.Context, data json.RawMessage) (json.RawMessage, error)
+	// Compensate undoes a completed step; nil when there is nothing to undo.
+	// cause is the error that aborted the saga.
+	Compensate func(ctx context.Context, data json.RawMessage, cause string) error
+}
+
+// Definition is the ordered steps of one kind of saga
+type Definition struct {
+	Kind        string
+	Steps       []Step
+	MaxAttempts int // per step, defaults to DefaultMaxAttempts
+}
+
+type abortError struct {
+	err error
+}
+
+func (e *abortError) Error() string { return e.err.Error() }
+func (e *abortError) Unwrap() error { return e.err }
+
+// Abort marks a step error as final: the step had no effect and retrying
+// cannot succeed, so the saga undoes the steps before it
+func Abort(err error) error {
+	return &abortError{err: err}
+}
+
+// IsAbort reports whether err was wrapped with Abort
+func IsAbort(err error) bool {
+	var abort *abortError
+	return errors.As(err, &abort)
+}
+
+// RetryDelay is the delay before retrying a step that failed its nth attempt:
+// 30s, 1m, 2m, ... capped at an hour
+func RetryDelay(attempt int) time.Duration {
+	delay := baseRetryDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= maxRetryDelay {
+			return maxRetryDelay
+		}
+	}
+	return delay
+}
@@ -0,0 +1,34 @@
+package saga
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAbort(t *testing.T) {
+	cause := errors.New("payment rejected by biller")
+	err := Abort(cause)
+
+	assert.True(t, IsAbort(err))
+	assert.True(t, IsAbort(fmt.Errorf("pay_biller: %w", err)))
+	assert.False(t, IsAbort(cause))
+	assert.ErrorIs(t, err, cause)
+	assert.Equal(t, "payment rejected by biller", err.Error())
+}
+
+func TestRetryDelay(t *testing.T) {
+	assert.Equal(t, 30*time.Second, RetryDelay(1))
+	assert.Equal(t, 2*time.Minute, RetryDelay(3))
+	assert.Equal(t, time.Hour, RetryDelay(20))
+}
+
+func TestFinished(t *testing.T) {
+	assert.False(t, (&Saga{Status: StatusRunning}).Finished())
+	assert.False(t, (&Saga{Status: StatusCompensating}).Finished())
+	assert.True(t, (&Saga{Status: StatusCompensated}).Finished())
+	assert.True(t, (&Saga{Status: StatusFailed}).Finished())
+}
@@ -0,0 +1,179 @@
+package statement
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/google/uuid"
+)
+
+type DeliveryStatus string
+
+const (
+	DeliveryStatusPending DeliveryStatus = "pending"
+	DeliveryStatusSent    DeliveryStatus = "sent"
+	DeliveryStatusFailed  DeliveryStatus = "failed"
+)
+
+const (
+	// PeriodLayout is the format of a statement period, a calendar month
+	PeriodLayout = "2006-01"
+
+	// MaxDeliveryAttempts is how many times the scheduled run tries to email
+	// a statement before leaving it to a manual re-send
+	MaxDeliveryAttempts = 3
+)
+
+// Subscription opts an account in to monthly statement emails. An empty
+// Email sends to the address on the user's profile at the time of sending.
+type Subscription struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	AccountID uuid.UUID `json:"account_id"`
+	Email     string    `json:"email,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Delivery tracks the email of one account's statement for one month
+type Delivery struct {
+	ID        uuid.UUID      `json:"id"`
+	UserID    uuid.UUID      `json:"user_id"`
+	AccountID uuid.UUID      `json:"account_id"`
+	Period    string         `json:"period"`
+	Email     string         `json:"email,omitempty"`
+	Status    DeliveryStatus `json:"status"`
+	Attempts  int            `json:"attempts"`
+	LastError string         `json:"last_error,omitempty"`
+	SentAt    *time.Time     `json:"sent_at,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+type SubscribeRequest struct {
+	Email string `json:"email" binding:"omitempty,email,max=255"`
+}
+
+// Line is one transaction on a statement
+type Line struct {
+	TransactionID uuid.UUID                   `json:"transaction_id"`
+	Date          time.Time                   `json:"date"`
+	Type          transaction.TransactionType `json:"transaction_type"`
+	Description   string                      `json:"description"`
+	Credit        float64                     `json:"credit"`
+	Debit         float64                     `json:"debit"`
+	Balance       float64                     `json:"balance"`
+}
+
+// Statement is an account's activity over one calendar month
+type Statement struct {
+	AccountNumber  string    `json:"account_number"`
+	HolderName     string    `json:"holder_name"`
+	Currency       string    `json:"currency"`
+	Period         string    `json:"period"`
+	PeriodStart    time.Time `json:"period_start"`
+	PeriodEnd      time.Time `json:"period_end"`
+	OpeningBalance float64   `json:"opening_balance"`
+	TotalCredits   float64   `json:"total_credits"`
+	TotalDebits    float64   `json:"total_debits"`
+	ClosingBalance float64   `json:"closing_balance"`
+	Lines          []Line    `json:"lines"`
+}
+
+// PreviousPeriod is the last full month before now, in zone
+func PreviousPeriod(now time.Time, zone *time.Location) string {
+	now = now.In(zone)
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, zone).AddDate(0, -1, 0).Format(PeriodLayout)
+}
+
+// PeriodBounds returns the start of the month and the start of the next,
+// at midnight in zone
+func PeriodBounds(period string, zone *time.Location) (time.Time, time.Time, error) {
+	start, err := time.ParseInLocation(PeriodLayout, period, zone)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid statement period %q, expected YYYY-MM", period)
+	}
+	return start, start.AddDate(0, 1, 0), nil
+}
+
+// Build assembles the statement of acct for the month [start, end). txns are
+// the account's completed transactions from start until now, in posting
+// order; the balances are worked back from the account's current balance, so
+// anything posted after end is taken out of the closing balance.
+func Build(acct *account.Account, holderName, period string, start, end time.Time, txns []*transaction.Transaction) *Statement {
+	s := &Statement{
+		AccountNumber: acct.AccountNumber,
+		HolderName:    holderName,
+		Currency:      acct.Currency,
+		Period:        period,
+		PeriodStart:   start,
+		PeriodEnd:     end,
+		Lines:         []Line{},
+	}
+
+	closing := acct.Balance
+	for _, txn := range txns {
+		credit, debit := amounts(acct.ID, txn)
+		if !postedAt(txn).Before(end) {
+			closing -= credit - debit
+			continue
+		}
+		s.TotalCredits += credit
+		s.TotalDebits += debit
+		s.Lines = append(s.Lines, Line{
+			TransactionID: txn.ID,
+			Date:          postedAt(txn),
+			Type:          txn.TransactionType,
+			Description:   txn.Description,
+			Credit:        credit,
+			Debit:         debit,
+		})
+	}
+
+	s.ClosingBalance = round(closing)
+	s.OpeningBalance = round(closing - s.TotalCredits + s.TotalDebits)
+	s.TotalCredits = round(s.TotalCredits)
+	s.TotalDebits = round(s.TotalDebits)
+
+	balance := s.OpeningBalance
+	for i := range s.Lines {
+		balance += s.Lines[i].Credit - s.Lines[i].Debit
+		s.Lines[i].Balance = round(balance)
+	}
+
+	return s
+}
+
+// MonthName is the period as shown to customers, e.g. "February 2026"
+func (s *Statement) MonthName() string {
+	return s.PeriodStart.Format("January 2006")
+}
+
+// FileName names the statement PDF
+func (s *Statement) FileName() string {
+	return fmt.Sprintf("statement-%s-%s.pdf", s.AccountNumber, s.Period)
+}
+
+func amounts(accountID uuid.UUID, txn *transaction.Transaction) (credit, debit float64) {
+	if txn.ToAccountID != nil && *txn.ToAccountID == accountID {
+		credit = txn.Amount
+	}
+	if txn.FromAccountID != nil && *txn.FromAccountID == accountID {
+		debit = txn.Amount
+	}
+	return credit, debit
+}
+
+func postedAt(txn *transaction.Transaction) time.Time {
+	if txn.CompletedAt != nil {
+		return *txn.CompletedAt
+	}
+	return txn.CreatedAt
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,91 @@
+package statement
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var jakarta = time.FixedZone("WIB", 7*3600)
+
+func TestPreviousPeriod(t *testing.T) {
+	// 1 March 01:00 in Jakarta is still February in UTC
+	now := time.Date(2026, 2, 28, 18, 0, 0, 0, time.UTC)
+	assert.Equal(t, "2026-02", PreviousPeriod(now, jakarta))
+	assert.Equal(t, "2026-01", PreviousPeriod(now, time.UTC))
+
+	assert.Equal(t, "2025-12", PreviousPeriod(time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC), time.UTC))
+}
+
+func TestPeriodBounds(t *testing.T) {
+	start, end, err := PeriodBounds("2026-02", jakarta)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 2, 1, 0, 0, 0, 0, jakarta), start)
+	assert.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, jakarta), end)
+
+	_, _, err = PeriodBounds("February", jakarta)
+	assert.Error(t, err)
+}
+
+func TestBuild(t *testing.T) {
+	acct := &account.Account{ID: uuid.New(), AccountNumber: "1000000001", Currency: "IDR", Balance: 900000}
+	other := uuid.New()
+	start, end, _ := PeriodBounds("2026-02", time.UTC)
+	at := func(day int) *time.Time {
+		t := time.Date(2026, 2, day, 10, 0, 0, 0, time.UTC)
+		return &t
+	}
+	txns := []*transaction.Transaction{
+		{ID: uuid.New(), ToAccountID: &acct.ID, Amount: 1000000, TransactionType: transaction.TransactionTypeDeposit, CompletedAt: at(3)},
+		{ID: uuid.New(), FromAccountID: &acct.ID, ToAccountID: &other, Amount: 250000, TransactionType: transaction.TransactionTypeTransfer, Description: "Rent", CompletedAt: at(10)},
+		// posted after the period, taken out of the closing balance
+		{ID: uuid.New(), FromAccountID: &acct.ID, Amount: 100000, TransactionType: transaction.TransactionTypeWithdrawal, CompletedAt: at(31)},
+	}
+
+	s := Build(acct, "Budi Santoso", "2026-02", start, end, txns)
+
+	assert.Equal(t, 1000000.0, s.ClosingBalance)
+	assert.Equal(t, 250000.0, s.OpeningBalance)
+	assert.Equal(t, 1000000.0, s.TotalCredits)
+	assert.Equal(t, 250000.0, s.TotalDebits)
+	if assert.Len(t, s.Lines, 2) {
+		assert.Equal(t, 1250000.0, s.Lines[0].Balance)
+		assert.Equal(t, 250000.0, s.Lines[1].Debit)
+		assert.Equal(t, 1000000.0, s.Lines[1].Balance)
+	}
+	assert.Equal(t, "February 2026", s.MonthName())
+	assert.Equal(t, "statement-1000000001-2026-02.pdf", s.FileName())
+}
+
+func TestStatement_Render(t *testing.T) {
+	start, end, _ := PeriodBounds("2026-02", jakarta)
+	s := &Statement{
+		AccountNumber: "1000000001", HolderName: "Budi Santoso", Currency: "IDR", Period: "2026-02",
+		PeriodStart: start, PeriodEnd: end, OpeningBalance: 250000, ClosingBalance: 250000,
+	}
+
+	out := string(s.Render("MadaBank", jakarta))
+	assert.Contains(t, out, "(Account Statement - February 2026)")
+	assert.Contains(t, out, "(01 Feb 2026 - 28 Feb 2026)")
+	assert.Contains(t, out, "(******0001)")
+	assert.Contains(t, out, "(No transactions in this period.)")
+	assert.Contains(t, out, "/Count 1")
+
+	for i := 0; i < 60; i++ {
+		s.Lines = append(s.Lines, Line{Date: start.Add(time.Duration(i) * time.Hour), Type: transaction.TransactionTypeDeposit, Description: fmt.Sprintf("Top up %d", i), Credit: 1000, Balance: 1000})
+	}
+	out = string(s.Render("MadaBank", jakarta))
+	assert.Contains(t, out, "/Count 2")
+	assert.Contains(t, out, "(Top up 59)")
+	assert.Contains(t, out, `\(continued\)`)
+}
+
+func TestDescribe(t *testing.T) {
+	assert.Equal(t, "bill payment", describe(Line{Type: transaction.TransactionTypeBillPayment}))
+	assert.Equal(t, "Monthly rent for the apartment in...", describe(Line{Description: "Monthly rent for the apartment in South Jakarta"}))
+}
@@ -0,0 +1,104 @@
+package statement
+
+import (
+	"strings"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/pkg/pdf"
+)
+
+const (
+	marginLeft   = 48.0
+	marginRight  = pdf.PageWidth - 48
+	marginBottom = pdf.PageHeight - 64
+	rowHeight    = 16.0
+
+	// right edges of the amount columns
+	colDebit   = 370.0
+	colCredit  = 460.0
+	colBalance = marginRight
+
+	maxDescriptionLen = 36
+)
+
+// Render draws the statement as a PDF, continuing the transaction table over
+// as many pages as needed. Dates are shown in zone.
+func (s *Statement) Render(bankName string, zone *time.Location) []byte {
+	doc := pdf.New()
+
+	doc.Text(marginLeft, 64, 20, true, bankName)
+	doc.Text(marginLeft, 88, 12, false, "Account Statement - "+s.MonthName())
+	doc.Line(marginLeft, 100, marginRight, 100, 1)
+
+	details := [][2]string{
+		{"Account holder", s.HolderName},
+		{"Account number", transaction.MaskAccountNumber(s.AccountNumber)},
+		{"Period", s.PeriodStart.In(zone).Format("02 Jan 2006") + " - " + s.PeriodEnd.In(zone).AddDate(0, 0, -1).Format("02 Jan 2006")},
+		{"Opening balance", transaction.FormatMoney(s.Currency, s.OpeningBalance)},
+		{"Money in", transaction.FormatMoney(s.Currency, s.TotalCredits)},
+		{"Money out", transaction.FormatMoney(s.Currency, s.TotalDebits)},
+		{"Closing balance", transaction.FormatMoney(s.Currency, s.ClosingBalance)},
+	}
+	y := 128.0
+	for _, d := range details {
+		doc.Text(marginLeft, y, 10, false, d[0])
+		doc.TextRight(marginRight, y, 10, true, d[1])
+		y += rowHeight
+	}
+
+	y = tableHeader(doc, y+16)
+	if len(s.Lines) == 0 {
+		doc.Text(marginLeft, y, 9, false, "No transactions in this period.")
+	}
+	for _, line := range s.Lines {
+		if y > marginBottom {
+			doc.AddPage()
+			doc.Text(marginLeft, 48, 9, false, bankName+" - Account Statement - "+s.MonthName()+" (continued)")
+			y = tableHeader(doc, 72)
+		}
+
+		doc.Text(marginLeft, y, 9, false, line.Date.In(zone).Format("02 Jan"))
+		doc.Text(marginLeft+48, y, 9, false, describe(line))
+		if line.Debit > 0 {
+			doc.TextRight(colDebit, y, 9, false, amount(line.Debit))
+		}
+		if line.Credit > 0 {
+			doc.TextRight(colCredit, y, 9, false, amount(line.Credit))
+		}
+		doc.TextRight(colBalance, y, 9, false, amount(line.Balance))
+		y += rowHeight
+	}
+
+	doc.Line(marginLeft, y, marginRight, y, 0.5)
+	doc.Text(marginLeft, y+20, 8, false, "This statement is generated electronically and is valid without a signature.")
+
+	return doc.Bytes()
+}
+
+// tableHeader draws the column titles at y and returns where the first row goes
+func tableHeader(doc *pdf.Document, y float64) float64 {
+	doc.Text(marginLeft, y, 9, true, "Date")
+	doc.Text(marginLeft+48, y, 9, true, "Description")
+	doc.TextRight(colDebit, y, 9, true, "Money out")
+	doc.TextRight(colCredit, y, 9, true, "Money in")
+	doc.TextRight(colBalance, y, 9, true, "Balance")
+	doc.Line(marginLeft, y+6, marginRight, y+6, 0.5)
+	return y + 22
+}
+
+func describe(line Line) string {
+	d := line.Description
+	if d == "" {
+		d = strings.ReplaceAll(string(line.Type), "_", " ")
+	}
+	if r := []rune(d); len(r) > maxDescriptionLen {
+		d = string(r[:maxDescriptionLen-3]) + "..."
+	}
+	return d
+}
+
+// amount formats a table amount without the currency, which is in the summary
+func amount(v float64) string {
+	return strings.TrimSpace(transaction.FormatMoney("", v))
+}
@@ -0,0 +1,184 @@
+package topup
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/validation"
+	"github.com/google/uuid"
+)
+
+type Type string
+type Status string
+type Provider string
+
+const (
+	TypePhoneCredit Type = "phone_credit"
+	TypeEWallet     Type = "ewallet"
+
+	// StatusPending: the account is debited and the order is not yet accepted by the aggregator
+	StatusPending Status = "pending"
+	// StatusProcessing: the aggregator accepted the order and is delivering it
+	StatusProcessing Status = "processing"
+	StatusSuccess    Status = "success"
+	StatusFailed     Status = "failed"
+)
+
+// Mobile operators
+const (
+	ProviderTelkomsel Provider = "telkomsel"
+	ProviderIndosat   Provider = "indosat"
+	ProviderXL        Provider = "xl"
+	ProviderAxis      Provider = "axis"
+	ProviderTri       Provider = "tri"
+	ProviderSmartfren Provider = "smartfren"
+)
+
+// E-wallets, addressed by the phone number registered with the wallet
+const (
+	ProviderGoPay     Provider = "gopay"
+	ProviderOVO       Provider = "ovo"
+	ProviderDANA      Provider = "dana"
+	ProviderShopeePay Provider = "shopeepay"
+	ProviderLinkAja   Provider = "linkaja"
+)
+
+// Admin fees charged on top of the top-up amount (IDR)
+const (
+	PhoneCreditAdminFee = 1500
+	EWalletAdminFee     = 1000
+
+	MinEWalletAmount = 10_000
+	MaxEWalletAmount = 2_000_000
+)
+
+// PhoneCreditDenominations are the phone credit amounts operators sell
+var PhoneCreditDenominations = []float64{5_000, 10_000, 20_000, 25_000, 50_000, 100_000, 150_000, 200_000, 300_000, 500_000, 1_000_000}
+
+// operatorPrefixes maps the first four digits of a local number to its operator
+var operatorPrefixes = map[string]Provider{
+	"0811": ProviderTelkomsel, "0812": ProviderTelkomsel, "0813": ProviderTelkomsel,
+	"0821": ProviderTelkomsel, "0822": ProviderTelkomsel, "0823": ProviderTelkomsel,
+	"0851": ProviderTelkomsel, "0852": ProviderTelkomsel, "0853": ProviderTelkomsel,
+	"0814": ProviderIndosat, "0815": ProviderIndosat, "0816": ProviderIndosat,
+	"0855": ProviderIndosat, "0856": ProviderIndosat, "0857": ProviderIndosat, "0858": ProviderIndosat,
+	"0817": ProviderXL, "0818": ProviderXL, "0819": ProviderXL,
+	"0859": ProviderXL, "0877": ProviderXL, "0878": ProviderXL,
+	"0831": ProviderAxis, "0832": ProviderAxis, "0833": ProviderAxis, "0838": ProviderAxis,
+	"0895": ProviderTri, "0896": ProviderTri, "0897": ProviderTri, "0898": ProviderTri, "0899": ProviderTri,
+	"0881": ProviderSmartfren, "0882": ProviderSmartfren, "0883": ProviderSmartfren, "0884": ProviderSmartfren,
+	"0885": ProviderSmartfren, "0886": ProviderSmartfren, "0887": ProviderSmartfren, "0888": ProviderSmartfren,
+	"0889": ProviderSmartfren,
+}
+
+type Topup struct {
+	ID            uuid.UUID  `json:"id"`
+	UserID        uuid.UUID  `json:"user_id"`
+	AccountID     uuid.UUID  `json:"account_id"`
+	TransactionID uuid.UUID  `json:"transaction_id"`
+	Type          Type       `json:"type"`
+	Provider      Provider   `json:"provider"`
+	Destination   string     `json:"destination"`
+	Amount        float64    `json:"amount"`
+	AdminFee      float64    `json:"admin_fee"`
+	Status        Status     `json:"status"`
+	Reference     string     `json:"reference,omitempty"`
+	FailureReason string     `json:"failure_reason,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+}
+
+// TotalAmount is what the account is debited
+func (t *Topup) TotalAmount() float64 {
+	return t.Amount + t.AdminFee
+}
+
+func (s Status) IsFinal() bool {
+	return s == StatusSuccess || s == StatusFailed
+}
+
+type CreateTopupRequest struct {
+	AccountID      string  `json:"account_id" binding:"required,uuid"`
+	Type           string  `json:"type" binding:"required,oneof=phone_credit ewallet"`
+	Provider       string  `json:"provider,omitempty"` // required for e-wallets; derived from the number for phone credit
+	Destination    string  `json:"destination" binding:"required"`
+	Amount         float64 `json:"amount" binding:"required,gt=0"`
+	IdempotencyKey string  `json:"idempotency_key" binding:"required"`
+}
+
+// Validate checks the amount; see package validation
+func (r *CreateTopupRequest) Validate() error {
+	return validation.New().Amount("amount", r.Amount).Err()
+}
+
+// NormalizePhoneNumber converts +62/62/0-prefixed Indonesian mobile numbers to the local 08... form
+func NormalizePhoneNumber(raw string) (string, error) {
+	number := strings.NewReplacer(" ", "", "-", "").Replace(raw)
+	switch {
+	case strings.HasPrefix(number, "+62"):
+		number = "0" + number[3:]
+	case strings.HasPrefix(number, "62"):
+		number = "0" + number[2:]
+	}
+
+	if !strings.HasPrefix(number, "08") || len(number) < 10 || len(number) > 13 {
+		return "", fmt.Errorf("invalid phone number")
+	}
+	for _, r := range number {
+		if r < '0' || r > '9' {
+			return "", fmt.Errorf("invalid phone number")
+		}
+	}
+
+	return number, nil
+}
+
+// OperatorForNumber identifies the mobile operator of a normalized phone number
+func OperatorForNumber(number string) (Provider, bool) {
+	if len(number) < 4 {
+		return "", false
+	}
+	operator, ok := operatorPrefixes[number[:4]]
+	return operator, ok
+}
+
+func IsEWallet(provider Provider) bool {
+	switch provider {
+	case ProviderGoPay, ProviderOVO, ProviderDANA, ProviderShopeePay, ProviderLinkAja:
+		return true
+	}
+	return false
+}
+
+// ValidateAmount checks the amount against what the destination accepts
+func ValidateAmount(topupType Type, amount float64) error {
+	switch topupType {
+	case TypePhoneCredit:
+		for _, denomination := range PhoneCreditDenominations {
+			if amount == denomination {
+				return nil
+			}
+		}
+		return fmt.Errorf("phone credit is only available in fixed denominations")
+	case TypeEWallet:
+		if amount < MinEWalletAmount || amount > MaxEWalletAmount {
+			return fmt.Errorf("e-wallet top-up must be between %d and %d IDR", MinEWalletAmount, MaxEWalletAmount)
+		}
+		if amount != float64(int64(amount/1000))*1000 {
+			return fmt.Errorf("e-wallet top-up must be a multiple of 1000 IDR")
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid top-up type")
+	}
+}
+
+// AdminFee returns the fee charged on top of a top-up of the given type
+func AdminFee(topupType Type) float64 {
+	if topupType == TypeEWallet {
+		return EWalletAdminFee
+	}
+	return PhoneCreditAdminFee
+}
@@ -0,0 +1,71 @@
+package topup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizePhoneNumber(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{"081234567890", "081234567890", false},
+		{"+6281234567890", "081234567890", false},
+		{"6281234567890", "081234567890", false},
+		{"0812-3456-7890", "081234567890", false},
+		{"0212345678", "", true},     // landline
+		{"08123", "", true},          // too short
+		{"08123456789012", "", true}, // too long
+		{"0812345678ab", "", true},
+	}
+
+	for _, tc := range tests {
+		got, err := NormalizePhoneNumber(tc.raw)
+		if tc.wantErr {
+			assert.Error(t, err, tc.raw)
+			continue
+		}
+		assert.NoError(t, err, tc.raw)
+		assert.Equal(t, tc.want, got)
+	}
+}
+
+func TestOperatorForNumber(t *testing.T) {
+	operator, ok := OperatorForNumber("081234567890")
+	assert.True(t, ok)
+	assert.Equal(t, ProviderTelkomsel, operator)
+
+	operator, ok = OperatorForNumber("087712345678")
+	assert.True(t, ok)
+	assert.Equal(t, ProviderXL, operator)
+
+	_, ok = OperatorForNumber("080012345678")
+	assert.False(t, ok)
+}
+
+func TestValidateAmount(t *testing.T) {
+	assert.NoError(t, ValidateAmount(TypePhoneCredit, 50_000))
+	assert.Error(t, ValidateAmount(TypePhoneCredit, 45_000))
+
+	assert.NoError(t, ValidateAmount(TypeEWallet, 125_000))
+	assert.Error(t, ValidateAmount(TypeEWallet, 5_000))
+	assert.Error(t, ValidateAmount(TypeEWallet, 2_500_000))
+	assert.Error(t, ValidateAmount(TypeEWallet, 10_500))
+
+	assert.Error(t, ValidateAmount("voucher", 10_000))
+}
+
+func TestTopup_TotalAmount(t *testing.T) {
+	tp := &Topup{Type: TypeEWallet, Amount: 100_000, AdminFee: AdminFee(TypeEWallet)}
+	assert.Equal(t, 101_000.0, tp.TotalAmount())
+}
+
+func TestStatus_IsFinal(t *testing.T) {
+	assert.False(t, StatusPending.IsFinal())
+	assert.False(t, StatusProcessing.IsFinal())
+	assert.True(t, StatusSuccess.IsFinal())
+	assert.True(t, StatusFailed.IsFinal())
+}
@@ -1,8 +1,15 @@
 package transaction
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
 
+	"github.com/darisadam/madabank-server/internal/domain/validation"
 	"github.com/google/uuid"
 )
 
@@ -15,6 +22,22 @@ const (
 	TransactionTypeWithdrawal TransactionType = "withdrawal"
 	TransactionTypeInterest   TransactionType = "interest"
 	TransactionTypeFee        TransactionType = "fee"
+	// Repayment of a credit card balance from a deposit account
+	TransactionTypeCardRepayment TransactionType = "card_repayment"
+	// Payment to a biller (utilities, internet, taxes) through the biller aggregator
+	TransactionTypeBillPayment TransactionType = "bill_payment"
+	// Phone credit or e-wallet top-up through the top-up aggregator
+	TransactionTypeTopup TransactionType = "topup"
+	// Customer payment of a merchant payment link, held until settlement
+	TransactionTypeMerchantPayment TransactionType = "merchant_payment"
+	// Daily payout of collected payments to a merchant's settlement account
+	TransactionTypeMerchantSettlement TransactionType = "merchant_settlement"
+	// Payout of an approved personal loan, net of the provision fee
+	TransactionTypeLoanDisbursement TransactionType = "loan_disbursement"
+	// Auto-debited loan installment
+	TransactionTypeLoanRepayment TransactionType = "loan_repayment"
+	// Spare change of a debit moved to the customer's round-up savings account
+	TransactionTypeRoundUp TransactionType = "round_up"
 
 	TransactionStatusPending   TransactionStatus = "pending"
 	TransactionStatusCompleted TransactionStatus = "completed"
@@ -22,6 +45,10 @@ const (
 	TransactionStatusReversed  TransactionStatus = "reversed"
 )
 
+// ErrInsufficientFunds is returned when a debit would take a balance below
+// what the account may go to: zero, or minus its overdraft limit
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
 type Transaction struct {
 	ID              uuid.UUID              `json:"id"`
 	IdempotencyKey  string                 `json:"idempotency_key"`
@@ -36,26 +63,76 @@ type Transaction struct {
 	CompletedAt     *time.Time             `json:"completed_at,omitempty"`
 }
 
+// Currency is the currency the amount moved in, as recorded in the metadata.
+// It is empty when none was recorded, which means the accounts' own currency.
+func (t *Transaction) Currency() string {
+	currency, _ := t.Metadata["currency"].(string)
+	return currency
+}
+
 type TransferRequest struct {
-	FromAccountID  string  `json:"from_account_id" binding:"required,uuid"`
-	ToAccountID    string  `json:"to_account_id" binding:"required,uuid"`
-	Amount         float64 `json:"amount" binding:"required,gt=0"`
-	Description    string  `json:"description,omitempty"`
-	IdempotencyKey string  `json:"idempotency_key" binding:"required"`
+	FromAccountID string `json:"from_account_id" binding:"required,uuid"`
+	ToAccountID   string `json:"to_account_id,omitempty" binding:"required_without_all=BeneficiaryID ToHandle,omitempty,uuid"`
+	// BeneficiaryID sends to a saved, verified recipient instead of ToAccountID
+	BeneficiaryID string `json:"beneficiary_id,omitempty" binding:"omitempty,uuid"`
+	// ToHandle sends to a customer's @handle instead of ToAccountID
+	ToHandle string  `json:"to_handle,omitempty" binding:"omitempty,max=31"`
+	Amount   float64 `json:"amount" binding:"required,gt=0"`
+	// Currency picks the balance the amount moves between, when both accounts
+	// hold it; by default the source account's own currency
+	Currency       string `json:"currency,omitempty"`
+	Description    string `json:"description,omitempty"`
+	IdempotencyKey string `json:"idempotency_key" binding:"required"`
+	// Country is where the request came from, per GeoIP, for the audit log
+	Country string `json:"-"`
 }
 
 type DepositRequest struct {
-	AccountID      string  `json:"account_id" binding:"required,uuid"`
-	Amount         float64 `json:"amount" binding:"required,gt=0"`
-	Description    string  `json:"description,omitempty"`
-	IdempotencyKey string  `json:"idempotency_key" binding:"required"`
+	AccountID string  `json:"account_id" binding:"required,uuid"`
+	Amount    float64 `json:"amount" binding:"required,gt=0"`
+	// Currency picks which of the account's balances; by default its own
+	Currency       string `json:"currency,omitempty"`
+	Description    string `json:"description,omitempty"`
+	IdempotencyKey string `json:"idempotency_key" binding:"required"`
 }
 
 type WithdrawalRequest struct {
-	AccountID      string  `json:"account_id" binding:"required,uuid"`
-	Amount         float64 `json:"amount" binding:"required,gt=0"`
-	Description    string  `json:"description,omitempty"`
-	IdempotencyKey string  `json:"idempotency_key" binding:"required"`
+	AccountID string  `json:"account_id" binding:"required,uuid"`
+	Amount    float64 `json:"amount" binding:"required,gt=0"`
+	// Currency picks which of the account's balances; by default its own
+	Currency       string `json:"currency,omitempty"`
+	Description    string `json:"description,omitempty"`
+	IdempotencyKey string `json:"idempotency_key" binding:"required"`
+}
+
+// Validate checks the amount, currency and description; see package
+// validation
+func (r *TransferRequest) Validate() error {
+	v := validation.New().Amount("amount", r.Amount).Description("description", r.Description)
+	if r.Currency != "" {
+		v.Currency("currency", r.Currency)
+	}
+	return v.Err()
+}
+
+// Validate checks the amount, currency and description; see package
+// validation
+func (r *DepositRequest) Validate() error {
+	v := validation.New().Amount("amount", r.Amount).Description("description", r.Description)
+	if r.Currency != "" {
+		v.Currency("currency", r.Currency)
+	}
+	return v.Err()
+}
+
+// Validate checks the amount, currency and description; see package
+// validation
+func (r *WithdrawalRequest) Validate() error {
+	v := validation.New().Amount("amount", r.Amount).Description("description", r.Description)
+	if r.Currency != "" {
+		v.Currency("currency", r.Currency)
+	}
+	return v.Err()
 }
 
 type TransactionResponse struct {
@@ -66,10 +143,21 @@ type TransactionResponse struct {
 	TransactionType TransactionType   `json:"transaction_type"`
 	Status          TransactionStatus `json:"status"`
 	Description     string            `json:"description,omitempty"`
+	Counterparty    *Counterparty     `json:"counterparty,omitempty"`
 	CreatedAt       time.Time         `json:"created_at"`
 	CompletedAt     *time.Time        `json:"completed_at,omitempty"`
 }
 
+// Counterparty is the other side of a transaction, as seen from the account
+// whose history is listed. Other customers' account numbers are masked and
+// their names shortened, as on receipts.
+type Counterparty struct {
+	AccountID     uuid.UUID `json:"account_id"`
+	AccountNumber string    `json:"account_number"`
+	HolderName    string    `json:"holder_name"`
+	Own           bool      `json:"own"` // one of the user's own accounts
+}
+
 type TransactionHistoryRequest struct {
 	AccountID string `form:"account_id" binding:"required,uuid"`
 	Limit     int    `form:"limit" binding:"omitempty,min=1,max=100"`
@@ -81,9 +169,10 @@ type TransactionHistoryRequest struct {
 
 type TransactionHistoryResponse struct {
 	Transactions []TransactionResponse `json:"transactions"`
-	Total        int                   `json:"total"`
+	Total        int                   `json:"total"` // matching transactions across all pages
 	Limit        int                   `json:"limit"`
 	Offset       int                   `json:"offset"`
+	HasMore      bool                  `json:"has_more"`
 }
 
 type QRResolutionResponse struct {
@@ -95,3 +184,76 @@ type QRResolutionResponse struct {
 type QRResolutionRequest struct {
 	QRCode string `json:"qr_code" binding:"required"`
 }
+
+// ArchivedHistoryRequest asks for an account's transactions in months that were
+// moved to cold storage
+type ArchivedHistoryRequest struct {
+	AccountID string `form:"account_id" binding:"required,uuid"`
+	StartDate string `form:"start_date" binding:"required"`
+	EndDate   string `form:"end_date" binding:"required"`
+}
+
+// MaxArchivedHistoryDays bounds an archived history request, since every
+// archived month in range is downloaded in full
+const MaxArchivedHistoryDays = 92
+
+// Archive records a month of transactions moved to cold storage
+type Archive struct {
+	Month      time.Time `json:"month"`
+	ObjectKey  string    `json:"object_key"`
+	Storage    string    `json:"storage"`
+	RowCount   int       `json:"row_count"`
+	ArchivedAt time.Time `json:"archived_at"`
+}
+
+// MonthStart returns the start of the UTC month containing t. Transactions are
+// partitioned by the UTC month of created_at.
+func MonthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// ArchiveKey is the object key of a month's archive
+func ArchiveKey(month time.Time) string {
+	return fmt.Sprintf("transactions/%s/transactions-%s.jsonl.gz", month.Format("2006"), month.Format("2006-01"))
+}
+
+// EncodeArchive writes transactions as gzip-compressed JSON lines
+func EncodeArchive(txns []*Transaction) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	enc := json.NewEncoder(gz)
+	for _, txn := range txns {
+		if err := enc.Encode(txn); err != nil {
+			return nil, fmt.Errorf("failed to encode transaction %s: %w", txn.ID, err)
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress transactions: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeArchive reads an archive written by EncodeArchive
+func DecodeArchive(data []byte) ([]*Transaction, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer func() { _ = gz.Close() }()
+
+	txns := []*Transaction{}
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for scanner.Scan() {
+		txn := &Transaction{}
+		if err := json.Unmarshal(scanner.Bytes(), txn); err != nil {
+			return nil, fmt.Errorf("failed to decode archived transaction: %w", err)
+		}
+		txns = append(txns, txn)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	return txns, nil
+}
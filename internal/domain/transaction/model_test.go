@@ -93,6 +93,23 @@ func TestTransferRequest_Structure(t *testing.T) {
 	assert.Equal(t, "transfer-key-456", req.IdempotencyKey)
 }
 
+func TestTransferRequest_ValidateCurrency(t *testing.T) {
+	req := TransferRequest{Amount: 10, Currency: "USD"}
+	assert.NoError(t, req.Validate())
+
+	req.Currency = "usd"
+	assert.EqualError(t, req.Validate(), "currency must be a supported currency code such as IDR")
+
+	// Empty is the source account's own currency
+	req.Currency = ""
+	assert.NoError(t, req.Validate())
+}
+
+func TestTransaction_Currency(t *testing.T) {
+	assert.Empty(t, (&Transaction{}).Currency())
+	assert.Equal(t, "USD", (&Transaction{Metadata: map[string]interface{}{"currency": "USD"}}).Currency())
+}
+
 func TestDepositRequest_Structure(t *testing.T) {
 	req := DepositRequest{
 		AccountID:      uuid.New().String(),
@@ -183,3 +200,33 @@ func TestQRResolutionRequest_Structure(t *testing.T) {
 
 	assert.Contains(t, req.QRCode, "madabank:account:")
 }
+
+func TestMonthStartAndArchiveKey(t *testing.T) {
+	// 05:30 on 1 February in Jakarta is still 31 January in UTC
+	local := time.Date(2024, 2, 1, 5, 30, 0, 0, time.FixedZone("WIB", 7*3600))
+	month := MonthStart(local)
+
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), month)
+	assert.Equal(t, "transactions/2024/transactions-2024-01.jsonl.gz", ArchiveKey(month))
+}
+
+func TestEncodeDecodeArchive(t *testing.T) {
+	from := uuid.New()
+	completed := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	txns := []*Transaction{
+		{ID: uuid.New(), IdempotencyKey: "k1", FromAccountID: &from, Amount: 1500.5, TransactionType: TransactionTypeWithdrawal,
+			Status: TransactionStatusCompleted, CreatedAt: completed, CompletedAt: &completed},
+		{ID: uuid.New(), IdempotencyKey: "k2", ToAccountID: &from, Amount: 20, TransactionType: TransactionTypeDeposit,
+			Status: TransactionStatusCompleted, Metadata: map[string]interface{}{"channel": "atm"}, CreatedAt: completed},
+	}
+
+	data, err := EncodeArchive(txns)
+	assert.NoError(t, err)
+
+	decoded, err := DecodeArchive(data)
+	assert.NoError(t, err)
+	assert.Equal(t, txns, decoded)
+
+	_, err = DecodeArchive([]byte("not gzip"))
+	assert.Error(t, err)
+}
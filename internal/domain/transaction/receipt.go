@@ -0,0 +1,205 @@
+package transaction
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/currency"
+	"github.com/darisadam/madabank-server/internal/pkg/pdf"
+	"github.com/google/uuid"
+)
+
+const (
+	ReceiptFormatJSON = "json"
+	ReceiptFormatPDF  = "pdf"
+
+	// DefaultReceiptShareTTL is how long a shared receipt link stays valid
+	DefaultReceiptShareTTL = 7 * 24 * time.Hour
+)
+
+var (
+	ErrInvalidShareToken = errors.New("invalid receipt link")
+	ErrExpiredShareToken = errors.New("receipt link has expired")
+)
+
+// ReceiptParty is one side of a receipt. The account number is masked.
+type ReceiptParty struct {
+	AccountNumber string `json:"account_number"`
+	HolderName    string `json:"holder_name"`
+}
+
+// Receipt is proof of a completed transaction. It carries only what a payer
+// needs to show someone else.
+type Receipt struct {
+	TransactionID  uuid.UUID         `json:"transaction_id"`
+	Type           TransactionType   `json:"transaction_type"`
+	Status         TransactionStatus `json:"status"`
+	Amount         float64           `json:"amount"`
+	Currency       string            `json:"currency"`
+	Description    string            `json:"description,omitempty"`
+	Date           time.Time         `json:"date"`
+	From           *ReceiptParty     `json:"from,omitempty"`
+	To             *ReceiptParty     `json:"to,omitempty"`
+	ShareURL       string            `json:"share_url,omitempty"`
+	ShareExpiresAt *time.Time        `json:"share_expires_at,omitempty"`
+}
+
+type ReceiptRequest struct {
+	Format string `form:"format" binding:"omitempty,oneof=json pdf"`
+}
+
+// NewReceipt builds the receipt of a transaction. Parties are nil for the
+// sides without a customer account, e.g. the source of a cash deposit.
+func NewReceipt(txn *Transaction, from, to *ReceiptParty) *Receipt {
+	r := &Receipt{
+		TransactionID: txn.ID,
+		Type:          txn.TransactionType,
+		Status:        txn.Status,
+		Amount:        txn.Amount,
+		Currency:      "IDR",
+		Description:   txn.Description,
+		Date:          txn.CreatedAt,
+		From:          from,
+		To:            to,
+	}
+	if currency := txn.Currency(); currency != "" {
+		r.Currency = currency
+	}
+	if txn.CompletedAt != nil {
+		r.Date = *txn.CompletedAt
+	}
+	return r
+}
+
+// Shared is the receipt shown through a public link, without the description
+// the customer may have written for themselves
+func (r *Receipt) Shared() *Receipt {
+	shared := *r
+	shared.Description = ""
+	shared.ShareURL = ""
+	shared.ShareExpiresAt = nil
+	return &shared
+}
+
+// FileName names the receipt PDF
+func (r *Receipt) FileName() string {
+	return fmt.Sprintf("receipt-%s.pdf", r.TransactionID)
+}
+
+// Render draws the receipt as a one-page PDF. Times are shown in zone.
+func (r *Receipt) Render(bankName string, zone *time.Location) []byte {
+	const left, right = 56.0, pdf.PageWidth - 56
+	doc := pdf.New()
+
+	doc.Text(left, 72, 20, true, bankName)
+	doc.Text(left, 96, 12, false, "Transaction Receipt")
+	doc.Line(left, 110, right, 110, 1)
+
+	doc.Text(left, 150, 10, false, "Amount")
+	doc.Text(left, 176, 22, true, FormatMoney(r.Currency, r.Amount))
+
+	rows := [][2]string{
+		{"Status", strings.ToUpper(string(r.Status))},
+		{"Type", strings.ReplaceAll(string(r.Type), "_", " ")},
+		{"Date", r.Date.In(zone).Format("02 Jan 2006 15:04 MST")},
+		{"Reference", r.TransactionID.String()},
+	}
+	if r.From != nil {
+		rows = append(rows, [2]string{"From", partyLine(r.From)})
+	}
+	if r.To != nil {
+		rows = append(rows, [2]string{"To", partyLine(r.To)})
+	}
+	if r.Description != "" {
+		rows = append(rows, [2]string{"Description", r.Description})
+	}
+
+	y := 220.0
+	for _, row := range rows {
+		doc.Text(left, y, 10, false, row[0])
+		doc.TextRight(right, y, 10, true, row[1])
+		y += 24
+	}
+
+	doc.Line(left, y, right, y, 0.5)
+	doc.Text(left, y+24, 8, false, "This receipt is generated electronically and is valid without a signature.")
+
+	return doc.Bytes()
+}
+
+func partyLine(p *ReceiptParty) string {
+	if p.HolderName == "" {
+		return p.AccountNumber
+	}
+	return p.HolderName + " - " + p.AccountNumber
+}
+
+// FormatMoney formats an amount in the currency's minor units with
+// thousands separators, e.g. IDR 1,500,000.00 or JPY 1,500
+func FormatMoney(code string, amount float64) string {
+	return currency.Format(code, amount)
+}
+
+// ShortHolderName shows an account holder by first name and last initial,
+// enough for a payee to recognise themselves without exposing their full name
+func ShortHolderName(firstName, lastName string) string {
+	if lastName == "" {
+		return firstName
+	}
+	return firstName + " " + lastName[:1] + "."
+}
+
+// MaskAccountNumber keeps the last 4 digits of an account number
+func MaskAccountNumber(number string) string {
+	if len(number) <= 4 {
+		return "****"
+	}
+	return strings.Repeat("*", len(number)-4) + number[len(number)-4:]
+}
+
+// SignShareToken creates the token of a public receipt link. It encodes the
+// transaction and expiry, signed so neither can be changed.
+func SignShareToken(secret []byte, txnID uuid.UUID, expiresAt time.Time) string {
+	payload := make([]byte, 24)
+	copy(payload, txnID[:])
+	binary.BigEndian.PutUint64(payload[16:], uint64(expiresAt.Unix()))
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(shareSignature(secret, payload))
+}
+
+// ParseShareToken verifies a public receipt link token and returns its transaction
+func ParseShareToken(secret []byte, token string, now time.Time) (uuid.UUID, error) {
+	encodedPayload, encodedSig, ok := strings.Cut(token, ".")
+	if !ok {
+		return uuid.Nil, ErrInvalidShareToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil || len(payload) != 24 {
+		return uuid.Nil, ErrInvalidShareToken
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(encodedSig)
+	if err != nil || !hmac.Equal(sig, shareSignature(secret, payload)) {
+		return uuid.Nil, ErrInvalidShareToken
+	}
+
+	expiresAt := time.Unix(int64(binary.BigEndian.Uint64(payload[16:])), 0)
+	if !now.Before(expiresAt) {
+		return uuid.Nil, ErrExpiredShareToken
+	}
+
+	var txnID uuid.UUID
+	copy(txnID[:], payload[:16])
+	return txnID, nil
+}
+
+func shareSignature(secret, payload []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("receipt-share:"))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
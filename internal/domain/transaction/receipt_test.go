@@ -0,0 +1,78 @@
+package transaction
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewReceipt(t *testing.T) {
+	created := time.Date(2026, 3, 1, 2, 0, 0, 0, time.UTC)
+	completed := created.Add(time.Second)
+	txn := &Transaction{
+		ID: uuid.New(), Amount: 150000, TransactionType: TransactionTypeTransfer, Status: TransactionStatusCompleted,
+		Description: "Rent", Metadata: map[string]interface{}{"currency": "USD"}, CreatedAt: created, CompletedAt: &completed,
+	}
+	to := &ReceiptParty{AccountNumber: MaskAccountNumber("1234567890"), HolderName: "Siti"}
+
+	r := NewReceipt(txn, nil, to)
+
+	assert.Equal(t, "USD", r.Currency)
+	assert.Equal(t, completed, r.Date)
+	assert.Equal(t, "******7890", r.To.AccountNumber)
+
+	shared := r.Shared()
+	assert.Empty(t, shared.Description)
+	assert.Equal(t, "Rent", r.Description)
+}
+
+func TestReceipt_Render(t *testing.T) {
+	r := &Receipt{
+		TransactionID: uuid.New(), Type: TransactionTypeBillPayment, Status: TransactionStatusCompleted,
+		Amount: 1500000, Currency: "IDR", Date: time.Date(2026, 3, 1, 2, 0, 0, 0, time.UTC),
+		From: &ReceiptParty{AccountNumber: "******7890", HolderName: "Budi"},
+	}
+	jakarta := time.FixedZone("WIB", 7*3600)
+
+	out := r.Render("MadaBank", jakarta)
+
+	assert.True(t, bytes.HasPrefix(out, []byte("%PDF-")))
+	assert.Contains(t, string(out), "(IDR 1,500,000.00)")
+	assert.Contains(t, string(out), "(01 Mar 2026 09:00 WIB)")
+	assert.Contains(t, string(out), "(Budi - ******7890)")
+	assert.Contains(t, string(out), "(bill payment)")
+}
+
+func TestFormatMoney(t *testing.T) {
+	assert.Equal(t, "IDR 0.50", FormatMoney("IDR", 0.5))
+	assert.Equal(t, "IDR 999.00", FormatMoney("IDR", 999))
+	assert.Equal(t, "IDR 1,000.00", FormatMoney("IDR", 1000))
+	assert.Equal(t, "USD -12,345,678.90", FormatMoney("USD", -12345678.9))
+}
+
+func TestShareToken(t *testing.T) {
+	secret := []byte("receipt-secret")
+	txnID := uuid.New()
+	now := time.Now()
+	token := SignShareToken(secret, txnID, now.Add(time.Hour))
+
+	got, err := ParseShareToken(secret, token, now)
+	assert.NoError(t, err)
+	assert.Equal(t, txnID, got)
+
+	_, err = ParseShareToken(secret, token, now.Add(2*time.Hour))
+	assert.ErrorIs(t, err, ErrExpiredShareToken)
+
+	_, err = ParseShareToken([]byte("other-secret"), token, now)
+	assert.ErrorIs(t, err, ErrInvalidShareToken)
+
+	forged := SignShareToken([]byte("other-secret"), txnID, now.Add(time.Hour))
+	_, err = ParseShareToken(secret, token[:len(token)/2]+forged[len(forged)/2:], now)
+	assert.ErrorIs(t, err, ErrInvalidShareToken)
+
+	_, err = ParseShareToken(secret, "not-a-token", now)
+	assert.ErrorIs(t, err, ErrInvalidShareToken)
+}
@@ -0,0 +1,88 @@
+package transaction
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SearchRequest finds transactions of the user's accounts by free text over
+// the description, counterparty and metadata, combined with field filters
+type SearchRequest struct {
+	Query string `form:"q" binding:"omitempty,max=100"`
+	// AccountID narrows the search to one account, otherwise all of the user's accounts are searched
+	AccountID string  `form:"account_id" binding:"omitempty,uuid"`
+	MinAmount float64 `form:"min_amount" binding:"omitempty,gt=0"`
+	MaxAmount float64 `form:"max_amount" binding:"omitempty,gt=0"`
+	Status    string  `form:"status" binding:"omitempty,oneof=pending completed failed reversed"`
+	TxnType   string  `form:"type,omitempty"`
+	StartDate string  `form:"start_date,omitempty"`
+	EndDate   string  `form:"end_date,omitempty"`
+	Limit     int     `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset    int     `form:"offset" binding:"omitempty,min=0"`
+}
+
+// SearchFilter is a validated search over a set of accounts
+type SearchFilter struct {
+	AccountIDs []uuid.UUID
+	Query      string
+	MinAmount  float64 // 0 for no lower bound
+	MaxAmount  float64 // 0 for no upper bound
+	Status     TransactionStatus
+	Type       TransactionType
+	From       *time.Time
+	To         *time.Time // exclusive
+	Limit      int
+	Offset     int
+}
+
+// Filter validates the request and turns it into a search over accountIDs.
+// The end date is inclusive.
+func (r *SearchRequest) Filter(accountIDs []uuid.UUID) (*SearchFilter, error) {
+	f := &SearchFilter{
+		AccountIDs: accountIDs,
+		Query:      strings.TrimSpace(r.Query),
+		MinAmount:  r.MinAmount,
+		MaxAmount:  r.MaxAmount,
+		Status:     TransactionStatus(r.Status),
+		Type:       TransactionType(r.TxnType),
+		Limit:      r.Limit,
+		Offset:     r.Offset,
+	}
+	if f.Limit == 0 {
+		f.Limit = 20
+	}
+	if f.MaxAmount > 0 && f.MinAmount > f.MaxAmount {
+		return nil, fmt.Errorf("min_amount must not be greater than max_amount")
+	}
+
+	if r.StartDate != "" {
+		from, err := time.Parse("2006-01-02", r.StartDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start_date, expected YYYY-MM-DD")
+		}
+		f.From = &from
+	}
+	if r.EndDate != "" {
+		end, err := time.Parse("2006-01-02", r.EndDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid end_date, expected YYYY-MM-DD")
+		}
+		to := end.AddDate(0, 0, 1)
+		f.To = &to
+	}
+	if f.From != nil && f.To != nil && !f.From.Before(*f.To) {
+		return nil, fmt.Errorf("start_date must not be after end_date")
+	}
+
+	return f, nil
+}
+
+// ContainsPattern is a LIKE pattern matching the query anywhere, with the
+// query's own wildcards escaped
+func (f *SearchFilter) ContainsPattern() string {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(f.Query)
+	return "%" + escaped + "%"
+}
@@ -0,0 +1,48 @@
+package transaction
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchRequest_Filter(t *testing.T) {
+	ids := []uuid.UUID{uuid.New()}
+	req := &SearchRequest{Query: "  bob ", MinAmount: 100000, Status: "completed", StartDate: "2026-03-01", EndDate: "2026-03-31"}
+
+	f, err := req.Filter(ids)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "bob", f.Query)
+	assert.Equal(t, ids, f.AccountIDs)
+	assert.Equal(t, TransactionStatusCompleted, f.Status)
+	assert.Equal(t, 20, f.Limit)
+	assert.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), *f.From)
+	assert.Equal(t, time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), *f.To)
+}
+
+func TestSearchRequest_Filter_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     SearchRequest
+		wantErr string
+	}{
+		{name: "amount range", req: SearchRequest{MinAmount: 500, MaxAmount: 100}, wantErr: "min_amount must not be greater than max_amount"},
+		{name: "start date", req: SearchRequest{StartDate: "01/03/2026"}, wantErr: "invalid start_date, expected YYYY-MM-DD"},
+		{name: "date range", req: SearchRequest{StartDate: "2026-04-01", EndDate: "2026-03-01"}, wantErr: "start_date must not be after end_date"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.req.Filter(nil)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestSearchFilter_ContainsPattern(t *testing.T) {
+	f := &SearchFilter{Query: `50%_off\`}
+	assert.Equal(t, `%50\%\_off\\%`, f.ContainsPattern())
+}
@@ -0,0 +1,88 @@
+package transaction
+
+import (
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/validation"
+	"github.com/google/uuid"
+)
+
+// MaxTemplatesPerUser bounds a user's saved transfers
+const MaxTemplatesPerUser = 50
+
+// Template is a saved transfer the user can repeat with one call. It goes
+// either to an account or to a saved beneficiary.
+type Template struct {
+	ID            uuid.UUID  `json:"id"`
+	UserID        uuid.UUID  `json:"user_id"`
+	Name          string     `json:"name"`
+	FromAccountID uuid.UUID  `json:"from_account_id"`
+	ToAccountID   *uuid.UUID `json:"to_account_id,omitempty"`
+	BeneficiaryID *uuid.UUID `json:"beneficiary_id,omitempty"`
+	Amount        float64    `json:"amount"`
+	Description   string     `json:"description,omitempty"`
+	LastUsedAt    *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TransferRequest builds the transfer for one execution of the template. A
+// positive amount overrides the saved amount.
+func (t *Template) TransferRequest(idempotencyKey string, amount float64) *TransferRequest {
+	req := &TransferRequest{
+		FromAccountID:  t.FromAccountID.String(),
+		Amount:         t.Amount,
+		Description:    t.Description,
+		IdempotencyKey: idempotencyKey,
+	}
+	if amount > 0 {
+		req.Amount = amount
+	}
+	if t.BeneficiaryID != nil {
+		req.BeneficiaryID = t.BeneficiaryID.String()
+	} else if t.ToAccountID != nil {
+		req.ToAccountID = t.ToAccountID.String()
+	}
+	return req
+}
+
+type CreateTemplateRequest struct {
+	Name          string  `json:"name" binding:"required,max=50"`
+	FromAccountID string  `json:"from_account_id" binding:"required,uuid"`
+	ToAccountID   string  `json:"to_account_id,omitempty" binding:"required_without=BeneficiaryID,excluded_with=BeneficiaryID,omitempty,uuid"`
+	BeneficiaryID string  `json:"beneficiary_id,omitempty" binding:"omitempty,uuid"`
+	Amount        float64 `json:"amount" binding:"required,gt=0"`
+	Description   string  `json:"description,omitempty" binding:"max=255"`
+}
+
+// UpdateTemplateRequest changes the fields that are set. The source and
+// destination cannot be changed; save a new template instead.
+type UpdateTemplateRequest struct {
+	Name        *string  `json:"name,omitempty" binding:"omitempty,min=1,max=50"`
+	Amount      *float64 `json:"amount,omitempty" binding:"omitempty,gt=0"`
+	Description *string  `json:"description,omitempty" binding:"omitempty,max=255"`
+}
+
+// Validate checks the amount and description; see package validation
+func (r *CreateTemplateRequest) Validate() error {
+	return validation.New().Amount("amount", r.Amount).Description("description", r.Description).Err()
+}
+
+// Validate checks the fields that are set
+func (r *UpdateTemplateRequest) Validate() error {
+	v := validation.New()
+	if r.Amount != nil {
+		v.Amount("amount", *r.Amount)
+	}
+	if r.Description != nil {
+		v.Description("description", *r.Description)
+	}
+	return v.Err()
+}
+
+type ExecuteTemplateRequest struct {
+	IdempotencyKey string  `json:"idempotency_key" binding:"required"`
+	Amount         float64 `json:"amount,omitempty" binding:"omitempty,gt=0"` // overrides the saved amount for this transfer
+	// Country is where the request came from, per GeoIP, for the audit log
+	Country string `json:"-"`
+}
@@ -0,0 +1,29 @@
+package transaction
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplate_TransferRequest(t *testing.T) {
+	fromID := uuid.New()
+	toID := uuid.New()
+	beneficiaryID := uuid.New()
+
+	toAccount := &Template{FromAccountID: fromID, ToAccountID: &toID, Amount: 150000, Description: "Rent"}
+	assert.Equal(t, &TransferRequest{
+		FromAccountID:  fromID.String(),
+		ToAccountID:    toID.String(),
+		Amount:         150000,
+		Description:    "Rent",
+		IdempotencyKey: "rent-march",
+	}, toAccount.TransferRequest("rent-march", 0))
+
+	toBeneficiary := &Template{FromAccountID: fromID, BeneficiaryID: &beneficiaryID, Amount: 150000}
+	req := toBeneficiary.TransferRequest("rent-april", 175000)
+	assert.Equal(t, beneficiaryID.String(), req.BeneficiaryID)
+	assert.Empty(t, req.ToAccountID)
+	assert.Equal(t, 175000.0, req.Amount)
+}
@@ -0,0 +1,44 @@
+package user
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultImpersonationTTL = 15 * time.Minute
+	MaxImpersonationTTL     = time.Hour
+)
+
+// ImpersonationScopes are granted to impersonation tokens: reading the
+// customer's data, never changing it
+var ImpersonationScopes = []string{
+	"users:read", "accounts:read", "transactions:read", "beneficiaries:read", "cards:read",
+	"bills:read", "topups:read", "fx:read", "merchants:read", "payment_links:read", "loans:read",
+}
+
+// ImpersonationRequest asks for a token to see the app as a customer does
+type ImpersonationRequest struct {
+	// Reason is recorded in the audit log, such as the support ticket
+	Reason          string `json:"reason" binding:"required,min=10,max=500"`
+	DurationMinutes int    `json:"duration_minutes,omitempty" binding:"omitempty,min=1,max=60"`
+}
+
+// TTL is how long the token lasts, DefaultImpersonationTTL unless asked otherwise
+func (r *ImpersonationRequest) TTL() time.Duration {
+	if r.DurationMinutes == 0 {
+		return DefaultImpersonationTTL
+	}
+	return time.Duration(r.DurationMinutes) * time.Minute
+}
+
+// ImpersonationResponse carries a read-only token acting as the user. It
+// cannot be refreshed.
+type ImpersonationResponse struct {
+	Token     string    `json:"token"`
+	TokenID   uuid.UUID `json:"token_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Scope     string    `json:"scope"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,29 @@
+package user
+
+import (
+	"github.com/google/uuid"
+)
+
+// IntrospectionRequest asks whether an access token is valid, in the
+// form-encoded shape of RFC 7662
+type IntrospectionRequest struct {
+	Token string `form:"token" binding:"required"`
+	// TokenTypeHint is accepted for compatibility; only access tokens can be introspected
+	TokenTypeHint string `form:"token_type_hint"`
+}
+
+// IntrospectionResponse describes an access token. Inactive tokens, whether
+// malformed, expired or belonging to a deactivated user, carry only Active.
+type IntrospectionResponse struct {
+	Active    bool       `json:"active"`
+	TokenType string     `json:"token_type,omitempty"`
+	Sub       *uuid.UUID `json:"sub,omitempty"`
+	Username  string     `json:"username,omitempty"` // the user's email
+	Role      string     `json:"role,omitempty"`
+	Scope     string     `json:"scope,omitempty"` // empty for unlimited tokens
+	// ImpersonatedBy is the support agent acting as the user, if any
+	ImpersonatedBy *uuid.UUID `json:"impersonated_by,omitempty"`
+	Exp            int64      `json:"exp,omitempty"`
+	Iat            int64      `json:"iat,omitempty"`
+	Nbf            int64      `json:"nbf,omitempty"`
+}
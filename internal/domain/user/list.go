@@ -0,0 +1,135 @@
+package user
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultListLimit = 50
+	MaxListLimit     = 200
+)
+
+// ListUsersRequest searches users for support tooling. Users come newest
+// first; pass the previous page's next_cursor to get the following page.
+type ListUsersRequest struct {
+	// Email matches any part of the address, case-insensitively
+	Email string `form:"email" binding:"omitempty,max=255"`
+	// Phone matches any part of the number
+	Phone       string `form:"phone" binding:"omitempty,max=20"`
+	KYCStatus   string `form:"kyc_status" binding:"omitempty,oneof=pending verified rejected"`
+	IsActive    *bool  `form:"is_active"`
+	CreatedFrom string `form:"created_from,omitempty"`
+	CreatedTo   string `form:"created_to,omitempty"`
+	Cursor      string `form:"cursor,omitempty"`
+	Limit       int    `form:"limit" binding:"omitempty,min=1,max=200"`
+}
+
+// ListFilter selects a page of users
+type ListFilter struct {
+	Email       string
+	Phone       string
+	KYCStatus   string
+	IsActive    *bool
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time // exclusive
+	After       *Cursor    // nil for the first page
+	Limit       int
+}
+
+// Cursor is the position of the last user of a page. Users sort by
+// created_at and then id, both descending, so the pair is unique.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// UserListResponse is a page of users. NextCursor is empty on the last page.
+type UserListResponse struct {
+	Users      []*User `json:"users"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+	HasMore    bool    `json:"has_more"`
+}
+
+// Filter validates the request. The created_to date is inclusive.
+func (r *ListUsersRequest) Filter() (*ListFilter, error) {
+	f := &ListFilter{
+		Email:     strings.TrimSpace(r.Email),
+		Phone:     strings.TrimSpace(r.Phone),
+		KYCStatus: r.KYCStatus,
+		IsActive:  r.IsActive,
+		Limit:     r.Limit,
+	}
+	if f.Limit == 0 {
+		f.Limit = DefaultListLimit
+	}
+	if f.Limit > MaxListLimit {
+		f.Limit = MaxListLimit
+	}
+
+	if r.CreatedFrom != "" {
+		from, err := time.Parse("2006-01-02", r.CreatedFrom)
+		if err != nil {
+			return nil, fmt.Errorf("invalid created_from, expected YYYY-MM-DD")
+		}
+		f.CreatedFrom = &from
+	}
+	if r.CreatedTo != "" {
+		end, err := time.Parse("2006-01-02", r.CreatedTo)
+		if err != nil {
+			return nil, fmt.Errorf("invalid created_to, expected YYYY-MM-DD")
+		}
+		to := end.AddDate(0, 0, 1)
+		f.CreatedTo = &to
+	}
+	if f.CreatedFrom != nil && f.CreatedTo != nil && !f.CreatedFrom.Before(*f.CreatedTo) {
+		return nil, fmt.Errorf("created_from must not be after created_to")
+	}
+
+	if r.Cursor != "" {
+		after, err := DecodeCursor(r.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		f.After = after
+	}
+
+	return f, nil
+}
+
+// ContainsPattern is a LIKE pattern matching s anywhere, with the wildcards
+// in s escaped
+func ContainsPattern(s string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+	return "%" + escaped + "%"
+}
+
+// Encode makes the cursor opaque to clients
+func (c *Cursor) Encode() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "," + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses a cursor made by Encode
+func DecodeCursor(s string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	createdAt, id, ok := strings.Cut(string(raw), ",")
+	if !ok {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	c := &Cursor{}
+	if c.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	if c.ID, err = uuid.Parse(id); err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	return c, nil
+}
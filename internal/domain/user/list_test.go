@@ -0,0 +1,50 @@
+package user
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListUsersRequest_Filter_Defaults(t *testing.T) {
+	f, err := (&ListUsersRequest{}).Filter()
+
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultListLimit, f.Limit)
+	assert.Nil(t, f.After)
+	assert.Nil(t, f.CreatedFrom)
+}
+
+func TestListUsersRequest_Filter_CreatedRange(t *testing.T) {
+	f, err := (&ListUsersRequest{CreatedFrom: "2026-01-01", CreatedTo: "2026-01-31"}).Filter()
+
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), *f.CreatedFrom)
+	assert.Equal(t, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), *f.CreatedTo)
+
+	_, err = (&ListUsersRequest{CreatedFrom: "2026-02-01", CreatedTo: "2026-01-31"}).Filter()
+	assert.EqualError(t, err, "created_from must not be after created_to")
+}
+
+func TestCursor_RoundTrip(t *testing.T) {
+	c := &Cursor{CreatedAt: time.Date(2026, 3, 4, 5, 6, 7, 890, time.UTC), ID: uuid.New()}
+
+	f, err := (&ListUsersRequest{Cursor: c.Encode()}).Filter()
+
+	assert.NoError(t, err)
+	assert.True(t, c.CreatedAt.Equal(f.After.CreatedAt))
+	assert.Equal(t, c.ID, f.After.ID)
+}
+
+func TestDecodeCursor_Invalid(t *testing.T) {
+	for _, s := range []string{"not base64!", "bm8tY29tbWE", "MjAyNi0wMS0wMVQwMDowMDowMFosbm90LWEtdXVpZA"} {
+		_, err := DecodeCursor(s)
+		assert.EqualError(t, err, "invalid cursor", s)
+	}
+}
+
+func TestContainsPattern(t *testing.T) {
+	assert.Equal(t, `%budi\_s\%%`, ContainsPattern("budi_s%"))
+}
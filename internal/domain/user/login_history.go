@@ -0,0 +1,46 @@
+package user
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// LoginOrigin describes where a login comes from. The handler fills it in
+// from the request headers; it is not part of the request body.
+type LoginOrigin struct {
+	// DeviceID is the install ID the mobile app sends as X-Device-ID
+	DeviceID  string
+	UserAgent string
+	IPAddress string
+	// Country is set by the GeoIP middleware, "" when unknown
+	Country string
+}
+
+// Fingerprint identifies the device: the hashed device ID, or the hashed user
+// agent for clients that send none
+func (o LoginOrigin) Fingerprint() string {
+	source := "ua:" + o.UserAgent
+	if o.DeviceID != "" {
+		source = "id:" + o.DeviceID
+	}
+	sum := sha256.Sum256([]byte(source))
+	return hex.EncodeToString(sum[:])
+}
+
+// LoginFamiliarity tells whether a login comes from a device and country the
+// user logged in from before
+type LoginFamiliarity struct {
+	// FirstLogin is set when there is no history yet, as for a new user
+	FirstLogin   bool
+	KnownDevice  bool
+	KnownCountry bool
+}
+
+// IsNew reports whether the login should be announced. An unknown country is
+// not treated as a new location.
+func (f *LoginFamiliarity) IsNew(origin LoginOrigin) bool {
+	if f.FirstLogin {
+		return false
+	}
+	return !f.KnownDevice || (origin.Country != "" && !f.KnownCountry)
+}
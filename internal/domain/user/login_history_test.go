@@ -0,0 +1,30 @@
+package user
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginOrigin_Fingerprint(t *testing.T) {
+	app := LoginOrigin{DeviceID: "install-1", UserAgent: "MadaBank/3.2.0"}
+	updated := LoginOrigin{DeviceID: "install-1", UserAgent: "MadaBank/3.3.0"}
+	browser := LoginOrigin{UserAgent: "MadaBank/3.2.0"}
+
+	assert.Len(t, app.Fingerprint(), 64)
+	// An app update keeps the device
+	assert.Equal(t, app.Fingerprint(), updated.Fingerprint())
+	assert.NotEqual(t, app.Fingerprint(), browser.Fingerprint())
+}
+
+func TestLoginFamiliarity_IsNew(t *testing.T) {
+	jakarta := LoginOrigin{Country: "ID"}
+	unknown := LoginOrigin{}
+
+	assert.False(t, (&LoginFamiliarity{FirstLogin: true}).IsNew(jakarta))
+	assert.False(t, (&LoginFamiliarity{KnownDevice: true, KnownCountry: true}).IsNew(jakarta))
+	assert.True(t, (&LoginFamiliarity{KnownDevice: false, KnownCountry: true}).IsNew(jakarta))
+	assert.True(t, (&LoginFamiliarity{KnownDevice: true, KnownCountry: false}).IsNew(jakarta))
+	// Without GeoIP only the device counts
+	assert.False(t, (&LoginFamiliarity{KnownDevice: true, KnownCountry: false}).IsNew(unknown))
+}
@@ -0,0 +1,47 @@
+package user
+
+import (
+	"fmt"
+	"time"
+)
+
+// Failed logins are counted per account, whichever IPs they come from; every
+// attempt that does not end in a successful login counts, including those
+// stopped for a one-time code. From LoginDelayAfterFailures on, the next
+// attempt has to wait a delay doubling with each failure, up to MaxLoginDelay;
+// from LoginOTPAfterFailures on, even the right password needs a one-time
+// code. The count is forgotten LoginFailureWindow after the last failure, or
+// on a successful login.
+const (
+	LoginDelayAfterFailures = 3
+	LoginOTPAfterFailures   = 5
+	BaseLoginDelay          = 2 * time.Second
+	MaxLoginDelay           = 5 * time.Minute
+	LoginFailureWindow      = time.Hour
+)
+
+// LoginDelay is how long after the last failure the next login attempt may
+// be made
+func LoginDelay(failures int) time.Duration {
+	if failures < LoginDelayAfterFailures {
+		return 0
+	}
+	delay := BaseLoginDelay
+	for i := LoginDelayAfterFailures; i < failures; i++ {
+		delay *= 2
+		if delay >= MaxLoginDelay {
+			return MaxLoginDelay
+		}
+	}
+	return delay
+}
+
+// LoginThrottledError rejects a login attempt made before the delay of the
+// account's failed attempts has passed. The password is not checked.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, try again in %d seconds", int(e.RetryAfter.Round(time.Second)/time.Second))
+}
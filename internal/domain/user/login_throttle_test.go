@@ -0,0 +1,23 @@
+package user
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginDelay(t *testing.T) {
+	assert.Equal(t, time.Duration(0), LoginDelay(0))
+	assert.Equal(t, time.Duration(0), LoginDelay(LoginDelayAfterFailures-1))
+	assert.Equal(t, 2*time.Second, LoginDelay(3))
+	assert.Equal(t, 4*time.Second, LoginDelay(4))
+	assert.Equal(t, 64*time.Second, LoginDelay(8))
+	assert.Equal(t, MaxLoginDelay, LoginDelay(11))
+	assert.Equal(t, MaxLoginDelay, LoginDelay(1000))
+}
+
+func TestLoginThrottledError(t *testing.T) {
+	err := &LoginThrottledError{RetryAfter: 3600 * time.Millisecond}
+	assert.EqualError(t, err, "too many failed login attempts, try again in 4 seconds")
+}
@@ -1,24 +1,60 @@
 package user
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// User roles carried in the JWT role claim
+const (
+	RoleCustomer = "customer"
+	RoleAdmin    = "admin"
+)
+
+// ReactivationWindow is how long after deleting their profile a user can
+// restore it. Personal data must be retained at least this long.
+const (
+	ReactivationWindowDays = 30
+	ReactivationWindow     = ReactivationWindowDays * 24 * time.Hour
+)
+
+// One-time codes sent for password reset, reactivation and contact changes
+// expire after OTPTTL, and are discarded after MaxOTPAttempts wrong guesses
+const (
+	OTPTTL         = 15 * time.Minute
+	MaxOTPAttempts = 5
+)
+
+// Placeholders written over the personal data of a deleted user once the
+// retention period has passed
+const (
+	AnonymizedFirstName  = "Deleted"
+	AnonymizedLastName   = "User"
+	AnonymizedCardHolder = "DELETED USER"
+)
+
 type User struct {
-	ID           uuid.UUID  `json:"id"`
-	Email        string     `json:"email"`
-	PasswordHash string     `json:"-"` // Never expose in JSON
-	FirstName    string     `json:"first_name"`
-	LastName     string     `json:"last_name"`
-	Phone        *string    `json:"phone,omitempty"`
-	DateOfBirth  *time.Time `json:"date_of_birth,omitempty"`
-	KYCStatus    string     `json:"kyc_status"`
-	IsActive     bool       `json:"is_active"`
-	CreatedAt    time.Time  `json:"created_at"`
-	UpdatedAt    time.Time  `json:"updated_at"`
-	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
+	ID    uuid.UUID `json:"id"`
+	Email string    `json:"email"`
+	// Username is the optional public handle, lowercase and without the @
+	Username     *string `json:"username,omitempty"`
+	PasswordHash string  `json:"-"` // Never expose in JSON
+	FirstName    string  `json:"first_name"`
+	LastName     string  `json:"last_name"`
+	Phone        *string `json:"phone,omitempty"`
+	// PhoneVerifiedAt is set once the phone number is confirmed by SMS. Only
+	// verified numbers can be used to log in or to receive payments.
+	PhoneVerifiedAt *time.Time `json:"phone_verified_at,omitempty"`
+	DateOfBirth     *time.Time `json:"date_of_birth,omitempty"`
+	KYCStatus       string     `json:"kyc_status"`
+	Role            string     `json:"role"`
+	IsActive        bool       `json:"is_active"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty"`
 }
 
 type CreateUserRequest struct {
@@ -31,27 +67,88 @@ type CreateUserRequest struct {
 }
 
 type LoginRequest struct {
-	Email    string `json:"email,omitempty"` // Either Email or Phone is required
-	Phone    string `json:"phone,omitempty"` // Either Email or Phone is required
+	Email    string `json:"email,omitempty"`    // One of Email, Phone or Username is required
+	Phone    string `json:"phone,omitempty"`    // One of Email, Phone or Username is required
+	Username string `json:"username,omitempty"` // One of Email, Phone or Username is required
 	Password string `json:"password" binding:"required"`
+	// OTP answers a step-up challenge, sent after a first attempt failed
+	// with ErrStepUpRequired
+	OTP string `json:"otp,omitempty" binding:"omitempty,len=6,numeric"`
+	// ClientType picks the token lifetimes; the default ones apply when empty
+	ClientType string `json:"client_type,omitempty" binding:"omitempty,oneof=mobile web partner"`
+	// StepUp is set when the GeoIP policy challenges the client's country
+	StepUp bool `json:"-"`
+	// Origin is the device and location of the request
+	Origin LoginOrigin `json:"-"`
 }
 
+// ErrStepUpRequired means the action needs a one-time code, which has just
+// been sent, before it can go ahead
+var ErrStepUpRequired = errors.New("additional verification required, enter the code we just sent")
+
+// ErrInvalidLoginCode is returned when a login that needs a one-time code has
+// a wrong password or a wrong, expired or used-up code; which one is not told
+var ErrInvalidLoginCode = errors.New("invalid password or code")
+
 type LoginResponse struct {
-	Token        string    `json:"token"`
-	RefreshToken string    `json:"refresh_token"`
-	ExpiresAt    time.Time `json:"expires_at"`
-	User         *User     `json:"user"`
+	Token            string    `json:"token"`
+	RefreshToken     string    `json:"refresh_token"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+	User             *User     `json:"user"`
 }
 
 type UpdateUserRequest struct {
-	FirstName   *string `json:"first_name,omitempty"`
+	FirstName *string `json:"first_name,omitempty"`
+	// Username sets the public handle; an empty string removes it
+	Username    *string `json:"username,omitempty"`
 	LastName    *string `json:"last_name,omitempty"`
 	Phone       *string `json:"phone,omitempty"`
 	DateOfBirth *string `json:"date_of_birth,omitempty"`
 }
 
+// OTPChannel is how a one-time code is delivered
+type OTPChannel string
+
+const (
+	OTPChannelEmail OTPChannel = "email"
+	OTPChannelSMS   OTPChannel = "sms"
+)
+
+// ForgotPasswordRequest starts a password reset. Channel picks where the code
+// goes; when empty it goes by SMS if the phone number is verified, else by email.
 type ForgotPasswordRequest struct {
-	Email string `json:"email" binding:"required,email"`
+	Email   string     `json:"email" binding:"required,email"`
+	Channel OTPChannel `json:"channel" binding:"omitempty,oneof=email sms"`
+}
+
+// ReactivationOTPRequest asks for a code to restore a deleted or deactivated
+// profile. The password proves the caller owns the profile.
+type ReactivationOTPRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+type ReactivateRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+	OTP      string `json:"otp" binding:"required,len=6"`
+}
+
+// EmailChangeRequest starts moving the profile to a new address. The
+// password is asked again because the email is used for account recovery.
+type EmailChangeRequest struct {
+	NewEmail string `json:"new_email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+type ConfirmEmailChangeRequest struct {
+	NewEmail string `json:"new_email" binding:"required,email"`
+	OTP      string `json:"otp" binding:"required,len=6"`
+}
+
+type VerifyPhoneRequest struct {
+	OTP string `json:"otp" binding:"required,len=6"`
 }
 
 type ResetPasswordRequest struct {
@@ -59,3 +156,56 @@ type ResetPasswordRequest struct {
 	OTP         string `json:"otp" binding:"required,len=6"`
 	NewPassword string `json:"new_password" binding:"required,min=8"`
 }
+
+// ChangePasswordRequest replaces the password of a signed-in user
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=8"`
+}
+
+// AnonymizedEmail is the placeholder address of an anonymized user. It stays
+// unique per user and can never receive mail.
+func AnonymizedEmail(id uuid.UUID) string {
+	return "deleted-" + id.String() + "@anonymized.invalid"
+}
+
+// CanReactivate reports why the user cannot restore their profile at now, if
+// they cannot
+func (u *User) CanReactivate(now time.Time) error {
+	if u.DeletedAt == nil {
+		if u.IsActive {
+			return fmt.Errorf("account is already active")
+		}
+		return nil
+	}
+	if now.Sub(*u.DeletedAt) > ReactivationWindow {
+		return fmt.Errorf("reactivation period has expired")
+	}
+	return nil
+}
+
+// PhoneVerified reports whether the user's current phone number is verified
+func (u *User) PhoneVerified() bool {
+	return u.Phone != nil && u.PhoneVerifiedAt != nil
+}
+
+// OTPChannelFor resolves the channel a code should go to. Codes are only
+// texted to a verified phone number.
+func (u *User) OTPChannelFor(preferred OTPChannel) (OTPChannel, error) {
+	switch preferred {
+	case OTPChannelEmail:
+		return OTPChannelEmail, nil
+	case OTPChannelSMS:
+		if !u.PhoneVerified() {
+			return "", fmt.Errorf("no verified phone number on profile, use email instead")
+		}
+		return OTPChannelSMS, nil
+	case "":
+		if u.PhoneVerified() {
+			return OTPChannelSMS, nil
+		}
+		return OTPChannelEmail, nil
+	default:
+		return "", fmt.Errorf("unsupported OTP channel %q", preferred)
+	}
+}
@@ -118,3 +118,55 @@ func TestResetPasswordRequest_Structure(t *testing.T) {
 	assert.Equal(t, "123456", req.OTP)
 	assert.Equal(t, "newpassword123", req.NewPassword)
 }
+
+func TestAnonymizedEmail_UniquePerUser(t *testing.T) {
+	id := uuid.MustParse("6f1c2a4e-0b5d-4e8a-9a3f-2c7d8e9f0a1b")
+
+	assert.Equal(t, "deleted-6f1c2a4e-0b5d-4e8a-9a3f-2c7d8e9f0a1b@anonymized.invalid", AnonymizedEmail(id))
+	assert.NotEqual(t, AnonymizedEmail(uuid.New()), AnonymizedEmail(uuid.New()))
+}
+
+func TestUser_CanReactivate(t *testing.T) {
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	recently := now.Add(-29 * 24 * time.Hour)
+	longAgo := now.Add(-31 * 24 * time.Hour)
+
+	assert.NoError(t, (&User{DeletedAt: &recently}).CanReactivate(now))
+	assert.EqualError(t, (&User{DeletedAt: &longAgo}).CanReactivate(now), "reactivation period has expired")
+	assert.NoError(t, (&User{IsActive: false}).CanReactivate(now))
+	assert.EqualError(t, (&User{IsActive: true}).CanReactivate(now), "account is already active")
+}
+
+func TestUser_PhoneVerified(t *testing.T) {
+	phone := "+6281234567890"
+	now := time.Now()
+
+	assert.True(t, (&User{Phone: &phone, PhoneVerifiedAt: &now}).PhoneVerified())
+	assert.False(t, (&User{Phone: &phone}).PhoneVerified())
+	assert.False(t, (&User{PhoneVerifiedAt: &now}).PhoneVerified())
+}
+
+func TestUser_OTPChannelFor(t *testing.T) {
+	phone := "+6281234567890"
+	now := time.Now()
+	verified := &User{Phone: &phone, PhoneVerifiedAt: &now}
+	unverified := &User{Phone: &phone}
+
+	for _, tc := range []struct {
+		user      *User
+		preferred OTPChannel
+		want      OTPChannel
+		wantErr   bool
+	}{
+		{verified, "", OTPChannelSMS, false},
+		{unverified, "", OTPChannelEmail, false},
+		{verified, OTPChannelEmail, OTPChannelEmail, false},
+		{verified, OTPChannelSMS, OTPChannelSMS, false},
+		{unverified, OTPChannelSMS, "", true},
+		{verified, "pigeon", "", true},
+	} {
+		got, err := tc.user.OTPChannelFor(tc.preferred)
+		assert.Equal(t, tc.wantErr, err != nil, tc.preferred)
+		assert.Equal(t, tc.want, got)
+	}
+}
@@ -0,0 +1,40 @@
+package user
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResetToken_RoundTrip(t *testing.T) {
+	secret := []byte("reset-secret")
+	userID := uuid.New()
+	nonce := bytes.Repeat([]byte{7}, ResetNonceSize)
+	now := time.Now()
+
+	token := SignResetToken(secret, userID, nonce, now.Add(ResetLinkTTL))
+	gotID, gotNonce, err := ParseResetToken(secret, token, now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, userID, gotID)
+	assert.Equal(t, nonce, gotNonce)
+}
+
+func TestParseResetToken_Rejects(t *testing.T) {
+	secret := []byte("reset-secret")
+	nonce := bytes.Repeat([]byte{7}, ResetNonceSize)
+	now := time.Now()
+	token := SignResetToken(secret, uuid.New(), nonce, now.Add(ResetLinkTTL))
+
+	_, _, err := ParseResetToken([]byte("other-secret"), token, now)
+	assert.ErrorIs(t, err, ErrInvalidResetLink)
+
+	_, _, err = ParseResetToken(secret, token, now.Add(ResetLinkTTL))
+	assert.ErrorIs(t, err, ErrExpiredResetLink)
+
+	_, _, err = ParseResetToken(secret, "not-a-token", now)
+	assert.ErrorIs(t, err, ErrInvalidResetLink)
+}
@@ -0,0 +1,94 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Scopes granted to internal services
+const (
+	ScopeTokensIntrospect = "tokens.introspect"
+	ScopeHoldsManage      = "holds.manage"
+)
+
+// GrantTypeClientCredentials is the only grant of the service token endpoint
+const GrantTypeClientCredentials = "client_credentials"
+
+// OAuth 2.0 token endpoint errors (RFC 6749 section 5.2)
+var (
+	ErrInvalidClient        = errors.New("invalid_client")
+	ErrInvalidScope         = errors.New("invalid_scope")
+	ErrUnsupportedGrantType = errors.New("unsupported_grant_type")
+)
+
+// ServiceClient is an internal caller, such as the statement renderer, that
+// may obtain service tokens for the scopes it is allowed
+type ServiceClient struct {
+	ID     string
+	Secret string
+	Scopes []string
+}
+
+// Grant resolves the scopes to put in a token. An empty request grants all of
+// the client's scopes; asking for any scope it does not have fails.
+func (c *ServiceClient) Grant(requested string) ([]string, error) {
+	if strings.TrimSpace(requested) == "" {
+		return c.Scopes, nil
+	}
+
+	allowed := make(map[string]bool, len(c.Scopes))
+	for _, scope := range c.Scopes {
+		allowed[scope] = true
+	}
+	var granted []string
+	for _, scope := range strings.Fields(requested) {
+		if !allowed[scope] {
+			return nil, ErrInvalidScope
+		}
+		granted = append(granted, scope)
+	}
+	return granted, nil
+}
+
+// ParseServiceClients parses clients in the "id:secret:scope1 scope2,id2:…"
+// format used by the SERVICE_CLIENTS environment variable. IDs and secrets
+// cannot contain colons or commas.
+func ParseServiceClients(raw string) ([]ServiceClient, error) {
+	var clients []ServiceClient
+	seen := map[string]bool{}
+
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[1] == "" || strings.TrimSpace(parts[2]) == "" {
+			return nil, fmt.Errorf("invalid service client entry, expected id:secret:scopes")
+		}
+		if seen[parts[0]] {
+			return nil, fmt.Errorf("duplicate service client %q", parts[0])
+		}
+		seen[parts[0]] = true
+		clients = append(clients, ServiceClient{ID: parts[0], Secret: parts[1], Scopes: strings.Fields(parts[2])})
+	}
+
+	return clients, nil
+}
+
+// ServiceTokenRequest is a client credentials grant (RFC 6749 section 4.4).
+// The credentials may also be sent with HTTP Basic authentication.
+type ServiceTokenRequest struct {
+	GrantType    string `form:"grant_type" binding:"required"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+	Scope        string `form:"scope"`
+}
+
+type ServiceTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"` // seconds
+	Scope       string `json:"scope"`
+}
@@ -0,0 +1,44 @@
+package user
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseServiceClients(t *testing.T) {
+	clients, err := ParseServiceClients(" statement-renderer:s3cret:tokens.introspect statements.render , fraud-worker:hunter2:tokens.introspect")
+	assert.NoError(t, err)
+	assert.Equal(t, []ServiceClient{
+		{ID: "statement-renderer", Secret: "s3cret", Scopes: []string{"tokens.introspect", "statements.render"}},
+		{ID: "fraud-worker", Secret: "hunter2", Scopes: []string{"tokens.introspect"}},
+	}, clients)
+
+	clients, err = ParseServiceClients("")
+	assert.NoError(t, err)
+	assert.Empty(t, clients)
+
+	_, err = ParseServiceClients("fraud-worker:secret")
+	assert.Error(t, err)
+
+	_, err = ParseServiceClients("fraud-worker:secret: ")
+	assert.Error(t, err)
+
+	_, err = ParseServiceClients("a:one:tokens.introspect,a:two:tokens.introspect")
+	assert.EqualError(t, err, `duplicate service client "a"`)
+}
+
+func TestServiceClient_Grant(t *testing.T) {
+	client := &ServiceClient{ID: "statement-renderer", Scopes: []string{"tokens.introspect", "statements.render"}}
+
+	granted, err := client.Grant("")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"tokens.introspect", "statements.render"}, granted)
+
+	granted, err = client.Grant("statements.render")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"statements.render"}, granted)
+
+	_, err = client.Grant("statements.render accounts.write")
+	assert.ErrorIs(t, err, ErrInvalidScope)
+}
@@ -0,0 +1,96 @@
+package user
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ClientType selects the token lifetimes of a login
+type ClientType string
+
+const (
+	// ClientTypeDefault applies to logins that do not say which client they are
+	ClientTypeDefault ClientType = "default"
+	ClientTypeMobile  ClientType = "mobile"
+	ClientTypeWeb     ClientType = "web"
+	ClientTypePartner ClientType = "partner"
+)
+
+// MaxAccessTTL bounds the lifetime of any access token
+const MaxAccessTTL = 24 * time.Hour
+
+// TokenTTL is the token lifetimes of one client type
+type TokenTTL struct {
+	Access  time.Duration
+	Refresh time.Duration
+	// SessionMax is how long after login the session ends for good, however
+	// often it is refreshed. No access token outlives it.
+	SessionMax time.Duration
+}
+
+// DefaultTokenTTL matches the lifetimes used before client types existed
+var DefaultTokenTTL = TokenTTL{Access: 24 * time.Hour, Refresh: 30 * 24 * time.Hour, SessionMax: 30 * 24 * time.Hour}
+
+// Validate checks the lifetimes are positive and nested: access tokens no
+// longer than refresh tokens, refresh tokens no longer than the session
+func (t TokenTTL) Validate() error {
+	switch {
+	case t.Access <= 0 || t.Refresh <= 0 || t.SessionMax <= 0:
+		return fmt.Errorf("token lifetimes must be positive")
+	case t.Access > MaxAccessTTL:
+		return fmt.Errorf("access token lifetime must be at most %s", MaxAccessTTL)
+	case t.Refresh < t.Access:
+		return fmt.Errorf("refresh token lifetime must not be shorter than the access token lifetime")
+	case t.SessionMax < t.Refresh:
+		return fmt.Errorf("session maximum must not be shorter than the refresh token lifetime")
+	}
+	return nil
+}
+
+// AccessExpiry is when an access token issued now expires: after the access
+// lifetime, or at the end of the session if that comes first
+func (t TokenTTL) AccessExpiry(now, sessionStartedAt time.Time) time.Time {
+	expiresAt := now.Add(t.Access)
+	if sessionEnd := sessionStartedAt.Add(t.SessionMax); sessionEnd.Before(expiresAt) {
+		return sessionEnd
+	}
+	return expiresAt
+}
+
+// TokenTTLs holds the lifetimes per client type
+type TokenTTLs map[ClientType]TokenTTL
+
+// For returns the lifetimes of a client type, falling back to the default ones
+func (t TokenTTLs) For(clientType ClientType) TokenTTL {
+	if ttl, ok := t[clientType]; ok {
+		return ttl
+	}
+	if ttl, ok := t[ClientTypeDefault]; ok {
+		return ttl
+	}
+	return DefaultTokenTTL
+}
+
+// MaxAccess is the longest access token lifetime of any client type
+func (t TokenTTLs) MaxAccess() time.Duration {
+	longest := DefaultTokenTTL.Access
+	if len(t) > 0 {
+		longest = 0
+	}
+	for _, ttl := range t {
+		if ttl.Access > longest {
+			longest = ttl.Access
+		}
+	}
+	return longest
+}
+
+// RefreshSession is a stored refresh token and the login it belongs to
+type RefreshSession struct {
+	UserID           uuid.UUID
+	ClientType       ClientType
+	ExpiresAt        time.Time
+	SessionStartedAt time.Time
+}
@@ -0,0 +1,41 @@
+package user
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenTTL_Validate(t *testing.T) {
+	assert.NoError(t, DefaultTokenTTL.Validate())
+	assert.NoError(t, TokenTTL{Access: 15 * time.Minute, Refresh: 12 * time.Hour, SessionMax: 12 * time.Hour}.Validate())
+
+	assert.EqualError(t, TokenTTL{Access: 0, Refresh: time.Hour, SessionMax: time.Hour}.Validate(), "token lifetimes must be positive")
+	assert.EqualError(t, TokenTTL{Access: 48 * time.Hour, Refresh: 72 * time.Hour, SessionMax: 72 * time.Hour}.Validate(), "access token lifetime must be at most 24h0m0s")
+	assert.EqualError(t, TokenTTL{Access: time.Hour, Refresh: time.Minute, SessionMax: time.Hour}.Validate(), "refresh token lifetime must not be shorter than the access token lifetime")
+	assert.EqualError(t, TokenTTL{Access: time.Minute, Refresh: 2 * time.Hour, SessionMax: time.Hour}.Validate(), "session maximum must not be shorter than the refresh token lifetime")
+}
+
+func TestTokenTTL_AccessExpiry(t *testing.T) {
+	ttl := TokenTTL{Access: 15 * time.Minute, Refresh: 12 * time.Hour, SessionMax: 12 * time.Hour}
+	start := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, start.Add(15*time.Minute), ttl.AccessExpiry(start, start))
+
+	// Ten minutes before the session ends the token only gets those ten minutes
+	now := start.Add(12*time.Hour - 10*time.Minute)
+	assert.Equal(t, start.Add(12*time.Hour), ttl.AccessExpiry(now, start))
+}
+
+func TestTokenTTLs_For(t *testing.T) {
+	web := TokenTTL{Access: 15 * time.Minute, Refresh: 12 * time.Hour, SessionMax: 12 * time.Hour}
+	fallback := TokenTTL{Access: time.Hour, Refresh: 24 * time.Hour, SessionMax: 24 * time.Hour}
+	ttls := TokenTTLs{ClientTypeWeb: web, ClientTypeDefault: fallback}
+
+	assert.Equal(t, web, ttls.For(ClientTypeWeb))
+	assert.Equal(t, fallback, ttls.For(ClientTypePartner))
+	assert.Equal(t, DefaultTokenTTL, TokenTTLs(nil).For(ClientTypeWeb))
+	assert.Equal(t, time.Hour, ttls.MaxAccess())
+	assert.Equal(t, DefaultTokenTTL.Access, TokenTTLs(nil).MaxAccess())
+}
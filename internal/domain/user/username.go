@@ -0,0 +1,45 @@
+package user
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// usernamePattern is 3 to 30 lowercase letters, digits, dots and
+// underscores, starting with a letter
+var usernamePattern = regexp.MustCompile(`^[a-z][a-z0-9._]{2,29}$`)
+
+// reservedUsernames could be mistaken for the bank itself
+var reservedUsernames = map[string]bool{
+	"admin":    true,
+	"support":  true,
+	"madabank": true,
+	"bank":     true,
+	"security": true,
+}
+
+// HandleResolution is what a payer sees before sending to an @handle: enough
+// to recognise the payee, without their account number or full name
+type HandleResolution struct {
+	Handle      string `json:"handle"`
+	DisplayName string `json:"display_name"`
+}
+
+// NormalizeUsername lowercases a username and strips a leading @, so
+// "@Budi.S" and "budi.s" are the same handle
+func NormalizeUsername(s string) (string, error) {
+	name := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(s), "@"))
+	if !usernamePattern.MatchString(name) {
+		return "", fmt.Errorf("username must be 3 to 30 letters, digits, dots or underscores, starting with a letter")
+	}
+	if reservedUsernames[name] {
+		return "", fmt.Errorf("username %q is reserved", name)
+	}
+	return name, nil
+}
+
+// Handle is the username as shown to others
+func Handle(username string) string {
+	return "@" + username
+}
@@ -0,0 +1,32 @@
+package user
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeUsername(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "@Budi.S", want: "budi.s"},
+		{in: " siti_rahma ", want: "siti_rahma"},
+		{in: "ab", wantErr: true},
+		{in: "1budi", wantErr: true},
+		{in: "budi santoso", wantErr: true},
+		{in: "@Admin", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizeUsername(tt.in)
+		if tt.wantErr {
+			assert.Error(t, err, tt.in)
+			continue
+		}
+		assert.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, got)
+	}
+}
@@ -0,0 +1,128 @@
+// Package validation checks the amounts, currencies and descriptions of
+// requests before they reach a repository. The checks are collected per
+// request, so a client learns about every invalid field at once.
+package validation
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/darisadam/madabank-server/internal/domain/currency"
+)
+
+const (
+	// MaxDecimals is the precision amounts are stored with (DECIMAL(15, 2))
+	MaxDecimals = 2
+	// MaxAmount is the largest amount a DECIMAL(15, 2) column holds
+	MaxAmount = 9_999_999_999_999.99
+	// MaxDescriptionLength is in characters, not bytes
+	MaxDescriptionLength = 255
+)
+
+// Codes of a FieldError, stable for clients to match on
+const (
+	CodeNotPositive         = "not_positive"
+	CodeTooLarge            = "too_large"
+	CodeTooPrecise          = "too_precise"
+	CodeUnsupportedCurrency = "unsupported_currency"
+	CodeTooLong             = "too_long"
+	CodeInvalidCharacters   = "invalid_characters"
+)
+
+// FieldError is one invalid request field
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	return e.Field + " " + e.Message
+}
+
+// Errors are all the invalid fields of a request
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, fe := range e {
+		messages[i] = fe.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Validator collects the FieldErrors of one request
+type Validator struct {
+	errs Errors
+}
+
+func New() *Validator {
+	return &Validator{}
+}
+
+func (v *Validator) add(field, code, message string) {
+	v.errs = append(v.errs, FieldError{Field: field, Code: code, Message: message})
+}
+
+// Amount checks that amount is positive, fits the database and has at most
+// MaxDecimals decimal places
+func (v *Validator) Amount(field string, amount float64) *Validator {
+	return v.amount(field, amount, MaxDecimals)
+}
+
+// AmountIn is Amount for a known currency, which may allow fewer decimal
+// places (none for JPY). An unsupported currency is reported by Currency.
+func (v *Validator) AmountIn(field string, amount float64, code string) *Validator {
+	return v.amount(field, amount, min(currency.MinorUnits(code), MaxDecimals))
+}
+
+func (v *Validator) amount(field string, amount float64, decimals int) *Validator {
+	switch {
+	case math.IsNaN(amount) || amount <= 0:
+		v.add(field, CodeNotPositive, "must be greater than 0")
+	case amount > MaxAmount:
+		v.add(field, CodeTooLarge, fmt.Sprintf("must not exceed %.2f", MaxAmount))
+	case !hasDecimals(amount, decimals):
+		v.add(field, CodeTooPrecise, fmt.Sprintf("must have at most %d decimal places", decimals))
+	}
+	return v
+}
+
+// hasDecimals reports whether amount has no more than the given decimal
+// places, allowing for the binary representation of values like 0.1
+func hasDecimals(amount float64, decimals int) bool {
+	scaled := amount * math.Pow10(decimals)
+	return math.Abs(scaled-math.Round(scaled)) < 1e-6
+}
+
+// Currency checks that code is one of the supported currencies, in upper
+// case
+func (v *Validator) Currency(field, code string) *Validator {
+	if !currency.IsSupported(code) {
+		v.add(field, CodeUnsupportedCurrency, "must be a supported currency code such as IDR")
+	}
+	return v
+}
+
+// Description checks an optional free-text field shown on statements and
+// receipts: at most MaxDescriptionLength characters and no control
+// characters
+func (v *Validator) Description(field, description string) *Validator {
+	if !utf8.ValidString(description) || strings.IndexFunc(description, unicode.IsControl) >= 0 {
+		v.add(field, CodeInvalidCharacters, "must not contain control characters")
+	} else if utf8.RuneCountInString(description) > MaxDescriptionLength {
+		v.add(field, CodeTooLong, fmt.Sprintf("must be at most %d characters", MaxDescriptionLength))
+	}
+	return v
+}
+
+// Err returns the collected Errors, or nil when every field is valid
+func (v *Validator) Err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
@@ -0,0 +1,87 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAmount(t *testing.T) {
+	tests := []struct {
+		amount float64
+		code   string
+	}{
+		{100, ""},
+		{0.01, ""},
+		{0.1 + 0.2, ""},
+		{1234567.89, ""},
+		{MaxAmount, ""},
+		{0, CodeNotPositive},
+		{-5, CodeNotPositive},
+		{MaxAmount + 1, CodeTooLarge},
+		{10.005, CodeTooPrecise},
+		{0.001, CodeTooPrecise},
+	}
+	for _, tt := range tests {
+		err := New().Amount("amount", tt.amount).Err()
+		if tt.code == "" {
+			assert.NoError(t, err, tt.amount)
+			continue
+		}
+		var errs Errors
+		require.ErrorAs(t, err, &errs, tt.amount)
+		assert.Equal(t, tt.code, errs[0].Code, tt.amount)
+		assert.Equal(t, "amount", errs[0].Field)
+	}
+}
+
+func TestAmountIn(t *testing.T) {
+	assert.NoError(t, New().AmountIn("amount", 1500, "JPY").Err())
+	assert.Error(t, New().AmountIn("amount", 1500.5, "JPY").Err())
+	assert.NoError(t, New().AmountIn("amount", 10.25, "USD").Err())
+	assert.Error(t, New().AmountIn("amount", 10.255, "USD").Err())
+}
+
+func TestCurrency(t *testing.T) {
+	for _, code := range []string{"USD", "IDR", "EUR", "JPY", "SGD"} {
+		assert.NoError(t, New().Currency("currency", code).Err(), code)
+	}
+	for _, code := range []string{"", "usd", "US", "USDT", "XAU", "XTS", "ABC", "KWD"} {
+		assert.Error(t, New().Currency("currency", code).Err(), code)
+	}
+}
+
+func TestDescription(t *testing.T) {
+	assert.NoError(t, New().Description("description", "").Err())
+	assert.NoError(t, New().Description("description", "Rent for März 🏠").Err())
+	assert.NoError(t, New().Description("description", strings.Repeat("é", MaxDescriptionLength)).Err())
+
+	var errs Errors
+	require.ErrorAs(t, New().Description("description", strings.Repeat("a", MaxDescriptionLength+1)).Err(), &errs)
+	assert.Equal(t, CodeTooLong, errs[0].Code)
+
+	require.ErrorAs(t, New().Description("description", "line\nbreak").Err(), &errs)
+	assert.Equal(t, CodeInvalidCharacters, errs[0].Code)
+
+	require.ErrorAs(t, New().Description("description", "bad \xff byte").Err(), &errs)
+	assert.Equal(t, CodeInvalidCharacters, errs[0].Code)
+}
+
+func TestErrors_CollectsEveryField(t *testing.T) {
+	err := New().
+		Amount("amount", -1).
+		Currency("currency", "usd").
+		Description("description", "ok").
+		Err()
+
+	var errs Errors
+	require.True(t, errors.As(err, &errs))
+	assert.Equal(t, Errors{
+		{Field: "amount", Code: CodeNotPositive, Message: "must be greater than 0"},
+		{Field: "currency", Code: CodeUnsupportedCurrency, Message: "must be a supported currency code such as IDR"},
+	}, errs)
+	assert.Equal(t, "amount must be greater than 0; currency must be a supported currency code such as IDR", err.Error())
+}
@@ -0,0 +1,139 @@
+package jobs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/pkg/metrics"
+	"github.com/darisadam/madabank-server/internal/pkg/objectstore"
+	"github.com/darisadam/madabank-server/internal/repository"
+)
+
+const defaultArchiveBatchSize = 5000
+
+// AuditArchiveConfig controls the audit log retention job
+type AuditArchiveConfig struct {
+	RetentionDays int
+	BatchSize     int
+	Interval      time.Duration
+}
+
+// AuditArchiver exports audit logs older than the retention window to object
+// storage as gzip-compressed JSONL and prunes them from Postgres. A batch is
+// only deleted after its upload succeeded.
+type AuditArchiver struct {
+	auditRepo repository.AuditRepository
+	store     objectstore.Store
+	cfg       AuditArchiveConfig
+	now       func() time.Time
+}
+
+func NewAuditArchiver(auditRepo repository.AuditRepository, store objectstore.Store, cfg AuditArchiveConfig) *AuditArchiver {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultArchiveBatchSize
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = 24 * time.Hour
+	}
+	return &AuditArchiver{
+		auditRepo: auditRepo,
+		store:     store,
+		cfg:       cfg,
+		now:       time.Now,
+	}
+}
+
+// Start runs the archiver every configured interval until ctx is cancelled
+func (a *AuditArchiver) Start(ctx context.Context) {
+	defer errtrack.RecoverWorker("audit_archiver")
+
+	ticker := time.NewTicker(a.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := a.RunOnce(ctx); err != nil {
+			logger.Error("Audit archive run failed", zap.Error(err))
+			errtrack.CaptureError(err, map[string]string{"worker": "audit_archiver"})
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce archives every eligible audit log and returns the number of rows pruned
+func (a *AuditArchiver) RunOnce(ctx context.Context) (int64, error) {
+	cutoff := a.now().UTC().AddDate(0, 0, -a.cfg.RetentionDays)
+	var total int64
+
+	for {
+		if err := ctx.Err(); err != nil {
+			metrics.RecordAuditArchiveRun(false)
+			return total, err
+		}
+
+		logs, err := a.auditRepo.ListOlderThan(cutoff, a.cfg.BatchSize)
+		if err != nil {
+			metrics.RecordAuditArchiveRun(false)
+			return total, err
+		}
+		if len(logs) == 0 {
+			break
+		}
+
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		enc := json.NewEncoder(gz)
+		for _, l := range logs {
+			if err := enc.Encode(l); err != nil {
+				metrics.RecordAuditArchiveRun(false)
+				return total, fmt.Errorf("failed to encode audit log %d: %w", l.ID, err)
+			}
+		}
+		if err := gz.Close(); err != nil {
+			metrics.RecordAuditArchiveRun(false)
+			return total, fmt.Errorf("failed to compress audit logs: %w", err)
+		}
+
+		firstID, lastID := logs[0].ID, logs[len(logs)-1].ID
+		key := fmt.Sprintf("audit-logs/%s/audit-%d-%d.jsonl.gz",
+			logs[0].Timestamp.UTC().Format("2006/01/02"), firstID, lastID)
+
+		if err := a.store.Put(ctx, key, buf.Bytes(), "application/gzip"); err != nil {
+			metrics.RecordAuditArchiveRun(false)
+			return total, err
+		}
+
+		deleted, err := a.auditRepo.DeleteOlderThan(cutoff, lastID)
+		if err != nil {
+			metrics.RecordAuditArchiveRun(false)
+			return total, err
+		}
+
+		total += deleted
+		metrics.RecordAuditArchiveBatch(int(deleted), buf.Len())
+		logger.Info("Archived audit logs",
+			zap.String("key", key),
+			zap.Int64("rows", deleted),
+			zap.Int("bytes", buf.Len()),
+		)
+
+		if len(logs) < a.cfg.BatchSize {
+			break
+		}
+	}
+
+	metrics.RecordAuditArchiveRun(true)
+	return total, nil
+}
@@ -0,0 +1,143 @@
+package jobs
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/darisadam/madabank-server/internal/domain/audit"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+)
+
+func init() {
+	logger.Init("test")
+}
+
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) Create(log *audit.AuditLog) error {
+	args := m.Called(log)
+	return args.Error(0)
+}
+
+func (m *MockAuditRepository) ListOlderThan(cutoff time.Time, limit int) ([]*audit.AuditLog, error) {
+	args := m.Called(cutoff, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*audit.AuditLog), args.Error(1)
+}
+
+func (m *MockAuditRepository) ListChain(afterID int64, limit int) ([]*audit.AuditLog, error) {
+	args := m.Called(afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*audit.AuditLog), args.Error(1)
+}
+
+func (m *MockAuditRepository) DeleteOlderThan(cutoff time.Time, maxID int64) (int64, error) {
+	args := m.Called(cutoff, maxID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type memoryStore struct {
+	objects map[string][]byte
+	err     error
+}
+
+func (s *memoryStore) Put(_ context.Context, key string, body []byte, _ string) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.objects[key] = body
+	return nil
+}
+
+func (s *memoryStore) Get(_ context.Context, key string) ([]byte, error) {
+	body, ok := s.objects[key]
+	if !ok {
+		return nil, fmt.Errorf("object %s not found", key)
+	}
+	return body, nil
+}
+
+func (s *memoryStore) Name() string { return "memory" }
+
+func newTestArchiver(repo *MockAuditRepository, store *memoryStore, batch int) *AuditArchiver {
+	a := NewAuditArchiver(repo, store, AuditArchiveConfig{RetentionDays: 90, BatchSize: batch})
+	a.now = func() time.Time { return time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC) }
+	return a
+}
+
+func TestAuditArchiver_ArchivesAndPrunes(t *testing.T) {
+	repo := new(MockAuditRepository)
+	store := &memoryStore{objects: map[string][]byte{}}
+	archiver := newTestArchiver(repo, store, 2)
+	cutoff := time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC)
+	ts := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	batch1 := []*audit.AuditLog{{ID: 1, Action: "LOGIN", Timestamp: ts}, {ID: 2, Action: "TRANSFER_COMPLETED", Timestamp: ts}}
+	batch2 := []*audit.AuditLog{{ID: 3, Action: "LOGIN", Timestamp: ts}}
+
+	repo.On("ListOlderThan", cutoff, 2).Return(batch1, nil).Once()
+	repo.On("DeleteOlderThan", cutoff, int64(2)).Return(int64(2), nil).Once()
+	repo.On("ListOlderThan", cutoff, 2).Return(batch2, nil).Once()
+	repo.On("DeleteOlderThan", cutoff, int64(3)).Return(int64(1), nil).Once()
+
+	total, err := archiver.RunOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	assert.Len(t, store.objects, 2)
+
+	body, ok := store.objects["audit-logs/2024/01/15/audit-1-2.jsonl.gz"]
+	assert.True(t, ok)
+
+	gz, err := gzip.NewReader(bytes.NewReader(body))
+	assert.NoError(t, err)
+	scanner := bufio.NewScanner(gz)
+	var lines []audit.AuditLog
+	for scanner.Scan() {
+		var l audit.AuditLog
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &l))
+		lines = append(lines, l)
+	}
+	assert.Len(t, lines, 2)
+	assert.Equal(t, "TRANSFER_COMPLETED", lines[1].Action)
+	repo.AssertExpectations(t)
+}
+
+func TestAuditArchiver_NothingToArchive(t *testing.T) {
+	repo := new(MockAuditRepository)
+	store := &memoryStore{objects: map[string][]byte{}}
+	archiver := newTestArchiver(repo, store, 10)
+
+	repo.On("ListOlderThan", mock.Anything, 10).Return([]*audit.AuditLog{}, nil)
+
+	total, err := archiver.RunOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), total)
+	assert.Empty(t, store.objects)
+}
+
+func TestAuditArchiver_UploadFailureKeepsRows(t *testing.T) {
+	repo := new(MockAuditRepository)
+	store := &memoryStore{objects: map[string][]byte{}, err: fmt.Errorf("s3 unavailable")}
+	archiver := newTestArchiver(repo, store, 10)
+
+	repo.On("ListOlderThan", mock.Anything, 10).Return([]*audit.AuditLog{{ID: 1, Timestamp: time.Now()}}, nil)
+
+	_, err := archiver.RunOnce(context.Background())
+	assert.Error(t, err)
+	repo.AssertNotCalled(t, "DeleteOlderThan", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,27 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/darisadam/madabank-server/internal/domain/export"
+)
+
+// BulkExportHandler is the queue handler for export.JobKind. It decodes the
+// export's ID from the payload and runs it; run records the outcome on the
+// export itself.
+func BulkExportHandler(run func(ctx context.Context, id uuid.UUID) error) JobHandler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var p export.JobPayload
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return fmt.Errorf("invalid bulk export payload: %w", err)
+		}
+		if p.ExportID == uuid.Nil {
+			return fmt.Errorf("bulk export payload has no export_id")
+		}
+		return run(ctx, p.ExportID)
+	}
+}
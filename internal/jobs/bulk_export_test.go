@@ -0,0 +1,45 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulkExportHandler_RunsExport(t *testing.T) {
+	id := uuid.New()
+	var ran uuid.UUID
+	handler := BulkExportHandler(func(_ context.Context, exportID uuid.UUID) error {
+		ran = exportID
+		return nil
+	})
+
+	err := handler(context.Background(), json.RawMessage(fmt.Sprintf(`{"export_id":%q}`, id)))
+
+	assert.NoError(t, err)
+	assert.Equal(t, id, ran)
+}
+
+func TestBulkExportHandler_ReturnsRunError(t *testing.T) {
+	handler := BulkExportHandler(func(context.Context, uuid.UUID) error {
+		return fmt.Errorf("storage down")
+	})
+
+	err := handler(context.Background(), json.RawMessage(fmt.Sprintf(`{"export_id":%q}`, uuid.New())))
+
+	assert.EqualError(t, err, "storage down")
+}
+
+func TestBulkExportHandler_RejectsPayloadWithoutExport(t *testing.T) {
+	handler := BulkExportHandler(func(context.Context, uuid.UUID) error {
+		t.Fatal("export should not run")
+		return nil
+	})
+
+	assert.Error(t, handler(context.Background(), json.RawMessage(`{}`)))
+	assert.Error(t, handler(context.Background(), json.RawMessage(`not json`)))
+}
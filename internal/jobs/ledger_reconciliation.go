@@ -0,0 +1,69 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+)
+
+// LedgerReconciliationRunner compares stored balances with the ledger
+type LedgerReconciliationRunner interface {
+	RunScheduledLedger(now time.Time) (int, error)
+}
+
+// LedgerReconciler runs the nightly ledger reconciliation. It checks hourly and
+// the runner only reconciles once per business day, so the run happens shortly
+// after midnight and a failed attempt is retried within the hour.
+type LedgerReconciler struct {
+	runner   LedgerReconciliationRunner
+	interval time.Duration
+	now      func() time.Time
+}
+
+func NewLedgerReconciler(runner LedgerReconciliationRunner, interval time.Duration) *LedgerReconciler {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	return &LedgerReconciler{
+		runner:   runner,
+		interval: interval,
+		now:      time.Now,
+	}
+}
+
+// Start runs the reconciler every configured interval until ctx is cancelled
+func (r *LedgerReconciler) Start(ctx context.Context) {
+	defer errtrack.RecoverWorker("ledger_reconciler")
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.RunOnce()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce reconciles the ledger if it is due and returns the number of breaks found
+func (r *LedgerReconciler) RunOnce() int {
+	breaks, err := r.runner.RunScheduledLedger(r.now())
+	if err != nil {
+		logger.Error("Ledger reconciliation run failed", zap.Error(err))
+		errtrack.CaptureError(err, map[string]string{"worker": "ledger_reconciler"})
+		return 0
+	}
+
+	if breaks > 0 {
+		logger.Warn("Ledger reconciliation found breaks", zap.Int("count", breaks))
+	}
+	return breaks
+}
@@ -0,0 +1,39 @@
+package jobs
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLedgerReconciliationRunner struct {
+	mock.Mock
+}
+
+func (m *MockLedgerReconciliationRunner) RunScheduledLedger(now time.Time) (int, error) {
+	args := m.Called(now)
+	return args.Int(0), args.Error(1)
+}
+
+func TestLedgerReconciler_RunOnce(t *testing.T) {
+	runner := new(MockLedgerReconciliationRunner)
+	now := time.Date(2026, 3, 1, 17, 5, 0, 0, time.UTC)
+	reconciler := NewLedgerReconciler(runner, 0)
+	reconciler.now = func() time.Time { return now }
+
+	runner.On("RunScheduledLedger", now).Return(2, nil).Once()
+	assert.Equal(t, 2, reconciler.RunOnce())
+
+	runner.On("RunScheduledLedger", now).Return(0, fmt.Errorf("db down")).Once()
+	assert.Equal(t, 0, reconciler.RunOnce())
+
+	runner.AssertExpectations(t)
+}
+
+func TestNewLedgerReconciler_DefaultInterval(t *testing.T) {
+	reconciler := NewLedgerReconciler(new(MockLedgerReconciliationRunner), 0)
+	assert.Equal(t, time.Hour, reconciler.interval)
+}
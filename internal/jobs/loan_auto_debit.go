@@ -0,0 +1,69 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+)
+
+// InstallmentCollector debits due loan installments from their loan accounts
+type InstallmentCollector interface {
+	CollectDueInstallments(now time.Time) (int, error)
+}
+
+// LoanAutoDebiter collects due loan installments. Each installment is attempted
+// at most once per day, so running hourly only retries a short balance the
+// following day while still catching installments soon after midnight.
+type LoanAutoDebiter struct {
+	collector InstallmentCollector
+	interval  time.Duration
+	now       func() time.Time
+}
+
+func NewLoanAutoDebiter(collector InstallmentCollector, interval time.Duration) *LoanAutoDebiter {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	return &LoanAutoDebiter{
+		collector: collector,
+		interval:  interval,
+		now:       time.Now,
+	}
+}
+
+// Start runs the auto-debit every configured interval until ctx is cancelled
+func (d *LoanAutoDebiter) Start(ctx context.Context) {
+	defer errtrack.RecoverWorker("loan_auto_debiter")
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		d.RunOnce()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce collects all due installments and returns how many were paid
+func (d *LoanAutoDebiter) RunOnce() int {
+	paid, err := d.collector.CollectDueInstallments(d.now())
+	if err != nil {
+		logger.Error("Loan auto-debit run failed", zap.Error(err))
+		errtrack.CaptureError(err, map[string]string{"worker": "loan_auto_debiter"})
+		return 0
+	}
+
+	if paid > 0 {
+		logger.Info("Collected loan installments", zap.Int("count", paid))
+	}
+	return paid
+}
@@ -0,0 +1,39 @@
+package jobs
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockInstallmentCollector struct {
+	mock.Mock
+}
+
+func (m *MockInstallmentCollector) CollectDueInstallments(now time.Time) (int, error) {
+	args := m.Called(now)
+	return args.Int(0), args.Error(1)
+}
+
+func TestLoanAutoDebiter_RunOnce(t *testing.T) {
+	collector := new(MockInstallmentCollector)
+	now := time.Date(2026, 3, 15, 1, 0, 0, 0, time.UTC)
+	debiter := NewLoanAutoDebiter(collector, 0)
+	debiter.now = func() time.Time { return now }
+
+	collector.On("CollectDueInstallments", now).Return(4, nil).Once()
+	assert.Equal(t, 4, debiter.RunOnce())
+
+	collector.On("CollectDueInstallments", now).Return(0, fmt.Errorf("db down")).Once()
+	assert.Equal(t, 0, debiter.RunOnce())
+
+	collector.AssertExpectations(t)
+}
+
+func TestNewLoanAutoDebiter_DefaultInterval(t *testing.T) {
+	debiter := NewLoanAutoDebiter(new(MockInstallmentCollector), 0)
+	assert.Equal(t, time.Hour, debiter.interval)
+}
@@ -0,0 +1,68 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+)
+
+// MerchantNotificationDeliverer retries merchant webhooks that are due
+type MerchantNotificationDeliverer interface {
+	DeliverNotifications(now time.Time) (int, error)
+}
+
+// MerchantNotifier retries payment webhooks that merchants did not accept the
+// first time, backing off between attempts.
+type MerchantNotifier struct {
+	deliverer MerchantNotificationDeliverer
+	interval  time.Duration
+	now       func() time.Time
+}
+
+func NewMerchantNotifier(deliverer MerchantNotificationDeliverer, interval time.Duration) *MerchantNotifier {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	return &MerchantNotifier{
+		deliverer: deliverer,
+		interval:  interval,
+		now:       time.Now,
+	}
+}
+
+// Start runs the notifier every configured interval until ctx is cancelled
+func (n *MerchantNotifier) Start(ctx context.Context) {
+	defer errtrack.RecoverWorker("merchant_notifier")
+
+	ticker := time.NewTicker(n.interval)
+	defer ticker.Stop()
+
+	for {
+		n.RunOnce()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce delivers one batch of due webhooks and returns how many were accepted
+func (n *MerchantNotifier) RunOnce() int {
+	delivered, err := n.deliverer.DeliverNotifications(n.now())
+	if err != nil {
+		logger.Error("Merchant notification run failed", zap.Error(err))
+		errtrack.CaptureError(err, map[string]string{"worker": "merchant_notifier"})
+		return 0
+	}
+
+	if delivered > 0 {
+		logger.Info("Delivered merchant notifications", zap.Int("count", delivered))
+	}
+	return delivered
+}
@@ -0,0 +1,35 @@
+package jobs
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockMerchantNotificationDeliverer struct {
+	mock.Mock
+}
+
+func (m *MockMerchantNotificationDeliverer) DeliverNotifications(now time.Time) (int, error) {
+	args := m.Called(now)
+	return args.Int(0), args.Error(1)
+}
+
+func TestMerchantNotifier_RunOnce(t *testing.T) {
+	deliverer := new(MockMerchantNotificationDeliverer)
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	notifier := NewMerchantNotifier(deliverer, 0)
+	notifier.now = func() time.Time { return now }
+
+	deliverer.On("DeliverNotifications", now).Return(2, nil).Once()
+	assert.Equal(t, 2, notifier.RunOnce())
+
+	deliverer.On("DeliverNotifications", now).Return(0, fmt.Errorf("db down")).Once()
+	assert.Equal(t, 0, notifier.RunOnce())
+
+	deliverer.AssertExpectations(t)
+	assert.Equal(t, 30*time.Second, notifier.interval)
+}
@@ -0,0 +1,69 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+)
+
+// MerchantSettlementRunner pays out merchants' collected payments
+type MerchantSettlementRunner interface {
+	SettleMerchants(now time.Time) (int, error)
+}
+
+// MerchantSettler settles each merchant's payments from previous business days.
+// It runs hourly so a missed or failed run is picked up soon after; a business
+// day is only ever settled once.
+type MerchantSettler struct {
+	runner   MerchantSettlementRunner
+	interval time.Duration
+	now      func() time.Time
+}
+
+func NewMerchantSettler(runner MerchantSettlementRunner, interval time.Duration) *MerchantSettler {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	return &MerchantSettler{
+		runner:   runner,
+		interval: interval,
+		now:      time.Now,
+	}
+}
+
+// Start runs the settler every configured interval until ctx is cancelled
+func (s *MerchantSettler) Start(ctx context.Context) {
+	defer errtrack.RecoverWorker("merchant_settler")
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.RunOnce()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce settles all merchants with unsettled payments and returns how many were settled
+func (s *MerchantSettler) RunOnce() int {
+	settled, err := s.runner.SettleMerchants(s.now())
+	if err != nil {
+		logger.Error("Merchant settlement run failed", zap.Error(err))
+		errtrack.CaptureError(err, map[string]string{"worker": "merchant_settler"})
+		return 0
+	}
+
+	if settled > 0 {
+		logger.Info("Settled merchants", zap.Int("count", settled))
+	}
+	return settled
+}
@@ -0,0 +1,39 @@
+package jobs
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockMerchantSettlementRunner struct {
+	mock.Mock
+}
+
+func (m *MockMerchantSettlementRunner) SettleMerchants(now time.Time) (int, error) {
+	args := m.Called(now)
+	return args.Int(0), args.Error(1)
+}
+
+func TestMerchantSettler_RunOnce(t *testing.T) {
+	runner := new(MockMerchantSettlementRunner)
+	now := time.Date(2026, 3, 1, 18, 0, 0, 0, time.UTC)
+	settler := NewMerchantSettler(runner, 0)
+	settler.now = func() time.Time { return now }
+
+	runner.On("SettleMerchants", now).Return(3, nil).Once()
+	assert.Equal(t, 3, settler.RunOnce())
+
+	runner.On("SettleMerchants", now).Return(0, fmt.Errorf("db down")).Once()
+	assert.Equal(t, 0, settler.RunOnce())
+
+	runner.AssertExpectations(t)
+}
+
+func TestNewMerchantSettler_DefaultInterval(t *testing.T) {
+	settler := NewMerchantSettler(new(MockMerchantSettlementRunner), 0)
+	assert.Equal(t, time.Hour, settler.interval)
+}
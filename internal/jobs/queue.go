@@ -0,0 +1,333 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/darisadam/madabank-server/internal/domain/queue"
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/pkg/metrics"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultQueueWorkers      = 4
+	defaultQueuePollInterval = time.Second
+	defaultQueueLockTimeout  = 15 * time.Minute
+	defaultQueueRetention    = 7 * 24 * time.Hour
+	defaultJobTimeout        = 5 * time.Minute
+	queueMaintenanceInterval = time.Minute
+)
+
+// JobHandler processes one job's payload. Returning an error retries the job
+// with exponential backoff until its attempts are exhausted, after which it
+// moves to the dead-letter queue.
+type JobHandler func(ctx context.Context, payload json.RawMessage) error
+
+// KindOptions configures how jobs of one kind are run
+type KindOptions struct {
+	MaxAttempts int           // defaults to queue.DefaultMaxAttempts
+	Timeout     time.Duration // defaults to 5 minutes
+}
+
+// EnqueueOptions configures a single enqueued job
+type EnqueueOptions struct {
+	RunAt     time.Time // zero runs the job as soon as a worker is free
+	UniqueKey string    // enqueueing a second job with the same key is a no-op
+}
+
+// QueueConfig controls the workers of a Queue
+type QueueConfig struct {
+	Workers      int
+	PollInterval time.Duration // how long an idle worker waits before polling again
+	// LockTimeout is how long a job may stay running before it is assumed
+	// its worker crashed and the job is released to run again
+	LockTimeout time.Duration
+	Retention   time.Duration // how long completed jobs are kept
+}
+
+type registration struct {
+	handler JobHandler
+	opts    KindOptions
+}
+
+// Queue runs background jobs persisted in Postgres. Every replica runs its own
+// workers against the shared table, and each job is claimed by one worker at
+// a time. Handlers must be registered before Start.
+type Queue struct {
+	repo     repository.JobRepository
+	cfg      QueueConfig
+	workerID string
+	kinds    map[string]registration
+	now      func() time.Time
+}
+
+// ReplicaID identifies this process among the API replicas
+func ReplicaID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
+func NewQueue(repo repository.JobRepository, cfg QueueConfig) *Queue {
+	if cfg.Workers <= 0 {
+		cfg.Workers = defaultQueueWorkers
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaultQueuePollInterval
+	}
+	if cfg.LockTimeout <= 0 {
+		cfg.LockTimeout = defaultQueueLockTimeout
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = defaultQueueRetention
+	}
+
+	return &Queue{
+		repo:     repo,
+		cfg:      cfg,
+		workerID: ReplicaID(),
+		kinds:    map[string]registration{},
+		now:      time.Now,
+	}
+}
+
+// Register sets the handler for a kind of job
+func (q *Queue) Register(kind string, handler JobHandler, opts KindOptions) {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = queue.DefaultMaxAttempts
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultJobTimeout
+	}
+	q.kinds[kind] = registration{handler: handler, opts: opts}
+}
+
+// Enqueue persists a job with a JSON-encoded payload. It returns false when a
+// job with the same unique key already exists.
+func (q *Queue) Enqueue(kind string, payload interface{}, opts EnqueueOptions) (bool, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode job payload: %w", err)
+	}
+
+	job := &queue.Job{
+		ID:          uuid.New(),
+		Kind:        kind,
+		Payload:     body,
+		MaxAttempts: queue.DefaultMaxAttempts,
+		RunAt:       opts.RunAt,
+	}
+	if reg, ok := q.kinds[kind]; ok {
+		job.MaxAttempts = reg.opts.MaxAttempts
+	}
+	if job.RunAt.IsZero() {
+		job.RunAt = q.now()
+	}
+	if opts.UniqueKey != "" {
+		job.UniqueKey = &opts.UniqueKey
+	}
+
+	return q.repo.Enqueue(job)
+}
+
+// Start runs the workers and queue maintenance until ctx is cancelled
+func (q *Queue) Start(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < q.cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.work(ctx)
+		}()
+	}
+
+	q.maintain(ctx)
+	wg.Wait()
+}
+
+// Every enqueues a job of the given kind once per interval until ctx is
+// cancelled. The unique key is derived from the interval slot, so replicas
+// running the same schedule enqueue each slot only once.
+func (q *Queue) Every(ctx context.Context, kind string, interval time.Duration) {
+	defer errtrack.RecoverWorker("queue_schedule_" + kind)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		slot := q.now().UTC().Truncate(interval)
+		if _, err := q.Enqueue(kind, struct{}{}, EnqueueOptions{
+			RunAt:     slot,
+			UniqueKey: fmt.Sprintf("%s@%s", kind, slot.Format(time.RFC3339)),
+		}); err != nil {
+			logger.Error("Failed to enqueue scheduled job", zap.String("kind", kind), zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (q *Queue) work(ctx context.Context) {
+	defer errtrack.RecoverWorker("queue_worker")
+
+	for {
+		processed, err := q.RunNext(ctx)
+		if err != nil {
+			logger.Error("Job queue worker failed", zap.Error(err))
+			errtrack.CaptureError(err, map[string]string{"worker": "queue_worker"})
+		}
+
+		if processed && err == nil {
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(q.cfg.PollInterval):
+		}
+	}
+}
+
+func (q *Queue) maintain(ctx context.Context) {
+	defer errtrack.RecoverWorker("queue_maintenance")
+
+	ticker := time.NewTicker(queueMaintenanceInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := q.Maintain(); err != nil {
+			logger.Error("Job queue maintenance failed", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunNext claims and runs one due job of a registered kind. It reports whether
+// a job was run; handler failures are recorded on the job and not returned.
+func (q *Queue) RunNext(ctx context.Context) (bool, error) {
+	kinds := make([]string, 0, len(q.kinds))
+	for kind := range q.kinds {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	job, err := q.repo.Claim(kinds, q.workerID, q.now())
+	if err != nil || job == nil {
+		return false, err
+	}
+
+	reg := q.kinds[job.Kind]
+	started := time.Now()
+	runErr := q.execute(ctx, reg, job)
+	duration := time.Since(started).Seconds()
+
+	if runErr == nil {
+		metrics.RecordQueueJob(job.Kind, "completed", duration)
+		return true, q.finished(job, q.repo.Complete(job.ID, q.workerID, q.now()))
+	}
+
+	if job.Exhausted() {
+		metrics.RecordQueueJob(job.Kind, "dead", duration)
+		logger.Error("Job moved to dead-letter queue",
+			zap.String("job_id", job.ID.String()),
+			zap.String("kind", job.Kind),
+			zap.Int("attempts", job.Attempts),
+			zap.Error(runErr),
+		)
+		errtrack.CaptureError(runErr, map[string]string{"worker": "queue_worker", "kind": job.Kind})
+		return true, q.finished(job, q.repo.Bury(job.ID, q.workerID, runErr.Error(), q.now()))
+	}
+
+	retryAt := q.now().Add(queue.Backoff(job.Attempts))
+	metrics.RecordQueueJob(job.Kind, "retried", duration)
+	logger.Warn("Job failed, will retry",
+		zap.String("job_id", job.ID.String()),
+		zap.String("kind", job.Kind),
+		zap.Int("attempt", job.Attempts),
+		zap.Time("retry_at", retryAt),
+		zap.Error(runErr),
+	)
+	return true, q.finished(job, q.repo.Reschedule(job.ID, q.workerID, runErr.Error(), retryAt))
+}
+
+// finished passes on the error from recording a job's outcome. A lost lock is
+// only logged: the job was requeued while it ran and another attempt owns it.
+func (q *Queue) finished(job *queue.Job, err error) error {
+	if errors.Is(err, queue.ErrLockLost) {
+		logger.Warn("Job lock lost before it finished, outcome discarded",
+			zap.String("job_id", job.ID.String()),
+			zap.String("kind", job.Kind),
+			zap.String("worker_id", q.workerID),
+		)
+		return nil
+	}
+	return err
+}
+
+// execute runs the handler with the kind's timeout, turning a panic into an error
+func (q *Queue) execute(ctx context.Context, reg registration, job *queue.Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, reg.opts.Timeout)
+	defer cancel()
+
+	return reg.handler(ctx, job.Payload)
+}
+
+// Maintain releases jobs whose worker died, prunes old completed jobs and
+// updates the queue depth metrics
+func (q *Queue) Maintain() error {
+	now := q.now()
+
+	released, err := q.repo.RequeueStale(now.Add(-q.cfg.LockTimeout), now)
+	if err != nil {
+		return err
+	}
+	if released > 0 {
+		logger.Warn("Released jobs with expired worker locks", zap.Int64("count", released))
+	}
+
+	if _, err := q.repo.DeleteCompletedBefore(now.Add(-q.cfg.Retention)); err != nil {
+		return err
+	}
+
+	counts, err := q.repo.CountByStatus()
+	if err != nil {
+		return err
+	}
+	for _, status := range []queue.Status{queue.StatusPending, queue.StatusRunning, queue.StatusCompleted, queue.StatusDead} {
+		metrics.UpdateQueueDepth(string(status), counts[status])
+	}
+
+	return nil
+}
@@ -0,0 +1,197 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/darisadam/madabank-server/internal/domain/queue"
+)
+
+type MockJobRepository struct {
+	mock.Mock
+}
+
+func (m *MockJobRepository) Enqueue(job *queue.Job) (bool, error) {
+	args := m.Called(job)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockJobRepository) Claim(kinds []string, workerID string, now time.Time) (*queue.Job, error) {
+	args := m.Called(kinds, workerID, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*queue.Job), args.Error(1)
+}
+
+func (m *MockJobRepository) Complete(id uuid.UUID, workerID string, now time.Time) error {
+	args := m.Called(id, workerID, now)
+	return args.Error(0)
+}
+
+func (m *MockJobRepository) Reschedule(id uuid.UUID, workerID, lastError string, runAt time.Time) error {
+	args := m.Called(id, workerID, lastError, runAt)
+	return args.Error(0)
+}
+
+func (m *MockJobRepository) Bury(id uuid.UUID, workerID, lastError string, now time.Time) error {
+	args := m.Called(id, workerID, lastError, now)
+	return args.Error(0)
+}
+
+func (m *MockJobRepository) RequeueStale(cutoff, now time.Time) (int64, error) {
+	args := m.Called(cutoff, now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockJobRepository) Retry(id uuid.UUID, now time.Time) (*queue.Job, error) {
+	args := m.Called(id, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*queue.Job), args.Error(1)
+}
+
+func (m *MockJobRepository) DeleteCompletedBefore(cutoff time.Time) (int64, error) {
+	args := m.Called(cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockJobRepository) GetByID(id uuid.UUID) (*queue.Job, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*queue.Job), args.Error(1)
+}
+
+func (m *MockJobRepository) List(status queue.Status, kind string, limit int) ([]*queue.Job, error) {
+	args := m.Called(status, kind, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*queue.Job), args.Error(1)
+}
+
+func (m *MockJobRepository) CountByStatus() (map[queue.Status]int, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[queue.Status]int), args.Error(1)
+}
+
+var queueNow = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestQueue(repo *MockJobRepository, handler JobHandler) *Queue {
+	q := NewQueue(repo, QueueConfig{})
+	q.now = func() time.Time { return queueNow }
+	q.Register("send_email", handler, KindOptions{MaxAttempts: 3})
+	return q
+}
+
+func TestQueue_EnqueueUsesKindOptions(t *testing.T) {
+	repo := new(MockJobRepository)
+	q := newTestQueue(repo, nil)
+	repo.On("Enqueue", mock.MatchedBy(func(j *queue.Job) bool {
+		return j.Kind == "send_email" && j.MaxAttempts == 3 && j.RunAt.Equal(queueNow) &&
+			*j.UniqueKey == "welcome:42" && string(j.Payload) == `{"to":"a@b.c"}`
+	})).Return(true, nil)
+
+	enqueued, err := q.Enqueue("send_email", map[string]string{"to": "a@b.c"}, EnqueueOptions{UniqueKey: "welcome:42"})
+
+	assert.NoError(t, err)
+	assert.True(t, enqueued)
+	repo.AssertExpectations(t)
+}
+
+func TestQueue_RunNextCompletesJob(t *testing.T) {
+	repo := new(MockJobRepository)
+	var got json.RawMessage
+	q := newTestQueue(repo, func(_ context.Context, payload json.RawMessage) error {
+		got = payload
+		return nil
+	})
+	job := &queue.Job{ID: uuid.New(), Kind: "send_email", Payload: json.RawMessage(`{"to":"a@b.c"}`), Attempts: 1, MaxAttempts: 3}
+	repo.On("Claim", []string{"send_email"}, q.workerID, queueNow).Return(job, nil)
+	repo.On("Complete", job.ID, q.workerID, queueNow).Return(nil)
+
+	processed, err := q.RunNext(context.Background())
+
+	assert.NoError(t, err)
+	assert.True(t, processed)
+	assert.JSONEq(t, `{"to":"a@b.c"}`, string(got))
+	repo.AssertExpectations(t)
+}
+
+func TestQueue_RunNextReschedulesWithBackoff(t *testing.T) {
+	repo := new(MockJobRepository)
+	q := newTestQueue(repo, func(context.Context, json.RawMessage) error { return fmt.Errorf("smtp timeout") })
+	job := &queue.Job{ID: uuid.New(), Kind: "send_email", Attempts: 2, MaxAttempts: 3}
+	repo.On("Claim", mock.Anything, mock.Anything, mock.Anything).Return(job, nil)
+	repo.On("Reschedule", job.ID, q.workerID, "smtp timeout", queueNow.Add(30*time.Second)).Return(nil)
+
+	processed, err := q.RunNext(context.Background())
+
+	assert.NoError(t, err)
+	assert.True(t, processed)
+	repo.AssertExpectations(t)
+}
+
+func TestQueue_RunNextBuriesExhaustedJob(t *testing.T) {
+	repo := new(MockJobRepository)
+	q := newTestQueue(repo, func(context.Context, json.RawMessage) error { panic("nil map") })
+	job := &queue.Job{ID: uuid.New(), Kind: "send_email", Attempts: 3, MaxAttempts: 3}
+	repo.On("Claim", mock.Anything, mock.Anything, mock.Anything).Return(job, nil)
+	repo.On("Bury", job.ID, q.workerID, "job panicked: nil map", queueNow).Return(nil)
+
+	processed, err := q.RunNext(context.Background())
+
+	assert.NoError(t, err)
+	assert.True(t, processed)
+	repo.AssertNotCalled(t, "Reschedule", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	repo.AssertExpectations(t)
+}
+
+func TestQueue_RunNextNothingDue(t *testing.T) {
+	repo := new(MockJobRepository)
+	q := newTestQueue(repo, nil)
+	repo.On("Claim", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+
+	processed, err := q.RunNext(context.Background())
+
+	assert.NoError(t, err)
+	assert.False(t, processed)
+}
+
+func TestQueue_Maintain(t *testing.T) {
+	repo := new(MockJobRepository)
+	q := newTestQueue(repo, nil)
+	repo.On("RequeueStale", queueNow.Add(-defaultQueueLockTimeout), queueNow).Return(int64(1), nil)
+	repo.On("DeleteCompletedBefore", queueNow.Add(-defaultQueueRetention)).Return(int64(10), nil)
+	repo.On("CountByStatus").Return(map[queue.Status]int{queue.StatusDead: 2}, nil)
+
+	assert.NoError(t, q.Maintain())
+	repo.AssertExpectations(t)
+}
+
+func TestQueue_RunNextLostLockIsNotAnError(t *testing.T) {
+	repo := new(MockJobRepository)
+	q := newTestQueue(repo, func(context.Context, json.RawMessage) error { return nil })
+	job := &queue.Job{ID: uuid.New(), Kind: "send_email", Attempts: 1, MaxAttempts: 3}
+	repo.On("Claim", mock.Anything, mock.Anything, mock.Anything).Return(job, nil)
+	repo.On("Complete", job.ID, q.workerID, queueNow).Return(queue.ErrLockLost)
+
+	processed, err := q.RunNext(context.Background())
+
+	assert.NoError(t, err)
+	assert.True(t, processed)
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,68 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+)
+
+// RegulatoryReportRunner generates the daily regulatory reports
+type RegulatoryReportRunner interface {
+	RunScheduled(now time.Time) (int, error)
+}
+
+// RegulatoryReporter generates the previous day's large and suspicious
+// transaction reports. It checks hourly and the runner skips reports already
+// generated, so they are ready shortly after midnight.
+type RegulatoryReporter struct {
+	runner   RegulatoryReportRunner
+	interval time.Duration
+	now      func() time.Time
+}
+
+func NewRegulatoryReporter(runner RegulatoryReportRunner, interval time.Duration) *RegulatoryReporter {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	return &RegulatoryReporter{
+		runner:   runner,
+		interval: interval,
+		now:      time.Now,
+	}
+}
+
+// Start runs the reporter every configured interval until ctx is cancelled
+func (r *RegulatoryReporter) Start(ctx context.Context) {
+	defer errtrack.RecoverWorker("regulatory_reporter")
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.RunOnce()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce generates the reports that are due and returns how many were generated
+func (r *RegulatoryReporter) RunOnce() int {
+	generated, err := r.runner.RunScheduled(r.now())
+	if err != nil {
+		logger.Error("Regulatory report run failed", zap.Error(err))
+		errtrack.CaptureError(err, map[string]string{"worker": "regulatory_reporter"})
+	}
+
+	if generated > 0 {
+		logger.Info("Generated regulatory reports", zap.Int("count", generated))
+	}
+	return generated
+}
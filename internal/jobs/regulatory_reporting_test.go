@@ -0,0 +1,40 @@
+package jobs
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRegulatoryReportRunner struct {
+	mock.Mock
+}
+
+func (m *MockRegulatoryReportRunner) RunScheduled(now time.Time) (int, error) {
+	args := m.Called(now)
+	return args.Int(0), args.Error(1)
+}
+
+func TestRegulatoryReporter_RunOnce(t *testing.T) {
+	runner := new(MockRegulatoryReportRunner)
+	now := time.Date(2026, 3, 1, 17, 5, 0, 0, time.UTC)
+	reporter := NewRegulatoryReporter(runner, 0)
+	reporter.now = func() time.Time { return now }
+
+	runner.On("RunScheduled", now).Return(2, nil).Once()
+	assert.Equal(t, 2, reporter.RunOnce())
+
+	// A report generated before the failure still counts
+	runner.On("RunScheduled", now).Return(1, fmt.Errorf("db down")).Once()
+	assert.Equal(t, 1, reporter.RunOnce())
+
+	runner.AssertExpectations(t)
+}
+
+func TestNewRegulatoryReporter_DefaultInterval(t *testing.T) {
+	reporter := NewRegulatoryReporter(new(MockRegulatoryReportRunner), 0)
+	assert.Equal(t, time.Hour, reporter.interval)
+}
@@ -0,0 +1,222 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/darisadam/madabank-server/internal/pkg/cron"
+	"github.com/darisadam/madabank-server/internal/pkg/distlock"
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/pkg/metrics"
+	"github.com/darisadam/madabank-server/internal/pkg/metricspush"
+)
+
+const (
+	schedulerTickInterval = 15 * time.Second
+	// scheduledTaskLockTTL is renewed while the task runs, so it only bounds
+	// how long a crashed run blocks the task
+	scheduledTaskLockTTL = time.Minute
+)
+
+// TaskFunc runs one scheduled slot of a task. now is the time the slot fired
+// in the scheduler's location.
+type TaskFunc func(ctx context.Context, now time.Time) error
+
+// Leadership reports whether this replica may run singleton work
+type Leadership interface {
+	IsLeader() bool
+}
+
+// TaskLocker runs fn while holding a lock shared by all replicas, returning
+// distlock.ErrNotAcquired if another replica holds it
+type TaskLocker interface {
+	WithLock(ctx context.Context, name string, ttl time.Duration, fn func(ctx context.Context, token int64) error) error
+}
+
+type scheduledTask struct {
+	name     string
+	schedule *cron.Schedule
+	run      TaskFunc
+	next     time.Time
+	running  atomic.Bool
+}
+
+// CountTask adapts a run that returns how many items it processed, such as
+// statements closed or merchants settled, into a TaskFunc
+func CountTask(what string, run func(now time.Time) (int, error)) TaskFunc {
+	return func(_ context.Context, now time.Time) error {
+		n, err := run(now)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			logger.Info("Scheduled task processed items", zap.String("items", what), zap.Int("count", n))
+		}
+		return nil
+	}
+}
+
+// Scheduler runs tasks on cron schedules. Every replica keeps time, but only
+// the elected leader runs due tasks, so running several API replicas does not
+// post interest or settle merchants twice. Slots that fall while no replica
+// is leader are skipped rather than caught up, so tasks must be idempotent
+// and pick up whatever a missed run left over.
+type Scheduler struct {
+	leader    Leadership
+	locker    TaskLocker            // optional, see UseLocker
+	publisher metricspush.Publisher // optional, see UsePublisher
+	loc       *time.Location        // cron expressions are evaluated in this zone
+	tasks     []*scheduledTask
+	wg        sync.WaitGroup
+	now       func() time.Time
+}
+
+func NewScheduler(leader Leadership, loc *time.Location) *Scheduler {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return &Scheduler{
+		leader: leader,
+		loc:    loc,
+		now:    time.Now,
+	}
+}
+
+// UseLocker makes every run hold a per-task lock, so a run that the previous
+// leader started before losing its lease never overlaps one on the new leader
+func (s *Scheduler) UseLocker(locker TaskLocker) {
+	s.locker = locker
+}
+
+// UsePublisher pushes the outcome of every run under the task's name, for a
+// monitoring stack that would otherwise miss runs shorter than its scrape
+// interval
+func (s *Scheduler) UsePublisher(publisher metricspush.Publisher) {
+	s.publisher = publisher
+}
+
+// Add registers a task under a unique name with a cron expression
+func (s *Scheduler) Add(name, spec string, run TaskFunc) error {
+	schedule, err := cron.Parse(spec)
+	if err != nil {
+		return fmt.Errorf("failed to schedule %s: %w", name, err)
+	}
+	for _, t := range s.tasks {
+		if t.name == name {
+			return fmt.Errorf("task %s is already scheduled", name)
+		}
+	}
+
+	s.tasks = append(s.tasks, &scheduledTask{
+		name:     name,
+		schedule: schedule,
+		run:      run,
+		next:     schedule.Next(s.now().In(s.loc)),
+	})
+	return nil
+}
+
+// Start checks for due tasks until ctx is cancelled, then waits for running
+// tasks to finish
+func (s *Scheduler) Start(ctx context.Context) {
+	defer errtrack.RecoverWorker("scheduler")
+
+	ticker := time.NewTicker(schedulerTickInterval)
+	defer ticker.Stop()
+
+	for {
+		s.Tick(ctx)
+
+		select {
+		case <-ctx.Done():
+			s.wg.Wait()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick starts every task whose slot has come and returns their names. Tasks
+// run in the background; a task still running from its previous slot is not
+// started again.
+func (s *Scheduler) Tick(ctx context.Context) []string {
+	now := s.now().In(s.loc)
+	leader := s.leader.IsLeader()
+	metrics.SetSchedulerLeader(leader)
+
+	var started []string
+	for _, t := range s.tasks {
+		if t.next.IsZero() || now.Before(t.next) {
+			continue
+		}
+		t.next = t.schedule.Next(now)
+
+		if !leader {
+			continue
+		}
+		if !t.running.CompareAndSwap(false, true) {
+			logger.Warn("Scheduled task still running, skipping slot", zap.String("task", t.name))
+			continue
+		}
+
+		started = append(started, t.name)
+		s.wg.Add(1)
+		go s.runTask(ctx, t, now)
+	}
+	return started
+}
+
+func (s *Scheduler) runTask(ctx context.Context, t *scheduledTask, now time.Time) {
+	defer s.wg.Done()
+	defer t.running.Store(false)
+	defer errtrack.RecoverWorker("scheduled_" + t.name)
+
+	started := time.Now()
+	err := s.execute(ctx, t, now)
+	if errors.Is(err, distlock.ErrNotAcquired) {
+		logger.Warn("Scheduled task running on another replica, skipping slot", zap.String("task", t.name))
+		return
+	}
+	finished := time.Now()
+	metrics.RecordScheduledTaskRun(t.name, err == nil, finished)
+	s.publishRun(t.name, started, finished, err)
+
+	if err != nil {
+		logger.Error("Scheduled task failed", zap.String("task", t.name), zap.Error(err))
+		errtrack.CaptureError(err, map[string]string{"worker": "scheduler", "task": t.name})
+		return
+	}
+	logger.Info("Scheduled task completed",
+		zap.String("task", t.name),
+		zap.Duration("duration", time.Since(started)),
+	)
+}
+
+func (s *Scheduler) publishRun(name string, started, finished time.Time, err error) {
+	if s.publisher == nil {
+		return
+	}
+	if pushErr := s.publisher.Publish(context.Background(), name, metricspush.RunRegistry(started, finished, err)); pushErr != nil {
+		logger.Warn("Failed to publish scheduled task metrics",
+			zap.String("task", name),
+			zap.String("publisher", s.publisher.Name()),
+			zap.Error(pushErr),
+		)
+	}
+}
+
+func (s *Scheduler) execute(ctx context.Context, t *scheduledTask, now time.Time) error {
+	if s.locker == nil {
+		return t.run(ctx, now)
+	}
+	return s.locker.WithLock(ctx, "scheduler:task:"+t.name, scheduledTaskLockTTL, func(ctx context.Context, _ int64) error {
+		return t.run(ctx, now)
+	})
+}
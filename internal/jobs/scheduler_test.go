@@ -0,0 +1,182 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/darisadam/madabank-server/internal/pkg/distlock"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+)
+
+type staticLeadership bool
+
+func (l staticLeadership) IsLeader() bool { return bool(l) }
+
+func newTestScheduler(leader bool, start time.Time) (*Scheduler, *time.Time) {
+	clock := start
+	s := NewScheduler(staticLeadership(leader), time.UTC)
+	s.now = func() time.Time { return clock }
+	return s, &clock
+}
+
+func TestScheduler_RunsDueTaskOncePerSlot(t *testing.T) {
+	s, clock := newTestScheduler(true, time.Date(2024, 3, 1, 0, 3, 0, 0, time.UTC))
+	var runs atomic.Int32
+	var firedAt time.Time
+	assert.NoError(t, s.Add("interest_accrual", "5 0 * * *", func(_ context.Context, now time.Time) error {
+		runs.Add(1)
+		firedAt = now
+		return nil
+	}))
+
+	assert.Empty(t, s.Tick(context.Background()))
+
+	*clock = time.Date(2024, 3, 1, 0, 5, 10, 0, time.UTC)
+	assert.Equal(t, []string{"interest_accrual"}, s.Tick(context.Background()))
+	s.wg.Wait()
+
+	*clock = time.Date(2024, 3, 1, 0, 5, 25, 0, time.UTC)
+	assert.Empty(t, s.Tick(context.Background()))
+
+	assert.Equal(t, int32(1), runs.Load())
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 5, 10, 0, time.UTC), firedAt)
+}
+
+func TestScheduler_FollowerSkipsSlot(t *testing.T) {
+	s, clock := newTestScheduler(false, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+	var runs atomic.Int32
+	assert.NoError(t, s.Add("card_expiry", "@hourly", func(context.Context, time.Time) error {
+		runs.Add(1)
+		return nil
+	}))
+
+	*clock = time.Date(2024, 3, 1, 1, 0, 0, 0, time.UTC)
+	assert.Empty(t, s.Tick(context.Background()))
+	s.wg.Wait()
+
+	assert.Equal(t, int32(0), runs.Load())
+	assert.Equal(t, time.Date(2024, 3, 1, 2, 0, 0, 0, time.UTC), s.tasks[0].next)
+}
+
+func TestScheduler_SkipsOverlappingRun(t *testing.T) {
+	s, clock := newTestScheduler(true, time.Date(2024, 3, 1, 0, 0, 30, 0, time.UTC))
+	release := make(chan struct{})
+	assert.NoError(t, s.Add("statement_cycle", "* * * * *", func(context.Context, time.Time) error {
+		<-release
+		return fmt.Errorf("statement cycle failed")
+	}))
+
+	*clock = clock.Add(time.Minute)
+	assert.Len(t, s.Tick(context.Background()), 1)
+	*clock = clock.Add(time.Minute)
+	assert.Empty(t, s.Tick(context.Background()))
+
+	close(release)
+	s.wg.Wait()
+	*clock = clock.Add(time.Minute)
+	assert.Len(t, s.Tick(context.Background()), 1)
+	s.wg.Wait()
+}
+
+// recordingPublisher keeps the jobs it was asked to publish
+type recordingPublisher struct {
+	jobs    []string
+	success []float64
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, job string, g prometheus.Gatherer) error {
+	families, err := g.Gather()
+	if err != nil {
+		return err
+	}
+	for _, f := range families {
+		if f.GetName() == "madabank_batch_job_success" {
+			p.success = append(p.success, f.GetMetric()[0].GetGauge().GetValue())
+		}
+	}
+	p.jobs = append(p.jobs, job)
+	return nil
+}
+
+func (p *recordingPublisher) Name() string { return "recording" }
+
+func TestScheduler_PublishesRuns(t *testing.T) {
+	s, clock := newTestScheduler(true, time.Date(2024, 3, 1, 0, 0, 30, 0, time.UTC))
+	publisher := &recordingPublisher{}
+	s.UsePublisher(publisher)
+	fail := false
+	assert.NoError(t, s.Add("merchant_settlement", "* * * * *", func(context.Context, time.Time) error {
+		if fail {
+			return fmt.Errorf("settlement failed")
+		}
+		return nil
+	}))
+
+	*clock = clock.Add(time.Minute)
+	s.Tick(context.Background())
+	s.wg.Wait()
+
+	fail = true
+	*clock = clock.Add(time.Minute)
+	s.Tick(context.Background())
+	s.wg.Wait()
+
+	assert.Equal(t, []string{"merchant_settlement", "merchant_settlement"}, publisher.jobs)
+	assert.Equal(t, []float64{1, 0}, publisher.success)
+}
+
+// heldLocker reports every lock as held by another replica
+type heldLocker struct{}
+
+func (heldLocker) WithLock(context.Context, string, time.Duration, func(context.Context, int64) error) error {
+	return distlock.ErrNotAcquired
+}
+
+func TestScheduler_SkipsTaskLockedElsewhere(t *testing.T) {
+	logger.Init("test")
+	s, clock := newTestScheduler(true, time.Date(2024, 3, 1, 0, 0, 30, 0, time.UTC))
+	s.UseLocker(heldLocker{})
+	var runs atomic.Int32
+	assert.NoError(t, s.Add("statement_cycle", "* * * * *", func(context.Context, time.Time) error {
+		runs.Add(1)
+		return nil
+	}))
+
+	*clock = clock.Add(time.Minute)
+	assert.Len(t, s.Tick(context.Background()), 1)
+	s.wg.Wait()
+
+	assert.Equal(t, int32(0), runs.Load())
+	assert.False(t, s.tasks[0].running.Load())
+}
+
+func TestScheduler_AddRejectsInvalidAndDuplicate(t *testing.T) {
+	s, _ := newTestScheduler(true, time.Now())
+	noop := func(context.Context, time.Time) error { return nil }
+
+	assert.Error(t, s.Add("bad", "every day", noop))
+	assert.NoError(t, s.Add("interest_accrual", "@daily", noop))
+	assert.Error(t, s.Add("interest_accrual", "@hourly", noop))
+}
+
+func TestCountTask_ReturnsRunError(t *testing.T) {
+	logger.Init("test")
+	day := time.Date(2024, 3, 1, 0, 15, 0, 0, time.UTC)
+	var seen time.Time
+
+	ok := CountTask("cards", func(now time.Time) (int, error) {
+		seen = now
+		return 3, nil
+	})
+	failing := CountTask("cards", func(time.Time) (int, error) { return 0, fmt.Errorf("db down") })
+
+	assert.NoError(t, ok(context.Background(), day))
+	assert.Equal(t, day, seen)
+	assert.EqualError(t, failing(context.Background(), day), "db down")
+}
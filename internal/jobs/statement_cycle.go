@@ -0,0 +1,69 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+)
+
+// StatementCloser closes credit card billing cycles that end on a given day
+type StatementCloser interface {
+	CloseDueStatements(now time.Time) (int, error)
+}
+
+// StatementCycler periodically closes due credit card statements. Closing is
+// idempotent per card and day, so running more often than daily is safe and
+// lets a failed card be retried the same day.
+type StatementCycler struct {
+	closer   StatementCloser
+	interval time.Duration
+	now      func() time.Time
+}
+
+func NewStatementCycler(closer StatementCloser, interval time.Duration) *StatementCycler {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	return &StatementCycler{
+		closer:   closer,
+		interval: interval,
+		now:      time.Now,
+	}
+}
+
+// Start runs the cycler every configured interval until ctx is cancelled
+func (s *StatementCycler) Start(ctx context.Context) {
+	defer errtrack.RecoverWorker("statement_cycler")
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.RunOnce()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce closes every statement due now and returns how many were closed
+func (s *StatementCycler) RunOnce() int {
+	closed, err := s.closer.CloseDueStatements(s.now())
+	if err != nil {
+		logger.Error("Statement cycle run failed", zap.Error(err))
+		errtrack.CaptureError(err, map[string]string{"worker": "statement_cycler"})
+		return 0
+	}
+
+	if closed > 0 {
+		logger.Info("Closed credit card statements", zap.Int("count", closed))
+	}
+	return closed
+}
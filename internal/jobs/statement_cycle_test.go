@@ -0,0 +1,39 @@
+package jobs
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockStatementCloser struct {
+	mock.Mock
+}
+
+func (m *MockStatementCloser) CloseDueStatements(now time.Time) (int, error) {
+	args := m.Called(now)
+	return args.Int(0), args.Error(1)
+}
+
+func TestStatementCycler_RunOnce(t *testing.T) {
+	closer := new(MockStatementCloser)
+	now := time.Date(2026, 2, 10, 1, 0, 0, 0, time.UTC)
+	cycler := NewStatementCycler(closer, 0)
+	cycler.now = func() time.Time { return now }
+
+	closer.On("CloseDueStatements", now).Return(3, nil).Once()
+	assert.Equal(t, 3, cycler.RunOnce())
+
+	closer.On("CloseDueStatements", now).Return(0, fmt.Errorf("db down")).Once()
+	assert.Equal(t, 0, cycler.RunOnce())
+
+	closer.AssertExpectations(t)
+}
+
+func TestNewStatementCycler_DefaultInterval(t *testing.T) {
+	cycler := NewStatementCycler(new(MockStatementCloser), 0)
+	assert.Equal(t, time.Hour, cycler.interval)
+}
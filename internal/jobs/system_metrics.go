@@ -0,0 +1,118 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/pkg/metrics"
+)
+
+// KindSystemMetrics refreshes the business and connection pool gauges
+const KindSystemMetrics = "system_metrics"
+
+// DefaultSystemMetricsInterval is how often KindSystemMetrics is enqueued
+// unless SYSTEM_METRICS_INTERVAL says otherwise
+const DefaultSystemMetricsInterval = 30 * time.Second
+
+// SystemStats counts the business entities reported as metrics
+type SystemStats interface {
+	CountUsers() (total int, active int, err error)
+	CountActiveAccountsByType() (map[account.AccountType]int, error)
+	SumActiveBalances() ([]*account.BalanceTotal, error)
+	// PendingTransactions counts transactions still pending and returns when
+	// the oldest of them was created, nil when there are none
+	PendingTransactions() (count int, oldest *time.Time, err error)
+}
+
+// MetricCollector refreshes one group of gauges
+type MetricCollector func(ctx context.Context) error
+
+type namedCollector struct {
+	name    string
+	collect MetricCollector
+}
+
+// SystemMetricsCollector is the queue handler for KindSystemMetrics. It runs
+// every registered collector; one failing does not stop the others. Pool
+// stats are those of the replica that ran the job.
+type SystemMetricsCollector struct {
+	collectors []namedCollector
+	now        func() time.Time
+}
+
+// NewSystemMetricsCollector registers the users, accounts, balances,
+// transaction backlog and connection pool collectors
+func NewSystemMetricsCollector(stats SystemStats, dbStats func() sql.DBStats) *SystemMetricsCollector {
+	c := &SystemMetricsCollector{now: time.Now}
+	c.Register("db_pool", func(context.Context) error {
+		metrics.DBConnectionsActive.Set(float64(dbStats().OpenConnections))
+		return nil
+	})
+	c.Register("users", func(context.Context) error {
+		total, active, err := stats.CountUsers()
+		if err != nil {
+			return err
+		}
+		metrics.UpdateUserMetrics(total, active)
+		return nil
+	})
+	c.Register("accounts", func(context.Context) error {
+		counts, err := stats.CountActiveAccountsByType()
+		if err != nil {
+			return err
+		}
+		for _, accountType := range []account.AccountType{account.AccountTypeChecking, account.AccountTypeSavings} {
+			metrics.UpdateAccountMetrics(string(accountType), counts[accountType])
+		}
+		return nil
+	})
+	c.Register("balances", func(context.Context) error {
+		totals, err := stats.SumActiveBalances()
+		if err != nil {
+			return err
+		}
+		// Drop currencies no account holds any more
+		metrics.TotalBalanceByType.Reset()
+		for _, t := range totals {
+			metrics.TotalBalanceByType.WithLabelValues(string(t.AccountType), t.Currency).Set(t.Total)
+		}
+		return nil
+	})
+	c.Register("transaction_backlog", func(context.Context) error {
+		count, oldest, err := stats.PendingTransactions()
+		if err != nil {
+			return err
+		}
+		var age time.Duration
+		if oldest != nil {
+			age = c.now().Sub(*oldest)
+		}
+		metrics.UpdatePendingTransactions(count, age)
+		return nil
+	})
+	return c
+}
+
+// Register adds a collector run on every KindSystemMetrics job
+func (c *SystemMetricsCollector) Register(name string, collect MetricCollector) {
+	c.collectors = append(c.collectors, namedCollector{name: name, collect: collect})
+}
+
+// Handle runs the collectors; it matches JobHandler
+func (c *SystemMetricsCollector) Handle(ctx context.Context, _ json.RawMessage) error {
+	var errs []error
+	for _, collector := range c.collectors {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := collector.collect(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", collector.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,101 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/pkg/metrics"
+)
+
+type stubSystemStats struct {
+	total, active int
+	accounts      map[account.AccountType]int
+	balances      []*account.BalanceTotal
+	pending       int
+	oldest        *time.Time
+	err           error
+}
+
+func (s *stubSystemStats) CountUsers() (int, int, error) {
+	return s.total, s.active, s.err
+}
+
+func (s *stubSystemStats) CountActiveAccountsByType() (map[account.AccountType]int, error) {
+	return s.accounts, nil
+}
+
+func (s *stubSystemStats) SumActiveBalances() ([]*account.BalanceTotal, error) {
+	return s.balances, nil
+}
+
+func (s *stubSystemStats) PendingTransactions() (int, *time.Time, error) {
+	return s.pending, s.oldest, nil
+}
+
+func TestSystemMetricsCollector_Handle(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	oldest := now.Add(-90 * time.Second)
+	stats := &stubSystemStats{
+		total:    10,
+		active:   7,
+		accounts: map[account.AccountType]int{account.AccountTypeSavings: 4},
+		balances: []*account.BalanceTotal{
+			{AccountType: account.AccountTypeSavings, Currency: "IDR", Total: 2500000},
+			{AccountType: account.AccountTypeChecking, Currency: "USD", Total: 120.5},
+		},
+		pending: 2,
+		oldest:  &oldest,
+	}
+	collector := NewSystemMetricsCollector(stats, func() sql.DBStats { return sql.DBStats{OpenConnections: 3} })
+	collector.now = func() time.Time { return now }
+
+	assert.NoError(t, collector.Handle(context.Background(), nil))
+	assert.Equal(t, 10.0, testutil.ToFloat64(metrics.UsersTotal))
+	assert.Equal(t, 7.0, testutil.ToFloat64(metrics.ActiveUsersTotal))
+	assert.Equal(t, 4.0, testutil.ToFloat64(metrics.AccountsTotal.WithLabelValues("savings")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.AccountsTotal.WithLabelValues("checking")))
+	assert.Equal(t, 3.0, testutil.ToFloat64(metrics.DBConnectionsActive))
+	assert.Equal(t, 2500000.0, testutil.ToFloat64(metrics.TotalBalanceByType.WithLabelValues("savings", "IDR")))
+	assert.Equal(t, 120.5, testutil.ToFloat64(metrics.TotalBalanceByType.WithLabelValues("checking", "USD")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.TransactionsPending))
+	assert.Equal(t, 90.0, testutil.ToFloat64(metrics.TransactionsPendingOldestSeconds))
+
+	// The backlog cleared and the USD accounts closed
+	stats.pending, stats.oldest = 0, nil
+	stats.balances = stats.balances[:1]
+	assert.NoError(t, collector.Handle(context.Background(), nil))
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.TransactionsPendingOldestSeconds))
+	assert.Equal(t, 1, testutil.CollectAndCount(metrics.TotalBalanceByType))
+}
+
+func TestSystemMetricsCollector_HandleError(t *testing.T) {
+	collector := NewSystemMetricsCollector(&stubSystemStats{err: fmt.Errorf("db down"), pending: 5}, func() sql.DBStats { return sql.DBStats{} })
+
+	err := collector.Handle(context.Background(), nil)
+	assert.EqualError(t, err, "users: db down")
+	// The other collectors still ran
+	assert.Equal(t, 5.0, testutil.ToFloat64(metrics.TransactionsPending))
+}
+
+func TestSystemMetricsCollector_Register(t *testing.T) {
+	collector := NewSystemMetricsCollector(&stubSystemStats{}, func() sql.DBStats { return sql.DBStats{} })
+	var ran bool
+	collector.Register("custom", func(context.Context) error {
+		ran = true
+		return nil
+	})
+
+	assert.NoError(t, collector.Handle(context.Background(), nil))
+	assert.True(t, ran)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, collector.Handle(ctx, nil), context.Canceled)
+}
@@ -0,0 +1,68 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+)
+
+// TopupSyncer drives unfinished top-ups towards a final status
+type TopupSyncer interface {
+	SyncUnfinished(now time.Time) (int, error)
+}
+
+// TopupTracker periodically resubmits top-ups the aggregator has not accepted
+// yet and polls accepted ones until they are delivered or fail.
+type TopupTracker struct {
+	syncer   TopupSyncer
+	interval time.Duration
+	now      func() time.Time
+}
+
+func NewTopupTracker(syncer TopupSyncer, interval time.Duration) *TopupTracker {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	return &TopupTracker{
+		syncer:   syncer,
+		interval: interval,
+		now:      time.Now,
+	}
+}
+
+// Start runs the tracker every configured interval until ctx is cancelled
+func (t *TopupTracker) Start(ctx context.Context) {
+	defer errtrack.RecoverWorker("topup_tracker")
+
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		t.RunOnce()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce syncs one batch of unfinished top-ups and returns how many finished
+func (t *TopupTracker) RunOnce() int {
+	finished, err := t.syncer.SyncUnfinished(t.now())
+	if err != nil {
+		logger.Error("Top-up sync run failed", zap.Error(err))
+		errtrack.CaptureError(err, map[string]string{"worker": "topup_tracker"})
+		return 0
+	}
+
+	if finished > 0 {
+		logger.Info("Finished top-ups", zap.Int("count", finished))
+	}
+	return finished
+}
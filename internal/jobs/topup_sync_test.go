@@ -0,0 +1,39 @@
+package jobs
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTopupSyncer struct {
+	mock.Mock
+}
+
+func (m *MockTopupSyncer) SyncUnfinished(now time.Time) (int, error) {
+	args := m.Called(now)
+	return args.Int(0), args.Error(1)
+}
+
+func TestTopupTracker_RunOnce(t *testing.T) {
+	syncer := new(MockTopupSyncer)
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	tracker := NewTopupTracker(syncer, 0)
+	tracker.now = func() time.Time { return now }
+
+	syncer.On("SyncUnfinished", now).Return(4, nil).Once()
+	assert.Equal(t, 4, tracker.RunOnce())
+
+	syncer.On("SyncUnfinished", now).Return(0, fmt.Errorf("db down")).Once()
+	assert.Equal(t, 0, tracker.RunOnce())
+
+	syncer.AssertExpectations(t)
+}
+
+func TestNewTopupTracker_DefaultInterval(t *testing.T) {
+	tracker := NewTopupTracker(new(MockTopupSyncer), 0)
+	assert.Equal(t, 30*time.Second, tracker.interval)
+}
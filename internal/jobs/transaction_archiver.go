@@ -0,0 +1,133 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/pkg/objectstore"
+	"github.com/darisadam/madabank-server/internal/repository"
+)
+
+const defaultPartitionsAhead = 3
+
+// TransactionArchiveConfig controls transaction partition maintenance and archiving
+type TransactionArchiveConfig struct {
+	// RetentionMonths is how many months before the current one stay in
+	// Postgres. Zero keeps every month.
+	RetentionMonths int
+	PartitionsAhead int
+	Interval        time.Duration
+}
+
+// TransactionArchiver creates monthly transaction partitions ahead of time and
+// moves months older than the retention window to object storage as
+// gzip-compressed JSONL. A partition is only dropped after its upload succeeded.
+type TransactionArchiver struct {
+	archiveRepo repository.TransactionArchiveRepository
+	store       objectstore.Store // nil disables archiving
+	cfg         TransactionArchiveConfig
+	now         func() time.Time
+}
+
+func NewTransactionArchiver(archiveRepo repository.TransactionArchiveRepository, store objectstore.Store, cfg TransactionArchiveConfig) *TransactionArchiver {
+	if cfg.PartitionsAhead <= 0 {
+		cfg.PartitionsAhead = defaultPartitionsAhead
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = 24 * time.Hour
+	}
+	return &TransactionArchiver{
+		archiveRepo: archiveRepo,
+		store:       store,
+		cfg:         cfg,
+		now:         time.Now,
+	}
+}
+
+// Start runs the archiver every configured interval until ctx is cancelled
+func (a *TransactionArchiver) Start(ctx context.Context) {
+	defer errtrack.RecoverWorker("transaction_archiver")
+
+	ticker := time.NewTicker(a.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := a.RunOnce(ctx); err != nil {
+			logger.Error("Transaction archive run failed", zap.Error(err))
+			errtrack.CaptureError(err, map[string]string{"worker": "transaction_archiver"})
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce creates upcoming partitions, archives every eligible month and
+// returns the number of months archived
+func (a *TransactionArchiver) RunOnce(ctx context.Context) (int, error) {
+	now := a.now()
+	if err := a.archiveRepo.EnsurePartitions(now, a.cfg.PartitionsAhead+1); err != nil {
+		return 0, err
+	}
+
+	if a.store == nil || a.cfg.RetentionMonths <= 0 {
+		return 0, nil
+	}
+
+	months, err := a.archiveRepo.ListPartitionMonths()
+	if err != nil {
+		return 0, err
+	}
+
+	cutoff := transaction.MonthStart(now).AddDate(0, -a.cfg.RetentionMonths, 0)
+	archived := 0
+	for _, month := range months {
+		if !month.Before(cutoff) {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return archived, err
+		}
+
+		txns, err := a.archiveRepo.ListPartition(month)
+		if err != nil {
+			return archived, err
+		}
+		data, err := transaction.EncodeArchive(txns)
+		if err != nil {
+			return archived, err
+		}
+
+		key := transaction.ArchiveKey(month)
+		if err := a.store.Put(ctx, key, data, "application/gzip"); err != nil {
+			return archived, err
+		}
+
+		if err := a.archiveRepo.ArchivePartition(&transaction.Archive{
+			Month:     month,
+			ObjectKey: key,
+			Storage:   a.store.Name(),
+			RowCount:  len(txns),
+		}); err != nil {
+			return archived, err
+		}
+
+		archived++
+		logger.Info("Archived transaction partition",
+			zap.String("month", month.Format("2006-01")),
+			zap.String("key", key),
+			zap.Int("rows", len(txns)),
+			zap.Int("bytes", len(data)),
+		)
+	}
+
+	return archived, nil
+}
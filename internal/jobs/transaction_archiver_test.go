@@ -0,0 +1,123 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/pkg/objectstore"
+)
+
+type MockTransactionArchiveRepository struct {
+	mock.Mock
+}
+
+func (m *MockTransactionArchiveRepository) EnsurePartitions(from time.Time, months int) error {
+	args := m.Called(from, months)
+	return args.Error(0)
+}
+
+func (m *MockTransactionArchiveRepository) ListPartitionMonths() ([]time.Time, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]time.Time), args.Error(1)
+}
+
+func (m *MockTransactionArchiveRepository) ListPartition(month time.Time) ([]*transaction.Transaction, error) {
+	args := m.Called(month)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*transaction.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionArchiveRepository) ArchivePartition(archive *transaction.Archive) error {
+	args := m.Called(archive)
+	return args.Error(0)
+}
+
+func (m *MockTransactionArchiveRepository) ListArchives(from, to time.Time) ([]*transaction.Archive, error) {
+	args := m.Called(from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*transaction.Archive), args.Error(1)
+}
+
+func (m *MockTransactionArchiveRepository) ListArchived(ctx context.Context, accountID uuid.UUID, from, to time.Time) ([]*transaction.Transaction, error) {
+	args := m.Called(ctx, accountID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*transaction.Transaction), args.Error(1)
+}
+
+func month(year int, m time.Month) time.Time {
+	return time.Date(year, m, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func newTestTransactionArchiver(repo *MockTransactionArchiveRepository, store objectstore.Store) *TransactionArchiver {
+	archiver := NewTransactionArchiver(repo, store, TransactionArchiveConfig{RetentionMonths: 12})
+	archiver.now = func() time.Time { return time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC) }
+	return archiver
+}
+
+func TestTransactionArchiver_ArchivesMonthsBeforeRetention(t *testing.T) {
+	repo := new(MockTransactionArchiveRepository)
+	store := &memoryStore{objects: map[string][]byte{}}
+	archiver := newTestTransactionArchiver(repo, store)
+	txns := []*transaction.Transaction{{ID: uuid.New(), Amount: 100, CreatedAt: time.Date(2023, 5, 3, 0, 0, 0, 0, time.UTC)}}
+
+	repo.On("EnsurePartitions", archiver.now(), 4).Return(nil)
+	repo.On("ListPartitionMonths").Return([]time.Time{month(2023, 5), month(2023, 6), month(2024, 6)}, nil)
+	repo.On("ListPartition", month(2023, 5)).Return(txns, nil)
+	repo.On("ArchivePartition", &transaction.Archive{
+		Month: month(2023, 5), ObjectKey: "transactions/2023/transactions-2023-05.jsonl.gz", Storage: "memory", RowCount: 1,
+	}).Return(nil)
+
+	archived, err := archiver.RunOnce(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, archived)
+	decoded, err := transaction.DecodeArchive(store.objects["transactions/2023/transactions-2023-05.jsonl.gz"])
+	assert.NoError(t, err)
+	assert.Equal(t, txns[0].ID, decoded[0].ID)
+	repo.AssertNotCalled(t, "ListPartition", month(2023, 6))
+	repo.AssertExpectations(t)
+}
+
+func TestTransactionArchiver_KeepsPartitionWhenUploadFails(t *testing.T) {
+	repo := new(MockTransactionArchiveRepository)
+	store := &memoryStore{objects: map[string][]byte{}, err: fmt.Errorf("s3 unavailable")}
+	archiver := newTestTransactionArchiver(repo, store)
+
+	repo.On("EnsurePartitions", mock.Anything, mock.Anything).Return(nil)
+	repo.On("ListPartitionMonths").Return([]time.Time{month(2023, 1)}, nil)
+	repo.On("ListPartition", month(2023, 1)).Return([]*transaction.Transaction{}, nil)
+
+	archived, err := archiver.RunOnce(context.Background())
+
+	assert.Error(t, err)
+	assert.Equal(t, 0, archived)
+	repo.AssertNotCalled(t, "ArchivePartition", mock.Anything)
+}
+
+func TestTransactionArchiver_WithoutStoreOnlyCreatesPartitions(t *testing.T) {
+	repo := new(MockTransactionArchiveRepository)
+	archiver := newTestTransactionArchiver(repo, nil)
+	repo.On("EnsurePartitions", mock.Anything, 4).Return(nil)
+
+	archived, err := archiver.RunOnce(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, archived)
+	repo.AssertNotCalled(t, "ListPartitionMonths")
+}
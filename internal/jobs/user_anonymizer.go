@@ -0,0 +1,67 @@
+package jobs
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+)
+
+const defaultAnonymizeBatchSize = 500
+
+// UserEraser finds soft-deleted users past retention and erases their
+// personal data. It is implemented by repository.UserRepository.
+type UserEraser interface {
+	ListAnonymizable(deletedBefore time.Time, limit int) ([]uuid.UUID, error)
+	Anonymize(id uuid.UUID) error
+}
+
+// UserAnonymizeConfig controls the deleted user retention job
+type UserAnonymizeConfig struct {
+	// RetentionDays is how long a deleted user's personal data is kept
+	RetentionDays int
+	BatchSize     int
+}
+
+// UserAnonymizer overwrites the names, email, phone and date of birth of users
+// deleted more than RetentionDays ago. Their accounts and transactions are
+// kept, so the ledger and regulatory reports stay complete.
+type UserAnonymizer struct {
+	users UserEraser
+	cfg   UserAnonymizeConfig
+}
+
+func NewUserAnonymizer(users UserEraser, cfg UserAnonymizeConfig) *UserAnonymizer {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultAnonymizeBatchSize
+	}
+	return &UserAnonymizer{
+		users: users,
+		cfg:   cfg,
+	}
+}
+
+// Run anonymizes one batch of users due at now and returns how many were
+// anonymized. A user that fails is reported and retried on the next run.
+func (a *UserAnonymizer) Run(now time.Time) (int, error) {
+	cutoff := now.AddDate(0, 0, -a.cfg.RetentionDays)
+	ids, err := a.users.ListAnonymizable(cutoff, a.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	anonymized := 0
+	for _, id := range ids {
+		if err := a.users.Anonymize(id); err != nil {
+			logger.Error("Failed to anonymize deleted user", zap.String("user_id", id.String()), zap.Error(err))
+			errtrack.CaptureError(err, map[string]string{"worker": "user_anonymizer", "user_id": id.String()})
+			continue
+		}
+		anonymized++
+	}
+
+	return anonymized, nil
+}
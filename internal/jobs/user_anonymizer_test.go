@@ -0,0 +1,53 @@
+package jobs
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockUserEraser struct {
+	mock.Mock
+}
+
+func (m *MockUserEraser) ListAnonymizable(deletedBefore time.Time, limit int) ([]uuid.UUID, error) {
+	args := m.Called(deletedBefore, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockUserEraser) Anonymize(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func TestUserAnonymizer_Run(t *testing.T) {
+	users := new(MockUserEraser)
+	now := time.Date(2026, 6, 1, 1, 30, 0, 0, time.UTC)
+	first, second := uuid.New(), uuid.New()
+	users.On("ListAnonymizable", time.Date(2026, 3, 3, 1, 30, 0, 0, time.UTC), 100).Return([]uuid.UUID{first, second}, nil)
+	users.On("Anonymize", first).Return(fmt.Errorf("connection reset"))
+	users.On("Anonymize", second).Return(nil)
+
+	n, err := NewUserAnonymizer(users, UserAnonymizeConfig{RetentionDays: 90, BatchSize: 100}).Run(now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	users.AssertExpectations(t)
+}
+
+func TestUserAnonymizer_ListFails(t *testing.T) {
+	users := new(MockUserEraser)
+	users.On("ListAnonymizable", mock.Anything, defaultAnonymizeBatchSize).Return(nil, fmt.Errorf("db down"))
+
+	_, err := NewUserAnonymizer(users, UserAnonymizeConfig{RetentionDays: 30}).Run(time.Now())
+
+	assert.EqualError(t, err, "db down")
+	users.AssertNotCalled(t, "Anonymize", mock.Anything)
+}
@@ -0,0 +1,63 @@
+package billeragg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+)
+
+var (
+	// ErrBillNotFound is returned when the biller has no open bill for the customer number
+	ErrBillNotFound = errors.New("no outstanding bill found for customer number")
+	// ErrPaymentRejected means the aggregator definitively refused the payment,
+	// so the debit can safely be reversed. Any other Pay error leaves the outcome unknown.
+	ErrPaymentRejected = errors.New("payment rejected by biller")
+)
+
+// Bill is the amount due reported by the aggregator
+type Bill struct {
+	CustomerName string
+	Period       string
+	Amount       float64
+	// Reference identifies the inquiry at the aggregator and is echoed on payment
+	Reference string
+}
+
+// Payment instructs the aggregator to settle a bill previously returned by Inquire
+type Payment struct {
+	// PaymentID is our transaction ID; aggregators use it to deduplicate retries
+	PaymentID        string
+	BillerCode       string
+	CustomerNumber   string
+	InquiryReference string
+	Amount           float64
+}
+
+// Receipt confirms a settled payment
+type Receipt struct {
+	Reference string
+}
+
+// Aggregator connects the bank to billers (utilities, telcos, tax office)
+// through a third-party biller aggregator.
+type Aggregator interface {
+	// Inquire fetches the amount due for a customer number
+	Inquire(ctx context.Context, billerCode, customerNumber string) (*Bill, error)
+	// Pay settles the bill at the biller
+	Pay(ctx context.Context, payment Payment) (*Receipt, error)
+	// Name identifies the aggregator in logs and transaction metadata
+	Name() string
+}
+
+// FromEnv builds the aggregator selected by BILLER_AGGREGATOR
+func FromEnv() (Aggregator, error) {
+	switch os.Getenv("BILLER_AGGREGATOR") {
+	case "", "simulated":
+		return NewSimulatedAggregator(), nil
+	case "http":
+		return NewHTTPAggregator(os.Getenv("BILLER_AGGREGATOR_URL"), os.Getenv("BILLER_AGGREGATOR_API_KEY"))
+	default:
+		return nil, fmt.Errorf("unknown BILLER_AGGREGATOR %q", os.Getenv("BILLER_AGGREGATOR"))
+	}
+}
@@ -0,0 +1,129 @@
+package billeragg
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimulatedAggregator_Inquire(t *testing.T) {
+	agg := NewSimulatedAggregator()
+
+	bill, err := agg.Inquire(context.Background(), "PLN_POSTPAID", "512345678901")
+	assert.NoError(t, err)
+	assert.Equal(t, "CUSTOMER 8901", bill.CustomerName)
+	assert.GreaterOrEqual(t, bill.Amount, 50_000.0)
+	assert.LessOrEqual(t, bill.Amount, 1_500_000.0)
+
+	// The same customer always gets the same bill
+	again, err := agg.Inquire(context.Background(), "PLN_POSTPAID", "512345678901")
+	assert.NoError(t, err)
+	assert.Equal(t, bill.Amount, again.Amount)
+	assert.Equal(t, bill.Reference, again.Reference)
+
+	_, err = agg.Inquire(context.Background(), "PLN_POSTPAID", "512345670000")
+	assert.ErrorIs(t, err, ErrBillNotFound)
+}
+
+func TestSimulatedAggregator_Pay(t *testing.T) {
+	agg := NewSimulatedAggregator()
+
+	receipt, err := agg.Pay(context.Background(), Payment{PaymentID: "txn-1", CustomerNumber: "512345678901"})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, receipt.Reference)
+
+	_, err = agg.Pay(context.Background(), Payment{PaymentID: "txn-2", CustomerNumber: "512345679999"})
+	assert.ErrorIs(t, err, ErrPaymentRejected)
+}
+
+func TestHTTPAggregator_Inquire(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/inquiries", r.URL.Path)
+		assert.Equal(t, "secret", r.Header.Get("X-API-Key"))
+
+		var req inquiryRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		if req.CustomerNumber == "0000" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(inquiryResponse{CustomerName: "BUDI", Period: "2026-01", Amount: 125000, Reference: "INQ-1"})
+	}))
+	defer server.Close()
+
+	agg, err := NewHTTPAggregator(server.URL+"/", "secret")
+	assert.NoError(t, err)
+
+	bill, err := agg.Inquire(context.Background(), "PDAM_JKT", "1234")
+	assert.NoError(t, err)
+	assert.Equal(t, &Bill{CustomerName: "BUDI", Period: "2026-01", Amount: 125000, Reference: "INQ-1"}, bill)
+
+	_, err = agg.Inquire(context.Background(), "PDAM_JKT", "0000")
+	assert.ErrorIs(t, err, ErrBillNotFound)
+}
+
+func TestHTTPAggregator_Pay(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/payments", r.URL.Path)
+
+		var req paymentRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "txn-1", req.PaymentID)
+		assert.Equal(t, "INQ-1", req.InquiryReference)
+
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			_ = json.NewEncoder(w).Encode(paymentResponse{Reference: "PAY-1"})
+		}
+	}))
+	defer server.Close()
+
+	agg, err := NewHTTPAggregator(server.URL, "secret")
+	assert.NoError(t, err)
+	payment := Payment{PaymentID: "txn-1", BillerCode: "PDAM_JKT", CustomerNumber: "1234", InquiryReference: "INQ-1", Amount: 125000}
+
+	receipt, err := agg.Pay(context.Background(), payment)
+	assert.NoError(t, err)
+	assert.Equal(t, "PAY-1", receipt.Reference)
+
+	status = http.StatusUnprocessableEntity
+	_, err = agg.Pay(context.Background(), payment)
+	assert.ErrorIs(t, err, ErrPaymentRejected)
+
+	// Anything else leaves the outcome unknown
+	status = http.StatusBadGateway
+	_, err = agg.Pay(context.Background(), payment)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrPaymentRejected)
+}
+
+func TestNewHTTPAggregator_RequiresConfig(t *testing.T) {
+	_, err := NewHTTPAggregator("", "secret")
+	assert.Error(t, err)
+
+	_, err = NewHTTPAggregator("https://aggregator.example.com", "")
+	assert.Error(t, err)
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("BILLER_AGGREGATOR", "")
+	agg, err := FromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, "simulated", agg.Name())
+
+	t.Setenv("BILLER_AGGREGATOR", "http")
+	t.Setenv("BILLER_AGGREGATOR_URL", "https://aggregator.example.com")
+	t.Setenv("BILLER_AGGREGATOR_API_KEY", "secret")
+	agg, err = FromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, "http", agg.Name())
+
+	t.Setenv("BILLER_AGGREGATOR", "carrier-pigeon")
+	_, err = FromEnv()
+	assert.Error(t, err)
+}
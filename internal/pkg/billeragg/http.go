@@ -0,0 +1,141 @@
+package billeragg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const httpTimeout = 15 * time.Second
+
+// HTTPAggregator talks to a biller aggregator exposing a JSON API:
+//
+//	POST {base}/inquiries  -> 200 bill, 404 when there is nothing to pay
+//	POST {base}/payments   -> 200 receipt, 422 when the biller rejects the payment
+//
+// Requests are authenticated with the X-API-Key header.
+type HTTPAggregator struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+}
+
+func NewHTTPAggregator(baseURL, apiKey string) (*HTTPAggregator, error) {
+	if baseURL == "" {
+		return nil, fmt.Errorf("BILLER_AGGREGATOR_URL is required")
+	}
+	if apiKey == "" {
+		return nil, fmt.Errorf("BILLER_AGGREGATOR_API_KEY is required")
+	}
+	return &HTTPAggregator{
+		client:  &http.Client{Timeout: httpTimeout},
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+	}, nil
+}
+
+type inquiryRequest struct {
+	BillerCode     string `json:"biller_code"`
+	CustomerNumber string `json:"customer_number"`
+}
+
+type inquiryResponse struct {
+	CustomerName string  `json:"customer_name"`
+	Period       string  `json:"period"`
+	Amount       float64 `json:"amount"`
+	Reference    string  `json:"reference"`
+}
+
+type paymentRequest struct {
+	PaymentID        string  `json:"payment_id"`
+	BillerCode       string  `json:"biller_code"`
+	CustomerNumber   string  `json:"customer_number"`
+	InquiryReference string  `json:"inquiry_reference"`
+	Amount           float64 `json:"amount"`
+}
+
+type paymentResponse struct {
+	Reference string `json:"reference"`
+}
+
+func (a *HTTPAggregator) Inquire(ctx context.Context, billerCode, customerNumber string) (*Bill, error) {
+	var resp inquiryResponse
+	status, err := a.post(ctx, "/inquiries", inquiryRequest{BillerCode: billerCode, CustomerNumber: customerNumber}, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	switch status {
+	case http.StatusOK:
+		return &Bill{
+			CustomerName: resp.CustomerName,
+			Period:       resp.Period,
+			Amount:       resp.Amount,
+			Reference:    resp.Reference,
+		}, nil
+	case http.StatusNotFound:
+		return nil, ErrBillNotFound
+	default:
+		return nil, fmt.Errorf("biller aggregator inquiry failed with status %d", status)
+	}
+}
+
+func (a *HTTPAggregator) Pay(ctx context.Context, payment Payment) (*Receipt, error) {
+	var resp paymentResponse
+	status, err := a.post(ctx, "/payments", paymentRequest{
+		PaymentID:        payment.PaymentID,
+		BillerCode:       payment.BillerCode,
+		CustomerNumber:   payment.CustomerNumber,
+		InquiryReference: payment.InquiryReference,
+		Amount:           payment.Amount,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	switch status {
+	case http.StatusOK:
+		return &Receipt{Reference: resp.Reference}, nil
+	case http.StatusUnprocessableEntity:
+		return nil, ErrPaymentRejected
+	default:
+		return nil, fmt.Errorf("biller aggregator payment failed with status %d", status)
+	}
+}
+
+func (a *HTTPAggregator) Name() string {
+	return "http"
+}
+
+// post sends a JSON request and decodes the body into out on 200 OK
+func (a *HTTPAggregator) post(ctx context.Context, path string, body, out interface{}) (int, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", a.apiKey)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("biller aggregator request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return 0, fmt.Errorf("failed to decode biller aggregator response: %w", err)
+		}
+	}
+
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,55 @@
+package billeragg
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SimulatedAggregator answers inquiries with deterministic bills so the
+// payment flow can be exercised without an aggregator contract (development only).
+//
+// Customer numbers ending in "0000" have no outstanding bill and those ending
+// in "9999" are rejected on payment.
+type SimulatedAggregator struct {
+	now func() time.Time
+}
+
+func NewSimulatedAggregator() *SimulatedAggregator {
+	return &SimulatedAggregator{now: time.Now}
+}
+
+func (a *SimulatedAggregator) Inquire(ctx context.Context, billerCode, customerNumber string) (*Bill, error) {
+	if len(customerNumber) < 4 || strings.HasSuffix(customerNumber, "0000") {
+		return nil, ErrBillNotFound
+	}
+
+	sum := sha256.Sum256([]byte(billerCode + ":" + customerNumber))
+	seed := binary.BigEndian.Uint64(sum[:8])
+
+	// Between 50,000 and 1,500,000 IDR, rounded to 100
+	amount := float64(50_000 + (seed%14_500)*100)
+
+	return &Bill{
+		CustomerName: fmt.Sprintf("CUSTOMER %s", customerNumber[len(customerNumber)-4:]),
+		Period:       a.now().Format("2006-01"),
+		Amount:       amount,
+		Reference:    fmt.Sprintf("SIMINQ-%x", sum[:6]),
+	}, nil
+}
+
+func (a *SimulatedAggregator) Pay(ctx context.Context, payment Payment) (*Receipt, error) {
+	if strings.HasSuffix(payment.CustomerNumber, "9999") {
+		return nil, ErrPaymentRejected
+	}
+
+	sum := sha256.Sum256([]byte(payment.PaymentID))
+	return &Receipt{Reference: fmt.Sprintf("SIMPAY-%x", sum[:6])}, nil
+}
+
+func (a *SimulatedAggregator) Name() string {
+	return "simulated"
+}
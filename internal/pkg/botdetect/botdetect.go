@@ -0,0 +1,283 @@
+// Package botdetect scores how likely a request comes from a bot or a
+// scripted client rather than the mobile app or a browser. The score adds up
+// user-agent anomalies, a header fingerprint and the request cadence of the
+// client IP; thresholds from BOT_* environment variables decide whether the
+// request needs a CAPTCHA or is denied.
+package botdetect
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// MaxScore is the highest score a request can get
+const MaxScore = 100
+
+const (
+	// cadenceSamples is how many request times are kept per IP
+	cadenceSamples = 10
+	// cadenceTTL drops the history of an IP that went quiet
+	cadenceTTL = 10 * time.Minute
+	// regularCadenceCV is the coefficient of variation of the gaps between
+	// requests below which the timing looks machine-made
+	regularCadenceCV = 0.1
+	// regularCadenceMaxGap ignores steady but slow polling, such as a
+	// balance refresh every minute
+	regularCadenceMaxGap = 10 * time.Second
+	// burstGap is the mean gap between requests below which a client is
+	// faster than a person tapping through the app
+	burstGap = 250 * time.Millisecond
+)
+
+// Signal weights, adding up to at most MaxScore
+const (
+	weightMissingUserAgent  = 40
+	weightAutomationAgent   = 35
+	weightMissingAccept     = 10
+	weightMissingEncoding   = 5
+	weightBrowserNoLanguage = 15
+	weightRegularCadence    = 30
+	weightBurstCadence      = 20
+)
+
+// automationAgents are user-agent fragments of HTTP libraries, command line
+// tools and headless browsers. okhttp and CFNetwork are left out, as the
+// mobile app sends requests through them.
+var automationAgents = []string{
+	"curl/", "wget/", "python-requests", "python-urllib", "aiohttp", "httpx",
+	"go-http-client", "java/", "apache-httpclient", "libwww-perl", "node-fetch",
+	"axios/", "postmanruntime", "insomnia", "scrapy", "headlesschrome",
+	"phantomjs", "selenium", "puppeteer", "playwright",
+}
+
+// Action is what the detector asks for
+type Action string
+
+const (
+	ActionAllow     Action = "allow"
+	ActionChallenge Action = "challenge"
+	ActionDeny      Action = "deny"
+)
+
+// Config holds the score thresholds
+type Config struct {
+	ChallengeScore int
+	DenyScore      int
+}
+
+// DefaultConfig asks for a CAPTCHA from 50 and denies from 80
+var DefaultConfig = Config{
+	ChallengeScore: 50,
+	DenyScore:      80,
+}
+
+// Validate checks the thresholds are within 1..MaxScore and ordered
+func (c Config) Validate() error {
+	if c.ChallengeScore < 1 || c.ChallengeScore > MaxScore {
+		return fmt.Errorf("bot challenge score must be between 1 and %d", MaxScore)
+	}
+	if c.DenyScore < c.ChallengeScore || c.DenyScore > MaxScore {
+		return fmt.Errorf("bot deny score must be between the challenge score and %d", MaxScore)
+	}
+	return nil
+}
+
+// Request is what the detector looks at
+type Request struct {
+	IP             string
+	UserAgent      string
+	Accept         string
+	AcceptLanguage string
+	AcceptEncoding string
+}
+
+// Verdict is the score of one request and the signals behind it
+type Verdict struct {
+	Score   int
+	Signals []string
+	Action  Action
+}
+
+// Detector scores requests. Request times are kept in Redis so the cadence
+// is seen across replicas.
+type Detector struct {
+	redis  *redis.Client
+	config Config
+	now    func() time.Time
+}
+
+func NewDetector(redisClient *redis.Client, config Config) (*Detector, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &Detector{redis: redisClient, config: config, now: time.Now}, nil
+}
+
+// ConfigFromEnv reads BOT_CHALLENGE_SCORE and BOT_DENY_SCORE, falling back to
+// DefaultConfig. Detection is enabled by BOT_DETECTION=true.
+func ConfigFromEnv() (Config, bool, error) {
+	config := DefaultConfig
+	for env, score := range map[string]*int{
+		"BOT_CHALLENGE_SCORE": &config.ChallengeScore,
+		"BOT_DENY_SCORE":      &config.DenyScore,
+	} {
+		if v := os.Getenv(env); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return Config{}, false, fmt.Errorf("invalid %s %q", env, v)
+			}
+			*score = n
+		}
+	}
+	if err := config.Validate(); err != nil {
+		return Config{}, false, err
+	}
+	return config, os.Getenv("BOT_DETECTION") == "true", nil
+}
+
+// Config returns the thresholds in use
+func (d *Detector) Config() Config {
+	return d.config
+}
+
+// Evaluate scores a request and records it in the cadence of its IP. When
+// Redis fails the cadence signals are skipped and the error is returned
+// with the verdict of the other signals.
+func (d *Detector) Evaluate(ctx context.Context, req Request) (*Verdict, error) {
+	v := &Verdict{}
+	add := func(weight int, signal string) {
+		v.Score += weight
+		v.Signals = append(v.Signals, signal)
+	}
+
+	agent := strings.ToLower(strings.TrimSpace(req.UserAgent))
+	switch {
+	case agent == "":
+		add(weightMissingUserAgent, "missing_user_agent")
+	case isAutomationAgent(agent):
+		add(weightAutomationAgent, "automation_user_agent")
+	}
+
+	if req.Accept == "" {
+		add(weightMissingAccept, "missing_accept")
+	}
+	if req.AcceptEncoding == "" {
+		add(weightMissingEncoding, "missing_accept_encoding")
+	}
+	// Browsers always send their languages; a client claiming to be one
+	// without them is likely spoofing the user agent
+	if strings.HasPrefix(agent, "mozilla/") && req.AcceptLanguage == "" {
+		add(weightBrowserNoLanguage, "browser_without_language")
+	}
+
+	gaps, err := d.recordRequest(ctx, req.IP)
+	if err == nil {
+		regular, burst := cadence(gaps)
+		if regular {
+			add(weightRegularCadence, "regular_cadence")
+		}
+		if burst {
+			add(weightBurstCadence, "burst_cadence")
+		}
+	}
+
+	v.Score = min(v.Score, MaxScore)
+	v.Action = d.decide(v.Score)
+	return v, err
+}
+
+func (d *Detector) decide(score int) Action {
+	switch {
+	case score >= d.config.DenyScore:
+		return ActionDeny
+	case score >= d.config.ChallengeScore:
+		return ActionChallenge
+	default:
+		return ActionAllow
+	}
+}
+
+func isAutomationAgent(agent string) bool {
+	for _, fragment := range automationAgents {
+		if strings.Contains(agent, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// recordRequest adds the current time to the IP's history and returns the
+// gaps between its recent requests, newest first
+func (d *Detector) recordRequest(ctx context.Context, ip string) ([]time.Duration, error) {
+	key := fmt.Sprintf("botdetect:cadence:%s", ip)
+
+	pipe := d.redis.TxPipeline()
+	pipe.LPush(ctx, key, d.now().UnixMilli())
+	pipe.LTrim(ctx, key, 0, cadenceSamples-1)
+	times := pipe.LRange(ctx, key, 0, -1)
+	pipe.Expire(ctx, key, cadenceTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to record request cadence: %w", err)
+	}
+
+	values := times.Val()
+	gaps := make([]time.Duration, 0, len(values))
+	for i := 1; i < len(values); i++ {
+		newer, err1 := strconv.ParseInt(values[i-1], 10, 64)
+		older, err2 := strconv.ParseInt(values[i], 10, 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		gaps = append(gaps, time.Duration(newer-older)*time.Millisecond)
+	}
+	return gaps, nil
+}
+
+// cadence reports whether the gaps are too regular or too short to come from
+// a person. It needs a full history, so a few quick taps are not flagged.
+func cadence(gaps []time.Duration) (regular, burst bool) {
+	if len(gaps) < cadenceSamples-1 {
+		return false, false
+	}
+
+	var sum float64
+	for _, g := range gaps {
+		sum += float64(g)
+	}
+	mean := sum / float64(len(gaps))
+
+	var variance float64
+	for _, g := range gaps {
+		variance += math.Pow(float64(g)-mean, 2)
+	}
+	stddev := math.Sqrt(variance / float64(len(gaps)))
+
+	burst = mean < float64(burstGap)
+	regular = mean > 0 && mean <= float64(regularCadenceMaxGap) && stddev/mean < regularCadenceCV
+	return regular, burst
+}
+
+// captchaPassTTL is how long an IP that solved a CAPTCHA is not challenged
+// again
+const captchaPassTTL = 30 * time.Minute
+
+// MarkVerified records that the IP solved a CAPTCHA
+func (d *Detector) MarkVerified(ctx context.Context, ip string) error {
+	return d.redis.Set(ctx, fmt.Sprintf("botdetect:verified:%s", ip), "1", captchaPassTTL).Err()
+}
+
+// IsVerified reports whether the IP solved a CAPTCHA recently
+func (d *Detector) IsVerified(ctx context.Context, ip string) (bool, error) {
+	n, err := d.redis.Exists(ctx, fmt.Sprintf("botdetect:verified:%s", ip)).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,130 @@
+package botdetect
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupDetector returns a detector whose clock is moved by the returned
+// function
+func setupDetector(t *testing.T) (*Detector, func(time.Duration)) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(mr.Close)
+
+	d, err := NewDetector(redis.NewClient(&redis.Options{Addr: mr.Addr()}), DefaultConfig)
+	require.NoError(t, err)
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	d.now = func() time.Time { return now }
+	return d, func(step time.Duration) { now = now.Add(step) }
+}
+
+var appRequest = Request{
+	IP:             "203.0.113.7",
+	UserAgent:      "MadaBank/3.2.0 (iPhone; iOS 17.4) CFNetwork/1490.0.4",
+	Accept:         "application/json",
+	AcceptLanguage: "id-ID",
+	AcceptEncoding: "gzip, deflate, br",
+}
+
+func TestEvaluate_AppRequestIsAllowed(t *testing.T) {
+	d, advance := setupDetector(t)
+
+	for i := 0; i < 12; i++ {
+		v, err := d.Evaluate(context.Background(), appRequest)
+		require.NoError(t, err)
+		assert.Equal(t, 0, v.Score)
+		assert.Equal(t, ActionAllow, v.Action)
+		advance(time.Duration(800+i*350) * time.Millisecond)
+	}
+}
+
+func TestEvaluate_UserAgentAndHeaders(t *testing.T) {
+	d, _ := setupDetector(t)
+	ctx := context.Background()
+
+	v, err := d.Evaluate(ctx, Request{IP: "198.51.100.1", UserAgent: "curl/8.4.0", Accept: "*/*"})
+	require.NoError(t, err)
+	assert.Equal(t, weightAutomationAgent+weightMissingEncoding, v.Score)
+	assert.ElementsMatch(t, []string{"automation_user_agent", "missing_accept_encoding"}, v.Signals)
+	assert.Equal(t, ActionAllow, v.Action)
+
+	v, err = d.Evaluate(ctx, Request{IP: "198.51.100.2"})
+	require.NoError(t, err)
+	assert.Equal(t, weightMissingUserAgent+weightMissingAccept+weightMissingEncoding, v.Score)
+	assert.Equal(t, ActionChallenge, v.Action)
+
+	v, err = d.Evaluate(ctx, Request{
+		IP:             "198.51.100.3",
+		UserAgent:      "Mozilla/5.0 (Windows NT 10.0; Win64; x64)",
+		Accept:         "text/html",
+		AcceptEncoding: "gzip",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"browser_without_language"}, v.Signals)
+}
+
+func TestEvaluate_Cadence(t *testing.T) {
+	d, advance := setupDetector(t)
+	ctx := context.Background()
+
+	// A script polling exactly every two seconds
+	var v *Verdict
+	var err error
+	for i := 0; i < cadenceSamples; i++ {
+		v, err = d.Evaluate(ctx, appRequest)
+		require.NoError(t, err)
+		advance(2 * time.Second)
+	}
+	assert.Equal(t, []string{"regular_cadence"}, v.Signals)
+
+	// A burst of requests 50ms apart, regular as well
+	burst := appRequest
+	burst.IP = "203.0.113.8"
+	burst.UserAgent = "python-requests/2.31"
+	for i := 0; i < cadenceSamples; i++ {
+		v, err = d.Evaluate(ctx, burst)
+		require.NoError(t, err)
+		advance(50 * time.Millisecond)
+	}
+	assert.Equal(t, weightAutomationAgent+weightRegularCadence+weightBurstCadence, v.Score)
+	assert.Equal(t, ActionDeny, v.Action)
+}
+
+func TestCaptchaPass(t *testing.T) {
+	d, _ := setupDetector(t)
+	ctx := context.Background()
+
+	verified, err := d.IsVerified(ctx, "203.0.113.7")
+	require.NoError(t, err)
+	assert.False(t, verified)
+
+	require.NoError(t, d.MarkVerified(ctx, "203.0.113.7"))
+	verified, err = d.IsVerified(ctx, "203.0.113.7")
+	require.NoError(t, err)
+	assert.True(t, verified)
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("BOT_DETECTION", "true")
+	t.Setenv("BOT_CHALLENGE_SCORE", "40")
+	t.Setenv("BOT_DENY_SCORE", "")
+	config, enabled, err := ConfigFromEnv()
+	require.NoError(t, err)
+	assert.True(t, enabled)
+	assert.Equal(t, Config{ChallengeScore: 40, DenyScore: 80}, config)
+
+	t.Setenv("BOT_DENY_SCORE", "30")
+	_, _, err = ConfigFromEnv()
+	assert.Error(t, err)
+
+	t.Setenv("BOT_DENY_SCORE", "many")
+	_, _, err = ConfigFromEnv()
+	assert.Error(t, err)
+}
@@ -0,0 +1,39 @@
+package botdetect
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSiteVerifyCaptcha(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "s3cret", r.PostForm.Get("secret"))
+		assert.Equal(t, "203.0.113.7", r.PostForm.Get("remoteip"))
+		if r.PostForm.Get("response") == "solved" {
+			_, _ = w.Write([]byte(`{"success": true}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"success": false, "error-codes": ["invalid-input-response"]}`))
+	}))
+	defer server.Close()
+
+	captcha, err := NewSiteVerifyCaptcha(server.URL, "s3cret")
+	require.NoError(t, err)
+
+	ok, err := captcha.Verify(context.Background(), "solved", "203.0.113.7")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = captcha.Verify(context.Background(), "guessed", "203.0.113.7")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = NewSiteVerifyCaptcha(server.URL, "")
+	assert.Error(t, err)
+}
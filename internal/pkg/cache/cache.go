@@ -0,0 +1,189 @@
+// Package cache keeps hot rows in Redis in front of Postgres. Entries are
+// read cache-aside with a TTL and dropped when the row changes: the writing
+// repository deletes them at once, and a database trigger announces every
+// other change so all replicas drop their copy too (see Listen).
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/pkg/metrics"
+)
+
+// keyPrefix starts every key the cache writes, so Flush can find them
+const keyPrefix = "cache:"
+
+// flushBatch is how many keys Flush scans for and deletes per command
+const flushBatch = 500
+
+// DefaultTTL bounds how long an entry can outlive a missed invalidation
+const DefaultTTL = 30 * time.Second
+
+type Cache struct {
+	redis *redis.Client
+	ttl   time.Duration
+}
+
+func New(redisClient *redis.Client, ttl time.Duration) *Cache {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Cache{redis: redisClient, ttl: ttl}
+}
+
+// Key is where the row of table with the given ID is cached. The table is
+// the Postgres table name, as sent by the invalidation trigger.
+func Key(table string, id uuid.UUID) string {
+	return keyPrefix + table + ":" + id.String()
+}
+
+// Fetch returns the cached row or loads it and caches the result. Errors
+// from load are returned as they are and never cached. When Redis is
+// unavailable the row is loaded from the database every time.
+//
+// Values are stored with gob rather than JSON so fields hidden from the API
+// (json:"-") survive the round trip.
+func Fetch[T any](ctx context.Context, c *Cache, table string, id uuid.UUID, load func() (*T, error)) (*T, error) {
+	key := Key(table, id)
+
+	data, err := c.redis.Get(ctx, key).Bytes()
+	if err == nil {
+		if value, ok := decode[T](key, data); ok {
+			metrics.RecordCacheLookup(table, true)
+			return value, nil
+		}
+	} else if !errors.Is(err, redis.Nil) {
+		logger.Warn("Failed to read cache", zap.String("key", key), zap.Error(err))
+	}
+	metrics.RecordCacheLookup(table, false)
+
+	value, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	if data, ok := encode(key, value); ok {
+		if err := c.redis.Set(ctx, key, data, c.ttl).Err(); err != nil {
+			logger.Warn("Failed to write cache", zap.String("key", key), zap.Error(err))
+		}
+	}
+	return value, nil
+}
+
+// FetchMany is Fetch for several rows: the cached ones are read in one round
+// trip and the others loaded together by load, which leaves unknown IDs out.
+// idOf tells which ID a loaded row is cached under. The rows come back in no
+// particular order.
+func FetchMany[T any](ctx context.Context, c *Cache, table string, ids []uuid.UUID, idOf func(*T) uuid.UUID, load func([]uuid.UUID) ([]*T, error)) ([]*T, error) {
+	if len(ids) == 0 {
+		return load(ids)
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = Key(table, id)
+	}
+
+	found := []*T{}
+	missing := ids
+	cached, err := c.redis.MGet(ctx, keys...).Result()
+	if err != nil {
+		logger.Warn("Failed to read cache", zap.String("table", table), zap.Error(err))
+	} else {
+		missing = nil
+		for i, entry := range cached {
+			if data, ok := entry.(string); ok {
+				if value, ok := decode[T](keys[i], []byte(data)); ok {
+					found = append(found, value)
+					continue
+				}
+			}
+			missing = append(missing, ids[i])
+		}
+	}
+	for range found {
+		metrics.RecordCacheLookup(table, true)
+	}
+	for range missing {
+		metrics.RecordCacheLookup(table, false)
+	}
+	if len(missing) == 0 {
+		return found, nil
+	}
+
+	loaded, err := load(missing)
+	if err != nil {
+		return nil, err
+	}
+
+	pipe := c.redis.Pipeline()
+	for _, value := range loaded {
+		key := Key(table, idOf(value))
+		if data, ok := encode(key, value); ok {
+			pipe.Set(ctx, key, data, c.ttl)
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.Warn("Failed to write cache", zap.String("table", table), zap.Error(err))
+	}
+	return append(found, loaded...), nil
+}
+
+func decode[T any](key string, data []byte) (*T, bool) {
+	value := new(T)
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(value); err != nil {
+		// Written by an older build with a different struct; it is replaced
+		logger.Warn("Failed to decode cached entry", zap.String("key", key), zap.Error(err))
+		return nil, false
+	}
+	return value, true
+}
+
+func encode(key string, value any) ([]byte, bool) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(value); err != nil {
+		logger.Warn("Failed to encode cache entry", zap.String("key", key), zap.Error(err))
+		return nil, false
+	}
+	return buf.Bytes(), true
+}
+
+// Invalidate drops the cached row. A failure is logged only: the entry
+// still expires with its TTL and the trigger notification retries it.
+func (c *Cache) Invalidate(ctx context.Context, table string, id uuid.UUID) {
+	key := Key(table, id)
+	if err := c.redis.Del(ctx, key).Err(); err != nil {
+		logger.Warn("Failed to invalidate cache", zap.String("key", key), zap.Error(err))
+	}
+}
+
+// Flush drops every cached row, for when invalidations may have been missed
+func (c *Cache) Flush(ctx context.Context) error {
+	// Collect first: deleting while scanning can make the cursor skip keys
+	var keys []string
+	iter := c.redis.Scan(ctx, 0, keyPrefix+"*", flushBatch).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+
+	for len(keys) > 0 {
+		n := min(len(keys), flushBatch)
+		if err := c.redis.Del(ctx, keys[:n]...).Err(); err != nil {
+			return err
+		}
+		keys = keys[n:]
+	}
+	return nil
+}
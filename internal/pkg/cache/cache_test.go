@@ -0,0 +1,194 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+)
+
+func init() {
+	logger.Init("test")
+}
+
+type row struct {
+	Name   string
+	Secret string `json:"-"`
+}
+
+func setupCacheTest(t *testing.T) (*miniredis.Miniredis, *Cache) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(mr.Close)
+
+	return mr, New(redis.NewClient(&redis.Options{Addr: mr.Addr()}), time.Minute)
+}
+
+func TestFetch_CachesLoadedRow(t *testing.T) {
+	mr, c := setupCacheTest(t)
+	ctx := context.Background()
+	id := uuid.New()
+
+	loads := 0
+	load := func() (*row, error) {
+		loads++
+		return &row{Name: "alice", Secret: "hash"}, nil
+	}
+
+	first, err := Fetch(ctx, c, "users", id, load)
+	require.NoError(t, err)
+	second, err := Fetch(ctx, c, "users", id, load)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, loads)
+	assert.Equal(t, first, second)
+	// Fields hidden from JSON are kept
+	assert.Equal(t, "hash", second.Secret)
+	assert.True(t, mr.Exists(Key("users", id)))
+	assert.Equal(t, time.Minute, mr.TTL(Key("users", id)))
+}
+
+func TestFetch_ErrorsNotCached(t *testing.T) {
+	mr, c := setupCacheTest(t)
+	ctx := context.Background()
+	id := uuid.New()
+
+	_, err := Fetch(ctx, c, "accounts", id, func() (*row, error) {
+		return nil, errors.New("account not found")
+	})
+	assert.EqualError(t, err, "account not found")
+	assert.False(t, mr.Exists(Key("accounts", id)))
+}
+
+func TestFetch_RedisDown(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	c := New(redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1}), time.Minute)
+	mr.Close()
+
+	loads := 0
+	for i := 0; i < 2; i++ {
+		got, err := Fetch(context.Background(), c, "accounts", uuid.New(), func() (*row, error) {
+			loads++
+			return &row{Name: "savings"}, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "savings", got.Name)
+	}
+	assert.Equal(t, 2, loads)
+}
+
+func TestFetch_UndecodableEntryReplaced(t *testing.T) {
+	mr, c := setupCacheTest(t)
+	id := uuid.New()
+	require.NoError(t, mr.Set(Key("accounts", id), "not gob"))
+
+	got, err := Fetch(context.Background(), c, "accounts", id, func() (*row, error) {
+		return &row{Name: "fresh"}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "fresh", got.Name)
+
+	cached, err := Fetch(context.Background(), c, "accounts", id, func() (*row, error) {
+		t.Fatal("entry was not replaced")
+		return nil, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "fresh", cached.Name)
+}
+
+func TestInvalidate(t *testing.T) {
+	mr, c := setupCacheTest(t)
+	ctx := context.Background()
+	id := uuid.New()
+
+	_, err := Fetch(ctx, c, "accounts", id, func() (*row, error) { return &row{Name: "old"}, nil })
+	require.NoError(t, err)
+
+	c.Invalidate(ctx, "accounts", id)
+	assert.False(t, mr.Exists(Key("accounts", id)))
+
+	got, err := Fetch(ctx, c, "accounts", id, func() (*row, error) { return &row{Name: "new"}, nil })
+	require.NoError(t, err)
+	assert.Equal(t, "new", got.Name)
+}
+
+func TestFlush(t *testing.T) {
+	mr, c := setupCacheTest(t)
+	ctx := context.Background()
+	require.NoError(t, mr.Set("ddos:config", "{}"))
+
+	for i := 0; i < 1200; i++ {
+		require.NoError(t, mr.Set(Key("accounts", uuid.New()), "x"))
+	}
+
+	require.NoError(t, c.Flush(ctx))
+	assert.Equal(t, []string{"ddos:config"}, mr.Keys())
+}
+
+func TestHandleNotification(t *testing.T) {
+	mr, c := setupCacheTest(t)
+	ctx := context.Background()
+	id := uuid.New()
+	require.NoError(t, mr.Set(Key("accounts", id), "x"))
+	require.NoError(t, mr.Set(Key("users", id), "x"))
+
+	c.handleNotification(ctx, "accounts:"+id.String())
+	assert.False(t, mr.Exists(Key("accounts", id)))
+	assert.True(t, mr.Exists(Key("users", id)))
+
+	// Malformed payloads are ignored
+	for _, payload := range []string{"", "users", "users:", ":" + id.String(), "users:*"} {
+		c.handleNotification(ctx, payload)
+	}
+	assert.True(t, mr.Exists(Key("users", id)))
+}
+
+func TestFetchMany(t *testing.T) {
+	mr, c := setupCacheTest(t)
+	ctx := context.Background()
+	cachedID, missingID, unknownID := uuid.New(), uuid.New(), uuid.New()
+
+	type account struct {
+		ID   uuid.UUID
+		Name string
+	}
+	idOf := func(a *account) uuid.UUID { return a.ID }
+
+	_, err := Fetch(ctx, c, "accounts", cachedID, func() (*account, error) {
+		return &account{ID: cachedID, Name: "cached"}, nil
+	})
+	require.NoError(t, err)
+
+	var loadedIDs []uuid.UUID
+	got, err := FetchMany(ctx, c, "accounts", []uuid.UUID{cachedID, missingID, unknownID}, idOf, func(ids []uuid.UUID) ([]*account, error) {
+		loadedIDs = ids
+		return []*account{{ID: missingID, Name: "loaded"}}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{missingID, unknownID}, loadedIDs)
+	assert.ElementsMatch(t, []*account{{ID: cachedID, Name: "cached"}, {ID: missingID, Name: "loaded"}}, got)
+	assert.True(t, mr.Exists(Key("accounts", missingID)))
+	assert.False(t, mr.Exists(Key("accounts", unknownID)))
+
+	// Everything cached now needs no load
+	got, err = FetchMany(ctx, c, "accounts", []uuid.UUID{cachedID, missingID}, idOf, func(ids []uuid.UUID) ([]*account, error) {
+		t.Fatal("rows were not cached")
+		return nil, nil
+	})
+	require.NoError(t, err)
+	assert.Len(t, got, 2)
+
+	_, err = FetchMany(ctx, c, "accounts", []uuid.UUID{unknownID}, idOf, func(ids []uuid.UUID) ([]*account, error) {
+		return nil, errors.New("failed to get accounts")
+	})
+	assert.EqualError(t, err, "failed to get accounts")
+}
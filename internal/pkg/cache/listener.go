@@ -0,0 +1,108 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+)
+
+// Channel is the Postgres notification channel the invalidation trigger
+// publishes "<table>:<id>" to whenever a cached row is updated or deleted
+const Channel = "cache_invalidation"
+
+// subscribeTimeout bounds how long Listen waits for the database
+const subscribeTimeout = 10 * time.Second
+
+// pingInterval checks an idle listener connection is still alive
+const pingInterval = 90 * time.Second
+
+// Listen subscribes to the database's invalidation notifications and drops
+// cached rows as they change, until ctx is cancelled. It returns once
+// subscribed; the cache must not be used when it fails.
+//
+// The notifications catch the writes that do not go through a caching
+// repository, such as balance moves inside other repositories'
+// transactions, and writes made by other replicas. Postgres sends them only
+// once the transaction commits, so a reader never re-caches the old row of a
+// transaction still in flight. Those sent while the connection is down are
+// lost, so the whole cache is flushed whenever it (re)connects.
+func (c *Cache) Listen(ctx context.Context, databaseURL string) error {
+	listener := pq.NewListener(databaseURL, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			logger.Warn("Cache invalidation listener connection problem", zap.Error(err))
+		}
+	})
+
+	// Listen waits for the connection, retrying for as long as it takes
+	subscribed := make(chan error, 1)
+	go func() {
+		subscribed <- listener.Listen(Channel)
+	}()
+	var err error
+	select {
+	case err = <-subscribed:
+	case <-time.After(subscribeTimeout):
+		err = errors.New("timed out connecting")
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if err != nil {
+		_ = listener.Close()
+		return fmt.Errorf("failed to listen for cache invalidations: %w", err)
+	}
+
+	c.flush(ctx)
+	go c.invalidate(ctx, listener)
+	return nil
+}
+
+func (c *Cache) invalidate(ctx context.Context, listener *pq.Listener) {
+	defer func() {
+		_ = listener.Close()
+	}()
+
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case n := <-listener.Notify:
+			if n == nil {
+				// Reconnected; anything could have changed meanwhile
+				c.flush(ctx)
+				continue
+			}
+			c.handleNotification(ctx, n.Extra)
+		case <-ticker.C:
+			go func() {
+				_ = listener.Ping()
+			}()
+		}
+	}
+}
+
+// handleNotification drops the row named by a "<table>:<id>" payload
+func (c *Cache) handleNotification(ctx context.Context, payload string) {
+	table, rawID, ok := strings.Cut(payload, ":")
+	id, err := uuid.Parse(rawID)
+	if !ok || table == "" || err != nil {
+		logger.Warn("Ignoring malformed cache invalidation", zap.String("payload", payload))
+		return
+	}
+	c.Invalidate(ctx, table, id)
+}
+
+func (c *Cache) flush(ctx context.Context) {
+	if err := c.Flush(ctx); err != nil {
+		logger.Error("Failed to flush cache", zap.Error(err))
+	}
+}
@@ -0,0 +1,54 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse_Invalid(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "* * * foo *"} {
+		_, err := Parse(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestSchedule_Next(t *testing.T) {
+	from := time.Date(2024, 1, 31, 23, 59, 30, 0, time.UTC) // a Wednesday
+
+	cases := map[string]time.Time{
+		"* * * * *":       time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		"*/15 * * * *":    time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		"30 2 * * *":      time.Date(2024, 2, 1, 2, 30, 0, 0, time.UTC),
+		"@monthly":        time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		"0 9 * * mon-fri": time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC),
+		"0 0 29 2 *":      time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+		"0 0 * * 7":       time.Date(2024, 2, 4, 0, 0, 0, 0, time.UTC),
+		"0 0 1,15 * *":    time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		"0 12 13 * 5":     time.Date(2024, 2, 2, 12, 0, 0, 0, time.UTC), // Friday or the 13th
+		"0 0 1 jan *":     time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	for spec, want := range cases {
+		assert.Equal(t, want, MustParse(spec).Next(from), spec)
+	}
+}
+
+func TestSchedule_NextIsStrictlyAfter(t *testing.T) {
+	at := time.Date(2024, 3, 1, 2, 30, 0, 0, time.UTC)
+	assert.Equal(t, at.AddDate(0, 0, 1), MustParse("30 2 * * *").Next(at))
+}
+
+func TestSchedule_NextUsesLocation(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*3600)
+	from := time.Date(2024, 3, 1, 0, 30, 0, 0, jakarta)
+
+	next := MustParse("5 0 * * *").Next(from)
+
+	assert.Equal(t, time.Date(2024, 3, 2, 0, 5, 0, 0, jakarta), next)
+}
+
+func TestSchedule_NextNever(t *testing.T) {
+	assert.True(t, MustParse("0 0 30 2 *").Next(time.Now()).IsZero())
+}
@@ -3,8 +3,11 @@ package crypto
 import (
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"io"
 )
@@ -129,3 +132,15 @@ func ValidateCVV(cvv string) bool {
 
 	return true
 }
+
+// Fingerprint returns a keyed, deterministic digest of value (HMAC-SHA256 under
+// a key derived from the encryption key). It allows looking up encrypted values
+// such as card numbers without decrypting every row.
+func (e *Encryptor) Fingerprint(value string) string {
+	derive := hmac.New(sha256.New, e.key)
+	derive.Write([]byte("madabank-fingerprint-v1"))
+
+	mac := hmac.New(sha256.New, derive.Sum(nil))
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -142,3 +142,14 @@ func TestValidateCVV_Invalid(t *testing.T) {
 	assert.False(t, ValidateCVV("12345"))
 	assert.False(t, ValidateCVV("abc"))
 }
+
+func TestFingerprint_DeterministicPerKey(t *testing.T) {
+	e1, _ := NewEncryptor("12345678901234567890123456789012")
+	e2, _ := NewEncryptor("abcdefghijklmnopqrstuvwxyz123456")
+
+	fp := e1.Fingerprint("4111111111111111")
+	assert.Len(t, fp, 64)
+	assert.Equal(t, fp, e1.Fingerprint("4111111111111111"))
+	assert.NotEqual(t, fp, e1.Fingerprint("4111111111111112"))
+	assert.NotEqual(t, fp, e2.Fingerprint("4111111111111111"))
+}
@@ -1,19 +1,155 @@
 package crypto
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
 const bcryptCost = 12
 
-// HashPassword generates a bcrypt hash of the password
+const argon2idPrefix = "$argon2id$"
+
+// Argon2Params holds the cost parameters used for new Argon2id hashes.
+// Memory is expressed in KiB.
+type Argon2Params struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2Params follows the OWASP baseline recommendation for Argon2id
+var DefaultArgon2Params = Argon2Params{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 2,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+var (
+	paramsMu     sync.RWMutex
+	argon2Params = DefaultArgon2Params
+)
+
+// SetArgon2Params changes the cost parameters used by HashPassword.
+// Existing hashes with different parameters are reported by NeedsRehash.
+func SetArgon2Params(p Argon2Params) error {
+	if p.Memory == 0 || p.Iterations == 0 || p.Parallelism == 0 {
+		return fmt.Errorf("argon2 memory, iterations and parallelism must be positive")
+	}
+	if p.SaltLength == 0 {
+		p.SaltLength = DefaultArgon2Params.SaltLength
+	}
+	if p.KeyLength == 0 {
+		p.KeyLength = DefaultArgon2Params.KeyLength
+	}
+
+	paramsMu.Lock()
+	defer paramsMu.Unlock()
+	argon2Params = p
+	return nil
+}
+
+func currentArgon2Params() Argon2Params {
+	paramsMu.RLock()
+	defer paramsMu.RUnlock()
+	return argon2Params
+}
+
+// HashPassword generates an Argon2id hash of the password in PHC string format
 func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcryptCost)
-	return string(bytes), err
+	p := currentArgon2Params()
+
+	salt := make([]byte, p.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2idPrefix, argon2.Version, p.Memory, p.Iterations, p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
 }
 
-// CheckPassword compares a password with a hash
+// CheckPassword compares a password with a hash. Both Argon2id and legacy
+// bcrypt hashes are accepted.
 func CheckPassword(password, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil
+	if !strings.HasPrefix(hash, argon2idPrefix) {
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		return err == nil
+	}
+
+	p, salt, key, err := decodeArgon2Hash(hash)
+	if err != nil {
+		return false
+	}
+
+	computed := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, uint32(len(key))) // #nosec G115 -- key length comes from a decoded hash
+	return subtle.ConstantTimeCompare(key, computed) == 1
+}
+
+// NeedsRehash reports whether a stored hash should be replaced with a fresh
+// one, either because it is a legacy bcrypt hash or because the configured
+// Argon2id parameters have changed.
+func NeedsRehash(hash string) bool {
+	if !strings.HasPrefix(hash, argon2idPrefix) {
+		return true
+	}
+
+	p, salt, key, err := decodeArgon2Hash(hash)
+	if err != nil {
+		return true
+	}
+
+	cur := currentArgon2Params()
+	return p.Memory != cur.Memory ||
+		p.Iterations != cur.Iterations ||
+		p.Parallelism != cur.Parallelism ||
+		uint32(len(salt)) != cur.SaltLength || // #nosec G115 -- lengths are small
+		uint32(len(key)) != cur.KeyLength // #nosec G115 -- lengths are small
+}
+
+func decodeArgon2Hash(hash string) (Argon2Params, []byte, []byte, error) {
+	// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return Argon2Params{}, nil, nil, fmt.Errorf("invalid argon2id hash format")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return Argon2Params{}, nil, nil, fmt.Errorf("invalid argon2id version: %w", err)
+	}
+	if version != argon2.Version {
+		return Argon2Params{}, nil, nil, fmt.Errorf("unsupported argon2 version %d", version)
+	}
+
+	var p Argon2Params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Iterations, &p.Parallelism); err != nil {
+		return Argon2Params{}, nil, nil, fmt.Errorf("invalid argon2id parameters: %w", err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return Argon2Params{}, nil, nil, fmt.Errorf("invalid argon2id salt: %w", err)
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return Argon2Params{}, nil, nil, fmt.Errorf("invalid argon2id key: %w", err)
+	}
+
+	return p, salt, key, nil
 }
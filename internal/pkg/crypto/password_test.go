@@ -1,7 +1,10 @@
 package crypto
 
 import (
+	"strings"
 	"testing"
+
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestHashPassword(t *testing.T) {
@@ -54,7 +57,7 @@ func TestHashPasswordDifferentHashes(t *testing.T) {
 		t.Fatalf("Second HashPassword failed: %v", err)
 	}
 
-	// Bcrypt should generate different hashes each time (due to random salt)
+	// Argon2id should generate different hashes each time (due to random salt)
 	if hash1 == hash2 {
 		t.Fatal("Two hashes of the same password should be different")
 	}
@@ -64,3 +67,72 @@ func TestHashPasswordDifferentHashes(t *testing.T) {
 		t.Fatal("Both hashes should validate the password")
 	}
 }
+
+func TestHashPasswordUsesArgon2id(t *testing.T) {
+	hash, err := HashPassword("SecurePassword123!")
+	if err != nil {
+		t.Fatalf("HashPassword failed: %v", err)
+	}
+
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=65536,t=3,p=2$") {
+		t.Fatalf("unexpected hash format: %s", hash)
+	}
+
+	if NeedsRehash(hash) {
+		t.Fatal("Fresh hash should not need rehash")
+	}
+}
+
+func TestCheckPasswordLegacyBcrypt(t *testing.T) {
+	password := "SecurePassword123!"
+
+	legacy, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("bcrypt failed: %v", err)
+	}
+
+	if !CheckPassword(password, string(legacy)) {
+		t.Fatal("CheckPassword should accept legacy bcrypt hashes")
+	}
+
+	if CheckPassword("WrongPassword", string(legacy)) {
+		t.Fatal("CheckPassword should reject wrong password for bcrypt hash")
+	}
+
+	if !NeedsRehash(string(legacy)) {
+		t.Fatal("Bcrypt hashes should need rehash")
+	}
+}
+
+func TestNeedsRehashAfterParamChange(t *testing.T) {
+	hash, err := HashPassword("SecurePassword123!")
+	if err != nil {
+		t.Fatalf("HashPassword failed: %v", err)
+	}
+
+	if err := SetArgon2Params(Argon2Params{Memory: 32 * 1024, Iterations: 2, Parallelism: 1}); err != nil {
+		t.Fatalf("SetArgon2Params failed: %v", err)
+	}
+	defer func() { _ = SetArgon2Params(DefaultArgon2Params) }()
+
+	if !NeedsRehash(hash) {
+		t.Fatal("Hash with old parameters should need rehash")
+	}
+
+	// Old hashes still verify with the parameters encoded in them
+	if !CheckPassword("SecurePassword123!", hash) {
+		t.Fatal("Hash with old parameters should still verify")
+	}
+}
+
+func TestSetArgon2ParamsInvalid(t *testing.T) {
+	if err := SetArgon2Params(Argon2Params{}); err == nil {
+		t.Fatal("SetArgon2Params should reject zero parameters")
+	}
+}
+
+func TestCheckPasswordMalformedArgon2(t *testing.T) {
+	if CheckPassword("anything", "$argon2id$v=19$garbage") {
+		t.Fatal("Malformed hash should not verify")
+	}
+}
@@ -0,0 +1,144 @@
+package dbmetrics
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NoTable labels statements that read no table, such as SELECT 1 or an
+// advisory lock
+const NoTable = "none"
+
+// OtherOperation labels anything but SELECT, INSERT, UPDATE and DELETE
+const OtherOperation = "other"
+
+type word struct {
+	text  string
+	depth int
+}
+
+// Classify returns the operation and main table of a statement, e.g.
+// ("update", "accounts"). Only the outer statement counts: the verb after a
+// WITH clause and the first table outside parentheses, without its schema.
+// Both labels come from the SQL text, never from arguments, so they stay
+// within the set of queries the repositories write.
+func Classify(query string) (operation, table string) {
+	words := splitWords(query)
+
+	verb := -1
+	for i, w := range words {
+		if w.depth == 0 {
+			verb = i
+			break
+		}
+	}
+	if verb >= 0 && words[verb].text == "with" {
+		// The CTE bodies are parenthesized, so the first depth 0 verb after
+		// them is the statement's own
+		next := -1
+		for i := verb + 1; i < len(words); i++ {
+			if words[i].depth == 0 && isVerb(words[i].text) {
+				next = i
+				break
+			}
+		}
+		verb = next
+	}
+	if verb == -1 || !isVerb(words[verb].text) {
+		return OtherOperation, NoTable
+	}
+
+	operation = words[verb].text
+	rest := words[verb+1:]
+	switch operation {
+	case "insert":
+		table = after(rest, "into")
+	case "update":
+		if len(rest) > 0 && rest[0].text == "only" {
+			rest = rest[1:]
+		}
+		if len(rest) > 0 && rest[0].depth == 0 {
+			table = rest[0].text
+		}
+	default:
+		table = after(rest, "from")
+	}
+	if table == "" {
+		return operation, NoTable
+	}
+	if i := strings.LastIndexByte(table, '.'); i >= 0 {
+		table = table[i+1:]
+	}
+	return operation, table
+}
+
+func isVerb(s string) bool {
+	switch s {
+	case "select", "insert", "update", "delete":
+		return true
+	}
+	return false
+}
+
+// after returns the depth 0 word following keyword
+func after(words []word, keyword string) string {
+	for i, w := range words {
+		if w.depth == 0 && w.text == keyword && i+1 < len(words) && words[i+1].depth == 0 {
+			return words[i+1].text
+		}
+	}
+	return ""
+}
+
+// splitWords lowercases the identifiers and keywords of a statement along with
+// their parenthesis depth, skipping string literals, quoted identifiers'
+// quotes and comments
+func splitWords(query string) []word {
+	var (
+		words []word
+		depth int
+		cur   strings.Builder
+	)
+	flush := func() {
+		if cur.Len() > 0 {
+			words = append(words, word{text: strings.ToLower(cur.String()), depth: depth})
+			cur.Reset()
+		}
+	}
+
+	runes := []rune(query)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\'':
+			flush()
+			for i++; i < len(runes) && runes[i] != '\''; i++ {
+			}
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			flush()
+			for i++; i < len(runes) && runes[i] != '\n'; i++ {
+			}
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			flush()
+			for i += 2; i+1 < len(runes) && (runes[i] != '*' || runes[i+1] != '/'); i++ {
+			}
+			i++
+		case r == '"':
+			// Quoted identifiers keep their text, e.g. "users" -> users
+		case r == '(':
+			flush()
+			depth++
+		case r == ')':
+			flush()
+			if depth > 0 {
+				depth--
+			}
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.' || r == '$':
+			cur.WriteRune(r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return words
+}
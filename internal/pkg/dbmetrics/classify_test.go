@@ -0,0 +1,38 @@
+package dbmetrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		query     string
+		operation string
+		table     string
+	}{
+		{"SELECT id, email FROM users WHERE id = $1", "select", "users"},
+		{"\n\t\tselect a.id from accounts a join users u on u.id = a.user_id", "select", "accounts"},
+		{"INSERT INTO transactions (id, amount) VALUES ($1, $2)", "insert", "transactions"},
+		{"UPDATE accounts SET balance = balance + $1 WHERE id = $2", "update", "accounts"},
+		{"UPDATE ONLY public.accounts SET status = 'frozen'", "update", "accounts"},
+		{"DELETE FROM refresh_tokens WHERE expires_at < NOW()", "delete", "refresh_tokens"},
+		{"SELECT COUNT(*) FROM (SELECT id FROM users) AS u", "select", "none"},
+		{"SELECT EXTRACT(EPOCH FROM NOW())", "select", "none"},
+		{"SELECT 1", "select", "none"},
+		{"SELECT pg_try_advisory_lock($1)", "select", "none"},
+		{`SELECT * FROM "audit_logs" WHERE action = 'select from users'`, "select", "audit_logs"},
+		{"-- pending first\nSELECT id FROM transactions /* from jobs */ WHERE status = 'pending'", "select", "transactions"},
+		{`WITH due AS (SELECT id FROM loans WHERE due_date <= $1)
+			UPDATE loan_installments SET status = 'due' WHERE loan_id IN (SELECT id FROM due)`, "update", "loan_installments"},
+		{"WITH RECURSIVE t(n) AS (SELECT 1) SELECT n FROM t", "select", "t"},
+		{"LOCK TABLE accounts IN EXCLUSIVE MODE", "other", "none"},
+		{"", "other", "none"},
+	}
+	for _, tt := range tests {
+		operation, table := Classify(tt.query)
+		assert.Equal(t, tt.operation, operation, tt.query)
+		assert.Equal(t, tt.table, table, tt.query)
+	}
+}
@@ -0,0 +1,184 @@
+// Package dbmetrics wraps the Postgres driver so every statement the
+// repositories run is counted in madabank_db_queries_total and timed in
+// madabank_db_query_duration_seconds, labelled by operation and table. It
+// works below database/sql, so queries inside transactions are covered and
+// repositories need no changes.
+package dbmetrics
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/darisadam/madabank-server/internal/pkg/metrics"
+)
+
+// Open opens a Postgres handle like sql.Open("postgres", dsn), with every
+// statement recorded
+func Open(dsn string) (*sql.DB, error) {
+	connector, err := pq.NewConnector(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return sql.OpenDB(Wrap(connector)), nil
+}
+
+// recordFunc receives one statement's labels and duration
+type recordFunc func(operation, table string, duration time.Duration)
+
+func recordMetrics(operation, table string, duration time.Duration) {
+	metrics.RecordDBQuery(operation, table, duration.Seconds())
+}
+
+// Wrap instruments the connections of any connector
+func Wrap(c driver.Connector) driver.Connector {
+	return &connector{Connector: c, record: recordMetrics}
+}
+
+type connector struct {
+	driver.Connector
+	record recordFunc
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	cn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{Conn: cn, record: c.record}, nil
+}
+
+// observe times fn and records it under the statement's labels. Errors count
+// too, as a failing query still costs a round trip, but driver.ErrSkip does
+// not, since database/sql then retries another way.
+func observe[T any](record recordFunc, query string, fn func() (T, error)) (T, error) {
+	start := time.Now()
+	result, err := fn()
+	if err != driver.ErrSkip {
+		operation, table := Classify(query)
+		record(operation, table, time.Since(start))
+	}
+	return result, err
+}
+
+// conn records the statements run on a connection. Query durations cover
+// sending the statement until the first rows arrive, not reading them all.
+type conn struct {
+	driver.Conn
+	record recordFunc
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	return observe(c.record, query, func() (driver.Rows, error) {
+		return queryer.QueryContext(ctx, query, args)
+	})
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	return observe(c.record, query, func() (driver.Result, error) {
+		return execer.ExecContext(ctx, query, args)
+	})
+}
+
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var (
+		st  driver.Stmt
+		err error
+	)
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		st, err = preparer.PrepareContext(ctx, query)
+	} else {
+		st, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &stmt{Stmt: st, query: query, record: c.record}, nil
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin() // drivers without BeginTx
+}
+
+func (c *conn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *conn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *conn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+// stmt records each execution of a prepared statement
+type stmt struct {
+	driver.Stmt
+	query  string
+	record recordFunc
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return observe(s.record, s.query, func() (driver.Rows, error) {
+		if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+			return queryer.QueryContext(ctx, args)
+		}
+		values, err := namedValues(args)
+		if err != nil {
+			return nil, err
+		}
+		return s.Stmt.Query(values) // drivers without QueryContext
+	})
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return observe(s.record, s.query, func() (driver.Result, error) {
+		if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+			return execer.ExecContext(ctx, args)
+		}
+		values, err := namedValues(args)
+		if err != nil {
+			return nil, err
+		}
+		return s.Stmt.Exec(values) // drivers without ExecContext
+	})
+}
+
+func namedValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, driver.ErrSkip
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
@@ -0,0 +1,122 @@
+package dbmetrics
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/darisadam/madabank-server/internal/pkg/metrics"
+)
+
+var errBadQuery = errors.New("relation does not exist")
+
+// fakeConnector hands out connections that answer every statement with no rows
+type fakeConnector struct{}
+
+func (fakeConnector) Connect(context.Context) (driver.Conn, error) { return fakeConn{}, nil }
+func (fakeConnector) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{}, nil }
+func (fakeConn) Close() error                              { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                 { return fakeTx{}, nil }
+
+func (fakeConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	return fakeRows{}, nil
+}
+
+func (fakeConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	if query == "DELETE FROM missing" {
+		return nil, errBadQuery
+	}
+	return driver.RowsAffected(1), nil
+}
+
+type fakeStmt struct{}
+
+func (fakeStmt) Close() error                               { return nil }
+func (fakeStmt) NumInput() int                              { return -1 }
+func (fakeStmt) Exec([]driver.Value) (driver.Result, error) { return driver.RowsAffected(1), nil }
+func (fakeStmt) Query([]driver.Value) (driver.Rows, error)  { return fakeRows{}, nil }
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeRows struct{}
+
+func (fakeRows) Columns() []string              { return []string{"id"} }
+func (fakeRows) Close() error                   { return nil }
+func (fakeRows) Next(dest []driver.Value) error { return io.EOF }
+
+type recorded struct{ operation, table string }
+
+func openRecorded(t *testing.T) (*sql.DB, *[]recorded) {
+	var got []recorded
+	db := sql.OpenDB(&connector{Connector: fakeConnector{}, record: func(operation, table string, _ time.Duration) {
+		got = append(got, recorded{operation, table})
+	}})
+	t.Cleanup(func() { _ = db.Close() })
+	return db, &got
+}
+
+func TestConn_RecordsStatements(t *testing.T) {
+	db, got := openRecorded(t)
+
+	_, err := db.Exec("UPDATE accounts SET balance = $1 WHERE id = $2", 100, 1)
+	require.NoError(t, err)
+	rows, err := db.Query("SELECT id FROM users WHERE email = $1", "a@example.com")
+	require.NoError(t, err)
+	_ = rows.Close()
+
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	_, err = tx.Exec("INSERT INTO transactions (id) VALUES ($1)", 1)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+
+	_, err = db.Exec("DELETE FROM missing")
+	assert.ErrorIs(t, err, errBadQuery)
+
+	assert.Equal(t, []recorded{
+		{"update", "accounts"},
+		{"select", "users"},
+		{"insert", "transactions"},
+		{"delete", "missing"},
+	}, *got)
+}
+
+func TestStmt_RecordsEachExecution(t *testing.T) {
+	db, got := openRecorded(t)
+
+	st, err := db.Prepare("INSERT INTO audit_logs (action) VALUES ($1)")
+	require.NoError(t, err)
+	defer func() { _ = st.Close() }()
+	for i := 0; i < 2; i++ {
+		_, err := st.Exec("login")
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, []recorded{{"insert", "audit_logs"}, {"insert", "audit_logs"}}, *got)
+}
+
+func TestWrap_FeedsQueryMetrics(t *testing.T) {
+	db := sql.OpenDB(Wrap(fakeConnector{}))
+	defer func() { _ = db.Close() }()
+
+	before := testutil.ToFloat64(metrics.DBQueriesTotal.WithLabelValues("update", "cards"))
+	_, err := db.Exec("UPDATE cards SET status = 'blocked' WHERE id = $1", 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, before+1, testutil.ToFloat64(metrics.DBQueriesTotal.WithLabelValues("update", "cards")))
+}
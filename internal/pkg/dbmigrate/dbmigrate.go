@@ -0,0 +1,59 @@
+package dbmigrate
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+
+	"github.com/darisadam/madabank-server/migrations"
+)
+
+// New builds a migrate instance over the embedded migrations and an open
+// database handle. The postgres driver takes an advisory lock while migrating,
+// so several replicas starting at once are safe.
+func New(db *sql.DB) (*migrate.Migrate, error) {
+	source, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load embedded migrations: %w", err)
+	}
+
+	driver, err := postgres.WithInstance(db, &postgres.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migration driver: %w", err)
+	}
+
+	m, err := migrate.NewWithInstance("iofs", source, "postgres", driver)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migration instance: %w", err)
+	}
+
+	return m, nil
+}
+
+// Up applies all pending migrations and returns the resulting version.
+// A dirty database is reported as an error rather than migrated.
+func Up(db *sql.DB) (uint, error) {
+	m, err := New(db)
+	if err != nil {
+		return 0, err
+	}
+
+	if version, dirty, err := m.Version(); err == nil && dirty {
+		return version, fmt.Errorf("database is dirty at version %d", version)
+	}
+
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return 0, fmt.Errorf("failed to apply migrations: %w", err)
+	}
+
+	version, _, err := m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return 0, fmt.Errorf("failed to read migration version: %w", err)
+	}
+
+	return version, nil
+}
@@ -0,0 +1,37 @@
+package dbmigrate
+
+import (
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/darisadam/madabank-server/migrations"
+)
+
+func TestEmbeddedMigrations(t *testing.T) {
+	source, err := iofs.New(migrations.FS, ".")
+	assert.NoError(t, err)
+
+	first, err := source.First()
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), first)
+
+	// Every up migration has a matching down migration
+	version := first
+	for {
+		up, _, err := source.ReadUp(version)
+		assert.NoError(t, err, "missing up migration for %d", version)
+		_ = up.Close()
+
+		down, _, err := source.ReadDown(version)
+		assert.NoError(t, err, "missing down migration for %d", version)
+		_ = down.Close()
+
+		next, err := source.Next(version)
+		if err != nil {
+			break
+		}
+		version = next
+	}
+}
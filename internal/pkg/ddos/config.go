@@ -0,0 +1,128 @@
+package ddos
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Config holds the thresholds the traffic monitor works with. Durations are
+// whole seconds so the config reads the same in env, JSON and Redis.
+type Config struct {
+	// RequestThreshold is how many requests one IP may make within the
+	// window before it is flagged
+	RequestThreshold int64 `json:"request_threshold"`
+	// WindowSeconds is how long a per-IP request count lives
+	WindowSeconds int `json:"window_seconds"`
+	// AttackThreshold is how many flagged IPs in one scan make a
+	// large-scale attack
+	AttackThreshold int `json:"attack_threshold"`
+	// ScanIntervalSeconds is how often the monitor analyzes traffic
+	ScanIntervalSeconds int `json:"scan_interval_seconds"`
+}
+
+// DefaultConfig flags IPs above 1000 requests a minute and reports an attack
+// once more than 10 are flagged, scanning every 10 seconds
+var DefaultConfig = Config{
+	RequestThreshold:    1000,
+	WindowSeconds:       60,
+	AttackThreshold:     10,
+	ScanIntervalSeconds: 10,
+}
+
+const (
+	maxWindowSeconds       = 3600
+	minScanIntervalSeconds = 2
+	maxScanIntervalSeconds = 3600
+)
+
+// ConfigFromEnv reads DDOS_REQUEST_THRESHOLD, DDOS_WINDOW, DDOS_ATTACK_THRESHOLD
+// and DDOS_SCAN_INTERVAL, falling back to DefaultConfig
+func ConfigFromEnv() (Config, error) {
+	config := DefaultConfig
+	if v := os.Getenv("DDOS_REQUEST_THRESHOLD"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid DDOS_REQUEST_THRESHOLD %q", v)
+		}
+		config.RequestThreshold = n
+	}
+	if v := os.Getenv("DDOS_ATTACK_THRESHOLD"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid DDOS_ATTACK_THRESHOLD %q", v)
+		}
+		config.AttackThreshold = n
+	}
+	for env, seconds := range map[string]*int{
+		"DDOS_WINDOW":        &config.WindowSeconds,
+		"DDOS_SCAN_INTERVAL": &config.ScanIntervalSeconds,
+	} {
+		if v := os.Getenv(env); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return Config{}, fmt.Errorf("invalid %s %q", env, v)
+			}
+			*seconds = int(d / time.Second)
+		}
+	}
+	return config, config.Validate()
+}
+
+// Validate rejects thresholds the monitor cannot work with
+func (c Config) Validate() error {
+	if c.RequestThreshold < 1 {
+		return fmt.Errorf("request threshold must be at least 1")
+	}
+	if c.WindowSeconds < 1 || c.WindowSeconds > maxWindowSeconds {
+		return fmt.Errorf("window must be between 1 and %d seconds", maxWindowSeconds)
+	}
+	if c.AttackThreshold < 1 {
+		return fmt.Errorf("attack threshold must be at least 1")
+	}
+	if c.ScanIntervalSeconds < minScanIntervalSeconds || c.ScanIntervalSeconds > maxScanIntervalSeconds {
+		return fmt.Errorf("scan interval must be between %d and %d seconds", minScanIntervalSeconds, maxScanIntervalSeconds)
+	}
+	return nil
+}
+
+func (c Config) Window() time.Duration {
+	return time.Duration(c.WindowSeconds) * time.Second
+}
+
+func (c Config) ScanInterval() time.Duration {
+	return time.Duration(c.ScanIntervalSeconds) * time.Second
+}
+
+// scanLockTTL lapses just before the next scan, so each scan runs on
+// whichever replica takes the lock first
+func (c Config) scanLockTTL() time.Duration {
+	interval := c.ScanInterval()
+	return interval - interval/10
+}
+
+// ConfigUpdate changes some thresholds at runtime; nil fields keep their value
+type ConfigUpdate struct {
+	RequestThreshold    *int64 `json:"request_threshold,omitempty"`
+	WindowSeconds       *int   `json:"window_seconds,omitempty"`
+	AttackThreshold     *int   `json:"attack_threshold,omitempty"`
+	ScanIntervalSeconds *int   `json:"scan_interval_seconds,omitempty"`
+}
+
+// Apply returns config with the update's fields set
+func (u *ConfigUpdate) Apply(config Config) Config {
+	if u.RequestThreshold != nil {
+		config.RequestThreshold = *u.RequestThreshold
+	}
+	if u.WindowSeconds != nil {
+		config.WindowSeconds = *u.WindowSeconds
+	}
+	if u.AttackThreshold != nil {
+		config.AttackThreshold = *u.AttackThreshold
+	}
+	if u.ScanIntervalSeconds != nil {
+		config.ScanIntervalSeconds = *u.ScanIntervalSeconds
+	}
+	return config
+}
@@ -0,0 +1,29 @@
+package ddos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("DDOS_REQUEST_THRESHOLD", "500")
+	t.Setenv("DDOS_WINDOW", "30s")
+	t.Setenv("DDOS_SCAN_INTERVAL", "5s")
+
+	config, err := ConfigFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, Config{
+		RequestThreshold:    500,
+		WindowSeconds:       30,
+		AttackThreshold:     DefaultConfig.AttackThreshold,
+		ScanIntervalSeconds: 5,
+	}, config)
+}
+
+func TestConfigFromEnv_Invalid(t *testing.T) {
+	t.Setenv("DDOS_WINDOW", "2h")
+
+	_, err := ConfigFromEnv()
+	assert.EqualError(t, err, "window must be between 1 and 3600 seconds")
+}
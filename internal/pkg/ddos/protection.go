@@ -2,34 +2,149 @@ package ddos
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
+	"github.com/darisadam/madabank-server/internal/pkg/distlock"
 	"github.com/darisadam/madabank-server/internal/pkg/logger"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 )
 
+// configKey holds thresholds changed at runtime, shared by all replicas.
+// Without it every replica uses the config it was started with.
+const configKey = "ddos:config"
+
 type DDoSProtection struct {
-	redis *redis.Client
+	redis  *redis.Client
+	locker *distlock.Locker
+
+	// base is the startup config, restored when the override is cleared
+	base    Config
+	mu      sync.RWMutex
+	config  Config
+	changed chan struct{}
 }
 
 func NewDDoSProtection(redisClient *redis.Client) *DDoSProtection {
+	return NewDDoSProtectionWithConfig(redisClient, DefaultConfig)
+}
+
+// NewDDoSProtectionWithConfig starts from the given thresholds; see ConfigFromEnv
+func NewDDoSProtectionWithConfig(redisClient *redis.Client, config Config) *DDoSProtection {
 	return &DDoSProtection{
-		redis: redisClient,
+		redis:   redisClient,
+		locker:  distlock.New(redisClient),
+		base:    config,
+		config:  config,
+		changed: make(chan struct{}, 1),
+	}
+}
+
+// Config returns the thresholds in effect on this replica
+func (d *DDoSProtection) Config() Config {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.config
+}
+
+// UpdateConfig changes thresholds on every replica. This one applies them at
+// once; the others pick them up at their next scan.
+func (d *DDoSProtection) UpdateConfig(ctx context.Context, update *ConfigUpdate) (Config, error) {
+	current, err := d.RefreshConfig(ctx)
+	if err != nil {
+		return Config{}, err
+	}
+	config := update.Apply(current)
+	if err := config.Validate(); err != nil {
+		return Config{}, err
+	}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to encode DDoS config: %w", err)
+	}
+	if err := d.redis.Set(ctx, configKey, data, 0).Err(); err != nil {
+		return Config{}, fmt.Errorf("failed to save DDoS config: %w", err)
+	}
+	d.setConfig(config)
+	return config, nil
+}
+
+// ResetConfig drops the runtime override, returning every replica to its
+// startup config
+func (d *DDoSProtection) ResetConfig(ctx context.Context) (Config, error) {
+	if err := d.redis.Del(ctx, configKey).Err(); err != nil {
+		return Config{}, fmt.Errorf("failed to reset DDoS config: %w", err)
+	}
+	d.setConfig(d.base)
+	return d.base, nil
+}
+
+// RefreshConfig loads the shared override, if any, and returns the config
+// now in effect
+func (d *DDoSProtection) RefreshConfig(ctx context.Context) (Config, error) {
+	data, err := d.redis.Get(ctx, configKey).Bytes()
+	if err == redis.Nil {
+		d.setConfig(d.base)
+		return d.base, nil
+	}
+	if err != nil {
+		return d.Config(), fmt.Errorf("failed to load DDoS config: %w", err)
+	}
+
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return d.Config(), fmt.Errorf("failed to decode DDoS config: %w", err)
+	}
+	if err := config.Validate(); err != nil {
+		return d.Config(), fmt.Errorf("stored DDoS config is invalid: %w", err)
+	}
+	d.setConfig(config)
+	return config, nil
+}
+
+func (d *DDoSProtection) setConfig(config Config) {
+	d.mu.Lock()
+	changed := d.config != config
+	d.config = config
+	d.mu.Unlock()
+
+	if changed {
+		logger.Info("DDoS thresholds changed",
+			zap.Int64("request_threshold", config.RequestThreshold),
+			zap.Int("window_seconds", config.WindowSeconds),
+			zap.Int("attack_threshold", config.AttackThreshold),
+			zap.Int("scan_interval_seconds", config.ScanIntervalSeconds),
+		)
+		// Wake the monitor so a new interval applies now, not after the old one
+		select {
+		case d.changed <- struct{}{}:
+		default:
+		}
 	}
 }
 
+// trackScript counts a request and starts the window on the first one.
+// Later requests do not extend the window, so the count is the requests
+// within one window, as the threshold assumes.
+//
+//	KEYS[1] request counter, ARGV[1] window in milliseconds
+var trackScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if redis.call("PTTL", KEYS[1]) < 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return count
+`)
+
 // TrackRequest tracks incoming requests per IP
 func (d *DDoSProtection) TrackRequest(ctx context.Context, ip string) error {
 	key := fmt.Sprintf("ddos:requests:%s", ip)
-
-	pipe := d.redis.Pipeline()
-	pipe.Incr(ctx, key)
-	pipe.Expire(ctx, key, time.Minute)
-
-	_, err := pipe.Exec(ctx)
-	return err
+	return trackScript.Run(ctx, d.redis, []string{key}, d.Config().Window().Milliseconds()).Err()
 }
 
 // CheckThreshold checks if IP has exceeded DDoS threshold
@@ -47,27 +162,63 @@ func (d *DDoSProtection) CheckThreshold(ctx context.Context, ip string, threshol
 	return count > threshold, nil
 }
 
-// MonitorGlobalTraffic monitors overall system traffic
+// MonitorGlobalTraffic monitors overall system traffic. The request counters
+// are shared, so each scan runs on one replica only. Threshold changes are
+// picked up before every scan, and a new scan interval takes effect at once.
 func (d *DDoSProtection) MonitorGlobalTraffic(ctx context.Context) {
-	ticker := time.NewTicker(10 * time.Second)
+	interval := d.Config().ScanInterval()
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
+		case <-d.changed:
 		case <-ticker.C:
-			d.analyzeTraffic(ctx)
+			if _, err := d.RefreshConfig(ctx); err != nil {
+				logger.Error("Failed to refresh DDoS config", zap.Error(err))
+			}
+			d.scanOnce(ctx)
+		}
+
+		if next := d.Config().ScanInterval(); next != interval {
+			interval = next
+			ticker.Reset(interval)
+		}
+	}
+}
+
+// scanOnce analyzes traffic unless another replica already took this
+// interval's scan. The lock is left to expire rather than released, so
+// replicas whose tickers fire later in the interval skip it.
+func (d *DDoSProtection) scanOnce(ctx context.Context) bool {
+	if _, err := d.locker.Acquire(ctx, "ddos:monitor", d.Config().scanLockTTL()); err != nil {
+		if !errors.Is(err, distlock.ErrNotAcquired) {
+			logger.Error("Failed to lock traffic scan", zap.Error(err))
 		}
+		return false
 	}
+
+	d.analyzeTraffic(ctx)
+	return true
+}
+
+// FlaggedIP is an IP whose request count is over the threshold
+type FlaggedIP struct {
+	IP       string `json:"ip"`
+	Requests int64  `json:"requests"`
 }
 
-func (d *DDoSProtection) analyzeTraffic(ctx context.Context) {
+// FlaggedIPs returns the IPs currently over the request threshold
+func (d *DDoSProtection) FlaggedIPs(ctx context.Context) ([]FlaggedIP, error) {
+	config := d.Config()
+
 	// Get all IP request counts
 	pattern := "ddos:requests:*"
 	iter := d.redis.Scan(ctx, 0, pattern, 0).Iterator()
 
-	suspiciousIPs := []string{}
+	flagged := []FlaggedIP{}
 
 	for iter.Next(ctx) {
 		key := iter.Val()
@@ -76,24 +227,35 @@ func (d *DDoSProtection) analyzeTraffic(ctx context.Context) {
 			continue
 		}
 
-		// Flag IPs with > 1000 requests/minute
-		if count > 1000 {
-			ip := key[len("ddos:requests:"):]
-			suspiciousIPs = append(suspiciousIPs, ip)
-
-			logger.Warn("Potential DDoS attack detected",
-				zap.String("ip", ip),
-				zap.Int64("requests_per_minute", count),
-			)
+		if count > config.RequestThreshold {
+			flagged = append(flagged, FlaggedIP{IP: key[len("ddos:requests:"):], Requests: count})
 		}
 	}
 
 	if err := iter.Err(); err != nil {
+		return flagged, fmt.Errorf("failed to scan traffic: %w", err)
+	}
+	return flagged, nil
+}
+
+func (d *DDoSProtection) analyzeTraffic(ctx context.Context) []FlaggedIP {
+	config := d.Config()
+
+	suspiciousIPs, err := d.FlaggedIPs(ctx)
+	if err != nil {
 		logger.Error("Failed to scan traffic", zap.Error(err))
 	}
 
+	for _, flagged := range suspiciousIPs {
+		logger.Warn("Potential DDoS attack detected",
+			zap.String("ip", flagged.IP),
+			zap.Int64("requests", flagged.Requests),
+			zap.Int("window_seconds", config.WindowSeconds),
+		)
+	}
+
 	// If many IPs are attacking, enable global rate limiting
-	if len(suspiciousIPs) > 10 {
+	if len(suspiciousIPs) > config.AttackThreshold {
 		logger.Error("Large-scale DDoS attack detected",
 			zap.Int("suspicious_ips", len(suspiciousIPs)),
 		)
@@ -101,4 +263,5 @@ func (d *DDoSProtection) analyzeTraffic(ctx context.Context) {
 		// TODO: Enable emergency mode
 		// TODO: Send alert to ops team
 	}
+	return suspiciousIPs
 }
@@ -0,0 +1,156 @@
+package ddos
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+)
+
+func TestScanOnce_OneReplicaPerInterval(t *testing.T) {
+	logger.Init("test")
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	t.Cleanup(mr.Close)
+	ctx := context.Background()
+
+	a := NewDDoSProtection(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	b := NewDDoSProtection(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+
+	assert.True(t, a.scanOnce(ctx))
+	assert.False(t, b.scanOnce(ctx))
+
+	mr.FastForward(DefaultConfig.ScanInterval())
+	assert.True(t, b.scanOnce(ctx))
+}
+
+func TestCheckThreshold(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	t.Cleanup(mr.Close)
+	ctx := context.Background()
+	d := NewDDoSProtection(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, d.TrackRequest(ctx, "10.0.0.1"))
+	}
+
+	over, err := d.CheckThreshold(ctx, "10.0.0.1", 2)
+	assert.NoError(t, err)
+	assert.True(t, over)
+
+	over, err = d.CheckThreshold(ctx, "10.0.0.2", 2)
+	assert.NoError(t, err)
+	assert.False(t, over)
+	assert.Equal(t, time.Minute, mr.TTL("ddos:requests:10.0.0.1"))
+}
+
+func TestTrackRequest_WindowStartsAtFirstRequest(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	t.Cleanup(mr.Close)
+	ctx := context.Background()
+	d := NewDDoSProtection(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+
+	assert.NoError(t, d.TrackRequest(ctx, "10.0.0.1"))
+	mr.FastForward(40 * time.Second)
+	assert.NoError(t, d.TrackRequest(ctx, "10.0.0.1"))
+
+	assert.Equal(t, 20*time.Second, mr.TTL("ddos:requests:10.0.0.1"))
+	mr.FastForward(20 * time.Second)
+	over, err := d.CheckThreshold(ctx, "10.0.0.1", 0)
+	assert.NoError(t, err)
+	assert.False(t, over)
+}
+
+func TestUpdateConfig_SharedAcrossReplicas(t *testing.T) {
+	logger.Init("test")
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	t.Cleanup(mr.Close)
+	ctx := context.Background()
+
+	a := NewDDoSProtection(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	b := NewDDoSProtection(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+
+	threshold := int64(200)
+	config, err := a.UpdateConfig(ctx, &ConfigUpdate{RequestThreshold: &threshold})
+	assert.NoError(t, err)
+	assert.Equal(t, threshold, config.RequestThreshold)
+	assert.Equal(t, DefaultConfig.WindowSeconds, config.WindowSeconds)
+	assert.Equal(t, config, a.Config())
+
+	// b still runs on its own config until it refreshes
+	assert.Equal(t, DefaultConfig, b.Config())
+	refreshed, err := b.RefreshConfig(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, config, refreshed)
+
+	reset, err := a.ResetConfig(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultConfig, reset)
+	refreshed, err = b.RefreshConfig(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultConfig, refreshed)
+}
+
+func TestUpdateConfig_Invalid(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	t.Cleanup(mr.Close)
+	d := NewDDoSProtection(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+
+	interval := 1
+	_, err = d.UpdateConfig(context.Background(), &ConfigUpdate{ScanIntervalSeconds: &interval})
+	assert.EqualError(t, err, "scan interval must be between 2 and 3600 seconds")
+	assert.Equal(t, DefaultConfig, d.Config())
+	assert.False(t, mr.Exists(configKey))
+}
+
+func TestAnalyzeTraffic_UsesConfiguredThreshold(t *testing.T) {
+	logger.Init("test")
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	t.Cleanup(mr.Close)
+	ctx := context.Background()
+
+	config := DefaultConfig
+	config.RequestThreshold = 2
+	config.WindowSeconds = 30
+	d := NewDDoSProtectionWithConfig(redis.NewClient(&redis.Options{Addr: mr.Addr()}), config)
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, d.TrackRequest(ctx, "10.0.0.1"))
+	}
+	assert.NoError(t, d.TrackRequest(ctx, "10.0.0.2"))
+
+	assert.Equal(t, []FlaggedIP{{IP: "10.0.0.1", Requests: 3}}, d.analyzeTraffic(ctx))
+	assert.Equal(t, 30*time.Second, mr.TTL("ddos:requests:10.0.0.1"))
+}
+
+func TestMonitorGlobalTraffic_AppliesNewInterval(t *testing.T) {
+	logger.Init("test")
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	t.Cleanup(mr.Close)
+
+	config := DefaultConfig
+	config.ScanIntervalSeconds = 3600
+	d := NewDDoSProtectionWithConfig(redis.NewClient(&redis.Options{Addr: mr.Addr()}), config)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.MonitorGlobalTraffic(ctx)
+
+	// The hourly ticker would not scan during the test; the new interval
+	// applies without waiting for it
+	interval := 2
+	_, err = d.UpdateConfig(ctx, &ConfigUpdate{ScanIntervalSeconds: &interval})
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool { return mr.Exists("lock:ddos:monitor") }, 5*time.Second, 50*time.Millisecond)
+}
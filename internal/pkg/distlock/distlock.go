@@ -0,0 +1,154 @@
+// Package distlock provides locks held in Redis, so work that must not run
+// twice (a scheduled posting, a traffic scan) runs on one API replica at a
+// time. Locks expire after their TTL unless renewed, so a crashed holder
+// cannot block others for long.
+//
+// Every acquisition gets a fencing token that increases per lock name. A
+// holder that stalls past its TTL may still believe it holds the lock; passing
+// the token along with guarded writes lets the receiver reject the stale one.
+package distlock
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+)
+
+var (
+	// ErrNotAcquired is returned when another owner holds the lock
+	ErrNotAcquired = errors.New("lock is held by another owner")
+	// ErrLockLost is returned when renewing a lock that expired or was taken over
+	ErrLockLost = errors.New("lock is no longer held")
+)
+
+// acquireScript sets the lock if free and returns the next fencing token, or 0
+var acquireScript = redis.NewScript(`
+if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	return redis.call("INCR", KEYS[2])
+end
+return 0
+`)
+
+// renewScript extends the lock only while its value is still ours
+var renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseScript deletes the lock only while its value is still ours
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// Locker acquires named locks
+type Locker struct {
+	client *redis.Client
+}
+
+func New(client *redis.Client) *Locker {
+	return &Locker{client: client}
+}
+
+// Lock is one acquisition of a named lock
+type Lock struct {
+	client *redis.Client
+	key    string
+	value  string
+	ttl    time.Duration
+
+	// Token is the fencing token of this acquisition. Later acquisitions of
+	// the same lock always get a higher token.
+	Token int64
+}
+
+func lockKey(name string) string {
+	return "lock:" + name
+}
+
+func fenceKey(name string) string {
+	return "lock:" + name + ":fence"
+}
+
+// Acquire takes the lock for ttl, returning ErrNotAcquired if it is held
+func (l *Locker) Acquire(ctx context.Context, name string, ttl time.Duration) (*Lock, error) {
+	value := uuid.New().String()
+	token, err := acquireScript.Run(ctx, l.client, []string{lockKey(name), fenceKey(name)}, value, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return nil, err
+	}
+	if token == 0 {
+		return nil, ErrNotAcquired
+	}
+
+	return &Lock{client: l.client, key: lockKey(name), value: value, ttl: ttl, Token: token}, nil
+}
+
+// WithLock runs fn while holding the lock, renewing it every third of its
+// TTL. If a renewal fails, fn's context is cancelled, since another owner may
+// take the lock. It returns ErrNotAcquired without running fn if the lock is
+// held.
+func (l *Locker) WithLock(ctx context.Context, name string, ttl time.Duration, fn func(ctx context.Context, token int64) error) error {
+	lock, err := l.Acquire(ctx, name, ttl)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := lock.Renew(ctx); err != nil {
+					logger.Warn("Lost lock while running", zap.String("lock", name), zap.Error(err))
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	err = fn(ctx, lock.Token)
+	close(done)
+
+	if errRelease := lock.Release(context.Background()); errRelease != nil {
+		logger.Warn("Failed to release lock", zap.String("lock", name), zap.Error(errRelease))
+	}
+	return err
+}
+
+// Renew extends the lock by its TTL, returning ErrLockLost if it expired or
+// another owner took it
+func (l *Lock) Renew(ctx context.Context) error {
+	renewed, err := renewScript.Run(ctx, l.client, []string{l.key}, l.value, l.ttl.Milliseconds()).Int()
+	if err != nil {
+		return err
+	}
+	if renewed == 0 {
+		return ErrLockLost
+	}
+	return nil
+}
+
+// Release frees the lock so others can take it right away. Releasing a lock
+// that was already lost is a no-op.
+func (l *Lock) Release(ctx context.Context) error {
+	return releaseScript.Run(ctx, l.client, []string{l.key}, l.value).Err()
+}
@@ -0,0 +1,108 @@
+package distlock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+)
+
+func init() {
+	logger.Init("test")
+}
+
+func setupLockerTest(t *testing.T) (*miniredis.Miniredis, *Locker) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	t.Cleanup(mr.Close)
+
+	return mr, New(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+}
+
+func TestAcquire_Exclusive(t *testing.T) {
+	_, locker := setupLockerTest(t)
+	ctx := context.Background()
+
+	lock, err := locker.Acquire(ctx, "statement_cycle", 30*time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), lock.Token)
+
+	_, err = locker.Acquire(ctx, "statement_cycle", 30*time.Second)
+	assert.ErrorIs(t, err, ErrNotAcquired)
+
+	other, err := locker.Acquire(ctx, "ddos_monitor", 30*time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), other.Token)
+}
+
+func TestAcquire_TokenIncreasesAfterExpiry(t *testing.T) {
+	mr, locker := setupLockerTest(t)
+	ctx := context.Background()
+
+	first, _ := locker.Acquire(ctx, "statement_cycle", 30*time.Second)
+	mr.FastForward(31 * time.Second)
+	second, err := locker.Acquire(ctx, "statement_cycle", 30*time.Second)
+
+	assert.NoError(t, err)
+	assert.Greater(t, second.Token, first.Token)
+	// The stale holder can neither renew nor release the new holder's lock
+	assert.ErrorIs(t, first.Renew(ctx), ErrLockLost)
+	assert.NoError(t, first.Release(ctx))
+	_, err = locker.Acquire(ctx, "statement_cycle", 30*time.Second)
+	assert.ErrorIs(t, err, ErrNotAcquired)
+}
+
+func TestRenewAndRelease(t *testing.T) {
+	mr, locker := setupLockerTest(t)
+	ctx := context.Background()
+
+	lock, _ := locker.Acquire(ctx, "statement_cycle", 30*time.Second)
+	mr.FastForward(20 * time.Second)
+	assert.NoError(t, lock.Renew(ctx))
+	mr.FastForward(20 * time.Second)
+	_, err := locker.Acquire(ctx, "statement_cycle", 30*time.Second)
+	assert.ErrorIs(t, err, ErrNotAcquired)
+
+	assert.NoError(t, lock.Release(ctx))
+	_, err = locker.Acquire(ctx, "statement_cycle", 30*time.Second)
+	assert.NoError(t, err)
+}
+
+func TestWithLock(t *testing.T) {
+	_, locker := setupLockerTest(t)
+	ctx := context.Background()
+	failure := errors.New("run failed")
+
+	err := locker.WithLock(ctx, "statement_cycle", 30*time.Second, func(ctx context.Context, token int64) error {
+		assert.Equal(t, int64(1), token)
+		// Held for the duration of the run
+		_, err := locker.Acquire(ctx, "statement_cycle", 30*time.Second)
+		assert.ErrorIs(t, err, ErrNotAcquired)
+		return failure
+	})
+
+	assert.ErrorIs(t, err, failure)
+	_, err = locker.Acquire(ctx, "statement_cycle", 30*time.Second)
+	assert.NoError(t, err, "released after the run")
+}
+
+func TestWithLock_HeldElsewhere(t *testing.T) {
+	_, locker := setupLockerTest(t)
+	ctx := context.Background()
+	_, _ = locker.Acquire(ctx, "statement_cycle", 30*time.Second)
+
+	ran := false
+	err := locker.WithLock(ctx, "statement_cycle", 30*time.Second, func(context.Context, int64) error {
+		ran = true
+		return nil
+	})
+
+	assert.ErrorIs(t, err, ErrNotAcquired)
+	assert.False(t, ran)
+}
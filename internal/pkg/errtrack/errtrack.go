@@ -0,0 +1,112 @@
+package errtrack
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"go.uber.org/zap"
+
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+)
+
+// enabled is false until Init succeeds with a DSN, so every helper in this
+// package is a cheap no-op when error tracking is not configured.
+var enabled atomic.Bool
+
+// Config holds the error tracker settings
+type Config struct {
+	DSN         string
+	Environment string
+	Version     string
+	CommitSHA   string
+	SampleRate  float64
+}
+
+// Init configures Sentry. An empty DSN leaves error tracking disabled.
+func Init(cfg Config) error {
+	if cfg.DSN == "" {
+		return nil
+	}
+
+	sampleRate := cfg.SampleRate
+	if sampleRate == 0 {
+		sampleRate = 1.0
+	}
+
+	err := sentry.Init(sentry.ClientOptions{
+		Dsn:              cfg.DSN,
+		Environment:      cfg.Environment,
+		Release:          Release(cfg.Version, cfg.CommitSHA),
+		SampleRate:       sampleRate,
+		AttachStacktrace: true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize sentry: %w", err)
+	}
+
+	sentry.ConfigureScope(func(scope *sentry.Scope) {
+		scope.SetTag("commit_sha", cfg.CommitSHA)
+	})
+
+	enabled.Store(true)
+	return nil
+}
+
+// Release builds the release identifier reported with every event
+func Release(version, commitSHA string) string {
+	return fmt.Sprintf("madabank-server@%s+%s", version, commitSHA)
+}
+
+// Enabled reports whether events are being sent
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// CaptureError reports err with optional tags (e.g. component, operation)
+func CaptureError(err error, tags map[string]string) {
+	if err == nil || !enabled.Load() {
+		return
+	}
+
+	sentry.WithScope(func(scope *sentry.Scope) {
+		for k, v := range tags {
+			scope.SetTag(k, v)
+		}
+		sentry.CaptureException(err)
+	})
+}
+
+// RecoverWorker is deferred at the top of background goroutines. It reports
+// a panic to the error tracker and logs it instead of crashing the process.
+func RecoverWorker(worker string) {
+	if r := recover(); r != nil {
+		logger.Error("Background worker panicked",
+			zap.String("worker", worker),
+			zap.Any("panic", r),
+		)
+		CapturePanic(r, map[string]string{"worker": worker})
+	}
+}
+
+// Flush waits for buffered events to be delivered
+func Flush(timeout time.Duration) {
+	if enabled.Load() {
+		sentry.Flush(timeout)
+	}
+}
+
+// CapturePanic reports a recovered panic value with optional tags
+func CapturePanic(recovered interface{}, tags map[string]string) {
+	if recovered == nil || !enabled.Load() {
+		return
+	}
+
+	sentry.WithScope(func(scope *sentry.Scope) {
+		for k, v := range tags {
+			scope.SetTag(k, v)
+		}
+		sentry.CurrentHub().Recover(recovered)
+	})
+}
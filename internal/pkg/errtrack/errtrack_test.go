@@ -0,0 +1,37 @@
+package errtrack
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+)
+
+func init() {
+	logger.Init("test")
+}
+
+func TestInit_EmptyDSNDisablesTracking(t *testing.T) {
+	assert.NoError(t, Init(Config{}))
+	assert.False(t, Enabled())
+
+	// Helpers must be safe no-ops when disabled
+	CaptureError(errors.New("boom"), map[string]string{"component": "test"})
+	CapturePanic("boom", nil)
+}
+
+func TestRelease(t *testing.T) {
+	assert.Equal(t, "madabank-server@v1.2.3+abc123", Release("v1.2.3", "abc123"))
+}
+
+func TestRecoverWorker_SwallowsPanic(t *testing.T) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer RecoverWorker("test-worker")
+		panic("worker failed")
+	}()
+	<-done
+}
@@ -0,0 +1,133 @@
+package fxrates
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/pkg/metrics"
+)
+
+// cacheKey holds the last snapshot fetched, shared by every replica
+const cacheKey = "fx:rates"
+
+// Config controls how long cached rates are used
+type Config struct {
+	// TTL is how long rates are served before the provider is asked again
+	TTL time.Duration
+	// MaxStaleness is how old rates may get while the provider is failing;
+	// beyond it no rates are quoted at all
+	MaxStaleness time.Duration
+}
+
+var DefaultConfig = Config{
+	TTL:          5 * time.Minute,
+	MaxStaleness: time.Hour,
+}
+
+// ConfigFromEnv reads FX_RATES_TTL and FX_RATES_MAX_STALENESS
+func ConfigFromEnv() (Config, error) {
+	config := DefaultConfig
+	for env, d := range map[string]*time.Duration{
+		"FX_RATES_TTL":           &config.TTL,
+		"FX_RATES_MAX_STALENESS": &config.MaxStaleness,
+	} {
+		if v := os.Getenv(env); v != "" {
+			parsed, err := time.ParseDuration(v)
+			if err != nil {
+				return Config{}, fmt.Errorf("invalid %s %q", env, v)
+			}
+			*d = parsed
+		}
+	}
+	return config, config.Validate()
+}
+
+func (c Config) Validate() error {
+	if c.TTL <= 0 {
+		return fmt.Errorf("FX rates TTL must be positive")
+	}
+	if c.MaxStaleness < c.TTL {
+		return fmt.Errorf("FX rates max staleness must be at least the TTL")
+	}
+	return nil
+}
+
+// Cache serves a provider's rates from Redis
+type Cache struct {
+	provider Provider
+	redis    *redis.Client
+	config   Config
+	now      func() time.Time
+}
+
+func NewCache(provider Provider, redisClient *redis.Client, config Config) *Cache {
+	return &Cache{provider: provider, redis: redisClient, config: config, now: time.Now}
+}
+
+type cachedSnapshot struct {
+	Snapshot  *Snapshot `json:"snapshot"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// Latest returns the provider's latest rates, fetched at most once per TTL.
+// When they are due for a refresh and the provider fails, the cached rates
+// are served as stale until they are MaxStaleness old; after that Latest
+// fails.
+func (c *Cache) Latest(ctx context.Context) (snapshot *Snapshot, stale bool, err error) {
+	now := c.now()
+	cached := c.read(ctx)
+	if cached != nil && now.Sub(cached.FetchedAt) < c.config.TTL {
+		return cached.Snapshot, false, nil
+	}
+
+	snapshot, err = c.provider.Latest(ctx)
+	metrics.RecordFXRateFetch(c.provider.Name(), err == nil)
+	if err != nil {
+		if cached != nil && now.Sub(cached.FetchedAt) < c.config.MaxStaleness {
+			logger.Warn("Serving stale FX rates", zap.String("provider", c.provider.Name()),
+				zap.Time("fetched_at", cached.FetchedAt), zap.Error(err))
+			return cached.Snapshot, true, nil
+		}
+		return nil, false, fmt.Errorf("FX rates unavailable: %w", err)
+	}
+
+	c.write(ctx, &cachedSnapshot{Snapshot: snapshot, FetchedAt: now})
+	return snapshot, false, nil
+}
+
+// read returns the cached snapshot, or nil when there is none or Redis fails
+func (c *Cache) read(ctx context.Context) *cachedSnapshot {
+	data, err := c.redis.Get(ctx, cacheKey).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			logger.Warn("Failed to read cached FX rates", zap.Error(err))
+		}
+		return nil
+	}
+	var cached cachedSnapshot
+	if err := json.Unmarshal(data, &cached); err != nil || cached.Snapshot == nil {
+		logger.Warn("Ignoring undecodable cached FX rates", zap.Error(err))
+		return nil
+	}
+	return &cached
+}
+
+func (c *Cache) write(ctx context.Context, cached *cachedSnapshot) {
+	data, err := json.Marshal(cached)
+	if err != nil {
+		logger.Warn("Failed to encode FX rates", zap.Error(err))
+		return
+	}
+	// Kept for as long as it may be served stale
+	if err := c.redis.Set(ctx, cacheKey, data, c.config.MaxStaleness).Err(); err != nil {
+		logger.Warn("Failed to cache FX rates", zap.Error(err))
+	}
+}
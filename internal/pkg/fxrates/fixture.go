@@ -0,0 +1,43 @@
+package fxrates
+
+import (
+	"context"
+	"time"
+)
+
+// fixtureRates are USD rates close to the market, for development and tests
+var fixtureRates = map[string]float64{
+	"AUD": 1.52,
+	"CHF": 0.88,
+	"CNY": 7.2,
+	"EUR": 0.92,
+	"GBP": 0.79,
+	"HKD": 7.8,
+	"IDR": 16300,
+	"JPY": 150,
+	"KRW": 1380,
+	"MYR": 4.7,
+	"SAR": 3.75,
+	"SGD": 1.34,
+	"THB": 36,
+}
+
+// FixtureProvider quotes fixed rates without a provider contract
+// (development only)
+type FixtureProvider struct{}
+
+func NewFixtureProvider() *FixtureProvider {
+	return &FixtureProvider{}
+}
+
+func (p *FixtureProvider) Latest(ctx context.Context) (*Snapshot, error) {
+	rates := make(map[string]float64, len(fixtureRates))
+	for code, r := range fixtureRates {
+		rates[code] = r
+	}
+	return &Snapshot{Base: "USD", Rates: rates, AsOf: time.Now().UTC().Truncate(time.Second)}, nil
+}
+
+func (p *FixtureProvider) Name() string {
+	return "fixture"
+}
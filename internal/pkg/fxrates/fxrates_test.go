@@ -0,0 +1,179 @@
+package fxrates
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+)
+
+func init() {
+	logger.Init("test")
+}
+
+func TestFixtureProvider(t *testing.T) {
+	snapshot, err := NewFixtureProvider().Latest(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "USD", snapshot.Base)
+	assert.Equal(t, 16300.0, snapshot.Rates["IDR"])
+	assert.WithinDuration(t, time.Now(), snapshot.AsOf, time.Minute)
+}
+
+func TestHTTPProvider(t *testing.T) {
+	body := `{"base":"USD","timestamp":1760601600,"rates":{"IDR":16250.5,"EUR":0.91}}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.Header.Get("X-API-Key"))
+		if r.URL.Path != "/latest" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	provider, err := NewHTTPProvider(server.URL+"/", "secret")
+	require.NoError(t, err)
+
+	snapshot, err := provider.Latest(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &Snapshot{
+		Base:  "USD",
+		Rates: map[string]float64{"IDR": 16250.5, "EUR": 0.91},
+		AsOf:  time.Date(2025, 10, 16, 8, 0, 0, 0, time.UTC),
+	}, snapshot)
+
+	body = `{"base":"USD","timestamp":1760601600,"rates":{"IDR":0}}`
+	_, err = provider.Latest(context.Background())
+	assert.EqualError(t, err, "FX rate provider returned rate 0 for IDR")
+
+	body = `{"rates":{}}`
+	_, err = provider.Latest(context.Background())
+	assert.EqualError(t, err, "FX rate provider returned incomplete rates")
+
+	_, err = NewHTTPProvider("", "secret")
+	assert.Error(t, err)
+}
+
+func TestSnapshot_Rebase(t *testing.T) {
+	snapshot := &Snapshot{Base: "USD", Rates: map[string]float64{"IDR": 16300, "EUR": 0.92, "XAU": 0.0004}}
+
+	rates, err := snapshot.Rebase("USD")
+	require.NoError(t, err)
+	// Unsupported currencies are left out
+	assert.Equal(t, map[string]float64{"IDR": 16300, "EUR": 0.92}, rates)
+
+	rates, err = snapshot.Rebase("IDR")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"USD": 6.1349693e-05, "EUR": 5.6441718e-05}, rates)
+
+	_, err = snapshot.Rebase("JPY")
+	assert.EqualError(t, err, "no rate for JPY")
+}
+
+// stubProvider counts fetches and fails while err is set
+type stubProvider struct {
+	fetches int
+	err     error
+}
+
+func (p *stubProvider) Latest(ctx context.Context) (*Snapshot, error) {
+	p.fetches++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &Snapshot{Base: "USD", Rates: map[string]float64{"IDR": float64(16000 + p.fetches)}}, nil
+}
+
+func (p *stubProvider) Name() string {
+	return "stub"
+}
+
+func setupCacheTest(t *testing.T) (*stubProvider, *Cache, *time.Time) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(mr.Close)
+
+	provider := &stubProvider{}
+	c := NewCache(provider, redis.NewClient(&redis.Options{Addr: mr.Addr()}), Config{TTL: time.Minute, MaxStaleness: time.Hour})
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+	return provider, c, &now
+}
+
+func TestCache_ServesFreshRates(t *testing.T) {
+	provider, c, now := setupCacheTest(t)
+	ctx := context.Background()
+
+	first, stale, err := c.Latest(ctx)
+	require.NoError(t, err)
+	assert.False(t, stale)
+
+	*now = now.Add(59 * time.Second)
+	second, _, err := c.Latest(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.Equal(t, 1, provider.fetches)
+
+	// Refreshed once the TTL is up
+	*now = now.Add(time.Second)
+	third, stale, err := c.Latest(ctx)
+	require.NoError(t, err)
+	assert.False(t, stale)
+	assert.Equal(t, 16002.0, third.Rates["IDR"])
+}
+
+func TestCache_StaleWhileProviderFails(t *testing.T) {
+	provider, c, now := setupCacheTest(t)
+	ctx := context.Background()
+
+	_, _, err := c.Latest(ctx)
+	require.NoError(t, err)
+
+	provider.err = errors.New("connection refused")
+	*now = now.Add(30 * time.Minute)
+	snapshot, stale, err := c.Latest(ctx)
+	require.NoError(t, err)
+	assert.True(t, stale)
+	assert.Equal(t, 16001.0, snapshot.Rates["IDR"])
+
+	*now = now.Add(30 * time.Minute)
+	_, _, err = c.Latest(ctx)
+	assert.EqualError(t, err, "FX rates unavailable: connection refused")
+}
+
+func TestCache_NothingCached(t *testing.T) {
+	provider, c, _ := setupCacheTest(t)
+	provider.err = errors.New("connection refused")
+
+	_, _, err := c.Latest(context.Background())
+	assert.EqualError(t, err, "FX rates unavailable: connection refused")
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	config, err := ConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultConfig, config)
+
+	t.Setenv("FX_RATES_TTL", "30s")
+	t.Setenv("FX_RATES_MAX_STALENESS", "10m")
+	config, err = ConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, Config{TTL: 30 * time.Second, MaxStaleness: 10 * time.Minute}, config)
+
+	t.Setenv("FX_RATES_MAX_STALENESS", "10s")
+	_, err = ConfigFromEnv()
+	assert.EqualError(t, err, "FX rates max staleness must be at least the TTL")
+
+	t.Setenv("FX_RATES_TTL", "soon")
+	_, err = ConfigFromEnv()
+	assert.EqualError(t, err, `invalid FX_RATES_TTL "soon"`)
+}
@@ -0,0 +1,80 @@
+package fxrates
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const httpTimeout = 10 * time.Second
+
+// HTTPProvider talks to a rate provider exposing a JSON API:
+//
+//	GET {base}/latest -> 200 {"base": "USD", "timestamp": 1760601600, "rates": {"IDR": 16300, ...}}
+//
+// Requests are authenticated with the X-API-Key header.
+type HTTPProvider struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+}
+
+func NewHTTPProvider(baseURL, apiKey string) (*HTTPProvider, error) {
+	if baseURL == "" {
+		return nil, fmt.Errorf("FX_RATE_PROVIDER_URL is required")
+	}
+	if apiKey == "" {
+		return nil, fmt.Errorf("FX_RATE_PROVIDER_API_KEY is required")
+	}
+	return &HTTPProvider{
+		client:  &http.Client{Timeout: httpTimeout},
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+	}, nil
+}
+
+type latestResponse struct {
+	Base      string             `json:"base"`
+	Timestamp int64              `json:"timestamp"`
+	Rates     map[string]float64 `json:"rates"`
+}
+
+func (p *HTTPProvider) Latest(ctx context.Context) (*Snapshot, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/latest", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("X-API-Key", p.apiKey)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("FX rate provider request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("FX rate provider returned status %d", resp.StatusCode)
+	}
+
+	var out latestResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("failed to decode FX rate provider response: %w", err)
+	}
+	if out.Base == "" || len(out.Rates) == 0 || out.Timestamp <= 0 {
+		return nil, fmt.Errorf("FX rate provider returned incomplete rates")
+	}
+	for code, r := range out.Rates {
+		if r <= 0 {
+			return nil, fmt.Errorf("FX rate provider returned rate %v for %s", r, code)
+		}
+	}
+
+	return &Snapshot{Base: out.Base, Rates: out.Rates, AsOf: time.Unix(out.Timestamp, 0).UTC()}, nil
+}
+
+func (p *HTTPProvider) Name() string {
+	return "http"
+}
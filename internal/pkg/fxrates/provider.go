@@ -0,0 +1,80 @@
+// Package fxrates fetches indicative foreign exchange rates from a rate
+// provider and caches them in Redis, so every replica quotes the same rates
+// and the provider is asked at most once per TTL.
+package fxrates
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/currency"
+)
+
+// Snapshot is a provider's mid-market rates at one moment: how many units of
+// each currency one unit of Base buys
+type Snapshot struct {
+	Base  string             `json:"base"`
+	Rates map[string]float64 `json:"rates"`
+	AsOf  time.Time          `json:"as_of"`
+}
+
+// Provider quotes the latest rates
+type Provider interface {
+	Latest(ctx context.Context) (*Snapshot, error)
+	Name() string
+}
+
+// FromEnv builds the provider selected by FX_RATE_PROVIDER
+func FromEnv() (Provider, error) {
+	switch os.Getenv("FX_RATE_PROVIDER") {
+	case "", "fixture":
+		return NewFixtureProvider(), nil
+	case "http":
+		return NewHTTPProvider(os.Getenv("FX_RATE_PROVIDER_URL"), os.Getenv("FX_RATE_PROVIDER_API_KEY"))
+	default:
+		return nil, fmt.Errorf("unknown FX_RATE_PROVIDER %q", os.Getenv("FX_RATE_PROVIDER"))
+	}
+}
+
+// Rebase returns the rates of the supported currencies against base, crossed
+// through the snapshot's own base. Currencies the bank does not support are
+// left out.
+func (s *Snapshot) Rebase(base string) (map[string]float64, error) {
+	perBase, ok := s.rate(base)
+	if !ok {
+		return nil, fmt.Errorf("no rate for %s", base)
+	}
+
+	rates := make(map[string]float64)
+	for _, c := range currency.Supported() {
+		if c.Code == base {
+			continue
+		}
+		if r, ok := s.rate(c.Code); ok {
+			rates[c.Code] = roundRate(r / perBase)
+		}
+	}
+	return rates, nil
+}
+
+func (s *Snapshot) rate(code string) (float64, bool) {
+	if code == s.Base {
+		return 1, true
+	}
+	r, ok := s.Rates[code]
+	return r, ok && r > 0
+}
+
+// roundRate keeps 8 significant digits, dropping the noise of crossing
+// rates in floating point (1 IDR is 0.000061349693 USD)
+func roundRate(r float64) float64 {
+	if r == 0 || math.IsInf(r, 0) || math.IsNaN(r) {
+		return r
+	}
+	rounded, _ := strconv.ParseFloat(strconv.FormatFloat(r, 'g', 8, 64), 64)
+	return rounded
+}
@@ -0,0 +1,93 @@
+package geoip
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"os"
+	"sort"
+	"strings"
+)
+
+type ipRange struct {
+	start, end netip.Addr
+	country    string
+}
+
+// CSVResolver looks client IPs up in an in-memory copy of a range file with
+// one "start_ip,end_ip,country_code" line per range
+type CSVResolver struct {
+	ranges []ipRange // sorted by start, not overlapping
+}
+
+// LoadCSVResolver reads the range file at path
+func LoadCSVResolver(path string) (*CSVResolver, error) {
+	if path == "" {
+		return nil, fmt.Errorf("GEOIP_DATABASE is required")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open GeoIP database: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	return NewCSVResolver(f)
+}
+
+func NewCSVResolver(r io.Reader) (*CSVResolver, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	var ranges []ipRange
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read GeoIP database: %w", err)
+		}
+		if len(record) < 3 {
+			return nil, fmt.Errorf("GeoIP database line %d: expected start,end,country", line)
+		}
+		start, errStart := netip.ParseAddr(strings.TrimSpace(record[0]))
+		end, errEnd := netip.ParseAddr(strings.TrimSpace(record[1]))
+		if errStart != nil || errEnd != nil || start.Is4() != end.Is4() || end.Less(start) {
+			return nil, fmt.Errorf("GeoIP database line %d: invalid range", line)
+		}
+		country := strings.ToUpper(strings.TrimSpace(record[2]))
+		// "ZZ" and "-" mark unassigned space in common databases
+		if len(country) != 2 || country == "ZZ" {
+			continue
+		}
+		ranges = append(ranges, ipRange{start: start, end: end, country: country})
+	}
+
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].start.Less(ranges[j].start) })
+	return &CSVResolver{ranges: ranges}, nil
+}
+
+func (r *CSVResolver) Country(req *http.Request, clientIP string) string {
+	addr, err := netip.ParseAddr(clientIP)
+	if err != nil {
+		return ""
+	}
+	addr = addr.Unmap()
+
+	// The last range starting at or before addr is the only one that can hold it
+	i := sort.Search(len(r.ranges), func(i int) bool { return addr.Less(r.ranges[i].start) })
+	if i == 0 {
+		return ""
+	}
+	candidate := r.ranges[i-1]
+	if candidate.start.Is4() != addr.Is4() || candidate.end.Less(addr) {
+		return ""
+	}
+	return candidate.country
+}
+
+func (r *CSVResolver) Name() string {
+	return "csv"
+}
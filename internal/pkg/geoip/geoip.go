@@ -0,0 +1,133 @@
+// Package geoip resolves the country a request comes from and decides, per
+// country, whether sensitive actions are allowed, need step-up verification
+// or are blocked. It is configured from GEOIP_* environment variables and
+// disabled unless GEOIP_PROVIDER is set.
+package geoip
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// DefaultCountryHeader is set by CloudFront when the viewer country header is
+// forwarded to the origin
+const DefaultCountryHeader = "CloudFront-Viewer-Country"
+
+// Resolver finds the ISO 3166-1 alpha-2 country of a request, or "" when it
+// is unknown
+type Resolver interface {
+	Country(r *http.Request, clientIP string) string
+	// Name identifies the resolver in logs
+	Name() string
+}
+
+// Action is what the policy asks for on a guarded request
+type Action string
+
+const (
+	ActionAllow     Action = "allow"
+	ActionChallenge Action = "challenge"
+	ActionBlock     Action = "block"
+)
+
+// Policy lists the countries whose logins and transfers are blocked or need
+// step-up verification. Unknown countries are allowed.
+type Policy struct {
+	Block     map[string]bool
+	Challenge map[string]bool
+}
+
+// NewPolicy builds a policy from country codes. A country in both lists is blocked.
+func NewPolicy(block, challenge []string) (Policy, error) {
+	p := Policy{Block: map[string]bool{}, Challenge: map[string]bool{}}
+	for list, codes := range map[*map[string]bool][]string{&p.Block: block, &p.Challenge: challenge} {
+		for _, code := range codes {
+			code = strings.ToUpper(strings.TrimSpace(code))
+			if code == "" {
+				continue
+			}
+			if len(code) != 2 {
+				return Policy{}, fmt.Errorf("invalid country code %q", code)
+			}
+			(*list)[code] = true
+		}
+	}
+	return p, nil
+}
+
+// Decide returns the action for a request from country
+func (p Policy) Decide(country string) Action {
+	switch {
+	case p.Block[country]:
+		return ActionBlock
+	case p.Challenge[country]:
+		return ActionChallenge
+	default:
+		return ActionAllow
+	}
+}
+
+// FromEnv builds the resolver selected by GEOIP_PROVIDER and the policy from
+// GEOIP_BLOCK_COUNTRIES and GEOIP_CHALLENGE_COUNTRIES (comma separated). The
+// resolver is nil when GEOIP_PROVIDER is empty.
+//
+//	header: trust the country header set by the CDN (GEOIP_COUNTRY_HEADER,
+//	        CloudFront-Viewer-Country by default). Only safe behind the CDN.
+//	csv:    look the client IP up in GEOIP_DATABASE, a "start,end,country"
+//	        range file such as the DB-IP Lite country CSV
+func FromEnv() (Resolver, Policy, error) {
+	policy, err := NewPolicy(splitList(os.Getenv("GEOIP_BLOCK_COUNTRIES")), splitList(os.Getenv("GEOIP_CHALLENGE_COUNTRIES")))
+	if err != nil {
+		return nil, Policy{}, err
+	}
+
+	var resolver Resolver
+	switch os.Getenv("GEOIP_PROVIDER") {
+	case "":
+		return nil, policy, nil
+	case "header":
+		resolver = NewHeaderResolver(os.Getenv("GEOIP_COUNTRY_HEADER"))
+	case "csv":
+		resolver, err = LoadCSVResolver(os.Getenv("GEOIP_DATABASE"))
+	default:
+		err = fmt.Errorf("unknown GEOIP_PROVIDER %q", os.Getenv("GEOIP_PROVIDER"))
+	}
+	if err != nil {
+		return nil, Policy{}, err
+	}
+	return resolver, policy, nil
+}
+
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+// HeaderResolver reads the country from a header set by the CDN in front of
+// the API
+type HeaderResolver struct {
+	header string
+}
+
+func NewHeaderResolver(header string) *HeaderResolver {
+	if header == "" {
+		header = DefaultCountryHeader
+	}
+	return &HeaderResolver{header: header}
+}
+
+func (r *HeaderResolver) Country(req *http.Request, clientIP string) string {
+	country := strings.ToUpper(strings.TrimSpace(req.Header.Get(r.header)))
+	if len(country) != 2 {
+		return ""
+	}
+	return country
+}
+
+func (r *HeaderResolver) Name() string {
+	return "header"
+}
@@ -0,0 +1,82 @@
+package geoip
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy_Decide(t *testing.T) {
+	policy, err := NewPolicy([]string{"kp", " IR"}, []string{"RU", "IR"})
+	require.NoError(t, err)
+
+	assert.Equal(t, ActionBlock, policy.Decide("KP"))
+	assert.Equal(t, ActionBlock, policy.Decide("IR")) // listed in both
+	assert.Equal(t, ActionChallenge, policy.Decide("RU"))
+	assert.Equal(t, ActionAllow, policy.Decide("ID"))
+	assert.Equal(t, ActionAllow, policy.Decide(""))
+
+	_, err = NewPolicy([]string{"Russia"}, nil)
+	assert.Error(t, err)
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("GEOIP_PROVIDER", "")
+	resolver, _, err := FromEnv()
+	assert.NoError(t, err)
+	assert.Nil(t, resolver)
+
+	t.Setenv("GEOIP_PROVIDER", "header")
+	t.Setenv("GEOIP_CHALLENGE_COUNTRIES", "SG,MY")
+	resolver, policy, err := FromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, "header", resolver.Name())
+	assert.Equal(t, ActionChallenge, policy.Decide("MY"))
+
+	t.Setenv("GEOIP_PROVIDER", "csv")
+	_, _, err = FromEnv()
+	assert.EqualError(t, err, "GEOIP_DATABASE is required")
+}
+
+func TestHeaderResolver(t *testing.T) {
+	resolver := NewHeaderResolver("")
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	assert.Equal(t, "", resolver.Country(req, "192.0.2.1"))
+
+	req.Header.Set(DefaultCountryHeader, "sg")
+	assert.Equal(t, "SG", resolver.Country(req, "192.0.2.1"))
+
+	req.Header.Set(DefaultCountryHeader, "Singapore")
+	assert.Equal(t, "", resolver.Country(req, "192.0.2.1"))
+}
+
+func TestCSVResolver(t *testing.T) {
+	resolver, err := NewCSVResolver(strings.NewReader(`1.0.0.0,1.0.0.255,AU
+36.64.0.0,36.95.255.255,ID
+10.0.0.0,10.255.255.255,ZZ
+2001:db8::,2001:db8:ffff:ffff:ffff:ffff:ffff:ffff,NL
+`))
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	for ip, want := range map[string]string{
+		"36.70.1.2":         "ID",
+		"1.0.0.255":         "AU",
+		"1.0.1.0":           "",
+		"10.1.2.3":          "", // unassigned
+		"::ffff:36.64.0.0":  "ID",
+		"2001:db8::1":       "NL",
+		"2001:db9::1":       "",
+		"0.0.0.1":           "",
+		"not an ip address": "",
+	} {
+		assert.Equal(t, want, resolver.Country(req, ip), ip)
+	}
+
+	_, err = NewCSVResolver(strings.NewReader("1.0.0.255,1.0.0.0,AU\n"))
+	assert.EqualError(t, err, "GeoIP database line 1: invalid range")
+}
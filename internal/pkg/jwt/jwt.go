@@ -1,51 +1,197 @@
 package jwt
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"strings"
+	"sync"
 	"time"
 
 	jwtv5 "github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
+// DefaultKeyID is used when the service is created from a single secret
+const DefaultKeyID = "default"
+
 type Claims struct {
 	UserID uuid.UUID `json:"user_id"`
 	Email  string    `json:"email"`
 	Role   string    `json:"role"`
+	// Scope limits the token to the listed permissions, space separated, such
+	// as "transactions:write cards:read". Tokens issued at login carry none and
+	// are not limited.
+	Scope string `json:"scope,omitempty"`
+	// ImpersonatedBy is the support agent acting as the user, set only on
+	// impersonation tokens
+	ImpersonatedBy *uuid.UUID `json:"impersonated_by,omitempty"`
 	jwtv5.RegisteredClaims
 }
 
+// Limited reports whether the token is restricted to its scopes
+func (c *Claims) Limited() bool {
+	return c.Scope != ""
+}
+
+// HasScope reports whether the token grants scope. Unlimited tokens grant every scope.
+func (c *Claims) HasScope(scope string) bool {
+	return !c.Limited() || hasScope(c.Scope, scope)
+}
+
+// hasScope reports whether a space separated scope list contains scope
+func hasScope(list, scope string) bool {
+	for _, granted := range strings.Fields(list) {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// KeySet describes the signing keys known to the service.
+// New tokens are signed with ActiveKID; every key in Keys is accepted for validation.
+type KeySet struct {
+	ActiveKID string            `json:"active_kid"`
+	Keys      map[string]string `json:"keys"`
+}
+
+// JWTService signs and validates customer access tokens. expiryHours is the
+// default token lifetime and must cover the longest lifetime tokens are
+// issued with, since retired keys are kept for that long.
 type JWTService struct {
-	secretKey         []byte
-	expiryHours       int
-	refreshExpiryDays int
+	mu          sync.RWMutex
+	keys        map[string][]byte
+	retired     map[string]time.Time // kid -> time after which the key is no longer accepted
+	activeKID   string
+	expiryHours int
 }
 
 func NewJWTService(secretKey string, expiryHours int) *JWTService {
 	return &JWTService{
-		secretKey:         []byte(secretKey),
-		expiryHours:       expiryHours,
-		refreshExpiryDays: 30, // Default to 30 days
+		keys:        map[string][]byte{DefaultKeyID: []byte(secretKey)},
+		retired:     map[string]time.Time{},
+		activeKID:   DefaultKeyID,
+		expiryHours: expiryHours,
+	}
+}
+
+// NewJWTServiceWithKeys creates a service that signs with the active key of the
+// key set and accepts tokens signed by any of its keys.
+func NewJWTServiceWithKeys(keySet KeySet, expiryHours int) (*JWTService, error) {
+	s := &JWTService{
+		retired:     map[string]time.Time{},
+		expiryHours: expiryHours,
+	}
+	if err := s.ReloadKeys(keySet); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ReloadKeys swaps the key material at runtime. Keys that disappear from the new
+// set stay valid for one token lifetime so already-issued tokens keep working
+// until they expire.
+func (s *JWTService) ReloadKeys(keySet KeySet) error {
+	if keySet.ActiveKID == "" {
+		return fmt.Errorf("active key id is required")
+	}
+	if _, ok := keySet.Keys[keySet.ActiveKID]; !ok {
+		return fmt.Errorf("active key %q not found in key set", keySet.ActiveKID)
+	}
+
+	keys := make(map[string][]byte, len(keySet.Keys))
+	for kid, secret := range keySet.Keys {
+		if secret == "" {
+			return fmt.Errorf("key %q has an empty secret", kid)
+		}
+		keys[kid] = []byte(secret)
 	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	graceUntil := now.Add(time.Hour * time.Duration(s.expiryHours))
+	for kid, secret := range s.keys {
+		if _, stillPresent := keys[kid]; stillPresent {
+			delete(s.retired, kid)
+			continue
+		}
+		if until, ok := s.retired[kid]; ok && now.After(until) {
+			delete(s.retired, kid)
+			continue
+		}
+		if _, ok := s.retired[kid]; !ok {
+			s.retired[kid] = graceUntil
+		}
+		keys[kid] = secret
+	}
+
+	s.keys = keys
+	s.activeKID = keySet.ActiveKID
+	return nil
+}
+
+// ActiveKeyID returns the kid used to sign new tokens
+func (s *JWTService) ActiveKeyID() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.activeKID
 }
 
 // GenerateToken creates a new JWT token
 func (s *JWTService) GenerateToken(userID uuid.UUID, email, role string) (string, time.Time, error) {
+	return s.GenerateScopedToken(userID, email, role, nil)
+}
+
+// GenerateScopedToken creates a token limited to scopes; with none it is
+// the same as GenerateToken
+func (s *JWTService) GenerateScopedToken(userID uuid.UUID, email, role string, scopes []string) (string, time.Time, error) {
 	expiresAt := time.Now().Add(time.Hour * time.Duration(s.expiryHours))
+	return s.generate(&Claims{UserID: userID, Email: email, Role: role, Scope: strings.Join(scopes, " ")}, expiresAt)
+}
 
+// GenerateTokenUntil creates a token expiring at expiresAt instead of after
+// the default lifetime
+func (s *JWTService) GenerateTokenUntil(userID uuid.UUID, email, role string, expiresAt time.Time) (string, time.Time, error) {
+	return s.generate(&Claims{UserID: userID, Email: email, Role: role}, expiresAt)
+}
+
+// GenerateImpersonationToken creates a token letting a support agent act as
+// the user, limited to scopes. tokenID is set as the jti claim so requests
+// made with the token can be traced back to where it was issued.
+func (s *JWTService) GenerateImpersonationToken(userID uuid.UUID, email, role string, agentID, tokenID uuid.UUID, scopes []string, expiresAt time.Time) (string, time.Time, error) {
+	// Without scopes the token would be unlimited
+	if len(scopes) == 0 {
+		return "", time.Time{}, fmt.Errorf("impersonation tokens must be limited to scopes")
+	}
 	claims := &Claims{
-		UserID: userID,
-		Email:  email,
-		Role:   role,
-		RegisteredClaims: jwtv5.RegisteredClaims{
-			ExpiresAt: jwtv5.NewNumericDate(expiresAt),
-			IssuedAt:  jwtv5.NewNumericDate(time.Now()),
-			NotBefore: jwtv5.NewNumericDate(time.Now()),
-		},
+		UserID:         userID,
+		Email:          email,
+		Role:           role,
+		Scope:          strings.Join(scopes, " "),
+		ImpersonatedBy: &agentID,
 	}
+	claims.ID = tokenID.String()
+	return s.generate(claims, expiresAt)
+}
+
+// generate signs claims with the active key, stamping their lifetime
+func (s *JWTService) generate(claims *Claims, expiresAt time.Time) (string, time.Time, error) {
+	now := time.Now()
+	claims.ExpiresAt = jwtv5.NewNumericDate(expiresAt)
+	claims.IssuedAt = jwtv5.NewNumericDate(now)
+	claims.NotBefore = jwtv5.NewNumericDate(now)
+
+	s.mu.RLock()
+	kid := s.activeKID
+	secret := s.keys[kid]
+	s.mu.RUnlock()
 
 	token := jwtv5.NewWithClaims(jwtv5.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(s.secretKey)
+	token.Header["kid"] = kid
+	tokenString, err := token.SignedString(secret)
 	if err != nil {
 		return "", time.Time{}, fmt.Errorf("failed to sign token: %w", err)
 	}
@@ -53,10 +199,9 @@ func (s *JWTService) GenerateToken(userID uuid.UUID, email, role string) (string
 	return tokenString, expiresAt, nil
 }
 
-// GenerateRefreshToken creates a random refresh token
-// GenerateRefreshToken creates a random refresh token
-func (s *JWTService) GenerateRefreshToken() (string, time.Time, error) {
-	expiresAt := time.Now().Add(time.Hour * 24 * time.Duration(s.refreshExpiryDays))
+// GenerateRefreshToken creates a random refresh token valid for ttl
+func (s *JWTService) GenerateRefreshToken(ttl time.Duration) (string, time.Time, error) {
+	expiresAt := time.Now().Add(ttl)
 	// Generate a secure random string using UUIDs for simplicity (this serves as a high-entropy random string)
 	token := uuid.New().String() + uuid.New().String()
 	return token, expiresAt, nil
@@ -68,17 +213,85 @@ func (s *JWTService) ValidateToken(tokenString string) (*Claims, error) {
 		if _, ok := token.Method.(*jwtv5.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return s.secretKey, nil
+		return s.lookupKey(token.Header["kid"])
 	})
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse token: %w", err)
 	}
 
+	// Service tokens carry no user, see ServiceTokenService
 	claims, ok := token.Claims.(*Claims)
-	if !ok || !token.Valid {
+	if !ok || !token.Valid || claims.UserID == uuid.Nil {
 		return nil, fmt.Errorf("invalid token")
 	}
 
 	return claims, nil
 }
+
+// lookupKey resolves the verification key for a token's kid header.
+// Tokens issued before key IDs were introduced carry no kid and are checked
+// against the active key.
+func (s *JWTService) lookupKey(kidHeader interface{}) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if kidHeader == nil {
+		return s.keys[s.activeKID], nil
+	}
+
+	kid, ok := kidHeader.(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid kid header")
+	}
+
+	secret, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key: %s", kid)
+	}
+
+	if until, retired := s.retired[kid]; retired && time.Now().After(until) {
+		return nil, fmt.Errorf("signing key %s has been retired", kid)
+	}
+
+	return secret, nil
+}
+
+// ParseKeySet parses keys in the "kid1:secret1,kid2:secret2" format used by
+// the JWT_SIGNING_KEYS environment variable.
+func ParseKeySet(raw, activeKID string) (KeySet, error) {
+	keySet := KeySet{ActiveKID: activeKID, Keys: map[string]string{}}
+
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		kid, secret, found := strings.Cut(entry, ":")
+		if !found || kid == "" || secret == "" {
+			return KeySet{}, fmt.Errorf("invalid key entry, expected kid:secret")
+		}
+		keySet.Keys[kid] = secret
+	}
+
+	if len(keySet.Keys) == 0 {
+		return KeySet{}, fmt.Errorf("no signing keys configured")
+	}
+
+	return keySet, nil
+}
+
+// LoadKeySetFile reads a JSON key set (as written by the secrets manager sidecar)
+func LoadKeySetFile(path string) (KeySet, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- path comes from trusted configuration
+	if err != nil {
+		return KeySet{}, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	var keySet KeySet
+	if err := json.Unmarshal(data, &keySet); err != nil {
+		return KeySet{}, fmt.Errorf("failed to parse key file: %w", err)
+	}
+
+	return keySet, nil
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	jwtv5 "github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
@@ -101,3 +102,194 @@ func TestTokenExpiration(t *testing.T) {
 		t.Fatal("ValidateToken should fail for expired token")
 	}
 }
+
+func TestGenerateToken_SetsKeyID(t *testing.T) {
+	jwtService, err := NewJWTServiceWithKeys(KeySet{
+		ActiveKID: "2024-01",
+		Keys:      map[string]string{"2024-01": "secret-one"},
+	}, 24)
+	if err != nil {
+		t.Fatalf("NewJWTServiceWithKeys failed: %v", err)
+	}
+
+	tokenString, _, err := jwtService.GenerateToken(uuid.New(), "test@madabank.com", "customer")
+	if err != nil {
+		t.Fatalf("GenerateToken failed: %v", err)
+	}
+
+	token, _, err := jwtv5.NewParser().ParseUnverified(tokenString, &Claims{})
+	if err != nil {
+		t.Fatalf("ParseUnverified failed: %v", err)
+	}
+
+	if token.Header["kid"] != "2024-01" {
+		t.Errorf("Expected kid 2024-01, got %v", token.Header["kid"])
+	}
+}
+
+func TestReloadKeys_OldKeyStillAccepted(t *testing.T) {
+	jwtService, err := NewJWTServiceWithKeys(KeySet{
+		ActiveKID: "old",
+		Keys:      map[string]string{"old": "secret-old"},
+	}, 24)
+	if err != nil {
+		t.Fatalf("NewJWTServiceWithKeys failed: %v", err)
+	}
+
+	oldToken, _, err := jwtService.GenerateToken(uuid.New(), "test@madabank.com", "customer")
+	if err != nil {
+		t.Fatalf("GenerateToken failed: %v", err)
+	}
+
+	// Rotate: the old key is dropped from configuration entirely
+	err = jwtService.ReloadKeys(KeySet{
+		ActiveKID: "new",
+		Keys:      map[string]string{"new": "secret-new"},
+	})
+	if err != nil {
+		t.Fatalf("ReloadKeys failed: %v", err)
+	}
+
+	if jwtService.ActiveKeyID() != "new" {
+		t.Errorf("Expected active kid new, got %s", jwtService.ActiveKeyID())
+	}
+
+	// Tokens signed with the retired key remain valid until they expire
+	if _, err := jwtService.ValidateToken(oldToken); err != nil {
+		t.Fatalf("Old token should still validate after rotation: %v", err)
+	}
+
+	newToken, _, err := jwtService.GenerateToken(uuid.New(), "test@madabank.com", "customer")
+	if err != nil {
+		t.Fatalf("GenerateToken failed: %v", err)
+	}
+	if _, err := jwtService.ValidateToken(newToken); err != nil {
+		t.Fatalf("New token should validate: %v", err)
+	}
+}
+
+func TestValidateToken_UnknownKeyID(t *testing.T) {
+	issuer, _ := NewJWTServiceWithKeys(KeySet{
+		ActiveKID: "other",
+		Keys:      map[string]string{"other": "secret-other"},
+	}, 24)
+	verifier, _ := NewJWTServiceWithKeys(KeySet{
+		ActiveKID: "mine",
+		Keys:      map[string]string{"mine": "secret-mine"},
+	}, 24)
+
+	token, _, err := issuer.GenerateToken(uuid.New(), "test@madabank.com", "customer")
+	if err != nil {
+		t.Fatalf("GenerateToken failed: %v", err)
+	}
+
+	if _, err := verifier.ValidateToken(token); err == nil {
+		t.Fatal("ValidateToken should fail for unknown kid")
+	}
+}
+
+func TestValidateToken_LegacyTokenWithoutKeyID(t *testing.T) {
+	jwtService := NewJWTService("test-secret-key-for-testing", 24)
+
+	claims := &Claims{
+		UserID: uuid.New(),
+		RegisteredClaims: jwtv5.RegisteredClaims{
+			ExpiresAt: jwtv5.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+	legacy, err := jwtv5.NewWithClaims(jwtv5.SigningMethodHS256, claims).SignedString([]byte("test-secret-key-for-testing"))
+	if err != nil {
+		t.Fatalf("SignedString failed: %v", err)
+	}
+
+	if _, err := jwtService.ValidateToken(legacy); err != nil {
+		t.Fatalf("Legacy token without kid should validate: %v", err)
+	}
+}
+
+func TestParseKeySet(t *testing.T) {
+	keySet, err := ParseKeySet("a:secret-a, b:secret-b", "b")
+	if err != nil {
+		t.Fatalf("ParseKeySet failed: %v", err)
+	}
+	if len(keySet.Keys) != 2 || keySet.Keys["b"] != "secret-b" {
+		t.Errorf("Unexpected key set: %+v", keySet)
+	}
+
+	if _, err := ParseKeySet("missing-secret", "a"); err == nil {
+		t.Fatal("ParseKeySet should fail for malformed entry")
+	}
+
+	if _, err := NewJWTServiceWithKeys(KeySet{ActiveKID: "c", Keys: keySet.Keys}, 24); err == nil {
+		t.Fatal("NewJWTServiceWithKeys should fail when active key is missing")
+	}
+}
+
+func TestGenerateScopedToken(t *testing.T) {
+	jwtService := NewJWTService("test-secret-key-for-testing", 1)
+
+	token, _, err := jwtService.GenerateScopedToken(uuid.New(), "test@madabank.com", "customer", []string{"transactions:write", "cards:read"})
+	if err != nil {
+		t.Fatalf("GenerateScopedToken failed: %v", err)
+	}
+
+	claims, err := jwtService.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("ValidateToken failed: %v", err)
+	}
+	if !claims.Limited() {
+		t.Fatal("Expected a scoped token to be limited")
+	}
+	if !claims.HasScope("transactions:write") || !claims.HasScope("cards:read") {
+		t.Errorf("Expected both scopes, got %q", claims.Scope)
+	}
+	if claims.HasScope("cards:write") {
+		t.Error("Expected cards:write not to be granted")
+	}
+}
+
+func TestGenerateToken_Unlimited(t *testing.T) {
+	jwtService := NewJWTService("test-secret-key-for-testing", 1)
+
+	token, _, _ := jwtService.GenerateToken(uuid.New(), "test@madabank.com", "customer")
+	claims, err := jwtService.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("ValidateToken failed: %v", err)
+	}
+
+	if claims.Limited() || !claims.HasScope("cards:write") {
+		t.Error("Expected a login token to grant every scope")
+	}
+}
+
+func TestGenerateImpersonationToken(t *testing.T) {
+	jwtService := NewJWTService("test-secret-key-for-testing", 1)
+	userID, agentID, tokenID := uuid.New(), uuid.New(), uuid.New()
+	expiresAt := time.Now().Add(15 * time.Minute).Truncate(time.Second)
+
+	token, _, err := jwtService.GenerateImpersonationToken(userID, "test@madabank.com", "customer", agentID, tokenID, []string{"accounts:read"}, expiresAt)
+	if err != nil {
+		t.Fatalf("GenerateImpersonationToken failed: %v", err)
+	}
+
+	claims, err := jwtService.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("ValidateToken failed: %v", err)
+	}
+	if claims.UserID != userID || claims.ImpersonatedBy == nil || *claims.ImpersonatedBy != agentID {
+		t.Errorf("Expected user %s impersonated by %s, got %s by %v", userID, agentID, claims.UserID, claims.ImpersonatedBy)
+	}
+	if claims.ID != tokenID.String() {
+		t.Errorf("Expected jti %s, got %s", tokenID, claims.ID)
+	}
+	if !claims.ExpiresAt.Equal(expiresAt) {
+		t.Errorf("Expected expiry %s, got %s", expiresAt, claims.ExpiresAt)
+	}
+	if claims.HasScope("accounts:write") {
+		t.Error("Expected the token to be read-only")
+	}
+
+	if _, _, err := jwtService.GenerateImpersonationToken(userID, "test@madabank.com", "customer", agentID, tokenID, nil, expiresAt); err == nil {
+		t.Error("Expected an impersonation token without scopes to be refused")
+	}
+}
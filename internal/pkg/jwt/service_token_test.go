@@ -0,0 +1,65 @@
+package jwt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestServiceToken_RoundTrip(t *testing.T) {
+	svc := NewServiceTokenService("service-secret", 15*time.Minute)
+
+	token, expiresAt, err := svc.GenerateServiceToken("statement-renderer", []string{"tokens.introspect", "statements.render"})
+	if err != nil {
+		t.Fatalf("GenerateServiceToken failed: %v", err)
+	}
+	if until := time.Until(expiresAt); until <= 14*time.Minute || until > 15*time.Minute {
+		t.Errorf("Expected a 15 minute lifetime, got %s", until)
+	}
+
+	claims, err := svc.ValidateServiceToken(token)
+	if err != nil {
+		t.Fatalf("ValidateServiceToken failed: %v", err)
+	}
+	if claims.ClientID != "statement-renderer" {
+		t.Errorf("Expected client statement-renderer, got %s", claims.ClientID)
+	}
+	if !claims.HasScope("tokens.introspect") || !claims.HasScope("statements.render") {
+		t.Errorf("Expected both scopes, got %q", claims.Scope)
+	}
+	if claims.HasScope("tokens") {
+		t.Error("A scope prefix must not match")
+	}
+}
+
+func TestServiceToken_NotInterchangeableWithCustomerTokens(t *testing.T) {
+	// Even with the same secret configured for both, neither kind of token
+	// is accepted in place of the other
+	customers := NewJWTService("shared-secret", 1)
+	services := NewServiceTokenService("shared-secret", time.Minute)
+
+	customerToken, _, err := customers.GenerateToken(uuid.New(), "test@madabank.com", "admin")
+	if err != nil {
+		t.Fatalf("GenerateToken failed: %v", err)
+	}
+	if _, err := services.ValidateServiceToken(customerToken); err == nil {
+		t.Error("A customer token must not validate as a service token")
+	}
+
+	serviceToken, _, err := services.GenerateServiceToken("fraud-worker", []string{"tokens.introspect"})
+	if err != nil {
+		t.Fatalf("GenerateServiceToken failed: %v", err)
+	}
+	if _, err := customers.ValidateToken(serviceToken); err == nil {
+		t.Error("A service token must not validate as a customer token")
+	}
+}
+
+func TestServiceToken_WrongSecret(t *testing.T) {
+	token, _, _ := NewServiceTokenService("secret-one", time.Minute).GenerateServiceToken("fraud-worker", nil)
+
+	if _, err := NewServiceTokenService("secret-two", time.Minute).ValidateServiceToken(token); err == nil {
+		t.Error("Expected a token signed with another secret to be rejected")
+	}
+}
@@ -0,0 +1,27 @@
+package keyprovider
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// FromEnv selects where the card-data encryption key comes from.
+// ENCRYPTION_KEY_PROVIDER is one of "env" (default, raw ENCRYPTION_KEY),
+// "kms" or "vault" (wrapped key in ENCRYPTION_KEY_CIPHERTEXT).
+func FromEnv(ctx context.Context) (KeyProvider, error) {
+	switch os.Getenv("ENCRYPTION_KEY_PROVIDER") {
+	case "", "env":
+		return NewEnvKeyProvider(os.Getenv("ENCRYPTION_KEY")), nil
+	case "kms":
+		return NewKMSKeyProvider(ctx, os.Getenv("ENCRYPTION_KEY_CIPHERTEXT"), os.Getenv("KMS_KEY_ID"))
+	case "vault":
+		return NewVaultKeyProvider(
+			os.Getenv("VAULT_TRANSIT_MOUNT"),
+			os.Getenv("VAULT_TRANSIT_KEY"),
+			os.Getenv("ENCRYPTION_KEY_CIPHERTEXT"),
+		)
+	default:
+		return nil, fmt.Errorf("unknown ENCRYPTION_KEY_PROVIDER %q", os.Getenv("ENCRYPTION_KEY_PROVIDER"))
+	}
+}
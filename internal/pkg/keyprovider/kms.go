@@ -0,0 +1,69 @@
+package keyprovider
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+)
+
+// kmsDecryptAPI is the subset of the KMS client used by the provider
+type kmsDecryptAPI interface {
+	Decrypt(ctx context.Context, params *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error)
+}
+
+// KMSKeyProvider unwraps a data key that was encrypted with an AWS KMS key
+// (e.g. generated with `aws kms generate-data-key`).
+type KMSKeyProvider struct {
+	client     kmsDecryptAPI
+	keyID      string
+	ciphertext []byte
+}
+
+// NewKMSKeyProvider loads AWS credentials from the default chain (task role on ECS).
+// wrappedKey is the base64 CiphertextBlob of the data key; keyID is optional.
+func NewKMSKeyProvider(ctx context.Context, wrappedKey, keyID string) (*KMSKeyProvider, error) {
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	return newKMSKeyProvider(kms.NewFromConfig(cfg), wrappedKey, keyID)
+}
+
+func newKMSKeyProvider(client kmsDecryptAPI, wrappedKey, keyID string) (*KMSKeyProvider, error) {
+	if wrappedKey == "" {
+		return nil, fmt.Errorf("wrapped data key is required")
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(wrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("wrapped data key is not valid base64: %w", err)
+	}
+	return &KMSKeyProvider{
+		client:     client,
+		keyID:      keyID,
+		ciphertext: ciphertext,
+	}, nil
+}
+
+func (p *KMSKeyProvider) DataKey(ctx context.Context) ([]byte, error) {
+	input := &kms.DecryptInput{
+		CiphertextBlob: p.ciphertext,
+	}
+	if p.keyID != "" {
+		input.KeyId = aws.String(p.keyID)
+	}
+
+	out, err := p.client.Decrypt(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("kms decrypt failed: %w", err)
+	}
+
+	return validateKey(out.Plaintext)
+}
+
+func (p *KMSKeyProvider) Name() string {
+	return "aws-kms"
+}
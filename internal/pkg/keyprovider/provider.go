@@ -0,0 +1,45 @@
+package keyprovider
+
+import (
+	"context"
+	"fmt"
+)
+
+// DataKeySize is the length of the AES-256 data encryption key
+const DataKeySize = 32
+
+// KeyProvider resolves the plaintext data encryption key (DEK) used for card data.
+// Implementations backed by a KMS only ever hold the wrapped key in configuration.
+type KeyProvider interface {
+	// DataKey returns the unwrapped 32-byte data encryption key
+	DataKey(ctx context.Context) ([]byte, error)
+	// Name identifies the provider in logs
+	Name() string
+}
+
+// EnvKeyProvider returns a raw key taken from the environment (development only)
+type EnvKeyProvider struct {
+	key string
+}
+
+func NewEnvKeyProvider(key string) *EnvKeyProvider {
+	return &EnvKeyProvider{key: key}
+}
+
+func (p *EnvKeyProvider) DataKey(ctx context.Context) ([]byte, error) {
+	if p.key == "" {
+		return nil, fmt.Errorf("ENCRYPTION_KEY environment variable is required")
+	}
+	return validateKey([]byte(p.key))
+}
+
+func (p *EnvKeyProvider) Name() string {
+	return "env"
+}
+
+func validateKey(key []byte) ([]byte, error) {
+	if len(key) != DataKeySize {
+		return nil, fmt.Errorf("data key must be %d bytes, got %d", DataKeySize, len(key))
+	}
+	return key, nil
+}
@@ -0,0 +1,109 @@
+package keyprovider
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	vault "github.com/hashicorp/vault/api"
+	"github.com/stretchr/testify/assert"
+)
+
+const testDataKey = "12345678901234567890123456789012"
+
+type fakeKMS struct {
+	plaintext []byte
+	err       error
+	gotInput  *kms.DecryptInput
+}
+
+func (f *fakeKMS) Decrypt(ctx context.Context, params *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error) {
+	f.gotInput = params
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &kms.DecryptOutput{Plaintext: f.plaintext}, nil
+}
+
+func TestEnvKeyProvider(t *testing.T) {
+	key, err := NewEnvKeyProvider(testDataKey).DataKey(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(testDataKey), key)
+
+	_, err = NewEnvKeyProvider("short").DataKey(context.Background())
+	assert.Error(t, err)
+
+	_, err = NewEnvKeyProvider("").DataKey(context.Background())
+	assert.Error(t, err)
+}
+
+func TestKMSKeyProvider_Unwrap(t *testing.T) {
+	fake := &fakeKMS{plaintext: []byte(testDataKey)}
+	wrapped := base64.StdEncoding.EncodeToString([]byte("wrapped-blob"))
+
+	p, err := newKMSKeyProvider(fake, wrapped, "alias/madabank-card-data")
+	assert.NoError(t, err)
+
+	key, err := p.DataKey(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(testDataKey), key)
+	assert.Equal(t, []byte("wrapped-blob"), fake.gotInput.CiphertextBlob)
+	assert.Equal(t, "alias/madabank-card-data", *fake.gotInput.KeyId)
+}
+
+func TestKMSKeyProvider_Errors(t *testing.T) {
+	_, err := newKMSKeyProvider(&fakeKMS{}, "", "")
+	assert.Error(t, err)
+
+	_, err = newKMSKeyProvider(&fakeKMS{}, "not base64!!", "")
+	assert.Error(t, err)
+
+	wrapped := base64.StdEncoding.EncodeToString([]byte("blob"))
+	p, _ := newKMSKeyProvider(&fakeKMS{err: fmt.Errorf("access denied")}, wrapped, "")
+	_, err = p.DataKey(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "kms decrypt failed")
+
+	p, _ = newKMSKeyProvider(&fakeKMS{plaintext: []byte("too-short")}, wrapped, "")
+	_, err = p.DataKey(context.Background())
+	assert.Error(t, err)
+}
+
+func TestVaultKeyProvider_Unwrap(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/transit/decrypt/card-data", r.URL.Path)
+
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		assert.Equal(t, "vault:v1:abc", body["ciphertext"])
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]string{
+				"plaintext": base64.StdEncoding.EncodeToString([]byte(testDataKey)),
+			},
+		})
+	}))
+	defer server.Close()
+
+	cfg := vault.DefaultConfig()
+	cfg.Address = server.URL
+	client, err := vault.NewClient(cfg)
+	assert.NoError(t, err)
+
+	p, err := newVaultKeyProvider(client, "", "card-data", "vault:v1:abc")
+	assert.NoError(t, err)
+
+	key, err := p.DataKey(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(testDataKey), key)
+}
+
+func TestVaultKeyProvider_RequiresKeyName(t *testing.T) {
+	_, err := newVaultKeyProvider(nil, "transit", "", "vault:v1:abc")
+	assert.Error(t, err)
+}
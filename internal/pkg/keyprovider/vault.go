@@ -0,0 +1,75 @@
+package keyprovider
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	vault "github.com/hashicorp/vault/api"
+)
+
+// VaultKeyProvider unwraps a data key using HashiCorp Vault's transit engine.
+// The wrapped key has the "vault:v1:..." format returned by transit/encrypt.
+type VaultKeyProvider struct {
+	client     *vault.Client
+	mount      string
+	keyName    string
+	ciphertext string
+}
+
+// NewVaultKeyProvider builds a client from the standard VAULT_ADDR/VAULT_TOKEN
+// environment variables.
+func NewVaultKeyProvider(mount, keyName, wrappedKey string) (*VaultKeyProvider, error) {
+	client, err := vault.NewClient(vault.DefaultConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create vault client: %w", err)
+	}
+	return newVaultKeyProvider(client, mount, keyName, wrappedKey)
+}
+
+func newVaultKeyProvider(client *vault.Client, mount, keyName, wrappedKey string) (*VaultKeyProvider, error) {
+	if keyName == "" {
+		return nil, fmt.Errorf("vault transit key name is required")
+	}
+	if wrappedKey == "" {
+		return nil, fmt.Errorf("wrapped data key is required")
+	}
+	if mount == "" {
+		mount = "transit"
+	}
+	return &VaultKeyProvider{
+		client:     client,
+		mount:      mount,
+		keyName:    keyName,
+		ciphertext: wrappedKey,
+	}, nil
+}
+
+func (p *VaultKeyProvider) DataKey(ctx context.Context) ([]byte, error) {
+	path := fmt.Sprintf("%s/decrypt/%s", p.mount, p.keyName)
+	secret, err := p.client.Logical().WriteWithContext(ctx, path, map[string]interface{}{
+		"ciphertext": p.ciphertext,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("vault transit decrypt failed: %w", err)
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, fmt.Errorf("vault transit decrypt returned no data")
+	}
+
+	encoded, ok := secret.Data["plaintext"].(string)
+	if !ok {
+		return nil, fmt.Errorf("vault transit decrypt returned no plaintext")
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode vault plaintext: %w", err)
+	}
+
+	return validateKey(key)
+}
+
+func (p *VaultKeyProvider) Name() string {
+	return "vault-transit"
+}
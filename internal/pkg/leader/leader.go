@@ -0,0 +1,139 @@
+// Package leader elects one replica to run singleton work, such as scheduled
+// postings, using a lease held in Redis. The leader renews its lease well
+// before it expires; if it stops renewing (crash, network partition), another
+// replica takes over once the lease runs out.
+package leader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/darisadam/madabank-server/internal/pkg/distlock"
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+)
+
+const defaultTTL = 30 * time.Second
+
+// Elector campaigns for a named lease
+type Elector struct {
+	locker *distlock.Locker
+	key    string
+	id     string
+	ttl    time.Duration
+	leader atomic.Bool
+
+	mu   sync.Mutex
+	lock *distlock.Lock // held lease, nil when not leader
+}
+
+// New returns an elector for the lease key. id identifies this replica in logs.
+func New(client *redis.Client, key, id string, ttl time.Duration) *Elector {
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+	return &Elector{locker: distlock.New(client), key: key, id: id, ttl: ttl}
+}
+
+// IsLeader reports whether this replica held the lease at its last renewal
+func (e *Elector) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// Token returns the fencing token of the current leadership, or 0 when this
+// replica is not the leader
+func (e *Elector) Token() int64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.lock == nil {
+		return 0
+	}
+	return e.lock.Token
+}
+
+// Run campaigns for the lease every third of its TTL until ctx is cancelled,
+// then releases it if held
+func (e *Elector) Run(ctx context.Context) {
+	defer errtrack.RecoverWorker("leader_elector")
+
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		if _, err := e.Campaign(ctx); err != nil {
+			logger.Error("Leader election failed", zap.String("key", e.key), zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			e.Release(context.Background())
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Campaign renews the lease if held, or tries to acquire it, and reports
+// whether this replica is the leader. Errors count as lost leadership, so a
+// replica cut off from Redis stops acting as leader.
+func (e *Elector) Campaign(ctx context.Context) (bool, error) {
+	was := e.leader.Load()
+	is, err := e.campaign(ctx)
+	e.leader.Store(is)
+
+	if is != was {
+		if is {
+			logger.Info("Acquired leadership", zap.String("key", e.key), zap.String("id", e.id))
+		} else {
+			logger.Warn("Lost leadership", zap.String("key", e.key), zap.String("id", e.id))
+		}
+	}
+	return is, err
+}
+
+func (e *Elector) campaign(ctx context.Context) (bool, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.lock != nil {
+		err := e.lock.Renew(ctx)
+		if err == nil {
+			return true, nil
+		}
+		if !errors.Is(err, distlock.ErrLockLost) {
+			return false, err
+		}
+		e.lock = nil
+	}
+
+	lock, err := e.locker.Acquire(ctx, e.key, e.ttl)
+	if errors.Is(err, distlock.ErrNotAcquired) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	e.lock = lock
+	return true, nil
+}
+
+// Release gives up the lease so another replica can take over immediately
+func (e *Elector) Release(ctx context.Context) {
+	e.leader.Store(false)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.lock == nil {
+		return
+	}
+	if err := e.lock.Release(ctx); err != nil {
+		logger.Error("Failed to release leadership", zap.String("key", e.key), zap.Error(err))
+	}
+	e.lock = nil
+}
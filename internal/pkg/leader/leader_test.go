@@ -0,0 +1,99 @@
+package leader
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+)
+
+func init() {
+	logger.Init("test")
+}
+
+func setupElectorTest(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	t.Cleanup(mr.Close)
+
+	return mr, redis.NewClient(&redis.Options{Addr: mr.Addr()})
+}
+
+func TestElector_OnlyOneLeader(t *testing.T) {
+	_, client := setupElectorTest(t)
+	ctx := context.Background()
+	a := New(client, "leader:scheduler", "a", 30*time.Second)
+	b := New(client, "leader:scheduler", "b", 30*time.Second)
+
+	isA, err := a.Campaign(ctx)
+	assert.NoError(t, err)
+	isB, err := b.Campaign(ctx)
+	assert.NoError(t, err)
+
+	assert.True(t, isA)
+	assert.False(t, isB)
+	assert.True(t, a.IsLeader())
+	assert.False(t, b.IsLeader())
+}
+
+func TestElector_RenewKeepsLease(t *testing.T) {
+	mr, client := setupElectorTest(t)
+	ctx := context.Background()
+	a := New(client, "leader:scheduler", "a", 30*time.Second)
+	b := New(client, "leader:scheduler", "b", 30*time.Second)
+
+	_, _ = a.Campaign(ctx)
+	mr.FastForward(20 * time.Second)
+	isA, _ := a.Campaign(ctx)
+	mr.FastForward(20 * time.Second)
+	isB, _ := b.Campaign(ctx)
+
+	assert.True(t, isA)
+	assert.False(t, isB)
+}
+
+func TestElector_TakeoverAfterExpiry(t *testing.T) {
+	mr, client := setupElectorTest(t)
+	ctx := context.Background()
+	a := New(client, "leader:scheduler", "a", 30*time.Second)
+	b := New(client, "leader:scheduler", "b", 30*time.Second)
+
+	_, _ = a.Campaign(ctx)
+	mr.FastForward(31 * time.Second)
+	isB, _ := b.Campaign(ctx)
+	isA, _ := a.Campaign(ctx)
+
+	assert.True(t, isB)
+	assert.False(t, isA)
+}
+
+func TestElector_Release(t *testing.T) {
+	_, client := setupElectorTest(t)
+	ctx := context.Background()
+	a := New(client, "leader:scheduler", "a", 30*time.Second)
+	b := New(client, "leader:scheduler", "b", 30*time.Second)
+
+	_, _ = a.Campaign(ctx)
+	a.Release(ctx)
+	isB, _ := b.Campaign(ctx)
+
+	assert.False(t, a.IsLeader())
+	assert.True(t, isB)
+}
+
+func TestElector_RedisDownLosesLeadership(t *testing.T) {
+	mr, client := setupElectorTest(t)
+	a := New(client, "leader:scheduler", "a", 30*time.Second)
+
+	_, _ = a.Campaign(context.Background())
+	mr.Close()
+	isA, err := a.Campaign(context.Background())
+
+	assert.Error(t, err)
+	assert.False(t, isA)
+}
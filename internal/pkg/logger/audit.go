@@ -0,0 +1,64 @@
+package logger
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const auditLoggerName = "audit"
+
+var auditLog = zap.NewNop()
+
+// Audit records a security-relevant event such as a login, a transfer or a
+// card reveal. With an audit output configured these go only there, as JSON,
+// never sampled and whatever the application log level, so a log shipper can
+// forward them to a separate stream (a CloudWatch log group, a Kafka topic)
+// with its own retention and access rules.
+func Audit(event string, fields ...zap.Field) {
+	auditLog.Info(event, fields...)
+}
+
+// auditSplit returns how the application core is wrapped: unchanged when
+// there is no audit output, or teed with an audit core that alone receives
+// the audit logger's entries
+func auditSplit(output string) (func(zapcore.Core) zapcore.Core, error) {
+	if output == "" {
+		return func(core zapcore.Core) zapcore.Core { return core }, nil
+	}
+
+	sink, _, err := zap.Open(output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log output: %w", err)
+	}
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.EncodeTime = zapcore.RFC3339NanoTimeEncoder
+	auditCore := zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), sink, zapcore.InfoLevel)
+
+	return func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(
+			&namedCore{Core: core, name: auditLoggerName, match: false},
+			&namedCore{Core: auditCore, name: auditLoggerName, match: true},
+		)
+	}, nil
+}
+
+// namedCore passes on only the entries whose logger name is (match) or is
+// not (!match) name
+type namedCore struct {
+	zapcore.Core
+	name  string
+	match bool
+}
+
+func (c *namedCore) With(fields []zapcore.Field) zapcore.Core {
+	return &namedCore{Core: c.Core.With(fields), name: c.name, match: c.match}
+}
+
+func (c *namedCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if (ent.LoggerName == c.name) != c.match {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
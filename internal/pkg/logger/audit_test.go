@@ -0,0 +1,65 @@
+package logger
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestAudit_SeparateOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	cfg := DefaultConfig("production")
+	cfg.Level = zapcore.ErrorLevel
+	cfg.AuditOutput = path
+	require.NoError(t, Configure(cfg))
+	t.Cleanup(func() { Init("test") })
+
+	Info("application entry")
+	Audit("LOGIN", zap.String("status", "success"), zap.String("email", "jane@example.com"))
+	Sync()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 1, "only audit events reach the audit output")
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	// Written although the application level is error
+	assert.Equal(t, "LOGIN", entry["msg"])
+	assert.Equal(t, "audit", entry["logger"])
+	assert.Equal(t, "success", entry["status"])
+	// Redaction applies to the audit output too
+	assert.Equal(t, "j***@example.com", entry["email"])
+}
+
+func TestAudit_NotSampled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	cfg := DefaultConfig("production")
+	cfg.SamplingInitial, cfg.SamplingThereafter = 1, 1000
+	cfg.AuditOutput = path
+	require.NoError(t, Configure(cfg))
+	t.Cleanup(func() { Init("test") })
+
+	for i := 0; i < 5; i++ {
+		Audit("TRANSFER_COMPLETED")
+	}
+	Sync()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 5, strings.Count(string(data), "TRANSFER_COMPLETED"))
+}
+
+func TestAudit_InvalidOutput(t *testing.T) {
+	cfg := DefaultConfig("production")
+	cfg.AuditOutput = filepath.Join(t.TempDir(), "missing", "audit.log")
+	assert.Error(t, Configure(cfg))
+}
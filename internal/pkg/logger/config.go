@@ -0,0 +1,117 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	EncodingJSON    = "json"
+	EncodingConsole = "console"
+)
+
+var (
+	// level is shared by every logger Configure builds, so it can be changed
+	// while the server runs
+	level     = zap.NewAtomicLevel()
+	baseLevel = zapcore.InfoLevel
+)
+
+// Config controls what is logged and how
+type Config struct {
+	Level zapcore.Level
+	// Encoding is EncodingJSON or EncodingConsole
+	Encoding string
+	// Sampling logs the first SamplingInitial entries with the same level and
+	// message each second, then every SamplingThereafter-th. It is off when
+	// SamplingInitial is 0.
+	SamplingInitial    int
+	SamplingThereafter int
+	// Development adds stack traces to warnings and panics on DPanic
+	Development bool
+	// AuditOutput is where audit events go instead of the application log:
+	// "stdout", "stderr" or a file path. Empty keeps them in the application
+	// log.
+	AuditOutput string
+}
+
+// DefaultConfig logs info and above as sampled JSON in production, and
+// everything as colored console output elsewhere
+func DefaultConfig(env string) Config {
+	if env == "production" {
+		return Config{Level: zapcore.InfoLevel, Encoding: EncodingJSON, SamplingInitial: 100, SamplingThereafter: 100}
+	}
+	return Config{Level: zapcore.DebugLevel, Encoding: EncodingConsole, Development: true}
+}
+
+// ConfigFromEnv overrides the defaults for env with LOG_LEVEL, LOG_ENCODING,
+// LOG_SAMPLING (false disables it), LOG_SAMPLING_INITIAL,
+// LOG_SAMPLING_THEREAFTER and AUDIT_LOG_OUTPUT
+func ConfigFromEnv(env string) (Config, error) {
+	cfg := DefaultConfig(env)
+	cfg.AuditOutput = os.Getenv("AUDIT_LOG_OUTPUT")
+	if v := os.Getenv("LOG_LEVEL"); v != "" {
+		l, err := zapcore.ParseLevel(v)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid LOG_LEVEL %q", v)
+		}
+		cfg.Level = l
+	}
+	if v := os.Getenv("LOG_ENCODING"); v != "" {
+		cfg.Encoding = v
+	}
+	if v := os.Getenv("LOG_SAMPLING"); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid LOG_SAMPLING %q", v)
+		}
+		if !enabled {
+			cfg.SamplingInitial = 0
+		} else if cfg.SamplingInitial == 0 {
+			cfg.SamplingInitial, cfg.SamplingThereafter = 100, 100
+		}
+	}
+	for name, n := range map[string]*int{
+		"LOG_SAMPLING_INITIAL":    &cfg.SamplingInitial,
+		"LOG_SAMPLING_THEREAFTER": &cfg.SamplingThereafter,
+	} {
+		if v := os.Getenv(name); v != "" {
+			i, err := strconv.Atoi(v)
+			if err != nil || i < 0 {
+				return cfg, fmt.Errorf("invalid %s %q", name, v)
+			}
+			*n = i
+		}
+	}
+	return cfg, cfg.Validate()
+}
+
+// Validate checks the encoding and sampling settings
+func (c Config) Validate() error {
+	if c.Encoding != EncodingJSON && c.Encoding != EncodingConsole {
+		return fmt.Errorf("log encoding must be %q or %q", EncodingJSON, EncodingConsole)
+	}
+	if c.SamplingInitial > 0 && c.SamplingThereafter <= 0 {
+		return fmt.Errorf("log sampling needs a positive thereafter value")
+	}
+	return nil
+}
+
+// Level returns the level currently logged
+func Level() zapcore.Level {
+	return level.Level()
+}
+
+// BaseLevel returns the level the logger was configured with
+func BaseLevel() zapcore.Level {
+	return baseLevel
+}
+
+// SetLevel changes the level of every logger at once, without a restart
+func SetLevel(l zapcore.Level) {
+	level.SetLevel(l)
+}
@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestConfigFromEnv_Defaults(t *testing.T) {
+	cfg, err := ConfigFromEnv("production")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultConfig("production"), cfg)
+	assert.Equal(t, zapcore.InfoLevel, cfg.Level)
+	assert.Equal(t, EncodingJSON, cfg.Encoding)
+
+	cfg, err = ConfigFromEnv("development")
+	require.NoError(t, err)
+	assert.Equal(t, zapcore.DebugLevel, cfg.Level)
+	assert.Zero(t, cfg.SamplingInitial)
+}
+
+func TestConfigFromEnv_Overrides(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "warn")
+	t.Setenv("LOG_ENCODING", "console")
+	t.Setenv("LOG_SAMPLING_INITIAL", "10")
+	t.Setenv("LOG_SAMPLING_THEREAFTER", "1000")
+
+	cfg, err := ConfigFromEnv("production")
+	require.NoError(t, err)
+	assert.Equal(t, zapcore.WarnLevel, cfg.Level)
+	assert.Equal(t, EncodingConsole, cfg.Encoding)
+	assert.Equal(t, 10, cfg.SamplingInitial)
+	assert.Equal(t, 1000, cfg.SamplingThereafter)
+}
+
+func TestConfigFromEnv_SamplingToggle(t *testing.T) {
+	t.Setenv("LOG_SAMPLING", "false")
+	cfg, err := ConfigFromEnv("production")
+	require.NoError(t, err)
+	assert.Zero(t, cfg.SamplingInitial)
+
+	t.Setenv("LOG_SAMPLING", "true")
+	cfg, err = ConfigFromEnv("development")
+	require.NoError(t, err)
+	assert.Equal(t, 100, cfg.SamplingInitial)
+}
+
+func TestConfigFromEnv_Invalid(t *testing.T) {
+	for env, value := range map[string]string{
+		"LOG_LEVEL":            "verbose",
+		"LOG_ENCODING":         "xml",
+		"LOG_SAMPLING":         "sometimes",
+		"LOG_SAMPLING_INITIAL": "-1",
+	} {
+		t.Run(env, func(t *testing.T) {
+			t.Setenv(env, value)
+			_, err := ConfigFromEnv("production")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSetLevel(t *testing.T) {
+	require.NoError(t, Configure(DefaultConfig("production")))
+	assert.False(t, Log.Core().Enabled(zapcore.DebugLevel))
+
+	SetLevel(zapcore.DebugLevel)
+	assert.True(t, Log.Core().Enabled(zapcore.DebugLevel))
+	assert.Equal(t, zapcore.DebugLevel, Level())
+	assert.Equal(t, zapcore.InfoLevel, BaseLevel())
+
+	SetLevel(BaseLevel())
+	assert.False(t, Log.Core().Enabled(zapcore.DebugLevel))
+}
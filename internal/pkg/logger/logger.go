@@ -7,23 +7,54 @@ import (
 
 var Log *zap.Logger
 
+// Init sets up the logger with the defaults for env
 func Init(env string) {
-	var config zap.Config
+	if err := Configure(DefaultConfig(env)); err != nil {
+		panic(err)
+	}
+}
 
-	if env == "production" {
-		config = zap.NewProductionConfig()
-	} else {
+// Configure (re)builds the logger from cfg
+func Configure(cfg Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	var config zap.Config
+	if cfg.Development {
 		config = zap.NewDevelopmentConfig()
+	} else {
+		config = zap.NewProductionConfig()
+	}
+	config.Encoding = cfg.Encoding
+	if cfg.Encoding == EncodingConsole && cfg.Development {
 		config.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
 	}
+	config.Sampling = nil
+	if cfg.SamplingInitial > 0 {
+		config.Sampling = &zap.SamplingConfig{Initial: cfg.SamplingInitial, Thereafter: cfg.SamplingThereafter}
+	}
 
+	level.SetLevel(cfg.Level)
+	baseLevel = cfg.Level
+	config.Level = level
 	config.OutputPaths = []string{"stdout"}
 
-	var err error
-	Log, err = config.Build()
+	split, err := auditSplit(cfg.AuditOutput)
 	if err != nil {
-		panic(err)
+		return err
+	}
+
+	// Every core is wrapped so PII never reaches the log sink
+	built, err := config.Build(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return NewRedactingCore(split(core))
+	}))
+	if err != nil {
+		return err
 	}
+	Log = built
+	auditLog = built.Named(auditLoggerName)
+	return nil
 }
 
 func Sync() {
@@ -0,0 +1,110 @@
+package logger
+
+import (
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const redactedValue = "[REDACTED]"
+
+// deniedFields are never written to the log, whatever their value.
+// Keys are compared case-insensitively.
+var deniedFields = map[string]bool{
+	"otp":           true,
+	"otp_code":      true,
+	"token":         true,
+	"access_token":  true,
+	"refresh_token": true,
+	"reset_token":   true,
+	"reveal_token":  true,
+	"authorization": true,
+	"password":      true,
+	"new_password":  true,
+	"secret":        true,
+	"cvv":           true,
+	"card_number":   true,
+	"pin":           true,
+}
+
+// maskedFields are logged in an obfuscated form that keeps them useful for
+// correlating support tickets without exposing the full value.
+var maskedFields = map[string]func(string) string{
+	"email":               MaskEmail,
+	"phone":               MaskPhone,
+	"phone_number":        MaskPhone,
+	"account_number":      MaskAccountNumber,
+	"from_account_number": MaskAccountNumber,
+	"to_account_number":   MaskAccountNumber,
+}
+
+// redactingCore wraps a zapcore.Core and scrubs PII from structured fields
+type redactingCore struct {
+	zapcore.Core
+}
+
+// NewRedactingCore wraps core so that sensitive fields are masked or dropped
+func NewRedactingCore(core zapcore.Core) zapcore.Core {
+	return &redactingCore{Core: core}
+}
+
+func (c *redactingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactingCore{Core: c.Core.With(redactFields(fields))}
+}
+
+func (c *redactingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *redactingCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	// Check again against the wrapped core, so samplers and filters below
+	// this one still decide which of their cores the entry reaches
+	if ce := c.Core.Check(ent, nil); ce != nil {
+		ce.Write(redactFields(fields)...)
+	}
+	return nil
+}
+
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	out := make([]zapcore.Field, 0, len(fields))
+	for _, f := range fields {
+		key := strings.ToLower(f.Key)
+		if deniedFields[key] {
+			out = append(out, zapcore.Field{Key: f.Key, Type: zapcore.StringType, String: redactedValue})
+			continue
+		}
+		if mask, ok := maskedFields[key]; ok && f.Type == zapcore.StringType {
+			f.String = mask(f.String)
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+// MaskEmail keeps the first character of the local part and the domain
+func MaskEmail(email string) string {
+	local, domain, found := strings.Cut(email, "@")
+	if !found || local == "" {
+		return "***"
+	}
+	return local[:1] + "***@" + domain
+}
+
+// MaskPhone keeps the last 4 digits of a phone number
+func MaskPhone(phone string) string {
+	if len(phone) <= 4 {
+		return "***"
+	}
+	return "***" + phone[len(phone)-4:]
+}
+
+// MaskAccountNumber keeps the last 4 digits of an account number
+func MaskAccountNumber(number string) string {
+	if len(number) <= 4 {
+		return "****"
+	}
+	return strings.Repeat("*", len(number)-4) + number[len(number)-4:]
+}
@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func newObservedLogger() (*zap.Logger, *observer.ObservedLogs) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	return zap.New(NewRedactingCore(core)), logs
+}
+
+// TestDeniedFieldsEnforced guards the denylist itself: removing an entry
+// must be a deliberate change to this test.
+func TestDeniedFieldsEnforced(t *testing.T) {
+	required := []string{
+		"otp", "otp_code", "token", "access_token", "refresh_token",
+		"password", "new_password", "secret", "cvv", "card_number", "authorization",
+	}
+
+	log, logs := newObservedLogger()
+	for _, key := range required {
+		assert.True(t, deniedFields[key], "%s must be in the denylist", key)
+		log.Info("test", zap.String(key, "sensitive-value"))
+	}
+
+	for _, entry := range logs.All() {
+		for _, f := range entry.Context {
+			assert.Equal(t, redactedValue, f.String, "field %s leaked", f.Key)
+		}
+	}
+}
+
+func TestRedactingCore_CaseInsensitive(t *testing.T) {
+	log, logs := newObservedLogger()
+	log.Info("test", zap.String("OTP_Code", "123456"))
+
+	assert.Equal(t, redactedValue, logs.All()[0].ContextMap()["OTP_Code"])
+}
+
+func TestRedactingCore_NonStringDeniedField(t *testing.T) {
+	log, logs := newObservedLogger()
+	log.Info("test", zap.Int("otp", 123456))
+
+	assert.Equal(t, redactedValue, logs.All()[0].ContextMap()["otp"])
+}
+
+func TestRedactingCore_MasksPII(t *testing.T) {
+	log, logs := newObservedLogger()
+	log.Info("test",
+		zap.String("email", "john.doe@example.com"),
+		zap.String("phone", "081234567890"),
+		zap.String("account_number", "1234567890"),
+		zap.String("user_id", "abc"),
+	)
+
+	ctx := logs.All()[0].ContextMap()
+	assert.Equal(t, "j***@example.com", ctx["email"])
+	assert.Equal(t, "***7890", ctx["phone"])
+	assert.Equal(t, "******7890", ctx["account_number"])
+	assert.Equal(t, "abc", ctx["user_id"])
+}
+
+func TestRedactingCore_With(t *testing.T) {
+	log, logs := newObservedLogger()
+	log.With(zap.String("refresh_token", "tok"), zap.String("email", "a@b.com")).Info("test")
+
+	ctx := logs.All()[0].ContextMap()
+	assert.Equal(t, redactedValue, ctx["refresh_token"])
+	assert.Equal(t, "a***@b.com", ctx["email"])
+}
+
+func TestMaskHelpers_ShortValues(t *testing.T) {
+	assert.Equal(t, "***", MaskEmail("invalid"))
+	assert.Equal(t, "***", MaskPhone("12"))
+	assert.Equal(t, "****", MaskAccountNumber("12"))
+}
@@ -0,0 +1,147 @@
+// Package loglevel lets admins raise or lower the log level of every API
+// replica at once while the server runs, for example to capture debug logs
+// of a production issue without a redeploy. The override is kept in Redis
+// with an expiry, so a forgotten debug level reverts on its own.
+package loglevel
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+)
+
+const overrideKey = "logger:level"
+
+const (
+	// DefaultTTL is how long an override lasts when no duration is given
+	DefaultTTL = time.Hour
+	// MaxTTL is the longest an override may last
+	MaxTTL = 24 * time.Hour
+	// DefaultRefreshInterval is how often replicas pick up overrides made on
+	// another replica
+	DefaultRefreshInterval = 10 * time.Second
+)
+
+// SetRequest changes the log level for a while
+type SetRequest struct {
+	Level string `json:"level" binding:"required,oneof=debug info warn error"`
+	// Duration is a Go duration such as "30m", DefaultTTL when empty
+	Duration string `json:"duration"`
+}
+
+// State is the log level in effect
+type State struct {
+	Level string `json:"level"`
+	// Default is the level the server was started with
+	Default string `json:"default"`
+	// ExpiresAt is when an override returns the level to Default
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// Controller applies the shared override to this replica's logger
+type Controller struct {
+	redis *redis.Client
+	now   func() time.Time
+}
+
+func NewController(redisClient *redis.Client) *Controller {
+	return &Controller{redis: redisClient, now: time.Now}
+}
+
+// Set overrides the level on every replica. This one applies it at once;
+// the others pick it up at their next refresh.
+func (c *Controller) Set(ctx context.Context, req *SetRequest) (*State, error) {
+	level, err := zapcore.ParseLevel(req.Level)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log level %q", req.Level)
+	}
+	ttl := DefaultTTL
+	if req.Duration != "" {
+		ttl, err = time.ParseDuration(req.Duration)
+		if err != nil || ttl <= 0 {
+			return nil, fmt.Errorf("invalid duration %q", req.Duration)
+		}
+		if ttl > MaxTTL {
+			return nil, fmt.Errorf("duration must not exceed %.0f hours", MaxTTL.Hours())
+		}
+	}
+
+	if err := c.redis.Set(ctx, overrideKey, level.String(), ttl).Err(); err != nil {
+		return nil, fmt.Errorf("failed to save log level: %w", err)
+	}
+	expiresAt := c.now().Add(ttl)
+	apply(level)
+	return c.state(level, &expiresAt), nil
+}
+
+// Reset drops the override, returning every replica to its startup level
+func (c *Controller) Reset(ctx context.Context) (*State, error) {
+	if err := c.redis.Del(ctx, overrideKey).Err(); err != nil {
+		return nil, fmt.Errorf("failed to reset log level: %w", err)
+	}
+	apply(logger.BaseLevel())
+	return c.state(logger.BaseLevel(), nil), nil
+}
+
+// Refresh loads the shared override, if any, and returns the level now in
+// effect
+func (c *Controller) Refresh(ctx context.Context) (*State, error) {
+	pipe := c.redis.Pipeline()
+	get := pipe.Get(ctx, overrideKey)
+	ttl := pipe.PTTL(ctx, overrideKey)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return c.state(logger.Level(), nil), fmt.Errorf("failed to load log level: %w", err)
+	}
+
+	if get.Err() == redis.Nil {
+		apply(logger.BaseLevel())
+		return c.state(logger.BaseLevel(), nil), nil
+	}
+	level, err := zapcore.ParseLevel(get.Val())
+	if err != nil {
+		return c.state(logger.Level(), nil), fmt.Errorf("stored log level %q is invalid", get.Val())
+	}
+	apply(level)
+
+	var expiresAt *time.Time
+	if d := ttl.Val(); d > 0 {
+		t := c.now().Add(d)
+		expiresAt = &t
+	}
+	return c.state(level, expiresAt), nil
+}
+
+// Watch refreshes the level every interval until ctx is done
+func (c *Controller) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := c.Refresh(ctx); err != nil {
+				logger.Error("Failed to refresh log level", zap.Error(err))
+			}
+		}
+	}
+}
+
+func (c *Controller) state(level zapcore.Level, expiresAt *time.Time) *State {
+	return &State{Level: level.String(), Default: logger.BaseLevel().String(), ExpiresAt: expiresAt}
+}
+
+func apply(level zapcore.Level) {
+	if previous := logger.Level(); previous != level {
+		logger.SetLevel(level)
+		// Logged at warn so the change shows whatever the new level
+		logger.Warn("Log level changed", zap.String("from", previous.String()), zap.String("to", level.String()))
+	}
+}
@@ -0,0 +1,80 @@
+package loglevel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+)
+
+func setupController(t *testing.T) (*Controller, *miniredis.Miniredis) {
+	require.NoError(t, logger.Configure(logger.DefaultConfig("production")))
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	return NewController(client), mr
+}
+
+func TestController_SetAndReset(t *testing.T) {
+	c, mr := setupController(t)
+	now := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	state, err := c.Set(context.Background(), &SetRequest{Level: "debug", Duration: "30m"})
+	require.NoError(t, err)
+	assert.Equal(t, "debug", state.Level)
+	assert.Equal(t, "info", state.Default)
+	assert.Equal(t, now.Add(30*time.Minute), *state.ExpiresAt)
+	assert.Equal(t, zapcore.DebugLevel, logger.Level())
+	assert.Equal(t, 30*time.Minute, mr.TTL(overrideKey))
+
+	state, err = c.Reset(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "info", state.Level)
+	assert.Nil(t, state.ExpiresAt)
+	assert.Equal(t, zapcore.InfoLevel, logger.Level())
+	assert.False(t, mr.Exists(overrideKey))
+}
+
+func TestController_SetDefaultsAndLimits(t *testing.T) {
+	c, mr := setupController(t)
+
+	_, err := c.Set(context.Background(), &SetRequest{Level: "warn"})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultTTL, mr.TTL(overrideKey))
+
+	_, err = c.Set(context.Background(), &SetRequest{Level: "debug", Duration: "48h"})
+	assert.Error(t, err)
+	_, err = c.Set(context.Background(), &SetRequest{Level: "debug", Duration: "soon"})
+	assert.Error(t, err)
+	_, err = c.Set(context.Background(), &SetRequest{Level: "loud"})
+	assert.Error(t, err)
+	assert.Equal(t, zapcore.WarnLevel, logger.Level())
+}
+
+func TestController_RefreshFollowsOtherReplicas(t *testing.T) {
+	c, mr := setupController(t)
+
+	// Another replica set the override
+	require.NoError(t, mr.Set(overrideKey, "debug"))
+	mr.SetTTL(overrideKey, 10*time.Minute)
+
+	state, err := c.Refresh(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "debug", state.Level)
+	assert.NotNil(t, state.ExpiresAt)
+	assert.Equal(t, zapcore.DebugLevel, logger.Level())
+
+	// The override expires
+	mr.FastForward(10 * time.Minute)
+	state, err = c.Refresh(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "info", state.Level)
+	assert.Equal(t, zapcore.InfoLevel, logger.Level())
+}
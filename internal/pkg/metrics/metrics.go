@@ -2,8 +2,10 @@ package metrics
 
 import (
 	"strconv"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
@@ -61,6 +63,20 @@ var (
 		[]string{"type", "error_type"},
 	)
 
+	TransactionsPending = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "madabank_transactions_pending",
+			Help: "Number of transactions still pending",
+		},
+	)
+
+	TransactionsPendingOldestSeconds = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "madabank_transactions_pending_oldest_seconds",
+			Help: "Age of the oldest pending transaction, 0 when none are pending",
+		},
+	)
+
 	// Account Metrics
 	AccountsTotal = promauto.NewGaugeVec(
 		prometheus.GaugeOpts{
@@ -70,18 +86,12 @@ var (
 		[]string{"type"},
 	)
 
-	AccountBalance = promauto.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Name: "madabank_account_balance",
-			Help: "Current account balances",
-		},
-		[]string{"account_id", "type", "currency"},
-	)
-
+	// Balances are only exported in aggregate; a series per account would grow
+	// with the customer base. Individual balances are served by the API.
 	TotalBalanceByType = promauto.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "madabank_total_balance_by_type",
-			Help: "Total balance by account type",
+			Help: "Total balance of active accounts by account type and currency",
 		},
 		[]string{"type", "currency"},
 	)
@@ -142,20 +152,187 @@ var (
 		[]string{"operation", "table"},
 	)
 
+	DBTransactionRetriesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "madabank_db_transaction_retries_total",
+			Help: "Total number of money movements retried after a serialization failure or deadlock",
+		},
+		[]string{"operation", "reason"},
+	)
+
+	// Audit Archive Metrics
+	AuditLogsArchivedTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "madabank_audit_logs_archived_total",
+			Help: "Total number of audit log rows exported to object storage and pruned",
+		},
+	)
+
+	AuditArchiveBytesTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "madabank_audit_archive_bytes_total",
+			Help: "Total compressed bytes written to the audit log archive",
+		},
+	)
+
+	AuditArchiveRunsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "madabank_audit_archive_runs_total",
+			Help: "Total number of audit archive job runs",
+		},
+		[]string{"status"},
+	)
+
+	// Card Metrics
+	CardAuthorizationsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "madabank_card_authorizations_total",
+			Help: "Total number of card authorization requests by channel and response code",
+		},
+		[]string{"channel", "response_code"},
+	)
+
+	// Job Queue Metrics
+	QueueJobsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "madabank_queue_jobs_total",
+			Help: "Total number of queued job attempts by kind and outcome",
+		},
+		[]string{"kind", "outcome"},
+	)
+
+	QueueJobDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "madabank_queue_job_duration_seconds",
+			Help:    "Queued job execution duration in seconds",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"kind"},
+	)
+
+	QueueJobsByStatus = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "madabank_queue_jobs",
+			Help: "Number of queued jobs by status",
+		},
+		[]string{"status"},
+	)
+
+	// Scheduler Metrics
+	ScheduledTaskRunsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "madabank_scheduled_task_runs_total",
+			Help: "Total number of scheduled task runs by task and status",
+		},
+		[]string{"task", "status"},
+	)
+
+	ScheduledTaskLastSuccess = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "madabank_scheduled_task_last_success_timestamp_seconds",
+			Help: "Unix time of the last successful run of a scheduled task",
+		},
+		[]string{"task"},
+	)
+
+	SchedulerLeader = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "madabank_scheduler_leader",
+			Help: "1 when this replica is the elected scheduler leader",
+		},
+	)
+
+	// Saga Metrics
+	SagasTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "madabank_sagas_total",
+			Help: "Total number of sagas that reached a final status by kind and status",
+		},
+		[]string{"kind", "status"},
+	)
+
+	SagaStepRetriesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "madabank_saga_step_retries_total",
+			Help: "Total number of failed saga step attempts scheduled for retry",
+		},
+		[]string{"kind", "step"},
+	)
+
+	// GeoIP Metrics
+	GeoRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "madabank_geo_requests_total",
+			Help: "Total number of requests by client country and the access policy action taken",
+		},
+		[]string{"country", "action"},
+	)
+
+	BotDetectionsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "madabank_bot_detections_total",
+			Help: "Total number of requests scored by bot detection, by the action taken",
+		},
+		[]string{"action"},
+	)
+
+	// Cache Metrics
+	CacheLookupsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "madabank_cache_lookups_total",
+			Help: "Total number of cache lookups, by table and whether the row was cached",
+		},
+		[]string{"table", "result"},
+	)
+
+	// FX Metrics
+	FXRateFetchesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "madabank_fx_rate_fetches_total",
+			Help: "Total number of FX rate fetches from the rate provider, by provider and result",
+		},
+		[]string{"provider", "result"},
+	)
+
 	// System Metrics
-	SystemInfo = promauto.NewGaugeVec(
+	// BuildInfo is always 1; its labels let dashboards line changes up with
+	// deploys, e.g. by joining on commit_sha
+	BuildInfo = promauto.NewGaugeVec(
 		prometheus.GaugeOpts{
-			Name: "madabank_system_info",
-			Help: "System information",
+			Name: "madabank_build_info",
+			Help: "Build information of the running binary",
 		},
-		[]string{"version", "commit_sha", "go_version"},
+		[]string{"version", "commit_sha", "build_time", "go_version"},
 	)
 )
 
-// RecordHTTPRequest records HTTP request metrics
-func RecordHTTPRequest(method, endpoint string, status int, duration float64) {
+func init() {
+	// The default registry comes with the process collector and a Go collector
+	// limited to the classic go_memstats metrics. Replace the latter with one
+	// that also exports the runtime's GC pause, memory class and scheduler
+	// latency histograms.
+	prometheus.Unregister(collectors.NewGoCollector())
+	prometheus.MustRegister(collectors.NewGoCollector(
+		collectors.WithGoCollectorRuntimeMetrics(collectors.MetricsGC, collectors.MetricsMemory, collectors.MetricsScheduler),
+	))
+}
+
+// RecordHTTPRequest records HTTP request metrics. traceID, when set, is
+// attached to the duration as an exemplar.
+func RecordHTTPRequest(method, endpoint string, status int, duration float64, traceID string) {
 	HTTPRequestsTotal.WithLabelValues(method, endpoint, strconv.Itoa(status)).Inc()
-	HTTPRequestDuration.WithLabelValues(method, endpoint).Observe(duration)
+	observeWithTraceID(HTTPRequestDuration.WithLabelValues(method, endpoint), duration, traceID)
+}
+
+// observeWithTraceID links the observation to its trace, so a dashboard can
+// jump from a slow bucket to an example request. Exemplars are only exposed
+// in the OpenMetrics format.
+func observeWithTraceID(o prometheus.Observer, value float64, traceID string) {
+	if eo, ok := o.(prometheus.ExemplarObserver); ok && traceID != "" {
+		eo.ObserveWithExemplar(value, prometheus.Labels{"trace_id": traceID})
+		return
+	}
+	o.Observe(value)
 }
 
 // RecordTransaction records transaction metrics
@@ -189,6 +366,12 @@ func UpdateAccountMetrics(accountType string, count int) {
 	AccountsTotal.WithLabelValues(accountType).Set(float64(count))
 }
 
+// UpdatePendingTransactions updates the transaction backlog metrics
+func UpdatePendingTransactions(count int, oldestAge time.Duration) {
+	TransactionsPending.Set(float64(count))
+	TransactionsPendingOldestSeconds.Set(oldestAge.Seconds())
+}
+
 // UpdateUserMetrics updates user-related metrics
 func UpdateUserMetrics(total, active int) {
 	UsersTotal.Set(float64(total))
@@ -201,7 +384,100 @@ func RecordDBQuery(operation, table string, duration float64) {
 	DBQueryDuration.WithLabelValues(operation, table).Observe(duration)
 }
 
-// SetSystemInfo sets system information metrics
-func SetSystemInfo(version, commitSHA, goVersion string) {
-	SystemInfo.WithLabelValues(version, commitSHA, goVersion).Set(1)
+// SetBuildInfo publishes the version the binary was built from
+func SetBuildInfo(version, commitSHA, buildTime, goVersion string) {
+	BuildInfo.WithLabelValues(version, commitSHA, buildTime, goVersion).Set(1)
+}
+
+// RecordAuditArchiveBatch records one archived batch of audit logs
+func RecordAuditArchiveBatch(rows int, bytes int) {
+	AuditLogsArchivedTotal.Add(float64(rows))
+	AuditArchiveBytesTotal.Add(float64(bytes))
+}
+
+// RecordAuditArchiveRun records the outcome of an audit archive job run
+func RecordAuditArchiveRun(success bool) {
+	status := "failed"
+	if success {
+		status = "success"
+	}
+	AuditArchiveRunsTotal.WithLabelValues(status).Inc()
+}
+
+// RecordCardAuthorization records the outcome of a card authorization request
+func RecordCardAuthorization(channel, responseCode string) {
+	CardAuthorizationsTotal.WithLabelValues(channel, responseCode).Inc()
+}
+
+// RecordQueueJob records one attempt of a queued job. Outcome is completed,
+// retried or dead.
+func RecordQueueJob(kind, outcome string, duration float64) {
+	QueueJobsTotal.WithLabelValues(kind, outcome).Inc()
+	QueueJobDuration.WithLabelValues(kind).Observe(duration)
+}
+
+// UpdateQueueDepth sets the number of queued jobs in a status
+func UpdateQueueDepth(status string, count int) {
+	QueueJobsByStatus.WithLabelValues(status).Set(float64(count))
+}
+
+// RecordScheduledTaskRun records the outcome of a scheduled task run
+func RecordScheduledTaskRun(task string, success bool, finishedAt time.Time) {
+	status := "failed"
+	if success {
+		status = "success"
+		ScheduledTaskLastSuccess.WithLabelValues(task).Set(float64(finishedAt.Unix()))
+	}
+	ScheduledTaskRunsTotal.WithLabelValues(task, status).Inc()
+}
+
+// SetSchedulerLeader records whether this replica is the scheduler leader
+func SetSchedulerLeader(leader bool) {
+	if leader {
+		SchedulerLeader.Set(1)
+		return
+	}
+	SchedulerLeader.Set(0)
+}
+
+// RecordSagaFinished records a saga reaching a final status: completed,
+// compensated or failed
+func RecordSagaFinished(kind, status string) {
+	SagasTotal.WithLabelValues(kind, status).Inc()
+}
+
+// RecordSagaStepRetry records a failed saga step attempt that will be retried
+func RecordSagaStepRetry(kind, step string) {
+	SagaStepRetriesTotal.WithLabelValues(kind, step).Inc()
+}
+
+// RecordGeoRequest records a request from country ("" when unknown) and the
+// policy action: allow, challenge or block
+func RecordGeoRequest(country, action string) {
+	if country == "" {
+		country = "unknown"
+	}
+	GeoRequestsTotal.WithLabelValues(country, action).Inc()
+}
+
+func RecordBotDetection(action string) {
+	BotDetectionsTotal.WithLabelValues(action).Inc()
+}
+
+// RecordCacheLookup records a cache lookup for a row of table
+func RecordCacheLookup(table string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	CacheLookupsTotal.WithLabelValues(table, result).Inc()
+}
+
+// RecordFXRateFetch records a fetch of the latest rates from provider
+func RecordFXRateFetch(provider string, ok bool) {
+	result := "error"
+	if ok {
+		result = "success"
+	}
+	FXRateFetchesTotal.WithLabelValues(provider, result).Inc()
 }
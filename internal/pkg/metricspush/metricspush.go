@@ -0,0 +1,131 @@
+// Package metricspush publishes the metrics of batch work that can finish
+// between two Prometheus scrapes, such as scheduled task runs and admin
+// commands, to a Pushgateway and/or a remote-write endpoint. It is configured
+// from METRICS_PUSH* / METRICS_REMOTE_WRITE* environment variables and
+// disabled unless a URL is set.
+package metricspush
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+const pushTimeout = 10 * time.Second
+
+// Publisher sends the metrics gathered from g, labelled with the job and the
+// instance that ran it
+type Publisher interface {
+	Publish(ctx context.Context, job string, g prometheus.Gatherer) error
+	// Name identifies the publisher in logs
+	Name() string
+}
+
+// FromEnv builds the publishers configured by METRICS_PUSHGATEWAY_URL and
+// METRICS_REMOTE_WRITE_URL, or returns nil when neither is set. Both use the
+// basic auth credentials METRICS_PUSH_USERNAME and METRICS_PUSH_PASSWORD when
+// given. The instance label is METRICS_PUSH_INSTANCE, the hostname by default.
+func FromEnv() (Publisher, error) {
+	instance := os.Getenv("METRICS_PUSH_INSTANCE")
+	if instance == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("METRICS_PUSH_INSTANCE is required: %w", err)
+		}
+		instance = hostname
+	}
+	auth := basicAuth{username: os.Getenv("METRICS_PUSH_USERNAME"), password: os.Getenv("METRICS_PUSH_PASSWORD")}
+
+	var publishers multiPublisher
+	if url := os.Getenv("METRICS_PUSHGATEWAY_URL"); url != "" {
+		publishers = append(publishers, NewPushgateway(url, instance, auth.username, auth.password))
+	}
+	if url := os.Getenv("METRICS_REMOTE_WRITE_URL"); url != "" {
+		publishers = append(publishers, NewRemoteWrite(url, instance, auth.username, auth.password))
+	}
+
+	switch len(publishers) {
+	case 0:
+		return nil, nil
+	case 1:
+		return publishers[0], nil
+	default:
+		return publishers, nil
+	}
+}
+
+type basicAuth struct {
+	username string
+	password string
+}
+
+func (a basicAuth) set(req *http.Request) {
+	if a.username != "" {
+		req.SetBasicAuth(a.username, a.password)
+	}
+}
+
+// Pushgateway pushes to a Prometheus Pushgateway, grouped by job and
+// instance. Pushes add to the group rather than replace it, so a metric left
+// out of a push, like the last success of a failed run, keeps its value.
+type Pushgateway struct {
+	client   *http.Client
+	url      string
+	instance string
+	auth     basicAuth
+}
+
+func NewPushgateway(url, instance, username, password string) *Pushgateway {
+	return &Pushgateway{
+		client:   &http.Client{Timeout: pushTimeout},
+		url:      strings.TrimRight(url, "/"),
+		instance: instance,
+		auth:     basicAuth{username: username, password: password},
+	}
+}
+
+func (p *Pushgateway) Publish(ctx context.Context, job string, g prometheus.Gatherer) error {
+	pusher := push.New(p.url, job).
+		Gatherer(g).
+		Grouping("instance", p.instance).
+		Client(p.client)
+	if p.auth.username != "" {
+		pusher = pusher.BasicAuth(p.auth.username, p.auth.password)
+	}
+	if err := pusher.AddContext(ctx); err != nil {
+		return fmt.Errorf("pushgateway push failed: %w", err)
+	}
+	return nil
+}
+
+func (p *Pushgateway) Name() string {
+	return "pushgateway"
+}
+
+// multiPublisher publishes to every publisher, even when one fails
+type multiPublisher []Publisher
+
+func (m multiPublisher) Publish(ctx context.Context, job string, g prometheus.Gatherer) error {
+	var errs []error
+	for _, p := range m {
+		if err := p.Publish(ctx, job, g); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m multiPublisher) Name() string {
+	names := make([]string, len(m))
+	for i, p := range m {
+		names[i] = p.Name()
+	}
+	return strings.Join(names, "+")
+}
@@ -0,0 +1,85 @@
+package metricspush
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("METRICS_PUSHGATEWAY_URL", "")
+	t.Setenv("METRICS_REMOTE_WRITE_URL", "")
+	publisher, err := FromEnv()
+	require.NoError(t, err)
+	assert.Nil(t, publisher)
+
+	t.Setenv("METRICS_PUSH_INSTANCE", "api-0")
+	t.Setenv("METRICS_PUSHGATEWAY_URL", "http://pushgateway:9091")
+	publisher, err = FromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, "pushgateway", publisher.Name())
+
+	t.Setenv("METRICS_REMOTE_WRITE_URL", "http://prometheus:9090/api/v1/write")
+	publisher, err = FromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, "pushgateway+remote_write", publisher.Name())
+}
+
+func TestPushgateway_Publish(t *testing.T) {
+	var method, path, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	started := time.Date(2026, 5, 1, 0, 5, 0, 0, time.UTC)
+	registry := RunRegistry(started, started.Add(90*time.Second), nil)
+
+	err := NewPushgateway(server.URL, "api-0", "", "").Publish(context.Background(), "interest_accrual", registry)
+	require.NoError(t, err)
+	// POST adds to the group instead of replacing it
+	assert.Equal(t, http.MethodPost, method)
+	assert.Equal(t, "/metrics/job/interest_accrual/instance/api-0", path)
+	assert.NotEmpty(t, body)
+}
+
+func TestPushgateway_PublishError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	err := NewPushgateway(server.URL, "api-0", "", "").Publish(context.Background(), "interest_accrual", RunRegistry(time.Now(), time.Now(), nil))
+	assert.Error(t, err)
+}
+
+func TestRunRegistry(t *testing.T) {
+	started := time.Date(2026, 5, 1, 0, 5, 0, 0, time.UTC)
+	finished := started.Add(2 * time.Second)
+
+	families, err := RunRegistry(started, finished, nil).Gather()
+	require.NoError(t, err)
+	values := map[string]float64{}
+	for _, f := range families {
+		values[f.GetName()] = f.GetMetric()[0].GetGauge().GetValue()
+	}
+	assert.Equal(t, 1.0, values["madabank_batch_job_success"])
+	assert.Equal(t, 2.0, values["madabank_batch_job_duration_seconds"])
+	assert.Equal(t, float64(finished.Unix()), values["madabank_batch_job_last_success_timestamp_seconds"])
+
+	families, err = RunRegistry(started, finished, assert.AnError).Gather()
+	require.NoError(t, err)
+	for _, f := range families {
+		assert.False(t, strings.Contains(f.GetName(), "last_success"), "a failed run keeps the previous success")
+	}
+}
@@ -0,0 +1,119 @@
+package metricspush
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// decodeWriteRequest reads back the series encodeWriteRequest wrote
+func decodeWriteRequest(t *testing.T, b []byte) []timeSeries {
+	var series []timeSeries
+	for len(b) > 0 {
+		_, _, n := protowire.ConsumeTag(b)
+		msg, m := protowire.ConsumeBytes(b[n:])
+		require.True(t, m > 0)
+		b = b[n+m:]
+
+		var ts timeSeries
+		for len(msg) > 0 {
+			num, _, n := protowire.ConsumeTag(msg)
+			field, m := protowire.ConsumeBytes(msg[n:])
+			msg = msg[n+m:]
+			if num == 1 {
+				_, _, n := protowire.ConsumeTag(field)
+				name, m := protowire.ConsumeString(field[n:])
+				_, _, n2 := protowire.ConsumeTag(field[n+m:])
+				value, _ := protowire.ConsumeString(field[n+m+n2:])
+				ts.labels = append(ts.labels, label{name: name, value: value})
+				continue
+			}
+			_, _, k := protowire.ConsumeTag(field)
+			bits, l := protowire.ConsumeFixed64(field[k:])
+			_, _, k2 := protowire.ConsumeTag(field[k+l:])
+			timestamp, _ := protowire.ConsumeVarint(field[k+l+k2:])
+			ts.value, ts.timestamp = math.Float64frombits(bits), int64(timestamp)
+		}
+		series = append(series, ts)
+	}
+	return series
+}
+
+func TestRemoteWrite_Publish(t *testing.T) {
+	var received []timeSeries
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "snappy", r.Header.Get("Content-Encoding"))
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		user, pass, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "push", user)
+		assert.Equal(t, "s3cret", pass)
+
+		compressed, _ := io.ReadAll(r.Body)
+		body, err := snappy.Decode(nil, compressed)
+		require.NoError(t, err)
+		received = decodeWriteRequest(t, body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	registry := prometheus.NewRegistry()
+	processed := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "statements_closed_total", Help: "test"}, []string{"status"})
+	processed.WithLabelValues("ok").Add(3)
+	registry.MustRegister(processed)
+
+	now := time.Date(2026, 5, 1, 0, 5, 0, 0, time.UTC)
+	writer := NewRemoteWrite(server.URL, "api-0", "push", "s3cret")
+	writer.now = func() time.Time { return now }
+	require.NoError(t, writer.Publish(context.Background(), "statement_cycle", registry))
+
+	require.Len(t, received, 1)
+	assert.Equal(t, []label{
+		{name: "__name__", value: "statements_closed_total"},
+		{name: "instance", value: "api-0"},
+		{name: "job", value: "statement_cycle"},
+		{name: "status", value: "ok"},
+	}, received[0].labels)
+	assert.Equal(t, 3.0, received[0].value)
+	assert.Equal(t, now.UnixMilli(), received[0].timestamp)
+}
+
+func TestToTimeSeries_Histogram(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	duration := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "run_seconds", Help: "test", Buckets: []float64{1, 5}})
+	duration.Observe(0.5)
+	duration.Observe(3)
+	registry.MustRegister(duration)
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	series := toTimeSeries(families, map[string]string{"job": "j"}, 0)
+
+	values := map[string]float64{}
+	for _, ts := range series {
+		key := ts.labels[0].value
+		for _, l := range ts.labels {
+			if l.name == "le" {
+				key += "{le=" + l.value + "}"
+			}
+		}
+		values[key] = ts.value
+	}
+	assert.Equal(t, map[string]float64{
+		"run_seconds_bucket{le=1}":    1,
+		"run_seconds_bucket{le=5}":    2,
+		"run_seconds_bucket{le=+Inf}": 2,
+		"run_seconds_sum":             3.5,
+		"run_seconds_count":           2,
+	}, values)
+}
@@ -0,0 +1,29 @@
+package metricspush
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RunRegistry holds the metrics of one batch run, to publish under the job's
+// name. The last success is left out of failed runs, so the Pushgateway
+// keeps the previous one.
+func RunRegistry(started, finished time.Time, err error) *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	gauge := func(name, help string, value float64) {
+		g := prometheus.NewGauge(prometheus.GaugeOpts{Name: name, Help: help})
+		g.Set(value)
+		registry.MustRegister(g)
+	}
+
+	success := 0.0
+	if err == nil {
+		success = 1
+		gauge("madabank_batch_job_last_success_timestamp_seconds", "Unix time the batch job last succeeded", float64(finished.Unix()))
+	}
+	gauge("madabank_batch_job_success", "Whether the last run of the batch job succeeded", success)
+	gauge("madabank_batch_job_duration_seconds", "Duration of the last run of the batch job", finished.Sub(started).Seconds())
+	gauge("madabank_batch_job_last_run_timestamp_seconds", "Unix time the batch job last finished", float64(finished.Unix()))
+	return registry
+}
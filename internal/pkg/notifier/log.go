@@ -0,0 +1,32 @@
+package notifier
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+)
+
+// LogNotifier logs emails instead of sending them, so flows that email
+// customers can be exercised without a mail relay (development only).
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (n *LogNotifier) SendEmail(ctx context.Context, email *Email) error {
+	if err := email.validate(); err != nil {
+		return err
+	}
+	logger.Info("Email not sent, log notifier configured",
+		zap.String("subject", email.Subject),
+		zap.Int("attachments", len(email.Attachments)),
+	)
+	return nil
+}
+
+func (n *LogNotifier) Name() string {
+	return "log"
+}
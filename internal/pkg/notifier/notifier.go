@@ -0,0 +1,72 @@
+// Package notifier sends email and text messages to customers, such as
+// monthly account statements and one-time codes. The email transport is
+// picked by EMAIL_NOTIFIER: an SMTP relay in production, or a notifier that
+// only logs for development. SMS_NOTIFIER does the same for text messages.
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Attachment is a file sent along with an email
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Email is a plain text message to one recipient
+type Email struct {
+	To          string
+	Subject     string
+	Body        string
+	Attachments []Attachment
+}
+
+// Notifier delivers emails. A nil error means the transport accepted the
+// message, not that it reached the inbox.
+type Notifier interface {
+	SendEmail(ctx context.Context, email *Email) error
+	// Name identifies the notifier in logs and delivery records
+	Name() string
+}
+
+// FromEnv builds the notifier selected by EMAIL_NOTIFIER
+func FromEnv() (Notifier, error) {
+	switch os.Getenv("EMAIL_NOTIFIER") {
+	case "", "log":
+		return NewLogNotifier(), nil
+	case "smtp":
+		return NewSMTPNotifier(SMTPConfig{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     os.Getenv("SMTP_PORT"),
+			Username: os.Getenv("SMTP_USER"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("EMAIL_FROM"),
+		})
+	default:
+		return nil, fmt.Errorf("unknown EMAIL_NOTIFIER %q", os.Getenv("EMAIL_NOTIFIER"))
+	}
+}
+
+// validate rejects line breaks in header values, which would let a caller
+// inject extra headers or recipients
+func (e *Email) validate() error {
+	if e.To == "" {
+		return fmt.Errorf("email recipient is required")
+	}
+	for _, v := range []string{e.To, e.Subject} {
+		if strings.ContainsAny(v, "\r\n") {
+			return fmt.Errorf("email headers must not contain line breaks")
+		}
+	}
+	for _, a := range e.Attachments {
+		if strings.ContainsAny(a.Filename+a.ContentType, "\r\n\"") {
+			return fmt.Errorf("invalid attachment %q", a.Filename)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"testing"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSMTPNotifier_BuildMessage(t *testing.T) {
+	n, err := NewSMTPNotifier(SMTPConfig{Host: "smtp.example.com", From: "MadaBank <statements@madabank.id>"})
+	require.NoError(t, err)
+	n.now = func() time.Time { return time.Date(2026, 3, 1, 6, 0, 0, 0, time.UTC) }
+	attachment := bytes.Repeat([]byte("%PDF-1.4 "), 20)
+
+	raw, err := n.buildMessage(&Email{
+		To:          "budi@example.com",
+		Subject:     "Your statement for February 2026",
+		Body:        "Hi Budi,\nyour statement is attached.",
+		Attachments: []Attachment{{Filename: "statement.pdf", ContentType: "application/pdf", Data: attachment}},
+	})
+	require.NoError(t, err)
+
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	require.NoError(t, err)
+	assert.Equal(t, `"MadaBank" <statements@madabank.id>`, msg.Header.Get("From"))
+	assert.Equal(t, "budi@example.com", msg.Header.Get("To"))
+	assert.Equal(t, "Your statement for February 2026", msg.Header.Get("Subject"))
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/mixed", mediaType)
+
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	body, err := mr.NextPart()
+	require.NoError(t, err)
+	text, _ := io.ReadAll(body)
+	assert.Equal(t, "Hi Budi,\r\nyour statement is attached.", string(text))
+
+	file, err := mr.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "statement.pdf", file.FileName())
+	encoded, _ := io.ReadAll(file)
+	decoded, err := base64.StdEncoding.DecodeString(string(bytes.ReplaceAll(encoded, []byte("\r\n"), nil)))
+	require.NoError(t, err)
+	assert.Equal(t, attachment, decoded)
+}
+
+func TestNewSMTPNotifier_Validation(t *testing.T) {
+	_, err := NewSMTPNotifier(SMTPConfig{From: "statements@madabank.id"})
+	assert.EqualError(t, err, "SMTP_HOST is required")
+
+	_, err = NewSMTPNotifier(SMTPConfig{Host: "smtp.example.com", From: "not an address"})
+	assert.Error(t, err)
+
+	n, err := NewSMTPNotifier(SMTPConfig{Host: "smtp.example.com", From: "statements@madabank.id"})
+	assert.NoError(t, err)
+	assert.Equal(t, "587", n.config.Port)
+}
+
+func TestEmail_RejectsHeaderInjection(t *testing.T) {
+	logger.Init("test")
+
+	err := NewLogNotifier().SendEmail(context.Background(), &Email{To: "budi@example.com\r\nBcc: eve@example.com", Subject: "Statement"})
+	assert.Error(t, err)
+
+	err = NewLogNotifier().SendEmail(context.Background(), &Email{To: "budi@example.com", Subject: "Statement"})
+	assert.NoError(t, err)
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("EMAIL_NOTIFIER", "")
+	n, err := FromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, "log", n.Name())
+
+	t.Setenv("EMAIL_NOTIFIER", "smtp")
+	t.Setenv("SMTP_HOST", "smtp.example.com")
+	t.Setenv("EMAIL_FROM", "statements@madabank.id")
+	n, err = FromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, "smtp", n.Name())
+
+	t.Setenv("EMAIL_NOTIFIER", "pigeon")
+	_, err = FromEnv()
+	assert.Error(t, err)
+}
@@ -0,0 +1,136 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+)
+
+const smsTimeout = 10 * time.Second
+
+// SMS is a text message to one phone number
+type SMS struct {
+	To   string
+	Body string
+}
+
+// SMSSender delivers text messages. A nil error means the gateway accepted
+// the message, not that it reached the phone.
+type SMSSender interface {
+	SendSMS(ctx context.Context, sms *SMS) error
+	// Name identifies the sender in logs
+	Name() string
+}
+
+// SMSFromEnv builds the sender selected by SMS_NOTIFIER
+func SMSFromEnv() (SMSSender, error) {
+	switch os.Getenv("SMS_NOTIFIER") {
+	case "", "log":
+		return NewLogSMSSender(), nil
+	case "http":
+		return NewHTTPSMSSender(os.Getenv("SMS_GATEWAY_URL"), os.Getenv("SMS_GATEWAY_API_KEY"))
+	default:
+		return nil, fmt.Errorf("unknown SMS_NOTIFIER %q", os.Getenv("SMS_NOTIFIER"))
+	}
+}
+
+func (s *SMS) validate() error {
+	if s.To == "" {
+		return fmt.Errorf("SMS recipient is required")
+	}
+	if s.Body == "" {
+		return fmt.Errorf("SMS body is required")
+	}
+	return nil
+}
+
+// LogSMSSender logs text messages instead of sending them (development only).
+// The body is not logged, as it usually carries a one-time code.
+type LogSMSSender struct{}
+
+func NewLogSMSSender() *LogSMSSender {
+	return &LogSMSSender{}
+}
+
+func (s *LogSMSSender) SendSMS(ctx context.Context, sms *SMS) error {
+	if err := sms.validate(); err != nil {
+		return err
+	}
+	logger.Info("SMS not sent, log notifier configured", zap.String("phone", sms.To))
+	return nil
+}
+
+func (s *LogSMSSender) Name() string {
+	return "log"
+}
+
+// HTTPSMSSender posts messages to an SMS gateway exposing a JSON API:
+//
+//	POST {base}/messages {"to": "+62...", "body": "..."} -> 2xx when accepted
+//
+// Requests are authenticated with the X-API-Key header.
+type HTTPSMSSender struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+}
+
+func NewHTTPSMSSender(baseURL, apiKey string) (*HTTPSMSSender, error) {
+	if baseURL == "" {
+		return nil, fmt.Errorf("SMS_GATEWAY_URL is required")
+	}
+	if apiKey == "" {
+		return nil, fmt.Errorf("SMS_GATEWAY_API_KEY is required")
+	}
+	return &HTTPSMSSender{
+		client:  &http.Client{Timeout: smsTimeout},
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+	}, nil
+}
+
+type smsRequest struct {
+	To   string `json:"to"`
+	Body string `json:"body"`
+}
+
+func (s *HTTPSMSSender) SendSMS(ctx context.Context, sms *SMS) error {
+	if err := sms.validate(); err != nil {
+		return err
+	}
+	payload, err := json.Marshal(smsRequest{To: sms.To, Body: sms.Body})
+	if err != nil {
+		return fmt.Errorf("failed to marshal SMS: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+"/messages", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", s.apiKey)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("SMS gateway request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("SMS gateway returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (s *HTTPSMSSender) Name() string {
+	return "http"
+}
@@ -0,0 +1,49 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPSMSSender_SendSMS(t *testing.T) {
+	var got smsRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/messages", r.URL.Path)
+		assert.Equal(t, "gateway-key", r.Header.Get("X-API-Key"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	sender, err := NewHTTPSMSSender(server.URL+"/", "gateway-key")
+	require.NoError(t, err)
+
+	err = sender.SendSMS(context.Background(), &SMS{To: "+6281234567890", Body: "Your MadaBank code is 123456"})
+	assert.NoError(t, err)
+	assert.Equal(t, smsRequest{To: "+6281234567890", Body: "Your MadaBank code is 123456"}, got)
+}
+
+func TestHTTPSMSSender_GatewayError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	sender, _ := NewHTTPSMSSender(server.URL, "gateway-key")
+
+	err := sender.SendSMS(context.Background(), &SMS{To: "+6281234567890", Body: "hi"})
+	assert.EqualError(t, err, "SMS gateway returned status 502")
+}
+
+func TestHTTPSMSSender_RequiresConfig(t *testing.T) {
+	_, err := NewHTTPSMSSender("", "key")
+	assert.Error(t, err)
+	_, err = NewHTTPSMSSender("https://sms.example.com", "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,77 @@
+package objectstore
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeS3 struct {
+	input    *s3.PutObjectInput
+	getInput *s3.GetObjectInput
+}
+
+func (f *fakeS3) PutObject(_ context.Context, params *s3.PutObjectInput, _ ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	f.input = params
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (f *fakeS3) GetObject(_ context.Context, params *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	f.getInput = params
+	return &s3.GetObjectOutput{Body: io.NopCloser(strings.NewReader("data"))}, nil
+}
+
+func TestS3Store_PutUsesPrefix(t *testing.T) {
+	client := &fakeS3{}
+	store := &S3Store{client: client, bucket: "archive", prefix: "madabank/"}
+
+	assert.NoError(t, store.Put(context.Background(), "audit/a.jsonl.gz", []byte("data"), "application/gzip"))
+	assert.Equal(t, "archive", *client.input.Bucket)
+	assert.Equal(t, "madabank/audit/a.jsonl.gz", *client.input.Key)
+	assert.Equal(t, "application/gzip", *client.input.ContentType)
+}
+
+func TestFileStore_Put(t *testing.T) {
+	dir := t.TempDir()
+	store := NewFileStore(dir)
+
+	assert.NoError(t, store.Put(context.Background(), "audit/2024/01/a.jsonl.gz", []byte("data"), "application/gzip"))
+
+	data, err := os.ReadFile(filepath.Join(dir, "audit", "2024", "01", "a.jsonl.gz"))
+	assert.NoError(t, err)
+	assert.Equal(t, "data", string(data))
+}
+
+func TestFileStore_RejectsTraversal(t *testing.T) {
+	store := NewFileStore(t.TempDir())
+	assert.Error(t, store.Put(context.Background(), "../escape", []byte("x"), "text/plain"))
+}
+
+func TestS3Store_GetUsesPrefix(t *testing.T) {
+	client := &fakeS3{}
+	store := &S3Store{client: client, bucket: "archive", prefix: "madabank"}
+
+	body, err := store.Get(context.Background(), "transactions/a.jsonl.gz")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "data", string(body))
+	assert.Equal(t, "madabank/transactions/a.jsonl.gz", *client.getInput.Key)
+}
+
+func TestFileStore_GetReturnsWrittenObject(t *testing.T) {
+	store := NewFileStore(t.TempDir())
+	assert.NoError(t, store.Put(context.Background(), "transactions/2024/01/a.jsonl.gz", []byte("data"), "application/gzip"))
+
+	body, err := store.Get(context.Background(), "transactions/2024/01/a.jsonl.gz")
+	assert.NoError(t, err)
+	assert.Equal(t, "data", string(body))
+
+	_, err = store.Get(context.Background(), "../escape")
+	assert.Error(t, err)
+}
@@ -0,0 +1,156 @@
+// Package passwordpolicy checks new passwords against the bank's password
+// rules: a minimum length, required character classes and, optionally, a
+// lookup in a breached password corpus. The rules come from PASSWORD_*
+// environment variables.
+package passwordpolicy
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// MinAllowedLength is the shortest minimum a policy may be configured with
+const MinAllowedLength = 8
+
+// Policy describes what a new password must contain
+type Policy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+}
+
+// DefaultPolicy asks for 8 characters mixing upper and lower case letters and digits
+var DefaultPolicy = Policy{
+	MinLength:    MinAllowedLength,
+	RequireUpper: true,
+	RequireLower: true,
+	RequireDigit: true,
+}
+
+// BreachChecker reports whether a password is known from a data breach
+type BreachChecker interface {
+	IsBreached(ctx context.Context, password string) (bool, error)
+}
+
+// Validator applies a policy to new passwords
+type Validator struct {
+	policy   Policy
+	breaches BreachChecker // nil skips the breach check
+}
+
+func NewValidator(policy Policy, breaches BreachChecker) (*Validator, error) {
+	if policy.MinLength < MinAllowedLength {
+		return nil, fmt.Errorf("password minimum length must be at least %d", MinAllowedLength)
+	}
+	return &Validator{policy: policy, breaches: breaches}, nil
+}
+
+// FromEnv builds the validator from PASSWORD_MIN_LENGTH, PASSWORD_REQUIRE_UPPER,
+// PASSWORD_REQUIRE_LOWER, PASSWORD_REQUIRE_DIGIT and PASSWORD_REQUIRE_SYMBOL,
+// falling back to DefaultPolicy. PASSWORD_BREACH_CHECK=true enables the breach
+// lookup against PASSWORD_BREACH_API_URL (Pwned Passwords by default).
+func FromEnv() (*Validator, error) {
+	policy := DefaultPolicy
+	if v := os.Getenv("PASSWORD_MIN_LENGTH"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PASSWORD_MIN_LENGTH %q", v)
+		}
+		policy.MinLength = n
+	}
+	for env, flag := range map[string]*bool{
+		"PASSWORD_REQUIRE_UPPER":  &policy.RequireUpper,
+		"PASSWORD_REQUIRE_LOWER":  &policy.RequireLower,
+		"PASSWORD_REQUIRE_DIGIT":  &policy.RequireDigit,
+		"PASSWORD_REQUIRE_SYMBOL": &policy.RequireSymbol,
+	} {
+		if v := os.Getenv(env); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %q", env, v)
+			}
+			*flag = b
+		}
+	}
+
+	var breaches BreachChecker
+	if os.Getenv("PASSWORD_BREACH_CHECK") == "true" {
+		breaches = NewPwnedPasswordsChecker(os.Getenv("PASSWORD_BREACH_API_URL"))
+	}
+
+	return NewValidator(policy, breaches)
+}
+
+// Policy returns the rules the validator enforces
+func (v *Validator) Policy() Policy {
+	return v.policy
+}
+
+// Validate returns an error naming every rule the password breaks. When the
+// breach lookup itself fails the password is accepted, so an outage of the
+// breach API does not block sign-ups and resets.
+func (v *Validator) Validate(ctx context.Context, password string) error {
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+
+	var missing []string
+	if len([]rune(password)) < v.policy.MinLength {
+		missing = append(missing, fmt.Sprintf("at least %d characters", v.policy.MinLength))
+	}
+	if v.policy.RequireUpper && !upper {
+		missing = append(missing, "an uppercase letter")
+	}
+	if v.policy.RequireLower && !lower {
+		missing = append(missing, "a lowercase letter")
+	}
+	if v.policy.RequireDigit && !digit {
+		missing = append(missing, "a digit")
+	}
+	if v.policy.RequireSymbol && !symbol {
+		missing = append(missing, "a symbol")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("password must contain %s", joinList(missing))
+	}
+
+	if v.breaches == nil {
+		return nil
+	}
+	breached, err := v.breaches.IsBreached(ctx, password)
+	if err != nil {
+		logger.Warn("Breached password check failed, accepting password", zap.Error(err))
+		return nil
+	}
+	if breached {
+		return fmt.Errorf("this password has appeared in a data breach, please choose a different one")
+	}
+	return nil
+}
+
+// joinList joins items as "a, b and c"
+func joinList(items []string) string {
+	if len(items) == 1 {
+		return items[0]
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
+}
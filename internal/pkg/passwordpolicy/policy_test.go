@@ -0,0 +1,84 @@
+package passwordpolicy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubBreaches struct {
+	breached bool
+	err      error
+}
+
+func (s stubBreaches) IsBreached(ctx context.Context, password string) (bool, error) {
+	return s.breached, s.err
+}
+
+func TestValidate_DefaultPolicy(t *testing.T) {
+	v, err := NewValidator(DefaultPolicy, nil)
+	assert.NoError(t, err)
+
+	assert.NoError(t, v.Validate(context.Background(), "Sunrise2026"))
+	assert.EqualError(t, v.Validate(context.Background(), "sunrise"),
+		"password must contain at least 8 characters, an uppercase letter and a digit")
+}
+
+func TestValidate_RequireSymbol(t *testing.T) {
+	v, _ := NewValidator(Policy{MinLength: 12, RequireSymbol: true}, nil)
+
+	assert.EqualError(t, v.Validate(context.Background(), "longpassword"), "password must contain a symbol")
+	assert.NoError(t, v.Validate(context.Background(), "long password"))
+}
+
+func TestNewValidator_MinLengthFloor(t *testing.T) {
+	_, err := NewValidator(Policy{MinLength: 6}, nil)
+	assert.Error(t, err)
+}
+
+func TestValidate_Breached(t *testing.T) {
+	logger.Init("test")
+	v, _ := NewValidator(DefaultPolicy, stubBreaches{breached: true})
+	assert.EqualError(t, v.Validate(context.Background(), "Password1"),
+		"this password has appeared in a data breach, please choose a different one")
+
+	// An unreachable breach API does not block the password
+	v, _ = NewValidator(DefaultPolicy, stubBreaches{err: errors.New("timeout")})
+	assert.NoError(t, v.Validate(context.Background(), "Password1"))
+}
+
+func TestPwnedPasswordsChecker(t *testing.T) {
+	// SHA-1 of "Password1" is 70CCD9007338D6D81DD3B6271621B9CF9A97EA00
+	var gotPath, gotPadding string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotPadding = r.URL.Path, r.Header.Get("Add-Padding")
+		_, _ = w.Write([]byte("0018A45C4D1DEF81644B54AB7F969B88D65:0\r\n9007338D6D81DD3B6271621B9CF9A97EA00:111658\r\n"))
+	}))
+	defer server.Close()
+	checker := NewPwnedPasswordsChecker(server.URL)
+
+	breached, err := checker.IsBreached(context.Background(), "Password1")
+	assert.NoError(t, err)
+	assert.True(t, breached)
+	assert.Equal(t, "/range/70CCD", gotPath)
+	assert.Equal(t, "true", gotPadding)
+
+	breached, err = checker.IsBreached(context.Background(), "Unlisted-Passphrase-42")
+	assert.NoError(t, err)
+	assert.False(t, breached)
+}
+
+func TestPwnedPasswordsChecker_ServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	_, err := NewPwnedPasswordsChecker(server.URL).IsBreached(context.Background(), "Password1")
+	assert.Error(t, err)
+}
@@ -0,0 +1,73 @@
+package passwordpolicy
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1" // #nosec G505 -- required by the Pwned Passwords range API, not used for storage
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultPwnedPasswordsURL is the public Pwned Passwords range API
+const DefaultPwnedPasswordsURL = "https://api.pwnedpasswords.com"
+
+const pwnedTimeout = 3 * time.Second
+
+// PwnedPasswordsChecker looks passwords up with the k-anonymity range API:
+//
+//	GET {base}/range/{first 5 hex chars of SHA-1} -> "SUFFIX:COUNT" lines
+//
+// Only the 5-character hash prefix leaves the server; the suffix is matched
+// locally. Padding is requested so the response size does not leak it either.
+type PwnedPasswordsChecker struct {
+	client  *http.Client
+	baseURL string
+}
+
+func NewPwnedPasswordsChecker(baseURL string) *PwnedPasswordsChecker {
+	if baseURL == "" {
+		baseURL = DefaultPwnedPasswordsURL
+	}
+	return &PwnedPasswordsChecker{
+		client:  &http.Client{Timeout: pwnedTimeout},
+		baseURL: strings.TrimRight(baseURL, "/"),
+	}
+}
+
+func (c *PwnedPasswordsChecker) IsBreached(ctx context.Context, password string) (bool, error) {
+	sum := sha1.Sum([]byte(password)) // #nosec G401 -- see the import
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/range/"+prefix, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to build breach check request: %w", err)
+	}
+	req.Header.Set("Add-Padding", "true")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("breach check request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("breach check returned status %d", resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		candidate, count, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		// Padding entries have a count of 0
+		if ok && strings.EqualFold(candidate, suffix) && count != "0" {
+			return true, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return false, fmt.Errorf("failed to read breach check response: %w", err)
+	}
+	return false, nil
+}
@@ -0,0 +1,58 @@
+package pdf
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDocument_Bytes(t *testing.T) {
+	doc := New()
+	doc.Text(40, 60, 18, true, "Transfer Receipt")
+	doc.Line(40, 70, 555, 70, 0.5)
+	doc.TextRight(555, 100, 10, false, "Rp 150.000,00")
+
+	out := doc.Bytes()
+
+	assert.True(t, bytes.HasPrefix(out, []byte("%PDF-1.4\n")))
+	assert.True(t, bytes.HasSuffix(out, []byte("%%EOF\n")))
+	assert.Contains(t, string(out), "(Transfer Receipt) Tj")
+	assert.Contains(t, string(out), "/BaseFont /Helvetica-Bold")
+
+	// startxref must point at the cross-reference table
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(out)
+	if assert.NotNil(t, m) {
+		offset, _ := strconv.Atoi(string(m[1]))
+		assert.True(t, bytes.HasPrefix(out[offset:], []byte("xref\n")))
+	}
+
+	// every object offset must point at its object
+	for i, m := range regexp.MustCompile(`(\d{10}) 00000 n`).FindAllSubmatch(out, -1) {
+		offset, _ := strconv.Atoi(string(m[1]))
+		assert.True(t, bytes.HasPrefix(out[offset:], []byte(strconv.Itoa(i+1)+" 0 obj")))
+	}
+}
+
+func TestDocument_AddPage(t *testing.T) {
+	doc := New()
+	doc.Text(40, 60, 10, false, "Page one")
+	doc.AddPage()
+	doc.Text(40, 60, 10, false, "Page two")
+
+	out := string(doc.Bytes())
+
+	assert.Equal(t, 2, doc.PageCount())
+	assert.Contains(t, out, "/Kids [5 0 R 7 0 R] /Count 2")
+	assert.Contains(t, out, "/Contents 8 0 R")
+	assert.Regexp(t, `8 0 obj\n<< /Length \d+ >>\nstream\nBT .*\(Page two\) Tj`, out)
+}
+
+func TestEscape(t *testing.T) {
+	assert.Equal(t, `Budi \(Sons\) \\ Co`, escape(`Budi (Sons) \ Co`))
+	assert.Equal(t, `Jos\351`, escape("José"))
+	assert.Equal(t, "?", escape("雅"))
+	assert.Equal(t, "a b", escape("a\nb"))
+}
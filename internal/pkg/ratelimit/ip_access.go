@@ -0,0 +1,205 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// blockedRangesKey and allowlistKey are Redis sets of CIDR ranges, shared
+	// by all replicas and kept until removed
+	blockedRangesKey = "ipaccess:blocked"
+	allowlistKey     = "ipaccess:allowed"
+)
+
+var ErrNotBlocked = errors.New("IP is not blocked")
+
+// IPAccess is what the access lists say about one client IP
+type IPAccess int
+
+const (
+	// IPAccessDefault means the IP is on neither list and is rate limited as usual
+	IPAccessDefault IPAccess = iota
+	// IPAccessAllowed IPs skip rate limiting and blocking, even when they
+	// also fall in a blocked range
+	IPAccessAllowed
+	// IPAccessBlocked IPs fall in a permanently blocked range
+	IPAccessBlocked
+)
+
+// BlockedIP is an IP under a temporary block
+type BlockedIP struct {
+	IP        string    `json:"ip"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// CIDRRequest names an IP range, or a single IP, for an access list
+type CIDRRequest struct {
+	CIDR string `json:"cidr" binding:"required,max=64"`
+}
+
+// ParseCIDR normalizes a range to its canonical form; a bare IP becomes a
+// single-address range
+func ParseCIDR(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, "/") {
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return "", fmt.Errorf("invalid CIDR %q", s)
+		}
+		return prefix.Masked().String(), nil
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid IP address %q", s)
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()).String(), nil
+}
+
+// CheckIPAccess looks the client IP up in the allowlist and the blocked
+// ranges. Both lists are read in one round trip.
+func (rl *RateLimiter) CheckIPAccess(ctx context.Context, ip string) (IPAccess, error) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return IPAccessDefault, nil
+	}
+	addr = addr.Unmap()
+
+	pipe := rl.client.Pipeline()
+	allowedCmd := pipe.SMembers(ctx, allowlistKey)
+	blockedCmd := pipe.SMembers(ctx, blockedRangesKey)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return IPAccessDefault, fmt.Errorf("ip access check failed: %w", err)
+	}
+
+	if rangesContain(allowedCmd.Val(), addr) {
+		return IPAccessAllowed, nil
+	}
+	if rangesContain(blockedCmd.Val(), addr) {
+		return IPAccessBlocked, nil
+	}
+	return IPAccessDefault, nil
+}
+
+func rangesContain(ranges []string, addr netip.Addr) bool {
+	for _, r := range ranges {
+		prefix, err := netip.ParsePrefix(r)
+		if err == nil && prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ListBlocked returns the IPs under a temporary block
+func (rl *RateLimiter) ListBlocked(ctx context.Context) ([]BlockedIP, error) {
+	now := rl.now()
+	blocked := []BlockedIP{}
+
+	iter := rl.client.Scan(ctx, 0, "blocked:*", 0).Iterator()
+	for iter.Next(ctx) {
+		ttl, err := rl.client.PTTL(ctx, iter.Val()).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list blocked IPs: %w", err)
+		}
+		// The block expired between the scan and the lookup
+		if ttl < 0 {
+			continue
+		}
+		blocked = append(blocked, BlockedIP{
+			IP:        strings.TrimPrefix(iter.Val(), "blocked:"),
+			ExpiresAt: now.Add(ttl),
+		})
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list blocked IPs: %w", err)
+	}
+
+	sort.Slice(blocked, func(i, j int) bool { return blocked[i].IP < blocked[j].IP })
+	return blocked, nil
+}
+
+// Unblock lifts a temporary block
+func (rl *RateLimiter) Unblock(ctx context.Context, key string) error {
+	deleted, err := rl.client.Del(ctx, fmt.Sprintf("blocked:%s", key)).Result()
+	if err != nil {
+		return fmt.Errorf("failed to unblock: %w", err)
+	}
+	if deleted == 0 {
+		return ErrNotBlocked
+	}
+	return nil
+}
+
+// BlockRange blocks a range until it is removed and returns it normalized
+func (rl *RateLimiter) BlockRange(ctx context.Context, cidr string) (string, error) {
+	return rl.addRange(ctx, blockedRangesKey, cidr)
+}
+
+// UnblockRange removes a permanently blocked range
+func (rl *RateLimiter) UnblockRange(ctx context.Context, cidr string) (string, error) {
+	return rl.removeRange(ctx, blockedRangesKey, cidr)
+}
+
+// BlockedRanges lists the permanently blocked ranges
+func (rl *RateLimiter) BlockedRanges(ctx context.Context) ([]string, error) {
+	return rl.listRanges(ctx, blockedRangesKey)
+}
+
+// Allow adds a range to the allowlist and returns it normalized
+func (rl *RateLimiter) Allow(ctx context.Context, cidr string) (string, error) {
+	return rl.addRange(ctx, allowlistKey, cidr)
+}
+
+// Disallow removes a range from the allowlist
+func (rl *RateLimiter) Disallow(ctx context.Context, cidr string) (string, error) {
+	return rl.removeRange(ctx, allowlistKey, cidr)
+}
+
+// Allowlist lists the allowlisted ranges
+func (rl *RateLimiter) Allowlist(ctx context.Context) ([]string, error) {
+	return rl.listRanges(ctx, allowlistKey)
+}
+
+func (rl *RateLimiter) addRange(ctx context.Context, key, cidr string) (string, error) {
+	normalized, err := ParseCIDR(cidr)
+	if err != nil {
+		return "", err
+	}
+	if err := rl.client.SAdd(ctx, key, normalized).Err(); err != nil {
+		return "", fmt.Errorf("failed to save IP range: %w", err)
+	}
+	return normalized, nil
+}
+
+func (rl *RateLimiter) removeRange(ctx context.Context, key, cidr string) (string, error) {
+	normalized, err := ParseCIDR(cidr)
+	if err != nil {
+		return "", err
+	}
+	removed, err := rl.client.SRem(ctx, key, normalized).Result()
+	if err != nil {
+		return "", fmt.Errorf("failed to remove IP range: %w", err)
+	}
+	if removed == 0 {
+		return "", fmt.Errorf("IP range %s is not listed", normalized)
+	}
+	return normalized, nil
+}
+
+func (rl *RateLimiter) listRanges(ctx context.Context, key string) ([]string, error) {
+	ranges, err := rl.client.SMembers(ctx, key).Result()
+	if err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to list IP ranges: %w", err)
+	}
+	sort.Strings(ranges)
+	return ranges, nil
+}
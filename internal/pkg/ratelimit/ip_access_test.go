@@ -0,0 +1,83 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCIDR(t *testing.T) {
+	for in, want := range map[string]string{
+		"10.1.2.3/8":       "10.0.0.0/8",
+		"192.168.0.7":      "192.168.0.7/32",
+		"::ffff:10.0.0.1":  "10.0.0.1/32",
+		"2001:db8::1/32":   "2001:db8::/32",
+		" 203.0.113.9/32 ": "203.0.113.9/32",
+	} {
+		got, err := ParseCIDR(in)
+		assert.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	_, err := ParseCIDR("10.0.0.0/33")
+	assert.Error(t, err)
+	_, err = ParseCIDR("example.com")
+	assert.Error(t, err)
+}
+
+func TestCheckIPAccess(t *testing.T) {
+	rl, mr := setupRateLimiterTest(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	_, err := rl.BlockRange(ctx, "10.0.0.0/8")
+	assert.NoError(t, err)
+	_, err = rl.Allow(ctx, "10.1.0.0/16")
+	assert.NoError(t, err)
+
+	for ip, want := range map[string]IPAccess{
+		"10.2.3.4":  IPAccessBlocked,
+		"10.1.3.4":  IPAccessAllowed, // the allowlist wins over a blocked range
+		"192.0.2.1": IPAccessDefault,
+		"garbage":   IPAccessDefault,
+	} {
+		access, err := rl.CheckIPAccess(ctx, ip)
+		assert.NoError(t, err)
+		assert.Equal(t, want, access, ip)
+	}
+
+	_, err = rl.UnblockRange(ctx, "10.0.0.0/8")
+	assert.NoError(t, err)
+	access, err := rl.CheckIPAccess(ctx, "10.2.3.4")
+	assert.NoError(t, err)
+	assert.Equal(t, IPAccessDefault, access)
+
+	_, err = rl.UnblockRange(ctx, "10.0.0.0/8")
+	assert.EqualError(t, err, "IP range 10.0.0.0/8 is not listed")
+}
+
+func TestListBlockedAndUnblock(t *testing.T) {
+	rl, mr := setupRateLimiterTest(t)
+	defer mr.Close()
+	ctx := context.Background()
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	rl.now = func() time.Time { return now }
+
+	assert.NoError(t, rl.Block(ctx, "198.51.100.7", time.Hour))
+	assert.NoError(t, rl.Block(ctx, "198.51.100.2", 5*time.Minute))
+
+	blocked, err := rl.ListBlocked(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []BlockedIP{
+		{IP: "198.51.100.2", ExpiresAt: now.Add(5 * time.Minute)},
+		{IP: "198.51.100.7", ExpiresAt: now.Add(time.Hour)},
+	}, blocked)
+
+	assert.NoError(t, rl.Unblock(ctx, "198.51.100.7"))
+	isBlocked, err := rl.IsBlocked(ctx, "198.51.100.7")
+	assert.NoError(t, err)
+	assert.False(t, isBlocked)
+	assert.ErrorIs(t, rl.Unblock(ctx, "198.51.100.7"), ErrNotBlocked)
+}
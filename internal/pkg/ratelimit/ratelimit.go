@@ -8,13 +8,48 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+// Algorithm is how requests are counted against a RateLimitConfig
+type Algorithm string
+
+const (
+	// AlgorithmSlidingLog keeps a timestamp per request in a sorted set. It is
+	// exact, but memory grows with the limit and rejected requests count too.
+	AlgorithmSlidingLog Algorithm = "sliding_log"
+	// AlgorithmSlidingWindow keeps a counter for the current and previous
+	// fixed window and weighs the previous one by how much of it still
+	// overlaps the sliding window, so bursts cannot straddle a window edge.
+	// Each check is one atomic Lua script.
+	AlgorithmSlidingWindow Algorithm = "sliding_window"
+	// AlgorithmTokenBucket refills Requests tokens per Window, up to Burst.
+	// A client that was quiet can burst, then settles to the steady rate,
+	// which suits mobile clients retrying after a network drop.
+	AlgorithmTokenBucket Algorithm = "token_bucket"
+)
+
+// ParseAlgorithm reads the RATE_LIMIT_ALGORITHM setting; empty means the sliding log
+func ParseAlgorithm(s string) (Algorithm, error) {
+	switch Algorithm(s) {
+	case "":
+		return AlgorithmSlidingLog, nil
+	case AlgorithmSlidingLog, AlgorithmSlidingWindow, AlgorithmTokenBucket:
+		return Algorithm(s), nil
+	default:
+		return "", fmt.Errorf("unknown rate limit algorithm %q", s)
+	}
+}
+
 type RateLimiter struct {
-	client *redis.Client
+	client    *redis.Client
+	algorithm Algorithm
+	now       func() time.Time
 }
 
 type RateLimitConfig struct {
 	Requests int           // Number of requests allowed
 	Window   time.Duration // Time window
+	// Burst is the token bucket capacity; 0 means Requests. Other
+	// algorithms ignore it.
+	Burst int
 }
 
 // Common rate limit configurations
@@ -45,98 +80,41 @@ var (
 )
 
 func NewRateLimiter(redisClient *redis.Client) *RateLimiter {
+	return NewRateLimiterWithAlgorithm(redisClient, AlgorithmSlidingLog)
+}
+
+func NewRateLimiterWithAlgorithm(redisClient *redis.Client, algorithm Algorithm) *RateLimiter {
 	return &RateLimiter{
-		client: redisClient,
+		client:    redisClient,
+		algorithm: algorithm,
+		now:       time.Now,
 	}
 }
 
+// Algorithm reports how the limiter counts requests
+func (rl *RateLimiter) Algorithm() Algorithm {
+	return rl.algorithm
+}
+
 // CheckLimit checks if the request is within rate limits
 func (rl *RateLimiter) CheckLimit(ctx context.Context, key string, config RateLimitConfig) (bool, error) {
-	now := time.Now()
-	windowStart := now.Add(-config.Window)
-
-	// Use Redis sorted set to track requests within the time window
-	// Score is timestamp, member is unique request ID
-	pipe := rl.client.Pipeline()
-
-	// Remove old entries outside the window
-	pipe.ZRemRangeByScore(ctx, key, "0", fmt.Sprintf("%d", windowStart.UnixMilli()))
-
-	// Count requests in the current window
-	countCmd := pipe.ZCard(ctx, key)
-
-	// Add current request
-	pipe.ZAdd(ctx, key, redis.Z{
-		Score:  float64(now.UnixMilli()),
-		Member: fmt.Sprintf("%d", now.UnixNano()),
-	})
-
-	// Set expiration on the key
-	pipe.Expire(ctx, key, config.Window+time.Minute)
-
-	_, err := pipe.Exec(ctx)
+	info, err := rl.CheckLimitWithInfo(ctx, key, config)
 	if err != nil {
-		return false, fmt.Errorf("rate limit check failed: %w", err)
+		return false, err
 	}
-
-	count := countCmd.Val()
-
-	// Allow if count is less than limit
-	return count < int64(config.Requests), nil
+	return info.Allowed, nil
 }
 
 // CheckLimitWithInfo checks rate limit and returns detailed info
 func (rl *RateLimiter) CheckLimitWithInfo(ctx context.Context, key string, config RateLimitConfig) (*RateLimitInfo, error) {
-	now := time.Now()
-	windowStart := now.Add(-config.Window)
-
-	pipe := rl.client.Pipeline()
-
-	// Remove old entries
-	pipe.ZRemRangeByScore(ctx, key, "0", fmt.Sprintf("%d", windowStart.UnixMilli()))
-
-	// Count current requests
-	countCmd := pipe.ZCard(ctx, key)
-
-	// Get oldest request in window
-	oldestCmd := pipe.ZRange(ctx, key, 0, 0)
-
-	// Add current request if within limit
-	pipe.ZAdd(ctx, key, redis.Z{
-		Score:  float64(now.UnixMilli()),
-		Member: fmt.Sprintf("%d", now.UnixNano()),
-	})
-
-	pipe.Expire(ctx, key, config.Window+time.Minute)
-
-	_, err := pipe.Exec(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("rate limit check failed: %w", err)
+	switch rl.algorithm {
+	case AlgorithmSlidingWindow:
+		return rl.checkSlidingWindow(ctx, key, config)
+	case AlgorithmTokenBucket:
+		return rl.checkTokenBucket(ctx, key, config)
+	default:
+		return rl.checkSlidingLog(ctx, key, config)
 	}
-
-	count := countCmd.Val()
-	allowed := count < int64(config.Requests)
-
-	info := &RateLimitInfo{
-		Limit:     config.Requests,
-		Remaining: config.Requests - int(count),
-		Reset:     now.Add(config.Window),
-		Allowed:   allowed,
-	}
-
-	if info.Remaining < 0 {
-		info.Remaining = 0
-	}
-
-	// Calculate retry after if blocked
-	if !allowed && len(oldestCmd.Val()) > 0 {
-		// Parse oldest timestamp
-		_ = oldestCmd.Val()[0]
-		// Set retry after based on when oldest request will expire
-		info.RetryAfter = config.Window
-	}
-
-	return info, nil
 }
 
 // Block temporarily blocks a key (for suspicious activity)
@@ -0,0 +1,54 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// slidingLogScript drops the log entries that left the window, counts the
+// rest and logs the request, as one step so concurrent replicas cannot both
+// see the last free slot.
+//
+//	KEYS[1] sorted set of request timestamps
+//	ARGV[1] oldest timestamp still in the window, ARGV[2] now,
+//	ARGV[3] member for this request, ARGV[4] key TTL, all in milliseconds
+//
+// Returns the number of requests in the window before this one.
+var slidingLogScript = redis.NewScript(`
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", ARGV[1])
+local count = redis.call("ZCARD", KEYS[1])
+redis.call("ZADD", KEYS[1], ARGV[2], ARGV[3])
+redis.call("PEXPIRE", KEYS[1], ARGV[4])
+return count
+`)
+
+func (rl *RateLimiter) checkSlidingLog(ctx context.Context, key string, config RateLimitConfig) (*RateLimitInfo, error) {
+	now := rl.now()
+	windowStart := now.Add(-config.Window)
+
+	// Replicas can log a request in the same nanosecond, so the member is
+	// random rather than the timestamp
+	count, err := slidingLogScript.Run(ctx, rl.client, []string{key},
+		windowStart.UnixMilli(), now.UnixMilli(), uuid.NewString(), (config.Window + time.Minute).Milliseconds()).Int64()
+	if err != nil {
+		return nil, fmt.Errorf("rate limit check failed: %w", err)
+	}
+
+	info := &RateLimitInfo{
+		Limit:     config.Requests,
+		Remaining: config.Requests - int(count),
+		Reset:     now.Add(config.Window),
+		Allowed:   count < int64(config.Requests),
+	}
+	if info.Remaining < 0 {
+		info.Remaining = 0
+	}
+	if !info.Allowed {
+		info.RetryAfter = config.Window
+	}
+	return info, nil
+}
@@ -0,0 +1,66 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlidingLog_SameInstantCountsEveryRequest(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(mr.Close)
+
+	rl := NewRateLimiter(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	rl.now = func() time.Time { return now }
+	ctx := context.Background()
+	config := RateLimitConfig{Requests: 2, Window: time.Minute}
+
+	for i := 0; i < 2; i++ {
+		allowed, err := rl.CheckLimit(ctx, "sl:instant", config)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+	allowed, err := rl.CheckLimit(ctx, "sl:instant", config)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
+
+func TestSlidingLog_ReplicasShareTheLimit(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(mr.Close)
+
+	// One limiter per replica, each with its own connection pool
+	replicas := make([]*RateLimiter, 4)
+	for i := range replicas {
+		replicas[i] = NewRateLimiter(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	}
+	ctx := context.Background()
+	config := RateLimitConfig{Requests: 5, Window: time.Minute}
+
+	var allowed atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 40; i++ {
+		wg.Add(1)
+		go func(rl *RateLimiter) {
+			defer wg.Done()
+			ok, err := rl.CheckLimit(ctx, "sl:shared", config)
+			assert.NoError(t, err)
+			if ok {
+				allowed.Add(1)
+			}
+		}(replicas[i%len(replicas)])
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(5), allowed.Load())
+}
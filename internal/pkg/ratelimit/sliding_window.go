@@ -0,0 +1,96 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// slidingWindowScript admits a request when the weighted count of the
+// sliding window stays within the limit, and only then counts it.
+//
+//	KEYS[1] current window counter, KEYS[2] previous window counter
+//	ARGV[1] limit, ARGV[2] weight of the previous window in parts per million,
+//	ARGV[3] counter TTL in milliseconds
+//
+// Returns {allowed, previous count, current count after the request}.
+var slidingWindowScript = redis.NewScript(`
+local current = tonumber(redis.call("GET", KEYS[1]) or "0")
+local previous = tonumber(redis.call("GET", KEYS[2]) or "0")
+local weighted = math.floor(previous * tonumber(ARGV[2]) / 1000000) + current
+if weighted + 1 > tonumber(ARGV[1]) then
+	return {0, previous, current}
+end
+current = redis.call("INCR", KEYS[1])
+redis.call("PEXPIRE", KEYS[1], ARGV[3])
+return {1, previous, current}
+`)
+
+func (rl *RateLimiter) checkSlidingWindow(ctx context.Context, key string, config RateLimitConfig) (*RateLimitInfo, error) {
+	now := rl.now()
+	window := config.Window.Milliseconds()
+	nowMs := now.UnixMilli()
+	currentStart := nowMs - nowMs%window
+	elapsed := nowMs - currentStart
+	// Share of the previous window still inside the sliding window
+	weight := float64(window-elapsed) / float64(window)
+
+	// The hash tag keeps both counters on one cluster slot
+	keys := []string{
+		fmt.Sprintf("{%s}:%d", key, currentStart),
+		fmt.Sprintf("{%s}:%d", key, currentStart-window),
+	}
+	res, err := slidingWindowScript.Run(ctx, rl.client, keys,
+		config.Requests, int64(weight*1e6), 2*window).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("rate limit check failed: %w", err)
+	}
+	allowed, previous, current := res[0] == 1, res[1], res[2]
+
+	used := int(math.Floor(float64(previous)*weight)) + int(current)
+	info := &RateLimitInfo{
+		Limit:     config.Requests,
+		Remaining: config.Requests - used,
+		Reset:     time.UnixMilli(currentStart + window),
+		Allowed:   allowed,
+	}
+	if info.Remaining < 0 {
+		info.Remaining = 0
+	}
+	if !allowed {
+		info.RetryAfter = slidingWindowRetryAfter(config.Requests, previous, current, window, elapsed)
+		info.Reset = now.Add(info.RetryAfter)
+	}
+	return info, nil
+}
+
+// slidingWindowRetryAfter is how long until the weighted count leaves room
+// for one more request, assuming no other request is admitted meanwhile
+func slidingWindowRetryAfter(limit int, previous, current, window, elapsed int64) time.Duration {
+	room := float64(limit - 1)
+	remaining := window - elapsed
+
+	// Within the current window, the previous window's share decays
+	if float64(current) <= room && previous > 0 {
+		// previous*(window-elapsed-t)/window + current <= room
+		t := float64(remaining) - (room-float64(current))*float64(window)/float64(previous)
+		if t < float64(remaining) {
+			return waitMillis(math.Max(t, 1))
+		}
+	}
+
+	// Otherwise the current window becomes the previous one and decays
+	// current*(window-e)/window <= room
+	e := float64(window)
+	if current > 0 {
+		e = float64(window) * (1 - room/float64(current))
+	}
+	return waitMillis(float64(remaining) + math.Max(e, 1))
+}
+
+func waitMillis(ms float64) time.Duration {
+	return time.Duration(math.Ceil(ms)) * time.Millisecond
+}
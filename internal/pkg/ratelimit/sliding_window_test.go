@@ -0,0 +1,101 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupSlidingWindowTest returns a limiter whose clock is moved by the
+// returned function
+func setupSlidingWindowTest(t *testing.T) (*RateLimiter, func(time.Duration)) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(mr.Close)
+
+	rl := NewRateLimiterWithAlgorithm(redis.NewClient(&redis.Options{Addr: mr.Addr()}), AlgorithmSlidingWindow)
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	rl.now = func() time.Time { return now }
+	return rl, func(d time.Duration) {
+		now = now.Add(d)
+		mr.FastForward(d)
+	}
+}
+
+func TestSlidingWindow_Limit(t *testing.T) {
+	rl, _ := setupSlidingWindowTest(t)
+	ctx := context.Background()
+	config := RateLimitConfig{Requests: 3, Window: time.Minute}
+
+	for i := 2; i >= 0; i-- {
+		info, err := rl.CheckLimitWithInfo(ctx, "sw:limit", config)
+		require.NoError(t, err)
+		assert.True(t, info.Allowed)
+		assert.Equal(t, i, info.Remaining)
+	}
+
+	info, err := rl.CheckLimitWithInfo(ctx, "sw:limit", config)
+	require.NoError(t, err)
+	assert.False(t, info.Allowed)
+	assert.Equal(t, 0, info.Remaining)
+	// The next window must start and the 3 requests decay to 2
+	assert.Equal(t, 80*time.Second, info.RetryAfter)
+}
+
+func TestSlidingWindow_NoBurstAcrossWindowEdge(t *testing.T) {
+	rl, advance := setupSlidingWindowTest(t)
+	ctx := context.Background()
+	config := RateLimitConfig{Requests: 10, Window: time.Minute}
+
+	// Fill the limit at the end of one window
+	advance(50 * time.Second)
+	for i := 0; i < 10; i++ {
+		allowed, err := rl.CheckLimit(ctx, "sw:edge", config)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+
+	// Just after the edge a fixed window would allow 10 more; here the
+	// previous window still weighs 3/4 (7.5 requests), so only 3 fit
+	advance(25 * time.Second)
+	admitted := 0
+	for i := 0; i < 10; i++ {
+		allowed, err := rl.CheckLimit(ctx, "sw:edge", config)
+		require.NoError(t, err)
+		if allowed {
+			admitted++
+		}
+	}
+	assert.Equal(t, 3, admitted)
+
+	// Rejected requests are not counted, so room returns as the old window decays
+	info, err := rl.CheckLimitWithInfo(ctx, "sw:edge", config)
+	require.NoError(t, err)
+	assert.False(t, info.Allowed)
+	advance(info.RetryAfter)
+	allowed, err := rl.CheckLimit(ctx, "sw:edge", config)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+func TestParseAlgorithm(t *testing.T) {
+	a, err := ParseAlgorithm("")
+	assert.NoError(t, err)
+	assert.Equal(t, AlgorithmSlidingLog, a)
+
+	a, err = ParseAlgorithm("sliding_window")
+	assert.NoError(t, err)
+	assert.Equal(t, AlgorithmSlidingWindow, a)
+
+	a, err = ParseAlgorithm("token_bucket")
+	assert.NoError(t, err)
+	assert.Equal(t, AlgorithmTokenBucket, a)
+
+	_, err = ParseAlgorithm("fixed")
+	assert.Error(t, err)
+}
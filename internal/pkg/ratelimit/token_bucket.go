@@ -0,0 +1,86 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucketScript refills the bucket for the time since the last request
+// and takes one token if there is one.
+//
+//	KEYS[1] bucket hash {tokens, ts}
+//	ARGV[1] refill rate in tokens per millisecond, ARGV[2] capacity,
+//	ARGV[3] now in milliseconds, ARGV[4] bucket TTL in milliseconds
+//
+// Returns {allowed, tokens left}. Tokens are fractional, so they come back
+// as a string.
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local capacity = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local state = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(state[1]) or capacity
+local ts = tonumber(state[2]) or now
+if now > ts then
+	tokens = math.min(capacity, tokens + (now - ts) * rate)
+	ts = now
+end
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "ts", tostring(ts))
+redis.call("PEXPIRE", KEYS[1], ARGV[4])
+return {allowed, tostring(tokens)}
+`)
+
+func (rl *RateLimiter) checkTokenBucket(ctx context.Context, key string, config RateLimitConfig) (*RateLimitInfo, error) {
+	now := rl.now()
+	capacity := config.Burst
+	if capacity <= 0 {
+		capacity = config.Requests
+	}
+	rate := float64(config.Requests) / float64(config.Window.Milliseconds())
+	// An idle bucket is full again after capacity/rate; drop it then
+	ttl := int64(math.Ceil(float64(capacity)/rate)) + time.Minute.Milliseconds()
+
+	res, err := tokenBucketScript.Run(ctx, rl.client, []string{"{" + key + "}:bucket"},
+		strconv.FormatFloat(rate, 'g', -1, 64), capacity, now.UnixMilli(), ttl).Slice()
+	if err != nil {
+		return nil, fmt.Errorf("rate limit check failed: %w", err)
+	}
+	if len(res) != 2 {
+		return nil, fmt.Errorf("rate limit check failed: unexpected reply %v", res)
+	}
+	allowed, _ := res[0].(int64)
+	tokens, err := strconv.ParseFloat(fmt.Sprint(res[1]), 64)
+	if err != nil {
+		return nil, fmt.Errorf("rate limit check failed: %w", err)
+	}
+
+	info := &RateLimitInfo{
+		Limit:     capacity,
+		Remaining: int(math.Floor(tokens)),
+		// When the bucket is full again
+		Reset:   now.Add(refillTime(float64(capacity)-tokens, rate)),
+		Allowed: allowed == 1,
+	}
+	if !info.Allowed {
+		info.RetryAfter = refillTime(1-tokens, rate)
+	}
+	return info, nil
+}
+
+// refillTime is how long the bucket takes to gain tokens
+func refillTime(tokens, rate float64) time.Duration {
+	if tokens <= 0 {
+		return 0
+	}
+	return time.Duration(math.Ceil(tokens/rate)) * time.Millisecond
+}
@@ -0,0 +1,100 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTokenBucketTest(t *testing.T) (*RateLimiter, func(time.Duration)) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(mr.Close)
+
+	rl := NewRateLimiterWithAlgorithm(redis.NewClient(&redis.Options{Addr: mr.Addr()}), AlgorithmTokenBucket)
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	rl.now = func() time.Time { return now }
+	return rl, func(d time.Duration) {
+		now = now.Add(d)
+		mr.FastForward(d)
+	}
+}
+
+func TestTokenBucket_Burst(t *testing.T) {
+	rl, _ := setupTokenBucketTest(t)
+	ctx := context.Background()
+	// 6 a minute is one token every 10s, with room for 3 at once
+	config := RateLimitConfig{Requests: 6, Window: time.Minute, Burst: 3}
+
+	for i := 2; i >= 0; i-- {
+		info, err := rl.CheckLimitWithInfo(ctx, "tb:burst", config)
+		require.NoError(t, err)
+		assert.True(t, info.Allowed)
+		assert.Equal(t, 3, info.Limit)
+		assert.Equal(t, i, info.Remaining)
+	}
+
+	info, err := rl.CheckLimitWithInfo(ctx, "tb:burst", config)
+	require.NoError(t, err)
+	assert.False(t, info.Allowed)
+	assert.Equal(t, 0, info.Remaining)
+	assert.Equal(t, 10*time.Second, info.RetryAfter)
+	// Refilling all 3 tokens takes 30s
+	assert.Equal(t, 30*time.Second, info.Reset.Sub(rl.now()))
+}
+
+func TestTokenBucket_Refill(t *testing.T) {
+	rl, advance := setupTokenBucketTest(t)
+	ctx := context.Background()
+	config := RateLimitConfig{Requests: 6, Window: time.Minute, Burst: 3}
+
+	for i := 0; i < 3; i++ {
+		allowed, err := rl.CheckLimit(ctx, "tb:refill", config)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+
+	// Half a token is not enough
+	advance(5 * time.Second)
+	info, err := rl.CheckLimitWithInfo(ctx, "tb:refill", config)
+	require.NoError(t, err)
+	assert.False(t, info.Allowed)
+	assert.Equal(t, 5*time.Second, info.RetryAfter)
+
+	// After the retry the steady rate admits one request at a time
+	advance(info.RetryAfter)
+	allowed, err := rl.CheckLimit(ctx, "tb:refill", config)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	allowed, err = rl.CheckLimit(ctx, "tb:refill", config)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	// A long pause refills the bucket only up to the burst
+	advance(time.Hour)
+	admitted := 0
+	for i := 0; i < 10; i++ {
+		allowed, err := rl.CheckLimit(ctx, "tb:refill", config)
+		require.NoError(t, err)
+		if allowed {
+			admitted++
+		}
+	}
+	assert.Equal(t, 3, admitted)
+}
+
+func TestTokenBucket_BurstDefaultsToRequests(t *testing.T) {
+	rl, _ := setupTokenBucketTest(t)
+	ctx := context.Background()
+
+	info, err := rl.CheckLimitWithInfo(ctx, "tb:default", AuthRateLimit)
+	require.NoError(t, err)
+	assert.True(t, info.Allowed)
+	assert.Equal(t, AuthRateLimit.Requests, info.Limit)
+	assert.Equal(t, AuthRateLimit.Requests-1, info.Remaining)
+}
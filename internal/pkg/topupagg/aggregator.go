@@ -0,0 +1,56 @@
+package topupagg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrOrderNotFound is returned by CheckStatus for an order the aggregator never accepted
+var ErrOrderNotFound = errors.New("top-up order not found")
+
+type Status string
+
+const (
+	StatusProcessing Status = "processing"
+	StatusSuccess    Status = "success"
+	StatusFailed     Status = "failed"
+)
+
+// Order asks the aggregator to deliver phone credit or e-wallet balance
+type Order struct {
+	// OrderID is our top-up ID; aggregators use it to deduplicate resubmissions
+	OrderID     string
+	Provider    string
+	Destination string
+	Amount      float64
+}
+
+// Result is the aggregator's view of an order. Fulfillment is asynchronous:
+// Submit usually answers StatusProcessing and the final status is polled.
+type Result struct {
+	Reference string
+	Status    Status
+	// Reason explains a failed order
+	Reason string
+}
+
+// Aggregator delivers phone credit and e-wallet top-ups through a third party
+type Aggregator interface {
+	Submit(ctx context.Context, order Order) (*Result, error)
+	CheckStatus(ctx context.Context, orderID string) (*Result, error)
+	Name() string
+}
+
+// FromEnv builds the aggregator selected by TOPUP_AGGREGATOR
+func FromEnv() (Aggregator, error) {
+	switch os.Getenv("TOPUP_AGGREGATOR") {
+	case "", "simulated":
+		return NewSimulatedAggregator(), nil
+	case "http":
+		return NewHTTPAggregator(os.Getenv("TOPUP_AGGREGATOR_URL"), os.Getenv("TOPUP_AGGREGATOR_API_KEY"))
+	default:
+		return nil, fmt.Errorf("unknown TOPUP_AGGREGATOR %q", os.Getenv("TOPUP_AGGREGATOR"))
+	}
+}
@@ -0,0 +1,85 @@
+package topupagg
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimulatedAggregator(t *testing.T) {
+	agg := NewSimulatedAggregator()
+
+	result, err := agg.Submit(context.Background(), Order{OrderID: "order-1", Provider: "gopay", Destination: "081234567890", Amount: 50000})
+	assert.NoError(t, err)
+	assert.Equal(t, StatusProcessing, result.Status)
+	assert.NotEmpty(t, result.Reference)
+
+	status, err := agg.CheckStatus(context.Background(), "order-1")
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status.Status)
+	assert.Equal(t, result.Reference, status.Reference)
+
+	rejected, err := agg.Submit(context.Background(), Order{OrderID: "order-2", Destination: "081234569999"})
+	assert.NoError(t, err)
+	assert.Equal(t, StatusFailed, rejected.Status)
+	assert.NotEmpty(t, rejected.Reason)
+}
+
+func TestHTTPAggregator(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.Header.Get("X-API-Key"))
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/orders":
+			var req orderRequest
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, "order-1", req.OrderID)
+			_ = json.NewEncoder(w).Encode(orderResponse{Reference: "REF-1", Status: StatusProcessing})
+		case r.Method == http.MethodGet && r.URL.Path == "/orders/order-1":
+			_ = json.NewEncoder(w).Encode(orderResponse{Reference: "REF-1", Status: StatusFailed, Reason: "number inactive"})
+		case r.Method == http.MethodGet && r.URL.Path == "/orders/odd":
+			_ = json.NewEncoder(w).Encode(orderResponse{Reference: "REF-2", Status: "refunded"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	agg, err := NewHTTPAggregator(server.URL, "secret")
+	assert.NoError(t, err)
+
+	result, err := agg.Submit(context.Background(), Order{OrderID: "order-1", Provider: "telkomsel", Destination: "081234567890", Amount: 50000})
+	assert.NoError(t, err)
+	assert.Equal(t, &Result{Reference: "REF-1", Status: StatusProcessing}, result)
+
+	result, err = agg.CheckStatus(context.Background(), "order-1")
+	assert.NoError(t, err)
+	assert.Equal(t, StatusFailed, result.Status)
+	assert.Equal(t, "number inactive", result.Reason)
+
+	_, err = agg.CheckStatus(context.Background(), "missing")
+	assert.ErrorIs(t, err, ErrOrderNotFound)
+
+	_, err = agg.CheckStatus(context.Background(), "odd")
+	assert.Error(t, err)
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("TOPUP_AGGREGATOR", "")
+	agg, err := FromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, "simulated", agg.Name())
+
+	t.Setenv("TOPUP_AGGREGATOR", "http")
+	t.Setenv("TOPUP_AGGREGATOR_URL", "")
+	_, err = FromEnv()
+	assert.Error(t, err)
+
+	t.Setenv("TOPUP_AGGREGATOR", "unknown")
+	_, err = FromEnv()
+	assert.Error(t, err)
+}
@@ -0,0 +1,114 @@
+package topupagg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const httpTimeout = 15 * time.Second
+
+// HTTPAggregator talks to a top-up aggregator exposing a JSON API:
+//
+//	POST {base}/orders       -> 200 result (idempotent on order_id)
+//	GET  {base}/orders/{id}  -> 200 result, 404 when the order is unknown
+//
+// Requests are authenticated with the X-API-Key header.
+type HTTPAggregator struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+}
+
+func NewHTTPAggregator(baseURL, apiKey string) (*HTTPAggregator, error) {
+	if baseURL == "" {
+		return nil, fmt.Errorf("TOPUP_AGGREGATOR_URL is required")
+	}
+	if apiKey == "" {
+		return nil, fmt.Errorf("TOPUP_AGGREGATOR_API_KEY is required")
+	}
+	return &HTTPAggregator{
+		client:  &http.Client{Timeout: httpTimeout},
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+	}, nil
+}
+
+type orderRequest struct {
+	OrderID     string  `json:"order_id"`
+	Provider    string  `json:"provider"`
+	Destination string  `json:"destination"`
+	Amount      float64 `json:"amount"`
+}
+
+type orderResponse struct {
+	Reference string `json:"reference"`
+	Status    Status `json:"status"`
+	Reason    string `json:"reason"`
+}
+
+func (a *HTTPAggregator) Submit(ctx context.Context, order Order) (*Result, error) {
+	payload, err := json.Marshal(orderRequest{
+		OrderID:     order.OrderID,
+		Provider:    order.Provider,
+		Destination: order.Destination,
+		Amount:      order.Amount,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	return a.do(ctx, http.MethodPost, "/orders", bytes.NewReader(payload))
+}
+
+func (a *HTTPAggregator) CheckStatus(ctx context.Context, orderID string) (*Result, error) {
+	return a.do(ctx, http.MethodGet, "/orders/"+url.PathEscape(orderID), nil)
+}
+
+func (a *HTTPAggregator) Name() string {
+	return "http"
+}
+
+func (a *HTTPAggregator) do(ctx context.Context, method, path string, body io.Reader) (*Result, error) {
+	req, err := http.NewRequestWithContext(ctx, method, a.baseURL+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("X-API-Key", a.apiKey)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("top-up aggregator request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, ErrOrderNotFound
+	default:
+		return nil, fmt.Errorf("top-up aggregator returned status %d", resp.StatusCode)
+	}
+
+	var out orderResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("failed to decode top-up aggregator response: %w", err)
+	}
+
+	switch out.Status {
+	case StatusProcessing, StatusSuccess, StatusFailed:
+	default:
+		return nil, fmt.Errorf("top-up aggregator returned unknown status %q", out.Status)
+	}
+
+	return &Result{Reference: out.Reference, Status: out.Status, Reason: out.Reason}, nil
+}
@@ -0,0 +1,40 @@
+package topupagg
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+)
+
+// SimulatedAggregator accepts every order and reports it delivered on the next
+// status check, so the asynchronous flow can be exercised without an aggregator
+// contract (development only).
+//
+// Destinations ending in "9999" are rejected on submission.
+type SimulatedAggregator struct{}
+
+func NewSimulatedAggregator() *SimulatedAggregator {
+	return &SimulatedAggregator{}
+}
+
+func (a *SimulatedAggregator) Submit(ctx context.Context, order Order) (*Result, error) {
+	reference := simulatedReference(order.OrderID)
+	if strings.HasSuffix(order.Destination, "9999") {
+		return &Result{Reference: reference, Status: StatusFailed, Reason: "destination not registered"}, nil
+	}
+	return &Result{Reference: reference, Status: StatusProcessing}, nil
+}
+
+func (a *SimulatedAggregator) CheckStatus(ctx context.Context, orderID string) (*Result, error) {
+	return &Result{Reference: simulatedReference(orderID), Status: StatusSuccess}, nil
+}
+
+func (a *SimulatedAggregator) Name() string {
+	return "simulated"
+}
+
+func simulatedReference(orderID string) string {
+	sum := sha256.Sum256([]byte(orderID))
+	return fmt.Sprintf("SIMTOP-%x", sum[:6])
+}
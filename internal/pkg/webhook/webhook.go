@@ -0,0 +1,72 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// SignatureHeader carries "t=<unix seconds>,v1=<hex HMAC-SHA256>"
+	SignatureHeader = "X-Madabank-Signature"
+	EventHeader     = "X-Madabank-Event"
+
+	httpTimeout = 5 * time.Second
+)
+
+// Sender delivers signed JSON events to a receiver's URL. It returns the
+// receiver's HTTP status, or 0 when the receiver did not answer.
+type Sender interface {
+	Send(ctx context.Context, url, secret, event string, payload []byte) (int, error)
+}
+
+// Sign computes the signature receivers recompute to verify a delivery.
+// The timestamp is part of the signed content so old deliveries cannot be replayed.
+func Sign(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, hex.EncodeToString(mac.Sum(nil)))
+}
+
+// HTTPSender posts events over HTTPS and treats any 2xx answer as delivered
+type HTTPSender struct {
+	client *http.Client
+	now    func() time.Time
+}
+
+func NewHTTPSender() *HTTPSender {
+	return &HTTPSender{
+		client: &http.Client{Timeout: httpTimeout},
+		now:    time.Now,
+	}
+}
+
+func (s *HTTPSender) Send(ctx context.Context, url, secret, event string, payload []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event)
+	req.Header.Set(SignatureHeader, Sign(secret, s.now().Unix(), payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook receiver returned status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
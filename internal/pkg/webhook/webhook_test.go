@@ -0,0 +1,72 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSign(t *testing.T) {
+	payload := []byte(`{"event":"payment.received"}`)
+
+	sig := Sign("secret", 1700000000, payload)
+
+	assert.Regexp(t, `^t=1700000000,v1=[0-9a-f]{64}$`, sig)
+	assert.Equal(t, sig, Sign("secret", 1700000000, payload))
+	assert.NotEqual(t, sig, Sign("other", 1700000000, payload))
+	assert.NotEqual(t, sig, Sign("secret", 1700000001, payload))
+}
+
+func TestHTTPSender_Send(t *testing.T) {
+	payload := []byte(`{"amount":150000}`)
+	now := time.Unix(1700000000, 0)
+
+	var gotSig, gotEvent string
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSig = r.Header.Get(SignatureHeader)
+		gotEvent = r.Header.Get(EventHeader)
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sender := NewHTTPSender()
+	sender.now = func() time.Time { return now }
+
+	status, err := sender.Send(context.Background(), server.URL, "secret", "payment.received", payload)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, status)
+	assert.Equal(t, Sign("secret", now.Unix(), payload), gotSig)
+	assert.Equal(t, "payment.received", gotEvent)
+	assert.Equal(t, payload, gotBody)
+}
+
+func TestHTTPSender_Send_ReceiverError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	status, err := NewHTTPSender().Send(context.Background(), server.URL, "secret", "payment.received", []byte(`{}`))
+
+	assert.EqualError(t, err, "webhook receiver returned status 500")
+	assert.Equal(t, http.StatusInternalServerError, status)
+}
+
+func TestHTTPSender_Send_NoAnswer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	status, err := NewHTTPSender().Send(context.Background(), url, "secret", "payment.received", []byte(`{}`))
+
+	assert.Error(t, err)
+	assert.Equal(t, 0, status)
+}
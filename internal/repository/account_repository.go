@@ -8,15 +8,36 @@ import (
 
 	"github.com/darisadam/madabank-server/internal/domain/account"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type AccountRepository interface {
 	Create(acc *account.Account) error
 	GetByID(id uuid.UUID) (*account.Account, error)
+	// GetByIDs loads the accounts in one query. Unknown and closed accounts
+	// are left out, and the order is unspecified.
+	GetByIDs(ids []uuid.UUID) ([]*account.Account, error)
 	GetByAccountNumber(accountNumber string) (*account.Account, error)
 	GetByUserID(userID uuid.UUID) ([]*account.Account, error)
-	Update(id uuid.UUID, updates map[string]interface{}) error
-	UpdateBalance(id uuid.UUID, newBalance float64) error
+	// ListByUser returns a page of the user's accounts matching the filter
+	ListByUser(f *account.ListFilter) ([]*account.Account, error)
+	CountByUser(f *account.ListFilter) (int, error)
+	// GetHolders loads the accounts with their owners' names in one query.
+	// Unknown IDs are left out.
+	GetHolders(ids []uuid.UUID) ([]*account.Holder, error)
+	// Update and UpdateBalance only apply while the account is still at
+	// version, and fail with account.ErrVersionConflict once it has moved on
+	Update(id uuid.UUID, version int, updates map[string]interface{}) error
+	UpdateBalance(id uuid.UUID, version int, newBalance float64) error
+	// AddCurrency opens a zero balance in a currency other than the
+	// account's own
+	AddCurrency(accountID uuid.UUID, currency string) error
+	// ListBalances returns the balances in the currencies besides the
+	// account's own, by currency code
+	ListBalances(accountID uuid.UUID) ([]*account.CurrencyBalance, error)
+	// SumActiveHolds returns the total of the open holds on the account,
+	// which its ledger balance still includes
+	SumActiveHolds(accountID uuid.UUID) (float64, error)
 	Delete(id uuid.UUID) error
 	GenerateAccountNumber() (string, error)
 }
@@ -58,7 +79,7 @@ func (r *accountRepository) Create(acc *account.Account) error {
 func (r *accountRepository) GetByID(id uuid.UUID) (*account.Account, error) {
 	query := `
 		SELECT id, user_id, account_number, account_type, balance, currency, 
-		       interest_rate, status, created_at, updated_at
+		       interest_rate, overdraft_limit, status, freeze_reason, version, created_at, updated_at
 		FROM accounts
 		WHERE id = $1 AND status != 'closed'
 	`
@@ -72,7 +93,10 @@ func (r *accountRepository) GetByID(id uuid.UUID) (*account.Account, error) {
 		&acc.Balance,
 		&acc.Currency,
 		&acc.InterestRate,
+		&acc.OverdraftLimit,
 		&acc.Status,
+		&acc.FreezeReason,
+		&acc.Version,
 		&acc.CreatedAt,
 		&acc.UpdatedAt,
 	)
@@ -87,10 +111,55 @@ func (r *accountRepository) GetByID(id uuid.UUID) (*account.Account, error) {
 	return acc, nil
 }
 
+func (r *accountRepository) GetByIDs(ids []uuid.UUID) ([]*account.Account, error) {
+	accounts := []*account.Account{}
+	if len(ids) == 0 {
+		return accounts, nil
+	}
+
+	rows, err := r.db.Query(`
+		SELECT id, user_id, account_number, account_type, balance, currency,
+		       interest_rate, overdraft_limit, status, freeze_reason, version, created_at, updated_at
+		FROM accounts
+		WHERE id = ANY($1::uuid[]) AND status != 'closed'
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get accounts: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	for rows.Next() {
+		acc := &account.Account{}
+		err := rows.Scan(
+			&acc.ID,
+			&acc.UserID,
+			&acc.AccountNumber,
+			&acc.AccountType,
+			&acc.Balance,
+			&acc.Currency,
+			&acc.InterestRate,
+			&acc.OverdraftLimit,
+			&acc.Status,
+			&acc.FreezeReason,
+			&acc.Version,
+			&acc.CreatedAt,
+			&acc.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan account: %w", err)
+		}
+		accounts = append(accounts, acc)
+	}
+
+	return accounts, rows.Err()
+}
+
 func (r *accountRepository) GetByAccountNumber(accountNumber string) (*account.Account, error) {
 	query := `
 		SELECT id, user_id, account_number, account_type, balance, currency,
-		       interest_rate, status, created_at, updated_at
+		       interest_rate, overdraft_limit, status, freeze_reason, version, created_at, updated_at
 		FROM accounts
 		WHERE account_number = $1 AND status != 'closed'
 	`
@@ -104,7 +173,10 @@ func (r *accountRepository) GetByAccountNumber(accountNumber string) (*account.A
 		&acc.Balance,
 		&acc.Currency,
 		&acc.InterestRate,
+		&acc.OverdraftLimit,
 		&acc.Status,
+		&acc.FreezeReason,
+		&acc.Version,
 		&acc.CreatedAt,
 		&acc.UpdatedAt,
 	)
@@ -122,7 +194,7 @@ func (r *accountRepository) GetByAccountNumber(accountNumber string) (*account.A
 func (r *accountRepository) GetByUserID(userID uuid.UUID) ([]*account.Account, error) {
 	query := `
 		SELECT id, user_id, account_number, account_type, balance, currency,
-		       interest_rate, status, created_at, updated_at
+		       interest_rate, overdraft_limit, status, freeze_reason, version, created_at, updated_at
 		FROM accounts
 		WHERE user_id = $1 AND status != 'closed'
 		ORDER BY created_at DESC
@@ -147,7 +219,10 @@ func (r *accountRepository) GetByUserID(userID uuid.UUID) ([]*account.Account, e
 			&acc.Balance,
 			&acc.Currency,
 			&acc.InterestRate,
+			&acc.OverdraftLimit,
 			&acc.Status,
+			&acc.FreezeReason,
+			&acc.Version,
 			&acc.CreatedAt,
 			&acc.UpdatedAt,
 		)
@@ -160,7 +235,110 @@ func (r *accountRepository) GetByUserID(userID uuid.UUID) ([]*account.Account, e
 	return accounts, nil
 }
 
-func (r *accountRepository) Update(id uuid.UUID, updates map[string]interface{}) error {
+func (r *accountRepository) ListByUser(f *account.ListFilter) ([]*account.Account, error) {
+	where, args := accountListClause(f)
+	query := `
+		SELECT id, user_id, account_number, account_type, balance, currency,
+		       interest_rate, overdraft_limit, status, freeze_reason, version, created_at, updated_at
+		FROM accounts
+		WHERE ` + where + fmt.Sprintf(" ORDER BY %s LIMIT $%d OFFSET $%d", f.OrderBy(), len(args)+1, len(args)+2)
+	args = append(args, f.Limit, f.Offset)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accounts: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	accounts := []*account.Account{}
+	for rows.Next() {
+		acc := &account.Account{}
+		err := rows.Scan(
+			&acc.ID,
+			&acc.UserID,
+			&acc.AccountNumber,
+			&acc.AccountType,
+			&acc.Balance,
+			&acc.Currency,
+			&acc.InterestRate,
+			&acc.OverdraftLimit,
+			&acc.Status,
+			&acc.FreezeReason,
+			&acc.Version,
+			&acc.CreatedAt,
+			&acc.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan account: %w", err)
+		}
+		accounts = append(accounts, acc)
+	}
+
+	return accounts, rows.Err()
+}
+
+func (r *accountRepository) CountByUser(f *account.ListFilter) (int, error) {
+	where, args := accountListClause(f)
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM accounts WHERE `+where, args...).Scan(&total); err != nil {
+		return 0, fmt.Errorf("failed to count accounts: %w", err)
+	}
+	return total, nil
+}
+
+// accountListClause builds the WHERE clause shared by ListByUser and its count
+func accountListClause(f *account.ListFilter) (string, []interface{}) {
+	where := "user_id = $1"
+	args := []interface{}{f.UserID}
+
+	if f.Status != "" {
+		args = append(args, f.Status)
+		where += fmt.Sprintf(" AND status = $%d", len(args))
+	} else {
+		where += " AND status != 'closed'"
+	}
+
+	if f.Type != "" {
+		args = append(args, f.Type)
+		where += fmt.Sprintf(" AND account_type = $%d", len(args))
+	}
+
+	return where, args
+}
+
+func (r *accountRepository) GetHolders(ids []uuid.UUID) ([]*account.Holder, error) {
+	holders := []*account.Holder{}
+	if len(ids) == 0 {
+		return holders, nil
+	}
+
+	rows, err := r.db.Query(`
+		SELECT a.id, a.user_id, a.account_number, u.first_name, u.last_name
+		FROM accounts a JOIN users u ON u.id = a.user_id
+		WHERE a.id = ANY($1::uuid[])
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account holders: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	for rows.Next() {
+		h := &account.Holder{}
+		if err := rows.Scan(&h.AccountID, &h.UserID, &h.AccountNumber, &h.FirstName, &h.LastName); err != nil {
+			return nil, fmt.Errorf("failed to scan account holder: %w", err)
+		}
+		holders = append(holders, h)
+	}
+
+	return holders, rows.Err()
+}
+
+func (r *accountRepository) Update(id uuid.UUID, version int, updates map[string]interface{}) error {
 	query := "UPDATE accounts SET "
 	args := []interface{}{}
 	argPos := 1
@@ -174,8 +352,8 @@ func (r *accountRepository) Update(id uuid.UUID, updates map[string]interface{})
 		argPos++
 	}
 
-	query += fmt.Sprintf(", updated_at = CURRENT_TIMESTAMP WHERE id = $%d AND status != 'closed'", argPos)
-	args = append(args, id)
+	query += fmt.Sprintf(", updated_at = CURRENT_TIMESTAMP WHERE id = $%d AND version = $%d AND status != 'closed'", argPos, argPos+1)
+	args = append(args, id, version)
 
 	result, err := r.db.Exec(query, args...)
 	if err != nil {
@@ -188,20 +366,20 @@ func (r *accountRepository) Update(id uuid.UUID, updates map[string]interface{})
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("account not found or already closed")
+		return r.updateMissed(id, "status != 'closed'", fmt.Errorf("account not found or already closed"))
 	}
 
 	return nil
 }
 
-func (r *accountRepository) UpdateBalance(id uuid.UUID, newBalance float64) error {
+func (r *accountRepository) UpdateBalance(id uuid.UUID, version int, newBalance float64) error {
 	query := `
 		UPDATE accounts 
 		SET balance = $1, updated_at = CURRENT_TIMESTAMP 
-		WHERE id = $2 AND status = 'active'
+		WHERE id = $2 AND version = $3 AND status = 'active'
 	`
 
-	result, err := r.db.Exec(query, newBalance, id)
+	result, err := r.db.Exec(query, newBalance, id, version)
 	if err != nil {
 		return fmt.Errorf("failed to update balance: %w", err)
 	}
@@ -212,12 +390,77 @@ func (r *accountRepository) UpdateBalance(id uuid.UUID, newBalance float64) erro
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("account not found or not active")
+		return r.updateMissed(id, "status = 'active'", fmt.Errorf("account not found or not active"))
+	}
+
+	return nil
+}
+
+// updateMissed explains why a compare-and-swap update touched no row: the
+// account still matches condition, so another request changed it first, or
+// it is gone and the update fails with notFound
+func (r *accountRepository) updateMissed(id uuid.UUID, condition string, notFound error) error {
+	var exists bool
+	err := r.db.QueryRow("SELECT EXISTS(SELECT 1 FROM accounts WHERE id = $1 AND "+condition+")", id).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check account: %w", err)
+	}
+	if exists {
+		return account.ErrVersionConflict
+	}
+	return notFound
+}
+
+func (r *accountRepository) AddCurrency(accountID uuid.UUID, currency string) error {
+	result, err := r.db.Exec(`
+		INSERT INTO account_balances (account_id, currency) VALUES ($1, $2)
+		ON CONFLICT (account_id, currency) DO NOTHING
+	`, accountID, currency)
+	if err != nil {
+		return fmt.Errorf("failed to add currency: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("account already holds %s", currency)
 	}
 
 	return nil
 }
 
+func (r *accountRepository) ListBalances(accountID uuid.UUID) ([]*account.CurrencyBalance, error) {
+	rows, err := r.db.Query(`SELECT currency, balance FROM account_balances WHERE account_id = $1 ORDER BY currency`, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list balances: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	balances := []*account.CurrencyBalance{}
+	for rows.Next() {
+		var code string
+		var amount float64
+		if err := rows.Scan(&code, &amount); err != nil {
+			return nil, fmt.Errorf("failed to scan balance: %w", err)
+		}
+		balances = append(balances, account.NewCurrencyBalance(code, amount))
+	}
+
+	return balances, rows.Err()
+}
+
+func (r *accountRepository) SumActiveHolds(accountID uuid.UUID) (float64, error) {
+	var held float64
+	if err := r.db.QueryRow(`SELECT `+accountHoldsSum, accountID).Scan(&held); err != nil {
+		return 0, fmt.Errorf("failed to sum holds: %w", err)
+	}
+	return held, nil
+}
+
 func (r *accountRepository) Delete(id uuid.UUID) error {
 	// Soft delete by setting status to closed
 	query := `UPDATE accounts SET status = 'closed', updated_at = CURRENT_TIMESTAMP WHERE id = $1`
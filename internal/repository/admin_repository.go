@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/card"
+	"github.com/google/uuid"
+)
+
+// AdminRepository holds maintenance queries used by the operations CLI.
+// They scan whole tables and are not meant for request paths.
+type AdminRepository interface {
+	LedgerBalances() ([]*account.LedgerBalance, error)
+	ListCardsAfter(afterID uuid.UUID, limit int) ([]*card.Card, error)
+	ListCardTokensAfter(afterID uuid.UUID, limit int) ([]*card.Token, error)
+	UpdateCardTokenNumber(id uuid.UUID, numberEncrypted, numberHash string) error
+	CountUsers() (total int, active int, err error)
+	CountActiveAccountsByType() (map[account.AccountType]int, error)
+	SumActiveBalances() ([]*account.BalanceTotal, error)
+	PendingTransactions() (count int, oldest *time.Time, err error)
+}
+
+type adminRepository struct {
+	db *sql.DB
+}
+
+func NewAdminRepository(db *sql.DB) AdminRepository {
+	return &adminRepository{db: db}
+}
+
+// LedgerBalances recomputes every account balance from its transactions.
+// Pending transactions count as debits: bill payments and top-ups take the
+// money up front and only give it back when reversed. Archived months count
+// through their recorded per-account totals. Only the account's own currency
+// is reconciled; movements in the other currencies it holds are left out.
+func (r *adminRepository) LedgerBalances() ([]*account.LedgerBalance, error) {
+	query := `
+		SELECT a.id, a.account_number, a.balance,
+		       COALESCE(SUM(CASE WHEN t.to_account_id = a.id AND t.status = 'completed' THEN t.amount ELSE 0 END), 0) -
+		       COALESCE(SUM(CASE WHEN t.from_account_id = a.id THEN t.amount ELSE 0 END), 0) +
+		       COALESCE((SELECT SUM(b.credits - b.debits) FROM transaction_archive_balances b WHERE b.account_id = a.id), 0)
+		FROM accounts a
+		LEFT JOIN transactions t
+		       ON (t.to_account_id = a.id OR t.from_account_id = a.id) AND t.status IN ('completed', 'pending')
+		      AND COALESCE(t.metadata->>'currency', a.currency) = a.currency
+		GROUP BY a.id, a.account_number, a.balance
+		ORDER BY a.account_number
+	`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute ledger balances: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var balances []*account.LedgerBalance
+	for rows.Next() {
+		b := &account.LedgerBalance{}
+		if err := rows.Scan(&b.AccountID, &b.AccountNumber, &b.StoredBalance, &b.LedgerBalance); err != nil {
+			return nil, fmt.Errorf("failed to scan ledger balance: %w", err)
+		}
+		balances = append(balances, b)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate ledger balances: %w", err)
+	}
+
+	return balances, nil
+}
+
+// ListCardsAfter pages through every card (including blocked and expired) by id
+func (r *adminRepository) ListCardsAfter(afterID uuid.UUID, limit int) ([]*card.Card, error) {
+	query := `
+		SELECT ` + cardColumns + `
+		FROM cards
+		WHERE id > $1
+		ORDER BY id
+		LIMIT $2
+	`
+
+	rows, err := r.db.Query(query, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cards: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var cards []*card.Card
+	for rows.Next() {
+		c, err := scanCard(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan card: %w", err)
+		}
+		cards = append(cards, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate cards: %w", err)
+	}
+
+	return cards, nil
+}
+
+// ListCardTokensAfter pages through every wallet token (including deleted) by id
+func (r *adminRepository) ListCardTokensAfter(afterID uuid.UUID, limit int) ([]*card.Token, error) {
+	query := `
+		SELECT ` + cardTokenColumns + `
+		FROM card_tokens
+		WHERE id > $1
+		ORDER BY id
+		LIMIT $2
+	`
+
+	rows, err := r.db.Query(query, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list card tokens: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var tokens []*card.Token
+	for rows.Next() {
+		t, err := scanCardToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan card token: %w", err)
+		}
+		tokens = append(tokens, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate card tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// UpdateCardTokenNumber stores a token number re-encrypted under a new data key
+func (r *adminRepository) UpdateCardTokenNumber(id uuid.UUID, numberEncrypted, numberHash string) error {
+	_, err := r.db.Exec(`
+		UPDATE card_tokens SET token_number_encrypted = $1, token_number_hash = $2 WHERE id = $3
+	`, numberEncrypted, numberHash, id)
+	if err != nil {
+		return fmt.Errorf("failed to update card token number: %w", err)
+	}
+
+	return nil
+}
+
+// CountUsers counts registered users and those of them that are active
+func (r *adminRepository) CountUsers() (int, int, error) {
+	var total, active int
+	err := r.db.QueryRow(`
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE is_active = true)
+		FROM users
+		WHERE deleted_at IS NULL
+	`).Scan(&total, &active)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	return total, active, nil
+}
+
+// CountActiveAccountsByType counts active accounts of each account type
+func (r *adminRepository) CountActiveAccountsByType() (map[account.AccountType]int, error) {
+	rows, err := r.db.Query(`SELECT account_type, COUNT(*) FROM accounts WHERE status = 'active' GROUP BY account_type`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count accounts: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	counts := map[account.AccountType]int{}
+	for rows.Next() {
+		var accountType account.AccountType
+		var count int
+		if err := rows.Scan(&accountType, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan account count: %w", err)
+		}
+		counts[accountType] = count
+	}
+
+	return counts, rows.Err()
+}
+
+// SumActiveBalances totals the balances of active accounts by type and
+// currency
+func (r *adminRepository) SumActiveBalances() ([]*account.BalanceTotal, error) {
+	rows, err := r.db.Query(`
+		SELECT account_type, currency, COALESCE(SUM(balance), 0)
+		FROM accounts
+		WHERE status = 'active'
+		GROUP BY account_type, currency
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum balances: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var totals []*account.BalanceTotal
+	for rows.Next() {
+		t := &account.BalanceTotal{}
+		if err := rows.Scan(&t.AccountType, &t.Currency, &t.Total); err != nil {
+			return nil, fmt.Errorf("failed to scan balance total: %w", err)
+		}
+		totals = append(totals, t)
+	}
+
+	return totals, rows.Err()
+}
+
+// PendingTransactions counts pending transactions and finds the oldest one
+func (r *adminRepository) PendingTransactions() (int, *time.Time, error) {
+	var count int
+	var oldest sql.NullTime
+	err := r.db.QueryRow(`
+		SELECT COUNT(*), MIN(created_at)
+		FROM transactions
+		WHERE status = 'pending'
+	`).Scan(&count, &oldest)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to count pending transactions: %w", err)
+	}
+	if !oldest.Valid {
+		return count, nil, nil
+	}
+	return count, &oldest.Time, nil
+}
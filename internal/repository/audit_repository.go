@@ -4,12 +4,18 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/darisadam/madabank-server/internal/domain/audit"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"go.uber.org/zap"
 )
 
 type AuditRepository interface {
 	Create(log *audit.AuditLog) error
+	ListOlderThan(cutoff time.Time, limit int) ([]*audit.AuditLog, error)
+	ListChain(afterID int64, limit int) ([]*audit.AuditLog, error)
+	DeleteOlderThan(cutoff time.Time, maxID int64) (int64, error)
 }
 
 type auditRepository struct {
@@ -20,21 +26,45 @@ func NewAuditRepository(db *sql.DB) AuditRepository {
 	return &auditRepository{db: db}
 }
 
+// Create appends the log to the tamper-evident hash chain. The chain head row
+// is locked for the duration of the insert so concurrent writers are serialized.
 func (r *auditRepository) Create(log *audit.AuditLog) error {
+	if log.Timestamp.IsZero() {
+		log.Timestamp = time.Now().UTC().Truncate(time.Microsecond)
+	}
+	log.IPAddress = audit.NormalizeIP(log.IPAddress)
+
 	requestJSON, _ := json.Marshal(log.RequestBody)
 	responseJSON, _ := json.Marshal(log.ResponseBody)
 	metadataJSON, _ := json.Marshal(log.Metadata)
 
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var prevHash string
+	if err := tx.QueryRow(`SELECT last_hash FROM audit_chain_head WHERE id = 1 FOR UPDATE`).Scan(&prevHash); err != nil {
+		return fmt.Errorf("failed to lock audit chain: %w", err)
+	}
+
+	hash, err := log.ComputeHash(prevHash)
+	if err != nil {
+		return err
+	}
+
 	query := `
-		INSERT INTO audit_logs (event_id, user_id, action, resource, ip_address, 
-		                       user_agent, status, request_body, response_body, metadata)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-		RETURNING id, timestamp
+		INSERT INTO audit_logs (event_id, timestamp, user_id, action, resource, ip_address,
+		                       user_agent, status, request_body, response_body, metadata, prev_hash, hash)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, '')::inet, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id
 	`
 
-	err := r.db.QueryRow(
+	err = tx.QueryRow(
 		query,
 		log.EventID,
+		log.Timestamp,
 		log.UserID,
 		log.Action,
 		log.Resource,
@@ -44,11 +74,143 @@ func (r *auditRepository) Create(log *audit.AuditLog) error {
 		requestJSON,
 		responseJSON,
 		metadataJSON,
-	).Scan(&log.ID, &log.Timestamp)
+		prevHash,
+		hash,
+	).Scan(&log.ID)
 
 	if err != nil {
 		return fmt.Errorf("failed to create audit log: %w", err)
 	}
 
+	if _, err := tx.Exec(`UPDATE audit_chain_head SET last_hash = $1, last_id = $2 WHERE id = 1`, hash, log.ID); err != nil {
+		return fmt.Errorf("failed to advance audit chain: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit audit log: %w", err)
+	}
+
+	log.PrevHash = prevHash
+	log.Hash = hash
+
+	// Mirror the committed record to the audit log stream
+	logger.Audit(log.Action, auditLogFields(log)...)
 	return nil
 }
+
+func auditLogFields(log *audit.AuditLog) []zap.Field {
+	fields := []zap.Field{
+		zap.Int64("audit_id", log.ID),
+		zap.String("event_id", log.EventID.String()),
+		zap.Time("timestamp", log.Timestamp),
+		zap.String("resource", log.Resource),
+		zap.String("status", log.Status),
+		zap.String("hash", log.Hash),
+	}
+	if log.UserID != nil {
+		fields = append(fields, zap.String("user_id", log.UserID.String()))
+	}
+	if log.IPAddress != "" {
+		fields = append(fields, zap.String("ip_address", log.IPAddress))
+	}
+	if len(log.Metadata) > 0 {
+		fields = append(fields, zap.Any("metadata", log.Metadata))
+	}
+	return fields
+}
+
+const auditLogColumns = `id, event_id, timestamp, user_id, action, resource, host(ip_address),
+		       user_agent, status, request_body, response_body, metadata,
+		       COALESCE(prev_hash, ''), COALESCE(hash, '')`
+
+// ListOlderThan returns the oldest audit logs written before cutoff, in id order
+func (r *auditRepository) ListOlderThan(cutoff time.Time, limit int) ([]*audit.AuditLog, error) {
+	query := `
+		SELECT ` + auditLogColumns + `
+		FROM audit_logs
+		WHERE timestamp < $1
+		ORDER BY id
+		LIMIT $2
+	`
+
+	return r.queryLogs(query, cutoff, limit)
+}
+
+// ListChain returns hashed audit logs with id greater than afterID, in chain order
+func (r *auditRepository) ListChain(afterID int64, limit int) ([]*audit.AuditLog, error) {
+	query := `
+		SELECT ` + auditLogColumns + `
+		FROM audit_logs
+		WHERE id > $1 AND hash IS NOT NULL
+		ORDER BY id
+		LIMIT $2
+	`
+
+	return r.queryLogs(query, afterID, limit)
+}
+
+func (r *auditRepository) queryLogs(query string, args ...interface{}) ([]*audit.AuditLog, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var logs []*audit.AuditLog
+	for rows.Next() {
+		log := &audit.AuditLog{}
+		var resource, ipAddress, userAgent, status sql.NullString
+		var requestJSON, responseJSON, metadataJSON []byte
+
+		if err := rows.Scan(
+			&log.ID,
+			&log.EventID,
+			&log.Timestamp,
+			&log.UserID,
+			&log.Action,
+			&resource,
+			&ipAddress,
+			&userAgent,
+			&status,
+			&requestJSON,
+			&responseJSON,
+			&metadataJSON,
+			&log.PrevHash,
+			&log.Hash,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan audit log: %w", err)
+		}
+
+		log.Resource = resource.String
+		log.IPAddress = ipAddress.String
+		log.UserAgent = userAgent.String
+		log.Status = status.String
+		_ = json.Unmarshal(requestJSON, &log.RequestBody)
+		_ = json.Unmarshal(responseJSON, &log.ResponseBody)
+		_ = json.Unmarshal(metadataJSON, &log.Metadata)
+
+		logs = append(logs, log)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate audit logs: %w", err)
+	}
+
+	return logs, nil
+}
+
+// DeleteOlderThan prunes audit logs written before cutoff with id <= maxID,
+// so only rows that were already exported are removed.
+func (r *auditRepository) DeleteOlderThan(cutoff time.Time, maxID int64) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM audit_logs WHERE timestamp < $1 AND id <= $2`, cutoff, maxID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete audit logs: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return deleted, nil
+}
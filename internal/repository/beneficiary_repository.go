@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/beneficiary"
+	"github.com/google/uuid"
+)
+
+type BeneficiaryRepository interface {
+	Create(b *beneficiary.Beneficiary) error
+	GetByID(id uuid.UUID) (*beneficiary.Beneficiary, error)
+	ListByUser(userID uuid.UUID) ([]*beneficiary.Beneficiary, error)
+	// GetByAccountID returns the user's saved recipient that resolves to the
+	// account, preferring verified and older entries
+	GetByAccountID(userID, accountID uuid.UUID) (*beneficiary.Beneficiary, error)
+	UpdateNickname(id uuid.UUID, nickname string) error
+	// UpdateVerification records the outcome of resolving the recipient
+	UpdateVerification(b *beneficiary.Beneficiary) error
+	MarkUsed(id uuid.UUID, at time.Time) error
+	Delete(id uuid.UUID) error
+}
+
+type beneficiaryRepository struct {
+	db *sql.DB
+}
+
+func NewBeneficiaryRepository(db *sql.DB) BeneficiaryRepository {
+	return &beneficiaryRepository{db: db}
+}
+
+const beneficiaryColumns = `id, user_id, beneficiary_type, nickname, COALESCE(account_number, ''), COALESCE(phone_number, ''),
+	COALESCE(bank_code, ''), holder_name, account_id, verification_status, verified_at, last_used_at, created_at, updated_at`
+
+func scanBeneficiary(row rowScanner) (*beneficiary.Beneficiary, error) {
+	b := &beneficiary.Beneficiary{}
+	err := row.Scan(
+		&b.ID, &b.UserID, &b.Type, &b.Nickname, &b.AccountNumber, &b.PhoneNumber,
+		&b.BankCode, &b.HolderName, &b.AccountID, &b.VerificationStatus, &b.VerifiedAt, &b.LastUsedAt, &b.CreatedAt, &b.UpdatedAt,
+	)
+	return b, err
+}
+
+func (r *beneficiaryRepository) Create(b *beneficiary.Beneficiary) error {
+	err := r.db.QueryRow(`
+		INSERT INTO beneficiaries (id, user_id, beneficiary_type, nickname, account_number, phone_number, bank_code,
+			holder_name, account_id, verification_status, verified_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''), $8, $9, $10, $11)
+		RETURNING created_at, updated_at
+	`, b.ID, b.UserID, b.Type, b.Nickname, b.AccountNumber, b.PhoneNumber, b.BankCode,
+		b.HolderName, b.AccountID, b.VerificationStatus, utcTime(b.VerifiedAt),
+	).Scan(&b.CreatedAt, &b.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create beneficiary: %w", err)
+	}
+	return nil
+}
+
+func (r *beneficiaryRepository) GetByID(id uuid.UUID) (*beneficiary.Beneficiary, error) {
+	return r.getOne(`SELECT `+beneficiaryColumns+` FROM beneficiaries WHERE id = $1`, id)
+}
+
+func (r *beneficiaryRepository) GetByAccountID(userID, accountID uuid.UUID) (*beneficiary.Beneficiary, error) {
+	return r.getOne(`
+		SELECT `+beneficiaryColumns+` FROM beneficiaries
+		WHERE user_id = $1 AND account_id = $2
+		ORDER BY verification_status = 'verified' DESC, created_at
+		LIMIT 1
+	`, userID, accountID)
+}
+
+func (r *beneficiaryRepository) getOne(query string, args ...interface{}) (*beneficiary.Beneficiary, error) {
+	b, err := scanBeneficiary(r.db.QueryRow(query, args...))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("beneficiary not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get beneficiary: %w", err)
+	}
+	return b, nil
+}
+
+func (r *beneficiaryRepository) ListByUser(userID uuid.UUID) ([]*beneficiary.Beneficiary, error) {
+	rows, err := r.db.Query(`
+		SELECT `+beneficiaryColumns+` FROM beneficiaries
+		WHERE user_id = $1
+		ORDER BY last_used_at DESC NULLS LAST, nickname
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list beneficiaries: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	beneficiaries := []*beneficiary.Beneficiary{}
+	for rows.Next() {
+		b, err := scanBeneficiary(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan beneficiary: %w", err)
+		}
+		beneficiaries = append(beneficiaries, b)
+	}
+
+	return beneficiaries, rows.Err()
+}
+
+func (r *beneficiaryRepository) UpdateNickname(id uuid.UUID, nickname string) error {
+	return r.exec(`UPDATE beneficiaries SET nickname = $1 WHERE id = $2`, nickname, id)
+}
+
+func (r *beneficiaryRepository) UpdateVerification(b *beneficiary.Beneficiary) error {
+	return r.exec(`
+		UPDATE beneficiaries SET holder_name = $1, account_id = $2, verification_status = $3, verified_at = $4
+		WHERE id = $5
+	`, b.HolderName, b.AccountID, b.VerificationStatus, utcTime(b.VerifiedAt), b.ID)
+}
+
+func (r *beneficiaryRepository) MarkUsed(id uuid.UUID, at time.Time) error {
+	return r.exec(`UPDATE beneficiaries SET last_used_at = $1 WHERE id = $2`, at.UTC(), id)
+}
+
+func (r *beneficiaryRepository) Delete(id uuid.UUID) error {
+	return r.exec(`DELETE FROM beneficiaries WHERE id = $1`, id)
+}
+
+func (r *beneficiaryRepository) exec(query string, args ...interface{}) error {
+	result, err := r.db.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update beneficiary: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("beneficiary not found")
+	}
+
+	return nil
+}
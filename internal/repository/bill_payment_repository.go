@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/darisadam/madabank-server/internal/domain/billpay"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/google/uuid"
+)
+
+type BillPaymentRepository interface {
+	ListBillers(category billpay.BillerCategory) ([]*billpay.Biller, error)
+	GetBiller(code string) (*billpay.Biller, error)
+
+	// ExecuteBillPayment debits the account and records a pending bill payment;
+	// the aggregator outcome is applied with CompleteBillPayment or ReverseBillPayment
+	ExecuteBillPayment(accountID uuid.UUID, amount float64, txn *transaction.Transaction) error
+	CompleteBillPayment(txnID uuid.UUID, billerReference string) error
+	ReverseBillPayment(txnID uuid.UUID, reason string) error
+}
+
+type billPaymentRepository struct {
+	db *sql.DB
+}
+
+func NewBillPaymentRepository(db *sql.DB) BillPaymentRepository {
+	return &billPaymentRepository{db: db}
+}
+
+const billerColumns = `code, name, category, customer_number_label, admin_fee, active, created_at`
+
+func scanBiller(row rowScanner) (*billpay.Biller, error) {
+	b := &billpay.Biller{}
+	err := row.Scan(&b.Code, &b.Name, &b.Category, &b.CustomerNumberLabel, &b.AdminFee, &b.Active, &b.CreatedAt)
+	return b, err
+}
+
+// ListBillers returns the active billers, optionally restricted to one category
+func (r *billPaymentRepository) ListBillers(category billpay.BillerCategory) ([]*billpay.Biller, error) {
+	query := `SELECT ` + billerColumns + ` FROM billers WHERE active AND ($1 = '' OR category = $1) ORDER BY category, name`
+
+	rows, err := r.db.Query(query, category)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list billers: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	billers := []*billpay.Biller{}
+	for rows.Next() {
+		b, err := scanBiller(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan biller: %w", err)
+		}
+		billers = append(billers, b)
+	}
+
+	return billers, rows.Err()
+}
+
+func (r *billPaymentRepository) GetBiller(code string) (*billpay.Biller, error) {
+	b, err := scanBiller(r.db.QueryRow(`SELECT `+billerColumns+` FROM billers WHERE code = $1`, code))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("biller not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get biller: %w", err)
+	}
+
+	return b, nil
+}
+
+func (r *billPaymentRepository) ExecuteBillPayment(accountID uuid.UUID, amount float64, txn *transaction.Transaction) error {
+	dbTx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = dbTx.Rollback() // Rollback if not committed
+	}()
+
+	held, err := lockBalance(dbTx, accountID, "")
+	if err != nil {
+		return fmt.Errorf("failed to lock account: %w", err)
+	}
+
+	if held.available < amount {
+		return insufficientFunds(held.available, amount)
+	}
+
+	if err := held.add(dbTx, -amount); err != nil {
+		return fmt.Errorf("failed to debit account: %w", err)
+	}
+
+	metadataJSON, _ := json.Marshal(txn.Metadata)
+	_, err = dbTx.Exec(`
+		INSERT INTO transactions (id, idempotency_key, from_account_id, amount, transaction_type, status, description, metadata)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, txn.ID, txn.IdempotencyKey, accountID, amount, txn.TransactionType, transaction.TransactionStatusPending, txn.Description, metadataJSON)
+	if err != nil {
+		return fmt.Errorf("failed to insert transaction: %w", err)
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// CompleteBillPayment marks a pending bill payment as settled at the biller
+func (r *billPaymentRepository) CompleteBillPayment(txnID uuid.UUID, billerReference string) error {
+	reference, _ := json.Marshal(map[string]string{"biller_reference": billerReference})
+
+	result, err := r.db.Exec(`
+		UPDATE transactions
+		SET status = $1, completed_at = CURRENT_TIMESTAMP, metadata = COALESCE(metadata, '{}'::jsonb) || $2::jsonb
+		WHERE id = $3 AND transaction_type = $4 AND status = $5
+	`, transaction.TransactionStatusCompleted, reference, txnID, transaction.TransactionTypeBillPayment, transaction.TransactionStatusPending)
+	if err != nil {
+		return fmt.Errorf("failed to complete bill payment: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("pending bill payment not found")
+	}
+
+	return nil
+}
+
+// ReverseBillPayment refunds a pending bill payment the biller rejected
+func (r *billPaymentRepository) ReverseBillPayment(txnID uuid.UUID, reason string) error {
+	dbTx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = dbTx.Rollback() // Rollback if not committed
+	}()
+
+	var accountID uuid.UUID
+	var amount float64
+	err = dbTx.QueryRow(`
+		SELECT from_account_id, amount FROM transactions
+		WHERE id = $1 AND transaction_type = $2 AND status = $3
+		FOR UPDATE
+	`, txnID, transaction.TransactionTypeBillPayment, transaction.TransactionStatusPending).Scan(&accountID, &amount)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("pending bill payment not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock bill payment: %w", err)
+	}
+
+	// The refund is credited even if the account was frozen in the meantime
+	_, err = dbTx.Exec(`UPDATE accounts SET balance = balance + $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`, amount, accountID)
+	if err != nil {
+		return fmt.Errorf("failed to refund account: %w", err)
+	}
+
+	note, _ := json.Marshal(map[string]string{"reversal_reason": reason})
+	_, err = dbTx.Exec(`
+		UPDATE transactions
+		SET status = $1, completed_at = CURRENT_TIMESTAMP, metadata = COALESCE(metadata, '{}'::jsonb) || $2::jsonb
+		WHERE id = $3
+	`, transaction.TransactionStatusReversed, note, txnID)
+	if err != nil {
+		return fmt.Errorf("failed to reverse bill payment: %w", err)
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/pkg/cache"
+	"github.com/google/uuid"
+)
+
+type cachedAccountRepository struct {
+	AccountRepository
+	cache *cache.Cache
+}
+
+// NewCachedAccountRepository serves GetByID and GetByIDs from the cache. Writes through
+// this repository drop the entry at once; balance changes made inside other
+// repositories' transactions are dropped by the cache's database listener.
+func NewCachedAccountRepository(inner AccountRepository, c *cache.Cache) AccountRepository {
+	return &cachedAccountRepository{AccountRepository: inner, cache: c}
+}
+
+func (r *cachedAccountRepository) GetByID(id uuid.UUID) (*account.Account, error) {
+	return cache.Fetch(context.Background(), r.cache, "accounts", id, func() (*account.Account, error) {
+		return r.AccountRepository.GetByID(id)
+	})
+}
+
+func (r *cachedAccountRepository) GetByIDs(ids []uuid.UUID) ([]*account.Account, error) {
+	idOf := func(acc *account.Account) uuid.UUID { return acc.ID }
+	return cache.FetchMany(context.Background(), r.cache, "accounts", ids, idOf, r.AccountRepository.GetByIDs)
+}
+
+func (r *cachedAccountRepository) Update(id uuid.UUID, version int, updates map[string]interface{}) error {
+	defer r.cache.Invalidate(context.Background(), "accounts", id)
+	return r.AccountRepository.Update(id, version, updates)
+}
+
+func (r *cachedAccountRepository) UpdateBalance(id uuid.UUID, version int, newBalance float64) error {
+	defer r.cache.Invalidate(context.Background(), "accounts", id)
+	return r.AccountRepository.UpdateBalance(id, version, newBalance)
+}
+
+func (r *cachedAccountRepository) Delete(id uuid.UUID) error {
+	defer r.cache.Invalidate(context.Background(), "accounts", id)
+	return r.AccountRepository.Delete(id)
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/darisadam/madabank-server/internal/domain/user"
+	"github.com/darisadam/madabank-server/internal/pkg/cache"
+	"github.com/google/uuid"
+)
+
+type cachedUserRepository struct {
+	UserRepository
+	cache *cache.Cache
+}
+
+// NewCachedUserRepository serves GetByID, which every authenticated profile
+// and ownership check goes through, from the cache. The cached user keeps
+// its password hash, as callers verify passwords against it.
+func NewCachedUserRepository(inner UserRepository, c *cache.Cache) UserRepository {
+	return &cachedUserRepository{UserRepository: inner, cache: c}
+}
+
+func (r *cachedUserRepository) GetByID(id uuid.UUID) (*user.User, error) {
+	return cache.Fetch(context.Background(), r.cache, "users", id, func() (*user.User, error) {
+		return r.UserRepository.GetByID(id)
+	})
+}
+
+func (r *cachedUserRepository) Update(id uuid.UUID, updates map[string]interface{}) error {
+	defer r.cache.Invalidate(context.Background(), "users", id)
+	return r.UserRepository.Update(id, updates)
+}
+
+func (r *cachedUserRepository) VerifyPhone(id uuid.UUID, phone string) error {
+	defer r.cache.Invalidate(context.Background(), "users", id)
+	return r.UserRepository.VerifyPhone(id, phone)
+}
+
+func (r *cachedUserRepository) Delete(id uuid.UUID) error {
+	defer r.cache.Invalidate(context.Background(), "users", id)
+	return r.UserRepository.Delete(id)
+}
+
+func (r *cachedUserRepository) Restore(id uuid.UUID) error {
+	defer r.cache.Invalidate(context.Background(), "users", id)
+	return r.UserRepository.Restore(id)
+}
+
+func (r *cachedUserRepository) Anonymize(id uuid.UUID) error {
+	defer r.cache.Invalidate(context.Background(), "users", id)
+	return r.UserRepository.Anonymize(id)
+}
@@ -0,0 +1,279 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/card"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/google/uuid"
+)
+
+type CardAuthorizationRepository interface {
+	Create(a *card.Authorization) error
+	PlaceHold(a *card.Authorization, cardType card.CardType, dailyLimit float64, dayStart time.Time) (card.HoldOutcome, error)
+	GetByID(id uuid.UUID) (*card.Authorization, error)
+	// Capture settles amount, at most the authorized amount, of an open
+	// authorization and ends its hold, in one database transaction. A debit
+	// card's account is debited and txn recorded; a credit card's purchase is
+	// posted to its outstanding balance and current cycle, and txn is unused.
+	// It returns card.ErrAuthorizationNotOpen once captured, released or expired.
+	Capture(id uuid.UUID, amount float64, txn *transaction.Transaction) error
+}
+
+type cardAuthorizationRepository struct {
+	db    *sql.DB
+	hooks []DebitHook
+}
+
+// NewCardAuthorizationRepository returns the repository. The hooks run after
+// every debit card capture.
+func NewCardAuthorizationRepository(db *sql.DB, hooks ...DebitHook) CardAuthorizationRepository {
+	return &cardAuthorizationRepository{db: db, hooks: hooks}
+}
+
+const authorizationColumns = `id, card_id, account_id, token_id, amount, currency, status, response_code,
+	COALESCE(decline_reason, ''), COALESCE(auth_code, ''), COALESCE(merchant_name, ''), COALESCE(merchant_category_code, ''),
+	channel, COALESCE(acquirer_reference, ''), expires_at, captured_amount, transaction_id, captured_at, created_at`
+
+func (r *cardAuthorizationRepository) GetByID(id uuid.UUID) (*card.Authorization, error) {
+	a := &card.Authorization{}
+	err := r.db.QueryRow(`SELECT `+authorizationColumns+` FROM card_authorizations WHERE id = $1`, id).Scan(
+		&a.ID, &a.CardID, &a.AccountID, &a.TokenID, &a.Amount, &a.Currency, &a.Status, &a.ResponseCode,
+		&a.DeclineReason, &a.AuthCode, &a.MerchantName, &a.MerchantCategory,
+		&a.Channel, &a.AcquirerReference, &a.ExpiresAt, &a.CapturedAmount, &a.TransactionID, &a.CapturedAt, &a.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("authorization not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get card authorization: %w", err)
+	}
+	return a, nil
+}
+
+// Create records an authorization as-is (used for declines)
+func (r *cardAuthorizationRepository) Create(a *card.Authorization) error {
+	return insertAuthorization(r.db, a)
+}
+
+// accountHoldsSum is the total of the open holds on account $1: approved card
+// authorizations and active account holds, neither captured, released nor
+// expired
+const accountHoldsSum = `(COALESCE((
+	SELECT SUM(amount) FROM card_authorizations
+	WHERE account_id = $1 AND status = 'approved' AND expires_at > CURRENT_TIMESTAMP
+), 0) + COALESCE((
+	SELECT SUM(amount) FROM account_holds
+	WHERE account_id = $1 AND status = 'active' AND expires_at > CURRENT_TIMESTAMP
+), 0))`
+
+// availableBalance is what a debit may take from account $1 in its own
+// currency: the balance plus the overdraft limit, less its open holds
+const availableBalance = `balance + overdraft_limit - ` + accountHoldsSum
+
+// PlaceHold stores an approved authorization if it fits within the card's
+// daily limit (counting approved and captured authorizations since dayStart)
+// and the available funds. Debit cards draw on the account's available
+// balance, overdraft included; credit cards on the unused credit limit less the card's open
+// holds. The row being drawn on is locked first so concurrent authorizations
+// can neither overspend nor exceed the daily limit together.
+func (r *cardAuthorizationRepository) PlaceHold(a *card.Authorization, cardType card.CardType, dailyLimit float64, dayStart time.Time) (card.HoldOutcome, error) {
+	dbTx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = dbTx.Rollback() // Rollback if not committed
+	}()
+
+	var available float64
+	if cardType == card.CardTypeCredit {
+		err = dbTx.QueryRow(`
+			SELECT credit_limit - outstanding_balance - COALESCE((
+			           SELECT SUM(amount) FROM card_authorizations
+			           WHERE card_id = $1 AND status = 'approved' AND expires_at > CURRENT_TIMESTAMP
+			       ), 0)
+			FROM cards WHERE id = $1 FOR UPDATE
+		`, a.CardID).Scan(&available)
+	} else {
+		err = dbTx.QueryRow(`
+			SELECT `+availableBalance+`
+			FROM accounts WHERE id = $1 AND status = 'active' FOR UPDATE
+		`, a.AccountID).Scan(&available)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to lock funds for authorization: %w", err)
+	}
+
+	// created_at is stored in UTC without a zone, so compare against UTC
+	var spentToday float64
+	err = dbTx.QueryRow(`
+		SELECT COALESCE(SUM(amount), 0)
+		FROM card_authorizations
+		WHERE card_id = $1 AND status IN ('approved', 'captured') AND created_at >= $2
+	`, a.CardID, dayStart.UTC()).Scan(&spentToday)
+	if err != nil {
+		return 0, fmt.Errorf("failed to sum card daily spend: %w", err)
+	}
+
+	if spentToday+a.Amount > dailyLimit {
+		return card.HoldDailyLimitExceeded, nil
+	}
+	if available < a.Amount {
+		return card.HoldInsufficientFunds, nil
+	}
+
+	if err := insertAuthorization(dbTx, a); err != nil {
+		return 0, err
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return card.HoldPlaced, nil
+}
+
+func (r *cardAuthorizationRepository) Capture(id uuid.UUID, amount float64, txn *transaction.Transaction) error {
+	dbTx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = dbTx.Rollback() // Rollback if not committed
+	}()
+
+	var cardID, accountID uuid.UUID
+	var authorized float64
+	var open bool
+	var cardType card.CardType
+	err = dbTx.QueryRow(`
+		SELECT a.card_id, a.account_id, a.amount, a.status = 'approved' AND a.expires_at > CURRENT_TIMESTAMP, c.card_type
+		FROM card_authorizations a JOIN cards c ON c.id = a.card_id
+		WHERE a.id = $1 FOR UPDATE OF a
+	`, id).Scan(&cardID, &accountID, &authorized, &open, &cardType)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("authorization not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock card authorization: %w", err)
+	}
+	if !open {
+		return card.ErrAuthorizationNotOpen
+	}
+	if amount > authorized {
+		return fmt.Errorf("capture amount exceeds the authorized amount of %.2f", authorized)
+	}
+
+	var transactionID *uuid.UUID
+	if cardType == card.CardTypeCredit {
+		if err := capturePurchase(dbTx, cardID, authorized, amount); err != nil {
+			return err
+		}
+	} else {
+		if err := r.captureDebit(dbTx, accountID, authorized, amount, txn); err != nil {
+			return err
+		}
+		transactionID = &txn.ID
+	}
+
+	_, err = dbTx.Exec(`
+		UPDATE card_authorizations
+		SET status = $2, captured_amount = $3, transaction_id = $4, captured_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, id, card.AuthorizationStatusCaptured, amount, transactionID)
+	if err != nil {
+		return fmt.Errorf("failed to capture card authorization: %w", err)
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// capturePurchase posts a credit card purchase to the card's outstanding
+// balance and current cycle. The authorization's own hold is counted in the
+// card's open holds, so the purchase may use it.
+func capturePurchase(dbTx *sql.Tx, cardID uuid.UUID, authorized, amount float64) error {
+	var available float64
+	err := dbTx.QueryRow(`
+		SELECT credit_limit - outstanding_balance - COALESCE((
+		           SELECT SUM(amount) FROM card_authorizations
+		           WHERE card_id = $1 AND status = 'approved' AND expires_at > CURRENT_TIMESTAMP
+		       ), 0)
+		FROM cards WHERE id = $1 FOR UPDATE
+	`, cardID).Scan(&available)
+	if err != nil {
+		return fmt.Errorf("failed to lock card: %w", err)
+	}
+	if available+authorized < amount {
+		return insufficientFunds(available+authorized, amount)
+	}
+
+	_, err = dbTx.Exec(`
+		UPDATE cards SET outstanding_balance = outstanding_balance + $1, cycle_purchases = cycle_purchases + $1
+		WHERE id = $2
+	`, amount, cardID)
+	if err != nil {
+		return fmt.Errorf("failed to post card purchase: %w", err)
+	}
+
+	return nil
+}
+
+// captureDebit debits a debit card payment from the account and records txn.
+// The authorization's own hold is counted in the held funds; the capture may
+// use it but not what other holds reserve.
+func (r *cardAuthorizationRepository) captureDebit(dbTx *sql.Tx, accountID uuid.UUID, authorized, amount float64, txn *transaction.Transaction) error {
+	held, err := lockBalance(dbTx, accountID, "")
+	if err != nil {
+		return fmt.Errorf("failed to lock account: %w", err)
+	}
+	available := held.available + authorized
+	if available < amount {
+		return insufficientFunds(available, amount)
+	}
+
+	if err := held.add(dbTx, -amount); err != nil {
+		if errors.Is(err, transaction.ErrInsufficientFunds) {
+			return err
+		}
+		return fmt.Errorf("failed to debit account: %w", err)
+	}
+
+	metadataJSON, _ := json.Marshal(txn.Metadata)
+	_, err = dbTx.Exec(`
+		INSERT INTO transactions (id, idempotency_key, from_account_id, amount, transaction_type, status, description, metadata, completed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, CURRENT_TIMESTAMP)
+	`, txn.ID, txn.IdempotencyKey, accountID, amount, txn.TransactionType, transaction.TransactionStatusCompleted, txn.Description, metadataJSON)
+	if err != nil {
+		return fmt.Errorf("failed to insert transaction: %w", err)
+	}
+
+	txn.FromAccountID, txn.Amount = &accountID, amount
+	return runDebitHooks(dbTx, r.hooks, txn)
+}
+
+func insertAuthorization(q queryRower, a *card.Authorization) error {
+	err := q.QueryRow(`
+		INSERT INTO card_authorizations (id, card_id, account_id, token_id, amount, currency, status, response_code,
+		                                 decline_reason, auth_code, merchant_name, merchant_category_code,
+		                                 channel, acquirer_reference, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), NULLIF($10, ''), $11, $12, $13, NULLIF($14, ''), $15)
+		RETURNING created_at
+	`, a.ID, a.CardID, a.AccountID, a.TokenID, a.Amount, a.Currency, a.Status, a.ResponseCode,
+		a.DeclineReason, a.AuthCode, a.MerchantName, a.MerchantCategory,
+		a.Channel, a.AcquirerReference, a.ExpiresAt,
+	).Scan(&a.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create card authorization: %w", err)
+	}
+
+	return nil
+}
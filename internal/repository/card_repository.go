@@ -3,8 +3,10 @@ package repository
 import (
 	cryptorand "crypto/rand"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"math/big"
+	"time"
 
 	"github.com/darisadam/madabank-server/internal/domain/card"
 	"github.com/google/uuid"
@@ -12,10 +14,22 @@ import (
 
 type CardRepository interface {
 	Create(card *card.Card) error
+	CreateReplacement(originalID uuid.UUID, replacement *card.Card) error
 	GetByID(id uuid.UUID) (*card.Card, error)
 	GetByAccountID(accountID uuid.UUID) ([]*card.Card, error)
+	GetByNumberHash(hash string) (*card.Card, error)
 	Update(id uuid.UUID, updates map[string]interface{}) error
+	// RecordPINAttempt counts a PIN attempt in a single statement, unless the
+	// PIN is locked at now. A correct PIN clears the failures; a wrong one
+	// adds one and, at card.MaxPINAttempts, locks the PIN for
+	// card.PINLockDuration. A locked PIN is left as it is and returned with
+	// its lock.
+	RecordPINAttempt(id uuid.UUID, correct bool, now time.Time) (*card.PINAttempt, error)
+	UpdateControls(id uuid.UUID, controls card.Controls) error
 	Delete(id uuid.UUID) error
+	// ExpireCards marks every card past its expiry month as expired, deletes
+	// their wallet tokens, and returns how many cards it expired
+	ExpireCards(today string) (int64, error)
 	GenerateCardNumber() (string, error)
 	GenerateCVV() string
 }
@@ -29,15 +43,85 @@ func NewCardRepository(db *sql.DB) CardRepository {
 }
 
 func (r *cardRepository) Create(c *card.Card) error {
+	return insertCard(r.db, c)
+}
+
+// CreateReplacement blocks the original card and inserts its replacement in
+// a single database transaction. Credit card statements and wallet tokens
+// follow the replacement.
+func (r *cardRepository) CreateReplacement(originalID uuid.UUID, replacement *card.Card) error {
+	dbTx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = dbTx.Rollback() // Rollback if not committed
+	}()
+
+	// The credit line (balance and cycle totals) moves to the replacement
+	result, err := dbTx.Exec(`
+		UPDATE cards SET status = 'blocked', outstanding_balance = 0, cycle_purchases = 0, cycle_payments = 0
+		WHERE id = $1 AND status IN ('active', 'frozen', 'blocked')
+	`, originalID)
+	if err != nil {
+		return fmt.Errorf("failed to block original card: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("card not found")
+	}
+
+	replacement.ReplacesCardID = &originalID
+	if err := insertCard(dbTx, replacement); err != nil {
+		return err
+	}
+
+	if _, err := dbTx.Exec(`UPDATE card_statements SET card_id = $1 WHERE card_id = $2`, replacement.ID, originalID); err != nil {
+		return fmt.Errorf("failed to move card statements: %w", err)
+	}
+
+	// Wallet tokens keep working on the replacement without re-provisioning
+	if _, err := dbTx.Exec(`UPDATE card_tokens SET card_id = $1 WHERE card_id = $2 AND status <> 'deleted'`, replacement.ID, originalID); err != nil {
+		return fmt.Errorf("failed to move card tokens: %w", err)
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+type queryRower interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+func insertCard(q queryRower, c *card.Card) error {
+	controlsJSON, err := json.Marshal(c.Controls)
+	if err != nil {
+		return fmt.Errorf("failed to marshal card controls: %w", err)
+	}
+
+	if c.StatementDay == 0 {
+		c.StatementDay = card.StatementDayFor(time.Now())
+	}
+
 	query := `
 		INSERT INTO cards (id, account_id, card_number_encrypted, cvv_encrypted, 
 		                   card_holder_name, card_type, expiry_month, expiry_year, 
-		                   status, daily_limit)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		                   status, daily_limit, controls, replaces_card_id,
+		                   credit_limit, outstanding_balance, statement_day,
+		                   cycle_purchases, cycle_payments, card_number_hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, NULLIF($18, ''))
 		RETURNING created_at
 	`
 
-	err := r.db.QueryRow(
+	err = q.QueryRow(
 		query,
 		c.ID,
 		c.AccountID,
@@ -49,6 +133,14 @@ func (r *cardRepository) Create(c *card.Card) error {
 		c.ExpiryYear,
 		c.Status,
 		c.DailyLimit,
+		controlsJSON,
+		c.ReplacesCardID,
+		c.CreditLimit,
+		c.OutstandingBalance,
+		c.StatementDay,
+		c.CyclePurchases,
+		c.CyclePayments,
+		c.CardNumberHash,
 	).Scan(&c.CreatedAt)
 
 	if err != nil {
@@ -58,19 +150,26 @@ func (r *cardRepository) Create(c *card.Card) error {
 	return nil
 }
 
-func (r *cardRepository) GetByID(id uuid.UUID) (*card.Card, error) {
-	query := `
-		SELECT id, account_id, card_number_encrypted, cvv_encrypted, card_holder_name,
-		       card_type, expiry_month, expiry_year, status, daily_limit, created_at
-		FROM cards
-		WHERE id = $1 AND status != 'expired'
-	`
+// cardColumns is the column list read by scanCard
+const cardColumns = `id, account_id, card_number_encrypted, COALESCE(card_number_hash, ''), cvv_encrypted, card_holder_name,
+		       card_type, expiry_month, expiry_year, status, daily_limit,
+		       COALESCE(pin_encrypted, ''), pin_failed_attempts, pin_locked_until, controls,
+		       replaces_card_id, credit_limit, outstanding_balance, statement_day,
+		       cycle_purchases, cycle_payments, created_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
 
+func scanCard(row rowScanner) (*card.Card, error) {
 	c := &card.Card{}
-	err := r.db.QueryRow(query, id).Scan(
+	var pinLockedUntil sql.NullTime
+	var controlsJSON []byte
+	err := row.Scan(
 		&c.ID,
 		&c.AccountID,
 		&c.CardNumberEncrypted,
+		&c.CardNumberHash,
 		&c.CVVEncrypted,
 		&c.CardHolderName,
 		&c.CardType,
@@ -78,9 +177,59 @@ func (r *cardRepository) GetByID(id uuid.UUID) (*card.Card, error) {
 		&c.ExpiryYear,
 		&c.Status,
 		&c.DailyLimit,
+		&c.PINEncrypted,
+		&c.PINFailedAttempts,
+		&pinLockedUntil,
+		&controlsJSON,
+		&c.ReplacesCardID,
+		&c.CreditLimit,
+		&c.OutstandingBalance,
+		&c.StatementDay,
+		&c.CyclePurchases,
+		&c.CyclePayments,
 		&c.CreatedAt,
 	)
+	if err != nil {
+		return nil, err
+	}
+	c.Controls = card.DefaultControls()
+	if err := json.Unmarshal(controlsJSON, &c.Controls); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal card controls: %w", err)
+	}
+	if pinLockedUntil.Valid {
+		c.PINLockedUntil = &pinLockedUntil.Time
+	}
+	return c, nil
+}
+
+func (r *cardRepository) GetByID(id uuid.UUID) (*card.Card, error) {
+	query := `
+		SELECT ` + cardColumns + `
+		FROM cards
+		WHERE id = $1 AND status != 'expired'
+	`
 
+	c, err := scanCard(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("card not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get card: %w", err)
+	}
+
+	return c, nil
+}
+
+// GetByNumberHash finds a card by the fingerprint of its number, including
+// blocked and expired cards so the caller can decline them explicitly
+func (r *cardRepository) GetByNumberHash(hash string) (*card.Card, error) {
+	query := `
+		SELECT ` + cardColumns + `
+		FROM cards
+		WHERE card_number_hash = $1
+	`
+
+	c, err := scanCard(r.db.QueryRow(query, hash))
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("card not found")
 	}
@@ -93,8 +242,7 @@ func (r *cardRepository) GetByID(id uuid.UUID) (*card.Card, error) {
 
 func (r *cardRepository) GetByAccountID(accountID uuid.UUID) ([]*card.Card, error) {
 	query := `
-		SELECT id, account_id, card_number_encrypted, cvv_encrypted, card_holder_name,
-		       card_type, expiry_month, expiry_year, status, daily_limit, created_at
+		SELECT ` + cardColumns + `
 		FROM cards
 		WHERE account_id = $1
 		ORDER BY created_at DESC
@@ -108,20 +256,7 @@ func (r *cardRepository) GetByAccountID(accountID uuid.UUID) ([]*card.Card, erro
 
 	cards := []*card.Card{}
 	for rows.Next() {
-		c := &card.Card{}
-		err := rows.Scan(
-			&c.ID,
-			&c.AccountID,
-			&c.CardNumberEncrypted,
-			&c.CVVEncrypted,
-			&c.CardHolderName,
-			&c.CardType,
-			&c.ExpiryMonth,
-			&c.ExpiryYear,
-			&c.Status,
-			&c.DailyLimit,
-			&c.CreatedAt,
-		)
+		c, err := scanCard(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan card: %w", err)
 		}
@@ -165,11 +300,66 @@ func (r *cardRepository) Update(id uuid.UUID, updates map[string]interface{}) er
 	return nil
 }
 
+func (r *cardRepository) RecordPINAttempt(id uuid.UUID, correct bool, now time.Time) (*card.PINAttempt, error) {
+	attempt := &card.PINAttempt{}
+	var lockedUntil sql.NullTime
+	err := r.db.QueryRow(`
+		UPDATE cards
+		SET pin_failed_attempts = CASE WHEN $2 OR pin_failed_attempts + 1 >= $3 THEN 0 ELSE pin_failed_attempts + 1 END,
+		    pin_locked_until = CASE WHEN NOT $2 AND pin_failed_attempts + 1 >= $3 THEN $4::timestamp END
+		WHERE id = $1 AND (pin_locked_until IS NULL OR pin_locked_until <= $5)
+		RETURNING pin_failed_attempts, pin_locked_until
+	`, id, correct, card.MaxPINAttempts, now.Add(card.PINLockDuration), now).Scan(&attempt.FailedAttempts, &lockedUntil)
+	if err == sql.ErrNoRows {
+		// Either the card does not exist or its PIN is locked
+		err = r.db.QueryRow(`SELECT pin_locked_until FROM cards WHERE id = $1`, id).Scan(&lockedUntil)
+		if err == sql.ErrNoRows || (err == nil && !lockedUntil.Valid) {
+			return nil, fmt.Errorf("card not found")
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to record PIN attempt: %w", err)
+	}
+	if lockedUntil.Valid {
+		attempt.LockedUntil = &lockedUntil.Time
+	}
+	return attempt, nil
+}
+
+func (r *cardRepository) UpdateControls(id uuid.UUID, controls card.Controls) error {
+	controlsJSON, err := json.Marshal(controls)
+	if err != nil {
+		return fmt.Errorf("failed to marshal card controls: %w", err)
+	}
+
+	result, err := r.db.Exec(`UPDATE cards SET controls = $1 WHERE id = $2`, controlsJSON, id)
+	if err != nil {
+		return fmt.Errorf("failed to update card controls: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("card not found")
+	}
+
+	return nil
+}
+
 func (r *cardRepository) Delete(id uuid.UUID) error {
-	// Soft delete by setting status to expired
-	query := `UPDATE cards SET status = 'expired' WHERE id = $1`
+	dbTx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = dbTx.Rollback() // Rollback if not committed
+	}()
 
-	result, err := r.db.Exec(query, id)
+	// Soft delete by setting status to expired
+	result, err := dbTx.Exec(`UPDATE cards SET status = 'expired' WHERE id = $1`, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete card: %w", err)
 	}
@@ -183,9 +373,66 @@ func (r *cardRepository) Delete(id uuid.UUID) error {
 		return fmt.Errorf("card not found")
 	}
 
+	// Wallet tokens cannot outlive their card
+	if _, err := dbTx.Exec(`UPDATE card_tokens SET status = 'deleted' WHERE card_id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete card tokens: %w", err)
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return nil
 }
 
+func (r *cardRepository) ExpireCards(today string) (int64, error) {
+	dbTx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = dbTx.Rollback() // Rollback if not committed
+	}()
+
+	// Cards stay valid through the last day of their expiry month
+	rows, err := dbTx.Query(`
+		UPDATE cards SET status = 'expired'
+		WHERE status IN ('active', 'frozen', 'blocked')
+		  AND make_date(expiry_year, expiry_month, 1) + INTERVAL '1 month' <= $1::DATE
+		RETURNING id
+	`, today)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire cards: %w", err)
+	}
+
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			_ = rows.Close()
+			return 0, fmt.Errorf("failed to scan card id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	// Wallet tokens cannot outlive their card
+	for _, id := range ids {
+		if _, err := dbTx.Exec(`UPDATE card_tokens SET status = 'deleted' WHERE card_id = $1`, id); err != nil {
+			return 0, fmt.Errorf("failed to delete card tokens: %w", err)
+		}
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return int64(len(ids)), nil
+}
+
 func (r *cardRepository) GenerateCardNumber() (string, error) {
 	// Generate a valid 16-digit card number using Luhn algorithm
 	// Format: 4XXX XXXX XXXX XXXX (starts with 4 for Visa simulation)
@@ -203,7 +450,7 @@ func (r *cardRepository) GenerateCardNumber() (string, error) {
 		}
 
 		// Calculate Luhn check digit
-		checkDigit := r.calculateLuhnCheckDigit(cardNumber)
+		checkDigit := calculateLuhnCheckDigit(cardNumber)
 		cardNumber += fmt.Sprintf("%d", checkDigit)
 
 		// Check if card number already exists
@@ -223,7 +470,7 @@ func (r *cardRepository) GenerateCardNumber() (string, error) {
 	return "", fmt.Errorf("failed to generate unique card number after %d attempts", maxAttempts)
 }
 
-func (r *cardRepository) calculateLuhnCheckDigit(cardNumber string) int {
+func calculateLuhnCheckDigit(cardNumber string) int {
 	var sum int
 
 	for i := 0; i < len(cardNumber); i++ {
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/darisadam/madabank-server/internal/domain/card"
+	"github.com/google/uuid"
+)
+
+// tokenBIN is the issuer range reserved for wallet tokens, kept apart from
+// card numbers so acquirers and logs can tell the two apart
+const tokenBIN = "489537"
+
+type CardTokenRepository interface {
+	Create(t *card.Token) error
+	GetByID(id uuid.UUID) (*card.Token, error)
+	GetByNumberHash(hash string) (*card.Token, error)
+	ListByCard(cardID uuid.UUID) ([]*card.Token, error)
+	UpdateStatus(id uuid.UUID, status card.TokenStatus) error
+	GenerateTokenNumber() (string, error)
+}
+
+type cardTokenRepository struct {
+	db *sql.DB
+}
+
+func NewCardTokenRepository(db *sql.DB) CardTokenRepository {
+	return &cardTokenRepository{db: db}
+}
+
+const cardTokenColumns = `id, card_id, token_number_encrypted, token_number_hash, last_four, wallet,
+	device_id, COALESCE(device_name, ''), status, expiry_month, expiry_year, created_at, updated_at`
+
+func scanCardToken(row rowScanner) (*card.Token, error) {
+	t := &card.Token{}
+	err := row.Scan(
+		&t.ID, &t.CardID, &t.TokenNumberEncrypted, &t.TokenNumberHash, &t.LastFour, &t.Wallet,
+		&t.DeviceID, &t.DeviceName, &t.Status, &t.ExpiryMonth, &t.ExpiryYear, &t.CreatedAt, &t.UpdatedAt,
+	)
+	return t, err
+}
+
+func (r *cardTokenRepository) Create(t *card.Token) error {
+	err := r.db.QueryRow(`
+		INSERT INTO card_tokens (id, card_id, token_number_encrypted, token_number_hash, last_four, wallet,
+		                         device_id, device_name, status, expiry_month, expiry_year)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), $9, $10, $11)
+		RETURNING created_at, updated_at
+	`, t.ID, t.CardID, t.TokenNumberEncrypted, t.TokenNumberHash, t.LastFour, t.Wallet,
+		t.DeviceID, t.DeviceName, t.Status, t.ExpiryMonth, t.ExpiryYear,
+	).Scan(&t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create card token: %w", err)
+	}
+
+	return nil
+}
+
+func (r *cardTokenRepository) GetByID(id uuid.UUID) (*card.Token, error) {
+	t, err := scanCardToken(r.db.QueryRow(`SELECT `+cardTokenColumns+` FROM card_tokens WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("card token not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get card token: %w", err)
+	}
+
+	return t, nil
+}
+
+func (r *cardTokenRepository) GetByNumberHash(hash string) (*card.Token, error) {
+	t, err := scanCardToken(r.db.QueryRow(`SELECT `+cardTokenColumns+` FROM card_tokens WHERE token_number_hash = $1`, hash))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("card token not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get card token: %w", err)
+	}
+
+	return t, nil
+}
+
+// ListByCard returns the card's tokens that have not been deleted, newest first
+func (r *cardTokenRepository) ListByCard(cardID uuid.UUID) ([]*card.Token, error) {
+	rows, err := r.db.Query(`
+		SELECT `+cardTokenColumns+`
+		FROM card_tokens
+		WHERE card_id = $1 AND status <> 'deleted'
+		ORDER BY created_at DESC
+	`, cardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list card tokens: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	tokens := []*card.Token{}
+	for rows.Next() {
+		t, err := scanCardToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan card token: %w", err)
+		}
+		tokens = append(tokens, t)
+	}
+
+	return tokens, rows.Err()
+}
+
+func (r *cardTokenRepository) UpdateStatus(id uuid.UUID, status card.TokenStatus) error {
+	result, err := r.db.Exec(`UPDATE card_tokens SET status = $1 WHERE id = $2`, status, id)
+	if err != nil {
+		return fmt.Errorf("failed to update card token: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("card token not found")
+	}
+
+	return nil
+}
+
+// GenerateTokenNumber returns a random Luhn-valid 16-digit number in the token
+// range. Uniqueness is enforced by the index on token_number_hash.
+func (r *cardTokenRepository) GenerateTokenNumber() (string, error) {
+	number := tokenBIN
+	for len(number) < 15 {
+		digit, err := cryptoRandInt(10)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate random digit: %w", err)
+		}
+		number += fmt.Sprintf("%d", digit)
+	}
+
+	return number + fmt.Sprintf("%d", calculateLuhnCheckDigit(number)), nil
+}
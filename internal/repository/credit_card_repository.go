@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/card"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/google/uuid"
+)
+
+type CreditCardRepository interface {
+	ExecuteRepayment(cardID, fromAccountID uuid.UUID, amount float64, txn *transaction.Transaction) error
+	CloseStatement(st *card.Statement) error
+	ListStatements(cardID uuid.UUID, limit int) ([]*card.Statement, error)
+	ListCardsDueForStatement(statementDay int, periodEnd time.Time) ([]*card.Card, error)
+}
+
+type creditCardRepository struct {
+	db *sql.DB
+}
+
+func NewCreditCardRepository(db *sql.DB) CreditCardRepository {
+	return &creditCardRepository{db: db}
+}
+
+// ExecuteRepayment debits the deposit account, reduces the card's outstanding
+// balance and records the ledger entry in a single database transaction
+func (r *creditCardRepository) ExecuteRepayment(cardID, fromAccountID uuid.UUID, amount float64, txn *transaction.Transaction) error {
+	dbTx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = dbTx.Rollback() // Rollback if not committed
+	}()
+
+	// Lock source account
+	held, err := lockBalance(dbTx, fromAccountID, "")
+	if err != nil {
+		return fmt.Errorf("failed to lock source account: %w", err)
+	}
+
+	// Lock card
+	var outstanding float64
+	err = dbTx.QueryRow(`SELECT outstanding_balance FROM cards WHERE id = $1 AND card_type = 'credit' FOR UPDATE`, cardID).Scan(&outstanding)
+	if err != nil {
+		return fmt.Errorf("failed to lock card: %w", err)
+	}
+
+	if held.available < amount {
+		return insufficientFunds(held.available, amount)
+	}
+	if amount > outstanding {
+		return fmt.Errorf("repayment of %.2f exceeds outstanding balance of %.2f", amount, outstanding)
+	}
+
+	if err := held.add(dbTx, -amount); err != nil {
+		return fmt.Errorf("failed to debit source account: %w", err)
+	}
+
+	_, err = dbTx.Exec(`
+		UPDATE cards SET outstanding_balance = outstanding_balance - $1, cycle_payments = cycle_payments + $1
+		WHERE id = $2
+	`, amount, cardID)
+	if err != nil {
+		return fmt.Errorf("failed to credit card balance: %w", err)
+	}
+
+	metadataJSON, _ := json.Marshal(txn.Metadata)
+	_, err = dbTx.Exec(`
+		INSERT INTO transactions (id, idempotency_key, from_account_id, amount, transaction_type, status, description, metadata, completed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, CURRENT_TIMESTAMP)
+	`, txn.ID, txn.IdempotencyKey, fromAccountID, amount, txn.TransactionType, transaction.TransactionStatusCompleted, txn.Description, metadataJSON)
+	if err != nil {
+		return fmt.Errorf("failed to insert transaction: %w", err)
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// CloseStatement stores the statement, adds its interest to the card balance
+// and starts a new cycle. Cycle totals are decremented rather than reset so
+// activity that lands while the statement is being built is kept.
+func (r *creditCardRepository) CloseStatement(st *card.Statement) error {
+	dbTx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = dbTx.Rollback() // Rollback if not committed
+	}()
+
+	err = dbTx.QueryRow(`
+		INSERT INTO card_statements (id, card_id, period_start, period_end, opening_balance, purchases,
+		                             payments, interest, closing_balance, minimum_payment, due_date)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING created_at
+	`, st.ID, st.CardID, st.PeriodStart, st.PeriodEnd, st.OpeningBalance, st.Purchases,
+		st.Payments, st.Interest, st.ClosingBalance, st.MinimumPayment, st.DueDate,
+	).Scan(&st.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create statement: %w", err)
+	}
+
+	_, err = dbTx.Exec(`
+		UPDATE cards
+		SET outstanding_balance = outstanding_balance + $1,
+		    cycle_purchases = cycle_purchases - $2,
+		    cycle_payments = cycle_payments - $3
+		WHERE id = $4
+	`, st.Interest, st.Purchases, st.Payments, st.CardID)
+	if err != nil {
+		return fmt.Errorf("failed to start new card cycle: %w", err)
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ListStatements returns the card's statements, newest first
+func (r *creditCardRepository) ListStatements(cardID uuid.UUID, limit int) ([]*card.Statement, error) {
+	query := `
+		SELECT id, card_id, period_start, period_end, opening_balance, purchases, payments,
+		       interest, closing_balance, minimum_payment, due_date, created_at
+		FROM card_statements
+		WHERE card_id = $1
+		ORDER BY period_end DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.Query(query, cardID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statements: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	statements := []*card.Statement{}
+	for rows.Next() {
+		st := &card.Statement{}
+		if err := rows.Scan(
+			&st.ID,
+			&st.CardID,
+			&st.PeriodStart,
+			&st.PeriodEnd,
+			&st.OpeningBalance,
+			&st.Purchases,
+			&st.Payments,
+			&st.Interest,
+			&st.ClosingBalance,
+			&st.MinimumPayment,
+			&st.DueDate,
+			&st.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan statement: %w", err)
+		}
+		statements = append(statements, st)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate statements: %w", err)
+	}
+
+	return statements, nil
+}
+
+// ListCardsDueForStatement returns credit cards whose cycle closes on
+// statementDay and that have no statement for periodEnd yet
+func (r *creditCardRepository) ListCardsDueForStatement(statementDay int, periodEnd time.Time) ([]*card.Card, error) {
+	query := `
+		SELECT ` + cardColumns + `
+		FROM cards c
+		WHERE card_type = 'credit' AND status != 'expired' AND statement_day = $1
+		  AND NOT EXISTS (
+		      SELECT 1 FROM card_statements s WHERE s.card_id = c.id AND s.period_end = $2
+		  )
+		ORDER BY id
+	`
+
+	rows, err := r.db.Query(query, statementDay, periodEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cards due for statement: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	cards := []*card.Card{}
+	for rows.Next() {
+		c, err := scanCard(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan card: %w", err)
+		}
+		cards = append(cards, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate cards: %w", err)
+	}
+
+	return cards, nil
+}
@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/export"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/google/uuid"
+)
+
+type ExportRepository interface {
+	Create(e *export.Export) error
+	GetByID(id uuid.UUID) (*export.Export, error)
+	List(limit int) ([]*export.Export, error)
+	// Start marks the export running with total items to process
+	Start(id uuid.UUID, total int, now time.Time) error
+	UpdateProgress(id uuid.UUID, processed int) error
+	Complete(id uuid.UUID, processed int, location string, now time.Time) error
+	Fail(id uuid.UUID, reason string, now time.Time) error
+
+	// CountAccounts counts the accounts opened before the end of a period,
+	// closed ones included
+	CountAccounts(before time.Time) (int, error)
+	// ListAccountsAfter pages through the same accounts by ID
+	ListAccountsAfter(before time.Time, afterID uuid.UUID, limit int) ([]*account.Account, error)
+	// CountTransactions counts the transactions created in [from, to), in any status
+	CountTransactions(from, to time.Time) (int, error)
+	// ListTransactionsAfter pages through the same transactions in creation
+	// order, starting after the given one; a nil after starts at the beginning
+	ListTransactionsAfter(from, to time.Time, after *transaction.Transaction, limit int) ([]*transaction.Transaction, error)
+}
+
+type exportRepository struct {
+	db *sql.DB
+}
+
+func NewExportRepository(db *sql.DB) ExportRepository {
+	return &exportRepository{db: db}
+}
+
+const exportColumns = `id, kind, period_start, period_end, status, total, processed, COALESCE(location, ''),
+	COALESCE(error, ''), requested_by, started_at, completed_at, created_at, updated_at`
+
+func scanExport(row rowScanner) (*export.Export, error) {
+	e := &export.Export{}
+	err := row.Scan(
+		&e.ID, &e.Kind, &e.PeriodStart, &e.PeriodEnd, &e.Status, &e.Total, &e.Processed, &e.Location,
+		&e.Error, &e.RequestedBy, &e.StartedAt, &e.CompletedAt, &e.CreatedAt, &e.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (r *exportRepository) Create(e *export.Export) error {
+	err := r.db.QueryRow(`
+		INSERT INTO bulk_exports (id, kind, period_start, period_end, status, requested_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at, updated_at
+	`, e.ID, e.Kind, e.PeriodStart.Format(export.DateLayout), e.PeriodEnd.Format(export.DateLayout), e.Status, e.RequestedBy,
+	).Scan(&e.CreatedAt, &e.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create export: %w", err)
+	}
+	return nil
+}
+
+func (r *exportRepository) GetByID(id uuid.UUID) (*export.Export, error) {
+	e, err := scanExport(r.db.QueryRow(`SELECT `+exportColumns+` FROM bulk_exports WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("export not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get export: %w", err)
+	}
+	return e, nil
+}
+
+func (r *exportRepository) List(limit int) ([]*export.Export, error) {
+	rows, err := r.db.Query(`SELECT `+exportColumns+` FROM bulk_exports ORDER BY created_at DESC LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list exports: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	exports := []*export.Export{}
+	for rows.Next() {
+		e, err := scanExport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan export: %w", err)
+		}
+		exports = append(exports, e)
+	}
+
+	return exports, rows.Err()
+}
+
+func (r *exportRepository) Start(id uuid.UUID, total int, now time.Time) error {
+	return r.update("start", `
+		UPDATE bulk_exports
+		SET status = $2, total = $3, processed = 0, error = NULL, started_at = $4, updated_at = $4
+		WHERE id = $1
+	`, id, export.StatusRunning, total, now.UTC())
+}
+
+func (r *exportRepository) UpdateProgress(id uuid.UUID, processed int) error {
+	return r.update("update", `
+		UPDATE bulk_exports SET processed = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $1
+	`, id, processed)
+}
+
+func (r *exportRepository) Complete(id uuid.UUID, processed int, location string, now time.Time) error {
+	return r.update("complete", `
+		UPDATE bulk_exports
+		SET status = $2, processed = $3, location = $4, completed_at = $5, updated_at = $5
+		WHERE id = $1
+	`, id, export.StatusCompleted, processed, location, now.UTC())
+}
+
+func (r *exportRepository) Fail(id uuid.UUID, reason string, now time.Time) error {
+	return r.update("fail", `
+		UPDATE bulk_exports
+		SET status = $2, error = $3, completed_at = $4, updated_at = $4
+		WHERE id = $1
+	`, id, export.StatusFailed, reason, now.UTC())
+}
+
+func (r *exportRepository) update(action, query string, args ...interface{}) error {
+	result, err := r.db.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to %s export: %w", action, err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("export not found")
+	}
+	return nil
+}
+
+func (r *exportRepository) CountAccounts(before time.Time) (int, error) {
+	var n int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM accounts WHERE created_at < $1`, before.UTC()).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to count accounts: %w", err)
+	}
+	return n, nil
+}
+
+func (r *exportRepository) ListAccountsAfter(before time.Time, afterID uuid.UUID, limit int) ([]*account.Account, error) {
+	rows, err := r.db.Query(`
+		SELECT id, user_id, account_number, account_type, balance, currency,
+		       interest_rate, overdraft_limit, status, freeze_reason, version, created_at, updated_at
+		FROM accounts
+		WHERE created_at < $1 AND id > $2
+		ORDER BY id
+		LIMIT $3
+	`, before.UTC(), afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accounts: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	accounts := []*account.Account{}
+	for rows.Next() {
+		acc := &account.Account{}
+		err := rows.Scan(
+			&acc.ID, &acc.UserID, &acc.AccountNumber, &acc.AccountType, &acc.Balance, &acc.Currency,
+			&acc.InterestRate, &acc.OverdraftLimit, &acc.Status, &acc.FreezeReason, &acc.Version, &acc.CreatedAt, &acc.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan account: %w", err)
+		}
+		accounts = append(accounts, acc)
+	}
+
+	return accounts, rows.Err()
+}
+
+func (r *exportRepository) CountTransactions(from, to time.Time) (int, error) {
+	var n int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM transactions WHERE created_at >= $1 AND created_at < $2`, from.UTC(), to.UTC()).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count transactions: %w", err)
+	}
+	return n, nil
+}
+
+func (r *exportRepository) ListTransactionsAfter(from, to time.Time, after *transaction.Transaction, limit int) ([]*transaction.Transaction, error) {
+	// Start just before the period when there is no previous page
+	afterCreated, afterID := from.UTC().Add(-time.Microsecond), uuid.Nil
+	if after != nil {
+		afterCreated, afterID = after.CreatedAt.UTC(), after.ID
+	}
+
+	rows, err := r.db.Query(`
+		SELECT id, idempotency_key, from_account_id, to_account_id, amount,
+		       transaction_type, status, description, metadata, created_at, completed_at
+		FROM transactions
+		WHERE created_at >= $1 AND created_at < $2
+		  AND (created_at, id) > ($3, $4)
+		ORDER BY created_at, id
+		LIMIT $5
+	`, from.UTC(), to.UTC(), afterCreated, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transactions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanTransactions(rows)
+}
@@ -0,0 +1,253 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/google/uuid"
+)
+
+type HoldRepository interface {
+	// Place stores an active hold if it fits within the account's available
+	// balance, or returns account.ErrInsufficientAvailable. It sets the
+	// hold's currency to the account's.
+	Place(h *account.Hold) error
+	GetByID(id uuid.UUID) (*account.Hold, error)
+	// ListActiveByAccount returns the account's unexpired active holds, oldest first
+	ListActiveByAccount(accountID uuid.UUID) ([]*account.Hold, error)
+	// Extend moves an active hold's expiry, or returns account.ErrHoldNotActive
+	Extend(id uuid.UUID, expiresAt time.Time) error
+	// Release ends an active hold without a debit, or returns account.ErrHoldNotActive
+	Release(id uuid.UUID) error
+	// Capture debits amount, at most the held amount, for an active hold and
+	// ends it, in one database transaction
+	Capture(id uuid.UUID, amount float64, txn *transaction.Transaction) error
+	// ExpireDue marks active holds past their expiry as expired and returns how many
+	ExpireDue(now time.Time) (int, error)
+}
+
+type holdRepository struct {
+	db    *sql.DB
+	hooks []DebitHook
+}
+
+// NewHoldRepository returns the repository. The hooks run after every capture.
+func NewHoldRepository(db *sql.DB, hooks ...DebitHook) HoldRepository {
+	return &holdRepository{db: db, hooks: hooks}
+}
+
+const holdColumns = `id, account_id, amount, currency, purpose, COALESCE(reference, ''), status, expires_at,
+	captured_amount, transaction_id, created_at, updated_at`
+
+func scanHold(row rowScanner) (*account.Hold, error) {
+	h := &account.Hold{}
+	err := row.Scan(&h.ID, &h.AccountID, &h.Amount, &h.Currency, &h.Purpose, &h.Reference, &h.Status, &h.ExpiresAt,
+		&h.CapturedAmount, &h.TransactionID, &h.CreatedAt, &h.UpdatedAt)
+	return h, err
+}
+
+func (r *holdRepository) Place(h *account.Hold) error {
+	dbTx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = dbTx.Rollback() // Rollback if not committed
+	}()
+
+	// Lock the account so concurrent holds and card authorizations cannot
+	// reserve the same funds
+	var available float64
+	err = dbTx.QueryRow(`
+		SELECT `+availableBalance+`, currency
+		FROM accounts WHERE id = $1 AND status = 'active' FOR UPDATE
+	`, h.AccountID).Scan(&available, &h.Currency)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("account not found or not active")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock funds for hold: %w", err)
+	}
+	if available < h.Amount {
+		return account.ErrInsufficientAvailable
+	}
+
+	err = dbTx.QueryRow(`
+		INSERT INTO account_holds (id, account_id, amount, currency, purpose, reference, status, expires_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8)
+		RETURNING created_at, updated_at
+	`, h.ID, h.AccountID, h.Amount, h.Currency, h.Purpose, h.Reference, h.Status, h.ExpiresAt.UTC()).Scan(&h.CreatedAt, &h.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create hold: %w", err)
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *holdRepository) GetByID(id uuid.UUID) (*account.Hold, error) {
+	h, err := scanHold(r.db.QueryRow(`SELECT `+holdColumns+` FROM account_holds WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("hold not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hold: %w", err)
+	}
+	return h, nil
+}
+
+func (r *holdRepository) ListActiveByAccount(accountID uuid.UUID) ([]*account.Hold, error) {
+	rows, err := r.db.Query(`
+		SELECT `+holdColumns+`
+		FROM account_holds
+		WHERE account_id = $1 AND status = 'active' AND expires_at > CURRENT_TIMESTAMP
+		ORDER BY created_at, id
+	`, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list holds: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	holds := []*account.Hold{}
+	for rows.Next() {
+		h, err := scanHold(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan hold: %w", err)
+		}
+		holds = append(holds, h)
+	}
+
+	return holds, rows.Err()
+}
+
+func (r *holdRepository) Extend(id uuid.UUID, expiresAt time.Time) error {
+	return r.updateActive(`expires_at = $2`, id, expiresAt.UTC())
+}
+
+func (r *holdRepository) Release(id uuid.UUID) error {
+	return r.updateActive(`status = 'released'`, id)
+}
+
+// updateActive applies set to the hold if it is still active and unexpired
+func (r *holdRepository) updateActive(set string, id uuid.UUID, args ...interface{}) error {
+	result, err := r.db.Exec(`
+		UPDATE account_holds SET `+set+`, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = 'active' AND expires_at > CURRENT_TIMESTAMP
+	`, append([]interface{}{id}, args...)...)
+	if err != nil {
+		return fmt.Errorf("failed to update hold: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return account.ErrHoldNotActive
+	}
+
+	return nil
+}
+
+func (r *holdRepository) Capture(id uuid.UUID, amount float64, txn *transaction.Transaction) error {
+	dbTx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = dbTx.Rollback()
+	}()
+
+	var accountID uuid.UUID
+	var heldAmount float64
+	var active bool
+	err = dbTx.QueryRow(`
+		SELECT account_id, amount, status = 'active' AND expires_at > CURRENT_TIMESTAMP
+		FROM account_holds WHERE id = $1 FOR UPDATE
+	`, id).Scan(&accountID, &heldAmount, &active)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("hold not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock hold: %w", err)
+	}
+	if !active {
+		return account.ErrHoldNotActive
+	}
+	if amount > heldAmount {
+		return fmt.Errorf("capture amount exceeds the held amount of %.2f", heldAmount)
+	}
+
+	held, err := lockBalance(dbTx, accountID, "")
+	if err != nil {
+		return fmt.Errorf("failed to lock account: %w", err)
+	}
+	// The hold's own reservation is counted in the held funds; the capture
+	// may use it but not what other holds reserve
+	available := held.available + heldAmount
+	if available < amount {
+		return insufficientFunds(available, amount)
+	}
+
+	if err := held.add(dbTx, -amount); err != nil {
+		if errors.Is(err, transaction.ErrInsufficientFunds) {
+			return err
+		}
+		return fmt.Errorf("failed to debit account: %w", err)
+	}
+
+	metadataJSON, _ := json.Marshal(txn.Metadata)
+	_, err = dbTx.Exec(`
+		INSERT INTO transactions (id, idempotency_key, from_account_id, amount, transaction_type, status, description, metadata, completed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, CURRENT_TIMESTAMP)
+	`, txn.ID, txn.IdempotencyKey, accountID, amount, txn.TransactionType, transaction.TransactionStatusCompleted, txn.Description, metadataJSON)
+	if err != nil {
+		return fmt.Errorf("failed to insert transaction: %w", err)
+	}
+
+	_, err = dbTx.Exec(`
+		UPDATE account_holds
+		SET status = 'captured', captured_amount = $2, transaction_id = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, id, amount, txn.ID)
+	if err != nil {
+		return fmt.Errorf("failed to capture hold: %w", err)
+	}
+
+	txn.FromAccountID, txn.Amount = &accountID, amount
+	if err := runDebitHooks(dbTx, r.hooks, txn); err != nil {
+		return err
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *holdRepository) ExpireDue(now time.Time) (int, error) {
+	result, err := r.db.Exec(`
+		UPDATE account_holds SET status = 'expired', updated_at = CURRENT_TIMESTAMP
+		WHERE status = 'active' AND expires_at <= $1
+	`, now.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire holds: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(rowsAffected), nil
+}
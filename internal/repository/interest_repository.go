@@ -0,0 +1,263 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/interest"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/google/uuid"
+)
+
+type InterestRepository interface {
+	// ListInterestBearing returns the balance and rate of every active savings
+	// account with a positive balance
+	ListInterestBearing() ([]*interest.Balance, error)
+	// ListRateTiers returns the rate tiers of every product
+	ListRateTiers() (interest.Tiers, error)
+	// SaveAccruals inserts a day's accruals, skipping accounts already accrued
+	// for the day, and returns how many were inserted
+	SaveAccruals(accruals []*interest.Accrual) (int, error)
+	// ListAccountsWithUnposted returns the accounts with unposted accruals dated before the given day
+	ListAccountsWithUnposted(before time.Time) ([]uuid.UUID, error)
+	// PostAccruals credits an account with its unposted accruals dated before
+	// the given day as one interest transaction, whose amount it sets. It
+	// returns false without posting when they round to less than a cent.
+	PostAccruals(accountID uuid.UUID, before time.Time, txn *transaction.Transaction) (bool, error)
+	// ListAccruals returns an account's accruals dated from the first day up
+	// to the second, oldest first
+	ListAccruals(accountID uuid.UUID, from, to time.Time) ([]*interest.Accrual, error)
+	// ListPostings returns the interest transactions credited to an account
+	// in [from, to), oldest first
+	ListPostings(accountID uuid.UUID, from, to time.Time) ([]*transaction.Transaction, error)
+}
+
+type interestRepository struct {
+	db *sql.DB
+}
+
+func NewInterestRepository(db *sql.DB) InterestRepository {
+	return &interestRepository{db: db}
+}
+
+func (r *interestRepository) ListInterestBearing() ([]*interest.Balance, error) {
+	rows, err := r.db.Query(`
+		SELECT id, account_type, currency, balance, interest_rate
+		FROM accounts
+		WHERE account_type = $1 AND status = 'active' AND balance > 0
+		ORDER BY id
+	`, account.AccountTypeSavings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list interest-bearing accounts: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	balances := []*interest.Balance{}
+	for rows.Next() {
+		b := &interest.Balance{}
+		if err := rows.Scan(&b.AccountID, &b.AccountType, &b.Currency, &b.Balance, &b.AnnualRate); err != nil {
+			return nil, fmt.Errorf("failed to scan interest-bearing account: %w", err)
+		}
+		balances = append(balances, b)
+	}
+
+	return balances, rows.Err()
+}
+
+func (r *interestRepository) ListRateTiers() (interest.Tiers, error) {
+	rows, err := r.db.Query(`
+		SELECT account_type, currency, min_balance, annual_rate
+		FROM interest_rate_tiers
+		ORDER BY account_type, currency, min_balance
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list interest rate tiers: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	tiers := interest.Tiers{}
+	for rows.Next() {
+		var t interest.Tier
+		if err := rows.Scan(&t.AccountType, &t.Currency, &t.MinBalance, &t.AnnualRate); err != nil {
+			return nil, fmt.Errorf("failed to scan interest rate tier: %w", err)
+		}
+		tiers = append(tiers, t)
+	}
+
+	return tiers, rows.Err()
+}
+
+func (r *interestRepository) SaveAccruals(accruals []*interest.Accrual) (int, error) {
+	dbTx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = dbTx.Rollback() // Rollback if not committed
+	}()
+
+	inserted := 0
+	for _, a := range accruals {
+		result, err := dbTx.Exec(`
+			INSERT INTO interest_accruals (id, account_id, accrual_date, balance, annual_rate, amount)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (account_id, accrual_date) DO NOTHING
+		`, a.ID, a.AccountID, a.AccrualDate.Format(interest.DateLayout), a.Balance, a.AnnualRate, a.Amount)
+		if err != nil {
+			return 0, fmt.Errorf("failed to save interest accrual: %w", err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		inserted += int(n)
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return inserted, nil
+}
+
+func (r *interestRepository) ListAccountsWithUnposted(before time.Time) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(`
+		SELECT DISTINCT account_id
+		FROM interest_accruals
+		WHERE posted_transaction_id IS NULL AND accrual_date < $1
+		ORDER BY account_id
+	`, before.Format(interest.DateLayout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unposted interest: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan account id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+func (r *interestRepository) PostAccruals(accountID uuid.UUID, before time.Time, txn *transaction.Transaction) (bool, error) {
+	dbTx, err := r.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = dbTx.Rollback() // Rollback if not committed
+	}()
+
+	// Lock account; closed accounts keep their accruals unposted
+	var status string
+	err = dbTx.QueryRow(`SELECT status FROM accounts WHERE id = $1 AND status <> 'closed' FOR UPDATE`, accountID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to lock account: %w", err)
+	}
+
+	// Claim the accruals, so a concurrent run finds nothing left to post
+	var accrued float64
+	var days int
+	var from, to time.Time
+	err = dbTx.QueryRow(`
+		WITH claimed AS (
+			UPDATE interest_accruals
+			SET posted_transaction_id = $1
+			WHERE account_id = $2 AND accrual_date < $3 AND posted_transaction_id IS NULL
+			RETURNING accrual_date, amount
+		)
+		SELECT COALESCE(SUM(amount), 0), COUNT(*), COALESCE(MIN(accrual_date), $3), COALESCE(MAX(accrual_date), $3)
+		FROM claimed
+	`, txn.ID, accountID, before.Format(interest.DateLayout)).Scan(&accrued, &days, &from, &to)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim interest accruals: %w", err)
+	}
+
+	amount := interest.PostingAmount(accrued)
+	if amount <= 0 {
+		return false, nil
+	}
+
+	txn.Amount = amount
+	txn.ToAccountID = &accountID
+	if txn.Metadata == nil {
+		txn.Metadata = map[string]interface{}{}
+	}
+	txn.Metadata["accrued"] = accrued
+	txn.Metadata["days"] = days
+	txn.Metadata["period_start"] = from.Format(interest.DateLayout)
+	txn.Metadata["period_end"] = to.Format(interest.DateLayout)
+
+	metadataJSON, _ := json.Marshal(txn.Metadata)
+	_, err = dbTx.Exec(`
+		INSERT INTO transactions (id, idempotency_key, to_account_id, amount, transaction_type, status, description, metadata, completed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, CURRENT_TIMESTAMP)
+	`, txn.ID, txn.IdempotencyKey, accountID, amount, txn.TransactionType, transaction.TransactionStatusCompleted, txn.Description, metadataJSON)
+	if err != nil {
+		return false, fmt.Errorf("failed to insert transaction: %w", err)
+	}
+
+	_, err = dbTx.Exec(`UPDATE accounts SET balance = balance + $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`, amount, accountID)
+	if err != nil {
+		return false, fmt.Errorf("failed to credit account: %w", err)
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return true, nil
+}
+
+func (r *interestRepository) ListAccruals(accountID uuid.UUID, from, to time.Time) ([]*interest.Accrual, error) {
+	rows, err := r.db.Query(`
+		SELECT id, account_id, accrual_date, balance, annual_rate, amount, posted_transaction_id, created_at
+		FROM interest_accruals
+		WHERE account_id = $1 AND accrual_date >= $2 AND accrual_date < $3
+		ORDER BY accrual_date
+	`, accountID, from.Format(interest.DateLayout), to.Format(interest.DateLayout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list interest accruals: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	accruals := []*interest.Accrual{}
+	for rows.Next() {
+		a := &interest.Accrual{}
+		if err := rows.Scan(&a.ID, &a.AccountID, &a.AccrualDate, &a.Balance, &a.AnnualRate, &a.Amount, &a.PostedTransactionID, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan interest accrual: %w", err)
+		}
+		accruals = append(accruals, a)
+	}
+
+	return accruals, rows.Err()
+}
+
+func (r *interestRepository) ListPostings(accountID uuid.UUID, from, to time.Time) ([]*transaction.Transaction, error) {
+	rows, err := r.db.Query(`
+		SELECT id, idempotency_key, from_account_id, to_account_id, amount,
+		       transaction_type, status, description, metadata, created_at, completed_at
+		FROM transactions
+		WHERE to_account_id = $1 AND transaction_type = $2 AND status = $3
+		  AND COALESCE(completed_at, created_at) >= $4
+		  AND COALESCE(completed_at, created_at) < $5
+		ORDER BY COALESCE(completed_at, created_at), id
+	`, accountID, transaction.TransactionTypeInterest, transaction.TransactionStatusCompleted, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list interest postings: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanTransactions(rows)
+}
@@ -0,0 +1,265 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/queue"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type JobRepository interface {
+	// Enqueue inserts a pending job. It returns false without error when a job
+	// with the same unique key already exists.
+	Enqueue(job *queue.Job) (bool, error)
+	// Claim locks the oldest due pending job of one of the given kinds for
+	// workerID and counts the attempt. It returns nil when no job is due.
+	Claim(kinds []string, workerID string, now time.Time) (*queue.Job, error)
+	// Complete, Reschedule and Bury finish a running job locked by workerID.
+	// They return queue.ErrLockLost when the worker no longer holds the job.
+	Complete(id uuid.UUID, workerID string, now time.Time) error
+	// Reschedule returns a failed job to pending to be retried at runAt
+	Reschedule(id uuid.UUID, workerID, lastError string, runAt time.Time) error
+	// Bury moves a job to the dead-letter queue
+	Bury(id uuid.UUID, workerID, lastError string, now time.Time) error
+	// RequeueStale releases running jobs locked before cutoff, whose worker
+	// is presumed to have crashed. Jobs without attempts left are buried.
+	RequeueStale(cutoff, now time.Time) (int64, error)
+	// Retry moves a dead job back to pending with a fresh set of attempts
+	Retry(id uuid.UUID, now time.Time) (*queue.Job, error)
+	// DeleteCompletedBefore prunes jobs that completed before cutoff
+	DeleteCompletedBefore(cutoff time.Time) (int64, error)
+
+	GetByID(id uuid.UUID) (*queue.Job, error)
+	List(status queue.Status, kind string, limit int) ([]*queue.Job, error)
+	CountByStatus() (map[queue.Status]int, error)
+}
+
+type jobRepository struct {
+	db *sql.DB
+}
+
+func NewJobRepository(db *sql.DB) JobRepository {
+	return &jobRepository{db: db}
+}
+
+const jobColumns = `id, kind, payload, status, attempts, max_attempts, run_at, unique_key,
+	COALESCE(last_error, ''), locked_by, locked_at, completed_at, created_at, updated_at`
+
+func scanJob(row rowScanner) (*queue.Job, error) {
+	job := &queue.Job{}
+	var payload []byte
+	err := row.Scan(
+		&job.ID, &job.Kind, &payload, &job.Status, &job.Attempts, &job.MaxAttempts, &job.RunAt, &job.UniqueKey,
+		&job.LastError, &job.LockedBy, &job.LockedAt, &job.CompletedAt, &job.CreatedAt, &job.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	job.Payload = payload
+	return job, nil
+}
+
+func (r *jobRepository) Enqueue(job *queue.Job) (bool, error) {
+	payload := []byte(job.Payload)
+	if len(payload) == 0 {
+		payload = []byte("{}")
+	}
+
+	err := r.db.QueryRow(`
+		INSERT INTO queued_jobs (id, kind, payload, status, max_attempts, run_at, unique_key)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (unique_key) DO NOTHING
+		RETURNING created_at, updated_at
+	`, job.ID, job.Kind, payload, queue.StatusPending, job.MaxAttempts, job.RunAt.UTC(), job.UniqueKey,
+	).Scan(&job.CreatedAt, &job.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to enqueue job: %w", err)
+	}
+
+	job.Status = queue.StatusPending
+	return true, nil
+}
+
+func (r *jobRepository) Claim(kinds []string, workerID string, now time.Time) (*queue.Job, error) {
+	job, err := scanJob(r.db.QueryRow(`
+		UPDATE queued_jobs
+		SET status = $1, attempts = attempts + 1, locked_by = $2, locked_at = $3, updated_at = $3
+		WHERE id = (
+			SELECT id FROM queued_jobs
+			WHERE status = $4 AND run_at <= $3 AND kind = ANY($5)
+			ORDER BY run_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+jobColumns,
+		queue.StatusRunning, workerID, now.UTC(), queue.StatusPending, pq.Array(kinds),
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim job: %w", err)
+	}
+
+	return job, nil
+}
+
+func (r *jobRepository) Complete(id uuid.UUID, workerID string, now time.Time) error {
+	result, err := r.db.Exec(`
+		UPDATE queued_jobs
+		SET status = $1, completed_at = $2, locked_by = NULL, locked_at = NULL, updated_at = $2
+		WHERE id = $3 AND locked_by = $4 AND status = $5
+	`, queue.StatusCompleted, now.UTC(), id, workerID, queue.StatusRunning)
+	if err != nil {
+		return fmt.Errorf("failed to complete job: %w", err)
+	}
+
+	return lockHeld(result)
+}
+
+func (r *jobRepository) Reschedule(id uuid.UUID, workerID, lastError string, runAt time.Time) error {
+	result, err := r.db.Exec(`
+		UPDATE queued_jobs
+		SET status = $1, last_error = $2, run_at = $3, locked_by = NULL, locked_at = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $4 AND locked_by = $5 AND status = $6
+	`, queue.StatusPending, lastError, runAt.UTC(), id, workerID, queue.StatusRunning)
+	if err != nil {
+		return fmt.Errorf("failed to reschedule job: %w", err)
+	}
+
+	return lockHeld(result)
+}
+
+func (r *jobRepository) Bury(id uuid.UUID, workerID, lastError string, now time.Time) error {
+	result, err := r.db.Exec(`
+		UPDATE queued_jobs
+		SET status = $1, last_error = $2, locked_by = NULL, locked_at = NULL, updated_at = $3
+		WHERE id = $4 AND locked_by = $5 AND status = $6
+	`, queue.StatusDead, lastError, now.UTC(), id, workerID, queue.StatusRunning)
+	if err != nil {
+		return fmt.Errorf("failed to bury job: %w", err)
+	}
+
+	return lockHeld(result)
+}
+
+// lockHeld turns an update of a running job that matched no row into
+// queue.ErrLockLost
+func lockHeld(result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return queue.ErrLockLost
+	}
+
+	return nil
+}
+
+func (r *jobRepository) RequeueStale(cutoff, now time.Time) (int64, error) {
+	result, err := r.db.Exec(`
+		UPDATE queued_jobs
+		SET status = CASE WHEN attempts >= max_attempts THEN $1 ELSE $2 END,
+		    last_error = 'worker lock expired',
+		    locked_by = NULL, locked_at = NULL, updated_at = $3
+		WHERE status = $4 AND locked_at < $5
+	`, queue.StatusDead, queue.StatusPending, now.UTC(), queue.StatusRunning, cutoff.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue stale jobs: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+func (r *jobRepository) Retry(id uuid.UUID, now time.Time) (*queue.Job, error) {
+	job, err := scanJob(r.db.QueryRow(`
+		UPDATE queued_jobs
+		SET status = $1, attempts = 0, run_at = $2, updated_at = $2
+		WHERE id = $3 AND status = $4
+		RETURNING `+jobColumns,
+		queue.StatusPending, now.UTC(), id, queue.StatusDead,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("dead job not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to retry job: %w", err)
+	}
+
+	return job, nil
+}
+
+func (r *jobRepository) DeleteCompletedBefore(cutoff time.Time) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM queued_jobs WHERE status = $1 AND completed_at < $2`,
+		queue.StatusCompleted, cutoff.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune completed jobs: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+func (r *jobRepository) GetByID(id uuid.UUID) (*queue.Job, error) {
+	job, err := scanJob(r.db.QueryRow(`SELECT `+jobColumns+` FROM queued_jobs WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("job not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+
+	return job, nil
+}
+
+func (r *jobRepository) List(status queue.Status, kind string, limit int) ([]*queue.Job, error) {
+	rows, err := r.db.Query(`
+		SELECT `+jobColumns+`
+		FROM queued_jobs
+		WHERE ($1 = '' OR status = $1) AND ($2 = '' OR kind = $2)
+		ORDER BY updated_at DESC
+		LIMIT $3
+	`, string(status), kind, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	jobs := []*queue.Job{}
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
+func (r *jobRepository) CountByStatus() (map[queue.Status]int, error) {
+	rows, err := r.db.Query(`SELECT status, COUNT(*) FROM queued_jobs GROUP BY status`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count jobs: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	counts := map[queue.Status]int{}
+	for rows.Next() {
+		var status queue.Status
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan job count: %w", err)
+		}
+		counts[status] = count
+	}
+
+	return counts, rows.Err()
+}
@@ -0,0 +1,415 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/loan"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/google/uuid"
+)
+
+type LoanRepository interface {
+	ListProducts() ([]*loan.Product, error)
+	GetProduct(code string) (*loan.Product, error)
+
+	Create(l *loan.Loan) error
+	GetByID(id uuid.UUID) (*loan.Loan, error)
+	ListByUser(userID uuid.UUID) ([]*loan.Loan, error)
+	ListByStatus(status loan.Status, limit int) ([]*loan.Loan, error)
+	CountPendingByUser(userID uuid.UUID) (int, error)
+
+	// Cancel and Reject close a pending application
+	Cancel(id uuid.UUID) error
+	Reject(id, officerID uuid.UUID, reason string) error
+	// Disburse approves a pending application: it credits the account, stores the
+	// schedule and activates the loan in one transaction
+	Disburse(id, officerID uuid.UUID, schedule []*loan.Installment, txn *transaction.Transaction) error
+
+	GetSchedule(loanID uuid.UUID) ([]*loan.Installment, error)
+	// ListDueInstallments returns unpaid installments of active loans due on or
+	// before today that have not been attempted today
+	ListDueInstallments(today time.Time, limit int) ([]*loan.Installment, error)
+	// CollectInstallment debits the installment from the loan account. When the
+	// balance is short it records the attempt and reports false.
+	CollectInstallment(installmentID uuid.UUID, txn *transaction.Transaction, today time.Time) (bool, error)
+}
+
+type loanRepository struct {
+	db *sql.DB
+}
+
+func NewLoanRepository(db *sql.DB) LoanRepository {
+	return &loanRepository{db: db}
+}
+
+const loanProductColumns = `code, name, min_amount, max_amount, min_tenor_months, max_tenor_months, annual_rate,
+	provision_fee_rate, active, created_at`
+
+func scanLoanProduct(row rowScanner) (*loan.Product, error) {
+	p := &loan.Product{}
+	err := row.Scan(
+		&p.Code, &p.Name, &p.MinAmount, &p.MaxAmount, &p.MinTenorMonths, &p.MaxTenorMonths, &p.AnnualRate,
+		&p.ProvisionFeeRate, &p.Active, &p.CreatedAt,
+	)
+	return p, err
+}
+
+const loanColumns = `id, user_id, product_code, account_id, principal, tenor_months, annual_rate, monthly_installment,
+	provision_fee, outstanding_principal, COALESCE(purpose, ''), status, COALESCE(decision_reason, ''), decided_by,
+	disbursement_transaction_id, created_at, updated_at, decided_at, disbursed_at, closed_at`
+
+func scanLoan(row rowScanner) (*loan.Loan, error) {
+	l := &loan.Loan{}
+	err := row.Scan(
+		&l.ID, &l.UserID, &l.ProductCode, &l.AccountID, &l.Principal, &l.TenorMonths, &l.AnnualRate, &l.MonthlyInstallment,
+		&l.ProvisionFee, &l.OutstandingPrincipal, &l.Purpose, &l.Status, &l.DecisionReason, &l.DecidedBy,
+		&l.DisbursementTransactionID, &l.CreatedAt, &l.UpdatedAt, &l.DecidedAt, &l.DisbursedAt, &l.ClosedAt,
+	)
+	return l, err
+}
+
+const installmentColumns = `id, loan_id, number, due_date, principal, interest, amount, status, debit_attempts,
+	last_attempt_date, paid_at, transaction_id`
+
+func scanInstallment(row rowScanner) (*loan.Installment, error) {
+	i := &loan.Installment{}
+	err := row.Scan(
+		&i.ID, &i.LoanID, &i.Number, &i.DueDate, &i.Principal, &i.Interest, &i.Amount, &i.Status, &i.DebitAttempts,
+		&i.LastAttemptDate, &i.PaidAt, &i.TransactionID,
+	)
+	return i, err
+}
+
+func (r *loanRepository) ListProducts() ([]*loan.Product, error) {
+	rows, err := r.db.Query(`SELECT ` + loanProductColumns + ` FROM loan_products WHERE active = TRUE ORDER BY min_amount`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list loan products: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	products := []*loan.Product{}
+	for rows.Next() {
+		p, err := scanLoanProduct(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan loan product: %w", err)
+		}
+		products = append(products, p)
+	}
+
+	return products, rows.Err()
+}
+
+func (r *loanRepository) GetProduct(code string) (*loan.Product, error) {
+	p, err := scanLoanProduct(r.db.QueryRow(`SELECT `+loanProductColumns+` FROM loan_products WHERE code = $1`, code))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("loan product not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loan product: %w", err)
+	}
+
+	return p, nil
+}
+
+func (r *loanRepository) Create(l *loan.Loan) error {
+	err := r.db.QueryRow(`
+		INSERT INTO loans (id, user_id, product_code, account_id, principal, tenor_months, annual_rate,
+		                   monthly_installment, provision_fee, purpose, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10, ''), $11)
+		RETURNING created_at, updated_at
+	`, l.ID, l.UserID, l.ProductCode, l.AccountID, l.Principal, l.TenorMonths, l.AnnualRate,
+		l.MonthlyInstallment, l.ProvisionFee, l.Purpose, l.Status,
+	).Scan(&l.CreatedAt, &l.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create loan: %w", err)
+	}
+
+	return nil
+}
+
+func (r *loanRepository) GetByID(id uuid.UUID) (*loan.Loan, error) {
+	l, err := scanLoan(r.db.QueryRow(`SELECT `+loanColumns+` FROM loans WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("loan not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loan: %w", err)
+	}
+
+	return l, nil
+}
+
+func (r *loanRepository) ListByUser(userID uuid.UUID) ([]*loan.Loan, error) {
+	return r.list(`SELECT `+loanColumns+` FROM loans WHERE user_id = $1 ORDER BY created_at DESC`, userID)
+}
+
+func (r *loanRepository) ListByStatus(status loan.Status, limit int) ([]*loan.Loan, error) {
+	return r.list(`SELECT `+loanColumns+` FROM loans WHERE status = $1 ORDER BY created_at LIMIT $2`, status, limit)
+}
+
+func (r *loanRepository) list(query string, args ...interface{}) ([]*loan.Loan, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list loans: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	loans := []*loan.Loan{}
+	for rows.Next() {
+		l, err := scanLoan(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan loan: %w", err)
+		}
+		loans = append(loans, l)
+	}
+
+	return loans, rows.Err()
+}
+
+func (r *loanRepository) CountPendingByUser(userID uuid.UUID) (int, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM loans WHERE user_id = $1 AND status = 'pending'`, userID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count pending loans: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *loanRepository) Cancel(id uuid.UUID) error {
+	result, err := r.db.Exec(`
+		UPDATE loans SET status = 'cancelled', closed_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = 'pending'
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to cancel loan: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("loan application is no longer pending")
+	}
+
+	return nil
+}
+
+func (r *loanRepository) Reject(id, officerID uuid.UUID, reason string) error {
+	result, err := r.db.Exec(`
+		UPDATE loans
+		SET status = 'rejected', decision_reason = $1, decided_by = $2, decided_at = CURRENT_TIMESTAMP, closed_at = CURRENT_TIMESTAMP
+		WHERE id = $3 AND status = 'pending'
+	`, reason, officerID, id)
+	if err != nil {
+		return fmt.Errorf("failed to reject loan: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("loan application is no longer pending")
+	}
+
+	return nil
+}
+
+func (r *loanRepository) Disburse(id, officerID uuid.UUID, schedule []*loan.Installment, txn *transaction.Transaction) error {
+	dbTx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = dbTx.Rollback() // Rollback if not committed
+	}()
+
+	var status loan.Status
+	var principal float64
+	err = dbTx.QueryRow(`SELECT status, principal FROM loans WHERE id = $1 FOR UPDATE`, id).Scan(&status, &principal)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("loan not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock loan: %w", err)
+	}
+	if status != loan.StatusPending {
+		return fmt.Errorf("loan application is no longer pending")
+	}
+
+	result, err := dbTx.Exec(`
+		UPDATE accounts SET balance = balance + $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND status = 'active'
+	`, txn.Amount, txn.ToAccountID)
+	if err != nil {
+		return fmt.Errorf("failed to credit account: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("disbursement account is not active")
+	}
+
+	metadataJSON, _ := json.Marshal(txn.Metadata)
+	_, err = dbTx.Exec(`
+		INSERT INTO transactions (id, idempotency_key, to_account_id, amount, transaction_type, status, description, metadata, completed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, CURRENT_TIMESTAMP)
+	`, txn.ID, txn.IdempotencyKey, txn.ToAccountID, txn.Amount, txn.TransactionType, transaction.TransactionStatusCompleted, txn.Description, metadataJSON)
+	if err != nil {
+		return fmt.Errorf("failed to insert transaction: %w", err)
+	}
+
+	for _, inst := range schedule {
+		_, err = dbTx.Exec(`
+			INSERT INTO loan_installments (id, loan_id, number, due_date, principal, interest, amount, status)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`, inst.ID, id, inst.Number, inst.DueDate, inst.Principal, inst.Interest, inst.Amount, inst.Status)
+		if err != nil {
+			return fmt.Errorf("failed to insert installment: %w", err)
+		}
+	}
+
+	_, err = dbTx.Exec(`
+		UPDATE loans
+		SET status = 'active', outstanding_principal = principal, decided_by = $1, decided_at = CURRENT_TIMESTAMP,
+		    disbursed_at = CURRENT_TIMESTAMP, disbursement_transaction_id = $2
+		WHERE id = $3
+	`, officerID, txn.ID, id)
+	if err != nil {
+		return fmt.Errorf("failed to activate loan: %w", err)
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *loanRepository) GetSchedule(loanID uuid.UUID) ([]*loan.Installment, error) {
+	return r.listInstallments(`SELECT `+installmentColumns+` FROM loan_installments WHERE loan_id = $1 ORDER BY number`, loanID)
+}
+
+func (r *loanRepository) ListDueInstallments(today time.Time, limit int) ([]*loan.Installment, error) {
+	return r.listInstallments(`
+		SELECT `+installmentColumns+` FROM loan_installments
+		WHERE status = 'pending' AND due_date <= $1
+		  AND (last_attempt_date IS NULL OR last_attempt_date < $1)
+		  AND loan_id IN (SELECT id FROM loans WHERE status = 'active')
+		ORDER BY due_date, number
+		LIMIT $2
+	`, today, limit)
+}
+
+func (r *loanRepository) listInstallments(query string, args ...interface{}) ([]*loan.Installment, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list installments: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	installments := []*loan.Installment{}
+	for rows.Next() {
+		i, err := scanInstallment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan installment: %w", err)
+		}
+		installments = append(installments, i)
+	}
+
+	return installments, rows.Err()
+}
+
+func (r *loanRepository) CollectInstallment(installmentID uuid.UUID, txn *transaction.Transaction, today time.Time) (bool, error) {
+	dbTx, err := r.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = dbTx.Rollback() // Rollback if not committed
+	}()
+
+	var loanID uuid.UUID
+	var status loan.InstallmentStatus
+	var amount, principal float64
+	err = dbTx.QueryRow(`
+		SELECT loan_id, status, amount, principal FROM loan_installments WHERE id = $1 FOR UPDATE
+	`, installmentID).Scan(&loanID, &status, &amount, &principal)
+	if err != nil {
+		return false, fmt.Errorf("failed to lock installment: %w", err)
+	}
+	if status != loan.InstallmentStatusPending {
+		return false, fmt.Errorf("installment is already %s", status)
+	}
+
+	held, err := lockBalance(dbTx, *txn.FromAccountID, "")
+	if err != nil && err != sql.ErrNoRows {
+		return false, fmt.Errorf("failed to lock account: %w", err)
+	}
+
+	// A frozen account or a short balance is retried on the next day
+	if err == sql.ErrNoRows || held.available < amount {
+		_, err = dbTx.Exec(`
+			UPDATE loan_installments SET debit_attempts = debit_attempts + 1, last_attempt_date = $1 WHERE id = $2
+		`, today, installmentID)
+		if err != nil {
+			return false, fmt.Errorf("failed to record debit attempt: %w", err)
+		}
+		if err := dbTx.Commit(); err != nil {
+			return false, fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		return false, nil
+	}
+
+	if err := held.add(dbTx, -amount); err != nil {
+		return false, fmt.Errorf("failed to debit account: %w", err)
+	}
+
+	metadataJSON, _ := json.Marshal(txn.Metadata)
+	_, err = dbTx.Exec(`
+		INSERT INTO transactions (id, idempotency_key, from_account_id, amount, transaction_type, status, description, metadata, completed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, CURRENT_TIMESTAMP)
+	`, txn.ID, txn.IdempotencyKey, txn.FromAccountID, amount, txn.TransactionType, transaction.TransactionStatusCompleted, txn.Description, metadataJSON)
+	if err != nil {
+		return false, fmt.Errorf("failed to insert transaction: %w", err)
+	}
+
+	_, err = dbTx.Exec(`
+		UPDATE loan_installments
+		SET status = 'paid', paid_at = CURRENT_TIMESTAMP, transaction_id = $1,
+		    debit_attempts = debit_attempts + 1, last_attempt_date = $2
+		WHERE id = $3
+	`, txn.ID, today, installmentID)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark installment paid: %w", err)
+	}
+
+	// The loan is paid off with its last pending installment
+	_, err = dbTx.Exec(`
+		UPDATE loans
+		SET outstanding_principal = GREATEST(outstanding_principal - $1, 0),
+		    status = CASE WHEN NOT EXISTS (
+		        SELECT 1 FROM loan_installments WHERE loan_id = $2 AND status = 'pending'
+		    ) THEN 'paid_off' ELSE status END,
+		    closed_at = CASE WHEN NOT EXISTS (
+		        SELECT 1 FROM loan_installments WHERE loan_id = $2 AND status = 'pending'
+		    ) THEN CURRENT_TIMESTAMP ELSE closed_at END
+		WHERE id = $2
+	`, principal, loanID)
+	if err != nil {
+		return false, fmt.Errorf("failed to update loan balance: %w", err)
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,637 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/merchant"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/google/uuid"
+)
+
+type MerchantRepository interface {
+	Create(m *merchant.Merchant) error
+	GetByID(id uuid.UUID) (*merchant.Merchant, error)
+	GetByAPIKeyHash(hash string) (*merchant.Merchant, error)
+	ListByOwner(ownerID uuid.UUID) ([]*merchant.Merchant, error)
+	UpdateAPIKey(id uuid.UUID, hint, hash string) error
+	// UpdateWebhook sets the merchant's webhook URL; an empty URL removes it
+	UpdateWebhook(id uuid.UUID, url string) error
+	UpdateWebhookSecret(id uuid.UUID, secret string) error
+
+	CreatePaymentLink(link *merchant.PaymentLink) error
+	GetPaymentLink(id uuid.UUID) (*merchant.PaymentLink, error)
+	ListPaymentLinks(merchantID uuid.UUID, limit int) ([]*merchant.PaymentLink, error)
+	// PayPaymentLink debits the payer and marks the link paid. The funds are
+	// held until the merchant's next settlement.
+	PayPaymentLink(linkID, payerAccountID uuid.UUID, txn *transaction.Transaction) error
+	// CancelPaymentLink closes an open link of the merchant so it can no longer be paid
+	CancelPaymentLink(linkID, merchantID uuid.UUID) error
+
+	// ListUnsettledMerchants returns merchants with payments received before the cutoff
+	// that have not been settled yet
+	ListUnsettledMerchants(cutoff time.Time) ([]uuid.UUID, error)
+	// Settle pays out the merchant's unsettled payments received before the cutoff,
+	// net of the MDR fee. It returns nil when there was nothing to settle.
+	Settle(merchantID uuid.UUID, cutoff, businessDate time.Time) (*merchant.Settlement, error)
+	ListSettlements(merchantID uuid.UUID, limit int) ([]*merchant.Settlement, error)
+
+	ListPendingNotifications(now time.Time, limit int) ([]*merchant.PendingNotification, error)
+	MarkNotified(linkID uuid.UUID) error
+	// RecordNotifyFailure schedules the next attempt; a nil nextAttemptAt stops retrying
+	RecordNotifyFailure(linkID uuid.UUID, nextAttemptAt *time.Time) error
+	// ScheduleRedelivery queues the payment notifications of links paid since
+	// the given time to be delivered again, with a fresh retry budget
+	ScheduleRedelivery(merchantID uuid.UUID, since time.Time) (int, error)
+	// RequeueFailedNotifications queues the payment notifications of links
+	// paid since the given time that ran out of retries, with a fresh retry
+	// budget
+	RequeueFailedNotifications(since time.Time) (int, error)
+	RecordWebhookDelivery(d *merchant.WebhookDelivery) error
+	// ListWebhookDeliveries returns the merchant's latest webhook attempts, newest first
+	ListWebhookDeliveries(merchantID uuid.UUID, limit int) ([]*merchant.WebhookDelivery, error)
+}
+
+type merchantRepository struct {
+	db    *sql.DB
+	hooks []DebitHook
+}
+
+// NewMerchantRepository returns the repository. The hooks run after every
+// payment link payment.
+func NewMerchantRepository(db *sql.DB, hooks ...DebitHook) MerchantRepository {
+	return &merchantRepository{db: db, hooks: hooks}
+}
+
+const merchantColumns = `id, owner_id, name, category, settlement_account_id, COALESCE(webhook_url, ''),
+	webhook_secret, api_key_hint, api_key_hash, status, created_at, updated_at`
+
+func scanMerchant(row rowScanner) (*merchant.Merchant, error) {
+	m := &merchant.Merchant{}
+	err := row.Scan(
+		&m.ID, &m.OwnerID, &m.Name, &m.Category, &m.SettlementAccountID, &m.WebhookURL,
+		&m.WebhookSecret, &m.APIKeyHint, &m.APIKeyHash, &m.Status, &m.CreatedAt, &m.UpdatedAt,
+	)
+	return m, err
+}
+
+const paymentLinkColumns = `l.id, l.merchant_id, m.name, l.reference, l.amount, COALESCE(l.description, ''), l.status,
+	l.expires_at, l.paid_at, l.payer_account_id, l.transaction_id, l.settlement_id, l.created_at`
+
+func scanPaymentLink(row rowScanner) (*merchant.PaymentLink, error) {
+	l := &merchant.PaymentLink{}
+	err := row.Scan(
+		&l.ID, &l.MerchantID, &l.MerchantName, &l.Reference, &l.Amount, &l.Description, &l.Status,
+		&l.ExpiresAt, &l.PaidAt, &l.PayerAccountID, &l.TransactionID, &l.SettlementID, &l.CreatedAt,
+	)
+	return l, err
+}
+
+const settlementColumns = `id, merchant_id, account_id, business_date, payment_count, gross_amount, fee_amount,
+	net_amount, transaction_id, created_at`
+
+func scanSettlement(row rowScanner) (*merchant.Settlement, error) {
+	s := &merchant.Settlement{}
+	err := row.Scan(
+		&s.ID, &s.MerchantID, &s.AccountID, &s.BusinessDate, &s.PaymentCount, &s.GrossAmount, &s.FeeAmount,
+		&s.NetAmount, &s.TransactionID, &s.CreatedAt,
+	)
+	return s, err
+}
+
+func (r *merchantRepository) Create(m *merchant.Merchant) error {
+	err := r.db.QueryRow(`
+		INSERT INTO merchants (id, owner_id, name, category, settlement_account_id, webhook_url, webhook_secret,
+		                       api_key_hint, api_key_hash, status)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8, $9, $10)
+		RETURNING created_at, updated_at
+	`, m.ID, m.OwnerID, m.Name, m.Category, m.SettlementAccountID, m.WebhookURL, m.WebhookSecret,
+		m.APIKeyHint, m.APIKeyHash, m.Status,
+	).Scan(&m.CreatedAt, &m.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create merchant: %w", err)
+	}
+
+	return nil
+}
+
+func (r *merchantRepository) GetByID(id uuid.UUID) (*merchant.Merchant, error) {
+	return r.getOne(`SELECT `+merchantColumns+` FROM merchants WHERE id = $1`, id)
+}
+
+func (r *merchantRepository) GetByAPIKeyHash(hash string) (*merchant.Merchant, error) {
+	return r.getOne(`SELECT `+merchantColumns+` FROM merchants WHERE api_key_hash = $1`, hash)
+}
+
+func (r *merchantRepository) getOne(query string, arg interface{}) (*merchant.Merchant, error) {
+	m, err := scanMerchant(r.db.QueryRow(query, arg))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("merchant not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get merchant: %w", err)
+	}
+	return m, nil
+}
+
+func (r *merchantRepository) ListByOwner(ownerID uuid.UUID) ([]*merchant.Merchant, error) {
+	rows, err := r.db.Query(`SELECT `+merchantColumns+` FROM merchants WHERE owner_id = $1 ORDER BY created_at`, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list merchants: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	merchants := []*merchant.Merchant{}
+	for rows.Next() {
+		m, err := scanMerchant(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan merchant: %w", err)
+		}
+		merchants = append(merchants, m)
+	}
+
+	return merchants, rows.Err()
+}
+
+func (r *merchantRepository) UpdateAPIKey(id uuid.UUID, hint, hash string) error {
+	result, err := r.db.Exec(`UPDATE merchants SET api_key_hint = $1, api_key_hash = $2 WHERE id = $3`, hint, hash, id)
+	if err != nil {
+		return fmt.Errorf("failed to update API key: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("merchant not found")
+	}
+
+	return nil
+}
+
+func (r *merchantRepository) UpdateWebhook(id uuid.UUID, url string) error {
+	return r.updateMerchant(`UPDATE merchants SET webhook_url = NULLIF($1, ''), updated_at = CURRENT_TIMESTAMP WHERE id = $2`, url, id)
+}
+
+func (r *merchantRepository) UpdateWebhookSecret(id uuid.UUID, secret string) error {
+	return r.updateMerchant(`UPDATE merchants SET webhook_secret = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`, secret, id)
+}
+
+// updateMerchant runs an update of one merchant, failing when it does not exist
+func (r *merchantRepository) updateMerchant(query string, args ...interface{}) error {
+	result, err := r.db.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update merchant: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("merchant not found")
+	}
+
+	return nil
+}
+
+func (r *merchantRepository) CreatePaymentLink(link *merchant.PaymentLink) error {
+	err := r.db.QueryRow(`
+		INSERT INTO payment_links (id, merchant_id, reference, amount, description, status, expires_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7)
+		RETURNING created_at
+	`, link.ID, link.MerchantID, link.Reference, link.Amount, link.Description, link.Status, link.ExpiresAt,
+	).Scan(&link.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create payment link: %w", err)
+	}
+
+	return nil
+}
+
+func (r *merchantRepository) GetPaymentLink(id uuid.UUID) (*merchant.PaymentLink, error) {
+	l, err := scanPaymentLink(r.db.QueryRow(`
+		SELECT `+paymentLinkColumns+`
+		FROM payment_links l JOIN merchants m ON m.id = l.merchant_id
+		WHERE l.id = $1
+	`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("payment link not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment link: %w", err)
+	}
+
+	return l, nil
+}
+
+func (r *merchantRepository) ListPaymentLinks(merchantID uuid.UUID, limit int) ([]*merchant.PaymentLink, error) {
+	rows, err := r.db.Query(`
+		SELECT `+paymentLinkColumns+`
+		FROM payment_links l JOIN merchants m ON m.id = l.merchant_id
+		WHERE l.merchant_id = $1
+		ORDER BY l.created_at DESC
+		LIMIT $2
+	`, merchantID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payment links: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	links := []*merchant.PaymentLink{}
+	for rows.Next() {
+		l, err := scanPaymentLink(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan payment link: %w", err)
+		}
+		links = append(links, l)
+	}
+
+	return links, rows.Err()
+}
+
+func (r *merchantRepository) PayPaymentLink(linkID, payerAccountID uuid.UUID, txn *transaction.Transaction) error {
+	dbTx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = dbTx.Rollback() // Rollback if not committed
+	}()
+
+	// Lock the link first so two payers cannot both pay it
+	var status merchant.PaymentLinkStatus
+	var amount float64
+	var expired, hasWebhook bool
+	err = dbTx.QueryRow(`
+		SELECT l.status, l.amount, l.expires_at <= CURRENT_TIMESTAMP, m.webhook_url IS NOT NULL
+		FROM payment_links l JOIN merchants m ON m.id = l.merchant_id
+		WHERE l.id = $1
+		FOR UPDATE OF l
+	`, linkID).Scan(&status, &amount, &expired, &hasWebhook)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("payment link not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock payment link: %w", err)
+	}
+
+	if status != merchant.PaymentLinkStatusOpen {
+		return fmt.Errorf("payment link is %s", status)
+	}
+	if expired {
+		return fmt.Errorf("payment link is %s", merchant.PaymentLinkStatusExpired)
+	}
+
+	held, err := lockBalance(dbTx, payerAccountID, "")
+	if err != nil {
+		return fmt.Errorf("failed to lock account: %w", err)
+	}
+
+	if held.available < amount {
+		return insufficientFunds(held.available, amount)
+	}
+
+	if err := held.add(dbTx, -amount); err != nil {
+		return fmt.Errorf("failed to debit account: %w", err)
+	}
+
+	metadataJSON, _ := json.Marshal(txn.Metadata)
+	_, err = dbTx.Exec(`
+		INSERT INTO transactions (id, idempotency_key, from_account_id, amount, transaction_type, status, description, metadata, completed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, CURRENT_TIMESTAMP)
+	`, txn.ID, txn.IdempotencyKey, payerAccountID, amount, txn.TransactionType, transaction.TransactionStatusCompleted, txn.Description, metadataJSON)
+	if err != nil {
+		return fmt.Errorf("failed to insert transaction: %w", err)
+	}
+
+	txn.FromAccountID, txn.Amount = &payerAccountID, amount
+	if err := runDebitHooks(dbTx, r.hooks, txn); err != nil {
+		return err
+	}
+
+	// Links of merchants without a webhook are never queued for notification
+	_, err = dbTx.Exec(`
+		UPDATE payment_links
+		SET status = 'paid', paid_at = CURRENT_TIMESTAMP, payer_account_id = $1, transaction_id = $2,
+		    next_notify_at = CASE WHEN $3 THEN CURRENT_TIMESTAMP END
+		WHERE id = $4
+	`, payerAccountID, txn.ID, hasWebhook, linkID)
+	if err != nil {
+		return fmt.Errorf("failed to mark payment link paid: %w", err)
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *merchantRepository) CancelPaymentLink(linkID, merchantID uuid.UUID) error {
+	result, err := r.db.Exec(`
+		UPDATE payment_links SET status = 'cancelled'
+		WHERE id = $1 AND merchant_id = $2 AND status = 'open'
+	`, linkID, merchantID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel payment link: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("payment link not found or not open")
+	}
+
+	return nil
+}
+
+func (r *merchantRepository) ListUnsettledMerchants(cutoff time.Time) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(`
+		SELECT DISTINCT merchant_id FROM payment_links
+		WHERE status = 'paid' AND settlement_id IS NULL AND paid_at < $1
+	`, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unsettled merchants: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan merchant id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+func (r *merchantRepository) Settle(merchantID uuid.UUID, cutoff, businessDate time.Time) (*merchant.Settlement, error) {
+	dbTx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = dbTx.Rollback() // Rollback if not committed
+	}()
+
+	var accountID uuid.UUID
+	var name string
+	err = dbTx.QueryRow(`SELECT settlement_account_id, name FROM merchants WHERE id = $1 FOR UPDATE`, merchantID).Scan(&accountID, &name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock merchant: %w", err)
+	}
+
+	rows, err := dbTx.Query(`
+		SELECT id, amount FROM payment_links
+		WHERE merchant_id = $1 AND status = 'paid' AND settlement_id IS NULL AND paid_at < $2
+		FOR UPDATE
+	`, merchantID, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock payments: %w", err)
+	}
+	linkIDs := []uuid.UUID{}
+	var gross float64
+	for rows.Next() {
+		var id uuid.UUID
+		var amount float64
+		if err := rows.Scan(&id, &amount); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("failed to scan payment: %w", err)
+		}
+		linkIDs = append(linkIDs, id)
+		gross += amount
+	}
+	if err := rows.Err(); err != nil {
+		_ = rows.Close()
+		return nil, fmt.Errorf("failed to read payments: %w", err)
+	}
+	_ = rows.Close()
+
+	if len(linkIDs) == 0 {
+		return nil, nil
+	}
+
+	fee := merchant.SettlementFee(gross)
+	s := &merchant.Settlement{
+		ID:            uuid.New(),
+		MerchantID:    merchantID,
+		AccountID:     accountID,
+		BusinessDate:  businessDate,
+		PaymentCount:  len(linkIDs),
+		GrossAmount:   gross,
+		FeeAmount:     fee,
+		NetAmount:     gross - fee,
+		TransactionID: uuid.New(),
+	}
+
+	_, err = dbTx.Exec(`UPDATE accounts SET balance = balance + $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`, s.NetAmount, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to credit settlement account: %w", err)
+	}
+
+	metadataJSON, _ := json.Marshal(map[string]interface{}{
+		"merchant_id":   merchantID.String(),
+		"settlement_id": s.ID.String(),
+		"business_date": businessDate.Format("2006-01-02"),
+		"payment_count": s.PaymentCount,
+		"gross_amount":  s.GrossAmount,
+		"fee_amount":    s.FeeAmount,
+	})
+	_, err = dbTx.Exec(`
+		INSERT INTO transactions (id, idempotency_key, to_account_id, amount, transaction_type, status, description, metadata, completed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, CURRENT_TIMESTAMP)
+	`, s.TransactionID, fmt.Sprintf("merchant-settlement:%s:%s", merchantID, businessDate.Format("2006-01-02")), accountID,
+		s.NetAmount, transaction.TransactionTypeMerchantSettlement, transaction.TransactionStatusCompleted,
+		fmt.Sprintf("%s settlement %s", name, businessDate.Format("2006-01-02")), metadataJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert transaction: %w", err)
+	}
+
+	err = dbTx.QueryRow(`
+		INSERT INTO merchant_settlements (id, merchant_id, account_id, business_date, payment_count, gross_amount,
+		                                  fee_amount, net_amount, transaction_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING created_at
+	`, s.ID, s.MerchantID, s.AccountID, s.BusinessDate, s.PaymentCount, s.GrossAmount, s.FeeAmount, s.NetAmount, s.TransactionID,
+	).Scan(&s.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create settlement: %w", err)
+	}
+
+	for _, id := range linkIDs {
+		if _, err := dbTx.Exec(`UPDATE payment_links SET settlement_id = $1 WHERE id = $2`, s.ID, id); err != nil {
+			return nil, fmt.Errorf("failed to mark payment settled: %w", err)
+		}
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return s, nil
+}
+
+func (r *merchantRepository) ListSettlements(merchantID uuid.UUID, limit int) ([]*merchant.Settlement, error) {
+	rows, err := r.db.Query(`
+		SELECT `+settlementColumns+` FROM merchant_settlements
+		WHERE merchant_id = $1
+		ORDER BY business_date DESC
+		LIMIT $2
+	`, merchantID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list settlements: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	settlements := []*merchant.Settlement{}
+	for rows.Next() {
+		s, err := scanSettlement(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan settlement: %w", err)
+		}
+		settlements = append(settlements, s)
+	}
+
+	return settlements, rows.Err()
+}
+
+func (r *merchantRepository) ListPendingNotifications(now time.Time, limit int) ([]*merchant.PendingNotification, error) {
+	rows, err := r.db.Query(`
+		SELECT `+paymentLinkColumns+`, m.webhook_url, m.webhook_secret, l.notify_attempts
+		FROM payment_links l JOIN merchants m ON m.id = l.merchant_id
+		WHERE l.notified_at IS NULL AND l.next_notify_at <= $1 AND m.webhook_url IS NOT NULL
+		ORDER BY l.next_notify_at
+		LIMIT $2
+	`, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending notifications: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	pending := []*merchant.PendingNotification{}
+	for rows.Next() {
+		l := &merchant.PaymentLink{}
+		n := &merchant.PendingNotification{Link: l}
+		err := rows.Scan(
+			&l.ID, &l.MerchantID, &l.MerchantName, &l.Reference, &l.Amount, &l.Description, &l.Status,
+			&l.ExpiresAt, &l.PaidAt, &l.PayerAccountID, &l.TransactionID, &l.SettlementID, &l.CreatedAt,
+			&n.WebhookURL, &n.WebhookSecret, &n.Attempts,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		pending = append(pending, n)
+	}
+
+	return pending, rows.Err()
+}
+
+func (r *merchantRepository) MarkNotified(linkID uuid.UUID) error {
+	_, err := r.db.Exec(`
+		UPDATE payment_links
+		SET notified_at = CURRENT_TIMESTAMP, notify_attempts = notify_attempts + 1, next_notify_at = NULL
+		WHERE id = $1
+	`, linkID)
+	if err != nil {
+		return fmt.Errorf("failed to mark notification delivered: %w", err)
+	}
+
+	return nil
+}
+
+func (r *merchantRepository) RecordNotifyFailure(linkID uuid.UUID, nextAttemptAt *time.Time) error {
+	_, err := r.db.Exec(`
+		UPDATE payment_links
+		SET notify_attempts = notify_attempts + 1, next_notify_at = $1
+		WHERE id = $2
+	`, nextAttemptAt, linkID)
+	if err != nil {
+		return fmt.Errorf("failed to record notification failure: %w", err)
+	}
+
+	return nil
+}
+
+func (r *merchantRepository) ScheduleRedelivery(merchantID uuid.UUID, since time.Time) (int, error) {
+	result, err := r.db.Exec(`
+		UPDATE payment_links
+		SET notified_at = NULL, notify_attempts = 0, next_notify_at = CURRENT_TIMESTAMP
+		WHERE merchant_id = $1 AND status = 'paid' AND transaction_id IS NOT NULL AND paid_at >= $2
+	`, merchantID, since)
+	if err != nil {
+		return 0, fmt.Errorf("failed to schedule notification redelivery: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
+
+func (r *merchantRepository) RequeueFailedNotifications(since time.Time) (int, error) {
+	result, err := r.db.Exec(`
+		UPDATE payment_links
+		SET notify_attempts = 0, next_notify_at = CURRENT_TIMESTAMP
+		WHERE status = 'paid' AND transaction_id IS NOT NULL AND paid_at >= $1
+		  AND notified_at IS NULL AND next_notify_at IS NULL AND notify_attempts > 0
+	`, since)
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue failed notifications: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
+
+func (r *merchantRepository) RecordWebhookDelivery(d *merchant.WebhookDelivery) error {
+	err := r.db.QueryRow(`
+		INSERT INTO webhook_deliveries (id, merchant_id, event, url, payment_link_id, status_code, success, error, duration_ms)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, 0), $7, NULLIF($8, ''), $9)
+		RETURNING created_at
+	`, d.ID, d.MerchantID, d.Event, d.URL, d.PaymentLinkID, d.StatusCode, d.Success, d.Error, d.DurationMs).Scan(&d.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook delivery: %w", err)
+	}
+	return nil
+}
+
+func (r *merchantRepository) ListWebhookDeliveries(merchantID uuid.UUID, limit int) ([]*merchant.WebhookDelivery, error) {
+	rows, err := r.db.Query(`
+		SELECT id, merchant_id, event, url, payment_link_id, COALESCE(status_code, 0), success,
+		       COALESCE(error, ''), duration_ms, created_at
+		FROM webhook_deliveries
+		WHERE merchant_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`, merchantID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	deliveries := []*merchant.WebhookDelivery{}
+	for rows.Next() {
+		d := &merchant.WebhookDelivery{}
+		if err := rows.Scan(&d.ID, &d.MerchantID, &d.Event, &d.URL, &d.PaymentLinkID, &d.StatusCode, &d.Success,
+			&d.Error, &d.DurationMs, &d.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/darisadam/madabank-server/internal/domain/note"
+	"github.com/google/uuid"
+)
+
+type NoteRepository interface {
+	Create(n *note.Note) error
+	// ListBySubject returns the notes on a transaction or user, oldest first,
+	// with their authors' emails
+	ListBySubject(subjectType note.SubjectType, subjectID uuid.UUID) ([]*note.Note, error)
+}
+
+type noteRepository struct {
+	db *sql.DB
+}
+
+func NewNoteRepository(db *sql.DB) NoteRepository {
+	return &noteRepository{db: db}
+}
+
+func (r *noteRepository) Create(n *note.Note) error {
+	err := r.db.QueryRow(`
+		INSERT INTO internal_notes (id, subject_type, subject_id, body, author_id)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at
+	`, n.ID, n.SubjectType, n.SubjectID, n.Body, n.AuthorID).Scan(&n.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create note: %w", err)
+	}
+
+	return nil
+}
+
+func (r *noteRepository) ListBySubject(subjectType note.SubjectType, subjectID uuid.UUID) ([]*note.Note, error) {
+	rows, err := r.db.Query(`
+		SELECT n.id, n.subject_type, n.subject_id, n.body, n.author_id, COALESCE(u.email, ''), n.created_at
+		FROM internal_notes n
+		LEFT JOIN users u ON u.id = n.author_id
+		WHERE n.subject_type = $1 AND n.subject_id = $2
+		ORDER BY n.created_at, n.id
+	`, subjectType, subjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notes: %w", err)
+	}
+	defer rows.Close()
+
+	notes := []*note.Note{}
+	for rows.Next() {
+		n := &note.Note{}
+		if err := rows.Scan(&n.ID, &n.SubjectType, &n.SubjectID, &n.Body, &n.AuthorID, &n.AuthorEmail, &n.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan note: %w", err)
+		}
+		notes = append(notes, n)
+	}
+
+	return notes, rows.Err()
+}
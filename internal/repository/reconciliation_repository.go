@@ -0,0 +1,269 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/reconciliation"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type ReconciliationRepository interface {
+	// SaveRun stores a run with the exceptions it found. An exception that is
+	// already open or under investigation is updated instead of duplicated, and
+	// the passed exception is refreshed with the stored row.
+	SaveRun(run *reconciliation.Run, exceptions []*reconciliation.Exception) error
+	// HasScheduledRun reports whether the nightly job already ran for the business date
+	HasScheduledRun(kind reconciliation.RunKind, businessDate time.Time) (bool, error)
+	ListRuns(limit int) ([]*reconciliation.Run, error)
+
+	ListExceptions(status reconciliation.ExceptionStatus, limit int) ([]*reconciliation.Exception, error)
+	GetException(id uuid.UUID) (*reconciliation.Exception, error)
+	// UpdateExceptionStatus moves an exception on from the given status. It fails
+	// if someone else moved it first.
+	UpdateExceptionStatus(id uuid.UUID, from, to reconciliation.ExceptionStatus, userID uuid.UUID, note string) error
+
+	// ListSettlementEntries returns our entries for a counterparty created in [from, to)
+	ListSettlementEntries(source reconciliation.Source, from, to time.Time) ([]*reconciliation.LedgerEntry, error)
+	// GetSettlementEntries looks up our entries for a counterparty by reference
+	GetSettlementEntries(source reconciliation.Source, references []string) ([]*reconciliation.LedgerEntry, error)
+}
+
+type reconciliationRepository struct {
+	db *sql.DB
+}
+
+func NewReconciliationRepository(db *sql.DB) ReconciliationRepository {
+	return &reconciliationRepository{db: db}
+}
+
+// settlementEntryQueries select reference, amount, status and whether the entry
+// is settled on our side, for each counterparty that sends settlement files.
+// The reference is the ID we sent the counterparty.
+var settlementEntryQueries = map[reconciliation.Source]string{
+	reconciliation.SourceBillerAggregator: `SELECT id::text, amount, status, status = 'completed' FROM transactions WHERE transaction_type = 'bill_payment'`,
+	reconciliation.SourceTopupAggregator:  `SELECT id::text, amount, status, status = 'success' FROM topups WHERE TRUE`,
+}
+
+const runColumns = `id, kind, source, business_date, COALESCE(file_name, ''), items_checked, breaks_found, started_by, created_at`
+
+func scanRun(row rowScanner) (*reconciliation.Run, error) {
+	run := &reconciliation.Run{}
+	err := row.Scan(
+		&run.ID, &run.Kind, &run.Source, &run.BusinessDate, &run.FileName,
+		&run.ItemsChecked, &run.BreaksFound, &run.StartedBy, &run.CreatedAt,
+	)
+	return run, err
+}
+
+const exceptionColumns = `id, run_id, exception_type, source, reference, account_id, expected_amount, actual_amount,
+	COALESCE(details, ''), status, assigned_to, COALESCE(resolution_note, ''), resolved_by, resolved_at,
+	detected_count, last_detected_at, created_at, updated_at`
+
+func scanException(row rowScanner) (*reconciliation.Exception, error) {
+	e := &reconciliation.Exception{}
+	var expected, actual sql.NullFloat64
+	err := row.Scan(
+		&e.ID, &e.RunID, &e.Type, &e.Source, &e.Reference, &e.AccountID, &expected, &actual,
+		&e.Details, &e.Status, &e.AssignedTo, &e.ResolutionNote, &e.ResolvedBy, &e.ResolvedAt,
+		&e.DetectedCount, &e.LastDetectedAt, &e.CreatedAt, &e.UpdatedAt,
+	)
+	if expected.Valid {
+		e.ExpectedAmount = &expected.Float64
+	}
+	if actual.Valid {
+		e.ActualAmount = &actual.Float64
+	}
+	return e, err
+}
+
+func (r *reconciliationRepository) SaveRun(run *reconciliation.Run, exceptions []*reconciliation.Exception) error {
+	dbTx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = dbTx.Rollback() // Rollback if not committed
+	}()
+
+	err = dbTx.QueryRow(`
+		INSERT INTO reconciliation_runs (id, kind, source, business_date, file_name, items_checked, breaks_found, started_by)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8)
+		RETURNING created_at
+	`, run.ID, run.Kind, run.Source, run.BusinessDate.Format(reconciliation.BusinessDateLayout), run.FileName,
+		run.ItemsChecked, run.BreaksFound, run.StartedBy,
+	).Scan(&run.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create reconciliation run: %w", err)
+	}
+
+	for _, e := range exceptions {
+		stored, err := scanException(dbTx.QueryRow(`
+			INSERT INTO reconciliation_exceptions
+				(id, run_id, exception_type, source, reference, account_id, expected_amount, actual_amount, details)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''))
+			ON CONFLICT (exception_type, source, reference) WHERE status IN ('open', 'investigating')
+			DO UPDATE SET run_id = EXCLUDED.run_id,
+			              expected_amount = EXCLUDED.expected_amount,
+			              actual_amount = EXCLUDED.actual_amount,
+			              details = EXCLUDED.details,
+			              detected_count = reconciliation_exceptions.detected_count + 1,
+			              last_detected_at = CURRENT_TIMESTAMP
+			RETURNING `+exceptionColumns,
+			e.ID, run.ID, e.Type, e.Source, e.Reference, e.AccountID, e.ExpectedAmount, e.ActualAmount, e.Details,
+		))
+		if err != nil {
+			return fmt.Errorf("failed to record reconciliation exception: %w", err)
+		}
+		*e = *stored
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *reconciliationRepository) HasScheduledRun(kind reconciliation.RunKind, businessDate time.Time) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM reconciliation_runs
+			WHERE kind = $1 AND business_date = $2 AND started_by IS NULL
+		)
+	`, kind, businessDate.Format(reconciliation.BusinessDateLayout)).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check reconciliation runs: %w", err)
+	}
+
+	return exists, nil
+}
+
+func (r *reconciliationRepository) ListRuns(limit int) ([]*reconciliation.Run, error) {
+	rows, err := r.db.Query(`SELECT `+runColumns+` FROM reconciliation_runs ORDER BY created_at DESC LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reconciliation runs: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	runs := []*reconciliation.Run{}
+	for rows.Next() {
+		run, err := scanRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan reconciliation run: %w", err)
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, rows.Err()
+}
+
+func (r *reconciliationRepository) ListExceptions(status reconciliation.ExceptionStatus, limit int) ([]*reconciliation.Exception, error) {
+	rows, err := r.db.Query(`
+		SELECT `+exceptionColumns+` FROM reconciliation_exceptions
+		WHERE status = $1
+		ORDER BY created_at
+		LIMIT $2
+	`, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reconciliation exceptions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	exceptions := []*reconciliation.Exception{}
+	for rows.Next() {
+		e, err := scanException(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan reconciliation exception: %w", err)
+		}
+		exceptions = append(exceptions, e)
+	}
+
+	return exceptions, rows.Err()
+}
+
+func (r *reconciliationRepository) GetException(id uuid.UUID) (*reconciliation.Exception, error) {
+	e, err := scanException(r.db.QueryRow(`SELECT `+exceptionColumns+` FROM reconciliation_exceptions WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("reconciliation exception not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reconciliation exception: %w", err)
+	}
+
+	return e, nil
+}
+
+func (r *reconciliationRepository) UpdateExceptionStatus(id uuid.UUID, from, to reconciliation.ExceptionStatus, userID uuid.UUID, note string) error {
+	var result sql.Result
+	var err error
+	if to.IsClosed() {
+		result, err = r.db.Exec(`
+			UPDATE reconciliation_exceptions
+			SET status = $1, resolution_note = NULLIF($2, ''), resolved_by = $3, resolved_at = CURRENT_TIMESTAMP
+			WHERE id = $4 AND status = $5
+		`, to, note, userID, id, from)
+	} else {
+		result, err = r.db.Exec(`
+			UPDATE reconciliation_exceptions SET status = $1, assigned_to = $2
+			WHERE id = $3 AND status = $4
+		`, to, userID, id, from)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update reconciliation exception: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("reconciliation exception was updated by someone else")
+	}
+
+	return nil
+}
+
+func (r *reconciliationRepository) ListSettlementEntries(source reconciliation.Source, from, to time.Time) ([]*reconciliation.LedgerEntry, error) {
+	query, ok := settlementEntryQueries[source]
+	if !ok {
+		return nil, fmt.Errorf("unsupported settlement source: %s", source)
+	}
+
+	return r.listEntries(query+` AND created_at >= $1 AND created_at < $2`, from.UTC(), to.UTC())
+}
+
+func (r *reconciliationRepository) GetSettlementEntries(source reconciliation.Source, references []string) ([]*reconciliation.LedgerEntry, error) {
+	query, ok := settlementEntryQueries[source]
+	if !ok {
+		return nil, fmt.Errorf("unsupported settlement source: %s", source)
+	}
+	if len(references) == 0 {
+		return []*reconciliation.LedgerEntry{}, nil
+	}
+
+	return r.listEntries(query+` AND id::text = ANY($1)`, pq.Array(references))
+}
+
+func (r *reconciliationRepository) listEntries(query string, args ...interface{}) ([]*reconciliation.LedgerEntry, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list settlement entries: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	entries := []*reconciliation.LedgerEntry{}
+	for rows.Next() {
+		e := &reconciliation.LedgerEntry{}
+		if err := rows.Scan(&e.Reference, &e.Amount, &e.Status, &e.Settled); err != nil {
+			return nil, fmt.Errorf("failed to scan settlement entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/regulatory"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type RegulatoryRepository interface {
+	CreateFlag(flag *regulatory.Flag) error
+	// ListLargeTransactions returns transactions completed in [from, to) with an
+	// amount of at least minAmount
+	ListLargeTransactions(from, to time.Time, minAmount float64) ([]*regulatory.Item, error)
+	// ListFlaggedTransactions returns the transactions flagged in [from, to), with
+	// the reasons of those flags
+	ListFlaggedTransactions(from, to time.Time) ([]*regulatory.Item, error)
+
+	SaveReport(report *regulatory.Report) error
+	// HasScheduledReport reports whether the daily job already generated the report for the day
+	HasScheduledReport(reportType regulatory.ReportType, day time.Time) (bool, error)
+	ListReports(limit int) ([]*regulatory.Report, error)
+	// GetReport returns a report with its file content
+	GetReport(id uuid.UUID) (*regulatory.Report, error)
+}
+
+type regulatoryRepository struct {
+	db *sql.DB
+}
+
+func NewRegulatoryRepository(db *sql.DB) RegulatoryRepository {
+	return &regulatoryRepository{db: db}
+}
+
+// itemColumns select a transaction and the account number and holder name on
+// each side of it
+const itemColumns = `t.id, t.transaction_type, t.amount, t.status, COALESCE(t.description, ''),
+	COALESCE(t.completed_at, t.created_at),
+	fa.id, fa.account_number, fu.first_name || ' ' || fu.last_name,
+	ta.id, ta.account_number, tu.first_name || ' ' || tu.last_name`
+
+const itemJoins = `
+	LEFT JOIN accounts fa ON fa.id = t.from_account_id
+	LEFT JOIN users fu ON fu.id = fa.user_id
+	LEFT JOIN accounts ta ON ta.id = t.to_account_id
+	LEFT JOIN users tu ON tu.id = ta.user_id`
+
+func scanItem(row rowScanner, extra ...interface{}) (*regulatory.Item, error) {
+	item := &regulatory.Item{}
+	var fromID, toID *uuid.UUID
+	var fromNumber, fromName, toNumber, toName sql.NullString
+	dest := append([]interface{}{
+		&item.TransactionID, &item.TransactionType, &item.Amount, &item.Status, &item.Description, &item.PostedAt,
+		&fromID, &fromNumber, &fromName,
+		&toID, &toNumber, &toName,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	if fromID != nil {
+		item.From = &regulatory.Party{AccountID: *fromID, AccountNumber: fromNumber.String, HolderName: fromName.String}
+	}
+	if toID != nil {
+		item.To = &regulatory.Party{AccountID: *toID, AccountNumber: toNumber.String, HolderName: toName.String}
+	}
+	return item, nil
+}
+
+const reportColumns = `id, report_type, period_start, period_end, item_count, total_amount, file_name, generated_by, created_at`
+
+func scanReport(row rowScanner, extra ...interface{}) (*regulatory.Report, error) {
+	report := &regulatory.Report{}
+	dest := append([]interface{}{
+		&report.ID, &report.Type, &report.PeriodStart, &report.PeriodEnd, &report.ItemCount,
+		&report.TotalAmount, &report.FileName, &report.GeneratedBy, &report.CreatedAt,
+	}, extra...)
+	return report, row.Scan(dest...)
+}
+
+func (r *regulatoryRepository) CreateFlag(flag *regulatory.Flag) error {
+	err := r.db.QueryRow(`
+		INSERT INTO transaction_flags (id, transaction_id, source, reason, flagged_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at
+	`, flag.ID, flag.TransactionID, flag.Source, flag.Reason, flag.FlaggedBy).Scan(&flag.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to flag transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *regulatoryRepository) ListLargeTransactions(from, to time.Time, minAmount float64) ([]*regulatory.Item, error) {
+	rows, err := r.db.Query(`
+		SELECT `+itemColumns+`
+		FROM transactions t`+itemJoins+`
+		WHERE t.status = $1
+		  AND COALESCE(t.completed_at, t.created_at) >= $2
+		  AND COALESCE(t.completed_at, t.created_at) < $3
+		  AND t.amount >= $4
+		ORDER BY COALESCE(t.completed_at, t.created_at), t.id
+	`, transaction.TransactionStatusCompleted, from.UTC(), to.UTC(), minAmount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list large transactions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	items := []*regulatory.Item{}
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan report item: %w", err)
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+func (r *regulatoryRepository) ListFlaggedTransactions(from, to time.Time) ([]*regulatory.Item, error) {
+	rows, err := r.db.Query(`
+		SELECT `+itemColumns+`,
+		       array_agg(f.source || ': ' || f.reason ORDER BY f.created_at)
+		FROM transaction_flags f
+		JOIN transactions t ON t.id = f.transaction_id`+itemJoins+`
+		WHERE f.created_at >= $1 AND f.created_at < $2
+		GROUP BY t.id, fa.id, fu.id, ta.id, tu.id
+		ORDER BY MIN(f.created_at), t.id
+	`, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list flagged transactions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	items := []*regulatory.Item{}
+	for rows.Next() {
+		var reasons []string
+		item, err := scanItem(rows, pq.Array(&reasons))
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan report item: %w", err)
+		}
+		item.Reasons = reasons
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+func (r *regulatoryRepository) SaveReport(report *regulatory.Report) error {
+	err := r.db.QueryRow(`
+		INSERT INTO regulatory_reports
+			(id, report_type, period_start, period_end, item_count, total_amount, file_name, content, generated_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING created_at
+	`, report.ID, report.Type, report.PeriodStart.Format(regulatory.DateLayout), report.PeriodEnd.Format(regulatory.DateLayout),
+		report.ItemCount, report.TotalAmount, report.FileName, report.Content, report.GeneratedBy,
+	).Scan(&report.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save regulatory report: %w", err)
+	}
+
+	return nil
+}
+
+func (r *regulatoryRepository) HasScheduledReport(reportType regulatory.ReportType, day time.Time) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM regulatory_reports
+			WHERE report_type = $1 AND period_start = $2 AND period_end = $2 AND generated_by IS NULL
+		)
+	`, reportType, day.Format(regulatory.DateLayout)).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check scheduled regulatory report: %w", err)
+	}
+
+	return exists, nil
+}
+
+func (r *regulatoryRepository) ListReports(limit int) ([]*regulatory.Report, error) {
+	rows, err := r.db.Query(`SELECT `+reportColumns+` FROM regulatory_reports ORDER BY created_at DESC LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list regulatory reports: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	reports := []*regulatory.Report{}
+	for rows.Next() {
+		report, err := scanReport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan regulatory report: %w", err)
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, rows.Err()
+}
+
+func (r *regulatoryRepository) GetReport(id uuid.UUID) (*regulatory.Report, error) {
+	var content []byte
+	report, err := scanReport(r.db.QueryRow(`SELECT `+reportColumns+`, content FROM regulatory_reports WHERE id = $1`, id), &content)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("regulatory report not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get regulatory report: %w", err)
+	}
+	report.Content = content
+
+	return report, nil
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/report"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+)
+
+type ReportRepository interface {
+	// DailyTotals sums the transactions completed in [from, to) by type and
+	// currency; transactions without a recorded currency count in
+	// defaultCurrency
+	DailyTotals(from, to time.Time, defaultCurrency string) ([]*report.TypeTotal, error)
+	// FailedCounts counts the transactions created in [from, to) that failed, by type
+	FailedCounts(from, to time.Time) ([]*report.FailedCount, error)
+}
+
+type reportRepository struct {
+	db *sql.DB
+}
+
+func NewReportRepository(db *sql.DB) ReportRepository {
+	return &reportRepository{db: db}
+}
+
+// DailyTotals takes fee income from the same metadata the general ledger
+// export posts to the income accounts
+func (r *reportRepository) DailyTotals(from, to time.Time, defaultCurrency string) ([]*report.TypeTotal, error) {
+	query := `
+		SELECT transaction_type,
+		       COALESCE(metadata->>'currency', $4) AS currency,
+		       COUNT(*),
+		       COALESCE(SUM(amount), 0),
+		       COALESCE(SUM(CASE transaction_type
+		           WHEN 'fee' THEN amount
+		           WHEN 'topup' THEN COALESCE((metadata->>'admin_fee')::numeric, 0)
+		           WHEN 'merchant_settlement' THEN COALESCE((metadata->>'fee_amount')::numeric, 0)
+		           WHEN 'loan_disbursement' THEN COALESCE((metadata->>'provision_fee')::numeric, 0)
+		           ELSE 0
+		       END), 0)
+		FROM transactions
+		WHERE status = $1
+		  AND COALESCE(completed_at, created_at) >= $2
+		  AND COALESCE(completed_at, created_at) < $3
+		GROUP BY 1, 2
+		ORDER BY 1, 2
+	`
+
+	rows, err := r.db.Query(query, transaction.TransactionStatusCompleted, from.UTC(), to.UTC(), defaultCurrency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum transactions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	totals := []*report.TypeTotal{}
+	for rows.Next() {
+		t := &report.TypeTotal{}
+		if err := rows.Scan(&t.TransactionType, &t.Currency, &t.Count, &t.Amount, &t.Fees); err != nil {
+			return nil, fmt.Errorf("failed to scan transaction total: %w", err)
+		}
+		totals = append(totals, t)
+	}
+
+	return totals, rows.Err()
+}
+
+func (r *reportRepository) FailedCounts(from, to time.Time) ([]*report.FailedCount, error) {
+	query := `
+		SELECT transaction_type, COUNT(*)
+		FROM transactions
+		WHERE status = $1 AND created_at >= $2 AND created_at < $3
+		GROUP BY 1
+		ORDER BY 1
+	`
+
+	rows, err := r.db.Query(query, transaction.TransactionStatusFailed, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to count failed transactions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	counts := []*report.FailedCount{}
+	for rows.Next() {
+		f := &report.FailedCount{}
+		if err := rows.Scan(&f.TransactionType, &f.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan failed count: %w", err)
+		}
+		counts = append(counts, f)
+	}
+
+	return counts, rows.Err()
+}
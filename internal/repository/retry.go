@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/pkg/metrics"
+	"github.com/lib/pq"
+)
+
+const (
+	// maxTxAttempts is how many times a money movement runs before a
+	// serialization failure or deadlock is returned to the caller
+	maxTxAttempts = 3
+	// txRetryBaseDelay is the wait before the first retry, doubling after it
+	txRetryBaseDelay = 20 * time.Millisecond
+)
+
+// retryableCodes are the Postgres errors that abort a transaction only to
+// resolve a conflict with another one, so running it again can succeed
+var retryableCodes = map[pq.ErrorCode]string{
+	"40001": "serialization_failure",
+	"40P01": "deadlock",
+}
+
+// retryReason returns the metric label for an error worth retrying, and
+// false for any other error
+func retryReason(err error) (string, bool) {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return "", false
+	}
+	reason, ok := retryableCodes[pqErr.Code]
+	return reason, ok
+}
+
+// withRetry runs fn, one whole database transaction, again while it fails
+// with a serialization failure or deadlock, up to maxTxAttempts times. The
+// waits between attempts double and are jittered so the transactions that
+// collided do not collide again. Every retry counts in
+// metrics.DBTransactionRetriesTotal under operation.
+func withRetry(operation string, fn func() error) error {
+	delay := txRetryBaseDelay
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		reason, retryable := retryReason(err)
+		if !retryable || attempt == maxTxAttempts {
+			return err
+		}
+
+		metrics.DBTransactionRetriesTotal.WithLabelValues(operation, reason).Inc()
+		time.Sleep(delay/2 + rand.N(delay))
+		delay *= 2
+	}
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/darisadam/madabank-server/internal/domain/roundup"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/google/uuid"
+)
+
+type RoundUpRepository interface {
+	// Save turns round-ups on for the account, or changes where they go
+	Save(rule *roundup.Rule) error
+	GetByAccountID(accountID uuid.UUID) (*roundup.Rule, error)
+	Delete(accountID uuid.UUID) error
+
+	// The repository is also the debit hook that posts the round-ups
+	DebitHook
+}
+
+type roundUpRepository struct {
+	db *sql.DB
+}
+
+func NewRoundUpRepository(db *sql.DB) RoundUpRepository {
+	return &roundUpRepository{db: db}
+}
+
+const roundUpRuleColumns = `r.id, r.user_id, r.account_id, r.savings_account_id,
+	(SELECT COALESCE(SUM(t.amount), 0) FROM transactions t
+	 WHERE t.from_account_id = r.account_id AND t.transaction_type = 'round_up'),
+	r.created_at, r.updated_at`
+
+func scanRoundUpRule(row rowScanner) (*roundup.Rule, error) {
+	rule := &roundup.Rule{}
+	err := row.Scan(&rule.ID, &rule.UserID, &rule.AccountID, &rule.SavingsAccountID, &rule.TotalSaved, &rule.CreatedAt, &rule.UpdatedAt)
+	return rule, err
+}
+
+func (r *roundUpRepository) Save(rule *roundup.Rule) error {
+	err := r.db.QueryRow(`
+		INSERT INTO round_up_rules (id, user_id, account_id, savings_account_id)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (account_id) DO UPDATE SET savings_account_id = EXCLUDED.savings_account_id
+		RETURNING id, created_at, updated_at
+	`, rule.ID, rule.UserID, rule.AccountID, rule.SavingsAccountID).Scan(&rule.ID, &rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save round-up rule: %w", err)
+	}
+	return nil
+}
+
+func (r *roundUpRepository) GetByAccountID(accountID uuid.UUID) (*roundup.Rule, error) {
+	rule, err := scanRoundUpRule(r.db.QueryRow(`
+		SELECT `+roundUpRuleColumns+` FROM round_up_rules r WHERE r.account_id = $1
+	`, accountID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("round-up rule not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get round-up rule: %w", err)
+	}
+	return rule, nil
+}
+
+func (r *roundUpRepository) Delete(accountID uuid.UUID) error {
+	result, err := r.db.Exec(`DELETE FROM round_up_rules WHERE account_id = $1`, accountID)
+	if err != nil {
+		return fmt.Errorf("failed to delete round-up rule: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("round-up rule not found")
+	}
+
+	return nil
+}
+
+// AfterDebit moves the spare change of a debit to the account's round-up
+// savings account. The round-up is skipped, and the debit goes through
+// alone, when the account has no rule, the debit already goes to the savings
+// account, the savings account is not active or the remaining available
+// balance, not counting the overdraft, does not cover it.
+func (r *roundUpRepository) AfterDebit(dbTx *sql.Tx, txn *transaction.Transaction) error {
+	amount := roundup.Amount(txn.Amount)
+	if txn.FromAccountID == nil || amount == 0 {
+		return nil
+	}
+	fromAccountID := *txn.FromAccountID
+
+	var savingsAccountID uuid.UUID
+	err := dbTx.QueryRow(`SELECT savings_account_id FROM round_up_rules WHERE account_id = $1`, fromAccountID).Scan(&savingsAccountID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get round-up rule: %w", err)
+	}
+	if txn.ToAccountID != nil && *txn.ToAccountID == savingsAccountID {
+		return nil
+	}
+
+	// The source account is already locked by the debit
+	var status string
+	err = dbTx.QueryRow(`SELECT status FROM accounts WHERE id = $1 FOR UPDATE`, savingsAccountID).Scan(&status)
+	if err != nil {
+		return fmt.Errorf("failed to lock round-up savings account: %w", err)
+	}
+	if status != "active" {
+		return nil
+	}
+
+	held, err := lockBalance(dbTx, fromAccountID, "")
+	if err != nil {
+		return fmt.Errorf("failed to lock account: %w", err)
+	}
+	if held.available-held.overdraft < amount {
+		return nil
+	}
+	if err := held.add(dbTx, -amount); err != nil {
+		return fmt.Errorf("failed to debit round-up: %w", err)
+	}
+
+	_, err = dbTx.Exec(`UPDATE accounts SET balance = balance + $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`, amount, savingsAccountID)
+	if err != nil {
+		return fmt.Errorf("failed to credit round-up savings account: %w", err)
+	}
+
+	metadataJSON, _ := json.Marshal(map[string]interface{}{
+		"round_up_of": txn.ID.String(),
+	})
+	_, err = dbTx.Exec(`
+		INSERT INTO transactions (id, idempotency_key, from_account_id, to_account_id,
+		                         amount, transaction_type, status, description, metadata, completed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, CURRENT_TIMESTAMP)
+	`, uuid.New(), "round-up:"+txn.ID.String(), fromAccountID, savingsAccountID, amount,
+		transaction.TransactionTypeRoundUp, transaction.TransactionStatusCompleted, "Round-up savings", metadataJSON)
+	if err != nil {
+		return fmt.Errorf("failed to insert round-up transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/saga"
+	"github.com/google/uuid"
+)
+
+type SagaRepository interface {
+	Create(s *saga.Saga) error
+	// Save records the saga's progress: status, step, data, attempts and lock
+	Save(s *saga.Saga) error
+	// ClaimDue locks up to limit unfinished sagas that are due for another
+	// attempt and not locked by a live runner, until lockedUntil
+	ClaimDue(now, lockedUntil time.Time, limit int) ([]*saga.Saga, error)
+	GetByID(id uuid.UUID) (*saga.Saga, error)
+}
+
+type sagaRepository struct {
+	db *sql.DB
+}
+
+func NewSagaRepository(db *sql.DB) SagaRepository {
+	return &sagaRepository{db: db}
+}
+
+const sagaColumns = `id, kind, status, data, step, attempts, COALESCE(last_error, ''),
+	next_attempt_at, locked_until, completed_at, created_at, updated_at`
+
+func scanSaga(row rowScanner) (*saga.Saga, error) {
+	s := &saga.Saga{}
+	var data []byte
+	err := row.Scan(
+		&s.ID, &s.Kind, &s.Status, &data, &s.Step, &s.Attempts, &s.LastError,
+		&s.NextAttemptAt, &s.LockedUntil, &s.CompletedAt, &s.CreatedAt, &s.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	s.Data = data
+	return s, nil
+}
+
+func (r *sagaRepository) Create(s *saga.Saga) error {
+	err := r.db.QueryRow(`
+		INSERT INTO sagas (id, kind, status, data, step, next_attempt_at, locked_until)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at, updated_at
+	`, s.ID, s.Kind, s.Status, []byte(s.Data), s.Step, s.NextAttemptAt.UTC(), utcTime(s.LockedUntil),
+	).Scan(&s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create saga: %w", err)
+	}
+
+	return nil
+}
+
+func (r *sagaRepository) Save(s *saga.Saga) error {
+	var lastError *string
+	if s.LastError != "" {
+		lastError = &s.LastError
+	}
+
+	result, err := r.db.Exec(`
+		UPDATE sagas
+		SET status = $1, data = $2, step = $3, attempts = $4, last_error = $5,
+		    next_attempt_at = $6, locked_until = $7, completed_at = $8, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $9
+	`, s.Status, []byte(s.Data), s.Step, s.Attempts, lastError,
+		s.NextAttemptAt.UTC(), utcTime(s.LockedUntil), utcTime(s.CompletedAt), s.ID)
+	if err != nil {
+		return fmt.Errorf("failed to save saga: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("saga not found")
+	}
+
+	return nil
+}
+
+func (r *sagaRepository) ClaimDue(now, lockedUntil time.Time, limit int) ([]*saga.Saga, error) {
+	rows, err := r.db.Query(`
+		UPDATE sagas
+		SET locked_until = $1, updated_at = $2
+		WHERE id IN (
+			SELECT id FROM sagas
+			WHERE status IN ($3, $4) AND next_attempt_at <= $2
+			  AND (locked_until IS NULL OR locked_until < $2)
+			ORDER BY next_attempt_at
+			LIMIT $5
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+sagaColumns,
+		lockedUntil.UTC(), now.UTC(), saga.StatusRunning, saga.StatusCompensating, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim sagas: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	sagas := []*saga.Saga{}
+	for rows.Next() {
+		s, err := scanSaga(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan saga: %w", err)
+		}
+		sagas = append(sagas, s)
+	}
+
+	return sagas, rows.Err()
+}
+
+func (r *sagaRepository) GetByID(id uuid.UUID) (*saga.Saga, error) {
+	s, err := scanSaga(r.db.QueryRow(`SELECT `+sagaColumns+` FROM sagas WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("saga not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saga: %w", err)
+	}
+
+	return s, nil
+}
+
+// utcTime converts an optional timestamp for a TIMESTAMP column
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
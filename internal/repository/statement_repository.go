@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/darisadam/madabank-server/internal/domain/statement"
+	"github.com/google/uuid"
+)
+
+type StatementRepository interface {
+	// SaveSubscription opts the account in, or changes the address it sends to
+	SaveSubscription(s *statement.Subscription) error
+	GetSubscription(accountID uuid.UUID) (*statement.Subscription, error)
+	DeleteSubscription(accountID uuid.UUID) error
+	// ListDueSubscriptions returns the subscriptions whose statement for the
+	// period has not been sent and has fewer than maxAttempts failures
+	ListDueSubscriptions(period string, maxAttempts int) ([]*statement.Subscription, error)
+
+	// EnsureDelivery loads the delivery of the account's statement for the
+	// period, creating it as pending if there is none
+	EnsureDelivery(d *statement.Delivery) error
+	GetDelivery(id uuid.UUID) (*statement.Delivery, error)
+	ListDeliveries(accountID uuid.UUID) ([]*statement.Delivery, error)
+	// RecordAttempt saves the outcome of sending a delivery
+	RecordAttempt(d *statement.Delivery) error
+}
+
+type statementRepository struct {
+	db *sql.DB
+}
+
+func NewStatementRepository(db *sql.DB) StatementRepository {
+	return &statementRepository{db: db}
+}
+
+const statementSubscriptionColumns = `id, user_id, account_id, COALESCE(email, ''), created_at, updated_at`
+
+const statementDeliveryColumns = `id, user_id, account_id, period, COALESCE(email, ''), status, attempts,
+	COALESCE(last_error, ''), sent_at, created_at, updated_at`
+
+func scanStatementSubscription(row rowScanner) (*statement.Subscription, error) {
+	s := &statement.Subscription{}
+	err := row.Scan(&s.ID, &s.UserID, &s.AccountID, &s.Email, &s.CreatedAt, &s.UpdatedAt)
+	return s, err
+}
+
+func scanStatementDelivery(row rowScanner) (*statement.Delivery, error) {
+	d := &statement.Delivery{}
+	err := row.Scan(
+		&d.ID, &d.UserID, &d.AccountID, &d.Period, &d.Email, &d.Status, &d.Attempts,
+		&d.LastError, &d.SentAt, &d.CreatedAt, &d.UpdatedAt,
+	)
+	return d, err
+}
+
+func (r *statementRepository) SaveSubscription(s *statement.Subscription) error {
+	err := r.db.QueryRow(`
+		INSERT INTO statement_subscriptions (id, user_id, account_id, email)
+		VALUES ($1, $2, $3, NULLIF($4, ''))
+		ON CONFLICT (account_id) DO UPDATE SET email = EXCLUDED.email
+		RETURNING id, created_at, updated_at
+	`, s.ID, s.UserID, s.AccountID, s.Email).Scan(&s.ID, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save statement subscription: %w", err)
+	}
+	return nil
+}
+
+func (r *statementRepository) GetSubscription(accountID uuid.UUID) (*statement.Subscription, error) {
+	s, err := scanStatementSubscription(r.db.QueryRow(`
+		SELECT `+statementSubscriptionColumns+` FROM statement_subscriptions WHERE account_id = $1
+	`, accountID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("statement subscription not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get statement subscription: %w", err)
+	}
+	return s, nil
+}
+
+func (r *statementRepository) DeleteSubscription(accountID uuid.UUID) error {
+	result, err := r.db.Exec(`DELETE FROM statement_subscriptions WHERE account_id = $1`, accountID)
+	if err != nil {
+		return fmt.Errorf("failed to delete statement subscription: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("statement subscription not found")
+	}
+
+	return nil
+}
+
+func (r *statementRepository) ListDueSubscriptions(period string, maxAttempts int) ([]*statement.Subscription, error) {
+	rows, err := r.db.Query(`
+		SELECT s.id, s.user_id, s.account_id, COALESCE(s.email, ''), s.created_at, s.updated_at
+		FROM statement_subscriptions s
+		JOIN accounts a ON a.id = s.account_id AND a.status <> 'closed'
+		LEFT JOIN statement_deliveries d ON d.account_id = s.account_id AND d.period = $1
+		WHERE d.id IS NULL OR (d.status <> 'sent' AND d.attempts < $2)
+		ORDER BY s.created_at, s.id
+	`, period, maxAttempts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due statement subscriptions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	subscriptions := []*statement.Subscription{}
+	for rows.Next() {
+		s, err := scanStatementSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan statement subscription: %w", err)
+		}
+		subscriptions = append(subscriptions, s)
+	}
+
+	return subscriptions, rows.Err()
+}
+
+func (r *statementRepository) EnsureDelivery(d *statement.Delivery) error {
+	if _, err := r.db.Exec(`
+		INSERT INTO statement_deliveries (id, user_id, account_id, period, status)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (account_id, period) DO NOTHING
+	`, d.ID, d.UserID, d.AccountID, d.Period, statement.DeliveryStatusPending); err != nil {
+		return fmt.Errorf("failed to create statement delivery: %w", err)
+	}
+
+	existing, err := scanStatementDelivery(r.db.QueryRow(`
+		SELECT `+statementDeliveryColumns+` FROM statement_deliveries WHERE account_id = $1 AND period = $2
+	`, d.AccountID, d.Period))
+	if err != nil {
+		return fmt.Errorf("failed to get statement delivery: %w", err)
+	}
+	*d = *existing
+	return nil
+}
+
+func (r *statementRepository) GetDelivery(id uuid.UUID) (*statement.Delivery, error) {
+	d, err := scanStatementDelivery(r.db.QueryRow(`
+		SELECT `+statementDeliveryColumns+` FROM statement_deliveries WHERE id = $1
+	`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("statement delivery not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get statement delivery: %w", err)
+	}
+	return d, nil
+}
+
+func (r *statementRepository) ListDeliveries(accountID uuid.UUID) ([]*statement.Delivery, error) {
+	rows, err := r.db.Query(`
+		SELECT `+statementDeliveryColumns+` FROM statement_deliveries
+		WHERE account_id = $1
+		ORDER BY period DESC
+	`, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statement deliveries: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	deliveries := []*statement.Delivery{}
+	for rows.Next() {
+		d, err := scanStatementDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan statement delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func (r *statementRepository) RecordAttempt(d *statement.Delivery) error {
+	result, err := r.db.Exec(`
+		UPDATE statement_deliveries
+		SET email = NULLIF($1, ''), status = $2, attempts = $3, last_error = NULLIF($4, ''), sent_at = $5
+		WHERE id = $6
+	`, d.Email, d.Status, d.Attempts, d.LastError, utcTime(d.SentAt), d.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update statement delivery: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("statement delivery not found")
+	}
+
+	return nil
+}
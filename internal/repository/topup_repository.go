@@ -0,0 +1,253 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/topup"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/google/uuid"
+)
+
+type TopupRepository interface {
+	// Create debits the account and records the pending top-up with its ledger entry
+	Create(t *topup.Topup, txn *transaction.Transaction) error
+	GetByID(id uuid.UUID) (*topup.Topup, error)
+	GetByTransactionID(txnID uuid.UUID) (*topup.Topup, error)
+	ListByUser(userID uuid.UUID, limit int) ([]*topup.Topup, error)
+	// ListUnfinished returns pending and processing top-ups last updated before the cutoff
+	ListUnfinished(updatedBefore time.Time, limit int) ([]*topup.Topup, error)
+
+	MarkProcessing(id uuid.UUID, reference string) error
+	Complete(id uuid.UUID, reference string) error
+	// Fail refunds the account and reverses the ledger entry
+	Fail(id uuid.UUID, reason string) error
+}
+
+type topupRepository struct {
+	db *sql.DB
+}
+
+func NewTopupRepository(db *sql.DB) TopupRepository {
+	return &topupRepository{db: db}
+}
+
+const topupColumns = `id, user_id, account_id, transaction_id, topup_type, provider, destination, amount,
+	admin_fee, status, COALESCE(reference, ''), COALESCE(failure_reason, ''), created_at, updated_at, completed_at`
+
+func scanTopup(row rowScanner) (*topup.Topup, error) {
+	t := &topup.Topup{}
+	err := row.Scan(
+		&t.ID, &t.UserID, &t.AccountID, &t.TransactionID, &t.Type, &t.Provider, &t.Destination, &t.Amount,
+		&t.AdminFee, &t.Status, &t.Reference, &t.FailureReason, &t.CreatedAt, &t.UpdatedAt, &t.CompletedAt,
+	)
+	return t, err
+}
+
+func (r *topupRepository) Create(t *topup.Topup, txn *transaction.Transaction) error {
+	dbTx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = dbTx.Rollback() // Rollback if not committed
+	}()
+
+	total := t.TotalAmount()
+
+	held, err := lockBalance(dbTx, t.AccountID, "")
+	if err != nil {
+		return fmt.Errorf("failed to lock account: %w", err)
+	}
+
+	if held.available < total {
+		return insufficientFunds(held.available, total)
+	}
+
+	if err := held.add(dbTx, -total); err != nil {
+		return fmt.Errorf("failed to debit account: %w", err)
+	}
+
+	metadataJSON, _ := json.Marshal(txn.Metadata)
+	_, err = dbTx.Exec(`
+		INSERT INTO transactions (id, idempotency_key, from_account_id, amount, transaction_type, status, description, metadata)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, txn.ID, txn.IdempotencyKey, t.AccountID, total, txn.TransactionType, transaction.TransactionStatusPending, txn.Description, metadataJSON)
+	if err != nil {
+		return fmt.Errorf("failed to insert transaction: %w", err)
+	}
+
+	err = dbTx.QueryRow(`
+		INSERT INTO topups (id, user_id, account_id, transaction_id, topup_type, provider, destination, amount, admin_fee, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING created_at, updated_at
+	`, t.ID, t.UserID, t.AccountID, t.TransactionID, t.Type, t.Provider, t.Destination, t.Amount, t.AdminFee, t.Status,
+	).Scan(&t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create top-up: %w", err)
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *topupRepository) GetByID(id uuid.UUID) (*topup.Topup, error) {
+	return r.getOne(`SELECT `+topupColumns+` FROM topups WHERE id = $1`, id)
+}
+
+func (r *topupRepository) GetByTransactionID(txnID uuid.UUID) (*topup.Topup, error) {
+	return r.getOne(`SELECT `+topupColumns+` FROM topups WHERE transaction_id = $1`, txnID)
+}
+
+func (r *topupRepository) getOne(query string, arg interface{}) (*topup.Topup, error) {
+	t, err := scanTopup(r.db.QueryRow(query, arg))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("top-up not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get top-up: %w", err)
+	}
+	return t, nil
+}
+
+func (r *topupRepository) ListByUser(userID uuid.UUID, limit int) ([]*topup.Topup, error) {
+	return r.list(`SELECT `+topupColumns+` FROM topups WHERE user_id = $1 ORDER BY created_at DESC LIMIT $2`, userID, limit)
+}
+
+func (r *topupRepository) ListUnfinished(updatedBefore time.Time, limit int) ([]*topup.Topup, error) {
+	return r.list(`
+		SELECT `+topupColumns+` FROM topups
+		WHERE status IN ('pending', 'processing') AND updated_at < $1
+		ORDER BY updated_at
+		LIMIT $2
+	`, updatedBefore, limit)
+}
+
+func (r *topupRepository) list(query string, args ...interface{}) ([]*topup.Topup, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list top-ups: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	topups := []*topup.Topup{}
+	for rows.Next() {
+		t, err := scanTopup(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan top-up: %w", err)
+		}
+		topups = append(topups, t)
+	}
+
+	return topups, rows.Err()
+}
+
+func (r *topupRepository) MarkProcessing(id uuid.UUID, reference string) error {
+	result, err := r.db.Exec(`
+		UPDATE topups SET status = $1, reference = $2 WHERE id = $3 AND status = $4
+	`, topup.StatusProcessing, reference, id, topup.StatusPending)
+	if err != nil {
+		return fmt.Errorf("failed to update top-up: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("pending top-up not found")
+	}
+
+	return nil
+}
+
+// Complete records the delivery and settles the ledger entry
+func (r *topupRepository) Complete(id uuid.UUID, reference string) error {
+	dbTx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = dbTx.Rollback() // Rollback if not committed
+	}()
+
+	var txnID uuid.UUID
+	err = dbTx.QueryRow(`
+		UPDATE topups SET status = $1, reference = $2, completed_at = CURRENT_TIMESTAMP
+		WHERE id = $3 AND status IN ($4, $5)
+		RETURNING transaction_id
+	`, topup.StatusSuccess, reference, id, topup.StatusPending, topup.StatusProcessing).Scan(&txnID)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("unfinished top-up not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to complete top-up: %w", err)
+	}
+
+	note, _ := json.Marshal(map[string]string{"provider_reference": reference})
+	_, err = dbTx.Exec(`
+		UPDATE transactions
+		SET status = $1, completed_at = CURRENT_TIMESTAMP, metadata = COALESCE(metadata, '{}'::jsonb) || $2::jsonb
+		WHERE id = $3
+	`, transaction.TransactionStatusCompleted, note, txnID)
+	if err != nil {
+		return fmt.Errorf("failed to complete transaction: %w", err)
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *topupRepository) Fail(id uuid.UUID, reason string) error {
+	dbTx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = dbTx.Rollback() // Rollback if not committed
+	}()
+
+	var txnID, accountID uuid.UUID
+	var amount, adminFee float64
+	err = dbTx.QueryRow(`
+		UPDATE topups SET status = $1, failure_reason = $2, completed_at = CURRENT_TIMESTAMP
+		WHERE id = $3 AND status IN ($4, $5)
+		RETURNING transaction_id, account_id, amount, admin_fee
+	`, topup.StatusFailed, reason, id, topup.StatusPending, topup.StatusProcessing).Scan(&txnID, &accountID, &amount, &adminFee)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("unfinished top-up not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fail top-up: %w", err)
+	}
+
+	// The refund is credited even if the account was frozen in the meantime
+	_, err = dbTx.Exec(`UPDATE accounts SET balance = balance + $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`, amount+adminFee, accountID)
+	if err != nil {
+		return fmt.Errorf("failed to refund account: %w", err)
+	}
+
+	note, _ := json.Marshal(map[string]string{"reversal_reason": reason})
+	_, err = dbTx.Exec(`
+		UPDATE transactions
+		SET status = $1, completed_at = CURRENT_TIMESTAMP, metadata = COALESCE(metadata, '{}'::jsonb) || $2::jsonb
+		WHERE id = $3
+	`, transaction.TransactionStatusReversed, note, txnID)
+	if err != nil {
+		return fmt.Errorf("failed to reverse transaction: %w", err)
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/pkg/objectstore"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// transactionPartitionPrefix names monthly partitions, e.g. transactions_p202401
+const transactionPartitionPrefix = "transactions_p"
+
+type TransactionArchiveRepository interface {
+	// EnsurePartitions creates the monthly partitions for the given number of
+	// months starting with the month of from
+	EnsurePartitions(from time.Time, months int) error
+	// ListPartitionMonths returns the months that still have a partition, oldest first
+	ListPartitionMonths() ([]time.Time, error)
+	// ListPartition returns every transaction in a month's partition
+	ListPartition(month time.Time) ([]*transaction.Transaction, error)
+	// ArchivePartition records the archive and each account's movements in the
+	// month, then detaches and drops the month's partition
+	ArchivePartition(archive *transaction.Archive) error
+
+	// ListArchives returns the archived months overlapping [from, to)
+	ListArchives(from, to time.Time) ([]*transaction.Archive, error)
+	// ListArchived reads an account's transactions created in [from, to) back
+	// from cold storage
+	ListArchived(ctx context.Context, accountID uuid.UUID, from, to time.Time) ([]*transaction.Transaction, error)
+}
+
+type transactionArchiveRepository struct {
+	db    *sql.DB
+	store objectstore.Store // nil when archiving is not configured
+}
+
+func NewTransactionArchiveRepository(db *sql.DB, store objectstore.Store) TransactionArchiveRepository {
+	return &transactionArchiveRepository{db: db, store: store}
+}
+
+func partitionName(month time.Time) string {
+	return transactionPartitionPrefix + month.Format("200601")
+}
+
+func (r *transactionArchiveRepository) EnsurePartitions(from time.Time, months int) error {
+	month := transaction.MonthStart(from)
+	for i := 0; i < months; i++ {
+		if _, err := r.db.Exec(`SELECT create_transaction_partition($1)`, month.AddDate(0, i, 0).Format("2006-01-02")); err != nil {
+			return fmt.Errorf("failed to create transaction partition: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *transactionArchiveRepository) ListPartitionMonths() ([]time.Time, error) {
+	rows, err := r.db.Query(`
+		SELECT c.relname
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = 'transactions'::regclass
+		ORDER BY c.relname
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transaction partitions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	months := []time.Time{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan transaction partition: %w", err)
+		}
+		if !strings.HasPrefix(name, transactionPartitionPrefix) {
+			continue // the default partition
+		}
+		month, err := time.Parse("200601", strings.TrimPrefix(name, transactionPartitionPrefix))
+		if err != nil {
+			continue
+		}
+		months = append(months, month)
+	}
+
+	return months, rows.Err()
+}
+
+func (r *transactionArchiveRepository) ListPartition(month time.Time) ([]*transaction.Transaction, error) {
+	rows, err := r.db.Query(`
+		SELECT id, idempotency_key, from_account_id, to_account_id, amount,
+		       transaction_type, status, description, metadata, created_at, completed_at
+		FROM ` + pq.QuoteIdentifier(partitionName(month)) + `
+		ORDER BY created_at, id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transaction partition: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	txns, err := scanTransactions(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return txns, rows.Err()
+}
+
+func (r *transactionArchiveRepository) ArchivePartition(archive *transaction.Archive) error {
+	partition := pq.QuoteIdentifier(partitionName(archive.Month))
+	month := archive.Month.Format("2006-01-02")
+
+	dbTx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = dbTx.Rollback() // Rollback if not committed
+	}()
+
+	err = dbTx.QueryRow(`
+		INSERT INTO transaction_archives (partition_month, object_key, storage, row_count)
+		VALUES ($1, $2, $3, $4)
+		RETURNING archived_at
+	`, month, archive.ObjectKey, archive.Storage, archive.RowCount).Scan(&archive.ArchivedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record transaction archive: %w", err)
+	}
+
+	// Same rules as the ledger balance: completed credits, completed and
+	// pending debits, in the account's own currency
+	_, err = dbTx.Exec(`
+		INSERT INTO transaction_archive_balances (partition_month, account_id, credits, debits)
+		SELECT $1, m.account_id, SUM(m.credit), SUM(m.debit)
+		FROM (
+			SELECT to_account_id AS account_id, amount AS credit, 0 AS debit, metadata->>'currency' AS currency
+			FROM `+partition+` WHERE to_account_id IS NOT NULL AND status = 'completed'
+			UNION ALL
+			SELECT from_account_id, 0, amount, metadata->>'currency'
+			FROM `+partition+` WHERE from_account_id IS NOT NULL AND status IN ('completed', 'pending')
+		) m
+		JOIN accounts a ON a.id = m.account_id
+		WHERE COALESCE(m.currency, a.currency) = a.currency
+		GROUP BY m.account_id
+	`, month)
+	if err != nil {
+		return fmt.Errorf("failed to record archived balances: %w", err)
+	}
+
+	if _, err := dbTx.Exec(`ALTER TABLE transactions DETACH PARTITION ` + partition); err != nil {
+		return fmt.Errorf("failed to detach transaction partition: %w", err)
+	}
+	if _, err := dbTx.Exec(`DROP TABLE ` + partition); err != nil {
+		return fmt.Errorf("failed to drop transaction partition: %w", err)
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *transactionArchiveRepository) ListArchives(from, to time.Time) ([]*transaction.Archive, error) {
+	rows, err := r.db.Query(`
+		SELECT partition_month, object_key, storage, row_count, archived_at
+		FROM transaction_archives
+		WHERE partition_month < $2 AND partition_month + INTERVAL '1 month' > $1
+		ORDER BY partition_month
+	`, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transaction archives: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	archives := []*transaction.Archive{}
+	for rows.Next() {
+		a := &transaction.Archive{}
+		if err := rows.Scan(&a.Month, &a.ObjectKey, &a.Storage, &a.RowCount, &a.ArchivedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan transaction archive: %w", err)
+		}
+		archives = append(archives, a)
+	}
+
+	return archives, rows.Err()
+}
+
+func (r *transactionArchiveRepository) ListArchived(ctx context.Context, accountID uuid.UUID, from, to time.Time) ([]*transaction.Transaction, error) {
+	archives, err := r.ListArchives(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if len(archives) > 0 && r.store == nil {
+		return nil, fmt.Errorf("transaction archive storage is not configured")
+	}
+
+	txns := []*transaction.Transaction{}
+	for _, a := range archives {
+		data, err := r.store.Get(ctx, a.ObjectKey)
+		if err != nil {
+			return nil, err
+		}
+		archived, err := transaction.DecodeArchive(data)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, txn := range archived {
+			if txn.CreatedAt.Before(from) || !txn.CreatedAt.Before(to) {
+				continue
+			}
+			if (txn.FromAccountID != nil && *txn.FromAccountID == accountID) ||
+				(txn.ToAccountID != nil && *txn.ToAccountID == accountID) {
+				txns = append(txns, txn)
+			}
+		}
+	}
+
+	return txns, nil
+}
@@ -1,13 +1,16 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/darisadam/madabank-server/internal/domain/transaction"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type TransactionRepository interface {
@@ -16,7 +19,16 @@ type TransactionRepository interface {
 	GetByIdempotencyKey(key string) (*transaction.Transaction, error)
 	GetByAccountID(accountID uuid.UUID, limit, offset int) ([]*transaction.Transaction, error)
 	GetByAccountIDWithFilters(accountID uuid.UUID, filters map[string]interface{}, limit, offset int) ([]*transaction.Transaction, error)
+	// CountByAccountID counts the account's transactions matching the same
+	// filters as GetByAccountIDWithFilters, across all pages
+	CountByAccountID(accountID uuid.UUID, filters map[string]interface{}) (int, error)
 	UpdateStatus(id uuid.UUID, status transaction.TransactionStatus) error
+	ListCompleted(from, to time.Time) ([]*transaction.Transaction, error)
+	ListCompletedByAccount(accountID uuid.UUID, from, to time.Time) ([]*transaction.Transaction, error)
+	// Search matches the filter's accounts' transactions, best match first when
+	// there is a text query and newest first otherwise
+	Search(f *transaction.SearchFilter) ([]*transaction.Transaction, error)
+	CountSearch(f *transaction.SearchFilter) (int, error)
 
 	// ACID operations - these run in a database transaction
 	ExecuteTransfer(fromAccountID, toAccountID uuid.UUID, amount float64, txn *transaction.Transaction) error
@@ -24,12 +36,129 @@ type TransactionRepository interface {
 	ExecuteWithdrawal(accountID uuid.UUID, amount float64, txn *transaction.Transaction) error
 }
 
+// DebitHook posts follow-up entries for a debit, such as round-up savings.
+// It runs inside the debit's database transaction, after the balances moved
+// and the transaction row was written, so its entries commit or roll back
+// together with the debit. Returning an error rolls the debit back.
+type DebitHook interface {
+	AfterDebit(dbTx *sql.Tx, txn *transaction.Transaction) error
+}
+
+func runDebitHooks(dbTx *sql.Tx, hooks []DebitHook, txn *transaction.Transaction) error {
+	for _, hook := range hooks {
+		if err := hook.AfterDebit(dbTx, txn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// heldBalance is an account's balance in one currency, locked for update
+type heldBalance struct {
+	accountID uuid.UUID
+	currency  string
+	balance   float64
+	// available is what a debit may take: in the account's own currency the
+	// balance plus the overdraft limit, less its open holds and card
+	// authorizations, which are reserved in that currency; in another
+	// currency, which has no overdraft, the balance
+	available float64
+	// overdraft is the overdraft limit counted in available; zero in a
+	// currency other than the account's own
+	overdraft float64
+	// own is the account's own currency, kept in accounts.balance rather
+	// than in account_balances
+	own bool
+}
+
+// lockBalance locks an active account and its balance in the currency; an
+// empty currency is the account's own. It fails when the account does not
+// hold the currency.
+func lockBalance(dbTx *sql.Tx, accountID uuid.UUID, currency string) (*heldBalance, error) {
+	held := &heldBalance{accountID: accountID}
+	err := dbTx.QueryRow(`
+		SELECT balance, `+availableBalance+`, overdraft_limit, currency
+		FROM accounts WHERE id = $1 AND status = 'active' FOR UPDATE
+	`, accountID).Scan(&held.balance, &held.available, &held.overdraft, &held.currency)
+	if err != nil {
+		return nil, err
+	}
+	if currency == "" || currency == held.currency {
+		held.own = true
+		return held, nil
+	}
+
+	held.currency = currency
+	err = dbTx.QueryRow(`SELECT balance FROM account_balances WHERE account_id = $1 AND currency = $2 FOR UPDATE`, accountID, currency).
+		Scan(&held.balance)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("account does not hold %s", currency)
+	}
+	if err != nil {
+		return nil, err
+	}
+	held.available, held.overdraft = held.balance, 0
+	return held, nil
+}
+
+// balanceConstraints are the CHECK constraints keeping balances from going
+// below zero, or below minus the overdraft limit (migration 000040)
+var balanceConstraints = map[string]bool{
+	"accounts_balance_within_overdraft": true,
+	"account_balances_non_negative":     true,
+}
+
+// isBalanceViolation reports whether err is the database refusing a debit
+// that would break a balance constraint
+func isBalanceViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23514" && balanceConstraints[pqErr.Constraint]
+}
+
+// insufficientFunds is the error for a debit of need from a balance of have
+func insufficientFunds(have, need float64) error {
+	return fmt.Errorf("%w: have %.2f, need %.2f", transaction.ErrInsufficientFunds, have, need)
+}
+
+// add moves the balance by delta, negative for a debit. A debit the database
+// refuses for taking the balance too low returns
+// transaction.ErrInsufficientFunds; the checks before it should make that
+// rare.
+func (h *heldBalance) add(dbTx *sql.Tx, delta float64) error {
+	var err error
+	if h.own {
+		_, err = dbTx.Exec(`UPDATE accounts SET balance = balance + $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`, delta, h.accountID)
+	} else {
+		_, err = dbTx.Exec(`UPDATE account_balances SET balance = balance + $1 WHERE account_id = $2 AND currency = $3`, delta, h.accountID, h.currency)
+	}
+	if isBalanceViolation(err) {
+		return insufficientFunds(h.balance, -delta)
+	}
+	if err != nil {
+		return err
+	}
+	h.balance += delta
+	h.available += delta
+	return nil
+}
+
 type transactionRepository struct {
-	db *sql.DB
+	db        *sql.DB
+	isolation sql.IsolationLevel
+	hooks     []DebitHook
+}
+
+// NewTransactionRepository returns the repository. Transfers, deposits and
+// withdrawals run at the isolation level, sql.LevelDefault leaving it to the
+// server. The hooks run after every transfer and withdrawal from an
+// account's own currency.
+func NewTransactionRepository(db *sql.DB, isolation sql.IsolationLevel, hooks ...DebitHook) TransactionRepository {
+	return &transactionRepository{db: db, isolation: isolation, hooks: hooks}
 }
 
-func NewTransactionRepository(db *sql.DB) TransactionRepository {
-	return &transactionRepository{db: db}
+// begin starts the database transaction of a money movement
+func (r *transactionRepository) begin() (*sql.Tx, error) {
+	return r.db.BeginTx(context.Background(), &sql.TxOptions{Isolation: r.isolation})
 }
 
 func (r *transactionRepository) Create(txn *transaction.Transaction) error {
@@ -167,50 +296,229 @@ func (r *transactionRepository) GetByAccountID(accountID uuid.UUID, limit, offse
 		_ = rows.Close()
 	}()
 
-	return r.scanTransactions(rows)
+	return scanTransactions(rows)
 }
 
 func (r *transactionRepository) GetByAccountIDWithFilters(accountID uuid.UUID, filters map[string]interface{}, limit, offset int) ([]*transaction.Transaction, error) {
+	where, args := accountFilterClause(accountID, filters)
 	query := `
 		SELECT id, idempotency_key, from_account_id, to_account_id, amount,
 		       transaction_type, status, description, metadata, created_at, completed_at
 		FROM transactions
-		WHERE (from_account_id = $1 OR to_account_id = $1)
-	`
+		WHERE ` + where + fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+	args = append(args, limit, offset)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	return scanTransactions(rows)
+}
+
+func (r *transactionRepository) CountByAccountID(accountID uuid.UUID, filters map[string]interface{}) (int, error) {
+	where, args := accountFilterClause(accountID, filters)
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM transactions WHERE `+where, args...).Scan(&total); err != nil {
+		return 0, fmt.Errorf("failed to count transactions: %w", err)
+	}
+	return total, nil
+}
+
+// accountFilterClause builds the WHERE clause shared by the filtered history
+// and its count
+func accountFilterClause(accountID uuid.UUID, filters map[string]interface{}) (string, []interface{}) {
+	where := "(from_account_id = $1 OR to_account_id = $1)"
 	args := []interface{}{accountID}
 	argPos := 2
 
-	// Add filters
 	if startDate, ok := filters["start_date"].(time.Time); ok {
-		query += fmt.Sprintf(" AND created_at >= $%d", argPos)
+		where += fmt.Sprintf(" AND created_at >= $%d", argPos)
 		args = append(args, startDate)
 		argPos++
 	}
 
 	if endDate, ok := filters["end_date"].(time.Time); ok {
-		query += fmt.Sprintf(" AND created_at <= $%d", argPos)
+		where += fmt.Sprintf(" AND created_at <= $%d", argPos)
 		args = append(args, endDate)
 		argPos++
 	}
 
 	if txnType, ok := filters["type"].(string); ok {
-		query += fmt.Sprintf(" AND transaction_type = $%d", argPos)
+		where += fmt.Sprintf(" AND transaction_type = $%d", argPos)
 		args = append(args, txnType)
-		argPos++
 	}
 
-	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", argPos, argPos+1)
-	args = append(args, limit, offset)
+	return where, args
+}
+
+// transactionSearchVector is the full-text document of a transaction: its
+// description and the string values of its metadata, such as biller and
+// merchant names. It must match the expression of idx_transactions_search.
+const transactionSearchVector = `(to_tsvector('simple', COALESCE(t.description, '')) ||
+	jsonb_to_tsvector('simple', COALESCE(t.metadata, '{}'::jsonb), '["string"]'))`
+
+// transactionSearchFrom joins each transaction to its counterparty, the other
+// side of it, so a transfer to Bob matches "bob" by the owner of the
+// destination account
+const transactionSearchFrom = `
+		FROM transactions t
+		LEFT JOIN accounts ca ON ca.id = CASE WHEN t.from_account_id = ANY($1::uuid[]) THEN t.to_account_id ELSE t.from_account_id END
+		LEFT JOIN users cu ON cu.id = ca.user_id
+		WHERE (t.from_account_id = ANY($1::uuid[]) OR t.to_account_id = ANY($1::uuid[]))`
+
+func (r *transactionRepository) Search(f *transaction.SearchFilter) ([]*transaction.Transaction, error) {
+	if len(f.AccountIDs) == 0 {
+		return []*transaction.Transaction{}, nil
+	}
+
+	where, args := searchClause(f)
+	orderBy := "t.created_at DESC"
+	if f.Query != "" {
+		// The query text is always $2
+		orderBy = "ts_rank(" + transactionSearchVector + ", plainto_tsquery('simple', $2)) DESC, t.created_at DESC"
+	}
+
+	query := `
+		SELECT t.id, t.idempotency_key, t.from_account_id, t.to_account_id, t.amount,
+		       t.transaction_type, t.status, t.description, t.metadata, t.created_at, t.completed_at` +
+		transactionSearchFrom + where +
+		fmt.Sprintf(" ORDER BY %s LIMIT $%d OFFSET $%d", orderBy, len(args)+1, len(args)+2)
+	args = append(args, f.Limit, f.Offset)
 
 	rows, err := r.db.Query(query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get transactions: %w", err)
+		return nil, fmt.Errorf("failed to search transactions: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	return scanTransactions(rows)
+}
+
+func (r *transactionRepository) CountSearch(f *transaction.SearchFilter) (int, error) {
+	if len(f.AccountIDs) == 0 {
+		return 0, nil
+	}
+
+	where, args := searchClause(f)
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*)`+transactionSearchFrom+where, args...).Scan(&total); err != nil {
+		return 0, fmt.Errorf("failed to count transactions: %w", err)
+	}
+	return total, nil
+}
+
+// searchClause builds the conditions added to transactionSearchFrom, shared
+// by the search and its count. The accounts are $1 and the query text $2.
+func searchClause(f *transaction.SearchFilter) (string, []interface{}) {
+	where := ""
+	args := []interface{}{pq.Array(f.AccountIDs)}
+	argPos := 2
+
+	if f.Query != "" {
+		// Whole words through the full-text index, substrings and typos
+		// through trigram matching
+		where += fmt.Sprintf(`
+		  AND (%[1]s @@ plainto_tsquery('simple', $%[2]d)
+		       OR t.description ILIKE $%[3]d OR $%[2]d <%% t.description
+		       OR cu.first_name || ' ' || cu.last_name ILIKE $%[3]d
+		       OR $%[2]d <%% (cu.first_name || ' ' || cu.last_name)
+		       OR ca.account_number = $%[2]d)`, transactionSearchVector, argPos, argPos+1)
+		args = append(args, f.Query, f.ContainsPattern())
+		argPos += 2
+	}
+
+	if f.MinAmount > 0 {
+		where += fmt.Sprintf(" AND t.amount >= $%d", argPos)
+		args = append(args, f.MinAmount)
+		argPos++
+	}
+
+	if f.MaxAmount > 0 {
+		where += fmt.Sprintf(" AND t.amount <= $%d", argPos)
+		args = append(args, f.MaxAmount)
+		argPos++
+	}
+
+	if f.Status != "" {
+		where += fmt.Sprintf(" AND t.status = $%d", argPos)
+		args = append(args, f.Status)
+		argPos++
+	}
+
+	if f.Type != "" {
+		where += fmt.Sprintf(" AND t.transaction_type = $%d", argPos)
+		args = append(args, f.Type)
+		argPos++
+	}
+
+	if f.From != nil {
+		where += fmt.Sprintf(" AND t.created_at >= $%d", argPos)
+		args = append(args, f.From.UTC())
+		argPos++
+	}
+
+	if f.To != nil {
+		where += fmt.Sprintf(" AND t.created_at < $%d", argPos)
+		args = append(args, f.To.UTC())
+	}
+
+	return where, args
+}
+
+// ListCompleted returns the transactions completed in [from, to), in posting order
+func (r *transactionRepository) ListCompleted(from, to time.Time) ([]*transaction.Transaction, error) {
+	query := `
+		SELECT id, idempotency_key, from_account_id, to_account_id, amount,
+		       transaction_type, status, description, metadata, created_at, completed_at
+		FROM transactions
+		WHERE status = $1
+		  AND COALESCE(completed_at, created_at) >= $2
+		  AND COALESCE(completed_at, created_at) < $3
+		ORDER BY COALESCE(completed_at, created_at), id
+	`
+
+	rows, err := r.db.Query(query, transaction.TransactionStatusCompleted, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list completed transactions: %w", err)
 	}
 	defer func() {
 		_ = rows.Close()
 	}()
 
-	return r.scanTransactions(rows)
+	return scanTransactions(rows)
+}
+
+// ListCompletedByAccount returns the account's transactions completed in
+// [from, to), in posting order
+func (r *transactionRepository) ListCompletedByAccount(accountID uuid.UUID, from, to time.Time) ([]*transaction.Transaction, error) {
+	query := `
+		SELECT id, idempotency_key, from_account_id, to_account_id, amount,
+		       transaction_type, status, description, metadata, created_at, completed_at
+		FROM transactions
+		WHERE (from_account_id = $1 OR to_account_id = $1)
+		  AND status = $2
+		  AND COALESCE(completed_at, created_at) >= $3
+		  AND COALESCE(completed_at, created_at) < $4
+		ORDER BY COALESCE(completed_at, created_at), id
+	`
+
+	rows, err := r.db.Query(query, accountID, transaction.TransactionStatusCompleted, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list completed transactions: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	return scanTransactions(rows)
 }
 
 func (r *transactionRepository) UpdateStatus(id uuid.UUID, status transaction.TransactionStatus) error {
@@ -237,10 +545,18 @@ func (r *transactionRepository) UpdateStatus(id uuid.UUID, status transaction.Tr
 	return nil
 }
 
-// ExecuteTransfer performs a transfer with ACID guarantees using database transaction
+// ExecuteTransfer performs a transfer with ACID guarantees using database
+// transaction. The amount moves in txn's currency, between the balances both
+// accounts hold in it. Serialization failures and deadlocks are retried.
 func (r *transactionRepository) ExecuteTransfer(fromAccountID, toAccountID uuid.UUID, amount float64, txn *transaction.Transaction) error {
+	return withRetry("transfer", func() error {
+		return r.executeTransfer(fromAccountID, toAccountID, amount, txn)
+	})
+}
+
+func (r *transactionRepository) executeTransfer(fromAccountID, toAccountID uuid.UUID, amount float64, txn *transaction.Transaction) error {
 	// Start database transaction
-	dbTx, err := r.db.Begin()
+	dbTx, err := r.begin()
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -248,37 +564,31 @@ func (r *transactionRepository) ExecuteTransfer(fromAccountID, toAccountID uuid.
 		_ = dbTx.Rollback() // Rollback if not committed
 	}()
 
-	// Lock both accounts for update (prevents race conditions)
-	var fromBalance, toBalance float64
-	var fromStatus, toStatus string
-
-	// Lock source account
-	query := `SELECT balance, status FROM accounts WHERE id = $1 AND status = 'active' FOR UPDATE`
-	err = dbTx.QueryRow(query, fromAccountID).Scan(&fromBalance, &fromStatus)
+	// Lock both balances for update (prevents race conditions)
+	from, err := lockBalance(dbTx, fromAccountID, txn.Currency())
 	if err != nil {
 		return fmt.Errorf("failed to lock source account: %w", err)
 	}
-
-	// Lock destination account
-	err = dbTx.QueryRow(query, toAccountID).Scan(&toBalance, &toStatus)
+	to, err := lockBalance(dbTx, toAccountID, txn.Currency())
 	if err != nil {
 		return fmt.Errorf("failed to lock destination account: %w", err)
 	}
 
-	// Validate sufficient balance
-	if fromBalance < amount {
-		return fmt.Errorf("insufficient balance: have %.2f, need %.2f", fromBalance, amount)
+	// Validate sufficient funds; held funds are not available
+	if from.available < amount {
+		return insufficientFunds(from.available, amount)
 	}
 
 	// Debit source account
-	_, err = dbTx.Exec(`UPDATE accounts SET balance = balance - $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`, amount, fromAccountID)
-	if err != nil {
+	if err := from.add(dbTx, -amount); err != nil {
+		if errors.Is(err, transaction.ErrInsufficientFunds) {
+			return err
+		}
 		return fmt.Errorf("failed to debit source account: %w", err)
 	}
 
 	// Credit destination account
-	_, err = dbTx.Exec(`UPDATE accounts SET balance = balance + $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`, amount, toAccountID)
-	if err != nil {
+	if err := to.add(dbTx, amount); err != nil {
 		return fmt.Errorf("failed to credit destination account: %w", err)
 	}
 
@@ -293,6 +603,13 @@ func (r *transactionRepository) ExecuteTransfer(fromAccountID, toAccountID uuid.
 		return fmt.Errorf("failed to insert transaction: %w", err)
 	}
 
+	txn.FromAccountID, txn.ToAccountID, txn.Amount = &fromAccountID, &toAccountID, amount
+	if from.own {
+		if err := runDebitHooks(dbTx, r.hooks, txn); err != nil {
+			return err
+		}
+	}
+
 	// Commit transaction - ACID guarantee
 	if err := dbTx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
@@ -301,9 +618,16 @@ func (r *transactionRepository) ExecuteTransfer(fromAccountID, toAccountID uuid.
 	return nil
 }
 
-// ExecuteDeposit performs a deposit with ACID guarantees
+// ExecuteDeposit performs a deposit with ACID guarantees, retrying
+// serialization failures and deadlocks
 func (r *transactionRepository) ExecuteDeposit(accountID uuid.UUID, amount float64, txn *transaction.Transaction) error {
-	dbTx, err := r.db.Begin()
+	return withRetry("deposit", func() error {
+		return r.executeDeposit(accountID, amount, txn)
+	})
+}
+
+func (r *transactionRepository) executeDeposit(accountID uuid.UUID, amount float64, txn *transaction.Transaction) error {
+	dbTx, err := r.begin()
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -312,15 +636,13 @@ func (r *transactionRepository) ExecuteDeposit(accountID uuid.UUID, amount float
 	}()
 
 	// Lock account
-	var status string
-	err = dbTx.QueryRow(`SELECT status FROM accounts WHERE id = $1 AND status = 'active' FOR UPDATE`, accountID).Scan(&status)
+	held, err := lockBalance(dbTx, accountID, txn.Currency())
 	if err != nil {
 		return fmt.Errorf("failed to lock account: %w", err)
 	}
 
 	// Credit account
-	_, err = dbTx.Exec(`UPDATE accounts SET balance = balance + $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`, amount, accountID)
-	if err != nil {
+	if err := held.add(dbTx, amount); err != nil {
 		return fmt.Errorf("failed to credit account: %w", err)
 	}
 
@@ -341,9 +663,16 @@ func (r *transactionRepository) ExecuteDeposit(accountID uuid.UUID, amount float
 	return nil
 }
 
-// ExecuteWithdrawal performs a withdrawal with ACID guarantees
+// ExecuteWithdrawal performs a withdrawal with ACID guarantees, retrying
+// serialization failures and deadlocks
 func (r *transactionRepository) ExecuteWithdrawal(accountID uuid.UUID, amount float64, txn *transaction.Transaction) error {
-	dbTx, err := r.db.Begin()
+	return withRetry("withdrawal", func() error {
+		return r.executeWithdrawal(accountID, amount, txn)
+	})
+}
+
+func (r *transactionRepository) executeWithdrawal(accountID uuid.UUID, amount float64, txn *transaction.Transaction) error {
+	dbTx, err := r.begin()
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -352,20 +681,20 @@ func (r *transactionRepository) ExecuteWithdrawal(accountID uuid.UUID, amount fl
 	}()
 
 	// Lock account and check balance
-	var balance float64
-	var status string
-	err = dbTx.QueryRow(`SELECT balance, status FROM accounts WHERE id = $1 AND status = 'active' FOR UPDATE`, accountID).Scan(&balance, &status)
+	held, err := lockBalance(dbTx, accountID, txn.Currency())
 	if err != nil {
 		return fmt.Errorf("failed to lock account: %w", err)
 	}
 
-	if balance < amount {
-		return fmt.Errorf("insufficient balance: have %.2f, need %.2f", balance, amount)
+	if held.available < amount {
+		return insufficientFunds(held.available, amount)
 	}
 
 	// Debit account
-	_, err = dbTx.Exec(`UPDATE accounts SET balance = balance - $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`, amount, accountID)
-	if err != nil {
+	if err := held.add(dbTx, -amount); err != nil {
+		if errors.Is(err, transaction.ErrInsufficientFunds) {
+			return err
+		}
 		return fmt.Errorf("failed to debit account: %w", err)
 	}
 
@@ -379,6 +708,13 @@ func (r *transactionRepository) ExecuteWithdrawal(accountID uuid.UUID, amount fl
 		return fmt.Errorf("failed to insert transaction: %w", err)
 	}
 
+	txn.FromAccountID, txn.Amount = &accountID, amount
+	if held.own {
+		if err := runDebitHooks(dbTx, r.hooks, txn); err != nil {
+			return err
+		}
+	}
+
 	if err := dbTx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
@@ -386,7 +722,7 @@ func (r *transactionRepository) ExecuteWithdrawal(accountID uuid.UUID, amount fl
 	return nil
 }
 
-func (r *transactionRepository) scanTransactions(rows *sql.Rows) ([]*transaction.Transaction, error) {
+func scanTransactions(rows *sql.Rows) ([]*transaction.Transaction, error) {
 	transactions := []*transaction.Transaction{}
 
 	for rows.Next() {
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/google/uuid"
+)
+
+type TransferTemplateRepository interface {
+	Create(t *transaction.Template) error
+	GetByID(id uuid.UUID) (*transaction.Template, error)
+	ListByUser(userID uuid.UUID) ([]*transaction.Template, error)
+	// Update saves the name, amount and description
+	Update(t *transaction.Template) error
+	MarkUsed(id uuid.UUID, at time.Time) error
+	Delete(id uuid.UUID) error
+}
+
+type transferTemplateRepository struct {
+	db *sql.DB
+}
+
+func NewTransferTemplateRepository(db *sql.DB) TransferTemplateRepository {
+	return &transferTemplateRepository{db: db}
+}
+
+const transferTemplateColumns = `id, user_id, name, from_account_id, to_account_id, beneficiary_id, amount, description,
+	last_used_at, created_at, updated_at`
+
+func scanTransferTemplate(row rowScanner) (*transaction.Template, error) {
+	t := &transaction.Template{}
+	err := row.Scan(
+		&t.ID, &t.UserID, &t.Name, &t.FromAccountID, &t.ToAccountID, &t.BeneficiaryID, &t.Amount, &t.Description,
+		&t.LastUsedAt, &t.CreatedAt, &t.UpdatedAt,
+	)
+	return t, err
+}
+
+func (r *transferTemplateRepository) Create(t *transaction.Template) error {
+	err := r.db.QueryRow(`
+		INSERT INTO transfer_templates (id, user_id, name, from_account_id, to_account_id, beneficiary_id, amount, description)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at, updated_at
+	`, t.ID, t.UserID, t.Name, t.FromAccountID, t.ToAccountID, t.BeneficiaryID, t.Amount, t.Description,
+	).Scan(&t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create transfer template: %w", err)
+	}
+	return nil
+}
+
+func (r *transferTemplateRepository) GetByID(id uuid.UUID) (*transaction.Template, error) {
+	t, err := scanTransferTemplate(r.db.QueryRow(`SELECT `+transferTemplateColumns+` FROM transfer_templates WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("transfer template not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transfer template: %w", err)
+	}
+	return t, nil
+}
+
+func (r *transferTemplateRepository) ListByUser(userID uuid.UUID) ([]*transaction.Template, error) {
+	rows, err := r.db.Query(`
+		SELECT `+transferTemplateColumns+` FROM transfer_templates
+		WHERE user_id = $1
+		ORDER BY last_used_at DESC NULLS LAST, name
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transfer templates: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	templates := []*transaction.Template{}
+	for rows.Next() {
+		t, err := scanTransferTemplate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transfer template: %w", err)
+		}
+		templates = append(templates, t)
+	}
+
+	return templates, rows.Err()
+}
+
+func (r *transferTemplateRepository) Update(t *transaction.Template) error {
+	return r.exec(`
+		UPDATE transfer_templates SET name = $1, amount = $2, description = $3 WHERE id = $4
+	`, t.Name, t.Amount, t.Description, t.ID)
+}
+
+func (r *transferTemplateRepository) MarkUsed(id uuid.UUID, at time.Time) error {
+	return r.exec(`UPDATE transfer_templates SET last_used_at = $1 WHERE id = $2`, at.UTC(), id)
+}
+
+func (r *transferTemplateRepository) Delete(id uuid.UUID) error {
+	return r.exec(`DELETE FROM transfer_templates WHERE id = $1`, id)
+}
+
+func (r *transferTemplateRepository) exec(query string, args ...interface{}) error {
+	result, err := r.db.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update transfer template: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("transfer template not found")
+	}
+
+	return nil
+}
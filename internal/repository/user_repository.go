@@ -13,15 +13,44 @@ type UserRepository interface {
 	Create(user *user.User) error
 	GetByID(id uuid.UUID) (*user.User, error)
 	GetByEmail(email string) (*user.User, error)
+	// GetByPhone finds the user who verified the phone number. Unverified
+	// numbers never match, so they cannot be used to log in or receive payments.
 	GetByPhone(phone string) (*user.User, error)
+	// GetByUsername finds a user by their normalized username
+	GetByUsername(username string) (*user.User, error)
+	// GetForReactivation finds a user by email, including soft-deleted ones
+	// whose personal data has not been anonymized yet
+	GetForReactivation(email string) (*user.User, error)
 	Update(id uuid.UUID, updates map[string]interface{}) error
+	// VerifyPhone marks the user's phone as verified, provided it is still
+	// phone and no other user has verified it
+	VerifyPhone(id uuid.UUID, phone string) error
+	// Delete soft-deletes the user and freezes their active accounts
 	Delete(id uuid.UUID) error
-	List(limit, offset int) ([]*user.User, error)
+	// Restore reverses Delete and re-enables a deactivated user
+	Restore(id uuid.UUID) error
+	// List returns up to f.Limit+1 users after the cursor, so the caller can
+	// tell whether another page follows
+	List(f *user.ListFilter) ([]*user.User, error)
+	// ListAnonymizable returns users soft-deleted before the cutoff whose
+	// personal data is still on file, oldest deletion first
+	ListAnonymizable(deletedBefore time.Time, limit int) ([]uuid.UUID, error)
+	Anonymize(id uuid.UUID) error
 
 	// Refresh Token methods
-	SaveRefreshToken(userID uuid.UUID, tokenHash string, expiresAt time.Time) error
-	GetRefreshToken(tokenHash string) (uuid.UUID, time.Time, error)
+	// SaveRefreshToken stores the refresh token of a new session
+	SaveRefreshToken(userID uuid.UUID, tokenHash string, clientType user.ClientType, expiresAt time.Time) error
+	GetRefreshToken(tokenHash string) (*user.RefreshSession, error)
 	RevokeRefreshToken(tokenHash string) error
+	// RevokeAllRefreshTokens signs the user out of every session
+	RevokeAllRefreshTokens(userID uuid.UUID) error
+
+	// Login history methods
+	// GetLoginFamiliarity checks the device and country against the user's
+	// previous logins
+	GetLoginFamiliarity(userID uuid.UUID, origin user.LoginOrigin) (*user.LoginFamiliarity, error)
+	// RecordLogin adds the device and country to the user's login history
+	RecordLogin(userID uuid.UUID, origin user.LoginOrigin) error
 }
 
 type userRepository struct {
@@ -32,13 +61,42 @@ func NewUserRepository(db *sql.DB) UserRepository {
 	return &userRepository{db: db}
 }
 
+const userColumns = `id, email, username, password_hash, first_name, last_name, phone, phone_verified_at, date_of_birth,
+	kyc_status, role, is_active, created_at, updated_at, deleted_at`
+
+func scanUser(row rowScanner) (*user.User, error) {
+	u := &user.User{}
+	err := row.Scan(
+		&u.ID,
+		&u.Email,
+		&u.Username,
+		&u.PasswordHash,
+		&u.FirstName,
+		&u.LastName,
+		&u.Phone,
+		&u.PhoneVerifiedAt,
+		&u.DateOfBirth,
+		&u.KYCStatus,
+		&u.Role,
+		&u.IsActive,
+		&u.CreatedAt,
+		&u.UpdatedAt,
+		&u.DeletedAt,
+	)
+	return u, err
+}
+
 func (r *userRepository) Create(u *user.User) error {
 	query := `
-		INSERT INTO users (id, email, password_hash, first_name, last_name, phone, date_of_birth, kyc_status, is_active)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO users (id, email, password_hash, first_name, last_name, phone, date_of_birth, kyc_status, role, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING created_at, updated_at
 	`
 
+	if u.Role == "" {
+		u.Role = user.RoleCustomer
+	}
+
 	err := r.db.QueryRow(
 		query,
 		u.ID,
@@ -49,6 +107,7 @@ func (r *userRepository) Create(u *user.User) error {
 		u.Phone,
 		u.DateOfBirth,
 		u.KYCStatus,
+		u.Role,
 		u.IsActive,
 	).Scan(&u.CreatedAt, &u.UpdatedAt)
 
@@ -60,29 +119,7 @@ func (r *userRepository) Create(u *user.User) error {
 }
 
 func (r *userRepository) GetByID(id uuid.UUID) (*user.User, error) {
-	query := `
-		SELECT id, email, password_hash, first_name, last_name, phone, date_of_birth, 
-		       kyc_status, is_active, created_at, updated_at, deleted_at
-		FROM users
-		WHERE id = $1 AND deleted_at IS NULL
-	`
-
-	u := &user.User{}
-	err := r.db.QueryRow(query, id).Scan(
-		&u.ID,
-		&u.Email,
-		&u.PasswordHash,
-		&u.FirstName,
-		&u.LastName,
-		&u.Phone,
-		&u.DateOfBirth,
-		&u.KYCStatus,
-		&u.IsActive,
-		&u.CreatedAt,
-		&u.UpdatedAt,
-		&u.DeletedAt,
-	)
-
+	u, err := scanUser(r.db.QueryRow(`SELECT `+userColumns+` FROM users WHERE id = $1 AND deleted_at IS NULL`, id))
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("user not found")
 	}
@@ -94,29 +131,7 @@ func (r *userRepository) GetByID(id uuid.UUID) (*user.User, error) {
 }
 
 func (r *userRepository) GetByEmail(email string) (*user.User, error) {
-	query := `
-		SELECT id, email, password_hash, first_name, last_name, phone, date_of_birth,
-		       kyc_status, is_active, created_at, updated_at, deleted_at
-		FROM users
-		WHERE email = $1 AND deleted_at IS NULL
-	`
-
-	u := &user.User{}
-	err := r.db.QueryRow(query, email).Scan(
-		&u.ID,
-		&u.Email,
-		&u.PasswordHash,
-		&u.FirstName,
-		&u.LastName,
-		&u.Phone,
-		&u.DateOfBirth,
-		&u.KYCStatus,
-		&u.IsActive,
-		&u.CreatedAt,
-		&u.UpdatedAt,
-		&u.DeletedAt,
-	)
-
+	u, err := scanUser(r.db.QueryRow(`SELECT `+userColumns+` FROM users WHERE email = $1 AND deleted_at IS NULL`, email))
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("user not found")
 	}
@@ -128,29 +143,19 @@ func (r *userRepository) GetByEmail(email string) (*user.User, error) {
 }
 
 func (r *userRepository) GetByPhone(phone string) (*user.User, error) {
-	query := `
-		SELECT id, email, password_hash, first_name, last_name, phone, date_of_birth,
-		       kyc_status, is_active, created_at, updated_at, deleted_at
-		FROM users
-		WHERE phone = $1 AND deleted_at IS NULL
-	`
+	u, err := scanUser(r.db.QueryRow(`SELECT `+userColumns+` FROM users WHERE phone = $1 AND phone_verified_at IS NOT NULL AND deleted_at IS NULL`, phone))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("user not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
 
-	u := &user.User{}
-	err := r.db.QueryRow(query, phone).Scan(
-		&u.ID,
-		&u.Email,
-		&u.PasswordHash,
-		&u.FirstName,
-		&u.LastName,
-		&u.Phone,
-		&u.DateOfBirth,
-		&u.KYCStatus,
-		&u.IsActive,
-		&u.CreatedAt,
-		&u.UpdatedAt,
-		&u.DeletedAt,
-	)
+	return u, nil
+}
 
+func (r *userRepository) GetByUsername(username string) (*user.User, error) {
+	u, err := scanUser(r.db.QueryRow(`SELECT `+userColumns+` FROM users WHERE username = $1 AND deleted_at IS NULL`, username))
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("user not found")
 	}
@@ -196,11 +201,42 @@ func (r *userRepository) Update(id uuid.UUID, updates map[string]interface{}) er
 	return nil
 }
 
+func (r *userRepository) VerifyPhone(id uuid.UUID, phone string) error {
+	result, err := r.db.Exec(`
+		UPDATE users SET phone_verified_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND phone = $2 AND deleted_at IS NULL
+		  AND NOT EXISTS (
+		      SELECT 1 FROM users other
+		      WHERE other.phone = $2 AND other.phone_verified_at IS NOT NULL AND other.id != $1 AND other.deleted_at IS NULL
+		  )
+	`, id, phone)
+	if err != nil {
+		return fmt.Errorf("failed to verify phone: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("phone number could not be verified")
+	}
+
+	return nil
+}
+
 func (r *userRepository) Delete(id uuid.UUID) error {
-	// Soft delete
-	query := `UPDATE users SET deleted_at = $1 WHERE id = $2 AND deleted_at IS NULL`
+	dbTx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = dbTx.Rollback() // Rollback if not committed
+	}()
 
-	result, err := r.db.Exec(query, time.Now(), id)
+	// Soft delete
+	result, err := dbTx.Exec(`UPDATE users SET deleted_at = $1 WHERE id = $2 AND deleted_at IS NULL`, time.Now(), id)
 	if err != nil {
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
@@ -214,20 +250,87 @@ func (r *userRepository) Delete(id uuid.UUID) error {
 		return fmt.Errorf("user not found")
 	}
 
+	// Freeze the accounts so they cannot be paid into or charged while the
+	// user is gone. Restore only unfreezes the ones frozen here.
+	_, err = dbTx.Exec(`
+		UPDATE accounts SET status = 'frozen', frozen_for_deletion = true, updated_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND status = 'active'
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to freeze accounts: %w", err)
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return nil
 }
 
-func (r *userRepository) List(limit, offset int) ([]*user.User, error) {
-	query := `
-		SELECT id, email, password_hash, first_name, last_name, phone, date_of_birth,
-		       kyc_status, is_active, created_at, updated_at, deleted_at
-		FROM users
-		WHERE deleted_at IS NULL
-		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2
-	`
+func (r *userRepository) GetForReactivation(email string) (*user.User, error) {
+	u, err := scanUser(r.db.QueryRow(`SELECT `+userColumns+` FROM users WHERE email = $1 AND anonymized_at IS NULL`, email))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("user not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
 
-	rows, err := r.db.Query(query, limit, offset)
+	return u, nil
+}
+
+func (r *userRepository) Restore(id uuid.UUID) error {
+	dbTx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = dbTx.Rollback() // Rollback if not committed
+	}()
+
+	result, err := dbTx.Exec(`
+		UPDATE users SET deleted_at = NULL, is_active = true
+		WHERE id = $1 AND anonymized_at IS NULL
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to restore user: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	_, err = dbTx.Exec(`
+		UPDATE accounts SET status = 'active', frozen_for_deletion = false, updated_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND frozen_for_deletion
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to unfreeze accounts: %w", err)
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *userRepository) List(f *user.ListFilter) ([]*user.User, error) {
+	where, args := userListClause(f)
+	args = append(args, f.Limit+1)
+	query := fmt.Sprintf(`
+		SELECT %s FROM users
+		WHERE %s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d
+	`, userColumns, where, len(args))
+
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list users: %w", err)
 	}
@@ -235,21 +338,7 @@ func (r *userRepository) List(limit, offset int) ([]*user.User, error) {
 
 	users := []*user.User{}
 	for rows.Next() {
-		u := &user.User{}
-		err := rows.Scan(
-			&u.ID,
-			&u.Email,
-			&u.PasswordHash,
-			&u.FirstName,
-			&u.LastName,
-			&u.Phone,
-			&u.DateOfBirth,
-			&u.KYCStatus,
-			&u.IsActive,
-			&u.CreatedAt,
-			&u.UpdatedAt,
-			&u.DeletedAt,
-		)
+		u, err := scanUser(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan user: %w", err)
 		}
@@ -259,29 +348,139 @@ func (r *userRepository) List(limit, offset int) ([]*user.User, error) {
 	return users, nil
 }
 
-func (r *userRepository) SaveRefreshToken(userID uuid.UUID, tokenHash string, expiresAt time.Time) error {
-	query := `INSERT INTO refresh_tokens (id, user_id, token_hash, expires_at) VALUES ($1, $2, $3, $4)`
-	_, err := r.db.Exec(query, uuid.New(), userID, tokenHash, expiresAt)
+func (r *userRepository) ListAnonymizable(deletedBefore time.Time, limit int) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(`
+		SELECT id FROM users
+		WHERE deleted_at IS NOT NULL AND deleted_at < $1 AND anonymized_at IS NULL
+		ORDER BY deleted_at
+		LIMIT $2
+	`, deletedBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users to anonymize: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan user id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// Anonymize overwrites a soft-deleted user's personal data and drops what is
+// only kept for them, like saved beneficiaries and statement addresses.
+// Accounts, cards and transactions stay for the ledger.
+func (r *userRepository) Anonymize(id uuid.UUID) error {
+	dbTx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = dbTx.Rollback() // Rollback if not committed
+	}()
+
+	result, err := dbTx.Exec(`
+		UPDATE users
+		SET email = $2, username = NULL, first_name = $3, last_name = $4, phone = NULL, phone_verified_at = NULL, date_of_birth = NULL,
+		    password_hash = '', is_active = false, anonymized_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NOT NULL AND anonymized_at IS NULL
+	`, id, user.AnonymizedEmail(id), user.AnonymizedFirstName, user.AnonymizedLastName)
+	if err != nil {
+		return fmt.Errorf("failed to anonymize user: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("deleted user not found")
+	}
+
+	for _, stmt := range []string{
+		`DELETE FROM refresh_tokens WHERE user_id = $1`,
+		`DELETE FROM user_login_history WHERE user_id = $1`,
+		`DELETE FROM beneficiaries WHERE user_id = $1`,
+		`DELETE FROM statement_subscriptions WHERE user_id = $1`,
+		`UPDATE statement_deliveries SET email = NULL WHERE user_id = $1`,
+		`UPDATE cards SET card_holder_name = '` + user.AnonymizedCardHolder + `'
+		 WHERE account_id IN (SELECT id FROM accounts WHERE user_id = $1)`,
+	} {
+		if _, err := dbTx.Exec(stmt, id); err != nil {
+			return fmt.Errorf("failed to anonymize user data: %w", err)
+		}
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// userListClause builds the WHERE clause of a user search
+func userListClause(f *user.ListFilter) (string, []interface{}) {
+	where := "deleted_at IS NULL"
+	args := []interface{}{}
+
+	if f.Email != "" {
+		args = append(args, user.ContainsPattern(f.Email))
+		where += fmt.Sprintf(" AND email ILIKE $%d", len(args))
+	}
+	if f.Phone != "" {
+		args = append(args, user.ContainsPattern(f.Phone))
+		where += fmt.Sprintf(" AND phone LIKE $%d", len(args))
+	}
+	if f.KYCStatus != "" {
+		args = append(args, f.KYCStatus)
+		where += fmt.Sprintf(" AND kyc_status = $%d", len(args))
+	}
+	if f.IsActive != nil {
+		args = append(args, *f.IsActive)
+		where += fmt.Sprintf(" AND is_active = $%d", len(args))
+	}
+	if f.CreatedFrom != nil {
+		args = append(args, *f.CreatedFrom)
+		where += fmt.Sprintf(" AND created_at >= $%d", len(args))
+	}
+	if f.CreatedTo != nil {
+		args = append(args, *f.CreatedTo)
+		where += fmt.Sprintf(" AND created_at < $%d", len(args))
+	}
+	if f.After != nil {
+		args = append(args, f.After.CreatedAt, f.After.ID)
+		where += fmt.Sprintf(" AND (created_at, id) < ($%d, $%d)", len(args)-1, len(args))
+	}
+
+	return where, args
+}
+
+func (r *userRepository) SaveRefreshToken(userID uuid.UUID, tokenHash string, clientType user.ClientType, expiresAt time.Time) error {
+	query := `INSERT INTO refresh_tokens (id, user_id, token_hash, client_type, expires_at) VALUES ($1, $2, $3, $4, $5)`
+	_, err := r.db.Exec(query, uuid.New(), userID, tokenHash, clientType, expiresAt)
 	if err != nil {
 		return fmt.Errorf("failed to save refresh token: %w", err)
 	}
 	return nil
 }
 
-func (r *userRepository) GetRefreshToken(tokenHash string) (uuid.UUID, time.Time, error) {
-	var userID uuid.UUID
-	var expiresAt time.Time
+func (r *userRepository) GetRefreshToken(tokenHash string) (*user.RefreshSession, error) {
+	var session user.RefreshSession
 
-	query := `SELECT user_id, expires_at FROM refresh_tokens WHERE token_hash = $1 AND revoked = false`
-	err := r.db.QueryRow(query, tokenHash).Scan(&userID, &expiresAt)
+	query := `SELECT user_id, client_type, expires_at, session_started_at FROM refresh_tokens WHERE token_hash = $1 AND revoked = false`
+	err := r.db.QueryRow(query, tokenHash).Scan(&session.UserID, &session.ClientType, &session.ExpiresAt, &session.SessionStartedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return uuid.Nil, time.Time{}, fmt.Errorf("invalid or revoked token")
+			return nil, fmt.Errorf("invalid or revoked token")
 		}
-		return uuid.Nil, time.Time{}, fmt.Errorf("failed to get refresh token: %w", err)
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
 	}
 
-	return userID, expiresAt, nil
+	return &session, nil
 }
 
 func (r *userRepository) RevokeRefreshToken(tokenHash string) error {
@@ -292,3 +491,45 @@ func (r *userRepository) RevokeRefreshToken(tokenHash string) error {
 	}
 	return nil
 }
+
+func (r *userRepository) RevokeAllRefreshTokens(userID uuid.UUID) error {
+	query := `UPDATE refresh_tokens SET revoked = true WHERE user_id = $1 AND revoked = false`
+	_, err := r.db.Exec(query, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+	return nil
+}
+
+func (r *userRepository) GetLoginFamiliarity(userID uuid.UUID, origin user.LoginOrigin) (*user.LoginFamiliarity, error) {
+	query := `
+		SELECT COUNT(*),
+		       COALESCE(BOOL_OR(device_fingerprint = $2), false),
+		       COALESCE(BOOL_OR(country = $3), false)
+		FROM user_login_history
+		WHERE user_id = $1
+	`
+	var count int
+	f := &user.LoginFamiliarity{}
+	err := r.db.QueryRow(query, userID, origin.Fingerprint(), origin.Country).Scan(&count, &f.KnownDevice, &f.KnownCountry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get login history: %w", err)
+	}
+	f.FirstLogin = count == 0
+	return f, nil
+}
+
+func (r *userRepository) RecordLogin(userID uuid.UUID, origin user.LoginOrigin) error {
+	query := `
+		INSERT INTO user_login_history (user_id, device_fingerprint, country, ip_address, user_agent)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, device_fingerprint, country) DO UPDATE
+		SET ip_address = EXCLUDED.ip_address, user_agent = EXCLUDED.user_agent,
+		    login_count = user_login_history.login_count + 1, last_seen_at = CURRENT_TIMESTAMP
+	`
+	_, err := r.db.Exec(query, userID, origin.Fingerprint(), origin.Country, origin.IPAddress, origin.UserAgent)
+	if err != nil {
+		return fmt.Errorf("failed to record login: %w", err)
+	}
+	return nil
+}
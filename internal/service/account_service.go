@@ -1,35 +1,76 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/audit"
+	"github.com/darisadam/madabank-server/internal/domain/currency"
+	"github.com/darisadam/madabank-server/internal/domain/interest"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/pkg/notifier"
 	"github.com/darisadam/madabank-server/internal/repository"
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 )
 
 type AccountService interface {
 	CreateAccount(userID uuid.UUID, req *account.CreateAccountRequest) (*account.Account, error)
+	// GetAccount returns the account's details, with the rate tiers and
+	// effective rate of a savings account
 	GetAccount(accountID uuid.UUID, userID uuid.UUID) (*account.Account, error)
 	GetAccountByNumber(accountNumber string, userID uuid.UUID) (*account.Account, error)
-	GetUserAccounts(userID uuid.UUID) ([]*account.Account, error)
+	// GetUserAccounts returns a page of the user's accounts and how many match in total
+	GetUserAccounts(f *account.ListFilter) ([]*account.Account, int, error)
 	GetBalance(accountID uuid.UUID, userID uuid.UUID) (*account.BalanceResponse, error)
+	// AddCurrency opens a balance in another currency, so the account can
+	// send and receive in it
+	AddCurrency(accountID uuid.UUID, userID uuid.UUID, req *account.AddCurrencyRequest) (*account.CurrencyBalance, error)
+	// UpdateAccount changes the account's status. Holders freeze for
+	// customer_request and can only lift freezes of their own; freezes and
+	// unfreezes are audited and emailed to the holder. It fails with
+	// account.ErrVersionConflict if the account changed concurrently.
 	UpdateAccount(accountID uuid.UUID, userID uuid.UUID, req *account.UpdateAccountRequest) (*account.Account, error)
+	// FreezeAccount freezes an account for the bank, for any freeze reason. A
+	// frozen account takes the new reason. metadata is added to the audit entry.
+	FreezeAccount(accountID uuid.UUID, reason account.FreezeReason, metadata map[string]interface{}) (*account.Account, error)
 	CloseAccount(accountID uuid.UUID, userID uuid.UUID) error
 }
 
 type accountService struct {
-	accountRepo repository.AccountRepository
+	accountRepo  repository.AccountRepository
+	userRepo     repository.UserRepository
+	auditRepo    repository.AuditRepository
+	interestRepo repository.InterestRepository
+	notifier     notifier.Notifier
 }
 
-func NewAccountService(accountRepo repository.AccountRepository) AccountService {
+func NewAccountService(
+	accountRepo repository.AccountRepository,
+	userRepo repository.UserRepository,
+	auditRepo repository.AuditRepository,
+	interestRepo repository.InterestRepository,
+	notifier notifier.Notifier,
+) AccountService {
 	return &accountService{
-		accountRepo: accountRepo,
+		accountRepo:  accountRepo,
+		userRepo:     userRepo,
+		auditRepo:    auditRepo,
+		interestRepo: interestRepo,
+		notifier:     notifier,
 	}
 }
 
 func (s *accountService) CreateAccount(userID uuid.UUID, req *account.CreateAccountRequest) (*account.Account, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Check max accounts limit (3 per user)
 	existingAccounts, err := s.accountRepo.GetByUserID(userID)
 	if err == nil && len(existingAccounts) >= 3 {
@@ -81,6 +122,25 @@ func (s *accountService) CreateAccount(userID uuid.UUID, req *account.CreateAcco
 }
 
 func (s *accountService) GetAccount(accountID uuid.UUID, userID uuid.UUID) (*account.Account, error) {
+	acc, err := s.getOwnedAccount(accountID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if acc.AccountType == account.AccountTypeSavings {
+		tiers, err := s.interestRepo.ListRateTiers()
+		if err != nil {
+			return nil, err
+		}
+		acc.InterestTiers = tiers.For(string(acc.AccountType), acc.Currency)
+		acc.EffectiveInterestRate = interest.EffectiveRate(acc.Balance, acc.InterestRate, acc.InterestTiers)
+	}
+
+	return acc, nil
+}
+
+// getOwnedAccount loads an account, checking it belongs to the user
+func (s *accountService) getOwnedAccount(accountID uuid.UUID, userID uuid.UUID) (*account.Account, error) {
 	acc, err := s.accountRepo.GetByID(accountID)
 	if err != nil {
 		return nil, err
@@ -108,69 +168,253 @@ func (s *accountService) GetAccountByNumber(accountNumber string, userID uuid.UU
 	return acc, nil
 }
 
-func (s *accountService) GetUserAccounts(userID uuid.UUID) ([]*account.Account, error) {
-	return s.accountRepo.GetByUserID(userID)
+func (s *accountService) GetUserAccounts(f *account.ListFilter) ([]*account.Account, int, error) {
+	accounts, err := s.accountRepo.ListByUser(f)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.accountRepo.CountByUser(f)
+	if err != nil {
+		return nil, 0, err
+	}
+	return accounts, total, nil
 }
 
 func (s *accountService) GetBalance(accountID uuid.UUID, userID uuid.UUID) (*account.BalanceResponse, error) {
-	acc, err := s.GetAccount(accountID, userID)
+	acc, err := s.getOwnedAccount(accountID, userID)
 	if err != nil {
 		return nil, err
 	}
 
+	others, err := s.accountRepo.ListBalances(acc.ID)
+	if err != nil {
+		return nil, err
+	}
+	balances := []account.CurrencyBalance{*account.NewCurrencyBalance(acc.Currency, acc.Balance)}
+	for _, b := range others {
+		balances = append(balances, *b)
+	}
+
+	held, err := s.accountRepo.SumActiveHolds(acc.ID)
+	if err != nil {
+		return nil, err
+	}
+	available := acc.AvailableBalance(held)
+
 	return &account.BalanceResponse{
-		AccountID:     acc.ID,
-		AccountNumber: acc.AccountNumber,
-		Balance:       acc.Balance,
-		Currency:      acc.Currency,
-		AsOfDate:      acc.UpdatedAt,
+		AccountID:                 acc.ID,
+		AccountNumber:             acc.AccountNumber,
+		Balance:                   acc.Balance,
+		FormattedBalance:          balances[0].FormattedBalance,
+		LedgerBalance:             acc.Balance,
+		AvailableBalance:          available,
+		FormattedAvailableBalance: currency.Format(acc.Currency, available),
+		HeldAmount:                held,
+		OverdraftLimit:            acc.OverdraftLimit,
+		Currency:                  acc.Currency,
+		AsOfDate:                  acc.UpdatedAt,
+		Balances:                  balances,
 	}, nil
 }
 
+func (s *accountService) AddCurrency(accountID uuid.UUID, userID uuid.UUID, req *account.AddCurrencyRequest) (*account.CurrencyBalance, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	acc, err := s.getOwnedAccount(accountID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if acc.Status != account.AccountStatusActive {
+		return nil, fmt.Errorf("account is %s, cannot add currencies", acc.Status)
+	}
+	if req.Currency == acc.Currency {
+		return nil, fmt.Errorf("account already holds %s", req.Currency)
+	}
+
+	if err := s.accountRepo.AddCurrency(acc.ID, req.Currency); err != nil {
+		return nil, err
+	}
+	return account.NewCurrencyBalance(req.Currency, 0), nil
+}
+
 func (s *accountService) UpdateAccount(accountID uuid.UUID, userID uuid.UUID, req *account.UpdateAccountRequest) (*account.Account, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Verify ownership
-	acc, err := s.GetAccount(accountID, userID)
+	acc, err := s.getOwnedAccount(accountID, userID)
 	if err != nil {
 		return nil, err
 	}
+	if req.Version != nil && *req.Version != acc.Version {
+		return nil, account.ErrVersionConflict
+	}
 
 	updates := make(map[string]interface{})
 
+	var newStatus account.AccountStatus
 	if req.Status != nil {
 		// Validate status transition
-		newStatus := account.AccountStatus(*req.Status)
+		newStatus = account.AccountStatus(*req.Status)
 		if err := s.validateStatusTransition(acc.Status, newStatus); err != nil {
 			return nil, err
 		}
+		if acc.Status == account.AccountStatusFrozen && (acc.FreezeReason == nil || *acc.FreezeReason != account.FreezeReasonCustomerRequest) {
+			return nil, fmt.Errorf("account was frozen by the bank, contact support to unfreeze it")
+		}
 		updates["status"] = newStatus
+		if newStatus == account.AccountStatusFrozen {
+			updates["freeze_reason"] = *req.Reason
+		} else {
+			updates["freeze_reason"] = nil
+		}
 	}
 
 	if len(updates) == 0 {
 		return acc, nil
 	}
 
-	if err := s.accountRepo.Update(accountID, updates); err != nil {
+	if err := s.accountRepo.Update(accountID, acc.Version, updates); err != nil {
 		return nil, err
 	}
 
+	switch {
+	case newStatus == account.AccountStatusFrozen:
+		s.recordFreeze(&userID, acc, account.FreezeReason(*req.Reason), nil)
+	case newStatus == account.AccountStatusActive && acc.Status == account.AccountStatusFrozen:
+		metadata := map[string]interface{}{"previous_status": acc.Status}
+		if acc.FreezeReason != nil {
+			metadata["freeze_reason"] = *acc.FreezeReason
+		}
+		s.recordAudit(&userID, "ACCOUNT_UNFROZEN", acc.ID, metadata)
+		s.notifyStatusChange(acc, newStatus, "")
+	}
+
 	return s.GetAccount(accountID, userID)
 }
 
+func (s *accountService) FreezeAccount(accountID uuid.UUID, reason account.FreezeReason, metadata map[string]interface{}) (*account.Account, error) {
+	if !reason.Valid() {
+		return nil, fmt.Errorf("unknown freeze reason %q", reason)
+	}
+
+	acc, err := s.accountRepo.GetByID(accountID)
+	if err != nil {
+		return nil, err
+	}
+	if acc.Status == account.AccountStatusClosed {
+		return nil, fmt.Errorf("account %s is closed", acc.AccountNumber)
+	}
+
+	if err := s.accountRepo.Update(acc.ID, acc.Version, map[string]interface{}{
+		"status":        account.AccountStatusFrozen,
+		"freeze_reason": string(reason),
+	}); err != nil {
+		return nil, err
+	}
+	s.recordFreeze(nil, acc, reason, metadata)
+
+	return s.accountRepo.GetByID(acc.ID)
+}
+
+// recordFreeze audits a freeze of acc by userID, nil for the bank, and emails
+// the holder
+func (s *accountService) recordFreeze(userID *uuid.UUID, acc *account.Account, reason account.FreezeReason, metadata map[string]interface{}) {
+	entry := map[string]interface{}{
+		"reason":          reason,
+		"previous_status": acc.Status,
+	}
+	if acc.FreezeReason != nil {
+		entry["previous_reason"] = *acc.FreezeReason
+	}
+	for k, v := range metadata {
+		entry[k] = v
+	}
+	s.recordAudit(userID, "ACCOUNT_FROZEN", acc.ID, entry)
+	s.notifyStatusChange(acc, account.AccountStatusFrozen, reason)
+}
+
 func (s *accountService) CloseAccount(accountID uuid.UUID, userID uuid.UUID) error {
 	// Verify ownership
-	acc, err := s.GetAccount(accountID, userID)
+	acc, err := s.getOwnedAccount(accountID, userID)
 	if err != nil {
 		return err
 	}
 
-	// Check if balance is zero
+	// Check if balance is zero, in every currency
 	if acc.Balance > 0 {
 		return fmt.Errorf("cannot close account with non-zero balance. Current balance: %.2f %s", acc.Balance, acc.Currency)
 	}
+	others, err := s.accountRepo.ListBalances(acc.ID)
+	if err != nil {
+		return err
+	}
+	for _, b := range others {
+		if b.Balance > 0 {
+			return fmt.Errorf("cannot close account with non-zero balance. Current balance: %.2f %s", b.Balance, b.Currency)
+		}
+	}
 
 	return s.accountRepo.Delete(accountID)
 }
 
+// recordAudit writes a successful account audit entry. Failures are logged and
+// do not fail the operation.
+func (s *accountService) recordAudit(userID *uuid.UUID, action string, accountID uuid.UUID, metadata map[string]interface{}) {
+	if err := s.auditRepo.Create(&audit.AuditLog{
+		EventID:  uuid.New(),
+		UserID:   userID,
+		Action:   action,
+		Resource: fmt.Sprintf("account:%s", accountID),
+		Status:   "success",
+		Metadata: metadata,
+	}); err != nil {
+		logger.Error("Failed to create audit log for account", zap.String("action", action), zap.Error(err))
+		errtrack.CaptureError(err, map[string]string{"component": "account_service", "operation": "audit_log"})
+	}
+}
+
+// freezeReasonText is how a freeze reason reads in the email to the holder
+var freezeReasonText = map[account.FreezeReason]string{
+	account.FreezeReasonFraud:           "suspected fraud",
+	account.FreezeReasonLegalHold:       "a legal hold",
+	account.FreezeReasonCustomerRequest: "your request",
+}
+
+// notifyStatusChange emails the holder that their account was frozen, for
+// reason, or unfrozen. Failures are logged but never fail the change.
+func (s *accountService) notifyStatusChange(acc *account.Account, status account.AccountStatus, reason account.FreezeReason) {
+	holder, err := s.userRepo.GetByID(acc.UserID)
+	if err != nil {
+		logger.Warn("Failed to load account holder for status notification", zap.String("account_id", acc.ID.String()), zap.Error(err))
+		return
+	}
+
+	number := transaction.MaskAccountNumber(acc.AccountNumber)
+	var b strings.Builder
+	fmt.Fprintf(&b, "Hello %s,\n\n", holder.FirstName)
+	email := &notifier.Email{To: holder.Email}
+	if status == account.AccountStatusFrozen {
+		email.Subject = "Your MadaBank account has been frozen"
+		fmt.Fprintf(&b, "Your account %s was frozen on %s because of %s. "+
+			"No money can be sent from or received into it until it is unfrozen.\n\n",
+			number, time.Now().UTC().Format("2 January 2006 15:04 MST"), freezeReasonText[reason])
+	} else {
+		email.Subject = "Your MadaBank account has been unfrozen"
+		fmt.Fprintf(&b, "Your account %s was unfrozen on %s and can be used again.\n\n",
+			number, time.Now().UTC().Format("2 January 2006 15:04 MST"))
+	}
+	b.WriteString("If you did not expect this, contact support immediately.\n")
+	email.Body = b.String()
+
+	if err := s.notifier.SendEmail(context.Background(), email); err != nil {
+		logger.Warn("Failed to send account status notification", zap.String("account_id", acc.ID.String()), zap.Error(err))
+	}
+}
+
 func (s *accountService) validateStatusTransition(current, new account.AccountStatus) error {
 	// Define valid status transitions
 	validTransitions := map[account.AccountStatus][]account.AccountStatus{
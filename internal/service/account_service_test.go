@@ -2,89 +2,158 @@ package service
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/audit"
+	"github.com/darisadam/madabank-server/internal/domain/interest"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/domain/user"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/pkg/notifier"
+	"github.com/darisadam/madabank-server/internal/testutil/fakes"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
-// MockAccountRepository is a mock implementation of repository.AccountRepository
-type MockAccountRepository struct {
-	mock.Mock
+// setupAccountServiceTest returns an account service whose repositories are
+// fakes sharing the returned database. Freezes are notified through the
+// service's MockNotifier.
+func setupAccountServiceTest(t *testing.T) (*accountService, *fakes.DB) {
+	logger.Init("test")
+	db := fakes.NewDB()
+	svc := NewAccountService(
+		fakes.NewAccountRepository(db),
+		fakes.NewUserRepository(db),
+		fakes.NewAuditRepository(db),
+		fakes.NewInterestRepository(db),
+		new(MockNotifier),
+	).(*accountService)
+	return svc, db
+}
+
+// seedAccountHolder stores John, who holds the accounts of the tests
+func seedAccountHolder(t *testing.T, db *fakes.DB) uuid.UUID {
+	t.Helper()
+	u := &user.User{ID: uuid.New(), Email: "john@example.com", FirstName: "John", IsActive: true}
+	require.NoError(t, fakes.NewUserRepository(db).Create(u))
+	return u.ID
+}
+
+// seedAccount stores an active IDR checking account of the user, with the
+// columns in updates then set, and returns it as stored
+func seedAccount(t *testing.T, db *fakes.DB, userID uuid.UUID, updates map[string]interface{}) *account.Account {
+	t.Helper()
+	accounts := fakes.NewAccountRepository(db)
+	number, err := accounts.GenerateAccountNumber()
+	require.NoError(t, err)
+	acc := &account.Account{
+		ID:            uuid.New(),
+		UserID:        userID,
+		AccountNumber: number,
+		AccountType:   account.AccountTypeChecking,
+		Currency:      "IDR",
+		Status:        account.AccountStatusActive,
+	}
+	require.NoError(t, accounts.Create(acc))
+	if len(updates) > 0 {
+		require.NoError(t, accounts.Update(acc.ID, 1, updates))
+	}
+	return storedAccount(t, db, acc.ID)
 }
 
-func (m *MockAccountRepository) Create(a *account.Account) error {
-	args := m.Called(a)
-	return args.Error(0)
+func storedAccount(t *testing.T, db *fakes.DB, id uuid.UUID) *account.Account {
+	t.Helper()
+	acc, err := fakes.NewAccountRepository(db).GetByID(id)
+	require.NoError(t, err)
+	return acc
 }
 
-func (m *MockAccountRepository) GetByID(id uuid.UUID) (*account.Account, error) {
-	args := m.Called(id)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*account.Account), args.Error(1)
+// depositCurrency credits amount to the account's balance in currency
+func depositCurrency(t *testing.T, db *fakes.DB, accountID uuid.UUID, currency string, amount float64) {
+	t.Helper()
+	require.NoError(t, fakes.NewTransactionRepository(db).ExecuteDeposit(accountID, amount, &transaction.Transaction{
+		ID:              uuid.New(),
+		IdempotencyKey:  uuid.NewString(),
+		TransactionType: transaction.TransactionTypeDeposit,
+		Metadata:        map[string]interface{}{"currency": currency},
+	}))
 }
 
-func (m *MockAccountRepository) GetByAccountNumber(accountNumber string) (*account.Account, error) {
-	args := m.Called(accountNumber)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*account.Account), args.Error(1)
+// lastAudit returns the latest audit entry
+func lastAudit(t *testing.T, db *fakes.DB) *audit.AuditLog {
+	t.Helper()
+	logs, err := fakes.NewAuditRepository(db).ListChain(0, 100)
+	require.NoError(t, err)
+	require.NotEmpty(t, logs)
+	return logs[len(logs)-1]
+}
+
+// failingAccounts is the fake account repository with the methods named in
+// fail returning their error instead
+type failingAccounts struct {
+	*fakes.AccountRepository
+	fail map[string]error
 }
 
-func (m *MockAccountRepository) GetByUserID(userID uuid.UUID) ([]*account.Account, error) {
-	args := m.Called(userID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
+func (r *failingAccounts) GenerateAccountNumber() (string, error) {
+	if err := r.fail["GenerateAccountNumber"]; err != nil {
+		return "", err
 	}
-	return args.Get(0).([]*account.Account), args.Error(1)
+	return r.AccountRepository.GenerateAccountNumber()
 }
 
-func (m *MockAccountRepository) Update(id uuid.UUID, updates map[string]interface{}) error {
-	args := m.Called(id, updates)
-	return args.Error(0)
+func (r *failingAccounts) Create(acc *account.Account) error {
+	if err := r.fail["Create"]; err != nil {
+		return err
+	}
+	return r.AccountRepository.Create(acc)
 }
 
-func (m *MockAccountRepository) Delete(id uuid.UUID) error {
-	args := m.Called(id)
-	return args.Error(0)
+func (r *failingAccounts) Update(id uuid.UUID, version int, updates map[string]interface{}) error {
+	if err := r.fail["Update"]; err != nil {
+		return err
+	}
+	return r.AccountRepository.Update(id, version, updates)
 }
 
-func (m *MockAccountRepository) GenerateAccountNumber() (string, error) {
-	args := m.Called()
-	return args.String(0), args.Error(1)
+func (r *failingAccounts) ListByUser(f *account.ListFilter) ([]*account.Account, error) {
+	if err := r.fail["ListByUser"]; err != nil {
+		return nil, err
+	}
+	return r.AccountRepository.ListByUser(f)
 }
 
-func (m *MockAccountRepository) UpdateBalance(id uuid.UUID, amount float64) error {
-	args := m.Called(id, amount)
-	return args.Error(0)
+// racingAccounts is the fake account repository with another device changing
+// the account just before every update
+type racingAccounts struct {
+	*fakes.AccountRepository
 }
 
-func setupAccountServiceTest(t *testing.T) (*accountService, *MockAccountRepository) {
-	mockRepo := new(MockAccountRepository)
-	svc := NewAccountService(mockRepo).(*accountService)
-	return svc, mockRepo
+func (r *racingAccounts) Update(id uuid.UUID, version int, updates map[string]interface{}) error {
+	current, err := r.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if err := r.AccountRepository.Update(id, current.Version, map[string]interface{}{"overdraft_limit": 100000.0}); err != nil {
+		return err
+	}
+	return r.AccountRepository.Update(id, version, updates)
 }
 
 func TestCreateAccount_Checking_Success(t *testing.T) {
-	svc, mockRepo := setupAccountServiceTest(t)
-	userID := uuid.New()
+	svc, db := setupAccountServiceTest(t)
+	userID := seedAccountHolder(t, db)
 
 	req := &account.CreateAccountRequest{
 		AccountType: "checking",
 		Currency:    "USD",
 	}
 
-	// Mock: user has no existing accounts (allows creation)
-	mockRepo.On("GetByUserID", userID).Return([]*account.Account{}, nil)
-	mockRepo.On("GenerateAccountNumber").Return("1234567890", nil)
-	mockRepo.On("Create", mock.AnythingOfType("*account.Account")).Return(nil)
-
 	acc, err := svc.CreateAccount(userID, req)
 	assert.NoError(t, err)
 	assert.NotNil(t, acc)
@@ -92,12 +161,31 @@ func TestCreateAccount_Checking_Success(t *testing.T) {
 	assert.Equal(t, "USD", acc.Currency)
 	assert.Equal(t, account.AccountStatusActive, acc.Status)
 	assert.Equal(t, float64(0), acc.Balance)
-	mockRepo.AssertExpectations(t)
+
+	stored := storedAccount(t, db, acc.ID)
+	assert.Equal(t, userID, stored.UserID)
+	assert.Equal(t, acc.AccountNumber, stored.AccountNumber)
+	assert.NotEmpty(t, stored.AccountNumber)
+}
+
+func TestCreateAccount_UnsupportedCurrency(t *testing.T) {
+	svc, db := setupAccountServiceTest(t)
+	userID := seedAccountHolder(t, db)
+
+	acc, err := svc.CreateAccount(userID, &account.CreateAccountRequest{
+		AccountType: "checking",
+		Currency:    "XYZ",
+	})
+	assert.Nil(t, acc)
+	assert.EqualError(t, err, "currency must be a supported currency code such as IDR")
+	accounts, err := svc.accountRepo.GetByUserID(userID)
+	assert.NoError(t, err)
+	assert.Empty(t, accounts)
 }
 
 func TestCreateAccount_Savings_WithDefaultInterest(t *testing.T) {
-	svc, mockRepo := setupAccountServiceTest(t)
-	userID := uuid.New()
+	svc, db := setupAccountServiceTest(t)
+	userID := seedAccountHolder(t, db)
 
 	req := &account.CreateAccountRequest{
 		AccountType:  "savings",
@@ -105,30 +193,22 @@ func TestCreateAccount_Savings_WithDefaultInterest(t *testing.T) {
 		InterestRate: 0, // Should default to 3.25%
 	}
 
-	// Mock: user has no existing accounts (allows creation)
-	mockRepo.On("GetByUserID", userID).Return([]*account.Account{}, nil)
-	mockRepo.On("GenerateAccountNumber").Return("9876543210", nil)
-	mockRepo.On("Create", mock.AnythingOfType("*account.Account")).Return(nil)
-
 	acc, err := svc.CreateAccount(userID, req)
 	assert.NoError(t, err)
 	assert.Equal(t, account.AccountTypeSavings, acc.AccountType)
 	assert.Equal(t, 0.0325, acc.InterestRate) // Default rate
-	mockRepo.AssertExpectations(t)
+	assert.Equal(t, 0.0325, storedAccount(t, db, acc.ID).InterestRate)
 }
 
 func TestCreateAccount_InvalidType(t *testing.T) {
-	svc, mockRepo := setupAccountServiceTest(t)
-	userID := uuid.New()
+	svc, db := setupAccountServiceTest(t)
+	userID := seedAccountHolder(t, db)
 
 	req := &account.CreateAccountRequest{
 		AccountType: "invalid",
 		Currency:    "USD",
 	}
 
-	// Mock: user has no existing accounts
-	mockRepo.On("GetByUserID", userID).Return([]*account.Account{}, nil)
-
 	acc, err := svc.CreateAccount(userID, req)
 	assert.Error(t, err)
 	assert.Nil(t, acc)
@@ -136,21 +216,18 @@ func TestCreateAccount_InvalidType(t *testing.T) {
 }
 
 func TestCreateAccount_MaxAccountsReached(t *testing.T) {
-	svc, mockRepo := setupAccountServiceTest(t)
-	userID := uuid.New()
+	svc, db := setupAccountServiceTest(t)
+	userID := seedAccountHolder(t, db)
 
 	req := &account.CreateAccountRequest{
 		AccountType: "checking",
 		Currency:    "IDR",
 	}
 
-	// Mock: user already has 3 accounts (max reached)
-	existingAccounts := []*account.Account{
-		{ID: uuid.New(), UserID: userID},
-		{ID: uuid.New(), UserID: userID},
-		{ID: uuid.New(), UserID: userID},
+	// The user already has 3 accounts (max reached)
+	for i := 0; i < 3; i++ {
+		seedAccount(t, db, userID, nil)
 	}
-	mockRepo.On("GetByUserID", userID).Return(existingAccounts, nil)
 
 	acc, err := svc.CreateAccount(userID, req)
 	assert.Error(t, err)
@@ -159,182 +236,326 @@ func TestCreateAccount_MaxAccountsReached(t *testing.T) {
 }
 
 func TestGetAccount_Success(t *testing.T) {
-	svc, mockRepo := setupAccountServiceTest(t)
-	userID := uuid.New()
-	accountID := uuid.New()
+	svc, db := setupAccountServiceTest(t)
+	userID := seedAccountHolder(t, db)
+	existing := seedAccount(t, db, userID, nil)
 
-	expectedAccount := &account.Account{
-		ID:     accountID,
-		UserID: userID,
-	}
+	acc, err := svc.GetAccount(existing.ID, userID)
+	assert.NoError(t, err)
+	assert.Equal(t, existing.ID, acc.ID)
+}
 
-	mockRepo.On("GetByID", accountID).Return(expectedAccount, nil)
+func TestGetAccount_SavingsEffectiveRate(t *testing.T) {
+	svc, db := setupAccountServiceTest(t)
+	userID := seedAccountHolder(t, db)
+	existing := seedAccount(t, db, userID, map[string]interface{}{
+		"account_type":  account.AccountTypeSavings,
+		"balance":       20000000.0,
+		"interest_rate": 0.0325,
+	})
+	interestRepo := fakes.NewInterestRepository(db)
+	interestRepo.AddRateTier(interest.Tier{AccountType: "savings", Currency: "IDR", MinBalance: 10000000, AnnualRate: 0.04})
+	interestRepo.AddRateTier(interest.Tier{AccountType: "savings", Currency: "USD", MinBalance: 10000, AnnualRate: 0.02})
+
+	acc, err := svc.GetAccount(existing.ID, userID)
 
-	acc, err := svc.GetAccount(accountID, userID)
 	assert.NoError(t, err)
-	assert.Equal(t, accountID, acc.ID)
-	mockRepo.AssertExpectations(t)
+	assert.Equal(t, []interest.Tier{{AccountType: "savings", Currency: "IDR", MinBalance: 10000000, AnnualRate: 0.04}}, acc.InterestTiers)
+	assert.Equal(t, 0.03625, acc.EffectiveInterestRate)
 }
 
 func TestGetAccount_UnauthorizedAccess(t *testing.T) {
-	svc, mockRepo := setupAccountServiceTest(t)
-	ownerID := uuid.New()
+	svc, db := setupAccountServiceTest(t)
+	ownerID := seedAccountHolder(t, db)
 	requestorID := uuid.New() // Different user
-	accountID := uuid.New()
+	existing := seedAccount(t, db, ownerID, nil)
 
-	existingAccount := &account.Account{
-		ID:     accountID,
-		UserID: ownerID, // Belongs to different user
-	}
-
-	mockRepo.On("GetByID", accountID).Return(existingAccount, nil)
-
-	acc, err := svc.GetAccount(accountID, requestorID)
+	acc, err := svc.GetAccount(existing.ID, requestorID)
 	assert.Error(t, err)
 	assert.Nil(t, acc)
 	assert.Contains(t, err.Error(), "unauthorized access")
-	mockRepo.AssertExpectations(t)
 }
 
 func TestGetAccount_NotFound(t *testing.T) {
-	svc, mockRepo := setupAccountServiceTest(t)
-	accountID := uuid.New()
-	userID := uuid.New()
-
-	mockRepo.On("GetByID", accountID).Return(nil, fmt.Errorf("account not found"))
+	svc, _ := setupAccountServiceTest(t)
 
-	acc, err := svc.GetAccount(accountID, userID)
+	acc, err := svc.GetAccount(uuid.New(), uuid.New())
 	assert.Error(t, err)
 	assert.Nil(t, acc)
-	mockRepo.AssertExpectations(t)
 }
 
 func TestGetUserAccounts_Success(t *testing.T) {
-	svc, mockRepo := setupAccountServiceTest(t)
-	userID := uuid.New()
-
-	accounts := []*account.Account{
-		{ID: uuid.New(), UserID: userID},
-		{ID: uuid.New(), UserID: userID},
+	svc, db := setupAccountServiceTest(t)
+	userID := seedAccountHolder(t, db)
+	for i := 0; i < 5; i++ {
+		seedAccount(t, db, userID, nil)
 	}
+	// Other users' accounts are not counted
+	seedAccount(t, db, uuid.New(), nil)
 
-	mockRepo.On("GetByUserID", userID).Return(accounts, nil)
+	filter := (&account.ListAccountsRequest{Limit: 2}).Filter(userID)
 
-	result, err := svc.GetUserAccounts(userID)
+	result, total, err := svc.GetUserAccounts(filter)
 	assert.NoError(t, err)
 	assert.Len(t, result, 2)
-	mockRepo.AssertExpectations(t)
+	assert.Equal(t, 5, total)
 }
 
 func TestGetBalance_Success(t *testing.T) {
-	svc, mockRepo := setupAccountServiceTest(t)
-	userID := uuid.New()
-	accountID := uuid.New()
+	svc, db := setupAccountServiceTest(t)
+	userID := seedAccountHolder(t, db)
+	existing := seedAccount(t, db, userID, map[string]interface{}{"currency": "USD", "balance": 1000.50})
+	require.NoError(t, svc.accountRepo.AddCurrency(existing.ID, "JPY"))
+	depositCurrency(t, db, existing.ID, "JPY", 15000)
 
-	expectedAccount := &account.Account{
-		ID:            accountID,
-		UserID:        userID,
-		AccountNumber: "1234567890",
-		Balance:       1000.50,
-		Currency:      "USD",
-		UpdatedAt:     time.Now(),
-	}
-
-	mockRepo.On("GetByID", accountID).Return(expectedAccount, nil)
-
-	balance, err := svc.GetBalance(accountID, userID)
+	balance, err := svc.GetBalance(existing.ID, userID)
 	assert.NoError(t, err)
 	assert.Equal(t, 1000.50, balance.Balance)
+	assert.Equal(t, "USD 1,000.50", balance.FormattedBalance)
+	assert.Equal(t, 1000.50, balance.LedgerBalance)
+	assert.Equal(t, 1000.50, balance.AvailableBalance)
 	assert.Equal(t, "USD", balance.Currency)
-	mockRepo.AssertExpectations(t)
+	assert.Equal(t, existing.AccountNumber, balance.AccountNumber)
+	assert.Equal(t, []account.CurrencyBalance{
+		{Currency: "USD", Balance: 1000.50, FormattedBalance: "USD 1,000.50"},
+		{Currency: "JPY", Balance: 15000, FormattedBalance: "JPY 15,000"},
+	}, balance.Balances)
+}
+
+func TestGetBalance_HoldsAndOverdraft(t *testing.T) {
+	svc, db := setupAccountServiceTest(t)
+	userID := seedAccountHolder(t, db)
+	existing := seedAccount(t, db, userID, map[string]interface{}{"balance": 1000000.0, "overdraft_limit": 500000.0})
+	require.NoError(t, fakes.NewHoldRepository(db).Place(&account.Hold{
+		ID:        uuid.New(),
+		AccountID: existing.ID,
+		Amount:    250000,
+		Purpose:   account.HoldPurposeCardAuthorization,
+		Status:    account.HoldStatusActive,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}))
+
+	balance, err := svc.GetBalance(existing.ID, userID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1000000.0, balance.LedgerBalance)
+	assert.Equal(t, 250000.0, balance.HeldAmount)
+	assert.Equal(t, 500000.0, balance.OverdraftLimit)
+	assert.Equal(t, 1250000.0, balance.AvailableBalance)
+	assert.Equal(t, "IDR 1,250,000.00", balance.FormattedAvailableBalance)
 }
 
 func TestCloseAccount_Success(t *testing.T) {
-	svc, mockRepo := setupAccountServiceTest(t)
-	userID := uuid.New()
-	accountID := uuid.New()
+	svc, db := setupAccountServiceTest(t)
+	userID := seedAccountHolder(t, db)
+	existing := seedAccount(t, db, userID, nil)
+	require.NoError(t, svc.accountRepo.AddCurrency(existing.ID, "USD"))
 
-	existingAccount := &account.Account{
-		ID:      accountID,
-		UserID:  userID,
-		Balance: 0, // Zero balance
-	}
+	err := svc.CloseAccount(existing.ID, userID)
+	assert.NoError(t, err)
+	_, err = svc.accountRepo.GetByID(existing.ID)
+	assert.EqualError(t, err, "account not found")
+}
 
-	mockRepo.On("GetByID", accountID).Return(existingAccount, nil)
-	mockRepo.On("Delete", accountID).Return(nil)
+func TestCloseAccount_NonZeroOtherCurrency(t *testing.T) {
+	svc, db := setupAccountServiceTest(t)
+	userID := seedAccountHolder(t, db)
+	existing := seedAccount(t, db, userID, nil)
+	require.NoError(t, svc.accountRepo.AddCurrency(existing.ID, "USD"))
+	depositCurrency(t, db, existing.ID, "USD", 25)
 
-	err := svc.CloseAccount(accountID, userID)
-	assert.NoError(t, err)
-	mockRepo.AssertExpectations(t)
+	err := svc.CloseAccount(existing.ID, userID)
+	assert.EqualError(t, err, "cannot close account with non-zero balance. Current balance: 25.00 USD")
+	assert.Equal(t, account.AccountStatusActive, storedAccount(t, db, existing.ID).Status)
 }
 
-func TestCloseAccount_NonZeroBalance(t *testing.T) {
-	svc, mockRepo := setupAccountServiceTest(t)
-	userID := uuid.New()
-	accountID := uuid.New()
+func TestAddCurrency_Success(t *testing.T) {
+	svc, db := setupAccountServiceTest(t)
+	userID := seedAccountHolder(t, db)
+	existing := seedAccount(t, db, userID, nil)
+
+	balance, err := svc.AddCurrency(existing.ID, userID, &account.AddCurrencyRequest{Currency: "USD"})
+	assert.NoError(t, err)
+	assert.Equal(t, &account.CurrencyBalance{Currency: "USD", Balance: 0, FormattedBalance: "USD 0.00"}, balance)
+	balances, err := svc.accountRepo.ListBalances(existing.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []*account.CurrencyBalance{balance}, balances)
+}
 
-	existingAccount := &account.Account{
-		ID:       accountID,
-		UserID:   userID,
-		Balance:  100.00, // Non-zero
-		Currency: "USD",
+func TestAddCurrency_Rejected(t *testing.T) {
+	tests := []struct {
+		name      string
+		updates   map[string]interface{}
+		otherUser bool
+		code      string
+		wantErr   string
+	}{
+		{"own currency", nil, false, "IDR", "account already holds IDR"},
+		{"unsupported", nil, false, "XYZ", "currency must be a supported currency code such as IDR"},
+		{"frozen", map[string]interface{}{"status": account.AccountStatusFrozen}, false, "USD", "account is frozen, cannot add currencies"},
+		{"other user", nil, true, "USD", "unauthorized access to account"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, db := setupAccountServiceTest(t)
+			userID := seedAccountHolder(t, db)
+			ownerID := userID
+			if tt.otherUser {
+				ownerID = uuid.New()
+			}
+			existing := seedAccount(t, db, ownerID, tt.updates)
+
+			balance, err := svc.AddCurrency(existing.ID, userID, &account.AddCurrencyRequest{Currency: tt.code})
+			assert.Nil(t, balance)
+			assert.EqualError(t, err, tt.wantErr)
+			balances, err := svc.accountRepo.ListBalances(existing.ID)
+			assert.NoError(t, err)
+			assert.Empty(t, balances)
+		})
 	}
+}
 
-	mockRepo.On("GetByID", accountID).Return(existingAccount, nil)
+func TestCloseAccount_NonZeroBalance(t *testing.T) {
+	svc, db := setupAccountServiceTest(t)
+	userID := seedAccountHolder(t, db)
+	existing := seedAccount(t, db, userID, map[string]interface{}{"balance": 100.00, "currency": "USD"})
 
-	err := svc.CloseAccount(accountID, userID)
+	err := svc.CloseAccount(existing.ID, userID)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "cannot close account with non-zero balance")
-	mockRepo.AssertNotCalled(t, "Delete", mock.Anything)
+	assert.Equal(t, account.AccountStatusActive, storedAccount(t, db, existing.ID).Status)
 }
 
-func TestUpdateAccount_StatusTransition_Success(t *testing.T) {
-	svc, mockRepo := setupAccountServiceTest(t)
-	userID := uuid.New()
-	accountID := uuid.New()
-
-	existingAccount := &account.Account{
-		ID:     accountID,
-		UserID: userID,
-		Status: account.AccountStatusActive,
-	}
+func TestUpdateAccount_Freeze(t *testing.T) {
+	svc, db := setupAccountServiceTest(t)
+	userID := seedAccountHolder(t, db)
+	existing := seedAccount(t, db, userID, nil)
+	mockNotifier := svc.notifier.(*MockNotifier)
 
 	newStatus := "frozen"
-	req := &account.UpdateAccountRequest{Status: &newStatus}
+	reason := "customer_request"
+	req := &account.UpdateAccountRequest{Status: &newStatus, Reason: &reason}
 
-	mockRepo.On("GetByID", accountID).Return(existingAccount, nil).Once()
-	mockRepo.On("Update", accountID, mock.AnythingOfType("map[string]interface {}")).Return(nil)
-	// After update, GetAccount is called again
-	updatedAccount := &account.Account{
-		ID:     accountID,
-		UserID: userID,
-		Status: account.AccountStatusFrozen,
-	}
-	mockRepo.On("GetByID", accountID).Return(updatedAccount, nil).Once()
+	mockNotifier.On("SendEmail", mock.MatchedBy(func(e *notifier.Email) bool {
+		return e.To == "john@example.com" &&
+			strings.Contains(e.Subject, "frozen") &&
+			strings.Contains(e.Body, transaction.MaskAccountNumber(existing.AccountNumber)) &&
+			strings.Contains(e.Body, "your request")
+	})).Return(nil)
 
-	acc, err := svc.UpdateAccount(accountID, userID, req)
+	acc, err := svc.UpdateAccount(existing.ID, userID, req)
 	assert.NoError(t, err)
 	assert.Equal(t, account.AccountStatusFrozen, acc.Status)
-	mockRepo.AssertExpectations(t)
+	assert.Equal(t, account.FreezeReasonCustomerRequest, *acc.FreezeReason)
+	assert.Equal(t, existing.Version+1, acc.Version)
+
+	entry := lastAudit(t, db)
+	assert.Equal(t, "ACCOUNT_FROZEN", entry.Action)
+	assert.Equal(t, &userID, entry.UserID)
+	assert.Equal(t, "customer_request", entry.Metadata["reason"])
+	mockNotifier.AssertExpectations(t)
 }
 
-func TestUpdateAccount_InvalidStatusTransition(t *testing.T) {
-	svc, mockRepo := setupAccountServiceTest(t)
-	userID := uuid.New()
-	accountID := uuid.New()
+func TestUpdateAccount_FreezeRequiresReason(t *testing.T) {
+	svc, _ := setupAccountServiceTest(t)
 
-	existingAccount := &account.Account{
-		ID:     accountID,
-		UserID: userID,
-		Status: account.AccountStatusClosed, // Cannot transition from closed
-	}
+	newStatus := "frozen"
+	_, err := svc.UpdateAccount(uuid.New(), uuid.New(), &account.UpdateAccountRequest{Status: &newStatus})
+
+	assert.EqualError(t, err, "reason is required when freezing an account")
+}
+
+func TestUpdateAccount_Unfreeze(t *testing.T) {
+	svc, db := setupAccountServiceTest(t)
+	userID := seedAccountHolder(t, db)
+	existing := seedAccount(t, db, userID, map[string]interface{}{
+		"status":        account.AccountStatusFrozen,
+		"freeze_reason": "customer_request",
+	})
+	mockNotifier := svc.notifier.(*MockNotifier)
 
 	newStatus := "active"
 	req := &account.UpdateAccountRequest{Status: &newStatus}
 
-	mockRepo.On("GetByID", accountID).Return(existingAccount, nil)
+	mockNotifier.On("SendEmail", mock.MatchedBy(func(e *notifier.Email) bool {
+		return strings.Contains(e.Subject, "unfrozen")
+	})).Return(nil)
+
+	acc, err := svc.UpdateAccount(existing.ID, userID, req)
+	assert.NoError(t, err)
+	assert.Equal(t, account.AccountStatusActive, acc.Status)
+	assert.Nil(t, acc.FreezeReason)
+
+	entry := lastAudit(t, db)
+	assert.Equal(t, "ACCOUNT_UNFROZEN", entry.Action)
+	assert.Equal(t, "customer_request", entry.Metadata["freeze_reason"])
+	mockNotifier.AssertExpectations(t)
+}
+
+func TestUpdateAccount_BankFreezeCannotBeLifted(t *testing.T) {
+	svc, db := setupAccountServiceTest(t)
+	userID := seedAccountHolder(t, db)
+	existing := seedAccount(t, db, userID, map[string]interface{}{
+		"status":        account.AccountStatusFrozen,
+		"freeze_reason": "fraud",
+	})
+
+	for _, status := range []string{"active", "closed"} {
+		acc, err := svc.UpdateAccount(existing.ID, userID, &account.UpdateAccountRequest{Status: &status})
+		assert.EqualError(t, err, "account was frozen by the bank, contact support to unfreeze it")
+		assert.Nil(t, acc)
+	}
+	assert.Equal(t, existing, storedAccount(t, db, existing.ID))
+	logs, err := fakes.NewAuditRepository(db).ListChain(0, 100)
+	assert.NoError(t, err)
+	assert.Empty(t, logs)
+}
+
+func TestFreezeAccount(t *testing.T) {
+	svc, db := setupAccountServiceTest(t)
+	userID := seedAccountHolder(t, db)
+	// The holder froze it first; the bank escalates to fraud
+	existing := seedAccount(t, db, userID, map[string]interface{}{
+		"status":        account.AccountStatusFrozen,
+		"freeze_reason": "customer_request",
+	})
+	mockNotifier := svc.notifier.(*MockNotifier)
+	mockNotifier.On("SendEmail", mock.MatchedBy(func(e *notifier.Email) bool {
+		return strings.Contains(e.Body, "suspected fraud")
+	})).Return(nil)
+
+	acc, err := svc.FreezeAccount(existing.ID, account.FreezeReasonFraud, map[string]interface{}{"source": "admin-cli"})
+	require.NoError(t, err)
+	assert.Equal(t, account.FreezeReasonFraud, *acc.FreezeReason)
+	assert.Equal(t, account.FreezeReasonFraud, *storedAccount(t, db, existing.ID).FreezeReason)
+
+	entry := lastAudit(t, db)
+	assert.Equal(t, "ACCOUNT_FROZEN", entry.Action)
+	assert.Nil(t, entry.UserID)
+	assert.Equal(t, "fraud", entry.Metadata["reason"])
+	assert.Equal(t, "customer_request", entry.Metadata["previous_reason"])
+	assert.Equal(t, "admin-cli", entry.Metadata["source"])
+	mockNotifier.AssertExpectations(t)
+}
+
+func TestFreezeAccount_UnknownReason(t *testing.T) {
+	svc, db := setupAccountServiceTest(t)
+	existing := seedAccount(t, db, seedAccountHolder(t, db), nil)
 
-	acc, err := svc.UpdateAccount(accountID, userID, req)
+	_, err := svc.FreezeAccount(existing.ID, "fraud investigation", nil)
+
+	assert.EqualError(t, err, `unknown freeze reason "fraud investigation"`)
+	assert.Equal(t, account.AccountStatusActive, storedAccount(t, db, existing.ID).Status)
+}
+
+func TestUpdateAccount_InvalidStatusTransition(t *testing.T) {
+	svc, db := setupAccountServiceTest(t)
+	userID := seedAccountHolder(t, db)
+	existing := seedAccount(t, db, userID, nil)
+
+	newStatus := "active"
+	req := &account.UpdateAccountRequest{Status: &newStatus}
+
+	acc, err := svc.UpdateAccount(existing.ID, userID, req)
 	assert.Error(t, err)
 	assert.Nil(t, acc)
 	assert.Contains(t, err.Error(), "cannot transition from")
@@ -372,74 +593,44 @@ func TestValidateStatusTransition_AllCases(t *testing.T) {
 // ==================== GetAccountByNumber Tests ====================
 
 func TestGetAccountByNumber_Success(t *testing.T) {
-	svc, mockRepo := setupAccountServiceTest(t)
-	userID := uuid.New()
-	accountNumber := "1234567890"
+	svc, db := setupAccountServiceTest(t)
+	userID := seedAccountHolder(t, db)
+	existing := seedAccount(t, db, userID, nil)
 
-	expectedAccount := &account.Account{
-		ID:            uuid.New(),
-		UserID:        userID,
-		AccountNumber: accountNumber,
-	}
-
-	mockRepo.On("GetByAccountNumber", accountNumber).Return(expectedAccount, nil)
-
-	acc, err := svc.GetAccountByNumber(accountNumber, userID)
+	acc, err := svc.GetAccountByNumber(existing.AccountNumber, userID)
 	assert.NoError(t, err)
-	assert.Equal(t, accountNumber, acc.AccountNumber)
-	mockRepo.AssertExpectations(t)
+	assert.Equal(t, existing.ID, acc.ID)
 }
 
 func TestGetAccountByNumber_NotFound(t *testing.T) {
-	svc, mockRepo := setupAccountServiceTest(t)
-	userID := uuid.New()
-	accountNumber := "nonexistent"
-
-	mockRepo.On("GetByAccountNumber", accountNumber).Return(nil, fmt.Errorf("account not found"))
+	svc, db := setupAccountServiceTest(t)
+	userID := seedAccountHolder(t, db)
 
-	acc, err := svc.GetAccountByNumber(accountNumber, userID)
+	acc, err := svc.GetAccountByNumber("nonexistent", userID)
 	assert.Error(t, err)
 	assert.Nil(t, acc)
-	mockRepo.AssertExpectations(t)
 }
 
 func TestGetAccountByNumber_Unauthorized(t *testing.T) {
-	svc, mockRepo := setupAccountServiceTest(t)
-	ownerID := uuid.New()
+	svc, db := setupAccountServiceTest(t)
+	ownerID := seedAccountHolder(t, db)
 	requestorID := uuid.New() // Different user
-	accountNumber := "1234567890"
-
-	existingAccount := &account.Account{
-		ID:            uuid.New(),
-		UserID:        ownerID, // Belongs to different user
-		AccountNumber: accountNumber,
-	}
-
-	mockRepo.On("GetByAccountNumber", accountNumber).Return(existingAccount, nil)
+	existing := seedAccount(t, db, ownerID, nil)
 
-	acc, err := svc.GetAccountByNumber(accountNumber, requestorID)
+	acc, err := svc.GetAccountByNumber(existing.AccountNumber, requestorID)
 	assert.Error(t, err)
 	assert.Nil(t, acc)
 	assert.Contains(t, err.Error(), "unauthorized access")
-	mockRepo.AssertExpectations(t)
 }
 
 // ==================== GetBalance Additional Tests ====================
 
 func TestGetBalance_Unauthorized(t *testing.T) {
-	svc, mockRepo := setupAccountServiceTest(t)
-	ownerID := uuid.New()
-	requestorID := uuid.New()
-	accountID := uuid.New()
-
-	existingAccount := &account.Account{
-		ID:     accountID,
-		UserID: ownerID,
-	}
+	svc, db := setupAccountServiceTest(t)
+	ownerID := seedAccountHolder(t, db)
+	existing := seedAccount(t, db, ownerID, nil)
 
-	mockRepo.On("GetByID", accountID).Return(existingAccount, nil)
-
-	balance, err := svc.GetBalance(accountID, requestorID)
+	balance, err := svc.GetBalance(existing.ID, uuid.New())
 	assert.Error(t, err)
 	assert.Nil(t, balance)
 	assert.Contains(t, err.Error(), "unauthorized access")
@@ -448,157 +639,160 @@ func TestGetBalance_Unauthorized(t *testing.T) {
 // ==================== UpdateAccount Additional Tests ====================
 
 func TestUpdateAccount_UpdateStatusToFrozen(t *testing.T) {
-	svc, mockRepo := setupAccountServiceTest(t)
+	svc, db := setupAccountServiceTest(t)
+	// The holder's user record cannot be loaded
 	userID := uuid.New()
-	accountID := uuid.New()
-
-	existingAccount := &account.Account{
-		ID:     accountID,
-		UserID: userID,
-		Status: account.AccountStatusActive,
-	}
+	existing := seedAccount(t, db, userID, nil)
 
 	status := "frozen"
-	req := &account.UpdateAccountRequest{Status: &status}
-
-	mockRepo.On("GetByID", accountID).Return(existingAccount, nil).Once()
-	mockRepo.On("Update", accountID, mock.AnythingOfType("map[string]interface {}")).Return(nil)
-	// After update, GetAccount is called again
-	updatedAccount := &account.Account{
-		ID:     accountID,
-		UserID: userID,
-		Status: account.AccountStatusFrozen,
-	}
-	mockRepo.On("GetByID", accountID).Return(updatedAccount, nil).Once()
+	reason := "customer_request"
+	req := &account.UpdateAccountRequest{Status: &status, Reason: &reason}
 
-	acc, err := svc.UpdateAccount(accountID, userID, req)
+	acc, err := svc.UpdateAccount(existing.ID, userID, req)
 	assert.NoError(t, err)
 	assert.Equal(t, account.AccountStatusFrozen, acc.Status)
-	mockRepo.AssertExpectations(t)
+	// The freeze stands even though the holder could not be notified
+	assert.Equal(t, account.AccountStatusFrozen, storedAccount(t, db, existing.ID).Status)
+	svc.notifier.(*MockNotifier).AssertNotCalled(t, "SendEmail", mock.Anything)
 }
 
 func TestUpdateAccount_Unauthorized(t *testing.T) {
-	svc, mockRepo := setupAccountServiceTest(t)
-	ownerID := uuid.New()
-	requestorID := uuid.New()
-	accountID := uuid.New()
-
-	existingAccount := &account.Account{
-		ID:     accountID,
-		UserID: ownerID,
-	}
+	svc, db := setupAccountServiceTest(t)
+	ownerID := seedAccountHolder(t, db)
+	existing := seedAccount(t, db, ownerID, nil)
 
 	status := "frozen"
-	req := &account.UpdateAccountRequest{Status: &status}
-
-	mockRepo.On("GetByID", accountID).Return(existingAccount, nil)
+	reason := "customer_request"
+	req := &account.UpdateAccountRequest{Status: &status, Reason: &reason}
 
-	acc, err := svc.UpdateAccount(accountID, requestorID, req)
+	acc, err := svc.UpdateAccount(existing.ID, uuid.New(), req)
 	assert.Error(t, err)
 	assert.Nil(t, acc)
 	assert.Contains(t, err.Error(), "unauthorized access")
+	assert.Equal(t, account.AccountStatusActive, storedAccount(t, db, existing.ID).Status)
 }
 
 func TestUpdateAccount_UpdateFails(t *testing.T) {
-	svc, mockRepo := setupAccountServiceTest(t)
-	userID := uuid.New()
-	accountID := uuid.New()
-
-	existingAccount := &account.Account{
-		ID:     accountID,
-		UserID: userID,
-		Status: account.AccountStatusActive,
+	svc, db := setupAccountServiceTest(t)
+	userID := seedAccountHolder(t, db)
+	existing := seedAccount(t, db, userID, nil)
+	svc.accountRepo = &failingAccounts{
+		AccountRepository: fakes.NewAccountRepository(db),
+		fail:              map[string]error{"Update": fmt.Errorf("database error")},
 	}
 
 	status := "frozen"
-	req := &account.UpdateAccountRequest{Status: &status}
+	reason := "customer_request"
+	req := &account.UpdateAccountRequest{Status: &status, Reason: &reason}
 
-	mockRepo.On("GetByID", accountID).Return(existingAccount, nil).Once()
-	mockRepo.On("Update", accountID, mock.AnythingOfType("map[string]interface {}")).Return(fmt.Errorf("database error"))
+	acc, err := svc.UpdateAccount(existing.ID, userID, req)
+	assert.EqualError(t, err, "database error")
+	assert.Nil(t, acc)
+}
 
-	acc, err := svc.UpdateAccount(accountID, userID, req)
-	assert.Error(t, err)
+func TestUpdateAccount_StaleVersion(t *testing.T) {
+	svc, db := setupAccountServiceTest(t)
+	userID := seedAccountHolder(t, db)
+	existing := seedAccount(t, db, userID, map[string]interface{}{"overdraft_limit": 100000.0})
+
+	status := "frozen"
+	reason := "customer_request"
+	version := existing.Version - 1
+	req := &account.UpdateAccountRequest{Status: &status, Reason: &reason, Version: &version}
+
+	acc, err := svc.UpdateAccount(existing.ID, userID, req)
+	assert.ErrorIs(t, err, account.ErrVersionConflict)
+	assert.Nil(t, acc)
+	assert.Equal(t, existing, storedAccount(t, db, existing.ID))
+}
+
+func TestUpdateAccount_ConcurrentChange(t *testing.T) {
+	svc, db := setupAccountServiceTest(t)
+	userID := seedAccountHolder(t, db)
+	existing := seedAccount(t, db, userID, nil)
+	// Another device changes the account between the read and the write
+	svc.accountRepo = &racingAccounts{AccountRepository: fakes.NewAccountRepository(db)}
+
+	status := "frozen"
+	reason := "customer_request"
+	version := existing.Version
+	req := &account.UpdateAccountRequest{Status: &status, Reason: &reason, Version: &version}
+
+	acc, err := svc.UpdateAccount(existing.ID, userID, req)
+	assert.ErrorIs(t, err, account.ErrVersionConflict)
 	assert.Nil(t, acc)
+	assert.Equal(t, account.AccountStatusActive, storedAccount(t, db, existing.ID).Status)
 }
 
 // ==================== CloseAccount Additional Tests ====================
 
 func TestCloseAccount_Unauthorized(t *testing.T) {
-	svc, mockRepo := setupAccountServiceTest(t)
-	ownerID := uuid.New()
-	requestorID := uuid.New()
-	accountID := uuid.New()
-
-	existingAccount := &account.Account{
-		ID:      accountID,
-		UserID:  ownerID,
-		Balance: 0,
-	}
-
-	mockRepo.On("GetByID", accountID).Return(existingAccount, nil)
+	svc, db := setupAccountServiceTest(t)
+	ownerID := seedAccountHolder(t, db)
+	existing := seedAccount(t, db, ownerID, nil)
 
-	err := svc.CloseAccount(accountID, requestorID)
+	err := svc.CloseAccount(existing.ID, uuid.New())
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "unauthorized access")
-	mockRepo.AssertNotCalled(t, "Delete", mock.Anything)
+	assert.Equal(t, account.AccountStatusActive, storedAccount(t, db, existing.ID).Status)
 }
 
 func TestCloseAccount_NotFound(t *testing.T) {
-	svc, mockRepo := setupAccountServiceTest(t)
-	userID := uuid.New()
-	accountID := uuid.New()
-
-	mockRepo.On("GetByID", accountID).Return(nil, fmt.Errorf("account not found"))
+	svc, _ := setupAccountServiceTest(t)
 
-	err := svc.CloseAccount(accountID, userID)
+	err := svc.CloseAccount(uuid.New(), uuid.New())
 	assert.Error(t, err)
-	mockRepo.AssertNotCalled(t, "Delete", mock.Anything)
 }
 
 // ==================== CreateAccount Additional Tests ====================
 
 func TestCreateAccount_GenerateAccountNumberFails(t *testing.T) {
-	svc, mockRepo := setupAccountServiceTest(t)
-	userID := uuid.New()
+	svc, db := setupAccountServiceTest(t)
+	userID := seedAccountHolder(t, db)
+	svc.accountRepo = &failingAccounts{
+		AccountRepository: fakes.NewAccountRepository(db),
+		fail:              map[string]error{"GenerateAccountNumber": fmt.Errorf("failed to generate")},
+	}
 
 	req := &account.CreateAccountRequest{
 		AccountType: "checking",
 		Currency:    "IDR",
 	}
 
-	mockRepo.On("GetByUserID", userID).Return([]*account.Account{}, nil)
-	mockRepo.On("GenerateAccountNumber").Return("", fmt.Errorf("failed to generate"))
-
 	acc, err := svc.CreateAccount(userID, req)
 	assert.Error(t, err)
 	assert.Nil(t, acc)
 }
 
 func TestCreateAccount_CreateFails(t *testing.T) {
-	svc, mockRepo := setupAccountServiceTest(t)
-	userID := uuid.New()
+	svc, db := setupAccountServiceTest(t)
+	userID := seedAccountHolder(t, db)
+	svc.accountRepo = &failingAccounts{
+		AccountRepository: fakes.NewAccountRepository(db),
+		fail:              map[string]error{"Create": fmt.Errorf("database error")},
+	}
 
 	req := &account.CreateAccountRequest{
 		AccountType: "checking",
 		Currency:    "IDR",
 	}
 
-	mockRepo.On("GetByUserID", userID).Return([]*account.Account{}, nil)
-	mockRepo.On("GenerateAccountNumber").Return("1234567890", nil)
-	mockRepo.On("Create", mock.AnythingOfType("*account.Account")).Return(fmt.Errorf("database error"))
-
 	acc, err := svc.CreateAccount(userID, req)
 	assert.Error(t, err)
 	assert.Nil(t, acc)
 }
 
 func TestGetUserAccounts_Error(t *testing.T) {
-	svc, mockRepo := setupAccountServiceTest(t)
-	userID := uuid.New()
+	svc, db := setupAccountServiceTest(t)
+	userID := seedAccountHolder(t, db)
+	svc.accountRepo = &failingAccounts{
+		AccountRepository: fakes.NewAccountRepository(db),
+		fail:              map[string]error{"ListByUser": fmt.Errorf("database error")},
+	}
 
-	mockRepo.On("GetByUserID", userID).Return(nil, fmt.Errorf("database error"))
+	filter := (&account.ListAccountsRequest{}).Filter(userID)
 
-	accounts, err := svc.GetUserAccounts(userID)
+	accounts, _, err := svc.GetUserAccounts(filter)
 	assert.Error(t, err)
 	assert.Nil(t, accounts)
 }
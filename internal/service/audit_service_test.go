@@ -0,0 +1,102 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/audit"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// buildChain creates n correctly linked audit logs
+func buildChain(t *testing.T, n int, anchor string) []*audit.AuditLog {
+	logs := make([]*audit.AuditLog, 0, n)
+	prev := anchor
+	for i := 1; i <= n; i++ {
+		l := &audit.AuditLog{
+			ID:        int64(i),
+			EventID:   uuid.New(),
+			Timestamp: time.Date(2024, 1, 1, 0, 0, i, 0, time.UTC),
+			Action:    "LOGIN",
+			Status:    "success",
+			Metadata:  map[string]interface{}{"n": float64(i)},
+			PrevHash:  prev,
+		}
+		hash, err := l.ComputeHash(prev)
+		assert.NoError(t, err)
+		l.Hash = hash
+		prev = hash
+		logs = append(logs, l)
+	}
+	return logs
+}
+
+func TestVerifyChain_Valid(t *testing.T) {
+	repo := new(MockAuditRepository)
+	svc := NewAuditService(repo)
+
+	repo.On("ListChain", int64(0), auditVerifyBatchSize).Return(buildChain(t, 3, ""), nil)
+
+	result, err := svc.VerifyChain()
+	assert.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.Equal(t, int64(3), result.RecordsChecked)
+	assert.Equal(t, int64(1), result.FirstID)
+	assert.Equal(t, int64(3), result.LastID)
+}
+
+func TestVerifyChain_AfterArchival(t *testing.T) {
+	repo := new(MockAuditRepository)
+	svc := NewAuditService(repo)
+
+	repo.On("ListChain", int64(0), auditVerifyBatchSize).Return(buildChain(t, 2, "archived-head"), nil)
+
+	result, err := svc.VerifyChain()
+	assert.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.Equal(t, "archived-head", result.AnchorHash)
+}
+
+func TestVerifyChain_TamperedRecord(t *testing.T) {
+	repo := new(MockAuditRepository)
+	svc := NewAuditService(repo)
+
+	chain := buildChain(t, 3, "")
+	chain[1].Status = "failed" // edited after the fact
+
+	repo.On("ListChain", int64(0), auditVerifyBatchSize).Return(chain, nil)
+
+	result, err := svc.VerifyChain()
+	assert.NoError(t, err)
+	assert.False(t, result.Valid)
+	assert.Equal(t, int64(2), *result.BrokenAtID)
+	assert.Contains(t, result.Reason, "contents")
+}
+
+func TestVerifyChain_DeletedRecord(t *testing.T) {
+	repo := new(MockAuditRepository)
+	svc := NewAuditService(repo)
+
+	chain := buildChain(t, 3, "")
+	chain = append(chain[:1], chain[2:]...)
+
+	repo.On("ListChain", int64(0), auditVerifyBatchSize).Return(chain, nil)
+
+	result, err := svc.VerifyChain()
+	assert.NoError(t, err)
+	assert.False(t, result.Valid)
+	assert.Equal(t, int64(3), *result.BrokenAtID)
+}
+
+func TestVerifyChain_RepositoryError(t *testing.T) {
+	repo := new(MockAuditRepository)
+	svc := NewAuditService(repo)
+
+	repo.On("ListChain", int64(0), auditVerifyBatchSize).Return(nil, fmt.Errorf("db down"))
+
+	result, err := svc.VerifyChain()
+	assert.Error(t, err)
+	assert.Nil(t, result)
+}
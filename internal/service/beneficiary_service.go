@@ -0,0 +1,246 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/audit"
+	"github.com/darisadam/madabank-server/internal/domain/beneficiary"
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type BeneficiaryService interface {
+	CreateBeneficiary(userID uuid.UUID, req *beneficiary.CreateBeneficiaryRequest) (*beneficiary.Beneficiary, error)
+	ListBeneficiaries(userID uuid.UUID) ([]*beneficiary.Beneficiary, error)
+	GetBeneficiary(userID uuid.UUID, id uuid.UUID) (*beneficiary.Beneficiary, error)
+	UpdateBeneficiary(userID uuid.UUID, id uuid.UUID, req *beneficiary.UpdateBeneficiaryRequest) (*beneficiary.Beneficiary, error)
+	DeleteBeneficiary(userID uuid.UUID, id uuid.UUID) error
+	// VerifyBeneficiary resolves an internal or phone recipient again, e.g.
+	// after the destination account was closed
+	VerifyBeneficiary(userID uuid.UUID, id uuid.UUID) (*beneficiary.Beneficiary, error)
+}
+
+type beneficiaryService struct {
+	beneficiaryRepo repository.BeneficiaryRepository
+	accountRepo     repository.AccountRepository
+	userRepo        repository.UserRepository
+	auditRepo       repository.AuditRepository
+}
+
+func NewBeneficiaryService(
+	beneficiaryRepo repository.BeneficiaryRepository,
+	accountRepo repository.AccountRepository,
+	userRepo repository.UserRepository,
+	auditRepo repository.AuditRepository,
+) BeneficiaryService {
+	return &beneficiaryService{
+		beneficiaryRepo: beneficiaryRepo,
+		accountRepo:     accountRepo,
+		userRepo:        userRepo,
+		auditRepo:       auditRepo,
+	}
+}
+
+// CreateBeneficiary saves a recipient. Internal account and phone recipients
+// must resolve to an active account and are saved verified; external bank
+// recipients are saved unverified with the holder name the user entered.
+func (s *beneficiaryService) CreateBeneficiary(userID uuid.UUID, req *beneficiary.CreateBeneficiaryRequest) (*beneficiary.Beneficiary, error) {
+	req.AccountNumber = strings.TrimSpace(req.AccountNumber)
+	req.BankCode = strings.TrimSpace(req.BankCode)
+	req.PhoneNumber = beneficiary.NormalizePhoneNumber(req.PhoneNumber)
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.beneficiaryRepo.ListByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= beneficiary.MaxPerUser {
+		return nil, fmt.Errorf("cannot save more than %d beneficiaries", beneficiary.MaxPerUser)
+	}
+
+	b := &beneficiary.Beneficiary{
+		ID:                 uuid.New(),
+		UserID:             userID,
+		Type:               req.Type,
+		Nickname:           strings.TrimSpace(req.Nickname),
+		AccountNumber:      req.AccountNumber,
+		PhoneNumber:        req.PhoneNumber,
+		BankCode:           req.BankCode,
+		HolderName:         strings.TrimSpace(req.HolderName),
+		VerificationStatus: beneficiary.VerificationUnverified,
+	}
+
+	for _, e := range existing {
+		if e.Type == b.Type && e.AccountNumber == b.AccountNumber && e.PhoneNumber == b.PhoneNumber && e.BankCode == b.BankCode {
+			return nil, fmt.Errorf("beneficiary already saved as %q", e.Nickname)
+		}
+	}
+
+	if b.Type != beneficiary.TypeExternalBank {
+		acct, holderName, err := s.resolve(b)
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		b.AccountID = &acct.ID
+		b.HolderName = holderName
+		b.VerificationStatus = beneficiary.VerificationVerified
+		b.VerifiedAt = &now
+	}
+
+	if err := s.beneficiaryRepo.Create(b); err != nil {
+		return nil, err
+	}
+
+	s.audit(userID, "BENEFICIARY_CREATED", b.ID, map[string]interface{}{
+		"type":                b.Type,
+		"verification_status": b.VerificationStatus,
+	})
+
+	return b, nil
+}
+
+func (s *beneficiaryService) ListBeneficiaries(userID uuid.UUID) ([]*beneficiary.Beneficiary, error) {
+	return s.beneficiaryRepo.ListByUser(userID)
+}
+
+func (s *beneficiaryService) GetBeneficiary(userID uuid.UUID, id uuid.UUID) (*beneficiary.Beneficiary, error) {
+	b, err := s.beneficiaryRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if b.UserID != userID {
+		return nil, fmt.Errorf("unauthorized: beneficiary does not belong to user")
+	}
+
+	return b, nil
+}
+
+func (s *beneficiaryService) UpdateBeneficiary(userID uuid.UUID, id uuid.UUID, req *beneficiary.UpdateBeneficiaryRequest) (*beneficiary.Beneficiary, error) {
+	b, err := s.GetBeneficiary(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	nickname := strings.TrimSpace(req.Nickname)
+	if err := s.beneficiaryRepo.UpdateNickname(id, nickname); err != nil {
+		return nil, err
+	}
+	b.Nickname = nickname
+
+	s.audit(userID, "BENEFICIARY_UPDATED", id, nil)
+
+	return b, nil
+}
+
+func (s *beneficiaryService) DeleteBeneficiary(userID uuid.UUID, id uuid.UUID) error {
+	if _, err := s.GetBeneficiary(userID, id); err != nil {
+		return err
+	}
+
+	if err := s.beneficiaryRepo.Delete(id); err != nil {
+		return err
+	}
+
+	s.audit(userID, "BENEFICIARY_DELETED", id, nil)
+
+	return nil
+}
+
+func (s *beneficiaryService) VerifyBeneficiary(userID uuid.UUID, id uuid.UUID) (*beneficiary.Beneficiary, error) {
+	b, err := s.GetBeneficiary(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if b.Type == beneficiary.TypeExternalBank {
+		return nil, fmt.Errorf("external bank beneficiaries cannot be verified yet")
+	}
+
+	metadata := map[string]interface{}{}
+	acct, holderName, err := s.resolve(b)
+	if err != nil {
+		// The recipient no longer resolves; clear the account so transfers
+		// cannot use a stale destination
+		b.AccountID = nil
+		b.VerificationStatus = beneficiary.VerificationFailed
+		b.VerifiedAt = nil
+		metadata["reason"] = err.Error()
+	} else {
+		now := time.Now()
+		b.AccountID = &acct.ID
+		b.HolderName = holderName
+		b.VerificationStatus = beneficiary.VerificationVerified
+		b.VerifiedAt = &now
+	}
+	metadata["verification_status"] = b.VerificationStatus
+
+	if err := s.beneficiaryRepo.UpdateVerification(b); err != nil {
+		return nil, err
+	}
+
+	s.audit(userID, "BENEFICIARY_VERIFIED", id, metadata)
+
+	return b, nil
+}
+
+// resolve finds the active account an internal or phone recipient points at
+// and its holder's name. A phone number resolves to the customer's oldest
+// active account.
+func (s *beneficiaryService) resolve(b *beneficiary.Beneficiary) (*account.Account, string, error) {
+	var acct *account.Account
+	switch b.Type {
+	case beneficiary.TypeInternalAccount:
+		found, err := s.accountRepo.GetByAccountNumber(b.AccountNumber)
+		if err != nil {
+			return nil, "", fmt.Errorf("destination account not found")
+		}
+		if found.Status != account.AccountStatusActive {
+			return nil, "", fmt.Errorf("destination account is %s", found.Status)
+		}
+		acct = found
+	case beneficiary.TypePhone:
+		u, err := s.userRepo.GetByPhone(b.PhoneNumber)
+		if err != nil || !u.IsActive {
+			return nil, "", fmt.Errorf("no customer is registered with this phone number")
+		}
+		accounts, err := s.accountRepo.GetByUserID(u.ID)
+		if err != nil {
+			return nil, "", err
+		}
+		acct = account.ReceivingAccount(accounts)
+		if acct == nil {
+			return nil, "", fmt.Errorf("customer has no active account to receive transfers")
+		}
+	default:
+		return nil, "", fmt.Errorf("cannot resolve %s beneficiaries", b.Type)
+	}
+
+	holder, err := s.userRepo.GetByID(acct.UserID)
+	if err != nil {
+		return nil, "", fmt.Errorf("destination account holder not found")
+	}
+
+	return acct, strings.TrimSpace(holder.FirstName + " " + holder.LastName), nil
+}
+
+func (s *beneficiaryService) audit(userID uuid.UUID, action string, beneficiaryID uuid.UUID, metadata map[string]interface{}) {
+	if err := s.auditRepo.Create(&audit.AuditLog{
+		EventID:  uuid.New(),
+		UserID:   &userID,
+		Action:   action,
+		Resource: fmt.Sprintf("beneficiary:%s", beneficiaryID),
+		Status:   "success",
+		Metadata: metadata,
+	}); err != nil {
+		logger.Error("Failed to create audit log for beneficiary", zap.String("action", action), zap.Error(err))
+		errtrack.CaptureError(err, map[string]string{"component": "beneficiary_service", "operation": "audit_log"})
+	}
+}
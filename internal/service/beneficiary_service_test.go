@@ -0,0 +1,185 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/beneficiary"
+	"github.com/darisadam/madabank-server/internal/domain/user"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockBeneficiaryRepository is a mock implementation of repository.BeneficiaryRepository
+type MockBeneficiaryRepository struct {
+	mock.Mock
+}
+
+func (m *MockBeneficiaryRepository) Create(b *beneficiary.Beneficiary) error {
+	args := m.Called(b)
+	return args.Error(0)
+}
+
+func (m *MockBeneficiaryRepository) GetByID(id uuid.UUID) (*beneficiary.Beneficiary, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*beneficiary.Beneficiary), args.Error(1)
+}
+
+func (m *MockBeneficiaryRepository) ListByUser(userID uuid.UUID) ([]*beneficiary.Beneficiary, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*beneficiary.Beneficiary), args.Error(1)
+}
+
+func (m *MockBeneficiaryRepository) GetByAccountID(userID, accountID uuid.UUID) (*beneficiary.Beneficiary, error) {
+	args := m.Called(userID, accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*beneficiary.Beneficiary), args.Error(1)
+}
+
+func (m *MockBeneficiaryRepository) UpdateNickname(id uuid.UUID, nickname string) error {
+	args := m.Called(id, nickname)
+	return args.Error(0)
+}
+
+func (m *MockBeneficiaryRepository) UpdateVerification(b *beneficiary.Beneficiary) error {
+	args := m.Called(b)
+	return args.Error(0)
+}
+
+func (m *MockBeneficiaryRepository) MarkUsed(id uuid.UUID, at time.Time) error {
+	args := m.Called(id, at)
+	return args.Error(0)
+}
+
+func (m *MockBeneficiaryRepository) Delete(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func setupBeneficiaryServiceTest() (BeneficiaryService, *MockBeneficiaryRepository, *MockAccountRepository, *MockUserRepository) {
+	logger.Init("test")
+	beneficiaryRepo := new(MockBeneficiaryRepository)
+	accountRepo := new(MockAccountRepository)
+	userRepo := new(MockUserRepository)
+	auditRepo := new(MockAuditRepository)
+	auditRepo.On("Create", mock.Anything).Return(nil)
+
+	return NewBeneficiaryService(beneficiaryRepo, accountRepo, userRepo, auditRepo), beneficiaryRepo, accountRepo, userRepo
+}
+
+func TestCreateBeneficiary_PhoneResolvesOldestActiveAccount(t *testing.T) {
+	svc, beneficiaryRepo, accountRepo, userRepo := setupBeneficiaryServiceTest()
+	userID := uuid.New()
+	payee := &user.User{ID: uuid.New(), FirstName: "Siti", LastName: "Rahma", IsActive: true}
+	now := time.Now()
+	oldest := &account.Account{ID: uuid.New(), UserID: payee.ID, Status: account.AccountStatusActive, CreatedAt: now.AddDate(-1, 0, 0)}
+	newer := &account.Account{ID: uuid.New(), UserID: payee.ID, Status: account.AccountStatusActive, CreatedAt: now}
+	closed := &account.Account{ID: uuid.New(), UserID: payee.ID, Status: account.AccountStatusClosed, CreatedAt: now.AddDate(-2, 0, 0)}
+
+	beneficiaryRepo.On("ListByUser", userID).Return([]*beneficiary.Beneficiary{}, nil)
+	userRepo.On("GetByPhone", "081234567890").Return(payee, nil)
+	userRepo.On("GetByID", payee.ID).Return(payee, nil)
+	accountRepo.On("GetByUserID", payee.ID).Return([]*account.Account{newer, oldest, closed}, nil)
+	beneficiaryRepo.On("Create", mock.Anything).Return(nil)
+
+	b, err := svc.CreateBeneficiary(userID, &beneficiary.CreateBeneficiaryRequest{
+		Type: beneficiary.TypePhone, Nickname: "Siti", PhoneNumber: "0812-3456-7890",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "081234567890", b.PhoneNumber)
+	assert.Equal(t, oldest.ID, *b.AccountID)
+	assert.Equal(t, "Siti Rahma", b.HolderName)
+	assert.Equal(t, beneficiary.VerificationVerified, b.VerificationStatus)
+	assert.NotNil(t, b.VerifiedAt)
+}
+
+func TestCreateBeneficiary_InternalAccountNotFound(t *testing.T) {
+	svc, beneficiaryRepo, accountRepo, _ := setupBeneficiaryServiceTest()
+	userID := uuid.New()
+
+	beneficiaryRepo.On("ListByUser", userID).Return([]*beneficiary.Beneficiary{}, nil)
+	accountRepo.On("GetByAccountNumber", "1234567890").Return(nil, fmt.Errorf("account not found"))
+
+	_, err := svc.CreateBeneficiary(userID, &beneficiary.CreateBeneficiaryRequest{
+		Type: beneficiary.TypeInternalAccount, Nickname: "Rent", AccountNumber: "1234567890",
+	})
+
+	assert.EqualError(t, err, "destination account not found")
+	beneficiaryRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestCreateBeneficiary_ExternalBankSavedUnverified(t *testing.T) {
+	svc, beneficiaryRepo, _, _ := setupBeneficiaryServiceTest()
+	userID := uuid.New()
+
+	beneficiaryRepo.On("ListByUser", userID).Return([]*beneficiary.Beneficiary{}, nil)
+	beneficiaryRepo.On("Create", mock.Anything).Return(nil)
+
+	b, err := svc.CreateBeneficiary(userID, &beneficiary.CreateBeneficiaryRequest{
+		Type: beneficiary.TypeExternalBank, Nickname: "Landlord", BankCode: "014", AccountNumber: "9876543210", HolderName: "Budi",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, beneficiary.VerificationUnverified, b.VerificationStatus)
+	assert.Nil(t, b.AccountID)
+	assert.Equal(t, "Budi", b.HolderName)
+}
+
+func TestCreateBeneficiary_Duplicate(t *testing.T) {
+	svc, beneficiaryRepo, _, _ := setupBeneficiaryServiceTest()
+	userID := uuid.New()
+
+	beneficiaryRepo.On("ListByUser", userID).Return([]*beneficiary.Beneficiary{
+		{Type: beneficiary.TypeExternalBank, Nickname: "Landlord", BankCode: "014", AccountNumber: "9876543210"},
+	}, nil)
+
+	_, err := svc.CreateBeneficiary(userID, &beneficiary.CreateBeneficiaryRequest{
+		Type: beneficiary.TypeExternalBank, Nickname: "Rent", BankCode: "014", AccountNumber: "9876543210", HolderName: "Budi",
+	})
+
+	assert.EqualError(t, err, `beneficiary already saved as "Landlord"`)
+}
+
+func TestVerifyBeneficiary_FailsWhenAccountClosed(t *testing.T) {
+	svc, beneficiaryRepo, accountRepo, _ := setupBeneficiaryServiceTest()
+	userID := uuid.New()
+	accountID := uuid.New()
+	saved := &beneficiary.Beneficiary{
+		ID: uuid.New(), UserID: userID, Type: beneficiary.TypeInternalAccount, AccountNumber: "1234567890",
+		AccountID: &accountID, VerificationStatus: beneficiary.VerificationVerified,
+	}
+
+	beneficiaryRepo.On("GetByID", saved.ID).Return(saved, nil)
+	accountRepo.On("GetByAccountNumber", "1234567890").Return(&account.Account{ID: accountID, Status: account.AccountStatusClosed}, nil)
+	beneficiaryRepo.On("UpdateVerification", saved).Return(nil)
+
+	b, err := svc.VerifyBeneficiary(userID, saved.ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, beneficiary.VerificationFailed, b.VerificationStatus)
+	assert.Nil(t, b.AccountID)
+}
+
+func TestDeleteBeneficiary_Unauthorized(t *testing.T) {
+	svc, beneficiaryRepo, _, _ := setupBeneficiaryServiceTest()
+	saved := &beneficiary.Beneficiary{ID: uuid.New(), UserID: uuid.New()}
+	beneficiaryRepo.On("GetByID", saved.ID).Return(saved, nil)
+
+	err := svc.DeleteBeneficiary(uuid.New(), saved.ID)
+
+	assert.Error(t, err)
+	beneficiaryRepo.AssertNotCalled(t, "Delete", mock.Anything)
+}
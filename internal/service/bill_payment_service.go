@@ -0,0 +1,388 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/audit"
+	"github.com/darisadam/madabank-server/internal/domain/billpay"
+	"github.com/darisadam/madabank-server/internal/domain/saga"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/pkg/billeragg"
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/pkg/metrics"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	// Kept below the server write timeout so the client still gets an answer
+	billAggregatorTimeout = 8 * time.Second
+
+	sagaKindBillPayment = "bill_payment"
+)
+
+type BillPaymentService interface {
+	ListBillers(req *billpay.ListBillersRequest) ([]*billpay.Biller, error)
+	Inquire(userID uuid.UUID, req *billpay.InquiryRequest) (*billpay.Bill, error)
+	Pay(userID uuid.UUID, req *billpay.PaymentRequest) (*transaction.Transaction, error)
+}
+
+type billPaymentService struct {
+	billRepo        repository.BillPaymentRepository
+	accountRepo     repository.AccountRepository
+	transactionRepo repository.TransactionRepository
+	auditRepo       repository.AuditRepository
+	redisClient     *redis.Client
+	aggregator      billeragg.Aggregator
+	orchestrator    SagaOrchestrator
+}
+
+func NewBillPaymentService(
+	billRepo repository.BillPaymentRepository,
+	accountRepo repository.AccountRepository,
+	transactionRepo repository.TransactionRepository,
+	auditRepo repository.AuditRepository,
+	redisClient *redis.Client,
+	aggregator billeragg.Aggregator,
+	orchestrator SagaOrchestrator,
+) BillPaymentService {
+	s := &billPaymentService{
+		billRepo:        billRepo,
+		accountRepo:     accountRepo,
+		transactionRepo: transactionRepo,
+		auditRepo:       auditRepo,
+		redisClient:     redisClient,
+		aggregator:      aggregator,
+		orchestrator:    orchestrator,
+	}
+	orchestrator.Register(s.billPaymentSaga())
+	return s
+}
+
+// pendingInquiry is what an inquiry leaves in Redis for the payment step
+type pendingInquiry struct {
+	UserID          uuid.UUID    `json:"user_id"`
+	BillerReference string       `json:"biller_reference"`
+	Bill            billpay.Bill `json:"bill"`
+}
+
+// billPaymentSagaData is the state a bill payment saga carries between steps
+type billPaymentSagaData struct {
+	UserID        uuid.UUID                `json:"user_id"`
+	Inquiry       pendingInquiry           `json:"inquiry"`
+	Transaction   *transaction.Transaction `json:"transaction"`
+	BillerReceipt string                   `json:"biller_receipt,omitempty"`
+}
+
+func billInquiryKey(inquiryID string) string {
+	return "bill_inquiry:" + inquiryID
+}
+
+func (s *billPaymentService) ListBillers(req *billpay.ListBillersRequest) ([]*billpay.Biller, error) {
+	category := billpay.BillerCategory(req.Category)
+	if category != "" && !billpay.IsValidCategory(category) {
+		return nil, fmt.Errorf("invalid biller category")
+	}
+
+	return s.billRepo.ListBillers(category)
+}
+
+// Inquire fetches the amount due from the aggregator and holds it for InquiryTTL
+func (s *billPaymentService) Inquire(userID uuid.UUID, req *billpay.InquiryRequest) (*billpay.Bill, error) {
+	biller, err := s.billRepo.GetBiller(req.BillerCode)
+	if err != nil || !biller.Active {
+		return nil, fmt.Errorf("biller not found")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), billAggregatorTimeout)
+	defer cancel()
+
+	result, err := s.aggregator.Inquire(ctx, biller.Code, req.CustomerNumber)
+	if errors.Is(err, billeragg.ErrBillNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		logger.Error("Bill inquiry failed", zap.String("biller", biller.Code), zap.String("aggregator", s.aggregator.Name()), zap.Error(err))
+		return nil, fmt.Errorf("biller is unavailable, please try again later")
+	}
+
+	bill := billpay.Bill{
+		InquiryID:      uuid.New().String(),
+		BillerCode:     biller.Code,
+		BillerName:     biller.Name,
+		Category:       biller.Category,
+		CustomerNumber: req.CustomerNumber,
+		CustomerName:   result.CustomerName,
+		Period:         result.Period,
+		Amount:         result.Amount,
+		AdminFee:       biller.AdminFee,
+		TotalAmount:    result.Amount + biller.AdminFee,
+		ExpiresAt:      time.Now().Add(billpay.InquiryTTL),
+	}
+
+	data, err := json.Marshal(pendingInquiry{UserID: userID, BillerReference: result.Reference, Bill: bill})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store inquiry: %w", err)
+	}
+	if err := s.redisClient.Set(context.Background(), billInquiryKey(bill.InquiryID), data, billpay.InquiryTTL).Err(); err != nil {
+		return nil, fmt.Errorf("failed to store inquiry: %w", err)
+	}
+
+	return &bill, nil
+}
+
+// Pay debits the account for an inquired bill and settles it with the aggregator
+// as a saga. A payment the biller rejects is refunded; one whose outcome is
+// unknown (timeout, aggregator error) stays pending while the saga retries it.
+func (s *billPaymentService) Pay(userID uuid.UUID, req *billpay.PaymentRequest) (*transaction.Transaction, error) {
+	start := time.Now()
+
+	accountID, err := uuid.Parse(req.AccountID)
+	if err != nil {
+		metrics.RecordTransactionError("bill_payment", "invalid_account")
+		return nil, fmt.Errorf("invalid account_id")
+	}
+
+	// Check idempotency
+	if existing, err := s.transactionRepo.GetByIdempotencyKey(req.IdempotencyKey); err == nil {
+		return existing, nil
+	}
+
+	acct, err := s.accountRepo.GetByID(accountID)
+	if err != nil {
+		metrics.RecordTransactionError("bill_payment", "account_not_found")
+		return nil, fmt.Errorf("account not found")
+	}
+	if acct.UserID != userID {
+		metrics.RecordTransactionError("bill_payment", "unauthorized")
+		return nil, fmt.Errorf("unauthorized: account does not belong to user")
+	}
+	if acct.Status != account.AccountStatusActive {
+		metrics.RecordTransactionError("bill_payment", "account_not_active")
+		return nil, fmt.Errorf("account is %s, cannot perform payments", acct.Status)
+	}
+
+	// Consume the inquiry so the same bill cannot be paid twice
+	inquiry, err := s.takeInquiry(userID, req.InquiryID)
+	if err != nil {
+		metrics.RecordTransactionError("bill_payment", "inquiry_not_found")
+		return nil, err
+	}
+	bill := inquiry.Bill
+
+	txn := &transaction.Transaction{
+		ID:              uuid.New(),
+		IdempotencyKey:  req.IdempotencyKey,
+		FromAccountID:   &accountID,
+		Amount:          bill.TotalAmount,
+		TransactionType: transaction.TransactionTypeBillPayment,
+		Status:          transaction.TransactionStatusPending,
+		Description:     fmt.Sprintf("%s %s", bill.BillerName, bill.CustomerNumber),
+		Metadata: map[string]interface{}{
+			"initiated_by":    userID.String(),
+			"currency":        acct.Currency,
+			"biller_code":     bill.BillerCode,
+			"biller_name":     bill.BillerName,
+			"biller_category": string(bill.Category),
+			"customer_number": bill.CustomerNumber,
+			"customer_name":   bill.CustomerName,
+			"period":          bill.Period,
+			"bill_amount":     bill.Amount,
+			"admin_fee":       bill.AdminFee,
+			"aggregator":      s.aggregator.Name(),
+		},
+	}
+
+	sg, err := s.orchestrator.Start(txn.ID, sagaKindBillPayment, &billPaymentSagaData{
+		UserID:      userID,
+		Inquiry:     *inquiry,
+		Transaction: txn,
+	})
+	switch {
+	case errors.Is(err, billeragg.ErrPaymentRejected):
+		// The debit has been refunded, or will be once the refund is retried
+		metrics.RecordTransaction("bill_payment", "failed", bill.TotalAmount, acct.Currency, time.Since(start).Seconds())
+		metrics.RecordTransactionError("bill_payment", "rejected")
+		s.recordAudit(userID, "BILL_PAYMENT_FAILED", "failed", txn.ID, bill, err)
+		return nil, err
+	case sg == nil || sg.Status == saga.StatusCompensated:
+		metrics.RecordTransaction("bill_payment", "failed", bill.TotalAmount, acct.Currency, time.Since(start).Seconds())
+		metrics.RecordTransactionError("bill_payment", "execution_failed")
+		s.recordAudit(userID, "BILL_PAYMENT_FAILED", "failed", txn.ID, bill, err)
+		// Nothing was debited, so the bill can still be paid (e.g. after a top-up)
+		s.restoreInquiry(inquiry)
+		return nil, err
+	case sg.Status != saga.StatusCompleted:
+		logger.Error("Bill payment outcome unknown, left pending for retry",
+			zap.String("transaction_id", txn.ID.String()),
+			zap.String("aggregator", s.aggregator.Name()),
+			zap.Error(err),
+		)
+		errtrack.CaptureError(err, map[string]string{"component": "bill_payment_service", "operation": "aggregator_pay"})
+		metrics.RecordTransaction("bill_payment", "pending", bill.TotalAmount, acct.Currency, time.Since(start).Seconds())
+		s.recordAudit(userID, "BILL_PAYMENT_PENDING", "success", txn.ID, bill, err)
+		return s.transactionRepo.GetByID(txn.ID)
+	}
+
+	metrics.RecordTransaction("bill_payment", "completed", bill.TotalAmount, acct.Currency, time.Since(start).Seconds())
+	s.recordAudit(userID, "BILL_PAYMENT_COMPLETED", "success", txn.ID, bill, nil)
+
+	return s.transactionRepo.GetByID(txn.ID)
+}
+
+// billPaymentSaga debits the account, pays the biller through the aggregator
+// and completes the payment. A payment the biller rejects is refunded; one
+// whose outcome is unknown (timeout, aggregator error) is retried, which is
+// safe because aggregators deduplicate payments by ID.
+func (s *billPaymentService) billPaymentSaga() saga.Definition {
+	return saga.Definition{
+		Kind: sagaKindBillPayment,
+		Steps: []saga.Step{
+			{Name: "debit", Action: s.debitBillPayment, Compensate: s.refundBillPayment},
+			{Name: "pay_biller", Action: s.payBiller},
+			{Name: "complete", Action: s.completeBillPayment},
+		},
+	}
+}
+
+func (s *billPaymentService) debitBillPayment(_ context.Context, raw json.RawMessage) (json.RawMessage, error) {
+	var data billPaymentSagaData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, saga.Abort(err)
+	}
+	txn := data.Transaction
+
+	// An earlier attempt may have debited the account before crashing
+	if _, err := s.transactionRepo.GetByIdempotencyKey(txn.IdempotencyKey); err == nil {
+		return nil, nil
+	}
+
+	if err := s.billRepo.ExecuteBillPayment(*txn.FromAccountID, txn.Amount, txn); err != nil {
+		return nil, saga.Abort(err)
+	}
+	return nil, nil
+}
+
+func (s *billPaymentService) refundBillPayment(_ context.Context, raw json.RawMessage, cause string) error {
+	var data billPaymentSagaData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return err
+	}
+
+	if err := s.billRepo.ReverseBillPayment(data.Transaction.ID, cause); err != nil {
+		if txn, errGet := s.transactionRepo.GetByID(data.Transaction.ID); errGet == nil && txn.Status == transaction.TransactionStatusReversed {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *billPaymentService) payBiller(ctx context.Context, raw json.RawMessage) (json.RawMessage, error) {
+	var data billPaymentSagaData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+	bill := data.Inquiry.Bill
+
+	ctx, cancel := context.WithTimeout(ctx, billAggregatorTimeout)
+	defer cancel()
+
+	receipt, err := s.aggregator.Pay(ctx, billeragg.Payment{
+		PaymentID:        data.Transaction.ID.String(),
+		BillerCode:       bill.BillerCode,
+		CustomerNumber:   bill.CustomerNumber,
+		InquiryReference: data.Inquiry.BillerReference,
+		Amount:           bill.Amount,
+	})
+	if errors.Is(err, billeragg.ErrPaymentRejected) {
+		return nil, saga.Abort(err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data.BillerReceipt = receipt.Reference
+	return json.Marshal(data)
+}
+
+func (s *billPaymentService) completeBillPayment(_ context.Context, raw json.RawMessage) (json.RawMessage, error) {
+	var data billPaymentSagaData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+
+	// The biller has been paid, so the debit stands whatever happens here
+	if err := s.billRepo.CompleteBillPayment(data.Transaction.ID, data.BillerReceipt); err != nil {
+		if txn, errGet := s.transactionRepo.GetByID(data.Transaction.ID); errGet == nil && txn.Status == transaction.TransactionStatusCompleted {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (s *billPaymentService) takeInquiry(userID uuid.UUID, inquiryID string) (*pendingInquiry, error) {
+	data, err := s.redisClient.GetDel(context.Background(), billInquiryKey(inquiryID)).Bytes()
+	if err == redis.Nil {
+		return nil, fmt.Errorf("inquiry not found or expired")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load inquiry: %w", err)
+	}
+
+	var inquiry pendingInquiry
+	if err := json.Unmarshal(data, &inquiry); err != nil {
+		return nil, fmt.Errorf("failed to load inquiry: %w", err)
+	}
+	if inquiry.UserID != userID {
+		return nil, fmt.Errorf("inquiry not found or expired")
+	}
+
+	return &inquiry, nil
+}
+
+func (s *billPaymentService) restoreInquiry(inquiry *pendingInquiry) {
+	ttl := time.Until(inquiry.Bill.ExpiresAt)
+	if ttl <= 0 {
+		return
+	}
+	data, err := json.Marshal(inquiry)
+	if err != nil {
+		return
+	}
+	if err := s.redisClient.Set(context.Background(), billInquiryKey(inquiry.Bill.InquiryID), data, ttl).Err(); err != nil {
+		logger.Warn("Failed to restore bill inquiry", zap.String("inquiry_id", inquiry.Bill.InquiryID), zap.Error(err))
+	}
+}
+
+func (s *billPaymentService) recordAudit(userID uuid.UUID, action, status string, txnID uuid.UUID, bill billpay.Bill, cause error) {
+	metadata := map[string]interface{}{
+		"amount":          bill.TotalAmount,
+		"biller_code":     bill.BillerCode,
+		"customer_number": bill.CustomerNumber,
+	}
+	if cause != nil {
+		metadata["error"] = cause.Error()
+	}
+
+	if err := s.auditRepo.Create(&audit.AuditLog{
+		EventID:  uuid.New(),
+		UserID:   &userID,
+		Action:   action,
+		Resource: fmt.Sprintf("transaction:%s", txnID),
+		Status:   status,
+		Metadata: metadata,
+	}); err != nil {
+		logger.Error("Failed to create audit log for bill payment", zap.Error(err))
+		errtrack.CaptureError(err, map[string]string{"component": "bill_payment_service", "operation": "audit_log"})
+	}
+}
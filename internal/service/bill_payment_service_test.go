@@ -0,0 +1,314 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	domainAccount "github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/audit"
+	"github.com/darisadam/madabank-server/internal/domain/billpay"
+	"github.com/darisadam/madabank-server/internal/domain/saga"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/pkg/billeragg"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockBillPaymentRepository is a mock implementation of repository.BillPaymentRepository
+type MockBillPaymentRepository struct {
+	mock.Mock
+}
+
+func (m *MockBillPaymentRepository) ListBillers(category billpay.BillerCategory) ([]*billpay.Biller, error) {
+	args := m.Called(category)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*billpay.Biller), args.Error(1)
+}
+
+func (m *MockBillPaymentRepository) GetBiller(code string) (*billpay.Biller, error) {
+	args := m.Called(code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*billpay.Biller), args.Error(1)
+}
+
+func (m *MockBillPaymentRepository) ExecuteBillPayment(accountID uuid.UUID, amount float64, txn *transaction.Transaction) error {
+	args := m.Called(accountID, amount, txn)
+	return args.Error(0)
+}
+
+func (m *MockBillPaymentRepository) CompleteBillPayment(txnID uuid.UUID, billerReference string) error {
+	args := m.Called(txnID, billerReference)
+	return args.Error(0)
+}
+
+func (m *MockBillPaymentRepository) ReverseBillPayment(txnID uuid.UUID, reason string) error {
+	args := m.Called(txnID, reason)
+	return args.Error(0)
+}
+
+// MockBillerAggregator is a mock implementation of billeragg.Aggregator
+type MockBillerAggregator struct {
+	mock.Mock
+}
+
+func (m *MockBillerAggregator) Inquire(ctx context.Context, billerCode, customerNumber string) (*billeragg.Bill, error) {
+	args := m.Called(billerCode, customerNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*billeragg.Bill), args.Error(1)
+}
+
+func (m *MockBillerAggregator) Pay(ctx context.Context, payment billeragg.Payment) (*billeragg.Receipt, error) {
+	args := m.Called(payment)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*billeragg.Receipt), args.Error(1)
+}
+
+func (m *MockBillerAggregator) Name() string {
+	return "mock"
+}
+
+type billPaymentTest struct {
+	svc          *billPaymentService
+	billRepo     *MockBillPaymentRepository
+	accountRepo  *MockAccountRepository
+	txnRepo      *MockTransactionRepository
+	auditRepo    *MockAuditRepository
+	aggregator   *MockBillerAggregator
+	sagaRepo     *MockSagaRepository
+	orchestrator SagaOrchestrator
+	mr           *miniredis.Miniredis
+	userID       uuid.UUID
+	accountID    uuid.UUID
+}
+
+func setupBillPaymentServiceTest(t *testing.T) *billPaymentTest {
+	logger.Init("test")
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	t.Cleanup(mr.Close)
+
+	tt := &billPaymentTest{
+		billRepo:    new(MockBillPaymentRepository),
+		accountRepo: new(MockAccountRepository),
+		txnRepo:     new(MockTransactionRepository),
+		auditRepo:   new(MockAuditRepository),
+		aggregator:  new(MockBillerAggregator),
+		mr:          mr,
+		userID:      uuid.New(),
+		accountID:   uuid.New(),
+	}
+	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	tt.orchestrator, tt.sagaRepo = newTestSagaOrchestrator()
+	tt.svc = NewBillPaymentService(tt.billRepo, tt.accountRepo, tt.txnRepo, tt.auditRepo, redisClient, tt.aggregator, tt.orchestrator).(*billPaymentService)
+
+	tt.billRepo.On("GetBiller", "PLN_POSTPAID").Return(&billpay.Biller{
+		Code: "PLN_POSTPAID", Name: "PLN Postpaid", Category: billpay.BillerCategoryElectricity, AdminFee: 2500, Active: true,
+	}, nil)
+	tt.accountRepo.On("GetByID", tt.accountID).Return(&domainAccount.Account{
+		ID: tt.accountID, UserID: tt.userID, Status: domainAccount.AccountStatusActive, Currency: "IDR",
+	}, nil)
+	tt.txnRepo.On("GetByIdempotencyKey", mock.Anything).Return(nil, fmt.Errorf("transaction not found"))
+	return tt
+}
+
+// inquire runs a successful inquiry for a 150,000 bill
+func (tt *billPaymentTest) inquire(t *testing.T, customerNumber string) *billpay.Bill {
+	tt.aggregator.On("Inquire", "PLN_POSTPAID", customerNumber).Return(&billeragg.Bill{
+		CustomerName: "BUDI SANTOSO", Period: "2026-01", Amount: 150000, Reference: "INQ-1",
+	}, nil).Once()
+
+	bill, err := tt.svc.Inquire(tt.userID, &billpay.InquiryRequest{BillerCode: "PLN_POSTPAID", CustomerNumber: customerNumber})
+	assert.NoError(t, err)
+	return bill
+}
+
+func (tt *billPaymentTest) payRequest(bill *billpay.Bill) *billpay.PaymentRequest {
+	return &billpay.PaymentRequest{AccountID: tt.accountID.String(), InquiryID: bill.InquiryID, IdempotencyKey: "bill-1"}
+}
+
+func TestListBillers_InvalidCategory(t *testing.T) {
+	tt := setupBillPaymentServiceTest(t)
+
+	_, err := tt.svc.ListBillers(&billpay.ListBillersRequest{Category: "groceries"})
+
+	assert.EqualError(t, err, "invalid biller category")
+	tt.billRepo.AssertNotCalled(t, "ListBillers", mock.Anything)
+}
+
+func TestInquire_Success(t *testing.T) {
+	tt := setupBillPaymentServiceTest(t)
+
+	bill := tt.inquire(t, "512345678901")
+
+	assert.Equal(t, "BUDI SANTOSO", bill.CustomerName)
+	assert.Equal(t, 150000.0, bill.Amount)
+	assert.Equal(t, 152500.0, bill.TotalAmount)
+	assert.True(t, tt.mr.Exists(billInquiryKey(bill.InquiryID)))
+	assert.Equal(t, billpay.InquiryTTL, tt.mr.TTL(billInquiryKey(bill.InquiryID)))
+}
+
+func TestInquire_BillNotFound(t *testing.T) {
+	tt := setupBillPaymentServiceTest(t)
+	tt.aggregator.On("Inquire", "PLN_POSTPAID", "512345670000").Return(nil, billeragg.ErrBillNotFound)
+
+	_, err := tt.svc.Inquire(tt.userID, &billpay.InquiryRequest{BillerCode: "PLN_POSTPAID", CustomerNumber: "512345670000"})
+
+	assert.ErrorIs(t, err, billeragg.ErrBillNotFound)
+}
+
+func TestInquire_UnknownBiller(t *testing.T) {
+	tt := setupBillPaymentServiceTest(t)
+	tt.billRepo.On("GetBiller", "NOPE").Return(nil, fmt.Errorf("biller not found"))
+
+	_, err := tt.svc.Inquire(tt.userID, &billpay.InquiryRequest{BillerCode: "NOPE", CustomerNumber: "1234"})
+
+	assert.EqualError(t, err, "biller not found")
+	tt.aggregator.AssertNotCalled(t, "Inquire", mock.Anything, mock.Anything)
+}
+
+func TestPayBill_Success(t *testing.T) {
+	tt := setupBillPaymentServiceTest(t)
+	bill := tt.inquire(t, "512345678901")
+
+	tt.billRepo.On("ExecuteBillPayment", tt.accountID, 152500.0, mock.MatchedBy(func(txn *transaction.Transaction) bool {
+		return txn.TransactionType == transaction.TransactionTypeBillPayment &&
+			txn.Metadata["biller_code"] == "PLN_POSTPAID" &&
+			txn.Metadata["customer_number"] == "512345678901" &&
+			txn.Metadata["admin_fee"] == 2500.0
+	})).Return(nil)
+	tt.aggregator.On("Pay", mock.MatchedBy(func(p billeragg.Payment) bool {
+		// The biller is paid the bill amount; the admin fee stays with the bank
+		return p.Amount == 150000 && p.InquiryReference == "INQ-1"
+	})).Return(&billeragg.Receipt{Reference: "PAY-1"}, nil)
+	tt.billRepo.On("CompleteBillPayment", mock.Anything, "PAY-1").Return(nil)
+	tt.auditRepo.On("Create", mock.MatchedBy(func(l *audit.AuditLog) bool {
+		return l.Action == "BILL_PAYMENT_COMPLETED"
+	})).Return(nil)
+	tt.txnRepo.On("GetByID", mock.Anything).Return(&transaction.Transaction{Status: transaction.TransactionStatusCompleted}, nil)
+
+	txn, err := tt.svc.Pay(tt.userID, tt.payRequest(bill))
+
+	assert.NoError(t, err)
+	assert.Equal(t, transaction.TransactionStatusCompleted, txn.Status)
+	assert.False(t, tt.mr.Exists(billInquiryKey(bill.InquiryID)), "inquiry must be single use")
+	tt.billRepo.AssertNotCalled(t, "ReverseBillPayment", mock.Anything, mock.Anything)
+	tt.billRepo.AssertExpectations(t)
+}
+
+func TestPayBill_RejectedIsReversed(t *testing.T) {
+	tt := setupBillPaymentServiceTest(t)
+	bill := tt.inquire(t, "512345679999")
+
+	tt.billRepo.On("ExecuteBillPayment", tt.accountID, 152500.0, mock.Anything).Return(nil)
+	tt.aggregator.On("Pay", mock.Anything).Return(nil, billeragg.ErrPaymentRejected)
+	tt.billRepo.On("ReverseBillPayment", mock.Anything, billeragg.ErrPaymentRejected.Error()).Return(nil)
+	tt.auditRepo.On("Create", mock.MatchedBy(func(l *audit.AuditLog) bool {
+		return l.Action == "BILL_PAYMENT_FAILED"
+	})).Return(nil)
+
+	_, err := tt.svc.Pay(tt.userID, tt.payRequest(bill))
+
+	assert.ErrorIs(t, err, billeragg.ErrPaymentRejected)
+	tt.billRepo.AssertExpectations(t)
+	tt.billRepo.AssertNotCalled(t, "CompleteBillPayment", mock.Anything, mock.Anything)
+}
+
+func TestPayBill_UnknownOutcomeStaysPending(t *testing.T) {
+	tt := setupBillPaymentServiceTest(t)
+	bill := tt.inquire(t, "512345678901")
+
+	tt.billRepo.On("ExecuteBillPayment", tt.accountID, 152500.0, mock.Anything).Return(nil)
+	tt.aggregator.On("Pay", mock.Anything).Return(nil, context.DeadlineExceeded)
+	tt.auditRepo.On("Create", mock.MatchedBy(func(l *audit.AuditLog) bool {
+		return l.Action == "BILL_PAYMENT_PENDING"
+	})).Return(nil)
+	tt.txnRepo.On("GetByID", mock.Anything).Return(&transaction.Transaction{Status: transaction.TransactionStatusPending}, nil)
+
+	txn, err := tt.svc.Pay(tt.userID, tt.payRequest(bill))
+
+	assert.NoError(t, err)
+	assert.Equal(t, transaction.TransactionStatusPending, txn.Status)
+	tt.billRepo.AssertNotCalled(t, "ReverseBillPayment", mock.Anything, mock.Anything)
+	tt.billRepo.AssertNotCalled(t, "CompleteBillPayment", mock.Anything, mock.Anything)
+}
+
+func TestPayBill_UnknownOutcomeCompletesOnResume(t *testing.T) {
+	tt := setupBillPaymentServiceTest(t)
+	bill := tt.inquire(t, "512345678901")
+
+	tt.billRepo.On("ExecuteBillPayment", tt.accountID, 152500.0, mock.Anything).Return(nil).Once()
+	tt.aggregator.On("Pay", mock.Anything).Return(nil, context.DeadlineExceeded).Once()
+	tt.auditRepo.On("Create", mock.Anything).Return(nil)
+	tt.txnRepo.On("GetByID", mock.Anything).Return(&transaction.Transaction{Status: transaction.TransactionStatusPending}, nil)
+
+	_, err := tt.svc.Pay(tt.userID, tt.payRequest(bill))
+	assert.NoError(t, err)
+
+	// The retry pays the same payment ID, which the aggregator deduplicates
+	pending := tt.sagaRepo.Calls[len(tt.sagaRepo.Calls)-1].Arguments.Get(0).(*saga.Saga)
+	tt.sagaRepo.On("ClaimDue", mock.Anything, mock.Anything, mock.Anything).Return([]*saga.Saga{pending}, nil)
+	tt.aggregator.On("Pay", mock.Anything).Return(&billeragg.Receipt{Reference: "PAY-2"}, nil).Once()
+	tt.billRepo.On("CompleteBillPayment", mock.Anything, "PAY-2").Return(nil)
+
+	finished, err := tt.orchestrator.ResumeDue(time.Now().Add(time.Minute))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, finished)
+	assert.Equal(t, saga.StatusCompleted, pending.Status)
+	tt.billRepo.AssertNumberOfCalls(t, "ExecuteBillPayment", 1)
+	tt.billRepo.AssertExpectations(t)
+}
+
+func TestPayBill_InsufficientBalanceKeepsInquiry(t *testing.T) {
+	tt := setupBillPaymentServiceTest(t)
+	bill := tt.inquire(t, "512345678901")
+
+	tt.billRepo.On("ExecuteBillPayment", tt.accountID, 152500.0, mock.Anything).Return(fmt.Errorf("insufficient balance: have 100.00, need 152500.00"))
+	tt.auditRepo.On("Create", mock.Anything).Return(nil)
+
+	_, err := tt.svc.Pay(tt.userID, tt.payRequest(bill))
+
+	assert.Error(t, err)
+	assert.True(t, tt.mr.Exists(billInquiryKey(bill.InquiryID)), "bill should still be payable after a top-up")
+	tt.aggregator.AssertNotCalled(t, "Pay", mock.Anything)
+}
+
+func TestPayBill_InquiryOfAnotherUser(t *testing.T) {
+	tt := setupBillPaymentServiceTest(t)
+	bill := tt.inquire(t, "512345678901")
+
+	otherUser := uuid.New()
+	otherAccount := uuid.New()
+	tt.accountRepo.On("GetByID", otherAccount).Return(&domainAccount.Account{
+		ID: otherAccount, UserID: otherUser, Status: domainAccount.AccountStatusActive,
+	}, nil)
+
+	_, err := tt.svc.Pay(otherUser, &billpay.PaymentRequest{AccountID: otherAccount.String(), InquiryID: bill.InquiryID, IdempotencyKey: "bill-2"})
+
+	assert.EqualError(t, err, "inquiry not found or expired")
+	tt.billRepo.AssertNotCalled(t, "ExecuteBillPayment", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPayBill_ExpiredInquiry(t *testing.T) {
+	tt := setupBillPaymentServiceTest(t)
+	bill := tt.inquire(t, "512345678901")
+	tt.mr.FastForward(billpay.InquiryTTL)
+
+	_, err := tt.svc.Pay(tt.userID, tt.payRequest(bill))
+
+	assert.EqualError(t, err, "inquiry not found or expired")
+}
@@ -0,0 +1,316 @@
+package service
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/card"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/pkg/crypto"
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/pkg/metrics"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// homeCountry is where cards are issued; merchants elsewhere count as foreign
+const homeCountry = "ID"
+
+type CardAuthorizationService interface {
+	Authorize(req *card.AuthorizeRequest) (*card.AuthorizeResponse, error)
+	// Capture settles an approved authorization, or part of it, turning its
+	// hold into a posted purchase: a debit from the account for a debit card,
+	// or a charge on the card's balance for a credit card
+	Capture(id uuid.UUID, req *card.CaptureRequest) (*card.Authorization, error)
+}
+
+type cardAuthorizationService struct {
+	cardRepo    repository.CardRepository
+	tokenRepo   repository.CardTokenRepository
+	authRepo    repository.CardAuthorizationRepository
+	accountRepo repository.AccountRepository
+	encryptor   *crypto.Encryptor
+	limitZone   *time.Location // daily limits reset at midnight in this zone
+}
+
+func NewCardAuthorizationService(
+	cardRepo repository.CardRepository,
+	tokenRepo repository.CardTokenRepository,
+	authRepo repository.CardAuthorizationRepository,
+	accountRepo repository.AccountRepository,
+	encryptor *crypto.Encryptor,
+	limitZone *time.Location,
+) CardAuthorizationService {
+	return &cardAuthorizationService{
+		cardRepo:    cardRepo,
+		tokenRepo:   tokenRepo,
+		authRepo:    authRepo,
+		accountRepo: accountRepo,
+		encryptor:   encryptor,
+		limitZone:   limitZone,
+	}
+}
+
+// Authorize validates an acquirer's card payment request and, if every check
+// passes, places a hold on the funds. The card's daily limit covers everything
+// authorized since local midnight in the configured limit zone. Declines are returned as a normal
+// response with an ISO 8583 response code; an error means the request could
+// not be processed at all.
+func (s *cardAuthorizationService) Authorize(req *card.AuthorizeRequest) (*card.AuthorizeResponse, error) {
+	if err := req.Validate(); err != nil {
+		metrics.RecordCardAuthorization(string(req.Channel), card.ResponseInvalidTransaction)
+		return nil, err
+	}
+	resp, err := s.authorize(req)
+	if err != nil {
+		metrics.RecordCardAuthorization(string(req.Channel), card.ResponseDoNotHonor)
+		return nil, err
+	}
+
+	metrics.RecordCardAuthorization(string(req.Channel), resp.ResponseCode)
+	return resp, nil
+}
+
+func (s *cardAuthorizationService) authorize(req *card.AuthorizeRequest) (*card.AuthorizeResponse, error) {
+	c, token, err := s.resolveCard(req.CardNumber)
+	if err != nil {
+		// Unknown cards are not recorded; there is no card to attach them to
+		return decline(card.ResponseInvalidCard, "card not found"), nil
+	}
+
+	now := time.Now()
+	a := &card.Authorization{
+		ID:                uuid.New(),
+		CardID:            c.ID,
+		AccountID:         c.AccountID,
+		Amount:            req.Amount,
+		Currency:          strings.ToUpper(req.Currency),
+		MerchantName:      req.MerchantName,
+		MerchantCategory:  req.MerchantCategory,
+		Channel:           req.Channel,
+		AcquirerReference: req.AcquirerReference,
+	}
+	if token != nil {
+		a.TokenID = &token.ID
+	}
+
+	if code, reason, err := s.check(c, token, req, now); err != nil {
+		return nil, err
+	} else if code != card.ResponseApproved {
+		return s.recordDecline(a, code, reason), nil
+	}
+
+	expiresAt := now.Add(card.HoldDuration)
+	a.Status = card.AuthorizationStatusApproved
+	a.ResponseCode = card.ResponseApproved
+	a.AuthCode = fmt.Sprintf("%06d", crypto.GenerateSecureRandomInt(1000000))
+	a.ExpiresAt = &expiresAt
+
+	outcome, err := s.authRepo.PlaceHold(a, c.CardType, c.DailyLimit, card.SpendDayStart(now, s.limitZone))
+	if err != nil {
+		return nil, err
+	}
+	switch outcome {
+	case card.HoldDailyLimitExceeded:
+		a.AuthCode = ""
+		a.ExpiresAt = nil
+		return s.recordDecline(a, card.ResponseExceedsLimit, "daily limit exceeded"), nil
+	case card.HoldInsufficientFunds:
+		a.AuthCode = ""
+		a.ExpiresAt = nil
+		return s.recordDecline(a, card.ResponseInsufficientFunds, "insufficient funds"), nil
+	}
+
+	return &card.AuthorizeResponse{
+		Approved:        true,
+		ResponseCode:    card.ResponseApproved,
+		AuthCode:        a.AuthCode,
+		AuthorizationID: &a.ID,
+		ExpiresAt:       a.ExpiresAt,
+	}, nil
+}
+
+func (s *cardAuthorizationService) Capture(id uuid.UUID, req *card.CaptureRequest) (*card.Authorization, error) {
+	a, err := s.authRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := req.Amount
+	if amount == 0 {
+		amount = a.Amount
+	}
+
+	txn := &transaction.Transaction{
+		ID:              uuid.New(),
+		IdempotencyKey:  "card-capture-" + id.String(), // an authorization is captured at most once
+		TransactionType: transaction.TransactionTypeWithdrawal,
+		Status:          transaction.TransactionStatusCompleted,
+		Description:     "Card payment at " + a.MerchantName,
+		Metadata: map[string]interface{}{
+			"authorization_id":       id.String(),
+			"card_id":                a.CardID.String(),
+			"auth_code":              a.AuthCode,
+			"merchant_name":          a.MerchantName,
+			"merchant_category_code": a.MerchantCategory,
+		},
+	}
+	if err := s.authRepo.Capture(id, amount, txn); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Card authorization captured",
+		zap.String("authorization_id", id.String()),
+		zap.String("card_id", a.CardID.String()),
+		zap.Float64("amount", amount),
+	)
+
+	return s.authRepo.GetByID(id)
+}
+
+// resolveCard finds the card for a presented number, which is either the card
+// number itself or a wallet token standing in for it
+func (s *cardAuthorizationService) resolveCard(number string) (*card.Card, *card.Token, error) {
+	hash := s.encryptor.Fingerprint(number)
+	if c, err := s.cardRepo.GetByNumberHash(hash); err == nil {
+		return c, nil, nil
+	}
+
+	token, err := s.tokenRepo.GetByNumberHash(hash)
+	if err != nil {
+		return nil, nil, err
+	}
+	c, err := s.cardRepo.GetByID(token.CardID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c, token, nil
+}
+
+// check runs the card-level checks in the order an issuer would, returning
+// the first failing response code and reason. Wallet tokens carry their own
+// status and expiry and are not checked against the card's CVV.
+func (s *cardAuthorizationService) check(c *card.Card, token *card.Token, req *card.AuthorizeRequest, now time.Time) (string, string, error) {
+	if token != nil {
+		if token.Status != card.TokenStatusActive {
+			return card.ResponseRestrictedCard, fmt.Sprintf("wallet token is %s", token.Status), nil
+		}
+		if req.ExpiryMonth != token.ExpiryMonth || req.ExpiryYear != token.ExpiryYear {
+			return card.ResponseInvalidCard, "invalid card details", nil
+		}
+		if token.IsExpired(now) {
+			return card.ResponseExpiredCard, "wallet token expired", nil
+		}
+		return s.checkCard(c, req)
+	}
+
+	if req.Channel == card.ChannelEcommerce && req.CVV == "" {
+		return card.ResponseInvalidCard, "CVV is required for ecommerce payments", nil
+	}
+	if req.CVV != "" {
+		cvv, err := s.encryptor.Decrypt(c.CVVEncrypted)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to decrypt CVV: %w", err)
+		}
+		if subtle.ConstantTimeCompare([]byte(cvv), []byte(req.CVV)) != 1 {
+			return card.ResponseInvalidCard, "invalid card details", nil
+		}
+	}
+
+	if req.ExpiryMonth != c.ExpiryMonth || req.ExpiryYear != c.ExpiryYear {
+		return card.ResponseInvalidCard, "invalid card details", nil
+	}
+	if c.IsExpired(now) {
+		return card.ResponseExpiredCard, "card expired", nil
+	}
+
+	return s.checkCard(c, req)
+}
+
+// checkCard runs the checks shared by card and token payments: card and
+// account status, spending controls, PIN and the single-payment limit
+func (s *cardAuthorizationService) checkCard(c *card.Card, req *card.AuthorizeRequest) (string, string, error) {
+	if c.Status != card.CardStatusActive {
+		return card.ResponseRestrictedCard, fmt.Sprintf("card is %s", c.Status), nil
+	}
+
+	acc, err := s.accountRepo.GetByID(c.AccountID)
+	if err != nil {
+		return "", "", fmt.Errorf("account not found")
+	}
+	if acc.Status != account.AccountStatusActive {
+		return card.ResponseRestrictedCard, fmt.Sprintf("account is %s", acc.Status), nil
+	}
+	if !strings.EqualFold(acc.Currency, req.Currency) {
+		return card.ResponseInvalidTransaction, "currency not supported", nil
+	}
+
+	foreign := !strings.EqualFold(req.MerchantCountry, homeCountry)
+	if err := c.Controls.Check(req.Channel, req.MerchantCategory, foreign); err != nil {
+		return card.ResponseNotPermitted, err.Error(), nil
+	}
+
+	if req.Channel == card.ChannelATM && req.PIN == "" {
+		return card.ResponseIncorrectPIN, "PIN is required for ATM withdrawals", nil
+	}
+	if req.PIN != "" {
+		if !c.HasPIN() {
+			return card.ResponseIncorrectPIN, "PIN not set", nil
+		}
+		result, err := checkCardPIN(s.cardRepo, s.encryptor, c, req.PIN)
+		if err != nil {
+			return "", "", err
+		}
+		if result.LockedUntil != nil {
+			return card.ResponsePINTriesExceeded, "PIN tries exceeded", nil
+		}
+		if !result.Valid {
+			return card.ResponseIncorrectPIN, "incorrect PIN", nil
+		}
+	}
+
+	// A single payment above the limit can be declined without touching the
+	// ledger; cumulative spend is checked when the hold is placed
+	if req.Amount > c.DailyLimit {
+		return card.ResponseExceedsLimit, "daily limit exceeded", nil
+	}
+
+	return card.ResponseApproved, "", nil
+}
+
+// recordDecline stores a declined authorization for the card's history and
+// builds the acquirer response. Failing to store it must not turn a decline
+// into an error, so the failure is only logged.
+func (s *cardAuthorizationService) recordDecline(a *card.Authorization, code, reason string) *card.AuthorizeResponse {
+	a.Status = card.AuthorizationStatusDeclined
+	a.ResponseCode = code
+	a.DeclineReason = reason
+
+	if err := s.authRepo.Create(a); err != nil {
+		logger.Error("Failed to record declined card authorization",
+			zap.String("card_id", a.CardID.String()),
+			zap.Error(err),
+		)
+		errtrack.CaptureError(err, map[string]string{
+			"component": "card_authorization_service",
+			"operation": "record_decline",
+		})
+	}
+
+	resp := decline(code, reason)
+	resp.AuthorizationID = &a.ID
+	return resp
+}
+
+func decline(code, reason string) *card.AuthorizeResponse {
+	return &card.AuthorizeResponse{
+		Approved:      false,
+		ResponseCode:  code,
+		DeclineReason: reason,
+	}
+}
@@ -0,0 +1,437 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	domainAccount "github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/card"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/pkg/crypto"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testCardNumber = "4532015112830366"
+
+var testLimitZone = time.FixedZone("WIB", 7*60*60)
+
+// MockCardRepository is a mock implementation of repository.CardRepository
+type MockCardRepository struct {
+	mock.Mock
+}
+
+func (m *MockCardRepository) Create(c *card.Card) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+func (m *MockCardRepository) CreateReplacement(originalID uuid.UUID, replacement *card.Card) error {
+	args := m.Called(originalID, replacement)
+	return args.Error(0)
+}
+
+func (m *MockCardRepository) GetByID(id uuid.UUID) (*card.Card, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*card.Card), args.Error(1)
+}
+
+func (m *MockCardRepository) GetByAccountID(accountID uuid.UUID) ([]*card.Card, error) {
+	args := m.Called(accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*card.Card), args.Error(1)
+}
+
+func (m *MockCardRepository) GetByNumberHash(hash string) (*card.Card, error) {
+	args := m.Called(hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*card.Card), args.Error(1)
+}
+
+func (m *MockCardRepository) Update(id uuid.UUID, updates map[string]interface{}) error {
+	args := m.Called(id, updates)
+	return args.Error(0)
+}
+
+func (m *MockCardRepository) UpdateControls(id uuid.UUID, controls card.Controls) error {
+	args := m.Called(id, controls)
+	return args.Error(0)
+}
+
+func (m *MockCardRepository) Delete(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockCardRepository) ExpireCards(today string) (int64, error) {
+	args := m.Called(today)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockCardRepository) GenerateCardNumber() (string, error) {
+	args := m.Called()
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockCardRepository) RecordPINAttempt(id uuid.UUID, correct bool, now time.Time) (*card.PINAttempt, error) {
+	args := m.Called(id, correct, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*card.PINAttempt), args.Error(1)
+}
+
+func (m *MockCardRepository) GenerateCVV() string {
+	args := m.Called()
+	return args.String(0)
+}
+
+// MockCardAuthorizationRepository is a mock implementation
+type MockCardAuthorizationRepository struct {
+	mock.Mock
+}
+
+func (m *MockCardAuthorizationRepository) Create(a *card.Authorization) error {
+	args := m.Called(a)
+	return args.Error(0)
+}
+
+func (m *MockCardAuthorizationRepository) PlaceHold(a *card.Authorization, cardType card.CardType, dailyLimit float64, dayStart time.Time) (card.HoldOutcome, error) {
+	args := m.Called(a, cardType, dailyLimit, dayStart)
+	return args.Get(0).(card.HoldOutcome), args.Error(1)
+}
+
+func (m *MockCardAuthorizationRepository) GetByID(id uuid.UUID) (*card.Authorization, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*card.Authorization), args.Error(1)
+}
+
+func (m *MockCardAuthorizationRepository) Capture(id uuid.UUID, amount float64, txn *transaction.Transaction) error {
+	args := m.Called(id, amount, txn)
+	return args.Error(0)
+}
+
+func setupCardAuthorizationTest(t *testing.T) (*cardAuthorizationService, *MockCardRepository, *MockCardAuthorizationRepository, *MockAccountRepository, *card.Card) {
+	logger.Init("test")
+	cardRepo := new(MockCardRepository)
+	tokenRepo := new(MockCardTokenRepository)
+	authRepo := new(MockCardAuthorizationRepository)
+	accountRepo := new(MockAccountRepository)
+
+	encryptor, err := crypto.NewEncryptor("12345678901234567890123456789012")
+	assert.NoError(t, err)
+
+	cvv, err := encryptor.Encrypt("123")
+	assert.NoError(t, err)
+	pin, err := encryptor.Encrypt("4826")
+	assert.NoError(t, err)
+
+	c := &card.Card{
+		ID:             uuid.New(),
+		AccountID:      uuid.New(),
+		CardNumberHash: encryptor.Fingerprint(testCardNumber),
+		CVVEncrypted:   cvv,
+		PINEncrypted:   pin,
+		CardType:       card.CardTypeDebit,
+		ExpiryMonth:    12,
+		ExpiryYear:     time.Now().Year() + 3,
+		Status:         card.CardStatusActive,
+		DailyLimit:     5_000_000,
+		Controls:       card.DefaultControls(),
+	}
+	cardRepo.On("GetByNumberHash", c.CardNumberHash).Return(c, nil).Maybe()
+	accountRepo.On("GetByID", c.AccountID).Return(&domainAccount.Account{
+		ID:       c.AccountID,
+		Currency: "IDR",
+		Status:   domainAccount.AccountStatusActive,
+	}, nil).Maybe()
+
+	svc := NewCardAuthorizationService(cardRepo, tokenRepo, authRepo, accountRepo, encryptor, testLimitZone).(*cardAuthorizationService)
+	return svc, cardRepo, authRepo, accountRepo, c
+}
+
+func authorizeRequest(c *card.Card) *card.AuthorizeRequest {
+	return &card.AuthorizeRequest{
+		CardNumber:       testCardNumber,
+		CVV:              "123",
+		ExpiryMonth:      c.ExpiryMonth,
+		ExpiryYear:       c.ExpiryYear,
+		Amount:           150_000,
+		Currency:         "IDR",
+		Channel:          card.ChannelEcommerce,
+		MerchantName:     "Toko Buku",
+		MerchantCategory: "5942",
+		MerchantCountry:  "ID",
+	}
+}
+
+func declinedWith(code string) interface{} {
+	return mock.MatchedBy(func(a *card.Authorization) bool {
+		return a.Status == card.AuthorizationStatusDeclined && a.ResponseCode == code
+	})
+}
+
+func TestAuthorize_Approved(t *testing.T) {
+	svc, _, authRepo, _, c := setupCardAuthorizationTest(t)
+
+	authRepo.On("PlaceHold", mock.MatchedBy(func(a *card.Authorization) bool {
+		return a.Status == card.AuthorizationStatusApproved && a.CardID == c.ID && a.AccountID == c.AccountID &&
+			len(a.AuthCode) == 6 && a.ExpiresAt != nil
+	}), card.CardTypeDebit, c.DailyLimit, mock.MatchedBy(func(dayStart time.Time) bool {
+		return dayStart.Equal(card.SpendDayStart(time.Now(), testLimitZone))
+	})).Return(card.HoldPlaced, nil)
+
+	resp, err := svc.Authorize(authorizeRequest(c))
+
+	assert.NoError(t, err)
+	assert.True(t, resp.Approved)
+	assert.Equal(t, card.ResponseApproved, resp.ResponseCode)
+	assert.Len(t, resp.AuthCode, 6)
+	assert.NotNil(t, resp.AuthorizationID)
+	authRepo.AssertExpectations(t)
+}
+
+func TestAuthorize_UnknownCard(t *testing.T) {
+	svc, cardRepo, authRepo, _, c := setupCardAuthorizationTest(t)
+	req := authorizeRequest(c)
+	req.CardNumber = "4111111111111111"
+
+	cardRepo.On("GetByNumberHash", mock.Anything).Return(nil, assert.AnError)
+	svc.tokenRepo.(*MockCardTokenRepository).On("GetByNumberHash", mock.Anything).Return(nil, assert.AnError)
+
+	resp, err := svc.Authorize(req)
+
+	assert.NoError(t, err)
+	assert.False(t, resp.Approved)
+	assert.Equal(t, card.ResponseInvalidCard, resp.ResponseCode)
+	authRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestAuthorize_Declines(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *card.Card, req *card.AuthorizeRequest)
+		code   string
+	}{
+		{"wrong CVV", func(c *card.Card, req *card.AuthorizeRequest) { req.CVV = "999" }, card.ResponseInvalidCard},
+		{"missing CVV online", func(c *card.Card, req *card.AuthorizeRequest) { req.CVV = "" }, card.ResponseInvalidCard},
+		{"wrong expiry", func(c *card.Card, req *card.AuthorizeRequest) { req.ExpiryMonth = 1 }, card.ResponseInvalidCard},
+		{"expired card", func(c *card.Card, req *card.AuthorizeRequest) {
+			c.ExpiryYear = time.Now().Year() - 1
+			req.ExpiryYear = c.ExpiryYear
+		}, card.ResponseExpiredCard},
+		{"frozen card", func(c *card.Card, req *card.AuthorizeRequest) { c.Status = card.CardStatusFrozen }, card.ResponseRestrictedCard},
+		{"currency mismatch", func(c *card.Card, req *card.AuthorizeRequest) { req.Currency = "USD" }, card.ResponseInvalidTransaction},
+		{"ecommerce disabled", func(c *card.Card, req *card.AuthorizeRequest) { c.Controls.EcommerceEnabled = false }, card.ResponseNotPermitted},
+		{"foreign disabled", func(c *card.Card, req *card.AuthorizeRequest) {
+			c.Controls.ForeignEnabled = false
+			req.MerchantCountry = "SG"
+		}, card.ResponseNotPermitted},
+		{"single payment over daily limit", func(c *card.Card, req *card.AuthorizeRequest) { req.Amount = c.DailyLimit + 1 }, card.ResponseExceedsLimit},
+		{"ATM without PIN", func(c *card.Card, req *card.AuthorizeRequest) {
+			req.Channel = card.ChannelATM
+			req.CVV = ""
+		}, card.ResponseIncorrectPIN},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _, authRepo, _, c := setupCardAuthorizationTest(t)
+			req := authorizeRequest(c)
+			tt.modify(c, req)
+
+			authRepo.On("Create", declinedWith(tt.code)).Return(nil)
+
+			resp, err := svc.Authorize(req)
+
+			assert.NoError(t, err)
+			assert.False(t, resp.Approved)
+			assert.Equal(t, tt.code, resp.ResponseCode)
+			authRepo.AssertExpectations(t)
+			authRepo.AssertNotCalled(t, "PlaceHold", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestAuthorize_IncorrectPINCountsAttempt(t *testing.T) {
+	svc, cardRepo, authRepo, _, c := setupCardAuthorizationTest(t)
+	req := authorizeRequest(c)
+	req.Channel = card.ChannelPOS
+	req.PIN = "1111"
+
+	cardRepo.On("RecordPINAttempt", c.ID, false, mock.Anything).Return(&card.PINAttempt{FailedAttempts: 1}, nil)
+	authRepo.On("Create", declinedWith(card.ResponseIncorrectPIN)).Return(nil)
+
+	resp, err := svc.Authorize(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, card.ResponseIncorrectPIN, resp.ResponseCode)
+	cardRepo.AssertExpectations(t)
+}
+
+func TestAuthorize_PINLocked(t *testing.T) {
+	svc, _, authRepo, _, c := setupCardAuthorizationTest(t)
+	lockedUntil := time.Now().Add(time.Hour)
+	c.PINLockedUntil = &lockedUntil
+	req := authorizeRequest(c)
+	req.PIN = "4826"
+
+	authRepo.On("Create", declinedWith(card.ResponsePINTriesExceeded)).Return(nil)
+
+	resp, err := svc.Authorize(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, card.ResponsePINTriesExceeded, resp.ResponseCode)
+}
+
+func TestAuthorize_DailyLimitExceeded(t *testing.T) {
+	svc, _, authRepo, _, c := setupCardAuthorizationTest(t)
+
+	authRepo.On("PlaceHold", mock.Anything, card.CardTypeDebit, c.DailyLimit, mock.Anything).Return(card.HoldDailyLimitExceeded, nil)
+	authRepo.On("Create", mock.MatchedBy(func(a *card.Authorization) bool {
+		return a.ResponseCode == card.ResponseExceedsLimit && a.AuthCode == "" && a.ExpiresAt == nil
+	})).Return(nil)
+
+	resp, err := svc.Authorize(authorizeRequest(c))
+
+	assert.NoError(t, err)
+	assert.False(t, resp.Approved)
+	assert.Equal(t, card.ResponseExceedsLimit, resp.ResponseCode)
+	authRepo.AssertExpectations(t)
+}
+
+func TestAuthorize_InsufficientFunds(t *testing.T) {
+	svc, _, authRepo, _, c := setupCardAuthorizationTest(t)
+
+	authRepo.On("PlaceHold", mock.Anything, card.CardTypeDebit, c.DailyLimit, mock.Anything).Return(card.HoldInsufficientFunds, nil)
+	authRepo.On("Create", mock.MatchedBy(func(a *card.Authorization) bool {
+		return a.ResponseCode == card.ResponseInsufficientFunds && a.AuthCode == "" && a.ExpiresAt == nil
+	})).Return(nil)
+
+	resp, err := svc.Authorize(authorizeRequest(c))
+
+	assert.NoError(t, err)
+	assert.False(t, resp.Approved)
+	assert.Equal(t, card.ResponseInsufficientFunds, resp.ResponseCode)
+	assert.Empty(t, resp.AuthCode)
+}
+
+func TestAuthorize_DeclineRecordFailureStillDeclines(t *testing.T) {
+	svc, _, authRepo, _, c := setupCardAuthorizationTest(t)
+	c.Status = card.CardStatusBlocked
+
+	authRepo.On("Create", mock.Anything).Return(assert.AnError)
+
+	resp, err := svc.Authorize(authorizeRequest(c))
+
+	assert.NoError(t, err)
+	assert.Equal(t, card.ResponseRestrictedCard, resp.ResponseCode)
+}
+
+const testTokenNumber = "4895371234567897"
+
+// setupWalletToken registers an active wallet token for the card
+func setupWalletToken(svc *cardAuthorizationService, cardRepo *MockCardRepository, c *card.Card) *card.Token {
+	tok := &card.Token{
+		ID:              uuid.New(),
+		CardID:          c.ID,
+		TokenNumberHash: svc.encryptor.Fingerprint(testTokenNumber),
+		Status:          card.TokenStatusActive,
+		ExpiryMonth:     6,
+		ExpiryYear:      c.ExpiryYear + 1,
+	}
+	cardRepo.On("GetByNumberHash", tok.TokenNumberHash).Return(nil, assert.AnError)
+	cardRepo.On("GetByID", c.ID).Return(c, nil)
+	svc.tokenRepo.(*MockCardTokenRepository).On("GetByNumberHash", tok.TokenNumberHash).Return(tok, nil)
+	return tok
+}
+
+func TestAuthorize_WalletToken(t *testing.T) {
+	svc, cardRepo, authRepo, _, c := setupCardAuthorizationTest(t)
+	tok := setupWalletToken(svc, cardRepo, c)
+
+	req := authorizeRequest(c)
+	req.CardNumber = testTokenNumber
+	req.CVV = "" // wallets do not send a CVV
+	req.ExpiryMonth = tok.ExpiryMonth
+	req.ExpiryYear = tok.ExpiryYear
+
+	authRepo.On("PlaceHold", mock.MatchedBy(func(a *card.Authorization) bool {
+		return a.CardID == c.ID && a.TokenID != nil && *a.TokenID == tok.ID
+	}), card.CardTypeDebit, c.DailyLimit, mock.Anything).Return(card.HoldPlaced, nil)
+
+	resp, err := svc.Authorize(req)
+
+	assert.NoError(t, err)
+	assert.True(t, resp.Approved)
+	authRepo.AssertExpectations(t)
+}
+
+func TestAuthorize_SuspendedWalletToken(t *testing.T) {
+	svc, cardRepo, authRepo, _, c := setupCardAuthorizationTest(t)
+	tok := setupWalletToken(svc, cardRepo, c)
+	tok.Status = card.TokenStatusSuspended
+
+	req := authorizeRequest(c)
+	req.CardNumber = testTokenNumber
+	req.ExpiryMonth = tok.ExpiryMonth
+	req.ExpiryYear = tok.ExpiryYear
+
+	authRepo.On("Create", mock.MatchedBy(func(a *card.Authorization) bool {
+		return a.ResponseCode == card.ResponseRestrictedCard && a.TokenID != nil
+	})).Return(nil)
+
+	resp, err := svc.Authorize(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, card.ResponseRestrictedCard, resp.ResponseCode)
+	authRepo.AssertExpectations(t)
+}
+
+func TestCapture_WholeAuthorization(t *testing.T) {
+	svc, _, authRepo, _, c := setupCardAuthorizationTest(t)
+	a := &card.Authorization{ID: uuid.New(), CardID: c.ID, AccountID: c.AccountID, Amount: 150_000, AuthCode: "482913",
+		MerchantName: "Toko Buku", Status: card.AuthorizationStatusApproved}
+	captured := *a
+	captured.Status = card.AuthorizationStatusCaptured
+
+	authRepo.On("GetByID", a.ID).Return(a, nil).Once()
+	authRepo.On("Capture", a.ID, 150_000.0, mock.MatchedBy(func(txn *transaction.Transaction) bool {
+		return txn.IdempotencyKey == "card-capture-"+a.ID.String() &&
+			txn.TransactionType == transaction.TransactionTypeWithdrawal &&
+			txn.Description == "Card payment at Toko Buku" &&
+			txn.Metadata["auth_code"] == "482913"
+	})).Return(nil)
+	authRepo.On("GetByID", a.ID).Return(&captured, nil).Once()
+
+	got, err := svc.Capture(a.ID, &card.CaptureRequest{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, card.AuthorizationStatusCaptured, got.Status)
+	authRepo.AssertExpectations(t)
+}
+
+func TestCapture_NotOpen(t *testing.T) {
+	svc, _, authRepo, _, c := setupCardAuthorizationTest(t)
+	a := &card.Authorization{ID: uuid.New(), CardID: c.ID, Amount: 150_000, Status: card.AuthorizationStatusCaptured}
+
+	authRepo.On("GetByID", a.ID).Return(a, nil)
+	authRepo.On("Capture", a.ID, 50_000.0, mock.Anything).Return(card.ErrAuthorizationNotOpen)
+
+	got, err := svc.Capture(a.ID, &card.CaptureRequest{Amount: 50_000})
+
+	assert.ErrorIs(t, err, card.ErrAuthorizationNotOpen)
+	assert.Nil(t, got)
+}
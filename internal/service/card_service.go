@@ -1,42 +1,121 @@
 package service
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
 	"fmt"
 	"time"
 
+	"github.com/darisadam/madabank-server/internal/domain/audit"
 	"github.com/darisadam/madabank-server/internal/domain/card"
+	"github.com/darisadam/madabank-server/internal/domain/user"
 	"github.com/darisadam/madabank-server/internal/pkg/crypto"
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/pkg/notifier"
 	"github.com/darisadam/madabank-server/internal/repository"
 	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
 )
 
+// CardLimits caps how many live (active or frozen) cards an account may hold,
+// in total and per card type. A zero or missing value means no limit.
+type CardLimits struct {
+	MaxPerAccount int
+	MaxPerType    map[card.CardType]int
+}
+
+// DefaultCardLimits allows a physical and a virtual debit card plus one credit card
+func DefaultCardLimits() CardLimits {
+	return CardLimits{
+		MaxPerAccount: 3,
+		MaxPerType: map[card.CardType]int{
+			card.CardTypeDebit:  2,
+			card.CardTypeCredit: 1,
+		},
+	}
+}
+
 type CardService interface {
 	CreateCard(userID uuid.UUID, req *card.CreateCardRequest) (*card.CardResponse, error)
 	GetUserCards(userID uuid.UUID, accountID uuid.UUID) ([]*card.CardResponse, error)
-	GetCardDetails(userID uuid.UUID, cardID uuid.UUID, password string) (*card.CardDetailsResponse, error)
+	RequestRevealChallenge(userID uuid.UUID, cardID uuid.UUID, password string) (*card.RevealChallengeResponse, error)
+	VerifyRevealChallenge(userID uuid.UUID, cardID uuid.UUID, req *card.RevealVerifyRequest) (*card.RevealTokenResponse, error)
+	GetCardDetails(userID uuid.UUID, cardID uuid.UUID, revealToken string) (*card.CardDetailsResponse, error)
 	UpdateCard(userID uuid.UUID, cardID uuid.UUID, req *card.UpdateCardRequest) (*card.CardResponse, error)
 	BlockCard(userID uuid.UUID, cardID uuid.UUID) error
+	FreezeCard(userID uuid.UUID, cardID uuid.UUID) error
+	UnfreezeCard(userID uuid.UUID, cardID uuid.UUID) error
+	ReissueCard(userID uuid.UUID, cardID uuid.UUID, req *card.ReissueCardRequest) (*card.CardResponse, error)
 	DeleteCard(userID uuid.UUID, cardID uuid.UUID) error
+	UpdateControls(userID uuid.UUID, cardID uuid.UUID, req *card.UpdateControlsRequest) (*card.CardResponse, error)
+	RotateCVV(userID uuid.UUID, cardID uuid.UUID, req *card.RotateCVVRequest) error
+	SetPIN(userID uuid.UUID, cardID uuid.UUID, req *card.SetPINRequest) error
+	VerifyPIN(userID uuid.UUID, cardID uuid.UUID, pin string) (*card.VerifyPINResponse, error)
+	// ExpireCards expires every card past its expiry month as of now and
+	// returns how many it expired
+	ExpireCards(now time.Time) (int, error)
 }
 
+// verifyRevealScript checks a code hash against the reveal challenge under
+// KEYS[1] and consumes the challenge in the same step, so concurrent guesses
+// cannot slip past the attempt limit and a code cannot be spent twice.
+// ARGV is the user ID, card ID, code hash and attempt limit. It returns -1
+// if no challenge is stored for that user and card, 0 on a match, -2 once
+// the limit is reached, and otherwise the number of wrong codes so far.
+var verifyRevealScript = redis.NewScript(`
+local challenge = redis.call("HMGET", KEYS[1], "user_id", "card_id", "hash")
+if challenge[1] ~= ARGV[1] or challenge[2] ~= ARGV[2] then
+	return -1
+end
+if challenge[3] == ARGV[3] then
+	redis.call("DEL", KEYS[1])
+	return 0
+end
+local attempts = redis.call("HINCRBY", KEYS[1], "attempts", 1)
+if attempts >= tonumber(ARGV[4]) then
+	redis.call("DEL", KEYS[1])
+	return -2
+end
+return attempts
+`)
+
 type cardService struct {
 	cardRepo    repository.CardRepository
 	accountRepo repository.AccountRepository
 	userRepo    repository.UserRepository
+	auditRepo   repository.AuditRepository
+	redisClient *redis.Client
 	encryptor   *crypto.Encryptor
+	notifier    notifier.Notifier
+	smsSender   notifier.SMSSender
+	limits      CardLimits
 }
 
 func NewCardService(
 	cardRepo repository.CardRepository,
 	accountRepo repository.AccountRepository,
 	userRepo repository.UserRepository,
+	auditRepo repository.AuditRepository,
+	redisClient *redis.Client,
 	encryptor *crypto.Encryptor,
+	emailNotifier notifier.Notifier,
+	smsSender notifier.SMSSender,
+	limits CardLimits,
 ) CardService {
 	return &cardService{
 		cardRepo:    cardRepo,
 		accountRepo: accountRepo,
 		userRepo:    userRepo,
+		auditRepo:   auditRepo,
+		redisClient: redisClient,
 		encryptor:   encryptor,
+		notifier:    emailNotifier,
+		smsSender:   smsSender,
+		limits:      limits,
 	}
 }
 
@@ -56,16 +135,67 @@ func (s *cardService) CreateCard(userID uuid.UUID, req *card.CreateCardRequest)
 		return nil, fmt.Errorf("unauthorized: account does not belong to user")
 	}
 
-	// Check one card per account limit
 	existingCards, err := s.cardRepo.GetByAccountID(accountID)
-	if err == nil && len(existingCards) >= 1 {
-		return nil, fmt.Errorf("each account can only have one debit card")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkCardLimits(existingCards, card.CardType(req.CardType)); err != nil {
+		return nil, err
+	}
+
+	cardType := card.CardType(req.CardType)
+	if cardType == card.CardTypeCredit && req.CreditLimit <= 0 {
+		return nil, fmt.Errorf("credit_limit is required for credit cards")
+	}
+
+	newCard, cardNumber, err := s.newCard(accountID, req.CardHolderName, cardType, req.DailyLimit, card.DefaultControls())
+	if err != nil {
+		return nil, err
+	}
+	if cardType == card.CardTypeCredit {
+		newCard.CreditLimit = req.CreditLimit
+	}
+
+	if err := s.cardRepo.Create(newCard); err != nil {
+		return nil, fmt.Errorf("failed to create card: %w", err)
+	}
+
+	return s.toCardResponse(newCard, cardNumber), nil
+}
+
+// checkCardLimits rejects a new card of cardType when the account already holds
+// the configured maximum. Blocked and expired cards do not count.
+func (s *cardService) checkCardLimits(existing []*card.Card, cardType card.CardType) error {
+	total, ofType := 0, 0
+	for _, c := range existing {
+		if c.Status != card.CardStatusActive && c.Status != card.CardStatusFrozen {
+			continue
+		}
+		total++
+		if c.CardType == cardType {
+			ofType++
+		}
+	}
+
+	if s.limits.MaxPerAccount > 0 && total >= s.limits.MaxPerAccount {
+		return fmt.Errorf("account already has the maximum of %d cards", s.limits.MaxPerAccount)
 	}
 
+	if max := s.limits.MaxPerType[cardType]; max > 0 && ofType >= max {
+		return fmt.Errorf("account already has the maximum of %d %s cards", max, cardType)
+	}
+
+	return nil
+}
+
+// newCard builds an active card with a fresh number, CVV and a 3 year expiry.
+// The plain card number is returned for building the response.
+func (s *cardService) newCard(accountID uuid.UUID, holderName string, cardType card.CardType, dailyLimit float64, controls card.Controls) (*card.Card, string, error) {
 	// Generate card number and CVV
 	cardNumber, err := s.cardRepo.GenerateCardNumber()
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate card number: %w", err)
+		return nil, "", fmt.Errorf("failed to generate card number: %w", err)
 	}
 
 	cvv := s.cardRepo.GenerateCVV()
@@ -73,37 +203,33 @@ func (s *cardService) CreateCard(userID uuid.UUID, req *card.CreateCardRequest)
 	// Encrypt sensitive data
 	encryptedCardNumber, err := s.encryptor.Encrypt(cardNumber)
 	if err != nil {
-		return nil, fmt.Errorf("failed to encrypt card number: %w", err)
+		return nil, "", fmt.Errorf("failed to encrypt card number: %w", err)
 	}
 
 	encryptedCVV, err := s.encryptor.Encrypt(cvv)
 	if err != nil {
-		return nil, fmt.Errorf("failed to encrypt CVV: %w", err)
+		return nil, "", fmt.Errorf("failed to encrypt CVV: %w", err)
 	}
 
 	// Set expiry date (3 years from now)
 	now := time.Now()
 	expiryDate := now.AddDate(3, 0, 0)
 
-	// Create card
-	newCard := &card.Card{
+	return &card.Card{
 		ID:                  uuid.New(),
 		AccountID:           accountID,
 		CardNumberEncrypted: encryptedCardNumber,
+		CardNumberHash:      s.encryptor.Fingerprint(cardNumber),
 		CVVEncrypted:        encryptedCVV,
-		CardHolderName:      req.CardHolderName,
-		CardType:            card.CardType(req.CardType),
+		CardHolderName:      holderName,
+		CardType:            cardType,
 		ExpiryMonth:         int(expiryDate.Month()),
 		ExpiryYear:          expiryDate.Year(),
 		Status:              card.CardStatusActive,
-		DailyLimit:          req.DailyLimit,
-	}
-
-	if err := s.cardRepo.Create(newCard); err != nil {
-		return nil, fmt.Errorf("failed to create card: %w", err)
-	}
-
-	return s.toCardResponse(newCard, cardNumber), nil
+		DailyLimit:          dailyLimit,
+		Controls:            controls,
+		StatementDay:        card.StatementDayFor(now),
+	}, cardNumber, nil
 }
 
 func (s *cardService) GetUserCards(userID uuid.UUID, accountID uuid.UUID) ([]*card.CardResponse, error) {
@@ -135,31 +261,152 @@ func (s *cardService) GetUserCards(userID uuid.UUID, accountID uuid.UUID) ([]*ca
 	return responses, nil
 }
 
-func (s *cardService) GetCardDetails(userID uuid.UUID, cardID uuid.UUID, password string) (*card.CardDetailsResponse, error) {
-	// Verify user password for additional security
-	user, err := s.userRepo.GetByID(userID)
+// RequestRevealChallenge starts the step-up flow for revealing card details.
+// After checking the password it sends a one-time code to the user and
+// returns the challenge it belongs to.
+func (s *cardService) RequestRevealChallenge(userID uuid.UUID, cardID uuid.UUID, password string) (*card.RevealChallengeResponse, error) {
+	if _, err := s.getOwnedCard(userID, cardID); err != nil {
+		return nil, err
+	}
+
+	u, err := s.userRepo.GetByID(userID)
 	if err != nil {
 		return nil, fmt.Errorf("user not found")
 	}
 
-	if !crypto.CheckPassword(password, user.PasswordHash) {
+	if !crypto.CheckPassword(password, u.PasswordHash) {
+		s.recordFailedAudit(userID, "CARD_REVEAL_CHALLENGE", cardID, map[string]interface{}{"reason": "invalid password"})
 		return nil, fmt.Errorf("invalid password")
 	}
 
-	// Get card
-	c, err := s.cardRepo.GetByID(cardID)
+	ctx := context.Background()
+
+	// One challenge per card per minute keeps the OTP from being farmed
+	rateLimitKey := fmt.Sprintf("rate_limit:card_reveal:%s", cardID)
+	allowed, err := s.redisClient.SetNX(ctx, rateLimitKey, "1", card.RevealChallengeDelay).Result()
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("redis error: %w", err)
+	}
+	if !allowed {
+		return nil, fmt.Errorf("please wait before requesting a new code")
 	}
 
-	// Verify ownership
-	account, err := s.accountRepo.GetByID(c.AccountID)
+	challengeID := uuid.New().String()
+	otp := fmt.Sprintf("%06d", crypto.GenerateSecureRandomInt(1000000))
+
+	// Only a keyed hash of the code is stored, never the code
+	challengeKey := revealChallengeKey(challengeID)
+	if err := s.redisClient.HSet(ctx, challengeKey, map[string]interface{}{
+		"user_id":  userID.String(),
+		"card_id":  cardID.String(),
+		"hash":     s.revealOTPHash(challengeKey, otp),
+		"attempts": 0,
+	}).Err(); err != nil {
+		return nil, fmt.Errorf("failed to store reveal challenge: %w", err)
+	}
+	if err := s.redisClient.Expire(ctx, challengeKey, card.RevealOTPTTL).Err(); err != nil {
+		return nil, fmt.Errorf("failed to store reveal challenge: %w", err)
+	}
+
+	// Deliver it the same way as other step-up codes. On failure the
+	// customer may ask again straight away.
+	channel, err := s.sendRevealOTP(ctx, u, otp)
 	if err != nil {
-		return nil, fmt.Errorf("account not found")
+		s.redisClient.Del(ctx, challengeKey, rateLimitKey)
+		logger.Error("Failed to send card reveal OTP", zap.String("card_id", cardID.String()), zap.Error(err))
+		return nil, fmt.Errorf("failed to send OTP, please try again")
 	}
 
-	if account.UserID != userID {
-		return nil, fmt.Errorf("unauthorized: card does not belong to user")
+	// The code itself is never logged
+	logger.Info("🔑 Card reveal OTP sent",
+		zap.String("card_id", cardID.String()),
+		zap.String("channel", string(channel)),
+	)
+
+	return &card.RevealChallengeResponse{
+		ChallengeID: challengeID,
+		Channel:     channel,
+		ExpiresAt:   time.Now().Add(card.RevealOTPTTL),
+	}, nil
+}
+
+// revealOTPHash binds a code to the challenge it was issued for
+func (s *cardService) revealOTPHash(challengeKey, otp string) string {
+	return s.encryptor.Fingerprint(challengeKey + ":" + otp)
+}
+
+// sendRevealOTP texts the code to a verified phone, or emails it otherwise,
+// and returns the channel used
+func (s *cardService) sendRevealOTP(ctx context.Context, u *user.User, otp string) (user.OTPChannel, error) {
+	channel, err := u.OTPChannelFor("")
+	if err != nil {
+		return "", err
+	}
+	text := fmt.Sprintf("Your MadaBank code to show your card details is %s. It expires in %d minutes. Never share it with anyone, including MadaBank staff.",
+		otp, int(card.RevealOTPTTL.Minutes()))
+	if channel == user.OTPChannelSMS {
+		return channel, s.smsSender.SendSMS(ctx, &notifier.SMS{To: *u.Phone, Body: text})
+	}
+	return channel, s.notifier.SendEmail(ctx, &notifier.Email{To: u.Email, Subject: "Your MadaBank verification code", Body: text + "\n"})
+}
+
+// VerifyRevealChallenge exchanges a correct OTP for a short-lived, single-use
+// reveal token. A challenge is discarded after card.MaxRevealOTPAttempts wrong codes.
+func (s *cardService) VerifyRevealChallenge(userID uuid.UUID, cardID uuid.UUID, req *card.RevealVerifyRequest) (*card.RevealTokenResponse, error) {
+	ctx := context.Background()
+	challengeKey := revealChallengeKey(req.ChallengeID)
+
+	// The script consumes the challenge on a match (prevent replay)
+	result, err := verifyRevealScript.Run(ctx, s.redisClient, []string{challengeKey},
+		userID.String(), cardID.String(), s.revealOTPHash(challengeKey, req.OTP), card.MaxRevealOTPAttempts).Int()
+	if err != nil {
+		return nil, fmt.Errorf("redis error: %w", err)
+	}
+	switch result {
+	case 0:
+	case -1:
+		return nil, fmt.Errorf("invalid or expired challenge")
+	case -2:
+		s.recordFailedAudit(userID, "CARD_REVEAL_VERIFY", cardID, map[string]interface{}{"reason": "invalid OTP", "attempts": card.MaxRevealOTPAttempts})
+		return nil, fmt.Errorf("too many incorrect codes, request a new one")
+	default:
+		s.recordFailedAudit(userID, "CARD_REVEAL_VERIFY", cardID, map[string]interface{}{"reason": "invalid OTP", "attempts": result})
+		return nil, fmt.Errorf("invalid OTP code")
+	}
+
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return nil, fmt.Errorf("failed to generate reveal token: %w", err)
+	}
+	token := hex.EncodeToString(tokenBytes)
+
+	if err := s.redisClient.Set(ctx, revealTokenKey(token), userID.String()+":"+cardID.String(), card.RevealTokenTTL).Err(); err != nil {
+		return nil, fmt.Errorf("failed to store reveal token: %w", err)
+	}
+
+	return &card.RevealTokenResponse{
+		RevealToken: token,
+		ExpiresAt:   time.Now().Add(card.RevealTokenTTL),
+	}, nil
+}
+
+// GetCardDetails returns the decrypted card number and CVV. It consumes a
+// reveal token issued for this user and card; every reveal is audited.
+func (s *cardService) GetCardDetails(userID uuid.UUID, cardID uuid.UUID, revealToken string) (*card.CardDetailsResponse, error) {
+	owner, err := s.redisClient.GetDel(context.Background(), revealTokenKey(revealToken)).Result()
+	if err == redis.Nil {
+		return nil, fmt.Errorf("invalid or expired reveal token")
+	} else if err != nil {
+		return nil, fmt.Errorf("redis error: %w", err)
+	}
+
+	if owner != userID.String()+":"+cardID.String() {
+		return nil, fmt.Errorf("invalid or expired reveal token")
+	}
+
+	c, err := s.getOwnedCard(userID, cardID)
+	if err != nil {
+		return nil, err
 	}
 
 	// Decrypt sensitive data
@@ -173,6 +420,8 @@ func (s *cardService) GetCardDetails(userID uuid.UUID, cardID uuid.UUID, passwor
 		return nil, fmt.Errorf("failed to decrypt CVV: %w", err)
 	}
 
+	s.recordAudit(userID, "CARD_DETAILS_REVEALED", cardID, nil)
+
 	return &card.CardDetailsResponse{
 		CardNumber:  cardNumber,
 		CVV:         cvv,
@@ -181,6 +430,14 @@ func (s *cardService) GetCardDetails(userID uuid.UUID, cardID uuid.UUID, passwor
 	}, nil
 }
 
+func revealChallengeKey(challengeID string) string {
+	return fmt.Sprintf("card_reveal:challenge:%s", challengeID)
+}
+
+func revealTokenKey(token string) string {
+	return fmt.Sprintf("card_reveal:token:%s", token)
+}
+
 func (s *cardService) UpdateCard(userID uuid.UUID, cardID uuid.UUID, req *card.UpdateCardRequest) (*card.CardResponse, error) {
 	// Get and verify ownership
 	c, err := s.cardRepo.GetByID(cardID)
@@ -201,7 +458,13 @@ func (s *cardService) UpdateCard(userID uuid.UUID, cardID uuid.UUID, req *card.U
 	updates := make(map[string]interface{})
 
 	if req.Status != nil {
-		updates["status"] = card.CardStatus(*req.Status)
+		next := card.CardStatus(*req.Status)
+		if next != c.Status {
+			if err := validateStatusTransition(c.Status, next); err != nil {
+				return nil, err
+			}
+			updates["status"] = next
+		}
 	}
 
 	if req.DailyLimit != nil {
@@ -236,6 +499,116 @@ func (s *cardService) BlockCard(userID uuid.UUID, cardID uuid.UUID) error {
 	return err
 }
 
+func (s *cardService) FreezeCard(userID uuid.UUID, cardID uuid.UUID) error {
+	return s.transitionStatus(userID, cardID, card.CardStatusActive, card.CardStatusFrozen)
+}
+
+func (s *cardService) UnfreezeCard(userID uuid.UUID, cardID uuid.UUID) error {
+	return s.transitionStatus(userID, cardID, card.CardStatusFrozen, card.CardStatusActive)
+}
+
+// ReissueCard blocks the card and issues a replacement with a new number, CVV
+// and expiry. The daily limit, spending controls and credit line carry over;
+// the PIN does not.
+func (s *cardService) ReissueCard(userID uuid.UUID, cardID uuid.UUID, req *card.ReissueCardRequest) (*card.CardResponse, error) {
+	original, err := s.getOwnedCard(userID, cardID)
+	if err != nil {
+		return nil, err
+	}
+
+	// A card is only ever replaced once; reissue the replacement instead
+	siblings, err := s.cardRepo.GetByAccountID(original.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	for _, sibling := range siblings {
+		if sibling.ReplacesCardID != nil && *sibling.ReplacesCardID == original.ID {
+			return nil, fmt.Errorf("card has already been replaced by card %s", sibling.ID)
+		}
+	}
+
+	replacement, cardNumber, err := s.newCard(original.AccountID, original.CardHolderName, original.CardType, original.DailyLimit, original.Controls)
+	if err != nil {
+		return nil, err
+	}
+
+	// A credit card's balance and billing cycle carry over to the replacement
+	replacement.CreditLimit = original.CreditLimit
+	replacement.OutstandingBalance = original.OutstandingBalance
+	replacement.StatementDay = original.StatementDay
+	replacement.CyclePurchases = original.CyclePurchases
+	replacement.CyclePayments = original.CyclePayments
+
+	if err := s.cardRepo.CreateReplacement(original.ID, replacement); err != nil {
+		return nil, fmt.Errorf("failed to reissue card: %w", err)
+	}
+
+	s.recordAudit(userID, "CARD_BLOCKED", original.ID, map[string]interface{}{
+		"reason":          req.Reason,
+		"previous_status": original.Status,
+		"replaced_by":     replacement.ID,
+	})
+	s.recordAudit(userID, "CARD_REISSUED", replacement.ID, map[string]interface{}{
+		"reason":           req.Reason,
+		"replaces_card_id": original.ID,
+	})
+
+	return s.toCardResponse(replacement, cardNumber), nil
+}
+
+// recordAudit writes a successful card audit entry. Failures are logged and do
+// not fail the operation, matching the transaction service.
+func (s *cardService) recordAudit(userID uuid.UUID, action string, cardID uuid.UUID, metadata map[string]interface{}) {
+	s.writeAudit(userID, action, "success", cardID, metadata)
+}
+
+// recordFailedAudit writes a card audit entry for a rejected attempt
+func (s *cardService) recordFailedAudit(userID uuid.UUID, action string, cardID uuid.UUID, metadata map[string]interface{}) {
+	s.writeAudit(userID, action, "failed", cardID, metadata)
+}
+
+func (s *cardService) writeAudit(userID uuid.UUID, action, status string, cardID uuid.UUID, metadata map[string]interface{}) {
+	if err := s.auditRepo.Create(&audit.AuditLog{
+		EventID:  uuid.New(),
+		UserID:   &userID,
+		Action:   action,
+		Resource: fmt.Sprintf("card:%s", cardID),
+		Status:   status,
+		Metadata: metadata,
+	}); err != nil {
+		logger.Error("Failed to create audit log for card", zap.String("action", action), zap.Error(err))
+		errtrack.CaptureError(err, map[string]string{"component": "card_service", "operation": "audit_log"})
+	}
+}
+
+// transitionStatus moves a card owned by the user from one status to another
+func (s *cardService) transitionStatus(userID uuid.UUID, cardID uuid.UUID, from, to card.CardStatus) error {
+	c, err := s.getOwnedCard(userID, cardID)
+	if err != nil {
+		return err
+	}
+
+	if c.Status != from {
+		return fmt.Errorf("card is %s, expected %s", c.Status, from)
+	}
+
+	if err := validateStatusTransition(c.Status, to); err != nil {
+		return err
+	}
+
+	return s.cardRepo.Update(cardID, map[string]interface{}{"status": to})
+}
+
+func validateStatusTransition(from, to card.CardStatus) error {
+	if from.CanTransitionTo(to) {
+		return nil
+	}
+	if from == card.CardStatusBlocked {
+		return fmt.Errorf("blocked cards cannot be reactivated, request a replacement instead")
+	}
+	return fmt.Errorf("cannot change card status from %s to %s", from, to)
+}
+
 func (s *cardService) DeleteCard(userID uuid.UUID, cardID uuid.UUID) error {
 	// Verify ownership
 	c, err := s.cardRepo.GetByID(cardID)
@@ -255,8 +628,213 @@ func (s *cardService) DeleteCard(userID uuid.UUID, cardID uuid.UUID) error {
 	return s.cardRepo.Delete(cardID)
 }
 
+func (s *cardService) ExpireCards(now time.Time) (int, error) {
+	n, err := s.cardRepo.ExpireCards(now.Format("2006-01-02"))
+	if err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
+
+func (s *cardService) UpdateControls(userID uuid.UUID, cardID uuid.UUID, req *card.UpdateControlsRequest) (*card.CardResponse, error) {
+	c, err := s.getOwnedCard(userID, cardID)
+	if err != nil {
+		return nil, err
+	}
+
+	controls := c.Controls.Apply(req)
+	if err := s.cardRepo.UpdateControls(cardID, controls); err != nil {
+		return nil, err
+	}
+	c.Controls = controls
+
+	cardNumber, _ := s.encryptor.Decrypt(c.CardNumberEncrypted)
+	return s.toCardResponse(c, cardNumber), nil
+}
+
+// RotateCVV replaces the card's CVV, e.g. after the card details may have
+// leaked. The new CVV is not returned; it is shown through the reveal flow.
+func (s *cardService) RotateCVV(userID uuid.UUID, cardID uuid.UUID, req *card.RotateCVVRequest) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return fmt.Errorf("user not found")
+	}
+
+	if !crypto.CheckPassword(req.Password, user.PasswordHash) {
+		s.recordFailedAudit(userID, "CARD_CVV_ROTATED", cardID, map[string]interface{}{"reason": "invalid password"})
+		return fmt.Errorf("invalid password")
+	}
+
+	c, err := s.getOwnedCard(userID, cardID)
+	if err != nil {
+		return err
+	}
+
+	if c.Status != card.CardStatusActive && c.Status != card.CardStatusFrozen {
+		return fmt.Errorf("card is %s", c.Status)
+	}
+
+	oldCVV, err := s.encryptor.Decrypt(c.CVVEncrypted)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt CVV: %w", err)
+	}
+
+	// Make sure the rotation actually changes the CVV
+	newCVV := s.cardRepo.GenerateCVV()
+	for newCVV == oldCVV {
+		newCVV = s.cardRepo.GenerateCVV()
+	}
+
+	encryptedCVV, err := s.encryptor.Encrypt(newCVV)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt CVV: %w", err)
+	}
+
+	if err := s.cardRepo.Update(cardID, map[string]interface{}{"cvv_encrypted": encryptedCVV}); err != nil {
+		return err
+	}
+
+	s.recordAudit(userID, "CARD_CVV_ROTATED", cardID, nil)
+	return nil
+}
+
+func (s *cardService) SetPIN(userID uuid.UUID, cardID uuid.UUID, req *card.SetPINRequest) error {
+	// Setting or changing a PIN requires the account password
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return fmt.Errorf("user not found")
+	}
+
+	if !crypto.CheckPassword(req.Password, user.PasswordHash) {
+		return fmt.Errorf("invalid password")
+	}
+
+	c, err := s.getOwnedCard(userID, cardID)
+	if err != nil {
+		return err
+	}
+
+	if c.Status != card.CardStatusActive {
+		return fmt.Errorf("card is not active")
+	}
+
+	if err := validatePIN(req.PIN); err != nil {
+		return err
+	}
+
+	encryptedPIN, err := s.encryptor.Encrypt(req.PIN)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt PIN: %w", err)
+	}
+
+	// A new PIN clears any previous lockout
+	return s.cardRepo.Update(cardID, map[string]interface{}{
+		"pin_encrypted":       encryptedPIN,
+		"pin_failed_attempts": 0,
+		"pin_locked_until":    nil,
+	})
+}
+
+func (s *cardService) VerifyPIN(userID uuid.UUID, cardID uuid.UUID, pin string) (*card.VerifyPINResponse, error) {
+	c, err := s.getOwnedCard(userID, cardID)
+	if err != nil {
+		return nil, err
+	}
+
+	return checkCardPIN(s.cardRepo, s.encryptor, c, pin)
+}
+
+// checkCardPIN compares pin against the card's stored PIN, counting failures and
+// locking the PIN after card.MaxPINAttempts consecutive wrong entries. It is
+// shared by cardholder verification and acquirer authorizations so both count
+// towards the same lock.
+func checkCardPIN(cardRepo repository.CardRepository, encryptor *crypto.Encryptor, c *card.Card, pin string) (*card.VerifyPINResponse, error) {
+	if !c.HasPIN() {
+		return nil, fmt.Errorf("PIN not set")
+	}
+
+	now := time.Now()
+	if c.PINLocked(now) {
+		return &card.VerifyPINResponse{
+			Valid:       false,
+			LockedUntil: c.PINLockedUntil,
+		}, nil
+	}
+
+	storedPIN, err := encryptor.Decrypt(c.PINEncrypted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt PIN: %w", err)
+	}
+
+	// The attempt is counted, and the lock checked, in the database rather
+	// than from c, which may have been read before a concurrent attempt
+	// locked the PIN
+	correct := subtle.ConstantTimeCompare([]byte(storedPIN), []byte(pin)) == 1
+	attempt, err := cardRepo.RecordPINAttempt(c.ID, correct, now)
+	if err != nil {
+		return nil, err
+	}
+	if attempt.LockedUntil != nil {
+		return &card.VerifyPINResponse{Valid: false, LockedUntil: attempt.LockedUntil}, nil
+	}
+
+	return &card.VerifyPINResponse{
+		Valid:             correct,
+		RemainingAttempts: card.MaxPINAttempts - attempt.FailedAttempts,
+	}, nil
+}
+
+func (s *cardService) getOwnedCard(userID uuid.UUID, cardID uuid.UUID) (*card.Card, error) {
+	return loadOwnedCard(s.cardRepo, s.accountRepo, userID, cardID)
+}
+
+// loadOwnedCard loads a card and verifies it belongs to one of the user's accounts
+func loadOwnedCard(cardRepo repository.CardRepository, accountRepo repository.AccountRepository, userID uuid.UUID, cardID uuid.UUID) (*card.Card, error) {
+	c, err := cardRepo.GetByID(cardID)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := accountRepo.GetByID(c.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("account not found")
+	}
+
+	if account.UserID != userID {
+		return nil, fmt.Errorf("unauthorized: card does not belong to user")
+	}
+
+	return c, nil
+}
+
+// validatePIN rejects PINs that are trivially guessable
+func validatePIN(pin string) error {
+	if len(pin) < 4 || len(pin) > 6 {
+		return fmt.Errorf("PIN must be 4 to 6 digits")
+	}
+
+	sameDigit, ascending, descending := true, true, true
+	for i := 0; i < len(pin); i++ {
+		if pin[i] < '0' || pin[i] > '9' {
+			return fmt.Errorf("PIN must contain only digits")
+		}
+		if i == 0 {
+			continue
+		}
+		sameDigit = sameDigit && pin[i] == pin[0]
+		ascending = ascending && pin[i] == pin[i-1]+1
+		descending = descending && pin[i] == pin[i-1]-1
+	}
+
+	if sameDigit || ascending || descending {
+		return fmt.Errorf("PIN is too easy to guess")
+	}
+
+	return nil
+}
+
 func (s *cardService) toCardResponse(c *card.Card, cardNumber string) *card.CardResponse {
-	return &card.CardResponse{
+	resp := &card.CardResponse{
 		ID:               c.ID,
 		AccountID:        c.AccountID,
 		CardNumberMasked: crypto.MaskCardNumber(cardNumber),
@@ -266,8 +844,20 @@ func (s *cardService) toCardResponse(c *card.Card, cardNumber string) *card.Card
 		ExpiryYear:       c.ExpiryYear,
 		Status:           c.Status,
 		DailyLimit:       c.DailyLimit,
+		HasPIN:           c.HasPIN(),
+		Controls:         c.Controls,
+		ReplacesCardID:   c.ReplacesCardID,
 		CreatedAt:        c.CreatedAt,
 	}
+
+	if c.CardType == card.CardTypeCredit {
+		resp.CreditLimit = c.CreditLimit
+		resp.OutstandingBalance = c.OutstandingBalance
+		resp.AvailableCredit = c.AvailableCredit()
+		resp.StatementDay = c.StatementDay
+	}
+
+	return resp
 }
 
 func stringPtr(s string) *string {
@@ -1,115 +1,168 @@
 package service
 
 import (
-	"fmt"
+	"context"
+	"errors"
+	"regexp"
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
+
 	domainAccount "github.com/darisadam/madabank-server/internal/domain/account"
 	"github.com/darisadam/madabank-server/internal/domain/card"
 	"github.com/darisadam/madabank-server/internal/domain/user"
 	"github.com/darisadam/madabank-server/internal/pkg/crypto"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/pkg/notifier"
+	"github.com/darisadam/madabank-server/internal/testutil/fakes"
 	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
-// MockCardRepository is a mock implementation of repository.CardRepository
-type MockCardRepository struct {
-	mock.Mock
-}
+// setupCardServiceTest returns a card service whose repositories are fakes
+// sharing the returned database
+func setupCardServiceTest(t *testing.T) (*cardService, *fakes.DB) {
+	db := fakes.NewDB()
 
-func (m *MockCardRepository) Create(c *card.Card) error {
-	args := m.Called(c)
-	return args.Error(0)
+	encryptor, err := crypto.NewEncryptor("12345678901234567890123456789012") // 32 bytes
+	assert.NoError(t, err)
+
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	t.Cleanup(mr.Close)
+	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	mockNotifier := new(MockNotifier)
+	mockNotifier.On("SendEmail", mock.Anything).Return(nil)
+
+	svc := NewCardService(
+		fakes.NewCardRepository(db),
+		fakes.NewAccountRepository(db),
+		fakes.NewUserRepository(db),
+		fakes.NewAuditRepository(db),
+		redisClient, encryptor, mockNotifier, new(MockSMSSender), DefaultCardLimits(),
+	).(*cardService)
+	return svc, db
 }
 
-func (m *MockCardRepository) GetByID(id uuid.UUID) (*card.Card, error) {
-	args := m.Called(id)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
+// seedCardOwner stores a user with the password "password123" and a
+// checking account of theirs
+func seedCardOwner(t *testing.T, db *fakes.DB, phone *string) (*user.User, *domainAccount.Account) {
+	t.Helper()
+	passwordHash, err := crypto.HashPassword("password123")
+	require.NoError(t, err)
+	u := &user.User{ID: uuid.New(), Email: uuid.NewString() + "@example.com", Phone: phone, PasswordHash: passwordHash, IsActive: true}
+	require.NoError(t, fakes.NewUserRepository(db).Create(u))
+
+	accounts := fakes.NewAccountRepository(db)
+	number, err := accounts.GenerateAccountNumber()
+	require.NoError(t, err)
+	acc := &domainAccount.Account{
+		ID:            uuid.New(),
+		UserID:        u.ID,
+		AccountNumber: number,
+		AccountType:   domainAccount.AccountTypeChecking,
+		Currency:      "IDR",
+		Status:        domainAccount.AccountStatusActive,
 	}
-	return args.Get(0).(*card.Card), args.Error(1)
+	require.NoError(t, accounts.Create(acc))
+	return u, acc
 }
 
-func (m *MockCardRepository) GetByAccountID(accountID uuid.UUID) ([]*card.Card, error) {
-	args := m.Called(accountID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
+// newTestCard returns an active debit card on the account with number
+// 4111111111111111 and CVV 123, for the test to adjust and store with seedCard
+func newTestCard(t *testing.T, svc *cardService, accountID uuid.UUID) *card.Card {
+	t.Helper()
+	encryptedNumber, err := svc.encryptor.Encrypt("4111111111111111")
+	require.NoError(t, err)
+	encryptedCVV, err := svc.encryptor.Encrypt("123")
+	require.NoError(t, err)
+	return &card.Card{
+		ID:                  uuid.New(),
+		AccountID:           accountID,
+		CardNumberEncrypted: encryptedNumber,
+		CVVEncrypted:        encryptedCVV,
+		CardHolderName:      "John Doe",
+		CardType:            card.CardTypeDebit,
+		ExpiryMonth:         12,
+		ExpiryYear:          2027,
+		Status:              card.CardStatusActive,
+		DailyLimit:          2500,
+		Controls:            card.DefaultControls(),
 	}
-	return args.Get(0).([]*card.Card), args.Error(1)
 }
 
-func (m *MockCardRepository) Update(id uuid.UUID, updates map[string]interface{}) error {
-	args := m.Called(id, updates)
-	return args.Error(0)
+func seedCard(t *testing.T, db *fakes.DB, c *card.Card) *card.Card {
+	t.Helper()
+	require.NoError(t, fakes.NewCardRepository(db).Create(c))
+	return c
 }
 
-func (m *MockCardRepository) Delete(id uuid.UUID) error {
-	args := m.Called(id)
-	return args.Error(0)
+func storedCard(t *testing.T, db *fakes.DB, id uuid.UUID) *card.Card {
+	t.Helper()
+	c, err := fakes.NewCardRepository(db).GetByID(id)
+	require.NoError(t, err)
+	return c
 }
 
-func (m *MockCardRepository) GenerateCardNumber() (string, error) {
-	args := m.Called()
-	return args.String(0), args.Error(1)
+// auditTrail lists the audit entries written so far as "ACTION status"
+func auditTrail(t *testing.T, db *fakes.DB) []string {
+	t.Helper()
+	logs, err := fakes.NewAuditRepository(db).ListChain(0, 100)
+	require.NoError(t, err)
+	entries := make([]string, 0, len(logs))
+	for _, l := range logs {
+		entries = append(entries, l.Action+" "+l.Status)
+	}
+	return entries
 }
 
-func (m *MockCardRepository) GenerateCVV() string {
-	args := m.Called()
-	return args.String(0)
+// scriptedCVVs hands out the given CVVs before falling back to random ones
+type scriptedCVVs struct {
+	*fakes.CardRepository
+	cvvs []string
 }
 
-func setupCardServiceTest(t *testing.T) (*cardService, *MockCardRepository, *MockAccountRepository, *MockUserRepository) {
-	cardRepo := new(MockCardRepository)
-	accountRepo := new(MockAccountRepository)
-	userRepo := new(MockUserRepository)
-
-	encryptor, err := crypto.NewEncryptor("12345678901234567890123456789012") // 32 bytes
-	assert.NoError(t, err)
-
-	svc := NewCardService(cardRepo, accountRepo, userRepo, encryptor).(*cardService)
-	return svc, cardRepo, accountRepo, userRepo
+func (r *scriptedCVVs) GenerateCVV() string {
+	if len(r.cvvs) == 0 {
+		return r.CardRepository.GenerateCVV()
+	}
+	cvv := r.cvvs[0]
+	r.cvvs = r.cvvs[1:]
+	return cvv
 }
 
 func TestCreateCard_Success(t *testing.T) {
-	svc, cardRepo, accountRepo, _ := setupCardServiceTest(t)
-	userID := uuid.New()
-	accountID := uuid.New()
+	svc, db := setupCardServiceTest(t)
+	owner, acc := seedCardOwner(t, db, nil)
 
 	req := &card.CreateCardRequest{
-		AccountID:      accountID.String(),
+		AccountID:      acc.ID.String(),
 		CardHolderName: "John Doe",
 		CardType:       "debit",
 		DailyLimit:     5000,
 	}
 
-	// Mock account ownership
-	accountRepo.On("GetByID", accountID).Return(&domainAccount.Account{
-		ID:     accountID,
-		UserID: userID,
-	}, nil)
-
-	// Mock: no existing cards for this account (allows creation)
-	cardRepo.On("GetByAccountID", accountID).Return([]*card.Card{}, nil)
-
-	// Mock card generation
-	cardRepo.On("GenerateCardNumber").Return("4111111111111111", nil)
-	cardRepo.On("GenerateCVV").Return("123")
-	cardRepo.On("Create", mock.AnythingOfType("*card.Card")).Return(nil)
-
-	resp, err := svc.CreateCard(userID, req)
+	resp, err := svc.CreateCard(owner.ID, req)
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 	assert.Equal(t, "John Doe", resp.CardHolderName)
 	assert.Equal(t, card.CardStatusActive, resp.Status)
 	assert.Contains(t, resp.CardNumberMasked, "****") // Should be masked
-	cardRepo.AssertExpectations(t)
-	accountRepo.AssertExpectations(t)
+
+	stored := storedCard(t, db, resp.ID)
+	number, err := svc.encryptor.Decrypt(stored.CardNumberEncrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.MaskCardNumber(number), resp.CardNumberMasked)
+	assert.Equal(t, svc.encryptor.Fingerprint(number), stored.CardNumberHash)
 }
 
 func TestCreateCard_InvalidAccountID(t *testing.T) {
-	svc, _, _, _ := setupCardServiceTest(t)
+	svc, _ := setupCardServiceTest(t)
 	userID := uuid.New()
 
 	req := &card.CreateCardRequest{
@@ -123,234 +176,644 @@ func TestCreateCard_InvalidAccountID(t *testing.T) {
 }
 
 func TestCreateCard_AccountNotFound(t *testing.T) {
-	svc, _, accountRepo, _ := setupCardServiceTest(t)
-	userID := uuid.New()
-	accountID := uuid.New()
-
-	req := &card.CreateCardRequest{AccountID: accountID.String()}
+	svc, _ := setupCardServiceTest(t)
 
-	accountRepo.On("GetByID", accountID).Return(nil, fmt.Errorf("not found"))
+	req := &card.CreateCardRequest{AccountID: uuid.NewString()}
 
-	resp, err := svc.CreateCard(userID, req)
+	resp, err := svc.CreateCard(uuid.New(), req)
 	assert.Error(t, err)
 	assert.Nil(t, resp)
 	assert.Contains(t, err.Error(), "account not found")
 }
 
 func TestCreateCard_Unauthorized(t *testing.T) {
-	svc, _, accountRepo, _ := setupCardServiceTest(t)
-	userID := uuid.New()
-	otherUserID := uuid.New()
-	accountID := uuid.New()
-
-	req := &card.CreateCardRequest{AccountID: accountID.String()}
-
+	svc, db := setupCardServiceTest(t)
 	// Account belongs to another user
-	accountRepo.On("GetByID", accountID).Return(&domainAccount.Account{
-		ID:     accountID,
-		UserID: otherUserID,
-	}, nil)
+	_, acc := seedCardOwner(t, db, nil)
 
-	resp, err := svc.CreateCard(userID, req)
+	req := &card.CreateCardRequest{AccountID: acc.ID.String()}
+
+	resp, err := svc.CreateCard(uuid.New(), req)
 	assert.Error(t, err)
 	assert.Nil(t, resp)
 	assert.Contains(t, err.Error(), "unauthorized")
 }
 
-func TestCreateCard_OneCardPerAccountLimit(t *testing.T) {
-	svc, cardRepo, accountRepo, _ := setupCardServiceTest(t)
-	userID := uuid.New()
-	accountID := uuid.New()
+func TestCreateCard_PerTypeLimit(t *testing.T) {
+	svc, db := setupCardServiceTest(t)
+	owner, acc := seedCardOwner(t, db, nil)
 
-	req := &card.CreateCardRequest{
-		AccountID:      accountID.String(),
+	// The account already has the default maximum of two debit cards
+	seedCard(t, db, newTestCard(t, svc, acc.ID))
+	frozen := newTestCard(t, svc, acc.ID)
+	frozen.Status = card.CardStatusFrozen
+	seedCard(t, db, frozen)
+
+	resp, err := svc.CreateCard(owner.ID, &card.CreateCardRequest{
+		AccountID:      acc.ID.String(),
 		CardHolderName: "John Doe",
 		CardType:       "debit",
-	}
-
-	// Mock account ownership
-	accountRepo.On("GetByID", accountID).Return(&domainAccount.Account{
-		ID:     accountID,
-		UserID: userID,
-	}, nil)
-
-	// Mock: account already has a card (should reject)
-	existingCards := []*card.Card{
-		{ID: uuid.New(), AccountID: accountID},
-	}
-	cardRepo.On("GetByAccountID", accountID).Return(existingCards, nil)
-
-	resp, err := svc.CreateCard(userID, req)
+	})
 	assert.Error(t, err)
 	assert.Nil(t, resp)
-	assert.Contains(t, err.Error(), "each account can only have one debit card")
+	assert.Contains(t, err.Error(), "maximum of 2 debit cards")
 }
 
-func TestGetUserCards_Success(t *testing.T) {
-	svc, cardRepo, accountRepo, _ := setupCardServiceTest(t)
-	userID := uuid.New()
-	accountID := uuid.New()
-
-	// Mock account ownership
-	accountRepo.On("GetByID", accountID).Return(&domainAccount.Account{
-		ID:     accountID,
-		UserID: userID,
-	}, nil)
-
-	// Encrypt a card number for the mock
-	encryptedNumber, _ := svc.encryptor.Encrypt("4111111111111111")
-
-	cards := []*card.Card{
-		{
-			ID:                  uuid.New(),
-			AccountID:           accountID,
-			CardNumberEncrypted: encryptedNumber,
-			CardHolderName:      "John Doe",
-			CardType:            card.CardTypeDebit,
-			Status:              card.CardStatusActive,
-			CreatedAt:           time.Now(),
-		},
+func TestCheckCardLimits(t *testing.T) {
+	svc, _ := setupCardServiceTest(t)
+	svc.limits = CardLimits{
+		MaxPerAccount: 2,
+		MaxPerType:    map[card.CardType]int{card.CardTypeDebit: 2},
 	}
 
-	cardRepo.On("GetByAccountID", accountID).Return(cards, nil)
+	debit := &card.Card{CardType: card.CardTypeDebit, Status: card.CardStatusActive}
+	credit := &card.Card{CardType: card.CardTypeCredit, Status: card.CardStatusActive}
+	blocked := &card.Card{CardType: card.CardTypeDebit, Status: card.CardStatusBlocked}
+	expired := &card.Card{CardType: card.CardTypeDebit, Status: card.CardStatusExpired}
+
+	// A physical and a virtual debit card may coexist
+	assert.NoError(t, svc.checkCardLimits([]*card.Card{debit}, card.CardTypeDebit))
+	// Blocked and expired cards do not count
+	assert.NoError(t, svc.checkCardLimits([]*card.Card{debit, blocked, expired}, card.CardTypeDebit))
+	// Total limit applies across types
+	assert.Error(t, svc.checkCardLimits([]*card.Card{debit, credit}, card.CardTypeDebit))
+	// No per-type limit configured for credit, but the total still applies
+	assert.NoError(t, svc.checkCardLimits([]*card.Card{debit}, card.CardTypeCredit))
+
+	svc.limits = CardLimits{}
+	assert.NoError(t, svc.checkCardLimits([]*card.Card{debit, debit, credit}, card.CardTypeDebit))
+}
 
-	result, err := svc.GetUserCards(userID, accountID)
+func TestGetUserCards_Success(t *testing.T) {
+	svc, db := setupCardServiceTest(t)
+	owner, acc := seedCardOwner(t, db, nil)
+	c := seedCard(t, db, newTestCard(t, svc, acc.ID))
+
+	result, err := svc.GetUserCards(owner.ID, acc.ID)
 	assert.NoError(t, err)
 	assert.Len(t, result, 1)
-	assert.Contains(t, result[0].CardNumberMasked, "****")
-	cardRepo.AssertExpectations(t)
-	accountRepo.AssertExpectations(t)
+	assert.Equal(t, c.ID, result[0].ID)
+	assert.Equal(t, crypto.MaskCardNumber("4111111111111111"), result[0].CardNumberMasked)
 }
 
-func TestGetCardDetails_Success(t *testing.T) {
-	svc, cardRepo, accountRepo, userRepo := setupCardServiceTest(t)
-	userID := uuid.New()
-	cardID := uuid.New()
-	accountID := uuid.New()
-	password := "password123"
-	passwordHash, _ := crypto.HashPassword(password)
-
-	// Mock user verification
-	userRepo.On("GetByID", userID).Return(&user.User{
-		ID:           userID,
-		PasswordHash: passwordHash,
-	}, nil)
-
-	// Encrypt card data
-	encryptedNumber, _ := svc.encryptor.Encrypt("4111111111111111")
-	encryptedCVV, _ := svc.encryptor.Encrypt("123")
-
-	// Mock card retrieval
-	cardRepo.On("GetByID", cardID).Return(&card.Card{
-		ID:                  cardID,
-		AccountID:           accountID,
-		CardNumberEncrypted: encryptedNumber,
-		CVVEncrypted:        encryptedCVV,
-		ExpiryMonth:         12,
-		ExpiryYear:          2027,
-	}, nil)
+// setupRevealCard stores a card and its owner, whose password is
+// "password123", for the reveal flow
+func setupRevealCard(t *testing.T, svc *cardService, db *fakes.DB) (uuid.UUID, uuid.UUID) {
+	owner, acc := seedCardOwner(t, db, nil)
+	c := seedCard(t, db, newTestCard(t, svc, acc.ID))
+	return owner.ID, c.ID
+}
+
+// revealOTP reads the code from the last email sent to the cardholder
+func revealOTP(t *testing.T, svc *cardService) string {
+	calls := svc.notifier.(*MockNotifier).Calls
+	if !assert.NotEmpty(t, calls) {
+		return ""
+	}
+	email := calls[len(calls)-1].Arguments.Get(0).(*notifier.Email)
+	return regexp.MustCompile(`\d{6}`).FindString(email.Body)
+}
+
+func TestRevealCardDetails_FullFlow(t *testing.T) {
+	svc, db := setupCardServiceTest(t)
+	userID, cardID := setupRevealCard(t, svc, db)
 
-	// Mock account ownership
-	accountRepo.On("GetByID", accountID).Return(&domainAccount.Account{
-		ID:     accountID,
-		UserID: userID,
-	}, nil)
+	challenge, err := svc.RequestRevealChallenge(userID, cardID, "password123")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, challenge.ChallengeID)
 
-	details, err := svc.GetCardDetails(userID, cardID, password)
+	token, err := svc.VerifyRevealChallenge(userID, cardID, &card.RevealVerifyRequest{
+		ChallengeID: challenge.ChallengeID,
+		OTP:         revealOTP(t, svc),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, token.RevealToken, 64)
+
+	details, err := svc.GetCardDetails(userID, cardID, token.RevealToken)
 	assert.NoError(t, err)
 	assert.Equal(t, "4111111111111111", details.CardNumber)
 	assert.Equal(t, "123", details.CVV)
 	assert.Equal(t, 12, details.ExpiryMonth)
 	assert.Equal(t, 2027, details.ExpiryYear)
+
+	// Tokens are single use
+	_, err = svc.GetCardDetails(userID, cardID, token.RevealToken)
+	assert.EqualError(t, err, "invalid or expired reveal token")
+	assert.Equal(t, []string{"CARD_DETAILS_REVEALED success"}, auditTrail(t, db))
 }
 
-func TestGetCardDetails_InvalidPassword(t *testing.T) {
-	svc, _, _, userRepo := setupCardServiceTest(t)
-	userID := uuid.New()
-	cardID := uuid.New()
-	correctHash, _ := crypto.HashPassword("correct")
+func TestRequestRevealChallenge_StoresHashOnly(t *testing.T) {
+	svc, db := setupCardServiceTest(t)
+	userID, cardID := setupRevealCard(t, svc, db)
+
+	challenge, err := svc.RequestRevealChallenge(userID, cardID, "password123")
+	assert.NoError(t, err)
+	assert.Equal(t, user.OTPChannelEmail, challenge.Channel)
+
+	stored, _ := svc.redisClient.HGetAll(context.Background(), revealChallengeKey(challenge.ChallengeID)).Result()
+	assert.Len(t, stored["hash"], 64)
+	assert.NotContains(t, stored, "otp")
+	assert.NotContains(t, stored["hash"], revealOTP(t, svc))
+}
+
+func TestRequestRevealChallenge_SMSToVerifiedPhone(t *testing.T) {
+	svc, db := setupCardServiceTest(t)
+	phone := "+6281234567890"
+	owner, acc := seedCardOwner(t, db, &phone)
+	require.NoError(t, fakes.NewUserRepository(db).VerifyPhone(owner.ID, phone))
+	c := seedCard(t, db, newTestCard(t, svc, acc.ID))
+	mockSMS := svc.smsSender.(*MockSMSSender)
+	mockSMS.On("SendSMS", mock.MatchedBy(func(sms *notifier.SMS) bool { return sms.To == phone })).Return(nil)
+
+	challenge, err := svc.RequestRevealChallenge(owner.ID, c.ID, "password123")
+
+	assert.NoError(t, err)
+	assert.Equal(t, user.OTPChannelSMS, challenge.Channel)
+	mockSMS.AssertExpectations(t)
+	svc.notifier.(*MockNotifier).AssertNotCalled(t, "SendEmail", mock.Anything)
+}
+
+func TestRequestRevealChallenge_DeliveryFailure(t *testing.T) {
+	svc, db := setupCardServiceTest(t)
+	userID, cardID := setupRevealCard(t, svc, db)
+	mockNotifier := new(MockNotifier)
+	mockNotifier.On("SendEmail", mock.Anything).Return(errors.New("smtp down")).Once()
+	mockNotifier.On("SendEmail", mock.Anything).Return(nil)
+	svc.notifier = mockNotifier
+
+	_, err := svc.RequestRevealChallenge(userID, cardID, "password123")
+	assert.EqualError(t, err, "failed to send OTP, please try again")
+
+	// The rate limit is lifted so the customer can ask again
+	_, err = svc.RequestRevealChallenge(userID, cardID, "password123")
+	assert.NoError(t, err)
+}
 
-	userRepo.On("GetByID", userID).Return(&user.User{
-		ID:           userID,
-		PasswordHash: correctHash,
-	}, nil)
+func TestVerifyRevealChallenge_SingleUse(t *testing.T) {
+	svc, db := setupCardServiceTest(t)
+	userID, cardID := setupRevealCard(t, svc, db)
 
-	details, err := svc.GetCardDetails(userID, cardID, "wrong")
+	challenge, err := svc.RequestRevealChallenge(userID, cardID, "password123")
+	assert.NoError(t, err)
+	req := &card.RevealVerifyRequest{ChallengeID: challenge.ChallengeID, OTP: revealOTP(t, svc)}
+
+	_, err = svc.VerifyRevealChallenge(userID, cardID, req)
+	assert.NoError(t, err)
+	_, err = svc.VerifyRevealChallenge(userID, cardID, req)
+	assert.EqualError(t, err, "invalid or expired challenge")
+}
+
+func TestRequestRevealChallenge_InvalidPassword(t *testing.T) {
+	svc, db := setupCardServiceTest(t)
+	userID, cardID := setupRevealCard(t, svc, db)
+
+	challenge, err := svc.RequestRevealChallenge(userID, cardID, "wrong")
 	assert.Error(t, err)
-	assert.Nil(t, details)
+	assert.Nil(t, challenge)
 	assert.Contains(t, err.Error(), "invalid password")
+	assert.Equal(t, []string{"CARD_REVEAL_CHALLENGE failed"}, auditTrail(t, db))
 }
 
-func TestBlockCard_Success(t *testing.T) {
-	svc, cardRepo, accountRepo, _ := setupCardServiceTest(t)
-	userID := uuid.New()
-	cardID := uuid.New()
-	accountID := uuid.New()
+func TestRequestRevealChallenge_RateLimited(t *testing.T) {
+	svc, db := setupCardServiceTest(t)
+	userID, cardID := setupRevealCard(t, svc, db)
 
-	encryptedNumber, _ := svc.encryptor.Encrypt("4111111111111111")
+	_, err := svc.RequestRevealChallenge(userID, cardID, "password123")
+	assert.NoError(t, err)
 
-	cardRepo.On("GetByID", cardID).Return(&card.Card{
-		ID:                  cardID,
-		AccountID:           accountID,
-		CardNumberEncrypted: encryptedNumber,
-		Status:              card.CardStatusActive,
-	}, nil).Twice()
+	_, err = svc.RequestRevealChallenge(userID, cardID, "password123")
+	assert.EqualError(t, err, "please wait before requesting a new code")
+}
 
-	accountRepo.On("GetByID", accountID).Return(&domainAccount.Account{
-		ID:     accountID,
-		UserID: userID,
-	}, nil)
+func TestVerifyRevealChallenge_TooManyWrongCodes(t *testing.T) {
+	svc, db := setupCardServiceTest(t)
+	userID, cardID := setupRevealCard(t, svc, db)
+
+	challenge, err := svc.RequestRevealChallenge(userID, cardID, "password123")
+	assert.NoError(t, err)
+	otp := revealOTP(t, svc)
+	wrong := "000000"
+	if otp == wrong {
+		wrong = "111111"
+	}
+
+	req := &card.RevealVerifyRequest{ChallengeID: challenge.ChallengeID, OTP: wrong}
+	for i := 1; i < card.MaxRevealOTPAttempts; i++ {
+		_, err = svc.VerifyRevealChallenge(userID, cardID, req)
+		assert.EqualError(t, err, "invalid OTP code")
+	}
+	_, err = svc.VerifyRevealChallenge(userID, cardID, req)
+	assert.EqualError(t, err, "too many incorrect codes, request a new one")
+
+	// The challenge is gone, so even the right code no longer works
+	_, err = svc.VerifyRevealChallenge(userID, cardID, &card.RevealVerifyRequest{ChallengeID: challenge.ChallengeID, OTP: otp})
+	assert.EqualError(t, err, "invalid or expired challenge")
+}
 
-	cardRepo.On("Update", cardID, mock.AnythingOfType("map[string]interface {}")).Return(nil)
+func TestVerifyRevealChallenge_OtherCard(t *testing.T) {
+	svc, db := setupCardServiceTest(t)
+	userID, cardID := setupRevealCard(t, svc, db)
 
-	err := svc.BlockCard(userID, cardID)
+	challenge, err := svc.RequestRevealChallenge(userID, cardID, "password123")
 	assert.NoError(t, err)
-	cardRepo.AssertExpectations(t)
+
+	_, err = svc.VerifyRevealChallenge(userID, uuid.New(), &card.RevealVerifyRequest{
+		ChallengeID: challenge.ChallengeID,
+		OTP:         revealOTP(t, svc),
+	})
+	assert.EqualError(t, err, "invalid or expired challenge")
 }
 
-func TestDeleteCard_Success(t *testing.T) {
-	svc, cardRepo, accountRepo, _ := setupCardServiceTest(t)
+func TestGetCardDetails_TokenForAnotherCard(t *testing.T) {
+	svc, _ := setupCardServiceTest(t)
 	userID := uuid.New()
-	cardID := uuid.New()
-	accountID := uuid.New()
+	issuedFor := uuid.New()
 
-	cardRepo.On("GetByID", cardID).Return(&card.Card{
-		ID:        cardID,
-		AccountID: accountID,
-	}, nil)
+	svc.redisClient.Set(context.Background(), revealTokenKey("token"), userID.String()+":"+issuedFor.String(), time.Minute)
 
-	accountRepo.On("GetByID", accountID).Return(&domainAccount.Account{
-		ID:     accountID,
-		UserID: userID,
-	}, nil)
+	details, err := svc.GetCardDetails(userID, uuid.New(), "token")
+	assert.Nil(t, details)
+	assert.EqualError(t, err, "invalid or expired reveal token")
+}
 
-	cardRepo.On("Delete", cardID).Return(nil)
+func TestBlockCard_Success(t *testing.T) {
+	svc, db := setupCardServiceTest(t)
+	owner, acc := seedCardOwner(t, db, nil)
+	c := seedCard(t, db, newTestCard(t, svc, acc.ID))
 
-	err := svc.DeleteCard(userID, cardID)
+	err := svc.BlockCard(owner.ID, c.ID)
 	assert.NoError(t, err)
-	cardRepo.AssertExpectations(t)
+	assert.Equal(t, card.CardStatusBlocked, storedCard(t, db, c.ID).Status)
 }
 
-func TestDeleteCard_Unauthorized(t *testing.T) {
-	svc, cardRepo, accountRepo, _ := setupCardServiceTest(t)
-	userID := uuid.New()
-	otherUserID := uuid.New()
-	cardID := uuid.New()
-	accountID := uuid.New()
+func TestDeleteCard_Success(t *testing.T) {
+	svc, db := setupCardServiceTest(t)
+	owner, acc := seedCardOwner(t, db, nil)
+	c := seedCard(t, db, newTestCard(t, svc, acc.ID))
 
-	cardRepo.On("GetByID", cardID).Return(&card.Card{
-		ID:        cardID,
-		AccountID: accountID,
-	}, nil)
+	err := svc.DeleteCard(owner.ID, c.ID)
+	assert.NoError(t, err)
+	_, err = fakes.NewCardRepository(db).GetByID(c.ID)
+	assert.EqualError(t, err, "card not found")
+}
 
+func TestDeleteCard_Unauthorized(t *testing.T) {
+	svc, db := setupCardServiceTest(t)
 	// Account belongs to another user
-	accountRepo.On("GetByID", accountID).Return(&domainAccount.Account{
-		ID:     accountID,
-		UserID: otherUserID,
-	}, nil)
+	_, acc := seedCardOwner(t, db, nil)
+	c := seedCard(t, db, newTestCard(t, svc, acc.ID))
 
-	err := svc.DeleteCard(userID, cardID)
+	err := svc.DeleteCard(uuid.New(), c.ID)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "unauthorized")
-	cardRepo.AssertNotCalled(t, "Delete", mock.Anything)
+	assert.Equal(t, card.CardStatusActive, storedCard(t, db, c.ID).Status)
+}
+
+// setCardPIN stores the PIN 4826 on the card with the given lockout state
+func setCardPIN(t *testing.T, svc *cardService, db *fakes.DB, cardID uuid.UUID, attempts int, lockedUntil *time.Time) {
+	t.Helper()
+	encryptedPIN, err := svc.encryptor.Encrypt("4826")
+	require.NoError(t, err)
+	require.NoError(t, fakes.NewCardRepository(db).Update(cardID, map[string]interface{}{
+		"pin_encrypted":       encryptedPIN,
+		"pin_failed_attempts": attempts,
+		"pin_locked_until":    lockedUntil,
+	}))
+}
+
+func TestSetPIN_Success(t *testing.T) {
+	svc, db := setupCardServiceTest(t)
+	owner, acc := seedCardOwner(t, db, nil)
+	c := seedCard(t, db, newTestCard(t, svc, acc.ID))
+	// A new PIN lifts a lockout
+	lockedUntil := time.Now().Add(time.Hour)
+	setCardPIN(t, svc, db, c.ID, 2, &lockedUntil)
+
+	err := svc.SetPIN(owner.ID, c.ID, &card.SetPINRequest{PIN: "195037", Password: "password123"})
+	assert.NoError(t, err)
+
+	stored := storedCard(t, db, c.ID)
+	pin, err := svc.encryptor.Decrypt(stored.PINEncrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, "195037", pin)
+	assert.Equal(t, 0, stored.PINFailedAttempts)
+	assert.Nil(t, stored.PINLockedUntil)
+}
+
+func TestSetPIN_InvalidPassword(t *testing.T) {
+	svc, db := setupCardServiceTest(t)
+	owner, acc := seedCardOwner(t, db, nil)
+	c := seedCard(t, db, newTestCard(t, svc, acc.ID))
+
+	err := svc.SetPIN(owner.ID, c.ID, &card.SetPINRequest{PIN: "4826", Password: "wrong"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid password")
+	assert.False(t, storedCard(t, db, c.ID).HasPIN())
+}
+
+func TestValidatePIN(t *testing.T) {
+	assert.NoError(t, validatePIN("4826"))
+	assert.NoError(t, validatePIN("195037"))
+	assert.Error(t, validatePIN("123"))
+	assert.Error(t, validatePIN("1234567"))
+	assert.Error(t, validatePIN("12a4"))
+	assert.Error(t, validatePIN("0000"))
+	assert.Error(t, validatePIN("1234"))
+	assert.Error(t, validatePIN("987654"))
+}
+
+// setupPINCard stores a card with the PIN 4826 and the given lockout state,
+// and returns its owner's ID and the card
+func setupPINCard(t *testing.T, svc *cardService, db *fakes.DB, attempts int, lockedUntil *time.Time) (uuid.UUID, *card.Card) {
+	owner, acc := seedCardOwner(t, db, nil)
+	c := seedCard(t, db, newTestCard(t, svc, acc.ID))
+	setCardPIN(t, svc, db, c.ID, attempts, lockedUntil)
+	return owner.ID, storedCard(t, db, c.ID)
+}
+
+func TestVerifyPIN_Correct(t *testing.T) {
+	svc, db := setupCardServiceTest(t)
+	userID, c := setupPINCard(t, svc, db, 1, nil)
+
+	resp, err := svc.VerifyPIN(userID, c.ID, "4826")
+	assert.NoError(t, err)
+	assert.True(t, resp.Valid)
+	assert.Equal(t, card.MaxPINAttempts, resp.RemainingAttempts)
+	assert.Equal(t, 0, storedCard(t, db, c.ID).PINFailedAttempts)
+}
+
+func TestVerifyPIN_WrongCountsAttempt(t *testing.T) {
+	svc, db := setupCardServiceTest(t)
+	userID, c := setupPINCard(t, svc, db, 0, nil)
+
+	resp, err := svc.VerifyPIN(userID, c.ID, "0000")
+	assert.NoError(t, err)
+	assert.False(t, resp.Valid)
+	assert.Equal(t, card.MaxPINAttempts-1, resp.RemainingAttempts)
+	assert.Nil(t, resp.LockedUntil)
+	assert.Equal(t, 1, storedCard(t, db, c.ID).PINFailedAttempts)
+}
+
+func TestVerifyPIN_LocksAfterMaxAttempts(t *testing.T) {
+	svc, db := setupCardServiceTest(t)
+	userID, c := setupPINCard(t, svc, db, card.MaxPINAttempts-1, nil)
+
+	resp, err := svc.VerifyPIN(userID, c.ID, "0000")
+	assert.NoError(t, err)
+	assert.False(t, resp.Valid)
+	assert.Equal(t, 0, resp.RemainingAttempts)
+	require.NotNil(t, resp.LockedUntil)
+	assert.WithinDuration(t, time.Now().Add(card.PINLockDuration), *resp.LockedUntil, 5*time.Second)
+	assert.Equal(t, resp.LockedUntil, storedCard(t, db, c.ID).PINLockedUntil)
+}
+
+func TestVerifyPIN_LockedSinceCardWasRead(t *testing.T) {
+	svc, db := setupCardServiceTest(t)
+	// The card was read before another request's wrong PIN locked it, so
+	// even the correct PIN is refused
+	_, c := setupPINCard(t, svc, db, card.MaxPINAttempts-1, nil)
+	locking, err := svc.cardRepo.RecordPINAttempt(c.ID, false, time.Now())
+	require.NoError(t, err)
+	require.NotNil(t, locking.LockedUntil)
+
+	resp, err := checkCardPIN(svc.cardRepo, svc.encryptor, c, "4826")
+	assert.NoError(t, err)
+	assert.False(t, resp.Valid)
+	assert.Equal(t, locking.LockedUntil, resp.LockedUntil)
+}
+
+func TestVerifyPIN_LockedRejectsCorrectPIN(t *testing.T) {
+	svc, db := setupCardServiceTest(t)
+	lockedUntil := time.Now().Add(time.Hour)
+	userID, c := setupPINCard(t, svc, db, 0, &lockedUntil)
+
+	resp, err := svc.VerifyPIN(userID, c.ID, "4826")
+	assert.NoError(t, err)
+	assert.False(t, resp.Valid)
+	assert.Equal(t, c.PINLockedUntil, resp.LockedUntil)
+	assert.Equal(t, c, storedCard(t, db, c.ID))
+}
+
+func TestVerifyPIN_NotSet(t *testing.T) {
+	svc, db := setupCardServiceTest(t)
+	owner, acc := seedCardOwner(t, db, nil)
+	c := seedCard(t, db, newTestCard(t, svc, acc.ID))
+
+	resp, err := svc.VerifyPIN(owner.ID, c.ID, "4826")
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+}
+
+func TestUpdateControls_Success(t *testing.T) {
+	svc, db := setupCardServiceTest(t)
+	owner, acc := seedCardOwner(t, db, nil)
+	c := seedCard(t, db, newTestCard(t, svc, acc.ID))
+	disabled := false
+
+	expected := card.DefaultControls()
+	expected.ForeignEnabled = false
+	expected.BlockedMCCs = []string{"7995"}
+
+	resp, err := svc.UpdateControls(owner.ID, c.ID, &card.UpdateControlsRequest{
+		ForeignEnabled: &disabled,
+		BlockedMCCs:    []string{"7995"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, expected, resp.Controls)
+	assert.Equal(t, expected, storedCard(t, db, c.ID).Controls)
+}
+
+func TestUpdateControls_Unauthorized(t *testing.T) {
+	svc, db := setupCardServiceTest(t)
+	_, acc := seedCardOwner(t, db, nil)
+	c := seedCard(t, db, newTestCard(t, svc, acc.ID))
+	disabled := false
+
+	resp, err := svc.UpdateControls(uuid.New(), c.ID, &card.UpdateControlsRequest{ForeignEnabled: &disabled})
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+	assert.Equal(t, card.DefaultControls(), storedCard(t, db, c.ID).Controls)
+}
+
+func TestFreezeAndUnfreezeCard(t *testing.T) {
+	svc, db := setupCardServiceTest(t)
+	owner, acc := seedCardOwner(t, db, nil)
+	c := seedCard(t, db, newTestCard(t, svc, acc.ID))
+
+	assert.NoError(t, svc.FreezeCard(owner.ID, c.ID))
+	assert.Equal(t, card.CardStatusFrozen, storedCard(t, db, c.ID).Status)
+	assert.NoError(t, svc.UnfreezeCard(owner.ID, c.ID))
+	assert.Equal(t, card.CardStatusActive, storedCard(t, db, c.ID).Status)
+}
+
+func TestFreezeCard_BlockedCard(t *testing.T) {
+	svc, db := setupCardServiceTest(t)
+	owner, acc := seedCardOwner(t, db, nil)
+	blocked := newTestCard(t, svc, acc.ID)
+	blocked.Status = card.CardStatusBlocked
+	c := seedCard(t, db, blocked)
+
+	assert.Error(t, svc.FreezeCard(owner.ID, c.ID))
+	assert.Error(t, svc.UnfreezeCard(owner.ID, c.ID))
+	assert.Equal(t, card.CardStatusBlocked, storedCard(t, db, c.ID).Status)
+}
+
+func TestUpdateCard_CannotReactivateBlockedCard(t *testing.T) {
+	svc, db := setupCardServiceTest(t)
+	owner, acc := seedCardOwner(t, db, nil)
+	blocked := newTestCard(t, svc, acc.ID)
+	blocked.Status = card.CardStatusBlocked
+	c := seedCard(t, db, blocked)
+
+	resp, err := svc.UpdateCard(owner.ID, c.ID, &card.UpdateCardRequest{Status: stringPtr("active")})
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+	assert.Contains(t, err.Error(), "replacement")
+	assert.Equal(t, card.CardStatusBlocked, storedCard(t, db, c.ID).Status)
+}
+
+func TestReissueCard_Success(t *testing.T) {
+	logger.Init("test")
+	svc, db := setupCardServiceTest(t)
+	owner, acc := seedCardOwner(t, db, nil)
+
+	controls := card.DefaultControls()
+	controls.ForeignEnabled = false
+	original := newTestCard(t, svc, acc.ID)
+	original.Status = card.CardStatusFrozen
+	original.Controls = controls
+	seedCard(t, db, original)
+
+	resp, err := svc.ReissueCard(owner.ID, original.ID, &card.ReissueCardRequest{Reason: "lost"})
+	assert.NoError(t, err)
+	assert.NotEqual(t, original.ID, resp.ID)
+	assert.Equal(t, &original.ID, resp.ReplacesCardID)
+	assert.Equal(t, card.CardStatusActive, resp.Status)
+	assert.Equal(t, 2500.0, resp.DailyLimit)
+	assert.Equal(t, controls, resp.Controls)
+	assert.Equal(t, "John Doe", resp.CardHolderName)
+
+	assert.Equal(t, card.CardStatusBlocked, storedCard(t, db, original.ID).Status)
+	assert.Equal(t, &original.ID, storedCard(t, db, resp.ID).ReplacesCardID)
+	assert.Equal(t, []string{"CARD_BLOCKED success", "CARD_REISSUED success"}, auditTrail(t, db))
+}
+
+func TestReissueCard_AlreadyReplaced(t *testing.T) {
+	svc, db := setupCardServiceTest(t)
+	owner, acc := seedCardOwner(t, db, nil)
+
+	original := newTestCard(t, svc, acc.ID)
+	original.Status = card.CardStatusBlocked
+	seedCard(t, db, original)
+	replacement := newTestCard(t, svc, acc.ID)
+	replacement.ReplacesCardID = &original.ID
+	seedCard(t, db, replacement)
+
+	resp, err := svc.ReissueCard(owner.ID, original.ID, &card.ReissueCardRequest{Reason: "lost"})
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+	assert.Contains(t, err.Error(), "already been replaced")
+	cards, err := svc.cardRepo.GetByAccountID(acc.ID)
+	assert.NoError(t, err)
+	assert.Len(t, cards, 2)
+}
+
+func TestCreateCard_CreditRequiresLimit(t *testing.T) {
+	svc, db := setupCardServiceTest(t)
+	owner, acc := seedCardOwner(t, db, nil)
+
+	resp, err := svc.CreateCard(owner.ID, &card.CreateCardRequest{
+		AccountID:      acc.ID.String(),
+		CardHolderName: "John Doe",
+		CardType:       "credit",
+		DailyLimit:     5000,
+	})
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+	assert.Contains(t, err.Error(), "credit_limit")
+}
+
+func TestCreateCard_Credit(t *testing.T) {
+	svc, db := setupCardServiceTest(t)
+	owner, acc := seedCardOwner(t, db, nil)
+
+	resp, err := svc.CreateCard(owner.ID, &card.CreateCardRequest{
+		AccountID:      acc.ID.String(),
+		CardHolderName: "John Doe",
+		CardType:       "credit",
+		DailyLimit:     5000,
+		CreditLimit:    10_000_000,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 10_000_000.0, resp.CreditLimit)
+	assert.Equal(t, 10_000_000.0, resp.AvailableCredit)
+
+	stored := storedCard(t, db, resp.ID)
+	assert.Equal(t, card.CardTypeCredit, stored.CardType)
+	assert.Equal(t, 10_000_000.0, stored.CreditLimit)
+	assert.GreaterOrEqual(t, stored.StatementDay, 1)
+	assert.LessOrEqual(t, stored.StatementDay, card.MaxStatementDay)
+}
+
+func TestRotateCVV_Success(t *testing.T) {
+	svc, db := setupCardServiceTest(t)
+	userID, cardID := setupRevealCard(t, svc, db)
+	// The first draw repeats the current CVV and must be discarded
+	svc.cardRepo = &scriptedCVVs{CardRepository: fakes.NewCardRepository(db), cvvs: []string{"123", "987"}}
+
+	err := svc.RotateCVV(userID, cardID, &card.RotateCVVRequest{Password: "password123"})
+
+	assert.NoError(t, err)
+	cvv, err := svc.encryptor.Decrypt(storedCard(t, db, cardID).CVVEncrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, "987", cvv)
+	assert.Equal(t, []string{"CARD_CVV_ROTATED success"}, auditTrail(t, db))
+}
+
+func TestRotateCVV_InvalidPassword(t *testing.T) {
+	svc, db := setupCardServiceTest(t)
+	userID, cardID := setupRevealCard(t, svc, db)
+
+	err := svc.RotateCVV(userID, cardID, &card.RotateCVVRequest{Password: "wrong"})
+
+	assert.EqualError(t, err, "invalid password")
+	cvv, err := svc.encryptor.Decrypt(storedCard(t, db, cardID).CVVEncrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, "123", cvv)
+	assert.Equal(t, []string{"CARD_CVV_ROTATED failed"}, auditTrail(t, db))
+}
+
+func TestRotateCVV_BlockedCard(t *testing.T) {
+	svc, db := setupCardServiceTest(t)
+	userID, cardID := setupRevealCard(t, svc, db)
+	require.NoError(t, svc.cardRepo.Update(cardID, map[string]interface{}{"status": card.CardStatusBlocked}))
+
+	err := svc.RotateCVV(userID, cardID, &card.RotateCVVRequest{Password: "password123"})
+
+	assert.EqualError(t, err, "card is blocked")
+}
+
+func TestExpireCards(t *testing.T) {
+	svc, db := setupCardServiceTest(t)
+	_, acc := seedCardOwner(t, db, nil)
+	// Cards stay valid through their expiry month
+	for _, expiry := range []struct{ month, year int }{{1, 2026}, {2, 2026}, {3, 2026}, {12, 2027}} {
+		c := newTestCard(t, svc, acc.ID)
+		c.ExpiryMonth, c.ExpiryYear = expiry.month, expiry.year
+		seedCard(t, db, c)
+	}
+
+	n, err := svc.ExpireCards(time.Date(2026, 3, 1, 0, 15, 0, 0, time.UTC))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	cards, err := svc.cardRepo.GetByAccountID(acc.ID)
+	assert.NoError(t, err)
+	expired := 0
+	for _, c := range cards {
+		if c.Status == card.CardStatusExpired {
+			expired++
+		}
+	}
+	assert.Equal(t, 2, expired)
 }
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/darisadam/madabank-server/internal/domain/audit"
+	"github.com/darisadam/madabank-server/internal/domain/card"
+	"github.com/darisadam/madabank-server/internal/pkg/crypto"
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type CardTokenService interface {
+	ProvisionToken(userID uuid.UUID, cardID uuid.UUID, req *card.ProvisionTokenRequest) (*card.ProvisionTokenResponse, error)
+	ListTokens(userID uuid.UUID, cardID uuid.UUID) ([]*card.Token, error)
+	SuspendToken(userID uuid.UUID, cardID uuid.UUID, tokenID uuid.UUID) error
+	ResumeToken(userID uuid.UUID, cardID uuid.UUID, tokenID uuid.UUID) error
+	DeleteToken(userID uuid.UUID, cardID uuid.UUID, tokenID uuid.UUID) error
+}
+
+type cardTokenService struct {
+	cardRepo    repository.CardRepository
+	tokenRepo   repository.CardTokenRepository
+	accountRepo repository.AccountRepository
+	auditRepo   repository.AuditRepository
+	encryptor   *crypto.Encryptor
+}
+
+func NewCardTokenService(
+	cardRepo repository.CardRepository,
+	tokenRepo repository.CardTokenRepository,
+	accountRepo repository.AccountRepository,
+	auditRepo repository.AuditRepository,
+	encryptor *crypto.Encryptor,
+) CardTokenService {
+	return &cardTokenService{
+		cardRepo:    cardRepo,
+		tokenRepo:   tokenRepo,
+		accountRepo: accountRepo,
+		auditRepo:   auditRepo,
+		encryptor:   encryptor,
+	}
+}
+
+// ProvisionToken issues a wallet token bound to one device. The wallet gets
+// the token number instead of the card number, so the PAN stays in the vault.
+func (s *cardTokenService) ProvisionToken(userID uuid.UUID, cardID uuid.UUID, req *card.ProvisionTokenRequest) (*card.ProvisionTokenResponse, error) {
+	c, err := s.getOwnedCard(userID, cardID)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Status != card.CardStatusActive {
+		return nil, fmt.Errorf("only active cards can be added to a wallet")
+	}
+
+	existing, err := s.tokenRepo.ListByCard(cardID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= card.MaxTokensPerCard {
+		return nil, fmt.Errorf("card has reached the maximum of %d wallet tokens", card.MaxTokensPerCard)
+	}
+	for _, t := range existing {
+		if t.Wallet == req.Wallet && t.DeviceID == req.DeviceID {
+			return nil, fmt.Errorf("card is already in this wallet on this device")
+		}
+	}
+
+	tokenNumber, err := s.tokenRepo.GenerateTokenNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedNumber, err := s.encryptor.Encrypt(tokenNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt token number: %w", err)
+	}
+
+	t := &card.Token{
+		ID:                   uuid.New(),
+		CardID:               c.ID,
+		TokenNumberEncrypted: encryptedNumber,
+		TokenNumberHash:      s.encryptor.Fingerprint(tokenNumber),
+		LastFour:             tokenNumber[len(tokenNumber)-4:],
+		Wallet:               req.Wallet,
+		DeviceID:             req.DeviceID,
+		DeviceName:           req.DeviceName,
+		Status:               card.TokenStatusActive,
+		ExpiryMonth:          c.ExpiryMonth,
+		ExpiryYear:           c.ExpiryYear,
+	}
+
+	if err := s.tokenRepo.Create(t); err != nil {
+		return nil, err
+	}
+
+	s.recordAudit(userID, "CARD_TOKEN_PROVISIONED", c.ID, map[string]interface{}{
+		"token_id": t.ID.String(),
+		"wallet":   string(t.Wallet),
+	})
+
+	return &card.ProvisionTokenResponse{Token: t, TokenNumber: tokenNumber}, nil
+}
+
+func (s *cardTokenService) ListTokens(userID uuid.UUID, cardID uuid.UUID) ([]*card.Token, error) {
+	if _, err := s.getOwnedCard(userID, cardID); err != nil {
+		return nil, err
+	}
+
+	return s.tokenRepo.ListByCard(cardID)
+}
+
+func (s *cardTokenService) SuspendToken(userID uuid.UUID, cardID uuid.UUID, tokenID uuid.UUID) error {
+	return s.transitionToken(userID, cardID, tokenID, card.TokenStatusSuspended, "CARD_TOKEN_SUSPENDED")
+}
+
+func (s *cardTokenService) ResumeToken(userID uuid.UUID, cardID uuid.UUID, tokenID uuid.UUID) error {
+	return s.transitionToken(userID, cardID, tokenID, card.TokenStatusActive, "CARD_TOKEN_RESUMED")
+}
+
+func (s *cardTokenService) DeleteToken(userID uuid.UUID, cardID uuid.UUID, tokenID uuid.UUID) error {
+	return s.transitionToken(userID, cardID, tokenID, card.TokenStatusDeleted, "CARD_TOKEN_DELETED")
+}
+
+// transitionToken moves one of the card's tokens to a new lifecycle status
+func (s *cardTokenService) transitionToken(userID uuid.UUID, cardID uuid.UUID, tokenID uuid.UUID, to card.TokenStatus, action string) error {
+	if _, err := s.getOwnedCard(userID, cardID); err != nil {
+		return err
+	}
+
+	t, err := s.tokenRepo.GetByID(tokenID)
+	if err != nil || t.CardID != cardID {
+		return fmt.Errorf("card token not found")
+	}
+
+	if !t.Status.CanTransitionTo(to) {
+		return fmt.Errorf("cannot change token status from %s to %s", t.Status, to)
+	}
+
+	if err := s.tokenRepo.UpdateStatus(t.ID, to); err != nil {
+		return err
+	}
+
+	s.recordAudit(userID, action, cardID, map[string]interface{}{"token_id": t.ID.String()})
+	return nil
+}
+
+func (s *cardTokenService) getOwnedCard(userID uuid.UUID, cardID uuid.UUID) (*card.Card, error) {
+	return loadOwnedCard(s.cardRepo, s.accountRepo, userID, cardID)
+}
+
+func (s *cardTokenService) recordAudit(userID uuid.UUID, action string, cardID uuid.UUID, metadata map[string]interface{}) {
+	if err := s.auditRepo.Create(&audit.AuditLog{
+		EventID:  uuid.New(),
+		UserID:   &userID,
+		Action:   action,
+		Resource: fmt.Sprintf("card:%s", cardID),
+		Status:   "success",
+		Metadata: metadata,
+	}); err != nil {
+		logger.Error("Failed to create audit log for card token", zap.String("action", action), zap.Error(err))
+		errtrack.CaptureError(err, map[string]string{"component": "card_token_service", "operation": "audit_log"})
+	}
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"testing"
+
+	domainAccount "github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/audit"
+	"github.com/darisadam/madabank-server/internal/domain/card"
+	"github.com/darisadam/madabank-server/internal/pkg/crypto"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockCardTokenRepository is a mock implementation
+type MockCardTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockCardTokenRepository) Create(t *card.Token) error {
+	args := m.Called(t)
+	return args.Error(0)
+}
+
+func (m *MockCardTokenRepository) GetByID(id uuid.UUID) (*card.Token, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*card.Token), args.Error(1)
+}
+
+func (m *MockCardTokenRepository) GetByNumberHash(hash string) (*card.Token, error) {
+	args := m.Called(hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*card.Token), args.Error(1)
+}
+
+func (m *MockCardTokenRepository) ListByCard(cardID uuid.UUID) ([]*card.Token, error) {
+	args := m.Called(cardID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*card.Token), args.Error(1)
+}
+
+func (m *MockCardTokenRepository) UpdateStatus(id uuid.UUID, status card.TokenStatus) error {
+	args := m.Called(id, status)
+	return args.Error(0)
+}
+
+func (m *MockCardTokenRepository) GenerateTokenNumber() (string, error) {
+	args := m.Called()
+	return args.String(0), args.Error(1)
+}
+
+func setupCardTokenServiceTest(t *testing.T) (*cardTokenService, *MockCardTokenRepository, *MockAuditRepository, uuid.UUID, *card.Card) {
+	logger.Init("test")
+	cardRepo := new(MockCardRepository)
+	tokenRepo := new(MockCardTokenRepository)
+	accountRepo := new(MockAccountRepository)
+	auditRepo := new(MockAuditRepository)
+
+	encryptor, err := crypto.NewEncryptor("12345678901234567890123456789012")
+	assert.NoError(t, err)
+
+	userID := uuid.New()
+	c := &card.Card{
+		ID:          uuid.New(),
+		AccountID:   uuid.New(),
+		Status:      card.CardStatusActive,
+		ExpiryMonth: 8,
+		ExpiryYear:  2030,
+	}
+	cardRepo.On("GetByID", c.ID).Return(c, nil)
+	accountRepo.On("GetByID", c.AccountID).Return(&domainAccount.Account{ID: c.AccountID, UserID: userID}, nil)
+
+	svc := NewCardTokenService(cardRepo, tokenRepo, accountRepo, auditRepo, encryptor).(*cardTokenService)
+	return svc, tokenRepo, auditRepo, userID, c
+}
+
+func TestProvisionToken_Success(t *testing.T) {
+	svc, tokenRepo, auditRepo, userID, c := setupCardTokenServiceTest(t)
+
+	tokenRepo.On("ListByCard", c.ID).Return([]*card.Token{}, nil)
+	tokenRepo.On("GenerateTokenNumber").Return("4895371234567897", nil)
+	tokenRepo.On("Create", mock.MatchedBy(func(tok *card.Token) bool {
+		return tok.CardID == c.ID && tok.LastFour == "7897" && tok.Status == card.TokenStatusActive &&
+			tok.TokenNumberHash == svc.encryptor.Fingerprint("4895371234567897") &&
+			tok.TokenNumberEncrypted != "4895371234567897" &&
+			tok.ExpiryMonth == 8 && tok.ExpiryYear == 2030
+	})).Return(nil)
+	auditRepo.On("Create", mock.MatchedBy(func(l *audit.AuditLog) bool { return l.Action == "CARD_TOKEN_PROVISIONED" })).Return(nil)
+
+	resp, err := svc.ProvisionToken(userID, c.ID, &card.ProvisionTokenRequest{
+		Wallet:     card.WalletGooglePay,
+		DeviceID:   "pixel-8-abc",
+		DeviceName: "Pixel 8",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "4895371234567897", resp.TokenNumber)
+	assert.Equal(t, card.WalletGooglePay, resp.Token.Wallet)
+	tokenRepo.AssertExpectations(t)
+	auditRepo.AssertExpectations(t)
+}
+
+func TestProvisionToken_InactiveCard(t *testing.T) {
+	svc, tokenRepo, _, userID, c := setupCardTokenServiceTest(t)
+	c.Status = card.CardStatusFrozen
+
+	_, err := svc.ProvisionToken(userID, c.ID, &card.ProvisionTokenRequest{Wallet: card.WalletApplePay, DeviceID: "iphone"})
+
+	assert.EqualError(t, err, "only active cards can be added to a wallet")
+	tokenRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestProvisionToken_SameDeviceTwice(t *testing.T) {
+	svc, tokenRepo, _, userID, c := setupCardTokenServiceTest(t)
+
+	tokenRepo.On("ListByCard", c.ID).Return([]*card.Token{
+		{ID: uuid.New(), CardID: c.ID, Wallet: card.WalletGooglePay, DeviceID: "pixel-8-abc", Status: card.TokenStatusSuspended},
+	}, nil)
+
+	_, err := svc.ProvisionToken(userID, c.ID, &card.ProvisionTokenRequest{Wallet: card.WalletGooglePay, DeviceID: "pixel-8-abc"})
+
+	assert.EqualError(t, err, "card is already in this wallet on this device")
+}
+
+func TestProvisionToken_Limit(t *testing.T) {
+	svc, tokenRepo, _, userID, c := setupCardTokenServiceTest(t)
+
+	existing := make([]*card.Token, card.MaxTokensPerCard)
+	for i := range existing {
+		existing[i] = &card.Token{ID: uuid.New(), CardID: c.ID, Wallet: card.WalletGooglePay, DeviceID: uuid.NewString()}
+	}
+	tokenRepo.On("ListByCard", c.ID).Return(existing, nil)
+
+	_, err := svc.ProvisionToken(userID, c.ID, &card.ProvisionTokenRequest{Wallet: card.WalletGooglePay, DeviceID: "new-device"})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "maximum")
+}
+
+func TestTokenLifecycle(t *testing.T) {
+	tests := []struct {
+		name   string
+		from   card.TokenStatus
+		change func(svc *cardTokenService, userID, cardID, tokenID uuid.UUID) error
+		to     card.TokenStatus
+		action string
+	}{
+		{"suspend active", card.TokenStatusActive, (*cardTokenService).SuspendToken, card.TokenStatusSuspended, "CARD_TOKEN_SUSPENDED"},
+		{"resume suspended", card.TokenStatusSuspended, (*cardTokenService).ResumeToken, card.TokenStatusActive, "CARD_TOKEN_RESUMED"},
+		{"delete suspended", card.TokenStatusSuspended, (*cardTokenService).DeleteToken, card.TokenStatusDeleted, "CARD_TOKEN_DELETED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, tokenRepo, auditRepo, userID, c := setupCardTokenServiceTest(t)
+			tok := &card.Token{ID: uuid.New(), CardID: c.ID, Status: tt.from}
+
+			tokenRepo.On("GetByID", tok.ID).Return(tok, nil)
+			tokenRepo.On("UpdateStatus", tok.ID, tt.to).Return(nil)
+			auditRepo.On("Create", mock.MatchedBy(func(l *audit.AuditLog) bool { return l.Action == tt.action })).Return(nil)
+
+			assert.NoError(t, tt.change(svc, userID, c.ID, tok.ID))
+			tokenRepo.AssertExpectations(t)
+			auditRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestResumeToken_Deleted(t *testing.T) {
+	svc, tokenRepo, _, userID, c := setupCardTokenServiceTest(t)
+	tok := &card.Token{ID: uuid.New(), CardID: c.ID, Status: card.TokenStatusDeleted}
+	tokenRepo.On("GetByID", tok.ID).Return(tok, nil)
+
+	err := svc.ResumeToken(userID, c.ID, tok.ID)
+
+	assert.EqualError(t, err, "cannot change token status from deleted to active")
+	tokenRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything)
+}
+
+func TestSuspendToken_OtherCard(t *testing.T) {
+	svc, tokenRepo, _, userID, c := setupCardTokenServiceTest(t)
+	tok := &card.Token{ID: uuid.New(), CardID: uuid.New(), Status: card.TokenStatusActive}
+	tokenRepo.On("GetByID", tok.ID).Return(tok, nil)
+
+	err := svc.SuspendToken(userID, c.ID, tok.ID)
+
+	assert.EqualError(t, err, "card token not found")
+}
+
+func TestListTokens_NotOwner(t *testing.T) {
+	svc, tokenRepo, _, _, c := setupCardTokenServiceTest(t)
+
+	_, err := svc.ListTokens(uuid.New(), c.ID)
+
+	assert.EqualError(t, err, "unauthorized: card does not belong to user")
+	tokenRepo.AssertNotCalled(t, "ListByCard", mock.Anything)
+}
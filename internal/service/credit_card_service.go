@@ -0,0 +1,176 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/audit"
+	"github.com/darisadam/madabank-server/internal/domain/card"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const maxStatementsListed = 24
+
+type CreditCardService interface {
+	Repay(userID uuid.UUID, cardID uuid.UUID, req *card.RepayCardRequest) (*transaction.Transaction, error)
+	GetStatements(userID uuid.UUID, cardID uuid.UUID) ([]*card.Statement, error)
+	CloseDueStatements(now time.Time) (int, error)
+}
+
+type creditCardService struct {
+	cardRepo        repository.CardRepository
+	creditRepo      repository.CreditCardRepository
+	accountRepo     repository.AccountRepository
+	transactionRepo repository.TransactionRepository
+	auditRepo       repository.AuditRepository
+}
+
+func NewCreditCardService(
+	cardRepo repository.CardRepository,
+	creditRepo repository.CreditCardRepository,
+	accountRepo repository.AccountRepository,
+	transactionRepo repository.TransactionRepository,
+	auditRepo repository.AuditRepository,
+) CreditCardService {
+	return &creditCardService{
+		cardRepo:        cardRepo,
+		creditRepo:      creditRepo,
+		accountRepo:     accountRepo,
+		transactionRepo: transactionRepo,
+		auditRepo:       auditRepo,
+	}
+}
+
+// Repay pays down a credit card balance from one of the user's deposit accounts
+func (s *creditCardService) Repay(userID uuid.UUID, cardID uuid.UUID, req *card.RepayCardRequest) (*transaction.Transaction, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	fromAccountID, err := uuid.Parse(req.FromAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from_account_id")
+	}
+
+	// Check idempotency
+	if existing, err := s.transactionRepo.GetByIdempotencyKey(req.IdempotencyKey); err == nil {
+		return existing, nil
+	}
+
+	c, err := s.getOwnedCreditCard(userID, cardID)
+	if err != nil {
+		return nil, err
+	}
+
+	fromAccount, err := s.accountRepo.GetByID(fromAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("account not found")
+	}
+	if fromAccount.UserID != userID {
+		return nil, fmt.Errorf("unauthorized: account does not belong to user")
+	}
+
+	if req.Amount > c.OutstandingBalance {
+		return nil, fmt.Errorf("repayment exceeds outstanding balance of %.2f", c.OutstandingBalance)
+	}
+
+	txn := &transaction.Transaction{
+		ID:              uuid.New(),
+		IdempotencyKey:  req.IdempotencyKey,
+		FromAccountID:   &fromAccountID,
+		Amount:          req.Amount,
+		TransactionType: transaction.TransactionTypeCardRepayment,
+		Status:          transaction.TransactionStatusPending,
+		Description:     "Credit card repayment",
+		Metadata: map[string]interface{}{
+			"initiated_by": userID.String(),
+			"card_id":      cardID.String(),
+			"currency":     fromAccount.Currency,
+		},
+	}
+
+	if err := s.creditRepo.ExecuteRepayment(cardID, fromAccountID, req.Amount, txn); err != nil {
+		return nil, err
+	}
+
+	if err := s.auditRepo.Create(&audit.AuditLog{
+		EventID:  uuid.New(),
+		UserID:   &userID,
+		Action:   "CARD_REPAYMENT_COMPLETED",
+		Resource: fmt.Sprintf("transaction:%s", txn.ID),
+		Status:   "success",
+		Metadata: map[string]interface{}{
+			"amount":  req.Amount,
+			"card_id": cardID.String(),
+			"from":    req.FromAccountID,
+		},
+	}); err != nil {
+		logger.Error("Failed to create audit log for card repayment", zap.Error(err))
+		errtrack.CaptureError(err, map[string]string{"component": "credit_card_service", "operation": "audit_log"})
+	}
+
+	return s.transactionRepo.GetByID(txn.ID)
+}
+
+func (s *creditCardService) GetStatements(userID uuid.UUID, cardID uuid.UUID) ([]*card.Statement, error) {
+	if _, err := s.getOwnedCreditCard(userID, cardID); err != nil {
+		return nil, err
+	}
+
+	return s.creditRepo.ListStatements(cardID, maxStatementsListed)
+}
+
+// CloseDueStatements closes the billing cycle of every credit card whose
+// statement day is today. Cards that fail are logged and retried on the next run.
+func (s *creditCardService) CloseDueStatements(now time.Time) (int, error) {
+	if now.Day() > card.MaxStatementDay {
+		return 0, nil
+	}
+
+	periodEnd := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	cards, err := s.creditRepo.ListCardsDueForStatement(now.Day(), periodEnd)
+	if err != nil {
+		return 0, err
+	}
+
+	closed := 0
+	for _, c := range cards {
+		previous, err := s.creditRepo.ListStatements(c.ID, 1)
+		if err != nil {
+			logger.Error("Failed to load previous statement", zap.String("card_id", c.ID.String()), zap.Error(err))
+			continue
+		}
+
+		var last *card.Statement
+		if len(previous) > 0 {
+			last = previous[0]
+		}
+
+		st := card.BuildStatement(c, last, periodEnd)
+		if err := s.creditRepo.CloseStatement(st); err != nil {
+			logger.Error("Failed to close card statement", zap.String("card_id", c.ID.String()), zap.Error(err))
+			errtrack.CaptureError(err, map[string]string{"component": "credit_card_service", "operation": "close_statement"})
+			continue
+		}
+		closed++
+	}
+
+	return closed, nil
+}
+
+func (s *creditCardService) getOwnedCreditCard(userID uuid.UUID, cardID uuid.UUID) (*card.Card, error) {
+	c, err := loadOwnedCard(s.cardRepo, s.accountRepo, userID, cardID)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.CardType != card.CardTypeCredit {
+		return nil, fmt.Errorf("card is not a credit card")
+	}
+
+	return c, nil
+}
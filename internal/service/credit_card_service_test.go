@@ -0,0 +1,193 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	domainAccount "github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/card"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockCreditCardRepository is a mock implementation
+type MockCreditCardRepository struct {
+	mock.Mock
+}
+
+func (m *MockCreditCardRepository) ExecuteRepayment(cardID, fromAccountID uuid.UUID, amount float64, txn *transaction.Transaction) error {
+	args := m.Called(cardID, fromAccountID, amount, txn)
+	return args.Error(0)
+}
+
+func (m *MockCreditCardRepository) CloseStatement(st *card.Statement) error {
+	args := m.Called(st)
+	return args.Error(0)
+}
+
+func (m *MockCreditCardRepository) ListStatements(cardID uuid.UUID, limit int) ([]*card.Statement, error) {
+	args := m.Called(cardID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*card.Statement), args.Error(1)
+}
+
+func (m *MockCreditCardRepository) ListCardsDueForStatement(statementDay int, periodEnd time.Time) ([]*card.Card, error) {
+	args := m.Called(statementDay, periodEnd)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*card.Card), args.Error(1)
+}
+
+func setupCreditCardServiceTest(t *testing.T) (*creditCardService, *MockCardRepository, *MockCreditCardRepository, *MockAccountRepository, *MockTransactionRepository, *MockAuditRepository) {
+	logger.Init("test")
+	cardRepo := new(MockCardRepository)
+	creditRepo := new(MockCreditCardRepository)
+	accountRepo := new(MockAccountRepository)
+	txnRepo := new(MockTransactionRepository)
+	auditRepo := new(MockAuditRepository)
+
+	svc := NewCreditCardService(cardRepo, creditRepo, accountRepo, txnRepo, auditRepo).(*creditCardService)
+	return svc, cardRepo, creditRepo, accountRepo, txnRepo, auditRepo
+}
+
+func TestRepay_Success(t *testing.T) {
+	svc, cardRepo, creditRepo, accountRepo, txnRepo, auditRepo := setupCreditCardServiceTest(t)
+	userID := uuid.New()
+	cardID := uuid.New()
+	cardAccountID := uuid.New()
+	fromAccountID := uuid.New()
+
+	txnRepo.On("GetByIdempotencyKey", "repay-1").Return(nil, fmt.Errorf("not found"))
+	cardRepo.On("GetByID", cardID).Return(&card.Card{
+		ID:                 cardID,
+		AccountID:          cardAccountID,
+		CardType:           card.CardTypeCredit,
+		CreditLimit:        5_000_000,
+		OutstandingBalance: 800_000,
+	}, nil)
+	accountRepo.On("GetByID", cardAccountID).Return(&domainAccount.Account{ID: cardAccountID, UserID: userID}, nil)
+	accountRepo.On("GetByID", fromAccountID).Return(&domainAccount.Account{ID: fromAccountID, UserID: userID, Currency: "IDR"}, nil)
+	creditRepo.On("ExecuteRepayment", cardID, fromAccountID, 300_000.0, mock.MatchedBy(func(txn *transaction.Transaction) bool {
+		return txn.TransactionType == transaction.TransactionTypeCardRepayment && *txn.FromAccountID == fromAccountID
+	})).Return(nil)
+	auditRepo.On("Create", mock.Anything).Return(nil)
+	txnRepo.On("GetByID", mock.AnythingOfType("uuid.UUID")).Return(&transaction.Transaction{
+		Amount:          300_000,
+		TransactionType: transaction.TransactionTypeCardRepayment,
+		Status:          transaction.TransactionStatusCompleted,
+	}, nil)
+
+	txn, err := svc.Repay(userID, cardID, &card.RepayCardRequest{
+		FromAccountID:  fromAccountID.String(),
+		Amount:         300_000,
+		IdempotencyKey: "repay-1",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, transaction.TransactionStatusCompleted, txn.Status)
+	creditRepo.AssertExpectations(t)
+	auditRepo.AssertExpectations(t)
+}
+
+func TestRepay_ExceedsOutstandingBalance(t *testing.T) {
+	svc, cardRepo, creditRepo, accountRepo, txnRepo, _ := setupCreditCardServiceTest(t)
+	userID := uuid.New()
+	cardID := uuid.New()
+	accountID := uuid.New()
+
+	txnRepo.On("GetByIdempotencyKey", "repay-2").Return(nil, fmt.Errorf("not found"))
+	cardRepo.On("GetByID", cardID).Return(&card.Card{
+		ID:                 cardID,
+		AccountID:          accountID,
+		CardType:           card.CardTypeCredit,
+		OutstandingBalance: 100_000,
+	}, nil)
+	accountRepo.On("GetByID", accountID).Return(&domainAccount.Account{ID: accountID, UserID: userID}, nil)
+
+	txn, err := svc.Repay(userID, cardID, &card.RepayCardRequest{
+		FromAccountID:  accountID.String(),
+		Amount:         150_000,
+		IdempotencyKey: "repay-2",
+	})
+	assert.Error(t, err)
+	assert.Nil(t, txn)
+	assert.Contains(t, err.Error(), "exceeds outstanding balance")
+	creditRepo.AssertNotCalled(t, "ExecuteRepayment", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRepay_DebitCard(t *testing.T) {
+	svc, cardRepo, _, accountRepo, txnRepo, _ := setupCreditCardServiceTest(t)
+	userID := uuid.New()
+	cardID := uuid.New()
+	accountID := uuid.New()
+
+	txnRepo.On("GetByIdempotencyKey", "repay-3").Return(nil, fmt.Errorf("not found"))
+	cardRepo.On("GetByID", cardID).Return(&card.Card{ID: cardID, AccountID: accountID, CardType: card.CardTypeDebit}, nil)
+	accountRepo.On("GetByID", accountID).Return(&domainAccount.Account{ID: accountID, UserID: userID}, nil)
+
+	txn, err := svc.Repay(userID, cardID, &card.RepayCardRequest{
+		FromAccountID:  accountID.String(),
+		Amount:         1000,
+		IdempotencyKey: "repay-3",
+	})
+	assert.Error(t, err)
+	assert.Nil(t, txn)
+	assert.Contains(t, err.Error(), "not a credit card")
+}
+
+func TestRepay_Idempotent(t *testing.T) {
+	svc, _, creditRepo, _, txnRepo, _ := setupCreditCardServiceTest(t)
+	existing := &transaction.Transaction{ID: uuid.New(), IdempotencyKey: "repay-4"}
+
+	txnRepo.On("GetByIdempotencyKey", "repay-4").Return(existing, nil)
+
+	txn, err := svc.Repay(uuid.New(), uuid.New(), &card.RepayCardRequest{
+		FromAccountID:  uuid.New().String(),
+		Amount:         1000,
+		IdempotencyKey: "repay-4",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, existing, txn)
+	creditRepo.AssertNotCalled(t, "ExecuteRepayment", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCloseDueStatements(t *testing.T) {
+	svc, _, creditRepo, _, _, _ := setupCreditCardServiceTest(t)
+	now := time.Date(2026, 2, 10, 2, 0, 0, 0, time.UTC)
+	periodEnd := time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC)
+
+	revolving := &card.Card{ID: uuid.New(), CardType: card.CardTypeCredit, OutstandingBalance: 600_000, CyclePayments: 400_000}
+	failing := &card.Card{ID: uuid.New(), CardType: card.CardTypeCredit}
+
+	creditRepo.On("ListCardsDueForStatement", 10, periodEnd).Return([]*card.Card{revolving, failing}, nil)
+	creditRepo.On("ListStatements", revolving.ID, 1).Return([]*card.Statement{
+		{PeriodEnd: time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC), ClosingBalance: 1_000_000},
+	}, nil)
+	creditRepo.On("ListStatements", failing.ID, 1).Return([]*card.Statement{}, nil)
+	creditRepo.On("CloseStatement", mock.MatchedBy(func(st *card.Statement) bool {
+		return st.CardID == revolving.ID && st.Interest > 0 && st.OpeningBalance == 1_000_000
+	})).Return(nil)
+	creditRepo.On("CloseStatement", mock.MatchedBy(func(st *card.Statement) bool {
+		return st.CardID == failing.ID
+	})).Return(fmt.Errorf("db down"))
+
+	closed, err := svc.CloseDueStatements(now)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, closed)
+	creditRepo.AssertExpectations(t)
+}
+
+func TestCloseDueStatements_SkipsLateMonthDays(t *testing.T) {
+	svc, _, creditRepo, _, _, _ := setupCreditCardServiceTest(t)
+
+	closed, err := svc.CloseDueStatements(time.Date(2026, 1, 30, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, closed)
+	creditRepo.AssertNotCalled(t, "ListCardsDueForStatement", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,388 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/audit"
+	"github.com/darisadam/madabank-server/internal/domain/export"
+	"github.com/darisadam/madabank-server/internal/domain/queue"
+	"github.com/darisadam/madabank-server/internal/domain/statement"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/pkg/notifier"
+	"github.com/darisadam/madabank-server/internal/pkg/objectstore"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	maxExportsListed = 50
+	// exportBatchSize is how many accounts or transactions are loaded at a
+	// time, and so how often progress is saved
+	exportBatchSize    = 500
+	exportEmailTimeout = time.Minute
+)
+
+type ExportService interface {
+	// RequestExport records an export of all statements or all transactions
+	// for a period and queues it to run in the background
+	RequestExport(userID uuid.UUID, req *export.CreateExportRequest) (*export.Export, error)
+	ListExports() ([]*export.Export, error)
+	GetExport(id uuid.UUID) (*export.Export, error)
+	// RunExport writes the export to object storage, saving its progress as
+	// it goes, and emails the admin who requested it when it completes or
+	// fails. Completed exports are not run again.
+	RunExport(ctx context.Context, id uuid.UUID) error
+}
+
+type exportService struct {
+	exportRepo      repository.ExportRepository
+	jobRepo         repository.JobRepository
+	accountRepo     repository.AccountRepository
+	transactionRepo repository.TransactionRepository
+	userRepo        repository.UserRepository
+	auditRepo       repository.AuditRepository
+	notifier        notifier.Notifier
+	store           objectstore.Store // nil when exports have no storage
+	bankName        string
+	zone            *time.Location // period days start at midnight in this zone
+}
+
+func NewExportService(
+	exportRepo repository.ExportRepository,
+	jobRepo repository.JobRepository,
+	accountRepo repository.AccountRepository,
+	transactionRepo repository.TransactionRepository,
+	userRepo repository.UserRepository,
+	auditRepo repository.AuditRepository,
+	notifier notifier.Notifier,
+	store objectstore.Store,
+	bankName string,
+	zone *time.Location,
+) ExportService {
+	return &exportService{
+		exportRepo:      exportRepo,
+		jobRepo:         jobRepo,
+		accountRepo:     accountRepo,
+		transactionRepo: transactionRepo,
+		userRepo:        userRepo,
+		auditRepo:       auditRepo,
+		notifier:        notifier,
+		store:           store,
+		bankName:        bankName,
+		zone:            zone,
+	}
+}
+
+func (s *exportService) RequestExport(userID uuid.UUID, req *export.CreateExportRequest) (*export.Export, error) {
+	if s.store == nil {
+		return nil, fmt.Errorf("exports are not configured: set EXPORT_BUCKET or EXPORT_DIR")
+	}
+
+	periodStart, err := time.ParseInLocation(export.DateLayout, req.From, s.zone)
+	if err != nil {
+		return nil, fmt.Errorf("from must be formatted as YYYY-MM-DD")
+	}
+	periodEnd, err := time.ParseInLocation(export.DateLayout, req.To, s.zone)
+	if err != nil {
+		return nil, fmt.Errorf("to must be formatted as YYYY-MM-DD")
+	}
+	if periodEnd.Before(periodStart) {
+		return nil, fmt.Errorf("to must not be before from")
+	}
+	if periodEnd.After(time.Now().In(s.zone)) {
+		return nil, fmt.Errorf("to must not be in the future")
+	}
+	if periodEnd.After(periodStart.AddDate(0, 0, export.MaxPeriodDays-1)) {
+		return nil, fmt.Errorf("export period cannot exceed %d days", export.MaxPeriodDays)
+	}
+
+	e := &export.Export{
+		ID:          uuid.New(),
+		Kind:        req.Kind,
+		PeriodStart: periodStart,
+		PeriodEnd:   periodEnd,
+		Status:      export.StatusPending,
+		RequestedBy: userID,
+	}
+	if err := s.exportRepo.Create(e); err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(export.JobPayload{ExportID: e.ID})
+	if err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("%s:%s", export.JobKind, e.ID)
+	if _, err := s.jobRepo.Enqueue(&queue.Job{
+		ID:          uuid.New(),
+		Kind:        export.JobKind,
+		Payload:     payload,
+		MaxAttempts: 1,
+		RunAt:       time.Now(),
+		UniqueKey:   &key,
+	}); err != nil {
+		err = fmt.Errorf("failed to queue export: %w", err)
+		if failErr := s.exportRepo.Fail(e.ID, err.Error(), time.Now()); failErr != nil {
+			logger.Error("Failed to record export failure", zap.String("export_id", e.ID.String()), zap.Error(failErr))
+		}
+		return nil, err
+	}
+
+	s.audit(userID, "BULK_EXPORT_REQUESTED", e.ID, map[string]interface{}{
+		"kind": e.Kind,
+		"from": req.From,
+		"to":   req.To,
+	})
+
+	return e, nil
+}
+
+func (s *exportService) ListExports() ([]*export.Export, error) {
+	return s.exportRepo.List(maxExportsListed)
+}
+
+func (s *exportService) GetExport(id uuid.UUID) (*export.Export, error) {
+	return s.exportRepo.GetByID(id)
+}
+
+func (s *exportService) RunExport(ctx context.Context, id uuid.UUID) error {
+	e, err := s.exportRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if e.Status == export.StatusCompleted {
+		return nil
+	}
+	if s.store == nil {
+		err := fmt.Errorf("exports are not configured")
+		s.fail(e, err)
+		return err
+	}
+
+	// Period dates are stored without a zone; the days run midnight to
+	// midnight in the service's zone
+	from := time.Date(e.PeriodStart.Year(), e.PeriodStart.Month(), e.PeriodStart.Day(), 0, 0, 0, 0, s.zone)
+	to := time.Date(e.PeriodEnd.Year(), e.PeriodEnd.Month(), e.PeriodEnd.Day(), 0, 0, 0, 0, s.zone).AddDate(0, 0, 1)
+
+	var location string
+	switch e.Kind {
+	case export.KindStatements:
+		location, err = s.exportStatements(ctx, e, from, to)
+	case export.KindTransactions:
+		location, err = s.exportTransactions(ctx, e, from, to)
+	default:
+		err = fmt.Errorf("unknown export kind %q", e.Kind)
+	}
+	if err != nil {
+		s.fail(e, err)
+		return err
+	}
+
+	now := time.Now()
+	if err := s.exportRepo.Complete(e.ID, e.Processed, location, now); err != nil {
+		return err
+	}
+	e.Status, e.Location, e.CompletedAt = export.StatusCompleted, location, &now
+
+	logger.Info("Bulk export completed",
+		zap.String("export_id", e.ID.String()),
+		zap.String("kind", string(e.Kind)),
+		zap.Int("processed", e.Processed),
+		zap.String("location", location),
+	)
+	s.notify(e)
+	return nil
+}
+
+// exportStatements renders every account's statement for the period to a
+// PDF under the export's prefix
+func (s *exportService) exportStatements(ctx context.Context, e *export.Export, from, to time.Time) (string, error) {
+	total, err := s.exportRepo.CountAccounts(to)
+	if err != nil {
+		return "", err
+	}
+	if err := s.start(e, total); err != nil {
+		return "", err
+	}
+
+	prefix := e.Prefix() + "statements/"
+	after := uuid.Nil
+	for {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		accounts, err := s.exportRepo.ListAccountsAfter(to, after, exportBatchSize)
+		if err != nil {
+			return "", err
+		}
+		if len(accounts) == 0 {
+			break
+		}
+
+		names, err := s.holderNames(accounts)
+		if err != nil {
+			return "", err
+		}
+		// The statements work the balances back from the current one, so
+		// they need everything posted since the period started
+		now := time.Now()
+		for _, acct := range accounts {
+			txns, err := s.transactionRepo.ListCompletedByAccount(acct.ID, from, now)
+			if err != nil {
+				return "", err
+			}
+			st := statement.Build(acct, names[acct.ID], e.Label(), from, to, txns)
+			if err := s.store.Put(ctx, prefix+st.FileName(), st.Render(s.bankName, s.zone), "application/pdf"); err != nil {
+				return "", err
+			}
+		}
+
+		after = accounts[len(accounts)-1].ID
+		s.progress(e, e.Processed+len(accounts))
+	}
+
+	return prefix, nil
+}
+
+// exportTransactions writes every transaction created in the period, in
+// any status, to one CSV file
+func (s *exportService) exportTransactions(ctx context.Context, e *export.Export, from, to time.Time) (string, error) {
+	total, err := s.exportRepo.CountTransactions(from, to)
+	if err != nil {
+		return "", err
+	}
+	if err := s.start(e, total); err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	w, err := export.NewTransactionWriter(&buf)
+	if err != nil {
+		return "", err
+	}
+	var after *transaction.Transaction
+	for {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		txns, err := s.exportRepo.ListTransactionsAfter(from, to, after, exportBatchSize)
+		if err != nil {
+			return "", err
+		}
+		if len(txns) == 0 {
+			break
+		}
+		for _, txn := range txns {
+			if err := w.Write(txn); err != nil {
+				return "", err
+			}
+		}
+
+		after = txns[len(txns)-1]
+		s.progress(e, e.Processed+len(txns))
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+
+	key := e.Prefix() + "transactions-" + e.Label() + ".csv"
+	if err := s.store.Put(ctx, key, buf.Bytes(), "text/csv"); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+func (s *exportService) start(e *export.Export, total int) error {
+	now := time.Now()
+	if err := s.exportRepo.Start(e.ID, total, now); err != nil {
+		return err
+	}
+	e.Status, e.Total, e.Processed, e.StartedAt = export.StatusRunning, total, 0, &now
+	return nil
+}
+
+// progress saves how far the export got. Failures are logged; the export
+// carries on.
+func (s *exportService) progress(e *export.Export, processed int) {
+	e.Processed = processed
+	if err := s.exportRepo.UpdateProgress(e.ID, processed); err != nil {
+		logger.Warn("Failed to save export progress", zap.String("export_id", e.ID.String()), zap.Error(err))
+	}
+}
+
+// fail records why the export failed and tells the admin who requested it
+func (s *exportService) fail(e *export.Export, cause error) {
+	logger.Error("Bulk export failed", zap.String("export_id", e.ID.String()), zap.String("kind", string(e.Kind)), zap.Error(cause))
+	if err := s.exportRepo.Fail(e.ID, cause.Error(), time.Now()); err != nil {
+		logger.Error("Failed to record export failure", zap.String("export_id", e.ID.String()), zap.Error(err))
+		errtrack.CaptureError(err, map[string]string{"component": "export_service", "operation": "record_failure"})
+	}
+	e.Status, e.Error = export.StatusFailed, cause.Error()
+	s.notify(e)
+}
+
+// holderNames maps each account to its holder's full name
+func (s *exportService) holderNames(accounts []*account.Account) (map[uuid.UUID]string, error) {
+	ids := make([]uuid.UUID, 0, len(accounts))
+	for _, acct := range accounts {
+		ids = append(ids, acct.ID)
+	}
+	holders, err := s.accountRepo.GetHolders(ids)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[uuid.UUID]string, len(holders))
+	for _, h := range holders {
+		names[h.AccountID] = h.FirstName + " " + h.LastName
+	}
+	return names, nil
+}
+
+// notify emails the admin who requested the export that it finished.
+// Failures are logged and do not change the export.
+func (s *exportService) notify(e *export.Export) {
+	admin, err := s.userRepo.GetByID(e.RequestedBy)
+	if err != nil {
+		logger.Warn("Failed to load export requester for notification", zap.String("export_id", e.ID.String()), zap.Error(err))
+		return
+	}
+
+	email := &notifier.Email{To: admin.Email}
+	if e.Status == export.StatusCompleted {
+		email.Subject = fmt.Sprintf("%s %s export for %s is ready", s.bankName, e.Kind, e.Label())
+		email.Body = fmt.Sprintf("Hello %s,\n\nThe %s export %s for %s to %s finished with %d items.\nIt is stored at %s.\n",
+			admin.FirstName, e.Kind, e.ID, e.PeriodStart.Format(export.DateLayout), e.PeriodEnd.Format(export.DateLayout), e.Processed, e.Location)
+	} else {
+		email.Subject = fmt.Sprintf("%s %s export for %s failed", s.bankName, e.Kind, e.Label())
+		email.Body = fmt.Sprintf("Hello %s,\n\nThe %s export %s for %s to %s failed after %d of %d items: %s\n",
+			admin.FirstName, e.Kind, e.ID, e.PeriodStart.Format(export.DateLayout), e.PeriodEnd.Format(export.DateLayout), e.Processed, e.Total, e.Error)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), exportEmailTimeout)
+	defer cancel()
+	if err := s.notifier.SendEmail(ctx, email); err != nil {
+		logger.Warn("Failed to send export notification", zap.String("export_id", e.ID.String()), zap.Error(err))
+	}
+}
+
+func (s *exportService) audit(userID uuid.UUID, action string, exportID uuid.UUID, metadata map[string]interface{}) {
+	if err := s.auditRepo.Create(&audit.AuditLog{
+		EventID:  uuid.New(),
+		UserID:   &userID,
+		Action:   action,
+		Resource: fmt.Sprintf("export:%s", exportID),
+		Status:   "success",
+		Metadata: metadata,
+	}); err != nil {
+		logger.Error("Failed to create audit log for export", zap.String("action", action), zap.Error(err))
+		errtrack.CaptureError(err, map[string]string{"component": "export_service", "operation": "audit_log"})
+	}
+}
@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/audit"
+	"github.com/darisadam/madabank-server/internal/domain/export"
+	"github.com/darisadam/madabank-server/internal/domain/queue"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/domain/user"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/pkg/notifier"
+	"github.com/darisadam/madabank-server/internal/pkg/objectstore"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockExportRepository is a mock implementation of repository.ExportRepository
+type MockExportRepository struct {
+	mock.Mock
+}
+
+func (m *MockExportRepository) Create(e *export.Export) error {
+	args := m.Called(e)
+	return args.Error(0)
+}
+
+func (m *MockExportRepository) GetByID(id uuid.UUID) (*export.Export, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*export.Export), args.Error(1)
+}
+
+func (m *MockExportRepository) List(limit int) ([]*export.Export, error) {
+	args := m.Called(limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*export.Export), args.Error(1)
+}
+
+func (m *MockExportRepository) Start(id uuid.UUID, total int, now time.Time) error {
+	args := m.Called(id, total, now)
+	return args.Error(0)
+}
+
+func (m *MockExportRepository) UpdateProgress(id uuid.UUID, processed int) error {
+	args := m.Called(id, processed)
+	return args.Error(0)
+}
+
+func (m *MockExportRepository) Complete(id uuid.UUID, processed int, location string, now time.Time) error {
+	args := m.Called(id, processed, location, now)
+	return args.Error(0)
+}
+
+func (m *MockExportRepository) Fail(id uuid.UUID, reason string, now time.Time) error {
+	args := m.Called(id, reason, now)
+	return args.Error(0)
+}
+
+func (m *MockExportRepository) CountAccounts(before time.Time) (int, error) {
+	args := m.Called(before)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockExportRepository) ListAccountsAfter(before time.Time, afterID uuid.UUID, limit int) ([]*account.Account, error) {
+	args := m.Called(before, afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*account.Account), args.Error(1)
+}
+
+func (m *MockExportRepository) CountTransactions(from, to time.Time) (int, error) {
+	args := m.Called(from, to)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockExportRepository) ListTransactionsAfter(from, to time.Time, after *transaction.Transaction, limit int) ([]*transaction.Transaction, error) {
+	args := m.Called(from, to, after, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*transaction.Transaction), args.Error(1)
+}
+
+type exportTest struct {
+	svc         ExportService
+	exportRepo  *MockExportRepository
+	jobRepo     *MockJobRepository
+	accountRepo *MockAccountRepository
+	txnRepo     *MockTransactionRepository
+	userRepo    *MockUserRepository
+	auditRepo   *MockAuditRepository
+	notifier    *MockNotifier
+	store       *objectstore.FileStore
+}
+
+func setupExportTest(t *testing.T) *exportTest {
+	t.Helper()
+	logger.Init("test")
+	et := &exportTest{
+		exportRepo:  new(MockExportRepository),
+		jobRepo:     new(MockJobRepository),
+		accountRepo: new(MockAccountRepository),
+		txnRepo:     new(MockTransactionRepository),
+		userRepo:    new(MockUserRepository),
+		auditRepo:   new(MockAuditRepository),
+		notifier:    new(MockNotifier),
+		store:       objectstore.NewFileStore(t.TempDir()),
+	}
+	et.svc = NewExportService(et.exportRepo, et.jobRepo, et.accountRepo, et.txnRepo, et.userRepo, et.auditRepo,
+		et.notifier, et.store, "MadaBank", time.UTC)
+	return et
+}
+
+func TestExportService_RequestExport(t *testing.T) {
+	et := setupExportTest(t)
+	adminID := uuid.New()
+
+	et.exportRepo.On("Create", mock.MatchedBy(func(e *export.Export) bool {
+		return e.Kind == export.KindTransactions && e.Status == export.StatusPending &&
+			e.PeriodStart.Format(export.DateLayout) == "2024-01-01" &&
+			e.PeriodEnd.Format(export.DateLayout) == "2024-03-31" &&
+			e.RequestedBy == adminID
+	})).Return(nil)
+	et.jobRepo.On("Enqueue", mock.MatchedBy(func(job *queue.Job) bool {
+		var p export.JobPayload
+		return job.Kind == export.JobKind && job.MaxAttempts == 1 &&
+			json.Unmarshal(job.Payload, &p) == nil && p.ExportID != uuid.Nil
+	})).Return(true, nil)
+	et.auditRepo.On("Create", mock.MatchedBy(func(l *audit.AuditLog) bool {
+		return l.Action == "BULK_EXPORT_REQUESTED" && l.Metadata["kind"] == export.KindTransactions
+	})).Return(nil)
+
+	e, err := et.svc.RequestExport(adminID, &export.CreateExportRequest{Kind: export.KindTransactions, From: "2024-01-01", To: "2024-03-31"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, export.StatusPending, e.Status)
+	et.jobRepo.AssertExpectations(t)
+	et.auditRepo.AssertExpectations(t)
+}
+
+func TestExportService_RequestExport_InvalidPeriod(t *testing.T) {
+	et := setupExportTest(t)
+	future := time.Now().AddDate(0, 0, 2).Format(export.DateLayout)
+
+	for name, tc := range map[string]struct {
+		from, to, err string
+	}{
+		"bad date":    {"01/01/2024", "2024-01-31", "from must be formatted as YYYY-MM-DD"},
+		"reversed":    {"2024-02-01", "2024-01-31", "to must not be before from"},
+		"future":      {"2024-01-01", future, "to must not be in the future"},
+		"over a year": {"2022-01-01", "2023-12-31", "export period cannot exceed 366 days"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := et.svc.RequestExport(uuid.New(), &export.CreateExportRequest{Kind: export.KindStatements, From: tc.from, To: tc.to})
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+	et.exportRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestExportService_RequestExport_NoStorage(t *testing.T) {
+	svc := NewExportService(new(MockExportRepository), new(MockJobRepository), new(MockAccountRepository), new(MockTransactionRepository),
+		new(MockUserRepository), new(MockAuditRepository), new(MockNotifier), nil, "MadaBank", time.UTC)
+
+	_, err := svc.RequestExport(uuid.New(), &export.CreateExportRequest{Kind: export.KindStatements, From: "2024-01-01", To: "2024-01-31"})
+
+	assert.ErrorContains(t, err, "exports are not configured")
+}
+
+func TestExportService_RunExport_Transactions(t *testing.T) {
+	et := setupExportTest(t)
+	adminID := uuid.New()
+	e := &export.Export{
+		ID:          uuid.New(),
+		Kind:        export.KindTransactions,
+		PeriodStart: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:   time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+		Status:      export.StatusPending,
+		RequestedBy: adminID,
+	}
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	accountID := uuid.New()
+	txns := []*transaction.Transaction{
+		{ID: uuid.New(), ToAccountID: &accountID, Amount: 150000, TransactionType: transaction.TransactionTypeDeposit,
+			Status: transaction.TransactionStatusCompleted, Description: "Salary", CreatedAt: from.Add(time.Hour)},
+		{ID: uuid.New(), FromAccountID: &accountID, Amount: 2500, TransactionType: transaction.TransactionTypeWithdrawal,
+			Status: transaction.TransactionStatusFailed, CreatedAt: from.Add(2 * time.Hour)},
+	}
+	key := e.Prefix() + "transactions-2024-01-01_2024-01-31.csv"
+
+	et.exportRepo.On("GetByID", e.ID).Return(e, nil)
+	et.exportRepo.On("CountTransactions", from, to).Return(2, nil)
+	et.exportRepo.On("Start", e.ID, 2, mock.Anything).Return(nil)
+	et.exportRepo.On("ListTransactionsAfter", from, to, (*transaction.Transaction)(nil), exportBatchSize).Return(txns, nil)
+	et.exportRepo.On("UpdateProgress", e.ID, 2).Return(nil)
+	et.exportRepo.On("ListTransactionsAfter", from, to, txns[1], exportBatchSize).Return([]*transaction.Transaction{}, nil)
+	et.exportRepo.On("Complete", e.ID, 2, key, mock.Anything).Return(nil)
+	et.userRepo.On("GetByID", adminID).Return(&user.User{ID: adminID, Email: "ops@madabank.id", FirstName: "Ops"}, nil)
+	et.notifier.On("SendEmail", mock.MatchedBy(func(m *notifier.Email) bool {
+		return m.To == "ops@madabank.id" && strings.Contains(m.Subject, "is ready") && strings.Contains(m.Body, key)
+	})).Return(nil)
+
+	err := et.svc.RunExport(context.Background(), e.ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, export.StatusCompleted, e.Status)
+	body, err := et.store.Get(context.Background(), key)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+	assert.Len(t, lines, 3)
+	assert.True(t, strings.HasPrefix(lines[0], "transaction_id,created_at,"))
+	assert.Contains(t, lines[1], "deposit,completed,150000.00")
+	assert.Contains(t, lines[2], "withdrawal,failed,2500.00")
+	et.exportRepo.AssertExpectations(t)
+	et.notifier.AssertExpectations(t)
+}
+
+func TestExportService_RunExport_Statements(t *testing.T) {
+	et := setupExportTest(t)
+	adminID := uuid.New()
+	e := &export.Export{
+		ID:          uuid.New(),
+		Kind:        export.KindStatements,
+		PeriodStart: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:   time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+		Status:      export.StatusPending,
+		RequestedBy: adminID,
+	}
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	acct := &account.Account{ID: uuid.New(), AccountNumber: "1234567890", Currency: "IDR", Balance: 150000}
+
+	et.exportRepo.On("GetByID", e.ID).Return(e, nil)
+	et.exportRepo.On("CountAccounts", to).Return(1, nil)
+	et.exportRepo.On("Start", e.ID, 1, mock.Anything).Return(nil)
+	et.exportRepo.On("ListAccountsAfter", to, uuid.Nil, exportBatchSize).Return([]*account.Account{acct}, nil)
+	et.accountRepo.On("GetHolders", []uuid.UUID{acct.ID}).Return([]*account.Holder{{AccountID: acct.ID, FirstName: "Siti", LastName: "Rahma"}}, nil)
+	et.txnRepo.On("ListCompletedByAccount", acct.ID, from, mock.Anything).Return([]*transaction.Transaction{}, nil)
+	et.exportRepo.On("UpdateProgress", e.ID, 1).Return(nil)
+	et.exportRepo.On("ListAccountsAfter", to, acct.ID, exportBatchSize).Return([]*account.Account{}, nil)
+	et.exportRepo.On("Complete", e.ID, 1, e.Prefix()+"statements/", mock.Anything).Return(nil)
+	et.userRepo.On("GetByID", adminID).Return(&user.User{ID: adminID, Email: "ops@madabank.id"}, nil)
+	et.notifier.On("SendEmail", mock.Anything).Return(nil)
+
+	err := et.svc.RunExport(context.Background(), e.ID)
+
+	assert.NoError(t, err)
+	pdf, err := et.store.Get(context.Background(), e.Prefix()+"statements/statement-1234567890-2024-01-01_2024-01-31.pdf")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(pdf), "%PDF"))
+	et.exportRepo.AssertExpectations(t)
+}
+
+func TestExportService_RunExport_FailureIsRecordedAndNotified(t *testing.T) {
+	et := setupExportTest(t)
+	adminID := uuid.New()
+	e := &export.Export{
+		ID:          uuid.New(),
+		Kind:        export.KindTransactions,
+		PeriodStart: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Status:      export.StatusPending,
+		RequestedBy: adminID,
+	}
+
+	et.exportRepo.On("GetByID", e.ID).Return(e, nil)
+	et.exportRepo.On("CountTransactions", mock.Anything, mock.Anything).Return(0, fmt.Errorf("db down"))
+	et.exportRepo.On("Fail", e.ID, "db down", mock.Anything).Return(nil)
+	et.userRepo.On("GetByID", adminID).Return(&user.User{ID: adminID, Email: "ops@madabank.id"}, nil)
+	et.notifier.On("SendEmail", mock.MatchedBy(func(m *notifier.Email) bool {
+		return strings.Contains(m.Subject, "failed") && strings.Contains(m.Body, "db down")
+	})).Return(nil)
+
+	err := et.svc.RunExport(context.Background(), e.ID)
+
+	assert.EqualError(t, err, "db down")
+	assert.Equal(t, export.StatusFailed, e.Status)
+	et.exportRepo.AssertExpectations(t)
+	et.notifier.AssertExpectations(t)
+}
+
+func TestExportService_RunExport_SkipsCompleted(t *testing.T) {
+	et := setupExportTest(t)
+	e := &export.Export{ID: uuid.New(), Kind: export.KindTransactions, Status: export.StatusCompleted}
+	et.exportRepo.On("GetByID", e.ID).Return(e, nil)
+
+	assert.NoError(t, et.svc.RunExport(context.Background(), e.ID))
+	et.exportRepo.AssertNotCalled(t, "Start", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/darisadam/madabank-server/internal/domain/fx"
+	"github.com/darisadam/madabank-server/internal/pkg/fxrates"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// FXService quotes indicative exchange rates
+type FXService interface {
+	GetRates(ctx context.Context, req *fx.RatesRequest) (*fx.RatesResponse, error)
+}
+
+type fxService struct {
+	rates *fxrates.Cache
+}
+
+func NewFXService(rates *fxrates.Cache) FXService {
+	return &fxService{rates: rates}
+}
+
+func (s *fxService) GetRates(ctx context.Context, req *fx.RatesRequest) (*fx.RatesResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	snapshot, stale, err := s.rates.Latest(ctx)
+	if err != nil {
+		logger.Error("Failed to get FX rates", zap.Error(err))
+		return nil, fx.ErrRatesUnavailable
+	}
+
+	rates, err := snapshot.Rebase(req.Base)
+	if err != nil {
+		return nil, fmt.Errorf("no exchange rates for %s", req.Base)
+	}
+
+	return &fx.RatesResponse{
+		Base:  req.Base,
+		Rates: rates,
+		AsOf:  snapshot.AsOf,
+		Stale: stale,
+	}, nil
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/darisadam/madabank-server/internal/domain/fx"
+	"github.com/darisadam/madabank-server/internal/pkg/fxrates"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// unreachableFXProvider fails every fetch, like a provider that is down
+type unreachableFXProvider struct{}
+
+func (unreachableFXProvider) Latest(ctx context.Context) (*fxrates.Snapshot, error) {
+	return nil, fmt.Errorf("connection refused")
+}
+
+func (unreachableFXProvider) Name() string {
+	return "unreachable"
+}
+
+func setupFXServiceTest(t *testing.T, provider fxrates.Provider) FXService {
+	logger.Init("test")
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	t.Cleanup(mr.Close)
+
+	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	return NewFXService(fxrates.NewCache(provider, redisClient, fxrates.DefaultConfig))
+}
+
+func TestGetRates_DefaultBase(t *testing.T) {
+	fxService := setupFXServiceTest(t, fxrates.NewFixtureProvider())
+
+	rates, err := fxService.GetRates(context.Background(), &fx.RatesRequest{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "IDR", rates.Base)
+	assert.NotContains(t, rates.Rates, "IDR")
+	assert.InDelta(t, 1.0/16300, rates.Rates["USD"], 1e-9)
+	assert.False(t, rates.Stale)
+}
+
+func TestGetRates_Base(t *testing.T) {
+	fxService := setupFXServiceTest(t, fxrates.NewFixtureProvider())
+
+	rates, err := fxService.GetRates(context.Background(), &fx.RatesRequest{Base: "USD"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "USD", rates.Base)
+	assert.Equal(t, 16300.0, rates.Rates["IDR"])
+}
+
+func TestGetRates_UnsupportedBase(t *testing.T) {
+	fxService := setupFXServiceTest(t, fxrates.NewFixtureProvider())
+
+	_, err := fxService.GetRates(context.Background(), &fx.RatesRequest{Base: "XYZ"})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "base")
+}
+
+func TestGetRates_Unavailable(t *testing.T) {
+	fxService := setupFXServiceTest(t, unreachableFXProvider{})
+
+	_, err := fxService.GetRates(context.Background(), &fx.RatesRequest{})
+
+	assert.ErrorIs(t, err, fx.ErrRatesUnavailable)
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/audit"
+	"github.com/darisadam/madabank-server/internal/domain/gl"
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type GeneralLedgerService interface {
+	// ExportJournal builds the journal entries for the transactions completed
+	// on date (YYYY-MM-DD)
+	ExportJournal(userID uuid.UUID, date string) (*gl.Journal, error)
+}
+
+type generalLedgerService struct {
+	txnRepo   repository.TransactionRepository
+	auditRepo repository.AuditRepository
+	zone      *time.Location // accounting days start at midnight in this zone
+}
+
+func NewGeneralLedgerService(
+	txnRepo repository.TransactionRepository,
+	auditRepo repository.AuditRepository,
+	zone *time.Location,
+) GeneralLedgerService {
+	return &generalLedgerService{
+		txnRepo:   txnRepo,
+		auditRepo: auditRepo,
+		zone:      zone,
+	}
+}
+
+func (s *generalLedgerService) ExportJournal(userID uuid.UUID, date string) (*gl.Journal, error) {
+	dayStart, err := time.ParseInLocation(gl.DateLayout, date, s.zone)
+	if err != nil {
+		return nil, fmt.Errorf("date must be formatted as YYYY-MM-DD")
+	}
+	now := time.Now()
+	if dayStart.After(now) {
+		return nil, fmt.Errorf("date cannot be in the future")
+	}
+
+	txns, err := s.txnRepo.ListCompleted(dayStart, dayStart.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	journal := gl.BuildJournal(dayStart, DefaultCurrency, txns, now)
+
+	s.audit(userID, "GL_EXPORTED", fmt.Sprintf("gl_journal:%s", journal.Date), map[string]interface{}{
+		"entries":      len(journal.Entries),
+		"total_debit":  journal.TotalDebit,
+		"total_credit": journal.TotalCredit,
+	})
+
+	return journal, nil
+}
+
+func (s *generalLedgerService) audit(userID uuid.UUID, action, resource string, metadata map[string]interface{}) {
+	if err := s.auditRepo.Create(&audit.AuditLog{
+		EventID:  uuid.New(),
+		UserID:   &userID,
+		Action:   action,
+		Resource: resource,
+		Status:   "success",
+		Metadata: metadata,
+	}); err != nil {
+		logger.Error("Failed to create audit log for general ledger export", zap.String("action", action), zap.Error(err))
+		errtrack.CaptureError(err, map[string]string{"component": "general_ledger_service", "operation": "audit_log"})
+	}
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/audit"
+	"github.com/darisadam/madabank-server/internal/domain/gl"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func setupGeneralLedgerServiceTest(t *testing.T) (GeneralLedgerService, *MockTransactionRepository, *MockAuditRepository) {
+	logger.Init("test")
+	txnRepo := new(MockTransactionRepository)
+	auditRepo := new(MockAuditRepository)
+	auditRepo.On("Create", mock.Anything).Return(nil)
+
+	return NewGeneralLedgerService(txnRepo, auditRepo, testSettlementZone), txnRepo, auditRepo
+}
+
+func TestExportJournal_UsesAccountingDay(t *testing.T) {
+	svc, txnRepo, auditRepo := setupGeneralLedgerServiceTest(t)
+	userID := uuid.New()
+	dayStart := time.Date(2026, 3, 1, 0, 0, 0, 0, testSettlementZone)
+	to := uuid.New()
+
+	txnRepo.On("ListCompleted", dayStart, dayStart.AddDate(0, 0, 1)).Return([]*transaction.Transaction{
+		{ID: uuid.New(), ToAccountID: &to, Amount: 500000, TransactionType: transaction.TransactionTypeDeposit},
+	}, nil)
+
+	journal, err := svc.ExportJournal(userID, "2026-03-01")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "2026-03-01", journal.Date)
+	assert.Equal(t, DefaultCurrency, journal.Currency)
+	assert.Len(t, journal.Entries, 1)
+	assert.Equal(t, gl.CodeCash, journal.Entries[0].Lines[0].GLCode)
+	assert.Equal(t, journal.TotalDebit, journal.TotalCredit)
+	auditRepo.AssertCalled(t, "Create", mock.MatchedBy(func(l *audit.AuditLog) bool {
+		return l.Action == "GL_EXPORTED" && l.Resource == "gl_journal:2026-03-01" && *l.UserID == userID
+	}))
+}
+
+func TestExportJournal_InvalidDate(t *testing.T) {
+	svc, txnRepo, _ := setupGeneralLedgerServiceTest(t)
+
+	_, err := svc.ExportJournal(uuid.New(), "01/03/2026")
+
+	assert.EqualError(t, err, "date must be formatted as YYYY-MM-DD")
+	txnRepo.AssertNotCalled(t, "ListCompleted", mock.Anything, mock.Anything)
+}
+
+func TestExportJournal_FutureDate(t *testing.T) {
+	svc, txnRepo, _ := setupGeneralLedgerServiceTest(t)
+
+	_, err := svc.ExportJournal(uuid.New(), time.Now().AddDate(0, 0, 2).Format(gl.DateLayout))
+
+	assert.EqualError(t, err, "date cannot be in the future")
+	txnRepo.AssertNotCalled(t, "ListCompleted", mock.Anything, mock.Anything)
+}
+
+func TestExportJournal_RepositoryError(t *testing.T) {
+	svc, txnRepo, auditRepo := setupGeneralLedgerServiceTest(t)
+	txnRepo.On("ListCompleted", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("db down"))
+
+	journal, err := svc.ExportJournal(uuid.New(), "2026-03-01")
+
+	assert.Error(t, err)
+	assert.Nil(t, journal)
+	auditRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// HoldService reserves funds on accounts for payments that settle later, such
+// as card authorizations and bulk transfers. Holds lower the available
+// balance until they are captured, released or expire.
+type HoldService interface {
+	PlaceHold(req *account.PlaceHoldRequest) (*account.Hold, error)
+	GetHold(id uuid.UUID) (*account.Hold, error)
+	ExtendHold(id uuid.UUID, req *account.ExtendHoldRequest) (*account.Hold, error)
+	// CaptureHold debits the held funds, or part of them, and ends the hold
+	CaptureHold(id uuid.UUID, req *account.CaptureHoldRequest) (*account.Hold, error)
+	ReleaseHold(id uuid.UUID) (*account.Hold, error)
+	// ListHolds returns the active holds on a customer's own account
+	ListHolds(accountID, userID uuid.UUID) ([]*account.Hold, error)
+	// ExpireHolds marks the holds past their expiry and returns how many.
+	// Expired holds already stop counting before this runs.
+	ExpireHolds(now time.Time) (int, error)
+}
+
+type holdService struct {
+	holdRepo    repository.HoldRepository
+	accountRepo repository.AccountRepository
+}
+
+func NewHoldService(holdRepo repository.HoldRepository, accountRepo repository.AccountRepository) HoldService {
+	return &holdService{
+		holdRepo:    holdRepo,
+		accountRepo: accountRepo,
+	}
+}
+
+func (s *holdService) PlaceHold(req *account.PlaceHoldRequest) (*account.Hold, error) {
+	accountID, err := uuid.Parse(req.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account ID")
+	}
+
+	h := &account.Hold{
+		ID:        uuid.New(),
+		AccountID: accountID,
+		Amount:    req.Amount,
+		Purpose:   account.HoldPurpose(req.Purpose),
+		Reference: req.Reference,
+		Status:    account.HoldStatusActive,
+		ExpiresAt: time.Now().Add(req.Duration()),
+	}
+	if err := s.holdRepo.Place(h); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Hold placed",
+		zap.String("hold_id", h.ID.String()),
+		zap.String("account_id", accountID.String()),
+		zap.Float64("amount", h.Amount),
+		zap.String("purpose", string(h.Purpose)),
+	)
+
+	return h, nil
+}
+
+func (s *holdService) GetHold(id uuid.UUID) (*account.Hold, error) {
+	return s.holdRepo.GetByID(id)
+}
+
+func (s *holdService) ExtendHold(id uuid.UUID, req *account.ExtendHoldRequest) (*account.Hold, error) {
+	h, err := s.holdRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(time.Duration(req.ExpiresInHours) * time.Hour)
+	if expiresAt.After(h.CreatedAt.Add(account.MaxHoldDuration)) {
+		return nil, fmt.Errorf("holds cannot last more than %d days", int(account.MaxHoldDuration.Hours()/24))
+	}
+
+	if err := s.holdRepo.Extend(id, expiresAt); err != nil {
+		return nil, err
+	}
+
+	return s.holdRepo.GetByID(id)
+}
+
+func (s *holdService) CaptureHold(id uuid.UUID, req *account.CaptureHoldRequest) (*account.Hold, error) {
+	h, err := s.holdRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := req.Amount
+	if amount == 0 {
+		amount = h.Amount
+	}
+	description := req.Description
+	if description == "" {
+		description = "Captured hold"
+	}
+
+	txn := &transaction.Transaction{
+		ID:              uuid.New(),
+		IdempotencyKey:  "hold-capture-" + id.String(), // a hold is captured at most once
+		TransactionType: transaction.TransactionTypeWithdrawal,
+		Status:          transaction.TransactionStatusCompleted,
+		Description:     description,
+		Metadata: map[string]interface{}{
+			"hold_id":   id.String(),
+			"purpose":   string(h.Purpose),
+			"reference": h.Reference,
+		},
+	}
+	if err := s.holdRepo.Capture(id, amount, txn); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Hold captured",
+		zap.String("hold_id", id.String()),
+		zap.String("transaction_id", txn.ID.String()),
+		zap.Float64("amount", amount),
+	)
+
+	return s.holdRepo.GetByID(id)
+}
+
+func (s *holdService) ReleaseHold(id uuid.UUID) (*account.Hold, error) {
+	if err := s.holdRepo.Release(id); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Hold released", zap.String("hold_id", id.String()))
+
+	return s.holdRepo.GetByID(id)
+}
+
+func (s *holdService) ListHolds(accountID, userID uuid.UUID) ([]*account.Hold, error) {
+	acc, err := s.accountRepo.GetByID(accountID)
+	if err != nil {
+		return nil, err
+	}
+	if acc.UserID != userID {
+		return nil, fmt.Errorf("unauthorized access to account")
+	}
+
+	return s.holdRepo.ListActiveByAccount(accountID)
+}
+
+func (s *holdService) ExpireHolds(now time.Time) (int, error) {
+	return s.holdRepo.ExpireDue(now)
+}
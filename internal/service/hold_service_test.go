@@ -0,0 +1,161 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockHoldRepository is a mock implementation of repository.HoldRepository
+type MockHoldRepository struct {
+	mock.Mock
+}
+
+func (m *MockHoldRepository) Place(h *account.Hold) error {
+	args := m.Called(h)
+	return args.Error(0)
+}
+
+func (m *MockHoldRepository) GetByID(id uuid.UUID) (*account.Hold, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*account.Hold), args.Error(1)
+}
+
+func (m *MockHoldRepository) ListActiveByAccount(accountID uuid.UUID) ([]*account.Hold, error) {
+	args := m.Called(accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*account.Hold), args.Error(1)
+}
+
+func (m *MockHoldRepository) Extend(id uuid.UUID, expiresAt time.Time) error {
+	args := m.Called(id, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockHoldRepository) Release(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockHoldRepository) Capture(id uuid.UUID, amount float64, txn *transaction.Transaction) error {
+	args := m.Called(id, amount, txn)
+	return args.Error(0)
+}
+
+func (m *MockHoldRepository) ExpireDue(now time.Time) (int, error) {
+	args := m.Called(now)
+	return args.Int(0), args.Error(1)
+}
+
+func setupHoldService(t *testing.T) (HoldService, *MockHoldRepository, *MockAccountRepository) {
+	logger.Init("test")
+	holdRepo := new(MockHoldRepository)
+	accountRepo := new(MockAccountRepository)
+	return NewHoldService(holdRepo, accountRepo), holdRepo, accountRepo
+}
+
+func TestPlaceHold(t *testing.T) {
+	svc, holdRepo, _ := setupHoldService(t)
+	accountID := uuid.New()
+
+	holdRepo.On("Place", mock.MatchedBy(func(h *account.Hold) bool {
+		return h.AccountID == accountID && h.Amount == 150000 && h.Status == account.HoldStatusActive &&
+			h.Purpose == account.HoldPurposeBulkTransfer && time.Until(h.ExpiresAt) > 47*time.Hour
+	})).Return(nil)
+
+	h, err := svc.PlaceHold(&account.PlaceHoldRequest{
+		AccountID: accountID.String(), Amount: 150000, Purpose: "bulk_transfer", Reference: "batch-42", ExpiresInHours: 48,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "batch-42", h.Reference)
+	holdRepo.AssertExpectations(t)
+}
+
+func TestPlaceHold_InsufficientAvailable(t *testing.T) {
+	svc, holdRepo, _ := setupHoldService(t)
+	holdRepo.On("Place", mock.Anything).Return(account.ErrInsufficientAvailable)
+
+	h, err := svc.PlaceHold(&account.PlaceHoldRequest{AccountID: uuid.New().String(), Amount: 150000, Purpose: "other"})
+
+	assert.Nil(t, h)
+	assert.ErrorIs(t, err, account.ErrInsufficientAvailable)
+}
+
+func TestExtendHold_BeyondMaxDuration(t *testing.T) {
+	svc, holdRepo, _ := setupHoldService(t)
+	holdID := uuid.New()
+	holdRepo.On("GetByID", holdID).Return(&account.Hold{ID: holdID, CreatedAt: time.Now().Add(-29 * 24 * time.Hour)}, nil)
+
+	h, err := svc.ExtendHold(holdID, &account.ExtendHoldRequest{ExpiresInHours: 48})
+
+	assert.Nil(t, h)
+	assert.EqualError(t, err, "holds cannot last more than 30 days")
+	holdRepo.AssertNotCalled(t, "Extend", mock.Anything, mock.Anything)
+}
+
+func TestCaptureHold(t *testing.T) {
+	svc, holdRepo, _ := setupHoldService(t)
+	holdID := uuid.New()
+	hold := &account.Hold{ID: holdID, Amount: 100000, Purpose: account.HoldPurposeCardAuthorization, Status: account.HoldStatusActive}
+	captured := &account.Hold{ID: holdID, Amount: 100000, Status: account.HoldStatusCaptured}
+
+	holdRepo.On("GetByID", holdID).Return(hold, nil).Once()
+	holdRepo.On("Capture", holdID, 100000.0, mock.MatchedBy(func(txn *transaction.Transaction) bool {
+		return txn.IdempotencyKey == "hold-capture-"+holdID.String() &&
+			txn.TransactionType == transaction.TransactionTypeWithdrawal && txn.Metadata["hold_id"] == holdID.String()
+	})).Return(nil)
+	holdRepo.On("GetByID", holdID).Return(captured, nil).Once()
+
+	h, err := svc.CaptureHold(holdID, &account.CaptureHoldRequest{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, account.HoldStatusCaptured, h.Status)
+	holdRepo.AssertExpectations(t)
+}
+
+func TestCaptureHold_Partial(t *testing.T) {
+	svc, holdRepo, _ := setupHoldService(t)
+	holdID := uuid.New()
+	holdRepo.On("GetByID", holdID).Return(&account.Hold{ID: holdID, Amount: 100000, Status: account.HoldStatusActive}, nil)
+	holdRepo.On("Capture", holdID, 80000.0, mock.Anything).Return(nil)
+
+	_, err := svc.CaptureHold(holdID, &account.CaptureHoldRequest{Amount: 80000})
+
+	assert.NoError(t, err)
+	holdRepo.AssertExpectations(t)
+}
+
+func TestReleaseHold_NotActive(t *testing.T) {
+	svc, holdRepo, _ := setupHoldService(t)
+	holdID := uuid.New()
+	holdRepo.On("Release", holdID).Return(account.ErrHoldNotActive)
+
+	h, err := svc.ReleaseHold(holdID)
+
+	assert.Nil(t, h)
+	assert.ErrorIs(t, err, account.ErrHoldNotActive)
+}
+
+func TestListHolds_OtherUsersAccount(t *testing.T) {
+	svc, holdRepo, accountRepo := setupHoldService(t)
+	accountID := uuid.New()
+	accountRepo.On("GetByID", accountID).Return(&account.Account{ID: accountID, UserID: uuid.New()}, nil)
+
+	holds, err := svc.ListHolds(accountID, uuid.New())
+
+	assert.Nil(t, holds)
+	assert.EqualError(t, err, "unauthorized access to account")
+	holdRepo.AssertNotCalled(t, "ListActiveByAccount", mock.Anything)
+}
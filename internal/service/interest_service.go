@@ -0,0 +1,144 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/interest"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/pkg/metrics"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type InterestService interface {
+	// AccrueDaily records yesterday's interest on every interest-bearing
+	// account and returns the number of accruals saved. Accounts already
+	// accrued for the day are skipped, so running it twice is harmless.
+	AccrueDaily(now time.Time) (int, error)
+	// PostDue credits every account with its interest accrued before the
+	// current month and returns the number of accounts credited
+	PostDue(now time.Time) (int, error)
+	// GetSummary returns a year of the account's accrued and posted interest
+	// and the tax withheld on it, by month
+	GetSummary(userID uuid.UUID, accountID uuid.UUID, req *interest.SummaryRequest) (*interest.Summary, error)
+}
+
+type interestService struct {
+	interestRepo repository.InterestRepository
+	accountRepo  repository.AccountRepository
+	zone         *time.Location // accrual days start at midnight in this zone
+}
+
+func NewInterestService(interestRepo repository.InterestRepository, accountRepo repository.AccountRepository, zone *time.Location) InterestService {
+	return &interestService{
+		interestRepo: interestRepo,
+		accountRepo:  accountRepo,
+		zone:         zone,
+	}
+}
+
+func (s *interestService) AccrueDaily(now time.Time) (int, error) {
+	day := s.dayStart(now).AddDate(0, 0, -1)
+
+	balances, err := s.interestRepo.ListInterestBearing()
+	if err != nil {
+		return 0, err
+	}
+	tiers, err := s.interestRepo.ListRateTiers()
+	if err != nil {
+		return 0, err
+	}
+	for _, b := range balances {
+		b.AnnualRate = interest.EffectiveRate(b.Balance, b.AnnualRate, tiers.For(b.AccountType, b.Currency))
+	}
+
+	accruals := interest.Accrue(day, balances)
+	if len(accruals) == 0 {
+		return 0, nil
+	}
+
+	return s.interestRepo.SaveAccruals(accruals)
+}
+
+func (s *interestService) PostDue(now time.Time) (int, error) {
+	today := s.dayStart(now)
+	monthStart := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, s.zone)
+	period := monthStart.AddDate(0, 0, -1).Format("2006-01")
+
+	accountIDs, err := s.interestRepo.ListAccountsWithUnposted(monthStart)
+	if err != nil {
+		return 0, err
+	}
+
+	posted := 0
+	for _, accountID := range accountIDs {
+		start := time.Now()
+		txn := &transaction.Transaction{
+			ID:              uuid.New(),
+			IdempotencyKey:  fmt.Sprintf("interest:%s:%s", accountID, period),
+			TransactionType: transaction.TransactionTypeInterest,
+			Description:     fmt.Sprintf("Savings interest for %s", period),
+		}
+
+		ok, err := s.interestRepo.PostAccruals(accountID, monthStart, txn)
+		if err != nil {
+			// One failing account should not hold back everyone else's interest
+			logger.Error("Failed to post interest",
+				zap.String("account_id", accountID.String()),
+				zap.Error(err),
+			)
+			errtrack.CaptureError(err, map[string]string{
+				"component": "interest",
+				"operation": "post",
+			})
+			metrics.RecordTransactionError("interest", "posting_failed")
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		metrics.RecordTransaction("interest", "completed", txn.Amount, DefaultCurrency, time.Since(start).Seconds())
+		posted++
+	}
+
+	return posted, nil
+}
+
+func (s *interestService) GetSummary(userID uuid.UUID, accountID uuid.UUID, req *interest.SummaryRequest) (*interest.Summary, error) {
+	acct, err := s.accountRepo.GetByID(accountID)
+	if err != nil {
+		return nil, fmt.Errorf("account not found")
+	}
+	if acct.UserID != userID {
+		return nil, fmt.Errorf("unauthorized: account does not belong to user")
+	}
+
+	year := req.Year
+	if year == 0 {
+		year = time.Now().In(s.zone).Year()
+	}
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, s.zone)
+	to := from.AddDate(1, 0, 0)
+
+	accruals, err := s.interestRepo.ListAccruals(accountID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	postings, err := s.interestRepo.ListPostings(accountID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return interest.Summarize(accountID, acct.Currency, year, s.zone, accruals, postings), nil
+}
+
+// dayStart is the start of the current day in the accrual time zone
+func (s *interestService) dayStart(now time.Time) time.Time {
+	local := now.In(s.zone)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, s.zone)
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/interest"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockInterestRepository is a mock implementation of repository.InterestRepository
+type MockInterestRepository struct {
+	mock.Mock
+}
+
+func (m *MockInterestRepository) ListInterestBearing() ([]*interest.Balance, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*interest.Balance), args.Error(1)
+}
+
+func (m *MockInterestRepository) ListRateTiers() (interest.Tiers, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(interest.Tiers), args.Error(1)
+}
+
+func (m *MockInterestRepository) ListAccruals(accountID uuid.UUID, from, to time.Time) ([]*interest.Accrual, error) {
+	args := m.Called(accountID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*interest.Accrual), args.Error(1)
+}
+
+func (m *MockInterestRepository) ListPostings(accountID uuid.UUID, from, to time.Time) ([]*transaction.Transaction, error) {
+	args := m.Called(accountID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*transaction.Transaction), args.Error(1)
+}
+
+func (m *MockInterestRepository) SaveAccruals(accruals []*interest.Accrual) (int, error) {
+	args := m.Called(accruals)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockInterestRepository) ListAccountsWithUnposted(before time.Time) ([]uuid.UUID, error) {
+	args := m.Called(before)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockInterestRepository) PostAccruals(accountID uuid.UUID, before time.Time, txn *transaction.Transaction) (bool, error) {
+	args := m.Called(accountID, before, txn)
+	return args.Bool(0), args.Error(1)
+}
+
+var jakarta = time.FixedZone("WIB", 7*60*60)
+
+func TestAccrueDaily_AccruesYesterdayInBusinessZone(t *testing.T) {
+	repo := new(MockInterestRepository)
+	svc := NewInterestService(repo, new(MockAccountRepository), jakarta)
+	accountID := uuid.New()
+
+	repo.On("ListInterestBearing").Return([]*interest.Balance{
+		{AccountID: accountID, Balance: 10000000, AnnualRate: 0.0365},
+		{AccountID: uuid.New(), Balance: 0, AnnualRate: 0.0365},
+	}, nil)
+	repo.On("ListRateTiers").Return(interest.Tiers{}, nil)
+	repo.On("SaveAccruals", mock.MatchedBy(func(a []*interest.Accrual) bool {
+		return len(a) == 1 && a[0].AccountID == accountID && a[0].Amount == 1000 &&
+			a[0].AccrualDate.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, jakarta))
+	})).Return(1, nil)
+
+	// 18:05 UTC on March 1st is already March 2nd in Jakarta
+	n, err := svc.AccrueDaily(time.Date(2026, 3, 1, 18, 5, 0, 0, time.UTC))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
+
+func TestAccrueDaily_TieredRate(t *testing.T) {
+	repo := new(MockInterestRepository)
+	svc := NewInterestService(repo, new(MockAccountRepository), jakarta)
+
+	repo.On("ListInterestBearing").Return([]*interest.Balance{
+		{AccountID: uuid.New(), AccountType: "savings", Currency: "IDR", Balance: 20000000, AnnualRate: 0.0325},
+		{AccountID: uuid.New(), AccountType: "savings", Currency: "USD", Balance: 20000000, AnnualRate: 0.0325},
+	}, nil)
+	repo.On("ListRateTiers").Return(interest.Tiers{
+		{AccountType: "savings", Currency: "IDR", MinBalance: 10000000, AnnualRate: 0.04},
+	}, nil)
+	repo.On("SaveAccruals", mock.MatchedBy(func(a []*interest.Accrual) bool {
+		// Only the IDR product has tiers: 10M at 3.25% and 10M at 4%
+		return len(a) == 2 && a[0].AnnualRate == 0.03625 && a[1].AnnualRate == 0.0325
+	})).Return(2, nil)
+
+	n, err := svc.AccrueDaily(time.Date(2026, 3, 1, 18, 5, 0, 0, time.UTC))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+}
+
+func TestPostDue_PostsPreviousMonthPerAccount(t *testing.T) {
+	logger.Init("test")
+	repo := new(MockInterestRepository)
+	svc := NewInterestService(repo, new(MockAccountRepository), jakarta)
+	posted, nothing, failing := uuid.New(), uuid.New(), uuid.New()
+	monthStart := time.Date(2026, 3, 1, 0, 0, 0, 0, jakarta)
+
+	repo.On("ListAccountsWithUnposted", monthStart).Return([]uuid.UUID{posted, nothing, failing}, nil)
+	repo.On("PostAccruals", posted, monthStart, mock.MatchedBy(func(txn *transaction.Transaction) bool {
+		return txn.IdempotencyKey == "interest:"+posted.String()+":2026-02" &&
+			txn.TransactionType == transaction.TransactionTypeInterest
+	})).Return(true, nil)
+	repo.On("PostAccruals", nothing, monthStart, mock.Anything).Return(false, nil)
+	repo.On("PostAccruals", failing, monthStart, mock.Anything).Return(false, fmt.Errorf("failed to lock account"))
+
+	n, err := svc.PostDue(time.Date(2026, 3, 1, 17, 30, 0, 0, time.UTC))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	repo.AssertNumberOfCalls(t, "PostAccruals", 3)
+}
+
+func TestGetSummary_ByMonthWithWithholdingTax(t *testing.T) {
+	repo := new(MockInterestRepository)
+	accountRepo := new(MockAccountRepository)
+	svc := NewInterestService(repo, accountRepo, jakarta)
+	userID, accountID := uuid.New(), uuid.New()
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, jakarta)
+	to := time.Date(2027, 1, 1, 0, 0, 0, 0, jakarta)
+
+	accountRepo.On("GetByID", accountID).Return(&account.Account{ID: accountID, UserID: userID, Currency: "IDR"}, nil)
+	repo.On("ListAccruals", accountID, from, to).Return([]*interest.Accrual{
+		{AccrualDate: time.Date(2026, 1, 30, 0, 0, 0, 0, jakarta), Amount: 890.410959},
+		{AccrualDate: time.Date(2026, 1, 31, 0, 0, 0, 0, jakarta), Amount: 890.410959},
+		{AccrualDate: time.Date(2026, 2, 1, 0, 0, 0, 0, jakarta), Amount: 890.410959},
+	}, nil)
+	// January's interest is credited just after midnight on February 1st in Jakarta
+	postedAt := time.Date(2026, 1, 31, 17, 30, 0, 0, time.UTC)
+	repo.On("ListPostings", accountID, from, to).Return([]*transaction.Transaction{
+		{Amount: 27602.74, TransactionType: transaction.TransactionTypeInterest, CompletedAt: &postedAt},
+	}, nil)
+
+	s, err := svc.GetSummary(userID, accountID, &interest.SummaryRequest{Year: 2026})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "IDR", s.Currency)
+	assert.Len(t, s.Months, 12)
+	assert.Equal(t, "2026-01", s.Months[0].Month)
+	assert.Equal(t, 1780.82, s.Months[0].Accrued)
+	assert.Equal(t, 0.0, s.Months[0].Posted)
+	assert.Equal(t, 890.41, s.Months[1].Accrued)
+	assert.Equal(t, 27602.74, s.Months[1].Posted)
+	assert.Equal(t, 5520.55, s.Months[1].WithholdingTax)
+	assert.Equal(t, 2671.23, s.TotalAccrued)
+	assert.Equal(t, 27602.74, s.TotalPosted)
+	assert.Equal(t, 5520.55, s.TotalWithholdingTax)
+}
+
+func TestGetSummary_OtherUsersAccount(t *testing.T) {
+	repo := new(MockInterestRepository)
+	accountRepo := new(MockAccountRepository)
+	svc := NewInterestService(repo, accountRepo, jakarta)
+	accountID := uuid.New()
+
+	accountRepo.On("GetByID", accountID).Return(&account.Account{ID: accountID, UserID: uuid.New()}, nil)
+
+	_, err := svc.GetSummary(uuid.New(), accountID, &interest.SummaryRequest{Year: 2026})
+
+	assert.EqualError(t, err, "unauthorized: account does not belong to user")
+	repo.AssertNotCalled(t, "ListAccruals", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/audit"
+	"github.com/darisadam/madabank-server/internal/domain/queue"
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const defaultJobListLimit = 50
+
+// JobService lets operators inspect the background job queue and retry jobs
+// from the dead-letter queue
+type JobService interface {
+	ListJobs(req *queue.ListJobsRequest) ([]*queue.Job, error)
+	GetJob(id uuid.UUID) (*queue.Job, error)
+	// RetryJob moves a dead job back to pending with a fresh set of attempts
+	RetryJob(userID, id uuid.UUID) (*queue.Job, error)
+}
+
+type jobService struct {
+	jobRepo   repository.JobRepository
+	auditRepo repository.AuditRepository
+}
+
+func NewJobService(jobRepo repository.JobRepository, auditRepo repository.AuditRepository) JobService {
+	return &jobService{
+		jobRepo:   jobRepo,
+		auditRepo: auditRepo,
+	}
+}
+
+func (s *jobService) ListJobs(req *queue.ListJobsRequest) ([]*queue.Job, error) {
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultJobListLimit
+	}
+	return s.jobRepo.List(req.Status, req.Kind, limit)
+}
+
+func (s *jobService) GetJob(id uuid.UUID) (*queue.Job, error) {
+	return s.jobRepo.GetByID(id)
+}
+
+func (s *jobService) RetryJob(userID, id uuid.UUID) (*queue.Job, error) {
+	job, err := s.jobRepo.Retry(id, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit(userID, "JOB_RETRIED", fmt.Sprintf("job:%s", job.ID), map[string]interface{}{
+		"kind":       job.Kind,
+		"last_error": job.LastError,
+	})
+
+	return job, nil
+}
+
+func (s *jobService) audit(userID uuid.UUID, action, resource string, metadata map[string]interface{}) {
+	if err := s.auditRepo.Create(&audit.AuditLog{
+		EventID:  uuid.New(),
+		UserID:   &userID,
+		Action:   action,
+		Resource: resource,
+		Status:   "success",
+		Metadata: metadata,
+	}); err != nil {
+		logger.Error("Failed to create audit log for job queue", zap.String("action", action), zap.Error(err))
+		errtrack.CaptureError(err, map[string]string{"component": "job_service", "operation": "audit_log"})
+	}
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/audit"
+	"github.com/darisadam/madabank-server/internal/domain/queue"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockJobRepository is a mock implementation of repository.JobRepository
+type MockJobRepository struct {
+	mock.Mock
+}
+
+func (m *MockJobRepository) Enqueue(job *queue.Job) (bool, error) {
+	args := m.Called(job)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockJobRepository) Claim(kinds []string, workerID string, now time.Time) (*queue.Job, error) {
+	args := m.Called(kinds, workerID, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*queue.Job), args.Error(1)
+}
+
+func (m *MockJobRepository) Complete(id uuid.UUID, workerID string, now time.Time) error {
+	args := m.Called(id, workerID, now)
+	return args.Error(0)
+}
+
+func (m *MockJobRepository) Reschedule(id uuid.UUID, workerID, lastError string, runAt time.Time) error {
+	args := m.Called(id, workerID, lastError, runAt)
+	return args.Error(0)
+}
+
+func (m *MockJobRepository) Bury(id uuid.UUID, workerID, lastError string, now time.Time) error {
+	args := m.Called(id, workerID, lastError, now)
+	return args.Error(0)
+}
+
+func (m *MockJobRepository) RequeueStale(cutoff, now time.Time) (int64, error) {
+	args := m.Called(cutoff, now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockJobRepository) Retry(id uuid.UUID, now time.Time) (*queue.Job, error) {
+	args := m.Called(id, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*queue.Job), args.Error(1)
+}
+
+func (m *MockJobRepository) DeleteCompletedBefore(cutoff time.Time) (int64, error) {
+	args := m.Called(cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockJobRepository) GetByID(id uuid.UUID) (*queue.Job, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*queue.Job), args.Error(1)
+}
+
+func (m *MockJobRepository) List(status queue.Status, kind string, limit int) ([]*queue.Job, error) {
+	args := m.Called(status, kind, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*queue.Job), args.Error(1)
+}
+
+func (m *MockJobRepository) CountByStatus() (map[queue.Status]int, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[queue.Status]int), args.Error(1)
+}
+
+func setupJobServiceTest(t *testing.T) (JobService, *MockJobRepository, *MockAuditRepository) {
+	logger.Init("test")
+	jobRepo := new(MockJobRepository)
+	auditRepo := new(MockAuditRepository)
+	auditRepo.On("Create", mock.Anything).Return(nil)
+
+	return NewJobService(jobRepo, auditRepo), jobRepo, auditRepo
+}
+
+func TestListJobs_DefaultLimit(t *testing.T) {
+	svc, jobRepo, _ := setupJobServiceTest(t)
+	jobRepo.On("List", queue.StatusDead, "", defaultJobListLimit).Return([]*queue.Job{{ID: uuid.New()}}, nil)
+
+	jobs, err := svc.ListJobs(&queue.ListJobsRequest{Status: queue.StatusDead})
+
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 1)
+}
+
+func TestRetryJob_Audited(t *testing.T) {
+	svc, jobRepo, auditRepo := setupJobServiceTest(t)
+	userID, id := uuid.New(), uuid.New()
+	jobRepo.On("Retry", id, mock.Anything).Return(&queue.Job{ID: id, Kind: "system_metrics", Status: queue.StatusPending}, nil)
+
+	job, err := svc.RetryJob(userID, id)
+
+	assert.NoError(t, err)
+	assert.Equal(t, queue.StatusPending, job.Status)
+	auditRepo.AssertCalled(t, "Create", mock.MatchedBy(func(l *audit.AuditLog) bool {
+		return l.Action == "JOB_RETRIED" && l.Resource == "job:"+id.String() && *l.UserID == userID
+	}))
+}
+
+func TestRetryJob_NotDead(t *testing.T) {
+	svc, jobRepo, auditRepo := setupJobServiceTest(t)
+	jobRepo.On("Retry", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("dead job not found"))
+
+	job, err := svc.RetryJob(uuid.New(), uuid.New())
+
+	assert.EqualError(t, err, "dead job not found")
+	assert.Nil(t, job)
+	auditRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
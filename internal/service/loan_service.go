@@ -0,0 +1,370 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/audit"
+	"github.com/darisadam/madabank-server/internal/domain/loan"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/pkg/metrics"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	maxLoanApplicationsListed = 100
+	loanCollectionBatch       = 200
+)
+
+type LoanService interface {
+	ListProducts() ([]*loan.Product, error)
+	Quote(req *loan.QuoteRequest) (*loan.Quote, error)
+	Apply(userID uuid.UUID, req *loan.ApplyLoanRequest) (*loan.Loan, error)
+	ListLoans(userID uuid.UUID) ([]*loan.Loan, error)
+	GetLoan(userID uuid.UUID, loanID uuid.UUID) (*loan.Loan, error)
+	CancelApplication(userID uuid.UUID, loanID uuid.UUID) (*loan.Loan, error)
+
+	// ListApplications, Approve and Reject are the loan officer's side of the workflow
+	ListApplications(status loan.Status) ([]*loan.Loan, error)
+	Approve(officerID uuid.UUID, loanID uuid.UUID) (*loan.Loan, error)
+	Reject(officerID uuid.UUID, loanID uuid.UUID, reason string) (*loan.Loan, error)
+
+	CollectDueInstallments(now time.Time) (int, error)
+}
+
+type loanService struct {
+	loanRepo    repository.LoanRepository
+	accountRepo repository.AccountRepository
+	auditRepo   repository.AuditRepository
+	zone        *time.Location // installments fall due at midnight in this zone
+}
+
+func NewLoanService(
+	loanRepo repository.LoanRepository,
+	accountRepo repository.AccountRepository,
+	auditRepo repository.AuditRepository,
+	zone *time.Location,
+) LoanService {
+	return &loanService{
+		loanRepo:    loanRepo,
+		accountRepo: accountRepo,
+		auditRepo:   auditRepo,
+		zone:        zone,
+	}
+}
+
+func (s *loanService) ListProducts() ([]*loan.Product, error) {
+	return s.loanRepo.ListProducts()
+}
+
+func (s *loanService) Quote(req *loan.QuoteRequest) (*loan.Quote, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	product, err := s.loanRepo.GetProduct(req.ProductCode)
+	if err != nil {
+		return nil, err
+	}
+	if err := product.Validate(req.Amount, req.TenorMonths); err != nil {
+		return nil, err
+	}
+
+	schedule := loan.BuildSchedule(uuid.Nil, req.Amount, product.AnnualRate, req.TenorMonths, s.today(time.Now()))
+	fee := product.ProvisionFee(req.Amount)
+	total := loan.TotalRepayment(schedule)
+
+	return &loan.Quote{
+		ProductCode:        product.Code,
+		Principal:          req.Amount,
+		TenorMonths:        req.TenorMonths,
+		AnnualRate:         product.AnnualRate,
+		ProvisionFee:       fee,
+		DisbursedAmount:    req.Amount - fee,
+		MonthlyInstallment: loan.MonthlyPayment(req.Amount, product.AnnualRate, req.TenorMonths),
+		TotalRepayment:     total,
+		TotalInterest:      math.Round((total-req.Amount)*100) / 100,
+		Schedule:           schedule,
+	}, nil
+}
+
+// Apply records a loan application for an officer to decide on. Terms are
+// fixed from the product at application time.
+func (s *loanService) Apply(userID uuid.UUID, req *loan.ApplyLoanRequest) (*loan.Loan, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	accountID, err := uuid.Parse(req.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account_id")
+	}
+
+	product, err := s.loanRepo.GetProduct(req.ProductCode)
+	if err != nil {
+		return nil, err
+	}
+	if err := product.Validate(req.Amount, req.TenorMonths); err != nil {
+		return nil, err
+	}
+
+	acct, err := s.accountRepo.GetByID(accountID)
+	if err != nil {
+		return nil, fmt.Errorf("account not found")
+	}
+	if acct.UserID != userID {
+		return nil, fmt.Errorf("unauthorized: account does not belong to user")
+	}
+	if acct.AccountType != account.AccountTypeChecking {
+		return nil, fmt.Errorf("loans are disbursed into a checking account")
+	}
+	if acct.Status != account.AccountStatusActive {
+		return nil, fmt.Errorf("account is %s, cannot receive a loan", acct.Status)
+	}
+
+	pending, err := s.loanRepo.CountPendingByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if pending >= loan.MaxPendingApplications {
+		return nil, fmt.Errorf("a loan application is already waiting for a decision")
+	}
+
+	l := &loan.Loan{
+		ID:                 uuid.New(),
+		UserID:             userID,
+		ProductCode:        product.Code,
+		AccountID:          accountID,
+		Principal:          req.Amount,
+		TenorMonths:        req.TenorMonths,
+		AnnualRate:         product.AnnualRate,
+		MonthlyInstallment: loan.MonthlyPayment(req.Amount, product.AnnualRate, req.TenorMonths),
+		ProvisionFee:       product.ProvisionFee(req.Amount),
+		Purpose:            req.Purpose,
+		Status:             loan.StatusPending,
+	}
+
+	if err := s.loanRepo.Create(l); err != nil {
+		return nil, err
+	}
+
+	s.audit(userID, "LOAN_APPLIED", l.ID, map[string]interface{}{
+		"product_code": l.ProductCode,
+		"amount":       l.Principal,
+		"tenor_months": l.TenorMonths,
+	})
+
+	return l, nil
+}
+
+func (s *loanService) ListLoans(userID uuid.UUID) ([]*loan.Loan, error) {
+	return s.loanRepo.ListByUser(userID)
+}
+
+// GetLoan returns the loan with its amortization schedule once disbursed
+func (s *loanService) GetLoan(userID uuid.UUID, loanID uuid.UUID) (*loan.Loan, error) {
+	l, err := s.getOwnedLoan(userID, loanID)
+	if err != nil {
+		return nil, err
+	}
+
+	if l.Status == loan.StatusActive || l.Status == loan.StatusPaidOff {
+		schedule, err := s.loanRepo.GetSchedule(l.ID)
+		if err != nil {
+			return nil, err
+		}
+		l.Schedule = schedule
+	}
+
+	return l, nil
+}
+
+func (s *loanService) CancelApplication(userID uuid.UUID, loanID uuid.UUID) (*loan.Loan, error) {
+	if _, err := s.getOwnedLoan(userID, loanID); err != nil {
+		return nil, err
+	}
+
+	if err := s.loanRepo.Cancel(loanID); err != nil {
+		return nil, err
+	}
+
+	s.audit(userID, "LOAN_CANCELLED", loanID, nil)
+
+	return s.loanRepo.GetByID(loanID)
+}
+
+func (s *loanService) ListApplications(status loan.Status) ([]*loan.Loan, error) {
+	if status == "" {
+		status = loan.StatusPending
+	}
+	if !loan.IsValidStatus(status) {
+		return nil, fmt.Errorf("invalid loan status")
+	}
+
+	return s.loanRepo.ListByStatus(status, maxLoanApplicationsListed)
+}
+
+// Approve disburses the loan, net of the provision fee, and starts the
+// amortization schedule from today
+func (s *loanService) Approve(officerID uuid.UUID, loanID uuid.UUID) (*loan.Loan, error) {
+	l, err := s.loanRepo.GetByID(loanID)
+	if err != nil {
+		return nil, err
+	}
+	if l.UserID == officerID {
+		return nil, fmt.Errorf("loan officers cannot decide on their own applications")
+	}
+	if l.Status != loan.StatusPending {
+		return nil, fmt.Errorf("loan application is no longer pending")
+	}
+
+	disbursed := l.Principal - l.ProvisionFee
+	schedule := loan.BuildSchedule(l.ID, l.Principal, l.AnnualRate, l.TenorMonths, s.today(time.Now()))
+
+	txn := &transaction.Transaction{
+		ID:              uuid.New(),
+		IdempotencyKey:  fmt.Sprintf("loan-disbursement:%s", l.ID),
+		ToAccountID:     &l.AccountID,
+		Amount:          disbursed,
+		TransactionType: transaction.TransactionTypeLoanDisbursement,
+		Status:          transaction.TransactionStatusCompleted,
+		Description:     fmt.Sprintf("Loan disbursement %s", l.ProductCode),
+		Metadata: map[string]interface{}{
+			"loan_id":       l.ID.String(),
+			"principal":     l.Principal,
+			"provision_fee": l.ProvisionFee,
+			"approved_by":   officerID.String(),
+		},
+	}
+
+	if err := s.loanRepo.Disburse(l.ID, officerID, schedule, txn); err != nil {
+		metrics.RecordTransactionError("loan_disbursement", "execution_failed")
+		return nil, err
+	}
+
+	metrics.RecordTransaction("loan_disbursement", "completed", disbursed, DefaultCurrency, 0)
+
+	s.audit(officerID, "LOAN_APPROVED", l.ID, map[string]interface{}{
+		"borrower_id":    l.UserID.String(),
+		"principal":      l.Principal,
+		"disbursed":      disbursed,
+		"transaction_id": txn.ID.String(),
+	})
+
+	return s.loanRepo.GetByID(l.ID)
+}
+
+func (s *loanService) Reject(officerID uuid.UUID, loanID uuid.UUID, reason string) (*loan.Loan, error) {
+	l, err := s.loanRepo.GetByID(loanID)
+	if err != nil {
+		return nil, err
+	}
+	if l.UserID == officerID {
+		return nil, fmt.Errorf("loan officers cannot decide on their own applications")
+	}
+
+	if err := s.loanRepo.Reject(loanID, officerID, reason); err != nil {
+		return nil, err
+	}
+
+	s.audit(officerID, "LOAN_REJECTED", loanID, map[string]interface{}{
+		"borrower_id": l.UserID.String(),
+		"reason":      reason,
+	})
+
+	return s.loanRepo.GetByID(loanID)
+}
+
+// CollectDueInstallments auto-debits every installment due today or earlier.
+// Installments that cannot be collected are retried the next day. It returns
+// how many installments were paid.
+func (s *loanService) CollectDueInstallments(now time.Time) (int, error) {
+	today := s.today(now)
+	due, err := s.loanRepo.ListDueInstallments(today, loanCollectionBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	paid := 0
+	for _, inst := range due {
+		l, err := s.loanRepo.GetByID(inst.LoanID)
+		if err != nil {
+			logger.Error("Failed to load loan for installment", zap.String("installment_id", inst.ID.String()), zap.Error(err))
+			continue
+		}
+
+		txn := &transaction.Transaction{
+			ID:              uuid.New(),
+			IdempotencyKey:  fmt.Sprintf("loan-installment:%s", inst.ID),
+			FromAccountID:   &l.AccountID,
+			Amount:          inst.Amount,
+			TransactionType: transaction.TransactionTypeLoanRepayment,
+			Status:          transaction.TransactionStatusCompleted,
+			Description:     fmt.Sprintf("Loan installment %d/%d", inst.Number, l.TenorMonths),
+			Metadata: map[string]interface{}{
+				"loan_id":        l.ID.String(),
+				"installment_id": inst.ID.String(),
+				"number":         inst.Number,
+				"principal":      inst.Principal,
+				"interest":       inst.Interest,
+			},
+		}
+
+		collected, err := s.loanRepo.CollectInstallment(inst.ID, txn, today)
+		if err != nil {
+			logger.Error("Failed to collect loan installment", zap.String("installment_id", inst.ID.String()), zap.Error(err))
+			errtrack.CaptureError(err, map[string]string{"component": "loan_service", "operation": "collect_installment"})
+			continue
+		}
+		if !collected {
+			metrics.RecordTransactionError("loan_repayment", "insufficient_funds")
+			logger.Warn("Loan installment not collected, will retry",
+				zap.String("loan_id", l.ID.String()),
+				zap.Int("number", inst.Number),
+				zap.Bool("overdue", inst.IsOverdue(today)),
+			)
+			continue
+		}
+
+		metrics.RecordTransaction("loan_repayment", "completed", inst.Amount, DefaultCurrency, 0)
+		paid++
+	}
+
+	return paid, nil
+}
+
+// today is the start of the current day in the loan time zone
+func (s *loanService) today(now time.Time) time.Time {
+	local := now.In(s.zone)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, s.zone)
+}
+
+func (s *loanService) getOwnedLoan(userID uuid.UUID, loanID uuid.UUID) (*loan.Loan, error) {
+	l, err := s.loanRepo.GetByID(loanID)
+	if err != nil {
+		return nil, err
+	}
+	if l.UserID != userID {
+		return nil, fmt.Errorf("unauthorized: loan does not belong to user")
+	}
+
+	return l, nil
+}
+
+func (s *loanService) audit(userID uuid.UUID, action string, loanID uuid.UUID, metadata map[string]interface{}) {
+	if err := s.auditRepo.Create(&audit.AuditLog{
+		EventID:  uuid.New(),
+		UserID:   &userID,
+		Action:   action,
+		Resource: fmt.Sprintf("loan:%s", loanID),
+		Status:   "success",
+		Metadata: metadata,
+	}); err != nil {
+		logger.Error("Failed to create audit log for loan", zap.String("action", action), zap.Error(err))
+		errtrack.CaptureError(err, map[string]string{"component": "loan_service", "operation": "audit_log"})
+	}
+}
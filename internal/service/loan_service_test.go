@@ -0,0 +1,332 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	domainAccount "github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/loan"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockLoanRepository is a mock implementation of repository.LoanRepository
+type MockLoanRepository struct {
+	mock.Mock
+}
+
+func (m *MockLoanRepository) ListProducts() ([]*loan.Product, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*loan.Product), args.Error(1)
+}
+
+func (m *MockLoanRepository) GetProduct(code string) (*loan.Product, error) {
+	args := m.Called(code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*loan.Product), args.Error(1)
+}
+
+func (m *MockLoanRepository) Create(l *loan.Loan) error {
+	args := m.Called(l)
+	return args.Error(0)
+}
+
+func (m *MockLoanRepository) GetByID(id uuid.UUID) (*loan.Loan, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*loan.Loan), args.Error(1)
+}
+
+func (m *MockLoanRepository) ListByUser(userID uuid.UUID) ([]*loan.Loan, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*loan.Loan), args.Error(1)
+}
+
+func (m *MockLoanRepository) ListByStatus(status loan.Status, limit int) ([]*loan.Loan, error) {
+	args := m.Called(status, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*loan.Loan), args.Error(1)
+}
+
+func (m *MockLoanRepository) CountPendingByUser(userID uuid.UUID) (int, error) {
+	args := m.Called(userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockLoanRepository) Cancel(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockLoanRepository) Reject(id, officerID uuid.UUID, reason string) error {
+	args := m.Called(id, officerID, reason)
+	return args.Error(0)
+}
+
+func (m *MockLoanRepository) Disburse(id, officerID uuid.UUID, schedule []*loan.Installment, txn *transaction.Transaction) error {
+	args := m.Called(id, officerID, schedule, txn)
+	return args.Error(0)
+}
+
+func (m *MockLoanRepository) GetSchedule(loanID uuid.UUID) ([]*loan.Installment, error) {
+	args := m.Called(loanID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*loan.Installment), args.Error(1)
+}
+
+func (m *MockLoanRepository) ListDueInstallments(today time.Time, limit int) ([]*loan.Installment, error) {
+	args := m.Called(today, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*loan.Installment), args.Error(1)
+}
+
+func (m *MockLoanRepository) CollectInstallment(installmentID uuid.UUID, txn *transaction.Transaction, today time.Time) (bool, error) {
+	args := m.Called(installmentID, txn, today)
+	return args.Bool(0), args.Error(1)
+}
+
+var testLoanProduct = &loan.Product{
+	Code: "PERSONAL", Name: "Personal Loan",
+	MinAmount: 5000000, MaxAmount: 100000000,
+	MinTenorMonths: 6, MaxTenorMonths: 36,
+	AnnualRate: 0.14, ProvisionFeeRate: 0.01, Active: true,
+}
+
+func setupLoanServiceTest(t *testing.T) (*loanService, *MockLoanRepository, *MockAccountRepository, uuid.UUID, uuid.UUID) {
+	logger.Init("test")
+	loanRepo := new(MockLoanRepository)
+	accountRepo := new(MockAccountRepository)
+	auditRepo := new(MockAuditRepository)
+
+	userID := uuid.New()
+	accountID := uuid.New()
+	accountRepo.On("GetByID", accountID).Return(&domainAccount.Account{
+		ID: accountID, UserID: userID, AccountType: domainAccount.AccountTypeChecking,
+		Status: domainAccount.AccountStatusActive, Currency: "IDR",
+	}, nil)
+	loanRepo.On("GetProduct", "PERSONAL").Return(testLoanProduct, nil).Maybe()
+	auditRepo.On("Create", mock.Anything).Return(nil)
+
+	svc := NewLoanService(loanRepo, accountRepo, auditRepo, testSettlementZone).(*loanService)
+	return svc, loanRepo, accountRepo, userID, accountID
+}
+
+func TestLoanQuote(t *testing.T) {
+	svc, _, _, _, _ := setupLoanServiceTest(t)
+
+	quote, err := svc.Quote(&loan.QuoteRequest{ProductCode: "PERSONAL", Amount: 10000000, TenorMonths: 12})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 100000.0, quote.ProvisionFee)
+	assert.Equal(t, 9900000.0, quote.DisbursedAmount)
+	assert.Len(t, quote.Schedule, 12)
+	assert.InDelta(t, quote.TotalRepayment-10000000, quote.TotalInterest, 0.001)
+}
+
+func TestLoanQuote_OutOfRange(t *testing.T) {
+	svc, _, _, _, _ := setupLoanServiceTest(t)
+
+	_, err := svc.Quote(&loan.QuoteRequest{ProductCode: "PERSONAL", Amount: 10000000, TenorMonths: 48})
+
+	assert.EqualError(t, err, "tenor must be between 6 and 36 months")
+}
+
+func TestApplyLoan_Success(t *testing.T) {
+	svc, loanRepo, _, userID, accountID := setupLoanServiceTest(t)
+	loanRepo.On("CountPendingByUser", userID).Return(0, nil)
+	loanRepo.On("Create", mock.MatchedBy(func(l *loan.Loan) bool {
+		return l.UserID == userID && l.AccountID == accountID && l.Status == loan.StatusPending
+	})).Return(nil)
+
+	l, err := svc.Apply(userID, &loan.ApplyLoanRequest{
+		ProductCode: "PERSONAL", AccountID: accountID.String(), Amount: 12000000, TenorMonths: 12,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0.14, l.AnnualRate)
+	assert.Equal(t, 120000.0, l.ProvisionFee)
+	assert.Equal(t, loan.MonthlyPayment(12000000, 0.14, 12), l.MonthlyInstallment)
+	loanRepo.AssertExpectations(t)
+}
+
+func TestApplyLoan_PendingApplicationExists(t *testing.T) {
+	svc, loanRepo, _, userID, accountID := setupLoanServiceTest(t)
+	loanRepo.On("CountPendingByUser", userID).Return(1, nil)
+
+	_, err := svc.Apply(userID, &loan.ApplyLoanRequest{
+		ProductCode: "PERSONAL", AccountID: accountID.String(), Amount: 12000000, TenorMonths: 12,
+	})
+
+	assert.EqualError(t, err, "a loan application is already waiting for a decision")
+	loanRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestApplyLoan_RequiresCheckingAccount(t *testing.T) {
+	svc, _, accountRepo, userID, _ := setupLoanServiceTest(t)
+	savingsID := uuid.New()
+	accountRepo.On("GetByID", savingsID).Return(&domainAccount.Account{
+		ID: savingsID, UserID: userID, AccountType: domainAccount.AccountTypeSavings,
+		Status: domainAccount.AccountStatusActive,
+	}, nil)
+
+	_, err := svc.Apply(userID, &loan.ApplyLoanRequest{
+		ProductCode: "PERSONAL", AccountID: savingsID.String(), Amount: 12000000, TenorMonths: 12,
+	})
+
+	assert.EqualError(t, err, "loans are disbursed into a checking account")
+}
+
+func TestApplyLoan_AccountOfAnotherUser(t *testing.T) {
+	svc, _, _, _, accountID := setupLoanServiceTest(t)
+
+	_, err := svc.Apply(uuid.New(), &loan.ApplyLoanRequest{
+		ProductCode: "PERSONAL", AccountID: accountID.String(), Amount: 12000000, TenorMonths: 12,
+	})
+
+	assert.EqualError(t, err, "unauthorized: account does not belong to user")
+}
+
+func pendingLoan(userID, accountID uuid.UUID) *loan.Loan {
+	return &loan.Loan{
+		ID: uuid.New(), UserID: userID, ProductCode: "PERSONAL", AccountID: accountID,
+		Principal: 12000000, TenorMonths: 12, AnnualRate: 0.14, ProvisionFee: 120000,
+		Status: loan.StatusPending,
+	}
+}
+
+func TestApproveLoan_Disburses(t *testing.T) {
+	svc, loanRepo, _, userID, accountID := setupLoanServiceTest(t)
+	officerID := uuid.New()
+	l := pendingLoan(userID, accountID)
+	loanRepo.On("GetByID", l.ID).Return(l, nil)
+	loanRepo.On("Disburse", l.ID, officerID,
+		mock.MatchedBy(func(s []*loan.Installment) bool {
+			return len(s) == 12 && loan.TotalRepayment(s) > l.Principal
+		}),
+		mock.MatchedBy(func(txn *transaction.Transaction) bool {
+			return *txn.ToAccountID == accountID && txn.Amount == 11880000 &&
+				txn.TransactionType == transaction.TransactionTypeLoanDisbursement &&
+				txn.IdempotencyKey == fmt.Sprintf("loan-disbursement:%s", l.ID)
+		}),
+	).Return(nil)
+
+	_, err := svc.Approve(officerID, l.ID)
+
+	assert.NoError(t, err)
+	loanRepo.AssertExpectations(t)
+}
+
+func TestApproveLoan_OwnApplication(t *testing.T) {
+	svc, loanRepo, _, userID, accountID := setupLoanServiceTest(t)
+	l := pendingLoan(userID, accountID)
+	loanRepo.On("GetByID", l.ID).Return(l, nil)
+
+	_, err := svc.Approve(userID, l.ID)
+
+	assert.EqualError(t, err, "loan officers cannot decide on their own applications")
+	loanRepo.AssertNotCalled(t, "Disburse", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestApproveLoan_NotPending(t *testing.T) {
+	svc, loanRepo, _, userID, accountID := setupLoanServiceTest(t)
+	l := pendingLoan(userID, accountID)
+	l.Status = loan.StatusRejected
+	loanRepo.On("GetByID", l.ID).Return(l, nil)
+
+	_, err := svc.Approve(uuid.New(), l.ID)
+
+	assert.EqualError(t, err, "loan application is no longer pending")
+}
+
+func TestRejectLoan(t *testing.T) {
+	svc, loanRepo, _, userID, accountID := setupLoanServiceTest(t)
+	officerID := uuid.New()
+	l := pendingLoan(userID, accountID)
+	loanRepo.On("GetByID", l.ID).Return(l, nil)
+	loanRepo.On("Reject", l.ID, officerID, "insufficient income").Return(nil)
+
+	_, err := svc.Reject(officerID, l.ID, "insufficient income")
+
+	assert.NoError(t, err)
+	loanRepo.AssertExpectations(t)
+}
+
+func TestCancelLoan_Unauthorized(t *testing.T) {
+	svc, loanRepo, _, userID, accountID := setupLoanServiceTest(t)
+	l := pendingLoan(userID, accountID)
+	loanRepo.On("GetByID", l.ID).Return(l, nil)
+
+	_, err := svc.CancelApplication(uuid.New(), l.ID)
+
+	assert.EqualError(t, err, "unauthorized: loan does not belong to user")
+	loanRepo.AssertNotCalled(t, "Cancel", mock.Anything)
+}
+
+func TestGetLoan_IncludesScheduleOnceActive(t *testing.T) {
+	svc, loanRepo, _, userID, accountID := setupLoanServiceTest(t)
+	l := pendingLoan(userID, accountID)
+	l.Status = loan.StatusActive
+	schedule := loan.BuildSchedule(l.ID, l.Principal, l.AnnualRate, l.TenorMonths, time.Now())
+	loanRepo.On("GetByID", l.ID).Return(l, nil)
+	loanRepo.On("GetSchedule", l.ID).Return(schedule, nil)
+
+	got, err := svc.GetLoan(userID, l.ID)
+
+	assert.NoError(t, err)
+	assert.Len(t, got.Schedule, 12)
+}
+
+func TestCollectDueInstallments(t *testing.T) {
+	svc, loanRepo, _, userID, accountID := setupLoanServiceTest(t)
+	l := pendingLoan(userID, accountID)
+	l.Status = loan.StatusActive
+	schedule := loan.BuildSchedule(l.ID, l.Principal, l.AnnualRate, l.TenorMonths, time.Date(2026, 1, 15, 0, 0, 0, 0, testSettlementZone))
+	first, second := schedule[0], schedule[1]
+
+	// 2026-03-15 00:30 in Jakarta is still 2026-03-14 in UTC
+	now := time.Date(2026, 3, 14, 17, 30, 0, 0, time.UTC)
+	today := time.Date(2026, 3, 15, 0, 0, 0, 0, testSettlementZone)
+
+	loanRepo.On("ListDueInstallments", today, loanCollectionBatch).Return([]*loan.Installment{first, second}, nil)
+	loanRepo.On("GetByID", l.ID).Return(l, nil)
+	loanRepo.On("CollectInstallment", first.ID, mock.MatchedBy(func(txn *transaction.Transaction) bool {
+		return *txn.FromAccountID == accountID && txn.Amount == first.Amount &&
+			txn.TransactionType == transaction.TransactionTypeLoanRepayment
+	}), today).Return(true, nil)
+	loanRepo.On("CollectInstallment", second.ID, mock.Anything, today).Return(false, nil)
+
+	paid, err := svc.CollectDueInstallments(now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, paid)
+	loanRepo.AssertExpectations(t)
+}
+
+func TestCollectDueInstallments_ListError(t *testing.T) {
+	svc, loanRepo, _, _, _ := setupLoanServiceTest(t)
+	loanRepo.On("ListDueInstallments", mock.Anything, loanCollectionBatch).Return(nil, fmt.Errorf("db down"))
+
+	_, err := svc.CollectDueInstallments(time.Now())
+
+	assert.Error(t, err)
+}
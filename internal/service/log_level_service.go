@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/darisadam/madabank-server/internal/domain/audit"
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/pkg/loglevel"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// LogLevelService lets admins change the log level of every replica for a
+// while, to debug a production issue without a redeploy
+type LogLevelService interface {
+	GetLogLevel() (*loglevel.State, error)
+	SetLogLevel(adminID uuid.UUID, req *loglevel.SetRequest) (*loglevel.State, error)
+	ResetLogLevel(adminID uuid.UUID) (*loglevel.State, error)
+}
+
+type logLevelService struct {
+	levels    *loglevel.Controller
+	auditRepo repository.AuditRepository
+}
+
+func NewLogLevelService(levels *loglevel.Controller, auditRepo repository.AuditRepository) LogLevelService {
+	return &logLevelService{
+		levels:    levels,
+		auditRepo: auditRepo,
+	}
+}
+
+func (s *logLevelService) GetLogLevel() (*loglevel.State, error) {
+	state, err := s.levels.Refresh(context.Background())
+	if err != nil {
+		logger.Error("Failed to load log level", zap.Error(err))
+		return nil, fmt.Errorf("failed to load log level")
+	}
+	return state, nil
+}
+
+func (s *logLevelService) SetLogLevel(adminID uuid.UUID, req *loglevel.SetRequest) (*loglevel.State, error) {
+	state, err := s.levels.Set(context.Background(), req)
+	if err != nil {
+		return nil, err
+	}
+	metadata := map[string]interface{}{"level": state.Level}
+	if state.ExpiresAt != nil {
+		metadata["expires_at"] = state.ExpiresAt
+	}
+	s.audit(adminID, "LOG_LEVEL_CHANGED", metadata)
+	return state, nil
+}
+
+func (s *logLevelService) ResetLogLevel(adminID uuid.UUID) (*loglevel.State, error) {
+	state, err := s.levels.Reset(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	s.audit(adminID, "LOG_LEVEL_RESET", map[string]interface{}{"level": state.Level})
+	return state, nil
+}
+
+func (s *logLevelService) audit(adminID uuid.UUID, action string, metadata map[string]interface{}) {
+	if err := s.auditRepo.Create(&audit.AuditLog{
+		EventID:  uuid.New(),
+		UserID:   &adminID,
+		Action:   action,
+		Resource: "logger:level",
+		Status:   "success",
+		Metadata: metadata,
+	}); err != nil {
+		logger.Error("Failed to create audit log for log level change", zap.String("action", action), zap.Error(err))
+		errtrack.CaptureError(err, map[string]string{"component": "log_level_service", "operation": "audit_log"})
+	}
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/darisadam/madabank-server/internal/domain/audit"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/pkg/loglevel"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zapcore"
+)
+
+func setupLogLevelTest(t *testing.T) (LogLevelService, *MockAuditRepository) {
+	logger.Init("production")
+	t.Cleanup(func() { logger.Init("test") })
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	t.Cleanup(mr.Close)
+
+	auditRepo := new(MockAuditRepository)
+	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	return NewLogLevelService(loglevel.NewController(redisClient), auditRepo), auditRepo
+}
+
+func TestSetLogLevel_Audited(t *testing.T) {
+	svc, auditRepo := setupLogLevelTest(t)
+	adminID := uuid.New()
+	auditRepo.On("Create", mock.MatchedBy(func(l *audit.AuditLog) bool {
+		return l.Action == "LOG_LEVEL_CHANGED" && *l.UserID == adminID && l.Metadata["level"] == "debug" && l.Metadata["expires_at"] != nil
+	})).Return(nil)
+
+	state, err := svc.SetLogLevel(adminID, &loglevel.SetRequest{Level: "debug", Duration: "15m"})
+	assert.NoError(t, err)
+	assert.Equal(t, "debug", state.Level)
+	assert.Equal(t, zapcore.DebugLevel, logger.Level())
+
+	current, err := svc.GetLogLevel()
+	assert.NoError(t, err)
+	assert.Equal(t, "debug", current.Level)
+	auditRepo.AssertExpectations(t)
+}
+
+func TestSetLogLevel_Invalid(t *testing.T) {
+	svc, auditRepo := setupLogLevelTest(t)
+
+	_, err := svc.SetLogLevel(uuid.New(), &loglevel.SetRequest{Level: "debug", Duration: "72h"})
+	assert.EqualError(t, err, "duration must not exceed 24 hours")
+	auditRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestResetLogLevel(t *testing.T) {
+	svc, auditRepo := setupLogLevelTest(t)
+	auditRepo.On("Create", mock.Anything).Return(nil)
+
+	_, err := svc.SetLogLevel(uuid.New(), &loglevel.SetRequest{Level: "error"})
+	assert.NoError(t, err)
+
+	state, err := svc.ResetLogLevel(uuid.New())
+	assert.NoError(t, err)
+	assert.Equal(t, "info", state.Level)
+	assert.Equal(t, zapcore.InfoLevel, logger.Level())
+	auditRepo.AssertNumberOfCalls(t, "Create", 2)
+}
@@ -0,0 +1,658 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/audit"
+	"github.com/darisadam/madabank-server/internal/domain/merchant"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/pkg/metrics"
+	"github.com/darisadam/madabank-server/internal/pkg/webhook"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	maxMerchantItemsListed  = 50
+	merchantNotifyBatch     = 100
+	merchantWebhookTimeout  = 5 * time.Second
+	merchantAPIKeyHintChars = 4
+)
+
+type MerchantService interface {
+	RegisterMerchant(ownerID uuid.UUID, req *merchant.RegisterMerchantRequest) (*merchant.RegisterMerchantResponse, error)
+	ListMerchants(ownerID uuid.UUID) ([]*merchant.Merchant, error)
+	RotateAPIKey(ownerID uuid.UUID, merchantID uuid.UUID) (*merchant.RotateAPIKeyResponse, error)
+	UpdateWebhook(ownerID uuid.UUID, merchantID uuid.UUID, req *merchant.UpdateWebhookRequest) (*merchant.Merchant, error)
+	// RotateWebhookSecret replaces the secret webhooks are signed with,
+	// starting with the next delivery
+	RotateWebhookSecret(ownerID uuid.UUID, merchantID uuid.UUID) (*merchant.RotateWebhookSecretResponse, error)
+	ListWebhookDeliveries(ownerID uuid.UUID, merchantID uuid.UUID) ([]*merchant.WebhookDelivery, error)
+	// TestWebhook posts a webhook.test event to the merchant's webhook and
+	// returns the attempt
+	TestWebhook(ownerID uuid.UUID, merchantID uuid.UUID) (*merchant.WebhookDelivery, error)
+	// ReplayWebhook queues the payment events since a point in time for
+	// redelivery by the notification job, for recovering from a receiver outage
+	ReplayWebhook(ownerID uuid.UUID, merchantID uuid.UUID, req *merchant.ReplayWebhookRequest) (*merchant.ReplayWebhookResponse, error)
+	// Authenticate resolves the merchant owning an API key
+	Authenticate(apiKey string) (*merchant.Merchant, error)
+
+	CreatePaymentLink(merchantID uuid.UUID, req *merchant.CreatePaymentLinkRequest) (*merchant.PaymentLink, error)
+	GetMerchantPaymentLink(merchantID uuid.UUID, linkID uuid.UUID) (*merchant.PaymentLink, error)
+	ListPaymentLinks(merchantID uuid.UUID) ([]*merchant.PaymentLink, error)
+	CancelPaymentLink(merchantID uuid.UUID, linkID uuid.UUID) (*merchant.PaymentLink, error)
+	ListSettlements(merchantID uuid.UUID) ([]*merchant.Settlement, error)
+
+	// GetPaymentLink and ResolveQR are the payer's view of a link
+	GetPaymentLink(linkID uuid.UUID) (*merchant.PaymentLink, error)
+	ResolveQR(payload string) (*merchant.PaymentLink, error)
+	PayPaymentLink(userID uuid.UUID, linkID uuid.UUID, req *merchant.PayPaymentLinkRequest) (*merchant.PaymentLink, error)
+
+	SettleMerchants(now time.Time) (int, error)
+	DeliverNotifications(now time.Time) (int, error)
+}
+
+type merchantService struct {
+	merchantRepo    repository.MerchantRepository
+	accountRepo     repository.AccountRepository
+	transactionRepo repository.TransactionRepository
+	auditRepo       repository.AuditRepository
+	sender          webhook.Sender
+	settlementZone  *time.Location // a business day ends at midnight in this zone
+	linkBaseURL     string
+}
+
+func NewMerchantService(
+	merchantRepo repository.MerchantRepository,
+	accountRepo repository.AccountRepository,
+	transactionRepo repository.TransactionRepository,
+	auditRepo repository.AuditRepository,
+	sender webhook.Sender,
+	settlementZone *time.Location,
+	linkBaseURL string,
+) MerchantService {
+	return &merchantService{
+		merchantRepo:    merchantRepo,
+		accountRepo:     accountRepo,
+		transactionRepo: transactionRepo,
+		auditRepo:       auditRepo,
+		sender:          sender,
+		settlementZone:  settlementZone,
+		linkBaseURL:     strings.TrimRight(linkBaseURL, "/"),
+	}
+}
+
+// RegisterMerchant creates a merchant settling into one of the owner's accounts.
+// The API key and webhook secret are returned once and only the key's hash is kept.
+func (s *merchantService) RegisterMerchant(ownerID uuid.UUID, req *merchant.RegisterMerchantRequest) (*merchant.RegisterMerchantResponse, error) {
+	category := merchant.Category(req.Category)
+	if !merchant.IsValidCategory(category) {
+		return nil, fmt.Errorf("invalid merchant category")
+	}
+
+	accountID, err := uuid.Parse(req.SettlementAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid settlement_account_id")
+	}
+
+	acct, err := s.accountRepo.GetByID(accountID)
+	if err != nil {
+		return nil, fmt.Errorf("account not found")
+	}
+	if acct.UserID != ownerID {
+		return nil, fmt.Errorf("unauthorized: account does not belong to user")
+	}
+	if acct.Status != account.AccountStatusActive {
+		return nil, fmt.Errorf("account is %s, cannot receive settlements", acct.Status)
+	}
+
+	apiKey, err := generateMerchantSecret(merchant.APIKeyPrefix)
+	if err != nil {
+		return nil, err
+	}
+	webhookSecret, err := generateMerchantSecret("whsec_")
+	if err != nil {
+		return nil, err
+	}
+
+	m := &merchant.Merchant{
+		ID:                  uuid.New(),
+		OwnerID:             ownerID,
+		Name:                req.Name,
+		Category:            category,
+		SettlementAccountID: accountID,
+		WebhookURL:          req.WebhookURL,
+		WebhookSecret:       webhookSecret,
+		APIKeyHint:          apiKeyHint(apiKey),
+		APIKeyHash:          hashMerchantAPIKey(apiKey),
+		Status:              merchant.StatusActive,
+	}
+
+	if err := s.merchantRepo.Create(m); err != nil {
+		return nil, err
+	}
+
+	s.audit(ownerID, "MERCHANT_REGISTERED", fmt.Sprintf("merchant:%s", m.ID), map[string]interface{}{
+		"name":                  m.Name,
+		"settlement_account_id": accountID.String(),
+	})
+
+	return &merchant.RegisterMerchantResponse{
+		Merchant:      m,
+		APIKey:        apiKey,
+		WebhookSecret: webhookSecret,
+	}, nil
+}
+
+func (s *merchantService) ListMerchants(ownerID uuid.UUID) ([]*merchant.Merchant, error) {
+	return s.merchantRepo.ListByOwner(ownerID)
+}
+
+// RotateAPIKey replaces the merchant's API key; the old key stops working immediately
+func (s *merchantService) RotateAPIKey(ownerID uuid.UUID, merchantID uuid.UUID) (*merchant.RotateAPIKeyResponse, error) {
+	if _, err := s.ownedMerchant(ownerID, merchantID); err != nil {
+		return nil, err
+	}
+
+	apiKey, err := generateMerchantSecret(merchant.APIKeyPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	hint := apiKeyHint(apiKey)
+	if err := s.merchantRepo.UpdateAPIKey(merchantID, hint, hashMerchantAPIKey(apiKey)); err != nil {
+		return nil, err
+	}
+
+	s.audit(ownerID, "MERCHANT_API_KEY_ROTATED", fmt.Sprintf("merchant:%s", merchantID), map[string]interface{}{
+		"api_key_hint": hint,
+	})
+
+	return &merchant.RotateAPIKeyResponse{APIKey: apiKey, APIKeyHint: hint}, nil
+}
+
+func (s *merchantService) UpdateWebhook(ownerID uuid.UUID, merchantID uuid.UUID, req *merchant.UpdateWebhookRequest) (*merchant.Merchant, error) {
+	m, err := s.ownedMerchant(ownerID, merchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.merchantRepo.UpdateWebhook(merchantID, req.WebhookURL); err != nil {
+		return nil, err
+	}
+
+	s.audit(ownerID, "MERCHANT_WEBHOOK_UPDATED", fmt.Sprintf("merchant:%s", merchantID), map[string]interface{}{
+		"previous_url": m.WebhookURL,
+		"url":          req.WebhookURL,
+	})
+
+	m.WebhookURL = req.WebhookURL
+	return m, nil
+}
+
+func (s *merchantService) RotateWebhookSecret(ownerID uuid.UUID, merchantID uuid.UUID) (*merchant.RotateWebhookSecretResponse, error) {
+	if _, err := s.ownedMerchant(ownerID, merchantID); err != nil {
+		return nil, err
+	}
+
+	secret, err := generateMerchantSecret("whsec_")
+	if err != nil {
+		return nil, err
+	}
+	if err := s.merchantRepo.UpdateWebhookSecret(merchantID, secret); err != nil {
+		return nil, err
+	}
+
+	s.audit(ownerID, "MERCHANT_WEBHOOK_SECRET_ROTATED", fmt.Sprintf("merchant:%s", merchantID), nil)
+
+	return &merchant.RotateWebhookSecretResponse{WebhookSecret: secret}, nil
+}
+
+func (s *merchantService) ListWebhookDeliveries(ownerID uuid.UUID, merchantID uuid.UUID) ([]*merchant.WebhookDelivery, error) {
+	if _, err := s.ownedMerchant(ownerID, merchantID); err != nil {
+		return nil, err
+	}
+	return s.merchantRepo.ListWebhookDeliveries(merchantID, maxMerchantItemsListed)
+}
+
+func (s *merchantService) TestWebhook(ownerID uuid.UUID, merchantID uuid.UUID) (*merchant.WebhookDelivery, error) {
+	m, err := s.ownedMerchant(ownerID, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	if m.WebhookURL == "" {
+		return nil, fmt.Errorf("merchant has no webhook URL")
+	}
+
+	payload, err := json.Marshal(merchant.WebhookTestNotification{
+		Event:      merchant.EventWebhookTest,
+		MerchantID: m.ID,
+		SentAt:     time.Now().UTC(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode test event: %w", err)
+	}
+
+	d, _ := s.send(m.ID, m.WebhookURL, m.WebhookSecret, merchant.EventWebhookTest, payload, nil)
+	return d, nil
+}
+
+func (s *merchantService) ReplayWebhook(ownerID uuid.UUID, merchantID uuid.UUID, req *merchant.ReplayWebhookRequest) (*merchant.ReplayWebhookResponse, error) {
+	since, err := req.SinceTime(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := s.ownedMerchant(ownerID, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	if m.WebhookURL == "" {
+		return nil, fmt.Errorf("merchant has no webhook URL")
+	}
+
+	scheduled, err := s.merchantRepo.ScheduleRedelivery(merchantID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit(ownerID, "MERCHANT_WEBHOOK_REPLAYED", fmt.Sprintf("merchant:%s", merchantID), map[string]interface{}{
+		"since":     since,
+		"scheduled": scheduled,
+	})
+
+	return &merchant.ReplayWebhookResponse{Since: since, Scheduled: scheduled}, nil
+}
+
+// ownedMerchant loads a merchant, checking it belongs to the user
+func (s *merchantService) ownedMerchant(ownerID uuid.UUID, merchantID uuid.UUID) (*merchant.Merchant, error) {
+	m, err := s.merchantRepo.GetByID(merchantID)
+	if err != nil {
+		return nil, err
+	}
+	if m.OwnerID != ownerID {
+		return nil, fmt.Errorf("unauthorized: merchant does not belong to user")
+	}
+	return m, nil
+}
+
+func (s *merchantService) Authenticate(apiKey string) (*merchant.Merchant, error) {
+	if !strings.HasPrefix(apiKey, merchant.APIKeyPrefix) {
+		return nil, fmt.Errorf("invalid API key")
+	}
+
+	// Lookup is by hash, so the comparison never touches the key itself
+	m, err := s.merchantRepo.GetByAPIKeyHash(hashMerchantAPIKey(apiKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid API key")
+	}
+	if m.Status != merchant.StatusActive {
+		return nil, fmt.Errorf("merchant is %s", m.Status)
+	}
+
+	return m, nil
+}
+
+func (s *merchantService) CreatePaymentLink(merchantID uuid.UUID, req *merchant.CreatePaymentLinkRequest) (*merchant.PaymentLink, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if req.Amount < merchant.MinPaymentAmount || req.Amount > merchant.MaxPaymentAmount {
+		return nil, fmt.Errorf("amount must be between %d and %d", merchant.MinPaymentAmount, merchant.MaxPaymentAmount)
+	}
+
+	ttl := merchant.DefaultPaymentLinkTTL
+	if req.ExpiresInMinutes > 0 {
+		ttl = time.Duration(req.ExpiresInMinutes) * time.Minute
+	}
+	if ttl > merchant.MaxPaymentLinkTTL {
+		return nil, fmt.Errorf("payment links expire after at most %d days", int(merchant.MaxPaymentLinkTTL.Hours()/24))
+	}
+
+	m, err := s.merchantRepo.GetByID(merchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	link := &merchant.PaymentLink{
+		ID:           uuid.New(),
+		MerchantID:   merchantID,
+		MerchantName: m.Name,
+		Reference:    req.Reference,
+		Amount:       req.Amount,
+		Description:  req.Description,
+		Status:       merchant.PaymentLinkStatusOpen,
+		ExpiresAt:    time.Now().UTC().Add(ttl),
+	}
+
+	if err := s.merchantRepo.CreatePaymentLink(link); err != nil {
+		return nil, err
+	}
+
+	return s.present(link), nil
+}
+
+func (s *merchantService) GetMerchantPaymentLink(merchantID uuid.UUID, linkID uuid.UUID) (*merchant.PaymentLink, error) {
+	link, err := s.merchantRepo.GetPaymentLink(linkID)
+	if err != nil {
+		return nil, err
+	}
+	if link.MerchantID != merchantID {
+		return nil, fmt.Errorf("payment link not found")
+	}
+
+	return s.present(link), nil
+}
+
+func (s *merchantService) ListPaymentLinks(merchantID uuid.UUID) ([]*merchant.PaymentLink, error) {
+	links, err := s.merchantRepo.ListPaymentLinks(merchantID, maxMerchantItemsListed)
+	if err != nil {
+		return nil, err
+	}
+	for _, link := range links {
+		s.present(link)
+	}
+
+	return links, nil
+}
+
+func (s *merchantService) CancelPaymentLink(merchantID uuid.UUID, linkID uuid.UUID) (*merchant.PaymentLink, error) {
+	if err := s.merchantRepo.CancelPaymentLink(linkID, merchantID); err != nil {
+		return nil, err
+	}
+
+	return s.GetMerchantPaymentLink(merchantID, linkID)
+}
+
+func (s *merchantService) ListSettlements(merchantID uuid.UUID) ([]*merchant.Settlement, error) {
+	return s.merchantRepo.ListSettlements(merchantID, maxMerchantItemsListed)
+}
+
+func (s *merchantService) GetPaymentLink(linkID uuid.UUID) (*merchant.PaymentLink, error) {
+	link, err := s.merchantRepo.GetPaymentLink(linkID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.present(link), nil
+}
+
+func (s *merchantService) ResolveQR(payload string) (*merchant.PaymentLink, error) {
+	linkID, err := merchant.ParseQRPayload(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetPaymentLink(linkID)
+}
+
+// PayPaymentLink debits the payer's account for the link amount and notifies the
+// merchant. The merchant is credited at the next daily settlement.
+func (s *merchantService) PayPaymentLink(userID uuid.UUID, linkID uuid.UUID, req *merchant.PayPaymentLinkRequest) (*merchant.PaymentLink, error) {
+	start := time.Now()
+
+	accountID, err := uuid.Parse(req.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account_id")
+	}
+
+	// Check idempotency
+	if existing, err := s.transactionRepo.GetByIdempotencyKey(req.IdempotencyKey); err == nil {
+		link, err := s.merchantRepo.GetPaymentLink(linkID)
+		if err != nil || link.TransactionID == nil || *link.TransactionID != existing.ID {
+			return nil, fmt.Errorf("idempotency key already used")
+		}
+		return s.present(link), nil
+	}
+
+	link, err := s.merchantRepo.GetPaymentLink(linkID)
+	if err != nil {
+		return nil, err
+	}
+	if status := link.EffectiveStatus(time.Now()); status != merchant.PaymentLinkStatusOpen {
+		return nil, fmt.Errorf("payment link is %s", status)
+	}
+
+	acct, err := s.accountRepo.GetByID(accountID)
+	if err != nil {
+		return nil, fmt.Errorf("account not found")
+	}
+	if acct.UserID != userID {
+		return nil, fmt.Errorf("unauthorized: account does not belong to user")
+	}
+	if acct.Status != account.AccountStatusActive {
+		return nil, fmt.Errorf("account is %s, cannot perform payments", acct.Status)
+	}
+
+	txn := &transaction.Transaction{
+		ID:              uuid.New(),
+		IdempotencyKey:  req.IdempotencyKey,
+		FromAccountID:   &accountID,
+		Amount:          link.Amount,
+		TransactionType: transaction.TransactionTypeMerchantPayment,
+		Status:          transaction.TransactionStatusCompleted,
+		Description:     fmt.Sprintf("Payment to %s", link.MerchantName),
+		Metadata: map[string]interface{}{
+			"initiated_by":    userID.String(),
+			"currency":        acct.Currency,
+			"merchant_id":     link.MerchantID.String(),
+			"payment_link_id": link.ID.String(),
+			"reference":       link.Reference,
+		},
+	}
+
+	if err := s.merchantRepo.PayPaymentLink(link.ID, accountID, txn); err != nil {
+		metrics.RecordTransactionError("merchant_payment", "execution_failed")
+		return nil, err
+	}
+
+	metrics.RecordTransaction("merchant_payment", "completed", link.Amount, acct.Currency, time.Since(start).Seconds())
+
+	s.audit(userID, "MERCHANT_PAYMENT_COMPLETED", fmt.Sprintf("payment_link:%s", link.ID), map[string]interface{}{
+		"merchant_id":    link.MerchantID.String(),
+		"amount":         link.Amount,
+		"transaction_id": txn.ID.String(),
+	})
+
+	paid, err := s.merchantRepo.GetPaymentLink(link.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Try the webhook right away; failures are retried by the notifier job
+	s.notifyNow(paid)
+
+	return s.present(paid), nil
+}
+
+// SettleMerchants pays out every merchant's payments from previous business days.
+// It returns how many merchants were settled; merchants that fail are retried on the next run.
+func (s *merchantService) SettleMerchants(now time.Time) (int, error) {
+	cutoff, businessDate := merchant.SettlementCutoff(now, s.settlementZone)
+
+	// paid_at is stored without a zone, in UTC
+	merchantIDs, err := s.merchantRepo.ListUnsettledMerchants(cutoff.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	settled := 0
+	for _, merchantID := range merchantIDs {
+		settlement, err := s.merchantRepo.Settle(merchantID, cutoff.UTC(), businessDate)
+		if err != nil {
+			logger.Error("Failed to settle merchant", zap.String("merchant_id", merchantID.String()), zap.Error(err))
+			errtrack.CaptureError(err, map[string]string{"component": "merchant_service", "operation": "settle"})
+			continue
+		}
+		if settlement == nil {
+			continue
+		}
+
+		metrics.RecordTransaction("merchant_settlement", "completed", settlement.NetAmount, DefaultCurrency, 0)
+		settled++
+	}
+
+	return settled, nil
+}
+
+// DeliverNotifications retries webhooks that are due and returns how many were delivered
+func (s *merchantService) DeliverNotifications(now time.Time) (int, error) {
+	pending, err := s.merchantRepo.ListPendingNotifications(now.UTC(), merchantNotifyBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, n := range pending {
+		if s.deliver(n, now) {
+			delivered++
+		}
+	}
+
+	return delivered, nil
+}
+
+func (s *merchantService) notifyNow(link *merchant.PaymentLink) {
+	m, err := s.merchantRepo.GetByID(link.MerchantID)
+	if err != nil || m.WebhookURL == "" {
+		return
+	}
+
+	s.deliver(&merchant.PendingNotification{
+		Link:          link,
+		WebhookURL:    m.WebhookURL,
+		WebhookSecret: m.WebhookSecret,
+	}, time.Now())
+}
+
+// deliver posts one payment notification and records the outcome
+func (s *merchantService) deliver(n *merchant.PendingNotification, now time.Time) bool {
+	link := n.Link
+	if link.TransactionID == nil || link.PaidAt == nil {
+		return false
+	}
+
+	payload, err := json.Marshal(merchant.PaymentNotification{
+		Event:         merchant.EventPaymentReceived,
+		MerchantID:    link.MerchantID,
+		PaymentLinkID: link.ID,
+		Reference:     link.Reference,
+		Amount:        link.Amount,
+		TransactionID: *link.TransactionID,
+		PaidAt:        *link.PaidAt,
+	})
+	if err != nil {
+		return false
+	}
+
+	_, sendErr := s.send(link.MerchantID, n.WebhookURL, n.WebhookSecret, merchant.EventPaymentReceived, payload, &link.ID)
+	if sendErr == nil {
+		if err := s.merchantRepo.MarkNotified(link.ID); err != nil {
+			logger.Error("Failed to mark merchant notification delivered", zap.String("payment_link_id", link.ID.String()), zap.Error(err))
+		}
+		return true
+	}
+
+	attempts := n.Attempts + 1
+	var next *time.Time
+	if attempts < merchant.MaxNotifyAttempts {
+		at := now.UTC().Add(merchant.NotifyBackoff(attempts))
+		next = &at
+	}
+	logger.Warn("Merchant webhook delivery failed",
+		zap.String("payment_link_id", link.ID.String()),
+		zap.Int("attempts", attempts),
+		zap.Bool("will_retry", next != nil),
+		zap.Error(sendErr),
+	)
+	if err := s.merchantRepo.RecordNotifyFailure(link.ID, next); err != nil {
+		logger.Error("Failed to record merchant notification failure", zap.String("payment_link_id", link.ID.String()), zap.Error(err))
+	}
+
+	return false
+}
+
+// send posts one webhook event and records the attempt in the merchant's
+// delivery log
+func (s *merchantService) send(merchantID uuid.UUID, url, secret, event string, payload []byte, linkID *uuid.UUID) (*merchant.WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), merchantWebhookTimeout)
+	defer cancel()
+
+	start := time.Now()
+	status, sendErr := s.sender.Send(ctx, url, secret, event, payload)
+
+	d := &merchant.WebhookDelivery{
+		ID:            uuid.New(),
+		MerchantID:    merchantID,
+		Event:         event,
+		URL:           url,
+		PaymentLinkID: linkID,
+		StatusCode:    status,
+		Success:       sendErr == nil,
+		DurationMs:    time.Since(start).Milliseconds(),
+		CreatedAt:     start.UTC(),
+	}
+	if sendErr != nil {
+		d.Error = sendErr.Error()
+	}
+	if err := s.merchantRepo.RecordWebhookDelivery(d); err != nil {
+		logger.Error("Failed to record webhook delivery", zap.String("merchant_id", merchantID.String()), zap.Error(err))
+	}
+
+	return d, sendErr
+}
+
+// present fills in the derived fields shown to merchants and payers
+func (s *merchantService) present(link *merchant.PaymentLink) *merchant.PaymentLink {
+	link.Status = link.EffectiveStatus(time.Now())
+	link.QRPayload = merchant.QRPayloadPrefix + link.ID.String()
+	if s.linkBaseURL != "" {
+		link.URL = s.linkBaseURL + "/" + link.ID.String()
+	}
+	return link
+}
+
+func (s *merchantService) audit(userID uuid.UUID, action, resource string, metadata map[string]interface{}) {
+	if err := s.auditRepo.Create(&audit.AuditLog{
+		EventID:  uuid.New(),
+		UserID:   &userID,
+		Action:   action,
+		Resource: resource,
+		Status:   "success",
+		Metadata: metadata,
+	}); err != nil {
+		logger.Error("Failed to create audit log for merchant", zap.String("action", action), zap.Error(err))
+		errtrack.CaptureError(err, map[string]string{"component": "merchant_service", "operation": "audit_log"})
+	}
+}
+
+// generateMerchantSecret returns the prefix followed by 32 random hex characters
+func generateMerchantSecret(prefix string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	return prefix + hex.EncodeToString(b), nil
+}
+
+// hashMerchantAPIKey is unsalted so keys can be looked up by hash; the keys are
+// random, so a plain SHA-256 is enough
+func hashMerchantAPIKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])
+}
+
+func apiKeyHint(apiKey string) string {
+	return "..." + apiKey[len(apiKey)-merchantAPIKeyHintChars:]
+}
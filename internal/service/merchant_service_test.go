@@ -0,0 +1,523 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	domainAccount "github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/merchant"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockMerchantRepository is a mock implementation of repository.MerchantRepository
+type MockMerchantRepository struct {
+	mock.Mock
+}
+
+func (m *MockMerchantRepository) Create(mer *merchant.Merchant) error {
+	args := m.Called(mer)
+	return args.Error(0)
+}
+
+func (m *MockMerchantRepository) GetByID(id uuid.UUID) (*merchant.Merchant, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*merchant.Merchant), args.Error(1)
+}
+
+func (m *MockMerchantRepository) GetByAPIKeyHash(hash string) (*merchant.Merchant, error) {
+	args := m.Called(hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*merchant.Merchant), args.Error(1)
+}
+
+func (m *MockMerchantRepository) ListByOwner(ownerID uuid.UUID) ([]*merchant.Merchant, error) {
+	args := m.Called(ownerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*merchant.Merchant), args.Error(1)
+}
+
+func (m *MockMerchantRepository) UpdateAPIKey(id uuid.UUID, hint, hash string) error {
+	args := m.Called(id, hint, hash)
+	return args.Error(0)
+}
+
+func (m *MockMerchantRepository) UpdateWebhook(id uuid.UUID, url string) error {
+	args := m.Called(id, url)
+	return args.Error(0)
+}
+
+func (m *MockMerchantRepository) UpdateWebhookSecret(id uuid.UUID, secret string) error {
+	args := m.Called(id, secret)
+	return args.Error(0)
+}
+
+func (m *MockMerchantRepository) CreatePaymentLink(link *merchant.PaymentLink) error {
+	args := m.Called(link)
+	return args.Error(0)
+}
+
+func (m *MockMerchantRepository) GetPaymentLink(id uuid.UUID) (*merchant.PaymentLink, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*merchant.PaymentLink), args.Error(1)
+}
+
+func (m *MockMerchantRepository) ListPaymentLinks(merchantID uuid.UUID, limit int) ([]*merchant.PaymentLink, error) {
+	args := m.Called(merchantID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*merchant.PaymentLink), args.Error(1)
+}
+
+func (m *MockMerchantRepository) PayPaymentLink(linkID, payerAccountID uuid.UUID, txn *transaction.Transaction) error {
+	args := m.Called(linkID, payerAccountID, txn)
+	return args.Error(0)
+}
+
+func (m *MockMerchantRepository) CancelPaymentLink(linkID, merchantID uuid.UUID) error {
+	args := m.Called(linkID, merchantID)
+	return args.Error(0)
+}
+
+func (m *MockMerchantRepository) ListUnsettledMerchants(cutoff time.Time) ([]uuid.UUID, error) {
+	args := m.Called(cutoff)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockMerchantRepository) Settle(merchantID uuid.UUID, cutoff, businessDate time.Time) (*merchant.Settlement, error) {
+	args := m.Called(merchantID, cutoff, businessDate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*merchant.Settlement), args.Error(1)
+}
+
+func (m *MockMerchantRepository) ListSettlements(merchantID uuid.UUID, limit int) ([]*merchant.Settlement, error) {
+	args := m.Called(merchantID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*merchant.Settlement), args.Error(1)
+}
+
+func (m *MockMerchantRepository) ListPendingNotifications(now time.Time, limit int) ([]*merchant.PendingNotification, error) {
+	args := m.Called(now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*merchant.PendingNotification), args.Error(1)
+}
+
+func (m *MockMerchantRepository) MarkNotified(linkID uuid.UUID) error {
+	args := m.Called(linkID)
+	return args.Error(0)
+}
+
+func (m *MockMerchantRepository) RecordNotifyFailure(linkID uuid.UUID, nextAttemptAt *time.Time) error {
+	args := m.Called(linkID, nextAttemptAt)
+	return args.Error(0)
+}
+
+func (m *MockMerchantRepository) ScheduleRedelivery(merchantID uuid.UUID, since time.Time) (int, error) {
+	args := m.Called(merchantID, since)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockMerchantRepository) RequeueFailedNotifications(since time.Time) (int, error) {
+	args := m.Called(since)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockMerchantRepository) RecordWebhookDelivery(d *merchant.WebhookDelivery) error {
+	args := m.Called(d)
+	return args.Error(0)
+}
+
+func (m *MockMerchantRepository) ListWebhookDeliveries(merchantID uuid.UUID, limit int) ([]*merchant.WebhookDelivery, error) {
+	args := m.Called(merchantID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*merchant.WebhookDelivery), args.Error(1)
+}
+
+// MockWebhookSender is a mock implementation of webhook.Sender
+type MockWebhookSender struct {
+	mock.Mock
+}
+
+func (m *MockWebhookSender) Send(ctx context.Context, url, secret, event string, payload []byte) (int, error) {
+	args := m.Called(url, secret, event, payload)
+	return args.Int(0), args.Error(1)
+}
+
+var testSettlementZone = time.FixedZone("WIB", 7*60*60)
+
+func setupMerchantServiceTest(t *testing.T) (*merchantService, *MockMerchantRepository, *MockWebhookSender, uuid.UUID, uuid.UUID) {
+	logger.Init("test")
+	merchantRepo := new(MockMerchantRepository)
+	accountRepo := new(MockAccountRepository)
+	txnRepo := new(MockTransactionRepository)
+	auditRepo := new(MockAuditRepository)
+	sender := new(MockWebhookSender)
+
+	userID := uuid.New()
+	accountID := uuid.New()
+	accountRepo.On("GetByID", accountID).Return(&domainAccount.Account{
+		ID: accountID, UserID: userID, Status: domainAccount.AccountStatusActive, Currency: "IDR",
+	}, nil)
+	txnRepo.On("GetByIdempotencyKey", mock.Anything).Return(nil, fmt.Errorf("transaction not found"))
+	auditRepo.On("Create", mock.Anything).Return(nil)
+
+	svc := NewMerchantService(merchantRepo, accountRepo, txnRepo, auditRepo, sender, testSettlementZone, "https://pay.madabank.id/").(*merchantService)
+	return svc, merchantRepo, sender, userID, accountID
+}
+
+func TestRegisterMerchant(t *testing.T) {
+	svc, merchantRepo, _, userID, accountID := setupMerchantServiceTest(t)
+
+	var stored *merchant.Merchant
+	merchantRepo.On("Create", mock.MatchedBy(func(m *merchant.Merchant) bool {
+		stored = m
+		return m.OwnerID == userID && m.SettlementAccountID == accountID && m.Status == merchant.StatusActive
+	})).Return(nil)
+
+	resp, err := svc.RegisterMerchant(userID, &merchant.RegisterMerchantRequest{
+		Name:                "Warung Kopi",
+		Category:            "food_beverage",
+		SettlementAccountID: accountID.String(),
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(resp.APIKey, merchant.APIKeyPrefix))
+	assert.True(t, strings.HasPrefix(resp.WebhookSecret, "whsec_"))
+	assert.Equal(t, hashMerchantAPIKey(resp.APIKey), stored.APIKeyHash)
+	assert.NotContains(t, stored.APIKeyHash, resp.APIKey)
+	assert.Equal(t, "..."+resp.APIKey[len(resp.APIKey)-4:], stored.APIKeyHint)
+}
+
+func TestRegisterMerchant_OtherUsersAccount(t *testing.T) {
+	svc, merchantRepo, _, _, accountID := setupMerchantServiceTest(t)
+
+	_, err := svc.RegisterMerchant(uuid.New(), &merchant.RegisterMerchantRequest{
+		Name:                "Warung Kopi",
+		Category:            "retail",
+		SettlementAccountID: accountID.String(),
+	})
+
+	assert.EqualError(t, err, "unauthorized: account does not belong to user")
+	merchantRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestAuthenticate(t *testing.T) {
+	svc, merchantRepo, _, _, _ := setupMerchantServiceTest(t)
+	apiKey := merchant.APIKeyPrefix + "0123456789abcdef0123456789abcdef"
+	active := &merchant.Merchant{ID: uuid.New(), Status: merchant.StatusActive}
+	merchantRepo.On("GetByAPIKeyHash", hashMerchantAPIKey(apiKey)).Return(active, nil)
+	merchantRepo.On("GetByAPIKeyHash", mock.Anything).Return(nil, fmt.Errorf("merchant not found"))
+
+	m, err := svc.Authenticate(apiKey)
+	assert.NoError(t, err)
+	assert.Equal(t, active.ID, m.ID)
+
+	_, err = svc.Authenticate(merchant.APIKeyPrefix + "unknown")
+	assert.EqualError(t, err, "invalid API key")
+
+	_, err = svc.Authenticate("not-a-merchant-key")
+	assert.EqualError(t, err, "invalid API key")
+}
+
+func TestRotateAPIKey_NotOwner(t *testing.T) {
+	svc, merchantRepo, _, _, _ := setupMerchantServiceTest(t)
+	merchantID := uuid.New()
+	merchantRepo.On("GetByID", merchantID).Return(&merchant.Merchant{ID: merchantID, OwnerID: uuid.New()}, nil)
+
+	_, err := svc.RotateAPIKey(uuid.New(), merchantID)
+
+	assert.EqualError(t, err, "unauthorized: merchant does not belong to user")
+	merchantRepo.AssertNotCalled(t, "UpdateAPIKey", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreatePaymentLink(t *testing.T) {
+	svc, merchantRepo, _, _, _ := setupMerchantServiceTest(t)
+	merchantID := uuid.New()
+	merchantRepo.On("GetByID", merchantID).Return(&merchant.Merchant{ID: merchantID, Name: "Warung Kopi"}, nil)
+	merchantRepo.On("CreatePaymentLink", mock.MatchedBy(func(l *merchant.PaymentLink) bool {
+		return l.MerchantID == merchantID && l.Amount == 150_000 && l.Status == merchant.PaymentLinkStatusOpen &&
+			time.Until(l.ExpiresAt) > 59*time.Minute && time.Until(l.ExpiresAt) <= time.Hour
+	})).Return(nil)
+
+	link, err := svc.CreatePaymentLink(merchantID, &merchant.CreatePaymentLinkRequest{
+		Reference:        "INV-001",
+		Amount:           150_000,
+		ExpiresInMinutes: 60,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, merchant.QRPayloadPrefix+link.ID.String(), link.QRPayload)
+	assert.Equal(t, "https://pay.madabank.id/"+link.ID.String(), link.URL)
+	merchantRepo.AssertExpectations(t)
+}
+
+func TestCreatePaymentLink_AmountOutOfRange(t *testing.T) {
+	svc, merchantRepo, _, _, _ := setupMerchantServiceTest(t)
+
+	_, err := svc.CreatePaymentLink(uuid.New(), &merchant.CreatePaymentLinkRequest{Reference: "INV-001", Amount: 500})
+
+	assert.EqualError(t, err, "amount must be between 1000 and 50000000")
+	merchantRepo.AssertNotCalled(t, "CreatePaymentLink", mock.Anything)
+}
+
+func TestGetMerchantPaymentLink_OtherMerchant(t *testing.T) {
+	svc, merchantRepo, _, _, _ := setupMerchantServiceTest(t)
+	linkID := uuid.New()
+	merchantRepo.On("GetPaymentLink", linkID).Return(&merchant.PaymentLink{ID: linkID, MerchantID: uuid.New()}, nil)
+
+	_, err := svc.GetMerchantPaymentLink(uuid.New(), linkID)
+
+	assert.EqualError(t, err, "payment link not found")
+}
+
+func TestPayPaymentLink_NotifiesMerchant(t *testing.T) {
+	svc, merchantRepo, sender, userID, accountID := setupMerchantServiceTest(t)
+	merchantID := uuid.New()
+	linkID := uuid.New()
+	txnID := uuid.New()
+	paidAt := time.Now()
+
+	open := &merchant.PaymentLink{
+		ID: linkID, MerchantID: merchantID, MerchantName: "Warung Kopi", Reference: "INV-001",
+		Amount: 150_000, Status: merchant.PaymentLinkStatusOpen, ExpiresAt: time.Now().Add(time.Hour),
+	}
+	paid := &merchant.PaymentLink{
+		ID: linkID, MerchantID: merchantID, MerchantName: "Warung Kopi", Reference: "INV-001",
+		Amount: 150_000, Status: merchant.PaymentLinkStatusPaid, ExpiresAt: open.ExpiresAt,
+		TransactionID: &txnID, PaidAt: &paidAt,
+	}
+	merchantRepo.On("GetPaymentLink", linkID).Return(open, nil).Once()
+	merchantRepo.On("GetPaymentLink", linkID).Return(paid, nil).Once()
+	merchantRepo.On("PayPaymentLink", linkID, accountID, mock.MatchedBy(func(txn *transaction.Transaction) bool {
+		return txn.TransactionType == transaction.TransactionTypeMerchantPayment && txn.Amount == 150_000
+	})).Return(nil)
+	merchantRepo.On("GetByID", merchantID).Return(&merchant.Merchant{
+		ID: merchantID, WebhookURL: "https://shop.example/hooks", WebhookSecret: "whsec_1",
+	}, nil)
+	sender.On("Send", "https://shop.example/hooks", "whsec_1", merchant.EventPaymentReceived, mock.Anything).Return(200, nil)
+	merchantRepo.On("RecordWebhookDelivery", mock.MatchedBy(func(d *merchant.WebhookDelivery) bool {
+		return d.MerchantID == merchantID && *d.PaymentLinkID == linkID && d.StatusCode == 200 && d.Success
+	})).Return(nil)
+	merchantRepo.On("MarkNotified", linkID).Return(nil)
+
+	link, err := svc.PayPaymentLink(userID, linkID, &merchant.PayPaymentLinkRequest{
+		AccountID:      accountID.String(),
+		IdempotencyKey: "pay-1",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, merchant.PaymentLinkStatusPaid, link.Status)
+	merchantRepo.AssertExpectations(t)
+	sender.AssertExpectations(t)
+}
+
+func TestPayPaymentLink_Expired(t *testing.T) {
+	svc, merchantRepo, _, userID, accountID := setupMerchantServiceTest(t)
+	linkID := uuid.New()
+	merchantRepo.On("GetPaymentLink", linkID).Return(&merchant.PaymentLink{
+		ID: linkID, Status: merchant.PaymentLinkStatusOpen, ExpiresAt: time.Now().Add(-time.Minute),
+	}, nil)
+
+	_, err := svc.PayPaymentLink(userID, linkID, &merchant.PayPaymentLinkRequest{
+		AccountID:      accountID.String(),
+		IdempotencyKey: "pay-2",
+	})
+
+	assert.EqualError(t, err, "payment link is expired")
+	merchantRepo.AssertNotCalled(t, "PayPaymentLink", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSettleMerchants(t *testing.T) {
+	svc, merchantRepo, _, _, _ := setupMerchantServiceTest(t)
+	// 01:30 WIB on 2 March settles payments made up to midnight, for 1 March
+	now := time.Date(2026, 3, 1, 18, 30, 0, 0, time.UTC)
+	cutoff := time.Date(2026, 3, 1, 17, 0, 0, 0, time.UTC)
+	businessDate := time.Date(2026, 3, 1, 0, 0, 0, 0, testSettlementZone)
+
+	settledID, emptyID, failingID := uuid.New(), uuid.New(), uuid.New()
+	merchantRepo.On("ListUnsettledMerchants", cutoff).Return([]uuid.UUID{settledID, emptyID, failingID}, nil)
+	merchantRepo.On("Settle", settledID, cutoff, businessDate).Return(&merchant.Settlement{NetAmount: 99_300}, nil)
+	merchantRepo.On("Settle", emptyID, cutoff, businessDate).Return(nil, nil)
+	merchantRepo.On("Settle", failingID, cutoff, businessDate).Return(nil, fmt.Errorf("failed to lock merchant"))
+
+	settled, err := svc.SettleMerchants(now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, settled)
+	merchantRepo.AssertExpectations(t)
+}
+
+func TestDeliverNotifications_SchedulesRetry(t *testing.T) {
+	svc, merchantRepo, sender, _, _ := setupMerchantServiceTest(t)
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	txnID := uuid.New()
+
+	retrying := &merchant.PendingNotification{
+		Link:       &merchant.PaymentLink{ID: uuid.New(), TransactionID: &txnID, PaidAt: &now},
+		WebhookURL: "https://a.example/hooks", Attempts: 2,
+	}
+	givingUp := &merchant.PendingNotification{
+		Link:       &merchant.PaymentLink{ID: uuid.New(), TransactionID: &txnID, PaidAt: &now},
+		WebhookURL: "https://b.example/hooks", Attempts: merchant.MaxNotifyAttempts - 1,
+	}
+	merchantRepo.On("ListPendingNotifications", now, merchantNotifyBatch).Return([]*merchant.PendingNotification{retrying, givingUp}, nil)
+	sender.On("Send", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(503, fmt.Errorf("webhook receiver returned status 503"))
+	merchantRepo.On("RecordWebhookDelivery", mock.MatchedBy(func(d *merchant.WebhookDelivery) bool {
+		return d.StatusCode == 503 && !d.Success && d.Error == "webhook receiver returned status 503"
+	})).Return(nil).Twice()
+
+	nextAt := now.Add(merchant.NotifyBackoff(3))
+	merchantRepo.On("RecordNotifyFailure", retrying.Link.ID, &nextAt).Return(nil)
+	merchantRepo.On("RecordNotifyFailure", givingUp.Link.ID, (*time.Time)(nil)).Return(nil)
+
+	delivered, err := svc.DeliverNotifications(now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+	merchantRepo.AssertExpectations(t)
+}
+
+func TestUpdateWebhook(t *testing.T) {
+	svc, merchantRepo, _, userID, _ := setupMerchantServiceTest(t)
+	merchantID := uuid.New()
+	merchantRepo.On("GetByID", merchantID).Return(&merchant.Merchant{ID: merchantID, OwnerID: userID}, nil)
+	merchantRepo.On("UpdateWebhook", merchantID, "https://shop.example/hooks").Return(nil)
+
+	m, err := svc.UpdateWebhook(userID, merchantID, &merchant.UpdateWebhookRequest{WebhookURL: "https://shop.example/hooks"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "https://shop.example/hooks", m.WebhookURL)
+	merchantRepo.AssertExpectations(t)
+}
+
+func TestRotateWebhookSecret(t *testing.T) {
+	svc, merchantRepo, _, userID, _ := setupMerchantServiceTest(t)
+	merchantID := uuid.New()
+	merchantRepo.On("GetByID", merchantID).Return(&merchant.Merchant{ID: merchantID, OwnerID: userID, WebhookSecret: "whsec_old"}, nil)
+	merchantRepo.On("UpdateWebhookSecret", merchantID, mock.MatchedBy(func(secret string) bool {
+		return strings.HasPrefix(secret, "whsec_") && secret != "whsec_old"
+	})).Return(nil)
+
+	resp, err := svc.RotateWebhookSecret(userID, merchantID)
+
+	assert.NoError(t, err)
+	assert.Len(t, resp.WebhookSecret, len("whsec_")+32)
+	merchantRepo.AssertExpectations(t)
+}
+
+func TestRotateWebhookSecret_NotOwner(t *testing.T) {
+	svc, merchantRepo, _, _, _ := setupMerchantServiceTest(t)
+	merchantID := uuid.New()
+	merchantRepo.On("GetByID", merchantID).Return(&merchant.Merchant{ID: merchantID, OwnerID: uuid.New()}, nil)
+
+	_, err := svc.RotateWebhookSecret(uuid.New(), merchantID)
+
+	assert.EqualError(t, err, "unauthorized: merchant does not belong to user")
+	merchantRepo.AssertNotCalled(t, "UpdateWebhookSecret", mock.Anything, mock.Anything)
+}
+
+func TestTestWebhook_RecordsFailedAttempt(t *testing.T) {
+	svc, merchantRepo, sender, userID, _ := setupMerchantServiceTest(t)
+	merchantID := uuid.New()
+	merchantRepo.On("GetByID", merchantID).Return(&merchant.Merchant{
+		ID: merchantID, OwnerID: userID, WebhookURL: "https://shop.example/hooks", WebhookSecret: "whsec_1",
+	}, nil)
+	sender.On("Send", "https://shop.example/hooks", "whsec_1", merchant.EventWebhookTest, mock.Anything).
+		Return(404, fmt.Errorf("webhook receiver returned status 404"))
+	merchantRepo.On("RecordWebhookDelivery", mock.Anything).Return(nil)
+
+	d, err := svc.TestWebhook(userID, merchantID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, merchant.EventWebhookTest, d.Event)
+	assert.Equal(t, 404, d.StatusCode)
+	assert.False(t, d.Success)
+	assert.Nil(t, d.PaymentLinkID)
+	merchantRepo.AssertExpectations(t)
+}
+
+func TestTestWebhook_NoURL(t *testing.T) {
+	svc, merchantRepo, sender, userID, _ := setupMerchantServiceTest(t)
+	merchantID := uuid.New()
+	merchantRepo.On("GetByID", merchantID).Return(&merchant.Merchant{ID: merchantID, OwnerID: userID}, nil)
+
+	_, err := svc.TestWebhook(userID, merchantID)
+
+	assert.EqualError(t, err, "merchant has no webhook URL")
+	sender.AssertNotCalled(t, "Send", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestReplayWebhook(t *testing.T) {
+	svc, merchantRepo, _, userID, _ := setupMerchantServiceTest(t)
+	merchantID := uuid.New()
+	merchantRepo.On("GetByID", merchantID).Return(&merchant.Merchant{ID: merchantID, OwnerID: userID, WebhookURL: "https://shop.example/hooks"}, nil)
+	since := time.Now().UTC().Add(-6 * time.Hour).Truncate(time.Second)
+	merchantRepo.On("ScheduleRedelivery", merchantID, since).Return(3, nil)
+
+	resp, err := svc.ReplayWebhook(userID, merchantID, &merchant.ReplayWebhookRequest{Since: since.Format(time.RFC3339)})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, resp.Scheduled)
+	assert.Equal(t, since, resp.Since)
+	merchantRepo.AssertExpectations(t)
+}
+
+func TestReplayWebhook_Rejected(t *testing.T) {
+	svc, merchantRepo, _, userID, _ := setupMerchantServiceTest(t)
+	merchantID := uuid.New()
+	otherID := uuid.New()
+	merchantRepo.On("GetByID", merchantID).Return(&merchant.Merchant{ID: merchantID, OwnerID: userID}, nil)
+	merchantRepo.On("GetByID", otherID).Return(&merchant.Merchant{ID: otherID, OwnerID: uuid.New(), WebhookURL: "https://shop.example/hooks"}, nil)
+	recent := &merchant.ReplayWebhookRequest{Since: time.Now().Add(-time.Hour).Format(time.RFC3339)}
+
+	_, err := svc.ReplayWebhook(userID, merchantID, recent)
+	assert.EqualError(t, err, "merchant has no webhook URL")
+
+	_, err = svc.ReplayWebhook(userID, otherID, recent)
+	assert.EqualError(t, err, "unauthorized: merchant does not belong to user")
+
+	_, err = svc.ReplayWebhook(userID, merchantID, &merchant.ReplayWebhookRequest{Since: time.Now().AddDate(0, 0, -31).Format(time.RFC3339)})
+	assert.EqualError(t, err, "since must be within the last 30 days")
+
+	merchantRepo.AssertNotCalled(t, "ScheduleRedelivery", mock.Anything, mock.Anything)
+}
+
+func TestCancelPaymentLink_AlreadyPaid(t *testing.T) {
+	svc, merchantRepo, _, _, _ := setupMerchantServiceTest(t)
+	merchantID := uuid.New()
+	linkID := uuid.New()
+	merchantRepo.On("CancelPaymentLink", linkID, merchantID).Return(fmt.Errorf("payment link not found or not open"))
+
+	_, err := svc.CancelPaymentLink(merchantID, linkID)
+
+	assert.EqualError(t, err, "payment link not found or not open")
+	merchantRepo.AssertNotCalled(t, "GetPaymentLink", mock.Anything)
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/darisadam/madabank-server/internal/domain/audit"
+	"github.com/darisadam/madabank-server/internal/domain/note"
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// NoteService keeps support staff's internal notes on transactions and users
+type NoteService interface {
+	AddNote(authorID uuid.UUID, subjectType note.SubjectType, subjectID uuid.UUID, req *note.CreateNoteRequest) (*note.Note, error)
+	ListNotes(subjectType note.SubjectType, subjectID uuid.UUID) ([]*note.Note, error)
+}
+
+type noteService struct {
+	noteRepo  repository.NoteRepository
+	txnRepo   repository.TransactionRepository
+	userRepo  repository.UserRepository
+	auditRepo repository.AuditRepository
+}
+
+func NewNoteService(
+	noteRepo repository.NoteRepository,
+	txnRepo repository.TransactionRepository,
+	userRepo repository.UserRepository,
+	auditRepo repository.AuditRepository,
+) NoteService {
+	return &noteService{
+		noteRepo:  noteRepo,
+		txnRepo:   txnRepo,
+		userRepo:  userRepo,
+		auditRepo: auditRepo,
+	}
+}
+
+func (s *noteService) AddNote(authorID uuid.UUID, subjectType note.SubjectType, subjectID uuid.UUID, req *note.CreateNoteRequest) (*note.Note, error) {
+	if err := s.checkSubject(subjectType, subjectID); err != nil {
+		return nil, err
+	}
+
+	n := &note.Note{
+		ID:          uuid.New(),
+		SubjectType: subjectType,
+		SubjectID:   subjectID,
+		Body:        req.Body,
+		AuthorID:    authorID,
+	}
+	if err := s.noteRepo.Create(n); err != nil {
+		return nil, err
+	}
+
+	// The body stays out of the audit log; the note itself is the record
+	if err := s.auditRepo.Create(&audit.AuditLog{
+		EventID:  uuid.New(),
+		UserID:   &authorID,
+		Action:   "NOTE_ADDED",
+		Resource: fmt.Sprintf("%s:%s", subjectType, subjectID),
+		Status:   "success",
+		Metadata: map[string]interface{}{"note_id": n.ID},
+	}); err != nil {
+		logger.Error("Failed to create audit log for note", zap.Error(err))
+		errtrack.CaptureError(err, map[string]string{"component": "note_service", "operation": "audit_log"})
+	}
+
+	return n, nil
+}
+
+func (s *noteService) ListNotes(subjectType note.SubjectType, subjectID uuid.UUID) ([]*note.Note, error) {
+	if err := s.checkSubject(subjectType, subjectID); err != nil {
+		return nil, err
+	}
+	return s.noteRepo.ListBySubject(subjectType, subjectID)
+}
+
+// checkSubject returns an error unless the transaction or user exists
+func (s *noteService) checkSubject(subjectType note.SubjectType, subjectID uuid.UUID) error {
+	switch subjectType {
+	case note.SubjectTransaction:
+		if _, err := s.txnRepo.GetByID(subjectID); err != nil {
+			return fmt.Errorf("transaction %w", note.ErrSubjectNotFound)
+		}
+	case note.SubjectUser:
+		if _, err := s.userRepo.GetByID(subjectID); err != nil {
+			return fmt.Errorf("user %w", note.ErrSubjectNotFound)
+		}
+	default:
+		return fmt.Errorf("invalid note subject")
+	}
+	return nil
+}
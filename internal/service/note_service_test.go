@@ -0,0 +1,94 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/darisadam/madabank-server/internal/domain/audit"
+	"github.com/darisadam/madabank-server/internal/domain/note"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/domain/user"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockNoteRepository is a mock implementation of repository.NoteRepository
+type MockNoteRepository struct {
+	mock.Mock
+}
+
+func (m *MockNoteRepository) Create(n *note.Note) error {
+	args := m.Called(n)
+	return args.Error(0)
+}
+
+func (m *MockNoteRepository) ListBySubject(subjectType note.SubjectType, subjectID uuid.UUID) ([]*note.Note, error) {
+	args := m.Called(subjectType, subjectID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*note.Note), args.Error(1)
+}
+
+func setupNoteService() (NoteService, *MockNoteRepository, *MockTransactionRepository, *MockUserRepository, *MockAuditRepository) {
+	noteRepo := new(MockNoteRepository)
+	txnRepo := new(MockTransactionRepository)
+	userRepo := new(MockUserRepository)
+	auditRepo := new(MockAuditRepository)
+	return NewNoteService(noteRepo, txnRepo, userRepo, auditRepo), noteRepo, txnRepo, userRepo, auditRepo
+}
+
+func TestAddNote_Transaction(t *testing.T) {
+	svc, noteRepo, txnRepo, _, auditRepo := setupNoteService()
+	authorID, txnID := uuid.New(), uuid.New()
+
+	txnRepo.On("GetByID", txnID).Return(&transaction.Transaction{ID: txnID}, nil)
+	noteRepo.On("Create", mock.MatchedBy(func(n *note.Note) bool {
+		return n.SubjectType == note.SubjectTransaction && n.SubjectID == txnID &&
+			n.AuthorID == authorID && n.Body == "Customer disputes the merchant charge"
+	})).Return(nil)
+	auditRepo.On("Create", mock.MatchedBy(func(log *audit.AuditLog) bool {
+		_, hasBody := log.Metadata["body"]
+		return log.Action == "NOTE_ADDED" && log.Resource == "transaction:"+txnID.String() && !hasBody
+	})).Return(nil)
+
+	n, err := svc.AddNote(authorID, note.SubjectTransaction, txnID, &note.CreateNoteRequest{Body: "Customer disputes the merchant charge"})
+
+	assert.NoError(t, err)
+	assert.NotEqual(t, uuid.Nil, n.ID)
+	noteRepo.AssertExpectations(t)
+	auditRepo.AssertExpectations(t)
+}
+
+func TestAddNote_SubjectNotFound(t *testing.T) {
+	svc, noteRepo, txnRepo, userRepo, _ := setupNoteService()
+	missingID := uuid.New()
+	txnRepo.On("GetByID", missingID).Return(nil, fmt.Errorf("transaction not found"))
+	userRepo.On("GetByID", missingID).Return(nil, fmt.Errorf("user not found"))
+
+	for _, subjectType := range []note.SubjectType{note.SubjectTransaction, note.SubjectUser} {
+		t.Run(string(subjectType), func(t *testing.T) {
+			n, err := svc.AddNote(uuid.New(), subjectType, missingID, &note.CreateNoteRequest{Body: "Called the customer"})
+
+			assert.Nil(t, n)
+			assert.ErrorIs(t, err, note.ErrSubjectNotFound)
+			assert.EqualError(t, err, string(subjectType)+" not found")
+		})
+	}
+	noteRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestListNotes_User(t *testing.T) {
+	svc, noteRepo, _, userRepo, _ := setupNoteService()
+	userID := uuid.New()
+	notes := []*note.Note{{ID: uuid.New(), SubjectType: note.SubjectUser, SubjectID: userID, Body: "Prefers contact by email"}}
+
+	userRepo.On("GetByID", userID).Return(&user.User{ID: userID}, nil)
+	noteRepo.On("ListBySubject", note.SubjectUser, userID).Return(notes, nil)
+
+	result, err := svc.ListNotes(note.SubjectUser, userID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, notes, result)
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+)
+
+type ReceiptConfig struct {
+	BankName string
+	// ShareSecret signs public receipt links; sharing is disabled when empty
+	ShareSecret []byte
+	// ShareBaseURL is the public URL the link token is appended to
+	ShareBaseURL string
+	ShareTTL     time.Duration
+}
+
+func DefaultReceiptConfig() ReceiptConfig {
+	return ReceiptConfig{
+		BankName: "MadaBank",
+		ShareTTL: transaction.DefaultReceiptShareTTL,
+	}
+}
+
+type ReceiptService interface {
+	// GetReceipt returns the receipt of a completed transaction on one of the
+	// user's accounts, with a signed link to share it
+	GetReceipt(userID uuid.UUID, transactionID uuid.UUID) (*transaction.Receipt, error)
+	// GetSharedReceipt returns the limited receipt behind a public link
+	GetSharedReceipt(token string) (*transaction.Receipt, error)
+	RenderPDF(r *transaction.Receipt) []byte
+}
+
+type receiptService struct {
+	transactionService TransactionService
+	transactionRepo    repository.TransactionRepository
+	accountRepo        repository.AccountRepository
+	userRepo           repository.UserRepository
+	config             ReceiptConfig
+	zone               *time.Location
+}
+
+func NewReceiptService(
+	transactionService TransactionService,
+	transactionRepo repository.TransactionRepository,
+	accountRepo repository.AccountRepository,
+	userRepo repository.UserRepository,
+	config ReceiptConfig,
+	zone *time.Location,
+) ReceiptService {
+	config.ShareBaseURL = strings.TrimRight(config.ShareBaseURL, "/")
+	if config.ShareTTL <= 0 {
+		config.ShareTTL = transaction.DefaultReceiptShareTTL
+	}
+	return &receiptService{
+		transactionService: transactionService,
+		transactionRepo:    transactionRepo,
+		accountRepo:        accountRepo,
+		userRepo:           userRepo,
+		config:             config,
+		zone:               zone,
+	}
+}
+
+func (s *receiptService) GetReceipt(userID uuid.UUID, transactionID uuid.UUID) (*transaction.Receipt, error) {
+	txn, err := s.transactionService.GetTransaction(userID, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if txn.Status != transaction.TransactionStatusCompleted {
+		return nil, fmt.Errorf("receipts are only available for completed transactions")
+	}
+
+	r := s.build(txn)
+	if len(s.config.ShareSecret) > 0 && s.config.ShareBaseURL != "" {
+		expiresAt := time.Now().Add(s.config.ShareTTL).Truncate(time.Second)
+		r.ShareURL = s.config.ShareBaseURL + "/" + transaction.SignShareToken(s.config.ShareSecret, txn.ID, expiresAt)
+		r.ShareExpiresAt = &expiresAt
+	}
+
+	return r, nil
+}
+
+func (s *receiptService) GetSharedReceipt(token string) (*transaction.Receipt, error) {
+	if len(s.config.ShareSecret) == 0 {
+		return nil, transaction.ErrInvalidShareToken
+	}
+	transactionID, err := transaction.ParseShareToken(s.config.ShareSecret, token, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	txn, err := s.transactionRepo.GetByID(transactionID)
+	if err != nil {
+		return nil, transaction.ErrInvalidShareToken
+	}
+
+	return s.build(txn).Shared(), nil
+}
+
+func (s *receiptService) RenderPDF(r *transaction.Receipt) []byte {
+	return r.Render(s.config.BankName, s.zone)
+}
+
+func (s *receiptService) build(txn *transaction.Transaction) *transaction.Receipt {
+	return transaction.NewReceipt(txn, s.party(txn.FromAccountID), s.party(txn.ToAccountID))
+}
+
+// party describes a customer account on the receipt
+func (s *receiptService) party(accountID *uuid.UUID) *transaction.ReceiptParty {
+	if accountID == nil {
+		return nil
+	}
+	acct, err := s.accountRepo.GetByID(*accountID)
+	if err != nil {
+		return nil
+	}
+
+	p := &transaction.ReceiptParty{AccountNumber: transaction.MaskAccountNumber(acct.AccountNumber)}
+	if holder, err := s.userRepo.GetByID(acct.UserID); err == nil {
+		p.HolderName = transaction.ShortHolderName(holder.FirstName, holder.LastName)
+	}
+	return p
+}
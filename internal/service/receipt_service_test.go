@@ -0,0 +1,119 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/domain/user"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type receiptTest struct {
+	svc         ReceiptService
+	txnService  *MockTransactionService
+	txnRepo     *MockTransactionRepository
+	accountRepo *MockAccountRepository
+	userRepo    *MockUserRepository
+}
+
+func setupReceiptServiceTest() *receiptTest {
+	rt := &receiptTest{
+		txnService:  new(MockTransactionService),
+		txnRepo:     new(MockTransactionRepository),
+		accountRepo: new(MockAccountRepository),
+		userRepo:    new(MockUserRepository),
+	}
+	config := DefaultReceiptConfig()
+	config.ShareSecret = []byte("receipt-secret")
+	config.ShareBaseURL = "https://madabank.example/api/v1/receipts/"
+	rt.svc = NewReceiptService(rt.txnService, rt.txnRepo, rt.accountRepo, rt.userRepo, config, time.UTC)
+	return rt
+}
+
+func (rt *receiptTest) completedTransfer(userID uuid.UUID) *transaction.Transaction {
+	fromID, toID := uuid.New(), uuid.New()
+	payeeID := uuid.New()
+	rt.accountRepo.On("GetByID", fromID).Return(&account.Account{ID: fromID, UserID: userID, AccountNumber: "1000000001"}, nil)
+	rt.accountRepo.On("GetByID", toID).Return(&account.Account{ID: toID, UserID: payeeID, AccountNumber: "1000000002"}, nil)
+	rt.userRepo.On("GetByID", userID).Return(&user.User{ID: userID, FirstName: "Budi", LastName: "Santoso"}, nil)
+	rt.userRepo.On("GetByID", payeeID).Return(&user.User{ID: payeeID, FirstName: "Siti"}, nil)
+
+	return &transaction.Transaction{
+		ID: uuid.New(), FromAccountID: &fromID, ToAccountID: &toID, Amount: 150000,
+		TransactionType: transaction.TransactionTypeTransfer, Status: transaction.TransactionStatusCompleted,
+		Description: "Rent", CreatedAt: time.Now(),
+	}
+}
+
+func TestGetReceipt(t *testing.T) {
+	rt := setupReceiptServiceTest()
+	userID := uuid.New()
+	txn := rt.completedTransfer(userID)
+	rt.txnService.On("GetTransaction", userID, txn.ID).Return(txn, nil)
+
+	r, err := rt.svc.GetReceipt(userID, txn.ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Budi S.", r.From.HolderName)
+	assert.Equal(t, "******0001", r.From.AccountNumber)
+	assert.Equal(t, "Siti", r.To.HolderName)
+	assert.True(t, strings.HasPrefix(r.ShareURL, "https://madabank.example/api/v1/receipts/"))
+	assert.NotNil(t, r.ShareExpiresAt)
+}
+
+func TestGetReceipt_NotCompleted(t *testing.T) {
+	rt := setupReceiptServiceTest()
+	userID := uuid.New()
+	txn := &transaction.Transaction{ID: uuid.New(), Status: transaction.TransactionStatusPending}
+	rt.txnService.On("GetTransaction", userID, txn.ID).Return(txn, nil)
+
+	_, err := rt.svc.GetReceipt(userID, txn.ID)
+
+	assert.EqualError(t, err, "receipts are only available for completed transactions")
+}
+
+func TestGetReceipt_NotOwned(t *testing.T) {
+	rt := setupReceiptServiceTest()
+	userID, txnID := uuid.New(), uuid.New()
+	rt.txnService.On("GetTransaction", userID, txnID).Return(nil, fmt.Errorf("unauthorized: transaction does not belong to user"))
+
+	_, err := rt.svc.GetReceipt(userID, txnID)
+
+	assert.Error(t, err)
+}
+
+func TestGetSharedReceipt(t *testing.T) {
+	rt := setupReceiptServiceTest()
+	userID := uuid.New()
+	txn := rt.completedTransfer(userID)
+	rt.txnService.On("GetTransaction", userID, txn.ID).Return(txn, nil)
+	rt.txnRepo.On("GetByID", txn.ID).Return(txn, nil)
+
+	owned, err := rt.svc.GetReceipt(userID, txn.ID)
+	assert.NoError(t, err)
+	token := strings.TrimPrefix(owned.ShareURL, "https://madabank.example/api/v1/receipts/")
+
+	shared, err := rt.svc.GetSharedReceipt(token)
+
+	assert.NoError(t, err)
+	assert.Equal(t, txn.ID, shared.TransactionID)
+	assert.Empty(t, shared.Description)
+	assert.Empty(t, shared.ShareURL)
+
+	_, err = rt.svc.GetSharedReceipt(token + "x")
+	assert.ErrorIs(t, err, transaction.ErrInvalidShareToken)
+}
+
+func TestGetSharedReceipt_SharingDisabled(t *testing.T) {
+	svc := NewReceiptService(new(MockTransactionService), new(MockTransactionRepository), new(MockAccountRepository), new(MockUserRepository), DefaultReceiptConfig(), time.UTC)
+	token := transaction.SignShareToken(nil, uuid.New(), time.Now().Add(time.Hour))
+
+	_, err := svc.GetSharedReceipt(token)
+
+	assert.ErrorIs(t, err, transaction.ErrInvalidShareToken)
+}
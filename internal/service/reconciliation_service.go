@@ -0,0 +1,267 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/audit"
+	"github.com/darisadam/madabank-server/internal/domain/reconciliation"
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	maxReconciliationRunsListed        = 50
+	defaultReconciliationExceptionPage = 50
+)
+
+type ReconciliationService interface {
+	// ReconcileLedger compares every stored balance with the ledger now
+	ReconcileLedger(startedBy uuid.UUID) (*reconciliation.Run, error)
+	// RunScheduledLedger runs the ledger reconciliation once per business day and
+	// returns the number of breaks found
+	RunScheduledLedger(now time.Time) (int, error)
+	// ImportSettlementFile reconciles a counterparty settlement file for a business date
+	ImportSettlementFile(startedBy uuid.UUID, source reconciliation.Source, businessDate, fileName string, file io.Reader) (*reconciliation.Run, error)
+	ListRuns() ([]*reconciliation.Run, error)
+
+	ListExceptions(req *reconciliation.ListExceptionsRequest) ([]*reconciliation.Exception, error)
+	GetException(id uuid.UUID) (*reconciliation.Exception, error)
+	UpdateException(userID uuid.UUID, id uuid.UUID, req *reconciliation.UpdateExceptionRequest) (*reconciliation.Exception, error)
+}
+
+type reconciliationService struct {
+	reconRepo repository.ReconciliationRepository
+	adminRepo repository.AdminRepository
+	auditRepo repository.AuditRepository
+	zone      *time.Location // business days start at midnight in this zone
+}
+
+func NewReconciliationService(
+	reconRepo repository.ReconciliationRepository,
+	adminRepo repository.AdminRepository,
+	auditRepo repository.AuditRepository,
+	zone *time.Location,
+) ReconciliationService {
+	return &reconciliationService{
+		reconRepo: reconRepo,
+		adminRepo: adminRepo,
+		auditRepo: auditRepo,
+		zone:      zone,
+	}
+}
+
+func (s *reconciliationService) ReconcileLedger(startedBy uuid.UUID) (*reconciliation.Run, error) {
+	run, err := s.reconcileLedger(&startedBy, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit(startedBy, "RECONCILIATION_LEDGER_RUN", fmt.Sprintf("reconciliation_run:%s", run.ID), map[string]interface{}{
+		"breaks_found": run.BreaksFound,
+	})
+
+	return run, nil
+}
+
+func (s *reconciliationService) RunScheduledLedger(now time.Time) (int, error) {
+	businessDate := s.businessDate(now)
+	done, err := s.reconRepo.HasScheduledRun(reconciliation.RunKindLedger, businessDate)
+	if err != nil {
+		return 0, err
+	}
+	if done {
+		return 0, nil
+	}
+
+	run, err := s.reconcileLedger(nil, now)
+	if err != nil {
+		return 0, err
+	}
+
+	return run.BreaksFound, nil
+}
+
+func (s *reconciliationService) reconcileLedger(startedBy *uuid.UUID, now time.Time) (*reconciliation.Run, error) {
+	balances, err := s.adminRepo.LedgerBalances()
+	if err != nil {
+		return nil, err
+	}
+
+	exceptions := reconciliation.CompareLedger(balances)
+	run := &reconciliation.Run{
+		ID:           uuid.New(),
+		Kind:         reconciliation.RunKindLedger,
+		Source:       reconciliation.SourceLedger,
+		BusinessDate: s.businessDate(now),
+		ItemsChecked: len(balances),
+		BreaksFound:  len(exceptions),
+		StartedBy:    startedBy,
+	}
+
+	return s.saveRun(run, exceptions)
+}
+
+func (s *reconciliationService) ImportSettlementFile(startedBy uuid.UUID, source reconciliation.Source, businessDate, fileName string, file io.Reader) (*reconciliation.Run, error) {
+	if !reconciliation.IsValidFileSource(source) {
+		return nil, fmt.Errorf("invalid settlement source")
+	}
+
+	dayStart, err := time.ParseInLocation(reconciliation.BusinessDateLayout, businessDate, s.zone)
+	if err != nil {
+		return nil, fmt.Errorf("business_date must be formatted as YYYY-MM-DD")
+	}
+	if !dayStart.Before(s.businessDate(time.Now())) {
+		return nil, fmt.Errorf("business_date must be a past day")
+	}
+
+	records, err := reconciliation.ParseSettlementFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	dayEntries, err := s.reconRepo.ListSettlementEntries(source, dayStart, dayStart.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	// Records settled on a different day than we booked them are matched too
+	known := make(map[string]bool, len(dayEntries))
+	for _, e := range dayEntries {
+		known[e.Reference] = true
+	}
+	var unmatched []string
+	for _, rec := range records {
+		if !known[rec.Reference] {
+			unmatched = append(unmatched, rec.Reference)
+		}
+	}
+	otherEntries, err := s.reconRepo.GetSettlementEntries(source, unmatched)
+	if err != nil {
+		return nil, err
+	}
+
+	exceptions := reconciliation.CompareSettlement(source, records, dayEntries, otherEntries)
+	run := &reconciliation.Run{
+		ID:           uuid.New(),
+		Kind:         reconciliation.RunKindSettlementFile,
+		Source:       source,
+		BusinessDate: dayStart,
+		FileName:     strings.TrimSpace(fileName),
+		ItemsChecked: len(records),
+		BreaksFound:  len(exceptions),
+		StartedBy:    &startedBy,
+	}
+
+	if _, err := s.saveRun(run, exceptions); err != nil {
+		return nil, err
+	}
+
+	s.audit(startedBy, "RECONCILIATION_FILE_IMPORTED", fmt.Sprintf("reconciliation_run:%s", run.ID), map[string]interface{}{
+		"source":        source,
+		"business_date": businessDate,
+		"file_name":     run.FileName,
+		"records":       len(records),
+		"breaks_found":  run.BreaksFound,
+	})
+
+	return run, nil
+}
+
+func (s *reconciliationService) saveRun(run *reconciliation.Run, exceptions []*reconciliation.Exception) (*reconciliation.Run, error) {
+	if err := s.reconRepo.SaveRun(run, exceptions); err != nil {
+		return nil, err
+	}
+	run.Exceptions = exceptions
+
+	if run.BreaksFound > 0 {
+		logger.Warn("Reconciliation found breaks",
+			zap.String("run_id", run.ID.String()),
+			zap.String("kind", string(run.Kind)),
+			zap.String("source", string(run.Source)),
+			zap.Int("breaks", run.BreaksFound),
+		)
+	}
+
+	return run, nil
+}
+
+func (s *reconciliationService) ListRuns() ([]*reconciliation.Run, error) {
+	return s.reconRepo.ListRuns(maxReconciliationRunsListed)
+}
+
+func (s *reconciliationService) ListExceptions(req *reconciliation.ListExceptionsRequest) ([]*reconciliation.Exception, error) {
+	status := reconciliation.ExceptionStatus(req.Status)
+	if status == "" {
+		status = reconciliation.ExceptionStatusOpen
+	}
+	if !reconciliation.IsValidExceptionStatus(status) {
+		return nil, fmt.Errorf("invalid exception status")
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultReconciliationExceptionPage
+	}
+
+	return s.reconRepo.ListExceptions(status, limit)
+}
+
+func (s *reconciliationService) GetException(id uuid.UUID) (*reconciliation.Exception, error) {
+	return s.reconRepo.GetException(id)
+}
+
+// UpdateException moves an exception through the resolution workflow. Taking
+// it under investigation assigns it to the caller; resolving or dismissing it
+// requires a note explaining why.
+func (s *reconciliationService) UpdateException(userID uuid.UUID, id uuid.UUID, req *reconciliation.UpdateExceptionRequest) (*reconciliation.Exception, error) {
+	e, err := s.reconRepo.GetException(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !reconciliation.CanTransition(e.Status, req.Status) {
+		return nil, fmt.Errorf("cannot move exception from %s to %s", e.Status, req.Status)
+	}
+	note := strings.TrimSpace(req.Note)
+	if req.Status.IsClosed() && note == "" {
+		return nil, fmt.Errorf("a note is required to close an exception")
+	}
+
+	if err := s.reconRepo.UpdateExceptionStatus(id, e.Status, req.Status, userID, note); err != nil {
+		return nil, err
+	}
+
+	s.audit(userID, "RECONCILIATION_EXCEPTION_UPDATED", fmt.Sprintf("reconciliation_exception:%s", id), map[string]interface{}{
+		"from": e.Status,
+		"to":   req.Status,
+		"note": note,
+	})
+
+	return s.reconRepo.GetException(id)
+}
+
+// businessDate is the start of the current day in the reconciliation time zone
+func (s *reconciliationService) businessDate(now time.Time) time.Time {
+	local := now.In(s.zone)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, s.zone)
+}
+
+func (s *reconciliationService) audit(userID uuid.UUID, action, resource string, metadata map[string]interface{}) {
+	if err := s.auditRepo.Create(&audit.AuditLog{
+		EventID:  uuid.New(),
+		UserID:   &userID,
+		Action:   action,
+		Resource: resource,
+		Status:   "success",
+		Metadata: metadata,
+	}); err != nil {
+		logger.Error("Failed to create audit log for reconciliation", zap.String("action", action), zap.Error(err))
+		errtrack.CaptureError(err, map[string]string{"component": "reconciliation_service", "operation": "audit_log"})
+	}
+}
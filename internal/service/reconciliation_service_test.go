@@ -0,0 +1,286 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/card"
+	"github.com/darisadam/madabank-server/internal/domain/reconciliation"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockReconciliationRepository is a mock implementation of repository.ReconciliationRepository
+type MockReconciliationRepository struct {
+	mock.Mock
+}
+
+func (m *MockReconciliationRepository) SaveRun(run *reconciliation.Run, exceptions []*reconciliation.Exception) error {
+	args := m.Called(run, exceptions)
+	return args.Error(0)
+}
+
+func (m *MockReconciliationRepository) HasScheduledRun(kind reconciliation.RunKind, businessDate time.Time) (bool, error) {
+	args := m.Called(kind, businessDate)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockReconciliationRepository) ListRuns(limit int) ([]*reconciliation.Run, error) {
+	args := m.Called(limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*reconciliation.Run), args.Error(1)
+}
+
+func (m *MockReconciliationRepository) ListExceptions(status reconciliation.ExceptionStatus, limit int) ([]*reconciliation.Exception, error) {
+	args := m.Called(status, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*reconciliation.Exception), args.Error(1)
+}
+
+func (m *MockReconciliationRepository) GetException(id uuid.UUID) (*reconciliation.Exception, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*reconciliation.Exception), args.Error(1)
+}
+
+func (m *MockReconciliationRepository) UpdateExceptionStatus(id uuid.UUID, from, to reconciliation.ExceptionStatus, userID uuid.UUID, note string) error {
+	args := m.Called(id, from, to, userID, note)
+	return args.Error(0)
+}
+
+func (m *MockReconciliationRepository) ListSettlementEntries(source reconciliation.Source, from, to time.Time) ([]*reconciliation.LedgerEntry, error) {
+	args := m.Called(source, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*reconciliation.LedgerEntry), args.Error(1)
+}
+
+func (m *MockReconciliationRepository) GetSettlementEntries(source reconciliation.Source, references []string) ([]*reconciliation.LedgerEntry, error) {
+	args := m.Called(source, references)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*reconciliation.LedgerEntry), args.Error(1)
+}
+
+// MockAdminRepository is a mock implementation of repository.AdminRepository
+type MockAdminRepository struct {
+	mock.Mock
+}
+
+func (m *MockAdminRepository) LedgerBalances() ([]*account.LedgerBalance, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*account.LedgerBalance), args.Error(1)
+}
+
+func (m *MockAdminRepository) ListCardsAfter(afterID uuid.UUID, limit int) ([]*card.Card, error) {
+	args := m.Called(afterID, limit)
+	return args.Get(0).([]*card.Card), args.Error(1)
+}
+
+func (m *MockAdminRepository) ListCardTokensAfter(afterID uuid.UUID, limit int) ([]*card.Token, error) {
+	args := m.Called(afterID, limit)
+	return args.Get(0).([]*card.Token), args.Error(1)
+}
+
+func (m *MockAdminRepository) UpdateCardTokenNumber(id uuid.UUID, numberEncrypted, numberHash string) error {
+	args := m.Called(id, numberEncrypted, numberHash)
+	return args.Error(0)
+}
+
+func (m *MockAdminRepository) CountUsers() (int, int, error) {
+	args := m.Called()
+	return args.Int(0), args.Int(1), args.Error(2)
+}
+
+func (m *MockAdminRepository) CountActiveAccountsByType() (map[account.AccountType]int, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[account.AccountType]int), args.Error(1)
+}
+
+func (m *MockAdminRepository) SumActiveBalances() ([]*account.BalanceTotal, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*account.BalanceTotal), args.Error(1)
+}
+
+func (m *MockAdminRepository) PendingTransactions() (int, *time.Time, error) {
+	args := m.Called()
+	if args.Get(1) == nil {
+		return args.Int(0), nil, args.Error(2)
+	}
+	return args.Int(0), args.Get(1).(*time.Time), args.Error(2)
+}
+
+func setupReconciliationServiceTest(t *testing.T) (*reconciliationService, *MockReconciliationRepository, *MockAdminRepository) {
+	logger.Init("test")
+	reconRepo := new(MockReconciliationRepository)
+	adminRepo := new(MockAdminRepository)
+	auditRepo := new(MockAuditRepository)
+	auditRepo.On("Create", mock.Anything).Return(nil)
+
+	svc := NewReconciliationService(reconRepo, adminRepo, auditRepo, testSettlementZone).(*reconciliationService)
+	return svc, reconRepo, adminRepo
+}
+
+func TestRunScheduledLedger_RecordsBreaks(t *testing.T) {
+	svc, reconRepo, adminRepo := setupReconciliationServiceTest(t)
+	// 18:30 UTC is already the next day in Jakarta
+	now := time.Date(2026, 3, 1, 18, 30, 0, 0, time.UTC)
+	businessDate := time.Date(2026, 3, 2, 0, 0, 0, 0, testSettlementZone)
+
+	reconRepo.On("HasScheduledRun", reconciliation.RunKindLedger, businessDate).Return(false, nil)
+	adminRepo.On("LedgerBalances").Return([]*account.LedgerBalance{
+		{AccountID: uuid.New(), AccountNumber: "1001", StoredBalance: 100, LedgerBalance: 100},
+		{AccountID: uuid.New(), AccountNumber: "1002", StoredBalance: 150, LedgerBalance: 100},
+	}, nil)
+	reconRepo.On("SaveRun", mock.MatchedBy(func(run *reconciliation.Run) bool {
+		return run.Kind == reconciliation.RunKindLedger && run.StartedBy == nil &&
+			run.ItemsChecked == 2 && run.BreaksFound == 1 && run.BusinessDate.Equal(businessDate)
+	}), mock.MatchedBy(func(e []*reconciliation.Exception) bool {
+		return len(e) == 1 && e[0].Reference == "1002"
+	})).Return(nil)
+
+	breaks, err := svc.RunScheduledLedger(now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, breaks)
+	reconRepo.AssertExpectations(t)
+}
+
+func TestRunScheduledLedger_AlreadyRanToday(t *testing.T) {
+	svc, reconRepo, adminRepo := setupReconciliationServiceTest(t)
+	reconRepo.On("HasScheduledRun", reconciliation.RunKindLedger, mock.Anything).Return(true, nil)
+
+	breaks, err := svc.RunScheduledLedger(time.Now())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, breaks)
+	adminRepo.AssertNotCalled(t, "LedgerBalances")
+}
+
+func TestImportSettlementFile(t *testing.T) {
+	svc, reconRepo, _ := setupReconciliationServiceTest(t)
+	adminID := uuid.New()
+	dayStart := time.Date(2026, 3, 1, 0, 0, 0, 0, testSettlementZone)
+
+	reconRepo.On("ListSettlementEntries", reconciliation.SourceBillerAggregator, dayStart, dayStart.AddDate(0, 0, 1)).
+		Return([]*reconciliation.LedgerEntry{
+			{Reference: "txn-1", Amount: 150000, Status: "completed", Settled: true},
+			{Reference: "txn-2", Amount: 50000, Status: "completed", Settled: true},
+		}, nil)
+	reconRepo.On("GetSettlementEntries", reconciliation.SourceBillerAggregator, []string{"txn-0"}).
+		Return([]*reconciliation.LedgerEntry{
+			{Reference: "txn-0", Amount: 20000, Status: "completed", Settled: true},
+		}, nil)
+	reconRepo.On("SaveRun", mock.MatchedBy(func(run *reconciliation.Run) bool {
+		return run.Kind == reconciliation.RunKindSettlementFile && *run.StartedBy == adminID &&
+			run.ItemsChecked == 2 && run.FileName == "biller-20260301.csv"
+	}), mock.Anything).Return(nil)
+
+	file := "reference,amount\ntxn-1,150000\ntxn-0,20000\n"
+	run, err := svc.ImportSettlementFile(adminID, reconciliation.SourceBillerAggregator, "2026-03-01", "biller-20260301.csv", strings.NewReader(file))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, run.BreaksFound)
+	assert.Equal(t, reconciliation.ExceptionMissingInFile, run.Exceptions[0].Type)
+	assert.Equal(t, "txn-2", run.Exceptions[0].Reference)
+}
+
+func TestImportSettlementFile_Invalid(t *testing.T) {
+	svc, reconRepo, _ := setupReconciliationServiceTest(t)
+	adminID := uuid.New()
+	today := time.Now().In(testSettlementZone).Format(reconciliation.BusinessDateLayout)
+
+	_, err := svc.ImportSettlementFile(adminID, "bank_statement", "2026-03-01", "f.csv", strings.NewReader(""))
+	assert.EqualError(t, err, "invalid settlement source")
+
+	_, err = svc.ImportSettlementFile(adminID, reconciliation.SourceTopupAggregator, "01/03/2026", "f.csv", strings.NewReader(""))
+	assert.EqualError(t, err, "business_date must be formatted as YYYY-MM-DD")
+
+	_, err = svc.ImportSettlementFile(adminID, reconciliation.SourceTopupAggregator, today, "f.csv", strings.NewReader(""))
+	assert.EqualError(t, err, "business_date must be a past day")
+
+	_, err = svc.ImportSettlementFile(adminID, reconciliation.SourceTopupAggregator, "2026-03-01", "f.csv", strings.NewReader("id,total\n"))
+	assert.Error(t, err)
+
+	reconRepo.AssertNotCalled(t, "SaveRun", mock.Anything, mock.Anything)
+}
+
+func TestUpdateException_Investigate(t *testing.T) {
+	svc, reconRepo, _ := setupReconciliationServiceTest(t)
+	adminID := uuid.New()
+	e := &reconciliation.Exception{ID: uuid.New(), Status: reconciliation.ExceptionStatusOpen}
+
+	reconRepo.On("GetException", e.ID).Return(e, nil)
+	reconRepo.On("UpdateExceptionStatus", e.ID, reconciliation.ExceptionStatusOpen, reconciliation.ExceptionStatusInvestigating, adminID, "").Return(nil)
+
+	_, err := svc.UpdateException(adminID, e.ID, &reconciliation.UpdateExceptionRequest{Status: reconciliation.ExceptionStatusInvestigating})
+
+	assert.NoError(t, err)
+	reconRepo.AssertExpectations(t)
+}
+
+func TestUpdateException_ResolveRequiresNote(t *testing.T) {
+	svc, reconRepo, _ := setupReconciliationServiceTest(t)
+	e := &reconciliation.Exception{ID: uuid.New(), Status: reconciliation.ExceptionStatusInvestigating}
+	reconRepo.On("GetException", e.ID).Return(e, nil)
+
+	_, err := svc.UpdateException(uuid.New(), e.ID, &reconciliation.UpdateExceptionRequest{Status: reconciliation.ExceptionStatusResolved, Note: "  "})
+
+	assert.EqualError(t, err, "a note is required to close an exception")
+	reconRepo.AssertNotCalled(t, "UpdateExceptionStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateException_AlreadyClosed(t *testing.T) {
+	svc, reconRepo, _ := setupReconciliationServiceTest(t)
+	e := &reconciliation.Exception{ID: uuid.New(), Status: reconciliation.ExceptionStatusDismissed}
+	reconRepo.On("GetException", e.ID).Return(e, nil)
+
+	_, err := svc.UpdateException(uuid.New(), e.ID, &reconciliation.UpdateExceptionRequest{Status: reconciliation.ExceptionStatusResolved, Note: "fixed"})
+
+	assert.EqualError(t, err, "cannot move exception from dismissed to resolved")
+}
+
+func TestListExceptions_DefaultsToOpen(t *testing.T) {
+	svc, reconRepo, _ := setupReconciliationServiceTest(t)
+	reconRepo.On("ListExceptions", reconciliation.ExceptionStatusOpen, defaultReconciliationExceptionPage).Return([]*reconciliation.Exception{}, nil)
+
+	_, err := svc.ListExceptions(&reconciliation.ListExceptionsRequest{})
+	assert.NoError(t, err)
+
+	_, err = svc.ListExceptions(&reconciliation.ListExceptionsRequest{Status: "closed"})
+	assert.EqualError(t, err, "invalid exception status")
+
+	reconRepo.AssertExpectations(t)
+}
+
+func TestReconcileLedger_RepositoryError(t *testing.T) {
+	svc, reconRepo, adminRepo := setupReconciliationServiceTest(t)
+	adminRepo.On("LedgerBalances").Return(nil, fmt.Errorf("db down"))
+
+	_, err := svc.ReconcileLedger(uuid.New())
+
+	assert.Error(t, err)
+	reconRepo.AssertNotCalled(t, "SaveRun", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/audit"
+	"github.com/darisadam/madabank-server/internal/domain/regulatory"
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const maxRegulatoryReportsListed = 50
+
+// RegulatoryReportConfig identifies the bank to the regulator and sets the
+// amounts at or above which transactions are reported
+type RegulatoryReportConfig struct {
+	EntityID   string
+	Thresholds regulatory.Thresholds
+}
+
+func DefaultRegulatoryReportConfig() RegulatoryReportConfig {
+	return RegulatoryReportConfig{
+		Thresholds: regulatory.DefaultThresholds(),
+	}
+}
+
+type RegulatoryReportService interface {
+	// FlagTransaction marks a transaction as suspicious for the next suspicious
+	// transaction report
+	FlagTransaction(userID, transactionID uuid.UUID, req *regulatory.FlagTransactionRequest) (*regulatory.Flag, error)
+	// GenerateReport compiles a report for a period of past days on request
+	GenerateReport(userID uuid.UUID, req *regulatory.GenerateReportRequest) (*regulatory.Report, error)
+	// RunScheduled generates each report type for the previous business day once
+	// and returns the number of reports generated
+	RunScheduled(now time.Time) (int, error)
+	ListReports() ([]*regulatory.Report, error)
+	GetReport(id uuid.UUID) (*regulatory.Report, error)
+}
+
+type regulatoryReportService struct {
+	regRepo   repository.RegulatoryRepository
+	txnRepo   repository.TransactionRepository
+	auditRepo repository.AuditRepository
+	config    RegulatoryReportConfig
+	zone      *time.Location // reporting days start at midnight in this zone
+}
+
+func NewRegulatoryReportService(
+	regRepo repository.RegulatoryRepository,
+	txnRepo repository.TransactionRepository,
+	auditRepo repository.AuditRepository,
+	config RegulatoryReportConfig,
+	zone *time.Location,
+) RegulatoryReportService {
+	return &regulatoryReportService{
+		regRepo:   regRepo,
+		txnRepo:   txnRepo,
+		auditRepo: auditRepo,
+		config:    config,
+		zone:      zone,
+	}
+}
+
+func (s *regulatoryReportService) FlagTransaction(userID, transactionID uuid.UUID, req *regulatory.FlagTransactionRequest) (*regulatory.Flag, error) {
+	if _, err := s.txnRepo.GetByID(transactionID); err != nil {
+		return nil, err
+	}
+
+	flag := &regulatory.Flag{
+		ID:            uuid.New(),
+		TransactionID: transactionID,
+		Source:        regulatory.FlagSourceManual,
+		Reason:        req.Reason,
+		FlaggedBy:     &userID,
+	}
+	if err := s.regRepo.CreateFlag(flag); err != nil {
+		return nil, err
+	}
+
+	s.audit(userID, "TRANSACTION_FLAGGED", fmt.Sprintf("transaction:%s", transactionID), map[string]interface{}{
+		"flag_id": flag.ID,
+		"reason":  req.Reason,
+	})
+
+	return flag, nil
+}
+
+func (s *regulatoryReportService) GenerateReport(userID uuid.UUID, req *regulatory.GenerateReportRequest) (*regulatory.Report, error) {
+	if !regulatory.IsValidReportType(req.Type) {
+		return nil, fmt.Errorf("invalid report type")
+	}
+
+	periodStart, err := time.ParseInLocation(regulatory.DateLayout, req.From, s.zone)
+	if err != nil {
+		return nil, fmt.Errorf("from must be formatted as YYYY-MM-DD")
+	}
+	periodEnd, err := time.ParseInLocation(regulatory.DateLayout, req.To, s.zone)
+	if err != nil {
+		return nil, fmt.Errorf("to must be formatted as YYYY-MM-DD")
+	}
+	if periodEnd.Before(periodStart) {
+		return nil, fmt.Errorf("to must not be before from")
+	}
+	if !periodEnd.Before(s.businessDate(time.Now())) {
+		return nil, fmt.Errorf("to must be a past day")
+	}
+	if periodEnd.After(periodStart.AddDate(0, 0, regulatory.MaxReportPeriodDays-1)) {
+		return nil, fmt.Errorf("report period cannot exceed %d days", regulatory.MaxReportPeriodDays)
+	}
+
+	report, err := s.generate(req.Type, periodStart, periodEnd, &userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit(userID, "REGULATORY_REPORT_GENERATED", fmt.Sprintf("regulatory_report:%s", report.ID), map[string]interface{}{
+		"type":       report.Type,
+		"from":       req.From,
+		"to":         req.To,
+		"item_count": report.ItemCount,
+	})
+
+	return report, nil
+}
+
+func (s *regulatoryReportService) RunScheduled(now time.Time) (int, error) {
+	day := s.businessDate(now).AddDate(0, 0, -1)
+
+	generated := 0
+	for _, reportType := range []regulatory.ReportType{
+		regulatory.ReportTypeLargeTransaction,
+		regulatory.ReportTypeSuspiciousTransaction,
+	} {
+		done, err := s.regRepo.HasScheduledReport(reportType, day)
+		if err != nil {
+			return generated, err
+		}
+		if done {
+			continue
+		}
+
+		if _, err := s.generate(reportType, day, day, nil); err != nil {
+			return generated, err
+		}
+		generated++
+	}
+
+	return generated, nil
+}
+
+// generate compiles, renders and stores a report for the whole days from
+// periodStart to periodEnd
+func (s *regulatoryReportService) generate(reportType regulatory.ReportType, periodStart, periodEnd time.Time, generatedBy *uuid.UUID) (*regulatory.Report, error) {
+	from, to := periodStart, periodEnd.AddDate(0, 0, 1)
+
+	var items []*regulatory.Item
+	var err error
+	switch reportType {
+	case regulatory.ReportTypeLargeTransaction:
+		items, err = s.regRepo.ListLargeTransactions(from, to, s.config.Thresholds.Min())
+		items = regulatory.SelectLarge(items, s.config.Thresholds)
+	case regulatory.ReportTypeSuspiciousTransaction:
+		items, err = s.regRepo.ListFlaggedTransactions(from, to)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report := &regulatory.Report{
+		ID:          uuid.New(),
+		Type:        reportType,
+		PeriodStart: periodStart,
+		PeriodEnd:   periodEnd,
+		ItemCount:   len(items),
+		FileName:    regulatory.FileName(reportType, periodStart, periodEnd),
+		GeneratedBy: generatedBy,
+	}
+	for _, item := range items {
+		report.TotalAmount += item.Amount
+	}
+	report.TotalAmount = math.Round(report.TotalAmount*100) / 100
+
+	report.Content, err = report.Render(s.config.EntityID, DefaultCurrency, items, time.Now().In(s.zone))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.regRepo.SaveReport(report); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func (s *regulatoryReportService) ListReports() ([]*regulatory.Report, error) {
+	return s.regRepo.ListReports(maxRegulatoryReportsListed)
+}
+
+func (s *regulatoryReportService) GetReport(id uuid.UUID) (*regulatory.Report, error) {
+	return s.regRepo.GetReport(id)
+}
+
+// businessDate is the start of the current day in the reporting time zone
+func (s *regulatoryReportService) businessDate(now time.Time) time.Time {
+	local := now.In(s.zone)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, s.zone)
+}
+
+func (s *regulatoryReportService) audit(userID uuid.UUID, action, resource string, metadata map[string]interface{}) {
+	if err := s.auditRepo.Create(&audit.AuditLog{
+		EventID:  uuid.New(),
+		UserID:   &userID,
+		Action:   action,
+		Resource: resource,
+		Status:   "success",
+		Metadata: metadata,
+	}); err != nil {
+		logger.Error("Failed to create audit log for regulatory reporting", zap.String("action", action), zap.Error(err))
+		errtrack.CaptureError(err, map[string]string{"component": "regulatory_report_service", "operation": "audit_log"})
+	}
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/regulatory"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockRegulatoryRepository is a mock implementation of repository.RegulatoryRepository
+type MockRegulatoryRepository struct {
+	mock.Mock
+}
+
+func (m *MockRegulatoryRepository) CreateFlag(flag *regulatory.Flag) error {
+	args := m.Called(flag)
+	return args.Error(0)
+}
+
+func (m *MockRegulatoryRepository) ListLargeTransactions(from, to time.Time, minAmount float64) ([]*regulatory.Item, error) {
+	args := m.Called(from, to, minAmount)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*regulatory.Item), args.Error(1)
+}
+
+func (m *MockRegulatoryRepository) ListFlaggedTransactions(from, to time.Time) ([]*regulatory.Item, error) {
+	args := m.Called(from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*regulatory.Item), args.Error(1)
+}
+
+func (m *MockRegulatoryRepository) SaveReport(report *regulatory.Report) error {
+	args := m.Called(report)
+	return args.Error(0)
+}
+
+func (m *MockRegulatoryRepository) HasScheduledReport(reportType regulatory.ReportType, day time.Time) (bool, error) {
+	args := m.Called(reportType, day)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRegulatoryRepository) ListReports(limit int) ([]*regulatory.Report, error) {
+	args := m.Called(limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*regulatory.Report), args.Error(1)
+}
+
+func (m *MockRegulatoryRepository) GetReport(id uuid.UUID) (*regulatory.Report, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*regulatory.Report), args.Error(1)
+}
+
+func setupRegulatoryReportServiceTest(t *testing.T) (RegulatoryReportService, *MockRegulatoryRepository, *MockTransactionRepository) {
+	logger.Init("test")
+	regRepo := new(MockRegulatoryRepository)
+	txnRepo := new(MockTransactionRepository)
+	auditRepo := new(MockAuditRepository)
+	auditRepo.On("Create", mock.Anything).Return(nil)
+
+	config := RegulatoryReportConfig{
+		EntityID: "MADABANK",
+		Thresholds: regulatory.Thresholds{
+			Default: 500000000,
+			PerType: map[transaction.TransactionType]float64{transaction.TransactionTypeTransfer: 100000000},
+		},
+	}
+	return NewRegulatoryReportService(regRepo, txnRepo, auditRepo, config, testSettlementZone), regRepo, txnRepo
+}
+
+func TestFlagTransaction(t *testing.T) {
+	svc, regRepo, txnRepo := setupRegulatoryReportServiceTest(t)
+	officerID := uuid.New()
+	txnID := uuid.New()
+
+	txnRepo.On("GetByID", txnID).Return(&transaction.Transaction{ID: txnID}, nil)
+	regRepo.On("CreateFlag", mock.MatchedBy(func(f *regulatory.Flag) bool {
+		return f.TransactionID == txnID && f.Source == regulatory.FlagSourceManual &&
+			*f.FlaggedBy == officerID && f.Reason == "structuring below threshold"
+	})).Return(nil)
+
+	flag, err := svc.FlagTransaction(officerID, txnID, &regulatory.FlagTransactionRequest{Reason: "structuring below threshold"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, txnID, flag.TransactionID)
+	regRepo.AssertExpectations(t)
+}
+
+func TestFlagTransaction_NotFound(t *testing.T) {
+	svc, regRepo, txnRepo := setupRegulatoryReportServiceTest(t)
+	txnRepo.On("GetByID", mock.Anything).Return(nil, fmt.Errorf("transaction not found"))
+
+	_, err := svc.FlagTransaction(uuid.New(), uuid.New(), &regulatory.FlagTransactionRequest{Reason: "x"})
+
+	assert.EqualError(t, err, "transaction not found")
+	regRepo.AssertNotCalled(t, "CreateFlag", mock.Anything)
+}
+
+func TestGenerateReport_LargeTransactionsUseThresholds(t *testing.T) {
+	svc, regRepo, _ := setupRegulatoryReportServiceTest(t)
+	officerID := uuid.New()
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, testSettlementZone)
+
+	regRepo.On("ListLargeTransactions", from, from.AddDate(0, 0, 7), 100000000.0).Return([]*regulatory.Item{
+		{TransactionID: uuid.New(), TransactionType: transaction.TransactionTypeTransfer, Amount: 120000000},
+		{TransactionID: uuid.New(), TransactionType: transaction.TransactionTypeDeposit, Amount: 120000000},
+		{TransactionID: uuid.New(), TransactionType: transaction.TransactionTypeDeposit, Amount: 600000000},
+	}, nil)
+	regRepo.On("SaveReport", mock.AnythingOfType("*regulatory.Report")).Return(nil)
+
+	report, err := svc.GenerateReport(officerID, &regulatory.GenerateReportRequest{
+		Type: regulatory.ReportTypeLargeTransaction, From: "2026-03-01", To: "2026-03-07",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.ItemCount)
+	assert.Equal(t, 720000000.0, report.TotalAmount)
+	assert.Equal(t, "LTKT-20260301-20260307.xml", report.FileName)
+	assert.Equal(t, officerID, *report.GeneratedBy)
+	assert.Equal(t, 2, strings.Count(string(report.Content), "<transaction>"))
+	assert.Contains(t, string(report.Content), "<rentity_id>MADABANK</rentity_id>")
+}
+
+func TestGenerateReport_InvalidPeriod(t *testing.T) {
+	svc, regRepo, _ := setupRegulatoryReportServiceTest(t)
+	today := time.Now().In(testSettlementZone).Format(regulatory.DateLayout)
+
+	tests := []struct {
+		name     string
+		from, to string
+		errMsg   string
+	}{
+		{"bad date", "2026/03/01", "2026-03-02", "from must be formatted as YYYY-MM-DD"},
+		{"reversed", "2026-03-05", "2026-03-01", "to must not be before from"},
+		{"includes today", "2026-03-01", today, "to must be a past day"},
+		{"too long", "2026-01-01", "2026-02-01", "report period cannot exceed 31 days"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.GenerateReport(uuid.New(), &regulatory.GenerateReportRequest{
+				Type: regulatory.ReportTypeSuspiciousTransaction, From: tt.from, To: tt.to,
+			})
+			assert.EqualError(t, err, tt.errMsg)
+		})
+	}
+	regRepo.AssertNotCalled(t, "SaveReport", mock.Anything)
+}
+
+func TestRunScheduled_GeneratesMissingReportsForPreviousDay(t *testing.T) {
+	svc, regRepo, _ := setupRegulatoryReportServiceTest(t)
+	// 18:30 UTC on 1 March is already 2 March in Jakarta, so 1 March is reported
+	now := time.Date(2026, 3, 1, 18, 30, 0, 0, time.UTC)
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, testSettlementZone)
+
+	regRepo.On("HasScheduledReport", regulatory.ReportTypeLargeTransaction, day).Return(true, nil)
+	regRepo.On("HasScheduledReport", regulatory.ReportTypeSuspiciousTransaction, day).Return(false, nil)
+	regRepo.On("ListFlaggedTransactions", day, day.AddDate(0, 0, 1)).Return([]*regulatory.Item{
+		{TransactionID: uuid.New(), TransactionType: transaction.TransactionTypeWithdrawal, Amount: 45000000,
+			Reasons: []string{"manual: structuring"}},
+	}, nil)
+	regRepo.On("SaveReport", mock.MatchedBy(func(r *regulatory.Report) bool {
+		return r.Type == regulatory.ReportTypeSuspiciousTransaction && r.GeneratedBy == nil &&
+			r.ItemCount == 1 && r.PeriodStart.Equal(day) && r.PeriodEnd.Equal(day)
+	})).Return(nil)
+
+	generated, err := svc.RunScheduled(now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, generated)
+	regRepo.AssertNotCalled(t, "ListLargeTransactions", mock.Anything, mock.Anything, mock.Anything)
+	regRepo.AssertExpectations(t)
+}
+
+func TestRunScheduled_RepositoryError(t *testing.T) {
+	svc, regRepo, _ := setupRegulatoryReportServiceTest(t)
+	regRepo.On("HasScheduledReport", regulatory.ReportTypeLargeTransaction, mock.Anything).Return(false, nil)
+	regRepo.On("ListLargeTransactions", mock.Anything, mock.Anything, mock.Anything).Return(nil, fmt.Errorf("db down"))
+
+	generated, err := svc.RunScheduled(time.Now())
+
+	assert.Error(t, err)
+	assert.Equal(t, 0, generated)
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/report"
+	"github.com/darisadam/madabank-server/internal/repository"
+)
+
+type ReportService interface {
+	// DailySummary summarizes the business day date (YYYY-MM-DD); today's
+	// summary covers the day so far
+	DailySummary(date string) (*report.DailySummary, error)
+}
+
+type reportService struct {
+	reportRepo repository.ReportRepository
+	zone       *time.Location // business days start at midnight in this zone
+}
+
+func NewReportService(reportRepo repository.ReportRepository, zone *time.Location) ReportService {
+	return &reportService{
+		reportRepo: reportRepo,
+		zone:       zone,
+	}
+}
+
+func (s *reportService) DailySummary(date string) (*report.DailySummary, error) {
+	dayStart, err := time.ParseInLocation(report.DateLayout, date, s.zone)
+	if err != nil {
+		return nil, fmt.Errorf("date must be formatted as YYYY-MM-DD")
+	}
+	now := time.Now()
+	if dayStart.After(now) {
+		return nil, fmt.Errorf("date cannot be in the future")
+	}
+	dayEnd := dayStart.AddDate(0, 0, 1)
+
+	totals, err := s.reportRepo.DailyTotals(dayStart, dayEnd, DefaultCurrency)
+	if err != nil {
+		return nil, err
+	}
+	failed, err := s.reportRepo.FailedCounts(dayStart, dayEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	return report.BuildDailySummary(dayStart, totals, failed, now), nil
+}
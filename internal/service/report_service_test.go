@@ -0,0 +1,86 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/report"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockReportRepository is a mock implementation of repository.ReportRepository
+type MockReportRepository struct {
+	mock.Mock
+}
+
+func (m *MockReportRepository) DailyTotals(from, to time.Time, defaultCurrency string) ([]*report.TypeTotal, error) {
+	args := m.Called(from, to, defaultCurrency)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*report.TypeTotal), args.Error(1)
+}
+
+func (m *MockReportRepository) FailedCounts(from, to time.Time) ([]*report.FailedCount, error) {
+	args := m.Called(from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*report.FailedCount), args.Error(1)
+}
+
+func TestDailySummary_UsesBusinessDay(t *testing.T) {
+	reportRepo := new(MockReportRepository)
+	svc := NewReportService(reportRepo, testSettlementZone)
+	dayStart := time.Date(2026, 3, 1, 0, 0, 0, 0, testSettlementZone)
+	dayEnd := dayStart.AddDate(0, 0, 1)
+
+	reportRepo.On("DailyTotals", dayStart, dayEnd, DefaultCurrency).Return([]*report.TypeTotal{
+		{TransactionType: transaction.TransactionTypeFee, Currency: DefaultCurrency, Count: 2, Amount: 13000, Fees: 13000},
+		{TransactionType: transaction.TransactionTypeInterest, Currency: DefaultCurrency, Count: 5, Amount: 4200},
+	}, nil)
+	reportRepo.On("FailedCounts", dayStart, dayEnd).Return([]*report.FailedCount{
+		{TransactionType: transaction.TransactionTypeTransfer, Count: 3},
+	}, nil)
+
+	summary, err := svc.DailySummary("2026-03-01")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "2026-03-01", summary.Date)
+	assert.Equal(t, 13000.0, summary.FeeRevenue[DefaultCurrency])
+	assert.Equal(t, 4200.0, summary.InterestPaid[DefaultCurrency])
+	assert.Equal(t, 3, summary.FailedTotal)
+}
+
+func TestDailySummary_InvalidDate(t *testing.T) {
+	reportRepo := new(MockReportRepository)
+	svc := NewReportService(reportRepo, testSettlementZone)
+
+	_, err := svc.DailySummary("01/03/2026")
+
+	assert.EqualError(t, err, "date must be formatted as YYYY-MM-DD")
+}
+
+func TestDailySummary_FutureDate(t *testing.T) {
+	reportRepo := new(MockReportRepository)
+	svc := NewReportService(reportRepo, testSettlementZone)
+
+	_, err := svc.DailySummary(time.Now().AddDate(0, 0, 2).Format(report.DateLayout))
+
+	assert.EqualError(t, err, "date cannot be in the future")
+}
+
+func TestDailySummary_RepositoryError(t *testing.T) {
+	reportRepo := new(MockReportRepository)
+	svc := NewReportService(reportRepo, testSettlementZone)
+	reportRepo.On("DailyTotals", mock.Anything, mock.Anything, mock.Anything).Return(nil, fmt.Errorf("db down"))
+
+	summary, err := svc.DailySummary("2026-03-01")
+
+	assert.Error(t, err)
+	assert.Nil(t, summary)
+	reportRepo.AssertNotCalled(t, "FailedCounts", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/audit"
+	"github.com/darisadam/madabank-server/internal/domain/roundup"
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type RoundUpService interface {
+	// Enable rounds every debit of the account up to the next 1,000 IDR and
+	// moves the difference to the savings account
+	Enable(userID uuid.UUID, accountID uuid.UUID, req *roundup.EnableRequest) (*roundup.Rule, error)
+	GetRule(userID uuid.UUID, accountID uuid.UUID) (*roundup.Rule, error)
+	Disable(userID uuid.UUID, accountID uuid.UUID) error
+}
+
+type roundUpService struct {
+	roundUpRepo repository.RoundUpRepository
+	accountRepo repository.AccountRepository
+	auditRepo   repository.AuditRepository
+}
+
+func NewRoundUpService(
+	roundUpRepo repository.RoundUpRepository,
+	accountRepo repository.AccountRepository,
+	auditRepo repository.AuditRepository,
+) RoundUpService {
+	return &roundUpService{
+		roundUpRepo: roundUpRepo,
+		accountRepo: accountRepo,
+		auditRepo:   auditRepo,
+	}
+}
+
+func (s *roundUpService) Enable(userID uuid.UUID, accountID uuid.UUID, req *roundup.EnableRequest) (*roundup.Rule, error) {
+	savingsAccountID, err := uuid.Parse(req.SavingsAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid savings account ID")
+	}
+	if savingsAccountID == accountID {
+		return nil, fmt.Errorf("round-ups cannot go to the account they come from")
+	}
+
+	acct, err := s.ownedAccount(userID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if acct.Status != account.AccountStatusActive {
+		return nil, fmt.Errorf("account is not active")
+	}
+	if acct.Currency != DefaultCurrency {
+		return nil, fmt.Errorf("round-ups are only available on %s accounts", DefaultCurrency)
+	}
+
+	savings, err := s.ownedAccount(userID, savingsAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if savings.AccountType != account.AccountTypeSavings {
+		return nil, fmt.Errorf("round-ups must go to a savings account")
+	}
+	if savings.Status != account.AccountStatusActive {
+		return nil, fmt.Errorf("savings account is not active")
+	}
+	if savings.Currency != acct.Currency {
+		return nil, fmt.Errorf("currency mismatch: account is %s, savings account is %s", acct.Currency, savings.Currency)
+	}
+
+	rule := &roundup.Rule{
+		ID:               uuid.New(),
+		UserID:           userID,
+		AccountID:        accountID,
+		SavingsAccountID: savingsAccountID,
+	}
+	if err := s.roundUpRepo.Save(rule); err != nil {
+		return nil, err
+	}
+
+	s.audit(userID, "ROUND_UP_ENABLED", accountID, map[string]interface{}{
+		"savings_account_id": savingsAccountID.String(),
+	})
+
+	return s.roundUpRepo.GetByAccountID(accountID)
+}
+
+func (s *roundUpService) GetRule(userID uuid.UUID, accountID uuid.UUID) (*roundup.Rule, error) {
+	if _, err := s.ownedAccount(userID, accountID); err != nil {
+		return nil, err
+	}
+	return s.roundUpRepo.GetByAccountID(accountID)
+}
+
+func (s *roundUpService) Disable(userID uuid.UUID, accountID uuid.UUID) error {
+	if _, err := s.ownedAccount(userID, accountID); err != nil {
+		return err
+	}
+	if err := s.roundUpRepo.Delete(accountID); err != nil {
+		return err
+	}
+
+	s.audit(userID, "ROUND_UP_DISABLED", accountID, nil)
+	return nil
+}
+
+func (s *roundUpService) ownedAccount(userID uuid.UUID, accountID uuid.UUID) (*account.Account, error) {
+	acct, err := s.accountRepo.GetByID(accountID)
+	if err != nil {
+		return nil, fmt.Errorf("account not found")
+	}
+	if acct.UserID != userID {
+		return nil, fmt.Errorf("unauthorized: account does not belong to user")
+	}
+	return acct, nil
+}
+
+func (s *roundUpService) audit(userID uuid.UUID, action string, accountID uuid.UUID, metadata map[string]interface{}) {
+	if err := s.auditRepo.Create(&audit.AuditLog{
+		EventID:  uuid.New(),
+		UserID:   &userID,
+		Action:   action,
+		Resource: fmt.Sprintf("account:%s", accountID),
+		Status:   "success",
+		Metadata: metadata,
+	}); err != nil {
+		logger.Error("Failed to create audit log for round-up", zap.String("action", action), zap.Error(err))
+		errtrack.CaptureError(err, map[string]string{"component": "round_up_service", "operation": "audit_log"})
+	}
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/roundup"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockRoundUpRepository is a mock implementation of repository.RoundUpRepository
+type MockRoundUpRepository struct {
+	mock.Mock
+}
+
+func (m *MockRoundUpRepository) Save(rule *roundup.Rule) error {
+	args := m.Called(rule)
+	return args.Error(0)
+}
+
+func (m *MockRoundUpRepository) GetByAccountID(accountID uuid.UUID) (*roundup.Rule, error) {
+	args := m.Called(accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*roundup.Rule), args.Error(1)
+}
+
+func (m *MockRoundUpRepository) Delete(accountID uuid.UUID) error {
+	args := m.Called(accountID)
+	return args.Error(0)
+}
+
+func (m *MockRoundUpRepository) AfterDebit(dbTx *sql.Tx, txn *transaction.Transaction) error {
+	return nil
+}
+
+type roundUpTest struct {
+	svc         RoundUpService
+	roundUpRepo *MockRoundUpRepository
+	accountRepo *MockAccountRepository
+}
+
+func setupRoundUpServiceTest() *roundUpTest {
+	rt := &roundUpTest{
+		roundUpRepo: new(MockRoundUpRepository),
+		accountRepo: new(MockAccountRepository),
+	}
+	auditRepo := new(MockAuditRepository)
+	auditRepo.On("Create", mock.Anything).Return(nil)
+	rt.svc = NewRoundUpService(rt.roundUpRepo, rt.accountRepo, auditRepo)
+	return rt
+}
+
+func (rt *roundUpTest) withAccount(userID uuid.UUID, accountType account.AccountType) *account.Account {
+	acct := &account.Account{ID: uuid.New(), UserID: userID, AccountType: accountType, Currency: "IDR", Status: account.AccountStatusActive}
+	rt.accountRepo.On("GetByID", acct.ID).Return(acct, nil)
+	return acct
+}
+
+func TestEnableRoundUp(t *testing.T) {
+	rt := setupRoundUpServiceTest()
+	userID := uuid.New()
+	checking := rt.withAccount(userID, account.AccountTypeChecking)
+	savings := rt.withAccount(userID, account.AccountTypeSavings)
+	rt.roundUpRepo.On("Save", mock.MatchedBy(func(r *roundup.Rule) bool {
+		return r.UserID == userID && r.AccountID == checking.ID && r.SavingsAccountID == savings.ID
+	})).Return(nil)
+	rt.roundUpRepo.On("GetByAccountID", checking.ID).Return(&roundup.Rule{AccountID: checking.ID, SavingsAccountID: savings.ID}, nil)
+
+	rule, err := rt.svc.Enable(userID, checking.ID, &roundup.EnableRequest{SavingsAccountID: savings.ID.String()})
+
+	assert.NoError(t, err)
+	assert.Equal(t, savings.ID, rule.SavingsAccountID)
+	rt.roundUpRepo.AssertExpectations(t)
+}
+
+func TestEnableRoundUp_Rejected(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name    string
+		setup   func(rt *roundUpTest) (uuid.UUID, uuid.UUID)
+		wantErr string
+	}{
+		{
+			name: "same account",
+			setup: func(rt *roundUpTest) (uuid.UUID, uuid.UUID) {
+				acct := rt.withAccount(userID, account.AccountTypeSavings)
+				return acct.ID, acct.ID
+			},
+			wantErr: "round-ups cannot go to the account they come from",
+		},
+		{
+			name: "savings account of another user",
+			setup: func(rt *roundUpTest) (uuid.UUID, uuid.UUID) {
+				return rt.withAccount(userID, account.AccountTypeChecking).ID, rt.withAccount(uuid.New(), account.AccountTypeSavings).ID
+			},
+			wantErr: "unauthorized: account does not belong to user",
+		},
+		{
+			name: "not a savings account",
+			setup: func(rt *roundUpTest) (uuid.UUID, uuid.UUID) {
+				return rt.withAccount(userID, account.AccountTypeChecking).ID, rt.withAccount(userID, account.AccountTypeChecking).ID
+			},
+			wantErr: "round-ups must go to a savings account",
+		},
+		{
+			name: "frozen savings account",
+			setup: func(rt *roundUpTest) (uuid.UUID, uuid.UUID) {
+				savings := rt.withAccount(userID, account.AccountTypeSavings)
+				savings.Status = account.AccountStatusFrozen
+				return rt.withAccount(userID, account.AccountTypeChecking).ID, savings.ID
+			},
+			wantErr: "savings account is not active",
+		},
+		{
+			name: "foreign currency account",
+			setup: func(rt *roundUpTest) (uuid.UUID, uuid.UUID) {
+				acct := rt.withAccount(userID, account.AccountTypeChecking)
+				acct.Currency = "USD"
+				return acct.ID, rt.withAccount(userID, account.AccountTypeSavings).ID
+			},
+			wantErr: "round-ups are only available on IDR accounts",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := setupRoundUpServiceTest()
+			accountID, savingsAccountID := tt.setup(rt)
+
+			_, err := rt.svc.Enable(userID, accountID, &roundup.EnableRequest{SavingsAccountID: savingsAccountID.String()})
+
+			assert.EqualError(t, err, tt.wantErr)
+			rt.roundUpRepo.AssertNotCalled(t, "Save", mock.Anything)
+		})
+	}
+}
+
+func TestDisableRoundUp(t *testing.T) {
+	rt := setupRoundUpServiceTest()
+	userID := uuid.New()
+	acct := rt.withAccount(userID, account.AccountTypeChecking)
+	rt.roundUpRepo.On("Delete", acct.ID).Return(fmt.Errorf("round-up rule not found"))
+
+	err := rt.svc.Disable(userID, acct.ID)
+
+	assert.EqualError(t, err, "round-up rule not found")
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/saga"
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/pkg/metrics"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// sagaLease is how long a runner holds a saga. A saga whose runner crashed
+	// is resumed once its lease lapses, so it must outlast the slowest step.
+	sagaLease       = 2 * time.Minute
+	sagaResumeBatch = 50
+)
+
+type SagaOrchestrator interface {
+	// Register adds a saga definition. Kinds must be registered before sagas
+	// of that kind are started or resumed.
+	Register(def saga.Definition)
+	// Start persists a new saga holding data and runs it until it finishes
+	// or a step has to wait for a retry. The returned error is the step error
+	// that stopped the run, if any; the saga is nil only if it could not be
+	// persisted.
+	Start(id uuid.UUID, kind string, data interface{}) (*saga.Saga, error)
+	// ResumeDue continues sagas whose retry is due or whose runner crashed and
+	// returns how many of them finished
+	ResumeDue(now time.Time) (int, error)
+}
+
+type sagaOrchestrator struct {
+	sagaRepo    repository.SagaRepository
+	mu          sync.RWMutex
+	definitions map[string]saga.Definition
+}
+
+func NewSagaOrchestrator(sagaRepo repository.SagaRepository) SagaOrchestrator {
+	return &sagaOrchestrator{
+		sagaRepo:    sagaRepo,
+		definitions: map[string]saga.Definition{},
+	}
+}
+
+func (o *sagaOrchestrator) Register(def saga.Definition) {
+	if def.MaxAttempts <= 0 {
+		def.MaxAttempts = saga.DefaultMaxAttempts
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.definitions[def.Kind] = def
+}
+
+func (o *sagaOrchestrator) definition(kind string) (saga.Definition, bool) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	def, ok := o.definitions[kind]
+	return def, ok
+}
+
+func (o *sagaOrchestrator) Start(id uuid.UUID, kind string, data interface{}) (*saga.Saga, error) {
+	def, ok := o.definition(kind)
+	if !ok {
+		return nil, fmt.Errorf("unknown saga kind %s", kind)
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode saga data: %w", err)
+	}
+
+	now := time.Now()
+	lockedUntil := now.Add(sagaLease)
+	s := &saga.Saga{
+		ID:            id,
+		Kind:          kind,
+		Status:        saga.StatusRunning,
+		Data:          raw,
+		NextAttemptAt: now,
+		LockedUntil:   &lockedUntil,
+	}
+	if err := o.sagaRepo.Create(s); err != nil {
+		return nil, err
+	}
+
+	return s, o.run(def, s)
+}
+
+func (o *sagaOrchestrator) ResumeDue(now time.Time) (int, error) {
+	sagas, err := o.sagaRepo.ClaimDue(now, now.Add(sagaLease), sagaResumeBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	finished := 0
+	for _, s := range sagas {
+		def, ok := o.definition(s.Kind)
+		if !ok {
+			// Left locked until the lease lapses, for a replica that knows the kind
+			logger.Warn("Cannot resume saga of unknown kind", zap.String("saga_id", s.ID.String()), zap.String("kind", s.Kind))
+			continue
+		}
+
+		_ = o.run(def, s) // step errors are recorded on the saga
+		if s.Finished() {
+			finished++
+		}
+	}
+
+	return finished, nil
+}
+
+// run advances the saga step by step, saving after each one, until it
+// finishes or a step fails with a retryable error
+func (o *sagaOrchestrator) run(def saga.Definition, s *saga.Saga) error {
+	ctx := context.Background()
+	var abortErr error
+
+	for {
+		switch {
+		case s.Status == saga.StatusRunning && s.Step >= len(def.Steps):
+			return o.finish(s, saga.StatusCompleted, nil)
+
+		case s.Status == saga.StatusRunning:
+			step := def.Steps[s.Step]
+			out, err := step.Action(ctx, s.Data)
+			if saga.IsAbort(err) {
+				// The failed step had no effect; undo the ones before it
+				logger.Warn("Saga aborted, compensating",
+					zap.String("saga_id", s.ID.String()),
+					zap.String("kind", s.Kind),
+					zap.String("step", step.Name),
+					zap.Error(err),
+				)
+				abortErr = err
+				s.Status = saga.StatusCompensating
+				s.Attempts = 0
+				s.LastError = err.Error()
+				if err := o.sagaRepo.Save(s); err != nil {
+					return err
+				}
+				continue
+			}
+			if err != nil {
+				return o.retry(def, s, step.Name, err)
+			}
+
+			if out != nil {
+				s.Data = out
+			}
+			s.Step++
+			s.Attempts = 0
+			if err := o.sagaRepo.Save(s); err != nil {
+				return err
+			}
+
+		case s.Status == saga.StatusCompensating && s.Step == 0:
+			return o.finish(s, saga.StatusCompensated, abortErr)
+
+		case s.Status == saga.StatusCompensating:
+			step := def.Steps[s.Step-1]
+			if step.Compensate != nil {
+				if err := step.Compensate(ctx, s.Data, s.LastError); err != nil {
+					retryErr := o.retry(def, s, step.Name, err)
+					if abortErr != nil {
+						return abortErr
+					}
+					return retryErr
+				}
+			}
+			s.Step--
+			if err := o.sagaRepo.Save(s); err != nil {
+				return err
+			}
+
+		default:
+			return nil
+		}
+	}
+}
+
+// retry records a failed step attempt and schedules the next one, or fails
+// the saga once the step's attempts are exhausted
+func (o *sagaOrchestrator) retry(def saga.Definition, s *saga.Saga, stepName string, stepErr error) error {
+	s.Attempts++
+	s.LockedUntil = nil
+	// While compensating, LastError keeps the cause that compensations are given
+	if s.Status == saga.StatusRunning {
+		s.LastError = stepErr.Error()
+	}
+
+	if s.Attempts >= def.MaxAttempts {
+		logger.Error("Saga step attempts exhausted, operator action required",
+			zap.String("saga_id", s.ID.String()),
+			zap.String("kind", s.Kind),
+			zap.String("step", stepName),
+			zap.Error(stepErr),
+		)
+		errtrack.CaptureError(stepErr, map[string]string{"component": "saga_orchestrator", "operation": s.Kind + "." + stepName})
+		if err := o.finish(s, saga.StatusFailed, nil); err != nil {
+			return err
+		}
+		return stepErr
+	}
+
+	s.NextAttemptAt = time.Now().Add(saga.RetryDelay(s.Attempts))
+	metrics.RecordSagaStepRetry(s.Kind, stepName)
+	logger.Warn("Saga step failed, will retry",
+		zap.String("saga_id", s.ID.String()),
+		zap.String("kind", s.Kind),
+		zap.String("step", stepName),
+		zap.Int("attempts", s.Attempts),
+		zap.Time("next_attempt_at", s.NextAttemptAt),
+		zap.Error(stepErr),
+	)
+	if err := o.sagaRepo.Save(s); err != nil {
+		return err
+	}
+	return stepErr
+}
+
+func (o *sagaOrchestrator) finish(s *saga.Saga, status saga.Status, result error) error {
+	now := time.Now()
+	s.Status = status
+	s.CompletedAt = &now
+	s.LockedUntil = nil
+	if err := o.sagaRepo.Save(s); err != nil {
+		return err
+	}
+
+	metrics.RecordSagaFinished(s.Kind, string(status))
+	return result
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/saga"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockSagaRepository is a mock implementation of repository.SagaRepository
+type MockSagaRepository struct {
+	mock.Mock
+}
+
+func (m *MockSagaRepository) Create(s *saga.Saga) error {
+	args := m.Called(s)
+	return args.Error(0)
+}
+
+func (m *MockSagaRepository) Save(s *saga.Saga) error {
+	args := m.Called(s)
+	return args.Error(0)
+}
+
+func (m *MockSagaRepository) ClaimDue(now, lockedUntil time.Time, limit int) ([]*saga.Saga, error) {
+	args := m.Called(now, lockedUntil, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*saga.Saga), args.Error(1)
+}
+
+func (m *MockSagaRepository) GetByID(id uuid.UUID) (*saga.Saga, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*saga.Saga), args.Error(1)
+}
+
+func newTestSagaOrchestrator() (SagaOrchestrator, *MockSagaRepository) {
+	logger.Init("test")
+	repo := new(MockSagaRepository)
+	repo.On("Create", mock.Anything).Return(nil)
+	repo.On("Save", mock.Anything).Return(nil)
+	return NewSagaOrchestrator(repo), repo
+}
+
+// recordingSaga builds a three step saga whose steps append to calls
+func recordingSaga(calls *[]string, failAt string, failure error) saga.Definition {
+	step := func(name string) saga.Step {
+		return saga.Step{
+			Name: name,
+			Action: func(_ context.Context, data json.RawMessage) (json.RawMessage, error) {
+				*calls = append(*calls, name)
+				if name == failAt {
+					return nil, failure
+				}
+				return nil, nil
+			},
+			Compensate: func(_ context.Context, _ json.RawMessage, cause string) error {
+				*calls = append(*calls, "undo_"+name+":"+cause)
+				return nil
+			},
+		}
+	}
+	return saga.Definition{Kind: "transfer_out", Steps: []saga.Step{step("debit"), step("send"), step("fee")}}
+}
+
+func TestSaga_Completes(t *testing.T) {
+	o, repo := newTestSagaOrchestrator()
+	var calls []string
+	o.Register(recordingSaga(&calls, "", nil))
+
+	s, err := o.Start(uuid.New(), "transfer_out", map[string]string{"ref": "abc"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, saga.StatusCompleted, s.Status)
+	assert.Equal(t, []string{"debit", "send", "fee"}, calls)
+	assert.JSONEq(t, `{"ref":"abc"}`, string(s.Data))
+	assert.Nil(t, s.LockedUntil)
+	repo.AssertNumberOfCalls(t, "Save", 4) // after each step, then on completion
+}
+
+func TestSaga_AbortCompensatesCompletedSteps(t *testing.T) {
+	o, _ := newTestSagaOrchestrator()
+	var calls []string
+	rejected := fmt.Errorf("rejected by rail")
+	o.Register(recordingSaga(&calls, "fee", saga.Abort(rejected)))
+
+	s, err := o.Start(uuid.New(), "transfer_out", nil)
+
+	assert.ErrorIs(t, err, rejected)
+	assert.Equal(t, saga.StatusCompensated, s.Status)
+	assert.Equal(t, []string{"debit", "send", "fee", "undo_send:rejected by rail", "undo_debit:rejected by rail"}, calls)
+}
+
+func TestSaga_RetryableErrorWaitsAndResumes(t *testing.T) {
+	o, repo := newTestSagaOrchestrator()
+	var calls []string
+	def := recordingSaga(&calls, "send", fmt.Errorf("rail timeout"))
+	o.Register(def)
+
+	s, err := o.Start(uuid.New(), "transfer_out", nil)
+
+	assert.EqualError(t, err, "rail timeout")
+	assert.Equal(t, saga.StatusRunning, s.Status)
+	assert.Equal(t, 1, s.Step)
+	assert.Equal(t, 1, s.Attempts)
+	assert.Equal(t, "rail timeout", s.LastError)
+	assert.True(t, s.NextAttemptAt.After(time.Now()))
+	assert.Nil(t, s.LockedUntil)
+
+	// The rail recovers; the resumed saga carries on from the failed step
+	calls = nil
+	o.Register(recordingSaga(&calls, "", nil))
+	now := time.Now().Add(time.Minute)
+	repo.On("ClaimDue", now, now.Add(sagaLease), sagaResumeBatch).Return([]*saga.Saga{s}, nil)
+
+	finished, err := o.ResumeDue(now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, finished)
+	assert.Equal(t, []string{"send", "fee"}, calls)
+	assert.Equal(t, saga.StatusCompleted, s.Status)
+}
+
+func TestSaga_FailsWhenAttemptsExhausted(t *testing.T) {
+	o, _ := newTestSagaOrchestrator()
+	var calls []string
+	def := recordingSaga(&calls, "debit", fmt.Errorf("database unavailable"))
+	def.MaxAttempts = 1
+	o.Register(def)
+
+	s, err := o.Start(uuid.New(), "transfer_out", nil)
+
+	assert.Error(t, err)
+	assert.Equal(t, saga.StatusFailed, s.Status)
+	assert.NotNil(t, s.CompletedAt)
+}
+
+func TestSaga_UnknownKind(t *testing.T) {
+	o, repo := newTestSagaOrchestrator()
+
+	s, err := o.Start(uuid.New(), "nope", nil)
+
+	assert.EqualError(t, err, "unknown saga kind nope")
+	assert.Nil(t, s)
+	repo.AssertNotCalled(t, "Create", mock.Anything)
+}
@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/audit"
+	"github.com/darisadam/madabank-server/internal/domain/statement"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/pkg/notifier"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const statementEmailTimeout = time.Minute
+
+type StatementService interface {
+	// Subscribe opts the account in to monthly statement emails
+	Subscribe(userID uuid.UUID, accountID uuid.UUID, req *statement.SubscribeRequest) (*statement.Subscription, error)
+	GetSubscription(userID uuid.UUID, accountID uuid.UUID) (*statement.Subscription, error)
+	Unsubscribe(userID uuid.UUID, accountID uuid.UUID) error
+	ListDeliveries(userID uuid.UUID, accountID uuid.UUID) ([]*statement.Delivery, error)
+	// Resend emails a past statement again, whatever its delivery status
+	Resend(userID uuid.UUID, accountID uuid.UUID, deliveryID uuid.UUID) (*statement.Delivery, error)
+	// SendDueStatements emails last month's statement to every subscribed
+	// account that has not received it, and returns how many were sent
+	SendDueStatements(now time.Time) (int, error)
+}
+
+type statementService struct {
+	statementRepo   repository.StatementRepository
+	accountRepo     repository.AccountRepository
+	transactionRepo repository.TransactionRepository
+	userRepo        repository.UserRepository
+	auditRepo       repository.AuditRepository
+	notifier        notifier.Notifier
+	bankName        string
+	zone            *time.Location
+}
+
+func NewStatementService(
+	statementRepo repository.StatementRepository,
+	accountRepo repository.AccountRepository,
+	transactionRepo repository.TransactionRepository,
+	userRepo repository.UserRepository,
+	auditRepo repository.AuditRepository,
+	notifier notifier.Notifier,
+	bankName string,
+	zone *time.Location,
+) StatementService {
+	return &statementService{
+		statementRepo:   statementRepo,
+		accountRepo:     accountRepo,
+		transactionRepo: transactionRepo,
+		userRepo:        userRepo,
+		auditRepo:       auditRepo,
+		notifier:        notifier,
+		bankName:        bankName,
+		zone:            zone,
+	}
+}
+
+func (s *statementService) Subscribe(userID uuid.UUID, accountID uuid.UUID, req *statement.SubscribeRequest) (*statement.Subscription, error) {
+	acct, err := s.ownedAccount(userID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if acct.Status == account.AccountStatusClosed {
+		return nil, fmt.Errorf("account is closed")
+	}
+
+	sub := &statement.Subscription{
+		ID:        uuid.New(),
+		UserID:    userID,
+		AccountID: accountID,
+		Email:     req.Email,
+	}
+	if err := s.statementRepo.SaveSubscription(sub); err != nil {
+		return nil, err
+	}
+
+	s.audit(userID, "STATEMENT_EMAIL_SUBSCRIBED", accountID, map[string]interface{}{
+		"custom_email": sub.Email != "",
+	})
+
+	return sub, nil
+}
+
+func (s *statementService) GetSubscription(userID uuid.UUID, accountID uuid.UUID) (*statement.Subscription, error) {
+	if _, err := s.ownedAccount(userID, accountID); err != nil {
+		return nil, err
+	}
+	return s.statementRepo.GetSubscription(accountID)
+}
+
+func (s *statementService) Unsubscribe(userID uuid.UUID, accountID uuid.UUID) error {
+	if _, err := s.ownedAccount(userID, accountID); err != nil {
+		return err
+	}
+	if err := s.statementRepo.DeleteSubscription(accountID); err != nil {
+		return err
+	}
+
+	s.audit(userID, "STATEMENT_EMAIL_UNSUBSCRIBED", accountID, nil)
+	return nil
+}
+
+func (s *statementService) ListDeliveries(userID uuid.UUID, accountID uuid.UUID) ([]*statement.Delivery, error) {
+	if _, err := s.ownedAccount(userID, accountID); err != nil {
+		return nil, err
+	}
+	return s.statementRepo.ListDeliveries(accountID)
+}
+
+func (s *statementService) Resend(userID uuid.UUID, accountID uuid.UUID, deliveryID uuid.UUID) (*statement.Delivery, error) {
+	if _, err := s.ownedAccount(userID, accountID); err != nil {
+		return nil, err
+	}
+
+	d, err := s.statementRepo.GetDelivery(deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if d.AccountID != accountID {
+		return nil, fmt.Errorf("statement delivery not found")
+	}
+
+	// Without a subscription the statement goes to the profile address
+	email := ""
+	if sub, err := s.statementRepo.GetSubscription(accountID); err == nil {
+		email = sub.Email
+	}
+
+	if err := s.send(d, email, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to send statement: %w", err)
+	}
+
+	s.audit(userID, "STATEMENT_EMAIL_RESENT", accountID, map[string]interface{}{
+		"delivery_id": d.ID.String(),
+		"period":      d.Period,
+	})
+
+	return d, nil
+}
+
+func (s *statementService) SendDueStatements(now time.Time) (int, error) {
+	period := statement.PreviousPeriod(now, s.zone)
+	subs, err := s.statementRepo.ListDueSubscriptions(period, statement.MaxDeliveryAttempts)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, sub := range subs {
+		d := &statement.Delivery{
+			ID:        uuid.New(),
+			UserID:    sub.UserID,
+			AccountID: sub.AccountID,
+			Period:    period,
+		}
+		if err := s.statementRepo.EnsureDelivery(d); err != nil {
+			logger.Error("Failed to create statement delivery", zap.String("account_id", sub.AccountID.String()), zap.Error(err))
+			continue
+		}
+
+		if err := s.send(d, sub.Email, now); err != nil {
+			logger.Warn("Statement email failed",
+				zap.String("delivery_id", d.ID.String()),
+				zap.Int("attempts", d.Attempts),
+				zap.Bool("will_retry", d.Attempts < statement.MaxDeliveryAttempts),
+				zap.Error(err),
+			)
+			continue
+		}
+		sent++
+	}
+
+	return sent, nil
+}
+
+// send renders the delivery's statement and emails it to email, or to the
+// account holder's profile address when email is empty. The outcome is
+// recorded on the delivery either way.
+func (s *statementService) send(d *statement.Delivery, email string, now time.Time) error {
+	st, to, err := s.build(d, email, now)
+	if err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), statementEmailTimeout)
+		err = s.notifier.SendEmail(ctx, &notifier.Email{
+			To:      to,
+			Subject: fmt.Sprintf("Your %s statement for %s", s.bankName, st.MonthName()),
+			Body:    s.emailBody(st),
+			Attachments: []notifier.Attachment{{
+				Filename:    st.FileName(),
+				ContentType: "application/pdf",
+				Data:        st.Render(s.bankName, s.zone),
+			}},
+		})
+		cancel()
+	}
+
+	d.Attempts++
+	d.Email = to
+	if err != nil {
+		d.Status = statement.DeliveryStatusFailed
+		d.LastError = err.Error()
+	} else {
+		sentAt := now
+		d.Status = statement.DeliveryStatusSent
+		d.LastError = ""
+		d.SentAt = &sentAt
+	}
+	if recordErr := s.statementRepo.RecordAttempt(d); recordErr != nil {
+		logger.Error("Failed to record statement delivery", zap.String("delivery_id", d.ID.String()), zap.Error(recordErr))
+		errtrack.CaptureError(recordErr, map[string]string{"component": "statement_service", "operation": "record_attempt"})
+	}
+
+	return err
+}
+
+// build assembles the statement of a delivery and picks its recipient
+func (s *statementService) build(d *statement.Delivery, email string, now time.Time) (*statement.Statement, string, error) {
+	start, end, err := statement.PeriodBounds(d.Period, s.zone)
+	if err != nil {
+		return nil, "", err
+	}
+	acct, err := s.accountRepo.GetByID(d.AccountID)
+	if err != nil {
+		return nil, "", err
+	}
+	holder, err := s.userRepo.GetByID(acct.UserID)
+	if err != nil {
+		return nil, "", err
+	}
+	if email == "" {
+		email = holder.Email
+	}
+
+	txns, err := s.transactionRepo.ListCompletedByAccount(acct.ID, start, now)
+	if err != nil {
+		return nil, email, err
+	}
+
+	return statement.Build(acct, holder.FirstName+" "+holder.LastName, d.Period, start, end, txns), email, nil
+}
+
+func (s *statementService) emailBody(st *statement.Statement) string {
+	return fmt.Sprintf(`Hello %s,
+
+Your statement for account %s for %s is attached.
+
+Opening balance: %s
+Closing balance: %s
+
+This is an automated message from %s. Please do not reply.
+`,
+		st.HolderName,
+		transaction.MaskAccountNumber(st.AccountNumber),
+		st.MonthName(),
+		transaction.FormatMoney(st.Currency, st.OpeningBalance),
+		transaction.FormatMoney(st.Currency, st.ClosingBalance),
+		s.bankName,
+	)
+}
+
+func (s *statementService) ownedAccount(userID uuid.UUID, accountID uuid.UUID) (*account.Account, error) {
+	acct, err := s.accountRepo.GetByID(accountID)
+	if err != nil {
+		return nil, fmt.Errorf("account not found")
+	}
+	if acct.UserID != userID {
+		return nil, fmt.Errorf("unauthorized: account does not belong to user")
+	}
+	return acct, nil
+}
+
+func (s *statementService) audit(userID uuid.UUID, action string, accountID uuid.UUID, metadata map[string]interface{}) {
+	if err := s.auditRepo.Create(&audit.AuditLog{
+		EventID:  uuid.New(),
+		UserID:   &userID,
+		Action:   action,
+		Resource: fmt.Sprintf("account:%s", accountID),
+		Status:   "success",
+		Metadata: metadata,
+	}); err != nil {
+		logger.Error("Failed to create audit log for statement", zap.String("action", action), zap.Error(err))
+		errtrack.CaptureError(err, map[string]string{"component": "statement_service", "operation": "audit_log"})
+	}
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/statement"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/domain/user"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/pkg/notifier"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockStatementRepository is a mock implementation of repository.StatementRepository
+type MockStatementRepository struct {
+	mock.Mock
+}
+
+func (m *MockStatementRepository) SaveSubscription(s *statement.Subscription) error {
+	args := m.Called(s)
+	return args.Error(0)
+}
+
+func (m *MockStatementRepository) GetSubscription(accountID uuid.UUID) (*statement.Subscription, error) {
+	args := m.Called(accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*statement.Subscription), args.Error(1)
+}
+
+func (m *MockStatementRepository) DeleteSubscription(accountID uuid.UUID) error {
+	args := m.Called(accountID)
+	return args.Error(0)
+}
+
+func (m *MockStatementRepository) ListDueSubscriptions(period string, maxAttempts int) ([]*statement.Subscription, error) {
+	args := m.Called(period, maxAttempts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*statement.Subscription), args.Error(1)
+}
+
+func (m *MockStatementRepository) EnsureDelivery(d *statement.Delivery) error {
+	args := m.Called(d)
+	return args.Error(0)
+}
+
+func (m *MockStatementRepository) GetDelivery(id uuid.UUID) (*statement.Delivery, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*statement.Delivery), args.Error(1)
+}
+
+func (m *MockStatementRepository) ListDeliveries(accountID uuid.UUID) ([]*statement.Delivery, error) {
+	args := m.Called(accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*statement.Delivery), args.Error(1)
+}
+
+func (m *MockStatementRepository) RecordAttempt(d *statement.Delivery) error {
+	args := m.Called(d)
+	return args.Error(0)
+}
+
+// MockNotifier is a mock implementation of notifier.Notifier
+type MockNotifier struct {
+	mock.Mock
+}
+
+func (m *MockNotifier) SendEmail(ctx context.Context, email *notifier.Email) error {
+	args := m.Called(email)
+	return args.Error(0)
+}
+
+func (m *MockNotifier) Name() string {
+	return "mock"
+}
+
+// MockSMSSender is a mock implementation of notifier.SMSSender
+type MockSMSSender struct {
+	mock.Mock
+}
+
+func (m *MockSMSSender) SendSMS(ctx context.Context, sms *notifier.SMS) error {
+	args := m.Called(sms)
+	return args.Error(0)
+}
+
+func (m *MockSMSSender) Name() string {
+	return "mock"
+}
+
+type statementTest struct {
+	svc           StatementService
+	statementRepo *MockStatementRepository
+	accountRepo   *MockAccountRepository
+	txnRepo       *MockTransactionRepository
+	userRepo      *MockUserRepository
+	notifier      *MockNotifier
+}
+
+func setupStatementServiceTest() *statementTest {
+	logger.Init("test")
+	st := &statementTest{
+		statementRepo: new(MockStatementRepository),
+		accountRepo:   new(MockAccountRepository),
+		txnRepo:       new(MockTransactionRepository),
+		userRepo:      new(MockUserRepository),
+		notifier:      new(MockNotifier),
+	}
+	auditRepo := new(MockAuditRepository)
+	auditRepo.On("Create", mock.Anything).Return(nil)
+	st.svc = NewStatementService(st.statementRepo, st.accountRepo, st.txnRepo, st.userRepo, auditRepo, st.notifier, "MadaBank", time.UTC)
+	return st
+}
+
+// withAccount registers an account of a user with one deposit in February 2026
+func (st *statementTest) withAccount(userID uuid.UUID) *account.Account {
+	acct := &account.Account{ID: uuid.New(), UserID: userID, AccountNumber: "1000000001", Currency: "IDR", Balance: 500000, Status: account.AccountStatusActive}
+	completed := time.Date(2026, 2, 14, 9, 0, 0, 0, time.UTC)
+	st.accountRepo.On("GetByID", acct.ID).Return(acct, nil)
+	st.userRepo.On("GetByID", userID).Return(&user.User{ID: userID, Email: "budi@example.com", FirstName: "Budi", LastName: "Santoso"}, nil)
+	st.txnRepo.On("ListCompletedByAccount", acct.ID, mock.Anything, mock.Anything).Return([]*transaction.Transaction{
+		{ID: uuid.New(), ToAccountID: &acct.ID, Amount: 200000, TransactionType: transaction.TransactionTypeDeposit, CompletedAt: &completed},
+	}, nil)
+	return acct
+}
+
+func TestSubscribe_OtherUsersAccount(t *testing.T) {
+	st := setupStatementServiceTest()
+	acct := st.withAccount(uuid.New())
+
+	_, err := st.svc.Subscribe(uuid.New(), acct.ID, &statement.SubscribeRequest{})
+
+	assert.EqualError(t, err, "unauthorized: account does not belong to user")
+	st.statementRepo.AssertNotCalled(t, "SaveSubscription", mock.Anything)
+}
+
+func TestSubscribe(t *testing.T) {
+	st := setupStatementServiceTest()
+	userID := uuid.New()
+	acct := st.withAccount(userID)
+	st.statementRepo.On("SaveSubscription", mock.Anything).Return(nil)
+
+	sub, err := st.svc.Subscribe(userID, acct.ID, &statement.SubscribeRequest{Email: "finance@example.com"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, acct.ID, sub.AccountID)
+	assert.Equal(t, "finance@example.com", sub.Email)
+}
+
+func TestSendDueStatements(t *testing.T) {
+	st := setupStatementServiceTest()
+	userID := uuid.New()
+	acct := st.withAccount(userID)
+	now := time.Date(2026, 3, 1, 6, 0, 0, 0, time.UTC)
+
+	st.statementRepo.On("ListDueSubscriptions", "2026-02", statement.MaxDeliveryAttempts).Return([]*statement.Subscription{
+		{UserID: userID, AccountID: acct.ID},
+	}, nil)
+	st.statementRepo.On("EnsureDelivery", mock.Anything).Return(nil)
+	st.notifier.On("SendEmail", mock.MatchedBy(func(e *notifier.Email) bool {
+		return e.To == "budi@example.com" &&
+			e.Subject == "Your MadaBank statement for February 2026" &&
+			len(e.Attachments) == 1 && e.Attachments[0].Filename == "statement-1000000001-2026-02.pdf"
+	})).Return(nil)
+	st.statementRepo.On("RecordAttempt", mock.MatchedBy(func(d *statement.Delivery) bool {
+		return d.Status == statement.DeliveryStatusSent && d.Attempts == 1 && d.SentAt != nil && d.Period == "2026-02"
+	})).Return(nil)
+
+	sent, err := st.svc.SendDueStatements(now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	st.statementRepo.AssertExpectations(t)
+}
+
+func TestSendDueStatements_RecordsFailure(t *testing.T) {
+	st := setupStatementServiceTest()
+	userID := uuid.New()
+	acct := st.withAccount(userID)
+
+	st.statementRepo.On("ListDueSubscriptions", "2026-02", statement.MaxDeliveryAttempts).Return([]*statement.Subscription{
+		{UserID: userID, AccountID: acct.ID, Email: "finance@example.com"},
+	}, nil)
+	st.statementRepo.On("EnsureDelivery", mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(0).(*statement.Delivery).Attempts = 1
+	}).Return(nil)
+	st.notifier.On("SendEmail", mock.Anything).Return(fmt.Errorf("relay unavailable"))
+	st.statementRepo.On("RecordAttempt", mock.MatchedBy(func(d *statement.Delivery) bool {
+		return d.Status == statement.DeliveryStatusFailed && d.Attempts == 2 &&
+			d.LastError == "relay unavailable" && d.Email == "finance@example.com"
+	})).Return(nil)
+
+	sent, err := st.svc.SendDueStatements(time.Date(2026, 3, 2, 6, 0, 0, 0, time.UTC))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, sent)
+	st.statementRepo.AssertExpectations(t)
+}
+
+func TestResend_DeliveryOfAnotherAccount(t *testing.T) {
+	st := setupStatementServiceTest()
+	userID := uuid.New()
+	acct := st.withAccount(userID)
+	deliveryID := uuid.New()
+	st.statementRepo.On("GetDelivery", deliveryID).Return(&statement.Delivery{ID: deliveryID, AccountID: uuid.New(), Period: "2026-02"}, nil)
+
+	_, err := st.svc.Resend(userID, acct.ID, deliveryID)
+
+	assert.EqualError(t, err, "statement delivery not found")
+	st.notifier.AssertNotCalled(t, "SendEmail", mock.Anything)
+}
+
+func TestResend(t *testing.T) {
+	st := setupStatementServiceTest()
+	userID := uuid.New()
+	acct := st.withAccount(userID)
+	d := &statement.Delivery{ID: uuid.New(), UserID: userID, AccountID: acct.ID, Period: "2026-02", Status: statement.DeliveryStatusFailed, Attempts: statement.MaxDeliveryAttempts}
+	st.statementRepo.On("GetDelivery", d.ID).Return(d, nil)
+	st.statementRepo.On("GetSubscription", acct.ID).Return(nil, fmt.Errorf("statement subscription not found"))
+	st.notifier.On("SendEmail", mock.Anything).Return(nil)
+	st.statementRepo.On("RecordAttempt", d).Return(nil)
+
+	result, err := st.svc.Resend(userID, acct.ID, d.ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, statement.DeliveryStatusSent, result.Status)
+	assert.Equal(t, "budi@example.com", result.Email)
+	assert.Equal(t, statement.MaxDeliveryAttempts+1, result.Attempts)
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/audit"
+	"github.com/darisadam/madabank-server/internal/domain/user"
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
+	"github.com/darisadam/madabank-server/internal/pkg/jwt"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// TokenService authenticates internal services and lets them check customer
+// access tokens without holding the signing keys
+type TokenService interface {
+	// IssueServiceToken exchanges a service client's credentials for a
+	// short-lived token carrying its scopes
+	IssueServiceToken(req *user.ServiceTokenRequest) (*user.ServiceTokenResponse, error)
+	// Introspect reports whether the token is a valid access token of an
+	// active user, with its claims when it is. Tokens that cannot be checked
+	// are reported inactive.
+	Introspect(token string) *user.IntrospectionResponse
+	// Impersonate mints a read-only token acting as a customer for a
+	// support agent. Every token is recorded in the audit log.
+	Impersonate(agentID, userID uuid.UUID, req *user.ImpersonationRequest) (*user.ImpersonationResponse, error)
+}
+
+type tokenService struct {
+	jwtService    *jwt.JWTService
+	serviceTokens *jwt.ServiceTokenService
+	clients       map[string]user.ServiceClient
+	userRepo      repository.UserRepository
+	auditRepo     repository.AuditRepository
+}
+
+func NewTokenService(jwtService *jwt.JWTService, serviceTokens *jwt.ServiceTokenService, clients []user.ServiceClient, userRepo repository.UserRepository, auditRepo repository.AuditRepository) TokenService {
+	byID := make(map[string]user.ServiceClient, len(clients))
+	for _, client := range clients {
+		byID[client.ID] = client
+	}
+	return &tokenService{
+		jwtService:    jwtService,
+		serviceTokens: serviceTokens,
+		clients:       byID,
+		userRepo:      userRepo,
+		auditRepo:     auditRepo,
+	}
+}
+
+func (s *tokenService) IssueServiceToken(req *user.ServiceTokenRequest) (*user.ServiceTokenResponse, error) {
+	if req.GrantType != user.GrantTypeClientCredentials {
+		return nil, user.ErrUnsupportedGrantType
+	}
+
+	client, ok := s.clients[req.ClientID]
+	// Compare against an empty secret for unknown clients so the timing does
+	// not reveal which client IDs exist
+	if subtle.ConstantTimeCompare([]byte(req.ClientSecret), []byte(client.Secret)) != 1 || !ok || s.serviceTokens == nil {
+		logger.Warn("Service client authentication failed", zap.String("client_id", req.ClientID))
+		return nil, user.ErrInvalidClient
+	}
+
+	scopes, err := client.Grant(req.Scope)
+	if err != nil {
+		return nil, err
+	}
+
+	token, _, err := s.serviceTokens.GenerateServiceToken(client.ID, scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user.ServiceTokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(s.serviceTokens.TTL().Seconds()),
+		Scope:       strings.Join(scopes, " "),
+	}, nil
+}
+
+func (s *tokenService) Introspect(token string) *user.IntrospectionResponse {
+	inactive := &user.IntrospectionResponse{Active: false}
+
+	claims, err := s.jwtService.ValidateToken(token)
+	if err != nil {
+		return inactive
+	}
+
+	// A signature alone is not enough: the user may have been deleted or
+	// deactivated since the token was issued
+	u, err := s.userRepo.GetByID(claims.UserID)
+	if err != nil || !u.IsActive {
+		return inactive
+	}
+
+	resp := &user.IntrospectionResponse{
+		Active:    true,
+		TokenType: "Bearer",
+		Sub:       &claims.UserID,
+		Username:  claims.Email,
+		Role:      claims.Role,
+		Scope:     claims.Scope,
+
+		ImpersonatedBy: claims.ImpersonatedBy,
+	}
+	if claims.ExpiresAt != nil {
+		resp.Exp = claims.ExpiresAt.Unix()
+	}
+	if claims.IssuedAt != nil {
+		resp.Iat = claims.IssuedAt.Unix()
+	}
+	if claims.NotBefore != nil {
+		resp.Nbf = claims.NotBefore.Unix()
+	}
+
+	return resp
+}
+
+func (s *tokenService) Impersonate(agentID, userID uuid.UUID, req *user.ImpersonationRequest) (*user.ImpersonationResponse, error) {
+	if agentID == userID {
+		return nil, fmt.Errorf("cannot impersonate yourself")
+	}
+
+	u, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found")
+	}
+	if !u.IsActive {
+		return nil, fmt.Errorf("user is not active")
+	}
+	// Impersonating another admin would be a way around the admin's own role
+	if u.Role != user.RoleCustomer {
+		return nil, fmt.Errorf("only customers can be impersonated")
+	}
+
+	tokenID := uuid.New()
+	expiresAt := time.Now().Add(req.TTL())
+	token, expiresAt, err := s.jwtService.GenerateImpersonationToken(u.ID, u.Email, u.Role, agentID, tokenID, user.ImpersonationScopes, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	// No audit record, no token: support access must always be traceable
+	if err := s.auditRepo.Create(&audit.AuditLog{
+		EventID:  uuid.New(),
+		UserID:   &agentID,
+		Action:   "USER_IMPERSONATION_STARTED",
+		Resource: "user:" + u.ID.String(),
+		Status:   "success",
+		Metadata: map[string]interface{}{
+			"reason":     req.Reason,
+			"token_id":   tokenID.String(),
+			"expires_at": expiresAt,
+		},
+	}); err != nil {
+		logger.Error("Failed to create audit log for impersonation", zap.Error(err))
+		errtrack.CaptureError(err, map[string]string{"component": "token_service", "operation": "audit_log"})
+		return nil, fmt.Errorf("failed to record impersonation")
+	}
+
+	logger.Audit("USER_IMPERSONATION_STARTED",
+		zap.String("agent_id", agentID.String()),
+		zap.String("user_id", u.ID.String()),
+		zap.String("token_id", tokenID.String()),
+	)
+
+	return &user.ImpersonationResponse{
+		Token:     token,
+		TokenID:   tokenID,
+		UserID:    u.ID,
+		Scope:     strings.Join(user.ImpersonationScopes, " "),
+		ExpiresAt: expiresAt,
+	}, nil
+}
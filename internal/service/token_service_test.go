@@ -0,0 +1,196 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/audit"
+	"github.com/darisadam/madabank-server/internal/domain/user"
+	"github.com/darisadam/madabank-server/internal/pkg/jwt"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestIntrospect_Active(t *testing.T) {
+	jwtSvc := jwt.NewJWTService("secret", 1)
+	userRepo := new(MockUserRepository)
+	svc := NewTokenService(jwtSvc, nil, nil, userRepo, nil)
+
+	userID := uuid.New()
+	token, expiresAt, err := jwtSvc.GenerateToken(userID, "budi@example.com", "customer")
+	assert.NoError(t, err)
+	userRepo.On("GetByID", userID).Return(&user.User{ID: userID, IsActive: true}, nil)
+
+	resp := svc.Introspect(token)
+
+	assert.True(t, resp.Active)
+	assert.Equal(t, "Bearer", resp.TokenType)
+	assert.Equal(t, userID, *resp.Sub)
+	assert.Equal(t, "budi@example.com", resp.Username)
+	assert.Equal(t, "customer", resp.Role)
+	assert.Equal(t, expiresAt.Unix(), resp.Exp)
+	assert.NotZero(t, resp.Iat)
+}
+
+func TestIntrospect_Inactive(t *testing.T) {
+	jwtSvc := jwt.NewJWTService("secret", 1)
+	userRepo := new(MockUserRepository)
+	svc := NewTokenService(jwtSvc, nil, nil, userRepo, nil)
+
+	deactivatedID := uuid.New()
+	deletedID := uuid.New()
+	userRepo.On("GetByID", deactivatedID).Return(&user.User{ID: deactivatedID, IsActive: false}, nil)
+	userRepo.On("GetByID", deletedID).Return(nil, fmt.Errorf("user not found"))
+
+	forged, _, _ := jwt.NewJWTService("other-secret", 1).GenerateToken(uuid.New(), "x@example.com", "admin")
+	deactivated, _, _ := jwtSvc.GenerateToken(deactivatedID, "a@example.com", "customer")
+	deleted, _, _ := jwtSvc.GenerateToken(deletedID, "b@example.com", "customer")
+
+	for name, token := range map[string]string{
+		"malformed":   "not-a-jwt",
+		"forged":      forged,
+		"deactivated": deactivated,
+		"deleted":     deleted,
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, &user.IntrospectionResponse{Active: false}, svc.Introspect(token))
+		})
+	}
+}
+
+func setupServiceTokenTest(t *testing.T) (TokenService, *jwt.ServiceTokenService) {
+	logger.Init("test")
+	serviceTokens := jwt.NewServiceTokenService("service-secret", 15*time.Minute)
+	clients := []user.ServiceClient{
+		{ID: "statement-renderer", Secret: "renderer-secret", Scopes: []string{"statements.render", user.ScopeTokensIntrospect}},
+	}
+	return NewTokenService(jwt.NewJWTService("secret", 1), serviceTokens, clients, new(MockUserRepository), nil), serviceTokens
+}
+
+func TestIssueServiceToken(t *testing.T) {
+	svc, serviceTokens := setupServiceTokenTest(t)
+
+	resp, err := svc.IssueServiceToken(&user.ServiceTokenRequest{
+		GrantType:    user.GrantTypeClientCredentials,
+		ClientID:     "statement-renderer",
+		ClientSecret: "renderer-secret",
+		Scope:        user.ScopeTokensIntrospect,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer", resp.TokenType)
+	assert.Equal(t, 900, resp.ExpiresIn)
+	assert.Equal(t, user.ScopeTokensIntrospect, resp.Scope)
+
+	claims, err := serviceTokens.ValidateServiceToken(resp.AccessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, "statement-renderer", claims.ClientID)
+	assert.True(t, claims.HasScope(user.ScopeTokensIntrospect))
+	assert.False(t, claims.HasScope("statements.render"))
+}
+
+func TestIssueServiceToken_Rejected(t *testing.T) {
+	svc, _ := setupServiceTokenTest(t)
+
+	tests := []struct {
+		name string
+		req  user.ServiceTokenRequest
+		want error
+	}{
+		{"wrong grant", user.ServiceTokenRequest{GrantType: "password", ClientID: "statement-renderer", ClientSecret: "renderer-secret"}, user.ErrUnsupportedGrantType},
+		{"wrong secret", user.ServiceTokenRequest{GrantType: user.GrantTypeClientCredentials, ClientID: "statement-renderer", ClientSecret: "guess"}, user.ErrInvalidClient},
+		{"unknown client", user.ServiceTokenRequest{GrantType: user.GrantTypeClientCredentials, ClientID: "fraud-worker"}, user.ErrInvalidClient},
+		{"scope not allowed", user.ServiceTokenRequest{GrantType: user.GrantTypeClientCredentials, ClientID: "statement-renderer", ClientSecret: "renderer-secret", Scope: "accounts.write"}, user.ErrInvalidScope},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.IssueServiceToken(&tt.req)
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestImpersonate(t *testing.T) {
+	logger.Init("test")
+	jwtSvc := jwt.NewJWTService("secret", 1)
+	userRepo := new(MockUserRepository)
+	auditRepo := new(MockAuditRepository)
+	svc := NewTokenService(jwtSvc, nil, nil, userRepo, auditRepo)
+
+	agentID, userID := uuid.New(), uuid.New()
+	userRepo.On("GetByID", userID).Return(&user.User{ID: userID, Email: "budi@example.com", Role: user.RoleCustomer, IsActive: true}, nil)
+	auditRepo.On("Create", mock.MatchedBy(func(log *audit.AuditLog) bool {
+		return log.Action == "USER_IMPERSONATION_STARTED" && *log.UserID == agentID &&
+			log.Resource == "user:"+userID.String() && log.Metadata["reason"] == "ticket 4821: balance not updating"
+	})).Return(nil)
+
+	before := time.Now()
+	resp, err := svc.Impersonate(agentID, userID, &user.ImpersonationRequest{Reason: "ticket 4821: balance not updating"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, userID, resp.UserID)
+	assert.WithinDuration(t, before.Add(user.DefaultImpersonationTTL), resp.ExpiresAt, 2*time.Second)
+
+	claims, err := jwtSvc.ValidateToken(resp.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, agentID, *claims.ImpersonatedBy)
+	assert.Equal(t, resp.TokenID.String(), claims.ID)
+	assert.True(t, claims.HasScope("accounts:read"))
+	assert.False(t, claims.HasScope("accounts:write"))
+	assert.False(t, claims.HasScope("admin:read"))
+
+	// Introspection shows who is behind the token
+	introspected := svc.Introspect(resp.Token)
+	assert.True(t, introspected.Active)
+	assert.Equal(t, agentID, *introspected.ImpersonatedBy)
+	auditRepo.AssertExpectations(t)
+}
+
+func TestImpersonate_Refused(t *testing.T) {
+	logger.Init("test")
+	agentID := uuid.New()
+	adminID, inactiveID, missingID := uuid.New(), uuid.New(), uuid.New()
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetByID", adminID).Return(&user.User{ID: adminID, Role: user.RoleAdmin, IsActive: true}, nil)
+	userRepo.On("GetByID", inactiveID).Return(&user.User{ID: inactiveID, Role: user.RoleCustomer, IsActive: false}, nil)
+	userRepo.On("GetByID", missingID).Return(nil, fmt.Errorf("user not found"))
+	svc := NewTokenService(jwt.NewJWTService("secret", 1), nil, nil, userRepo, new(MockAuditRepository))
+
+	tests := []struct {
+		name   string
+		userID uuid.UUID
+		err    string
+	}{
+		{"self", agentID, "cannot impersonate yourself"},
+		{"admin", adminID, "only customers can be impersonated"},
+		{"inactive", inactiveID, "user is not active"},
+		{"missing", missingID, "user not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := svc.Impersonate(agentID, tt.userID, &user.ImpersonationRequest{Reason: "ticket 4821: balance not updating"})
+			assert.Nil(t, resp)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestImpersonate_AuditFailure(t *testing.T) {
+	logger.Init("test")
+	userID := uuid.New()
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetByID", userID).Return(&user.User{ID: userID, Role: user.RoleCustomer, IsActive: true}, nil)
+	auditRepo := new(MockAuditRepository)
+	auditRepo.On("Create", mock.Anything).Return(fmt.Errorf("connection refused"))
+	svc := NewTokenService(jwt.NewJWTService("secret", 1), nil, nil, userRepo, auditRepo)
+
+	resp, err := svc.Impersonate(uuid.New(), userID, &user.ImpersonationRequest{Reason: "ticket 4821: balance not updating"})
+
+	assert.Nil(t, resp)
+	assert.EqualError(t, err, "failed to record impersonation")
+}
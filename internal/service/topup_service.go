@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/audit"
+	"github.com/darisadam/madabank-server/internal/domain/topup"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/pkg/metrics"
+	"github.com/darisadam/madabank-server/internal/pkg/topupagg"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	maxTopupsListed = 50
+	topupSyncBatch  = 100
+	// Unfinished top-ups younger than this are left to the request that created them
+	topupSyncGrace = time.Minute
+	// Kept below the server write timeout so the client still gets an answer
+	topupAggregatorTimeout = 8 * time.Second
+)
+
+type TopupService interface {
+	CreateTopup(userID uuid.UUID, req *topup.CreateTopupRequest) (*topup.Topup, error)
+	GetTopup(userID uuid.UUID, topupID uuid.UUID) (*topup.Topup, error)
+	ListTopups(userID uuid.UUID) ([]*topup.Topup, error)
+	SyncUnfinished(now time.Time) (int, error)
+}
+
+type topupService struct {
+	topupRepo       repository.TopupRepository
+	accountRepo     repository.AccountRepository
+	transactionRepo repository.TransactionRepository
+	auditRepo       repository.AuditRepository
+	aggregator      topupagg.Aggregator
+}
+
+func NewTopupService(
+	topupRepo repository.TopupRepository,
+	accountRepo repository.AccountRepository,
+	transactionRepo repository.TransactionRepository,
+	auditRepo repository.AuditRepository,
+	aggregator topupagg.Aggregator,
+) TopupService {
+	return &topupService{
+		topupRepo:       topupRepo,
+		accountRepo:     accountRepo,
+		transactionRepo: transactionRepo,
+		auditRepo:       auditRepo,
+		aggregator:      aggregator,
+	}
+}
+
+// CreateTopup debits the account and submits the order to the aggregator.
+// Delivery is asynchronous; the returned top-up is usually still processing.
+func (s *topupService) CreateTopup(userID uuid.UUID, req *topup.CreateTopupRequest) (*topup.Topup, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	accountID, err := uuid.Parse(req.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account_id")
+	}
+
+	topupType := topup.Type(req.Type)
+	destination, err := topup.NormalizePhoneNumber(req.Destination)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := resolveTopupProvider(topupType, topup.Provider(req.Provider), destination)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := topup.ValidateAmount(topupType, req.Amount); err != nil {
+		return nil, err
+	}
+
+	// Check idempotency
+	if existing, err := s.transactionRepo.GetByIdempotencyKey(req.IdempotencyKey); err == nil {
+		t, err := s.topupRepo.GetByTransactionID(existing.ID)
+		if err != nil || t.UserID != userID {
+			return nil, fmt.Errorf("idempotency key already used")
+		}
+		return t, nil
+	}
+
+	acct, err := s.accountRepo.GetByID(accountID)
+	if err != nil {
+		return nil, fmt.Errorf("account not found")
+	}
+	if acct.UserID != userID {
+		return nil, fmt.Errorf("unauthorized: account does not belong to user")
+	}
+	if acct.Status != account.AccountStatusActive {
+		return nil, fmt.Errorf("account is %s, cannot perform payments", acct.Status)
+	}
+
+	t := &topup.Topup{
+		ID:            uuid.New(),
+		UserID:        userID,
+		AccountID:     accountID,
+		TransactionID: uuid.New(),
+		Type:          topupType,
+		Provider:      provider,
+		Destination:   destination,
+		Amount:        req.Amount,
+		AdminFee:      topup.AdminFee(topupType),
+		Status:        topup.StatusPending,
+	}
+
+	txn := &transaction.Transaction{
+		ID:              t.TransactionID,
+		IdempotencyKey:  req.IdempotencyKey,
+		FromAccountID:   &accountID,
+		Amount:          t.TotalAmount(),
+		TransactionType: transaction.TransactionTypeTopup,
+		Status:          transaction.TransactionStatusPending,
+		Description:     fmt.Sprintf("%s top-up %s", provider, destination),
+		Metadata: map[string]interface{}{
+			"initiated_by": userID.String(),
+			"currency":     acct.Currency,
+			"topup_id":     t.ID.String(),
+			"topup_type":   string(topupType),
+			"provider":     string(provider),
+			"destination":  destination,
+			"topup_amount": t.Amount,
+			"admin_fee":    t.AdminFee,
+			"aggregator":   s.aggregator.Name(),
+		},
+	}
+
+	if err := s.topupRepo.Create(t, txn); err != nil {
+		metrics.RecordTransactionError("topup", "execution_failed")
+		return nil, err
+	}
+
+	if err := s.auditRepo.Create(&audit.AuditLog{
+		EventID:  uuid.New(),
+		UserID:   &userID,
+		Action:   "TOPUP_REQUESTED",
+		Resource: fmt.Sprintf("topup:%s", t.ID),
+		Status:   "success",
+		Metadata: map[string]interface{}{
+			"amount":      t.TotalAmount(),
+			"provider":    string(provider),
+			"destination": destination,
+		},
+	}); err != nil {
+		logger.Error("Failed to create audit log for top-up", zap.Error(err))
+		errtrack.CaptureError(err, map[string]string{"component": "topup_service", "operation": "audit_log"})
+	}
+
+	s.submit(t)
+
+	return s.topupRepo.GetByID(t.ID)
+}
+
+func (s *topupService) GetTopup(userID uuid.UUID, topupID uuid.UUID) (*topup.Topup, error) {
+	t, err := s.topupRepo.GetByID(topupID)
+	if err != nil {
+		return nil, err
+	}
+	if t.UserID != userID {
+		return nil, fmt.Errorf("unauthorized: top-up does not belong to user")
+	}
+
+	return t, nil
+}
+
+func (s *topupService) ListTopups(userID uuid.UUID) ([]*topup.Topup, error) {
+	return s.topupRepo.ListByUser(userID, maxTopupsListed)
+}
+
+// SyncUnfinished resubmits pending top-ups and polls processing ones for their
+// final status. It returns how many top-ups reached a final status.
+func (s *topupService) SyncUnfinished(now time.Time) (int, error) {
+	topups, err := s.topupRepo.ListUnfinished(now.Add(-topupSyncGrace), topupSyncBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	finished := 0
+	for _, t := range topups {
+		var final bool
+		if t.Status == topup.StatusPending {
+			// Orders are deduplicated by ID, so resubmitting is safe
+			final = s.submit(t)
+		} else {
+			final = s.poll(t)
+		}
+		if final {
+			finished++
+		}
+	}
+
+	return finished, nil
+}
+
+// submit sends the order to the aggregator and applies the answer. An
+// aggregator error leaves the top-up pending for the next sync.
+func (s *topupService) submit(t *topup.Topup) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), topupAggregatorTimeout)
+	defer cancel()
+
+	result, err := s.aggregator.Submit(ctx, topupagg.Order{
+		OrderID:     t.ID.String(),
+		Provider:    string(t.Provider),
+		Destination: t.Destination,
+		Amount:      t.Amount,
+	})
+	if err != nil {
+		logger.Warn("Top-up submission failed, will retry", zap.String("topup_id", t.ID.String()), zap.Error(err))
+		return false
+	}
+
+	return s.apply(t, result)
+}
+
+func (s *topupService) poll(t *topup.Topup) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), topupAggregatorTimeout)
+	defer cancel()
+
+	result, err := s.aggregator.CheckStatus(ctx, t.ID.String())
+	if errors.Is(err, topupagg.ErrOrderNotFound) {
+		// The aggregator lost an order it accepted; it needs a human, not a retry loop
+		logger.Error("Processing top-up unknown to aggregator", zap.String("topup_id", t.ID.String()), zap.String("reference", t.Reference))
+		errtrack.CaptureError(err, map[string]string{"component": "topup_service", "operation": "check_status"})
+		return false
+	}
+	if err != nil {
+		logger.Warn("Top-up status check failed, will retry", zap.String("topup_id", t.ID.String()), zap.Error(err))
+		return false
+	}
+
+	return s.apply(t, result)
+}
+
+// apply records the aggregator's answer and reports whether the top-up is final
+func (s *topupService) apply(t *topup.Topup, result *topupagg.Result) bool {
+	var err error
+	switch result.Status {
+	case topupagg.StatusProcessing:
+		if t.Status == topup.StatusPending {
+			err = s.topupRepo.MarkProcessing(t.ID, result.Reference)
+		}
+	case topupagg.StatusSuccess:
+		err = s.topupRepo.Complete(t.ID, result.Reference)
+	case topupagg.StatusFailed:
+		err = s.topupRepo.Fail(t.ID, result.Reason)
+	}
+	if err != nil {
+		logger.Error("Failed to update top-up status",
+			zap.String("topup_id", t.ID.String()),
+			zap.String("status", string(result.Status)),
+			zap.Error(err),
+		)
+		errtrack.CaptureError(err, map[string]string{"component": "topup_service", "operation": "apply_status"})
+		return false
+	}
+
+	switch result.Status {
+	case topupagg.StatusSuccess:
+		metrics.RecordTransaction("topup", "completed", t.TotalAmount(), DefaultCurrency, time.Since(t.CreatedAt).Seconds())
+		return true
+	case topupagg.StatusFailed:
+		metrics.RecordTransaction("topup", "failed", t.TotalAmount(), DefaultCurrency, time.Since(t.CreatedAt).Seconds())
+		return true
+	}
+	return false
+}
+
+// resolveTopupProvider checks the destination against the requested provider.
+// Phone credit goes to the operator that owns the number prefix.
+func resolveTopupProvider(topupType topup.Type, requested topup.Provider, destination string) (topup.Provider, error) {
+	switch topupType {
+	case topup.TypePhoneCredit:
+		operator, ok := topup.OperatorForNumber(destination)
+		if !ok {
+			return "", fmt.Errorf("unsupported mobile operator")
+		}
+		if requested != "" && requested != operator {
+			return "", fmt.Errorf("destination is not a %s number", requested)
+		}
+		return operator, nil
+	case topup.TypeEWallet:
+		if !topup.IsEWallet(requested) {
+			return "", fmt.Errorf("unsupported e-wallet provider")
+		}
+		return requested, nil
+	default:
+		return "", fmt.Errorf("invalid top-up type")
+	}
+}
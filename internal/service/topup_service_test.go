@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	domainAccount "github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/topup"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/pkg/topupagg"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTopupRepository is a mock implementation of repository.TopupRepository
+type MockTopupRepository struct {
+	mock.Mock
+}
+
+func (m *MockTopupRepository) Create(t *topup.Topup, txn *transaction.Transaction) error {
+	args := m.Called(t, txn)
+	return args.Error(0)
+}
+
+func (m *MockTopupRepository) GetByID(id uuid.UUID) (*topup.Topup, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*topup.Topup), args.Error(1)
+}
+
+func (m *MockTopupRepository) GetByTransactionID(txnID uuid.UUID) (*topup.Topup, error) {
+	args := m.Called(txnID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*topup.Topup), args.Error(1)
+}
+
+func (m *MockTopupRepository) ListByUser(userID uuid.UUID, limit int) ([]*topup.Topup, error) {
+	args := m.Called(userID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*topup.Topup), args.Error(1)
+}
+
+func (m *MockTopupRepository) ListUnfinished(updatedBefore time.Time, limit int) ([]*topup.Topup, error) {
+	args := m.Called(updatedBefore, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*topup.Topup), args.Error(1)
+}
+
+func (m *MockTopupRepository) MarkProcessing(id uuid.UUID, reference string) error {
+	args := m.Called(id, reference)
+	return args.Error(0)
+}
+
+func (m *MockTopupRepository) Complete(id uuid.UUID, reference string) error {
+	args := m.Called(id, reference)
+	return args.Error(0)
+}
+
+func (m *MockTopupRepository) Fail(id uuid.UUID, reason string) error {
+	args := m.Called(id, reason)
+	return args.Error(0)
+}
+
+// MockTopupAggregator is a mock implementation of topupagg.Aggregator
+type MockTopupAggregator struct {
+	mock.Mock
+}
+
+func (m *MockTopupAggregator) Submit(ctx context.Context, order topupagg.Order) (*topupagg.Result, error) {
+	args := m.Called(order)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*topupagg.Result), args.Error(1)
+}
+
+func (m *MockTopupAggregator) CheckStatus(ctx context.Context, orderID string) (*topupagg.Result, error) {
+	args := m.Called(orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*topupagg.Result), args.Error(1)
+}
+
+func (m *MockTopupAggregator) Name() string {
+	return "mock"
+}
+
+func setupTopupServiceTest(t *testing.T) (*topupService, *MockTopupRepository, *MockTopupAggregator, uuid.UUID, uuid.UUID) {
+	logger.Init("test")
+	topupRepo := new(MockTopupRepository)
+	accountRepo := new(MockAccountRepository)
+	txnRepo := new(MockTransactionRepository)
+	auditRepo := new(MockAuditRepository)
+	aggregator := new(MockTopupAggregator)
+
+	userID := uuid.New()
+	accountID := uuid.New()
+	accountRepo.On("GetByID", accountID).Return(&domainAccount.Account{
+		ID: accountID, UserID: userID, Status: domainAccount.AccountStatusActive, Currency: "IDR",
+	}, nil)
+	txnRepo.On("GetByIdempotencyKey", mock.Anything).Return(nil, fmt.Errorf("transaction not found"))
+	auditRepo.On("Create", mock.Anything).Return(nil)
+
+	svc := NewTopupService(topupRepo, accountRepo, txnRepo, auditRepo, aggregator).(*topupService)
+	return svc, topupRepo, aggregator, userID, accountID
+}
+
+func TestCreateTopup_PhoneCredit(t *testing.T) {
+	svc, topupRepo, aggregator, userID, accountID := setupTopupServiceTest(t)
+
+	topupRepo.On("Create", mock.MatchedBy(func(tp *topup.Topup) bool {
+		return tp.Provider == topup.ProviderTelkomsel && tp.Destination == "081234567890" &&
+			tp.Amount == 50_000 && tp.AdminFee == topup.PhoneCreditAdminFee && tp.Status == topup.StatusPending
+	}), mock.MatchedBy(func(txn *transaction.Transaction) bool {
+		return txn.TransactionType == transaction.TransactionTypeTopup && txn.Amount == 51_500
+	})).Return(nil)
+	aggregator.On("Submit", mock.MatchedBy(func(o topupagg.Order) bool {
+		return o.Provider == "telkomsel" && o.Amount == 50_000
+	})).Return(&topupagg.Result{Reference: "REF-1", Status: topupagg.StatusProcessing}, nil)
+	topupRepo.On("MarkProcessing", mock.Anything, "REF-1").Return(nil)
+	topupRepo.On("GetByID", mock.Anything).Return(&topup.Topup{Status: topup.StatusProcessing}, nil)
+
+	result, err := svc.CreateTopup(userID, &topup.CreateTopupRequest{
+		AccountID:      accountID.String(),
+		Type:           "phone_credit",
+		Destination:    "+62 812-3456-7890",
+		Amount:         50_000,
+		IdempotencyKey: "topup-1",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, topup.StatusProcessing, result.Status)
+	topupRepo.AssertExpectations(t)
+}
+
+func TestCreateTopup_RejectedIsRefunded(t *testing.T) {
+	svc, topupRepo, aggregator, userID, accountID := setupTopupServiceTest(t)
+
+	topupRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	aggregator.On("Submit", mock.Anything).Return(&topupagg.Result{Status: topupagg.StatusFailed, Reason: "destination not registered"}, nil)
+	topupRepo.On("Fail", mock.Anything, "destination not registered").Return(nil)
+	topupRepo.On("GetByID", mock.Anything).Return(&topup.Topup{Status: topup.StatusFailed}, nil)
+
+	result, err := svc.CreateTopup(userID, &topup.CreateTopupRequest{
+		AccountID:      accountID.String(),
+		Type:           "ewallet",
+		Provider:       "gopay",
+		Destination:    "081234569999",
+		Amount:         100_000,
+		IdempotencyKey: "topup-2",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, topup.StatusFailed, result.Status)
+	topupRepo.AssertExpectations(t)
+}
+
+func TestCreateTopup_AggregatorDownStaysPending(t *testing.T) {
+	svc, topupRepo, aggregator, userID, accountID := setupTopupServiceTest(t)
+
+	topupRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	aggregator.On("Submit", mock.Anything).Return(nil, fmt.Errorf("connection refused"))
+	topupRepo.On("GetByID", mock.Anything).Return(&topup.Topup{Status: topup.StatusPending}, nil)
+
+	result, err := svc.CreateTopup(userID, &topup.CreateTopupRequest{
+		AccountID:      accountID.String(),
+		Type:           "ewallet",
+		Provider:       "ovo",
+		Destination:    "081234567890",
+		Amount:         100_000,
+		IdempotencyKey: "topup-3",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, topup.StatusPending, result.Status)
+	topupRepo.AssertNotCalled(t, "Fail", mock.Anything, mock.Anything)
+}
+
+func TestCreateTopup_ValidationErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		req  topup.CreateTopupRequest
+		want string
+	}{
+		{"invalid number", topup.CreateTopupRequest{Type: "phone_credit", Destination: "12345", Amount: 50_000}, "invalid phone number"},
+		{"unknown operator", topup.CreateTopupRequest{Type: "phone_credit", Destination: "080012345678", Amount: 50_000}, "unsupported mobile operator"},
+		{"operator mismatch", topup.CreateTopupRequest{Type: "phone_credit", Provider: "xl", Destination: "081234567890", Amount: 50_000}, "destination is not a xl number"},
+		{"odd denomination", topup.CreateTopupRequest{Type: "phone_credit", Destination: "081234567890", Amount: 45_000}, "phone credit is only available in fixed denominations"},
+		{"unknown wallet", topup.CreateTopupRequest{Type: "ewallet", Provider: "paypal", Destination: "081234567890", Amount: 50_000}, "unsupported e-wallet provider"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, topupRepo, _, userID, accountID := setupTopupServiceTest(t)
+			tc.req.AccountID = accountID.String()
+			tc.req.IdempotencyKey = "topup-x"
+
+			_, err := svc.CreateTopup(userID, &tc.req)
+
+			assert.EqualError(t, err, tc.want)
+			topupRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestGetTopup_OtherUser(t *testing.T) {
+	svc, topupRepo, _, _, _ := setupTopupServiceTest(t)
+	topupID := uuid.New()
+	topupRepo.On("GetByID", topupID).Return(&topup.Topup{ID: topupID, UserID: uuid.New()}, nil)
+
+	_, err := svc.GetTopup(uuid.New(), topupID)
+
+	assert.EqualError(t, err, "unauthorized: top-up does not belong to user")
+}
+
+func TestSyncUnfinished(t *testing.T) {
+	svc, topupRepo, aggregator, _, _ := setupTopupServiceTest(t)
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	pending := &topup.Topup{ID: uuid.New(), Status: topup.StatusPending, Amount: 50_000}
+	delivered := &topup.Topup{ID: uuid.New(), Status: topup.StatusProcessing, Amount: 50_000}
+	failed := &topup.Topup{ID: uuid.New(), Status: topup.StatusProcessing, Amount: 50_000}
+	stillRunning := &topup.Topup{ID: uuid.New(), Status: topup.StatusProcessing, Amount: 50_000}
+
+	topupRepo.On("ListUnfinished", now.Add(-topupSyncGrace), topupSyncBatch).
+		Return([]*topup.Topup{pending, delivered, failed, stillRunning}, nil)
+
+	aggregator.On("Submit", mock.MatchedBy(func(o topupagg.Order) bool { return o.OrderID == pending.ID.String() })).
+		Return(&topupagg.Result{Reference: "REF-P", Status: topupagg.StatusProcessing}, nil)
+	topupRepo.On("MarkProcessing", pending.ID, "REF-P").Return(nil)
+
+	aggregator.On("CheckStatus", delivered.ID.String()).Return(&topupagg.Result{Reference: "REF-D", Status: topupagg.StatusSuccess}, nil)
+	topupRepo.On("Complete", delivered.ID, "REF-D").Return(nil)
+
+	aggregator.On("CheckStatus", failed.ID.String()).Return(&topupagg.Result{Status: topupagg.StatusFailed, Reason: "number inactive"}, nil)
+	topupRepo.On("Fail", failed.ID, "number inactive").Return(nil)
+
+	aggregator.On("CheckStatus", stillRunning.ID.String()).Return(&topupagg.Result{Status: topupagg.StatusProcessing}, nil)
+
+	finished, err := svc.SyncUnfinished(now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, finished)
+	topupRepo.AssertExpectations(t)
+	aggregator.AssertExpectations(t)
+}
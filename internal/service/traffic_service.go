@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/darisadam/madabank-server/internal/domain/audit"
+	"github.com/darisadam/madabank-server/internal/pkg/ddos"
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/pkg/ratelimit"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// TrafficService lets admins inspect and tune abuse protection at runtime,
+// for example to tighten DDoS thresholds or block a network during an attack
+type TrafficService interface {
+	GetDDoSConfig() (ddos.Config, error)
+	UpdateDDoSConfig(adminID uuid.UUID, req *ddos.ConfigUpdate) (ddos.Config, error)
+	ResetDDoSConfig(adminID uuid.UUID) (ddos.Config, error)
+
+	ListBlockedIPs() (*IPBlocklist, error)
+	UnblockIP(adminID uuid.UUID, ip string) error
+	BlockRange(adminID uuid.UUID, req *ratelimit.CIDRRequest) (string, error)
+	UnblockRange(adminID uuid.UUID, cidr string) error
+	GetAllowlist() ([]string, error)
+	AllowRange(adminID uuid.UUID, req *ratelimit.CIDRRequest) (string, error)
+	DisallowRange(adminID uuid.UUID, cidr string) error
+}
+
+// IPBlocklist is what currently keeps IPs out, plus the IPs the DDoS monitor
+// would flag, so an admin can decide whether to block them
+type IPBlocklist struct {
+	Blocked       []ratelimit.BlockedIP `json:"blocked"`
+	BlockedRanges []string              `json:"blocked_ranges"`
+	Flagged       []ddos.FlaggedIP      `json:"flagged"`
+}
+
+type trafficService struct {
+	ddos        *ddos.DDoSProtection
+	rateLimiter *ratelimit.RateLimiter
+	auditRepo   repository.AuditRepository
+}
+
+func NewTrafficService(ddosProtection *ddos.DDoSProtection, rateLimiter *ratelimit.RateLimiter, auditRepo repository.AuditRepository) TrafficService {
+	return &trafficService{
+		ddos:        ddosProtection,
+		rateLimiter: rateLimiter,
+		auditRepo:   auditRepo,
+	}
+}
+
+func (s *trafficService) GetDDoSConfig() (ddos.Config, error) {
+	config, err := s.ddos.RefreshConfig(context.Background())
+	if err != nil {
+		logger.Error("Failed to load DDoS config", zap.Error(err))
+		return ddos.Config{}, fmt.Errorf("failed to load DDoS config")
+	}
+	return config, nil
+}
+
+func (s *trafficService) UpdateDDoSConfig(adminID uuid.UUID, req *ddos.ConfigUpdate) (ddos.Config, error) {
+	config, err := s.ddos.UpdateConfig(context.Background(), req)
+	if err != nil {
+		return ddos.Config{}, err
+	}
+	s.audit(adminID, "DDOS_CONFIG_UPDATED", "ddos:config", ddosConfigMetadata(config))
+	return config, nil
+}
+
+func (s *trafficService) ResetDDoSConfig(adminID uuid.UUID) (ddos.Config, error) {
+	config, err := s.ddos.ResetConfig(context.Background())
+	if err != nil {
+		return ddos.Config{}, err
+	}
+	s.audit(adminID, "DDOS_CONFIG_RESET", "ddos:config", ddosConfigMetadata(config))
+	return config, nil
+}
+
+func (s *trafficService) ListBlockedIPs() (*IPBlocklist, error) {
+	ctx := context.Background()
+
+	blocked, err := s.rateLimiter.ListBlocked(ctx)
+	if err != nil {
+		logger.Error("Failed to list blocked IPs", zap.Error(err))
+		return nil, fmt.Errorf("failed to list blocked IPs")
+	}
+	ranges, err := s.rateLimiter.BlockedRanges(ctx)
+	if err != nil {
+		logger.Error("Failed to list blocked IP ranges", zap.Error(err))
+		return nil, fmt.Errorf("failed to list blocked IPs")
+	}
+	flagged, err := s.ddos.FlaggedIPs(ctx)
+	if err != nil {
+		logger.Error("Failed to list flagged IPs", zap.Error(err))
+		return nil, fmt.Errorf("failed to list blocked IPs")
+	}
+
+	return &IPBlocklist{Blocked: blocked, BlockedRanges: ranges, Flagged: flagged}, nil
+}
+
+func (s *trafficService) UnblockIP(adminID uuid.UUID, ip string) error {
+	if err := s.rateLimiter.Unblock(context.Background(), ip); err != nil {
+		return err
+	}
+	s.audit(adminID, "IP_UNBLOCKED", fmt.Sprintf("ip:%s", ip), nil)
+	return nil
+}
+
+func (s *trafficService) BlockRange(adminID uuid.UUID, req *ratelimit.CIDRRequest) (string, error) {
+	cidr, err := s.rateLimiter.BlockRange(context.Background(), req.CIDR)
+	if err != nil {
+		return "", err
+	}
+	s.audit(adminID, "IP_RANGE_BLOCKED", fmt.Sprintf("ip_range:%s", cidr), nil)
+	return cidr, nil
+}
+
+func (s *trafficService) UnblockRange(adminID uuid.UUID, cidr string) error {
+	cidr, err := s.rateLimiter.UnblockRange(context.Background(), cidr)
+	if err != nil {
+		return err
+	}
+	s.audit(adminID, "IP_RANGE_UNBLOCKED", fmt.Sprintf("ip_range:%s", cidr), nil)
+	return nil
+}
+
+func (s *trafficService) GetAllowlist() ([]string, error) {
+	ranges, err := s.rateLimiter.Allowlist(context.Background())
+	if err != nil {
+		logger.Error("Failed to list allowlisted IP ranges", zap.Error(err))
+		return nil, fmt.Errorf("failed to list allowlist")
+	}
+	return ranges, nil
+}
+
+func (s *trafficService) AllowRange(adminID uuid.UUID, req *ratelimit.CIDRRequest) (string, error) {
+	cidr, err := s.rateLimiter.Allow(context.Background(), req.CIDR)
+	if err != nil {
+		return "", err
+	}
+	s.audit(adminID, "IP_RANGE_ALLOWLISTED", fmt.Sprintf("ip_range:%s", cidr), nil)
+	return cidr, nil
+}
+
+func (s *trafficService) DisallowRange(adminID uuid.UUID, cidr string) error {
+	cidr, err := s.rateLimiter.Disallow(context.Background(), cidr)
+	if err != nil {
+		return err
+	}
+	s.audit(adminID, "IP_RANGE_UNALLOWLISTED", fmt.Sprintf("ip_range:%s", cidr), nil)
+	return nil
+}
+
+func ddosConfigMetadata(config ddos.Config) map[string]interface{} {
+	return map[string]interface{}{
+		"request_threshold":     config.RequestThreshold,
+		"window_seconds":        config.WindowSeconds,
+		"attack_threshold":      config.AttackThreshold,
+		"scan_interval_seconds": config.ScanIntervalSeconds,
+	}
+}
+
+func (s *trafficService) audit(adminID uuid.UUID, action, resource string, metadata map[string]interface{}) {
+	if err := s.auditRepo.Create(&audit.AuditLog{
+		EventID:  uuid.New(),
+		UserID:   &adminID,
+		Action:   action,
+		Resource: resource,
+		Status:   "success",
+		Metadata: metadata,
+	}); err != nil {
+		logger.Error("Failed to create audit log for traffic protection", zap.String("action", action), zap.Error(err))
+		errtrack.CaptureError(err, map[string]string{"component": "traffic_service", "operation": "audit_log"})
+	}
+}
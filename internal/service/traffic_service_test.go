@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/darisadam/madabank-server/internal/domain/audit"
+	"github.com/darisadam/madabank-server/internal/pkg/ddos"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/pkg/ratelimit"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func setupTrafficTest(t *testing.T) (TrafficService, *ratelimit.RateLimiter, *MockAuditRepository) {
+	logger.Init("test")
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	t.Cleanup(mr.Close)
+
+	auditRepo := new(MockAuditRepository)
+	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	limiter := ratelimit.NewRateLimiter(redisClient)
+	return NewTrafficService(ddos.NewDDoSProtection(redisClient), limiter, auditRepo), limiter, auditRepo
+}
+
+func TestUpdateDDoSConfig_Audited(t *testing.T) {
+	svc, _, auditRepo := setupTrafficTest(t)
+	adminID := uuid.New()
+	auditRepo.On("Create", mock.MatchedBy(func(l *audit.AuditLog) bool {
+		return l.Action == "DDOS_CONFIG_UPDATED" && *l.UserID == adminID && l.Metadata["attack_threshold"] == 3
+	})).Return(nil)
+
+	attackThreshold := 3
+	config, err := svc.UpdateDDoSConfig(adminID, &ddos.ConfigUpdate{AttackThreshold: &attackThreshold})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, config.AttackThreshold)
+
+	current, err := svc.GetDDoSConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, config, current)
+	auditRepo.AssertExpectations(t)
+}
+
+func TestUpdateDDoSConfig_Invalid(t *testing.T) {
+	svc, _, auditRepo := setupTrafficTest(t)
+
+	threshold := int64(0)
+	_, err := svc.UpdateDDoSConfig(uuid.New(), &ddos.ConfigUpdate{RequestThreshold: &threshold})
+	assert.EqualError(t, err, "request threshold must be at least 1")
+	auditRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestResetDDoSConfig(t *testing.T) {
+	svc, _, auditRepo := setupTrafficTest(t)
+	auditRepo.On("Create", mock.Anything).Return(nil)
+
+	window := 10
+	_, err := svc.UpdateDDoSConfig(uuid.New(), &ddos.ConfigUpdate{WindowSeconds: &window})
+	assert.NoError(t, err)
+
+	config, err := svc.ResetDDoSConfig(uuid.New())
+	assert.NoError(t, err)
+	assert.Equal(t, ddos.DefaultConfig, config)
+	auditRepo.AssertNumberOfCalls(t, "Create", 2)
+}
+
+func TestListBlockedIPs(t *testing.T) {
+	svc, limiter, _ := setupTrafficTest(t)
+	ctx := context.Background()
+	assert.NoError(t, limiter.Block(ctx, "198.51.100.7", time.Hour))
+	_, err := limiter.BlockRange(ctx, "203.0.113.0/24")
+	assert.NoError(t, err)
+
+	blocklist, err := svc.ListBlockedIPs()
+	assert.NoError(t, err)
+	assert.Len(t, blocklist.Blocked, 1)
+	assert.Equal(t, "198.51.100.7", blocklist.Blocked[0].IP)
+	assert.Equal(t, []string{"203.0.113.0/24"}, blocklist.BlockedRanges)
+	assert.Empty(t, blocklist.Flagged)
+}
+
+func TestUnblockIP(t *testing.T) {
+	svc, limiter, auditRepo := setupTrafficTest(t)
+	adminID := uuid.New()
+	assert.NoError(t, limiter.Block(context.Background(), "198.51.100.7", time.Hour))
+	auditRepo.On("Create", mock.MatchedBy(func(l *audit.AuditLog) bool {
+		return l.Action == "IP_UNBLOCKED" && l.Resource == "ip:198.51.100.7"
+	})).Return(nil).Once()
+
+	assert.NoError(t, svc.UnblockIP(adminID, "198.51.100.7"))
+	assert.ErrorIs(t, svc.UnblockIP(adminID, "198.51.100.7"), ratelimit.ErrNotBlocked)
+	auditRepo.AssertExpectations(t)
+}
+
+func TestBlockRange_Normalized(t *testing.T) {
+	svc, _, auditRepo := setupTrafficTest(t)
+	auditRepo.On("Create", mock.MatchedBy(func(l *audit.AuditLog) bool {
+		return l.Action == "IP_RANGE_BLOCKED" && l.Resource == "ip_range:10.0.0.0/8"
+	})).Return(nil)
+
+	cidr, err := svc.BlockRange(uuid.New(), &ratelimit.CIDRRequest{CIDR: "10.20.30.40/8"})
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.0/8", cidr)
+	auditRepo.AssertExpectations(t)
+}
+
+func TestAllowRange(t *testing.T) {
+	svc, _, auditRepo := setupTrafficTest(t)
+	auditRepo.On("Create", mock.Anything).Return(nil)
+
+	_, err := svc.AllowRange(uuid.New(), &ratelimit.CIDRRequest{CIDR: "not-an-ip"})
+	assert.EqualError(t, err, `invalid IP address "not-an-ip"`)
+
+	cidr, err := svc.AllowRange(uuid.New(), &ratelimit.CIDRRequest{CIDR: "192.0.2.10"})
+	assert.NoError(t, err)
+	assert.Equal(t, "192.0.2.10/32", cidr)
+
+	ranges, err := svc.GetAllowlist()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"192.0.2.10/32"}, ranges)
+
+	assert.NoError(t, svc.DisallowRange(uuid.New(), "192.0.2.10"))
+	ranges, err = svc.GetAllowlist()
+	assert.NoError(t, err)
+	assert.Empty(t, ranges)
+	auditRepo.AssertNumberOfCalls(t, "Create", 2)
+}
@@ -1,12 +1,20 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/darisadam/madabank-server/internal/domain/account"
 	"github.com/darisadam/madabank-server/internal/domain/audit"
+	"github.com/darisadam/madabank-server/internal/domain/beneficiary"
+	"github.com/darisadam/madabank-server/internal/domain/currency"
 	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/domain/user"
+	"github.com/darisadam/madabank-server/internal/domain/validation"
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
 	"github.com/darisadam/madabank-server/internal/pkg/logger"
 	"github.com/darisadam/madabank-server/internal/pkg/metrics"
 	"github.com/darisadam/madabank-server/internal/repository"
@@ -20,7 +28,7 @@ const (
 	MaxTransferAmount   = 10_000_000 // Maximum 10 million IDR per transaction
 	MinWithdrawalAmount = 1
 	MaxWithdrawalAmount = 10_000_000
-	DefaultCurrency     = "IDR"
+	DefaultCurrency     = currency.Default
 )
 
 type TransactionService interface {
@@ -28,6 +36,9 @@ type TransactionService interface {
 	Deposit(userID uuid.UUID, req *transaction.DepositRequest) (*transaction.Transaction, error)
 	Withdrawal(userID uuid.UUID, req *transaction.WithdrawalRequest) (*transaction.Transaction, error)
 	GetTransactionHistory(userID uuid.UUID, req *transaction.TransactionHistoryRequest) (*transaction.TransactionHistoryResponse, error)
+	GetArchivedHistory(userID uuid.UUID, req *transaction.ArchivedHistoryRequest) (*transaction.TransactionHistoryResponse, error)
+	// SearchTransactions searches one or all of the user's accounts
+	SearchTransactions(userID uuid.UUID, req *transaction.SearchRequest) (*transaction.TransactionHistoryResponse, error)
 	GetTransaction(userID uuid.UUID, transactionID uuid.UUID) (*transaction.Transaction, error)
 	ResolveQR(qrCode string) (*transaction.QRResolutionResponse, error)
 }
@@ -37,6 +48,8 @@ type transactionService struct {
 	accountRepo     repository.AccountRepository
 	auditRepo       repository.AuditRepository
 	userRepo        repository.UserRepository
+	archiveRepo     repository.TransactionArchiveRepository
+	beneficiaryRepo repository.BeneficiaryRepository
 }
 
 func NewTransactionService(
@@ -44,18 +57,27 @@ func NewTransactionService(
 	accountRepo repository.AccountRepository,
 	auditRepo repository.AuditRepository,
 	userRepo repository.UserRepository,
+	archiveRepo repository.TransactionArchiveRepository,
+	beneficiaryRepo repository.BeneficiaryRepository,
 ) TransactionService {
 	return &transactionService{
 		transactionRepo: transactionRepo,
 		accountRepo:     accountRepo,
 		auditRepo:       auditRepo,
 		userRepo:        userRepo,
+		archiveRepo:     archiveRepo,
+		beneficiaryRepo: beneficiaryRepo,
 	}
 }
 
 func (s *transactionService) Transfer(userID uuid.UUID, req *transaction.TransferRequest) (*transaction.Transaction, error) {
 	start := time.Now()
 
+	if err := req.Validate(); err != nil {
+		metrics.RecordTransactionError("transfer", "invalid_request")
+		return nil, err
+	}
+
 	// Validate transfer amount limits
 	if req.Amount < MinTransferAmount {
 		metrics.RecordTransactionError("transfer", "amount_below_minimum")
@@ -73,10 +95,9 @@ func (s *transactionService) Transfer(userID uuid.UUID, req *transaction.Transfe
 		return nil, fmt.Errorf("invalid from_account_id")
 	}
 
-	toAccountID, err := uuid.Parse(req.ToAccountID)
+	toAccountID, payee, err := s.resolveTransferDestination(userID, req)
 	if err != nil {
-		metrics.RecordTransactionError("transfer", "invalid_to_account")
-		return nil, fmt.Errorf("invalid to_account_id")
+		return nil, err
 	}
 
 	// Validate accounts are different
@@ -124,10 +145,25 @@ func (s *transactionService) Transfer(userID uuid.UUID, req *transaction.Transfe
 		return nil, fmt.Errorf("destination account is %s, cannot receive transfers", toAccount.Status)
 	}
 
-	// Validate currency match
-	if fromAccount.Currency != toAccount.Currency {
+	// The amount moves in the requested currency, by default the source
+	// account's own, which both accounts must hold
+	txnCurrency := req.Currency
+	if txnCurrency == "" {
+		txnCurrency = fromAccount.Currency
+	}
+	if err := s.checkHoldsCurrency(fromAccount, txnCurrency, "source account"); err != nil {
+		metrics.RecordTransactionError("transfer", "currency_mismatch")
+		return nil, err
+	}
+	if err := s.checkHoldsCurrency(toAccount, txnCurrency, "destination account"); err != nil {
 		metrics.RecordTransactionError("transfer", "currency_mismatch")
-		return nil, fmt.Errorf("currency mismatch: source account is %s, destination is %s", fromAccount.Currency, toAccount.Currency)
+		return nil, err
+	}
+
+	// Amounts in currencies without minor units must be whole
+	if err := validation.New().AmountIn("amount", req.Amount, txnCurrency).Err(); err != nil {
+		metrics.RecordTransactionError("transfer", "invalid_request")
+		return nil, err
 	}
 
 	// Create transaction object
@@ -141,18 +177,22 @@ func (s *transactionService) Transfer(userID uuid.UUID, req *transaction.Transfe
 		Status:          transaction.TransactionStatusPending,
 		Description:     req.Description,
 		Metadata: map[string]interface{}{
-			"initiated_by": userID.String(),
-			"currency":     fromAccount.Currency,
+			"initiated_by":    userID.String(),
+			"currency":        txnCurrency,
+			"new_beneficiary": beneficiary.IsNewDestination(payee, start),
 		},
 	}
+	if payee != nil {
+		txn.Metadata["beneficiary_id"] = payee.ID.String()
+	}
 
 	// Execute transfer with ACID guarantees
 	err = s.transactionRepo.ExecuteTransfer(fromAccountID, toAccountID, req.Amount, txn)
 	if err != nil {
 		// Record failed transaction
 		duration := time.Since(start).Seconds()
-		metrics.RecordTransaction("transfer", "failed", req.Amount, fromAccount.Currency, duration)
-		metrics.RecordTransactionError("transfer", "execution_failed")
+		metrics.RecordTransaction("transfer", "failed", req.Amount, txnCurrency, duration)
+		metrics.RecordTransactionError("transfer", executionFailure(err))
 
 		// Log failed transaction attempt
 		if errAudit := s.auditRepo.Create(&audit.AuditLog{
@@ -161,21 +201,19 @@ func (s *transactionService) Transfer(userID uuid.UUID, req *transaction.Transfe
 			Action:   "TRANSFER_FAILED",
 			Resource: fmt.Sprintf("transaction:%s", txn.ID),
 			Status:   "failed",
-			Metadata: map[string]interface{}{
-				"error":  err.Error(),
-				"amount": req.Amount,
-				"from":   req.FromAccountID,
-				"to":     req.ToAccountID,
-			},
+			Metadata: transferAuditMetadata(req, toAccountID, map[string]interface{}{
+				"error": err.Error(),
+			}),
 		}); errAudit != nil {
 			logger.Error("Failed to create audit log for failed transfer", zap.Error(errAudit))
+			errtrack.CaptureError(errAudit, map[string]string{"component": "transaction_service", "operation": "audit_log"})
 		}
 		return nil, err
 	}
 
 	// Record successful transaction
 	duration := time.Since(start).Seconds()
-	metrics.RecordTransaction("transfer", "completed", req.Amount, fromAccount.Currency, duration)
+	metrics.RecordTransaction("transfer", "completed", req.Amount, txnCurrency, duration)
 
 	// Log successful transaction
 	if err := s.auditRepo.Create(&audit.AuditLog{
@@ -184,22 +222,146 @@ func (s *transactionService) Transfer(userID uuid.UUID, req *transaction.Transfe
 		Action:   "TRANSFER_COMPLETED",
 		Resource: fmt.Sprintf("transaction:%s", txn.ID),
 		Status:   "success",
-		Metadata: map[string]interface{}{
-			"amount": req.Amount,
-			"from":   req.FromAccountID,
-			"to":     req.ToAccountID,
-		},
+		Metadata: transferAuditMetadata(req, toAccountID, nil),
 	}); err != nil {
 		logger.Error("Failed to create audit log for completed transfer", zap.Error(err))
+		errtrack.CaptureError(err, map[string]string{"component": "transaction_service", "operation": "audit_log"})
+	}
+
+	if payee != nil {
+		if err := s.beneficiaryRepo.MarkUsed(payee.ID, start); err != nil {
+			logger.Warn("Failed to mark beneficiary used", zap.String("beneficiary_id", payee.ID.String()), zap.Error(err))
+		}
 	}
 
 	// Retrieve the completed transaction
 	return s.transactionRepo.GetByID(txn.ID)
 }
 
+// checkHoldsCurrency fails unless the account has a balance in the currency:
+// its own, or another the customer opened. name is how the error refers to
+// the account.
+func (s *transactionService) checkHoldsCurrency(acc *account.Account, code, name string) error {
+	if acc.Currency == code {
+		return nil
+	}
+	balances, err := s.accountRepo.ListBalances(acc.ID)
+	if err != nil {
+		return err
+	}
+	for _, b := range balances {
+		if b.Currency == code {
+			return nil
+		}
+	}
+	return fmt.Errorf("currency mismatch: %s does not hold %s", name, code)
+}
+
+// resolveTransferDestination returns the destination account of a transfer and
+// the saved beneficiary it goes to, or nil when the destination is not in the
+// user's payee directory
+// transferAuditMetadata describes a transfer for its audit log, with the
+// request's country when GeoIP resolved one
+func transferAuditMetadata(req *transaction.TransferRequest, toAccountID uuid.UUID, extra map[string]interface{}) map[string]interface{} {
+	metadata := map[string]interface{}{
+		"amount": req.Amount,
+		"from":   req.FromAccountID,
+		"to":     toAccountID.String(),
+	}
+	if req.Country != "" {
+		metadata["country"] = req.Country
+	}
+	for k, v := range extra {
+		metadata[k] = v
+	}
+	return metadata
+}
+
+func (s *transactionService) resolveTransferDestination(userID uuid.UUID, req *transaction.TransferRequest) (uuid.UUID, *beneficiary.Beneficiary, error) {
+	if req.BeneficiaryID == "" && req.ToHandle != "" {
+		if req.ToAccountID != "" {
+			metrics.RecordTransactionError("transfer", "ambiguous_destination")
+			return uuid.Nil, nil, fmt.Errorf("use either to_account_id or to_handle")
+		}
+		toAccountID, err := s.resolveHandle(req.ToHandle)
+		if err != nil {
+			metrics.RecordTransactionError("transfer", "handle_not_found")
+			return uuid.Nil, nil, err
+		}
+		payee, err := s.beneficiaryRepo.GetByAccountID(userID, toAccountID)
+		if err != nil {
+			payee = nil // not saved; flagged as a new beneficiary
+		}
+		return toAccountID, payee, nil
+	}
+	if req.BeneficiaryID == "" {
+		toAccountID, err := uuid.Parse(req.ToAccountID)
+		if err != nil {
+			metrics.RecordTransactionError("transfer", "invalid_to_account")
+			return uuid.Nil, nil, fmt.Errorf("invalid to_account_id")
+		}
+		payee, err := s.beneficiaryRepo.GetByAccountID(userID, toAccountID)
+		if err != nil {
+			payee = nil // not saved; flagged as a new beneficiary
+		}
+		return toAccountID, payee, nil
+	}
+
+	beneficiaryID, err := uuid.Parse(req.BeneficiaryID)
+	if err != nil {
+		metrics.RecordTransactionError("transfer", "invalid_beneficiary")
+		return uuid.Nil, nil, fmt.Errorf("invalid beneficiary_id")
+	}
+	payee, err := s.beneficiaryRepo.GetByID(beneficiaryID)
+	if err != nil || payee.UserID != userID {
+		metrics.RecordTransactionError("transfer", "beneficiary_not_found")
+		return uuid.Nil, nil, fmt.Errorf("beneficiary not found")
+	}
+	if payee.Type == beneficiary.TypeExternalBank {
+		metrics.RecordTransactionError("transfer", "beneficiary_not_transferable")
+		return uuid.Nil, nil, fmt.Errorf("transfers to other banks are not supported yet")
+	}
+	if !payee.Transferable() {
+		metrics.RecordTransactionError("transfer", "beneficiary_not_transferable")
+		return uuid.Nil, nil, fmt.Errorf("beneficiary verification is %s, verify it before transferring", payee.VerificationStatus)
+	}
+	if req.ToAccountID != "" && req.ToAccountID != payee.AccountID.String() {
+		metrics.RecordTransactionError("transfer", "beneficiary_mismatch")
+		return uuid.Nil, nil, fmt.Errorf("to_account_id does not match the beneficiary")
+	}
+
+	return *payee.AccountID, payee, nil
+}
+
+// resolveHandle finds the account that receives payments to an @handle
+func (s *transactionService) resolveHandle(handle string) (uuid.UUID, error) {
+	username, err := user.NormalizeUsername(handle)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("no customer with this handle")
+	}
+	payee, err := s.userRepo.GetByUsername(username)
+	if err != nil || !payee.IsActive {
+		return uuid.Nil, fmt.Errorf("no customer with this handle")
+	}
+	accounts, err := s.accountRepo.GetByUserID(payee.ID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to get accounts: %w", err)
+	}
+	acct := account.ReceivingAccount(accounts)
+	if acct == nil {
+		return uuid.Nil, fmt.Errorf("customer has no active account to receive transfers")
+	}
+	return acct.ID, nil
+}
+
 func (s *transactionService) Deposit(userID uuid.UUID, req *transaction.DepositRequest) (*transaction.Transaction, error) {
 	start := time.Now()
 
+	if err := req.Validate(); err != nil {
+		metrics.RecordTransactionError("deposit", "invalid_request")
+		return nil, err
+	}
+
 	accountID, err := uuid.Parse(req.AccountID)
 	if err != nil {
 		metrics.RecordTransactionError("deposit", "invalid_account")
@@ -224,6 +386,18 @@ func (s *transactionService) Deposit(userID uuid.UUID, req *transaction.DepositR
 		metrics.RecordTransactionError("deposit", "unauthorized")
 		return nil, fmt.Errorf("unauthorized: account does not belong to user")
 	}
+	txnCurrency := req.Currency
+	if txnCurrency == "" {
+		txnCurrency = account.Currency
+	}
+	if err := s.checkHoldsCurrency(account, txnCurrency, "account"); err != nil {
+		metrics.RecordTransactionError("deposit", "currency_mismatch")
+		return nil, err
+	}
+	if err := validation.New().AmountIn("amount", req.Amount, txnCurrency).Err(); err != nil {
+		metrics.RecordTransactionError("deposit", "invalid_request")
+		return nil, err
+	}
 
 	// Create transaction
 	txn := &transaction.Transaction{
@@ -236,7 +410,7 @@ func (s *transactionService) Deposit(userID uuid.UUID, req *transaction.DepositR
 		Description:     req.Description,
 		Metadata: map[string]interface{}{
 			"initiated_by": userID.String(),
-			"currency":     account.Currency,
+			"currency":     txnCurrency,
 		},
 	}
 
@@ -244,7 +418,7 @@ func (s *transactionService) Deposit(userID uuid.UUID, req *transaction.DepositR
 	err = s.transactionRepo.ExecuteDeposit(accountID, req.Amount, txn)
 	if err != nil {
 		duration := time.Since(start).Seconds()
-		metrics.RecordTransaction("deposit", "failed", req.Amount, account.Currency, duration)
+		metrics.RecordTransaction("deposit", "failed", req.Amount, txnCurrency, duration)
 		metrics.RecordTransactionError("deposit", "execution_failed")
 
 		if errAudit := s.auditRepo.Create(&audit.AuditLog{
@@ -259,12 +433,13 @@ func (s *transactionService) Deposit(userID uuid.UUID, req *transaction.DepositR
 			},
 		}); errAudit != nil {
 			logger.Error("Failed to create audit log for failed deposit", zap.Error(errAudit))
+			errtrack.CaptureError(errAudit, map[string]string{"component": "transaction_service", "operation": "audit_log"})
 		}
 		return nil, err
 	}
 
 	duration := time.Since(start).Seconds()
-	metrics.RecordTransaction("deposit", "completed", req.Amount, account.Currency, duration)
+	metrics.RecordTransaction("deposit", "completed", req.Amount, txnCurrency, duration)
 
 	if err := s.auditRepo.Create(&audit.AuditLog{
 		EventID:  uuid.New(),
@@ -277,6 +452,7 @@ func (s *transactionService) Deposit(userID uuid.UUID, req *transaction.DepositR
 		},
 	}); err != nil {
 		logger.Error("Failed to create audit log for completed deposit", zap.Error(err))
+		errtrack.CaptureError(err, map[string]string{"component": "transaction_service", "operation": "audit_log"})
 	}
 
 	return s.transactionRepo.GetByID(txn.ID)
@@ -285,6 +461,11 @@ func (s *transactionService) Deposit(userID uuid.UUID, req *transaction.DepositR
 func (s *transactionService) Withdrawal(userID uuid.UUID, req *transaction.WithdrawalRequest) (*transaction.Transaction, error) {
 	start := time.Now()
 
+	if err := req.Validate(); err != nil {
+		metrics.RecordTransactionError("withdrawal", "invalid_request")
+		return nil, err
+	}
+
 	// Validate withdrawal amount limits
 	if req.Amount < MinWithdrawalAmount {
 		metrics.RecordTransactionError("withdrawal", "amount_below_minimum")
@@ -325,6 +506,18 @@ func (s *transactionService) Withdrawal(userID uuid.UUID, req *transaction.Withd
 		metrics.RecordTransactionError("withdrawal", "account_not_active")
 		return nil, fmt.Errorf("account is %s, cannot perform withdrawals", acct.Status)
 	}
+	txnCurrency := req.Currency
+	if txnCurrency == "" {
+		txnCurrency = acct.Currency
+	}
+	if err := s.checkHoldsCurrency(acct, txnCurrency, "account"); err != nil {
+		metrics.RecordTransactionError("withdrawal", "currency_mismatch")
+		return nil, err
+	}
+	if err := validation.New().AmountIn("amount", req.Amount, txnCurrency).Err(); err != nil {
+		metrics.RecordTransactionError("withdrawal", "invalid_request")
+		return nil, err
+	}
 
 	// Create transaction
 	txn := &transaction.Transaction{
@@ -337,7 +530,7 @@ func (s *transactionService) Withdrawal(userID uuid.UUID, req *transaction.Withd
 		Description:     req.Description,
 		Metadata: map[string]interface{}{
 			"initiated_by": userID.String(),
-			"currency":     acct.Currency,
+			"currency":     txnCurrency,
 		},
 	}
 
@@ -345,8 +538,8 @@ func (s *transactionService) Withdrawal(userID uuid.UUID, req *transaction.Withd
 	err = s.transactionRepo.ExecuteWithdrawal(accountID, req.Amount, txn)
 	if err != nil {
 		duration := time.Since(start).Seconds()
-		metrics.RecordTransaction("withdrawal", "failed", req.Amount, acct.Currency, duration)
-		metrics.RecordTransactionError("withdrawal", "execution_failed")
+		metrics.RecordTransaction("withdrawal", "failed", req.Amount, txnCurrency, duration)
+		metrics.RecordTransactionError("withdrawal", executionFailure(err))
 
 		if errAudit := s.auditRepo.Create(&audit.AuditLog{
 			EventID:  uuid.New(),
@@ -360,12 +553,13 @@ func (s *transactionService) Withdrawal(userID uuid.UUID, req *transaction.Withd
 			},
 		}); errAudit != nil {
 			logger.Error("Failed to create audit log for failed withdrawal", zap.Error(errAudit))
+			errtrack.CaptureError(errAudit, map[string]string{"component": "transaction_service", "operation": "audit_log"})
 		}
 		return nil, err
 	}
 
 	duration := time.Since(start).Seconds()
-	metrics.RecordTransaction("withdrawal", "completed", req.Amount, acct.Currency, duration)
+	metrics.RecordTransaction("withdrawal", "completed", req.Amount, txnCurrency, duration)
 
 	if err := s.auditRepo.Create(&audit.AuditLog{
 		EventID:  uuid.New(),
@@ -378,6 +572,7 @@ func (s *transactionService) Withdrawal(userID uuid.UUID, req *transaction.Withd
 		},
 	}); err != nil {
 		logger.Error("Failed to create audit log for completed withdrawal", zap.Error(err))
+		errtrack.CaptureError(err, map[string]string{"component": "transaction_service", "operation": "audit_log"})
 	}
 
 	return s.transactionRepo.GetByID(txn.ID)
@@ -438,7 +633,123 @@ func (s *transactionService) GetTransactionHistory(userID uuid.UUID, req *transa
 		return nil, err
 	}
 
-	// Convert to response format
+	total, err := s.transactionRepo.CountByAccountID(accountID, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	txnResponses := s.withCounterparties(userID, []uuid.UUID{accountID}, transactions)
+
+	return &transaction.TransactionHistoryResponse{
+		Transactions: txnResponses,
+		Total:        total,
+		Limit:        limit,
+		Offset:       offset,
+		HasMore:      offset+len(txnResponses) < total,
+	}, nil
+}
+
+func (s *transactionService) SearchTransactions(userID uuid.UUID, req *transaction.SearchRequest) (*transaction.TransactionHistoryResponse, error) {
+	var accountIDs []uuid.UUID
+	if req.AccountID != "" {
+		accountID, err := uuid.Parse(req.AccountID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid account_id")
+		}
+		account, err := s.accountRepo.GetByID(accountID)
+		if err != nil {
+			return nil, fmt.Errorf("account not found")
+		}
+		if account.UserID != userID {
+			return nil, fmt.Errorf("unauthorized: account does not belong to user")
+		}
+		accountIDs = []uuid.UUID{accountID}
+	} else {
+		accounts, err := s.accountRepo.GetByUserID(userID)
+		if err != nil {
+			return nil, err
+		}
+		for _, acct := range accounts {
+			accountIDs = append(accountIDs, acct.ID)
+		}
+	}
+
+	filter, err := req.Filter(accountIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, err := s.transactionRepo.Search(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := s.transactionRepo.CountSearch(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	txnResponses := s.withCounterparties(userID, accountIDs, transactions)
+
+	return &transaction.TransactionHistoryResponse{
+		Transactions: txnResponses,
+		Total:        total,
+		Limit:        filter.Limit,
+		Offset:       filter.Offset,
+		HasMore:      filter.Offset+len(txnResponses) < total,
+	}, nil
+}
+
+// GetArchivedHistory returns an account's transactions from months that were
+// moved to cold storage, newest first
+func (s *transactionService) GetArchivedHistory(userID uuid.UUID, req *transaction.ArchivedHistoryRequest) (*transaction.TransactionHistoryResponse, error) {
+	accountID, err := uuid.Parse(req.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account_id")
+	}
+
+	// Verify account ownership
+	account, err := s.accountRepo.GetByID(accountID)
+	if err != nil {
+		return nil, fmt.Errorf("account not found")
+	}
+	if account.UserID != userID {
+		return nil, fmt.Errorf("unauthorized: account does not belong to user")
+	}
+
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start_date, expected YYYY-MM-DD")
+	}
+	endDate, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end_date, expected YYYY-MM-DD")
+	}
+	if endDate.Before(startDate) {
+		return nil, fmt.Errorf("end_date must not be before start_date")
+	}
+	if endDate.Sub(startDate) >= transaction.MaxArchivedHistoryDays*24*time.Hour {
+		return nil, fmt.Errorf("date range must not exceed %d days", transaction.MaxArchivedHistoryDays)
+	}
+
+	transactions, err := s.archiveRepo.ListArchived(context.Background(), accountID, startDate, endDate.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(transactions, func(i, j int) bool {
+		return transactions[i].CreatedAt.After(transactions[j].CreatedAt)
+	})
+
+	txnResponses := s.withCounterparties(userID, []uuid.UUID{accountID}, transactions)
+
+	return &transaction.TransactionHistoryResponse{
+		Transactions: txnResponses,
+		Total:        len(txnResponses),
+		Limit:        len(txnResponses),
+	}, nil
+}
+
+func toTransactionResponses(transactions []*transaction.Transaction) []transaction.TransactionResponse {
 	txnResponses := make([]transaction.TransactionResponse, len(transactions))
 	for i, txn := range transactions {
 		txnResponses[i] = transaction.TransactionResponse{
@@ -453,13 +764,68 @@ func (s *transactionService) GetTransactionHistory(userID uuid.UUID, req *transa
 			CompletedAt:     txn.CompletedAt,
 		}
 	}
+	return txnResponses
+}
 
-	return &transaction.TransactionHistoryResponse{
-		Transactions: txnResponses,
-		Total:        len(txnResponses),
-		Limit:        limit,
-		Offset:       offset,
-	}, nil
+// withCounterparties converts transactions listed for the viewed accounts to
+// responses naming the other side of each. The holders are loaded in one
+// query; if that fails the history is still returned, without them.
+func (s *transactionService) withCounterparties(userID uuid.UUID, viewed []uuid.UUID, transactions []*transaction.Transaction) []transaction.TransactionResponse {
+	txnResponses := toTransactionResponses(transactions)
+
+	isViewed := make(map[uuid.UUID]bool, len(viewed))
+	for _, id := range viewed {
+		isViewed[id] = true
+	}
+
+	// The counterparty of a debit is its destination, of a credit its source
+	counterpartyIDs := make([]*uuid.UUID, len(transactions))
+	ids := []uuid.UUID{}
+	seen := map[uuid.UUID]bool{}
+	for i, txn := range transactions {
+		id := txn.ToAccountID
+		if txn.FromAccountID == nil || !isViewed[*txn.FromAccountID] {
+			id = txn.FromAccountID
+		}
+		if id == nil {
+			continue
+		}
+		counterpartyIDs[i] = id
+		if !seen[*id] {
+			seen[*id] = true
+			ids = append(ids, *id)
+		}
+	}
+	if len(ids) == 0 {
+		return txnResponses
+	}
+
+	holders, err := s.accountRepo.GetHolders(ids)
+	if err != nil {
+		logger.Warn("Failed to load transaction counterparties", zap.Error(err))
+		return txnResponses
+	}
+	byID := make(map[uuid.UUID]*transaction.Counterparty, len(holders))
+	for _, h := range holders {
+		cp := &transaction.Counterparty{
+			AccountID:     h.AccountID,
+			AccountNumber: transaction.MaskAccountNumber(h.AccountNumber),
+			HolderName:    transaction.ShortHolderName(h.FirstName, h.LastName),
+		}
+		if h.UserID == userID {
+			cp.Own = true
+			cp.AccountNumber = h.AccountNumber
+			cp.HolderName = h.FirstName + " " + h.LastName
+		}
+		byID[h.AccountID] = cp
+	}
+
+	for i, id := range counterpartyIDs {
+		if id != nil {
+			txnResponses[i].Counterparty = byID[*id]
+		}
+	}
+	return txnResponses
 }
 
 func (s *transactionService) GetTransaction(userID uuid.UUID, transactionID uuid.UUID) (*transaction.Transaction, error) {
@@ -469,16 +835,19 @@ func (s *transactionService) GetTransaction(userID uuid.UUID, transactionID uuid
 	}
 
 	// Verify user has access to this transaction
-	var hasAccess bool
-	if txn.FromAccountID != nil {
-		fromAccount, err := s.accountRepo.GetByID(*txn.FromAccountID)
-		if err == nil && fromAccount.UserID == userID {
-			hasAccess = true
+	var ids []uuid.UUID
+	for _, id := range []*uuid.UUID{txn.FromAccountID, txn.ToAccountID} {
+		if id != nil {
+			ids = append(ids, *id)
 		}
 	}
-	if txn.ToAccountID != nil {
-		toAccount, err := s.accountRepo.GetByID(*txn.ToAccountID)
-		if err == nil && toAccount.UserID == userID {
+	accounts, err := s.accountRepo.GetByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	var hasAccess bool
+	for _, acct := range accounts {
+		if acct.UserID == userID {
 			hasAccess = true
 		}
 	}
@@ -520,3 +889,11 @@ func (s *transactionService) ResolveQR(qrCode string) (*transaction.QRResolution
 		Currency:  account.Currency,
 	}, nil
 }
+
+// executionFailure is the metrics error type of a failed debit
+func executionFailure(err error) string {
+	if errors.Is(err, transaction.ErrInsufficientFunds) {
+		return "insufficient_funds"
+	}
+	return "execution_failed"
+}
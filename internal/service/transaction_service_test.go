@@ -1,18 +1,23 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	domainAccount "github.com/darisadam/madabank-server/internal/domain/account"
 	"github.com/darisadam/madabank-server/internal/domain/audit"
+	"github.com/darisadam/madabank-server/internal/domain/beneficiary"
 	"github.com/darisadam/madabank-server/internal/domain/transaction"
 	"github.com/darisadam/madabank-server/internal/domain/user"
+	"github.com/darisadam/madabank-server/internal/domain/validation"
 	"github.com/darisadam/madabank-server/internal/pkg/logger"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockTransactionRepository is a mock implementation
@@ -49,6 +54,40 @@ func (m *MockTransactionRepository) GetByAccountIDWithFilters(accountID uuid.UUI
 	return args.Get(0).([]*transaction.Transaction), args.Error(1)
 }
 
+func (m *MockTransactionRepository) ListCompleted(from, to time.Time) ([]*transaction.Transaction, error) {
+	args := m.Called(from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*transaction.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionRepository) ListCompletedByAccount(accountID uuid.UUID, from, to time.Time) ([]*transaction.Transaction, error) {
+	args := m.Called(accountID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*transaction.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionRepository) CountByAccountID(accountID uuid.UUID, filters map[string]interface{}) (int, error) {
+	args := m.Called(accountID, filters)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockTransactionRepository) CountSearch(f *transaction.SearchFilter) (int, error) {
+	args := m.Called(f)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockTransactionRepository) Search(f *transaction.SearchFilter) ([]*transaction.Transaction, error) {
+	args := m.Called(f)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*transaction.Transaction), args.Error(1)
+}
+
 func (m *MockTransactionRepository) GetByIdempotencyKey(key string) (*transaction.Transaction, error) {
 	args := m.Called(key)
 	if args.Get(0) == nil {
@@ -77,6 +116,107 @@ func (m *MockTransactionRepository) UpdateStatus(id uuid.UUID, status transactio
 	return args.Error(0)
 }
 
+// MockAccountRepository is a mock implementation of repository.AccountRepository
+type MockAccountRepository struct {
+	mock.Mock
+}
+
+func (m *MockAccountRepository) Create(a *domainAccount.Account) error {
+	args := m.Called(a)
+	return args.Error(0)
+}
+
+func (m *MockAccountRepository) GetByID(id uuid.UUID) (*domainAccount.Account, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainAccount.Account), args.Error(1)
+}
+
+func (m *MockAccountRepository) GetByIDs(ids []uuid.UUID) ([]*domainAccount.Account, error) {
+	args := m.Called(ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domainAccount.Account), args.Error(1)
+}
+
+func (m *MockAccountRepository) GetByAccountNumber(accountNumber string) (*domainAccount.Account, error) {
+	args := m.Called(accountNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainAccount.Account), args.Error(1)
+}
+
+func (m *MockAccountRepository) GetByUserID(userID uuid.UUID) ([]*domainAccount.Account, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domainAccount.Account), args.Error(1)
+}
+
+func (m *MockAccountRepository) ListByUser(f *domainAccount.ListFilter) ([]*domainAccount.Account, error) {
+	args := m.Called(f)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domainAccount.Account), args.Error(1)
+}
+
+func (m *MockAccountRepository) CountByUser(f *domainAccount.ListFilter) (int, error) {
+	args := m.Called(f)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockAccountRepository) GetHolders(ids []uuid.UUID) ([]*domainAccount.Holder, error) {
+	args := m.Called(ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domainAccount.Holder), args.Error(1)
+}
+
+func (m *MockAccountRepository) Update(id uuid.UUID, version int, updates map[string]interface{}) error {
+	args := m.Called(id, version, updates)
+	return args.Error(0)
+}
+
+func (m *MockAccountRepository) Delete(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockAccountRepository) GenerateAccountNumber() (string, error) {
+	args := m.Called()
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockAccountRepository) UpdateBalance(id uuid.UUID, version int, amount float64) error {
+	args := m.Called(id, version, amount)
+	return args.Error(0)
+}
+
+func (m *MockAccountRepository) AddCurrency(accountID uuid.UUID, currency string) error {
+	args := m.Called(accountID, currency)
+	return args.Error(0)
+}
+
+func (m *MockAccountRepository) ListBalances(accountID uuid.UUID) ([]*domainAccount.CurrencyBalance, error) {
+	args := m.Called(accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domainAccount.CurrencyBalance), args.Error(1)
+}
+
+func (m *MockAccountRepository) SumActiveHolds(accountID uuid.UUID) (float64, error) {
+	args := m.Called(accountID)
+	return args.Get(0).(float64), args.Error(1)
+}
+
 // MockAuditRepository is a mock implementation
 type MockAuditRepository struct {
 	mock.Mock
@@ -95,14 +235,84 @@ func (m *MockAuditRepository) GetByUserID(userID uuid.UUID, limit, offset int) (
 	return args.Get(0).([]*audit.AuditLog), args.Error(1)
 }
 
+func (m *MockAuditRepository) ListOlderThan(cutoff time.Time, limit int) ([]*audit.AuditLog, error) {
+	args := m.Called(cutoff, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*audit.AuditLog), args.Error(1)
+}
+
+func (m *MockAuditRepository) ListChain(afterID int64, limit int) ([]*audit.AuditLog, error) {
+	args := m.Called(afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*audit.AuditLog), args.Error(1)
+}
+
+func (m *MockAuditRepository) DeleteOlderThan(cutoff time.Time, maxID int64) (int64, error) {
+	args := m.Called(cutoff, maxID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// MockTransactionArchiveRepository is a mock implementation
+type MockTransactionArchiveRepository struct {
+	mock.Mock
+}
+
+func (m *MockTransactionArchiveRepository) EnsurePartitions(from time.Time, months int) error {
+	args := m.Called(from, months)
+	return args.Error(0)
+}
+
+func (m *MockTransactionArchiveRepository) ListPartitionMonths() ([]time.Time, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]time.Time), args.Error(1)
+}
+
+func (m *MockTransactionArchiveRepository) ListPartition(month time.Time) ([]*transaction.Transaction, error) {
+	args := m.Called(month)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*transaction.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionArchiveRepository) ArchivePartition(archive *transaction.Archive) error {
+	args := m.Called(archive)
+	return args.Error(0)
+}
+
+func (m *MockTransactionArchiveRepository) ListArchives(from, to time.Time) ([]*transaction.Archive, error) {
+	args := m.Called(from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*transaction.Archive), args.Error(1)
+}
+
+func (m *MockTransactionArchiveRepository) ListArchived(ctx context.Context, accountID uuid.UUID, from, to time.Time) ([]*transaction.Transaction, error) {
+	args := m.Called(ctx, accountID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*transaction.Transaction), args.Error(1)
+}
+
 func setupTransactionServiceTest(t *testing.T) (*transactionService, *MockTransactionRepository, *MockAccountRepository, *MockAuditRepository, *MockUserRepository) {
 	logger.Init("test")
 	txnRepo := new(MockTransactionRepository)
 	accountRepo := new(MockAccountRepository)
 	auditRepo := new(MockAuditRepository)
 	userRepo := new(MockUserRepository)
+	beneficiaryRepo := new(MockBeneficiaryRepository)
+	beneficiaryRepo.On("GetByAccountID", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("beneficiary not found")).Maybe()
 
-	svc := NewTransactionService(txnRepo, accountRepo, auditRepo, userRepo).(*transactionService)
+	svc := NewTransactionService(txnRepo, accountRepo, auditRepo, userRepo, new(MockTransactionArchiveRepository), beneficiaryRepo).(*transactionService)
 	return svc, txnRepo, accountRepo, auditRepo, userRepo
 }
 
@@ -120,6 +330,7 @@ func TestTransfer_Success(t *testing.T) {
 		Amount:         100.00,
 		Description:    "Test transfer",
 		IdempotencyKey: "test-key-123",
+		Country:        "ID",
 	}
 
 	// Mock idempotency check (not found)
@@ -145,8 +356,10 @@ func TestTransfer_Success(t *testing.T) {
 	// Mock execute transfer
 	txnRepo.On("ExecuteTransfer", fromAccountID, toAccountID, 100.00, mock.AnythingOfType("*transaction.Transaction")).Return(nil)
 
-	// Mock audit log
-	auditRepo.On("Create", mock.AnythingOfType("*audit.AuditLog")).Return(nil)
+	// Mock audit log, which records where the request came from
+	auditRepo.On("Create", mock.MatchedBy(func(l *audit.AuditLog) bool {
+		return l.Action == "TRANSFER_COMPLETED" && l.Metadata["country"] == "ID"
+	})).Return(nil)
 
 	// Mock get result
 	completedTxn := &transaction.Transaction{
@@ -164,6 +377,146 @@ func TestTransfer_Success(t *testing.T) {
 	assert.NotNil(t, result)
 	assert.Equal(t, 100.00, result.Amount)
 	txnRepo.AssertExpectations(t)
+	auditRepo.AssertExpectations(t)
+}
+
+func TestTransfer_ToSavedBeneficiary(t *testing.T) {
+	logger.Init("test")
+	txnRepo := new(MockTransactionRepository)
+	accountRepo := new(MockAccountRepository)
+	auditRepo := new(MockAuditRepository)
+	beneficiaryRepo := new(MockBeneficiaryRepository)
+	svc := NewTransactionService(txnRepo, accountRepo, auditRepo, new(MockUserRepository), new(MockTransactionArchiveRepository), beneficiaryRepo)
+
+	userID := uuid.New()
+	fromAccountID := uuid.New()
+	toAccountID := uuid.New()
+	payee := &beneficiary.Beneficiary{
+		ID: uuid.New(), UserID: userID, Type: beneficiary.TypePhone, AccountID: &toAccountID,
+		VerificationStatus: beneficiary.VerificationVerified, CreatedAt: time.Now().AddDate(0, -1, 0),
+	}
+	req := &transaction.TransferRequest{
+		FromAccountID:  fromAccountID.String(),
+		BeneficiaryID:  payee.ID.String(),
+		Amount:         100.00,
+		IdempotencyKey: "beneficiary-key",
+	}
+
+	beneficiaryRepo.On("GetByID", payee.ID).Return(payee, nil)
+	beneficiaryRepo.On("MarkUsed", payee.ID, mock.Anything).Return(nil)
+	txnRepo.On("GetByIdempotencyKey", req.IdempotencyKey).Return(nil, fmt.Errorf("not found"))
+	accountRepo.On("GetByID", fromAccountID).Return(&domainAccount.Account{
+		ID: fromAccountID, UserID: userID, Currency: "IDR", Status: domainAccount.AccountStatusActive,
+	}, nil)
+	accountRepo.On("GetByID", toAccountID).Return(&domainAccount.Account{
+		ID: toAccountID, UserID: uuid.New(), Currency: "IDR", Status: domainAccount.AccountStatusActive,
+	}, nil)
+	txnRepo.On("ExecuteTransfer", fromAccountID, toAccountID, 100.00, mock.MatchedBy(func(txn *transaction.Transaction) bool {
+		return txn.Metadata["new_beneficiary"] == false && txn.Metadata["beneficiary_id"] == payee.ID.String()
+	})).Return(nil)
+	auditRepo.On("Create", mock.Anything).Return(nil)
+	txnRepo.On("GetByID", mock.AnythingOfType("uuid.UUID")).Return(&transaction.Transaction{ID: uuid.New()}, nil)
+
+	_, err := svc.Transfer(userID, req)
+
+	assert.NoError(t, err)
+	txnRepo.AssertExpectations(t)
+	beneficiaryRepo.AssertExpectations(t)
+}
+
+func TestTransfer_ToHandle(t *testing.T) {
+	svc, txnRepo, accountRepo, auditRepo, userRepo := setupTransactionServiceTest(t)
+	userID := uuid.New()
+	fromAccountID := uuid.New()
+	payee := &user.User{ID: uuid.New(), IsActive: true}
+	receiving := &domainAccount.Account{ID: uuid.New(), UserID: payee.ID, Currency: "IDR", Status: domainAccount.AccountStatusActive, CreatedAt: time.Now().AddDate(-1, 0, 0)}
+	newer := &domainAccount.Account{ID: uuid.New(), UserID: payee.ID, Currency: "IDR", Status: domainAccount.AccountStatusActive, CreatedAt: time.Now()}
+	req := &transaction.TransferRequest{
+		FromAccountID:  fromAccountID.String(),
+		ToHandle:       "@Siti.R",
+		Amount:         100.00,
+		IdempotencyKey: "handle-key",
+	}
+
+	userRepo.On("GetByUsername", "siti.r").Return(payee, nil)
+	accountRepo.On("GetByUserID", payee.ID).Return([]*domainAccount.Account{newer, receiving}, nil)
+	txnRepo.On("GetByIdempotencyKey", req.IdempotencyKey).Return(nil, fmt.Errorf("not found"))
+	accountRepo.On("GetByID", fromAccountID).Return(&domainAccount.Account{
+		ID: fromAccountID, UserID: userID, Currency: "IDR", Status: domainAccount.AccountStatusActive,
+	}, nil)
+	accountRepo.On("GetByID", receiving.ID).Return(receiving, nil)
+	txnRepo.On("ExecuteTransfer", fromAccountID, receiving.ID, 100.00, mock.Anything).Return(nil)
+	auditRepo.On("Create", mock.Anything).Return(nil)
+	txnRepo.On("GetByID", mock.AnythingOfType("uuid.UUID")).Return(&transaction.Transaction{ID: uuid.New()}, nil)
+
+	_, err := svc.Transfer(userID, req)
+
+	assert.NoError(t, err)
+	txnRepo.AssertExpectations(t)
+}
+
+func TestTransfer_ToUnknownHandle(t *testing.T) {
+	svc, txnRepo, _, _, userRepo := setupTransactionServiceTest(t)
+	userRepo.On("GetByUsername", "nobody").Return(nil, fmt.Errorf("user not found"))
+
+	_, err := svc.Transfer(uuid.New(), &transaction.TransferRequest{
+		FromAccountID:  uuid.New().String(),
+		ToHandle:       "@nobody",
+		Amount:         100.00,
+		IdempotencyKey: "unknown-handle-key",
+	})
+
+	assert.EqualError(t, err, "no customer with this handle")
+	txnRepo.AssertNotCalled(t, "ExecuteTransfer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTransfer_UnsavedDestinationFlaggedAsNewBeneficiary(t *testing.T) {
+	svc, txnRepo, accountRepo, auditRepo, _ := setupTransactionServiceTest(t)
+	userID := uuid.New()
+	fromAccountID := uuid.New()
+	toAccountID := uuid.New()
+	req := &transaction.TransferRequest{
+		FromAccountID:  fromAccountID.String(),
+		ToAccountID:    toAccountID.String(),
+		Amount:         100.00,
+		IdempotencyKey: "new-payee-key",
+	}
+
+	txnRepo.On("GetByIdempotencyKey", req.IdempotencyKey).Return(nil, fmt.Errorf("not found"))
+	accountRepo.On("GetByID", fromAccountID).Return(&domainAccount.Account{
+		ID: fromAccountID, UserID: userID, Currency: "IDR", Status: domainAccount.AccountStatusActive,
+	}, nil)
+	accountRepo.On("GetByID", toAccountID).Return(&domainAccount.Account{
+		ID: toAccountID, UserID: uuid.New(), Currency: "IDR", Status: domainAccount.AccountStatusActive,
+	}, nil)
+	txnRepo.On("ExecuteTransfer", fromAccountID, toAccountID, 100.00, mock.MatchedBy(func(txn *transaction.Transaction) bool {
+		_, saved := txn.Metadata["beneficiary_id"]
+		return txn.Metadata["new_beneficiary"] == true && !saved
+	})).Return(nil)
+	auditRepo.On("Create", mock.Anything).Return(nil)
+	txnRepo.On("GetByID", mock.AnythingOfType("uuid.UUID")).Return(&transaction.Transaction{ID: uuid.New()}, nil)
+
+	_, err := svc.Transfer(userID, req)
+
+	assert.NoError(t, err)
+	txnRepo.AssertExpectations(t)
+}
+
+func TestTransfer_UnverifiedBeneficiary(t *testing.T) {
+	svc, txnRepo, _, _, _ := setupTransactionServiceTest(t)
+	beneficiaryRepo := svc.beneficiaryRepo.(*MockBeneficiaryRepository)
+	userID := uuid.New()
+	payee := &beneficiary.Beneficiary{ID: uuid.New(), UserID: userID, Type: beneficiary.TypeInternalAccount, VerificationStatus: beneficiary.VerificationFailed}
+	beneficiaryRepo.On("GetByID", payee.ID).Return(payee, nil)
+
+	_, err := svc.Transfer(userID, &transaction.TransferRequest{
+		FromAccountID: uuid.New().String(),
+		BeneficiaryID: payee.ID.String(),
+		Amount:        100.00,
+	})
+
+	assert.EqualError(t, err, "beneficiary verification is failed, verify it before transferring")
+	txnRepo.AssertNotCalled(t, "ExecuteTransfer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 }
 
 func TestTransfer_Idempotency(t *testing.T) {
@@ -287,11 +640,83 @@ func TestTransfer_CurrencyMismatch(t *testing.T) {
 		Currency: "EUR", // Different currency
 		Status:   domainAccount.AccountStatusActive,
 	}, nil)
+	accountRepo.On("ListBalances", toAccountID).Return([]*domainAccount.CurrencyBalance{}, nil)
 
 	result, err := svc.Transfer(userID, req)
 	assert.Error(t, err)
 	assert.Nil(t, result)
-	assert.Contains(t, err.Error(), "currency mismatch")
+	assert.EqualError(t, err, "currency mismatch: destination account does not hold USD")
+}
+
+func TestTransfer_OtherCurrencyHeldByBoth(t *testing.T) {
+	svc, txnRepo, accountRepo, auditRepo, _ := setupTransactionServiceTest(t)
+	userID := uuid.New()
+	fromAccountID := uuid.New()
+	toAccountID := uuid.New()
+
+	req := &transaction.TransferRequest{
+		FromAccountID:  fromAccountID.String(),
+		ToAccountID:    toAccountID.String(),
+		Amount:         100.00,
+		Currency:       "USD",
+		IdempotencyKey: "key",
+	}
+
+	txnRepo.On("GetByIdempotencyKey", req.IdempotencyKey).Return(nil, fmt.Errorf("not found"))
+	accountRepo.On("GetByID", fromAccountID).Return(&domainAccount.Account{
+		ID: fromAccountID, UserID: userID, Currency: "IDR", Status: domainAccount.AccountStatusActive,
+	}, nil)
+	accountRepo.On("GetByID", toAccountID).Return(&domainAccount.Account{
+		ID: toAccountID, UserID: uuid.New(), Currency: "USD", Status: domainAccount.AccountStatusActive,
+	}, nil)
+	// The source holds USD besides its own IDR; USD is the destination's own
+	accountRepo.On("ListBalances", fromAccountID).Return([]*domainAccount.CurrencyBalance{
+		domainAccount.NewCurrencyBalance("USD", 250),
+	}, nil)
+	txnRepo.On("ExecuteTransfer", fromAccountID, toAccountID, 100.00, mock.MatchedBy(func(txn *transaction.Transaction) bool {
+		return txn.Currency() == "USD"
+	})).Return(nil)
+	auditRepo.On("Create", mock.Anything).Return(nil)
+	txnRepo.On("GetByID", mock.AnythingOfType("uuid.UUID")).Return(&transaction.Transaction{Amount: 100.00}, nil)
+
+	result, err := svc.Transfer(userID, req)
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	txnRepo.AssertExpectations(t)
+	accountRepo.AssertNotCalled(t, "ListBalances", toAccountID)
+}
+
+func TestTransfer_FractionOfWholeCurrency(t *testing.T) {
+	svc, txnRepo, accountRepo, _, _ := setupTransactionServiceTest(t)
+	userID := uuid.New()
+	fromAccountID := uuid.New()
+	toAccountID := uuid.New()
+
+	req := &transaction.TransferRequest{
+		FromAccountID:  fromAccountID.String(),
+		ToAccountID:    toAccountID.String(),
+		Amount:         1500.50,
+		IdempotencyKey: "key",
+	}
+
+	txnRepo.On("GetByIdempotencyKey", req.IdempotencyKey).Return(nil, fmt.Errorf("not found"))
+	accountRepo.On("GetByID", fromAccountID).Return(&domainAccount.Account{
+		ID:       fromAccountID,
+		UserID:   userID,
+		Currency: "JPY",
+		Status:   domainAccount.AccountStatusActive,
+	}, nil)
+	accountRepo.On("GetByID", toAccountID).Return(&domainAccount.Account{
+		ID:       toAccountID,
+		UserID:   uuid.New(),
+		Currency: "JPY",
+		Status:   domainAccount.AccountStatusActive,
+	}, nil)
+
+	result, err := svc.Transfer(userID, req)
+	assert.Nil(t, result)
+	assert.EqualError(t, err, "amount must have at most 0 decimal places")
+	txnRepo.AssertNotCalled(t, "ExecuteTransfer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 }
 
 // ==================== Deposit Tests ====================
@@ -423,11 +848,12 @@ func TestWithdrawal_InsufficientFunds(t *testing.T) {
 	}, nil)
 
 	// ExecuteWithdrawal returns an error
-	txnRepo.On("ExecuteWithdrawal", accountID, 2000.00, mock.AnythingOfType("*transaction.Transaction")).Return(fmt.Errorf("insufficient funds"))
+	txnRepo.On("ExecuteWithdrawal", accountID, 2000.00, mock.AnythingOfType("*transaction.Transaction")).
+		Return(fmt.Errorf("%w: have 500.00, need 2000.00", transaction.ErrInsufficientFunds))
 	auditRepo.On("Create", mock.AnythingOfType("*audit.AuditLog")).Return(nil)
 
 	result, err := svc.Withdrawal(userID, req)
-	assert.Error(t, err)
+	assert.ErrorIs(t, err, transaction.ErrInsufficientFunds)
 	assert.Nil(t, result)
 	assert.Contains(t, err.Error(), "insufficient funds")
 }
@@ -456,11 +882,122 @@ func TestGetTransactionHistory_Success(t *testing.T) {
 	}
 
 	txnRepo.On("GetByAccountID", accountID, 10, 0).Return(transactions, nil)
+	txnRepo.On("CountByAccountID", accountID, map[string]interface{}{}).Return(25, nil)
 
 	result, err := svc.GetTransactionHistory(userID, req)
 	assert.NoError(t, err)
 	assert.Len(t, result.Transactions, 2)
 	assert.Equal(t, 10, result.Limit)
+	assert.Equal(t, 25, result.Total)
+	assert.True(t, result.HasMore)
+}
+
+func TestSearchTransactions_AllAccountsOfUser(t *testing.T) {
+	svc, txnRepo, accountRepo, _, _ := setupTransactionServiceTest(t)
+	userID := uuid.New()
+	checking, savings := uuid.New(), uuid.New()
+
+	accountRepo.On("GetByUserID", userID).Return([]*domainAccount.Account{
+		{ID: checking, UserID: userID},
+		{ID: savings, UserID: userID},
+	}, nil)
+	txnRepo.On("Search", mock.MatchedBy(func(f *transaction.SearchFilter) bool {
+		return assert.ObjectsAreEqual([]uuid.UUID{checking, savings}, f.AccountIDs) &&
+			f.Query == "bob" && f.Status == transaction.TransactionStatusCompleted && f.MinAmount == 50000
+	})).Return([]*transaction.Transaction{
+		{ID: uuid.New(), Amount: 75000, TransactionType: transaction.TransactionTypeTransfer, Description: "Dinner with Bob"},
+	}, nil)
+	txnRepo.On("CountSearch", mock.Anything).Return(1, nil)
+
+	result, err := svc.SearchTransactions(userID, &transaction.SearchRequest{Query: "bob", Status: "completed", MinAmount: 50000})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Total)
+	assert.False(t, result.HasMore)
+	assert.Equal(t, 20, result.Limit)
+}
+
+func TestSearchTransactions_OtherUsersAccount(t *testing.T) {
+	svc, txnRepo, accountRepo, _, _ := setupTransactionServiceTest(t)
+	accountID := uuid.New()
+
+	accountRepo.On("GetByID", accountID).Return(&domainAccount.Account{ID: accountID, UserID: uuid.New()}, nil)
+
+	result, err := svc.SearchTransactions(uuid.New(), &transaction.SearchRequest{AccountID: accountID.String(), Query: "bob"})
+
+	assert.EqualError(t, err, "unauthorized: account does not belong to user")
+	assert.Nil(t, result)
+	txnRepo.AssertNotCalled(t, "Search", mock.Anything)
+}
+
+func TestGetTransactionHistory_Counterparties(t *testing.T) {
+	svc, txnRepo, accountRepo, _, _ := setupTransactionServiceTest(t)
+	userID := uuid.New()
+	accountID, savingsID, bobAccountID := uuid.New(), uuid.New(), uuid.New()
+
+	accountRepo.On("GetByID", accountID).Return(&domainAccount.Account{ID: accountID, UserID: userID}, nil)
+	transactions := []*transaction.Transaction{
+		{ID: uuid.New(), FromAccountID: &accountID, ToAccountID: &bobAccountID, Amount: 250000, TransactionType: transaction.TransactionTypeTransfer},
+		{ID: uuid.New(), FromAccountID: &bobAccountID, ToAccountID: &accountID, Amount: 50000, TransactionType: transaction.TransactionTypeTransfer},
+		{ID: uuid.New(), FromAccountID: &accountID, ToAccountID: &savingsID, Amount: 500, TransactionType: transaction.TransactionTypeRoundUp},
+		{ID: uuid.New(), ToAccountID: &accountID, Amount: 100000, TransactionType: transaction.TransactionTypeDeposit},
+	}
+	txnRepo.On("GetByAccountID", accountID, 20, 0).Return(transactions, nil)
+	txnRepo.On("CountByAccountID", accountID, map[string]interface{}{}).Return(len(transactions), nil)
+	// Bob's account is looked up once for both of his transactions
+	accountRepo.On("GetHolders", []uuid.UUID{bobAccountID, savingsID}).Return([]*domainAccount.Holder{
+		{AccountID: bobAccountID, UserID: uuid.New(), AccountNumber: "1000000042", FirstName: "Bob", LastName: "Hartono"},
+		{AccountID: savingsID, UserID: userID, AccountNumber: "1000000007", FirstName: "Budi", LastName: "Santoso"},
+	}, nil).Once()
+
+	result, err := svc.GetTransactionHistory(userID, &transaction.TransactionHistoryRequest{AccountID: accountID.String()})
+
+	assert.NoError(t, err)
+	bob := &transaction.Counterparty{AccountID: bobAccountID, AccountNumber: "******0042", HolderName: "Bob H."}
+	assert.Equal(t, bob, result.Transactions[0].Counterparty)
+	assert.Equal(t, bob, result.Transactions[1].Counterparty)
+	assert.Equal(t, &transaction.Counterparty{AccountID: savingsID, AccountNumber: "1000000007", HolderName: "Budi Santoso", Own: true}, result.Transactions[2].Counterparty)
+	assert.Nil(t, result.Transactions[3].Counterparty)
+	accountRepo.AssertExpectations(t)
+}
+
+func TestGetArchivedHistory_NewestFirst(t *testing.T) {
+	svc, _, accountRepo, _, _ := setupTransactionServiceTest(t)
+	archiveRepo := svc.archiveRepo.(*MockTransactionArchiveRepository)
+	userID := uuid.New()
+	accountID := uuid.New()
+
+	accountRepo.On("GetByID", accountID).Return(&domainAccount.Account{ID: accountID, UserID: userID}, nil)
+
+	older := &transaction.Transaction{ID: uuid.New(), Amount: 100, CreatedAt: time.Date(2023, 1, 5, 0, 0, 0, 0, time.UTC)}
+	newer := &transaction.Transaction{ID: uuid.New(), Amount: 50, CreatedAt: time.Date(2023, 2, 5, 0, 0, 0, 0, time.UTC)}
+	archiveRepo.On("ListArchived", mock.Anything, accountID,
+		time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC),
+	).Return([]*transaction.Transaction{older, newer}, nil)
+
+	result, err := svc.GetArchivedHistory(userID, &transaction.ArchivedHistoryRequest{
+		AccountID: accountID.String(), StartDate: "2023-01-01", EndDate: "2023-02-28",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Total)
+	assert.Equal(t, newer.ID, result.Transactions[0].ID)
+}
+
+func TestGetArchivedHistory_RangeTooLong(t *testing.T) {
+	svc, _, accountRepo, _, _ := setupTransactionServiceTest(t)
+	userID := uuid.New()
+	accountID := uuid.New()
+
+	accountRepo.On("GetByID", accountID).Return(&domainAccount.Account{ID: accountID, UserID: userID}, nil)
+
+	result, err := svc.GetArchivedHistory(userID, &transaction.ArchivedHistoryRequest{
+		AccountID: accountID.String(), StartDate: "2023-01-01", EndDate: "2023-12-31",
+	})
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Contains(t, err.Error(), "must not exceed")
 }
 
 func TestGetTransactionHistory_WithFilters(t *testing.T) {
@@ -486,6 +1023,7 @@ func TestGetTransactionHistory_WithFilters(t *testing.T) {
 	}
 
 	txnRepo.On("GetByAccountIDWithFilters", accountID, mock.AnythingOfType("map[string]interface {}"), 20, 0).Return(transactions, nil)
+	txnRepo.On("CountByAccountID", accountID, mock.AnythingOfType("map[string]interface {}")).Return(1, nil)
 
 	result, err := svc.GetTransactionHistory(userID, req)
 	assert.NoError(t, err)
@@ -508,6 +1046,7 @@ func TestGetTransactionHistory_LimitCap(t *testing.T) {
 	}, nil)
 
 	txnRepo.On("GetByAccountID", accountID, 100, 0).Return([]*transaction.Transaction{}, nil) // Capped at 100
+	txnRepo.On("CountByAccountID", accountID, map[string]interface{}{}).Return(0, nil)
 
 	result, err := svc.GetTransactionHistory(userID, req)
 	assert.NoError(t, err)
@@ -530,10 +1069,10 @@ func TestGetTransaction_Success_FromAccount(t *testing.T) {
 
 	txnRepo.On("GetByID", transactionID).Return(existingTxn, nil)
 
-	accountRepo.On("GetByID", fromAccountID).Return(&domainAccount.Account{
+	accountRepo.On("GetByIDs", []uuid.UUID{fromAccountID}).Return([]*domainAccount.Account{{
 		ID:     fromAccountID,
 		UserID: userID, // User owns this account
-	}, nil)
+	}}, nil)
 
 	result, err := svc.GetTransaction(userID, transactionID)
 	assert.NoError(t, err)
@@ -558,13 +1097,9 @@ func TestGetTransaction_Unauthorized(t *testing.T) {
 	txnRepo.On("GetByID", transactionID).Return(existingTxn, nil)
 
 	// Neither account belongs to user
-	accountRepo.On("GetByID", fromAccountID).Return(&domainAccount.Account{
-		ID:     fromAccountID,
-		UserID: otherUserID,
-	}, nil)
-	accountRepo.On("GetByID", toAccountID).Return(&domainAccount.Account{
-		ID:     toAccountID,
-		UserID: otherUserID,
+	accountRepo.On("GetByIDs", []uuid.UUID{fromAccountID, toAccountID}).Return([]*domainAccount.Account{
+		{ID: fromAccountID, UserID: otherUserID},
+		{ID: toAccountID, UserID: otherUserID},
 	}, nil)
 
 	result, err := svc.GetTransaction(userID, transactionID)
@@ -573,6 +1108,31 @@ func TestGetTransaction_Unauthorized(t *testing.T) {
 	assert.Contains(t, err.Error(), "unauthorized")
 }
 
+func TestGetTransaction_Success_ToAccount(t *testing.T) {
+	svc, txnRepo, accountRepo, _, _ := setupTransactionServiceTest(t)
+	userID := uuid.New()
+	transactionID := uuid.New()
+	fromAccountID := uuid.New()
+	toAccountID := uuid.New()
+
+	txnRepo.On("GetByID", transactionID).Return(&transaction.Transaction{
+		ID:            transactionID,
+		FromAccountID: &fromAccountID,
+		ToAccountID:   &toAccountID,
+		Amount:        100.00,
+	}, nil)
+
+	// Both accounts are loaded in one call; the closed sender is left out
+	accountRepo.On("GetByIDs", []uuid.UUID{fromAccountID, toAccountID}).Return([]*domainAccount.Account{
+		{ID: toAccountID, UserID: userID},
+	}, nil).Once()
+
+	result, err := svc.GetTransaction(userID, transactionID)
+	assert.NoError(t, err)
+	assert.Equal(t, transactionID, result.ID)
+	accountRepo.AssertNotCalled(t, "GetByID", mock.Anything)
+}
+
 // ==================== ResolveQR Tests ====================
 
 func TestResolveQR_Success(t *testing.T) {
@@ -641,6 +1201,28 @@ func TestTransfer_AmountBelowMinimum(t *testing.T) {
 	assert.Contains(t, err.Error(), "minimum transfer amount")
 }
 
+func TestTransfer_InvalidRequest(t *testing.T) {
+	svc, txnRepo, accountRepo, _, _ := setupTransactionServiceTest(t)
+	userID := uuid.New()
+
+	req := &transaction.TransferRequest{
+		FromAccountID:  uuid.New().String(),
+		ToAccountID:    uuid.New().String(),
+		Amount:         -100,
+		Description:    strings.Repeat("x", 256),
+		IdempotencyKey: "key",
+	}
+
+	result, err := svc.Transfer(userID, req)
+	assert.Nil(t, result)
+	var invalid validation.Errors
+	require.ErrorAs(t, err, &invalid)
+	assert.Len(t, invalid, 2)
+	// Rejected before anything is looked up
+	accountRepo.AssertNotCalled(t, "GetByID", mock.Anything)
+	txnRepo.AssertNotCalled(t, "GetByIdempotencyKey", mock.Anything)
+}
+
 func TestTransfer_AmountAboveMaximum(t *testing.T) {
 	svc, _, _, _, _ := setupTransactionServiceTest(t)
 	userID := uuid.New()
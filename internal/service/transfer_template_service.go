@@ -0,0 +1,222 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/audit"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type TransferTemplateService interface {
+	CreateTemplate(userID uuid.UUID, req *transaction.CreateTemplateRequest) (*transaction.Template, error)
+	ListTemplates(userID uuid.UUID) ([]*transaction.Template, error)
+	GetTemplate(userID uuid.UUID, id uuid.UUID) (*transaction.Template, error)
+	UpdateTemplate(userID uuid.UUID, id uuid.UUID, req *transaction.UpdateTemplateRequest) (*transaction.Template, error)
+	DeleteTemplate(userID uuid.UUID, id uuid.UUID) error
+	// ExecuteTemplate runs the saved transfer through the regular transfer
+	// flow, so limits, idempotency and fraud flags apply as usual
+	ExecuteTemplate(userID uuid.UUID, id uuid.UUID, req *transaction.ExecuteTemplateRequest) (*transaction.Transaction, error)
+}
+
+type transferTemplateService struct {
+	templateRepo       repository.TransferTemplateRepository
+	accountRepo        repository.AccountRepository
+	beneficiaryRepo    repository.BeneficiaryRepository
+	auditRepo          repository.AuditRepository
+	transactionService TransactionService
+}
+
+func NewTransferTemplateService(
+	templateRepo repository.TransferTemplateRepository,
+	accountRepo repository.AccountRepository,
+	beneficiaryRepo repository.BeneficiaryRepository,
+	auditRepo repository.AuditRepository,
+	transactionService TransactionService,
+) TransferTemplateService {
+	return &transferTemplateService{
+		templateRepo:       templateRepo,
+		accountRepo:        accountRepo,
+		beneficiaryRepo:    beneficiaryRepo,
+		auditRepo:          auditRepo,
+		transactionService: transactionService,
+	}
+}
+
+func (s *transferTemplateService) CreateTemplate(userID uuid.UUID, req *transaction.CreateTemplateRequest) (*transaction.Template, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if err := validateTemplateAmount(req.Amount); err != nil {
+		return nil, err
+	}
+
+	fromAccountID, err := uuid.Parse(req.FromAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from_account_id")
+	}
+	fromAccount, err := s.accountRepo.GetByID(fromAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("source account not found")
+	}
+	if fromAccount.UserID != userID {
+		return nil, fmt.Errorf("unauthorized: source account does not belong to user")
+	}
+
+	t := &transaction.Template{
+		ID:            uuid.New(),
+		UserID:        userID,
+		Name:          strings.TrimSpace(req.Name),
+		FromAccountID: fromAccountID,
+		Amount:        req.Amount,
+		Description:   req.Description,
+	}
+
+	if req.BeneficiaryID != "" {
+		beneficiaryID, err := uuid.Parse(req.BeneficiaryID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid beneficiary_id")
+		}
+		payee, err := s.beneficiaryRepo.GetByID(beneficiaryID)
+		if err != nil || payee.UserID != userID {
+			return nil, fmt.Errorf("beneficiary not found")
+		}
+		t.BeneficiaryID = &beneficiaryID
+	} else {
+		toAccountID, err := uuid.Parse(req.ToAccountID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid to_account_id")
+		}
+		if toAccountID == fromAccountID {
+			return nil, fmt.Errorf("cannot transfer to the same account")
+		}
+		if _, err := s.accountRepo.GetByID(toAccountID); err != nil {
+			return nil, fmt.Errorf("destination account not found")
+		}
+		t.ToAccountID = &toAccountID
+	}
+
+	existing, err := s.templateRepo.ListByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= transaction.MaxTemplatesPerUser {
+		return nil, fmt.Errorf("cannot save more than %d transfer templates", transaction.MaxTemplatesPerUser)
+	}
+
+	if err := s.templateRepo.Create(t); err != nil {
+		return nil, err
+	}
+
+	s.audit(userID, "TRANSFER_TEMPLATE_CREATED", t.ID, map[string]interface{}{"amount": t.Amount})
+
+	return t, nil
+}
+
+func (s *transferTemplateService) ListTemplates(userID uuid.UUID) ([]*transaction.Template, error) {
+	return s.templateRepo.ListByUser(userID)
+}
+
+func (s *transferTemplateService) GetTemplate(userID uuid.UUID, id uuid.UUID) (*transaction.Template, error) {
+	t, err := s.templateRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if t.UserID != userID {
+		return nil, fmt.Errorf("unauthorized: transfer template does not belong to user")
+	}
+
+	return t, nil
+}
+
+func (s *transferTemplateService) UpdateTemplate(userID uuid.UUID, id uuid.UUID, req *transaction.UpdateTemplateRequest) (*transaction.Template, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	t, err := s.GetTemplate(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		t.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Amount != nil {
+		if err := validateTemplateAmount(*req.Amount); err != nil {
+			return nil, err
+		}
+		t.Amount = *req.Amount
+	}
+	if req.Description != nil {
+		t.Description = *req.Description
+	}
+
+	if err := s.templateRepo.Update(t); err != nil {
+		return nil, err
+	}
+
+	s.audit(userID, "TRANSFER_TEMPLATE_UPDATED", id, map[string]interface{}{"amount": t.Amount})
+
+	return t, nil
+}
+
+func (s *transferTemplateService) DeleteTemplate(userID uuid.UUID, id uuid.UUID) error {
+	if _, err := s.GetTemplate(userID, id); err != nil {
+		return err
+	}
+
+	if err := s.templateRepo.Delete(id); err != nil {
+		return err
+	}
+
+	s.audit(userID, "TRANSFER_TEMPLATE_DELETED", id, nil)
+
+	return nil
+}
+
+func (s *transferTemplateService) ExecuteTemplate(userID uuid.UUID, id uuid.UUID, req *transaction.ExecuteTemplateRequest) (*transaction.Transaction, error) {
+	t, err := s.GetTemplate(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	transfer := t.TransferRequest(req.IdempotencyKey, req.Amount)
+	transfer.Country = req.Country
+	txn, err := s.transactionService.Transfer(userID, transfer)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.templateRepo.MarkUsed(id, time.Now()); err != nil {
+		logger.Warn("Failed to mark transfer template used", zap.String("template_id", id.String()), zap.Error(err))
+	}
+
+	return txn, nil
+}
+
+func validateTemplateAmount(amount float64) error {
+	if amount < MinTransferAmount || amount > MaxTransferAmount {
+		return fmt.Errorf("template amount must be between %d and %d IDR", MinTransferAmount, MaxTransferAmount)
+	}
+	return nil
+}
+
+func (s *transferTemplateService) audit(userID uuid.UUID, action string, templateID uuid.UUID, metadata map[string]interface{}) {
+	if err := s.auditRepo.Create(&audit.AuditLog{
+		EventID:  uuid.New(),
+		UserID:   &userID,
+		Action:   action,
+		Resource: fmt.Sprintf("transfer_template:%s", templateID),
+		Status:   "success",
+		Metadata: metadata,
+	}); err != nil {
+		logger.Error("Failed to create audit log for transfer template", zap.String("action", action), zap.Error(err))
+		errtrack.CaptureError(err, map[string]string{"component": "transfer_template_service", "operation": "audit_log"})
+	}
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/beneficiary"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTransferTemplateRepository is a mock implementation of repository.TransferTemplateRepository
+type MockTransferTemplateRepository struct {
+	mock.Mock
+}
+
+func (m *MockTransferTemplateRepository) Create(t *transaction.Template) error {
+	args := m.Called(t)
+	return args.Error(0)
+}
+
+func (m *MockTransferTemplateRepository) GetByID(id uuid.UUID) (*transaction.Template, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*transaction.Template), args.Error(1)
+}
+
+func (m *MockTransferTemplateRepository) ListByUser(userID uuid.UUID) ([]*transaction.Template, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*transaction.Template), args.Error(1)
+}
+
+func (m *MockTransferTemplateRepository) Update(t *transaction.Template) error {
+	args := m.Called(t)
+	return args.Error(0)
+}
+
+func (m *MockTransferTemplateRepository) MarkUsed(id uuid.UUID, at time.Time) error {
+	args := m.Called(id, at)
+	return args.Error(0)
+}
+
+func (m *MockTransferTemplateRepository) Delete(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+// MockTransactionService is a mock implementation of TransactionService
+type MockTransactionService struct {
+	mock.Mock
+}
+
+func (m *MockTransactionService) Transfer(userID uuid.UUID, req *transaction.TransferRequest) (*transaction.Transaction, error) {
+	args := m.Called(userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*transaction.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionService) Deposit(userID uuid.UUID, req *transaction.DepositRequest) (*transaction.Transaction, error) {
+	args := m.Called(userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*transaction.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionService) Withdrawal(userID uuid.UUID, req *transaction.WithdrawalRequest) (*transaction.Transaction, error) {
+	args := m.Called(userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*transaction.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionService) GetTransactionHistory(userID uuid.UUID, req *transaction.TransactionHistoryRequest) (*transaction.TransactionHistoryResponse, error) {
+	args := m.Called(userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*transaction.TransactionHistoryResponse), args.Error(1)
+}
+
+func (m *MockTransactionService) GetArchivedHistory(userID uuid.UUID, req *transaction.ArchivedHistoryRequest) (*transaction.TransactionHistoryResponse, error) {
+	args := m.Called(userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*transaction.TransactionHistoryResponse), args.Error(1)
+}
+
+func (m *MockTransactionService) SearchTransactions(userID uuid.UUID, req *transaction.SearchRequest) (*transaction.TransactionHistoryResponse, error) {
+	args := m.Called(userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*transaction.TransactionHistoryResponse), args.Error(1)
+}
+
+func (m *MockTransactionService) GetTransaction(userID uuid.UUID, transactionID uuid.UUID) (*transaction.Transaction, error) {
+	args := m.Called(userID, transactionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*transaction.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionService) ResolveQR(qrCode string) (*transaction.QRResolutionResponse, error) {
+	args := m.Called(qrCode)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*transaction.QRResolutionResponse), args.Error(1)
+}
+
+type transferTemplateTest struct {
+	svc             TransferTemplateService
+	templateRepo    *MockTransferTemplateRepository
+	accountRepo     *MockAccountRepository
+	beneficiaryRepo *MockBeneficiaryRepository
+	txnService      *MockTransactionService
+}
+
+func setupTransferTemplateServiceTest() *transferTemplateTest {
+	logger.Init("test")
+	tt := &transferTemplateTest{
+		templateRepo:    new(MockTransferTemplateRepository),
+		accountRepo:     new(MockAccountRepository),
+		beneficiaryRepo: new(MockBeneficiaryRepository),
+		txnService:      new(MockTransactionService),
+	}
+	auditRepo := new(MockAuditRepository)
+	auditRepo.On("Create", mock.Anything).Return(nil)
+	tt.svc = NewTransferTemplateService(tt.templateRepo, tt.accountRepo, tt.beneficiaryRepo, auditRepo, tt.txnService)
+	return tt
+}
+
+func TestCreateTemplate_ToBeneficiary(t *testing.T) {
+	tt := setupTransferTemplateServiceTest()
+	userID := uuid.New()
+	fromAccountID := uuid.New()
+	beneficiaryID := uuid.New()
+
+	tt.accountRepo.On("GetByID", fromAccountID).Return(&account.Account{ID: fromAccountID, UserID: userID}, nil)
+	tt.beneficiaryRepo.On("GetByID", beneficiaryID).Return(&beneficiary.Beneficiary{ID: beneficiaryID, UserID: userID}, nil)
+	tt.templateRepo.On("ListByUser", userID).Return([]*transaction.Template{}, nil)
+	tt.templateRepo.On("Create", mock.Anything).Return(nil)
+
+	tmpl, err := tt.svc.CreateTemplate(userID, &transaction.CreateTemplateRequest{
+		Name: " Rent ", FromAccountID: fromAccountID.String(), BeneficiaryID: beneficiaryID.String(), Amount: 1500000,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Rent", tmpl.Name)
+	assert.Equal(t, beneficiaryID, *tmpl.BeneficiaryID)
+	assert.Nil(t, tmpl.ToAccountID)
+}
+
+func TestCreateTemplate_OtherUsersAccount(t *testing.T) {
+	tt := setupTransferTemplateServiceTest()
+	fromAccountID := uuid.New()
+	tt.accountRepo.On("GetByID", fromAccountID).Return(&account.Account{ID: fromAccountID, UserID: uuid.New()}, nil)
+
+	_, err := tt.svc.CreateTemplate(uuid.New(), &transaction.CreateTemplateRequest{
+		Name: "Rent", FromAccountID: fromAccountID.String(), ToAccountID: uuid.New().String(), Amount: 1500000,
+	})
+
+	assert.EqualError(t, err, "unauthorized: source account does not belong to user")
+	tt.templateRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestCreateTemplate_AmountAboveMaximum(t *testing.T) {
+	tt := setupTransferTemplateServiceTest()
+
+	_, err := tt.svc.CreateTemplate(uuid.New(), &transaction.CreateTemplateRequest{
+		Name: "Car", FromAccountID: uuid.New().String(), ToAccountID: uuid.New().String(), Amount: MaxTransferAmount + 1,
+	})
+
+	assert.Error(t, err)
+}
+
+func TestExecuteTemplate(t *testing.T) {
+	tt := setupTransferTemplateServiceTest()
+	userID := uuid.New()
+	toAccountID := uuid.New()
+	tmpl := &transaction.Template{ID: uuid.New(), UserID: userID, FromAccountID: uuid.New(), ToAccountID: &toAccountID, Amount: 250000}
+	txn := &transaction.Transaction{ID: uuid.New(), Amount: 300000}
+
+	tt.templateRepo.On("GetByID", tmpl.ID).Return(tmpl, nil)
+	tt.txnService.On("Transfer", userID, tmpl.TransferRequest("run-1", 300000)).Return(txn, nil)
+	tt.templateRepo.On("MarkUsed", tmpl.ID, mock.Anything).Return(nil)
+
+	result, err := tt.svc.ExecuteTemplate(userID, tmpl.ID, &transaction.ExecuteTemplateRequest{IdempotencyKey: "run-1", Amount: 300000})
+
+	assert.NoError(t, err)
+	assert.Equal(t, txn, result)
+	tt.templateRepo.AssertExpectations(t)
+}
+
+func TestExecuteTemplate_TransferFails(t *testing.T) {
+	tt := setupTransferTemplateServiceTest()
+	userID := uuid.New()
+	toAccountID := uuid.New()
+	tmpl := &transaction.Template{ID: uuid.New(), UserID: userID, FromAccountID: uuid.New(), ToAccountID: &toAccountID, Amount: 250000}
+
+	tt.templateRepo.On("GetByID", tmpl.ID).Return(tmpl, nil)
+	tt.txnService.On("Transfer", userID, mock.Anything).Return(nil, fmt.Errorf("insufficient balance"))
+
+	_, err := tt.svc.ExecuteTemplate(userID, tmpl.ID, &transaction.ExecuteTemplateRequest{IdempotencyKey: "run-1"})
+
+	assert.EqualError(t, err, "insufficient balance")
+	tt.templateRepo.AssertNotCalled(t, "MarkUsed", mock.Anything, mock.Anything)
+}
@@ -2,16 +2,24 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	domainAccount "github.com/darisadam/madabank-server/internal/domain/account"
 	"github.com/darisadam/madabank-server/internal/domain/card"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
 	"github.com/darisadam/madabank-server/internal/domain/user"
 	"github.com/darisadam/madabank-server/internal/pkg/crypto"
+	"github.com/darisadam/madabank-server/internal/pkg/errtrack"
 	"github.com/darisadam/madabank-server/internal/pkg/jwt"
 	"github.com/darisadam/madabank-server/internal/pkg/logger"
 	"github.com/darisadam/madabank-server/internal/pkg/metrics"
+	"github.com/darisadam/madabank-server/internal/pkg/notifier"
+	"github.com/darisadam/madabank-server/internal/pkg/passwordpolicy"
 	"github.com/darisadam/madabank-server/internal/repository"
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
@@ -25,10 +33,126 @@ type UserService interface {
 	UpdateProfile(userID uuid.UUID, req *user.UpdateUserRequest) (*user.User, error)
 	DeleteAccount(userID uuid.UUID) error
 	RefreshToken(refreshToken string) (*user.LoginResponse, error)
-	ForgotPassword(req *user.ForgotPasswordRequest) error
+	// ForgotPassword sends a reset code over the requested channel, or by
+	// SMS when the profile has a verified phone number, and reports which
+	ForgotPassword(req *user.ForgotPasswordRequest) (user.OTPChannel, error)
 	ResetPassword(req *user.ResetPasswordRequest) error
+	// ResetPasswordWithLink completes a reset from the emailed link. Using
+	// either the link or the OTP cancels the other.
+	ResetPasswordWithLink(req *user.ResetPasswordLinkRequest) error
+	// ChangePassword replaces the password of a signed-in user and signs
+	// them out of every other session
+	ChangePassword(userID uuid.UUID, req *user.ChangePasswordRequest) error
+	// ResolveHandle shows who an @handle belongs to before paying them
+	ResolveHandle(handle string) (*user.HandleResolution, error)
+	// RequestEmailChange sends a code to the new address, and
+	// ConfirmEmailChange switches to it, tells the old address and signs
+	// the user out of every session
+	RequestEmailChange(userID uuid.UUID, req *user.EmailChangeRequest) error
+	ConfirmEmailChange(userID uuid.UUID, req *user.ConfirmEmailChangeRequest) (*user.User, error)
+	// RequestPhoneVerification texts a code to the profile's phone number, and
+	// VerifyPhone confirms it, enabling login by phone
+	RequestPhoneVerification(userID uuid.UUID) error
+	VerifyPhone(userID uuid.UUID, req *user.VerifyPhoneRequest) (*user.User, error)
+	// RequestStepUp sends a code confirming a transfer from a country the
+	// GeoIP policy challenges, and VerifyStepUp spends it. Each code
+	// confirms one action.
+	RequestStepUp(userID uuid.UUID) (user.OTPChannel, error)
+	VerifyStepUp(userID uuid.UUID, otp string) error
+	// RequestReactivation and Reactivate restore a deleted or deactivated
+	// profile within user.ReactivationWindow, after verifying the password
+	// and a code sent to the email address
+	RequestReactivation(req *user.ReactivationOTPRequest) error
+	Reactivate(req *user.ReactivateRequest) (*user.User, error)
+	// ListUsers is the support side, searching all users
+	ListUsers(f *user.ListFilter) (*user.UserListResponse, error)
 }
 
+// ResetLinkConfig enables password reset links, emailed next to the OTP.
+// Links are off when either field is empty.
+type ResetLinkConfig struct {
+	// Secret signs the link tokens
+	Secret []byte
+	// BaseURL is the web page the token is appended to as ?token=
+	BaseURL string
+}
+
+func (c ResetLinkConfig) enabled() bool {
+	return len(c.Secret) > 0 && c.BaseURL != ""
+}
+
+// LoginAlertConfig controls logins from a device or country the user has not
+// logged in from before. They are always announced by email.
+type LoginAlertConfig struct {
+	// RequireOTP asks for a one-time code before issuing tokens
+	RequireOTP bool
+}
+
+// consumeResetNonceScript deletes the stored reset link nonce only if it is
+// the one presented, so a link works once and an older link cannot cancel
+// a newer one
+var consumeResetNonceScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// verifyOTPScript checks a code hash against the one stored under KEYS[1]
+// and consumes the code in the same step, so concurrent guesses cannot slip
+// past the attempt limit and a code cannot be spent twice. It returns -1 if
+// no code is stored, 0 on a match, -2 once ARGV[2] wrong codes have been
+// tried, and otherwise the number of wrong codes so far.
+var verifyOTPScript = redis.NewScript(`
+local stored = redis.call("HGET", KEYS[1], "hash")
+if not stored then
+	return -1
+end
+if stored == ARGV[1] then
+	redis.call("DEL", KEYS[1])
+	return 0
+end
+local attempts = redis.call("HINCRBY", KEYS[1], "attempts", 1)
+if attempts >= tonumber(ARGV[2]) then
+	redis.call("DEL", KEYS[1])
+	return -2
+end
+return attempts
+`)
+
+// claimLoginAttemptScript reads the failed logins counted under KEYS[1]
+// and, unless the delay after the last one is still running, counts this
+// attempt in the same step, so a burst of parallel attempts cannot all pass
+// one check. ARGV[1] is the time in milliseconds, ARGV[2] how long the count
+// is kept, and ARGV[3..] the delays after 0, 1, 2, ... failures, the last
+// applying to any higher count. It returns the earlier failures and the
+// milliseconds still to wait, 0 when the attempt was counted.
+var claimLoginAttemptScript = redis.NewScript(`
+local count = tonumber(redis.call("HGET", KEYS[1], "count") or "0")
+local last = tonumber(redis.call("HGET", KEYS[1], "last") or "0")
+local now = tonumber(ARGV[1])
+local delay = tonumber(ARGV[math.min(count + 3, #ARGV)])
+if now < last + delay then
+	return {count, last + delay - now}
+end
+redis.call("HSET", KEYS[1], "count", count + 1, "last", now)
+redis.call("PEXPIRE", KEYS[1], ARGV[2])
+return {count, 0}
+`)
+
+// loginDelays is user.LoginDelay in milliseconds after 0, 1, 2, ... failures,
+// up to the first that reaches user.MaxLoginDelay
+var loginDelays = func() []interface{} {
+	var delays []interface{}
+	for failures := 0; ; failures++ {
+		delay := user.LoginDelay(failures)
+		delays = append(delays, delay.Milliseconds())
+		if delay >= user.MaxLoginDelay {
+			return delays
+		}
+	}
+}()
+
 type userService struct {
 	userRepo    repository.UserRepository
 	accountRepo repository.AccountRepository
@@ -36,6 +160,13 @@ type userService struct {
 	jwtService  *jwt.JWTService
 	redisClient *redis.Client
 	encryptor   *crypto.Encryptor
+	notifier    notifier.Notifier
+	smsSender   notifier.SMSSender
+	// passwordPolicy checks every new password: on register, reset and change
+	passwordPolicy *passwordpolicy.Validator
+	resetLinks     ResetLinkConfig
+	loginAlerts    LoginAlertConfig
+	tokenTTLs      user.TokenTTLs
 }
 
 func NewUserService(
@@ -45,14 +176,27 @@ func NewUserService(
 	jwtService *jwt.JWTService,
 	redisClient *redis.Client,
 	encryptor *crypto.Encryptor,
+	emailNotifier notifier.Notifier,
+	smsSender notifier.SMSSender,
+	passwordPolicy *passwordpolicy.Validator,
+	resetLinks ResetLinkConfig,
+	loginAlerts LoginAlertConfig,
+	tokenTTLs user.TokenTTLs,
 ) UserService {
+	resetLinks.BaseURL = strings.TrimRight(resetLinks.BaseURL, "/")
 	return &userService{
-		userRepo:    userRepo,
-		accountRepo: accountRepo,
-		cardRepo:    cardRepo,
-		jwtService:  jwtService,
-		redisClient: redisClient,
-		encryptor:   encryptor,
+		userRepo:       userRepo,
+		accountRepo:    accountRepo,
+		cardRepo:       cardRepo,
+		jwtService:     jwtService,
+		redisClient:    redisClient,
+		encryptor:      encryptor,
+		notifier:       emailNotifier,
+		smsSender:      smsSender,
+		passwordPolicy: passwordPolicy,
+		resetLinks:     resetLinks,
+		loginAlerts:    loginAlerts,
+		tokenTTLs:      tokenTTLs,
 	}
 }
 
@@ -63,6 +207,10 @@ func (s *userService) Register(req *user.CreateUserRequest) (*user.User, error)
 		return nil, fmt.Errorf("user with this email already exists")
 	}
 
+	if err := s.passwordPolicy.Validate(context.Background(), req.Password); err != nil {
+		return nil, err
+	}
+
 	// Hash password
 	passwordHash, err := crypto.HashPassword(req.Password)
 	if err != nil {
@@ -89,6 +237,7 @@ func (s *userService) Register(req *user.CreateUserRequest) (*user.User, error)
 		Phone:        req.Phone,
 		DateOfBirth:  dob,
 		KYCStatus:    "pending",
+		Role:         user.RoleCustomer,
 		IsActive:     true,
 	}
 
@@ -103,6 +252,7 @@ func (s *userService) Register(req *user.CreateUserRequest) (*user.User, error)
 			zap.String("user_id", newUser.ID.String()),
 			zap.Error(err),
 		)
+		errtrack.CaptureError(err, map[string]string{"component": "user_service", "operation": "auto_onboarding"})
 	}
 
 	// Remove sensitive data before returning
@@ -172,6 +322,7 @@ func (s *userService) createFirstAccountAndCard(newUser *user.User) error {
 		ID:                  uuid.New(),
 		AccountID:           firstAccount.ID,
 		CardNumberEncrypted: encryptedCardNumber,
+		CardNumberHash:      s.encryptor.Fingerprint(cardNumber),
 		CVVEncrypted:        encryptedCVV,
 		CardHolderName:      cardHolderName,
 		CardType:            card.CardTypeDebit,
@@ -179,6 +330,7 @@ func (s *userService) createFirstAccountAndCard(newUser *user.User) error {
 		ExpiryYear:          expiryDate.Year(),
 		Status:              card.CardStatusActive,
 		DailyLimit:          10_000_000, // 10 million IDR daily limit
+		Controls:            card.DefaultControls(),
 		CreatedAt:           now,
 	}
 
@@ -198,45 +350,132 @@ func (s *userService) Login(req *user.LoginRequest) (*user.LoginResponse, error)
 	var u *user.User
 	var err error
 
-	// Validate that either email or phone is provided
-	if req.Email == "" && req.Phone == "" {
+	// Validate that an email, phone or username is provided
+	if req.Email == "" && req.Phone == "" && req.Username == "" {
 		metrics.RecordAuthAttempt(false)
-		return nil, fmt.Errorf("email or phone number is required")
+		return nil, fmt.Errorf("email, phone number or username is required")
 	}
 
-	// Get user by email or phone
-	if req.Email != "" {
+	// Get user by email, phone or username
+	var invalidCredentials error
+	var identifier string
+	switch {
+	case req.Email != "":
+		invalidCredentials = fmt.Errorf("invalid email or password")
+		identifier = req.Email
 		u, err = s.userRepo.GetByEmail(req.Email)
-		if err != nil {
-			metrics.RecordAuthAttempt(false)
-			return nil, fmt.Errorf("invalid email or password")
-		}
-	} else {
+	case req.Phone != "":
 		// Login by phone number
+		invalidCredentials = fmt.Errorf("invalid phone number or password")
+		identifier = req.Phone
 		u, err = s.userRepo.GetByPhone(req.Phone)
-		if err != nil {
-			metrics.RecordAuthAttempt(false)
-			return nil, fmt.Errorf("invalid phone number or password")
+		if err == nil && !u.PhoneVerified() {
+			err = fmt.Errorf("phone number is not verified")
+		}
+	default:
+		invalidCredentials = fmt.Errorf("invalid username or password")
+		identifier = req.Username
+		var username string
+		if username, err = user.NormalizeUsername(req.Username); err == nil {
+			u, err = s.userRepo.GetByUsername(username)
 		}
 	}
 
+	// Failed logins are counted per account, so rotating IPs does not get
+	// around them. Unknown identifiers are throttled the same way, so the
+	// responses do not tell which accounts exist.
+	failureSubject := "id:" + strings.ToLower(strings.TrimSpace(identifier))
+	if err == nil {
+		failureSubject = u.ID.String()
+	}
+	// Every attempt that gets past the throttle is counted before the
+	// password is checked, in the same step as the check, and only a
+	// successful login clears the count
+	failures, wait := s.claimLoginAttempt(failureSubject)
+	if wait > 0 {
+		metrics.RecordAuthAttempt(false)
+		auditLogin(u, req.Origin, "throttled")
+		return nil, &user.LoginThrottledError{RetryAfter: wait}
+	}
+
+	if err != nil {
+		metrics.RecordAuthAttempt(false)
+		auditLogin(nil, req.Origin, "unknown_account")
+		return nil, invalidCredentials
+	}
+
 	// Check if user is active
 	if !u.IsActive {
 		metrics.RecordAuthAttempt(false)
+		auditLogin(u, req.Origin, "inactive")
 		return nil, fmt.Errorf("account is inactive")
 	}
 
-	// Verify password
-	if !crypto.CheckPassword(req.Password, u.PasswordHash) {
-		metrics.RecordAuthAttempt(false)
-		if req.Email != "" {
-			return nil, fmt.Errorf("invalid email or password")
+	passwordOK := crypto.CheckPassword(req.Password, u.PasswordHash)
+
+	// Logins from a device or country the user has not logged in from
+	// before are announced, and may need a code as well. A failed history
+	// lookup does not block the login.
+	familiarity, err := s.userRepo.GetLoginFamiliarity(u.ID, req.Origin)
+	if err != nil {
+		logger.Error("Failed to check login history", zap.String("user_id", u.ID.String()), zap.Error(err))
+		errtrack.CaptureError(err, map[string]string{"component": "user_service", "operation": "login_history"})
+	}
+	newOrigin := familiarity != nil && familiarity.IsNew(req.Origin)
+
+	// Logins from a country the GeoIP policy challenges also need a code, as
+	// do those after repeated failures. Whether the password was right is not
+	// revealed until the code is checked: a wrong password also gets
+	// ErrStepUpRequired, just without a code being sent, and a wrong password
+	// with a code fails like a wrong code.
+	if req.StepUp || (newOrigin && s.loginAlerts.RequireOTP) || failures >= user.LoginOTPAfterFailures {
+		if req.OTP == "" {
+			if !passwordOK {
+				metrics.RecordAuthAttempt(false)
+				auditLogin(u, req.Origin, "invalid_password")
+				return nil, user.ErrStepUpRequired
+			}
+			if _, err := s.sendStepUp(u); err != nil {
+				return nil, err
+			}
+			return nil, user.ErrStepUpRequired
 		}
-		return nil, fmt.Errorf("invalid phone number or password")
+		if !passwordOK {
+			metrics.RecordAuthAttempt(false)
+			auditLogin(u, req.Origin, "invalid_password")
+			return nil, user.ErrInvalidLoginCode
+		}
+		if err := s.VerifyStepUp(u.ID, req.OTP); err != nil {
+			metrics.RecordAuthAttempt(false)
+			auditLogin(u, req.Origin, "invalid_code")
+			// Only the code's own failures are folded together; a
+			// wrapped error is Redis failing, not the code
+			if errors.Unwrap(err) != nil {
+				return nil, err
+			}
+			return nil, user.ErrInvalidLoginCode
+		}
+	}
+
+	if !passwordOK {
+		metrics.RecordAuthAttempt(false)
+		auditLogin(u, req.Origin, "invalid_password")
+		return nil, invalidCredentials
 	}
 
-	// Generate JWT token
-	token, expiresAt, err := s.jwtService.GenerateToken(u.ID, u.Email, "customer")
+	// Transparently upgrade legacy or outdated password hashes
+	if crypto.NeedsRehash(u.PasswordHash) {
+		s.rehashPassword(u.ID, req.Password)
+	}
+
+	// Generate JWT token, living as long as the client type allows
+	clientType := user.ClientTypeDefault
+	if req.ClientType != "" {
+		clientType = user.ClientType(req.ClientType)
+	}
+	ttl := s.tokenTTLs.For(clientType)
+	now := time.Now()
+	token, expiresAt, err := s.jwtService.GenerateTokenUntil(u.ID, u.Email, u.Role, ttl.AccessExpiry(now, now))
 	if err != nil {
 		metrics.RecordAuthAttempt(false)
 		return nil, fmt.Errorf("failed to generate token: %w", err)
@@ -250,7 +489,7 @@ func (s *userService) Login(req *user.LoginRequest) (*user.LoginResponse, error)
 	u.PasswordHash = ""
 
 	// Generate Refresh token
-	refreshToken, refreshExpiresAt, err := s.jwtService.GenerateRefreshToken()
+	refreshToken, refreshExpiresAt, err := s.jwtService.GenerateRefreshToken(ttl.Refresh)
 	if err != nil {
 		metrics.RecordAuthAttempt(false)
 		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
@@ -260,16 +499,25 @@ func (s *userService) Login(req *user.LoginRequest) (*user.LoginResponse, error)
 	// For better security, we should hash it. Using crypto package from our project.
 	// But `GenerateRefreshToken` output is just a string.
 	// Let's assume we store it directly for MVP or hash it if `SaveRefreshToken` expects hash.
-	// The repo method is `SaveRefreshToken(userID, tokenHash, expiresAt)`.
+	// The repo method is `SaveRefreshToken(userID, tokenHash, clientType, expiresAt)`.
 	// We should hash it.
 
 	// refreshTokenHash, err := crypto.HashPassword(refreshToken) // REMOVED
 	refreshTokenHash := refreshToken
 
-	if err := s.userRepo.SaveRefreshToken(u.ID, refreshTokenHash, refreshExpiresAt); err != nil {
+	if err := s.userRepo.SaveRefreshToken(u.ID, refreshTokenHash, clientType, refreshExpiresAt); err != nil {
 		return nil, fmt.Errorf("failed to save refresh token: %w", err)
 	}
 
+	s.clearLoginFailures(failureSubject)
+	if err := s.userRepo.RecordLogin(u.ID, req.Origin); err != nil {
+		logger.Error("Failed to record login history", zap.String("user_id", u.ID.String()), zap.Error(err))
+	}
+	if newOrigin {
+		s.sendLoginAlert(u, req.Origin)
+	}
+	auditLogin(u, req.Origin, "")
+
 	// Record successful auth
 	metrics.RecordAuthAttempt(true)
 	metrics.RecordAuthTokenGenerated()
@@ -278,13 +526,96 @@ func (s *userService) Login(req *user.LoginRequest) (*user.LoginResponse, error)
 	u.PasswordHash = ""
 
 	return &user.LoginResponse{
-		Token:        token,
-		RefreshToken: refreshToken,
-		ExpiresAt:    expiresAt,
-		User:         u,
+		Token:            token,
+		RefreshToken:     refreshToken,
+		ExpiresAt:        expiresAt,
+		RefreshExpiresAt: refreshExpiresAt,
+		User:             u,
 	}, nil
 }
 
+func loginFailureKey(subject string) string {
+	return fmt.Sprintf("login_failures:%s", subject)
+}
+
+// claimLoginAttempt counts a login attempt against the subject unless its
+// failed attempts still throttle it, and returns how many failures came before
+// and how long it must wait. When Redis fails the attempt goes ahead.
+func (s *userService) claimLoginAttempt(subject string) (int, time.Duration) {
+	args := append([]interface{}{time.Now().UnixMilli(), user.LoginFailureWindow.Milliseconds()}, loginDelays...)
+	result, err := claimLoginAttemptScript.Run(context.Background(), s.redisClient, []string{loginFailureKey(subject)}, args...).Int64Slice()
+	if err != nil {
+		logger.Error("Failed to check login failures", zap.Error(err))
+		return 0, 0
+	}
+	return int(result[0]), time.Duration(result[1]) * time.Millisecond
+}
+
+func (s *userService) clearLoginFailures(subject string) {
+	s.redisClient.Del(context.Background(), loginFailureKey(subject))
+}
+
+// auditLogin sends a login attempt to the audit log stream. An empty reason
+// means the login succeeded; u is nil when the identifier matched no user.
+func auditLogin(u *user.User, origin user.LoginOrigin, reason string) {
+	fields := []zap.Field{
+		zap.String("status", "success"),
+		zap.String("ip_address", origin.IPAddress),
+		zap.String("device_id", origin.DeviceID),
+		zap.String("country", origin.Country),
+	}
+	if reason != "" {
+		fields[0] = zap.String("status", "failed")
+		fields = append(fields, zap.String("reason", reason))
+	}
+	if u != nil {
+		fields = append(fields, zap.String("user_id", u.ID.String()))
+	}
+	logger.Audit("LOGIN", fields...)
+}
+
+// sendLoginAlert emails the user about a login from a new device or country.
+// Failures are logged but never block the login.
+func (s *userService) sendLoginAlert(u *user.User, origin user.LoginOrigin) {
+	location := origin.Country
+	if location == "" {
+		location = "unknown"
+	}
+	device := origin.UserAgent
+	if device == "" {
+		device = "unknown"
+	}
+	alert := &notifier.Email{
+		To:      u.Email,
+		Subject: "New sign-in to your MadaBank account",
+		Body: fmt.Sprintf("Hello %s,\n\nYour account was signed in to from a new device or location on %s.\n\n"+
+			"Device: %s\nCountry: %s\nIP address: %s\n\n"+
+			"If this was not you, change your password and contact support immediately.\n",
+			u.FirstName, time.Now().UTC().Format("2 January 2006 15:04 MST"), device, location, origin.IPAddress),
+	}
+	if err := s.notifier.SendEmail(context.Background(), alert); err != nil {
+		logger.Warn("Failed to send new login alert", zap.String("user_id", u.ID.String()), zap.Error(err))
+	}
+}
+
+// rehashPassword stores a fresh hash of a just-verified password. Failures are
+// logged but never block the login.
+func (s *userService) rehashPassword(userID uuid.UUID, password string) {
+	newHash, err := crypto.HashPassword(password)
+	if err != nil {
+		logger.Warn("Failed to rehash password", zap.String("user_id", userID.String()), zap.Error(err))
+		return
+	}
+
+	if err := s.userRepo.Update(userID, map[string]interface{}{"password_hash": newHash}); err != nil {
+		logger.Warn("Failed to store upgraded password hash", zap.String("user_id", userID.String()), zap.Error(err))
+		errtrack.CaptureError(err, map[string]string{"component": "user_service", "operation": "rehash_password"})
+		return
+	}
+
+	logger.Info("Upgraded password hash", zap.String("user_id", userID.String()))
+}
+
 func (s *userService) GetProfile(userID uuid.UUID) (*user.User, error) {
 	u, err := s.userRepo.GetByID(userID)
 	if err != nil {
@@ -304,8 +635,33 @@ func (s *userService) UpdateProfile(userID uuid.UUID, req *user.UpdateUserReques
 	if req.LastName != nil {
 		updates["last_name"] = *req.LastName
 	}
+	if req.Username != nil {
+		if *req.Username == "" {
+			updates["username"] = nil
+		} else {
+			username, err := user.NormalizeUsername(*req.Username)
+			if err != nil {
+				return nil, err
+			}
+			if owner, err := s.userRepo.GetByUsername(username); err == nil && owner.ID != userID {
+				return nil, fmt.Errorf("username is already taken")
+			}
+			updates["username"] = username
+		}
+	}
 	if req.Phone != nil {
-		updates["phone"] = *req.Phone
+		current, err := s.GetProfile(userID)
+		if err != nil {
+			return nil, err
+		}
+		if current.Phone == nil || *current.Phone != *req.Phone {
+			if owner, err := s.userRepo.GetByPhone(*req.Phone); err == nil && owner.ID != userID {
+				return nil, fmt.Errorf("phone number is already registered to another customer")
+			}
+			// A new number has to be verified before it can be used to log in
+			updates["phone"] = *req.Phone
+			updates["phone_verified_at"] = nil
+		}
 	}
 	if req.DateOfBirth != nil {
 		parsedDOB, err := time.Parse("2006-01-02", *req.DateOfBirth)
@@ -353,23 +709,28 @@ func (s *userService) RefreshToken(refreshToken string) (*user.LoginResponse, er
 	// and update the Login code to NOT hash it with bcrypt.
 
 	// Check if token revocation is handled
-	userID, expiresAt, err := s.userRepo.GetRefreshToken(refreshToken)
+	session, err := s.userRepo.GetRefreshToken(refreshToken)
 	if err != nil {
 		return nil, fmt.Errorf("invalid or expired refresh token")
 	}
 
-	if time.Now().After(expiresAt) {
+	now := time.Now()
+	if now.After(session.ExpiresAt) {
 		return nil, fmt.Errorf("refresh token expired")
 	}
+	ttl := s.tokenTTLs.For(session.ClientType)
+	if !now.Before(session.SessionStartedAt.Add(ttl.SessionMax)) {
+		return nil, fmt.Errorf("session expired, please log in again")
+	}
 
 	// Get user
-	u, err := s.userRepo.GetByID(userID)
+	u, err := s.userRepo.GetByID(session.UserID)
 	if err != nil {
 		return nil, fmt.Errorf("user not found")
 	}
 
-	// Generate new access token
-	token, newExpiresAt, err := s.jwtService.GenerateToken(u.ID, u.Email, "customer")
+	// Generate new access token, never outliving the session
+	token, newExpiresAt, err := s.jwtService.GenerateTokenUntil(u.ID, u.Email, u.Role, ttl.AccessExpiry(now, session.SessionStartedAt))
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate token: %w", err)
 	}
@@ -378,71 +739,169 @@ func (s *userService) RefreshToken(refreshToken string) (*user.LoginResponse, er
 	// For simplicity, we keep the same refresh token until it expires
 
 	return &user.LoginResponse{
-		Token:        token,
-		RefreshToken: refreshToken,
-		ExpiresAt:    newExpiresAt,
-		User:         u,
+		Token:            token,
+		RefreshToken:     refreshToken,
+		ExpiresAt:        newExpiresAt,
+		RefreshExpiresAt: session.ExpiresAt,
+		User:             u,
 	}, nil
 }
 
-func (s *userService) ForgotPassword(req *user.ForgotPasswordRequest) error {
+func (s *userService) ForgotPassword(req *user.ForgotPasswordRequest) (user.OTPChannel, error) {
 	// 1. Check if user exists (Silent fail if security paranoid, but for UX we usually check)
-	_, err := s.userRepo.GetByEmail(req.Email)
+	u, err := s.userRepo.GetByEmail(req.Email)
 	if err != nil {
 		// User not found
-		return fmt.Errorf("user not found")
+		return "", fmt.Errorf("user not found")
+	}
+
+	channel, err := u.OTPChannelFor(req.Channel)
+	if err != nil {
+		return "", err
+	}
+	address := u.Email
+	if channel == user.OTPChannelSMS {
+		address = *u.Phone
+	}
+	// The code is keyed by email either way, which is what ResetPassword is given
+	if err := s.sendOTP("otp", req.Email, channel, address); err != nil {
+		return "", err
+	}
+
+	// 2. Email a reset link too, for web users. The OTP still works if this fails.
+	if s.resetLinks.enabled() {
+		if err := s.sendResetLink(u); err != nil {
+			logger.Warn("Failed to send password reset link", zap.String("user_id", u.ID.String()), zap.Error(err))
+		}
+	}
+	return channel, nil
+}
+
+// resetLinkKey holds the nonce of the user's current reset link
+func resetLinkKey(userID uuid.UUID) string {
+	return fmt.Sprintf("reset_link:%s", userID)
+}
+
+// sendResetLink emails a signed link to the reset page. Its nonce replaces
+// any earlier one, so only the newest link works.
+func (s *userService) sendResetLink(u *user.User) error {
+	nonce := make([]byte, user.ResetNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate reset link nonce: %w", err)
+	}
+	expiresAt := time.Now().Add(user.ResetLinkTTL)
+	if err := s.redisClient.Set(context.Background(), resetLinkKey(u.ID), hex.EncodeToString(nonce), user.ResetLinkTTL).Err(); err != nil {
+		return fmt.Errorf("failed to store reset link: %w", err)
 	}
 
-	// 2. Check Rate Limit (15 minutes)
-	// Key: rate_limit:otp:{email}
-	rateLimitKey := fmt.Sprintf("rate_limit:otp:%s", req.Email)
-	exists, err := s.redisClient.Exists(context.Background(), rateLimitKey).Result()
+	link := s.resetLinks.BaseURL + "?token=" + user.SignResetToken(s.resetLinks.Secret, u.ID, nonce, expiresAt)
+	email := &notifier.Email{
+		To:      u.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hello %s,\n\nOpen this link to choose a new password:\n%s\n\n"+
+			"The link works once and expires in %d minutes. You can also use the code sent to you.\n"+
+			"If you did not ask to reset your password, ignore this email.\n",
+			u.FirstName, link, int(user.ResetLinkTTL.Minutes())),
+	}
+	return s.notifier.SendEmail(context.Background(), email)
+}
+
+// sendOTP issues a 6-digit code for purpose, stored under {purpose}:{recipient}
+// for 15 minutes, and delivers it over channel to address. Each channel has
+// its own rate limit: a new code can be requested every 15 minutes.
+func (s *userService) sendOTP(purpose, recipient string, channel user.OTPChannel, address string) error {
+	ctx := context.Background()
+
+	// 1. Check Rate Limit (15 minutes)
+	// Key: rate_limit:{purpose}:{channel}:{recipient}
+	rateLimitKey := fmt.Sprintf("rate_limit:%s:%s:%s", purpose, channel, recipient)
+	allowed, err := s.redisClient.SetNX(ctx, rateLimitKey, "1", user.OTPTTL).Result()
 	if err != nil {
 		return fmt.Errorf("redis error: %w", err)
 	}
-	if exists > 0 {
+	if !allowed {
 		return fmt.Errorf("please wait 15 minutes before requesting a new OTP")
 	}
 
-	// 3. Generate 6-digit OTP
-	otp := fmt.Sprintf("%06d", crypto.GenerateSecureRandomInt(999999))
+	// 2. Generate 6-digit OTP
+	otp := fmt.Sprintf("%06d", crypto.GenerateSecureRandomInt(1000000))
 
-	// 4. Store OTP in Redis with 15m TTL
-	// Key: otp:{email}
-	otpKey := fmt.Sprintf("otp:%s", req.Email)
-	err = s.redisClient.Set(context.Background(), otpKey, otp, 15*time.Minute).Err()
-	if err != nil {
+	// 3. Store a keyed hash of the OTP in Redis with 15m TTL, never the code
+	// Key: {purpose}:{recipient}
+	otpKey := fmt.Sprintf("%s:%s", purpose, recipient)
+	if err := s.redisClient.HSet(ctx, otpKey, map[string]interface{}{
+		"hash":     s.otpHash(otpKey, otp),
+		"attempts": 0,
+	}).Err(); err != nil {
+		return fmt.Errorf("failed to store OTP: %w", err)
+	}
+	if err := s.redisClient.Expire(ctx, otpKey, user.OTPTTL).Err(); err != nil {
 		return fmt.Errorf("failed to store OTP: %w", err)
 	}
 
-	// 5. Set Rate Limit Key (15m TTL)
-	err = s.redisClient.Set(context.Background(), rateLimitKey, "1", 15*time.Minute).Err()
+	// 4. Deliver it. On failure the customer may ask again straight away.
+	text := fmt.Sprintf("Your MadaBank code is %s. It expires in %d minutes. Never share it with anyone, including MadaBank staff.",
+		otp, int(user.OTPTTL.Minutes()))
+	var to zap.Field
+	switch channel {
+	case user.OTPChannelSMS:
+		to = zap.String("phone", address)
+		err = s.smsSender.SendSMS(ctx, &notifier.SMS{To: address, Body: text})
+	default:
+		to = zap.String("email", address)
+		err = s.notifier.SendEmail(ctx, &notifier.Email{To: address, Subject: "Your MadaBank verification code", Body: text + "\n"})
+	}
 	if err != nil {
-		return fmt.Errorf("failed to set rate limit: %w", err)
+		s.redisClient.Del(ctx, otpKey, rateLimitKey)
+		logger.Error("Failed to send OTP", to, zap.String("channel", string(channel)), zap.Error(err))
+		return fmt.Errorf("failed to send OTP, please try again")
 	}
 
-	// 6. Send OTP (Mock for now)
-	logger.Info("🔑 [MOCK EMAIL] OTP Sent",
-		zap.String("email", req.Email),
-		zap.String("otp_code", otp),
-	)
+	// The code itself is never logged
+	logger.Info("🔑 OTP sent", to, zap.String("channel", string(channel)), zap.String("purpose", strings.SplitN(purpose, ":", 2)[0]))
 
 	return nil
 }
 
-func (s *userService) ResetPassword(req *user.ResetPasswordRequest) error {
-	// 1. Verify OTP
-	otpKey := fmt.Sprintf("otp:%s", req.Email)
-	storedOTP, err := s.redisClient.Get(context.Background(), otpKey).Result()
-	if err == redis.Nil {
-		return fmt.Errorf("invalid or expired OTP")
-	} else if err != nil {
+// otpHash binds a code to the key it was issued under, so a code stored for
+// one purpose or recipient never matches another
+func (s *userService) otpHash(otpKey, otp string) string {
+	return s.encryptor.Fingerprint(otpKey + ":" + otp)
+}
+
+// verifyOTP checks a code against the one issued under otpKey and consumes
+// it on a match. After user.MaxOTPAttempts wrong codes the code is discarded;
+// since a new one can only be requested every 15 minutes, that locks the
+// flow.
+func (s *userService) verifyOTP(otpKey, otp string) error {
+	result, err := verifyOTPScript.Run(context.Background(), s.redisClient, []string{otpKey}, s.otpHash(otpKey, otp), user.MaxOTPAttempts).Int()
+	if err != nil {
 		return fmt.Errorf("redis error: %w", err)
 	}
-
-	if storedOTP != req.OTP {
+	switch result {
+	case 0:
+		return nil
+	case -1:
+		return fmt.Errorf("invalid or expired OTP")
+	case -2:
+		logger.Warn("OTP discarded after too many incorrect codes", zap.String("purpose", strings.SplitN(otpKey, ":", 2)[0]))
+		return fmt.Errorf("too many incorrect codes, request a new one")
+	default:
 		return fmt.Errorf("invalid OTP code")
 	}
+}
+
+func (s *userService) ResetPassword(req *user.ResetPasswordRequest) error {
+	// Check the new password first, so a rejected one does not use up the code
+	if err := s.passwordPolicy.Validate(context.Background(), req.NewPassword); err != nil {
+		return err
+	}
+
+	// 1. Verify OTP
+	otpKey := fmt.Sprintf("otp:%s", req.Email)
+	if err := s.verifyOTP(otpKey, req.OTP); err != nil {
+		return err
+	}
 
 	// 2. Get User
 	u, err := s.userRepo.GetByEmail(req.Email)
@@ -463,9 +922,332 @@ func (s *userService) ResetPassword(req *user.ResetPasswordRequest) error {
 		return fmt.Errorf("failed to update password: %w", err)
 	}
 
-	// 4. Delete OTP (Prevent replay)
-	s.redisClient.Del(context.Background(), otpKey)
+	// 4. Delete the reset link sent with the code. Failed logins before the
+	// reset no longer slow the user down.
+	s.redisClient.Del(context.Background(), resetLinkKey(u.ID), loginFailureKey(u.ID.String()))
 
 	logger.Info("✅ Password reset successfully", zap.String("email", req.Email))
 	return nil
 }
+
+func (s *userService) ResetPasswordWithLink(req *user.ResetPasswordLinkRequest) error {
+	if !s.resetLinks.enabled() {
+		return fmt.Errorf("password reset links are not enabled")
+	}
+
+	// 1. Verify the signature and expiry
+	userID, nonce, err := user.ParseResetToken(s.resetLinks.Secret, req.Token, time.Now())
+	if err != nil {
+		return err
+	}
+
+	// 2. Check the password before spending the link, so the user can retry
+	if err := s.passwordPolicy.Validate(context.Background(), req.NewPassword); err != nil {
+		return err
+	}
+
+	// 3. Spend the link. It fails if the link was used, replaced by a newer
+	// one or cancelled by a reset with the OTP.
+	consumed, err := consumeResetNonceScript.Run(context.Background(), s.redisClient, []string{resetLinkKey(userID)}, hex.EncodeToString(nonce)).Int()
+	if err != nil {
+		return fmt.Errorf("redis error: %w", err)
+	}
+	if consumed == 0 {
+		return fmt.Errorf("password reset link has already been used or replaced")
+	}
+
+	u, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return fmt.Errorf("user not found")
+	}
+
+	// 4. Update Password
+	newHash, err := crypto.HashPassword(req.NewPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	if err := s.userRepo.Update(u.ID, map[string]interface{}{"password_hash": newHash}); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	// 5. The OTP sent with the link must not work afterwards
+	s.redisClient.Del(context.Background(), fmt.Sprintf("otp:%s", u.Email), loginFailureKey(u.ID.String()))
+
+	logger.Info("✅ Password reset with link", zap.String("user_id", u.ID.String()))
+	return nil
+}
+
+func (s *userService) ChangePassword(userID uuid.UUID, req *user.ChangePasswordRequest) error {
+	u, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if !crypto.CheckPassword(req.CurrentPassword, u.PasswordHash) {
+		return fmt.Errorf("invalid password")
+	}
+	if req.NewPassword == req.CurrentPassword {
+		return fmt.Errorf("new password must be different from the current one")
+	}
+	if err := s.passwordPolicy.Validate(context.Background(), req.NewPassword); err != nil {
+		return err
+	}
+
+	newHash, err := crypto.HashPassword(req.NewPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	if err := s.userRepo.Update(userID, map[string]interface{}{"password_hash": newHash}); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	// Sessions opened with the old password must not outlive it
+	if err := s.userRepo.RevokeAllRefreshTokens(userID); err != nil {
+		logger.Error("Failed to revoke sessions after password change", zap.String("user_id", userID.String()), zap.Error(err))
+		errtrack.CaptureError(err, map[string]string{"component": "user_service", "operation": "change_password"})
+	}
+
+	logger.Info("✅ Password changed", zap.String("user_id", userID.String()))
+	return nil
+}
+
+func (s *userService) ListUsers(f *user.ListFilter) (*user.UserListResponse, error) {
+	users, err := s.userRepo.List(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	resp := &user.UserListResponse{Users: users}
+	if len(users) > f.Limit {
+		resp.Users = users[:f.Limit]
+		last := resp.Users[f.Limit-1]
+		resp.NextCursor = (&user.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}).Encode()
+		resp.HasMore = true
+	}
+
+	return resp, nil
+}
+
+func (s *userService) ResolveHandle(handle string) (*user.HandleResolution, error) {
+	username, err := user.NormalizeUsername(handle)
+	if err != nil {
+		return nil, fmt.Errorf("no customer with this handle")
+	}
+	u, err := s.userRepo.GetByUsername(username)
+	if err != nil || !u.IsActive {
+		return nil, fmt.Errorf("no customer with this handle")
+	}
+
+	accounts, err := s.accountRepo.GetByUserID(u.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get accounts: %w", err)
+	}
+	if domainAccount.ReceivingAccount(accounts) == nil {
+		return nil, fmt.Errorf("customer has no active account to receive transfers")
+	}
+
+	return &user.HandleResolution{
+		Handle:      user.Handle(username),
+		DisplayName: transaction.ShortHolderName(u.FirstName, u.LastName),
+	}, nil
+}
+
+// emailChangePurpose ties an email change code to the user who asked for it
+func emailChangePurpose(userID uuid.UUID) string {
+	return fmt.Sprintf("email_change:%s", userID)
+}
+
+func (s *userService) RequestEmailChange(userID uuid.UUID, req *user.EmailChangeRequest) error {
+	u, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if !crypto.CheckPassword(req.Password, u.PasswordHash) {
+		return fmt.Errorf("invalid password")
+	}
+	if strings.EqualFold(req.NewEmail, u.Email) {
+		return fmt.Errorf("new email is the same as the current one")
+	}
+	if existing, _ := s.userRepo.GetByEmail(req.NewEmail); existing != nil {
+		return fmt.Errorf("user with this email already exists")
+	}
+
+	return s.sendOTP(emailChangePurpose(userID), req.NewEmail, user.OTPChannelEmail, req.NewEmail)
+}
+
+func (s *userService) ConfirmEmailChange(userID uuid.UUID, req *user.ConfirmEmailChangeRequest) (*user.User, error) {
+	// 1. Verify OTP, which is only valid for the address it was sent to
+	otpKey := fmt.Sprintf("%s:%s", emailChangePurpose(userID), req.NewEmail)
+	if err := s.verifyOTP(otpKey, req.OTP); err != nil {
+		return nil, err
+	}
+
+	u, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if existing, _ := s.userRepo.GetByEmail(req.NewEmail); existing != nil {
+		return nil, fmt.Errorf("user with this email already exists")
+	}
+
+	// 2. Switch the address
+	if err := s.userRepo.Update(userID, map[string]interface{}{"email": req.NewEmail}); err != nil {
+		return nil, fmt.Errorf("failed to change email: %w", err)
+	}
+
+	// 3. Sign out every session. Access tokens already issued stay valid
+	// until they expire.
+	if err := s.userRepo.RevokeAllRefreshTokens(userID); err != nil {
+		logger.Error("Failed to revoke sessions after email change", zap.String("user_id", userID.String()), zap.Error(err))
+		errtrack.CaptureError(err, map[string]string{"component": "user_service", "operation": "email_change"})
+	}
+
+	// 5. Tell the old address, so an unexpected change can be reported
+	notice := &notifier.Email{
+		To:      u.Email,
+		Subject: "Your email address was changed",
+		Body: fmt.Sprintf("Hello %s,\n\nThe email address of your profile was changed to %s on %s.\n"+
+			"If you did not make this change, contact support immediately.\n",
+			u.FirstName, req.NewEmail, time.Now().UTC().Format("2 January 2006 15:04 MST")),
+	}
+	if err := s.notifier.SendEmail(context.Background(), notice); err != nil {
+		logger.Warn("Failed to notify old email address of change", zap.String("user_id", userID.String()), zap.Error(err))
+	}
+
+	logger.Info("✅ Email changed", zap.String("user_id", userID.String()))
+
+	return s.GetProfile(userID)
+}
+
+// phoneOTPPurpose ties a phone verification code to the user, so two
+// profiles claiming the same number cannot use each other's code
+func phoneOTPPurpose(userID uuid.UUID) string {
+	return fmt.Sprintf("phone_otp:%s", userID)
+}
+
+func (s *userService) RequestPhoneVerification(userID uuid.UUID) error {
+	u, err := s.GetProfile(userID)
+	if err != nil {
+		return err
+	}
+	if u.Phone == nil {
+		return fmt.Errorf("no phone number on profile")
+	}
+	if u.PhoneVerified() {
+		return fmt.Errorf("phone number is already verified")
+	}
+	if owner, err := s.userRepo.GetByPhone(*u.Phone); err == nil && owner.ID != userID {
+		return fmt.Errorf("phone number is already registered to another customer")
+	}
+
+	return s.sendOTP(phoneOTPPurpose(userID), *u.Phone, user.OTPChannelSMS, *u.Phone)
+}
+
+func (s *userService) VerifyPhone(userID uuid.UUID, req *user.VerifyPhoneRequest) (*user.User, error) {
+	u, err := s.GetProfile(userID)
+	if err != nil {
+		return nil, err
+	}
+	if u.Phone == nil {
+		return nil, fmt.Errorf("no phone number on profile")
+	}
+
+	// The code is only valid for the number it was sent to
+	otpKey := fmt.Sprintf("%s:%s", phoneOTPPurpose(userID), *u.Phone)
+	if err := s.verifyOTP(otpKey, req.OTP); err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.VerifyPhone(userID, *u.Phone); err != nil {
+		return nil, err
+	}
+
+	return s.GetProfile(userID)
+}
+
+const stepUpPurpose = "step_up"
+
+func (s *userService) RequestStepUp(userID uuid.UUID) (user.OTPChannel, error) {
+	u, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return "", fmt.Errorf("user not found")
+	}
+	return s.sendStepUp(u)
+}
+
+// sendStepUp texts the code when the phone number is verified and emails it
+// otherwise
+func (s *userService) sendStepUp(u *user.User) (user.OTPChannel, error) {
+	channel, err := u.OTPChannelFor("")
+	if err != nil {
+		return "", err
+	}
+	address := u.Email
+	if channel == user.OTPChannelSMS {
+		address = *u.Phone
+	}
+	if err := s.sendOTP(stepUpPurpose, u.ID.String(), channel, address); err != nil {
+		return "", err
+	}
+	return channel, nil
+}
+
+func (s *userService) VerifyStepUp(userID uuid.UUID, otp string) error {
+	otpKey := fmt.Sprintf("%s:%s", stepUpPurpose, userID)
+	if err := s.verifyOTP(otpKey, otp); err != nil {
+		return err
+	}
+
+	// The next challenged action may ask for a new code straight away rather
+	// than wait out the resend limit
+	s.redisClient.Del(context.Background(),
+		fmt.Sprintf("rate_limit:%s:%s:%s", stepUpPurpose, user.OTPChannelEmail, userID),
+		fmt.Sprintf("rate_limit:%s:%s:%s", stepUpPurpose, user.OTPChannelSMS, userID),
+	)
+	return nil
+}
+
+// reactivationCandidate finds the user to reactivate and checks the password
+// and grace window. Unknown emails and wrong passwords get the same error.
+func (s *userService) reactivationCandidate(email, password string) (*user.User, error) {
+	u, err := s.userRepo.GetForReactivation(email)
+	if err != nil || !crypto.CheckPassword(password, u.PasswordHash) {
+		return nil, fmt.Errorf("invalid email or password")
+	}
+	if err := u.CanReactivate(time.Now()); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func (s *userService) RequestReactivation(req *user.ReactivationOTPRequest) error {
+	if _, err := s.reactivationCandidate(req.Email, req.Password); err != nil {
+		return err
+	}
+	return s.sendOTP("reactivation_otp", req.Email, user.OTPChannelEmail, req.Email)
+}
+
+func (s *userService) Reactivate(req *user.ReactivateRequest) (*user.User, error) {
+	// 1. Verify OTP
+	otpKey := fmt.Sprintf("reactivation_otp:%s", req.Email)
+	if err := s.verifyOTP(otpKey, req.OTP); err != nil {
+		return nil, err
+	}
+
+	// 2. Verify password and grace window
+	u, err := s.reactivationCandidate(req.Email, req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Restore the profile and the accounts frozen when it was deleted
+	if err := s.userRepo.Restore(u.ID); err != nil {
+		return nil, fmt.Errorf("failed to reactivate account: %w", err)
+	}
+
+	logger.Info("✅ User reactivated", zap.String("user_id", u.ID.String()))
+
+	u.DeletedAt = nil
+	u.IsActive = true
+	u.PasswordHash = ""
+	return u, nil
+}
@@ -2,7 +2,11 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -13,10 +17,13 @@ import (
 	"github.com/darisadam/madabank-server/internal/pkg/crypto"
 	"github.com/darisadam/madabank-server/internal/pkg/jwt"
 	"github.com/darisadam/madabank-server/internal/pkg/logger"
+	"github.com/darisadam/madabank-server/internal/pkg/notifier"
+	"github.com/darisadam/madabank-server/internal/pkg/passwordpolicy"
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
 )
 
 // MockUserRepository is a mock implementation of repository.UserRepository
@@ -55,22 +62,82 @@ func (m *MockUserRepository) Delete(id uuid.UUID) error {
 	return args.Error(0)
 }
 
-func (m *MockUserRepository) List(limit, offset int) ([]*user.User, error) {
-	args := m.Called(limit, offset)
+func (m *MockUserRepository) List(f *user.ListFilter) ([]*user.User, error) {
+	args := m.Called(f)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]*user.User), args.Error(1)
 }
 
-func (m *MockUserRepository) SaveRefreshToken(userID uuid.UUID, tokenHash string, expiresAt time.Time) error {
-	args := m.Called(userID, tokenHash, expiresAt)
+func (m *MockUserRepository) GetByUsername(username string) (*user.User, error) {
+	args := m.Called(username)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.User), args.Error(1)
+}
+
+func (m *MockUserRepository) RevokeAllRefreshTokens(userID uuid.UUID) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetLoginFamiliarity(userID uuid.UUID, origin user.LoginOrigin) (*user.LoginFamiliarity, error) {
+	args := m.Called(userID, origin)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.LoginFamiliarity), args.Error(1)
+}
+
+func (m *MockUserRepository) RecordLogin(userID uuid.UUID, origin user.LoginOrigin) error {
+	args := m.Called(userID, origin)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) VerifyPhone(id uuid.UUID, phone string) error {
+	args := m.Called(id, phone)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetForReactivation(email string) (*user.User, error) {
+	args := m.Called(email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.User), args.Error(1)
+}
+
+func (m *MockUserRepository) Restore(id uuid.UUID) error {
+	args := m.Called(id)
 	return args.Error(0)
 }
 
-func (m *MockUserRepository) GetRefreshToken(tokenHash string) (uuid.UUID, time.Time, error) {
+func (m *MockUserRepository) ListAnonymizable(deletedBefore time.Time, limit int) ([]uuid.UUID, error) {
+	args := m.Called(deletedBefore, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockUserRepository) Anonymize(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) SaveRefreshToken(userID uuid.UUID, tokenHash string, clientType user.ClientType, expiresAt time.Time) error {
+	args := m.Called(userID, tokenHash, clientType, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetRefreshToken(tokenHash string) (*user.RefreshSession, error) {
 	args := m.Called(tokenHash)
-	return args.Get(0).(uuid.UUID), args.Get(1).(time.Time), args.Error(2)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.RefreshSession), args.Error(1)
 }
 
 func (m *MockUserRepository) RevokeRefreshToken(tokenHash string) error {
@@ -104,6 +171,14 @@ func (m *MockAccountRepositoryForUser) GetByID(id uuid.UUID) (*account.Account,
 	return args.Get(0).(*account.Account), args.Error(1)
 }
 
+func (m *MockAccountRepositoryForUser) GetByIDs(ids []uuid.UUID) ([]*account.Account, error) {
+	args := m.Called(ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*account.Account), args.Error(1)
+}
+
 func (m *MockAccountRepositoryForUser) GetByUserID(userID uuid.UUID) ([]*account.Account, error) {
 	args := m.Called(userID)
 	if args.Get(0) == nil {
@@ -112,6 +187,27 @@ func (m *MockAccountRepositoryForUser) GetByUserID(userID uuid.UUID) ([]*account
 	return args.Get(0).([]*account.Account), args.Error(1)
 }
 
+func (m *MockAccountRepositoryForUser) ListByUser(f *account.ListFilter) ([]*account.Account, error) {
+	args := m.Called(f)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*account.Account), args.Error(1)
+}
+
+func (m *MockAccountRepositoryForUser) CountByUser(f *account.ListFilter) (int, error) {
+	args := m.Called(f)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockAccountRepositoryForUser) GetHolders(ids []uuid.UUID) ([]*account.Holder, error) {
+	args := m.Called(ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*account.Holder), args.Error(1)
+}
+
 func (m *MockAccountRepositoryForUser) GetByAccountNumber(number string) (*account.Account, error) {
 	args := m.Called(number)
 	if args.Get(0) == nil {
@@ -120,8 +216,8 @@ func (m *MockAccountRepositoryForUser) GetByAccountNumber(number string) (*accou
 	return args.Get(0).(*account.Account), args.Error(1)
 }
 
-func (m *MockAccountRepositoryForUser) Update(id uuid.UUID, updates map[string]interface{}) error {
-	args := m.Called(id, updates)
+func (m *MockAccountRepositoryForUser) Update(id uuid.UUID, version int, updates map[string]interface{}) error {
+	args := m.Called(id, version, updates)
 	return args.Error(0)
 }
 
@@ -135,11 +231,29 @@ func (m *MockAccountRepositoryForUser) GenerateAccountNumber() (string, error) {
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockAccountRepositoryForUser) UpdateBalance(id uuid.UUID, amount float64) error {
-	args := m.Called(id, amount)
+func (m *MockAccountRepositoryForUser) UpdateBalance(id uuid.UUID, version int, amount float64) error {
+	args := m.Called(id, version, amount)
 	return args.Error(0)
 }
 
+func (m *MockAccountRepositoryForUser) AddCurrency(accountID uuid.UUID, currency string) error {
+	args := m.Called(accountID, currency)
+	return args.Error(0)
+}
+
+func (m *MockAccountRepositoryForUser) ListBalances(accountID uuid.UUID) ([]*account.CurrencyBalance, error) {
+	args := m.Called(accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*account.CurrencyBalance), args.Error(1)
+}
+
+func (m *MockAccountRepositoryForUser) SumActiveHolds(accountID uuid.UUID) (float64, error) {
+	args := m.Called(accountID)
+	return args.Get(0).(float64), args.Error(1)
+}
+
 // MockCardRepositoryForUser is a mock implementation for UserService tests
 type MockCardRepositoryForUser struct {
 	mock.Mock
@@ -150,6 +264,11 @@ func (m *MockCardRepositoryForUser) Create(c *card.Card) error {
 	return args.Error(0)
 }
 
+func (m *MockCardRepositoryForUser) CreateReplacement(originalID uuid.UUID, replacement *card.Card) error {
+	args := m.Called(originalID, replacement)
+	return args.Error(0)
+}
+
 func (m *MockCardRepositoryForUser) GetByID(id uuid.UUID) (*card.Card, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
@@ -166,26 +285,57 @@ func (m *MockCardRepositoryForUser) GetByAccountID(accountID uuid.UUID) ([]*card
 	return args.Get(0).([]*card.Card), args.Error(1)
 }
 
+func (m *MockCardRepositoryForUser) GetByNumberHash(hash string) (*card.Card, error) {
+	args := m.Called(hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*card.Card), args.Error(1)
+}
+
 func (m *MockCardRepositoryForUser) Update(id uuid.UUID, updates map[string]interface{}) error {
 	args := m.Called(id, updates)
 	return args.Error(0)
 }
 
+func (m *MockCardRepositoryForUser) UpdateControls(id uuid.UUID, controls card.Controls) error {
+	args := m.Called(id, controls)
+	return args.Error(0)
+}
+
 func (m *MockCardRepositoryForUser) Delete(id uuid.UUID) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
 
+func (m *MockCardRepositoryForUser) ExpireCards(today string) (int64, error) {
+	args := m.Called(today)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *MockCardRepositoryForUser) GenerateCardNumber() (string, error) {
 	args := m.Called()
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockCardRepositoryForUser) RecordPINAttempt(id uuid.UUID, correct bool, now time.Time) (*card.PINAttempt, error) {
+	args := m.Called(id, correct, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*card.PINAttempt), args.Error(1)
+}
+
 func (m *MockCardRepositoryForUser) GenerateCVV() string {
 	args := m.Called()
 	return args.String(0)
 }
 
+// testTokenTTLs gives web logins short tokens; other clients get the defaults
+var testTokenTTLs = user.TokenTTLs{
+	user.ClientTypeWeb: {Access: 15 * time.Minute, Refresh: 12 * time.Hour, SessionMax: 12 * time.Hour},
+}
+
 func setupTest(t *testing.T) (*userService, *MockUserRepository, *MockAccountRepositoryForUser, *MockCardRepositoryForUser, *miniredis.Miniredis) {
 	// Initialize Logger
 	logger.Init("test")
@@ -209,29 +359,52 @@ func setupTest(t *testing.T) (*userService, *MockUserRepository, *MockAccountRep
 	// Setup Encryptor (32-byte key for AES-256)
 	encryptor, _ := crypto.NewEncryptor("12345678901234567890123456789012")
 
+	// Length-only policy, so fixtures can use simple passwords
+	passwordPolicy, _ := passwordpolicy.NewValidator(passwordpolicy.Policy{MinLength: 8}, nil)
+
 	// Create Service
-	svc := NewUserService(mockUserRepo, mockAccountRepo, mockCardRepo, jwtSvc, redisClient, encryptor).(*userService)
+	svc := NewUserService(mockUserRepo, mockAccountRepo, mockCardRepo, jwtSvc, redisClient, encryptor, new(MockNotifier), new(MockSMSSender), passwordPolicy, ResetLinkConfig{}, LoginAlertConfig{}, testTokenTTLs).(*userService)
 
 	return svc, mockUserRepo, mockAccountRepo, mockCardRepo, mr
 }
 
+// storeOTP stands in for sendOTP, storing a known code under otpKey
+func storeOTP(svc *userService, otpKey, otp string) {
+	svc.redisClient.HSet(context.Background(), otpKey, map[string]interface{}{"hash": svc.otpHash(otpKey, otp), "attempts": 0})
+	svc.redisClient.Expire(context.Background(), otpKey, user.OTPTTL)
+}
+
+// expectKnownLogin makes a login come from a device and country the user
+// logged in from before
+func expectKnownLogin(mockRepo *MockUserRepository, userID uuid.UUID) {
+	mockRepo.On("GetLoginFamiliarity", userID, mock.Anything).Return(&user.LoginFamiliarity{KnownDevice: true, KnownCountry: true}, nil)
+	mockRepo.On("RecordLogin", userID, mock.Anything).Return(nil)
+}
+
 func TestForgotPassword_Success(t *testing.T) {
 	svc, mockRepo, _, _, _ := setupTest(t)
 	email := "test@example.com"
 
 	// Mock User Exists
 	mockRepo.On("GetByEmail", email).Return(&user.User{ID: uuid.New(), Email: email}, nil)
+	mockNotifier := svc.notifier.(*MockNotifier)
+	mockNotifier.On("SendEmail", mock.MatchedBy(func(e *notifier.Email) bool {
+		return e.To == email && e.Subject == "Your MadaBank verification code"
+	})).Return(nil)
 
-	err := svc.ForgotPassword(&user.ForgotPasswordRequest{Email: email})
+	channel, err := svc.ForgotPassword(&user.ForgotPasswordRequest{Email: email})
 	assert.NoError(t, err)
+	assert.Equal(t, user.OTPChannelEmail, channel)
+	mockNotifier.AssertExpectations(t)
 
-	// Verify OTP is stored in Redis
+	// Verify OTP is stored in Redis, as a hash only
 	otpKey := fmt.Sprintf("otp:%s", email)
-	exists, _ := svc.redisClient.Exists(context.Background(), otpKey).Result()
-	assert.Equal(t, int64(1), exists)
+	stored, _ := svc.redisClient.HGetAll(context.Background(), otpKey).Result()
+	assert.Len(t, stored["hash"], 64)
+	assert.NotContains(t, stored, "otp")
 
 	// Verify Rate Limit is set
-	rateLimitKey := fmt.Sprintf("rate_limit:otp:%s", email)
+	rateLimitKey := fmt.Sprintf("rate_limit:otp:email:%s", email)
 	rlExists, _ := svc.redisClient.Exists(context.Background(), rateLimitKey).Result()
 	assert.Equal(t, int64(1), rlExists)
 }
@@ -244,10 +417,10 @@ func TestForgotPassword_RateLimited(t *testing.T) {
 	mockRepo.On("GetByEmail", email).Return(&user.User{ID: uuid.New(), Email: email}, nil)
 
 	// Set Rate Limit Key directly
-	rateLimitKey := fmt.Sprintf("rate_limit:otp:%s", email)
+	rateLimitKey := fmt.Sprintf("rate_limit:otp:email:%s", email)
 	svc.redisClient.Set(context.Background(), rateLimitKey, "1", 15*time.Minute)
 
-	err := svc.ForgotPassword(&user.ForgotPasswordRequest{Email: email})
+	_, err := svc.ForgotPassword(&user.ForgotPasswordRequest{Email: email})
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "please wait 15 minutes")
 }
@@ -259,7 +432,7 @@ func TestForgotPassword_UserNotFound(t *testing.T) {
 	// Mock User Not Found
 	mockRepo.On("GetByEmail", email).Return((*user.User)(nil), fmt.Errorf("user not found"))
 
-	err := svc.ForgotPassword(&user.ForgotPasswordRequest{Email: email})
+	_, err := svc.ForgotPassword(&user.ForgotPasswordRequest{Email: email})
 	assert.Error(t, err)
 	assert.Equal(t, "user not found", err.Error())
 }
@@ -273,7 +446,7 @@ func TestResetPassword_Success(t *testing.T) {
 
 	// Setup Redis with Valid OTP
 	otpKey := fmt.Sprintf("otp:%s", email)
-	svc.redisClient.Set(context.Background(), otpKey, otp, 15*time.Minute)
+	storeOTP(svc, otpKey, otp)
 
 	// Mock Expectations
 	mockRepo.On("GetByEmail", email).Return(&user.User{ID: uid, Email: email}, nil)
@@ -297,12 +470,12 @@ func TestResetPassword_InvalidOTP(t *testing.T) {
 
 	// Setup Redis with Valid OTP
 	otpKey := fmt.Sprintf("otp:%s", email)
-	svc.redisClient.Set(context.Background(), otpKey, "123456", 15*time.Minute)
+	storeOTP(svc, otpKey, "123456")
 
 	err := svc.ResetPassword(&user.ResetPasswordRequest{
 		Email:       email,
 		OTP:         "000000", // Wrong OTP
-		NewPassword: "new",
+		NewPassword: "newSecret123",
 	})
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid OTP code")
@@ -316,7 +489,7 @@ func TestResetPassword_ExpiredOTP(t *testing.T) {
 	err := svc.ResetPassword(&user.ResetPasswordRequest{
 		Email:       email,
 		OTP:         "123456",
-		NewPassword: "new",
+		NewPassword: "newSecret123",
 	})
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid or expired OTP")
@@ -382,7 +555,8 @@ func TestLogin_Success(t *testing.T) {
 
 	mockRepo.On("GetByEmail", email).Return(u, nil)
 	// Expect SaveRefreshToken to be called
-	mockRepo.On("SaveRefreshToken", u.ID, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(nil)
+	mockRepo.On("SaveRefreshToken", u.ID, mock.AnythingOfType("string"), user.ClientTypeDefault, mock.AnythingOfType("time.Time")).Return(nil)
+	expectKnownLogin(mockRepo, u.ID)
 
 	resp, err := svc.Login(&user.LoginRequest{Email: email, Password: password})
 	assert.NoError(t, err)
@@ -391,6 +565,26 @@ func TestLogin_Success(t *testing.T) {
 	assert.NotEmpty(t, resp.Token)
 }
 
+func TestLogin_WebClientGetsShortTokens(t *testing.T) {
+	svc, mockRepo, _, _, _ := setupTest(t)
+	email := "web@example.com"
+	password := "password123"
+
+	hash, _ := crypto.HashPassword(password)
+	u := &user.User{ID: uuid.New(), Email: email, PasswordHash: hash, IsActive: true}
+
+	mockRepo.On("GetByEmail", email).Return(u, nil)
+	mockRepo.On("SaveRefreshToken", u.ID, mock.AnythingOfType("string"), user.ClientTypeWeb, mock.AnythingOfType("time.Time")).Return(nil)
+	expectKnownLogin(mockRepo, u.ID)
+
+	resp, err := svc.Login(&user.LoginRequest{Email: email, Password: password, ClientType: "web"})
+
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), resp.ExpiresAt, 5*time.Second)
+	assert.WithinDuration(t, time.Now().Add(12*time.Hour), resp.RefreshExpiresAt, 5*time.Second)
+	mockRepo.AssertExpectations(t)
+}
+
 func TestLogin_InvalidPassword(t *testing.T) {
 	svc, mockRepo, _, _, _ := setupTest(t)
 	email := "badpass@example.com"
@@ -404,6 +598,7 @@ func TestLogin_InvalidPassword(t *testing.T) {
 	}
 
 	mockRepo.On("GetByEmail", email).Return(u, nil)
+	expectKnownLogin(mockRepo, u.ID)
 
 	resp, err := svc.Login(&user.LoginRequest{Email: email, Password: "wrongPassword"})
 	assert.Error(t, err)
@@ -411,6 +606,56 @@ func TestLogin_InvalidPassword(t *testing.T) {
 	assert.Contains(t, err.Error(), "invalid email or password")
 }
 
+func TestLogin_UpgradesLegacyBcryptHash(t *testing.T) {
+	svc, mockRepo, _, _, _ := setupTest(t)
+	email := "legacy@example.com"
+	password := "password123"
+
+	legacy, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	u := &user.User{
+		ID:           uuid.New(),
+		Email:        email,
+		PasswordHash: string(legacy),
+		IsActive:     true,
+	}
+
+	mockRepo.On("GetByEmail", email).Return(u, nil)
+	mockRepo.On("Update", u.ID, mock.MatchedBy(func(updates map[string]interface{}) bool {
+		hash, ok := updates["password_hash"].(string)
+		return ok && !crypto.NeedsRehash(hash) && crypto.CheckPassword(password, hash)
+	})).Return(nil)
+	mockRepo.On("SaveRefreshToken", u.ID, mock.AnythingOfType("string"), user.ClientTypeDefault, mock.AnythingOfType("time.Time")).Return(nil)
+	expectKnownLogin(mockRepo, u.ID)
+
+	resp, err := svc.Login(&user.LoginRequest{Email: email, Password: password})
+	assert.NoError(t, err)
+	assert.NotNil(t, resp)
+	mockRepo.AssertCalled(t, "Update", u.ID, mock.Anything)
+}
+
+func TestLogin_RehashFailureDoesNotBlockLogin(t *testing.T) {
+	svc, mockRepo, _, _, _ := setupTest(t)
+	email := "legacyfail@example.com"
+	password := "password123"
+
+	legacy, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	u := &user.User{
+		ID:           uuid.New(),
+		Email:        email,
+		PasswordHash: string(legacy),
+		IsActive:     true,
+	}
+
+	mockRepo.On("GetByEmail", email).Return(u, nil)
+	mockRepo.On("Update", u.ID, mock.Anything).Return(fmt.Errorf("db down"))
+	mockRepo.On("SaveRefreshToken", u.ID, mock.AnythingOfType("string"), user.ClientTypeDefault, mock.AnythingOfType("time.Time")).Return(nil)
+	expectKnownLogin(mockRepo, u.ID)
+
+	resp, err := svc.Login(&user.LoginRequest{Email: email, Password: password})
+	assert.NoError(t, err)
+	assert.NotNil(t, resp)
+}
+
 func TestLogin_ByPhone_Success(t *testing.T) {
 	svc, mockRepo, _, _, _ := setupTest(t)
 	phone := "+6281234567890"
@@ -418,16 +663,19 @@ func TestLogin_ByPhone_Success(t *testing.T) {
 
 	// Create user with hashed password
 	hash, _ := crypto.HashPassword(password)
+	verifiedAt := time.Now().Add(-time.Hour)
 	u := &user.User{
-		ID:           uuid.New(),
-		Email:        "phone-user@example.com",
-		Phone:        &phone,
-		PasswordHash: hash,
-		IsActive:     true,
+		ID:              uuid.New(),
+		Email:           "phone-user@example.com",
+		Phone:           &phone,
+		PhoneVerifiedAt: &verifiedAt,
+		PasswordHash:    hash,
+		IsActive:        true,
 	}
 
 	mockRepo.On("GetByPhone", phone).Return(u, nil)
-	mockRepo.On("SaveRefreshToken", u.ID, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(nil)
+	mockRepo.On("SaveRefreshToken", u.ID, mock.AnythingOfType("string"), user.ClientTypeDefault, mock.AnythingOfType("time.Time")).Return(nil)
+	expectKnownLogin(mockRepo, u.ID)
 
 	resp, err := svc.Login(&user.LoginRequest{Phone: phone, Password: password})
 	assert.NoError(t, err)
@@ -436,13 +684,27 @@ func TestLogin_ByPhone_Success(t *testing.T) {
 	assert.NotEmpty(t, resp.RefreshToken)
 }
 
+func TestLogin_ByPhone_Unverified(t *testing.T) {
+	svc, mockRepo, _, _, _ := setupTest(t)
+	phone := "+6281234567890"
+	hash, _ := crypto.HashPassword("password123")
+	u := &user.User{ID: uuid.New(), Phone: &phone, PasswordHash: hash, IsActive: true}
+
+	mockRepo.On("GetByPhone", phone).Return(u, nil)
+
+	resp, err := svc.Login(&user.LoginRequest{Phone: phone, Password: "password123"})
+	assert.Nil(t, resp)
+	assert.EqualError(t, err, "invalid phone number or password")
+	mockRepo.AssertNotCalled(t, "SaveRefreshToken", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestLogin_NoCredentials(t *testing.T) {
 	svc, _, _, _, _ := setupTest(t)
 
 	resp, err := svc.Login(&user.LoginRequest{Password: "password123"})
 	assert.Error(t, err)
 	assert.Nil(t, resp)
-	assert.Contains(t, err.Error(), "email or phone number is required")
+	assert.Contains(t, err.Error(), "email, phone number or username is required")
 }
 
 func TestGetProfile_Success(t *testing.T) {
@@ -480,7 +742,7 @@ func TestRefreshToken_Success(t *testing.T) {
 	expiresAt := time.Now().Add(time.Hour)
 
 	// Mock GetRefreshToken
-	mockRepo.On("GetRefreshToken", token).Return(uid, expiresAt, nil)
+	mockRepo.On("GetRefreshToken", token).Return(&user.RefreshSession{UserID: uid, ClientType: user.ClientTypeDefault, ExpiresAt: expiresAt, SessionStartedAt: time.Now().Add(-time.Hour)}, nil)
 	// Mock GetByID
 	mockRepo.On("GetByID", uid).Return(&user.User{ID: uid, Email: "refresh@example.com"}, nil)
 
@@ -490,13 +752,46 @@ func TestRefreshToken_Success(t *testing.T) {
 	assert.NotEmpty(t, resp.Token)
 }
 
+func TestRefreshToken_ClampedToSessionMax(t *testing.T) {
+	svc, mockRepo, _, _, _ := setupTest(t)
+	token := "web_refresh_token"
+	uid := uuid.New()
+	// The web session began 11h50m ago and ends after 12 hours
+	started := time.Now().Add(-12*time.Hour + 10*time.Minute)
+	mockRepo.On("GetRefreshToken", token).Return(&user.RefreshSession{
+		UserID: uid, ClientType: user.ClientTypeWeb, ExpiresAt: started.Add(12 * time.Hour), SessionStartedAt: started,
+	}, nil)
+	mockRepo.On("GetByID", uid).Return(&user.User{ID: uid, Email: "web@example.com"}, nil)
+
+	resp, err := svc.RefreshToken(token)
+
+	assert.NoError(t, err)
+	assert.WithinDuration(t, started.Add(12*time.Hour), resp.ExpiresAt, time.Second)
+}
+
+func TestRefreshToken_SessionMaxReached(t *testing.T) {
+	svc, mockRepo, _, _, _ := setupTest(t)
+	token := "long_lived_token"
+	// The refresh token would still be valid, but the session is over
+	started := time.Now().Add(-13 * time.Hour)
+	mockRepo.On("GetRefreshToken", token).Return(&user.RefreshSession{
+		UserID: uuid.New(), ClientType: user.ClientTypeWeb, ExpiresAt: time.Now().Add(time.Hour), SessionStartedAt: started,
+	}, nil)
+
+	resp, err := svc.RefreshToken(token)
+
+	assert.EqualError(t, err, "session expired, please log in again")
+	assert.Nil(t, resp)
+	mockRepo.AssertNotCalled(t, "GetByID", mock.Anything)
+}
+
 func TestRefreshToken_Expired(t *testing.T) {
 	svc, mockRepo, _, _, _ := setupTest(t)
 	token := "expired_token"
 	uid := uuid.New()
 	expiresAt := time.Now().Add(-time.Hour) // Expired
 
-	mockRepo.On("GetRefreshToken", token).Return(uid, expiresAt, nil)
+	mockRepo.On("GetRefreshToken", token).Return(&user.RefreshSession{UserID: uid, ClientType: user.ClientTypeDefault, ExpiresAt: expiresAt, SessionStartedAt: time.Now().Add(-time.Hour)}, nil)
 
 	resp, err := svc.RefreshToken(token)
 	assert.Error(t, err)
@@ -508,7 +803,7 @@ func TestRefreshToken_InvalidToken(t *testing.T) {
 	svc, mockRepo, _, _, _ := setupTest(t)
 	token := "invalid_token"
 
-	mockRepo.On("GetRefreshToken", token).Return(uuid.Nil, time.Time{}, fmt.Errorf("invalid or revoked token"))
+	mockRepo.On("GetRefreshToken", token).Return(nil, fmt.Errorf("invalid or revoked token"))
 
 	resp, err := svc.RefreshToken(token)
 	assert.Error(t, err)
@@ -521,7 +816,7 @@ func TestRefreshToken_UserNotFound(t *testing.T) {
 	uid := uuid.New()
 	expiresAt := time.Now().Add(time.Hour)
 
-	mockRepo.On("GetRefreshToken", token).Return(uid, expiresAt, nil)
+	mockRepo.On("GetRefreshToken", token).Return(&user.RefreshSession{UserID: uid, ClientType: user.ClientTypeDefault, ExpiresAt: expiresAt, SessionStartedAt: time.Now().Add(-time.Hour)}, nil)
 	mockRepo.On("GetByID", uid).Return(nil, fmt.Errorf("user not found"))
 
 	resp, err := svc.RefreshToken(token)
@@ -647,6 +942,36 @@ func TestUpdateProfile_WithPhone(t *testing.T) {
 	assert.Equal(t, &phone, result.Phone)
 }
 
+func TestUpdateProfile_NewPhoneNeedsVerification(t *testing.T) {
+	svc, mockRepo, _, _, _ := setupTest(t)
+	uid := uuid.New()
+	oldPhone, newPhone := "+6281111111111", "+6282222222222"
+	verifiedAt := time.Now()
+
+	mockRepo.On("GetByID", uid).Return(&user.User{ID: uid, Phone: &oldPhone, PhoneVerifiedAt: &verifiedAt}, nil).Once()
+	mockRepo.On("GetByPhone", newPhone).Return(nil, fmt.Errorf("user not found"))
+	mockRepo.On("Update", uid, map[string]interface{}{"phone": newPhone, "phone_verified_at": nil}).Return(nil)
+	mockRepo.On("GetByID", uid).Return(&user.User{ID: uid, Phone: &newPhone}, nil)
+
+	result, err := svc.UpdateProfile(uid, &user.UpdateUserRequest{Phone: &newPhone})
+	assert.NoError(t, err)
+	assert.False(t, result.PhoneVerified())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateProfile_PhoneOfAnotherCustomer(t *testing.T) {
+	svc, mockRepo, _, _, _ := setupTest(t)
+	uid := uuid.New()
+	phone := "+6282222222222"
+
+	mockRepo.On("GetByID", uid).Return(&user.User{ID: uid}, nil)
+	mockRepo.On("GetByPhone", phone).Return(&user.User{ID: uuid.New(), Phone: &phone}, nil)
+
+	_, err := svc.UpdateProfile(uid, &user.UpdateUserRequest{Phone: &phone})
+	assert.EqualError(t, err, "phone number is already registered to another customer")
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
 func TestUpdateProfile_WithValidDateOfBirth(t *testing.T) {
 	svc, mockRepo, _, _, _ := setupTest(t)
 	uid := uuid.New()
@@ -727,9 +1052,761 @@ func TestLogin_ByPhone_WrongPassword(t *testing.T) {
 	}
 
 	mockRepo.On("GetByPhone", phone).Return(u, nil)
+	expectKnownLogin(mockRepo, u.ID)
 
 	resp, err := svc.Login(&user.LoginRequest{Phone: phone, Password: "wrong_password"})
 	assert.Error(t, err)
 	assert.Nil(t, resp)
 	assert.Contains(t, err.Error(), "invalid phone number or password")
 }
+
+func TestListUsers_NextCursor(t *testing.T) {
+	svc, mockRepo, _, _, _ := setupTest(t)
+	f := &user.ListFilter{KYCStatus: "pending", Limit: 2}
+	created := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	users := []*user.User{
+		{ID: uuid.New(), CreatedAt: created.Add(2 * time.Hour)},
+		{ID: uuid.New(), CreatedAt: created.Add(time.Hour)},
+		{ID: uuid.New(), CreatedAt: created},
+	}
+	mockRepo.On("List", f).Return(users, nil)
+
+	resp, err := svc.ListUsers(f)
+
+	assert.NoError(t, err)
+	assert.Len(t, resp.Users, 2)
+	assert.True(t, resp.HasMore)
+	cursor, err := user.DecodeCursor(resp.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, users[1].ID, cursor.ID)
+	assert.True(t, users[1].CreatedAt.Equal(cursor.CreatedAt))
+}
+
+func TestListUsers_LastPage(t *testing.T) {
+	svc, mockRepo, _, _, _ := setupTest(t)
+	f := &user.ListFilter{Limit: 2}
+	mockRepo.On("List", f).Return([]*user.User{{ID: uuid.New()}}, nil)
+
+	resp, err := svc.ListUsers(f)
+
+	assert.NoError(t, err)
+	assert.Len(t, resp.Users, 1)
+	assert.False(t, resp.HasMore)
+	assert.Empty(t, resp.NextCursor)
+}
+
+func deletedUser(t *testing.T, deletedAgo time.Duration) *user.User {
+	hash, err := crypto.HashPassword("secret123")
+	assert.NoError(t, err)
+	deletedAt := time.Now().Add(-deletedAgo)
+	return &user.User{ID: uuid.New(), Email: "gone@example.com", PasswordHash: hash, DeletedAt: &deletedAt}
+}
+
+func TestRequestReactivation_SendsOTP(t *testing.T) {
+	svc, mockRepo, _, _, mr := setupTest(t)
+	mockRepo.On("GetForReactivation", "gone@example.com").Return(deletedUser(t, 48*time.Hour), nil)
+	svc.notifier.(*MockNotifier).On("SendEmail", mock.MatchedBy(func(e *notifier.Email) bool {
+		return e.To == "gone@example.com"
+	})).Return(nil)
+
+	err := svc.RequestReactivation(&user.ReactivationOTPRequest{Email: "gone@example.com", Password: "secret123"})
+
+	assert.NoError(t, err)
+	assert.True(t, mr.Exists("reactivation_otp:gone@example.com"))
+}
+
+func TestRequestReactivation_WrongPassword(t *testing.T) {
+	svc, mockRepo, _, _, mr := setupTest(t)
+	mockRepo.On("GetForReactivation", "gone@example.com").Return(deletedUser(t, 48*time.Hour), nil)
+
+	err := svc.RequestReactivation(&user.ReactivationOTPRequest{Email: "gone@example.com", Password: "wrong-password"})
+
+	assert.EqualError(t, err, "invalid email or password")
+	assert.False(t, mr.Exists("reactivation_otp:gone@example.com"))
+}
+
+func TestRequestReactivation_WindowExpired(t *testing.T) {
+	svc, mockRepo, _, _, _ := setupTest(t)
+	mockRepo.On("GetForReactivation", "gone@example.com").Return(deletedUser(t, user.ReactivationWindow+time.Hour), nil)
+
+	err := svc.RequestReactivation(&user.ReactivationOTPRequest{Email: "gone@example.com", Password: "secret123"})
+
+	assert.EqualError(t, err, "reactivation period has expired")
+}
+
+func TestReactivate_Success(t *testing.T) {
+	svc, mockRepo, _, _, mr := setupTest(t)
+	u := deletedUser(t, 48*time.Hour)
+	storeOTP(svc, "reactivation_otp:gone@example.com", "123456")
+	mockRepo.On("GetForReactivation", "gone@example.com").Return(u, nil)
+	mockRepo.On("Restore", u.ID).Return(nil)
+
+	restored, err := svc.Reactivate(&user.ReactivateRequest{Email: "gone@example.com", Password: "secret123", OTP: "123456"})
+
+	assert.NoError(t, err)
+	assert.Nil(t, restored.DeletedAt)
+	assert.True(t, restored.IsActive)
+	assert.Empty(t, restored.PasswordHash)
+	assert.False(t, mr.Exists("reactivation_otp:gone@example.com"))
+}
+
+func TestReactivate_InvalidOTP(t *testing.T) {
+	svc, mockRepo, _, _, _ := setupTest(t)
+	storeOTP(svc, "reactivation_otp:gone@example.com", "123456")
+
+	_, err := svc.Reactivate(&user.ReactivateRequest{Email: "gone@example.com", Password: "secret123", OTP: "654321"})
+
+	assert.EqualError(t, err, "invalid OTP code")
+	mockRepo.AssertNotCalled(t, "Restore", mock.Anything)
+}
+
+func TestRequestPhoneVerification_SendsOTP(t *testing.T) {
+	svc, mockRepo, _, _, mr := setupTest(t)
+	uid := uuid.New()
+	phone := "+6281234567890"
+	mockRepo.On("GetByID", uid).Return(&user.User{ID: uid, Phone: &phone}, nil)
+	mockRepo.On("GetByPhone", phone).Return(nil, fmt.Errorf("user not found"))
+	svc.smsSender.(*MockSMSSender).On("SendSMS", mock.MatchedBy(func(m *notifier.SMS) bool {
+		return m.To == phone
+	})).Return(nil)
+
+	err := svc.RequestPhoneVerification(uid)
+
+	assert.NoError(t, err)
+	assert.True(t, mr.Exists(fmt.Sprintf("phone_otp:%s:%s", uid, phone)))
+}
+
+func TestRequestPhoneVerification_AlreadyVerified(t *testing.T) {
+	svc, mockRepo, _, _, _ := setupTest(t)
+	uid := uuid.New()
+	phone := "+6281234567890"
+	verifiedAt := time.Now()
+	mockRepo.On("GetByID", uid).Return(&user.User{ID: uid, Phone: &phone, PhoneVerifiedAt: &verifiedAt}, nil)
+
+	err := svc.RequestPhoneVerification(uid)
+
+	assert.EqualError(t, err, "phone number is already verified")
+}
+
+func TestVerifyPhone_Success(t *testing.T) {
+	svc, mockRepo, _, _, mr := setupTest(t)
+	uid := uuid.New()
+	phone := "+6281234567890"
+	otpKey := fmt.Sprintf("phone_otp:%s:%s", uid, phone)
+	storeOTP(svc, otpKey, "123456")
+	mockRepo.On("GetByID", uid).Return(&user.User{ID: uid, Phone: &phone}, nil)
+	mockRepo.On("VerifyPhone", uid, phone).Return(nil)
+
+	_, err := svc.VerifyPhone(uid, &user.VerifyPhoneRequest{OTP: "123456"})
+
+	assert.NoError(t, err)
+	assert.False(t, mr.Exists(otpKey))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestVerifyPhone_CodeForAnotherNumber(t *testing.T) {
+	svc, mockRepo, _, _, _ := setupTest(t)
+	uid := uuid.New()
+	phone := "+6281234567890"
+	storeOTP(svc, fmt.Sprintf("phone_otp:%s:%s", uid, "+6289999999999"), "123456")
+	mockRepo.On("GetByID", uid).Return(&user.User{ID: uid, Phone: &phone}, nil)
+
+	_, err := svc.VerifyPhone(uid, &user.VerifyPhoneRequest{OTP: "123456"})
+
+	assert.EqualError(t, err, "invalid or expired OTP")
+	mockRepo.AssertNotCalled(t, "VerifyPhone", mock.Anything, mock.Anything)
+}
+
+func TestRequestEmailChange_SendsCodeToNewAddress(t *testing.T) {
+	svc, mockRepo, _, _, mr := setupTest(t)
+	uid := uuid.New()
+	hash, _ := crypto.HashPassword("secret123")
+	mockRepo.On("GetByID", uid).Return(&user.User{ID: uid, Email: "old@example.com", PasswordHash: hash}, nil)
+	mockRepo.On("GetByEmail", "new@example.com").Return(nil, fmt.Errorf("user not found"))
+	mockNotifier := svc.notifier.(*MockNotifier)
+	mockNotifier.On("SendEmail", mock.MatchedBy(func(e *notifier.Email) bool {
+		return e.To == "new@example.com"
+	})).Return(nil)
+
+	err := svc.RequestEmailChange(uid, &user.EmailChangeRequest{NewEmail: "new@example.com", Password: "secret123"})
+
+	assert.NoError(t, err)
+	mockNotifier.AssertExpectations(t)
+	assert.True(t, mr.Exists(fmt.Sprintf("email_change:%s:new@example.com", uid)))
+}
+
+func TestRequestEmailChange_WrongPassword(t *testing.T) {
+	svc, mockRepo, _, _, _ := setupTest(t)
+	uid := uuid.New()
+	hash, _ := crypto.HashPassword("secret123")
+	mockRepo.On("GetByID", uid).Return(&user.User{ID: uid, Email: "old@example.com", PasswordHash: hash}, nil)
+
+	err := svc.RequestEmailChange(uid, &user.EmailChangeRequest{NewEmail: "new@example.com", Password: "wrong"})
+
+	assert.EqualError(t, err, "invalid password")
+}
+
+func TestRequestEmailChange_AddressTaken(t *testing.T) {
+	svc, mockRepo, _, _, _ := setupTest(t)
+	uid := uuid.New()
+	hash, _ := crypto.HashPassword("secret123")
+	mockRepo.On("GetByID", uid).Return(&user.User{ID: uid, Email: "old@example.com", PasswordHash: hash}, nil)
+	mockRepo.On("GetByEmail", "new@example.com").Return(&user.User{ID: uuid.New()}, nil)
+
+	err := svc.RequestEmailChange(uid, &user.EmailChangeRequest{NewEmail: "new@example.com", Password: "secret123"})
+
+	assert.EqualError(t, err, "user with this email already exists")
+}
+
+func TestConfirmEmailChange_Success(t *testing.T) {
+	svc, mockRepo, _, _, mr := setupTest(t)
+	uid := uuid.New()
+	otpKey := fmt.Sprintf("email_change:%s:new@example.com", uid)
+	storeOTP(svc, otpKey, "123456")
+	mockRepo.On("GetByID", uid).Return(&user.User{ID: uid, Email: "old@example.com", FirstName: "Budi"}, nil).Once()
+	mockRepo.On("GetByEmail", "new@example.com").Return(nil, fmt.Errorf("user not found"))
+	mockRepo.On("Update", uid, map[string]interface{}{"email": "new@example.com"}).Return(nil)
+	mockRepo.On("RevokeAllRefreshTokens", uid).Return(nil)
+	mockRepo.On("GetByID", uid).Return(&user.User{ID: uid, Email: "new@example.com"}, nil)
+	mockNotifier := svc.notifier.(*MockNotifier)
+	mockNotifier.On("SendEmail", mock.MatchedBy(func(e *notifier.Email) bool {
+		return e.To == "old@example.com" && strings.Contains(e.Body, "new@example.com")
+	})).Return(nil)
+
+	u, err := svc.ConfirmEmailChange(uid, &user.ConfirmEmailChangeRequest{NewEmail: "new@example.com", OTP: "123456"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "new@example.com", u.Email)
+	assert.False(t, mr.Exists(otpKey))
+	mockRepo.AssertExpectations(t)
+	mockNotifier.AssertExpectations(t)
+}
+
+func TestConfirmEmailChange_CodeForAnotherAddress(t *testing.T) {
+	svc, mockRepo, _, _, _ := setupTest(t)
+	uid := uuid.New()
+	storeOTP(svc, fmt.Sprintf("email_change:%s:new@example.com", uid), "123456")
+
+	_, err := svc.ConfirmEmailChange(uid, &user.ConfirmEmailChangeRequest{NewEmail: "other@example.com", OTP: "123456"})
+
+	assert.EqualError(t, err, "invalid or expired OTP")
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestLogin_ByUsername(t *testing.T) {
+	svc, mockRepo, _, _, _ := setupTest(t)
+	hash, _ := crypto.HashPassword("password123")
+	username := "budi.s"
+	u := &user.User{ID: uuid.New(), Email: "budi@example.com", Username: &username, PasswordHash: hash, IsActive: true}
+
+	mockRepo.On("GetByUsername", "budi.s").Return(u, nil)
+	mockRepo.On("SaveRefreshToken", u.ID, mock.AnythingOfType("string"), user.ClientTypeDefault, mock.AnythingOfType("time.Time")).Return(nil)
+	expectKnownLogin(mockRepo, u.ID)
+
+	resp, err := svc.Login(&user.LoginRequest{Username: "@Budi.S", Password: "password123"})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, resp.Token)
+}
+
+func TestLogin_ByUsername_WrongPassword(t *testing.T) {
+	svc, mockRepo, _, _, _ := setupTest(t)
+	hash, _ := crypto.HashPassword("password123")
+	u := &user.User{ID: uuid.New(), PasswordHash: hash, IsActive: true}
+	mockRepo.On("GetByUsername", "budi.s").Return(u, nil)
+	expectKnownLogin(mockRepo, u.ID)
+
+	_, err := svc.Login(&user.LoginRequest{Username: "budi.s", Password: "wrong"})
+	assert.EqualError(t, err, "invalid username or password")
+}
+
+func TestUpdateProfile_UsernameTaken(t *testing.T) {
+	svc, mockRepo, _, _, _ := setupTest(t)
+	uid := uuid.New()
+	taken := "budi.s"
+	mockRepo.On("GetByUsername", "budi.s").Return(&user.User{ID: uuid.New(), Username: &taken}, nil)
+
+	_, err := svc.UpdateProfile(uid, &user.UpdateUserRequest{Username: &taken})
+	assert.EqualError(t, err, "username is already taken")
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestResolveHandle(t *testing.T) {
+	svc, mockRepo, mockAccountRepo, _, _ := setupTest(t)
+	payee := &user.User{ID: uuid.New(), FirstName: "Siti", LastName: "Rahma", IsActive: true}
+	mockRepo.On("GetByUsername", "siti.r").Return(payee, nil)
+	mockAccountRepo.On("GetByUserID", payee.ID).Return([]*account.Account{{ID: uuid.New(), Status: account.AccountStatusActive}}, nil)
+
+	res, err := svc.ResolveHandle("@siti.r")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "@siti.r", res.Handle)
+	assert.Equal(t, "Siti R.", res.DisplayName)
+}
+
+func TestRegister_PasswordPolicy(t *testing.T) {
+	svc, mockRepo, _, _, _ := setupTest(t)
+	svc.passwordPolicy, _ = passwordpolicy.NewValidator(passwordpolicy.DefaultPolicy, nil)
+	mockRepo.On("GetByEmail", "new@example.com").Return(nil, fmt.Errorf("user not found"))
+
+	_, err := svc.Register(&user.CreateUserRequest{Email: "new@example.com", Password: "password123"})
+
+	assert.EqualError(t, err, "password must contain an uppercase letter")
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestResetPassword_PasswordPolicyKeepsOTP(t *testing.T) {
+	svc, _, _, _, _ := setupTest(t)
+	email := "reset@example.com"
+	otpKey := fmt.Sprintf("otp:%s", email)
+	storeOTP(svc, otpKey, "123456")
+
+	err := svc.ResetPassword(&user.ResetPasswordRequest{Email: email, OTP: "123456", NewPassword: "short"})
+
+	assert.EqualError(t, err, "password must contain at least 8 characters")
+	// The customer can retry with a better password
+	exists, _ := svc.redisClient.Exists(context.Background(), otpKey).Result()
+	assert.Equal(t, int64(1), exists)
+}
+
+func TestChangePassword_Success(t *testing.T) {
+	svc, mockRepo, _, _, _ := setupTest(t)
+	uid := uuid.New()
+	hash, _ := crypto.HashPassword("oldSecret123")
+	mockRepo.On("GetByID", uid).Return(&user.User{ID: uid, PasswordHash: hash}, nil)
+	mockRepo.On("Update", uid, mock.MatchedBy(func(u map[string]interface{}) bool {
+		newHash, ok := u["password_hash"].(string)
+		return ok && crypto.CheckPassword("newSecret456", newHash)
+	})).Return(nil)
+	mockRepo.On("RevokeAllRefreshTokens", uid).Return(nil)
+
+	err := svc.ChangePassword(uid, &user.ChangePasswordRequest{CurrentPassword: "oldSecret123", NewPassword: "newSecret456"})
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestChangePassword_WrongCurrentPassword(t *testing.T) {
+	svc, mockRepo, _, _, _ := setupTest(t)
+	uid := uuid.New()
+	hash, _ := crypto.HashPassword("oldSecret123")
+	mockRepo.On("GetByID", uid).Return(&user.User{ID: uid, PasswordHash: hash}, nil)
+
+	err := svc.ChangePassword(uid, &user.ChangePasswordRequest{CurrentPassword: "guess", NewPassword: "newSecret456"})
+
+	assert.EqualError(t, err, "invalid password")
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+// forgotPasswordWithLink runs ForgotPassword with reset links enabled and
+// returns the token of the emailed link
+func forgotPasswordWithLink(t *testing.T, svc *userService, mockRepo *MockUserRepository, u *user.User) string {
+	svc.resetLinks = ResetLinkConfig{Secret: []byte("reset-secret"), BaseURL: "https://app.madabank.test/reset"}
+	mockRepo.On("GetByEmail", u.Email).Return(u, nil)
+	var link string
+	svc.notifier.(*MockNotifier).On("SendEmail", mock.MatchedBy(func(e *notifier.Email) bool {
+		return e.Subject == "Your MadaBank verification code"
+	})).Return(nil)
+	svc.notifier.(*MockNotifier).On("SendEmail", mock.MatchedBy(func(e *notifier.Email) bool {
+		return e.To == u.Email && e.Subject == "Reset your password"
+	})).Run(func(args mock.Arguments) {
+		body := args.Get(0).(*notifier.Email).Body
+		start := strings.Index(body, "https://app.madabank.test/reset?token=")
+		link = strings.Fields(body[start:])[0]
+	}).Return(nil)
+
+	_, err := svc.ForgotPassword(&user.ForgotPasswordRequest{Email: u.Email})
+	assert.NoError(t, err)
+	return strings.TrimPrefix(link, "https://app.madabank.test/reset?token=")
+}
+
+func TestResetPasswordWithLink_Success(t *testing.T) {
+	svc, mockRepo, _, _, mr := setupTest(t)
+	u := &user.User{ID: uuid.New(), Email: "link@example.com", FirstName: "Budi"}
+	token := forgotPasswordWithLink(t, svc, mockRepo, u)
+	mockRepo.On("GetByID", u.ID).Return(u, nil)
+	mockRepo.On("Update", u.ID, mock.AnythingOfType("map[string]interface {}")).Return(nil)
+
+	err := svc.ResetPasswordWithLink(&user.ResetPasswordLinkRequest{Token: token, NewPassword: "newSecret123"})
+	assert.NoError(t, err)
+
+	// The OTP sent alongside is cancelled, and the link cannot be used twice
+	assert.False(t, mr.Exists("otp:link@example.com"))
+	err = svc.ResetPasswordWithLink(&user.ResetPasswordLinkRequest{Token: token, NewPassword: "newSecret123"})
+	assert.EqualError(t, err, "password reset link has already been used or replaced")
+}
+
+func TestResetPassword_WithOTPCancelsLink(t *testing.T) {
+	svc, mockRepo, _, _, _ := setupTest(t)
+	u := &user.User{ID: uuid.New(), Email: "link@example.com"}
+	token := forgotPasswordWithLink(t, svc, mockRepo, u)
+	storeOTP(svc, "otp:link@example.com", "123456")
+	mockRepo.On("Update", u.ID, mock.AnythingOfType("map[string]interface {}")).Return(nil)
+
+	assert.NoError(t, svc.ResetPassword(&user.ResetPasswordRequest{Email: u.Email, OTP: "123456", NewPassword: "newSecret123"}))
+
+	err := svc.ResetPasswordWithLink(&user.ResetPasswordLinkRequest{Token: token, NewPassword: "otherSecret123"})
+	assert.EqualError(t, err, "password reset link has already been used or replaced")
+}
+
+func TestResetPasswordWithLink_Disabled(t *testing.T) {
+	svc, _, _, _, _ := setupTest(t)
+
+	err := svc.ResetPasswordWithLink(&user.ResetPasswordLinkRequest{Token: "anything", NewPassword: "newSecret123"})
+	assert.EqualError(t, err, "password reset links are not enabled")
+}
+
+func TestResetPassword_LockedAfterTooManyAttempts(t *testing.T) {
+	svc, mockRepo, _, _, mr := setupTest(t)
+	email := "guess@example.com"
+	otpKey := fmt.Sprintf("otp:%s", email)
+	storeOTP(svc, otpKey, "123456")
+
+	for i := 1; i < user.MaxOTPAttempts; i++ {
+		err := svc.ResetPassword(&user.ResetPasswordRequest{Email: email, OTP: fmt.Sprintf("%06d", i), NewPassword: "newSecret123"})
+		assert.EqualError(t, err, "invalid OTP code")
+	}
+	err := svc.ResetPassword(&user.ResetPasswordRequest{Email: email, OTP: "000000", NewPassword: "newSecret123"})
+	assert.EqualError(t, err, "too many incorrect codes, request a new one")
+
+	// Even the right code no longer works
+	err = svc.ResetPassword(&user.ResetPasswordRequest{Email: email, OTP: "123456", NewPassword: "newSecret123"})
+	assert.EqualError(t, err, "invalid or expired OTP")
+	assert.False(t, mr.Exists(otpKey))
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestVerifyOTP_SingleUse(t *testing.T) {
+	svc, _, _, _, mr := setupTest(t)
+	storeOTP(svc, "otp:once@example.com", "123456")
+
+	assert.NoError(t, svc.verifyOTP("otp:once@example.com", "123456"))
+	assert.False(t, mr.Exists("otp:once@example.com"))
+	assert.EqualError(t, svc.verifyOTP("otp:once@example.com", "123456"), "invalid or expired OTP")
+}
+
+func TestResetPassword_WeakPasswordKeepsOTP(t *testing.T) {
+	svc, _, _, _, mr := setupTest(t)
+	otpKey := "otp:weak@example.com"
+	storeOTP(svc, otpKey, "123456")
+
+	err := svc.ResetPassword(&user.ResetPasswordRequest{Email: "weak@example.com", OTP: "123456", NewPassword: "new"})
+	assert.Error(t, err)
+	assert.True(t, mr.Exists(otpKey))
+}
+
+func TestVerifyOTP_BoundToKey(t *testing.T) {
+	svc, _, _, _, _ := setupTest(t)
+	storeOTP(svc, "otp:a@example.com", "123456")
+
+	// The same code stored for one recipient does not match under another key
+	svc.redisClient.HSet(context.Background(), "otp:b@example.com", "hash", svc.otpHash("otp:a@example.com", "123456"))
+
+	assert.NoError(t, svc.verifyOTP("otp:a@example.com", "123456"))
+	assert.EqualError(t, svc.verifyOTP("otp:b@example.com", "123456"), "invalid OTP code")
+}
+
+func TestForgotPassword_SMSToVerifiedPhone(t *testing.T) {
+	svc, mockRepo, _, _, _ := setupTest(t)
+	phone := "+6281234567890"
+	now := time.Now()
+	mockRepo.On("GetByEmail", "sms@example.com").Return(&user.User{ID: uuid.New(), Email: "sms@example.com", Phone: &phone, PhoneVerifiedAt: &now}, nil)
+	mockSMS := svc.smsSender.(*MockSMSSender)
+	mockSMS.On("SendSMS", mock.MatchedBy(func(m *notifier.SMS) bool {
+		return m.To == phone && strings.Contains(m.Body, "Your MadaBank code is")
+	})).Return(nil)
+
+	// No channel given: a verified phone is preferred
+	channel, err := svc.ForgotPassword(&user.ForgotPasswordRequest{Email: "sms@example.com"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, user.OTPChannelSMS, channel)
+	mockSMS.AssertExpectations(t)
+
+	// The email channel has its own rate limit
+	svc.notifier.(*MockNotifier).On("SendEmail", mock.Anything).Return(nil)
+	channel, err = svc.ForgotPassword(&user.ForgotPasswordRequest{Email: "sms@example.com", Channel: user.OTPChannelEmail})
+	assert.NoError(t, err)
+	assert.Equal(t, user.OTPChannelEmail, channel)
+
+	_, err = svc.ForgotPassword(&user.ForgotPasswordRequest{Email: "sms@example.com", Channel: user.OTPChannelSMS})
+	assert.Contains(t, err.Error(), "please wait 15 minutes")
+}
+
+func TestForgotPassword_SMSNeedsVerifiedPhone(t *testing.T) {
+	svc, mockRepo, _, _, _ := setupTest(t)
+	phone := "+6281234567890"
+	mockRepo.On("GetByEmail", "unverified@example.com").Return(&user.User{ID: uuid.New(), Email: "unverified@example.com", Phone: &phone}, nil)
+
+	_, err := svc.ForgotPassword(&user.ForgotPasswordRequest{Email: "unverified@example.com", Channel: user.OTPChannelSMS})
+
+	assert.EqualError(t, err, "no verified phone number on profile, use email instead")
+}
+
+func TestForgotPassword_DeliveryFailureAllowsRetry(t *testing.T) {
+	svc, mockRepo, _, _, mr := setupTest(t)
+	mockRepo.On("GetByEmail", "down@example.com").Return(&user.User{ID: uuid.New(), Email: "down@example.com"}, nil)
+	svc.notifier.(*MockNotifier).On("SendEmail", mock.Anything).Return(fmt.Errorf("relay unavailable")).Once()
+
+	_, err := svc.ForgotPassword(&user.ForgotPasswordRequest{Email: "down@example.com"})
+
+	assert.EqualError(t, err, "failed to send OTP, please try again")
+	assert.False(t, mr.Exists("otp:down@example.com"))
+	assert.False(t, mr.Exists("rate_limit:otp:email:down@example.com"))
+}
+
+func TestLogin_StepUp(t *testing.T) {
+	svc, mockRepo, _, _, mr := setupTest(t)
+	hash, _ := crypto.HashPassword("password123")
+	u := &user.User{ID: uuid.New(), Email: "budi@example.com", PasswordHash: hash, IsActive: true}
+	mockRepo.On("GetByEmail", "budi@example.com").Return(u, nil)
+	mockRepo.On("SaveRefreshToken", u.ID, mock.AnythingOfType("string"), user.ClientTypeDefault, mock.AnythingOfType("time.Time")).Return(nil)
+	expectKnownLogin(mockRepo, u.ID)
+	mockNotifier := svc.notifier.(*MockNotifier)
+	mockNotifier.On("SendEmail", mock.MatchedBy(func(e *notifier.Email) bool {
+		return e.To == "budi@example.com"
+	})).Return(nil).Once()
+
+	// The first attempt sends a code instead of signing in
+	_, err := svc.Login(&user.LoginRequest{Email: "budi@example.com", Password: "password123", StepUp: true})
+	assert.ErrorIs(t, err, user.ErrStepUpRequired)
+	otpKey := fmt.Sprintf("step_up:%s", u.ID)
+	assert.True(t, mr.Exists(otpKey))
+	mockNotifier.AssertExpectations(t)
+
+	_, err = svc.Login(&user.LoginRequest{Email: "budi@example.com", Password: "password123", OTP: "000000", StepUp: true})
+	assert.ErrorIs(t, err, user.ErrInvalidLoginCode)
+
+	storeOTP(svc, otpKey, "123456")
+	resp, err := svc.Login(&user.LoginRequest{Email: "budi@example.com", Password: "password123", OTP: "123456", StepUp: true})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, resp.Token)
+	// Spent, and a new code may be requested at once
+	assert.False(t, mr.Exists(otpKey))
+	assert.False(t, mr.Exists(fmt.Sprintf("rate_limit:step_up:email:%s", u.ID)))
+}
+
+func TestLogin_StepUp_WrongPasswordSendsNoCode(t *testing.T) {
+	svc, mockRepo, _, _, mr := setupTest(t)
+	hash, _ := crypto.HashPassword("password123")
+	u := &user.User{ID: uuid.New(), Email: "budi@example.com", PasswordHash: hash, IsActive: true}
+	mockRepo.On("GetByEmail", "budi@example.com").Return(u, nil)
+	expectKnownLogin(mockRepo, u.ID)
+
+	// Answered as if the password were right, so it cannot be told apart
+	otpKey := fmt.Sprintf("step_up:%s", u.ID)
+	_, err := svc.Login(&user.LoginRequest{Email: "budi@example.com", Password: "wrong", StepUp: true})
+	assert.ErrorIs(t, err, user.ErrStepUpRequired)
+	assert.False(t, mr.Exists(otpKey))
+
+	// Even with the right code, it fails like a wrong code and leaves the
+	// code alone
+	storeOTP(svc, otpKey, "123456")
+	_, err = svc.Login(&user.LoginRequest{Email: "budi@example.com", Password: "wrong", OTP: "123456", StepUp: true})
+	assert.ErrorIs(t, err, user.ErrInvalidLoginCode)
+	assert.Equal(t, "0", mr.HGet(otpKey, "attempts"))
+}
+
+func TestRequestStepUp_BySMS(t *testing.T) {
+	svc, mockRepo, _, _, _ := setupTest(t)
+	phone := "+6281234567890"
+	now := time.Now()
+	u := &user.User{ID: uuid.New(), Email: "budi@example.com", Phone: &phone, PhoneVerifiedAt: &now}
+	mockRepo.On("GetByID", u.ID).Return(u, nil)
+	mockSMS := svc.smsSender.(*MockSMSSender)
+	mockSMS.On("SendSMS", mock.MatchedBy(func(m *notifier.SMS) bool { return m.To == phone })).Return(nil)
+
+	channel, err := svc.RequestStepUp(u.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, user.OTPChannelSMS, channel)
+	mockSMS.AssertExpectations(t)
+
+	storeOTP(svc, fmt.Sprintf("step_up:%s", u.ID), "123456")
+	assert.NoError(t, svc.VerifyStepUp(u.ID, "123456"))
+	assert.EqualError(t, svc.VerifyStepUp(u.ID, "123456"), "invalid or expired OTP")
+}
+
+func TestLogin_NewDeviceSendsAlert(t *testing.T) {
+	svc, mockRepo, _, _, _ := setupTest(t)
+	hash, _ := crypto.HashPassword("password123")
+	u := &user.User{ID: uuid.New(), Email: "budi@example.com", FirstName: "Budi", PasswordHash: hash, IsActive: true}
+	origin := user.LoginOrigin{DeviceID: "install-2", UserAgent: "MadaBank/3.2.0 (Android 14)", IPAddress: "203.0.113.7", Country: "SG"}
+	mockRepo.On("GetByEmail", "budi@example.com").Return(u, nil)
+	mockRepo.On("SaveRefreshToken", u.ID, mock.AnythingOfType("string"), user.ClientTypeDefault, mock.AnythingOfType("time.Time")).Return(nil)
+	mockRepo.On("GetLoginFamiliarity", u.ID, origin).Return(&user.LoginFamiliarity{KnownDevice: false, KnownCountry: true}, nil)
+	mockRepo.On("RecordLogin", u.ID, origin).Return(nil)
+	mockNotifier := svc.notifier.(*MockNotifier)
+	mockNotifier.On("SendEmail", mock.MatchedBy(func(e *notifier.Email) bool {
+		return e.To == "budi@example.com" && e.Subject == "New sign-in to your MadaBank account" &&
+			strings.Contains(e.Body, "Country: SG") && strings.Contains(e.Body, "203.0.113.7")
+	})).Return(nil).Once()
+
+	resp, err := svc.Login(&user.LoginRequest{Email: "budi@example.com", Password: "password123", Origin: origin})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, resp.Token)
+	mockNotifier.AssertExpectations(t)
+	mockRepo.AssertCalled(t, "RecordLogin", u.ID, origin)
+}
+
+func TestLogin_FirstLoginSendsNoAlert(t *testing.T) {
+	svc, mockRepo, _, _, _ := setupTest(t)
+	svc.loginAlerts.RequireOTP = true
+	hash, _ := crypto.HashPassword("password123")
+	u := &user.User{ID: uuid.New(), Email: "budi@example.com", PasswordHash: hash, IsActive: true}
+	mockRepo.On("GetByEmail", "budi@example.com").Return(u, nil)
+	mockRepo.On("SaveRefreshToken", u.ID, mock.AnythingOfType("string"), user.ClientTypeDefault, mock.AnythingOfType("time.Time")).Return(nil)
+	mockRepo.On("GetLoginFamiliarity", u.ID, mock.Anything).Return(&user.LoginFamiliarity{FirstLogin: true}, nil)
+	mockRepo.On("RecordLogin", u.ID, mock.Anything).Return(nil)
+
+	resp, err := svc.Login(&user.LoginRequest{Email: "budi@example.com", Password: "password123"})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, resp.Token)
+	svc.notifier.(*MockNotifier).AssertNotCalled(t, "SendEmail", mock.Anything)
+}
+
+func TestLogin_NewCountryRequiresOTP(t *testing.T) {
+	svc, mockRepo, _, _, mr := setupTest(t)
+	svc.loginAlerts.RequireOTP = true
+	hash, _ := crypto.HashPassword("password123")
+	u := &user.User{ID: uuid.New(), Email: "budi@example.com", PasswordHash: hash, IsActive: true}
+	origin := user.LoginOrigin{DeviceID: "install-1", Country: "SG"}
+	mockRepo.On("GetByEmail", "budi@example.com").Return(u, nil)
+	mockRepo.On("SaveRefreshToken", u.ID, mock.AnythingOfType("string"), user.ClientTypeDefault, mock.AnythingOfType("time.Time")).Return(nil)
+	mockRepo.On("GetLoginFamiliarity", u.ID, origin).Return(&user.LoginFamiliarity{KnownDevice: true, KnownCountry: false}, nil)
+	mockRepo.On("RecordLogin", u.ID, origin).Return(nil)
+	mockNotifier := svc.notifier.(*MockNotifier)
+	mockNotifier.On("SendEmail", mock.Anything).Return(nil)
+
+	// No tokens until the code sent to the user comes back
+	_, err := svc.Login(&user.LoginRequest{Email: "budi@example.com", Password: "password123", Origin: origin})
+	assert.ErrorIs(t, err, user.ErrStepUpRequired)
+	mockRepo.AssertNotCalled(t, "RecordLogin", mock.Anything, mock.Anything)
+
+	otpKey := fmt.Sprintf("step_up:%s", u.ID)
+	assert.True(t, mr.Exists(otpKey))
+	storeOTP(svc, otpKey, "123456")
+
+	resp, err := svc.Login(&user.LoginRequest{Email: "budi@example.com", Password: "password123", OTP: "123456", Origin: origin})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, resp.Token)
+	mockRepo.AssertCalled(t, "RecordLogin", u.ID, origin)
+	mockNotifier.AssertNumberOfCalls(t, "SendEmail", 2) // the code, then the alert
+}
+
+func TestLogin_HistoryFailureDoesNotBlockLogin(t *testing.T) {
+	svc, mockRepo, _, _, _ := setupTest(t)
+	hash, _ := crypto.HashPassword("password123")
+	u := &user.User{ID: uuid.New(), Email: "budi@example.com", PasswordHash: hash, IsActive: true}
+	mockRepo.On("GetByEmail", "budi@example.com").Return(u, nil)
+	mockRepo.On("SaveRefreshToken", u.ID, mock.AnythingOfType("string"), user.ClientTypeDefault, mock.AnythingOfType("time.Time")).Return(nil)
+	mockRepo.On("GetLoginFamiliarity", u.ID, mock.Anything).Return(nil, fmt.Errorf("db down"))
+	mockRepo.On("RecordLogin", u.ID, mock.Anything).Return(fmt.Errorf("db down"))
+
+	resp, err := svc.Login(&user.LoginRequest{Email: "budi@example.com", Password: "password123"})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, resp.Token)
+}
+
+// backdateLoginFailure moves the last failed login of the subject back, as if
+// the delay had passed
+func backdateLoginFailure(mr *miniredis.Miniredis, subject string, d time.Duration) {
+	mr.HSet(loginFailureKey(subject), "last", strconv.FormatInt(time.Now().Add(-d).UnixMilli(), 10))
+}
+
+func TestLogin_FailuresThrottleTheAccount(t *testing.T) {
+	svc, mockRepo, _, _, mr := setupTest(t)
+	hash, _ := crypto.HashPassword("password123")
+	u := &user.User{ID: uuid.New(), Email: "budi@example.com", PasswordHash: hash, IsActive: true}
+	mockRepo.On("GetByEmail", "budi@example.com").Return(u, nil)
+	expectKnownLogin(mockRepo, u.ID)
+
+	for i := 0; i < user.LoginDelayAfterFailures; i++ {
+		_, err := svc.Login(&user.LoginRequest{Email: "budi@example.com", Password: "wrong"})
+		assert.EqualError(t, err, "invalid email or password")
+	}
+
+	// Even the right password has to wait, whatever IP it comes from
+	_, err := svc.Login(&user.LoginRequest{Email: "budi@example.com", Password: "password123"})
+	var throttled *user.LoginThrottledError
+	assert.ErrorAs(t, err, &throttled)
+	assert.InDelta(t, user.BaseLoginDelay.Seconds(), throttled.RetryAfter.Seconds(), 1)
+
+	// Once it passed, another failure doubles the delay
+	backdateLoginFailure(mr, u.ID.String(), user.BaseLoginDelay)
+	_, err = svc.Login(&user.LoginRequest{Email: "budi@example.com", Password: "wrong"})
+	assert.EqualError(t, err, "invalid email or password")
+	_, err = svc.Login(&user.LoginRequest{Email: "budi@example.com", Password: "password123"})
+	assert.ErrorAs(t, err, &throttled)
+	assert.InDelta(t, (2 * user.BaseLoginDelay).Seconds(), throttled.RetryAfter.Seconds(), 1)
+}
+
+func TestLogin_FailuresRequireOTP(t *testing.T) {
+	svc, mockRepo, _, _, mr := setupTest(t)
+	hash, _ := crypto.HashPassword("password123")
+	u := &user.User{ID: uuid.New(), Email: "budi@example.com", PasswordHash: hash, IsActive: true}
+	mockRepo.On("GetByEmail", "budi@example.com").Return(u, nil)
+	mockRepo.On("SaveRefreshToken", u.ID, mock.AnythingOfType("string"), user.ClientTypeDefault, mock.AnythingOfType("time.Time")).Return(nil)
+	expectKnownLogin(mockRepo, u.ID)
+	svc.notifier.(*MockNotifier).On("SendEmail", mock.Anything).Return(nil)
+
+	mr.HSet(loginFailureKey(u.ID.String()), "count", strconv.Itoa(user.LoginOTPAfterFailures))
+	backdateLoginFailure(mr, u.ID.String(), user.MaxLoginDelay)
+
+	_, err := svc.Login(&user.LoginRequest{Email: "budi@example.com", Password: "password123"})
+	assert.ErrorIs(t, err, user.ErrStepUpRequired)
+
+	// Asking for the code counted as an attempt too, so the delay has to
+	// pass again
+	otpKey := fmt.Sprintf("step_up:%s", u.ID)
+	storeOTP(svc, otpKey, "123456")
+	backdateLoginFailure(mr, u.ID.String(), user.MaxLoginDelay)
+	resp, err := svc.Login(&user.LoginRequest{Email: "budi@example.com", Password: "password123", OTP: "123456"})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, resp.Token)
+	// A successful login forgets the failures
+	assert.False(t, mr.Exists(loginFailureKey(u.ID.String())))
+}
+
+func TestLogin_ParallelAttemptsShareTheDelay(t *testing.T) {
+	svc, mockRepo, _, _, mr := setupTest(t)
+	hash, _ := crypto.HashPassword("password123")
+	u := &user.User{ID: uuid.New(), Email: "budi@example.com", PasswordHash: hash, IsActive: true}
+	mockRepo.On("GetByEmail", "budi@example.com").Return(u, nil)
+	expectKnownLogin(mockRepo, u.ID)
+
+	mr.HSet(loginFailureKey(u.ID.String()), "count", strconv.Itoa(user.LoginDelayAfterFailures))
+	backdateLoginFailure(mr, u.ID.String(), user.BaseLoginDelay)
+
+	// A burst sent the moment the delay runs out gets one guess, not one each
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	guesses := 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := svc.Login(&user.LoginRequest{Email: "budi@example.com", Password: "wrong"})
+			var throttled *user.LoginThrottledError
+			if !errors.As(err, &throttled) {
+				mu.Lock()
+				guesses++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, guesses)
+}
+
+func TestLogin_UnknownAccountIsThrottledToo(t *testing.T) {
+	svc, mockRepo, _, _, _ := setupTest(t)
+	mockRepo.On("GetByEmail", mock.Anything).Return(nil, fmt.Errorf("user not found"))
+
+	for i := 0; i < user.LoginDelayAfterFailures; i++ {
+		_, err := svc.Login(&user.LoginRequest{Email: "nobody@example.com", Password: "guess"})
+		assert.EqualError(t, err, "invalid email or password")
+	}
+
+	_, err := svc.Login(&user.LoginRequest{Email: "Nobody@Example.com", Password: "guess"})
+	var throttled *user.LoginThrottledError
+	assert.ErrorAs(t, err, &throttled)
+}
@@ -0,0 +1,363 @@
+package fakes
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/card"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+)
+
+var _ repository.AccountRepository = (*AccountRepository)(nil)
+
+// accountRow is an accounts row with the columns the domain model leaves out
+type accountRow struct {
+	account.Account
+	frozenForDeletion bool
+}
+
+// touched is the row after an UPDATE: like the database trigger, every
+// update moves the version on
+func (a accountRow) touched(now time.Time) accountRow {
+	a.Version++
+	a.UpdatedAt = now
+	return a
+}
+
+// balanceKey is an account_balances row's key
+type balanceKey struct {
+	accountID uuid.UUID
+	currency  string
+}
+
+type AccountRepository struct {
+	db *DB
+}
+
+func NewAccountRepository(db *DB) *AccountRepository {
+	return &AccountRepository{db: db}
+}
+
+func copyAccount(a account.Account) *account.Account {
+	a.FreezeReason = copyPtr(a.FreezeReason)
+	a.InterestTiers = nil
+	a.EffectiveInterestRate = 0
+	return &a
+}
+
+func (r *AccountRepository) Create(acc *account.Account) error {
+	return r.db.update(func(t *tables) error {
+		if _, ok := t.accounts[acc.ID]; ok {
+			return fmt.Errorf("failed to create account: duplicate id %s", acc.ID)
+		}
+		for _, other := range t.accounts {
+			if other.AccountNumber == acc.AccountNumber {
+				return fmt.Errorf("failed to create account: account number %s already exists", acc.AccountNumber)
+			}
+		}
+
+		now := r.db.timestamp()
+		acc.CreatedAt, acc.UpdatedAt = now, now
+		t.accounts[acc.ID] = accountRow{Account: account.Account{
+			ID:            acc.ID,
+			UserID:        acc.UserID,
+			AccountNumber: acc.AccountNumber,
+			AccountType:   acc.AccountType,
+			Balance:       acc.Balance,
+			Currency:      acc.Currency,
+			InterestRate:  acc.InterestRate,
+			Status:        acc.Status,
+			Version:       1,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}}
+		return nil
+	})
+}
+
+// getOne returns the first open account matching the condition
+func (r *AccountRepository) getOne(match func(row *accountRow) bool) (*account.Account, error) {
+	var found *account.Account
+	err := r.db.view(func(t *tables) error {
+		for _, row := range t.accounts {
+			if row.Status != account.AccountStatusClosed && match(&row) {
+				found = copyAccount(row.Account)
+				return nil
+			}
+		}
+		return fmt.Errorf("account not found")
+	})
+	return found, err
+}
+
+func (r *AccountRepository) GetByID(id uuid.UUID) (*account.Account, error) {
+	return r.getOne(func(row *accountRow) bool { return row.ID == id })
+}
+
+func (r *AccountRepository) GetByIDs(ids []uuid.UUID) ([]*account.Account, error) {
+	accounts := []*account.Account{}
+	err := r.db.view(func(t *tables) error {
+		seen := map[uuid.UUID]bool{}
+		for _, id := range ids {
+			row, ok := t.accounts[id]
+			if !ok || seen[id] || row.Status == account.AccountStatusClosed {
+				continue
+			}
+			seen[id] = true
+			accounts = append(accounts, copyAccount(row.Account))
+		}
+		return nil
+	})
+	return accounts, err
+}
+
+func (r *AccountRepository) GetByAccountNumber(accountNumber string) (*account.Account, error) {
+	return r.getOne(func(row *accountRow) bool { return row.AccountNumber == accountNumber })
+}
+
+func (r *AccountRepository) GetByUserID(userID uuid.UUID) ([]*account.Account, error) {
+	return r.list(&account.ListFilter{UserID: userID, Limit: -1})
+}
+
+func (r *AccountRepository) ListByUser(f *account.ListFilter) ([]*account.Account, error) {
+	return r.list(f)
+}
+
+func (r *AccountRepository) CountByUser(f *account.ListFilter) (int, error) {
+	all := *f
+	all.Limit, all.Offset = -1, 0
+	accounts, err := r.list(&all)
+	return len(accounts), err
+}
+
+// list returns the user's accounts matching the filter, sorted as
+// f.OrderBy sorts them
+func (r *AccountRepository) list(f *account.ListFilter) ([]*account.Account, error) {
+	accounts := []*account.Account{}
+	err := r.db.view(func(t *tables) error {
+		rows := sortedValues(t.accounts, accountOrder(f.Sort))
+		for _, row := range rows {
+			if row.UserID != f.UserID {
+				continue
+			}
+			if f.Status != "" && row.Status != f.Status {
+				continue
+			}
+			if f.Status == "" && row.Status == account.AccountStatusClosed {
+				continue
+			}
+			if f.Type != "" && row.AccountType != f.Type {
+				continue
+			}
+			accounts = append(accounts, copyAccount(row.Account))
+		}
+		accounts = page(accounts, f.Limit, f.Offset)
+		return nil
+	})
+	return accounts, err
+}
+
+// accountOrder is account.ListFilter.OrderBy as a comparison
+func accountOrder(sort string) func(a, b *accountRow) bool {
+	desc := strings.HasPrefix(sort, "-")
+	column := strings.TrimPrefix(sort, "-")
+
+	var cmp func(a, b *accountRow) int
+	switch column {
+	case "balance":
+		cmp = func(a, b *accountRow) int {
+			switch {
+			case a.Balance < b.Balance:
+				return -1
+			case a.Balance > b.Balance:
+				return 1
+			}
+			return 0
+		}
+	case "account_number":
+		cmp = func(a, b *accountRow) int { return strings.Compare(a.AccountNumber, b.AccountNumber) }
+	case "created_at":
+		cmp = func(a, b *accountRow) int { return a.CreatedAt.Compare(b.CreatedAt) }
+	default:
+		desc = true
+		cmp = func(a, b *accountRow) int { return a.CreatedAt.Compare(b.CreatedAt) }
+	}
+
+	return func(a, b *accountRow) bool {
+		c := cmp(a, b)
+		if desc {
+			c = -c
+		}
+		if c != 0 {
+			return c < 0
+		}
+		return idLess(a.ID, b.ID)
+	}
+}
+
+func (r *AccountRepository) GetHolders(ids []uuid.UUID) ([]*account.Holder, error) {
+	holders := []*account.Holder{}
+	err := r.db.view(func(t *tables) error {
+		seen := map[uuid.UUID]bool{}
+		for _, id := range ids {
+			acc, ok := t.accounts[id]
+			if !ok || seen[id] {
+				continue
+			}
+			u, ok := t.users[acc.UserID]
+			if !ok {
+				continue
+			}
+			seen[id] = true
+			holders = append(holders, &account.Holder{
+				AccountID:     acc.ID,
+				UserID:        acc.UserID,
+				AccountNumber: acc.AccountNumber,
+				FirstName:     u.FirstName,
+				LastName:      u.LastName,
+			})
+		}
+		return nil
+	})
+	return holders, err
+}
+
+func (r *AccountRepository) Update(id uuid.UUID, version int, updates map[string]interface{}) error {
+	return r.db.update(func(t *tables) error {
+		row, ok := t.accounts[id]
+		if !ok || row.Status == account.AccountStatusClosed {
+			return fmt.Errorf("account not found or already closed")
+		}
+		if row.Version != version {
+			return account.ErrVersionConflict
+		}
+		row.Account = *copyAccount(row.Account)
+		if err := setColumns(&row.Account, updates); err != nil {
+			return fmt.Errorf("failed to update account: %w", err)
+		}
+		t.accounts[id] = row.touched(r.db.timestamp())
+		return nil
+	})
+}
+
+func (r *AccountRepository) UpdateBalance(id uuid.UUID, version int, newBalance float64) error {
+	return r.db.update(func(t *tables) error {
+		row, ok := t.accounts[id]
+		if !ok || row.Status != account.AccountStatusActive {
+			return fmt.Errorf("account not found or not active")
+		}
+		if row.Version != version {
+			return account.ErrVersionConflict
+		}
+		if newBalance < -row.OverdraftLimit {
+			return fmt.Errorf("failed to update balance: balance below overdraft limit")
+		}
+		row.Balance = newBalance
+		t.accounts[id] = row.touched(r.db.timestamp())
+		return nil
+	})
+}
+
+func (r *AccountRepository) AddCurrency(accountID uuid.UUID, currency string) error {
+	return r.db.update(func(t *tables) error {
+		if _, ok := t.accounts[accountID]; !ok {
+			return fmt.Errorf("failed to add currency: account %s does not exist", accountID)
+		}
+		key := balanceKey{accountID: accountID, currency: currency}
+		if _, ok := t.balances[key]; ok {
+			return fmt.Errorf("account already holds %s", currency)
+		}
+		t.balances[key] = 0
+		return nil
+	})
+}
+
+func (r *AccountRepository) ListBalances(accountID uuid.UUID) ([]*account.CurrencyBalance, error) {
+	balances := []*account.CurrencyBalance{}
+	err := r.db.view(func(t *tables) error {
+		keys := sortedKeys(t.balances, func(a, b balanceKey) bool { return a.currency < b.currency })
+		for _, key := range keys {
+			if key.accountID == accountID {
+				balances = append(balances, account.NewCurrencyBalance(key.currency, t.balances[key]))
+			}
+		}
+		return nil
+	})
+	return balances, err
+}
+
+func (r *AccountRepository) SumActiveHolds(accountID uuid.UUID) (float64, error) {
+	var held float64
+	err := r.db.view(func(t *tables) error {
+		held = t.accountHoldsSum(accountID, r.db.timestamp())
+		return nil
+	})
+	return held, err
+}
+
+func (r *AccountRepository) Delete(id uuid.UUID) error {
+	return r.db.update(func(t *tables) error {
+		row, ok := t.accounts[id]
+		if !ok {
+			return fmt.Errorf("account not found")
+		}
+		row.Status = account.AccountStatusClosed
+		t.accounts[id] = row.touched(r.db.timestamp())
+		return nil
+	})
+}
+
+func (r *AccountRepository) GenerateAccountNumber() (string, error) {
+	const maxAttempts = 10
+
+	for i := 0; i < maxAttempts; i++ {
+		n, err := rand.Int(rand.Reader, big.NewInt(10000000000))
+		if err != nil {
+			return "", fmt.Errorf("failed to generate random number: %w", err)
+		}
+		accountNumber := fmt.Sprintf("MDA%010d", n.Int64())
+
+		exists := false
+		_ = r.db.view(func(t *tables) error {
+			for _, row := range t.accounts {
+				if row.AccountNumber == accountNumber {
+					exists = true
+				}
+			}
+			return nil
+		})
+		if !exists {
+			return accountNumber, nil
+		}
+	}
+
+	return "", fmt.Errorf("failed to generate unique account number after %d attempts", maxAttempts)
+}
+
+// accountHoldsSum is the total of the open holds on the account: approved
+// card authorizations and active account holds that have not expired
+// availableBalance is what a debit may take from the account in its own
+// currency: the balance plus the overdraft limit, less its open holds
+func (t *tables) availableBalance(acc accountRow, now time.Time) float64 {
+	return acc.Balance + acc.OverdraftLimit - t.accountHoldsSum(acc.ID, now)
+}
+
+func (t *tables) accountHoldsSum(accountID uuid.UUID, now time.Time) float64 {
+	var held float64
+	for _, a := range t.authorizations {
+		if a.AccountID == accountID && a.Status == card.AuthorizationStatusApproved && a.ExpiresAt != nil && a.ExpiresAt.After(now) {
+			held += a.Amount
+		}
+	}
+	for _, h := range t.holds {
+		if h.AccountID == accountID && h.Status == account.HoldStatusActive && h.ExpiresAt.After(now) {
+			held += h.Amount
+		}
+	}
+	return held
+}
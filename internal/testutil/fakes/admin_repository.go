@@ -0,0 +1,180 @@
+package fakes
+
+import (
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/card"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+)
+
+var _ repository.AdminRepository = (*AdminRepository)(nil)
+
+type AdminRepository struct {
+	db *DB
+}
+
+func NewAdminRepository(db *DB) *AdminRepository {
+	return &AdminRepository{db: db}
+}
+
+// LedgerBalances recomputes every account balance from its transactions, as
+// the SQL repository does: pending transactions count as debits, archived
+// months through their per-account totals, and only movements in the
+// account's own currency count.
+func (r *AdminRepository) LedgerBalances() ([]*account.LedgerBalance, error) {
+	var balances []*account.LedgerBalance
+	err := r.db.view(func(t *tables) error {
+		for _, acc := range sortedValues(t.accounts, func(a, b *accountRow) bool { return a.AccountNumber < b.AccountNumber }) {
+			var ledger float64
+			for _, txn := range t.transactions {
+				if txn.Status != transaction.TransactionStatusCompleted && txn.Status != transaction.TransactionStatusPending {
+					continue
+				}
+				if currency, ok := metadataText(txn.Metadata, "currency"); ok && currency != acc.Currency {
+					continue
+				}
+				if txn.ToAccountID != nil && *txn.ToAccountID == acc.ID && txn.Status == transaction.TransactionStatusCompleted {
+					ledger += txn.Amount
+				}
+				if txn.FromAccountID != nil && *txn.FromAccountID == acc.ID {
+					ledger -= txn.Amount
+				}
+			}
+			for key, archived := range t.archiveBalances {
+				if key.accountID == acc.ID {
+					ledger += archived.credits - archived.debits
+				}
+			}
+			balances = append(balances, &account.LedgerBalance{
+				AccountID:     acc.ID,
+				AccountNumber: acc.AccountNumber,
+				StoredBalance: acc.Balance,
+				LedgerBalance: ledger,
+			})
+		}
+		return nil
+	})
+	return balances, err
+}
+
+// ListCardsAfter pages through every card (including blocked and expired) by id
+func (r *AdminRepository) ListCardsAfter(afterID uuid.UUID, limit int) ([]*card.Card, error) {
+	var cards []*card.Card
+	err := r.db.view(func(t *tables) error {
+		for _, c := range sortedValues(t.cards, func(a, b *card.Card) bool { return idLess(a.ID, b.ID) }) {
+			if idLess(afterID, c.ID) {
+				cards = append(cards, copyCard(c))
+			}
+		}
+		cards = page(cards, limit, 0)
+		return nil
+	})
+	return cards, err
+}
+
+// ListCardTokensAfter pages through every wallet token (including deleted) by id
+func (r *AdminRepository) ListCardTokensAfter(afterID uuid.UUID, limit int) ([]*card.Token, error) {
+	var tokens []*card.Token
+	err := r.db.view(func(t *tables) error {
+		for _, token := range sortedValues(t.cardTokens, func(a, b *card.Token) bool { return idLess(a.ID, b.ID) }) {
+			if idLess(afterID, token.ID) {
+				tokens = append(tokens, &token)
+			}
+		}
+		tokens = page(tokens, limit, 0)
+		return nil
+	})
+	return tokens, err
+}
+
+// UpdateCardTokenNumber stores a token number re-encrypted under a new data key
+func (r *AdminRepository) UpdateCardTokenNumber(id uuid.UUID, numberEncrypted, numberHash string) error {
+	return r.db.update(func(t *tables) error {
+		token, ok := t.cardTokens[id]
+		if !ok {
+			return nil
+		}
+		token.TokenNumberEncrypted, token.TokenNumberHash = numberEncrypted, numberHash
+		token.UpdatedAt = r.db.timestamp()
+		t.cardTokens[id] = token
+		return nil
+	})
+}
+
+// CountUsers counts registered users and those of them that are active
+func (r *AdminRepository) CountUsers() (int, int, error) {
+	var total, active int
+	err := r.db.view(func(t *tables) error {
+		for _, u := range t.users {
+			if u.DeletedAt != nil {
+				continue
+			}
+			total++
+			if u.IsActive {
+				active++
+			}
+		}
+		return nil
+	})
+	return total, active, err
+}
+
+// CountActiveAccountsByType counts active accounts of each account type
+func (r *AdminRepository) CountActiveAccountsByType() (map[account.AccountType]int, error) {
+	counts := map[account.AccountType]int{}
+	err := r.db.view(func(t *tables) error {
+		for _, acc := range t.accounts {
+			if acc.Status == account.AccountStatusActive {
+				counts[acc.AccountType]++
+			}
+		}
+		return nil
+	})
+	return counts, err
+}
+
+// SumActiveBalances totals the balances of active accounts by type and
+// currency
+func (r *AdminRepository) SumActiveBalances() ([]*account.BalanceTotal, error) {
+	var totals []*account.BalanceTotal
+	err := r.db.view(func(t *tables) error {
+		groups := map[account.BalanceTotal]*account.BalanceTotal{}
+		for _, acc := range t.accounts {
+			if acc.Status != account.AccountStatusActive {
+				continue
+			}
+			key := account.BalanceTotal{AccountType: acc.AccountType, Currency: acc.Currency}
+			total, ok := groups[key]
+			if !ok {
+				total = &account.BalanceTotal{AccountType: acc.AccountType, Currency: acc.Currency}
+				groups[key] = total
+				totals = append(totals, total)
+			}
+			total.Total += acc.Balance
+		}
+		return nil
+	})
+	return totals, err
+}
+
+// PendingTransactions counts pending transactions and finds the oldest one
+func (r *AdminRepository) PendingTransactions() (int, *time.Time, error) {
+	var count int
+	var oldest *time.Time
+	err := r.db.view(func(t *tables) error {
+		for _, txn := range t.transactions {
+			if txn.Status != transaction.TransactionStatusPending {
+				continue
+			}
+			count++
+			if oldest == nil || txn.CreatedAt.Before(*oldest) {
+				oldest = ptr(txn.CreatedAt)
+			}
+		}
+		return nil
+	})
+	return count, oldest, err
+}
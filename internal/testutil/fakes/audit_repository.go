@@ -0,0 +1,100 @@
+package fakes
+
+import (
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/audit"
+	"github.com/darisadam/madabank-server/internal/repository"
+)
+
+var _ repository.AuditRepository = (*AuditRepository)(nil)
+
+type AuditRepository struct {
+	db *DB
+}
+
+func NewAuditRepository(db *DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+func copyAuditLog(log audit.AuditLog) *audit.AuditLog {
+	log.UserID = copyPtr(log.UserID)
+	log.RequestBody, _ = jsonMetadata(log.RequestBody)
+	log.ResponseBody, _ = jsonMetadata(log.ResponseBody)
+	log.Metadata, _ = jsonMetadata(log.Metadata)
+	return &log
+}
+
+// Create appends the log to the hash chain, as the SQL repository does
+func (r *AuditRepository) Create(log *audit.AuditLog) error {
+	if log.Timestamp.IsZero() {
+		log.Timestamp = r.db.timestamp()
+	}
+	log.IPAddress = audit.NormalizeIP(log.IPAddress)
+
+	return r.db.update(func(t *tables) error {
+		prevHash := t.auditLastHash
+		hash, err := log.ComputeHash(prevHash)
+		if err != nil {
+			return err
+		}
+
+		t.auditLastID++
+		stored := *copyAuditLog(*log)
+		stored.ID = t.auditLastID
+		stored.Timestamp = log.Timestamp.UTC().Truncate(time.Microsecond)
+		stored.PrevHash, stored.Hash = prevHash, hash
+		t.auditLogs = append(t.auditLogs, stored)
+		t.auditLastHash = hash
+
+		log.ID, log.PrevHash, log.Hash = stored.ID, prevHash, hash
+		return nil
+	})
+}
+
+// ListOlderThan returns the oldest audit logs written before cutoff, in id order
+func (r *AuditRepository) ListOlderThan(cutoff time.Time, limit int) ([]*audit.AuditLog, error) {
+	return r.list(limit, func(log *audit.AuditLog) bool { return log.Timestamp.Before(cutoff) })
+}
+
+// ListChain returns hashed audit logs with id greater than afterID, in chain order
+func (r *AuditRepository) ListChain(afterID int64, limit int) ([]*audit.AuditLog, error) {
+	return r.list(limit, func(log *audit.AuditLog) bool { return log.ID > afterID && log.Hash != "" })
+}
+
+// list returns up to limit logs matching the condition; like the SQL
+// repository it returns nil when none match
+func (r *AuditRepository) list(limit int, match func(log *audit.AuditLog) bool) ([]*audit.AuditLog, error) {
+	var logs []*audit.AuditLog
+	err := r.db.view(func(t *tables) error {
+		// Logs are appended in id order
+		for i := range t.auditLogs {
+			if len(logs) == limit {
+				break
+			}
+			if match(&t.auditLogs[i]) {
+				logs = append(logs, copyAuditLog(t.auditLogs[i]))
+			}
+		}
+		return nil
+	})
+	return logs, err
+}
+
+// DeleteOlderThan prunes audit logs written before cutoff with id <= maxID
+func (r *AuditRepository) DeleteOlderThan(cutoff time.Time, maxID int64) (int64, error) {
+	var deleted int64
+	err := r.db.update(func(t *tables) error {
+		kept := t.auditLogs[:0:0]
+		for _, log := range t.auditLogs {
+			if log.Timestamp.Before(cutoff) && log.ID <= maxID {
+				deleted++
+				continue
+			}
+			kept = append(kept, log)
+		}
+		t.auditLogs = kept
+		return nil
+	})
+	return deleted, err
+}
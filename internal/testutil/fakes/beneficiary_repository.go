@@ -0,0 +1,162 @@
+package fakes
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/beneficiary"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+)
+
+var _ repository.BeneficiaryRepository = (*BeneficiaryRepository)(nil)
+
+type BeneficiaryRepository struct {
+	db *DB
+}
+
+func NewBeneficiaryRepository(db *DB) *BeneficiaryRepository {
+	return &BeneficiaryRepository{db: db}
+}
+
+func copyBeneficiary(b beneficiary.Beneficiary) *beneficiary.Beneficiary {
+	b.AccountID = copyPtr(b.AccountID)
+	b.VerifiedAt = copyPtr(b.VerifiedAt)
+	b.LastUsedAt = copyPtr(b.LastUsedAt)
+	return &b
+}
+
+func (r *BeneficiaryRepository) Create(b *beneficiary.Beneficiary) error {
+	return r.db.update(func(t *tables) error {
+		if _, ok := t.beneficiaries[b.ID]; ok {
+			return fmt.Errorf("failed to create beneficiary: duplicate id %s", b.ID)
+		}
+		now := r.db.timestamp()
+		b.CreatedAt, b.UpdatedAt = now, now
+		stored := *copyBeneficiary(*b)
+		stored.VerifiedAt = utcTime(b.VerifiedAt)
+		stored.LastUsedAt = nil
+		t.beneficiaries[b.ID] = stored
+		return nil
+	})
+}
+
+func (r *BeneficiaryRepository) GetByID(id uuid.UUID) (*beneficiary.Beneficiary, error) {
+	var found *beneficiary.Beneficiary
+	err := r.db.view(func(t *tables) error {
+		b, ok := t.beneficiaries[id]
+		if !ok {
+			return fmt.Errorf("beneficiary not found")
+		}
+		found = copyBeneficiary(b)
+		return nil
+	})
+	return found, err
+}
+
+// GetByAccountID returns the user's saved recipient that resolves to the
+// account, preferring verified and older entries
+func (r *BeneficiaryRepository) GetByAccountID(userID, accountID uuid.UUID) (*beneficiary.Beneficiary, error) {
+	var found *beneficiary.Beneficiary
+	err := r.db.view(func(t *tables) error {
+		for _, b := range sortedValues(t.beneficiaries, func(a, b *beneficiary.Beneficiary) bool {
+			aVerified := a.VerificationStatus == beneficiary.VerificationVerified
+			bVerified := b.VerificationStatus == beneficiary.VerificationVerified
+			if aVerified != bVerified {
+				return aVerified
+			}
+			return oldestFirst(a.CreatedAt, b.CreatedAt, a.ID, b.ID)
+		}) {
+			if b.UserID == userID && b.AccountID != nil && *b.AccountID == accountID {
+				found = copyBeneficiary(b)
+				return nil
+			}
+		}
+		return fmt.Errorf("beneficiary not found")
+	})
+	return found, err
+}
+
+func (r *BeneficiaryRepository) ListByUser(userID uuid.UUID) ([]*beneficiary.Beneficiary, error) {
+	beneficiaries := []*beneficiary.Beneficiary{}
+	err := r.db.view(func(t *tables) error {
+		for _, b := range sortedValues(t.beneficiaries, func(a, b *beneficiary.Beneficiary) bool {
+			if c := lastUsedFirst(a.LastUsedAt, b.LastUsedAt); c != 0 {
+				return c < 0
+			}
+			if a.Nickname != b.Nickname {
+				return a.Nickname < b.Nickname
+			}
+			return idLess(a.ID, b.ID)
+		}) {
+			if b.UserID == userID {
+				beneficiaries = append(beneficiaries, copyBeneficiary(b))
+			}
+		}
+		return nil
+	})
+	return beneficiaries, err
+}
+
+// lastUsedFirst orders by last use, most recent first and never used last,
+// as ORDER BY last_used_at DESC NULLS LAST does
+func lastUsedFirst(a, b *time.Time) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+	return b.Compare(*a)
+}
+
+func (r *BeneficiaryRepository) UpdateNickname(id uuid.UUID, nickname string) error {
+	return r.update(id, func(b *beneficiary.Beneficiary) { b.Nickname = nickname })
+}
+
+func (r *BeneficiaryRepository) UpdateVerification(b *beneficiary.Beneficiary) error {
+	return r.update(b.ID, func(stored *beneficiary.Beneficiary) {
+		stored.HolderName = b.HolderName
+		stored.AccountID = copyPtr(b.AccountID)
+		stored.VerificationStatus = b.VerificationStatus
+		stored.VerifiedAt = utcTime(b.VerifiedAt)
+	})
+}
+
+func (r *BeneficiaryRepository) MarkUsed(id uuid.UUID, at time.Time) error {
+	return r.update(id, func(b *beneficiary.Beneficiary) { b.LastUsedAt = ptr(at.UTC()) })
+}
+
+func (r *BeneficiaryRepository) Delete(id uuid.UUID) error {
+	return r.db.update(func(t *tables) error {
+		if _, ok := t.beneficiaries[id]; !ok {
+			return fmt.Errorf("beneficiary not found")
+		}
+		delete(t.beneficiaries, id)
+		return nil
+	})
+}
+
+func (r *BeneficiaryRepository) update(id uuid.UUID, set func(b *beneficiary.Beneficiary)) error {
+	return r.db.update(func(t *tables) error {
+		b, ok := t.beneficiaries[id]
+		if !ok {
+			return fmt.Errorf("beneficiary not found")
+		}
+		set(&b)
+		b.UpdatedAt = r.db.timestamp()
+		t.beneficiaries[id] = b
+		return nil
+	})
+}
+
+// utcTime is the time as a TIMESTAMP column stores it
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
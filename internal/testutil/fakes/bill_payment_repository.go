@@ -0,0 +1,133 @@
+package fakes
+
+import (
+	"fmt"
+
+	"github.com/darisadam/madabank-server/internal/domain/billpay"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+)
+
+var _ repository.BillPaymentRepository = (*BillPaymentRepository)(nil)
+
+type BillPaymentRepository struct {
+	db *DB
+}
+
+func NewBillPaymentRepository(db *DB) *BillPaymentRepository {
+	return &BillPaymentRepository{db: db}
+}
+
+// AddBiller stores a biller, as the migrations seed them, replacing any with
+// the same code
+func (r *BillPaymentRepository) AddBiller(b billpay.Biller) {
+	_ = r.db.update(func(t *tables) error {
+		if b.CreatedAt.IsZero() {
+			b.CreatedAt = r.db.timestamp()
+		}
+		t.billers[b.Code] = b
+		return nil
+	})
+}
+
+// ListBillers returns the active billers, optionally restricted to one category
+func (r *BillPaymentRepository) ListBillers(category billpay.BillerCategory) ([]*billpay.Biller, error) {
+	billers := []*billpay.Biller{}
+	err := r.db.view(func(t *tables) error {
+		for _, b := range sortedValues(t.billers, func(a, b *billpay.Biller) bool {
+			if a.Category != b.Category {
+				return a.Category < b.Category
+			}
+			return a.Name < b.Name
+		}) {
+			if b.Active && (category == "" || b.Category == category) {
+				billers = append(billers, &b)
+			}
+		}
+		return nil
+	})
+	return billers, err
+}
+
+func (r *BillPaymentRepository) GetBiller(code string) (*billpay.Biller, error) {
+	var found *billpay.Biller
+	err := r.db.view(func(t *tables) error {
+		b, ok := t.billers[code]
+		if !ok {
+			return fmt.Errorf("biller not found")
+		}
+		found = &b
+		return nil
+	})
+	return found, err
+}
+
+func (r *BillPaymentRepository) ExecuteBillPayment(accountID uuid.UUID, amount float64, txn *transaction.Transaction) error {
+	return r.db.update(func(t *tables) error {
+		now := r.db.timestamp()
+
+		held, err := t.lockBalance(accountID, "", now)
+		if err != nil {
+			return fmt.Errorf("failed to lock account: %w", err)
+		}
+		if held.available < amount {
+			return insufficientFunds(held.available, amount)
+		}
+		if err := t.add(held, -amount, now); err != nil {
+			return fmt.Errorf("failed to debit account: %w", err)
+		}
+
+		return t.insertTransaction(&transaction.Transaction{
+			ID:              txn.ID,
+			IdempotencyKey:  txn.IdempotencyKey,
+			FromAccountID:   &accountID,
+			Amount:          amount,
+			TransactionType: txn.TransactionType,
+			Status:          transaction.TransactionStatusPending,
+			Description:     txn.Description,
+			Metadata:        txn.Metadata,
+			CreatedAt:       now,
+		})
+	})
+}
+
+// pendingBillPayment returns the pending bill payment with the id
+func (t *tables) pendingBillPayment(id uuid.UUID) (transaction.Transaction, error) {
+	txn, ok := t.transactions[id]
+	if !ok || txn.TransactionType != transaction.TransactionTypeBillPayment || txn.Status != transaction.TransactionStatusPending {
+		return txn, fmt.Errorf("pending bill payment not found")
+	}
+	return txn, nil
+}
+
+// CompleteBillPayment marks a pending bill payment as settled at the biller
+func (r *BillPaymentRepository) CompleteBillPayment(txnID uuid.UUID, billerReference string) error {
+	return r.db.update(func(t *tables) error {
+		if _, err := t.pendingBillPayment(txnID); err != nil {
+			return err
+		}
+		t.settleTransaction(txnID, transaction.TransactionStatusCompleted,
+			map[string]interface{}{"biller_reference": billerReference}, r.db.timestamp())
+		return nil
+	})
+}
+
+// ReverseBillPayment refunds a pending bill payment the biller rejected
+func (r *BillPaymentRepository) ReverseBillPayment(txnID uuid.UUID, reason string) error {
+	return r.db.update(func(t *tables) error {
+		now := r.db.timestamp()
+		txn, err := t.pendingBillPayment(txnID)
+		if err != nil {
+			return err
+		}
+
+		// The refund is credited even if the account was frozen in the meantime
+		if err := t.moveBalance(*txn.FromAccountID, txn.Amount, now); err != nil {
+			return fmt.Errorf("failed to refund account: %w", err)
+		}
+		t.settleTransaction(txnID, transaction.TransactionStatusReversed,
+			map[string]interface{}{"reversal_reason": reason}, now)
+		return nil
+	})
+}
@@ -0,0 +1,204 @@
+package fakes
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/card"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+)
+
+var _ repository.CardAuthorizationRepository = (*CardAuthorizationRepository)(nil)
+
+type CardAuthorizationRepository struct {
+	db    *DB
+	hooks []repository.DebitHook
+}
+
+// NewCardAuthorizationRepository returns the repository. The hooks run after
+// every debit card capture.
+func NewCardAuthorizationRepository(db *DB, hooks ...repository.DebitHook) *CardAuthorizationRepository {
+	return &CardAuthorizationRepository{db: db, hooks: hooks}
+}
+
+func copyAuthorization(a card.Authorization) card.Authorization {
+	a.TokenID = copyPtr(a.TokenID)
+	a.ExpiresAt = copyPtr(a.ExpiresAt)
+	a.CapturedAmount = copyPtr(a.CapturedAmount)
+	a.TransactionID = copyPtr(a.TransactionID)
+	a.CapturedAt = copyPtr(a.CapturedAt)
+	return a
+}
+
+func (r *CardAuthorizationRepository) GetByID(id uuid.UUID) (*card.Authorization, error) {
+	var found *card.Authorization
+	err := r.db.view(func(t *tables) error {
+		a, ok := t.authorizations[id]
+		if !ok {
+			return fmt.Errorf("authorization not found")
+		}
+		a = copyAuthorization(a)
+		found = &a
+		return nil
+	})
+	return found, err
+}
+
+// Create records an authorization as-is (used for declines)
+func (r *CardAuthorizationRepository) Create(a *card.Authorization) error {
+	return r.db.update(func(t *tables) error {
+		return t.insertAuthorization(a, r.db.timestamp())
+	})
+}
+
+// PlaceHold stores an approved authorization if it fits within the card's
+// daily limit and the available funds, as the SQL repository does
+func (r *CardAuthorizationRepository) PlaceHold(a *card.Authorization, cardType card.CardType, dailyLimit float64, dayStart time.Time) (card.HoldOutcome, error) {
+	var outcome card.HoldOutcome
+	err := r.db.update(func(t *tables) error {
+		now := r.db.timestamp()
+
+		var available float64
+		if cardType == card.CardTypeCredit {
+			c, ok := t.cards[a.CardID]
+			if !ok {
+				return fmt.Errorf("failed to lock funds for authorization: card %s not found", a.CardID)
+			}
+			available = t.availableCredit(c, now)
+		} else {
+			acc, ok := t.accounts[a.AccountID]
+			if !ok || acc.Status != account.AccountStatusActive {
+				return fmt.Errorf("failed to lock funds for authorization: account %s not found or not active", a.AccountID)
+			}
+			available = t.availableBalance(acc, now)
+		}
+
+		var spentToday float64
+		for _, other := range t.authorizations {
+			if other.CardID == a.CardID &&
+				(other.Status == card.AuthorizationStatusApproved || other.Status == card.AuthorizationStatusCaptured) &&
+				!other.CreatedAt.Before(dayStart.UTC()) {
+				spentToday += other.Amount
+			}
+		}
+
+		switch {
+		case spentToday+a.Amount > dailyLimit:
+			outcome = card.HoldDailyLimitExceeded
+			return nil
+		case available < a.Amount:
+			outcome = card.HoldInsufficientFunds
+			return nil
+		}
+
+		outcome = card.HoldPlaced
+		return t.insertAuthorization(a, now)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return outcome, nil
+}
+
+func (r *CardAuthorizationRepository) Capture(id uuid.UUID, amount float64, txn *transaction.Transaction) error {
+	return r.db.update(func(t *tables) error {
+		now := r.db.timestamp()
+		a, ok := t.authorizations[id]
+		if !ok {
+			return fmt.Errorf("authorization not found")
+		}
+		if !a.IsOpen(now) {
+			return card.ErrAuthorizationNotOpen
+		}
+		if amount > a.Amount {
+			return fmt.Errorf("capture amount exceeds the authorized amount of %.2f", a.Amount)
+		}
+		c, ok := t.cards[a.CardID]
+		if !ok {
+			return fmt.Errorf("failed to lock card authorization: card %s not found", a.CardID)
+		}
+
+		if c.CardType == card.CardTypeCredit {
+			// The authorization's own hold is counted in the card's open
+			// holds, so the purchase may use it
+			available := t.availableCredit(c, now) + a.Amount
+			if available < amount {
+				return insufficientFunds(available, amount)
+			}
+			c.OutstandingBalance += amount
+			c.CyclePurchases += amount
+			t.cards[c.ID] = c
+		} else {
+			if err := r.captureDebit(t, a, amount, txn, now); err != nil {
+				return err
+			}
+			a.TransactionID = ptr(txn.ID)
+		}
+
+		a.Status = card.AuthorizationStatusCaptured
+		a.CapturedAmount = ptr(amount)
+		a.CapturedAt = ptr(now)
+		t.authorizations[id] = a
+		return nil
+	})
+}
+
+// captureDebit debits a debit card payment from the account and records txn,
+// as the SQL repository does
+func (r *CardAuthorizationRepository) captureDebit(t *tables, a card.Authorization, amount float64, txn *transaction.Transaction, now time.Time) error {
+	held, err := t.lockBalance(a.AccountID, "", now)
+	if err != nil {
+		return fmt.Errorf("failed to lock account: %w", err)
+	}
+	// The authorization's own hold is counted in the held funds; the capture
+	// may use it but not what other holds reserve
+	available := held.available + a.Amount
+	if available < amount {
+		return insufficientFunds(available, amount)
+	}
+	if err := t.add(held, -amount, now); err != nil {
+		return err
+	}
+
+	accountID := a.AccountID
+	if err := t.insertTransaction(&transaction.Transaction{
+		ID:              txn.ID,
+		IdempotencyKey:  txn.IdempotencyKey,
+		FromAccountID:   &accountID,
+		Amount:          amount,
+		TransactionType: txn.TransactionType,
+		Status:          transaction.TransactionStatusCompleted,
+		Description:     txn.Description,
+		Metadata:        txn.Metadata,
+		CreatedAt:       now,
+		CompletedAt:     &now,
+	}); err != nil {
+		return err
+	}
+
+	txn.FromAccountID, txn.Amount = &accountID, amount
+	return runDebitHooks(t, r.hooks, txn, now)
+}
+
+// availableCredit is the card's unused credit limit less its open holds
+func (t *tables) availableCredit(c card.Card, now time.Time) float64 {
+	available := c.CreditLimit - c.OutstandingBalance
+	for _, other := range t.authorizations {
+		if other.CardID == c.ID && other.IsOpen(now) {
+			available -= other.Amount
+		}
+	}
+	return available
+}
+
+func (t *tables) insertAuthorization(a *card.Authorization, now time.Time) error {
+	if _, ok := t.authorizations[a.ID]; ok {
+		return fmt.Errorf("failed to create card authorization: duplicate id %s", a.ID)
+	}
+	a.CreatedAt = now
+	t.authorizations[a.ID] = copyAuthorization(*a)
+	return nil
+}
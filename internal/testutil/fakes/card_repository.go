@@ -0,0 +1,307 @@
+package fakes
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"slices"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/card"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+)
+
+var _ repository.CardRepository = (*CardRepository)(nil)
+
+type CardRepository struct {
+	db *DB
+}
+
+func NewCardRepository(db *DB) *CardRepository {
+	return &CardRepository{db: db}
+}
+
+func copyCard(c card.Card) *card.Card {
+	c.PINLockedUntil = copyPtr(c.PINLockedUntil)
+	c.ReplacesCardID = copyPtr(c.ReplacesCardID)
+	c.Controls.BlockedMCCs = slices.Clone(c.Controls.BlockedMCCs)
+	if c.Controls.BlockedMCCs == nil {
+		c.Controls.BlockedMCCs = []string{}
+	}
+	return &c
+}
+
+func (r *CardRepository) Create(c *card.Card) error {
+	return r.db.update(func(t *tables) error {
+		return t.insertCard(c, r.db.timestamp())
+	})
+}
+
+func (t *tables) insertCard(c *card.Card, now time.Time) error {
+	if _, ok := t.cards[c.ID]; ok {
+		return fmt.Errorf("failed to create card: duplicate id %s", c.ID)
+	}
+	if _, ok := t.accounts[c.AccountID]; !ok {
+		return fmt.Errorf("failed to create card: account %s does not exist", c.AccountID)
+	}
+	if c.CardNumberHash != "" {
+		for _, other := range t.cards {
+			if other.CardNumberHash == c.CardNumberHash {
+				return fmt.Errorf("failed to create card: card number already exists")
+			}
+		}
+	}
+	if c.StatementDay == 0 {
+		c.StatementDay = card.StatementDayFor(time.Now())
+	}
+
+	c.CreatedAt = now
+	stored := *copyCard(*c)
+	stored.PINEncrypted, stored.PINFailedAttempts, stored.PINLockedUntil = "", 0, nil
+	t.cards[c.ID] = stored
+	return nil
+}
+
+func (r *CardRepository) CreateReplacement(originalID uuid.UUID, replacement *card.Card) error {
+	return r.db.update(func(t *tables) error {
+		original, ok := t.cards[originalID]
+		if !ok || original.Status == card.CardStatusExpired {
+			return fmt.Errorf("card not found")
+		}
+		// The credit line (balance and cycle totals) moves to the replacement
+		original.Status = card.CardStatusBlocked
+		original.OutstandingBalance, original.CyclePurchases, original.CyclePayments = 0, 0, 0
+		t.cards[originalID] = original
+
+		replacement.ReplacesCardID = &originalID
+		if err := t.insertCard(replacement, r.db.timestamp()); err != nil {
+			return err
+		}
+
+		for id, s := range t.cardStatements {
+			if s.CardID == originalID {
+				s.CardID = replacement.ID
+				t.cardStatements[id] = s
+			}
+		}
+		// Wallet tokens keep working on the replacement without re-provisioning
+		for id, token := range t.cardTokens {
+			if token.CardID == originalID && token.Status != card.TokenStatusDeleted {
+				token.CardID = replacement.ID
+				t.cardTokens[id] = token
+			}
+		}
+		return nil
+	})
+}
+
+func (r *CardRepository) GetByID(id uuid.UUID) (*card.Card, error) {
+	var found *card.Card
+	err := r.db.view(func(t *tables) error {
+		c, ok := t.cards[id]
+		if !ok || c.Status == card.CardStatusExpired {
+			return fmt.Errorf("card not found")
+		}
+		found = copyCard(c)
+		return nil
+	})
+	return found, err
+}
+
+// GetByNumberHash finds a card by the fingerprint of its number, including
+// blocked and expired cards so the caller can decline them explicitly
+func (r *CardRepository) GetByNumberHash(hash string) (*card.Card, error) {
+	var found *card.Card
+	err := r.db.view(func(t *tables) error {
+		for _, c := range t.cards {
+			if hash != "" && c.CardNumberHash == hash {
+				found = copyCard(c)
+				return nil
+			}
+		}
+		return fmt.Errorf("card not found")
+	})
+	return found, err
+}
+
+func (r *CardRepository) GetByAccountID(accountID uuid.UUID) ([]*card.Card, error) {
+	cards := []*card.Card{}
+	err := r.db.view(func(t *tables) error {
+		for _, c := range sortedValues(t.cards, func(a, b *card.Card) bool {
+			return newestFirst(a.CreatedAt, b.CreatedAt, a.ID, b.ID)
+		}) {
+			if c.AccountID == accountID {
+				cards = append(cards, copyCard(c))
+			}
+		}
+		return nil
+	})
+	return cards, err
+}
+
+func (r *CardRepository) Update(id uuid.UUID, updates map[string]interface{}) error {
+	return r.db.update(func(t *tables) error {
+		c, ok := t.cards[id]
+		if !ok {
+			return fmt.Errorf("card not found")
+		}
+		c = *copyCard(c)
+		if err := setColumns(&c, updates); err != nil {
+			return fmt.Errorf("failed to update card: %w", err)
+		}
+		t.cards[id] = c
+		return nil
+	})
+}
+
+func (r *CardRepository) RecordPINAttempt(id uuid.UUID, correct bool, now time.Time) (*card.PINAttempt, error) {
+	var attempt *card.PINAttempt
+	err := r.db.update(func(t *tables) error {
+		c, ok := t.cards[id]
+		if !ok {
+			return fmt.Errorf("card not found")
+		}
+		if c.PINLocked(now) {
+			attempt = &card.PINAttempt{FailedAttempts: c.PINFailedAttempts, LockedUntil: copyPtr(c.PINLockedUntil)}
+			return nil
+		}
+		c = *copyCard(c)
+		switch {
+		case correct:
+			c.PINFailedAttempts, c.PINLockedUntil = 0, nil
+		case c.PINFailedAttempts+1 >= card.MaxPINAttempts:
+			lockedUntil := now.Add(card.PINLockDuration)
+			c.PINFailedAttempts, c.PINLockedUntil = 0, &lockedUntil
+		default:
+			c.PINFailedAttempts, c.PINLockedUntil = c.PINFailedAttempts+1, nil
+		}
+		t.cards[id] = c
+		attempt = &card.PINAttempt{FailedAttempts: c.PINFailedAttempts, LockedUntil: copyPtr(c.PINLockedUntil)}
+		return nil
+	})
+	return attempt, err
+}
+
+func (r *CardRepository) UpdateControls(id uuid.UUID, controls card.Controls) error {
+	return r.db.update(func(t *tables) error {
+		c, ok := t.cards[id]
+		if !ok {
+			return fmt.Errorf("card not found")
+		}
+		c.Controls = controls
+		t.cards[id] = *copyCard(c)
+		return nil
+	})
+}
+
+func (r *CardRepository) Delete(id uuid.UUID) error {
+	return r.db.update(func(t *tables) error {
+		c, ok := t.cards[id]
+		if !ok {
+			return fmt.Errorf("card not found")
+		}
+		// Soft delete by setting status to expired
+		c.Status = card.CardStatusExpired
+		t.cards[id] = c
+		t.deleteCardTokens(id)
+		return nil
+	})
+}
+
+// deleteCardTokens deletes the card's wallet tokens, which cannot outlive it
+func (t *tables) deleteCardTokens(cardID uuid.UUID) {
+	for id, token := range t.cardTokens {
+		if token.CardID == cardID {
+			token.Status = card.TokenStatusDeleted
+			t.cardTokens[id] = token
+		}
+	}
+}
+
+func (r *CardRepository) ExpireCards(today string) (int64, error) {
+	day, err := time.Parse("2006-01-02", today)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire cards: %w", err)
+	}
+
+	var expired int64
+	err = r.db.update(func(t *tables) error {
+		for id, c := range t.cards {
+			if c.Status == card.CardStatusExpired {
+				continue
+			}
+			// Cards stay valid through the last day of their expiry month
+			validUntil := time.Date(c.ExpiryYear, time.Month(c.ExpiryMonth), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1, 0)
+			if validUntil.After(day) {
+				continue
+			}
+			c.Status = card.CardStatusExpired
+			t.cards[id] = c
+			t.deleteCardTokens(id)
+			expired++
+		}
+		return nil
+	})
+	return expired, err
+}
+
+func (r *CardRepository) GenerateCardNumber() (string, error) {
+	const maxAttempts = 10
+	for i := 0; i < maxAttempts; i++ {
+		cardNumber, err := randomLuhnNumber("4", 16)
+		if err != nil {
+			return "", err
+		}
+
+		exists := false
+		_ = r.db.view(func(t *tables) error {
+			for _, c := range t.cards {
+				if c.CardNumberEncrypted == cardNumber {
+					exists = true
+				}
+			}
+			return nil
+		})
+		if !exists {
+			return cardNumber, nil
+		}
+	}
+
+	return "", fmt.Errorf("failed to generate unique card number after %d attempts", maxAttempts)
+}
+
+func (r *CardRepository) GenerateCVV() string {
+	cvv, err := rand.Int(rand.Reader, big.NewInt(1000))
+	if err != nil {
+		return "000"
+	}
+	return fmt.Sprintf("%03d", cvv.Int64())
+}
+
+// randomLuhnNumber returns a random number of the given length that starts
+// with prefix and ends with its Luhn check digit
+func randomLuhnNumber(prefix string, length int) (string, error) {
+	number := prefix
+	for len(number) < length-1 {
+		digit, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", fmt.Errorf("failed to generate random digit: %w", err)
+		}
+		number += digit.String()
+	}
+
+	var sum int
+	for i := 0; i < len(number); i++ {
+		digit := int(number[i] - '0')
+		// Double every second digit from right to left
+		if (len(number)-i)%2 == 1 {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+	}
+	return number + fmt.Sprintf("%d", (10-sum%10)%10), nil
+}
@@ -0,0 +1,98 @@
+package fakes
+
+import (
+	"fmt"
+
+	"github.com/darisadam/madabank-server/internal/domain/card"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+)
+
+var _ repository.CardTokenRepository = (*CardTokenRepository)(nil)
+
+type CardTokenRepository struct {
+	db *DB
+}
+
+func NewCardTokenRepository(db *DB) *CardTokenRepository {
+	return &CardTokenRepository{db: db}
+}
+
+func (r *CardTokenRepository) Create(token *card.Token) error {
+	return r.db.update(func(t *tables) error {
+		if _, ok := t.cardTokens[token.ID]; ok {
+			return fmt.Errorf("failed to create card token: duplicate id %s", token.ID)
+		}
+		if _, ok := t.cards[token.CardID]; !ok {
+			return fmt.Errorf("failed to create card token: card %s does not exist", token.CardID)
+		}
+		for _, other := range t.cardTokens {
+			if other.TokenNumberHash == token.TokenNumberHash {
+				return fmt.Errorf("failed to create card token: token number already exists")
+			}
+		}
+
+		now := r.db.timestamp()
+		token.CreatedAt, token.UpdatedAt = now, now
+		t.cardTokens[token.ID] = *token
+		return nil
+	})
+}
+
+// getOne returns the token matching the condition
+func (r *CardTokenRepository) getOne(match func(token *card.Token) bool) (*card.Token, error) {
+	var found *card.Token
+	err := r.db.view(func(t *tables) error {
+		for _, token := range t.cardTokens {
+			if match(&token) {
+				found = &token
+				return nil
+			}
+		}
+		return fmt.Errorf("card token not found")
+	})
+	return found, err
+}
+
+func (r *CardTokenRepository) GetByID(id uuid.UUID) (*card.Token, error) {
+	return r.getOne(func(token *card.Token) bool { return token.ID == id })
+}
+
+func (r *CardTokenRepository) GetByNumberHash(hash string) (*card.Token, error) {
+	return r.getOne(func(token *card.Token) bool { return token.TokenNumberHash == hash })
+}
+
+// ListByCard returns the card's tokens that have not been deleted, newest first
+func (r *CardTokenRepository) ListByCard(cardID uuid.UUID) ([]*card.Token, error) {
+	tokens := []*card.Token{}
+	err := r.db.view(func(t *tables) error {
+		for _, token := range sortedValues(t.cardTokens, func(a, b *card.Token) bool {
+			return newestFirst(a.CreatedAt, b.CreatedAt, a.ID, b.ID)
+		}) {
+			if token.CardID == cardID && token.Status != card.TokenStatusDeleted {
+				tokens = append(tokens, &token)
+			}
+		}
+		return nil
+	})
+	return tokens, err
+}
+
+func (r *CardTokenRepository) UpdateStatus(id uuid.UUID, status card.TokenStatus) error {
+	return r.db.update(func(t *tables) error {
+		token, ok := t.cardTokens[id]
+		if !ok {
+			return fmt.Errorf("card token not found")
+		}
+		token.Status = status
+		token.UpdatedAt = r.db.timestamp()
+		t.cardTokens[id] = token
+		return nil
+	})
+}
+
+// GenerateTokenNumber returns a random Luhn-valid 16-digit number in the
+// wallet token range
+func (r *CardTokenRepository) GenerateTokenNumber() (string, error) {
+	return randomLuhnNumber("489537", 16)
+}
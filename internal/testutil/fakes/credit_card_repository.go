@@ -0,0 +1,128 @@
+package fakes
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/card"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+)
+
+var _ repository.CreditCardRepository = (*CreditCardRepository)(nil)
+
+type CreditCardRepository struct {
+	db *DB
+}
+
+func NewCreditCardRepository(db *DB) *CreditCardRepository {
+	return &CreditCardRepository{db: db}
+}
+
+// ExecuteRepayment debits the deposit account, reduces the card's outstanding
+// balance and records the ledger entry in a single database transaction
+func (r *CreditCardRepository) ExecuteRepayment(cardID, fromAccountID uuid.UUID, amount float64, txn *transaction.Transaction) error {
+	return r.db.update(func(t *tables) error {
+		now := r.db.timestamp()
+
+		held, err := t.lockBalance(fromAccountID, "", now)
+		if err != nil {
+			return fmt.Errorf("failed to lock source account: %w", err)
+		}
+		c, ok := t.cards[cardID]
+		if !ok || c.CardType != card.CardTypeCredit {
+			return fmt.Errorf("failed to lock card: credit card %s not found", cardID)
+		}
+
+		if held.available < amount {
+			return insufficientFunds(held.available, amount)
+		}
+		if amount > c.OutstandingBalance {
+			return fmt.Errorf("repayment of %.2f exceeds outstanding balance of %.2f", amount, c.OutstandingBalance)
+		}
+
+		if err := t.add(held, -amount, now); err != nil {
+			return fmt.Errorf("failed to debit source account: %w", err)
+		}
+		c.OutstandingBalance -= amount
+		c.CyclePayments += amount
+		t.cards[cardID] = c
+
+		return t.insertTransaction(&transaction.Transaction{
+			ID:              txn.ID,
+			IdempotencyKey:  txn.IdempotencyKey,
+			FromAccountID:   &fromAccountID,
+			Amount:          amount,
+			TransactionType: txn.TransactionType,
+			Status:          transaction.TransactionStatusCompleted,
+			Description:     txn.Description,
+			Metadata:        txn.Metadata,
+			CreatedAt:       now,
+			CompletedAt:     &now,
+		})
+	})
+}
+
+// CloseStatement stores the statement, adds its interest to the card balance
+// and starts a new cycle
+func (r *CreditCardRepository) CloseStatement(st *card.Statement) error {
+	return r.db.update(func(t *tables) error {
+		if _, ok := t.cardStatements[st.ID]; ok {
+			return fmt.Errorf("failed to create statement: duplicate id %s", st.ID)
+		}
+		c, ok := t.cards[st.CardID]
+		if !ok {
+			return fmt.Errorf("failed to create statement: card %s does not exist", st.CardID)
+		}
+
+		st.CreatedAt = r.db.timestamp()
+		stored := *st
+		stored.PeriodStart, stored.PeriodEnd, stored.DueDate = dateOf(st.PeriodStart), dateOf(st.PeriodEnd), dateOf(st.DueDate)
+		t.cardStatements[st.ID] = stored
+
+		c.OutstandingBalance += st.Interest
+		c.CyclePurchases -= st.Purchases
+		c.CyclePayments -= st.Payments
+		t.cards[st.CardID] = c
+		return nil
+	})
+}
+
+// ListStatements returns the card's statements, newest first
+func (r *CreditCardRepository) ListStatements(cardID uuid.UUID, limit int) ([]*card.Statement, error) {
+	statements := []*card.Statement{}
+	err := r.db.view(func(t *tables) error {
+		for _, st := range sortedValues(t.cardStatements, func(a, b *card.Statement) bool {
+			return a.PeriodEnd.After(b.PeriodEnd)
+		}) {
+			if st.CardID == cardID {
+				statements = append(statements, &st)
+			}
+		}
+		statements = page(statements, limit, 0)
+		return nil
+	})
+	return statements, err
+}
+
+// ListCardsDueForStatement returns credit cards whose cycle closes on
+// statementDay and that have no statement for periodEnd yet
+func (r *CreditCardRepository) ListCardsDueForStatement(statementDay int, periodEnd time.Time) ([]*card.Card, error) {
+	cards := []*card.Card{}
+	err := r.db.view(func(t *tables) error {
+		closed := map[uuid.UUID]bool{}
+		for _, st := range t.cardStatements {
+			if st.PeriodEnd.Equal(dateOf(periodEnd)) {
+				closed[st.CardID] = true
+			}
+		}
+		for _, c := range sortedValues(t.cards, func(a, b *card.Card) bool { return idLess(a.ID, b.ID) }) {
+			if c.CardType == card.CardTypeCredit && c.Status != card.CardStatusExpired && c.StatementDay == statementDay && !closed[c.ID] {
+				cards = append(cards, copyCard(c))
+			}
+		}
+		return nil
+	})
+	return cards, err
+}
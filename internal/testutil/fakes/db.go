@@ -0,0 +1,459 @@
+// Package fakes provides in-memory implementations of the repository
+// interfaces for service and integration tests. The repositories share a DB,
+// so a transfer made through the fake TransactionRepository shows up in the
+// balances read through the fake AccountRepository, as it would in Postgres.
+//
+// They follow the SQL repositories' semantics: soft deletes, version checks,
+// balance locks and constraints, and the error messages callers match on.
+// Operations that run in a database transaction are atomic: when one fails,
+// every row it touched is left as it was. Records are copied on the way in
+// and out, so changing a returned value does not change what is stored.
+package fakes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/audit"
+	"github.com/darisadam/madabank-server/internal/domain/beneficiary"
+	"github.com/darisadam/madabank-server/internal/domain/billpay"
+	"github.com/darisadam/madabank-server/internal/domain/card"
+	"github.com/darisadam/madabank-server/internal/domain/export"
+	"github.com/darisadam/madabank-server/internal/domain/interest"
+	"github.com/darisadam/madabank-server/internal/domain/loan"
+	"github.com/darisadam/madabank-server/internal/domain/merchant"
+	"github.com/darisadam/madabank-server/internal/domain/note"
+	"github.com/darisadam/madabank-server/internal/domain/queue"
+	"github.com/darisadam/madabank-server/internal/domain/reconciliation"
+	"github.com/darisadam/madabank-server/internal/domain/regulatory"
+	"github.com/darisadam/madabank-server/internal/domain/roundup"
+	"github.com/darisadam/madabank-server/internal/domain/saga"
+	"github.com/darisadam/madabank-server/internal/domain/statement"
+	"github.com/darisadam/madabank-server/internal/domain/topup"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/google/uuid"
+)
+
+// DB is the in-memory database the fake repositories share. The zero value
+// is not usable; create one with NewDB.
+type DB struct {
+	mu  sync.Mutex
+	t   tables
+	now func() time.Time
+}
+
+// NewDB returns an empty database whose clock is time.Now
+func NewDB() *DB {
+	return &DB{t: newTables(), now: time.Now}
+}
+
+// SetClock replaces the clock used for CURRENT_TIMESTAMP, such as the
+// created_at of new rows and the expiry checks of holds
+func (db *DB) SetClock(now func() time.Time) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.now = now
+}
+
+// view runs fn with the database locked
+func (db *DB) view(fn func(t *tables) error) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return fn(&db.t)
+}
+
+// update runs fn with the database locked, as one database transaction:
+// when fn fails every table is put back as it was
+func (db *DB) update(fn func(t *tables) error) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	saved := db.t.clone()
+	if err := fn(&db.t); err != nil {
+		db.t = saved
+		return err
+	}
+	return nil
+}
+
+// timestamp is CURRENT_TIMESTAMP, at the microsecond precision Postgres keeps
+func (db *DB) timestamp() time.Time {
+	return db.now().UTC().Truncate(time.Microsecond)
+}
+
+// tables holds the rows by primary key. Rows are stored by value and replaced
+// on every write, so cloning the maps is enough to snapshot the database.
+type tables struct {
+	users         map[uuid.UUID]userRow
+	refreshTokens map[string]refreshToken
+	logins        map[loginKey]struct{}
+
+	accounts     map[uuid.UUID]accountRow
+	balances     map[balanceKey]float64
+	holds        map[uuid.UUID]account.Hold
+	transactions map[uuid.UUID]transaction.Transaction
+	roundUps     map[uuid.UUID]roundup.Rule
+	templates    map[uuid.UUID]transaction.Template
+
+	cards          map[uuid.UUID]card.Card
+	cardTokens     map[uuid.UUID]card.Token
+	authorizations map[uuid.UUID]card.Authorization
+	cardStatements map[uuid.UUID]card.Statement
+
+	billers map[string]billpay.Biller
+	topups  map[uuid.UUID]topup.Topup
+
+	merchants         map[uuid.UUID]merchant.Merchant
+	paymentLinks      map[uuid.UUID]paymentLinkRow
+	settlements       map[uuid.UUID]merchant.Settlement
+	webhookDeliveries map[uuid.UUID]merchant.WebhookDelivery
+
+	loanProducts map[string]loan.Product
+	loans        map[uuid.UUID]loan.Loan
+	installments map[uuid.UUID]loan.Installment
+
+	beneficiaries map[uuid.UUID]beneficiary.Beneficiary
+	subscriptions map[uuid.UUID]statement.Subscription
+	deliveries    map[uuid.UUID]statement.Delivery
+	notes         map[uuid.UUID]note.Note
+
+	jobs  map[uuid.UUID]queue.Job
+	sagas map[uuid.UUID]saga.Saga
+
+	reconciliationRuns       map[uuid.UUID]reconciliation.Run
+	reconciliationExceptions map[uuid.UUID]reconciliation.Exception
+	flags                    map[uuid.UUID]regulatory.Flag
+	reports                  map[uuid.UUID]regulatory.Report
+
+	rateTiers interest.Tiers
+	accruals  map[uuid.UUID]interest.Accrual
+
+	exports map[uuid.UUID]export.Export
+
+	auditLogs     []audit.AuditLog
+	auditLastHash string
+	auditLastID   int64
+
+	partitions      map[time.Time]bool
+	archives        map[time.Time]transaction.Archive
+	archiveBalances map[archiveBalanceKey]archiveBalance
+}
+
+func newTables() tables {
+	return tables{
+		users:                    map[uuid.UUID]userRow{},
+		refreshTokens:            map[string]refreshToken{},
+		logins:                   map[loginKey]struct{}{},
+		accounts:                 map[uuid.UUID]accountRow{},
+		balances:                 map[balanceKey]float64{},
+		holds:                    map[uuid.UUID]account.Hold{},
+		transactions:             map[uuid.UUID]transaction.Transaction{},
+		roundUps:                 map[uuid.UUID]roundup.Rule{},
+		templates:                map[uuid.UUID]transaction.Template{},
+		cards:                    map[uuid.UUID]card.Card{},
+		cardTokens:               map[uuid.UUID]card.Token{},
+		authorizations:           map[uuid.UUID]card.Authorization{},
+		cardStatements:           map[uuid.UUID]card.Statement{},
+		billers:                  map[string]billpay.Biller{},
+		topups:                   map[uuid.UUID]topup.Topup{},
+		merchants:                map[uuid.UUID]merchant.Merchant{},
+		paymentLinks:             map[uuid.UUID]paymentLinkRow{},
+		settlements:              map[uuid.UUID]merchant.Settlement{},
+		webhookDeliveries:        map[uuid.UUID]merchant.WebhookDelivery{},
+		loanProducts:             map[string]loan.Product{},
+		loans:                    map[uuid.UUID]loan.Loan{},
+		installments:             map[uuid.UUID]loan.Installment{},
+		beneficiaries:            map[uuid.UUID]beneficiary.Beneficiary{},
+		subscriptions:            map[uuid.UUID]statement.Subscription{},
+		deliveries:               map[uuid.UUID]statement.Delivery{},
+		notes:                    map[uuid.UUID]note.Note{},
+		jobs:                     map[uuid.UUID]queue.Job{},
+		sagas:                    map[uuid.UUID]saga.Saga{},
+		reconciliationRuns:       map[uuid.UUID]reconciliation.Run{},
+		reconciliationExceptions: map[uuid.UUID]reconciliation.Exception{},
+		flags:                    map[uuid.UUID]regulatory.Flag{},
+		reports:                  map[uuid.UUID]regulatory.Report{},
+		accruals:                 map[uuid.UUID]interest.Accrual{},
+		exports:                  map[uuid.UUID]export.Export{},
+		partitions:               map[time.Time]bool{},
+		archives:                 map[time.Time]transaction.Archive{},
+		archiveBalances:          map[archiveBalanceKey]archiveBalance{},
+	}
+}
+
+func (t *tables) clone() tables {
+	return tables{
+		users:                    maps.Clone(t.users),
+		refreshTokens:            maps.Clone(t.refreshTokens),
+		logins:                   maps.Clone(t.logins),
+		accounts:                 maps.Clone(t.accounts),
+		balances:                 maps.Clone(t.balances),
+		holds:                    maps.Clone(t.holds),
+		transactions:             maps.Clone(t.transactions),
+		roundUps:                 maps.Clone(t.roundUps),
+		templates:                maps.Clone(t.templates),
+		cards:                    maps.Clone(t.cards),
+		cardTokens:               maps.Clone(t.cardTokens),
+		authorizations:           maps.Clone(t.authorizations),
+		cardStatements:           maps.Clone(t.cardStatements),
+		billers:                  maps.Clone(t.billers),
+		topups:                   maps.Clone(t.topups),
+		merchants:                maps.Clone(t.merchants),
+		paymentLinks:             maps.Clone(t.paymentLinks),
+		settlements:              maps.Clone(t.settlements),
+		webhookDeliveries:        maps.Clone(t.webhookDeliveries),
+		loanProducts:             maps.Clone(t.loanProducts),
+		loans:                    maps.Clone(t.loans),
+		installments:             maps.Clone(t.installments),
+		beneficiaries:            maps.Clone(t.beneficiaries),
+		subscriptions:            maps.Clone(t.subscriptions),
+		deliveries:               maps.Clone(t.deliveries),
+		notes:                    maps.Clone(t.notes),
+		jobs:                     maps.Clone(t.jobs),
+		sagas:                    maps.Clone(t.sagas),
+		reconciliationRuns:       maps.Clone(t.reconciliationRuns),
+		reconciliationExceptions: maps.Clone(t.reconciliationExceptions),
+		flags:                    maps.Clone(t.flags),
+		reports:                  maps.Clone(t.reports),
+		rateTiers:                slices.Clone(t.rateTiers),
+		accruals:                 maps.Clone(t.accruals),
+		exports:                  maps.Clone(t.exports),
+		auditLogs:                slices.Clone(t.auditLogs),
+		auditLastHash:            t.auditLastHash,
+		auditLastID:              t.auditLastID,
+		partitions:               maps.Clone(t.partitions),
+		archives:                 maps.Clone(t.archives),
+		archiveBalances:          maps.Clone(t.archiveBalances),
+	}
+}
+
+// sortedValues returns the map's values ordered by less
+func sortedValues[K comparable, V any](m map[K]V, less func(a, b *V) bool) []V {
+	values := make([]V, 0, len(m))
+	for _, v := range m {
+		values = append(values, v)
+	}
+	slices.SortFunc(values, func(a, b V) int {
+		switch {
+		case less(&a, &b):
+			return -1
+		case less(&b, &a):
+			return 1
+		}
+		return 0
+	})
+	return values
+}
+
+// sortedKeys returns the map's keys ordered by less
+func sortedKeys[K comparable, V any](m map[K]V, less func(a, b K) bool) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b K) int {
+		switch {
+		case less(a, b):
+			return -1
+		case less(b, a):
+			return 1
+		}
+		return 0
+	})
+	return keys
+}
+
+// page applies LIMIT and OFFSET; a negative limit returns every row
+func page[T any](rows []T, limit, offset int) []T {
+	if offset >= len(rows) {
+		return rows[:0]
+	}
+	rows = rows[offset:]
+	if limit >= 0 && limit < len(rows) {
+		rows = rows[:limit]
+	}
+	return rows
+}
+
+// idLess orders UUIDs as Postgres does, by their bytes
+func idLess(a, b uuid.UUID) bool {
+	return bytes.Compare(a[:], b[:]) < 0
+}
+
+// newestFirst orders by created time descending, then by id descending
+func newestFirst(aCreated, bCreated time.Time, aID, bID uuid.UUID) bool {
+	if !aCreated.Equal(bCreated) {
+		return aCreated.After(bCreated)
+	}
+	return idLess(bID, aID)
+}
+
+// oldestFirst orders by created time, then by id
+func oldestFirst(aCreated, bCreated time.Time, aID, bID uuid.UUID) bool {
+	if !aCreated.Equal(bCreated) {
+		return aCreated.Before(bCreated)
+	}
+	return idLess(aID, bID)
+}
+
+// dateOf truncates a time to its date, as a DATE column stores it
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// copyPtr copies the value a pointer field points to, so stored rows and the
+// values handed out do not share it
+func copyPtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+// jsonMetadata stores metadata the way a JSONB column does, so numbers read
+// back as float64
+func jsonMetadata(metadata map[string]interface{}) (map[string]interface{}, error) {
+	if metadata == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	var stored map[string]interface{}
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
+	}
+	return stored, nil
+}
+
+// withMetadata returns a copy of the metadata with the keys merged in, as
+// metadata || jsonb_build_object(...) does
+func withMetadata(metadata map[string]interface{}, extra map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(metadata)+len(extra))
+	maps.Copy(merged, metadata)
+	maps.Copy(merged, extra)
+	return merged
+}
+
+// setColumns applies a column => value map to a row, as the dynamic UPDATE
+// statements of the SQL repositories do. Columns are matched to fields by
+// their snake_case names, so kyc_status sets KYCStatus. Values are converted
+// to the field's type where Postgres would accept them, such as a string for
+// a *FreezeReason; nil clears a field.
+func setColumns(row interface{}, updates map[string]interface{}) error {
+	v := reflect.ValueOf(row).Elem()
+	for column, value := range updates {
+		field, ok := fieldByColumn(v, column)
+		if !ok {
+			return fmt.Errorf("column %q does not exist", column)
+		}
+		if err := setField(field, value); err != nil {
+			return fmt.Errorf("invalid value for column %q: %w", column, err)
+		}
+	}
+	return nil
+}
+
+func fieldByColumn(v reflect.Value, column string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			if field, ok := fieldByColumn(v.Field(i), column); ok {
+				return field, true
+			}
+			continue
+		}
+		if snakeCase(f.Name) == column {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// snakeCase converts a Go field name to its column name, keeping acronyms
+// together: PINFailedAttempts is pin_failed_attempts
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+func setField(field reflect.Value, value interface{}) error {
+	if value == nil {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+
+	val := reflect.ValueOf(value)
+	if val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			field.Set(reflect.Zero(field.Type()))
+			return nil
+		}
+		if field.Kind() != reflect.Ptr {
+			val = val.Elem()
+		}
+	}
+
+	target := field.Type()
+	if target.Kind() == reflect.Ptr && val.Type() != target {
+		converted, err := convert(val, target.Elem())
+		if err != nil {
+			return err
+		}
+		p := reflect.New(target.Elem())
+		p.Elem().Set(converted)
+		field.Set(p)
+		return nil
+	}
+
+	converted, err := convert(val, target)
+	if err != nil {
+		return err
+	}
+	field.Set(converted)
+	return nil
+}
+
+func convert(val reflect.Value, target reflect.Type) (reflect.Value, error) {
+	if val.Type().AssignableTo(target) {
+		return val, nil
+	}
+	numeric := func(k reflect.Kind) bool {
+		return k >= reflect.Int && k <= reflect.Float64
+	}
+	if val.Kind() == target.Kind() || (numeric(val.Kind()) && numeric(target.Kind())) {
+		if val.Type().ConvertibleTo(target) {
+			return val.Convert(target), nil
+		}
+	}
+	return reflect.Value{}, fmt.Errorf("cannot use %s as %s", val.Type(), target)
+}
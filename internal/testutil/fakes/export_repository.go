@@ -0,0 +1,180 @@
+package fakes
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/export"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+)
+
+var _ repository.ExportRepository = (*ExportRepository)(nil)
+
+type ExportRepository struct {
+	db *DB
+}
+
+func NewExportRepository(db *DB) *ExportRepository {
+	return &ExportRepository{db: db}
+}
+
+func copyExport(e export.Export) *export.Export {
+	e.StartedAt = copyPtr(e.StartedAt)
+	e.CompletedAt = copyPtr(e.CompletedAt)
+	return &e
+}
+
+func (r *ExportRepository) Create(e *export.Export) error {
+	return r.db.update(func(t *tables) error {
+		if _, ok := t.exports[e.ID]; ok {
+			return fmt.Errorf("failed to create export: duplicate id %s", e.ID)
+		}
+		now := r.db.timestamp()
+		e.CreatedAt, e.UpdatedAt = now, now
+		t.exports[e.ID] = export.Export{
+			ID:          e.ID,
+			Kind:        e.Kind,
+			PeriodStart: dateOf(e.PeriodStart),
+			PeriodEnd:   dateOf(e.PeriodEnd),
+			Status:      e.Status,
+			RequestedBy: e.RequestedBy,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		return nil
+	})
+}
+
+func (r *ExportRepository) GetByID(id uuid.UUID) (*export.Export, error) {
+	var found *export.Export
+	err := r.db.view(func(t *tables) error {
+		e, ok := t.exports[id]
+		if !ok {
+			return fmt.Errorf("export not found")
+		}
+		found = copyExport(e)
+		return nil
+	})
+	return found, err
+}
+
+func (r *ExportRepository) List(limit int) ([]*export.Export, error) {
+	exports := []*export.Export{}
+	err := r.db.view(func(t *tables) error {
+		for _, e := range sortedValues(t.exports, func(a, b *export.Export) bool {
+			return newestFirst(a.CreatedAt, b.CreatedAt, a.ID, b.ID)
+		}) {
+			exports = append(exports, copyExport(e))
+		}
+		exports = page(exports, limit, 0)
+		return nil
+	})
+	return exports, err
+}
+
+func (r *ExportRepository) Start(id uuid.UUID, total int, now time.Time) error {
+	return r.update(id, func(e *export.Export) {
+		now := now.UTC()
+		e.Status = export.StatusRunning
+		e.Total, e.Processed = total, 0
+		e.Error = ""
+		e.StartedAt, e.UpdatedAt = &now, now
+	})
+}
+
+func (r *ExportRepository) UpdateProgress(id uuid.UUID, processed int) error {
+	return r.update(id, func(e *export.Export) {
+		e.Processed = processed
+		e.UpdatedAt = r.db.timestamp()
+	})
+}
+
+func (r *ExportRepository) Complete(id uuid.UUID, processed int, location string, now time.Time) error {
+	return r.update(id, func(e *export.Export) {
+		now := now.UTC()
+		e.Status = export.StatusCompleted
+		e.Processed, e.Location = processed, location
+		e.CompletedAt, e.UpdatedAt = &now, now
+	})
+}
+
+func (r *ExportRepository) Fail(id uuid.UUID, reason string, now time.Time) error {
+	return r.update(id, func(e *export.Export) {
+		now := now.UTC()
+		e.Status = export.StatusFailed
+		e.Error = reason
+		e.CompletedAt, e.UpdatedAt = &now, now
+	})
+}
+
+func (r *ExportRepository) update(id uuid.UUID, set func(e *export.Export)) error {
+	return r.db.update(func(t *tables) error {
+		e, ok := t.exports[id]
+		if !ok {
+			return fmt.Errorf("export not found")
+		}
+		set(&e)
+		t.exports[id] = e
+		return nil
+	})
+}
+
+func (r *ExportRepository) CountAccounts(before time.Time) (int, error) {
+	var n int
+	err := r.db.view(func(t *tables) error {
+		for _, acc := range t.accounts {
+			if acc.CreatedAt.Before(before) {
+				n++
+			}
+		}
+		return nil
+	})
+	return n, err
+}
+
+func (r *ExportRepository) ListAccountsAfter(before time.Time, afterID uuid.UUID, limit int) ([]*account.Account, error) {
+	accounts := []*account.Account{}
+	err := r.db.view(func(t *tables) error {
+		for _, acc := range sortedValues(t.accounts, func(a, b *accountRow) bool { return idLess(a.ID, b.ID) }) {
+			if acc.CreatedAt.Before(before) && idLess(afterID, acc.ID) {
+				accounts = append(accounts, copyAccount(acc.Account))
+			}
+		}
+		accounts = page(accounts, limit, 0)
+		return nil
+	})
+	return accounts, err
+}
+
+func (r *ExportRepository) CountTransactions(from, to time.Time) (int, error) {
+	var n int
+	err := r.db.view(func(t *tables) error {
+		for _, txn := range t.transactions {
+			if !txn.CreatedAt.Before(from) && txn.CreatedAt.Before(to) {
+				n++
+			}
+		}
+		return nil
+	})
+	return n, err
+}
+
+func (r *ExportRepository) ListTransactionsAfter(from, to time.Time, after *transaction.Transaction, limit int) ([]*transaction.Transaction, error) {
+	var txns []*transaction.Transaction
+	err := r.db.view(func(t *tables) error {
+		txns = t.selectTransactions(func(txn *transaction.Transaction) bool {
+			if txn.CreatedAt.Before(from) || !txn.CreatedAt.Before(to) {
+				return false
+			}
+			return after == nil || oldestFirst(after.CreatedAt, txn.CreatedAt, after.ID, txn.ID)
+		}, func(a, b *transaction.Transaction) bool {
+			return oldestFirst(a.CreatedAt, b.CreatedAt, a.ID, b.ID)
+		})
+		txns = page(txns, limit, 0)
+		return nil
+	})
+	return txns, err
+}
@@ -0,0 +1,432 @@
+package fakes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/card"
+	"github.com/darisadam/madabank-server/internal/domain/queue"
+	"github.com/darisadam/madabank-server/internal/domain/roundup"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/domain/user"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createUser(t *testing.T, db *DB) *user.User {
+	t.Helper()
+	u := &user.User{ID: uuid.New(), Email: uuid.NewString() + "@example.com", FirstName: "Ada", LastName: "Lovelace", IsActive: true}
+	require.NoError(t, NewUserRepository(db).Create(u))
+	return u
+}
+
+func createAccount(t *testing.T, db *DB, userID uuid.UUID, balance float64) *account.Account {
+	t.Helper()
+	repo := NewAccountRepository(db)
+	number, err := repo.GenerateAccountNumber()
+	require.NoError(t, err)
+	acc := &account.Account{
+		ID:            uuid.New(),
+		UserID:        userID,
+		AccountNumber: number,
+		AccountType:   account.AccountTypeChecking,
+		Balance:       balance,
+		Currency:      "IDR",
+		Status:        account.AccountStatusActive,
+	}
+	require.NoError(t, repo.Create(acc))
+	return acc
+}
+
+func transfer() *transaction.Transaction {
+	return &transaction.Transaction{
+		ID:              uuid.New(),
+		IdempotencyKey:  uuid.NewString(),
+		TransactionType: transaction.TransactionTypeTransfer,
+	}
+}
+
+func balanceOf(t *testing.T, db *DB, id uuid.UUID) float64 {
+	t.Helper()
+	acc, err := NewAccountRepository(db).GetByID(id)
+	require.NoError(t, err)
+	return acc.Balance
+}
+
+func TestExecuteTransfer(t *testing.T) {
+	db := NewDB()
+	u := createUser(t, db)
+	from := createAccount(t, db, u.ID, 100)
+	to := createAccount(t, db, u.ID, 0)
+	repo := NewTransactionRepository(db)
+
+	txn := transfer()
+	require.NoError(t, repo.ExecuteTransfer(from.ID, to.ID, 40, txn))
+
+	assert.Equal(t, 60.0, balanceOf(t, db, from.ID))
+	assert.Equal(t, 40.0, balanceOf(t, db, to.ID))
+
+	stored, err := repo.GetByID(txn.ID)
+	require.NoError(t, err)
+	assert.Equal(t, transaction.TransactionStatusCompleted, stored.Status)
+	assert.Equal(t, 40.0, stored.Amount)
+	assert.NotNil(t, stored.CompletedAt)
+}
+
+func TestExecuteTransfer_InsufficientFundsRollsBack(t *testing.T) {
+	db := NewDB()
+	u := createUser(t, db)
+	from := createAccount(t, db, u.ID, 10)
+	to := createAccount(t, db, u.ID, 0)
+	repo := NewTransactionRepository(db)
+
+	txn := transfer()
+	err := repo.ExecuteTransfer(from.ID, to.ID, 40, txn)
+	assert.ErrorIs(t, err, transaction.ErrInsufficientFunds)
+
+	assert.Equal(t, 10.0, balanceOf(t, db, from.ID))
+	assert.Equal(t, 0.0, balanceOf(t, db, to.ID))
+	_, err = repo.GetByID(txn.ID)
+	assert.EqualError(t, err, "transaction not found")
+}
+
+func TestExecuteTransfer_HoldBlocksTransfer(t *testing.T) {
+	db := NewDB()
+	u := createUser(t, db)
+	from := createAccount(t, db, u.ID, 100)
+	to := createAccount(t, db, u.ID, 0)
+	holds := NewHoldRepository(db)
+	repo := NewTransactionRepository(db)
+
+	hold := &account.Hold{
+		ID:        uuid.New(),
+		AccountID: from.ID,
+		Amount:    70,
+		Purpose:   account.HoldPurposeOther,
+		Status:    account.HoldStatusActive,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	require.NoError(t, holds.Place(hold))
+
+	err := repo.ExecuteTransfer(from.ID, to.ID, 40, transfer())
+	assert.ErrorIs(t, err, transaction.ErrInsufficientFunds)
+	assert.Equal(t, 100.0, balanceOf(t, db, from.ID))
+
+	require.NoError(t, holds.Release(hold.ID))
+	require.NoError(t, repo.ExecuteTransfer(from.ID, to.ID, 40, transfer()))
+	assert.Equal(t, 60.0, balanceOf(t, db, from.ID))
+}
+
+func TestExecuteBillPayment_HoldBlocksPayment(t *testing.T) {
+	db := NewDB()
+	u := createUser(t, db)
+	acc := createAccount(t, db, u.ID, 100)
+	require.NoError(t, NewHoldRepository(db).Place(&account.Hold{
+		ID:        uuid.New(),
+		AccountID: acc.ID,
+		Amount:    70,
+		Purpose:   account.HoldPurposeOther,
+		Status:    account.HoldStatusActive,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}))
+	repo := NewBillPaymentRepository(db)
+
+	payment := func() *transaction.Transaction {
+		txn := transfer()
+		txn.TransactionType = transaction.TransactionTypeBillPayment
+		return txn
+	}
+
+	err := repo.ExecuteBillPayment(acc.ID, 40, payment())
+	assert.ErrorIs(t, err, transaction.ErrInsufficientFunds)
+	assert.Equal(t, 100.0, balanceOf(t, db, acc.ID))
+
+	require.NoError(t, repo.ExecuteBillPayment(acc.ID, 30, payment()))
+	assert.Equal(t, 70.0, balanceOf(t, db, acc.ID))
+}
+
+func TestExecuteWithdrawal_Overdraft(t *testing.T) {
+	db := NewDB()
+	u := createUser(t, db)
+	created := createAccount(t, db, u.ID, 20)
+	accounts := NewAccountRepository(db)
+	acc, err := accounts.GetByID(created.ID)
+	require.NoError(t, err)
+	require.NoError(t, accounts.Update(acc.ID, acc.Version, map[string]interface{}{"overdraft_limit": 50.0}))
+	repo := NewTransactionRepository(db)
+
+	withdrawal := func() *transaction.Transaction {
+		txn := transfer()
+		txn.TransactionType = transaction.TransactionTypeWithdrawal
+		return txn
+	}
+
+	require.NoError(t, repo.ExecuteWithdrawal(acc.ID, 60, withdrawal()))
+	assert.Equal(t, -40.0, balanceOf(t, db, acc.ID))
+
+	err = repo.ExecuteWithdrawal(acc.ID, 20, withdrawal())
+	assert.ErrorIs(t, err, transaction.ErrInsufficientFunds)
+
+	require.NoError(t, repo.ExecuteWithdrawal(acc.ID, 10, withdrawal()))
+	assert.Equal(t, -50.0, balanceOf(t, db, acc.ID))
+}
+
+func TestPlaceHold_DebitCardUsesOverdraft(t *testing.T) {
+	db := NewDB()
+	u := createUser(t, db)
+	created := createAccount(t, db, u.ID, 20)
+	accounts := NewAccountRepository(db)
+	acc, err := accounts.GetByID(created.ID)
+	require.NoError(t, err)
+	require.NoError(t, accounts.Update(acc.ID, acc.Version, map[string]interface{}{"overdraft_limit": 50.0}))
+	repo := NewCardAuthorizationRepository(db)
+
+	authorize := func(amount float64) card.HoldOutcome {
+		expires := time.Now().Add(time.Hour)
+		outcome, err := repo.PlaceHold(&card.Authorization{
+			ID:        uuid.New(),
+			CardID:    uuid.New(),
+			AccountID: acc.ID,
+			Amount:    amount,
+			Status:    card.AuthorizationStatusApproved,
+			ExpiresAt: &expires,
+		}, card.CardTypeDebit, 1000, time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		return outcome
+	}
+
+	assert.Equal(t, card.HoldPlaced, authorize(60))
+	assert.Equal(t, card.HoldInsufficientFunds, authorize(20))
+	assert.Equal(t, card.HoldPlaced, authorize(10))
+}
+
+func TestCaptureAuthorization_DebitCard(t *testing.T) {
+	db := NewDB()
+	u := createUser(t, db)
+	acc := createAccount(t, db, u.ID, 100)
+	c := &card.Card{ID: uuid.New(), AccountID: acc.ID, CardType: card.CardTypeDebit, Status: card.CardStatusActive}
+	require.NoError(t, NewCardRepository(db).Create(c))
+	repo := NewCardAuthorizationRepository(db)
+
+	expires := time.Now().Add(time.Hour)
+	a := &card.Authorization{ID: uuid.New(), CardID: c.ID, AccountID: acc.ID, Amount: 100, Status: card.AuthorizationStatusApproved, ExpiresAt: &expires}
+	outcome, err := repo.PlaceHold(a, card.CardTypeDebit, 1000, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, card.HoldPlaced, outcome)
+
+	// The whole balance is held, but the capture may use its own hold
+	txn := transfer()
+	txn.TransactionType = transaction.TransactionTypeWithdrawal
+	require.NoError(t, repo.Capture(a.ID, 80, txn))
+	assert.Equal(t, 20.0, balanceOf(t, db, acc.ID))
+
+	captured, err := repo.GetByID(a.ID)
+	require.NoError(t, err)
+	assert.Equal(t, card.AuthorizationStatusCaptured, captured.Status)
+	assert.Equal(t, 80.0, *captured.CapturedAmount)
+	assert.Equal(t, txn.ID, *captured.TransactionID)
+
+	assert.ErrorIs(t, repo.Capture(a.ID, 20, transfer()), card.ErrAuthorizationNotOpen)
+}
+
+func TestCaptureAuthorization_CreditCard(t *testing.T) {
+	db := NewDB()
+	u := createUser(t, db)
+	acc := createAccount(t, db, u.ID, 0)
+	c := &card.Card{ID: uuid.New(), AccountID: acc.ID, CardType: card.CardTypeCredit, Status: card.CardStatusActive, CreditLimit: 500}
+	cards := NewCardRepository(db)
+	require.NoError(t, cards.Create(c))
+	repo := NewCardAuthorizationRepository(db)
+
+	expires := time.Now().Add(time.Hour)
+	a := &card.Authorization{ID: uuid.New(), CardID: c.ID, AccountID: acc.ID, Amount: 500, Status: card.AuthorizationStatusApproved, ExpiresAt: &expires}
+	outcome, err := repo.PlaceHold(a, card.CardTypeCredit, 1000, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, card.HoldPlaced, outcome)
+
+	require.NoError(t, repo.Capture(a.ID, 500, transfer()))
+
+	// The purchase is charged to the card; the deposit account is untouched
+	stored, err := cards.GetByID(c.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 500.0, stored.OutstandingBalance)
+	assert.Equal(t, 500.0, stored.CyclePurchases)
+	assert.Equal(t, 0.0, balanceOf(t, db, acc.ID))
+
+	captured, err := repo.GetByID(a.ID)
+	require.NoError(t, err)
+	assert.Nil(t, captured.TransactionID)
+}
+
+func TestRecordPINAttempt_Locks(t *testing.T) {
+	db := NewDB()
+	u := createUser(t, db)
+	acc := createAccount(t, db, u.ID, 0)
+	c := &card.Card{ID: uuid.New(), AccountID: acc.ID, CardType: card.CardTypeDebit, Status: card.CardStatusActive}
+	cards := NewCardRepository(db)
+	require.NoError(t, cards.Create(c))
+	now := time.Now()
+
+	for i := 1; i < card.MaxPINAttempts; i++ {
+		attempt, err := cards.RecordPINAttempt(c.ID, false, now)
+		require.NoError(t, err)
+		assert.Equal(t, i, attempt.FailedAttempts)
+		assert.Nil(t, attempt.LockedUntil)
+	}
+	attempt, err := cards.RecordPINAttempt(c.ID, false, now)
+	require.NoError(t, err)
+	require.NotNil(t, attempt.LockedUntil)
+
+	// While locked, even a correct PIN changes nothing
+	again, err := cards.RecordPINAttempt(c.ID, true, now)
+	require.NoError(t, err)
+	assert.Equal(t, attempt.LockedUntil, again.LockedUntil)
+
+	// Once the lock runs out, a correct PIN clears it
+	cleared, err := cards.RecordPINAttempt(c.ID, true, now.Add(card.PINLockDuration))
+	require.NoError(t, err)
+	assert.Equal(t, 0, cleared.FailedAttempts)
+	assert.Nil(t, cleared.LockedUntil)
+}
+
+func TestHoldCapture_UsesOwnReservation(t *testing.T) {
+	db := NewDB()
+	u := createUser(t, db)
+	acc := createAccount(t, db, u.ID, 100)
+	holds := NewHoldRepository(db)
+
+	hold := &account.Hold{
+		ID:        uuid.New(),
+		AccountID: acc.ID,
+		Amount:    100,
+		Purpose:   account.HoldPurposeOther,
+		Status:    account.HoldStatusActive,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	require.NoError(t, holds.Place(hold))
+
+	txn := transfer()
+	txn.TransactionType = transaction.TransactionTypeWithdrawal
+	require.NoError(t, holds.Capture(hold.ID, 100, txn))
+	assert.Equal(t, 0.0, balanceOf(t, db, acc.ID))
+}
+
+func TestExecuteTransfer_DuplicateIdempotencyKeyRollsBack(t *testing.T) {
+	db := NewDB()
+	u := createUser(t, db)
+	from := createAccount(t, db, u.ID, 100)
+	to := createAccount(t, db, u.ID, 0)
+	repo := NewTransactionRepository(db)
+
+	first := transfer()
+	require.NoError(t, repo.ExecuteTransfer(from.ID, to.ID, 10, first))
+
+	second := transfer()
+	second.IdempotencyKey = first.IdempotencyKey
+	assert.Error(t, repo.ExecuteTransfer(from.ID, to.ID, 10, second))
+
+	assert.Equal(t, 90.0, balanceOf(t, db, from.ID))
+	assert.Equal(t, 10.0, balanceOf(t, db, to.ID))
+}
+
+func TestExecuteTransfer_RoundUpHook(t *testing.T) {
+	db := NewDB()
+	u := createUser(t, db)
+	from := createAccount(t, db, u.ID, 5000)
+	to := createAccount(t, db, u.ID, 0)
+	savings := createAccount(t, db, u.ID, 0)
+
+	roundUps := NewRoundUpRepository(db)
+	require.NoError(t, roundUps.Save(&roundup.Rule{ID: uuid.New(), UserID: u.ID, AccountID: from.ID, SavingsAccountID: savings.ID}))
+	repo := NewTransactionRepository(db, roundUps)
+
+	debit := roundup.Increment - 0.25
+	require.NoError(t, repo.ExecuteTransfer(from.ID, to.ID, debit, transfer()))
+
+	assert.InDelta(t, 5000-roundup.Increment, balanceOf(t, db, from.ID), 0.001)
+	assert.InDelta(t, 0.25, balanceOf(t, db, savings.ID), 0.001)
+
+	rule, err := roundUps.GetByAccountID(from.ID)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.25, rule.TotalSaved, 0.001)
+}
+
+func TestAccountUpdate_VersionConflict(t *testing.T) {
+	db := NewDB()
+	u := createUser(t, db)
+	created := createAccount(t, db, u.ID, 0)
+	repo := NewAccountRepository(db)
+	// Like the SQL repository, Create does not return the version
+	acc, err := repo.GetByID(created.ID)
+	require.NoError(t, err)
+
+	require.NoError(t, repo.Update(acc.ID, acc.Version, map[string]interface{}{"interest_rate": 2.5}))
+
+	err = repo.Update(acc.ID, acc.Version, map[string]interface{}{"interest_rate": 3.0})
+	assert.ErrorIs(t, err, account.ErrVersionConflict)
+
+	stored, err := repo.GetByID(acc.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2.5, stored.InterestRate)
+	assert.Equal(t, acc.Version+1, stored.Version)
+}
+
+func TestJobComplete_LostLock(t *testing.T) {
+	repo := NewJobRepository(NewDB())
+	now := time.Now().UTC()
+	job := &queue.Job{ID: uuid.New(), Kind: "send_email", MaxAttempts: 3, RunAt: now}
+	_, err := repo.Enqueue(job)
+	require.NoError(t, err)
+
+	_, err = repo.Claim([]string{"send_email"}, "worker-a", now)
+	require.NoError(t, err)
+	requeued, err := repo.RequeueStale(now.Add(time.Minute), now.Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), requeued)
+	_, err = repo.Claim([]string{"send_email"}, "worker-b", now.Add(time.Minute))
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, repo.Complete(job.ID, "worker-a", now.Add(2*time.Minute)), queue.ErrLockLost)
+	require.NoError(t, repo.Complete(job.ID, "worker-b", now.Add(2*time.Minute)))
+
+	stored, err := repo.GetByID(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, queue.StatusCompleted, stored.Status)
+}
+
+func TestUserDeleteAndRestore(t *testing.T) {
+	db := NewDB()
+	u := createUser(t, db)
+	acc := createAccount(t, db, u.ID, 0)
+	users := NewUserRepository(db)
+
+	require.NoError(t, users.Delete(u.ID))
+	_, err := users.GetByID(u.ID)
+	assert.EqualError(t, err, "user not found")
+	frozen, err := NewAccountRepository(db).GetByID(acc.ID)
+	require.NoError(t, err)
+	assert.Equal(t, account.AccountStatusFrozen, frozen.Status)
+
+	require.NoError(t, users.Restore(u.ID))
+	restored, err := users.GetByID(u.ID)
+	require.NoError(t, err)
+	assert.Nil(t, restored.DeletedAt)
+	active, err := NewAccountRepository(db).GetByID(acc.ID)
+	require.NoError(t, err)
+	assert.Equal(t, account.AccountStatusActive, active.Status)
+}
+
+func TestReturnedRecordsAreCopies(t *testing.T) {
+	db := NewDB()
+	u := createUser(t, db)
+	acc := createAccount(t, db, u.ID, 50)
+	repo := NewAccountRepository(db)
+
+	got, err := repo.GetByID(acc.ID)
+	require.NoError(t, err)
+	got.Balance = 1000
+
+	assert.Equal(t, 50.0, balanceOf(t, db, acc.ID))
+}
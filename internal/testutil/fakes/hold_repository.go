@@ -0,0 +1,175 @@
+package fakes
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+)
+
+var _ repository.HoldRepository = (*HoldRepository)(nil)
+
+type HoldRepository struct {
+	db    *DB
+	hooks []repository.DebitHook
+}
+
+// NewHoldRepository returns the repository. The hooks run after every capture.
+func NewHoldRepository(db *DB, hooks ...repository.DebitHook) *HoldRepository {
+	return &HoldRepository{db: db, hooks: hooks}
+}
+
+func copyHold(h account.Hold) *account.Hold {
+	h.CapturedAmount = copyPtr(h.CapturedAmount)
+	h.TransactionID = copyPtr(h.TransactionID)
+	return &h
+}
+
+func (r *HoldRepository) Place(h *account.Hold) error {
+	return r.db.update(func(t *tables) error {
+		now := r.db.timestamp()
+		acc, ok := t.accounts[h.AccountID]
+		if !ok || acc.Status != account.AccountStatusActive {
+			return fmt.Errorf("account not found or not active")
+		}
+		h.Currency = acc.Currency
+		if t.availableBalance(acc, now) < h.Amount {
+			return account.ErrInsufficientAvailable
+		}
+		if _, ok := t.holds[h.ID]; ok {
+			return fmt.Errorf("failed to create hold: duplicate id %s", h.ID)
+		}
+
+		h.CreatedAt, h.UpdatedAt = now, now
+		stored := *copyHold(*h)
+		stored.ExpiresAt = h.ExpiresAt.UTC()
+		t.holds[h.ID] = stored
+		return nil
+	})
+}
+
+func (r *HoldRepository) GetByID(id uuid.UUID) (*account.Hold, error) {
+	var found *account.Hold
+	err := r.db.view(func(t *tables) error {
+		h, ok := t.holds[id]
+		if !ok {
+			return fmt.Errorf("hold not found")
+		}
+		found = copyHold(h)
+		return nil
+	})
+	return found, err
+}
+
+func (r *HoldRepository) ListActiveByAccount(accountID uuid.UUID) ([]*account.Hold, error) {
+	holds := []*account.Hold{}
+	err := r.db.view(func(t *tables) error {
+		now := r.db.timestamp()
+		for _, h := range sortedValues(t.holds, func(a, b *account.Hold) bool {
+			return oldestFirst(a.CreatedAt, b.CreatedAt, a.ID, b.ID)
+		}) {
+			if h.AccountID == accountID && h.IsActive(now) {
+				holds = append(holds, copyHold(h))
+			}
+		}
+		return nil
+	})
+	return holds, err
+}
+
+func (r *HoldRepository) Extend(id uuid.UUID, expiresAt time.Time) error {
+	return r.updateActive(id, func(h *account.Hold) { h.ExpiresAt = expiresAt.UTC() })
+}
+
+func (r *HoldRepository) Release(id uuid.UUID) error {
+	return r.updateActive(id, func(h *account.Hold) { h.Status = account.HoldStatusReleased })
+}
+
+// updateActive applies set to the hold if it is still active and unexpired
+func (r *HoldRepository) updateActive(id uuid.UUID, set func(h *account.Hold)) error {
+	return r.db.update(func(t *tables) error {
+		now := r.db.timestamp()
+		h, ok := t.holds[id]
+		if !ok || !h.IsActive(now) {
+			return account.ErrHoldNotActive
+		}
+		set(&h)
+		h.UpdatedAt = now
+		t.holds[id] = h
+		return nil
+	})
+}
+
+func (r *HoldRepository) Capture(id uuid.UUID, amount float64, txn *transaction.Transaction) error {
+	return r.db.update(func(t *tables) error {
+		now := r.db.timestamp()
+		h, ok := t.holds[id]
+		if !ok {
+			return fmt.Errorf("hold not found")
+		}
+		if !h.IsActive(now) {
+			return account.ErrHoldNotActive
+		}
+		if amount > h.Amount {
+			return fmt.Errorf("capture amount exceeds the held amount of %.2f", h.Amount)
+		}
+
+		held, err := t.lockBalance(h.AccountID, "", now)
+		if err != nil {
+			return fmt.Errorf("failed to lock account: %w", err)
+		}
+		// The hold's own reservation is counted in the held funds; the
+		// capture may use it but not what other holds reserve
+		available := held.available + h.Amount
+		if available < amount {
+			return insufficientFunds(available, amount)
+		}
+		if err := t.add(held, -amount, now); err != nil {
+			return err
+		}
+
+		accountID := h.AccountID
+		if err := t.insertTransaction(&transaction.Transaction{
+			ID:              txn.ID,
+			IdempotencyKey:  txn.IdempotencyKey,
+			FromAccountID:   &accountID,
+			Amount:          amount,
+			TransactionType: txn.TransactionType,
+			Status:          transaction.TransactionStatusCompleted,
+			Description:     txn.Description,
+			Metadata:        txn.Metadata,
+			CreatedAt:       now,
+			CompletedAt:     &now,
+		}); err != nil {
+			return err
+		}
+
+		h.Status = account.HoldStatusCaptured
+		h.CapturedAmount = ptr(amount)
+		h.TransactionID = ptr(txn.ID)
+		h.UpdatedAt = now
+		t.holds[id] = h
+
+		txn.FromAccountID, txn.Amount = &accountID, amount
+		return runDebitHooks(t, r.hooks, txn, now)
+	})
+}
+
+func (r *HoldRepository) ExpireDue(now time.Time) (int, error) {
+	var expired int
+	err := r.db.update(func(t *tables) error {
+		for id, h := range t.holds {
+			if h.Status == account.HoldStatusActive && !h.ExpiresAt.After(now) {
+				h.Status = account.HoldStatusExpired
+				h.UpdatedAt = r.db.timestamp()
+				t.holds[id] = h
+				expired++
+			}
+		}
+		return nil
+	})
+	return expired, err
+}
@@ -0,0 +1,229 @@
+package fakes
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/interest"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+)
+
+var _ repository.InterestRepository = (*InterestRepository)(nil)
+
+type InterestRepository struct {
+	db *DB
+}
+
+func NewInterestRepository(db *DB) *InterestRepository {
+	return &InterestRepository{db: db}
+}
+
+// AddRateTier seeds an interest_rate_tiers row
+func (r *InterestRepository) AddRateTier(tier interest.Tier) {
+	_ = r.db.update(func(t *tables) error {
+		t.rateTiers = append(t.rateTiers, tier)
+		return nil
+	})
+}
+
+func (r *InterestRepository) ListInterestBearing() ([]*interest.Balance, error) {
+	balances := []*interest.Balance{}
+	err := r.db.view(func(t *tables) error {
+		for _, acc := range sortedValues(t.accounts, func(a, b *accountRow) bool { return idLess(a.ID, b.ID) }) {
+			if acc.AccountType != account.AccountTypeSavings || acc.Status != account.AccountStatusActive || acc.Balance <= 0 {
+				continue
+			}
+			balances = append(balances, &interest.Balance{
+				AccountID:   acc.ID,
+				AccountType: string(acc.AccountType),
+				Currency:    acc.Currency,
+				Balance:     acc.Balance,
+				AnnualRate:  acc.InterestRate,
+			})
+		}
+		return nil
+	})
+	return balances, err
+}
+
+func (r *InterestRepository) ListRateTiers() (interest.Tiers, error) {
+	var tiers interest.Tiers
+	err := r.db.view(func(t *tables) error {
+		tiers = append(interest.Tiers{}, t.rateTiers...)
+		slices.SortStableFunc(tiers, func(a, b interest.Tier) int {
+			if c := strings.Compare(a.AccountType, b.AccountType); c != 0 {
+				return c
+			}
+			if c := strings.Compare(a.Currency, b.Currency); c != 0 {
+				return c
+			}
+			switch {
+			case a.MinBalance < b.MinBalance:
+				return -1
+			case a.MinBalance > b.MinBalance:
+				return 1
+			}
+			return 0
+		})
+		return nil
+	})
+	return tiers, err
+}
+
+func (r *InterestRepository) SaveAccruals(accruals []*interest.Accrual) (int, error) {
+	inserted := 0
+	err := r.db.update(func(t *tables) error {
+		now := r.db.timestamp()
+		for _, a := range accruals {
+			if t.hasAccrual(a.AccountID, dateOf(a.AccrualDate)) {
+				continue
+			}
+			if _, ok := t.accruals[a.ID]; ok {
+				return fmt.Errorf("failed to save interest accrual: duplicate id %s", a.ID)
+			}
+			t.accruals[a.ID] = interest.Accrual{
+				ID:          a.ID,
+				AccountID:   a.AccountID,
+				AccrualDate: dateOf(a.AccrualDate),
+				Balance:     a.Balance,
+				AnnualRate:  a.AnnualRate,
+				Amount:      a.Amount,
+				CreatedAt:   now,
+			}
+			inserted++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return inserted, nil
+}
+
+func (t *tables) hasAccrual(accountID uuid.UUID, date time.Time) bool {
+	for _, a := range t.accruals {
+		if a.AccountID == accountID && a.AccrualDate.Equal(date) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *InterestRepository) ListAccountsWithUnposted(before time.Time) ([]uuid.UUID, error) {
+	ids := []uuid.UUID{}
+	err := r.db.view(func(t *tables) error {
+		before := dateOf(before)
+		for _, a := range t.accruals {
+			if a.PostedTransactionID == nil && a.AccrualDate.Before(before) && !slices.Contains(ids, a.AccountID) {
+				ids = append(ids, a.AccountID)
+			}
+		}
+		slices.SortFunc(ids, func(a, b uuid.UUID) int { return bytes.Compare(a[:], b[:]) })
+		return nil
+	})
+	return ids, err
+}
+
+func (r *InterestRepository) PostAccruals(accountID uuid.UUID, before time.Time, txn *transaction.Transaction) (bool, error) {
+	var posted bool
+	err := r.db.update(func(t *tables) error {
+		// Closed accounts keep their accruals unposted
+		acc, ok := t.accounts[accountID]
+		if !ok || acc.Status == account.AccountStatusClosed {
+			return nil
+		}
+
+		before := dateOf(before)
+		var claimed []uuid.UUID
+		var accrued float64
+		from, to := before, before
+		for _, a := range sortedValues(t.accruals, func(a, b *interest.Accrual) bool { return a.AccrualDate.Before(b.AccrualDate) }) {
+			if a.AccountID != accountID || !a.AccrualDate.Before(before) || a.PostedTransactionID != nil {
+				continue
+			}
+			if len(claimed) == 0 {
+				from = a.AccrualDate
+			}
+			to = a.AccrualDate
+			accrued += a.Amount
+			claimed = append(claimed, a.ID)
+		}
+
+		amount := interest.PostingAmount(accrued)
+		if amount <= 0 {
+			return nil
+		}
+
+		now := r.db.timestamp()
+		txn.Amount = amount
+		txn.ToAccountID = &accountID
+		if txn.Metadata == nil {
+			txn.Metadata = map[string]interface{}{}
+		}
+		txn.Metadata["accrued"] = accrued
+		txn.Metadata["days"] = len(claimed)
+		txn.Metadata["period_start"] = from.Format(interest.DateLayout)
+		txn.Metadata["period_end"] = to.Format(interest.DateLayout)
+
+		if err := t.insertTransaction(&transaction.Transaction{
+			ID:              txn.ID,
+			IdempotencyKey:  txn.IdempotencyKey,
+			ToAccountID:     &accountID,
+			Amount:          amount,
+			TransactionType: txn.TransactionType,
+			Status:          transaction.TransactionStatusCompleted,
+			Description:     txn.Description,
+			Metadata:        txn.Metadata,
+			CreatedAt:       now,
+			CompletedAt:     &now,
+		}); err != nil {
+			return err
+		}
+		if err := t.moveBalance(accountID, amount, now); err != nil {
+			return fmt.Errorf("failed to credit account: %w", err)
+		}
+
+		for _, id := range claimed {
+			a := t.accruals[id]
+			a.PostedTransactionID = ptr(txn.ID)
+			t.accruals[id] = a
+		}
+		posted = true
+		return nil
+	})
+	return posted, err
+}
+
+func (r *InterestRepository) ListAccruals(accountID uuid.UUID, from, to time.Time) ([]*interest.Accrual, error) {
+	accruals := []*interest.Accrual{}
+	err := r.db.view(func(t *tables) error {
+		from, to := dateOf(from), dateOf(to)
+		for _, a := range sortedValues(t.accruals, func(a, b *interest.Accrual) bool { return a.AccrualDate.Before(b.AccrualDate) }) {
+			if a.AccountID == accountID && !a.AccrualDate.Before(from) && a.AccrualDate.Before(to) {
+				a.PostedTransactionID = copyPtr(a.PostedTransactionID)
+				accruals = append(accruals, &a)
+			}
+		}
+		return nil
+	})
+	return accruals, err
+}
+
+func (r *InterestRepository) ListPostings(accountID uuid.UUID, from, to time.Time) ([]*transaction.Transaction, error) {
+	completed := completedIn(from, to)
+	var txns []*transaction.Transaction
+	err := r.db.view(func(t *tables) error {
+		txns = t.selectTransactions(func(txn *transaction.Transaction) bool {
+			return txn.ToAccountID != nil && *txn.ToAccountID == accountID &&
+				txn.TransactionType == transaction.TransactionTypeInterest && completed(txn)
+		}, postingOrder)
+		return nil
+	})
+	return txns, err
+}
@@ -0,0 +1,228 @@
+package fakes
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/queue"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+)
+
+var _ repository.JobRepository = (*JobRepository)(nil)
+
+type JobRepository struct {
+	db *DB
+}
+
+func NewJobRepository(db *DB) *JobRepository {
+	return &JobRepository{db: db}
+}
+
+func copyJob(job queue.Job) *queue.Job {
+	job.Payload = bytes.Clone(job.Payload)
+	job.UniqueKey = copyPtr(job.UniqueKey)
+	job.LockedBy = copyPtr(job.LockedBy)
+	job.LockedAt = copyPtr(job.LockedAt)
+	job.CompletedAt = copyPtr(job.CompletedAt)
+	return &job
+}
+
+func (r *JobRepository) Enqueue(job *queue.Job) (bool, error) {
+	var inserted bool
+	err := r.db.update(func(t *tables) error {
+		if job.UniqueKey != nil {
+			for _, other := range t.jobs {
+				if other.UniqueKey != nil && *other.UniqueKey == *job.UniqueKey {
+					return nil
+				}
+			}
+		}
+		if _, ok := t.jobs[job.ID]; ok {
+			return fmt.Errorf("failed to enqueue job: duplicate id %s", job.ID)
+		}
+
+		payload := bytes.Clone(job.Payload)
+		if len(payload) == 0 {
+			payload = []byte("{}")
+		}
+		now := r.db.timestamp()
+		t.jobs[job.ID] = queue.Job{
+			ID:          job.ID,
+			Kind:        job.Kind,
+			Payload:     payload,
+			Status:      queue.StatusPending,
+			MaxAttempts: job.MaxAttempts,
+			RunAt:       job.RunAt.UTC(),
+			UniqueKey:   copyPtr(job.UniqueKey),
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		job.CreatedAt, job.UpdatedAt = now, now
+		job.Status = queue.StatusPending
+		inserted = true
+		return nil
+	})
+	return inserted, err
+}
+
+func (r *JobRepository) Claim(kinds []string, workerID string, now time.Time) (*queue.Job, error) {
+	var claimed *queue.Job
+	err := r.db.update(func(t *tables) error {
+		now := now.UTC()
+		for _, job := range sortedValues(t.jobs, func(a, b *queue.Job) bool {
+			return oldestFirst(a.RunAt, b.RunAt, a.ID, b.ID)
+		}) {
+			if job.Status != queue.StatusPending || job.RunAt.After(now) || !slices.Contains(kinds, job.Kind) {
+				continue
+			}
+			job.Status = queue.StatusRunning
+			job.Attempts++
+			job.LockedBy, job.LockedAt = &workerID, &now
+			job.UpdatedAt = now
+			t.jobs[job.ID] = *copyJob(job)
+			claimed = copyJob(job)
+			return nil
+		}
+		return nil
+	})
+	return claimed, err
+}
+
+func (r *JobRepository) Complete(id uuid.UUID, workerID string, now time.Time) error {
+	return r.finish(id, workerID, func(job *queue.Job) {
+		now := now.UTC()
+		job.Status = queue.StatusCompleted
+		job.CompletedAt = &now
+		job.LockedBy, job.LockedAt = nil, nil
+		job.UpdatedAt = now
+	})
+}
+
+func (r *JobRepository) Reschedule(id uuid.UUID, workerID, lastError string, runAt time.Time) error {
+	return r.finish(id, workerID, func(job *queue.Job) {
+		job.Status = queue.StatusPending
+		job.LastError = lastError
+		job.RunAt = runAt.UTC()
+		job.LockedBy, job.LockedAt = nil, nil
+		job.UpdatedAt = r.db.timestamp()
+	})
+}
+
+func (r *JobRepository) Bury(id uuid.UUID, workerID, lastError string, now time.Time) error {
+	return r.finish(id, workerID, func(job *queue.Job) {
+		job.Status = queue.StatusDead
+		job.LastError = lastError
+		job.LockedBy, job.LockedAt = nil, nil
+		job.UpdatedAt = now.UTC()
+	})
+}
+
+// finish applies set to a running job locked by workerID, returning
+// queue.ErrLockLost when the worker no longer holds it
+func (r *JobRepository) finish(id uuid.UUID, workerID string, set func(job *queue.Job)) error {
+	return r.db.update(func(t *tables) error {
+		job, ok := t.jobs[id]
+		if !ok || job.Status != queue.StatusRunning || job.LockedBy == nil || *job.LockedBy != workerID {
+			return queue.ErrLockLost
+		}
+		set(&job)
+		t.jobs[id] = job
+		return nil
+	})
+}
+
+func (r *JobRepository) RequeueStale(cutoff, now time.Time) (int64, error) {
+	var requeued int64
+	err := r.db.update(func(t *tables) error {
+		for id, job := range t.jobs {
+			if job.Status != queue.StatusRunning || job.LockedAt == nil || !job.LockedAt.Before(cutoff) {
+				continue
+			}
+			job.Status = queue.StatusPending
+			if job.Exhausted() {
+				job.Status = queue.StatusDead
+			}
+			job.LastError = "worker lock expired"
+			job.LockedBy, job.LockedAt = nil, nil
+			job.UpdatedAt = now.UTC()
+			t.jobs[id] = job
+			requeued++
+		}
+		return nil
+	})
+	return requeued, err
+}
+
+func (r *JobRepository) Retry(id uuid.UUID, now time.Time) (*queue.Job, error) {
+	var retried *queue.Job
+	err := r.db.update(func(t *tables) error {
+		job, ok := t.jobs[id]
+		if !ok || job.Status != queue.StatusDead {
+			return fmt.Errorf("dead job not found")
+		}
+		job.Status = queue.StatusPending
+		job.Attempts = 0
+		job.RunAt, job.UpdatedAt = now.UTC(), now.UTC()
+		t.jobs[id] = job
+		retried = copyJob(job)
+		return nil
+	})
+	return retried, err
+}
+
+func (r *JobRepository) DeleteCompletedBefore(cutoff time.Time) (int64, error) {
+	var deleted int64
+	err := r.db.update(func(t *tables) error {
+		for id, job := range t.jobs {
+			if job.Status == queue.StatusCompleted && job.CompletedAt != nil && job.CompletedAt.Before(cutoff) {
+				delete(t.jobs, id)
+				deleted++
+			}
+		}
+		return nil
+	})
+	return deleted, err
+}
+
+func (r *JobRepository) GetByID(id uuid.UUID) (*queue.Job, error) {
+	var found *queue.Job
+	err := r.db.view(func(t *tables) error {
+		job, ok := t.jobs[id]
+		if !ok {
+			return fmt.Errorf("job not found")
+		}
+		found = copyJob(job)
+		return nil
+	})
+	return found, err
+}
+
+func (r *JobRepository) List(status queue.Status, kind string, limit int) ([]*queue.Job, error) {
+	jobs := []*queue.Job{}
+	err := r.db.view(func(t *tables) error {
+		for _, job := range sortedValues(t.jobs, func(a, b *queue.Job) bool {
+			return newestFirst(a.UpdatedAt, b.UpdatedAt, a.ID, b.ID)
+		}) {
+			if (status == "" || job.Status == status) && (kind == "" || job.Kind == kind) {
+				jobs = append(jobs, copyJob(job))
+			}
+		}
+		jobs = page(jobs, limit, 0)
+		return nil
+	})
+	return jobs, err
+}
+
+func (r *JobRepository) CountByStatus() (map[queue.Status]int, error) {
+	counts := map[queue.Status]int{}
+	err := r.db.view(func(t *tables) error {
+		for _, job := range t.jobs {
+			counts[job.Status]++
+		}
+		return nil
+	})
+	return counts, err
+}
@@ -0,0 +1,360 @@
+package fakes
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/loan"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+)
+
+var _ repository.LoanRepository = (*LoanRepository)(nil)
+
+type LoanRepository struct {
+	db *DB
+}
+
+func NewLoanRepository(db *DB) *LoanRepository {
+	return &LoanRepository{db: db}
+}
+
+// AddProduct stores a loan product, as the migrations seed them, replacing
+// any with the same code
+func (r *LoanRepository) AddProduct(p loan.Product) {
+	_ = r.db.update(func(t *tables) error {
+		if p.CreatedAt.IsZero() {
+			p.CreatedAt = r.db.timestamp()
+		}
+		t.loanProducts[p.Code] = p
+		return nil
+	})
+}
+
+func copyLoan(l loan.Loan) *loan.Loan {
+	l.DecidedBy = copyPtr(l.DecidedBy)
+	l.DisbursementTransactionID = copyPtr(l.DisbursementTransactionID)
+	l.DecidedAt = copyPtr(l.DecidedAt)
+	l.DisbursedAt = copyPtr(l.DisbursedAt)
+	l.ClosedAt = copyPtr(l.ClosedAt)
+	l.Schedule = nil
+	return &l
+}
+
+func copyInstallment(i loan.Installment) *loan.Installment {
+	i.LastAttemptDate = copyPtr(i.LastAttemptDate)
+	i.PaidAt = copyPtr(i.PaidAt)
+	i.TransactionID = copyPtr(i.TransactionID)
+	return &i
+}
+
+func (r *LoanRepository) ListProducts() ([]*loan.Product, error) {
+	products := []*loan.Product{}
+	err := r.db.view(func(t *tables) error {
+		for _, p := range sortedValues(t.loanProducts, func(a, b *loan.Product) bool {
+			if a.MinAmount != b.MinAmount {
+				return a.MinAmount < b.MinAmount
+			}
+			return a.Code < b.Code
+		}) {
+			if p.Active {
+				products = append(products, &p)
+			}
+		}
+		return nil
+	})
+	return products, err
+}
+
+func (r *LoanRepository) GetProduct(code string) (*loan.Product, error) {
+	var found *loan.Product
+	err := r.db.view(func(t *tables) error {
+		p, ok := t.loanProducts[code]
+		if !ok {
+			return fmt.Errorf("loan product not found")
+		}
+		found = &p
+		return nil
+	})
+	return found, err
+}
+
+func (r *LoanRepository) Create(l *loan.Loan) error {
+	return r.db.update(func(t *tables) error {
+		if _, ok := t.loans[l.ID]; ok {
+			return fmt.Errorf("failed to create loan: duplicate id %s", l.ID)
+		}
+		if _, ok := t.loanProducts[l.ProductCode]; !ok {
+			return fmt.Errorf("failed to create loan: loan product %s does not exist", l.ProductCode)
+		}
+		now := r.db.timestamp()
+		l.CreatedAt, l.UpdatedAt = now, now
+		t.loans[l.ID] = loan.Loan{
+			ID:                 l.ID,
+			UserID:             l.UserID,
+			ProductCode:        l.ProductCode,
+			AccountID:          l.AccountID,
+			Principal:          l.Principal,
+			TenorMonths:        l.TenorMonths,
+			AnnualRate:         l.AnnualRate,
+			MonthlyInstallment: l.MonthlyInstallment,
+			ProvisionFee:       l.ProvisionFee,
+			Purpose:            l.Purpose,
+			Status:             l.Status,
+			CreatedAt:          now,
+			UpdatedAt:          now,
+		}
+		return nil
+	})
+}
+
+func (r *LoanRepository) GetByID(id uuid.UUID) (*loan.Loan, error) {
+	var found *loan.Loan
+	err := r.db.view(func(t *tables) error {
+		l, ok := t.loans[id]
+		if !ok {
+			return fmt.Errorf("loan not found")
+		}
+		found = copyLoan(l)
+		return nil
+	})
+	return found, err
+}
+
+func (r *LoanRepository) ListByUser(userID uuid.UUID) ([]*loan.Loan, error) {
+	return r.list(func(l *loan.Loan) bool { return l.UserID == userID }, func(a, b *loan.Loan) bool {
+		return newestFirst(a.CreatedAt, b.CreatedAt, a.ID, b.ID)
+	}, -1)
+}
+
+func (r *LoanRepository) ListByStatus(status loan.Status, limit int) ([]*loan.Loan, error) {
+	return r.list(func(l *loan.Loan) bool { return l.Status == status }, func(a, b *loan.Loan) bool {
+		return oldestFirst(a.CreatedAt, b.CreatedAt, a.ID, b.ID)
+	}, limit)
+}
+
+func (r *LoanRepository) list(match func(l *loan.Loan) bool, less func(a, b *loan.Loan) bool, limit int) ([]*loan.Loan, error) {
+	loans := []*loan.Loan{}
+	err := r.db.view(func(t *tables) error {
+		for _, l := range sortedValues(t.loans, less) {
+			if match(&l) {
+				loans = append(loans, copyLoan(l))
+			}
+		}
+		loans = page(loans, limit, 0)
+		return nil
+	})
+	return loans, err
+}
+
+func (r *LoanRepository) CountPendingByUser(userID uuid.UUID) (int, error) {
+	loans, err := r.list(func(l *loan.Loan) bool {
+		return l.UserID == userID && l.Status == loan.StatusPending
+	}, func(a, b *loan.Loan) bool { return idLess(a.ID, b.ID) }, -1)
+	return len(loans), err
+}
+
+func (r *LoanRepository) Cancel(id uuid.UUID) error {
+	return r.closePending(id, func(l *loan.Loan, now time.Time) {
+		l.Status = loan.StatusCancelled
+		l.ClosedAt = &now
+	})
+}
+
+func (r *LoanRepository) Reject(id, officerID uuid.UUID, reason string) error {
+	return r.closePending(id, func(l *loan.Loan, now time.Time) {
+		l.Status = loan.StatusRejected
+		l.DecisionReason = reason
+		l.DecidedBy = &officerID
+		l.DecidedAt, l.ClosedAt = &now, &now
+	})
+}
+
+// closePending applies set to a pending application
+func (r *LoanRepository) closePending(id uuid.UUID, set func(l *loan.Loan, now time.Time)) error {
+	return r.db.update(func(t *tables) error {
+		l, ok := t.loans[id]
+		if !ok || l.Status != loan.StatusPending {
+			return fmt.Errorf("loan application is no longer pending")
+		}
+		now := r.db.timestamp()
+		set(&l, now)
+		l.UpdatedAt = now
+		t.loans[id] = l
+		return nil
+	})
+}
+
+func (r *LoanRepository) Disburse(id, officerID uuid.UUID, schedule []*loan.Installment, txn *transaction.Transaction) error {
+	return r.db.update(func(t *tables) error {
+		now := r.db.timestamp()
+
+		l, ok := t.loans[id]
+		if !ok {
+			return fmt.Errorf("loan not found")
+		}
+		if l.Status != loan.StatusPending {
+			return fmt.Errorf("loan application is no longer pending")
+		}
+
+		if txn.ToAccountID == nil {
+			return fmt.Errorf("disbursement account is not active")
+		}
+		acc, ok := t.accounts[*txn.ToAccountID]
+		if !ok || acc.Status != account.AccountStatusActive {
+			return fmt.Errorf("disbursement account is not active")
+		}
+		if err := t.moveBalance(acc.ID, txn.Amount, now); err != nil {
+			return fmt.Errorf("failed to credit account: %w", err)
+		}
+
+		if err := t.insertTransaction(&transaction.Transaction{
+			ID:              txn.ID,
+			IdempotencyKey:  txn.IdempotencyKey,
+			ToAccountID:     txn.ToAccountID,
+			Amount:          txn.Amount,
+			TransactionType: txn.TransactionType,
+			Status:          transaction.TransactionStatusCompleted,
+			Description:     txn.Description,
+			Metadata:        txn.Metadata,
+			CreatedAt:       now,
+			CompletedAt:     &now,
+		}); err != nil {
+			return err
+		}
+
+		for _, inst := range schedule {
+			if _, ok := t.installments[inst.ID]; ok {
+				return fmt.Errorf("failed to insert installment: duplicate id %s", inst.ID)
+			}
+			t.installments[inst.ID] = loan.Installment{
+				ID:        inst.ID,
+				LoanID:    id,
+				Number:    inst.Number,
+				DueDate:   dateOf(inst.DueDate),
+				Principal: inst.Principal,
+				Interest:  inst.Interest,
+				Amount:    inst.Amount,
+				Status:    inst.Status,
+			}
+		}
+
+		l.Status = loan.StatusActive
+		l.OutstandingPrincipal = l.Principal
+		l.DecidedBy = &officerID
+		l.DecidedAt, l.DisbursedAt = &now, &now
+		l.DisbursementTransactionID = ptr(txn.ID)
+		l.UpdatedAt = now
+		t.loans[id] = l
+		return nil
+	})
+}
+
+func (r *LoanRepository) GetSchedule(loanID uuid.UUID) ([]*loan.Installment, error) {
+	return r.listInstallments(func(t *tables, i *loan.Installment) bool { return i.LoanID == loanID },
+		func(a, b *loan.Installment) bool { return a.Number < b.Number }, -1)
+}
+
+func (r *LoanRepository) ListDueInstallments(today time.Time, limit int) ([]*loan.Installment, error) {
+	day := dateOf(today)
+	return r.listInstallments(func(t *tables, i *loan.Installment) bool {
+		return i.Status == loan.InstallmentStatusPending && !i.DueDate.After(day) &&
+			(i.LastAttemptDate == nil || i.LastAttemptDate.Before(day)) &&
+			t.loans[i.LoanID].Status == loan.StatusActive
+	}, func(a, b *loan.Installment) bool {
+		if !a.DueDate.Equal(b.DueDate) {
+			return a.DueDate.Before(b.DueDate)
+		}
+		if a.Number != b.Number {
+			return a.Number < b.Number
+		}
+		return idLess(a.ID, b.ID)
+	}, limit)
+}
+
+func (r *LoanRepository) listInstallments(match func(t *tables, i *loan.Installment) bool, less func(a, b *loan.Installment) bool, limit int) ([]*loan.Installment, error) {
+	installments := []*loan.Installment{}
+	err := r.db.view(func(t *tables) error {
+		for _, i := range sortedValues(t.installments, less) {
+			if match(t, &i) {
+				installments = append(installments, copyInstallment(i))
+			}
+		}
+		installments = page(installments, limit, 0)
+		return nil
+	})
+	return installments, err
+}
+
+func (r *LoanRepository) CollectInstallment(installmentID uuid.UUID, txn *transaction.Transaction, today time.Time) (bool, error) {
+	var collected bool
+	err := r.db.update(func(t *tables) error {
+		now := r.db.timestamp()
+		day := dateOf(today)
+
+		inst, ok := t.installments[installmentID]
+		if !ok {
+			return fmt.Errorf("failed to lock installment: installment %s not found", installmentID)
+		}
+		if inst.Status != loan.InstallmentStatusPending {
+			return fmt.Errorf("installment is already %s", inst.Status)
+		}
+
+		// A frozen account or a short balance is retried on the next day
+		inst.DebitAttempts++
+		inst.LastAttemptDate = &day
+		var held *heldBalance
+		if txn.FromAccountID != nil {
+			held, _ = t.lockBalance(*txn.FromAccountID, "", now)
+		}
+		if held == nil || held.available < inst.Amount {
+			t.installments[installmentID] = inst
+			return nil
+		}
+
+		if err := t.add(held, -inst.Amount, now); err != nil {
+			return fmt.Errorf("failed to debit account: %w", err)
+		}
+		if err := t.insertTransaction(&transaction.Transaction{
+			ID:              txn.ID,
+			IdempotencyKey:  txn.IdempotencyKey,
+			FromAccountID:   txn.FromAccountID,
+			Amount:          inst.Amount,
+			TransactionType: txn.TransactionType,
+			Status:          transaction.TransactionStatusCompleted,
+			Description:     txn.Description,
+			Metadata:        txn.Metadata,
+			CreatedAt:       now,
+			CompletedAt:     &now,
+		}); err != nil {
+			return err
+		}
+
+		inst.Status = loan.InstallmentStatusPaid
+		inst.PaidAt = &now
+		inst.TransactionID = ptr(txn.ID)
+		t.installments[installmentID] = inst
+
+		// The loan is paid off with its last pending installment
+		l := t.loans[inst.LoanID]
+		l.OutstandingPrincipal = max(l.OutstandingPrincipal-inst.Principal, 0)
+		paidOff := true
+		for _, other := range t.installments {
+			if other.LoanID == inst.LoanID && other.Status == loan.InstallmentStatusPending {
+				paidOff = false
+			}
+		}
+		if paidOff {
+			l.Status = loan.StatusPaidOff
+			l.ClosedAt = &now
+		}
+		l.UpdatedAt = now
+		t.loans[inst.LoanID] = l
+
+		collected = true
+		return nil
+	})
+	return collected, err
+}
@@ -0,0 +1,487 @@
+package fakes
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/merchant"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+)
+
+var _ repository.MerchantRepository = (*MerchantRepository)(nil)
+
+// paymentLinkRow is a payment_links row with the notification columns the
+// domain model leaves out
+type paymentLinkRow struct {
+	merchant.PaymentLink
+	notifiedAt     *time.Time
+	nextNotifyAt   *time.Time
+	notifyAttempts int
+}
+
+type MerchantRepository struct {
+	db    *DB
+	hooks []repository.DebitHook
+}
+
+// NewMerchantRepository returns the repository. The hooks run after every
+// payment link payment.
+func NewMerchantRepository(db *DB, hooks ...repository.DebitHook) *MerchantRepository {
+	return &MerchantRepository{db: db, hooks: hooks}
+}
+
+func (r *MerchantRepository) Create(m *merchant.Merchant) error {
+	return r.db.update(func(t *tables) error {
+		if _, ok := t.merchants[m.ID]; ok {
+			return fmt.Errorf("failed to create merchant: duplicate id %s", m.ID)
+		}
+		for _, other := range t.merchants {
+			if other.APIKeyHash == m.APIKeyHash {
+				return fmt.Errorf("failed to create merchant: API key already exists")
+			}
+		}
+		now := r.db.timestamp()
+		m.CreatedAt, m.UpdatedAt = now, now
+		t.merchants[m.ID] = *m
+		return nil
+	})
+}
+
+// getOne returns the merchant matching the condition
+func (r *MerchantRepository) getOne(match func(m *merchant.Merchant) bool) (*merchant.Merchant, error) {
+	var found *merchant.Merchant
+	err := r.db.view(func(t *tables) error {
+		for _, m := range t.merchants {
+			if match(&m) {
+				found = &m
+				return nil
+			}
+		}
+		return fmt.Errorf("merchant not found")
+	})
+	return found, err
+}
+
+func (r *MerchantRepository) GetByID(id uuid.UUID) (*merchant.Merchant, error) {
+	return r.getOne(func(m *merchant.Merchant) bool { return m.ID == id })
+}
+
+func (r *MerchantRepository) GetByAPIKeyHash(hash string) (*merchant.Merchant, error) {
+	return r.getOne(func(m *merchant.Merchant) bool { return m.APIKeyHash == hash })
+}
+
+func (r *MerchantRepository) ListByOwner(ownerID uuid.UUID) ([]*merchant.Merchant, error) {
+	merchants := []*merchant.Merchant{}
+	err := r.db.view(func(t *tables) error {
+		for _, m := range sortedValues(t.merchants, func(a, b *merchant.Merchant) bool {
+			return oldestFirst(a.CreatedAt, b.CreatedAt, a.ID, b.ID)
+		}) {
+			if m.OwnerID == ownerID {
+				merchants = append(merchants, &m)
+			}
+		}
+		return nil
+	})
+	return merchants, err
+}
+
+func (r *MerchantRepository) UpdateAPIKey(id uuid.UUID, hint, hash string) error {
+	return r.updateMerchant(id, func(m *merchant.Merchant) { m.APIKeyHint, m.APIKeyHash = hint, hash })
+}
+
+func (r *MerchantRepository) UpdateWebhook(id uuid.UUID, url string) error {
+	return r.updateMerchant(id, func(m *merchant.Merchant) { m.WebhookURL = url })
+}
+
+func (r *MerchantRepository) UpdateWebhookSecret(id uuid.UUID, secret string) error {
+	return r.updateMerchant(id, func(m *merchant.Merchant) { m.WebhookSecret = secret })
+}
+
+// updateMerchant applies set to one merchant, failing when it does not exist
+func (r *MerchantRepository) updateMerchant(id uuid.UUID, set func(m *merchant.Merchant)) error {
+	return r.db.update(func(t *tables) error {
+		m, ok := t.merchants[id]
+		if !ok {
+			return fmt.Errorf("merchant not found")
+		}
+		set(&m)
+		m.UpdatedAt = r.db.timestamp()
+		t.merchants[id] = m
+		return nil
+	})
+}
+
+func (r *MerchantRepository) CreatePaymentLink(link *merchant.PaymentLink) error {
+	return r.db.update(func(t *tables) error {
+		if _, ok := t.paymentLinks[link.ID]; ok {
+			return fmt.Errorf("failed to create payment link: duplicate id %s", link.ID)
+		}
+		if _, ok := t.merchants[link.MerchantID]; !ok {
+			return fmt.Errorf("failed to create payment link: merchant %s does not exist", link.MerchantID)
+		}
+		link.CreatedAt = r.db.timestamp()
+		t.paymentLinks[link.ID] = paymentLinkRow{PaymentLink: merchant.PaymentLink{
+			ID:          link.ID,
+			MerchantID:  link.MerchantID,
+			Reference:   link.Reference,
+			Amount:      link.Amount,
+			Description: link.Description,
+			Status:      link.Status,
+			ExpiresAt:   link.ExpiresAt,
+			CreatedAt:   link.CreatedAt,
+		}}
+		return nil
+	})
+}
+
+// paymentLink returns a copy of the link joined with its merchant's name
+func (t *tables) paymentLink(row paymentLinkRow) *merchant.PaymentLink {
+	l := row.PaymentLink
+	l.MerchantName = t.merchants[l.MerchantID].Name
+	l.PaidAt = copyPtr(l.PaidAt)
+	l.PayerAccountID = copyPtr(l.PayerAccountID)
+	l.TransactionID = copyPtr(l.TransactionID)
+	l.SettlementID = copyPtr(l.SettlementID)
+	return &l
+}
+
+func (r *MerchantRepository) GetPaymentLink(id uuid.UUID) (*merchant.PaymentLink, error) {
+	var found *merchant.PaymentLink
+	err := r.db.view(func(t *tables) error {
+		row, ok := t.paymentLinks[id]
+		if !ok {
+			return fmt.Errorf("payment link not found")
+		}
+		found = t.paymentLink(row)
+		return nil
+	})
+	return found, err
+}
+
+func (r *MerchantRepository) ListPaymentLinks(merchantID uuid.UUID, limit int) ([]*merchant.PaymentLink, error) {
+	links := []*merchant.PaymentLink{}
+	err := r.db.view(func(t *tables) error {
+		for _, row := range sortedValues(t.paymentLinks, func(a, b *paymentLinkRow) bool {
+			return newestFirst(a.CreatedAt, b.CreatedAt, a.ID, b.ID)
+		}) {
+			if row.MerchantID == merchantID {
+				links = append(links, t.paymentLink(row))
+			}
+		}
+		links = page(links, limit, 0)
+		return nil
+	})
+	return links, err
+}
+
+func (r *MerchantRepository) PayPaymentLink(linkID, payerAccountID uuid.UUID, txn *transaction.Transaction) error {
+	return r.db.update(func(t *tables) error {
+		now := r.db.timestamp()
+
+		row, ok := t.paymentLinks[linkID]
+		if !ok {
+			return fmt.Errorf("payment link not found")
+		}
+		if row.Status != merchant.PaymentLinkStatusOpen {
+			return fmt.Errorf("payment link is %s", row.Status)
+		}
+		if !row.ExpiresAt.After(now) {
+			return fmt.Errorf("payment link is %s", merchant.PaymentLinkStatusExpired)
+		}
+
+		held, err := t.lockBalance(payerAccountID, "", now)
+		if err != nil {
+			return fmt.Errorf("failed to lock account: %w", err)
+		}
+		if held.available < row.Amount {
+			return insufficientFunds(held.available, row.Amount)
+		}
+		if err := t.add(held, -row.Amount, now); err != nil {
+			return fmt.Errorf("failed to debit account: %w", err)
+		}
+
+		if err := t.insertTransaction(&transaction.Transaction{
+			ID:              txn.ID,
+			IdempotencyKey:  txn.IdempotencyKey,
+			FromAccountID:   &payerAccountID,
+			Amount:          row.Amount,
+			TransactionType: txn.TransactionType,
+			Status:          transaction.TransactionStatusCompleted,
+			Description:     txn.Description,
+			Metadata:        txn.Metadata,
+			CreatedAt:       now,
+			CompletedAt:     &now,
+		}); err != nil {
+			return err
+		}
+
+		txn.FromAccountID, txn.Amount = &payerAccountID, row.Amount
+		if err := runDebitHooks(t, r.hooks, txn, now); err != nil {
+			return err
+		}
+
+		row.Status = merchant.PaymentLinkStatusPaid
+		row.PaidAt = &now
+		row.PayerAccountID = &payerAccountID
+		row.TransactionID = ptr(txn.ID)
+		// Links of merchants without a webhook are never queued for notification
+		row.nextNotifyAt = nil
+		if t.merchants[row.MerchantID].WebhookURL != "" {
+			row.nextNotifyAt = &now
+		}
+		t.paymentLinks[linkID] = row
+		return nil
+	})
+}
+
+func (r *MerchantRepository) CancelPaymentLink(linkID, merchantID uuid.UUID) error {
+	return r.db.update(func(t *tables) error {
+		row, ok := t.paymentLinks[linkID]
+		if !ok || row.MerchantID != merchantID || row.Status != merchant.PaymentLinkStatusOpen {
+			return fmt.Errorf("payment link not found or not open")
+		}
+		row.Status = merchant.PaymentLinkStatusCancelled
+		t.paymentLinks[linkID] = row
+		return nil
+	})
+}
+
+// unsettled reports whether the link is a payment received before the cutoff
+// that has not been settled
+func unsettled(row *paymentLinkRow, cutoff time.Time) bool {
+	return row.Status == merchant.PaymentLinkStatusPaid && row.SettlementID == nil && row.PaidAt != nil && row.PaidAt.Before(cutoff)
+}
+
+func (r *MerchantRepository) ListUnsettledMerchants(cutoff time.Time) ([]uuid.UUID, error) {
+	ids := []uuid.UUID{}
+	err := r.db.view(func(t *tables) error {
+		seen := map[uuid.UUID]bool{}
+		for _, row := range sortedValues(t.paymentLinks, func(a, b *paymentLinkRow) bool { return idLess(a.ID, b.ID) }) {
+			if unsettled(&row, cutoff) && !seen[row.MerchantID] {
+				seen[row.MerchantID] = true
+				ids = append(ids, row.MerchantID)
+			}
+		}
+		return nil
+	})
+	return ids, err
+}
+
+func (r *MerchantRepository) Settle(merchantID uuid.UUID, cutoff, businessDate time.Time) (*merchant.Settlement, error) {
+	var settled *merchant.Settlement
+	err := r.db.update(func(t *tables) error {
+		now := r.db.timestamp()
+
+		m, ok := t.merchants[merchantID]
+		if !ok {
+			return fmt.Errorf("failed to lock merchant: merchant %s not found", merchantID)
+		}
+
+		linkIDs := []uuid.UUID{}
+		var gross float64
+		for id, row := range t.paymentLinks {
+			if row.MerchantID == merchantID && unsettled(&row, cutoff) {
+				linkIDs = append(linkIDs, id)
+				gross += row.Amount
+			}
+		}
+		if len(linkIDs) == 0 {
+			return nil
+		}
+
+		fee := merchant.SettlementFee(gross)
+		s := merchant.Settlement{
+			ID:            uuid.New(),
+			MerchantID:    merchantID,
+			AccountID:     m.SettlementAccountID,
+			BusinessDate:  dateOf(businessDate),
+			PaymentCount:  len(linkIDs),
+			GrossAmount:   gross,
+			FeeAmount:     fee,
+			NetAmount:     gross - fee,
+			TransactionID: uuid.New(),
+			CreatedAt:     now,
+		}
+
+		if err := t.moveBalance(s.AccountID, s.NetAmount, now); err != nil {
+			return fmt.Errorf("failed to credit settlement account: %w", err)
+		}
+
+		day := businessDate.Format("2006-01-02")
+		if err := t.insertTransaction(&transaction.Transaction{
+			ID:              s.TransactionID,
+			IdempotencyKey:  fmt.Sprintf("merchant-settlement:%s:%s", merchantID, day),
+			ToAccountID:     &s.AccountID,
+			Amount:          s.NetAmount,
+			TransactionType: transaction.TransactionTypeMerchantSettlement,
+			Status:          transaction.TransactionStatusCompleted,
+			Description:     fmt.Sprintf("%s settlement %s", m.Name, day),
+			Metadata: map[string]interface{}{
+				"merchant_id":   merchantID.String(),
+				"settlement_id": s.ID.String(),
+				"business_date": day,
+				"payment_count": s.PaymentCount,
+				"gross_amount":  s.GrossAmount,
+				"fee_amount":    s.FeeAmount,
+			},
+			CreatedAt:   now,
+			CompletedAt: &now,
+		}); err != nil {
+			return err
+		}
+
+		t.settlements[s.ID] = s
+		for _, id := range linkIDs {
+			row := t.paymentLinks[id]
+			row.SettlementID = ptr(s.ID)
+			t.paymentLinks[id] = row
+		}
+
+		s.BusinessDate = businessDate
+		settled = &s
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return settled, nil
+}
+
+func (r *MerchantRepository) ListSettlements(merchantID uuid.UUID, limit int) ([]*merchant.Settlement, error) {
+	settlements := []*merchant.Settlement{}
+	err := r.db.view(func(t *tables) error {
+		for _, s := range sortedValues(t.settlements, func(a, b *merchant.Settlement) bool {
+			return newestFirst(a.BusinessDate, b.BusinessDate, a.ID, b.ID)
+		}) {
+			if s.MerchantID == merchantID {
+				settlements = append(settlements, &s)
+			}
+		}
+		settlements = page(settlements, limit, 0)
+		return nil
+	})
+	return settlements, err
+}
+
+func (r *MerchantRepository) ListPendingNotifications(now time.Time, limit int) ([]*merchant.PendingNotification, error) {
+	pending := []*merchant.PendingNotification{}
+	err := r.db.view(func(t *tables) error {
+		for _, row := range sortedValues(t.paymentLinks, func(a, b *paymentLinkRow) bool {
+			if a.nextNotifyAt == nil || b.nextNotifyAt == nil {
+				return b.nextNotifyAt == nil && a.nextNotifyAt != nil
+			}
+			return oldestFirst(*a.nextNotifyAt, *b.nextNotifyAt, a.ID, b.ID)
+		}) {
+			m := t.merchants[row.MerchantID]
+			if row.notifiedAt != nil || row.nextNotifyAt == nil || row.nextNotifyAt.After(now) || m.WebhookURL == "" {
+				continue
+			}
+			pending = append(pending, &merchant.PendingNotification{
+				Link:          t.paymentLink(row),
+				WebhookURL:    m.WebhookURL,
+				WebhookSecret: m.WebhookSecret,
+				Attempts:      row.notifyAttempts,
+			})
+		}
+		pending = page(pending, limit, 0)
+		return nil
+	})
+	return pending, err
+}
+
+func (r *MerchantRepository) MarkNotified(linkID uuid.UUID) error {
+	return r.updateLink(linkID, func(row *paymentLinkRow) {
+		row.notifiedAt = ptr(r.db.timestamp())
+		row.notifyAttempts++
+		row.nextNotifyAt = nil
+	})
+}
+
+func (r *MerchantRepository) RecordNotifyFailure(linkID uuid.UUID, nextAttemptAt *time.Time) error {
+	return r.updateLink(linkID, func(row *paymentLinkRow) {
+		row.notifyAttempts++
+		row.nextNotifyAt = copyPtr(nextAttemptAt)
+	})
+}
+
+// updateLink applies set to the link; like an UPDATE matching no row, a
+// missing link is not an error
+func (r *MerchantRepository) updateLink(linkID uuid.UUID, set func(row *paymentLinkRow)) error {
+	return r.db.update(func(t *tables) error {
+		row, ok := t.paymentLinks[linkID]
+		if !ok {
+			return nil
+		}
+		set(&row)
+		t.paymentLinks[linkID] = row
+		return nil
+	})
+}
+
+func (r *MerchantRepository) ScheduleRedelivery(merchantID uuid.UUID, since time.Time) (int, error) {
+	var scheduled int
+	err := r.db.update(func(t *tables) error {
+		now := r.db.timestamp()
+		for id, row := range t.paymentLinks {
+			if row.MerchantID != merchantID || row.Status != merchant.PaymentLinkStatusPaid ||
+				row.TransactionID == nil || row.PaidAt == nil || row.PaidAt.Before(since) {
+				continue
+			}
+			row.notifiedAt, row.notifyAttempts, row.nextNotifyAt = nil, 0, &now
+			t.paymentLinks[id] = row
+			scheduled++
+		}
+		return nil
+	})
+	return scheduled, err
+}
+
+func (r *MerchantRepository) RequeueFailedNotifications(since time.Time) (int, error) {
+	var requeued int
+	err := r.db.update(func(t *tables) error {
+		now := r.db.timestamp()
+		for id, row := range t.paymentLinks {
+			if row.Status != merchant.PaymentLinkStatusPaid || row.TransactionID == nil || row.PaidAt == nil ||
+				row.PaidAt.Before(since) || row.notifiedAt != nil || row.nextNotifyAt != nil || row.notifyAttempts == 0 {
+				continue
+			}
+			row.notifyAttempts, row.nextNotifyAt = 0, &now
+			t.paymentLinks[id] = row
+			requeued++
+		}
+		return nil
+	})
+	return requeued, err
+}
+
+func (r *MerchantRepository) RecordWebhookDelivery(d *merchant.WebhookDelivery) error {
+	return r.db.update(func(t *tables) error {
+		if _, ok := t.webhookDeliveries[d.ID]; ok {
+			return fmt.Errorf("failed to record webhook delivery: duplicate id %s", d.ID)
+		}
+		d.CreatedAt = r.db.timestamp()
+		stored := *d
+		stored.PaymentLinkID = copyPtr(d.PaymentLinkID)
+		t.webhookDeliveries[d.ID] = stored
+		return nil
+	})
+}
+
+func (r *MerchantRepository) ListWebhookDeliveries(merchantID uuid.UUID, limit int) ([]*merchant.WebhookDelivery, error) {
+	deliveries := []*merchant.WebhookDelivery{}
+	err := r.db.view(func(t *tables) error {
+		for _, d := range sortedValues(t.webhookDeliveries, func(a, b *merchant.WebhookDelivery) bool {
+			return newestFirst(a.CreatedAt, b.CreatedAt, a.ID, b.ID)
+		}) {
+			if d.MerchantID == merchantID {
+				d.PaymentLinkID = copyPtr(d.PaymentLinkID)
+				deliveries = append(deliveries, &d)
+			}
+		}
+		deliveries = page(deliveries, limit, 0)
+		return nil
+	})
+	return deliveries, err
+}
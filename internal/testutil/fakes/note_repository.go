@@ -0,0 +1,48 @@
+package fakes
+
+import (
+	"fmt"
+
+	"github.com/darisadam/madabank-server/internal/domain/note"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+)
+
+var _ repository.NoteRepository = (*NoteRepository)(nil)
+
+type NoteRepository struct {
+	db *DB
+}
+
+func NewNoteRepository(db *DB) *NoteRepository {
+	return &NoteRepository{db: db}
+}
+
+func (r *NoteRepository) Create(n *note.Note) error {
+	return r.db.update(func(t *tables) error {
+		if _, ok := t.notes[n.ID]; ok {
+			return fmt.Errorf("failed to create note: duplicate id %s", n.ID)
+		}
+		n.CreatedAt = r.db.timestamp()
+		stored := *n
+		stored.AuthorEmail = ""
+		t.notes[n.ID] = stored
+		return nil
+	})
+}
+
+func (r *NoteRepository) ListBySubject(subjectType note.SubjectType, subjectID uuid.UUID) ([]*note.Note, error) {
+	notes := []*note.Note{}
+	err := r.db.view(func(t *tables) error {
+		for _, n := range sortedValues(t.notes, func(a, b *note.Note) bool {
+			return oldestFirst(a.CreatedAt, b.CreatedAt, a.ID, b.ID)
+		}) {
+			if n.SubjectType == subjectType && n.SubjectID == subjectID {
+				n.AuthorEmail = t.users[n.AuthorID].Email
+				notes = append(notes, &n)
+			}
+		}
+		return nil
+	})
+	return notes, err
+}
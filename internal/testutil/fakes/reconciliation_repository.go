@@ -0,0 +1,221 @@
+package fakes
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/reconciliation"
+	"github.com/darisadam/madabank-server/internal/domain/topup"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+)
+
+var _ repository.ReconciliationRepository = (*ReconciliationRepository)(nil)
+
+type ReconciliationRepository struct {
+	db *DB
+}
+
+func NewReconciliationRepository(db *DB) *ReconciliationRepository {
+	return &ReconciliationRepository{db: db}
+}
+
+func copyException(e reconciliation.Exception) *reconciliation.Exception {
+	e.AccountID = copyPtr(e.AccountID)
+	e.ExpectedAmount = copyPtr(e.ExpectedAmount)
+	e.ActualAmount = copyPtr(e.ActualAmount)
+	e.AssignedTo = copyPtr(e.AssignedTo)
+	e.ResolvedBy = copyPtr(e.ResolvedBy)
+	e.ResolvedAt = copyPtr(e.ResolvedAt)
+	return &e
+}
+
+func (r *ReconciliationRepository) SaveRun(run *reconciliation.Run, exceptions []*reconciliation.Exception) error {
+	return r.db.update(func(t *tables) error {
+		if _, ok := t.reconciliationRuns[run.ID]; ok {
+			return fmt.Errorf("failed to create reconciliation run: duplicate id %s", run.ID)
+		}
+		now := r.db.timestamp()
+		run.CreatedAt = now
+		t.reconciliationRuns[run.ID] = reconciliation.Run{
+			ID:           run.ID,
+			Kind:         run.Kind,
+			Source:       run.Source,
+			BusinessDate: dateOf(run.BusinessDate),
+			FileName:     run.FileName,
+			ItemsChecked: run.ItemsChecked,
+			BreaksFound:  run.BreaksFound,
+			StartedBy:    copyPtr(run.StartedBy),
+			CreatedAt:    now,
+		}
+
+		for _, e := range exceptions {
+			stored, ok := t.openException(e)
+			if ok {
+				stored.DetectedCount++
+				stored.LastDetectedAt = now
+			} else {
+				stored = reconciliation.Exception{
+					ID:             e.ID,
+					Type:           e.Type,
+					Source:         e.Source,
+					Reference:      e.Reference,
+					AccountID:      copyPtr(e.AccountID),
+					Status:         reconciliation.ExceptionStatusOpen,
+					DetectedCount:  1,
+					LastDetectedAt: now,
+					CreatedAt:      now,
+				}
+			}
+			stored.RunID = run.ID
+			stored.ExpectedAmount = copyPtr(e.ExpectedAmount)
+			stored.ActualAmount = copyPtr(e.ActualAmount)
+			stored.Details = e.Details
+			stored.UpdatedAt = now
+			t.reconciliationExceptions[stored.ID] = stored
+			*e = *copyException(stored)
+		}
+		return nil
+	})
+}
+
+// openException finds the open or investigating exception for the same break
+func (t *tables) openException(e *reconciliation.Exception) (reconciliation.Exception, bool) {
+	for _, other := range t.reconciliationExceptions {
+		if other.Type == e.Type && other.Source == e.Source && other.Reference == e.Reference && !other.Status.IsClosed() {
+			return other, true
+		}
+	}
+	return reconciliation.Exception{}, false
+}
+
+func (r *ReconciliationRepository) HasScheduledRun(kind reconciliation.RunKind, businessDate time.Time) (bool, error) {
+	var exists bool
+	err := r.db.view(func(t *tables) error {
+		for _, run := range t.reconciliationRuns {
+			if run.Kind == kind && run.BusinessDate.Equal(dateOf(businessDate)) && run.StartedBy == nil {
+				exists = true
+			}
+		}
+		return nil
+	})
+	return exists, err
+}
+
+func (r *ReconciliationRepository) ListRuns(limit int) ([]*reconciliation.Run, error) {
+	runs := []*reconciliation.Run{}
+	err := r.db.view(func(t *tables) error {
+		for _, run := range sortedValues(t.reconciliationRuns, func(a, b *reconciliation.Run) bool {
+			return newestFirst(a.CreatedAt, b.CreatedAt, a.ID, b.ID)
+		}) {
+			run.StartedBy = copyPtr(run.StartedBy)
+			runs = append(runs, &run)
+		}
+		runs = page(runs, limit, 0)
+		return nil
+	})
+	return runs, err
+}
+
+func (r *ReconciliationRepository) ListExceptions(status reconciliation.ExceptionStatus, limit int) ([]*reconciliation.Exception, error) {
+	exceptions := []*reconciliation.Exception{}
+	err := r.db.view(func(t *tables) error {
+		for _, e := range sortedValues(t.reconciliationExceptions, func(a, b *reconciliation.Exception) bool {
+			return oldestFirst(a.CreatedAt, b.CreatedAt, a.ID, b.ID)
+		}) {
+			if e.Status == status {
+				exceptions = append(exceptions, copyException(e))
+			}
+		}
+		exceptions = page(exceptions, limit, 0)
+		return nil
+	})
+	return exceptions, err
+}
+
+func (r *ReconciliationRepository) GetException(id uuid.UUID) (*reconciliation.Exception, error) {
+	var found *reconciliation.Exception
+	err := r.db.view(func(t *tables) error {
+		e, ok := t.reconciliationExceptions[id]
+		if !ok {
+			return fmt.Errorf("reconciliation exception not found")
+		}
+		found = copyException(e)
+		return nil
+	})
+	return found, err
+}
+
+func (r *ReconciliationRepository) UpdateExceptionStatus(id uuid.UUID, from, to reconciliation.ExceptionStatus, userID uuid.UUID, note string) error {
+	return r.db.update(func(t *tables) error {
+		e, ok := t.reconciliationExceptions[id]
+		if !ok || e.Status != from {
+			return fmt.Errorf("reconciliation exception was updated by someone else")
+		}
+		now := r.db.timestamp()
+		e.Status = to
+		if to.IsClosed() {
+			e.ResolutionNote = note
+			e.ResolvedBy, e.ResolvedAt = &userID, &now
+		} else {
+			e.AssignedTo = &userID
+		}
+		e.UpdatedAt = now
+		t.reconciliationExceptions[id] = e
+		return nil
+	})
+}
+
+func (r *ReconciliationRepository) ListSettlementEntries(source reconciliation.Source, from, to time.Time) ([]*reconciliation.LedgerEntry, error) {
+	return r.listEntries(source, func(_ string, createdAt time.Time) bool {
+		return !createdAt.Before(from) && createdAt.Before(to)
+	})
+}
+
+func (r *ReconciliationRepository) GetSettlementEntries(source reconciliation.Source, references []string) ([]*reconciliation.LedgerEntry, error) {
+	return r.listEntries(source, func(reference string, _ time.Time) bool {
+		return slices.Contains(references, reference)
+	})
+}
+
+// listEntries returns our entries for the counterparty that match; the
+// reference is the ID we sent the counterparty
+func (r *ReconciliationRepository) listEntries(source reconciliation.Source, match func(reference string, createdAt time.Time) bool) ([]*reconciliation.LedgerEntry, error) {
+	entries := []*reconciliation.LedgerEntry{}
+	err := r.db.view(func(t *tables) error {
+		switch source {
+		case reconciliation.SourceBillerAggregator:
+			for _, txn := range sortedValues(t.transactions, func(a, b *transaction.Transaction) bool {
+				return oldestFirst(a.CreatedAt, b.CreatedAt, a.ID, b.ID)
+			}) {
+				if txn.TransactionType == transaction.TransactionTypeBillPayment && match(txn.ID.String(), txn.CreatedAt) {
+					entries = append(entries, &reconciliation.LedgerEntry{
+						Reference: txn.ID.String(),
+						Amount:    txn.Amount,
+						Status:    string(txn.Status),
+						Settled:   txn.Status == transaction.TransactionStatusCompleted,
+					})
+				}
+			}
+		case reconciliation.SourceTopupAggregator:
+			for _, tu := range sortedValues(t.topups, func(a, b *topup.Topup) bool {
+				return oldestFirst(a.CreatedAt, b.CreatedAt, a.ID, b.ID)
+			}) {
+				if match(tu.ID.String(), tu.CreatedAt) {
+					entries = append(entries, &reconciliation.LedgerEntry{
+						Reference: tu.ID.String(),
+						Amount:    tu.Amount,
+						Status:    string(tu.Status),
+						Settled:   tu.Status == topup.StatusSuccess,
+					})
+				}
+			}
+		default:
+			return fmt.Errorf("unsupported settlement source: %s", source)
+		}
+		return nil
+	})
+	return entries, err
+}
@@ -0,0 +1,167 @@
+package fakes
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/regulatory"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+)
+
+var _ repository.RegulatoryRepository = (*RegulatoryRepository)(nil)
+
+type RegulatoryRepository struct {
+	db *DB
+}
+
+func NewRegulatoryRepository(db *DB) *RegulatoryRepository {
+	return &RegulatoryRepository{db: db}
+}
+
+func (r *RegulatoryRepository) CreateFlag(flag *regulatory.Flag) error {
+	return r.db.update(func(t *tables) error {
+		if _, ok := t.transactions[flag.TransactionID]; !ok {
+			return fmt.Errorf("failed to flag transaction: transaction %s does not exist", flag.TransactionID)
+		}
+		if _, ok := t.flags[flag.ID]; ok {
+			return fmt.Errorf("failed to flag transaction: duplicate id %s", flag.ID)
+		}
+		flag.CreatedAt = r.db.timestamp()
+		stored := *flag
+		stored.FlaggedBy = copyPtr(flag.FlaggedBy)
+		t.flags[flag.ID] = stored
+		return nil
+	})
+}
+
+// item is the transaction with the account number and holder name on each side
+func (t *tables) item(txn *transaction.Transaction) *regulatory.Item {
+	return &regulatory.Item{
+		TransactionID:   txn.ID,
+		TransactionType: txn.TransactionType,
+		Amount:          txn.Amount,
+		Status:          txn.Status,
+		Description:     txn.Description,
+		PostedAt:        postedAt(txn),
+		From:            t.party(txn.FromAccountID),
+		To:              t.party(txn.ToAccountID),
+	}
+}
+
+func (t *tables) party(accountID *uuid.UUID) *regulatory.Party {
+	if accountID == nil {
+		return nil
+	}
+	acc, ok := t.accounts[*accountID]
+	if !ok {
+		return nil
+	}
+	party := &regulatory.Party{AccountID: acc.ID, AccountNumber: acc.AccountNumber}
+	if u, ok := t.users[acc.UserID]; ok {
+		party.HolderName = u.FirstName + " " + u.LastName
+	}
+	return party
+}
+
+func (r *RegulatoryRepository) ListLargeTransactions(from, to time.Time, minAmount float64) ([]*regulatory.Item, error) {
+	items := []*regulatory.Item{}
+	err := r.db.view(func(t *tables) error {
+		for _, txn := range sortedValues(t.transactions, postingOrder) {
+			posted := postedAt(&txn)
+			if txn.Status == transaction.TransactionStatusCompleted && !posted.Before(from) && posted.Before(to) && txn.Amount >= minAmount {
+				items = append(items, t.item(&txn))
+			}
+		}
+		return nil
+	})
+	return items, err
+}
+
+func (r *RegulatoryRepository) ListFlaggedTransactions(from, to time.Time) ([]*regulatory.Item, error) {
+	items := []*regulatory.Item{}
+	err := r.db.view(func(t *tables) error {
+		// Flags in creation order, so each item is first seen at its earliest
+		// flag and its reasons are in order
+		flagged := map[uuid.UUID]*regulatory.Item{}
+		for _, flag := range sortedValues(t.flags, func(a, b *regulatory.Flag) bool {
+			return oldestFirst(a.CreatedAt, b.CreatedAt, a.TransactionID, b.TransactionID)
+		}) {
+			if flag.CreatedAt.Before(from) || !flag.CreatedAt.Before(to) {
+				continue
+			}
+			item, ok := flagged[flag.TransactionID]
+			if !ok {
+				txn := t.transactions[flag.TransactionID]
+				item = t.item(&txn)
+				flagged[flag.TransactionID] = item
+				items = append(items, item)
+			}
+			item.Reasons = append(item.Reasons, string(flag.Source)+": "+flag.Reason)
+		}
+		return nil
+	})
+	return items, err
+}
+
+func (r *RegulatoryRepository) SaveReport(report *regulatory.Report) error {
+	return r.db.update(func(t *tables) error {
+		if _, ok := t.reports[report.ID]; ok {
+			return fmt.Errorf("failed to save regulatory report: duplicate id %s", report.ID)
+		}
+		report.CreatedAt = r.db.timestamp()
+		stored := *report
+		stored.PeriodStart, stored.PeriodEnd = dateOf(report.PeriodStart), dateOf(report.PeriodEnd)
+		stored.GeneratedBy = copyPtr(report.GeneratedBy)
+		stored.Content = bytes.Clone(report.Content)
+		t.reports[report.ID] = stored
+		return nil
+	})
+}
+
+func (r *RegulatoryRepository) HasScheduledReport(reportType regulatory.ReportType, day time.Time) (bool, error) {
+	var exists bool
+	err := r.db.view(func(t *tables) error {
+		day := dateOf(day)
+		for _, report := range t.reports {
+			if report.Type == reportType && report.PeriodStart.Equal(day) && report.PeriodEnd.Equal(day) && report.GeneratedBy == nil {
+				exists = true
+			}
+		}
+		return nil
+	})
+	return exists, err
+}
+
+func (r *RegulatoryRepository) ListReports(limit int) ([]*regulatory.Report, error) {
+	reports := []*regulatory.Report{}
+	err := r.db.view(func(t *tables) error {
+		for _, report := range sortedValues(t.reports, func(a, b *regulatory.Report) bool {
+			return newestFirst(a.CreatedAt, b.CreatedAt, a.ID, b.ID)
+		}) {
+			report.GeneratedBy = copyPtr(report.GeneratedBy)
+			report.Content = nil
+			reports = append(reports, &report)
+		}
+		reports = page(reports, limit, 0)
+		return nil
+	})
+	return reports, err
+}
+
+func (r *RegulatoryRepository) GetReport(id uuid.UUID) (*regulatory.Report, error) {
+	var found *regulatory.Report
+	err := r.db.view(func(t *tables) error {
+		report, ok := t.reports[id]
+		if !ok {
+			return fmt.Errorf("regulatory report not found")
+		}
+		report.GeneratedBy = copyPtr(report.GeneratedBy)
+		report.Content = bytes.Clone(report.Content)
+		found = &report
+		return nil
+	})
+	return found, err
+}
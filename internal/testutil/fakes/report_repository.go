@@ -0,0 +1,120 @@
+package fakes
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/report"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/repository"
+)
+
+var _ repository.ReportRepository = (*ReportRepository)(nil)
+
+type ReportRepository struct {
+	db *DB
+}
+
+func NewReportRepository(db *DB) *ReportRepository {
+	return &ReportRepository{db: db}
+}
+
+// metadataText is metadata->>key: the value as text, or false when it is
+// missing or null
+func metadataText(metadata map[string]interface{}, key string) (string, bool) {
+	v, ok := metadata[key]
+	if !ok || v == nil {
+		return "", false
+	}
+	if s, ok := v.(string); ok {
+		return s, true
+	}
+	return fmt.Sprint(v), true
+}
+
+// metadataAmount is COALESCE((metadata->>key)::numeric, 0)
+func metadataAmount(metadata map[string]interface{}, key string) float64 {
+	text, ok := metadataText(metadata, key)
+	if !ok {
+		return 0
+	}
+	amount, _ := strconv.ParseFloat(text, 64)
+	return amount
+}
+
+// DailyTotals takes fee income from the same metadata the general ledger
+// export posts to the income accounts
+func (r *ReportRepository) DailyTotals(from, to time.Time, defaultCurrency string) ([]*report.TypeTotal, error) {
+	totals := []*report.TypeTotal{}
+	err := r.db.view(func(t *tables) error {
+		type group struct {
+			txnType  transaction.TransactionType
+			currency string
+		}
+		groups := map[group]*report.TypeTotal{}
+		completed := completedIn(from, to)
+		for _, txn := range t.transactions {
+			if !completed(&txn) {
+				continue
+			}
+			currency, ok := metadataText(txn.Metadata, "currency")
+			if !ok {
+				currency = defaultCurrency
+			}
+			key := group{txnType: txn.TransactionType, currency: currency}
+			total, ok := groups[key]
+			if !ok {
+				total = &report.TypeTotal{TransactionType: txn.TransactionType, Currency: currency}
+				groups[key] = total
+				totals = append(totals, total)
+			}
+			total.Count++
+			total.Amount += txn.Amount
+			switch txn.TransactionType {
+			case transaction.TransactionTypeFee:
+				total.Fees += txn.Amount
+			case transaction.TransactionTypeTopup:
+				total.Fees += metadataAmount(txn.Metadata, "admin_fee")
+			case transaction.TransactionTypeMerchantSettlement:
+				total.Fees += metadataAmount(txn.Metadata, "fee_amount")
+			case transaction.TransactionTypeLoanDisbursement:
+				total.Fees += metadataAmount(txn.Metadata, "provision_fee")
+			}
+		}
+		slices.SortFunc(totals, func(a, b *report.TypeTotal) int {
+			if c := strings.Compare(string(a.TransactionType), string(b.TransactionType)); c != 0 {
+				return c
+			}
+			return strings.Compare(a.Currency, b.Currency)
+		})
+		return nil
+	})
+	return totals, err
+}
+
+func (r *ReportRepository) FailedCounts(from, to time.Time) ([]*report.FailedCount, error) {
+	counts := []*report.FailedCount{}
+	err := r.db.view(func(t *tables) error {
+		byType := map[transaction.TransactionType]*report.FailedCount{}
+		for _, txn := range t.transactions {
+			if txn.Status != transaction.TransactionStatusFailed || txn.CreatedAt.Before(from) || !txn.CreatedAt.Before(to) {
+				continue
+			}
+			count, ok := byType[txn.TransactionType]
+			if !ok {
+				count = &report.FailedCount{TransactionType: txn.TransactionType}
+				byType[txn.TransactionType] = count
+				counts = append(counts, count)
+			}
+			count.Count++
+		}
+		slices.SortFunc(counts, func(a, b *report.FailedCount) int {
+			return strings.Compare(string(a.TransactionType), string(b.TransactionType))
+		})
+		return nil
+	})
+	return counts, err
+}
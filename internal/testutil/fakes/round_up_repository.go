@@ -0,0 +1,140 @@
+package fakes
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/roundup"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+)
+
+var _ repository.RoundUpRepository = (*RoundUpRepository)(nil)
+
+type RoundUpRepository struct {
+	db *DB
+}
+
+func NewRoundUpRepository(db *DB) *RoundUpRepository {
+	return &RoundUpRepository{db: db}
+}
+
+func (r *RoundUpRepository) Save(rule *roundup.Rule) error {
+	return r.db.update(func(t *tables) error {
+		now := r.db.timestamp()
+		stored, ok := t.roundUps[rule.AccountID]
+		if ok {
+			stored.SavingsAccountID = rule.SavingsAccountID
+			stored.UpdatedAt = now
+		} else {
+			stored = roundup.Rule{
+				ID:               rule.ID,
+				UserID:           rule.UserID,
+				AccountID:        rule.AccountID,
+				SavingsAccountID: rule.SavingsAccountID,
+				CreatedAt:        now,
+				UpdatedAt:        now,
+			}
+		}
+		t.roundUps[rule.AccountID] = stored
+		rule.ID, rule.CreatedAt, rule.UpdatedAt = stored.ID, stored.CreatedAt, stored.UpdatedAt
+		return nil
+	})
+}
+
+func (r *RoundUpRepository) GetByAccountID(accountID uuid.UUID) (*roundup.Rule, error) {
+	var found *roundup.Rule
+	err := r.db.view(func(t *tables) error {
+		rule, ok := t.roundUps[accountID]
+		if !ok {
+			return fmt.Errorf("round-up rule not found")
+		}
+		for _, txn := range t.transactions {
+			if txn.FromAccountID != nil && *txn.FromAccountID == accountID && txn.TransactionType == transaction.TransactionTypeRoundUp {
+				rule.TotalSaved += txn.Amount
+			}
+		}
+		found = &rule
+		return nil
+	})
+	return found, err
+}
+
+func (r *RoundUpRepository) Delete(accountID uuid.UUID) error {
+	return r.db.update(func(t *tables) error {
+		if _, ok := t.roundUps[accountID]; !ok {
+			return fmt.Errorf("round-up rule not found")
+		}
+		delete(t.roundUps, accountID)
+		return nil
+	})
+}
+
+// AfterDebit is called by repositories outside this package, which hold no
+// lock on the DB
+func (r *RoundUpRepository) AfterDebit(_ *sql.Tx, txn *transaction.Transaction) error {
+	return r.db.update(func(t *tables) error {
+		return r.afterDebit(t, txn, r.db.timestamp())
+	})
+}
+
+// afterDebit moves the spare change of a debit to the account's round-up
+// savings account, skipping it as the SQL hook does
+func (r *RoundUpRepository) afterDebit(t *tables, txn *transaction.Transaction, now time.Time) error {
+	amount := roundup.Amount(txn.Amount)
+	if txn.FromAccountID == nil || amount == 0 {
+		return nil
+	}
+	fromAccountID := *txn.FromAccountID
+
+	rule, ok := t.roundUps[fromAccountID]
+	if !ok {
+		return nil
+	}
+	savingsAccountID := rule.SavingsAccountID
+	if txn.ToAccountID != nil && *txn.ToAccountID == savingsAccountID {
+		return nil
+	}
+
+	savings, ok := t.accounts[savingsAccountID]
+	if !ok {
+		return fmt.Errorf("failed to lock round-up savings account: %w", sql.ErrNoRows)
+	}
+	if savings.Status != account.AccountStatusActive {
+		return nil
+	}
+	held, err := t.lockBalance(fromAccountID, "", now)
+	if err != nil {
+		return fmt.Errorf("failed to lock account: %w", err)
+	}
+	if held.available-held.overdraft < amount {
+		return nil
+	}
+
+	if err := t.add(held, -amount, now); err != nil {
+		return fmt.Errorf("failed to debit round-up: %w", err)
+	}
+	if err := t.moveBalance(savingsAccountID, amount, now); err != nil {
+		return fmt.Errorf("failed to credit round-up savings account: %w", err)
+	}
+
+	if err := t.insertTransaction(&transaction.Transaction{
+		ID:              uuid.New(),
+		IdempotencyKey:  "round-up:" + txn.ID.String(),
+		FromAccountID:   &fromAccountID,
+		ToAccountID:     &savingsAccountID,
+		Amount:          amount,
+		TransactionType: transaction.TransactionTypeRoundUp,
+		Status:          transaction.TransactionStatusCompleted,
+		Description:     "Round-up savings",
+		Metadata:        map[string]interface{}{"round_up_of": txn.ID.String()},
+		CreatedAt:       now,
+		CompletedAt:     &now,
+	}); err != nil {
+		return fmt.Errorf("failed to insert round-up transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package fakes
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/saga"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+)
+
+var _ repository.SagaRepository = (*SagaRepository)(nil)
+
+type SagaRepository struct {
+	db *DB
+}
+
+func NewSagaRepository(db *DB) *SagaRepository {
+	return &SagaRepository{db: db}
+}
+
+func copySaga(s saga.Saga) *saga.Saga {
+	s.Data = bytes.Clone(s.Data)
+	s.LockedUntil = copyPtr(s.LockedUntil)
+	s.CompletedAt = copyPtr(s.CompletedAt)
+	return &s
+}
+
+func (r *SagaRepository) Create(s *saga.Saga) error {
+	return r.db.update(func(t *tables) error {
+		if _, ok := t.sagas[s.ID]; ok {
+			return fmt.Errorf("failed to create saga: duplicate id %s", s.ID)
+		}
+		now := r.db.timestamp()
+		s.CreatedAt, s.UpdatedAt = now, now
+		t.sagas[s.ID] = saga.Saga{
+			ID:            s.ID,
+			Kind:          s.Kind,
+			Status:        s.Status,
+			Data:          bytes.Clone(s.Data),
+			Step:          s.Step,
+			NextAttemptAt: s.NextAttemptAt.UTC(),
+			LockedUntil:   utcTime(s.LockedUntil),
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+		return nil
+	})
+}
+
+func (r *SagaRepository) Save(s *saga.Saga) error {
+	return r.db.update(func(t *tables) error {
+		stored, ok := t.sagas[s.ID]
+		if !ok {
+			return fmt.Errorf("saga not found")
+		}
+		stored.Status = s.Status
+		stored.Data = bytes.Clone(s.Data)
+		stored.Step = s.Step
+		stored.Attempts = s.Attempts
+		stored.LastError = s.LastError
+		stored.NextAttemptAt = s.NextAttemptAt.UTC()
+		stored.LockedUntil = utcTime(s.LockedUntil)
+		stored.CompletedAt = utcTime(s.CompletedAt)
+		stored.UpdatedAt = r.db.timestamp()
+		t.sagas[s.ID] = stored
+		return nil
+	})
+}
+
+func (r *SagaRepository) ClaimDue(now, lockedUntil time.Time, limit int) ([]*saga.Saga, error) {
+	sagas := []*saga.Saga{}
+	err := r.db.update(func(t *tables) error {
+		now, lockedUntil := now.UTC(), lockedUntil.UTC()
+		for _, s := range sortedValues(t.sagas, func(a, b *saga.Saga) bool {
+			return oldestFirst(a.NextAttemptAt, b.NextAttemptAt, a.ID, b.ID)
+		}) {
+			if len(sagas) == limit {
+				break
+			}
+			if s.Status != saga.StatusRunning && s.Status != saga.StatusCompensating {
+				continue
+			}
+			if s.NextAttemptAt.After(now) || (s.LockedUntil != nil && !s.LockedUntil.Before(now)) {
+				continue
+			}
+			s.LockedUntil = &lockedUntil
+			s.UpdatedAt = now
+			t.sagas[s.ID] = *copySaga(s)
+			sagas = append(sagas, copySaga(s))
+		}
+		return nil
+	})
+	return sagas, err
+}
+
+func (r *SagaRepository) GetByID(id uuid.UUID) (*saga.Saga, error) {
+	var found *saga.Saga
+	err := r.db.view(func(t *tables) error {
+		s, ok := t.sagas[id]
+		if !ok {
+			return fmt.Errorf("saga not found")
+		}
+		found = copySaga(s)
+		return nil
+	})
+	return found, err
+}
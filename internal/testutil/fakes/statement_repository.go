@@ -0,0 +1,171 @@
+package fakes
+
+import (
+	"fmt"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/statement"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+)
+
+var _ repository.StatementRepository = (*StatementRepository)(nil)
+
+type StatementRepository struct {
+	db *DB
+}
+
+func NewStatementRepository(db *DB) *StatementRepository {
+	return &StatementRepository{db: db}
+}
+
+func copyDelivery(d statement.Delivery) *statement.Delivery {
+	d.SentAt = copyPtr(d.SentAt)
+	return &d
+}
+
+func (r *StatementRepository) SaveSubscription(s *statement.Subscription) error {
+	return r.db.update(func(t *tables) error {
+		now := r.db.timestamp()
+		stored, ok := t.subscriptions[s.AccountID]
+		if ok {
+			stored.Email = s.Email
+			stored.UpdatedAt = now
+		} else {
+			stored = statement.Subscription{
+				ID:        s.ID,
+				UserID:    s.UserID,
+				AccountID: s.AccountID,
+				Email:     s.Email,
+				CreatedAt: now,
+				UpdatedAt: now,
+			}
+		}
+		t.subscriptions[s.AccountID] = stored
+		s.ID, s.CreatedAt, s.UpdatedAt = stored.ID, stored.CreatedAt, stored.UpdatedAt
+		return nil
+	})
+}
+
+func (r *StatementRepository) GetSubscription(accountID uuid.UUID) (*statement.Subscription, error) {
+	var found *statement.Subscription
+	err := r.db.view(func(t *tables) error {
+		s, ok := t.subscriptions[accountID]
+		if !ok {
+			return fmt.Errorf("statement subscription not found")
+		}
+		found = &s
+		return nil
+	})
+	return found, err
+}
+
+func (r *StatementRepository) DeleteSubscription(accountID uuid.UUID) error {
+	return r.db.update(func(t *tables) error {
+		if _, ok := t.subscriptions[accountID]; !ok {
+			return fmt.Errorf("statement subscription not found")
+		}
+		delete(t.subscriptions, accountID)
+		return nil
+	})
+}
+
+func (r *StatementRepository) ListDueSubscriptions(period string, maxAttempts int) ([]*statement.Subscription, error) {
+	subscriptions := []*statement.Subscription{}
+	err := r.db.view(func(t *tables) error {
+		for _, s := range sortedValues(t.subscriptions, func(a, b *statement.Subscription) bool {
+			return oldestFirst(a.CreatedAt, b.CreatedAt, a.ID, b.ID)
+		}) {
+			acc, ok := t.accounts[s.AccountID]
+			if !ok || acc.Status == account.AccountStatusClosed {
+				continue
+			}
+			d, ok := t.delivery(s.AccountID, period)
+			if ok && (d.Status == statement.DeliveryStatusSent || d.Attempts >= maxAttempts) {
+				continue
+			}
+			subscriptions = append(subscriptions, &s)
+		}
+		return nil
+	})
+	return subscriptions, err
+}
+
+// delivery returns the delivery of the account's statement for the period
+func (t *tables) delivery(accountID uuid.UUID, period string) (statement.Delivery, bool) {
+	for _, d := range t.deliveries {
+		if d.AccountID == accountID && d.Period == period {
+			return d, true
+		}
+	}
+	return statement.Delivery{}, false
+}
+
+func (r *StatementRepository) EnsureDelivery(d *statement.Delivery) error {
+	return r.db.update(func(t *tables) error {
+		existing, ok := t.delivery(d.AccountID, d.Period)
+		if !ok {
+			if _, ok := t.deliveries[d.ID]; ok {
+				return fmt.Errorf("failed to create statement delivery: duplicate id %s", d.ID)
+			}
+			now := r.db.timestamp()
+			existing = statement.Delivery{
+				ID:        d.ID,
+				UserID:    d.UserID,
+				AccountID: d.AccountID,
+				Period:    d.Period,
+				Status:    statement.DeliveryStatusPending,
+				CreatedAt: now,
+				UpdatedAt: now,
+			}
+			t.deliveries[d.ID] = existing
+		}
+		*d = *copyDelivery(existing)
+		return nil
+	})
+}
+
+func (r *StatementRepository) GetDelivery(id uuid.UUID) (*statement.Delivery, error) {
+	var found *statement.Delivery
+	err := r.db.view(func(t *tables) error {
+		d, ok := t.deliveries[id]
+		if !ok {
+			return fmt.Errorf("statement delivery not found")
+		}
+		found = copyDelivery(d)
+		return nil
+	})
+	return found, err
+}
+
+func (r *StatementRepository) ListDeliveries(accountID uuid.UUID) ([]*statement.Delivery, error) {
+	deliveries := []*statement.Delivery{}
+	err := r.db.view(func(t *tables) error {
+		for _, d := range sortedValues(t.deliveries, func(a, b *statement.Delivery) bool {
+			return a.Period > b.Period
+		}) {
+			if d.AccountID == accountID {
+				deliveries = append(deliveries, copyDelivery(d))
+			}
+		}
+		return nil
+	})
+	return deliveries, err
+}
+
+func (r *StatementRepository) RecordAttempt(d *statement.Delivery) error {
+	return r.db.update(func(t *tables) error {
+		stored, ok := t.deliveries[d.ID]
+		if !ok {
+			return fmt.Errorf("statement delivery not found")
+		}
+		stored.Email = d.Email
+		stored.Status = d.Status
+		stored.Attempts = d.Attempts
+		stored.LastError = d.LastError
+		stored.SentAt = utcTime(d.SentAt)
+		stored.UpdatedAt = r.db.timestamp()
+		t.deliveries[d.ID] = stored
+		return nil
+	})
+}
@@ -0,0 +1,175 @@
+package fakes
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/topup"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+)
+
+var _ repository.TopupRepository = (*TopupRepository)(nil)
+
+type TopupRepository struct {
+	db *DB
+}
+
+func NewTopupRepository(db *DB) *TopupRepository {
+	return &TopupRepository{db: db}
+}
+
+func copyTopup(tp topup.Topup) *topup.Topup {
+	tp.CompletedAt = copyPtr(tp.CompletedAt)
+	return &tp
+}
+
+func (r *TopupRepository) Create(tp *topup.Topup, txn *transaction.Transaction) error {
+	return r.db.update(func(t *tables) error {
+		now := r.db.timestamp()
+		total := tp.TotalAmount()
+
+		held, err := t.lockBalance(tp.AccountID, "", now)
+		if err != nil {
+			return fmt.Errorf("failed to lock account: %w", err)
+		}
+		if held.available < total {
+			return insufficientFunds(held.available, total)
+		}
+		if err := t.add(held, -total, now); err != nil {
+			return fmt.Errorf("failed to debit account: %w", err)
+		}
+
+		accountID := tp.AccountID
+		if err := t.insertTransaction(&transaction.Transaction{
+			ID:              txn.ID,
+			IdempotencyKey:  txn.IdempotencyKey,
+			FromAccountID:   &accountID,
+			Amount:          total,
+			TransactionType: txn.TransactionType,
+			Status:          transaction.TransactionStatusPending,
+			Description:     txn.Description,
+			Metadata:        txn.Metadata,
+			CreatedAt:       now,
+		}); err != nil {
+			return err
+		}
+
+		if _, ok := t.topups[tp.ID]; ok {
+			return fmt.Errorf("failed to create top-up: duplicate id %s", tp.ID)
+		}
+		tp.CreatedAt, tp.UpdatedAt = now, now
+		stored := *copyTopup(*tp)
+		stored.Reference, stored.FailureReason, stored.CompletedAt = "", "", nil
+		t.topups[tp.ID] = stored
+		return nil
+	})
+}
+
+// getOne returns the top-up matching the condition
+func (r *TopupRepository) getOne(match func(tp *topup.Topup) bool) (*topup.Topup, error) {
+	var found *topup.Topup
+	err := r.db.view(func(t *tables) error {
+		for _, tp := range t.topups {
+			if match(&tp) {
+				found = copyTopup(tp)
+				return nil
+			}
+		}
+		return fmt.Errorf("top-up not found")
+	})
+	return found, err
+}
+
+func (r *TopupRepository) GetByID(id uuid.UUID) (*topup.Topup, error) {
+	return r.getOne(func(tp *topup.Topup) bool { return tp.ID == id })
+}
+
+func (r *TopupRepository) GetByTransactionID(txnID uuid.UUID) (*topup.Topup, error) {
+	return r.getOne(func(tp *topup.Topup) bool { return tp.TransactionID == txnID })
+}
+
+func (r *TopupRepository) ListByUser(userID uuid.UUID, limit int) ([]*topup.Topup, error) {
+	return r.list(func(tp *topup.Topup) bool { return tp.UserID == userID }, func(a, b *topup.Topup) bool {
+		return newestFirst(a.CreatedAt, b.CreatedAt, a.ID, b.ID)
+	}, limit)
+}
+
+func (r *TopupRepository) ListUnfinished(updatedBefore time.Time, limit int) ([]*topup.Topup, error) {
+	return r.list(func(tp *topup.Topup) bool {
+		return unfinished(tp) && tp.UpdatedAt.Before(updatedBefore)
+	}, func(a, b *topup.Topup) bool {
+		return oldestFirst(a.UpdatedAt, b.UpdatedAt, a.ID, b.ID)
+	}, limit)
+}
+
+func (r *TopupRepository) list(match func(tp *topup.Topup) bool, less func(a, b *topup.Topup) bool, limit int) ([]*topup.Topup, error) {
+	topups := []*topup.Topup{}
+	err := r.db.view(func(t *tables) error {
+		for _, tp := range sortedValues(t.topups, less) {
+			if match(&tp) {
+				topups = append(topups, copyTopup(tp))
+			}
+		}
+		topups = page(topups, limit, 0)
+		return nil
+	})
+	return topups, err
+}
+
+func unfinished(tp *topup.Topup) bool {
+	return tp.Status == topup.StatusPending || tp.Status == topup.StatusProcessing
+}
+
+func (r *TopupRepository) MarkProcessing(id uuid.UUID, reference string) error {
+	return r.db.update(func(t *tables) error {
+		tp, ok := t.topups[id]
+		if !ok || tp.Status != topup.StatusPending {
+			return fmt.Errorf("pending top-up not found")
+		}
+		tp.Status, tp.Reference = topup.StatusProcessing, reference
+		tp.UpdatedAt = r.db.timestamp()
+		t.topups[id] = tp
+		return nil
+	})
+}
+
+// Complete records the delivery and settles the ledger entry
+func (r *TopupRepository) Complete(id uuid.UUID, reference string) error {
+	return r.db.update(func(t *tables) error {
+		now := r.db.timestamp()
+		tp, ok := t.topups[id]
+		if !ok || !unfinished(&tp) {
+			return fmt.Errorf("unfinished top-up not found")
+		}
+		tp.Status, tp.Reference = topup.StatusSuccess, reference
+		tp.CompletedAt, tp.UpdatedAt = &now, now
+		t.topups[id] = tp
+
+		t.settleTransaction(tp.TransactionID, transaction.TransactionStatusCompleted,
+			map[string]interface{}{"provider_reference": reference}, now)
+		return nil
+	})
+}
+
+func (r *TopupRepository) Fail(id uuid.UUID, reason string) error {
+	return r.db.update(func(t *tables) error {
+		now := r.db.timestamp()
+		tp, ok := t.topups[id]
+		if !ok || !unfinished(&tp) {
+			return fmt.Errorf("unfinished top-up not found")
+		}
+		tp.Status, tp.FailureReason = topup.StatusFailed, reason
+		tp.CompletedAt, tp.UpdatedAt = &now, now
+		t.topups[id] = tp
+
+		// The refund is credited even if the account was frozen in the meantime
+		if err := t.moveBalance(tp.AccountID, tp.Amount+tp.AdminFee, now); err != nil {
+			return fmt.Errorf("failed to refund account: %w", err)
+		}
+		t.settleTransaction(tp.TransactionID, transaction.TransactionStatusReversed,
+			map[string]interface{}{"reversal_reason": reason}, now)
+		return nil
+	})
+}
@@ -0,0 +1,178 @@
+package fakes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/pkg/objectstore"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+)
+
+var _ repository.TransactionArchiveRepository = (*TransactionArchiveRepository)(nil)
+
+// archiveBalanceKey is a transaction_archive_balances row's key
+type archiveBalanceKey struct {
+	month     time.Time
+	accountID uuid.UUID
+}
+
+// archiveBalance is an account's movements in an archived month
+type archiveBalance struct {
+	credits float64
+	debits  float64
+}
+
+// TransactionArchiveRepository models the monthly partitions of the
+// transactions table as a set of months; a transaction belongs to the
+// partition of the month it was created in.
+type TransactionArchiveRepository struct {
+	db    *DB
+	store objectstore.Store // nil when archiving is not configured
+}
+
+func NewTransactionArchiveRepository(db *DB, store objectstore.Store) *TransactionArchiveRepository {
+	return &TransactionArchiveRepository{db: db, store: store}
+}
+
+func (r *TransactionArchiveRepository) EnsurePartitions(from time.Time, months int) error {
+	return r.db.update(func(t *tables) error {
+		month := transaction.MonthStart(from)
+		for i := 0; i < months; i++ {
+			t.partitions[month.AddDate(0, i, 0)] = true
+		}
+		return nil
+	})
+}
+
+func (r *TransactionArchiveRepository) ListPartitionMonths() ([]time.Time, error) {
+	var months []time.Time
+	err := r.db.view(func(t *tables) error {
+		months = sortedKeys(t.partitions, time.Time.Before)
+		return nil
+	})
+	return months, err
+}
+
+// inPartition matches the transactions of a month's partition
+func inPartition(month time.Time) func(txn *transaction.Transaction) bool {
+	return func(txn *transaction.Transaction) bool {
+		return transaction.MonthStart(txn.CreatedAt).Equal(month)
+	}
+}
+
+func (r *TransactionArchiveRepository) ListPartition(month time.Time) ([]*transaction.Transaction, error) {
+	var txns []*transaction.Transaction
+	err := r.db.view(func(t *tables) error {
+		month := transaction.MonthStart(month)
+		if !t.partitions[month] {
+			return fmt.Errorf("failed to list transaction partition: partition for %s does not exist", month.Format("2006-01"))
+		}
+		txns = t.selectTransactions(inPartition(month), func(a, b *transaction.Transaction) bool {
+			return oldestFirst(a.CreatedAt, b.CreatedAt, a.ID, b.ID)
+		})
+		return nil
+	})
+	return txns, err
+}
+
+func (r *TransactionArchiveRepository) ArchivePartition(archive *transaction.Archive) error {
+	return r.db.update(func(t *tables) error {
+		month := transaction.MonthStart(archive.Month)
+		if _, ok := t.archives[month]; ok {
+			return fmt.Errorf("failed to record transaction archive: month %s is already archived", month.Format("2006-01"))
+		}
+		if !t.partitions[month] {
+			return fmt.Errorf("failed to detach transaction partition: partition for %s does not exist", month.Format("2006-01"))
+		}
+
+		archive.ArchivedAt = r.db.timestamp()
+		stored := *archive
+		stored.Month = month
+		t.archives[month] = stored
+
+		// Same rules as the ledger balance: completed credits, completed and
+		// pending debits, in the account's own currency
+		belongs := inPartition(month)
+		for id, txn := range t.transactions {
+			if !belongs(&txn) {
+				continue
+			}
+			if txn.ToAccountID != nil && txn.Status == transaction.TransactionStatusCompleted {
+				t.recordArchived(month, *txn.ToAccountID, &txn, txn.Amount, 0)
+			}
+			if txn.FromAccountID != nil && (txn.Status == transaction.TransactionStatusCompleted || txn.Status == transaction.TransactionStatusPending) {
+				t.recordArchived(month, *txn.FromAccountID, &txn, 0, txn.Amount)
+			}
+			delete(t.transactions, id)
+		}
+		delete(t.partitions, month)
+		return nil
+	})
+}
+
+// recordArchived adds a movement to the account's archived totals for the
+// month, unless it is in another currency than the account's own
+func (t *tables) recordArchived(month time.Time, accountID uuid.UUID, txn *transaction.Transaction, credit, debit float64) {
+	acc, ok := t.accounts[accountID]
+	if !ok {
+		return
+	}
+	if currency, ok := metadataText(txn.Metadata, "currency"); ok && currency != acc.Currency {
+		return
+	}
+	key := archiveBalanceKey{month: month, accountID: accountID}
+	b := t.archiveBalances[key]
+	b.credits += credit
+	b.debits += debit
+	t.archiveBalances[key] = b
+}
+
+func (r *TransactionArchiveRepository) ListArchives(from, to time.Time) ([]*transaction.Archive, error) {
+	archives := []*transaction.Archive{}
+	err := r.db.view(func(t *tables) error {
+		for _, month := range sortedKeys(t.archives, time.Time.Before) {
+			if month.Before(to) && month.AddDate(0, 1, 0).After(from) {
+				a := t.archives[month]
+				archives = append(archives, &a)
+			}
+		}
+		return nil
+	})
+	return archives, err
+}
+
+func (r *TransactionArchiveRepository) ListArchived(ctx context.Context, accountID uuid.UUID, from, to time.Time) ([]*transaction.Transaction, error) {
+	archives, err := r.ListArchives(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if len(archives) > 0 && r.store == nil {
+		return nil, fmt.Errorf("transaction archive storage is not configured")
+	}
+
+	txns := []*transaction.Transaction{}
+	for _, a := range archives {
+		data, err := r.store.Get(ctx, a.ObjectKey)
+		if err != nil {
+			return nil, err
+		}
+		archived, err := transaction.DecodeArchive(data)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, txn := range archived {
+			if txn.CreatedAt.Before(from) || !txn.CreatedAt.Before(to) {
+				continue
+			}
+			if involves(txn, accountID) {
+				txns = append(txns, txn)
+			}
+		}
+	}
+
+	return txns, nil
+}
@@ -0,0 +1,544 @@
+package fakes
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+)
+
+var _ repository.TransactionRepository = (*TransactionRepository)(nil)
+
+// memoryHook is a debit hook of this package. It runs inside the debit's
+// database transaction, on the same tables, rather than through a *sql.Tx.
+type memoryHook interface {
+	afterDebit(t *tables, txn *transaction.Transaction, now time.Time) error
+}
+
+// runDebitHooks runs the hooks after a debit. Hooks from outside this package,
+// such as mocks, are called with a nil *sql.Tx while the database is locked,
+// so they must not call back into the fakes. An error rolls the debit back.
+func runDebitHooks(t *tables, hooks []repository.DebitHook, txn *transaction.Transaction, now time.Time) error {
+	for _, hook := range hooks {
+		var err error
+		if h, ok := hook.(memoryHook); ok {
+			err = h.afterDebit(t, txn, now)
+		} else {
+			err = hook.AfterDebit(nil, txn)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// heldBalance is an account's balance in one currency, locked for update
+type heldBalance struct {
+	accountID uuid.UUID
+	currency  string
+	balance   float64
+	// available is what a debit may take: in the account's own currency the
+	// balance plus the overdraft limit less its open holds, in another
+	// currency the balance
+	available float64
+	// overdraft is the overdraft limit counted in available
+	overdraft float64
+	// own is the account's own currency, kept on the account rather than in
+	// its other balances
+	own bool
+}
+
+// lockBalance loads an active account's balance in the currency; an empty
+// currency is the account's own. It fails when the account does not hold the
+// currency.
+func (t *tables) lockBalance(accountID uuid.UUID, currency string, now time.Time) (*heldBalance, error) {
+	acc, ok := t.accounts[accountID]
+	if !ok || acc.Status != account.AccountStatusActive {
+		return nil, sql.ErrNoRows
+	}
+	if currency == "" || currency == acc.Currency {
+		return &heldBalance{
+			accountID: accountID,
+			currency:  acc.Currency,
+			balance:   acc.Balance,
+			available: t.availableBalance(acc, now),
+			overdraft: acc.OverdraftLimit,
+			own:       true,
+		}, nil
+	}
+
+	balance, ok := t.balances[balanceKey{accountID: accountID, currency: currency}]
+	if !ok {
+		return nil, fmt.Errorf("account does not hold %s", currency)
+	}
+	return &heldBalance{accountID: accountID, currency: currency, balance: balance, available: balance}, nil
+}
+
+// insufficientFunds is the error for a debit of need from a balance of have
+func insufficientFunds(have, need float64) error {
+	return fmt.Errorf("%w: have %.2f, need %.2f", transaction.ErrInsufficientFunds, have, need)
+}
+
+// add moves the balance by delta, negative for a debit. Like the CHECK
+// constraints, it refuses to take the account's own balance below its
+// overdraft limit or another balance below zero.
+func (t *tables) add(h *heldBalance, delta float64, now time.Time) error {
+	if h.own {
+		acc := t.accounts[h.accountID]
+		if acc.Balance+delta < -acc.OverdraftLimit {
+			return insufficientFunds(h.balance, -delta)
+		}
+		acc.Balance += delta
+		t.accounts[h.accountID] = acc.touched(now)
+	} else {
+		key := balanceKey{accountID: h.accountID, currency: h.currency}
+		if t.balances[key]+delta < 0 {
+			return insufficientFunds(h.balance, -delta)
+		}
+		t.balances[key] += delta
+	}
+	h.balance += delta
+	h.available += delta
+	return nil
+}
+
+// moveBalance adds delta to the account's own balance without the checks of
+// a money movement, as the UPDATE statements of the other repositories do
+func (t *tables) moveBalance(accountID uuid.UUID, delta float64, now time.Time) error {
+	acc, ok := t.accounts[accountID]
+	if !ok {
+		return fmt.Errorf("account %s does not exist", accountID)
+	}
+	if acc.Balance+delta < -acc.OverdraftLimit {
+		return insufficientFunds(acc.Balance, -delta)
+	}
+	acc.Balance += delta
+	t.accounts[accountID] = acc.touched(now)
+	return nil
+}
+
+// insertTransaction stores a copy of the transaction, keeping the id and
+// idempotency key unique
+func (t *tables) insertTransaction(txn *transaction.Transaction) error {
+	if _, ok := t.transactions[txn.ID]; ok {
+		return fmt.Errorf("failed to insert transaction: duplicate id %s", txn.ID)
+	}
+	for _, other := range t.transactions {
+		if other.IdempotencyKey == txn.IdempotencyKey {
+			return fmt.Errorf("failed to insert transaction: duplicate idempotency key %q", txn.IdempotencyKey)
+		}
+	}
+	stored, err := storedTransaction(txn)
+	if err != nil {
+		return err
+	}
+	t.transactions[txn.ID] = stored
+	return nil
+}
+
+// storedTransaction is the transaction as a row holds it
+func storedTransaction(txn *transaction.Transaction) (transaction.Transaction, error) {
+	stored := *txn
+	metadata, err := jsonMetadata(txn.Metadata)
+	if err != nil {
+		return stored, err
+	}
+	stored.Metadata = metadata
+	stored.FromAccountID = copyPtr(txn.FromAccountID)
+	stored.ToAccountID = copyPtr(txn.ToAccountID)
+	stored.CompletedAt = copyPtr(txn.CompletedAt)
+	return stored, nil
+}
+
+func copyTransaction(txn transaction.Transaction) *transaction.Transaction {
+	txn.Metadata, _ = jsonMetadata(txn.Metadata)
+	txn.FromAccountID = copyPtr(txn.FromAccountID)
+	txn.ToAccountID = copyPtr(txn.ToAccountID)
+	txn.CompletedAt = copyPtr(txn.CompletedAt)
+	return &txn
+}
+
+// involves reports whether the transaction moves money from or to the account
+func involves(txn *transaction.Transaction, accountID uuid.UUID) bool {
+	return (txn.FromAccountID != nil && *txn.FromAccountID == accountID) ||
+		(txn.ToAccountID != nil && *txn.ToAccountID == accountID)
+}
+
+// postedAt is COALESCE(completed_at, created_at)
+func postedAt(txn *transaction.Transaction) time.Time {
+	if txn.CompletedAt != nil {
+		return *txn.CompletedAt
+	}
+	return txn.CreatedAt
+}
+
+func newestTransactionFirst(a, b *transaction.Transaction) bool {
+	return newestFirst(a.CreatedAt, b.CreatedAt, a.ID, b.ID)
+}
+
+func postingOrder(a, b *transaction.Transaction) bool {
+	return oldestFirst(postedAt(a), postedAt(b), a.ID, b.ID)
+}
+
+// selectTransactions returns copies of the transactions matching the
+// condition, ordered by less
+func (t *tables) selectTransactions(match func(txn *transaction.Transaction) bool, less func(a, b *transaction.Transaction) bool) []*transaction.Transaction {
+	txns := []*transaction.Transaction{}
+	for _, txn := range sortedValues(t.transactions, less) {
+		if match(&txn) {
+			txns = append(txns, copyTransaction(txn))
+		}
+	}
+	return txns
+}
+
+type TransactionRepository struct {
+	db    *DB
+	hooks []repository.DebitHook
+}
+
+// NewTransactionRepository returns the repository. The hooks run after every
+// transfer and withdrawal from an account's own currency; a
+// RoundUpRepository passed as a hook must share the DB.
+func NewTransactionRepository(db *DB, hooks ...repository.DebitHook) *TransactionRepository {
+	return &TransactionRepository{db: db, hooks: hooks}
+}
+
+func (r *TransactionRepository) Create(txn *transaction.Transaction) error {
+	return r.db.update(func(t *tables) error {
+		txn.CreatedAt = r.db.timestamp()
+		stored := *txn
+		stored.CompletedAt = nil
+		if err := t.insertTransaction(&stored); err != nil {
+			return fmt.Errorf("failed to create transaction: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *TransactionRepository) GetByID(id uuid.UUID) (*transaction.Transaction, error) {
+	var found *transaction.Transaction
+	err := r.db.view(func(t *tables) error {
+		txn, ok := t.transactions[id]
+		if !ok {
+			return fmt.Errorf("transaction not found")
+		}
+		found = copyTransaction(txn)
+		return nil
+	})
+	return found, err
+}
+
+func (r *TransactionRepository) GetByIdempotencyKey(key string) (*transaction.Transaction, error) {
+	var found *transaction.Transaction
+	err := r.db.view(func(t *tables) error {
+		for _, txn := range t.transactions {
+			if txn.IdempotencyKey == key {
+				found = copyTransaction(txn)
+				return nil
+			}
+		}
+		return fmt.Errorf("transaction not found")
+	})
+	return found, err
+}
+
+func (r *TransactionRepository) GetByAccountID(accountID uuid.UUID, limit, offset int) ([]*transaction.Transaction, error) {
+	return r.GetByAccountIDWithFilters(accountID, nil, limit, offset)
+}
+
+func (r *TransactionRepository) GetByAccountIDWithFilters(accountID uuid.UUID, filters map[string]interface{}, limit, offset int) ([]*transaction.Transaction, error) {
+	var txns []*transaction.Transaction
+	err := r.db.view(func(t *tables) error {
+		txns = page(t.selectTransactions(accountFilter(accountID, filters), newestTransactionFirst), limit, offset)
+		return nil
+	})
+	return txns, err
+}
+
+func (r *TransactionRepository) CountByAccountID(accountID uuid.UUID, filters map[string]interface{}) (int, error) {
+	txns, err := r.GetByAccountIDWithFilters(accountID, filters, -1, 0)
+	return len(txns), err
+}
+
+// accountFilter is the condition shared by the filtered history and its count
+func accountFilter(accountID uuid.UUID, filters map[string]interface{}) func(txn *transaction.Transaction) bool {
+	startDate, hasStart := filters["start_date"].(time.Time)
+	endDate, hasEnd := filters["end_date"].(time.Time)
+	txnType, hasType := filters["type"].(string)
+
+	return func(txn *transaction.Transaction) bool {
+		return involves(txn, accountID) &&
+			(!hasStart || !txn.CreatedAt.Before(startDate)) &&
+			(!hasEnd || !txn.CreatedAt.After(endDate)) &&
+			(!hasType || string(txn.TransactionType) == txnType)
+	}
+}
+
+// Search matches the query text as a case-insensitive substring of the
+// description, the metadata's string values or the counterparty's name, or
+// as the counterparty's account number. Unlike Postgres it does not rank the
+// matches; they come newest first.
+func (r *TransactionRepository) Search(f *transaction.SearchFilter) ([]*transaction.Transaction, error) {
+	txns := []*transaction.Transaction{}
+	if len(f.AccountIDs) == 0 {
+		return txns, nil
+	}
+	err := r.db.view(func(t *tables) error {
+		txns = page(t.selectTransactions(t.searchFilter(f), newestTransactionFirst), f.Limit, f.Offset)
+		return nil
+	})
+	return txns, err
+}
+
+func (r *TransactionRepository) CountSearch(f *transaction.SearchFilter) (int, error) {
+	if len(f.AccountIDs) == 0 {
+		return 0, nil
+	}
+	var total int
+	err := r.db.view(func(t *tables) error {
+		total = len(t.selectTransactions(t.searchFilter(f), newestTransactionFirst))
+		return nil
+	})
+	return total, err
+}
+
+func (t *tables) searchFilter(f *transaction.SearchFilter) func(txn *transaction.Transaction) bool {
+	ours := map[uuid.UUID]bool{}
+	for _, id := range f.AccountIDs {
+		ours[id] = true
+	}
+	query := strings.ToLower(f.Query)
+
+	return func(txn *transaction.Transaction) bool {
+		from := txn.FromAccountID != nil && ours[*txn.FromAccountID]
+		to := txn.ToAccountID != nil && ours[*txn.ToAccountID]
+		if !from && !to {
+			return false
+		}
+		if query != "" && !t.searchMatches(txn, from, query) {
+			return false
+		}
+		if f.MinAmount > 0 && txn.Amount < f.MinAmount {
+			return false
+		}
+		if f.MaxAmount > 0 && txn.Amount > f.MaxAmount {
+			return false
+		}
+		if f.Status != "" && txn.Status != f.Status {
+			return false
+		}
+		if f.Type != "" && txn.TransactionType != f.Type {
+			return false
+		}
+		if f.From != nil && txn.CreatedAt.Before(*f.From) {
+			return false
+		}
+		if f.To != nil && !txn.CreatedAt.Before(*f.To) {
+			return false
+		}
+		return true
+	}
+}
+
+// searchMatches matches the query against the transaction and the owner of
+// the account on its other side
+func (t *tables) searchMatches(txn *transaction.Transaction, fromOurs bool, query string) bool {
+	if strings.Contains(strings.ToLower(txn.Description), query) {
+		return true
+	}
+	for _, v := range txn.Metadata {
+		if s, ok := v.(string); ok && strings.Contains(strings.ToLower(s), query) {
+			return true
+		}
+	}
+
+	counterparty := txn.FromAccountID
+	if fromOurs {
+		counterparty = txn.ToAccountID
+	}
+	if counterparty == nil {
+		return false
+	}
+	acc, ok := t.accounts[*counterparty]
+	if !ok {
+		return false
+	}
+	if strings.ToLower(acc.AccountNumber) == query {
+		return true
+	}
+	u, ok := t.users[acc.UserID]
+	return ok && strings.Contains(strings.ToLower(u.FirstName+" "+u.LastName), query)
+}
+
+func (r *TransactionRepository) ListCompleted(from, to time.Time) ([]*transaction.Transaction, error) {
+	var txns []*transaction.Transaction
+	err := r.db.view(func(t *tables) error {
+		txns = t.selectTransactions(completedIn(from, to), postingOrder)
+		return nil
+	})
+	return txns, err
+}
+
+func (r *TransactionRepository) ListCompletedByAccount(accountID uuid.UUID, from, to time.Time) ([]*transaction.Transaction, error) {
+	completed := completedIn(from, to)
+	var txns []*transaction.Transaction
+	err := r.db.view(func(t *tables) error {
+		txns = t.selectTransactions(func(txn *transaction.Transaction) bool {
+			return involves(txn, accountID) && completed(txn)
+		}, postingOrder)
+		return nil
+	})
+	return txns, err
+}
+
+// completedIn matches the transactions completed in [from, to)
+func completedIn(from, to time.Time) func(txn *transaction.Transaction) bool {
+	return func(txn *transaction.Transaction) bool {
+		at := postedAt(txn)
+		return txn.Status == transaction.TransactionStatusCompleted && !at.Before(from) && at.Before(to)
+	}
+}
+
+func (r *TransactionRepository) UpdateStatus(id uuid.UUID, status transaction.TransactionStatus) error {
+	return r.db.update(func(t *tables) error {
+		txn, ok := t.transactions[id]
+		if !ok {
+			return fmt.Errorf("transaction not found")
+		}
+		txn.Status = status
+		if status == transaction.TransactionStatusCompleted {
+			txn.CompletedAt = ptr(r.db.timestamp())
+		}
+		t.transactions[id] = txn
+		return nil
+	})
+}
+
+func (r *TransactionRepository) ExecuteTransfer(fromAccountID, toAccountID uuid.UUID, amount float64, txn *transaction.Transaction) error {
+	return r.db.update(func(t *tables) error {
+		now := r.db.timestamp()
+
+		from, err := t.lockBalance(fromAccountID, txn.Currency(), now)
+		if err != nil {
+			return fmt.Errorf("failed to lock source account: %w", err)
+		}
+		to, err := t.lockBalance(toAccountID, txn.Currency(), now)
+		if err != nil {
+			return fmt.Errorf("failed to lock destination account: %w", err)
+		}
+
+		if from.available < amount {
+			return insufficientFunds(from.available, amount)
+		}
+		if err := t.add(from, -amount, now); err != nil {
+			return err
+		}
+		if err := t.add(to, amount, now); err != nil {
+			return fmt.Errorf("failed to credit destination account: %w", err)
+		}
+
+		if err := t.insertTransaction(&transaction.Transaction{
+			ID:              txn.ID,
+			IdempotencyKey:  txn.IdempotencyKey,
+			FromAccountID:   &fromAccountID,
+			ToAccountID:     &toAccountID,
+			Amount:          amount,
+			TransactionType: txn.TransactionType,
+			Status:          transaction.TransactionStatusCompleted,
+			Description:     txn.Description,
+			Metadata:        txn.Metadata,
+			CreatedAt:       now,
+			CompletedAt:     &now,
+		}); err != nil {
+			return err
+		}
+
+		txn.FromAccountID, txn.ToAccountID, txn.Amount = &fromAccountID, &toAccountID, amount
+		if from.own {
+			return runDebitHooks(t, r.hooks, txn, now)
+		}
+		return nil
+	})
+}
+
+func (r *TransactionRepository) ExecuteDeposit(accountID uuid.UUID, amount float64, txn *transaction.Transaction) error {
+	return r.db.update(func(t *tables) error {
+		now := r.db.timestamp()
+
+		held, err := t.lockBalance(accountID, txn.Currency(), now)
+		if err != nil {
+			return fmt.Errorf("failed to lock account: %w", err)
+		}
+		if err := t.add(held, amount, now); err != nil {
+			return fmt.Errorf("failed to credit account: %w", err)
+		}
+
+		return t.insertTransaction(&transaction.Transaction{
+			ID:              txn.ID,
+			IdempotencyKey:  txn.IdempotencyKey,
+			ToAccountID:     &accountID,
+			Amount:          amount,
+			TransactionType: txn.TransactionType,
+			Status:          transaction.TransactionStatusCompleted,
+			Description:     txn.Description,
+			Metadata:        txn.Metadata,
+			CreatedAt:       now,
+			CompletedAt:     &now,
+		})
+	})
+}
+
+func (r *TransactionRepository) ExecuteWithdrawal(accountID uuid.UUID, amount float64, txn *transaction.Transaction) error {
+	return r.db.update(func(t *tables) error {
+		now := r.db.timestamp()
+
+		held, err := t.lockBalance(accountID, txn.Currency(), now)
+		if err != nil {
+			return fmt.Errorf("failed to lock account: %w", err)
+		}
+		if held.available < amount {
+			return insufficientFunds(held.available, amount)
+		}
+		if err := t.add(held, -amount, now); err != nil {
+			return err
+		}
+
+		if err := t.insertTransaction(&transaction.Transaction{
+			ID:              txn.ID,
+			IdempotencyKey:  txn.IdempotencyKey,
+			FromAccountID:   &accountID,
+			Amount:          amount,
+			TransactionType: txn.TransactionType,
+			Status:          transaction.TransactionStatusCompleted,
+			Description:     txn.Description,
+			Metadata:        txn.Metadata,
+			CreatedAt:       now,
+			CompletedAt:     &now,
+		}); err != nil {
+			return err
+		}
+
+		txn.FromAccountID, txn.Amount = &accountID, amount
+		if held.own {
+			return runDebitHooks(t, r.hooks, txn, now)
+		}
+		return nil
+	})
+}
+
+// settleTransaction moves a transaction to its final status and merges the
+// note into its metadata
+func (t *tables) settleTransaction(id uuid.UUID, status transaction.TransactionStatus, note map[string]interface{}, now time.Time) {
+	txn := t.transactions[id]
+	txn.Status = status
+	txn.CompletedAt = &now
+	txn.Metadata = withMetadata(txn.Metadata, note)
+	t.transactions[id] = txn
+}
@@ -0,0 +1,108 @@
+package fakes
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+)
+
+var _ repository.TransferTemplateRepository = (*TransferTemplateRepository)(nil)
+
+type TransferTemplateRepository struct {
+	db *DB
+}
+
+func NewTransferTemplateRepository(db *DB) *TransferTemplateRepository {
+	return &TransferTemplateRepository{db: db}
+}
+
+func copyTemplate(tmpl transaction.Template) *transaction.Template {
+	tmpl.ToAccountID = copyPtr(tmpl.ToAccountID)
+	tmpl.BeneficiaryID = copyPtr(tmpl.BeneficiaryID)
+	tmpl.LastUsedAt = copyPtr(tmpl.LastUsedAt)
+	return &tmpl
+}
+
+func (r *TransferTemplateRepository) Create(tmpl *transaction.Template) error {
+	return r.db.update(func(t *tables) error {
+		if _, ok := t.templates[tmpl.ID]; ok {
+			return fmt.Errorf("failed to create transfer template: duplicate id %s", tmpl.ID)
+		}
+		now := r.db.timestamp()
+		tmpl.CreatedAt, tmpl.UpdatedAt = now, now
+		stored := *copyTemplate(*tmpl)
+		stored.LastUsedAt = nil
+		t.templates[tmpl.ID] = stored
+		return nil
+	})
+}
+
+func (r *TransferTemplateRepository) GetByID(id uuid.UUID) (*transaction.Template, error) {
+	var found *transaction.Template
+	err := r.db.view(func(t *tables) error {
+		tmpl, ok := t.templates[id]
+		if !ok {
+			return fmt.Errorf("transfer template not found")
+		}
+		found = copyTemplate(tmpl)
+		return nil
+	})
+	return found, err
+}
+
+func (r *TransferTemplateRepository) ListByUser(userID uuid.UUID) ([]*transaction.Template, error) {
+	templates := []*transaction.Template{}
+	err := r.db.view(func(t *tables) error {
+		for _, tmpl := range sortedValues(t.templates, func(a, b *transaction.Template) bool {
+			if c := lastUsedFirst(a.LastUsedAt, b.LastUsedAt); c != 0 {
+				return c < 0
+			}
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+			return idLess(a.ID, b.ID)
+		}) {
+			if tmpl.UserID == userID {
+				templates = append(templates, copyTemplate(tmpl))
+			}
+		}
+		return nil
+	})
+	return templates, err
+}
+
+func (r *TransferTemplateRepository) Update(tmpl *transaction.Template) error {
+	return r.update(tmpl.ID, func(stored *transaction.Template) {
+		stored.Name, stored.Amount, stored.Description = tmpl.Name, tmpl.Amount, tmpl.Description
+	})
+}
+
+func (r *TransferTemplateRepository) MarkUsed(id uuid.UUID, at time.Time) error {
+	return r.update(id, func(tmpl *transaction.Template) { tmpl.LastUsedAt = ptr(at.UTC()) })
+}
+
+func (r *TransferTemplateRepository) Delete(id uuid.UUID) error {
+	return r.db.update(func(t *tables) error {
+		if _, ok := t.templates[id]; !ok {
+			return fmt.Errorf("transfer template not found")
+		}
+		delete(t.templates, id)
+		return nil
+	})
+}
+
+func (r *TransferTemplateRepository) update(id uuid.UUID, set func(tmpl *transaction.Template)) error {
+	return r.db.update(func(t *tables) error {
+		tmpl, ok := t.templates[id]
+		if !ok {
+			return fmt.Errorf("transfer template not found")
+		}
+		set(&tmpl)
+		tmpl.UpdatedAt = r.db.timestamp()
+		t.templates[id] = tmpl
+		return nil
+	})
+}
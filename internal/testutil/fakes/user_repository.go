@@ -0,0 +1,397 @@
+package fakes
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/user"
+	"github.com/darisadam/madabank-server/internal/repository"
+	"github.com/google/uuid"
+)
+
+var _ repository.UserRepository = (*UserRepository)(nil)
+
+// userRow is a users row with the columns the domain model leaves out
+type userRow struct {
+	user.User
+	anonymizedAt *time.Time
+}
+
+type refreshToken struct {
+	userID     uuid.UUID
+	clientType user.ClientType
+	expiresAt  time.Time
+	startedAt  time.Time
+	revoked    bool
+}
+
+type loginKey struct {
+	userID      uuid.UUID
+	fingerprint string
+	country     string
+}
+
+type UserRepository struct {
+	db *DB
+}
+
+func NewUserRepository(db *DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+func copyUser(u user.User) *user.User {
+	u.Username = copyPtr(u.Username)
+	u.Phone = copyPtr(u.Phone)
+	u.PhoneVerifiedAt = copyPtr(u.PhoneVerifiedAt)
+	u.DateOfBirth = copyPtr(u.DateOfBirth)
+	u.DeletedAt = copyPtr(u.DeletedAt)
+	return &u
+}
+
+func (r *UserRepository) Create(u *user.User) error {
+	return r.db.update(func(t *tables) error {
+		if _, ok := t.users[u.ID]; ok {
+			return fmt.Errorf("failed to create user: duplicate id %s", u.ID)
+		}
+		for _, other := range t.users {
+			if other.Email == u.Email {
+				return fmt.Errorf("failed to create user: email %s already exists", u.Email)
+			}
+		}
+
+		if u.Role == "" {
+			u.Role = user.RoleCustomer
+		}
+		now := r.db.timestamp()
+		u.CreatedAt, u.UpdatedAt = now, now
+
+		row := *copyUser(*u)
+		// Only the columns Create inserts are stored
+		row.Username, row.PhoneVerifiedAt, row.DeletedAt = nil, nil, nil
+		t.users[u.ID] = userRow{User: row}
+		return nil
+	})
+}
+
+// getOne returns the first user matching the condition
+func (r *UserRepository) getOne(match func(row *userRow) bool) (*user.User, error) {
+	var found *user.User
+	err := r.db.view(func(t *tables) error {
+		for _, row := range t.users {
+			if match(&row) {
+				found = copyUser(row.User)
+				return nil
+			}
+		}
+		return fmt.Errorf("user not found")
+	})
+	return found, err
+}
+
+func (r *UserRepository) GetByID(id uuid.UUID) (*user.User, error) {
+	return r.getOne(func(row *userRow) bool {
+		return row.ID == id && row.DeletedAt == nil
+	})
+}
+
+func (r *UserRepository) GetByEmail(email string) (*user.User, error) {
+	return r.getOne(func(row *userRow) bool {
+		return row.Email == email && row.DeletedAt == nil
+	})
+}
+
+func (r *UserRepository) GetByPhone(phone string) (*user.User, error) {
+	return r.getOne(func(row *userRow) bool {
+		return row.Phone != nil && *row.Phone == phone && row.PhoneVerifiedAt != nil && row.DeletedAt == nil
+	})
+}
+
+func (r *UserRepository) GetByUsername(username string) (*user.User, error) {
+	return r.getOne(func(row *userRow) bool {
+		return row.Username != nil && *row.Username == username && row.DeletedAt == nil
+	})
+}
+
+func (r *UserRepository) GetForReactivation(email string) (*user.User, error) {
+	return r.getOne(func(row *userRow) bool {
+		return row.Email == email && row.anonymizedAt == nil
+	})
+}
+
+func (r *UserRepository) Update(id uuid.UUID, updates map[string]interface{}) error {
+	return r.db.update(func(t *tables) error {
+		row, ok := t.users[id]
+		if !ok || row.DeletedAt != nil {
+			return fmt.Errorf("user not found")
+		}
+		row.User = *copyUser(row.User)
+		if err := setColumns(&row.User, updates); err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+		row.UpdatedAt = r.db.timestamp()
+		t.users[id] = row
+		return nil
+	})
+}
+
+func (r *UserRepository) VerifyPhone(id uuid.UUID, phone string) error {
+	return r.db.update(func(t *tables) error {
+		row, ok := t.users[id]
+		if !ok || row.DeletedAt != nil || row.Phone == nil || *row.Phone != phone {
+			return fmt.Errorf("phone number could not be verified")
+		}
+		for _, other := range t.users {
+			if other.ID != id && other.Phone != nil && *other.Phone == phone &&
+				other.PhoneVerifiedAt != nil && other.DeletedAt == nil {
+				return fmt.Errorf("phone number could not be verified")
+			}
+		}
+
+		now := r.db.timestamp()
+		row.PhoneVerifiedAt, row.UpdatedAt = &now, now
+		t.users[id] = row
+		return nil
+	})
+}
+
+func (r *UserRepository) Delete(id uuid.UUID) error {
+	return r.db.update(func(t *tables) error {
+		row, ok := t.users[id]
+		if !ok || row.DeletedAt != nil {
+			return fmt.Errorf("user not found")
+		}
+		now := r.db.timestamp()
+		row.DeletedAt = &now
+		t.users[id] = row
+
+		// Freeze the accounts; Restore only unfreezes the ones frozen here
+		for accID, acc := range t.accounts {
+			if acc.UserID == id && acc.Status == account.AccountStatusActive {
+				acc.Status, acc.frozenForDeletion = account.AccountStatusFrozen, true
+				t.accounts[accID] = acc.touched(now)
+			}
+		}
+		return nil
+	})
+}
+
+func (r *UserRepository) Restore(id uuid.UUID) error {
+	return r.db.update(func(t *tables) error {
+		row, ok := t.users[id]
+		if !ok || row.anonymizedAt != nil {
+			return fmt.Errorf("user not found")
+		}
+		row.DeletedAt, row.IsActive = nil, true
+		t.users[id] = row
+
+		now := r.db.timestamp()
+		for accID, acc := range t.accounts {
+			if acc.UserID == id && acc.frozenForDeletion {
+				acc.Status, acc.frozenForDeletion = account.AccountStatusActive, false
+				t.accounts[accID] = acc.touched(now)
+			}
+		}
+		return nil
+	})
+}
+
+func (r *UserRepository) List(f *user.ListFilter) ([]*user.User, error) {
+	users := []*user.User{}
+	err := r.db.view(func(t *tables) error {
+		rows := sortedValues(t.users, func(a, b *userRow) bool {
+			return newestFirst(a.CreatedAt, b.CreatedAt, a.ID, b.ID)
+		})
+		for _, row := range rows {
+			if !userMatches(&row, f) {
+				continue
+			}
+			if len(users) == f.Limit+1 {
+				break
+			}
+			users = append(users, copyUser(row.User))
+		}
+		return nil
+	})
+	return users, err
+}
+
+// userMatches is the WHERE clause of a user search
+func userMatches(row *userRow, f *user.ListFilter) bool {
+	if row.DeletedAt != nil {
+		return false
+	}
+	if f.Email != "" && !strings.Contains(strings.ToLower(row.Email), strings.ToLower(f.Email)) {
+		return false
+	}
+	if f.Phone != "" && (row.Phone == nil || !strings.Contains(*row.Phone, f.Phone)) {
+		return false
+	}
+	if f.KYCStatus != "" && row.KYCStatus != f.KYCStatus {
+		return false
+	}
+	if f.IsActive != nil && row.IsActive != *f.IsActive {
+		return false
+	}
+	if f.CreatedFrom != nil && row.CreatedAt.Before(*f.CreatedFrom) {
+		return false
+	}
+	if f.CreatedTo != nil && !row.CreatedAt.Before(*f.CreatedTo) {
+		return false
+	}
+	if f.After != nil && !newestFirst(f.After.CreatedAt, row.CreatedAt, f.After.ID, row.ID) {
+		return false
+	}
+	return true
+}
+
+func (r *UserRepository) ListAnonymizable(deletedBefore time.Time, limit int) ([]uuid.UUID, error) {
+	ids := []uuid.UUID{}
+	err := r.db.view(func(t *tables) error {
+		rows := sortedValues(t.users, func(a, b *userRow) bool {
+			if a.DeletedAt == nil || b.DeletedAt == nil {
+				return a.DeletedAt != nil
+			}
+			return oldestFirst(*a.DeletedAt, *b.DeletedAt, a.ID, b.ID)
+		})
+		for _, row := range rows {
+			if row.DeletedAt != nil && row.DeletedAt.Before(deletedBefore) && row.anonymizedAt == nil {
+				ids = append(ids, row.ID)
+			}
+		}
+		ids = page(ids, limit, 0)
+		return nil
+	})
+	return ids, err
+}
+
+func (r *UserRepository) Anonymize(id uuid.UUID) error {
+	return r.db.update(func(t *tables) error {
+		row, ok := t.users[id]
+		if !ok || row.DeletedAt == nil || row.anonymizedAt != nil {
+			return fmt.Errorf("deleted user not found")
+		}
+		now := r.db.timestamp()
+		row.Email = user.AnonymizedEmail(id)
+		row.Username = nil
+		row.FirstName, row.LastName = user.AnonymizedFirstName, user.AnonymizedLastName
+		row.Phone, row.PhoneVerifiedAt, row.DateOfBirth = nil, nil, nil
+		row.PasswordHash, row.IsActive = "", false
+		row.anonymizedAt = &now
+		t.users[id] = row
+
+		for hash, token := range t.refreshTokens {
+			if token.userID == id {
+				delete(t.refreshTokens, hash)
+			}
+		}
+		for key := range t.logins {
+			if key.userID == id {
+				delete(t.logins, key)
+			}
+		}
+		for bID, b := range t.beneficiaries {
+			if b.UserID == id {
+				delete(t.beneficiaries, bID)
+			}
+		}
+		for accID, s := range t.subscriptions {
+			if s.UserID == id {
+				delete(t.subscriptions, accID)
+			}
+		}
+		for dID, d := range t.deliveries {
+			if d.UserID == id {
+				d.Email = ""
+				t.deliveries[dID] = d
+			}
+		}
+		for cID, c := range t.cards {
+			if acc, ok := t.accounts[c.AccountID]; ok && acc.UserID == id {
+				c.CardHolderName = user.AnonymizedCardHolder
+				t.cards[cID] = c
+			}
+		}
+		return nil
+	})
+}
+
+func (r *UserRepository) SaveRefreshToken(userID uuid.UUID, tokenHash string, clientType user.ClientType, expiresAt time.Time) error {
+	return r.db.update(func(t *tables) error {
+		if _, ok := t.refreshTokens[tokenHash]; ok {
+			return fmt.Errorf("failed to save refresh token: duplicate token")
+		}
+		t.refreshTokens[tokenHash] = refreshToken{
+			userID:     userID,
+			clientType: clientType,
+			expiresAt:  expiresAt,
+			startedAt:  r.db.timestamp(),
+		}
+		return nil
+	})
+}
+
+func (r *UserRepository) GetRefreshToken(tokenHash string) (*user.RefreshSession, error) {
+	var session *user.RefreshSession
+	err := r.db.view(func(t *tables) error {
+		token, ok := t.refreshTokens[tokenHash]
+		if !ok || token.revoked {
+			return fmt.Errorf("invalid or revoked token")
+		}
+		session = &user.RefreshSession{
+			UserID:           token.userID,
+			ClientType:       token.clientType,
+			ExpiresAt:        token.expiresAt,
+			SessionStartedAt: token.startedAt,
+		}
+		return nil
+	})
+	return session, err
+}
+
+func (r *UserRepository) RevokeRefreshToken(tokenHash string) error {
+	return r.db.update(func(t *tables) error {
+		if token, ok := t.refreshTokens[tokenHash]; ok {
+			token.revoked = true
+			t.refreshTokens[tokenHash] = token
+		}
+		return nil
+	})
+}
+
+func (r *UserRepository) RevokeAllRefreshTokens(userID uuid.UUID) error {
+	return r.db.update(func(t *tables) error {
+		for hash, token := range t.refreshTokens {
+			if token.userID == userID {
+				token.revoked = true
+				t.refreshTokens[hash] = token
+			}
+		}
+		return nil
+	})
+}
+
+func (r *UserRepository) GetLoginFamiliarity(userID uuid.UUID, origin user.LoginOrigin) (*user.LoginFamiliarity, error) {
+	f := &user.LoginFamiliarity{FirstLogin: true}
+	err := r.db.view(func(t *tables) error {
+		fingerprint := origin.Fingerprint()
+		for key := range t.logins {
+			if key.userID != userID {
+				continue
+			}
+			f.FirstLogin = false
+			f.KnownDevice = f.KnownDevice || key.fingerprint == fingerprint
+			f.KnownCountry = f.KnownCountry || key.country == origin.Country
+		}
+		return nil
+	})
+	return f, err
+}
+
+func (r *UserRepository) RecordLogin(userID uuid.UUID, origin user.LoginOrigin) error {
+	return r.db.update(func(t *tables) error {
+		t.logins[loginKey{userID: userID, fingerprint: origin.Fingerprint(), country: origin.Country}] = struct{}{}
+		return nil
+	})
+}
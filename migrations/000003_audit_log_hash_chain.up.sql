@@ -0,0 +1,22 @@
+-- Tamper-evident audit trail: every row stores the hash of the previous row
+ALTER TABLE audit_logs ADD COLUMN prev_hash VARCHAR(64);
+ALTER TABLE audit_logs ADD COLUMN hash VARCHAR(64);
+
+-- Single-row table holding the current chain head; locked on every insert
+CREATE TABLE audit_chain_head (
+    id SMALLINT PRIMARY KEY CHECK (id = 1),
+    last_hash VARCHAR(64) NOT NULL DEFAULT '',
+    last_id BIGINT
+);
+INSERT INTO audit_chain_head (id, last_hash) VALUES (1, '');
+
+-- Audit rows are append-only
+CREATE OR REPLACE FUNCTION prevent_audit_log_update()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_logs rows are immutable';
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER audit_logs_immutable BEFORE UPDATE ON audit_logs
+    FOR EACH ROW EXECUTE FUNCTION prevent_audit_log_update();
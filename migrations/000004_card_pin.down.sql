@@ -0,0 +1,3 @@
+ALTER TABLE cards DROP COLUMN IF EXISTS pin_locked_until;
+ALTER TABLE cards DROP COLUMN IF EXISTS pin_failed_attempts;
+ALTER TABLE cards DROP COLUMN IF EXISTS pin_encrypted;
@@ -0,0 +1,3 @@
+ALTER TABLE cards ADD COLUMN pin_encrypted TEXT;
+ALTER TABLE cards ADD COLUMN pin_failed_attempts INT NOT NULL DEFAULT 0;
+ALTER TABLE cards ADD COLUMN pin_locked_until TIMESTAMP;
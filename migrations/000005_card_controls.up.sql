@@ -0,0 +1,2 @@
+ALTER TABLE cards ADD COLUMN controls JSONB NOT NULL
+    DEFAULT '{"ecommerce_enabled": true, "contactless_enabled": true, "atm_enabled": true, "foreign_enabled": true, "blocked_mccs": []}';
@@ -0,0 +1,4 @@
+-- Frozen cards stay unusable after a rollback rather than coming back to life
+UPDATE cards SET status = 'blocked' WHERE status = 'frozen';
+ALTER TABLE cards DROP CONSTRAINT IF EXISTS cards_status_check;
+ALTER TABLE cards ADD CONSTRAINT cards_status_check CHECK (status IN ('active', 'blocked', 'expired'));
@@ -0,0 +1,2 @@
+ALTER TABLE cards DROP CONSTRAINT IF EXISTS cards_status_check;
+ALTER TABLE cards ADD CONSTRAINT cards_status_check CHECK (status IN ('active', 'frozen', 'blocked', 'expired'));
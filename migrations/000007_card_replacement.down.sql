@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_cards_replaces_card_id;
+ALTER TABLE cards DROP COLUMN IF EXISTS replaces_card_id;
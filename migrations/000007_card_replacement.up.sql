@@ -0,0 +1,2 @@
+ALTER TABLE cards ADD COLUMN replaces_card_id UUID REFERENCES cards(id);
+CREATE UNIQUE INDEX idx_cards_replaces_card_id ON cards(replaces_card_id) WHERE replaces_card_id IS NOT NULL;
@@ -0,0 +1,12 @@
+DELETE FROM transactions WHERE transaction_type = 'card_repayment';
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_transaction_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_transaction_type_check
+    CHECK (transaction_type IN ('transfer', 'deposit', 'withdrawal', 'interest', 'fee'));
+
+DROP TABLE IF EXISTS card_statements;
+
+ALTER TABLE cards DROP COLUMN IF EXISTS cycle_payments;
+ALTER TABLE cards DROP COLUMN IF EXISTS cycle_purchases;
+ALTER TABLE cards DROP COLUMN IF EXISTS statement_day;
+ALTER TABLE cards DROP COLUMN IF EXISTS outstanding_balance;
+ALTER TABLE cards DROP COLUMN IF EXISTS credit_limit;
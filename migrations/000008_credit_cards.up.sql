@@ -0,0 +1,28 @@
+ALTER TABLE cards ADD COLUMN credit_limit DECIMAL(15, 2) NOT NULL DEFAULT 0 CHECK (credit_limit >= 0);
+ALTER TABLE cards ADD COLUMN outstanding_balance DECIMAL(15, 2) NOT NULL DEFAULT 0 CHECK (outstanding_balance >= 0);
+ALTER TABLE cards ADD COLUMN statement_day INTEGER NOT NULL DEFAULT 1 CHECK (statement_day BETWEEN 1 AND 28);
+ALTER TABLE cards ADD COLUMN cycle_purchases DECIMAL(15, 2) NOT NULL DEFAULT 0;
+ALTER TABLE cards ADD COLUMN cycle_payments DECIMAL(15, 2) NOT NULL DEFAULT 0;
+
+CREATE TABLE card_statements (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    card_id UUID NOT NULL REFERENCES cards(id),
+    period_start DATE NOT NULL,
+    period_end DATE NOT NULL,
+    opening_balance DECIMAL(15, 2) NOT NULL,
+    purchases DECIMAL(15, 2) NOT NULL,
+    payments DECIMAL(15, 2) NOT NULL,
+    interest DECIMAL(15, 2) NOT NULL,
+    closing_balance DECIMAL(15, 2) NOT NULL,
+    minimum_payment DECIMAL(15, 2) NOT NULL,
+    due_date DATE NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE (card_id, period_end)
+);
+
+CREATE INDEX idx_card_statements_card_id ON card_statements(card_id, period_end DESC);
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_transaction_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_transaction_type_check
+    CHECK (transaction_type IN ('transfer', 'deposit', 'withdrawal', 'interest', 'fee', 'card_repayment'));
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS card_authorizations;
+DROP INDEX IF EXISTS idx_cards_card_number_hash;
+ALTER TABLE cards DROP COLUMN IF EXISTS card_number_hash;
@@ -0,0 +1,24 @@
+-- Keyed digest of the card number so cards can be found by PAN without decrypting
+ALTER TABLE cards ADD COLUMN card_number_hash VARCHAR(64);
+CREATE UNIQUE INDEX idx_cards_card_number_hash ON cards(card_number_hash) WHERE card_number_hash IS NOT NULL;
+
+CREATE TABLE card_authorizations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    card_id UUID NOT NULL REFERENCES cards(id),
+    account_id UUID NOT NULL REFERENCES accounts(id),
+    amount DECIMAL(15, 2) NOT NULL CHECK (amount > 0),
+    currency VARCHAR(3) NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('approved', 'declined', 'captured', 'released')),
+    response_code VARCHAR(2) NOT NULL,
+    decline_reason TEXT,
+    auth_code VARCHAR(6),
+    merchant_name VARCHAR(255),
+    merchant_category_code VARCHAR(4),
+    channel VARCHAR(20) NOT NULL,
+    acquirer_reference VARCHAR(255),
+    expires_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_card_authorizations_card_created ON card_authorizations(card_id, created_at DESC);
+CREATE INDEX idx_card_authorizations_account_status ON card_authorizations(account_id, status);
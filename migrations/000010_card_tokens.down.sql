@@ -0,0 +1,2 @@
+ALTER TABLE card_authorizations DROP COLUMN IF EXISTS token_id;
+DROP TABLE IF EXISTS card_tokens;
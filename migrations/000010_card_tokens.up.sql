@@ -0,0 +1,24 @@
+-- Wallet tokens stand in for the card number on a device so the PAN never leaves the vault
+CREATE TABLE card_tokens (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    card_id UUID NOT NULL REFERENCES cards(id),
+    token_number_encrypted TEXT NOT NULL,
+    token_number_hash VARCHAR(64) NOT NULL UNIQUE,
+    last_four VARCHAR(4) NOT NULL,
+    wallet VARCHAR(20) NOT NULL CHECK (wallet IN ('google_pay', 'apple_pay', 'samsung_pay')),
+    device_id VARCHAR(128) NOT NULL,
+    device_name VARCHAR(100),
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'suspended', 'deleted')),
+    expiry_month INTEGER NOT NULL CHECK (expiry_month >= 1 AND expiry_month <= 12),
+    expiry_year INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- A card can be provisioned to each wallet on a device only once at a time
+CREATE UNIQUE INDEX idx_card_tokens_card_device ON card_tokens(card_id, wallet, device_id) WHERE status <> 'deleted';
+
+CREATE TRIGGER update_card_tokens_updated_at BEFORE UPDATE ON card_tokens
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE card_authorizations ADD COLUMN token_id UUID REFERENCES card_tokens(id);
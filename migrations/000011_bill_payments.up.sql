@@ -0,0 +1,25 @@
+-- Biller catalog for bill payments through the biller aggregator
+CREATE TABLE billers (
+    code VARCHAR(32) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    category VARCHAR(20) NOT NULL CHECK (category IN ('electricity', 'water', 'internet', 'phone', 'tax')),
+    customer_number_label VARCHAR(50) NOT NULL,
+    admin_fee DECIMAL(15, 2) NOT NULL DEFAULT 0 CHECK (admin_fee >= 0),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_billers_category ON billers(category) WHERE active;
+
+INSERT INTO billers (code, name, category, customer_number_label, admin_fee) VALUES
+    ('PLN_POSTPAID', 'PLN Postpaid', 'electricity', 'Customer ID', 2500),
+    ('PDAM_JKT', 'PAM Jaya', 'water', 'Customer Number', 2500),
+    ('INDIHOME', 'IndiHome', 'internet', 'Subscriber Number', 2500),
+    ('BIZNET', 'Biznet Home', 'internet', 'Customer ID', 2500),
+    ('TELKOMSEL_HALO', 'Telkomsel Halo', 'phone', 'Phone Number', 2500),
+    ('PBB_JKT', 'PBB DKI Jakarta', 'tax', 'Tax Object Number (NOP)', 0),
+    ('DJP_MPN', 'Tax Payment (MPN)', 'tax', 'Billing Code', 0);
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_transaction_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_transaction_type_check
+    CHECK (transaction_type IN ('transfer', 'deposit', 'withdrawal', 'interest', 'fee', 'card_repayment', 'bill_payment'));
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS topups;
+
+DELETE FROM transactions WHERE transaction_type = 'topup';
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_transaction_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_transaction_type_check
+    CHECK (transaction_type IN ('transfer', 'deposit', 'withdrawal', 'interest', 'fee', 'card_repayment', 'bill_payment'));
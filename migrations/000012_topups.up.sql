@@ -0,0 +1,28 @@
+-- Phone credit and e-wallet top-ups fulfilled asynchronously by the top-up aggregator
+CREATE TABLE topups (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id),
+    account_id UUID NOT NULL REFERENCES accounts(id),
+    transaction_id UUID NOT NULL UNIQUE REFERENCES transactions(id),
+    topup_type VARCHAR(20) NOT NULL CHECK (topup_type IN ('phone_credit', 'ewallet')),
+    provider VARCHAR(20) NOT NULL,
+    destination VARCHAR(20) NOT NULL,
+    amount DECIMAL(15, 2) NOT NULL CHECK (amount > 0),
+    admin_fee DECIMAL(15, 2) NOT NULL DEFAULT 0 CHECK (admin_fee >= 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'processing', 'success', 'failed')),
+    reference VARCHAR(100),
+    failure_reason VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP
+);
+
+CREATE INDEX idx_topups_user_id ON topups(user_id, created_at DESC);
+CREATE INDEX idx_topups_unfinished ON topups(updated_at) WHERE status IN ('pending', 'processing');
+
+CREATE TRIGGER update_topups_updated_at BEFORE UPDATE ON topups
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_transaction_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_transaction_type_check
+    CHECK (transaction_type IN ('transfer', 'deposit', 'withdrawal', 'interest', 'fee', 'card_repayment', 'bill_payment', 'topup'));
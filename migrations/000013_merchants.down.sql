@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS payment_links;
+DROP TABLE IF EXISTS merchant_settlements;
+DROP TABLE IF EXISTS merchants;
+
+DELETE FROM transactions WHERE transaction_type IN ('merchant_payment', 'merchant_settlement');
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_transaction_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_transaction_type_check
+    CHECK (transaction_type IN ('transfer', 'deposit', 'withdrawal', 'interest', 'fee', 'card_repayment', 'bill_payment', 'topup'));
@@ -0,0 +1,60 @@
+-- Merchants collect payments through payment links and are settled daily
+CREATE TABLE merchants (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    owner_id UUID NOT NULL REFERENCES users(id),
+    name VARCHAR(100) NOT NULL,
+    category VARCHAR(30) NOT NULL CHECK (category IN ('retail', 'food_beverage', 'services', 'online', 'other')),
+    settlement_account_id UUID NOT NULL REFERENCES accounts(id),
+    webhook_url VARCHAR(255),
+    webhook_secret VARCHAR(64) NOT NULL,
+    api_key_hint VARCHAR(20) NOT NULL,
+    api_key_hash VARCHAR(64) NOT NULL UNIQUE,
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'suspended')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_merchants_owner_id ON merchants(owner_id);
+
+CREATE TRIGGER update_merchants_updated_at BEFORE UPDATE ON merchants
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE merchant_settlements (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    merchant_id UUID NOT NULL REFERENCES merchants(id),
+    account_id UUID NOT NULL REFERENCES accounts(id),
+    business_date DATE NOT NULL,
+    payment_count INTEGER NOT NULL CHECK (payment_count > 0),
+    gross_amount DECIMAL(15, 2) NOT NULL,
+    fee_amount DECIMAL(15, 2) NOT NULL,
+    net_amount DECIMAL(15, 2) NOT NULL,
+    transaction_id UUID NOT NULL UNIQUE REFERENCES transactions(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (merchant_id, business_date)
+);
+
+CREATE TABLE payment_links (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    merchant_id UUID NOT NULL REFERENCES merchants(id),
+    reference VARCHAR(64) NOT NULL,
+    amount DECIMAL(15, 2) NOT NULL CHECK (amount > 0),
+    description VARCHAR(255),
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'paid', 'cancelled')),
+    expires_at TIMESTAMP NOT NULL,
+    paid_at TIMESTAMP,
+    payer_account_id UUID REFERENCES accounts(id),
+    transaction_id UUID UNIQUE REFERENCES transactions(id),
+    settlement_id UUID REFERENCES merchant_settlements(id),
+    notified_at TIMESTAMP,
+    notify_attempts INTEGER NOT NULL DEFAULT 0,
+    next_notify_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (merchant_id, reference)
+);
+
+CREATE INDEX idx_payment_links_unsettled ON payment_links(merchant_id, paid_at) WHERE status = 'paid' AND settlement_id IS NULL;
+CREATE INDEX idx_payment_links_notify ON payment_links(next_notify_at) WHERE notified_at IS NULL AND next_notify_at IS NOT NULL;
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_transaction_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_transaction_type_check
+    CHECK (transaction_type IN ('transfer', 'deposit', 'withdrawal', 'interest', 'fee', 'card_repayment', 'bill_payment', 'topup', 'merchant_payment', 'merchant_settlement'));
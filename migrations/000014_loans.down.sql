@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS loan_installments;
+DROP TABLE IF EXISTS loans;
+DROP TABLE IF EXISTS loan_products;
+
+DELETE FROM transactions WHERE transaction_type IN ('loan_disbursement', 'loan_repayment');
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_transaction_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_transaction_type_check
+    CHECK (transaction_type IN ('transfer', 'deposit', 'withdrawal', 'interest', 'fee', 'card_repayment', 'bill_payment', 'topup', 'merchant_payment', 'merchant_settlement'));
@@ -0,0 +1,72 @@
+-- Personal loans: product catalog, applications and amortization schedules
+CREATE TABLE loan_products (
+    code VARCHAR(30) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    min_amount DECIMAL(15, 2) NOT NULL CHECK (min_amount > 0),
+    max_amount DECIMAL(15, 2) NOT NULL,
+    min_tenor_months INTEGER NOT NULL CHECK (min_tenor_months > 0),
+    max_tenor_months INTEGER NOT NULL,
+    annual_rate DECIMAL(5, 4) NOT NULL CHECK (annual_rate >= 0),
+    provision_fee_rate DECIMAL(5, 4) NOT NULL DEFAULT 0 CHECK (provision_fee_rate >= 0),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (max_amount >= min_amount),
+    CHECK (max_tenor_months >= min_tenor_months)
+);
+
+INSERT INTO loan_products (code, name, min_amount, max_amount, min_tenor_months, max_tenor_months, annual_rate, provision_fee_rate) VALUES
+    ('PERSONAL', 'Personal Loan', 5000000, 100000000, 6, 36, 0.1400, 0.0100),
+    ('PERSONAL_PLUS', 'Personal Loan Plus', 50000000, 500000000, 12, 60, 0.1100, 0.0100),
+    ('CASH_ADVANCE', 'Cash Advance', 1000000, 10000000, 1, 6, 0.1800, 0.0200);
+
+CREATE TABLE loans (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id),
+    product_code VARCHAR(30) NOT NULL REFERENCES loan_products(code),
+    account_id UUID NOT NULL REFERENCES accounts(id),
+    principal DECIMAL(15, 2) NOT NULL CHECK (principal > 0),
+    tenor_months INTEGER NOT NULL CHECK (tenor_months > 0),
+    annual_rate DECIMAL(5, 4) NOT NULL,
+    monthly_installment DECIMAL(15, 2) NOT NULL,
+    provision_fee DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    outstanding_principal DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    purpose VARCHAR(255),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'rejected', 'cancelled', 'active', 'paid_off')),
+    decision_reason VARCHAR(255),
+    decided_by UUID REFERENCES users(id),
+    disbursement_transaction_id UUID UNIQUE REFERENCES transactions(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    decided_at TIMESTAMP,
+    disbursed_at TIMESTAMP,
+    closed_at TIMESTAMP
+);
+
+CREATE INDEX idx_loans_user_id ON loans(user_id, created_at DESC);
+CREATE INDEX idx_loans_status ON loans(status, created_at);
+
+CREATE TRIGGER update_loans_updated_at BEFORE UPDATE ON loans
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE loan_installments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    loan_id UUID NOT NULL REFERENCES loans(id) ON DELETE CASCADE,
+    number INTEGER NOT NULL CHECK (number > 0),
+    due_date DATE NOT NULL,
+    principal DECIMAL(15, 2) NOT NULL,
+    interest DECIMAL(15, 2) NOT NULL,
+    amount DECIMAL(15, 2) NOT NULL CHECK (amount > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'paid')),
+    debit_attempts INTEGER NOT NULL DEFAULT 0,
+    last_attempt_date DATE,
+    paid_at TIMESTAMP,
+    transaction_id UUID UNIQUE REFERENCES transactions(id),
+    UNIQUE (loan_id, number)
+);
+
+CREATE INDEX idx_loan_installments_due ON loan_installments(due_date) WHERE status = 'pending';
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_transaction_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_transaction_type_check
+    CHECK (transaction_type IN ('transfer', 'deposit', 'withdrawal', 'interest', 'fee', 'card_repayment', 'bill_payment', 'topup',
+                                'merchant_payment', 'merchant_settlement', 'loan_disbursement', 'loan_repayment'));
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS reconciliation_exceptions;
+DROP TABLE IF EXISTS reconciliation_runs;
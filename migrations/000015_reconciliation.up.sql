@@ -0,0 +1,46 @@
+-- Reconciliation runs compare the ledger with stored balances (nightly) or with
+-- a counterparty settlement file (on upload); breaks become exceptions
+CREATE TABLE reconciliation_runs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('ledger', 'settlement_file')),
+    source VARCHAR(30) NOT NULL,
+    business_date DATE NOT NULL,
+    file_name VARCHAR(255),
+    items_checked INTEGER NOT NULL DEFAULT 0,
+    breaks_found INTEGER NOT NULL DEFAULT 0,
+    started_by UUID REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_reconciliation_runs_kind_date ON reconciliation_runs(kind, business_date);
+
+CREATE TABLE reconciliation_exceptions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    run_id UUID NOT NULL REFERENCES reconciliation_runs(id),
+    exception_type VARCHAR(30) NOT NULL CHECK (exception_type IN
+        ('balance_mismatch', 'missing_in_ledger', 'missing_in_file', 'amount_mismatch', 'status_mismatch')),
+    source VARCHAR(30) NOT NULL,
+    reference VARCHAR(64) NOT NULL,
+    account_id UUID REFERENCES accounts(id),
+    expected_amount DECIMAL(15, 2),
+    actual_amount DECIMAL(15, 2),
+    details VARCHAR(255),
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'investigating', 'resolved', 'dismissed')),
+    assigned_to UUID REFERENCES users(id),
+    resolution_note VARCHAR(500),
+    resolved_by UUID REFERENCES users(id),
+    resolved_at TIMESTAMP,
+    detected_count INTEGER NOT NULL DEFAULT 1,
+    last_detected_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- A break that is still being worked on is updated on re-detection rather than duplicated
+CREATE UNIQUE INDEX idx_reconciliation_exceptions_open
+    ON reconciliation_exceptions(exception_type, source, reference)
+    WHERE status IN ('open', 'investigating');
+CREATE INDEX idx_reconciliation_exceptions_status ON reconciliation_exceptions(status, created_at);
+
+CREATE TRIGGER update_reconciliation_exceptions_updated_at BEFORE UPDATE ON reconciliation_exceptions
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS regulatory_reports;
+DROP TABLE IF EXISTS transaction_flags;
@@ -0,0 +1,29 @@
+-- Transactions flagged as suspicious, by fraud screening or a compliance officer,
+-- are listed in the suspicious transaction report for the day they were flagged
+CREATE TABLE transaction_flags (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    transaction_id UUID NOT NULL REFERENCES transactions(id),
+    source VARCHAR(20) NOT NULL CHECK (source IN ('fraud_rule', 'manual')),
+    reason VARCHAR(500) NOT NULL,
+    flagged_by UUID REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_transaction_flags_created_at ON transaction_flags(created_at);
+CREATE INDEX idx_transaction_flags_transaction_id ON transaction_flags(transaction_id);
+
+-- Generated report files for the regulator
+CREATE TABLE regulatory_reports (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    report_type VARCHAR(30) NOT NULL CHECK (report_type IN ('large_transaction', 'suspicious_transaction')),
+    period_start DATE NOT NULL,
+    period_end DATE NOT NULL,
+    item_count INTEGER NOT NULL DEFAULT 0,
+    total_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    file_name VARCHAR(255) NOT NULL,
+    content BYTEA NOT NULL,
+    generated_by UUID REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_regulatory_reports_type_period ON regulatory_reports(report_type, period_start);
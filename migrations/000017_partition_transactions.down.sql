@@ -0,0 +1,66 @@
+-- Archived months are not restored; rows referencing them must be cleared or
+-- the months re-imported before rolling back
+DROP TABLE IF EXISTS transaction_archive_balances;
+DROP TABLE IF EXISTS transaction_archives;
+
+DROP TRIGGER IF EXISTS register_transaction_key ON transactions;
+DROP FUNCTION IF EXISTS register_transaction_key();
+
+ALTER TABLE transactions RENAME TO transactions_partitioned;
+ALTER TABLE transactions_partitioned RENAME CONSTRAINT transactions_pkey TO transactions_partitioned_pkey;
+DROP INDEX idx_transactions_from_account;
+DROP INDEX idx_transactions_to_account;
+DROP INDEX idx_transactions_created_at;
+DROP INDEX idx_transactions_idempotency;
+DROP INDEX idx_transactions_status;
+
+CREATE TABLE transactions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    idempotency_key VARCHAR(255) UNIQUE NOT NULL,
+    from_account_id UUID REFERENCES accounts(id),
+    to_account_id UUID REFERENCES accounts(id),
+    amount DECIMAL(15, 2) NOT NULL CHECK (amount > 0),
+    transaction_type VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'completed', 'failed', 'reversed')),
+    description TEXT,
+    metadata JSONB,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP,
+
+    CONSTRAINT transactions_transaction_type_check
+        CHECK (transaction_type IN ('transfer', 'deposit', 'withdrawal', 'interest', 'fee', 'card_repayment', 'bill_payment', 'topup',
+                                    'merchant_payment', 'merchant_settlement', 'loan_disbursement', 'loan_repayment')),
+    CONSTRAINT valid_accounts CHECK (
+        from_account_id IS NOT NULL OR to_account_id IS NOT NULL
+    )
+);
+
+INSERT INTO transactions (id, idempotency_key, from_account_id, to_account_id, amount, transaction_type,
+                          status, description, metadata, created_at, completed_at)
+SELECT id, idempotency_key, from_account_id, to_account_id, amount, transaction_type,
+       status, description, metadata, created_at, completed_at
+FROM transactions_partitioned;
+
+DROP TABLE transactions_partitioned;
+DROP FUNCTION IF EXISTS create_transaction_partition(DATE);
+
+CREATE INDEX idx_transactions_from_account ON transactions(from_account_id);
+CREATE INDEX idx_transactions_to_account ON transactions(to_account_id);
+CREATE INDEX idx_transactions_created_at ON transactions(created_at DESC);
+CREATE INDEX idx_transactions_idempotency ON transactions(idempotency_key);
+CREATE INDEX idx_transactions_status ON transactions(status);
+
+ALTER TABLE topups DROP CONSTRAINT topups_transaction_id_fkey,
+    ADD CONSTRAINT topups_transaction_id_fkey FOREIGN KEY (transaction_id) REFERENCES transactions(id);
+ALTER TABLE merchant_settlements DROP CONSTRAINT merchant_settlements_transaction_id_fkey,
+    ADD CONSTRAINT merchant_settlements_transaction_id_fkey FOREIGN KEY (transaction_id) REFERENCES transactions(id);
+ALTER TABLE payment_links DROP CONSTRAINT payment_links_transaction_id_fkey,
+    ADD CONSTRAINT payment_links_transaction_id_fkey FOREIGN KEY (transaction_id) REFERENCES transactions(id);
+ALTER TABLE loans DROP CONSTRAINT loans_disbursement_transaction_id_fkey,
+    ADD CONSTRAINT loans_disbursement_transaction_id_fkey FOREIGN KEY (disbursement_transaction_id) REFERENCES transactions(id);
+ALTER TABLE loan_installments DROP CONSTRAINT loan_installments_transaction_id_fkey,
+    ADD CONSTRAINT loan_installments_transaction_id_fkey FOREIGN KEY (transaction_id) REFERENCES transactions(id);
+ALTER TABLE transaction_flags DROP CONSTRAINT transaction_flags_transaction_id_fkey,
+    ADD CONSTRAINT transaction_flags_transaction_id_fkey FOREIGN KEY (transaction_id) REFERENCES transactions(id);
+
+DROP TABLE IF EXISTS transaction_keys;
@@ -0,0 +1,135 @@
+-- Partition transactions by month of created_at so old months can be archived
+-- by detaching whole partitions.
+--
+-- A partitioned table can only enforce uniqueness on keys that include the
+-- partition column, so transaction IDs and idempotency keys are registered in
+-- transaction_keys. It also outlives archived partitions, so other tables
+-- reference it instead of transactions.
+CREATE TABLE transaction_keys (
+    id UUID PRIMARY KEY,
+    idempotency_key VARCHAR(255) UNIQUE NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+INSERT INTO transaction_keys (id, idempotency_key, created_at)
+SELECT id, idempotency_key, COALESCE(created_at, CURRENT_TIMESTAMP) FROM transactions;
+
+ALTER TABLE topups DROP CONSTRAINT topups_transaction_id_fkey,
+    ADD CONSTRAINT topups_transaction_id_fkey FOREIGN KEY (transaction_id) REFERENCES transaction_keys(id);
+ALTER TABLE merchant_settlements DROP CONSTRAINT merchant_settlements_transaction_id_fkey,
+    ADD CONSTRAINT merchant_settlements_transaction_id_fkey FOREIGN KEY (transaction_id) REFERENCES transaction_keys(id);
+ALTER TABLE payment_links DROP CONSTRAINT payment_links_transaction_id_fkey,
+    ADD CONSTRAINT payment_links_transaction_id_fkey FOREIGN KEY (transaction_id) REFERENCES transaction_keys(id);
+ALTER TABLE loans DROP CONSTRAINT loans_disbursement_transaction_id_fkey,
+    ADD CONSTRAINT loans_disbursement_transaction_id_fkey FOREIGN KEY (disbursement_transaction_id) REFERENCES transaction_keys(id);
+ALTER TABLE loan_installments DROP CONSTRAINT loan_installments_transaction_id_fkey,
+    ADD CONSTRAINT loan_installments_transaction_id_fkey FOREIGN KEY (transaction_id) REFERENCES transaction_keys(id);
+ALTER TABLE transaction_flags DROP CONSTRAINT transaction_flags_transaction_id_fkey,
+    ADD CONSTRAINT transaction_flags_transaction_id_fkey FOREIGN KEY (transaction_id) REFERENCES transaction_keys(id);
+
+ALTER TABLE transactions RENAME TO transactions_unpartitioned;
+ALTER TABLE transactions_unpartitioned RENAME CONSTRAINT transactions_pkey TO transactions_unpartitioned_pkey;
+DROP INDEX idx_transactions_from_account;
+DROP INDEX idx_transactions_to_account;
+DROP INDEX idx_transactions_created_at;
+DROP INDEX idx_transactions_idempotency;
+DROP INDEX idx_transactions_status;
+
+CREATE TABLE transactions (
+    id UUID NOT NULL DEFAULT uuid_generate_v4(),
+    idempotency_key VARCHAR(255) NOT NULL,
+    from_account_id UUID REFERENCES accounts(id),
+    to_account_id UUID REFERENCES accounts(id),
+    amount DECIMAL(15, 2) NOT NULL CHECK (amount > 0),
+    transaction_type VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'completed', 'failed', 'reversed')),
+    description TEXT,
+    metadata JSONB,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP,
+
+    CONSTRAINT transactions_pkey PRIMARY KEY (id, created_at),
+    CONSTRAINT transactions_transaction_type_check
+        CHECK (transaction_type IN ('transfer', 'deposit', 'withdrawal', 'interest', 'fee', 'card_repayment', 'bill_payment', 'topup',
+                                    'merchant_payment', 'merchant_settlement', 'loan_disbursement', 'loan_repayment')),
+    CONSTRAINT valid_accounts CHECK (
+        from_account_id IS NOT NULL OR to_account_id IS NOT NULL
+    )
+) PARTITION BY RANGE (created_at);
+
+-- Rows outside every monthly partition land here; the archival job creates
+-- partitions ahead of time so it stays empty
+CREATE TABLE transactions_default PARTITION OF transactions DEFAULT;
+
+-- create_transaction_partition creates the partition for the month containing
+-- the given date and returns its name
+CREATE OR REPLACE FUNCTION create_transaction_partition(month_date DATE)
+RETURNS TEXT AS $$
+DECLARE
+    start_date DATE := date_trunc('month', month_date)::DATE;
+    partition_name TEXT := 'transactions_p' || to_char(start_date, 'YYYYMM');
+BEGIN
+    EXECUTE format(
+        'CREATE TABLE IF NOT EXISTS %I PARTITION OF transactions FOR VALUES FROM (%L) TO (%L)',
+        partition_name, start_date, (start_date + INTERVAL '1 month')::DATE
+    );
+    RETURN partition_name;
+END;
+$$ LANGUAGE plpgsql;
+
+DO $$
+DECLARE
+    m DATE := date_trunc('month', COALESCE((SELECT MIN(created_at) FROM transactions_unpartitioned), CURRENT_TIMESTAMP))::DATE;
+BEGIN
+    WHILE m <= (date_trunc('month', CURRENT_TIMESTAMP) + INTERVAL '3 months')::DATE LOOP
+        PERFORM create_transaction_partition(m);
+        m := (m + INTERVAL '1 month')::DATE;
+    END LOOP;
+END $$;
+
+INSERT INTO transactions (id, idempotency_key, from_account_id, to_account_id, amount, transaction_type,
+                          status, description, metadata, created_at, completed_at)
+SELECT id, idempotency_key, from_account_id, to_account_id, amount, transaction_type,
+       status, description, metadata, COALESCE(created_at, CURRENT_TIMESTAMP), completed_at
+FROM transactions_unpartitioned;
+
+DROP TABLE transactions_unpartitioned;
+
+CREATE INDEX idx_transactions_from_account ON transactions(from_account_id);
+CREATE INDEX idx_transactions_to_account ON transactions(to_account_id);
+CREATE INDEX idx_transactions_created_at ON transactions(created_at DESC);
+CREATE INDEX idx_transactions_idempotency ON transactions(idempotency_key);
+CREATE INDEX idx_transactions_status ON transactions(status);
+
+CREATE OR REPLACE FUNCTION register_transaction_key()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO transaction_keys (id, idempotency_key, created_at)
+    VALUES (NEW.id, NEW.idempotency_key, NEW.created_at);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER register_transaction_key BEFORE INSERT ON transactions
+    FOR EACH ROW EXECUTE FUNCTION register_transaction_key();
+
+-- Months moved to cold storage by the archival job
+CREATE TABLE transaction_archives (
+    partition_month DATE PRIMARY KEY,
+    object_key VARCHAR(255) NOT NULL,
+    storage VARCHAR(20) NOT NULL,
+    row_count INTEGER NOT NULL,
+    archived_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Each account's movements in an archived month, so ledger balances still add
+-- up once the month's transactions are gone
+CREATE TABLE transaction_archive_balances (
+    partition_month DATE NOT NULL REFERENCES transaction_archives(partition_month),
+    account_id UUID NOT NULL REFERENCES accounts(id),
+    credits DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    debits DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    PRIMARY KEY (partition_month, account_id)
+);
+
+CREATE INDEX idx_transaction_archive_balances_account ON transaction_archive_balances(account_id);
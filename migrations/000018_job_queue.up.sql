@@ -0,0 +1,24 @@
+-- Persistent queue for background work. Workers claim due jobs with
+-- FOR UPDATE SKIP LOCKED, so any number of API replicas can share the queue.
+-- Jobs that exhaust their attempts stay in the table as 'dead' (the
+-- dead-letter queue) until an operator retries them.
+CREATE TABLE queued_jobs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    kind VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'completed', 'dead')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 5,
+    run_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    -- Enqueueing a second job with the same key is a no-op
+    unique_key VARCHAR(255) UNIQUE,
+    last_error TEXT,
+    locked_by VARCHAR(100),
+    locked_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_queued_jobs_due ON queued_jobs(run_at) WHERE status = 'pending';
+CREATE INDEX idx_queued_jobs_status ON queued_jobs(status, updated_at);
@@ -0,0 +1,17 @@
+-- Daily interest earned by savings accounts. Accruals keep six decimal places
+-- and are credited to the account as one interest transaction per month.
+CREATE TABLE interest_accruals (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    account_id UUID NOT NULL REFERENCES accounts(id),
+    accrual_date DATE NOT NULL,
+    balance DECIMAL(15, 2) NOT NULL,
+    annual_rate DECIMAL(5, 4) NOT NULL,
+    amount DECIMAL(20, 6) NOT NULL CHECK (amount >= 0),
+    -- Deferred so the posting transaction can claim its accruals before the
+    -- transaction row exists
+    posted_transaction_id UUID REFERENCES transaction_keys(id) DEFERRABLE INITIALLY DEFERRED,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (account_id, accrual_date)
+);
+
+CREATE INDEX idx_interest_accruals_unposted ON interest_accruals(accrual_date) WHERE posted_transaction_id IS NULL;
@@ -0,0 +1,22 @@
+-- Multi-leg operations (internal posting, external rail, completion) run as
+-- sagas. The orchestrator saves progress after every step, so a saga whose
+-- runner crashed is picked up again once its lock expires and either carries
+-- on or compensates the steps already done.
+CREATE TABLE sagas (
+    id UUID PRIMARY KEY,
+    kind VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('running', 'completed', 'compensating', 'compensated', 'failed')),
+    data JSONB NOT NULL DEFAULT '{}',
+    -- Index of the next step to run, or while compensating, one past the next
+    -- step to undo
+    step INTEGER NOT NULL DEFAULT 0,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    locked_until TIMESTAMP,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_sagas_unfinished ON sagas(next_attempt_at) WHERE status IN ('running', 'compensating');
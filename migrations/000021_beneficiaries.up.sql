@@ -0,0 +1,31 @@
+-- Saved transfer recipients. Internal accounts and phone numbers are resolved
+-- to an account on verification; external bank recipients stay unverified
+-- until an interbank name inquiry is available.
+CREATE TABLE beneficiaries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id),
+    beneficiary_type VARCHAR(20) NOT NULL CHECK (beneficiary_type IN ('internal_account', 'phone', 'external_bank')),
+    nickname VARCHAR(50) NOT NULL,
+    account_number VARCHAR(34),
+    phone_number VARCHAR(20),
+    bank_code VARCHAR(20),
+    holder_name VARCHAR(200) NOT NULL DEFAULT '',
+    account_id UUID REFERENCES accounts(id),
+    verification_status VARCHAR(20) NOT NULL DEFAULT 'unverified'
+        CHECK (verification_status IN ('unverified', 'verified', 'failed')),
+    verified_at TIMESTAMP,
+    last_used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- A recipient is saved once per user
+CREATE UNIQUE INDEX idx_beneficiaries_destination ON beneficiaries(
+    user_id, beneficiary_type, COALESCE(bank_code, ''), COALESCE(account_number, ''), COALESCE(phone_number, '')
+);
+
+-- Transfers look up whether the destination account is a saved recipient
+CREATE INDEX idx_beneficiaries_user_account ON beneficiaries(user_id, account_id) WHERE account_id IS NOT NULL;
+
+CREATE TRIGGER update_beneficiaries_updated_at BEFORE UPDATE ON beneficiaries
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
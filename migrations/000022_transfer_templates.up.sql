@@ -0,0 +1,21 @@
+-- Saved transfers the user can repeat with one call. A template goes either
+-- to an account or to a saved beneficiary, and is removed with it.
+CREATE TABLE transfer_templates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id),
+    name VARCHAR(50) NOT NULL,
+    from_account_id UUID NOT NULL REFERENCES accounts(id),
+    to_account_id UUID REFERENCES accounts(id),
+    beneficiary_id UUID REFERENCES beneficiaries(id) ON DELETE CASCADE,
+    amount DECIMAL(15, 2) NOT NULL CHECK (amount > 0),
+    description TEXT NOT NULL DEFAULT '',
+    last_used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((to_account_id IS NULL) <> (beneficiary_id IS NULL))
+);
+
+CREATE INDEX idx_transfer_templates_user_id ON transfer_templates(user_id);
+
+CREATE TRIGGER update_transfer_templates_updated_at BEFORE UPDATE ON transfer_templates
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS statement_deliveries;
+DROP TABLE IF EXISTS statement_subscriptions;
@@ -0,0 +1,34 @@
+-- Accounts opted in to monthly statement emails. An empty email sends to the
+-- address on the user's profile.
+CREATE TABLE statement_subscriptions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id),
+    account_id UUID NOT NULL UNIQUE REFERENCES accounts(id),
+    email VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_statement_subscriptions_updated_at BEFORE UPDATE ON statement_subscriptions
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- One row per account and month, tracking the emailed statement
+CREATE TABLE statement_deliveries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id),
+    account_id UUID NOT NULL REFERENCES accounts(id),
+    period CHAR(7) NOT NULL,
+    email VARCHAR(255),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    sent_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (account_id, period)
+);
+
+CREATE INDEX idx_statement_deliveries_period_status ON statement_deliveries(period, status);
+
+CREATE TRIGGER update_statement_deliveries_updated_at BEFORE UPDATE ON statement_deliveries
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
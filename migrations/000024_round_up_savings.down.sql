@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS round_up_rules;
+
+DELETE FROM transactions WHERE transaction_type = 'round_up';
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_transaction_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_transaction_type_check
+    CHECK (transaction_type IN ('transfer', 'deposit', 'withdrawal', 'interest', 'fee', 'card_repayment', 'bill_payment', 'topup',
+                                'merchant_payment', 'merchant_settlement', 'loan_disbursement', 'loan_repayment'));
@@ -0,0 +1,19 @@
+-- Opt-in round-up savings: every debit of the account is rounded up to the
+-- next 1,000 and the difference moved to the chosen savings account
+CREATE TABLE round_up_rules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id),
+    account_id UUID NOT NULL UNIQUE REFERENCES accounts(id),
+    savings_account_id UUID NOT NULL REFERENCES accounts(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (account_id <> savings_account_id)
+);
+
+CREATE TRIGGER update_round_up_rules_updated_at BEFORE UPDATE ON round_up_rules
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_transaction_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_transaction_type_check
+    CHECK (transaction_type IN ('transfer', 'deposit', 'withdrawal', 'interest', 'fee', 'card_repayment', 'bill_payment', 'topup',
+                                'merchant_payment', 'merchant_settlement', 'loan_disbursement', 'loan_repayment', 'round_up'));
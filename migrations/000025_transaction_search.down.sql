@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_transactions_description_trgm;
+DROP INDEX IF EXISTS idx_transactions_search;
+DROP EXTENSION IF EXISTS pg_trgm;
@@ -0,0 +1,10 @@
+-- Search over transaction history: full-text over the description and the
+-- string values of the metadata, trigram matching for substrings and typos
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_transactions_search ON transactions USING GIN (
+    (to_tsvector('simple', COALESCE(description, '')) ||
+     jsonb_to_tsvector('simple', COALESCE(metadata, '{}'::jsonb), '["string"]'))
+);
+
+CREATE INDEX idx_transactions_description_trgm ON transactions USING GIN (description gin_trgm_ops);
@@ -0,0 +1,6 @@
+-- Soft-deleted users have their personal data overwritten once the retention
+-- period ends. The row stays so accounts and transactions keep their owner.
+ALTER TABLE users ADD COLUMN anonymized_at TIMESTAMP;
+
+CREATE INDEX idx_users_pending_anonymization ON users(deleted_at)
+    WHERE deleted_at IS NOT NULL AND anonymized_at IS NULL;
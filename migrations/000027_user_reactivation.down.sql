@@ -0,0 +1,2 @@
+UPDATE accounts SET status = 'active' WHERE frozen_for_deletion;
+ALTER TABLE accounts DROP COLUMN IF EXISTS frozen_for_deletion;
@@ -0,0 +1,7 @@
+-- Accounts frozen because their owner deleted their profile. Reactivating the
+-- profile unfreezes exactly these, not accounts the user froze themselves.
+ALTER TABLE accounts ADD COLUMN frozen_for_deletion BOOLEAN NOT NULL DEFAULT false;
+
+UPDATE accounts a SET status = 'frozen', frozen_for_deletion = true
+FROM users u
+WHERE u.id = a.user_id AND u.deleted_at IS NOT NULL AND a.status = 'active';
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_users_verified_phone;
+ALTER TABLE users DROP COLUMN IF EXISTS phone_verified_at;
@@ -0,0 +1,6 @@
+-- Phone numbers are only trusted for login and payments once confirmed by SMS.
+-- Numbers on file before this migration start out unverified.
+ALTER TABLE users ADD COLUMN phone_verified_at TIMESTAMP;
+
+CREATE UNIQUE INDEX idx_users_verified_phone ON users(phone)
+    WHERE phone_verified_at IS NOT NULL AND deleted_at IS NULL;
@@ -0,0 +1,4 @@
+-- Optional public handle for login and @handle payments. Stored lowercase.
+ALTER TABLE users ADD COLUMN username VARCHAR(30);
+
+CREATE UNIQUE INDEX idx_users_username ON users(username) WHERE username IS NOT NULL;
@@ -0,0 +1,15 @@
+-- Devices and countries each user has logged in from, so a login from a new
+-- one can be announced and confirmed with a code
+CREATE TABLE user_login_history (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id),
+    device_fingerprint CHAR(64) NOT NULL,
+    -- ISO 3166-1 alpha-2, empty when GeoIP is off or the country is unknown
+    country VARCHAR(2) NOT NULL DEFAULT '',
+    ip_address VARCHAR(45),
+    user_agent TEXT,
+    login_count INTEGER NOT NULL DEFAULT 1,
+    first_seen_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, device_fingerprint, country)
+);
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS users_cache_invalidation ON users;
+DROP TRIGGER IF EXISTS accounts_cache_invalidation ON accounts;
+DROP FUNCTION IF EXISTS notify_cache_invalidation();
@@ -0,0 +1,17 @@
+-- Announce changes to cached rows so every API replica drops its copy, also
+-- when the change is made outside the caching repositories (balance moves in
+-- transfers, payments, interest runs...). Notifications are delivered on
+-- commit only, and only once per distinct payload within a transaction.
+CREATE OR REPLACE FUNCTION notify_cache_invalidation()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify('cache_invalidation', TG_TABLE_NAME || ':' || OLD.id);
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER accounts_cache_invalidation AFTER UPDATE OR DELETE ON accounts
+    FOR EACH ROW EXECUTE FUNCTION notify_cache_invalidation();
+
+CREATE TRIGGER users_cache_invalidation AFTER UPDATE OR DELETE ON users
+    FOR EACH ROW EXECUTE FUNCTION notify_cache_invalidation();
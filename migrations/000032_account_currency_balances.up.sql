@@ -0,0 +1,14 @@
+-- The other currencies an account holds. Its own currency (accounts.currency)
+-- keeps its balance in accounts.balance; every further currency gets a row
+-- here once the customer opens it.
+CREATE TABLE account_balances (
+    account_id UUID NOT NULL REFERENCES accounts(id),
+    currency VARCHAR(3) NOT NULL,
+    balance DECIMAL(15, 2) NOT NULL DEFAULT 0.00 CHECK (balance >= 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (account_id, currency)
+);
+
+CREATE TRIGGER update_account_balances_updated_at BEFORE UPDATE ON account_balances
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
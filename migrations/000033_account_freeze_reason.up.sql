@@ -0,0 +1,3 @@
+-- Why a frozen account was frozen. Cleared when it is unfrozen.
+ALTER TABLE accounts ADD COLUMN freeze_reason VARCHAR(20)
+    CHECK (freeze_reason IN ('fraud', 'legal_hold', 'customer_request'));
@@ -0,0 +1,2 @@
+ALTER TABLE interest_accruals ALTER COLUMN annual_rate TYPE DECIMAL(5, 4);
+DROP TABLE IF EXISTS interest_rate_tiers;
@@ -0,0 +1,18 @@
+-- Higher savings rates on the part of a balance above a threshold, per product
+-- (account type and currency). Below the first tier the account's own
+-- interest_rate applies.
+CREATE TABLE interest_rate_tiers (
+    account_type VARCHAR(20) NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    min_balance DECIMAL(15, 2) NOT NULL CHECK (min_balance > 0),
+    annual_rate DECIMAL(5, 4) NOT NULL CHECK (annual_rate >= 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (account_type, currency, min_balance)
+);
+
+INSERT INTO interest_rate_tiers (account_type, currency, min_balance, annual_rate) VALUES
+    ('savings', 'IDR', 10000000, 0.0400),
+    ('savings', 'IDR', 100000000, 0.0450);
+
+-- Accruals record the effective rate across tiers, which needs more places
+ALTER TABLE interest_accruals ALTER COLUMN annual_rate TYPE DECIMAL(8, 6);
@@ -0,0 +1,16 @@
+-- Every attempt to post an event to a merchant's webhook, for the merchant to
+-- debug their receiver
+CREATE TABLE webhook_deliveries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    merchant_id UUID NOT NULL REFERENCES merchants(id),
+    event VARCHAR(50) NOT NULL,
+    url VARCHAR(255) NOT NULL,
+    payment_link_id UUID REFERENCES payment_links(id),
+    status_code INT, -- NULL when the receiver did not answer
+    success BOOLEAN NOT NULL,
+    error TEXT,
+    duration_ms INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_webhook_deliveries_merchant ON webhook_deliveries(merchant_id, created_at DESC);
@@ -0,0 +1,24 @@
+-- refresh_tokens was only ever created by internal/repository/migrations, so
+-- make sure it exists before extending it
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    revoked BOOLEAN DEFAULT FALSE
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens(user_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_token_hash ON refresh_tokens(token_hash);
+
+-- Refresh tokens remember which kind of client logged in, for its token
+-- lifetimes, and when the session began, for the absolute session maximum
+ALTER TABLE refresh_tokens ADD COLUMN client_type VARCHAR(20) NOT NULL DEFAULT 'default'
+    CHECK (client_type IN ('default', 'mobile', 'web', 'partner'));
+ALTER TABLE refresh_tokens ADD COLUMN session_started_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE refresh_tokens SET session_started_at = COALESCE(created_at, CURRENT_TIMESTAMP);
+
+ALTER TABLE refresh_tokens ALTER COLUMN session_started_at SET NOT NULL;
+ALTER TABLE refresh_tokens ALTER COLUMN session_started_at SET DEFAULT CURRENT_TIMESTAMP;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS internal_notes;
@@ -0,0 +1,12 @@
+-- Staff annotations on transactions and users, served only on admin routes.
+-- Append-only: corrections are new notes.
+CREATE TABLE internal_notes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    subject_type VARCHAR(20) NOT NULL CHECK (subject_type IN ('transaction', 'user')),
+    subject_id UUID NOT NULL,
+    body TEXT NOT NULL,
+    author_id UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_internal_notes_subject ON internal_notes(subject_type, subject_id, created_at);
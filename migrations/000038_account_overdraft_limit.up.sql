@@ -0,0 +1,2 @@
+-- How far below zero an account may go. Zero, the default, means no overdraft.
+ALTER TABLE accounts ADD COLUMN overdraft_limit DECIMAL(15, 2) NOT NULL DEFAULT 0 CHECK (overdraft_limit >= 0);
@@ -0,0 +1,19 @@
+-- Funds reserved on an account for payments not settled yet. Active holds
+-- lower the available balance until captured, released or expired.
+CREATE TABLE account_holds (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    account_id UUID NOT NULL REFERENCES accounts(id),
+    amount DECIMAL(15, 2) NOT NULL CHECK (amount > 0),
+    currency VARCHAR(3) NOT NULL,
+    purpose VARCHAR(30) NOT NULL CHECK (purpose IN ('card_authorization', 'bulk_transfer', 'other')),
+    reference VARCHAR(255),
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'captured', 'released', 'expired')),
+    expires_at TIMESTAMP NOT NULL,
+    captured_amount DECIMAL(15, 2),
+    transaction_id UUID REFERENCES transaction_keys(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_account_holds_account_active ON account_holds(account_id) WHERE status = 'active';
+CREATE INDEX idx_account_holds_expiry ON account_holds(expires_at) WHERE status = 'active';
@@ -0,0 +1,5 @@
+ALTER TABLE account_balances DROP CONSTRAINT IF EXISTS account_balances_non_negative;
+ALTER TABLE account_balances ADD CONSTRAINT account_balances_balance_check CHECK (balance >= 0);
+
+ALTER TABLE accounts DROP CONSTRAINT IF EXISTS accounts_balance_within_overdraft;
+ALTER TABLE accounts ADD CONSTRAINT accounts_balance_check CHECK (balance >= 0);
@@ -0,0 +1,8 @@
+-- Balances may not go below zero, or below minus the overdraft limit for
+-- accounts that have one. The constraints are named so the application can
+-- recognise a refused debit.
+ALTER TABLE accounts DROP CONSTRAINT IF EXISTS accounts_balance_check;
+ALTER TABLE accounts ADD CONSTRAINT accounts_balance_within_overdraft CHECK (balance >= -overdraft_limit);
+
+ALTER TABLE account_balances DROP CONSTRAINT IF EXISTS account_balances_balance_check;
+ALTER TABLE account_balances ADD CONSTRAINT account_balances_non_negative CHECK (balance >= 0);
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS increment_accounts_version ON accounts;
+DROP FUNCTION IF EXISTS increment_version_column();
+ALTER TABLE accounts DROP COLUMN IF EXISTS version;
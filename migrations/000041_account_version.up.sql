@@ -0,0 +1,15 @@
+ALTER TABLE accounts ADD COLUMN version INT NOT NULL DEFAULT 1;
+
+-- Every update to an account moves its version on, including the balance
+-- changes made directly by other repositories, so a compare-and-swap on
+-- version catches any concurrent change
+CREATE OR REPLACE FUNCTION increment_version_column()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.version = OLD.version + 1;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER increment_accounts_version BEFORE UPDATE ON accounts
+    FOR EACH ROW EXECUTE FUNCTION increment_version_column();
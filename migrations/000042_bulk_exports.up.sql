@@ -0,0 +1,21 @@
+-- Admin-requested exports of all statements or transactions for a period,
+-- run by the job queue and written to object storage
+CREATE TABLE bulk_exports (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('statements', 'transactions')),
+    period_start DATE NOT NULL,
+    period_end DATE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'completed', 'failed')),
+    total INT NOT NULL DEFAULT 0,
+    processed INT NOT NULL DEFAULT 0,
+    location TEXT,
+    error TEXT,
+    requested_by UUID NOT NULL REFERENCES users(id),
+    started_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (period_end >= period_start)
+);
+
+CREATE INDEX idx_bulk_exports_created ON bulk_exports(created_at DESC);
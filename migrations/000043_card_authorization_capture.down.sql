@@ -0,0 +1,4 @@
+ALTER TABLE card_authorizations
+    DROP COLUMN IF EXISTS captured_at,
+    DROP COLUMN IF EXISTS transaction_id,
+    DROP COLUMN IF EXISTS captured_amount;
@@ -0,0 +1,6 @@
+-- What an acquirer captured of an approved authorization, and for a debit
+-- card the transaction that debited the account
+ALTER TABLE card_authorizations
+    ADD COLUMN captured_amount DECIMAL(15, 2),
+    ADD COLUMN transaction_id UUID REFERENCES transaction_keys(id),
+    ADD COLUMN captured_at TIMESTAMP;
@@ -0,0 +1,10 @@
+// Package migrations embeds the SQL migrations so binaries do not need the
+// migrations directory at runtime.
+package migrations
+
+import "embed"
+
+// FS holds every *.sql migration in this directory
+//
+//go:embed *.sql
+var FS embed.FS
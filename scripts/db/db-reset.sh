@@ -24,7 +24,7 @@ fi
 
 echo "Running Migrate DOWN (Drop all tables)..."
 export DATABASE_URL=$DB_URL
-go run cmd/migrate/main.go -down
+go run ./cmd/migrate down
 
 if [ $? -ne 0 ]; then
     echo "❌ Migrate Down failed!"
@@ -32,7 +32,7 @@ if [ $? -ne 0 ]; then
 fi
 
 echo "Running Migrate UP (Re-create tables)..."
-go run cmd/migrate/main.go -up
+go run ./cmd/migrate up
 
 if [ $? -ne 0 ]; then
     echo "❌ Migrate Up failed!"
@@ -0,0 +1,138 @@
+package integration
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/darisadam/madabank-server/internal/domain/account"
+	"github.com/darisadam/madabank-server/internal/domain/transaction"
+	"github.com/darisadam/madabank-server/internal/domain/user"
+	"github.com/darisadam/madabank-server/internal/repository"
+)
+
+// newAccount creates a user with an active checking account holding balance
+func newAccount(t *testing.T, balance float64) *account.Account {
+	t.Helper()
+	u := &user.User{
+		ID:           uuid.New(),
+		Email:        uuid.NewString() + "@example.com",
+		PasswordHash: "unused",
+		FirstName:    "Integration",
+		LastName:     "Test",
+		KYCStatus:    "verified",
+		IsActive:     true,
+	}
+	require.NoError(t, repository.NewUserRepository(testDB).Create(u))
+
+	accounts := repository.NewAccountRepository(testDB)
+	number, err := accounts.GenerateAccountNumber()
+	require.NoError(t, err)
+	acc := &account.Account{
+		ID:            uuid.New(),
+		UserID:        u.ID,
+		AccountNumber: number,
+		AccountType:   account.AccountTypeChecking,
+		Balance:       balance,
+		Currency:      "IDR",
+		Status:        account.AccountStatusActive,
+	}
+	require.NoError(t, accounts.Create(acc))
+	return acc
+}
+
+func newTransfer() *transaction.Transaction {
+	return &transaction.Transaction{
+		ID:              uuid.New(),
+		IdempotencyKey:  uuid.NewString(),
+		TransactionType: transaction.TransactionTypeTransfer,
+	}
+}
+
+func balanceOf(t *testing.T, id uuid.UUID) float64 {
+	t.Helper()
+	acc, err := repository.NewAccountRepository(testDB).GetByID(id)
+	require.NoError(t, err)
+	return acc.Balance
+}
+
+func TestTransfer_HoldBlocksTransfer(t *testing.T) {
+	newTestServer(t) // skips without a database and runs the migrations
+	setupTestDB(t)
+
+	from := newAccount(t, 100)
+	to := newAccount(t, 0)
+	holds := repository.NewHoldRepository(testDB)
+	txns := repository.NewTransactionRepository(testDB, sql.LevelReadCommitted)
+
+	hold := &account.Hold{
+		ID:        uuid.New(),
+		AccountID: from.ID,
+		Amount:    70,
+		Purpose:   account.HoldPurposeOther,
+		Status:    account.HoldStatusActive,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	require.NoError(t, holds.Place(hold))
+
+	err := txns.ExecuteTransfer(from.ID, to.ID, 40, newTransfer())
+	assert.ErrorIs(t, err, transaction.ErrInsufficientFunds)
+	assert.Equal(t, 100.0, balanceOf(t, from.ID))
+
+	require.NoError(t, holds.Release(hold.ID))
+	require.NoError(t, txns.ExecuteTransfer(from.ID, to.ID, 40, newTransfer()))
+	assert.Equal(t, 60.0, balanceOf(t, from.ID))
+}
+
+func TestTransfer_OverdraftUpToLimit(t *testing.T) {
+	newTestServer(t)
+	setupTestDB(t)
+
+	from := newAccount(t, 20)
+	to := newAccount(t, 0)
+	_, err := testDB.Exec(`UPDATE accounts SET overdraft_limit = 50 WHERE id = $1`, from.ID)
+	require.NoError(t, err)
+	txns := repository.NewTransactionRepository(testDB, sql.LevelReadCommitted)
+
+	require.NoError(t, txns.ExecuteTransfer(from.ID, to.ID, 60, newTransfer()))
+	assert.Equal(t, -40.0, balanceOf(t, from.ID))
+
+	err = txns.ExecuteTransfer(from.ID, to.ID, 20, newTransfer())
+	assert.ErrorIs(t, err, transaction.ErrInsufficientFunds)
+
+	require.NoError(t, txns.ExecuteTransfer(from.ID, to.ID, 10, newTransfer()))
+	assert.Equal(t, -50.0, balanceOf(t, from.ID))
+}
+
+func TestBillPayment_HoldBlocksPayment(t *testing.T) {
+	newTestServer(t)
+	setupTestDB(t)
+
+	acc := newAccount(t, 100)
+	require.NoError(t, repository.NewHoldRepository(testDB).Place(&account.Hold{
+		ID:        uuid.New(),
+		AccountID: acc.ID,
+		Amount:    70,
+		Purpose:   account.HoldPurposeOther,
+		Status:    account.HoldStatusActive,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}))
+	bills := repository.NewBillPaymentRepository(testDB)
+
+	payment := func() *transaction.Transaction {
+		txn := newTransfer()
+		txn.TransactionType = transaction.TransactionTypeBillPayment
+		return txn
+	}
+
+	err := bills.ExecuteBillPayment(acc.ID, 40, payment())
+	assert.ErrorIs(t, err, transaction.ErrInsufficientFunds)
+	assert.Equal(t, 100.0, balanceOf(t, acc.ID))
+
+	require.NoError(t, bills.ExecuteBillPayment(acc.ID, 30, payment()))
+	assert.Equal(t, 70.0, balanceOf(t, acc.ID))
+}
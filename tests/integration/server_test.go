@@ -0,0 +1,104 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/darisadam/madabank-server/internal/app"
+	"github.com/darisadam/madabank-server/internal/domain/user"
+)
+
+// newTestServer serves the whole API against the test database and an
+// in-memory Redis, without the background jobs
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	if err := testDB.Ping(); err != nil {
+		t.Skipf("test database not available: %v", err)
+	}
+
+	t.Setenv("JWT_SECRET", "integration-test-secret")
+	t.Setenv("ENCRYPTION_KEY", "0123456789abcdef0123456789abcdef")
+	t.Setenv("AUTO_MIGRATE", "true")
+
+	mr := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = redisClient.Close() })
+
+	application, err := app.New(app.Options{
+		Env:         "test",
+		DB:          testDB,
+		Redis:       redisClient,
+		DisableJobs: true,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = application.Shutdown(ctx)
+	})
+
+	srv := httptest.NewServer(application.Handler())
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func postJSON(t *testing.T, url string, body any) *http.Response {
+	t.Helper()
+	payload, err := json.Marshal(body)
+	require.NoError(t, err)
+	resp, err := http.Post(url, "application/json", bytes.NewReader(payload))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	return resp
+}
+
+func TestServer_Ready(t *testing.T) {
+	srv := newTestServer(t)
+
+	resp, err := http.Get(srv.URL + "/ready")
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestServer_RegisterLoginProfile(t *testing.T) {
+	srv := newTestServer(t)
+	setupTestDB(t)
+
+	resp := postJSON(t, srv.URL+"/api/v1/auth/register", user.CreateUserRequest{
+		Email:     "integration@example.com",
+		Password:  "Password123",
+		FirstName: "Integration",
+		LastName:  "Test",
+	})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	resp = postJSON(t, srv.URL+"/api/v1/auth/login", user.LoginRequest{
+		Email:    "integration@example.com",
+		Password: "Password123",
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var login user.LoginResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&login))
+	require.NotEmpty(t, login.Token)
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/api/v1/users/profile", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+login.Token)
+	profile, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer func() { _ = profile.Body.Close() }()
+
+	assert.Equal(t, http.StatusOK, profile.StatusCode)
+}
@@ -48,5 +48,3 @@ func setupTestDB(t *testing.T) {
 		}
 	}
 }
-
-var _ = setupTestDB // Suppress unused warning